@@ -0,0 +1,450 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/advice"
+	"gasboat/controller/internal/beadsapi"
+)
+
+// apiPrefix is the versioned JSON API root. It sits behind the same auth
+// middleware as the HTML UI.
+const apiPrefix = "/api/v1"
+
+// registerAPIRoutes adds the JSON API routes to the mux.
+func (s *Server) registerAPIRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET "+apiPrefix+"/advice", s.apiListAdvice)
+	mux.HandleFunc("POST "+apiPrefix+"/advice", s.apiCreateAdvice)
+	mux.HandleFunc("GET "+apiPrefix+"/advice/{id}", s.apiGetAdvice)
+	mux.HandleFunc("PATCH "+apiPrefix+"/advice/{id}", s.apiUpdateAdvice)
+	mux.HandleFunc("DELETE "+apiPrefix+"/advice/{id}", s.apiDeleteAdvice)
+	mux.HandleFunc("POST "+apiPrefix+"/advice/{id}/feedback", s.apiAdviceFeedback)
+	mux.HandleFunc("GET "+apiPrefix+"/advice/{id}/revisions", s.apiAdviceRevisions)
+	mux.HandleFunc("POST "+apiPrefix+"/advice/{id}/rollback", s.apiAdviceRollback)
+	mux.HandleFunc("GET "+apiPrefix+"/agents/{id}/advice", s.apiAgentAdvice)
+	mux.HandleFunc("POST "+apiPrefix+"/generate", s.apiGenerate)
+}
+
+// adviceJSON is the API representation of an advice bead.
+type adviceJSON struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Labels      []string `json:"labels"`
+	HookCommand string   `json:"hook_command,omitempty"`
+	HookTrigger string   `json:"hook_trigger,omitempty"`
+	CreatedBy   string   `json:"created_by,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+
+	Effectiveness advice.Effectiveness `json:"effectiveness"`
+}
+
+func toAdviceJSON(b *beadsapi.BeadDetail) adviceJSON {
+	a := adviceJSON{
+		ID:          b.ID,
+		Title:       b.Title,
+		Description: b.Description,
+		Status:      b.Status,
+		Labels:      b.Labels,
+		HookCommand: b.Fields["hook_command"],
+		HookTrigger: b.Fields["hook_trigger"],
+		CreatedBy:   b.CreatedBy,
+
+		Effectiveness: advice.EffectivenessOf(b),
+	}
+	if a.Labels == nil {
+		a.Labels = []string{}
+	}
+	if !b.UpdatedAt.IsZero() {
+		a.UpdatedAt = b.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return a
+}
+
+// adviceWriteRequest is the body of create and update requests. Pointer
+// fields distinguish "not given" from "clear" on update.
+type adviceWriteRequest struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Labels      *[]string `json:"labels"`
+	HookCommand *string   `json:"hook_command"`
+	HookTrigger *string   `json:"hook_trigger"`
+
+	// Targeting shorthands, create only (see targetingLabels).
+	Rig   string `json:"rig"`
+	Role  string `json:"role"`
+	Agent string `json:"agent"`
+}
+
+func writeAPIJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, msg string) {
+	writeAPIJSON(w, status, map[string]string{"error": msg})
+}
+
+// decodeAPIBody decodes a JSON request body, rejecting unknown fields.
+func decodeAPIBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// getAdviceBead fetches id and checks it is an advice bead, writing a 404
+// otherwise.
+func (s *Server) getAdviceBead(w http.ResponseWriter, r *http.Request, id string) (*beadsapi.BeadDetail, bool) {
+	bead, err := s.daemon.GetBead(r.Context(), id)
+	if err != nil {
+		var apiErr *beadsapi.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			writeAPIError(w, http.StatusNotFound, "advice not found")
+			return nil, false
+		}
+		s.logger.Error("api: getting advice", "id", id, "error", err)
+		writeAPIError(w, http.StatusBadGateway, "failed to get advice")
+		return nil, false
+	}
+	if bead.Type != "advice" {
+		writeAPIError(w, http.StatusNotFound, "advice not found")
+		return nil, false
+	}
+	return bead, true
+}
+
+// apiListAdvice returns open advice, filtered by the q, tag, role, project,
+// and fresh query parameters.
+func (s *Server) apiListAdvice(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAdviceQuery(r.URL.Query(), time.Now())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	beads, err := s.searchAdvice(r.Context(), q)
+	if err != nil {
+		s.logger.Error("api: listing advice", "error", err)
+		writeAPIError(w, http.StatusBadGateway, "failed to list advice")
+		return
+	}
+	items := make([]adviceJSON, len(beads))
+	for i, b := range beads {
+		items[i] = toAdviceJSON(b)
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]any{"advice": items, "total": len(items)})
+}
+
+// apiGetAdvice returns one advice bead.
+func (s *Server) apiGetAdvice(w http.ResponseWriter, r *http.Request) {
+	bead, ok := s.getAdviceBead(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	writeAPIJSON(w, http.StatusOK, toAdviceJSON(bead))
+}
+
+// apiCreateAdvice creates an advice bead. Labels default to global when no
+// targeting label is given, as in the HTML form.
+func (s *Server) apiCreateAdvice(w http.ResponseWriter, r *http.Request) {
+	var req adviceWriteRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if req.Title == nil || strings.TrimSpace(*req.Title) == "" {
+		writeAPIError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+	var labels []string
+	if req.Labels != nil {
+		labels = *req.Labels
+	}
+	labels = targetingLabels(labels, req.Rig, req.Role, req.Agent)
+
+	id, err := s.createAdvice(r.Context(), *req.Title, deref(req.Description), labels,
+		hookFields(deref(req.HookCommand), deref(req.HookTrigger)))
+	if err != nil {
+		s.logger.Error("api: creating advice", "error", err)
+		writeAPIError(w, http.StatusBadGateway, "failed to create advice")
+		return
+	}
+
+	bead, ok := s.getAdviceBead(w, r, id)
+	if !ok {
+		return
+	}
+	s.index.Put(bead)
+	w.Header().Set("Location", s.basePath+apiPrefix+"/advice/"+id)
+	writeAPIJSON(w, http.StatusCreated, toAdviceJSON(bead))
+}
+
+// apiUpdateAdvice applies a partial update. Labels, when given, replace the
+// bead's labels. The previous content is kept as a revision.
+func (s *Server) apiUpdateAdvice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var req adviceWriteRequest
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if req.Rig != "" || req.Role != "" || req.Agent != "" {
+		writeAPIError(w, http.StatusBadRequest, "rig, role, and agent apply to create only; set labels instead")
+		return
+	}
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		writeAPIError(w, http.StatusBadRequest, "title cannot be empty")
+		return
+	}
+	bead, ok := s.getAdviceBead(w, r, id)
+	if !ok {
+		return
+	}
+
+	next := contentOf(bead)
+	if req.Title != nil {
+		next.Title = *req.Title
+	}
+	if req.Description != nil {
+		next.Description = *req.Description
+	}
+	if req.Labels != nil {
+		next.Labels = *req.Labels
+	}
+	// Empty values are skipped: the daemon rejects an empty hook_trigger.
+	if v := deref(req.HookCommand); v != "" {
+		next.HookCommand = v
+	}
+	if v := deref(req.HookTrigger); v != "" {
+		next.HookTrigger = v
+	}
+	if err := s.saveAdvice(r.Context(), bead, next, editorName(r)); err != nil {
+		s.logger.Error("api: updating advice", "id", id, "error", err)
+		writeAPIError(w, http.StatusBadGateway, "failed to update advice")
+		return
+	}
+
+	bead, ok = s.getAdviceBead(w, r, id)
+	if !ok {
+		return
+	}
+	s.index.Put(bead)
+	writeAPIJSON(w, http.StatusOK, toAdviceJSON(bead))
+}
+
+// apiDeleteAdvice retires advice by closing its bead, so it stops matching
+// agents but stays in the audit trail.
+func (s *Server) apiDeleteAdvice(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.getAdviceBead(w, r, id); !ok {
+		return
+	}
+	if err := s.daemon.CloseBead(r.Context(), id, nil); err != nil {
+		s.logger.Error("api: closing advice", "id", id, "error", err)
+		writeAPIError(w, http.StatusBadGateway, "failed to close advice")
+		return
+	}
+	s.index.Remove(id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apiAdviceFeedback records one agent's vote on, or use of, an advice bead.
+// vote is 1 (helpful), -1 (unhelpful), or 0 (withdraw); omit it to record
+// only applied.
+func (s *Server) apiAdviceFeedback(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var req struct {
+		AgentID string `json:"agent_id"`
+		Vote    *int   `json:"vote"`
+		Applied bool   `json:"applied"`
+	}
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if req.AgentID == "" {
+		writeAPIError(w, http.StatusBadRequest, "agent_id is required")
+		return
+	}
+	if req.Vote == nil && !req.Applied {
+		writeAPIError(w, http.StatusBadRequest, "set vote, applied, or both")
+		return
+	}
+	if req.Vote != nil && (*req.Vote < -1 || *req.Vote > 1) {
+		writeAPIError(w, http.StatusBadRequest, "vote must be -1, 0, or 1")
+		return
+	}
+	if _, ok := s.getAdviceBead(w, r, id); !ok {
+		return
+	}
+
+	e, err := advice.RecordFeedback(r.Context(), s.daemon, id, req.AgentID, advice.FeedbackUpdate{
+		Vote:    req.Vote,
+		Applied: req.Applied,
+	})
+	if err != nil {
+		s.logger.Error("api: recording feedback", "id", id, "agent", req.AgentID, "error", err)
+		writeAPIError(w, http.StatusBadGateway, "failed to record feedback")
+		return
+	}
+	s.refreshIndex(r.Context(), id)
+	writeAPIJSON(w, http.StatusOK, map[string]any{"id": id, "effectiveness": e})
+}
+
+// revisionJSON is the API representation of an earlier advice version.
+type revisionJSON struct {
+	Rev         int        `json:"rev"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Labels      []string   `json:"labels"`
+	HookCommand string     `json:"hook_command,omitempty"`
+	HookTrigger string     `json:"hook_trigger,omitempty"`
+	ReplacedAt  string     `json:"replaced_at"`
+	ReplacedBy  string     `json:"replaced_by,omitempty"`
+	Diff        []diffLine `json:"description_diff,omitempty"` // from this version to the next
+}
+
+// apiAdviceRevisions returns an advice bead's earlier versions, newest
+// first, each with a description diff to the version that replaced it.
+func (s *Server) apiAdviceRevisions(w http.ResponseWriter, r *http.Request) {
+	bead, ok := s.getAdviceBead(w, r, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	versions := adviceHistory(bead) // newest first, current at [0]
+	items := make([]revisionJSON, 0, len(versions)-1)
+	for i := 1; i < len(versions); i++ {
+		v := versions[i]
+		item := revisionJSON{
+			Rev:         v.Rev,
+			Title:       v.Content.Title,
+			Description: v.Content.Description,
+			Labels:      v.Content.Labels,
+			HookCommand: v.Content.HookCommand,
+			HookTrigger: v.Content.HookTrigger,
+			ReplacedAt:  v.ReplacedAt.UTC().Format("2006-01-02T15:04:05Z"),
+			ReplacedBy:  v.ReplacedBy,
+		}
+		if item.Labels == nil {
+			item.Labels = []string{}
+		}
+		if newer := versions[i-1]; newer.DescChanged {
+			item.Diff = newer.DescDiff
+		}
+		items = append(items, item)
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]any{
+		"current":     toAdviceJSON(bead),
+		"current_rev": versions[0].Rev,
+		"revisions":   items,
+	})
+}
+
+// apiAdviceRollback restores an earlier revision. The replaced content
+// becomes a new revision.
+func (s *Server) apiAdviceRollback(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var req struct {
+		Rev int `json:"rev"`
+	}
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	bead, ok := s.getAdviceBead(w, r, id)
+	if !ok {
+		return
+	}
+	if err := s.rollbackAdvice(r.Context(), bead, req.Rev, editorName(r)); err != nil {
+		if errors.Is(err, errRevisionNotFound) {
+			writeAPIError(w, http.StatusNotFound, "revision not found")
+			return
+		}
+		s.logger.Error("api: rolling back advice", "id", id, "rev", req.Rev, "error", err)
+		writeAPIError(w, http.StatusBadGateway, "failed to roll back advice")
+		return
+	}
+
+	bead, ok = s.getAdviceBead(w, r, id)
+	if !ok {
+		return
+	}
+	s.index.Put(bead)
+	writeAPIJSON(w, http.StatusOK, toAdviceJSON(bead))
+}
+
+// matchedAdviceJSON is advice matched for an agent.
+type matchedAdviceJSON struct {
+	adviceJSON
+	MatchedLabels []string `json:"matched_labels"`
+	Scope         string   `json:"scope"`
+	ScopeTarget   string   `json:"scope_target,omitempty"`
+}
+
+// apiAgentAdvice returns the advice matched for an agent and the agent's
+// subscriptions.
+func (s *Server) apiAgentAdvice(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("id")
+	matched, subs, err := advice.ListAdviceForAgent(r.Context(), s.daemon, agentID)
+	if err != nil {
+		s.logger.Error("api: listing advice for agent", "agent", agentID, "error", err)
+		writeAPIError(w, http.StatusBadGateway, "failed to list advice")
+		return
+	}
+	items := make([]matchedAdviceJSON, len(matched))
+	for i, m := range matched {
+		items[i] = matchedAdviceJSON{
+			adviceJSON:    toAdviceJSON(m.Bead),
+			MatchedLabels: m.MatchedLabels,
+			Scope:         m.Scope,
+			ScopeTarget:   m.ScopeTarget,
+		}
+	}
+	writeAPIJSON(w, http.StatusOK, map[string]any{
+		"agent_id":      agentID,
+		"subscriptions": subs,
+		"advice":        items,
+		"total":         len(items),
+	})
+}
+
+// apiGenerate dispatches an advice-generation agent.
+func (s *Server) apiGenerate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Topic   string `json:"topic"`
+		Project string `json:"project"`
+	}
+	if !decodeAPIBody(w, r, &req) {
+		return
+	}
+	if req.Topic == "" || req.Project == "" {
+		writeAPIError(w, http.StatusBadRequest, "topic and project are required")
+		return
+	}
+
+	d, err := s.dispatchGeneration(r.Context(), req.Topic, req.Project)
+	if d.TaskID == "" {
+		writeAPIError(w, http.StatusBadGateway, "failed to create generation task")
+		return
+	}
+	if err != nil {
+		writeAPIJSON(w, http.StatusBadGateway, map[string]any{
+			"error":   "created task but failed to spawn agent",
+			"task_id": d.TaskID,
+		})
+		return
+	}
+	writeAPIJSON(w, http.StatusAccepted, d)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gasboat/controller/internal/advice"
+)
+
+// apiRequest runs a request against a test server's routes.
+func apiRequest(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	srv, _ := testServer(t)
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestAPIListAdvice(t *testing.T) {
+	w := apiRequest(t, "GET", "/api/v1/advice", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var resp struct {
+		Advice []adviceJSON `json:"advice"`
+		Total  int          `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 2 || len(resp.Advice) != 2 {
+		t.Fatalf("expected 2 advice, got %+v", resp)
+	}
+	if resp.Advice[0].ID != "test-advice-1" || resp.Advice[1].Labels[0] != "role:crew" {
+		t.Errorf("unexpected advice: %+v", resp.Advice)
+	}
+}
+
+func TestAPIGetAdvice(t *testing.T) {
+	w := apiRequest(t, "GET", "/api/v1/advice/test-advice-1", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var a adviceJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Title != "Test Advice" || a.HookCommand != "echo hi" {
+		t.Errorf("unexpected advice: %+v", a)
+	}
+}
+
+func TestAPIGetAdvice_NotFound(t *testing.T) {
+	w := apiRequest(t, "GET", "/api/v1/advice/missing", "")
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"error"`) {
+		t.Errorf("expected JSON error body, got %s", w.Body.String())
+	}
+}
+
+func TestAPICreateAdvice(t *testing.T) {
+	w := apiRequest(t, "POST", "/api/v1/advice", `{"title":"New Advice","description":"d","role":"crew"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if loc := w.Header().Get("Location"); loc != "/api/v1/advice/new-bead-id" {
+		t.Errorf("Location = %q", loc)
+	}
+	var a adviceJSON
+	if err := json.Unmarshal(w.Body.Bytes(), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != "new-bead-id" {
+		t.Errorf("ID = %q", a.ID)
+	}
+}
+
+func TestAPICreateAdvice_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing title", `{"description":"d"}`},
+		{"blank title", `{"title":"  "}`},
+		{"unknown field", `{"title":"t","scope":"global"}`},
+		{"malformed", `{"title":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := apiRequest(t, "POST", "/api/v1/advice", tt.body)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestAPIUpdateAdvice(t *testing.T) {
+	w := apiRequest(t, "PATCH", "/api/v1/advice/test-advice-1",
+		`{"title":"Updated","labels":["global","role:crew"],"hook_trigger":"session-end"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = apiRequest(t, "PATCH", "/api/v1/advice/test-advice-1", `{"role":"crew"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("targeting shorthand on update: expected 400, got %d", w.Code)
+	}
+
+	w = apiRequest(t, "PATCH", "/api/v1/advice/missing", `{"title":"x"}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing advice: expected 404, got %d", w.Code)
+	}
+}
+
+func TestAPIDeleteAdvice(t *testing.T) {
+	w := apiRequest(t, "DELETE", "/api/v1/advice/test-advice-1", "")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+}
+
+func TestAPIAgentAdvice(t *testing.T) {
+	w := apiRequest(t, "GET", "/api/v1/agents/agent-1/advice", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		AgentID       string              `json:"agent_id"`
+		Subscriptions []string            `json:"subscriptions"`
+		Advice        []matchedAdviceJSON `json:"advice"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.AgentID != "agent-1" || len(resp.Subscriptions) == 0 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	found := false
+	for _, a := range resp.Advice {
+		if a.ID == "test-advice-1" && a.Scope == "global" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected global advice in matches, got %+v", resp.Advice)
+	}
+}
+
+func TestAPIGenerate(t *testing.T) {
+	w := apiRequest(t, "POST", "/api/v1/generate", `{"topic":"testing","project":"gasboat"}`)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", w.Code, w.Body.String())
+	}
+	var d generationDispatch
+	if err := json.Unmarshal(w.Body.Bytes(), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.TaskID != "new-bead-id" || d.AgentName == "" {
+		t.Errorf("unexpected dispatch: %+v", d)
+	}
+
+	w = apiRequest(t, "POST", "/api/v1/generate", `{"topic":"testing"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing project: expected 400, got %d", w.Code)
+	}
+}
+
+func TestAPIAdviceFeedback(t *testing.T) {
+	w := apiRequest(t, "POST", "/api/v1/advice/test-advice-1/feedback", `{"agent_id":"agent-1","vote":1,"applied":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Effectiveness advice.Effectiveness `json:"effectiveness"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if e := resp.Effectiveness; e.Up != 1 || e.Applied != 1 || e.Score <= 0.5 {
+		t.Errorf("unexpected effectiveness: %+v", e)
+	}
+
+	for _, body := range []string{
+		`{"vote":1}`,
+		`{"agent_id":"agent-1"}`,
+		`{"agent_id":"agent-1","vote":2}`,
+	} {
+		if w := apiRequest(t, "POST", "/api/v1/advice/test-advice-1/feedback", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, w.Code)
+		}
+	}
+	if w := apiRequest(t, "POST", "/api/v1/advice/missing/feedback", `{"agent_id":"a","vote":1}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing advice: expected 404, got %d", w.Code)
+	}
+}
+
+func TestAPIListAdvice_SortByScore(t *testing.T) {
+	w := apiRequest(t, "GET", "/api/v1/advice?sort=score", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Advice []adviceJSON `json:"advice"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Advice) != 2 || resp.Advice[0].ID != "test-advice-2" {
+		t.Fatalf("expected rated advice first, got %+v", resp.Advice)
+	}
+	if !resp.Advice[0].Effectiveness.Rated() || resp.Advice[1].Effectiveness.Rated() {
+		t.Errorf("unexpected effectiveness: %+v", resp.Advice)
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/advice"
+	"gasboat/controller/internal/beadsapi"
+)
+
+const (
+	// indexResyncInterval bounds how stale the index can get from changes the
+	// event stream does not carry (e.g. label-only edits).
+	indexResyncInterval = 5 * time.Minute
+	// indexReconnectDelay is the pause before resubscribing after the event
+	// stream drops.
+	indexReconnectDelay = 5 * time.Second
+)
+
+// adviceIndex is an in-memory search index over open advice beads. It is
+// loaded with one full listing and then kept current from bead SSE events,
+// so list and search requests do not re-scan the daemon.
+type adviceIndex struct {
+	daemon *beadsapi.Client
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	docs   map[string]indexedAdvice
+	loaded bool
+}
+
+// indexedAdvice is an advice bead with its search keys precomputed.
+type indexedAdvice struct {
+	bead   *beadsapi.BeadDetail
+	title  string          // lowercased
+	body   string          // lowercased description
+	labels map[string]bool // gN: group prefixes stripped
+	score  float64         // effectiveness score
+}
+
+func newIndexedAdvice(b *beadsapi.BeadDetail) indexedAdvice {
+	doc := indexedAdvice{
+		bead:   b,
+		title:  strings.ToLower(b.Title),
+		body:   strings.ToLower(b.Description),
+		labels: make(map[string]bool, len(b.Labels)),
+		score:  advice.EffectivenessOf(b).Score,
+	}
+	for _, l := range b.Labels {
+		doc.labels[advice.StripGroupPrefix(l)] = true
+	}
+	return doc
+}
+
+func newAdviceIndex(daemon *beadsapi.Client, logger *slog.Logger) *adviceIndex {
+	return &adviceIndex{
+		daemon: daemon,
+		logger: logger,
+		docs:   make(map[string]indexedAdvice),
+	}
+}
+
+// Run keeps the index current until ctx is canceled.
+func (x *adviceIndex) Run(ctx context.Context) {
+	for {
+		x.follow(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(indexReconnectDelay):
+		}
+	}
+}
+
+// follow subscribes to bead events, reloads the index, and applies events
+// until the stream ends. Subscribing before reloading means no change made
+// during the reload is missed.
+func (x *adviceIndex) follow(ctx context.Context) {
+	events, err := x.daemon.EventStream(ctx, "beads.bead.>")
+	if err != nil {
+		x.logger.Warn("advice index: subscribing to bead events", "error", err)
+	}
+	if err := x.Reload(ctx); err != nil && ctx.Err() == nil {
+		x.logger.Warn("advice index: loading advice", "error", err)
+	}
+	if events == nil {
+		return
+	}
+
+	resync := time.NewTicker(indexResyncInterval)
+	defer resync.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-events:
+			if !ok {
+				x.logger.Info("advice index: event stream ended, reconnecting")
+				return
+			}
+			x.apply(ctx, evt)
+		case <-resync.C:
+			if err := x.Reload(ctx); err != nil && ctx.Err() == nil {
+				x.logger.Warn("advice index: resync", "error", err)
+			}
+		}
+	}
+}
+
+// Reload replaces the index contents with a full listing of open advice.
+func (x *adviceIndex) Reload(ctx context.Context) error {
+	beads, err := advice.ListAllAdvice(ctx, x.daemon)
+	if err != nil {
+		return err
+	}
+	docs := make(map[string]indexedAdvice, len(beads))
+	for _, b := range beads {
+		docs[b.ID] = newIndexedAdvice(b)
+	}
+	x.mu.Lock()
+	x.docs = docs
+	x.loaded = true
+	x.mu.Unlock()
+	x.logger.Debug("advice index: loaded", "count", len(docs))
+	return nil
+}
+
+// apply updates the index from one beads.bead.* event. The event payload
+// carries fields as raw JSON and may omit labels, so changed advice is
+// re-read from the daemon.
+func (x *adviceIndex) apply(ctx context.Context, evt beadsapi.SSEEvent) {
+	var payload struct {
+		Bead struct {
+			ID     string `json:"id"`
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		} `json:"bead"`
+	}
+	if err := json.Unmarshal(evt.Data, &payload); err != nil || payload.Bead.ID == "" {
+		return
+	}
+	b := payload.Bead
+	if b.Type != "advice" {
+		return
+	}
+	if strings.HasSuffix(evt.Event, ".deleted") || strings.HasSuffix(evt.Event, ".closed") || b.Status == "closed" {
+		x.Remove(b.ID)
+		return
+	}
+
+	bead, err := x.daemon.GetBead(ctx, b.ID)
+	if err != nil {
+		var apiErr *beadsapi.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			x.Remove(b.ID)
+			return
+		}
+		x.logger.Warn("advice index: refreshing advice", "id", b.ID, "error", err)
+		return
+	}
+	x.Put(bead)
+}
+
+// Put adds or replaces an advice bead. Beads that are not open advice are
+// removed instead.
+func (x *adviceIndex) Put(b *beadsapi.BeadDetail) {
+	if b.Type != "advice" || b.Status != "open" {
+		x.Remove(b.ID)
+		return
+	}
+	doc := newIndexedAdvice(b)
+	x.mu.Lock()
+	x.docs[b.ID] = doc
+	x.mu.Unlock()
+}
+
+// Remove drops an advice bead from the index.
+func (x *adviceIndex) Remove(id string) {
+	x.mu.Lock()
+	delete(x.docs, id)
+	x.mu.Unlock()
+}
+
+// Search returns the indexed advice matching q. ok is false until the index
+// has loaded, in which case callers should fall back to listing the daemon.
+func (x *adviceIndex) Search(q adviceQuery) (results []*beadsapi.BeadDetail, ok bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if !x.loaded {
+		return nil, false
+	}
+	docs := make([]indexedAdvice, 0, len(x.docs))
+	for _, doc := range x.docs {
+		docs = append(docs, doc)
+	}
+	return q.filter(docs), true
+}
+
+// adviceQuery holds search and filter parameters for the advice list.
+type adviceQuery struct {
+	Text    string    // all terms must appear in the title or description
+	Tags    []string  // all labels must be present (gN: prefixes ignored)
+	Role    string    // matches role:<Role>, singular or plural
+	Project string    // matches rig:<Project>
+	Fresh   string    // raw freshness value, e.g. "7d" or "12h"
+	Since   time.Time // derived from Fresh; zero for no limit
+	Sort    string    // "" for newest first, "score" for most effective first
+}
+
+// parseAdviceQuery reads q, tag (repeatable or comma-separated), role,
+// project, fresh, and sort from query parameters.
+func parseAdviceQuery(v url.Values, now time.Time) (adviceQuery, error) {
+	q := adviceQuery{
+		Text:    strings.TrimSpace(v.Get("q")),
+		Role:    strings.TrimSpace(v.Get("role")),
+		Project: strings.TrimSpace(v.Get("project")),
+		Fresh:   strings.TrimSpace(v.Get("fresh")),
+		Sort:    v.Get("sort"),
+	}
+	if q.Sort != "" && q.Sort != "score" {
+		return q, fmt.Errorf("invalid sort %q: use score or leave empty", q.Sort)
+	}
+	for _, raw := range v["tag"] {
+		for _, tag := range strings.Split(raw, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				q.Tags = append(q.Tags, tag)
+			}
+		}
+	}
+	if q.Fresh != "" {
+		d, err := parseFreshness(q.Fresh)
+		if err != nil {
+			return q, err
+		}
+		q.Since = now.Add(-d)
+	}
+	return q, nil
+}
+
+// parseFreshness parses a positive duration, accepting a "d" suffix for days.
+func parseFreshness(s string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid freshness %q: use e.g. 7d or 12h", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid freshness %q: use e.g. 7d or 12h", s)
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid freshness %q: must be positive", s)
+	}
+	return d, nil
+}
+
+// IsZero reports whether q has no search terms or filters.
+func (q adviceQuery) IsZero() bool {
+	return q.Text == "" && len(q.Tags) == 0 && q.Role == "" && q.Project == "" && q.Fresh == ""
+}
+
+// filter returns the docs matching q, newest first or, with Sort "score",
+// most effective first. With a text query, title matches rank ahead of
+// description-only matches.
+func (q adviceQuery) filter(docs []indexedAdvice) []*beadsapi.BeadDetail {
+	terms := strings.Fields(strings.ToLower(q.Text))
+	type hit struct {
+		bead    *beadsapi.BeadDetail
+		inTitle bool
+		score   float64
+	}
+	var hits []hit
+	for _, doc := range docs {
+		if !q.matchesFilters(doc) {
+			continue
+		}
+		inTitle := true
+		matched := true
+		for _, term := range terms {
+			if strings.Contains(doc.title, term) {
+				continue
+			}
+			inTitle = false
+			if !strings.Contains(doc.body, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			hits = append(hits, hit{doc.bead, inTitle, doc.score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := hits[i], hits[j]
+		if len(terms) > 0 && a.inTitle != b.inTitle {
+			return a.inTitle
+		}
+		if q.Sort == "score" && a.score != b.score {
+			return a.score > b.score
+		}
+		if !a.bead.UpdatedAt.Equal(b.bead.UpdatedAt) {
+			return a.bead.UpdatedAt.After(b.bead.UpdatedAt)
+		}
+		return a.bead.ID < b.bead.ID
+	})
+	results := make([]*beadsapi.BeadDetail, len(hits))
+	for i, h := range hits {
+		results[i] = h.bead
+	}
+	return results
+}
+
+func (q adviceQuery) matchesFilters(doc indexedAdvice) bool {
+	for _, tag := range q.Tags {
+		if !doc.labels[tag] {
+			return false
+		}
+	}
+	if q.Role != "" && !doc.labels["role:"+q.Role] && !doc.labels["role:"+advice.Singularize(q.Role)] {
+		return false
+	}
+	if q.Project != "" && !doc.labels["rig:"+q.Project] {
+		return false
+	}
+	if !q.Since.IsZero() && doc.bead.UpdatedAt.Before(q.Since) {
+		return false
+	}
+	return true
+}
+
+// searchAdvice returns the advice matching q from the index, or from a full
+// listing while the index is still loading.
+func (s *Server) searchAdvice(ctx context.Context, q adviceQuery) ([]*beadsapi.BeadDetail, error) {
+	if results, ok := s.index.Search(q); ok {
+		return results, nil
+	}
+	beads, err := advice.ListAllAdvice(ctx, s.daemon)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]indexedAdvice, len(beads))
+	for i, b := range beads {
+		docs[i] = newIndexedAdvice(b)
+	}
+	return q.filter(docs), nil
+}
+
+// refreshIndex re-reads one advice bead into the index after a write made
+// through the viewer, so the next page load reflects it without waiting for
+// the event stream. Errors are ignored; the stream or resync catches up.
+func (s *Server) refreshIndex(ctx context.Context, id string) {
+	bead, err := s.daemon.GetBead(ctx, id)
+	if err != nil {
+		return
+	}
+	s.index.Put(bead)
+}
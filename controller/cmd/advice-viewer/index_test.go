@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func testDocs(now time.Time) []indexedAdvice {
+	beads := []*beadsapi.BeadDetail{
+		{ID: "a1", Title: "Run tests before pushing", Description: "Use make test.", Labels: []string{"global", "topic:testing"}, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "a2", Title: "Crew etiquette", Description: "Always run tests in CI.", Labels: []string{"g0:rig:gasboat", "g0:role:crew"}, UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "a3", Title: "Polecat cleanup", Description: "Remove worktrees.", Labels: []string{"role:polecat", "rig:beads"}, UpdatedAt: now.Add(-30 * 24 * time.Hour)},
+	}
+	docs := make([]indexedAdvice, len(beads))
+	for i, b := range beads {
+		docs[i] = newIndexedAdvice(b)
+	}
+	return docs
+}
+
+func resultIDs(beads []*beadsapi.BeadDetail) string {
+	ids := make([]string, len(beads))
+	for i, b := range beads {
+		ids[i] = b.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestAdviceQuery_Filter(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		params string
+		want   string
+	}{
+		{"no filters, newest first", "", "a1,a2,a3"},
+		{"text matches title and body, title first", "q=run+TESTS", "a1,a2"},
+		{"all terms must match", "q=tests+worktrees", ""},
+		{"tag", "tag=topic:testing", "a1"},
+		{"tag ignores group prefix", "tag=rig:gasboat", "a2"},
+		{"tags are ANDed", "tag=global,rig:gasboat", ""},
+		{"role", "role=crew", "a2"},
+		{"plural role", "role=polecats", "a3"},
+		{"project", "project=beads", "a3"},
+		{"fresh days", "fresh=7d", "a1,a2"},
+		{"fresh hours", "fresh=24h", "a1"},
+		{"combined", "q=tests&project=gasboat&fresh=7d", "a2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _ := url.ParseQuery(tt.params)
+			q, err := parseAdviceQuery(v, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resultIDs(q.filter(testDocs(now))); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAdviceQuery_InvalidFresh(t *testing.T) {
+	for _, fresh := range []string{"week", "0d", "-1h", "3x"} {
+		v := url.Values{"fresh": {fresh}}
+		if _, err := parseAdviceQuery(v, time.Now()); err == nil {
+			t.Errorf("fresh=%q: expected an error", fresh)
+		}
+	}
+}
+
+func TestAdviceIndex_SearchBeforeLoad(t *testing.T) {
+	srv, _ := testServer(t)
+	if _, ok := srv.index.Search(adviceQuery{}); ok {
+		t.Fatal("expected search to report not loaded")
+	}
+	// searchAdvice falls back to listing the daemon.
+	results, err := srv.searchAdvice(context.Background(), adviceQuery{Role: "crew"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resultIDs(results); got != "test-advice-2" {
+		t.Errorf("got %q, want test-advice-2", got)
+	}
+}
+
+func TestAdviceIndex_ApplyEvents(t *testing.T) {
+	srv, _ := testServer(t)
+	ctx := context.Background()
+	if err := srv.index.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	event := func(topic, id, beadType, status string) beadsapi.SSEEvent {
+		data, _ := json.Marshal(map[string]any{
+			"bead": map[string]string{"id": id, "type": beadType, "status": status},
+		})
+		return beadsapi.SSEEvent{Event: topic, Data: data}
+	}
+
+	// An update re-reads the bead: the mock returns the "Test Advice" title.
+	srv.index.apply(ctx, event("beads.bead.updated", "test-advice-1", "advice", "open"))
+	results, _ := srv.index.Search(adviceQuery{Text: "test advice"})
+	if got := resultIDs(results); got != "test-advice-1" {
+		t.Errorf("after update: got %q, want test-advice-1", got)
+	}
+
+	// Non-advice events are ignored.
+	srv.index.apply(ctx, event("beads.bead.closed", "test-advice-2", "task", "closed"))
+	if results, _ := srv.index.Search(adviceQuery{}); len(results) != 2 {
+		t.Errorf("non-advice event changed the index: %q", resultIDs(results))
+	}
+
+	srv.index.apply(ctx, event("beads.bead.closed", "test-advice-2", "advice", "closed"))
+	results, _ = srv.index.Search(adviceQuery{})
+	if got := resultIDs(results); got != "test-advice-1" {
+		t.Errorf("after close: got %q, want test-advice-1", got)
+	}
+
+	// A bead the daemon no longer has is dropped.
+	srv.index.Put(&beadsapi.BeadDetail{ID: "missing", Type: "advice", Status: "open"})
+	srv.index.apply(ctx, event("beads.bead.updated", "missing", "advice", "open"))
+	if results, _ := srv.index.Search(adviceQuery{}); len(results) != 1 {
+		t.Errorf("after 404: got %q", resultIDs(results))
+	}
+}
+
+func TestAPIListAdvice_Search(t *testing.T) {
+	w := apiRequest(t, "GET", "/api/v1/advice?role=crew&q=role", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Advice []adviceJSON `json:"advice"`
+		Total  int          `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 1 || resp.Advice[0].ID != "test-advice-2" {
+		t.Errorf("unexpected result: %+v", resp)
+	}
+
+	if w := apiRequest(t, "GET", "/api/v1/advice?fresh=soon", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid fresh: expected 400, got %d", w.Code)
+	}
+}
+
+func TestHandleAdviceList_Search(t *testing.T) {
+	srv, _ := testServer(t)
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux)
+
+	req := httptest.NewRequest("GET", "/advice?q=global", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Global Advice") || strings.Contains(body, "Role Advice") {
+		t.Error("expected only the matching advice")
+	}
+	if !strings.Contains(body, `value="global"`) {
+		t.Error("expected the search box to keep the query")
+	}
+}
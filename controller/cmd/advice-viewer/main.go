@@ -73,6 +73,9 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 
+	// Keep the advice search index warm from bead events.
+	go srv.index.Run(ctx)
+
 	go func() {
 		logger.Info("starting HTTP server", "addr", cfg.listenAddr)
 		if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
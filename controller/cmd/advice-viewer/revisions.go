@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+const (
+	// revisionsField holds an advice bead's earlier versions as a JSON array,
+	// oldest first.
+	revisionsField = "revisions"
+	// maxAdviceRevisions caps stored revisions; the oldest are dropped.
+	maxAdviceRevisions = 50
+)
+
+// adviceContent is the editable content of an advice bead: what a revision
+// preserves and a rollback restores.
+type adviceContent struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	HookCommand string   `json:"hook_command,omitempty"`
+	HookTrigger string   `json:"hook_trigger,omitempty"`
+}
+
+func contentOf(b *beadsapi.BeadDetail) adviceContent {
+	return adviceContent{
+		Title:       b.Title,
+		Description: b.Description,
+		Labels:      slices.Clone(b.Labels),
+		HookCommand: b.Fields["hook_command"],
+		HookTrigger: b.Fields["hook_trigger"],
+	}
+}
+
+func (c adviceContent) equal(o adviceContent) bool {
+	return c.Title == o.Title && c.Description == o.Description &&
+		c.HookCommand == o.HookCommand && c.HookTrigger == o.HookTrigger &&
+		sameLabels(c.Labels, o.Labels)
+}
+
+func sameLabels(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// adviceRevision is an earlier version of an advice bead.
+type adviceRevision struct {
+	Rev int `json:"rev"` // 1 for the original wording, increasing
+	adviceContent
+	ReplacedAt time.Time `json:"replaced_at"`
+	ReplacedBy string    `json:"replaced_by,omitempty"`
+}
+
+// parseRevisions returns the revisions stored on an advice bead, oldest
+// first. A missing or malformed field yields none.
+func parseRevisions(fields map[string]string) []adviceRevision {
+	var revs []adviceRevision
+	if raw := fields[revisionsField]; raw != "" {
+		_ = json.Unmarshal([]byte(raw), &revs)
+	}
+	return revs
+}
+
+// currentRev is the revision number the bead's live content will get once
+// it is replaced.
+func currentRev(revs []adviceRevision) int {
+	if len(revs) == 0 {
+		return 1
+	}
+	return revs[len(revs)-1].Rev + 1
+}
+
+// editorName identifies who made a change: the basic-auth user when auth is
+// enabled, otherwise the viewer itself.
+func editorName(r *http.Request) string {
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return user
+	}
+	return "advice-viewer"
+}
+
+// saveAdvice writes next over the bead's current content, first recording
+// the current content as a revision. An empty hook_trigger in next leaves
+// the stored trigger unchanged: the daemon rejects an empty value.
+func (s *Server) saveAdvice(ctx context.Context, bead *beadsapi.BeadDetail, next adviceContent, editor string) error {
+	cur := contentOf(bead)
+	if cur.equal(next) {
+		return nil
+	}
+
+	revs := parseRevisions(bead.Fields)
+	revs = append(revs, adviceRevision{
+		Rev:           currentRev(revs),
+		adviceContent: cur,
+		ReplacedAt:    time.Now().UTC(),
+		ReplacedBy:    editor,
+	})
+	if len(revs) > maxAdviceRevisions {
+		revs = revs[len(revs)-maxAdviceRevisions:]
+	}
+	data, err := json.Marshal(revs)
+	if err != nil {
+		return err
+	}
+	fields := map[string]string{revisionsField: string(data)}
+	if next.HookCommand != cur.HookCommand {
+		fields["hook_command"] = next.HookCommand
+	}
+	if next.HookTrigger != cur.HookTrigger && next.HookTrigger != "" {
+		fields["hook_trigger"] = next.HookTrigger
+	}
+	// Fields first, so the revision is stored before the content it
+	// preserves is overwritten.
+	if err := s.daemon.UpdateBeadFields(ctx, bead.ID, fields); err != nil {
+		return fmt.Errorf("recording revision: %w", err)
+	}
+
+	if next.Title != cur.Title || next.Description != cur.Description {
+		if err := s.daemon.UpdateBead(ctx, bead.ID, beadsapi.UpdateBeadRequest{
+			Title:       &next.Title,
+			Description: &next.Description,
+		}); err != nil {
+			return fmt.Errorf("updating title and description: %w", err)
+		}
+	}
+	if !sameLabels(next.Labels, cur.Labels) {
+		s.syncLabels(ctx, bead.ID, next.Labels)
+	}
+	return nil
+}
+
+// rollbackAdvice restores revision rev, recording the current content as a
+// new revision so the rollback can itself be undone.
+func (s *Server) rollbackAdvice(ctx context.Context, bead *beadsapi.BeadDetail, rev int, editor string) error {
+	for _, r := range parseRevisions(bead.Fields) {
+		if r.Rev == rev {
+			return s.saveAdvice(ctx, bead, r.adviceContent, editor)
+		}
+	}
+	return errRevisionNotFound
+}
+
+var errRevisionNotFound = errors.New("revision not found")
+
+// adviceVersion is one version in the history view, with its changes
+// relative to the version before it.
+type adviceVersion struct {
+	Rev        int
+	Current    bool
+	ReplacedAt time.Time
+	ReplacedBy string
+	Content    adviceContent
+
+	First         bool // the original wording; nothing to diff against
+	OldTitle      string
+	TitleChanged  bool
+	LabelsAdded   []string
+	LabelsRemoved []string
+	HookChanged   bool
+	DescChanged   bool
+	DescDiff      []diffLine
+}
+
+// adviceHistory returns all versions of a bead, newest (current) first.
+func adviceHistory(bead *beadsapi.BeadDetail) []adviceVersion {
+	revs := parseRevisions(bead.Fields)
+	versions := make([]adviceVersion, 0, len(revs)+1)
+	for _, r := range revs {
+		versions = append(versions, adviceVersion{
+			Rev:        r.Rev,
+			ReplacedAt: r.ReplacedAt,
+			ReplacedBy: r.ReplacedBy,
+			Content:    r.adviceContent,
+		})
+	}
+	versions = append(versions, adviceVersion{
+		Rev:     currentRev(revs),
+		Current: true,
+		Content: contentOf(bead),
+	})
+
+	for i := range versions {
+		v := &versions[i]
+		if i == 0 {
+			v.First = true
+			continue
+		}
+		prev := versions[i-1].Content
+		v.OldTitle = prev.Title
+		v.TitleChanged = prev.Title != v.Content.Title
+		v.LabelsAdded = missingFrom(v.Content.Labels, prev.Labels)
+		v.LabelsRemoved = missingFrom(prev.Labels, v.Content.Labels)
+		v.HookChanged = prev.HookCommand != v.Content.HookCommand || prev.HookTrigger != v.Content.HookTrigger
+		v.DescChanged = prev.Description != v.Content.Description
+		if v.DescChanged {
+			v.DescDiff = diffLines(prev.Description, v.Content.Description)
+		}
+	}
+	slices.Reverse(versions)
+	return versions
+}
+
+// missingFrom returns the labels in a that are not in b.
+func missingFrom(a, b []string) []string {
+	var out []string
+	for _, l := range a {
+		if !slices.Contains(b, l) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// diffLine is one line of a line diff. Op is "=", "+", or "-".
+type diffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// diffLines returns a line diff from a to b using a longest-common-
+// subsequence table. Advice bodies are short, so the quadratic cost is fine.
+func diffLines(a, b string) []diffLine {
+	x, y := splitLines(a), splitLines(b)
+	// lcs[i][j] is the LCS length of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out []diffLine
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			out = append(out, diffLine{"=", x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			out = append(out, diffLine{"-", x[i]})
+			i++
+		default:
+			out = append(out, diffLine{"+", y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		out = append(out, diffLine{"-", x[i]})
+	}
+	for ; j < len(y); j++ {
+		out = append(out, diffLine{"+", y[j]})
+	}
+	return out
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// handleAdviceHistory shows an advice bead's versions with diffs.
+func (s *Server) handleAdviceHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	bead, err := s.daemon.GetBead(r.Context(), id)
+	if err != nil {
+		s.logger.Error("getting advice for history", "id", id, "error", err)
+		http.Error(w, "Advice not found", http.StatusNotFound)
+		return
+	}
+	s.render(w, "advice_history.html", map[string]any{
+		"Bead":     bead,
+		"Versions": adviceHistory(bead),
+	})
+}
+
+// handleAdviceRollback restores a revision from the history view.
+func (s *Server) handleAdviceRollback(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	rev, err := strconv.Atoi(r.FormValue("rev"))
+	if err != nil {
+		http.Error(w, "Invalid revision", http.StatusBadRequest)
+		return
+	}
+	bead, err := s.daemon.GetBead(r.Context(), id)
+	if err != nil {
+		s.logger.Error("getting advice for rollback", "id", id, "error", err)
+		http.Error(w, "Advice not found", http.StatusNotFound)
+		return
+	}
+	if err := s.rollbackAdvice(r.Context(), bead, rev, editorName(r)); err != nil {
+		if errors.Is(err, errRevisionNotFound) {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+		s.logger.Error("rolling back advice", "id", id, "rev", rev, "error", err)
+		http.Error(w, "Failed to roll back advice", http.StatusInternalServerError)
+		return
+	}
+	s.refreshIndex(r.Context(), id)
+
+	http.Redirect(w, r, s.basePath+"/advice/"+id+"/history", http.StatusSeeOther)
+}
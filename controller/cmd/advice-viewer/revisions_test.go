@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// statefulDaemon serves one advice bead from memory and applies PATCH and
+// label writes to it, so edits can be read back.
+type statefulDaemon struct {
+	mu   sync.Mutex
+	bead map[string]any
+}
+
+func (d *statefulDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	base := "/v1/beads/" + d.bead["id"].(string)
+	switch {
+	case r.Method == "GET" && r.URL.Path == base:
+		_ = json.NewEncoder(w).Encode(d.bead)
+	case r.Method == "PATCH" && r.URL.Path == base:
+		var body map[string]json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for k, v := range body {
+			if k == "fields" {
+				var fields map[string]string
+				_ = json.Unmarshal(v, &fields)
+				d.bead["fields"] = fields
+				continue
+			}
+			var s string
+			_ = json.Unmarshal(v, &s)
+			d.bead[k] = s
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "POST" && r.URL.Path == base+"/labels":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		d.bead["labels"] = append(d.bead["labels"].([]string), body["label"])
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, base+"/labels/"):
+		label, _ := url.PathUnescape(strings.TrimPrefix(r.URL.Path, base+"/labels/"))
+		d.bead["labels"] = slices.DeleteFunc(d.bead["labels"].([]string), func(l string) bool { return l == label })
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+	}
+}
+
+func statefulServer(t *testing.T) (*Server, *http.ServeMux, *statefulDaemon) {
+	t.Helper()
+	d := &statefulDaemon{bead: map[string]any{
+		"id":          "adv-1",
+		"title":       "Run tests",
+		"type":        "advice",
+		"status":      "open",
+		"description": "Run make test.\nFix failures.",
+		"labels":      []string{"global"},
+		"fields":      map[string]string{"hook_command": "make test"},
+	}}
+	mock := httptest.NewServer(d)
+	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: mock.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		daemon.Close()
+		mock.Close()
+	})
+	srv := NewServer(daemon, slog.Default(), "")
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux)
+	return srv, mux, d
+}
+
+func serve(mux *http.ServeMux, method, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestDiffLines(t *testing.T) {
+	got := diffLines("a\nb\nc", "a\nB\nc\nd")
+	var ops []string
+	for _, l := range got {
+		ops = append(ops, l.Op+l.Text)
+	}
+	want := "=a,-b,+B,=c,+d"
+	if s := strings.Join(ops, ","); s != want {
+		t.Errorf("diff = %s, want %s", s, want)
+	}
+	if d := diffLines("", "x"); len(d) != 1 || d[0].Op != "+" {
+		t.Errorf("diff from empty = %+v", d)
+	}
+}
+
+func TestAdviceEditRecordsRevision(t *testing.T) {
+	_, mux, d := statefulServer(t)
+
+	form := url.Values{
+		"title":       {"Run all tests"},
+		"description": {"Run make test.\nFix every failure."},
+		"labels":      {"global, topic:testing"},
+	}
+	w := serve(mux, "POST", "/advice/adv-1/edit", "application/x-www-form-urlencoded", form.Encode())
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", w.Code)
+	}
+
+	revs := parseRevisions(d.bead["fields"].(map[string]string))
+	if len(revs) != 1 {
+		t.Fatalf("expected 1 revision, got %d", len(revs))
+	}
+	if r := revs[0]; r.Rev != 1 || r.Title != "Run tests" || r.HookCommand != "make test" || !slices.Equal(r.Labels, []string{"global"}) {
+		t.Errorf("unexpected revision: %+v", r)
+	}
+	if d.bead["title"] != "Run all tests" {
+		t.Errorf("title not updated: %v", d.bead["title"])
+	}
+	if !sameLabels(d.bead["labels"].([]string), []string{"global", "topic:testing"}) {
+		t.Errorf("labels not updated: %v", d.bead["labels"])
+	}
+
+	// An edit that changes nothing adds no revision.
+	serve(mux, "POST", "/advice/adv-1/edit", "application/x-www-form-urlencoded", form.Encode())
+	if n := len(parseRevisions(d.bead["fields"].(map[string]string))); n != 1 {
+		t.Errorf("no-op edit added a revision: %d", n)
+	}
+}
+
+func TestAdviceRollback(t *testing.T) {
+	_, mux, d := statefulServer(t)
+
+	w := serve(mux, "PATCH", "/api/v1/advice/adv-1", "application/json", `{"title":"Changed","description":"New body"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = serve(mux, "GET", "/api/v1/advice/adv-1/revisions", "", "")
+	var history struct {
+		CurrentRev int            `json:"current_rev"`
+		Revisions  []revisionJSON `json:"revisions"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatal(err)
+	}
+	if history.CurrentRev != 2 || len(history.Revisions) != 1 || history.Revisions[0].Title != "Run tests" {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if len(history.Revisions[0].Diff) == 0 {
+		t.Error("expected a description diff")
+	}
+
+	w = serve(mux, "POST", "/advice/adv-1/rollback", "application/x-www-form-urlencoded", "rev=1")
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("rollback: expected 303, got %d", w.Code)
+	}
+	if d.bead["title"] != "Run tests" || d.bead["description"] != "Run make test.\nFix failures." {
+		t.Errorf("rollback did not restore content: %v", d.bead)
+	}
+	revs := parseRevisions(d.bead["fields"].(map[string]string))
+	if len(revs) != 2 || revs[1].Rev != 2 || revs[1].Title != "Changed" {
+		t.Errorf("rollback should keep the replaced version: %+v", revs)
+	}
+
+	if w := serve(mux, "POST", "/api/v1/advice/adv-1/rollback", "application/json", `{"rev":9}`); w.Code != http.StatusNotFound {
+		t.Errorf("unknown revision: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleAdviceHistory(t *testing.T) {
+	_, mux, _ := statefulServer(t)
+	serve(mux, "PATCH", "/api/v1/advice/adv-1", "application/json", `{"description":"Run make test.\nFix every failure."}`)
+
+	w := serve(mux, "GET", "/advice/adv-1/history", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{"Revision 2", "Revision 1", "Fix failures.", "Fix every failure.", "diff-add", "diff-del", "Restore this version"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("history page missing %q", want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"html/template"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"gasboat/controller/internal/advice"
 	"gasboat/controller/internal/beadsapi"
@@ -19,18 +21,20 @@ type Server struct {
 	logger   *slog.Logger
 	pages    map[string]*template.Template
 	basePath string // external URL prefix (e.g. "/advice"), empty for root
+	index    *adviceIndex
 }
 
 // NewServer creates an advice viewer server.
 func NewServer(daemon *beadsapi.Client, logger *slog.Logger, basePath string) *Server {
 	funcMap := template.FuncMap{
-		"join": strings.Join,
+		"join":          strings.Join,
+		"effectiveness": advice.EffectivenessOf,
 	}
 	// Parse each page template together with the layout so {{define "content"}}
 	// blocks don't collide across pages.
 	pageNames := []string{
 		"index.html", "agent.html", "advice_list.html", "advice_show.html",
-		"advice_edit.html", "advice_new.html", "generate.html",
+		"advice_edit.html", "advice_new.html", "advice_history.html", "generate.html",
 	}
 	pages := make(map[string]*template.Template, len(pageNames))
 	for _, name := range pageNames {
@@ -43,6 +47,7 @@ func NewServer(daemon *beadsapi.Client, logger *slog.Logger, basePath string) *S
 		logger:   logger,
 		pages:    pages,
 		basePath: basePath,
+		index:    newAdviceIndex(daemon, logger),
 	}
 }
 
@@ -56,8 +61,11 @@ func (s *Server) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /advice/{id}/edit", s.handleAdviceEdit)
 	mux.HandleFunc("POST /advice/{id}/edit", s.handleAdviceUpdate)
 	mux.HandleFunc("GET /advice/{id}", s.handleAdviceShow)
+	mux.HandleFunc("GET /advice/{id}/history", s.handleAdviceHistory)
+	mux.HandleFunc("POST /advice/{id}/rollback", s.handleAdviceRollback)
 	mux.HandleFunc("GET /generate", s.handleGenerateForm)
 	mux.HandleFunc("POST /generate", s.handleGenerateDispatch)
+	s.registerAPIRoutes(mux)
 }
 
 func (s *Server) render(w http.ResponseWriter, name string, data any) {
@@ -152,17 +160,26 @@ func (s *Server) handleAgent(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleAdviceList shows all advice beads.
+// handleAdviceList shows advice beads, filtered by the search form.
 func (s *Server) handleAdviceList(w http.ResponseWriter, r *http.Request) {
-	allAdvice, err := advice.ListAllAdvice(r.Context(), s.daemon)
+	q, qerr := parseAdviceQuery(r.URL.Query(), time.Now())
+	if qerr != nil {
+		q.Since = time.Time{}
+	}
+	results, err := s.searchAdvice(r.Context(), q)
 	if err != nil {
 		s.logger.Error("listing advice", "error", err)
 		http.Error(w, "Failed to list advice", http.StatusInternalServerError)
 		return
 	}
-	s.render(w, "advice_list.html", map[string]any{
-		"Advice": allAdvice,
-	})
+	data := map[string]any{
+		"Advice": results,
+		"Query":  q,
+	}
+	if qerr != nil {
+		data["Error"] = qerr.Error()
+	}
+	s.render(w, "advice_list.html", data)
 }
 
 // handleAdviceShow shows a single advice bead.
@@ -175,7 +192,9 @@ func (s *Server) handleAdviceShow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	s.render(w, "advice_show.html", map[string]any{
-		"Bead": bead,
+		"Bead":          bead,
+		"Effectiveness": advice.EffectivenessOf(bead),
+		"Feedback":      advice.ParseFeedback(bead.Fields),
 	})
 }
 
@@ -193,7 +212,8 @@ func (s *Server) handleAdviceEdit(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// handleAdviceUpdate processes the edit form submission.
+// handleAdviceUpdate processes the edit form submission. The previous
+// content is kept as a revision.
 func (s *Server) handleAdviceUpdate(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if err := r.ParseForm(); err != nil {
@@ -201,62 +221,31 @@ func (s *Server) handleAdviceUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	title := r.FormValue("title")
-	description := r.FormValue("description")
-	labelsStr := r.FormValue("labels")
-	hookCommand := r.FormValue("hook_command")
-	hookTrigger := r.FormValue("hook_trigger")
-
-	// Update title and description.
-	if err := s.daemon.UpdateBead(r.Context(), id, beadsapi.UpdateBeadRequest{
-		Title:       &title,
-		Description: &description,
-	}); err != nil {
-		s.logger.Error("updating advice", "id", id, "error", err)
-		http.Error(w, "Failed to update advice", http.StatusInternalServerError)
+	bead, err := s.daemon.GetBead(r.Context(), id)
+	if err != nil {
+		s.logger.Error("getting advice for update", "id", id, "error", err)
+		http.Error(w, "Advice not found", http.StatusNotFound)
 		return
 	}
 
-	// Update fields — only send non-empty values; the API rejects empty
-	// hook_trigger (must be one of the allowed trigger names).
-	fields := make(map[string]string)
-	if hookCommand != "" {
-		fields["hook_command"] = hookCommand
+	next := contentOf(bead)
+	next.Title = r.FormValue("title")
+	next.Description = r.FormValue("description")
+	next.Labels = parseLabelsString(r.FormValue("labels"))
+	// Empty hook fields leave the stored values unchanged.
+	if v := r.FormValue("hook_command"); v != "" {
+		next.HookCommand = v
 	}
-	if hookTrigger != "" {
-		fields["hook_trigger"] = hookTrigger
-	}
-	if len(fields) > 0 {
-		if err := s.daemon.UpdateBeadFields(r.Context(), id, fields); err != nil {
-			s.logger.Error("updating advice fields", "id", id, "error", err)
-			http.Error(w, "Failed to update advice fields", http.StatusInternalServerError)
-			return
-		}
+	if v := r.FormValue("hook_trigger"); v != "" {
+		next.HookTrigger = v
 	}
 
-	// Sync labels: get current labels, compute diff, add/remove.
-	bead, err := s.daemon.GetBead(r.Context(), id)
-	if err == nil {
-		newLabels := parseLabelsString(labelsStr)
-		oldSet := make(map[string]bool)
-		for _, l := range bead.Labels {
-			oldSet[l] = true
-		}
-		newSet := make(map[string]bool)
-		for _, l := range newLabels {
-			newSet[l] = true
-		}
-		for _, l := range newLabels {
-			if !oldSet[l] {
-				_ = s.daemon.AddLabel(r.Context(), id, l)
-			}
-		}
-		for _, l := range bead.Labels {
-			if !newSet[l] {
-				_ = s.daemon.RemoveLabel(r.Context(), id, l)
-			}
-		}
+	if err := s.saveAdvice(r.Context(), bead, next, editorName(r)); err != nil {
+		s.logger.Error("updating advice", "id", id, "error", err)
+		http.Error(w, "Failed to update advice", http.StatusInternalServerError)
+		return
 	}
+	s.refreshIndex(r.Context(), id)
 
 	http.Redirect(w, r, s.basePath+"/advice/"+id, http.StatusSeeOther)
 }
@@ -282,9 +271,69 @@ func (s *Server) handleAdviceCreate(w http.ResponseWriter, r *http.Request) {
 	role := r.FormValue("role")
 	agent := r.FormValue("agent")
 
-	labels := parseLabelsString(labelsStr)
+	labels := targetingLabels(parseLabelsString(labelsStr), rig, role, agent)
 
-	// Add targeting labels.
+	id, err := s.createAdvice(r.Context(), title, description, labels, hookFields(hookCommand, hookTrigger))
+	if err != nil {
+		s.logger.Error("creating advice", "error", err)
+		http.Error(w, "Failed to create advice", http.StatusInternalServerError)
+		return
+	}
+	s.refreshIndex(r.Context(), id)
+
+	http.Redirect(w, r, s.basePath+"/advice/"+id, http.StatusSeeOther)
+}
+
+// handleGenerateForm shows the generation dispatch form.
+func (s *Server) handleGenerateForm(w http.ResponseWriter, r *http.Request) {
+	agents, err := s.daemon.ListAgentBeads(r.Context())
+	if err != nil {
+		s.logger.Error("listing agents for generate", "error", err)
+	}
+	s.render(w, "generate.html", map[string]any{
+		"Agents": agents,
+	})
+}
+
+// handleGenerateDispatch creates a task bead and spawns an agent to work on it.
+func (s *Server) handleGenerateDispatch(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	topic := r.FormValue("topic")
+	project := r.FormValue("project")
+
+	if topic == "" {
+		http.Error(w, "Topic is required", http.StatusBadRequest)
+		return
+	}
+	if project == "" {
+		http.Error(w, "Project is required", http.StatusBadRequest)
+		return
+	}
+
+	d, err := s.dispatchGeneration(r.Context(), topic, project)
+	if d.TaskID == "" {
+		http.Error(w, "Failed to create generation task", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		s.render(w, "generate.html", map[string]any{
+			"Success": fmt.Sprintf("Created task %s but failed to spawn agent: %v", d.TaskID, err),
+		})
+		return
+	}
+
+	s.render(w, "generate.html", map[string]any{
+		"Success": fmt.Sprintf("Created task %s and spawned agent %s (%s)", d.TaskID, d.AgentName, d.AgentID),
+	})
+}
+
+// targetingLabels adds rig/role/agent targeting to labels. Several targets
+// are combined into one AND group (g0:); with none, the advice is global.
+func targetingLabels(labels []string, rig, role, agent string) []string {
 	var targeting []string
 	if rig != "" {
 		targeting = append(targeting, "rig:"+rig)
@@ -306,22 +355,29 @@ func (s *Server) handleAdviceCreate(w http.ResponseWriter, r *http.Request) {
 	if !advice.HasTargetingLabel(labels) {
 		labels = append(labels, "global")
 	}
+	return labels
+}
 
-	fields := make(map[string]any)
+// hookFields returns the non-empty hook fields.
+func hookFields(hookCommand, hookTrigger string) map[string]string {
+	fields := make(map[string]string)
 	if hookCommand != "" {
 		fields["hook_command"] = hookCommand
 	}
 	if hookTrigger != "" {
 		fields["hook_trigger"] = hookTrigger
 	}
+	return fields
+}
 
+// createAdvice creates an advice bead and returns its ID.
+func (s *Server) createAdvice(ctx context.Context, title, description string, labels []string, fields map[string]string) (string, error) {
 	var fieldsJSON json.RawMessage
 	if len(fields) > 0 {
 		b, _ := json.Marshal(fields)
 		fieldsJSON = b
 	}
-
-	id, err := s.daemon.CreateBead(r.Context(), beadsapi.CreateBeadRequest{
+	return s.daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
 		Title:       title,
 		Description: description,
 		Type:        "advice",
@@ -329,45 +385,46 @@ func (s *Server) handleAdviceCreate(w http.ResponseWriter, r *http.Request) {
 		CreatedBy:   "advice-viewer",
 		Fields:      fieldsJSON,
 	})
-	if err != nil {
-		s.logger.Error("creating advice", "error", err)
-		http.Error(w, "Failed to create advice", http.StatusInternalServerError)
-		return
-	}
-
-	http.Redirect(w, r, s.basePath+"/advice/"+id, http.StatusSeeOther)
 }
 
-// handleGenerateForm shows the generation dispatch form.
-func (s *Server) handleGenerateForm(w http.ResponseWriter, r *http.Request) {
-	agents, err := s.daemon.ListAgentBeads(r.Context())
+// syncLabels makes the bead's labels equal to labels. Failures on
+// individual labels are ignored.
+func (s *Server) syncLabels(ctx context.Context, id string, labels []string) {
+	bead, err := s.daemon.GetBead(ctx, id)
 	if err != nil {
-		s.logger.Error("listing agents for generate", "error", err)
-	}
-	s.render(w, "generate.html", map[string]any{
-		"Agents": agents,
-	})
-}
-
-// handleGenerateDispatch creates a task bead and spawns an agent to work on it.
-func (s *Server) handleGenerateDispatch(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseForm(); err != nil {
-		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
-
-	topic := r.FormValue("topic")
-	project := r.FormValue("project")
-
-	if topic == "" {
-		http.Error(w, "Topic is required", http.StatusBadRequest)
-		return
+	oldSet := make(map[string]bool)
+	for _, l := range bead.Labels {
+		oldSet[l] = true
 	}
-	if project == "" {
-		http.Error(w, "Project is required", http.StatusBadRequest)
-		return
+	newSet := make(map[string]bool)
+	for _, l := range labels {
+		newSet[l] = true
 	}
+	for _, l := range labels {
+		if !oldSet[l] {
+			_ = s.daemon.AddLabel(ctx, id, l)
+		}
+	}
+	for _, l := range bead.Labels {
+		if !newSet[l] {
+			_ = s.daemon.RemoveLabel(ctx, id, l)
+		}
+	}
+}
+
+// generationDispatch is the result of dispatching advice generation.
+type generationDispatch struct {
+	TaskID    string `json:"task_id"`
+	AgentName string `json:"agent_name,omitempty"`
+	AgentID   string `json:"agent_id,omitempty"`
+}
 
+// dispatchGeneration creates a generation task bead and spawns an agent to
+// work on it. On a spawn failure the returned dispatch still carries the
+// task ID.
+func (s *Server) dispatchGeneration(ctx context.Context, topic, project string) (generationDispatch, error) {
 	labels := []string{"advice-generation", "project:" + project}
 
 	title := fmt.Sprintf("Generate advice: %s", topic)
@@ -375,7 +432,7 @@ func (s *Server) handleGenerateDispatch(w http.ResponseWriter, r *http.Request)
 		title = title[:200]
 	}
 
-	taskID, err := s.daemon.CreateBead(r.Context(), beadsapi.CreateBeadRequest{
+	taskID, err := s.daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
 		Title:       title,
 		Description: topic,
 		Type:        "task",
@@ -384,28 +441,22 @@ func (s *Server) handleGenerateDispatch(w http.ResponseWriter, r *http.Request)
 	})
 	if err != nil {
 		s.logger.Error("creating generation task", "error", err)
-		http.Error(w, "Failed to create generation task", http.StatusInternalServerError)
-		return
+		return generationDispatch{}, err
 	}
+	d := generationDispatch{TaskID: taskID}
 
 	// Derive a unique agent name from the task bead ID.
-	agentName := "advgen"
+	d.AgentName = "advgen"
 	if len(taskID) > 3 {
-		agentName = "advgen-" + taskID[3:] // strip "kd-" prefix
+		d.AgentName = "advgen-" + taskID[3:] // strip "kd-" prefix
 	}
 
-	agentID, err := s.daemon.SpawnAgent(r.Context(), agentName, project, taskID, "crew")
+	d.AgentID, err = s.daemon.SpawnAgent(ctx, d.AgentName, project, taskID, "crew")
 	if err != nil {
 		s.logger.Error("spawning agent for generation", "task", taskID, "error", err)
-		s.render(w, "generate.html", map[string]any{
-			"Success": fmt.Sprintf("Created task %s but failed to spawn agent: %v", taskID, err),
-		})
-		return
+		return generationDispatch{TaskID: taskID}, err
 	}
-
-	s.render(w, "generate.html", map[string]any{
-		"Success": fmt.Sprintf("Created task %s and spawned agent %s (%s)", taskID, agentName, agentID),
-	})
+	return d, nil
 }
 
 // parseLabelsString splits a comma-separated label string into a slice.
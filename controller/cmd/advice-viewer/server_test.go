@@ -37,6 +37,18 @@ func mockDaemon(t *testing.T) *httptest.Server {
 				"description": "Test description",
 				"fields":      map[string]string{"hook_command": "echo hi"},
 			})
+		case r.Method == "GET" && r.URL.Path == "/v1/beads/new-bead-id":
+			writeJSON(t, w, map[string]any{
+				"id":     "new-bead-id",
+				"title":  "New Advice",
+				"type":   "advice",
+				"status": "open",
+				"labels": []string{"global"},
+				"fields": map[string]string{},
+			})
+		case r.Method == "GET" && r.URL.Path == "/v1/beads/missing":
+			w.WriteHeader(http.StatusNotFound)
+			writeJSON(t, w, map[string]string{"error": "bead not found"})
 		case r.Method == "GET" && r.URL.Path == "/v1/beads":
 			q := r.URL.Query()
 			beadType := q.Get("type")
@@ -59,7 +71,9 @@ func mockDaemon(t *testing.T) *httptest.Server {
 							"status":      "open",
 							"labels":      []string{"role:crew"},
 							"description": "Role advice text",
-							"fields":      map[string]string{},
+							"fields": map[string]string{
+								"feedback": `{"agent-1":{"vote":1,"applied":2,"at":"2026-01-02T03:04:05Z"}}`,
+							},
 						},
 					},
 					"total": 2,
@@ -156,6 +170,9 @@ func TestHandleAdviceList(t *testing.T) {
 	if !strings.Contains(body, "Global Advice") {
 		t.Error("expected advice title in response")
 	}
+	if !strings.Contains(body, "0.75") {
+		t.Error("expected effectiveness score for rated advice")
+	}
 }
 
 func TestHandleAdviceShow(t *testing.T) {
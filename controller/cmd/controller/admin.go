@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/reconciler"
+	"gasboat/controller/internal/subscriber"
+)
+
+const (
+	// adminEnqueueTimeout bounds how long a forced reconcile waits for the
+	// sync loop to accept it. Only the leader runs the loop.
+	adminEnqueueTimeout = 5 * time.Second
+	// adminReconcileTimeout bounds how long a forced reconcile waits for
+	// the pass to finish.
+	adminReconcileTimeout = 2 * time.Minute
+)
+
+// syncTrigger asks the periodic sync loop to run a pass now. When the sent
+// reply channel is non-nil it receives the reconcile result; it must be
+// buffered so the loop never blocks on a caller that gave up.
+type syncTrigger chan chan error
+
+// nudge requests a sync pass without waiting for it. If a pass is already
+// queued, or no loop is running, the request is dropped.
+func (t syncTrigger) nudge() {
+	select {
+	case t <- nil:
+	default:
+	}
+}
+
+// adminProjectStore reads and updates project beads.
+type adminProjectStore interface {
+	ListProjectBeads(ctx context.Context) (map[string]beadsapi.ProjectInfo, error)
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// stateInspector reports the desired-vs-actual pod diff and reconcile
+// plans (reconciler.Reconciler).
+type stateInspector interface {
+	Diff(ctx context.Context) (*reconciler.StateDiff, error)
+	Plan(ctx context.Context) (*reconciler.ReconcilePlan, error)
+	LastPlan() *reconciler.ReconcilePlan
+	History() *reconciler.History
+}
+
+// eventRedriver queues dead-lettered events again (eventQueue).
+type eventRedriver interface {
+	redrive(ctx context.Context, event subscriber.Event) error
+}
+
+// adminHandler serves the /admin/ API for manual operations that would
+// otherwise mean deleting pods by hand:
+//
+//	POST   /admin/reconcile                           run a sync pass now and wait for it
+//	POST   /admin/agents/{agent}/restart              delete the agent's pod so it is recreated
+//	POST   /admin/projects/{project}/pause            stop reconciling the project's pods
+//	POST   /admin/projects/{project}/resume           resume reconciling the project's pods
+//	POST   /admin/projects/{project}/budget/reset     discount today's spend so far
+//	POST   /admin/projects/{project}/budget/override  spawn past the hard budget ?until=
+//	GET    /admin/diff                                desired-vs-actual pod diff
+//	GET    /admin/plan                                plan of the latest reconcile pass
+//	GET    /admin/plan/next                           plan the next pass would apply (dry run)
+//	GET    /admin/history                             past passes that changed pods or failed
+//	GET    /admin/history/drift                       pod operations per agent
+//	GET    /admin/dead-letters                        events that failed every attempt
+//	GET    /admin/dead-letters/{id}                   one dead-lettered event
+//	POST   /admin/dead-letters/{id}/redrive           handle the event again
+//	POST   /admin/dead-letters/redrive                handle every dead-lettered event again
+//	DELETE /admin/dead-letters/{id}                   discard the event
+//
+// Re-driven events are queued like new ones and dead-lettered again if they
+// still fail; only the leader can re-drive. /admin/dead-letters takes
+// ?agent= to show only one agent's events.
+//
+// The history endpoints take ?since= as a duration back from now ("24h") or
+// an RFC 3339 time, and /admin/history takes ?agent= (agent name, pod name
+// or bead ID) to show only the passes touching that agent.
+//
+// Requests must carry "Authorization: Bearer <token>".
+func adminHandler(client kubernetes.Interface, namespace, token string, projects adminProjectStore, rec stateInspector, trigger syncTrigger, dead *deadLetters, events eventRedriver, logger *slog.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /admin/reconcile", func(w http.ResponseWriter, r *http.Request) {
+		reply := make(chan error, 1)
+		select {
+		case trigger <- reply:
+		case <-time.After(adminEnqueueTimeout):
+			http.Error(w, "sync loop is not running on this replica (not the leader?)", http.StatusServiceUnavailable)
+			return
+		case <-r.Context().Done():
+			return
+		}
+		logger.Info("admin: forced reconcile requested")
+		select {
+		case err := <-reply:
+			if err != nil {
+				http.Error(w, fmt.Sprintf("reconcile failed: %v", err), http.StatusBadGateway)
+				return
+			}
+		case <-time.After(adminReconcileTimeout):
+			http.Error(w, "reconcile did not finish in time", http.StatusGatewayTimeout)
+			return
+		case <-r.Context().Done():
+			return
+		}
+		writeAdminJSON(w, map[string]string{"status": "reconciled"})
+	})
+
+	mux.HandleFunc("POST /admin/agents/{agent}/restart", func(w http.ResponseWriter, r *http.Request) {
+		agent := r.PathValue("agent")
+		if client == nil {
+			http.Error(w, "agent restart needs the kubernetes agent backend", http.StatusNotImplemented)
+			return
+		}
+		pod, err := newestAgentPod(r.Context(), client, namespace, agent)
+		if errors.Is(err, errAgentPodNotFound) {
+			http.Error(w, fmt.Sprintf("no pod found for agent %q", agent), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if err := client.CoreV1().Pods(pod.Namespace).Delete(r.Context(), pod.Name, metav1.DeleteOptions{}); err != nil {
+			http.Error(w, fmt.Sprintf("deleting pod %s: %v", pod.Name, err), http.StatusBadGateway)
+			return
+		}
+		logger.Info("admin: restarted agent pod", "agent", agent, "pod", pod.Name)
+		// The reconciler recreates the pod; don't wait for the next tick.
+		trigger.nudge()
+		writeAdminJSON(w, map[string]string{"agent": agent, "pod": pod.Name, "status": "restarting"})
+	})
+
+	// project looks up the {project} bead, writing the error if it fails.
+	project := func(w http.ResponseWriter, r *http.Request) (beadsapi.ProjectInfo, bool) {
+		name := r.PathValue("project")
+		all, err := projects.ListProjectBeads(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return beadsapi.ProjectInfo{}, false
+		}
+		info, ok := all[name]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown project %q", name), http.StatusNotFound)
+			return beadsapi.ProjectInfo{}, false
+		}
+		return info, true
+	}
+
+	setPaused := func(paused bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			info, ok := project(w, r)
+			if !ok {
+				return
+			}
+			name := info.Name
+			value := ""
+			if paused {
+				value = "true"
+			}
+			if err := projects.UpdateBeadFields(r.Context(), info.ID, map[string]string{beadsapi.ReconcilePausedField: value}); err != nil {
+				http.Error(w, fmt.Sprintf("updating project %s: %v", name, err), http.StatusBadGateway)
+				return
+			}
+			logger.Info("admin: set project reconcile pause", "project", name, "paused", paused)
+			// The pause is read from the project bead on the next pass.
+			trigger.nudge()
+			writeAdminJSON(w, map[string]any{"project": name, "reconcile_paused": paused})
+		}
+	}
+	mux.HandleFunc("POST /admin/projects/{project}/pause", setPaused(true))
+	mux.HandleFunc("POST /admin/projects/{project}/resume", setPaused(false))
+
+	mux.HandleFunc("POST /admin/projects/{project}/budget/reset", func(w http.ResponseWriter, r *http.Request) {
+		info, ok := project(w, r)
+		if !ok {
+			return
+		}
+		day := beadsapi.SpendDay(time.Now())
+		reset := beadsapi.BudgetReset{Day: day, USD: info.BudgetResetToday(day)}
+		if info.Budget != nil && info.Budget.Day == day {
+			reset.USD += info.Budget.SpentUSD
+		}
+		data, _ := json.Marshal(reset)
+		if err := projects.UpdateBeadFields(r.Context(), info.ID, map[string]string{beadsapi.BudgetResetField: string(data)}); err != nil {
+			http.Error(w, fmt.Sprintf("updating project %s: %v", info.Name, err), http.StatusBadGateway)
+			return
+		}
+		logger.Info("admin: reset project budget", "project", info.Name, "discounted_usd", reset.USD)
+		trigger.nudge()
+		writeAdminJSON(w, map[string]any{"project": info.Name, "budget_reset": reset})
+	})
+
+	mux.HandleFunc("POST /admin/projects/{project}/budget/override", func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		until, err := parseUntil(r.URL.Query().Get("until"), now)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		info, ok := project(w, r)
+		if !ok {
+			return
+		}
+		value := until.UTC().Format(time.RFC3339)
+		if err := projects.UpdateBeadFields(r.Context(), info.ID, map[string]string{beadsapi.BudgetOverrideField: value}); err != nil {
+			http.Error(w, fmt.Sprintf("updating project %s: %v", info.Name, err), http.StatusBadGateway)
+			return
+		}
+		logger.Info("admin: override project hard budget", "project", info.Name, "until", value)
+		trigger.nudge()
+		writeAdminJSON(w, map[string]any{"project": info.Name, "budget_override_until": value})
+	})
+
+	mux.HandleFunc("GET /admin/diff", func(w http.ResponseWriter, r *http.Request) {
+		diff, err := rec.Diff(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeAdminJSON(w, struct {
+			InSync bool `json:"in_sync"`
+			*reconciler.StateDiff
+		}{diff.InSync(), diff})
+	})
+
+	mux.HandleFunc("GET /admin/plan", func(w http.ResponseWriter, r *http.Request) {
+		plan := rec.LastPlan()
+		if plan == nil {
+			http.Error(w, "no reconcile pass has run on this replica", http.StatusNotFound)
+			return
+		}
+		writeAdminJSON(w, plan)
+	})
+
+	mux.HandleFunc("GET /admin/plan/next", func(w http.ResponseWriter, r *http.Request) {
+		plan, err := rec.Plan(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeAdminJSON(w, plan)
+	})
+
+	history := func(w http.ResponseWriter, r *http.Request) (*reconciler.History, time.Time, bool) {
+		h := rec.History()
+		if h == nil {
+			http.Error(w, "reconcile history is disabled (RECONCILE_HISTORY_SIZE=0)", http.StatusNotFound)
+			return nil, time.Time{}, false
+		}
+		since, err := parseSince(r.URL.Query().Get("since"), time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, time.Time{}, false
+		}
+		return h, since, true
+	}
+
+	mux.HandleFunc("GET /admin/history", func(w http.ResponseWriter, r *http.Request) {
+		h, since, ok := history(w, r)
+		if !ok {
+			return
+		}
+		passes, err := h.Entries(r.Context(), reconciler.HistoryFilter{Since: since, Agent: r.URL.Query().Get("agent")})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeAdminJSON(w, map[string]any{"passes": passes})
+	})
+
+	mux.HandleFunc("GET /admin/history/drift", func(w http.ResponseWriter, r *http.Request) {
+		h, since, ok := history(w, r)
+		if !ok {
+			return
+		}
+		agents, err := h.Drift(r.Context(), since)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		writeAdminJSON(w, map[string]any{"since": since, "agents": agents})
+	})
+
+	deadLettersOn := func(w http.ResponseWriter) bool {
+		if dead == nil {
+			http.Error(w, "dead-lettering is disabled (DEAD_LETTER_SIZE=0)", http.StatusNotFound)
+			return false
+		}
+		return true
+	}
+	// letter looks up the {id} dead letter, writing the error if it fails.
+	letter := func(w http.ResponseWriter, r *http.Request) (deadLetter, bool) {
+		if !deadLettersOn(w) {
+			return deadLetter{}, false
+		}
+		id := r.PathValue("id")
+		l, ok, err := dead.get(r.Context(), id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return deadLetter{}, false
+		}
+		if !ok {
+			http.Error(w, fmt.Sprintf("no dead letter %q", id), http.StatusNotFound)
+			return deadLetter{}, false
+		}
+		return l, true
+	}
+	redriveStatus := func(err error) int {
+		if errors.Is(err, errEventsNotRunning) {
+			return http.StatusServiceUnavailable
+		}
+		return http.StatusBadGateway
+	}
+
+	mux.HandleFunc("GET /admin/dead-letters", func(w http.ResponseWriter, r *http.Request) {
+		if !deadLettersOn(w) {
+			return
+		}
+		letters, err := dead.list(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if agent := r.URL.Query().Get("agent"); agent != "" {
+			letters = slices.DeleteFunc(letters, func(l deadLetter) bool {
+				return l.Event.AgentName != agent && eventBeadID(l.Event) != agent
+			})
+		}
+		if letters == nil {
+			letters = []deadLetter{}
+		}
+		writeAdminJSON(w, map[string]any{"dead_letters": letters})
+	})
+
+	mux.HandleFunc("GET /admin/dead-letters/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if l, ok := letter(w, r); ok {
+			writeAdminJSON(w, l)
+		}
+	})
+
+	mux.HandleFunc("POST /admin/dead-letters/{id}/redrive", func(w http.ResponseWriter, r *http.Request) {
+		l, ok := letter(w, r)
+		if !ok {
+			return
+		}
+		if err := events.redrive(r.Context(), l.Event); err != nil {
+			http.Error(w, fmt.Sprintf("re-driving %s: %v", l.ID, err), redriveStatus(err))
+			return
+		}
+		if _, err := dead.remove(r.Context(), l.ID); err != nil {
+			logger.Warn("admin: failed to remove re-driven dead letter", "id", l.ID, "error", err)
+		}
+		logger.Info("admin: re-drove dead-lettered event", "id", l.ID, "type", l.Event.Type, "bead", eventBeadID(l.Event))
+		writeAdminJSON(w, map[string]string{"id": l.ID, "status": "redriven"})
+	})
+
+	mux.HandleFunc("POST /admin/dead-letters/redrive", func(w http.ResponseWriter, r *http.Request) {
+		if !deadLettersOn(w) {
+			return
+		}
+		letters, err := dead.list(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		var redriven []string
+		var failed error
+		for _, l := range letters {
+			if err := events.redrive(r.Context(), l.Event); err != nil {
+				failed = fmt.Errorf("re-driving %s: %w", l.ID, err)
+				break
+			}
+			redriven = append(redriven, l.ID)
+		}
+		if _, err := dead.remove(r.Context(), redriven...); err != nil {
+			logger.Warn("admin: failed to remove re-driven dead letters", "error", err)
+		}
+		logger.Info("admin: re-drove dead-lettered events", "redriven", len(redriven), "remaining", len(letters)-len(redriven))
+		if failed != nil && len(redriven) == 0 {
+			http.Error(w, failed.Error(), redriveStatus(failed))
+			return
+		}
+		resp := map[string]any{"redriven": len(redriven), "remaining": len(letters) - len(redriven)}
+		if failed != nil {
+			resp["error"] = failed.Error()
+		}
+		writeAdminJSON(w, resp)
+	})
+
+	mux.HandleFunc("DELETE /admin/dead-letters/{id}", func(w http.ResponseWriter, r *http.Request) {
+		l, ok := letter(w, r)
+		if !ok {
+			return
+		}
+		if _, err := dead.remove(r.Context(), l.ID); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		logger.Info("admin: discarded dead-lettered event", "id", l.ID, "type", l.Event.Type, "bead", eventBeadID(l.Event))
+		writeAdminJSON(w, map[string]string{"id": l.ID, "status": "discarded"})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// parseSince parses a ?since= value: a duration back from now or an RFC 3339
+// time. Empty means the beginning of the history.
+func parseSince(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q: want a duration like 24h or an RFC 3339 time", v)
+	}
+	return t, nil
+}
+
+// parseUntil parses an ?until= value: a duration ahead of now or an RFC 3339
+// time. Empty means the end of the current UTC day.
+func parseUntil(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		day, _ := time.Parse(time.DateOnly, beadsapi.SpendDay(now))
+		return day.AddDate(0, 0, 1), nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid until %q: want a duration like 4h or an RFC 3339 time", v)
+	}
+	return t, nil
+}
+
+func writeAdminJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/reconciler"
+)
+
+type fakeProjectStore struct {
+	projects map[string]beadsapi.ProjectInfo
+	updates  map[string]map[string]string // bead ID → fields
+}
+
+func (f *fakeProjectStore) ListProjectBeads(_ context.Context) (map[string]beadsapi.ProjectInfo, error) {
+	return f.projects, nil
+}
+
+func (f *fakeProjectStore) UpdateBeadFields(_ context.Context, beadID string, fields map[string]string) error {
+	f.updates[beadID] = fields
+	return nil
+}
+
+type fakeDiffer struct {
+	diff *reconciler.StateDiff
+	plan *reconciler.ReconcilePlan
+	last *reconciler.ReconcilePlan
+	hist *reconciler.History
+	err  error
+}
+
+func (f *fakeDiffer) Diff(_ context.Context) (*reconciler.StateDiff, error) {
+	return f.diff, f.err
+}
+
+func (f *fakeDiffer) Plan(_ context.Context) (*reconciler.ReconcilePlan, error) {
+	return f.plan, f.err
+}
+
+func (f *fakeDiffer) LastPlan() *reconciler.ReconcilePlan {
+	return f.last
+}
+
+func (f *fakeDiffer) History() *reconciler.History {
+	return f.hist
+}
+
+func adminRequest(method, path, token string) *http.Request {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func newAdminTestHandler(client kubernetes.Interface, store *fakeProjectStore, differ stateInspector, trigger syncTrigger) http.Handler {
+	return adminHandler(client, "gasboat", "s3cret", store, differ, trigger, nil, nil, slog.Default())
+}
+
+func TestAdminHandler_RequiresToken(t *testing.T) {
+	h := newAdminTestHandler(fake.NewSimpleClientset(), &fakeProjectStore{}, &fakeDiffer{}, make(syncTrigger, 1))
+	for _, token := range []string{"", "wrong"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/diff", token))
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, rec.Code)
+		}
+	}
+}
+
+func TestAdminHandler_Reconcile(t *testing.T) {
+	trigger := make(syncTrigger, 1)
+	h := newAdminTestHandler(fake.NewSimpleClientset(), &fakeProjectStore{}, &fakeDiffer{}, trigger)
+
+	// Stand in for runPeriodicSync: fail the first pass, succeed the second.
+	go func() {
+		(<-trigger) <- errors.New("daemon unreachable")
+		(<-trigger) <- nil
+	}()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/reconcile", "s3cret"))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("failed pass: expected 502, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/reconcile", "s3cret"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAdminHandler_RestartDeletesNewestPod(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		agentPod("crew-old", "my-bot", now.Add(-time.Hour)),
+		agentPod("crew-new", "my-bot", now),
+	)
+	trigger := make(syncTrigger, 1)
+	h := newAdminTestHandler(client, &fakeProjectStore{}, &fakeDiffer{}, trigger)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/agents/my-bot/restart", "s3cret"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, err := client.CoreV1().Pods("gasboat").Get(context.Background(), "crew-new", metav1.GetOptions{}); err == nil {
+		t.Error("expected crew-new to be deleted")
+	}
+	if _, err := client.CoreV1().Pods("gasboat").Get(context.Background(), "crew-old", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected crew-old to remain: %v", err)
+	}
+	if len(trigger) != 1 {
+		t.Error("expected a sync pass to be requested")
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/agents/ghost/restart", "s3cret"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown agent: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler_PauseResumeProject(t *testing.T) {
+	store := &fakeProjectStore{
+		projects: map[string]beadsapi.ProjectInfo{"gasboat": {ID: "kd-proj1", Name: "gasboat"}},
+		updates:  map[string]map[string]string{},
+	}
+	h := newAdminTestHandler(fake.NewSimpleClientset(), store, &fakeDiffer{}, make(syncTrigger, 1))
+
+	for _, tt := range []struct {
+		action, want string
+	}{{"pause", "true"}, {"resume", ""}} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/projects/gasboat/"+tt.action, "s3cret"))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.action, rec.Code, rec.Body.String())
+		}
+		if got, ok := store.updates["kd-proj1"][beadsapi.ReconcilePausedField]; !ok || got != tt.want {
+			t.Errorf("%s: reconcile_paused = %q, want %q", tt.action, got, tt.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/projects/nope/pause", "s3cret"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown project: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler_ProjectBudget(t *testing.T) {
+	day := beadsapi.SpendDay(time.Now())
+	store := &fakeProjectStore{
+		projects: map[string]beadsapi.ProjectInfo{"gasboat": {ID: "kd-proj1", Name: "gasboat",
+			BudgetReset: &beadsapi.BudgetReset{Day: day, USD: 5},
+			Budget:      &beadsapi.BudgetStatus{Day: day, SpentUSD: 20, Level: beadsapi.BudgetHard, Paused: true}}},
+		updates: map[string]map[string]string{},
+	}
+	h := newAdminTestHandler(fake.NewSimpleClientset(), store, &fakeDiffer{}, make(syncTrigger, 1))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/projects/gasboat/budget/reset", "s3cret"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reset: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	want := fmt.Sprintf(`{"day":%q,"usd":25}`, day)
+	if got := store.updates["kd-proj1"][beadsapi.BudgetResetField]; got != want {
+		t.Errorf("budget_reset = %s, want %s", got, want)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/projects/gasboat/budget/override?until=2h", "s3cret"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("override: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	until, err := time.Parse(time.RFC3339, store.updates["kd-proj1"][beadsapi.BudgetOverrideField])
+	if err != nil || until.Before(time.Now().Add(time.Hour)) {
+		t.Errorf("budget_override_until = %v (%v), want about 2h ahead", until, err)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodPost, "/admin/projects/gasboat/budget/override?until=soon", "s3cret"))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad until: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler_Diff(t *testing.T) {
+	differ := &fakeDiffer{diff: &reconciler.StateDiff{
+		Desired: 2,
+		Actual:  1,
+		Missing: []reconciler.DiffEntry{{Pod: "crew-gasboat-dev-alpha", Project: "gasboat", Agent: "alpha"}},
+	}}
+	h := newAdminTestHandler(fake.NewSimpleClientset(), &fakeProjectStore{}, differ, make(syncTrigger, 1))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/diff", "s3cret"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got struct {
+		InSync  bool                   `json:"in_sync"`
+		Desired int                    `json:"desired"`
+		Missing []reconciler.DiffEntry `json:"missing"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.InSync || got.Desired != 2 || len(got.Missing) != 1 || got.Missing[0].Agent != "alpha" {
+		t.Errorf("unexpected diff: %+v", got)
+	}
+}
+
+func TestAdminHandler_Plan(t *testing.T) {
+	differ := &fakeDiffer{plan: &reconciler.ReconcilePlan{
+		Desired: 1,
+		Actions: []reconciler.PlanAction{{Kind: reconciler.ActionCreate, Pod: "crew-gasboat-dev-alpha", Reason: "missing pod"}},
+	}}
+	h := newAdminTestHandler(fake.NewSimpleClientset(), &fakeProjectStore{}, differ, make(syncTrigger, 1))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/plan", "s3cret"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("plan before any pass: expected 404, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/plan/next", "s3cret"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got reconciler.ReconcilePlan
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Actions) != 1 || got.Actions[0].Kind != reconciler.ActionCreate || got.Actions[0].Reason != "missing pod" {
+		t.Errorf("unexpected plan: %+v", got)
+	}
+
+	differ.last = differ.plan
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/plan", "s3cret"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("latest plan: expected 200, got %d", rec.Code)
+	}
+}
+
+func TestAdminHandler_History(t *testing.T) {
+	differ := &fakeDiffer{}
+	h := newAdminTestHandler(fake.NewSimpleClientset(), &fakeProjectStore{}, differ, make(syncTrigger, 1))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/history", "s3cret"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("history disabled: expected 404, got %d", rec.Code)
+	}
+
+	// Another replica recorded the passes; this one reads them from the store.
+	store := reconciler.NewFileHistoryStore(filepath.Join(t.TempDir(), "history.json"))
+	now := time.Now()
+	restart := reconciler.PlanAction{Kind: reconciler.ActionRestart, Pod: "crew-gasboat-dev-alpha", Agent: "alpha", Reason: "pod Failed"}
+	create := reconciler.PlanAction{Kind: reconciler.ActionCreate, Pod: "crew-gasboat-dev-beta", Agent: "beta", Reason: "missing pod"}
+	if err := store.SaveHistory(context.Background(), []reconciler.HistoryEntry{
+		{At: now.Add(-48 * time.Hour), Actions: []reconciler.PlanAction{create}},
+		{At: now.Add(-3 * time.Hour), Actions: []reconciler.PlanAction{restart, create}},
+		{At: now.Add(-time.Hour), Actions: []reconciler.PlanAction{restart}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	differ.hist = reconciler.NewHistory(store, 10, slog.Default())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/history?agent=alpha&since=24h", "s3cret"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var got struct {
+		Passes []reconciler.HistoryEntry `json:"passes"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Passes) != 2 || len(got.Passes[1].Actions) != 1 || got.Passes[1].Actions[0].Agent != "alpha" {
+		t.Errorf("alpha's passes = %+v", got.Passes)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/history/drift", "s3cret"))
+	var drift struct {
+		Agents []reconciler.AgentDrift `json:"agents"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &drift); err != nil {
+		t.Fatal(err)
+	}
+	if len(drift.Agents) != 2 || drift.Agents[0].Pod != "crew-gasboat-dev-alpha" || drift.Agents[0].Restarts != 2 {
+		t.Errorf("drift = %+v", drift.Agents)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/history?since=yesterday", "s3cret"))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("bad since: expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+const (
+	// agentExecMaxBody caps request bodies; a command line is small.
+	agentExecMaxBody = 64 << 10
+	// agentExecMaxOutput caps each of stdout and stderr in the response.
+	agentExecMaxOutput = 1 << 20
+	// agentExecDefaultTimeout bounds a command when the caller gives none.
+	agentExecDefaultTimeout = 60 * time.Second
+	// agentExecMaxTimeout bounds how long any one command may run.
+	agentExecMaxTimeout = 10 * time.Minute
+)
+
+// podExecFunc runs command in the agent container of pod, writing its output
+// to stdout and stderr.
+type podExecFunc func(ctx context.Context, pod *corev1.Pod, command []string, stdout, stderr io.Writer) error
+
+// agentExecRequest is the JSON body of POST /agent-exec.
+type agentExecRequest struct {
+	Agent          string   `json:"agent"`
+	Command        []string `json:"command"`
+	TimeoutSeconds int      `json:"timeout_seconds"` // default 60, max 600
+}
+
+// agentExecResponse is the JSON response of POST /agent-exec.
+type agentExecResponse struct {
+	Pod       string `json:"pod"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	ExitCode  int    `json:"exit_code"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// agentExecHandler serves POST /agent-exec, running a one-off command in the
+// agent's newest pod so gb agent exec works without kubectl or knowledge of
+// pod naming. Requests must carry "Authorization: Bearer <token>".
+func agentExecHandler(client kubernetes.Interface, namespace, token string, run podExecFunc, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var req agentExecRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, agentExecMaxBody)).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if req.Agent == "" || len(req.Command) == 0 {
+			http.Error(w, "agent and command are required", http.StatusBadRequest)
+			return
+		}
+		timeout := agentExecDefaultTimeout
+		if req.TimeoutSeconds > 0 {
+			timeout = min(time.Duration(req.TimeoutSeconds)*time.Second, agentExecMaxTimeout)
+		}
+
+		pod, err := newestAgentPod(r.Context(), client, namespace, req.Agent)
+		if errors.Is(err, errAgentPodNotFound) {
+			http.Error(w, fmt.Sprintf("no pod found for agent %q", req.Agent), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		logger.Info("running command in agent pod",
+			"agent", req.Agent, "pod", pod.Name, "command", req.Command)
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		stdout := &cappedBuffer{max: agentExecMaxOutput}
+		stderr := &cappedBuffer{max: agentExecMaxOutput}
+		resp := agentExecResponse{Pod: pod.Name}
+		if err := run(ctx, pod, req.Command, stdout, stderr); err != nil {
+			var exitErr utilexec.ExitError
+			if !errors.As(err, &exitErr) {
+				http.Error(w, fmt.Sprintf("exec in pod %s: %v", pod.Name, err), http.StatusBadGateway)
+				return
+			}
+			resp.ExitCode = exitErr.ExitStatus()
+		}
+		resp.Stdout = stdout.String()
+		resp.Stderr = stderr.String()
+		resp.Truncated = stdout.truncated || stderr.truncated
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// newPodExec returns a podExecFunc that runs commands through the K8s
+// pods/exec subresource.
+func newPodExec(client kubernetes.Interface, restCfg *rest.Config) podExecFunc {
+	return func(ctx context.Context, pod *corev1.Pod, command []string, stdout, stderr io.Writer) error {
+		req := client.CoreV1().RESTClient().Post().
+			Resource("pods").
+			Namespace(pod.Namespace).
+			Name(pod.Name).
+			SubResource("exec").
+			VersionedParams(&corev1.PodExecOptions{
+				Container: podmanager.ContainerName,
+				Command:   command,
+				Stdout:    true,
+				Stderr:    true,
+			}, scheme.ParameterCodec)
+		executor, err := remotecommand.NewSPDYExecutor(restCfg, http.MethodPost, req.URL())
+		if err != nil {
+			return fmt.Errorf("creating executor: %w", err)
+		}
+		return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	}
+}
+
+// cappedBuffer keeps the first max bytes written and discards the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
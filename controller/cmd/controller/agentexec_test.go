@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func execRequest(t *testing.T, token, body string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/agent-exec", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestAgentExecHandler_RunsInNewestPod(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		agentPod("crew-old", "my-bot", now.Add(-time.Hour)),
+		agentPod("crew-new", "my-bot", now),
+	)
+	var gotPod string
+	var gotCmd []string
+	run := func(_ context.Context, pod *corev1.Pod, command []string, stdout, stderr io.Writer) error {
+		gotPod, gotCmd = pod.Name, command
+		fmt.Fprint(stdout, "out")
+		fmt.Fprint(stderr, "err")
+		return utilexec.CodeExitError{Err: errors.New("exit 3"), Code: 3}
+	}
+
+	rec := httptest.NewRecorder()
+	agentExecHandler(client, "gasboat", "s3cret", run, slog.Default())(rec,
+		execRequest(t, "s3cret", `{"agent":"my-bot","command":["git","status"]}`))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if gotPod != "crew-new" || strings.Join(gotCmd, " ") != "git status" {
+		t.Errorf("ran %v in %q", gotCmd, gotPod)
+	}
+	var resp agentExecResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Pod != "crew-new" || resp.Stdout != "out" || resp.Stderr != "err" || resp.ExitCode != 3 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestAgentExecHandler_Errors(t *testing.T) {
+	client := fake.NewSimpleClientset(agentPod("crew-a", "my-bot", time.Now()))
+	run := func(context.Context, *corev1.Pod, []string, io.Writer, io.Writer) error {
+		return errors.New("upgrade failed")
+	}
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		code  int
+	}{
+		{"no token", "", `{"agent":"my-bot","command":["ls"]}`, http.StatusUnauthorized},
+		{"wrong token", "nope", `{"agent":"my-bot","command":["ls"]}`, http.StatusUnauthorized},
+		{"bad json", "s3cret", `{`, http.StatusBadRequest},
+		{"no command", "s3cret", `{"agent":"my-bot"}`, http.StatusBadRequest},
+		{"unknown agent", "s3cret", `{"agent":"missing","command":["ls"]}`, http.StatusNotFound},
+		{"exec failure", "s3cret", `{"agent":"my-bot","command":["ls"]}`, http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		agentExecHandler(client, "gasboat", "s3cret", run, slog.Default())(rec, execRequest(t, tt.token, tt.body))
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.code, rec.Code)
+		}
+	}
+}
+
+func TestCappedBuffer_Truncates(t *testing.T) {
+	b := &cappedBuffer{max: 4}
+	n, err := b.Write([]byte("abcdef"))
+	if err != nil || n != 6 {
+		t.Fatalf("Write = %d, %v", n, err)
+	}
+	if b.String() != "abcd" || !b.truncated {
+		t.Errorf("got %q truncated=%v", b.String(), b.truncated)
+	}
+}
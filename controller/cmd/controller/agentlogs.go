@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+const (
+	// agentLogsDefaultLines is the tail length when the caller gives none.
+	agentLogsDefaultLines = 50
+	// agentLogsMaxLines caps the tail so a single request stays small.
+	agentLogsMaxLines = 500
+)
+
+// newestAgentPod returns the agent's pod. When several pods carry the agent
+// label (e.g. during a restart), the most recently created one is used.
+func newestAgentPod(ctx context.Context, client kubernetes.Interface, namespace, agent string) (*corev1.Pod, error) {
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: podmanager.LabelAgent + "=" + agent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing pods for agent %s: %w", agent, err)
+	}
+	if len(pods.Items) == 0 {
+		return nil, errAgentPodNotFound
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.After(pods.Items[j].CreationTimestamp.Time)
+	})
+	return &pods.Items[0], nil
+}
+
+// agentLogs returns the last lines of the agent container's log for the
+// named agent.
+func agentLogs(ctx context.Context, client kubernetes.Interface, namespace, agent string, lines int64) (string, string, error) {
+	pod, err := newestAgentPod(ctx, client, namespace, agent)
+	if err != nil {
+		return "", "", err
+	}
+	raw, err := client.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: podmanager.ContainerName,
+		TailLines: &lines,
+	}).DoRaw(ctx)
+	if err != nil {
+		return "", pod.Name, fmt.Errorf("reading logs for pod %s: %w", pod.Name, err)
+	}
+	return string(raw), pod.Name, nil
+}
+
+// streamAgentLogs copies the agent container's log to w, starting with the
+// last lines and following new output until ctx is done or the container
+// exits. w is flushed after every write so callers see output live.
+func streamAgentLogs(ctx context.Context, client kubernetes.Interface, namespace string, pod *corev1.Pod, lines int64, w http.ResponseWriter) error {
+	stream, err := client.CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: podmanager.ContainerName,
+		TailLines: &lines,
+		Follow:    true,
+	}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("streaming logs for pod %s: %w", pod.Name, err)
+	}
+	defer stream.Close()
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Pod-Name", pod.Name)
+	flusher, _ := w.(http.Flusher)
+	buf := make([]byte, 32*1024)
+	for {
+		n, readErr := stream.Read(buf)
+		if n > 0 {
+			if _, err := w.Write(buf[:n]); err != nil {
+				return nil // client went away
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		if readErr != nil {
+			return nil // EOF: container exited or stream closed
+		}
+	}
+}
+
+// errAgentPodNotFound is returned when no pod carries the agent label.
+var errAgentPodNotFound = errors.New("no pod found for agent")
+
+// agentLogsHandler serves GET /agent-logs?agent=&lines=&follow= as text/plain
+// so the Slack bridge and gb agent logs can show agent logs without cluster
+// access. The pod name is returned in the X-Pod-Name header. With follow=true
+// the response streams until the client disconnects. Requests must carry
+// "Authorization: Bearer <token>".
+func agentLogsHandler(client kubernetes.Interface, namespace, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		q := r.URL.Query()
+		agent := q.Get("agent")
+		if agent == "" {
+			http.Error(w, "agent is required", http.StatusBadRequest)
+			return
+		}
+		lines := int64(agentLogsDefaultLines)
+		if v := q.Get("lines"); v != "" {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil || n <= 0 {
+				http.Error(w, "lines must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			lines = min(n, agentLogsMaxLines)
+		}
+
+		if follow, _ := strconv.ParseBool(q.Get("follow")); follow {
+			pod, err := newestAgentPod(r.Context(), client, namespace, agent)
+			if errors.Is(err, errAgentPodNotFound) {
+				http.Error(w, fmt.Sprintf("no pod found for agent %q", agent), http.StatusNotFound)
+				return
+			}
+			if err == nil {
+				err = streamAgentLogs(r.Context(), client, namespace, pod, lines, w)
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+			}
+			return
+		}
+
+		logs, podName, err := agentLogs(r.Context(), client, namespace, agent, lines)
+		if errors.Is(err, errAgentPodNotFound) {
+			http.Error(w, fmt.Sprintf("no pod found for agent %q", agent), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Pod-Name", podName)
+		_, _ = w.Write([]byte(logs))
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+func agentPod(name, agent string, created time.Time) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         "gasboat",
+		Labels:            map[string]string{podmanager.LabelAgent: agent},
+		CreationTimestamp: metav1.NewTime(created),
+	}}
+}
+
+func TestAgentLogsHandler_ReturnsNewestPodLogs(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		agentPod("crew-old", "my-bot", now.Add(-time.Hour)),
+		agentPod("crew-new", "my-bot", now),
+		agentPod("crew-other", "other-bot", now),
+	)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/agent-logs?agent=my-bot&lines=20", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	agentLogsHandler(client, "gasboat", "s3cret")(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Pod-Name"); got != "crew-new" {
+		t.Errorf("expected newest pod crew-new, got %q", got)
+	}
+	if rec.Body.String() != "fake logs" {
+		t.Errorf("unexpected body %q", rec.Body.String())
+	}
+}
+
+func TestAgentLogsHandler_Errors(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	tests := []struct {
+		query string
+		code  int
+	}{
+		{"", http.StatusBadRequest},
+		{"agent=my-bot&lines=abc", http.StatusBadRequest},
+		{"agent=my-bot&lines=0", http.StatusBadRequest},
+		{"agent=missing", http.StatusNotFound},
+	}
+	for _, token := range []string{"", "wrong"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/agent-logs?agent=my-bot", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		agentLogsHandler(client, "gasboat", "s3cret")(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, rec.Code)
+		}
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/agent-logs?"+tt.query, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		agentLogsHandler(client, "gasboat", "s3cret")(rec, req)
+		if rec.Code != tt.code {
+			t.Errorf("query %q: expected %d, got %d", tt.query, tt.code, rec.Code)
+		}
+	}
+}
+
+func TestAgentLogsHandler_Follow(t *testing.T) {
+	client := fake.NewSimpleClientset(agentPod("crew-a", "my-bot", time.Now()))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/agent-logs?agent=my-bot&follow=true", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	agentLogsHandler(client, "gasboat", "s3cret")(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Pod-Name"); got != "crew-a" {
+		t.Errorf("expected pod crew-a, got %q", got)
+	}
+	if rec.Body.String() != "fake logs" {
+		t.Errorf("unexpected body %q", rec.Body.String())
+	}
+}
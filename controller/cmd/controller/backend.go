@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/secretreconciler"
+	"gasboat/controller/internal/statusreporter"
+)
+
+// agentBackend is what agents run on: the home Kubernetes cluster and any
+// remote clusters, or a Docker engine (AGENT_BACKEND=docker). The reconciler
+// and status reporter only see its pods; the Kubernetes-only fields are nil
+// for Docker.
+type agentBackend struct {
+	pods podmanager.Manager // agent pods on every cluster
+
+	// statusClusters, when set, are reported instead of client's pods.
+	statusClusters []statusreporter.Cluster
+	// checks are readiness checks of the backend beyond the home cluster's
+	// (see controllerReadinessChecks).
+	checks []readinessCheck
+
+	client         kubernetes.Interface // home cluster
+	restConfig     *rest.Config
+	home           *podmanager.K8sManager
+	multi          *podmanager.MultiCluster
+	clusterClients []kubernetes.Interface
+	secretRec      *secretreconciler.Reconciler
+}
+
+// newAgentBackend sets up the backend selected by cfg.AgentBackend.
+func newAgentBackend(cfg *config.Config, logger *slog.Logger) (*agentBackend, error) {
+	if err := cfg.ValidateBackend(); err != nil {
+		return nil, err
+	}
+	if cfg.AgentBackend == config.BackendDocker {
+		return newDockerBackend(cfg, logger)
+	}
+	return newKubernetesBackend(cfg, logger)
+}
+
+// newDockerBackend runs agents as containers on the Docker engine at
+// cfg.DockerHost.
+func newDockerBackend(cfg *config.Config, logger *slog.Logger) (*agentBackend, error) {
+	docker, err := podmanager.NewDocker(cfg.DockerHost, cfg.DockerNetwork, cfg.DockerSecretsDir, logger)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("running agents on docker", "host", cfg.DockerHost, "network", cfg.DockerNetwork)
+	return &agentBackend{
+		pods:           docker,
+		statusClusters: []statusreporter.Cluster{{Pods: docker}},
+		checks:         []readinessCheck{managerReadinessCheck("docker", docker, cfg.Namespace)},
+	}, nil
+}
+
+// newKubernetesBackend runs agents as pods on the home cluster plus any
+// remote clusters registered via AGENT_CLUSTERS, placed by project pin or
+// capacity.
+func newKubernetesBackend(cfg *config.Config, logger *slog.Logger) (*agentBackend, error) {
+	k8sClient, err := buildK8sClient(cfg.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating K8s client: %w", err)
+	}
+
+	// Build dynamic client for ExternalSecret reconciliation.
+	k8sCfg, err := buildK8sConfig(cfg.KubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("building K8s config for dynamic client: %w", err)
+	}
+	dynClient, err := dynamic.NewForConfig(k8sCfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating dynamic K8s client: %w", err)
+	}
+	secretRec := secretreconciler.New(
+		dynClient, cfg.Namespace,
+		cfg.ExternalSecretStoreName,
+		cfg.ExternalSecretStoreKind,
+		cfg.ExternalSecretRefreshInterval,
+		logger,
+	)
+
+	remotes, err := cfg.RemoteClusters()
+	if err != nil {
+		return nil, fmt.Errorf("invalid AGENT_CLUSTERS: %w", err)
+	}
+	// Pod operations may run as an impersonated user so cluster audit logs
+	// attribute them to it.
+	homePods, err := buildPodClient(cfg.KubeConfig, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating K8s client for pod operations: %w", err)
+	}
+	if cfg.ImpersonateUser != "" {
+		logger.Info("impersonating user for agent pod operations",
+			"user", cfg.ImpersonateUser, "groups", cfg.ImpersonateGroups)
+	}
+	home := podmanager.New(homePods, logger)
+	clusters := []podmanager.Cluster{{Name: cfg.ClusterName, Manager: home, MaxPods: cfg.ClusterMaxPods}}
+	statusClusters := []statusreporter.Cluster{{Name: cfg.ClusterName, Client: k8sClient}}
+	clusterClients := []kubernetes.Interface{k8sClient}
+	var remoteChecks []readinessCheck
+	for _, rc := range remotes {
+		client, err := buildK8sClient(rc.KubeConfig)
+		if err != nil {
+			return nil, fmt.Errorf("creating K8s client for cluster %s: %w", rc.Name, err)
+		}
+		podClient, err := buildPodClient(rc.KubeConfig, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("creating K8s client for pod operations on cluster %s: %w", rc.Name, err)
+		}
+		clusters = append(clusters, podmanager.Cluster{Name: rc.Name, Manager: podmanager.New(podClient, logger), MaxPods: rc.MaxPods})
+		statusClusters = append(statusClusters, statusreporter.Cluster{Name: rc.Name, Client: client})
+		clusterClients = append(clusterClients, client)
+		remoteChecks = append(remoteChecks, clusterReadinessCheck("cluster:"+rc.Name, client, cfg.Namespace))
+	}
+	multi, err := podmanager.NewMultiCluster(clusters, cfg.ClusterPlacement, logger)
+	if err != nil {
+		return nil, fmt.Errorf("setting up agent clusters: %w", err)
+	}
+	if len(remotes) == 0 {
+		// A single cluster is reported without a cluster name.
+		statusClusters = nil
+	} else {
+		logger.Info("multi-cluster agent placement enabled",
+			"clusters", multi.Clusters(), "placement", cfg.ClusterPlacement)
+	}
+	return &agentBackend{
+		pods:           multi,
+		statusClusters: statusClusters,
+		checks:         remoteChecks,
+		client:         k8sClient,
+		restConfig:     k8sCfg,
+		home:           home,
+		multi:          multi,
+		clusterClients: clusterClients,
+		secretRec:      secretRec,
+	}, nil
+}
+
+// managerReadinessCheck checks that agent pods can be listed through pods.
+func managerReadinessCheck(name string, pods podmanager.Manager, namespace string) readinessCheck {
+	return readinessCheck{name: name, check: func(ctx context.Context) (string, error) {
+		if _, err := pods.ListAgentPods(ctx, namespace, map[string]string{podmanager.LabelApp: podmanager.LabelAppValue}); err != nil {
+			return "", fmt.Errorf("listing agent pods: %w", err)
+		}
+		return "reachable", nil
+	}}
+}
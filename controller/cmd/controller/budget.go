@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"sort"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+)
+
+// budgetPageSize is how many agent beads are read per request when
+// summing spend.
+const budgetPageSize = 200
+
+// budgetStore reads spend and records budget state (beadsapi.Client).
+type budgetStore interface {
+	ListProjectBeads(ctx context.Context) (map[string]beadsapi.ProjectInfo, error)
+	ListBeadsFiltered(ctx context.Context, q beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error)
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
+	CloseBead(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// budgetTracker enforces per-project daily API budgets. Each pass it sums
+// the spend agents reported today (beadsapi.SpendField) per project and
+// records it on the project bead. Crossing the soft cap publishes a
+// BudgetTopic alert for the bridge; crossing the hard cap also stops the
+// reconciler spawning the project's agents until the next UTC day, a
+// reset, or an override. Running agents are left alone.
+type budgetTracker struct {
+	store  budgetStore
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func newBudgetTracker(store budgetStore, logger *slog.Logger) *budgetTracker {
+	return &budgetTracker{store: store, logger: logger, now: time.Now}
+}
+
+// check updates the budget status of every project with a cap and marks
+// paused projects in a new cfg.ProjectCache snapshot.
+func (b *budgetTracker) check(ctx context.Context, cfg *config.Config) error {
+	projects, err := b.store.ListProjectBeads(ctx)
+	if err != nil {
+		return fmt.Errorf("listing projects: %w", err)
+	}
+	names := make([]string, 0, len(projects))
+	for name, p := range projects {
+		if p.BudgetSoft != "" || p.BudgetHard != "" || p.Budget != nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+
+	now := b.now()
+	spend, err := b.spendToday(ctx, now)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	cache := maps.Clone(cfg.ProjectCache.Load())
+	for _, name := range names {
+		paused, err := b.checkProject(ctx, projects[name], spend[name], now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("project %s: %w", name, err))
+		}
+		if entry, ok := cache[name]; ok {
+			entry.BudgetPaused = paused
+			cache[name] = entry
+		}
+	}
+	cfg.ProjectCache.Store(cache)
+	return errors.Join(errs...)
+}
+
+// checkProject records p's spend today and reports whether its new agents
+// are held back.
+func (b *budgetTracker) checkProject(ctx context.Context, p beadsapi.ProjectInfo, spent beadsapi.Spend, now time.Time) (bool, error) {
+	soft, hard, err := p.BudgetCaps()
+	if err != nil {
+		return p.BudgetPaused(now), nil // reported when the project is saved
+	}
+	day := beadsapi.SpendDay(now)
+	status := beadsapi.BudgetStatus{
+		Day:      day,
+		SpentUSD: math.Max(0, spent.USD-p.BudgetResetToday(day)),
+		Tokens:   spent.Tokens,
+	}
+	capUSD := 0.0
+	switch {
+	case hard > 0 && status.SpentUSD >= hard:
+		status.Level, status.Paused, capUSD = beadsapi.BudgetHard, true, hard
+	case soft > 0 && status.SpentUSD >= soft:
+		status.Level, capUSD = beadsapi.BudgetSoft, soft
+	}
+
+	prev := p.Budget
+	if prev != nil && prev.Day != day {
+		prev = nil
+	}
+	if prev == nil || prev.Level != status.Level || prev.Paused != status.Paused ||
+		math.Abs(prev.SpentUSD-status.SpentUSD) >= 0.01 {
+		data, _ := json.Marshal(status)
+		if err := b.store.UpdateBeadFields(ctx, p.ID, map[string]string{beadsapi.BudgetStatusField: string(data)}); err != nil {
+			return status.Paused && !p.BudgetOverridden(now), fmt.Errorf("recording budget status: %w", err)
+		}
+	}
+
+	prevLevel := ""
+	if prev != nil {
+		prevLevel = prev.Level
+	}
+	if budgetRank(status.Level) > budgetRank(prevLevel) {
+		alert := beadsapi.BudgetAlert{
+			Project:  p.Name,
+			Day:      day,
+			Level:    status.Level,
+			SpentUSD: status.SpentUSD,
+			CapUSD:   capUSD,
+			Tokens:   status.Tokens,
+		}
+		b.logger.Warn("project budget cap reached", "project", p.Name, "level", status.Level,
+			"spent_usd", status.SpentUSD, "cap_usd", capUSD)
+		if err := b.publish(ctx, alert); err != nil {
+			b.logger.Warn("failed to publish budget alert", "project", p.Name, "error", err)
+		}
+	}
+	return status.Paused && !p.BudgetOverridden(now), nil
+}
+
+// spendToday sums the spend agents reported for now's UTC day, by project.
+// Agent beads are read most recently updated first, stopping at the first
+// one not updated today.
+func (b *budgetTracker) spendToday(ctx context.Context, now time.Time) (map[string]beadsapi.Spend, error) {
+	day := beadsapi.SpendDay(now)
+	start, _ := time.Parse(time.DateOnly, day)
+	totals := make(map[string]beadsapi.Spend)
+	for offset := 0; ; offset += budgetPageSize {
+		res, err := b.store.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{
+			Types:    []string{"agent"},
+			Statuses: []string{"open", "in_progress", "blocked", "deferred", "closed"},
+			Sort:     "-updated_at",
+			Limit:    budgetPageSize,
+			Offset:   offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing agent spend: %w", err)
+		}
+		for _, bead := range res.Beads {
+			if !bead.UpdatedAt.IsZero() && bead.UpdatedAt.Before(start) {
+				return totals, nil
+			}
+			s, ok := beadsapi.SpendFromFields(bead.Fields)[day]
+			if !ok {
+				continue
+			}
+			project := bead.Fields["project"]
+			t := totals[project]
+			t.USD += s.USD
+			t.Tokens += s.Tokens
+			totals[project] = t
+		}
+		if len(res.Beads) < budgetPageSize {
+			return totals, nil
+		}
+	}
+}
+
+// publish records the alert as an event bead and closes it right away;
+// the bridge sees it via the bead's creation event.
+func (b *budgetTracker) publish(ctx context.Context, alert beadsapi.BudgetAlert) error {
+	fields, err := json.Marshal(map[string]any{
+		"topic":   beadsapi.BudgetTopic,
+		"payload": alert,
+	})
+	if err != nil {
+		return err
+	}
+	id, err := b.store.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title:     fmt.Sprintf("event: %s (%s %s)", beadsapi.BudgetTopic, alert.Project, alert.Level),
+		Type:      "event",
+		Labels:    []string{"bus:" + beadsapi.BudgetTopic, "project:" + alert.Project},
+		CreatedBy: "controller",
+		Fields:    fields,
+	})
+	if err != nil {
+		return fmt.Errorf("publishing %s: %w", beadsapi.BudgetTopic, err)
+	}
+	if err := b.store.CloseBead(ctx, id, nil); err != nil {
+		b.logger.Warn("budget alert published but not closed", "id", id, "error", err)
+	}
+	return nil
+}
+
+func budgetRank(level string) int {
+	switch level {
+	case beadsapi.BudgetHard:
+		return 2
+	case beadsapi.BudgetSoft:
+		return 1
+	}
+	return 0
+}
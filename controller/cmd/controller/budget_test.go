@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+)
+
+// fakeBudgetStore serves one project and a page of agent beads, recording
+// status updates and published alerts.
+type fakeBudgetStore struct {
+	project beadsapi.ProjectInfo
+	agents  []*beadsapi.BeadDetail // most recently updated first
+	updates map[string]string
+	alerts  []beadsapi.BudgetAlert
+}
+
+func (f *fakeBudgetStore) ListProjectBeads(context.Context) (map[string]beadsapi.ProjectInfo, error) {
+	return map[string]beadsapi.ProjectInfo{f.project.Name: f.project}, nil
+}
+
+func (f *fakeBudgetStore) ListBeadsFiltered(context.Context, beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error) {
+	return &beadsapi.ListBeadsResult{Beads: f.agents, Total: len(f.agents)}, nil
+}
+
+func (f *fakeBudgetStore) UpdateBeadFields(_ context.Context, _ string, fields map[string]string) error {
+	if f.updates == nil {
+		f.updates = make(map[string]string)
+	}
+	for k, v := range fields {
+		f.updates[k] = v
+	}
+	// Later passes see what this one recorded.
+	f.project = beadsapi.ProjectInfoFromFields(f.project.Name, mergeFields(f.project.Fields(), f.updates))
+	f.project.ID = "bd-proj"
+	return nil
+}
+
+func (f *fakeBudgetStore) CreateBead(_ context.Context, req beadsapi.CreateBeadRequest) (string, error) {
+	var event struct {
+		Payload beadsapi.BudgetAlert `json:"payload"`
+	}
+	_ = json.Unmarshal(req.Fields, &event)
+	f.alerts = append(f.alerts, event.Payload)
+	return "kd-event", nil
+}
+
+func (f *fakeBudgetStore) CloseBead(context.Context, string, map[string]string) error { return nil }
+
+func mergeFields(a, b map[string]string) map[string]string {
+	for k, v := range b {
+		a[k] = v
+	}
+	return a
+}
+
+func spendingAgent(project string, updated time.Time, usd float64) *beadsapi.BeadDetail {
+	raw, _ := beadsapi.AddSpend("", beadsapi.SpendDay(updated), beadsapi.Spend{USD: usd, Tokens: 1000})
+	return &beadsapi.BeadDetail{
+		ID:        "bd-" + project,
+		UpdatedAt: updated,
+		Fields:    map[string]string{"project": project, beadsapi.SpendField: raw},
+	}
+}
+
+func TestBudgetTracker_SoftThenHardCap(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	store := &fakeBudgetStore{
+		project: beadsapi.ProjectInfo{ID: "bd-proj", Name: "api", BudgetSoft: "10", BudgetHard: "20"},
+		agents: []*beadsapi.BeadDetail{
+			spendingAgent("api", now.Add(-time.Hour), 6),
+			spendingAgent("web", now.Add(-time.Hour), 50),
+			spendingAgent("api", now.Add(-2*time.Hour), 6),
+			// Not updated today: the listing stops here.
+			spendingAgent("api", now.AddDate(0, 0, -1), 100),
+		},
+	}
+	b := newBudgetTracker(store, slog.Default())
+	b.now = func() time.Time { return now }
+	cfg := &config.Config{ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{"api": {}})}
+
+	if err := b.check(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.alerts) != 1 || store.alerts[0].Level != beadsapi.BudgetSoft || store.alerts[0].SpentUSD != 12 {
+		t.Fatalf("alerts = %+v, want one soft alert at $12", store.alerts)
+	}
+	if cfg.ProjectCache.Load()["api"].BudgetPaused {
+		t.Error("paused below the hard cap")
+	}
+
+	// The same level is not announced twice.
+	if err := b.check(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.alerts) != 1 {
+		t.Errorf("alerts = %d after an unchanged pass, want 1", len(store.alerts))
+	}
+
+	store.agents[0] = spendingAgent("api", now.Add(-time.Minute), 15)
+	if err := b.check(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.alerts) != 2 || store.alerts[1].Level != beadsapi.BudgetHard || store.alerts[1].CapUSD != 20 {
+		t.Fatalf("alerts = %+v, want a hard alert", store.alerts)
+	}
+	if !cfg.ProjectCache.Load()["api"].BudgetPaused || !store.project.BudgetPaused(now) {
+		t.Error("project over its hard cap not paused")
+	}
+
+	// A reset discounts what was spent so far.
+	store.project.BudgetReset = &beadsapi.BudgetReset{Day: "2026-03-02", USD: 21}
+	if err := b.check(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ProjectCache.Load()["api"].BudgetPaused || store.project.Budget.Level != "" {
+		t.Errorf("project still held back after a reset: %+v", store.project.Budget)
+	}
+}
+
+func TestBudgetTracker_OverrideLiftsPause(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	store := &fakeBudgetStore{
+		project: beadsapi.ProjectInfo{ID: "bd-proj", Name: "api", BudgetHard: "5",
+			BudgetOverrideUntil: now.Add(time.Hour).Format(time.RFC3339)},
+		agents: []*beadsapi.BeadDetail{spendingAgent("api", now, 8)},
+	}
+	b := newBudgetTracker(store, slog.Default())
+	b.now = func() time.Time { return now }
+	cfg := &config.Config{ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{"api": {}})}
+
+	if err := b.check(context.Background(), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ProjectCache.Load()["api"].BudgetPaused {
+		t.Error("override ignored")
+	}
+	if len(store.alerts) != 1 || store.alerts[0].Level != beadsapi.BudgetHard {
+		t.Errorf("alerts = %+v, want the hard cap still announced", store.alerts)
+	}
+}
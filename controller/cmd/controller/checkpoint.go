@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+// preemptionNudge is sent to an agent whose spot node is being reclaimed.
+const preemptionNudge = "This pod's spot node is being reclaimed and the pod will be replaced shortly. " +
+	"Stop starting new work and run `gb yield --checkpoint --note \"spot preemption\"` now so the next session can resume."
+
+// coopCheckpointer asks an agent about to lose its pod (to a preemption, or
+// a termination grace window) to checkpoint by nudging it through its coop
+// API.
+type coopCheckpointer struct {
+	client *http.Client
+}
+
+func newCoopCheckpointer() *coopCheckpointer {
+	return &coopCheckpointer{client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Checkpoint implements reconciler.Checkpointer.
+func (c *coopCheckpointer) Checkpoint(ctx context.Context, pod *corev1.Pod) error {
+	return c.Nudge(ctx, pod, preemptionNudge)
+}
+
+// Nudge sends message to the agent in pod through its coop API.
+func (c *coopCheckpointer) Nudge(ctx context.Context, pod *corev1.Pod, message string) error {
+	if pod.Status.PodIP == "" {
+		return fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+	body, err := json.Marshal(map[string]string{"message": message})
+	if err != nil {
+		return fmt.Errorf("marshal nudge body: %w", err)
+	}
+	url := fmt.Sprintf("http://%s:%d/api/v1/agent/nudge", pod.Status.PodIP, podmanager.CoopDefaultPort)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create nudge request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("nudge request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("nudge returned status %d", resp.StatusCode)
+	}
+	var result struct {
+		Delivered bool   `json:"delivered"`
+		Reason    string `json:"reason"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && !result.Delivered {
+		return fmt.Errorf("nudge not delivered: %s", result.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/cooptoken"
+)
+
+const (
+	// coopTokenCookie carries a coop token after the first request, so the
+	// browser's follow-up requests (assets, websockets) need no token in the
+	// URL. It is scoped to the agent's proxy path.
+	coopTokenCookie = "gasboat_coop_token"
+
+	// coopRegistryTTL is how long the proxy reuses the coop registry.
+	coopRegistryTTL = 5 * time.Second
+
+	// coopTokenMaxBody caps POST /coop-token request bodies.
+	coopTokenMaxBody = 4 << 10
+)
+
+// coopRegistryReader reads the coop service registry the controller
+// publishes (*beadsapi.Client).
+type coopRegistryReader interface {
+	GetCoopRegistry(ctx context.Context) (*beadsapi.CoopRegistry, error)
+}
+
+// coopProxy serves /coop/{agent}/..., forwarding requests that carry a valid
+// coop token for the agent to the agent's coop session. Tokens come from
+// the Authorization header, a ?token= parameter, or the cookie set when a
+// ?token= link is first opened.
+type coopProxy struct {
+	signer   *cooptoken.Signer
+	registry coopRegistryReader
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu       sync.Mutex
+	cached   *beadsapi.CoopRegistry
+	cachedAt time.Time
+}
+
+func newCoopProxy(signer *cooptoken.Signer, registry coopRegistryReader, logger *slog.Logger) *coopProxy {
+	return &coopProxy{signer: signer, registry: registry, logger: logger, now: time.Now}
+}
+
+func (p *coopProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	agent, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/coop/"), "/")
+	if agent == "" {
+		http.NotFound(w, r)
+		return
+	}
+	base := "/coop/" + agent + "/"
+
+	token, fromQuery := coopRequestToken(r)
+	claims, err := p.signer.Verify(token)
+	switch {
+	case token == "":
+		http.Error(w, "coop token required", http.StatusUnauthorized)
+		return
+	case errors.Is(err, cooptoken.ErrExpired):
+		http.Error(w, "coop token expired", http.StatusUnauthorized)
+		return
+	case err != nil:
+		http.Error(w, "invalid coop token", http.StatusUnauthorized)
+		return
+	case claims.Agent != agent:
+		http.Error(w, "coop token is for another agent", http.StatusForbidden)
+		return
+	}
+
+	if fromQuery {
+		// Keep the token for follow-up requests, and out of the URL so it
+		// is not logged or leaked in Referer headers.
+		http.SetCookie(w, &http.Cookie{
+			Name:     coopTokenCookie,
+			Value:    token,
+			Path:     base,
+			Expires:  claims.Expires(),
+			HttpOnly: true,
+			Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+			SameSite: http.SameSiteLaxMode,
+		})
+		if r.Method == http.MethodGet {
+			q := r.URL.Query()
+			q.Del("token")
+			target := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+			http.Redirect(w, r, target.String(), http.StatusSeeOther)
+			return
+		}
+	}
+
+	target, err := p.coopURL(r.Context(), agent)
+	if err != nil {
+		p.logger.Warn("coop proxy: reading coop registry", "agent", agent, "error", err)
+		http.Error(w, "coop registry unavailable", http.StatusBadGateway)
+		return
+	}
+	if target == nil {
+		http.Error(w, "agent has no coop session", http.StatusNotFound)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.URL.Path = strings.TrimRight(target.Path, "/") + "/" + rest
+			pr.Out.URL.RawPath = ""
+			q := pr.Out.URL.Query()
+			q.Del("token")
+			pr.Out.URL.RawQuery = q.Encode()
+			pr.Out.Header.Del("Authorization")
+			stripCookie(pr.Out.Header, coopTokenCookie)
+			pr.SetXForwarded()
+		},
+		ErrorHandler: func(w http.ResponseWriter, _ *http.Request, err error) {
+			p.logger.Warn("coop proxy: forwarding", "agent", agent, "error", err)
+			http.Error(w, "coop unreachable", http.StatusBadGateway)
+		},
+	}
+	p.logger.Debug("coop proxy", "agent", agent, "subject", claims.Subject, "method", r.Method, "path", rest)
+	proxy.ServeHTTP(w, r)
+}
+
+// coopURL returns the coop URL of agent, or nil if it has no session.
+func (p *coopProxy) coopURL(ctx context.Context, agent string) (*url.URL, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cached == nil || p.now().Sub(p.cachedAt) > coopRegistryTTL {
+		reg, err := p.registry.GetCoopRegistry(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if reg == nil {
+			reg = &beadsapi.CoopRegistry{}
+		}
+		p.cached, p.cachedAt = reg, p.now()
+	}
+	ep, ok := p.cached.Agents[agent]
+	if !ok || ep.URL == "" {
+		return nil, nil
+	}
+	return url.Parse(ep.URL)
+}
+
+// coopRequestToken returns the coop token r carries, and whether it came
+// from the query string.
+func coopRequestToken(r *http.Request) (string, bool) {
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return strings.TrimPrefix(h, "Bearer "), false
+	}
+	if t := r.URL.Query().Get("token"); t != "" {
+		return t, true
+	}
+	if c, err := r.Cookie(coopTokenCookie); err == nil {
+		return c.Value, false
+	}
+	return "", false
+}
+
+// stripCookie removes the named cookie from h's Cookie headers.
+func stripCookie(h http.Header, name string) {
+	cookies := (&http.Request{Header: h}).Cookies()
+	h.Del("Cookie")
+	var kept []string
+	for _, c := range cookies {
+		if c.Name != name {
+			kept = append(kept, c.String())
+		}
+	}
+	if len(kept) > 0 {
+		h.Set("Cookie", strings.Join(kept, "; "))
+	}
+}
+
+// coopTokenRequest is the body of POST /coop-token.
+type coopTokenRequest struct {
+	Agent   string `json:"agent"`
+	Subject string `json:"subject,omitempty"`
+}
+
+// coopTokenResponse is a minted coop token and the proxy path it opens.
+type coopTokenResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Path      string    `json:"path"`
+}
+
+// coopTokenHandler mints coop tokens for bridges, which authenticate with
+// the client bearer token.
+func coopTokenHandler(signer *cooptoken.Signer, clientToken string, ttl time.Duration, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(clientToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req coopTokenRequest
+		if err := json.NewDecoder(io.LimitReader(r.Body, coopTokenMaxBody)).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if req.Agent == "" || strings.Contains(req.Agent, "/") {
+			http.Error(w, "agent is required", http.StatusBadRequest)
+			return
+		}
+		token, claims, err := signer.Mint(req.Agent, req.Subject, ttl)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		logger.Info("minted coop token", "agent", req.Agent, "subject", req.Subject, "expires", claims.Expires())
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(coopTokenResponse{
+			Token:     token,
+			ExpiresAt: claims.Expires().UTC(),
+			Path:      "/coop/" + req.Agent + "/",
+		})
+	}
+}
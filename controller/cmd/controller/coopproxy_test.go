@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/cooptoken"
+)
+
+// fakeCoopRegistry serves a fixed coop registry.
+type fakeCoopRegistry struct {
+	reg *beadsapi.CoopRegistry
+}
+
+func (f fakeCoopRegistry) GetCoopRegistry(context.Context) (*beadsapi.CoopRegistry, error) {
+	return f.reg, nil
+}
+
+func testCoopSigner(t *testing.T) *cooptoken.Signer {
+	t.Helper()
+	signer, err := cooptoken.NewSigner([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func TestCoopProxy(t *testing.T) {
+	var got *http.Request
+	coop := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		_, _ = w.Write([]byte("screen"))
+	}))
+	defer coop.Close()
+
+	signer := testCoopSigner(t)
+	proxy := newCoopProxy(signer, fakeCoopRegistry{reg: &beadsapi.CoopRegistry{Agents: map[string]beadsapi.CoopEndpoint{
+		"kd-a": {URL: coop.URL},
+	}}}, quietLogger())
+	token, _, _ := signer.Mint("kd-a", "slack-bridge", time.Hour)
+	other, _, _ := signer.Mint("kd-b", "slack-bridge", time.Hour)
+
+	serve := func(target, bearer string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		proxy.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/coop/kd-a/api/v1/screen", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("no token: expected 401, got %d", rec.Code)
+	}
+	if rec := serve("/coop/kd-a/api/v1/screen", other); rec.Code != http.StatusForbidden {
+		t.Errorf("token for another agent: expected 403, got %d", rec.Code)
+	}
+	if rec := serve("/coop/kd-b/api/v1/screen", other); rec.Code != http.StatusNotFound {
+		t.Errorf("agent without a session: expected 404, got %d", rec.Code)
+	}
+
+	rec := serve("/coop/kd-a/api/v1/screen?lines=5", token)
+	if rec.Code != http.StatusOK || rec.Body.String() != "screen" {
+		t.Fatalf("proxied: %d %q", rec.Code, rec.Body)
+	}
+	if got.URL.Path != "/api/v1/screen" || got.URL.Query().Get("lines") != "5" || got.Header.Get("Authorization") != "" {
+		t.Errorf("forwarded request: path %q, query %q, auth %q", got.URL.Path, got.URL.RawQuery, got.Header.Get("Authorization"))
+	}
+
+	// A ?token= link sets a cookie scoped to the agent and drops the token
+	// from the URL; the cookie then authorizes follow-up requests.
+	rec = serve("/coop/kd-a/?token="+token+"&view=term", "")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/coop/kd-a/?view=term" {
+		t.Fatalf("token link: %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Path != "/coop/kd-a/" || !cookies[0].HttpOnly {
+		t.Fatalf("cookies = %+v", cookies)
+	}
+	got = nil
+	if rec := serve("/coop/kd-a/", "", cookies[0], &http.Cookie{Name: "theme", Value: "dark"}); rec.Code != http.StatusOK {
+		t.Fatalf("with cookie: expected 200, got %d", rec.Code)
+	}
+	if c := got.Header.Get("Cookie"); strings.Contains(c, coopTokenCookie) || !strings.Contains(c, "theme=dark") {
+		t.Errorf("forwarded cookies = %q", c)
+	}
+}
+
+func TestCoopProxy_ExpiredToken(t *testing.T) {
+	signer := testCoopSigner(t)
+	proxy := newCoopProxy(signer, fakeCoopRegistry{}, quietLogger())
+	token, _, _ := signer.Mint("kd-a", "", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/coop/kd-a/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	proxy.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "expired") {
+		t.Errorf("expired token: %d %q", rec.Code, rec.Body)
+	}
+}
+
+func TestCoopTokenHandler(t *testing.T) {
+	signer := testCoopSigner(t)
+	h := coopTokenHandler(signer, "client-secret", 15*time.Minute, quietLogger())
+	mint := func(bearer, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/coop-token", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		rec := httptest.NewRecorder()
+		h(rec, req)
+		return rec
+	}
+
+	if rec := mint("wrong", `{"agent":"kd-a"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong client token: expected 401, got %d", rec.Code)
+	}
+	if rec := mint("client-secret", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("no agent: expected 400, got %d", rec.Code)
+	}
+	rec := mint("client-secret", `{"agent":"kd-a","subject":"slack-bridge"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("mint: %d %s", rec.Code, rec.Body)
+	}
+	var resp coopTokenResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	claims, err := signer.Verify(resp.Token)
+	if err != nil || claims.Agent != "kd-a" || claims.Subject != "slack-bridge" || resp.Path != "/coop/kd-a/" {
+		t.Errorf("minted %+v: claims %+v, err %v", resp, claims, err)
+	}
+	if left := time.Until(resp.ExpiresAt); left > 15*time.Minute || left < 14*time.Minute {
+		t.Errorf("expires in %s, want 15m", left)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/schedule"
+)
+
+// cronRunsLimit bounds how many runs of one template are listed per pass.
+const cronRunsLimit = 200
+
+// cronStore reads and writes template and run beads (beadsapi.Client).
+type cronStore interface {
+	ListBeadsFiltered(ctx context.Context, q beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error)
+	SpawnAgentWith(ctx context.Context, req beadsapi.SpawnAgentRequest) (string, error)
+	AddLabel(ctx context.Context, beadID, label string) error
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+	CloseBead(ctx context.Context, beadID string, fields map[string]string) error
+	DeleteBead(ctx context.Context, beadID string) error
+}
+
+// cronScheduler starts scheduled jobs: a template bead with a cron field
+// gets a fresh job agent bead at each tick, which the reconciler then
+// schedules like any other agent. It runs on the periodic sync, so a run
+// starts up to one sync interval after its tick.
+//
+// The last tick handled and skipped runs are recorded on the template bead,
+// so a restarted or newly elected controller neither repeats nor silently
+// drops runs: ticks missed while no controller ran are reported skipped and
+// only the latest one runs.
+type cronScheduler struct {
+	store  cronStore
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func newCronScheduler(store cronStore, logger *slog.Logger) *cronScheduler {
+	return &cronScheduler{store: store, logger: logger, now: time.Now}
+}
+
+// run starts the due runs of every scheduled template.
+func (s *cronScheduler) run(ctx context.Context, templates map[string]beadsapi.TemplateInfo) error {
+	names := make([]string, 0, len(templates))
+	for name, t := range templates {
+		if t.Fields[beadsapi.TemplateCronField] != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	now := s.now()
+	var errs []error
+	for _, name := range names {
+		if err := s.runTemplate(ctx, templates[name], now); err != nil {
+			errs = append(errs, fmt.Errorf("template %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runTemplate handles the latest tick of t's schedule since the last one
+// handled, applying its concurrency policy and history limit.
+func (s *cronScheduler) runTemplate(ctx context.Context, t beadsapi.TemplateInfo, now time.Time) error {
+	cron, err := schedule.ParseCron(t.Fields[beadsapi.TemplateCronField])
+	if err != nil {
+		return nil // reported when the template cache was refreshed
+	}
+	last, err := time.Parse(time.RFC3339, t.Fields[beadsapi.TemplateCronLastRunField])
+	if err != nil {
+		// Newly scheduled: count ticks from now, not from the template's past.
+		return s.store.UpdateBeadFields(ctx, t.ID, map[string]string{
+			beadsapi.TemplateCronLastRunField: now.UTC().Format(time.RFC3339),
+		})
+	}
+	due, missed := dueTick(cron, last, now)
+	if due.IsZero() {
+		return nil
+	}
+
+	runs, err := s.runs(ctx, t.Name)
+	if err != nil {
+		return err
+	}
+	var active, finished []*beadsapi.BeadDetail
+	for _, r := range runs {
+		if r.Status == "closed" {
+			finished = append(finished, r)
+		} else {
+			active = append(active, r)
+		}
+	}
+
+	fields := map[string]string{beadsapi.TemplateCronLastRunField: due.UTC().Format(time.RFC3339)}
+	skipped, _ := strconv.Atoi(t.Fields[beadsapi.TemplateCronSkippedField])
+	skip := func(n int, reason string) {
+		skipped += n
+		fields[beadsapi.TemplateCronSkippedField] = strconv.Itoa(skipped)
+		fields[beadsapi.TemplateCronLastSkipField] = due.UTC().Format(time.RFC3339) + ": " + reason
+		s.logger.Warn("scheduled job run skipped", "template", t.Name, "tick", due, "runs", n, "reason", reason)
+	}
+	if missed > 0 {
+		skip(missed, fmt.Sprintf("%d earlier tick(s) missed while no controller was running", missed))
+	}
+
+	switch t.CronConcurrency() {
+	case beadsapi.CronForbid:
+		if len(active) > 0 {
+			skip(1, fmt.Sprintf("previous run %s still active (concurrency Forbid)", active[0].Title))
+			return s.store.UpdateBeadFields(ctx, t.ID, fields)
+		}
+	case beadsapi.CronReplace:
+		for _, r := range active {
+			s.logger.Info("replacing scheduled job run", "template", t.Name, "agent", r.Title, "bead", r.ID)
+			if err := s.store.CloseBead(ctx, r.ID, map[string]string{"reason": "replaced by scheduled run"}); err != nil {
+				return fmt.Errorf("closing previous run %s: %w", r.ID, err)
+			}
+			finished = append([]*beadsapi.BeadDetail{r}, finished...)
+		}
+	}
+
+	role := t.Fields["role"]
+	if role == "" {
+		role = "job"
+	}
+	name := fmt.Sprintf("%s-%s", t.Name, due.UTC().Format("20060102-1504"))
+	id, err := s.store.SpawnAgentWith(ctx, beadsapi.SpawnAgentRequest{
+		AgentName: name,
+		Project:   t.Fields["project"],
+		Role:      role,
+		Mode:      "job",
+		Template:  t.Name,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.store.AddLabel(ctx, id, beadsapi.CronRunLabel(t.Name)); err != nil {
+		s.logger.Warn("failed to label scheduled job run", "template", t.Name, "bead", id, "error", err)
+	}
+	s.logger.Info("started scheduled job run", "template", t.Name, "agent", name, "bead", id, "tick", due)
+	if err := s.store.UpdateBeadFields(ctx, t.ID, fields); err != nil {
+		return fmt.Errorf("recording run %s: %w", name, err)
+	}
+
+	limit, _ := t.CronHistoryLimit()
+	for i := limit; i < len(finished); i++ {
+		if err := s.store.DeleteBead(ctx, finished[i].ID); err != nil {
+			s.logger.Warn("failed to delete old scheduled job run", "template", t.Name, "bead", finished[i].ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// runs lists the agent beads of template's scheduled runs, newest first.
+func (s *cronScheduler) runs(ctx context.Context, template string) ([]*beadsapi.BeadDetail, error) {
+	res, err := s.store.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{
+		Types:    []string{"agent"},
+		Statuses: []string{"open", "in_progress", "blocked", "deferred", "closed"},
+		Labels:   []string{beadsapi.CronRunLabel(template)},
+		Sort:     "-created_at",
+		Limit:    cronRunsLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing runs: %w", err)
+	}
+	return res.Beads, nil
+}
+
+// dueTick returns the latest tick of cron after last and at or before now,
+// and how many earlier ticks in between were missed. due is zero if no tick
+// is due.
+func dueTick(cron *schedule.Cron, last, now time.Time) (due time.Time, missed int) {
+	for t := cron.Next(last); !t.IsZero() && !t.After(now); t = cron.Next(t) {
+		if !due.IsZero() {
+			missed++
+		}
+		due = t
+	}
+	return due, missed
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/schedule"
+)
+
+// fakeCronStore keeps run beads in memory and records template updates.
+type fakeCronStore struct {
+	runs    []*beadsapi.BeadDetail // newest first
+	spawned []beadsapi.SpawnAgentRequest
+	closed  []string
+	deleted []string
+	updates map[string]string
+}
+
+func (f *fakeCronStore) ListBeadsFiltered(context.Context, beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error) {
+	return &beadsapi.ListBeadsResult{Beads: f.runs, Total: len(f.runs)}, nil
+}
+
+func (f *fakeCronStore) SpawnAgentWith(_ context.Context, req beadsapi.SpawnAgentRequest) (string, error) {
+	f.spawned = append(f.spawned, req)
+	return "bd-" + req.AgentName, nil
+}
+
+func (f *fakeCronStore) AddLabel(context.Context, string, string) error { return nil }
+
+func (f *fakeCronStore) UpdateBeadFields(_ context.Context, _ string, fields map[string]string) error {
+	if f.updates == nil {
+		f.updates = make(map[string]string)
+	}
+	for k, v := range fields {
+		f.updates[k] = v
+	}
+	return nil
+}
+
+func (f *fakeCronStore) CloseBead(_ context.Context, id string, _ map[string]string) error {
+	f.closed = append(f.closed, id)
+	return nil
+}
+
+func (f *fakeCronStore) DeleteBead(_ context.Context, id string) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func cronTemplate(policy, lastRun string) map[string]beadsapi.TemplateInfo {
+	return map[string]beadsapi.TemplateInfo{"nightly": {ID: "bd-tmpl", Name: "nightly", Fields: map[string]string{
+		"project": "gasboat", "cron": "0 3 * * *", "cron_concurrency": policy,
+		"cron_history_limit": "1", "cron_last_run": lastRun,
+	}}}
+}
+
+func newTestCronScheduler(store cronStore, now time.Time) *cronScheduler {
+	s := newCronScheduler(store, slog.Default())
+	s.now = func() time.Time { return now }
+	return s
+}
+
+func TestCronScheduler_StartsDueRunAndPrunesHistory(t *testing.T) {
+	store := &fakeCronStore{runs: []*beadsapi.BeadDetail{
+		{ID: "bd-r2", Title: "nightly-2", Status: "closed"},
+		{ID: "bd-r1", Title: "nightly-1", Status: "closed"},
+	}}
+	now := time.Date(2026, 3, 3, 3, 0, 40, 0, time.UTC)
+	s := newTestCronScheduler(store, now)
+
+	if err := s.run(context.Background(), cronTemplate("", "2026-03-02T03:00:00Z")); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.spawned) != 1 {
+		t.Fatalf("spawned %d runs, want 1", len(store.spawned))
+	}
+	req := store.spawned[0]
+	if req.AgentName != "nightly-20260303-0300" || req.Mode != "job" || req.Role != "job" || req.Template != "nightly" || req.Project != "gasboat" {
+		t.Errorf("spawn request = %+v", req)
+	}
+	if store.updates["cron_last_run"] != "2026-03-03T03:00:00Z" {
+		t.Errorf("cron_last_run = %q", store.updates["cron_last_run"])
+	}
+	if len(store.deleted) != 1 || store.deleted[0] != "bd-r1" {
+		t.Errorf("deleted = %v, want the oldest run beyond the history limit", store.deleted)
+	}
+
+	// The same tick is not run twice.
+	store.spawned = nil
+	if err := s.run(context.Background(), cronTemplate("", store.updates["cron_last_run"])); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.spawned) != 0 {
+		t.Errorf("tick ran twice: %v", store.spawned)
+	}
+}
+
+func TestCronScheduler_ConcurrencyPolicies(t *testing.T) {
+	now := time.Date(2026, 3, 3, 3, 1, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		policy  string
+		spawned int
+		closed  int
+		skipped string
+	}{
+		{"Forbid", 0, 0, "1"},
+		{"Replace", 1, 1, ""},
+		{"Allow", 1, 0, ""},
+	} {
+		store := &fakeCronStore{runs: []*beadsapi.BeadDetail{{ID: "bd-r1", Title: "nightly-1", Status: "in_progress"}}}
+		s := newTestCronScheduler(store, now)
+		if err := s.run(context.Background(), cronTemplate(tt.policy, "2026-03-02T03:00:00Z")); err != nil {
+			t.Fatal(err)
+		}
+		if len(store.spawned) != tt.spawned || len(store.closed) != tt.closed || store.updates["cron_skipped"] != tt.skipped {
+			t.Errorf("%s: spawned %d, closed %d, skipped %q", tt.policy, len(store.spawned), len(store.closed), store.updates["cron_skipped"])
+		}
+		if tt.policy == "Forbid" && !strings.Contains(store.updates["cron_last_skip"], "nightly-1 still active") {
+			t.Errorf("cron_last_skip = %q", store.updates["cron_last_skip"])
+		}
+	}
+}
+
+func TestCronScheduler_ReportsMissedTicks(t *testing.T) {
+	store := &fakeCronStore{}
+	s := newTestCronScheduler(store, time.Date(2026, 3, 5, 4, 0, 0, 0, time.UTC))
+	if err := s.run(context.Background(), cronTemplate("", "2026-03-02T03:00:00Z")); err != nil {
+		t.Fatal(err)
+	}
+	// Ticks on the 3rd and 4th were missed; the 5th runs.
+	if len(store.spawned) != 1 || store.spawned[0].AgentName != "nightly-20260305-0300" {
+		t.Errorf("spawned = %+v", store.spawned)
+	}
+	if store.updates["cron_skipped"] != "2" {
+		t.Errorf("cron_skipped = %q, want 2", store.updates["cron_skipped"])
+	}
+}
+
+func TestCronScheduler_NewScheduleStartsCountingNow(t *testing.T) {
+	store := &fakeCronStore{}
+	now := time.Date(2026, 3, 5, 4, 0, 0, 0, time.UTC)
+	s := newTestCronScheduler(store, now)
+	if err := s.run(context.Background(), cronTemplate("", "")); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.spawned) != 0 || store.updates["cron_last_run"] != "2026-03-05T04:00:00Z" {
+		t.Errorf("spawned %v, updates %v", store.spawned, store.updates)
+	}
+}
+
+func TestDueTick(t *testing.T) {
+	cron, err := schedule.ParseCron("*/10 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	if due, _ := dueTick(cron, last, last.Add(5*time.Minute)); !due.IsZero() {
+		t.Errorf("due = %s, want none", due)
+	}
+	due, missed := dueTick(cron, last, last.Add(35*time.Minute))
+	if !due.Equal(last.Add(30*time.Minute)) || missed != 2 {
+		t.Errorf("due = %s, missed = %d", due, missed)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/subscriber"
+)
+
+// deadLetterConfigKey is the daemon config key holding dead-lettered events.
+const deadLetterConfigKey = "controller:dead-letters"
+
+// deadLetter is a beads event the controller gave up on after retrying it.
+type deadLetter struct {
+	ID       string           `json:"id"`
+	Event    subscriber.Event `json:"event"`
+	Error    string           `json:"error"`
+	Attempts int              `json:"attempts"`
+	At       time.Time        `json:"at"`
+	// Redrives counts earlier re-drives of the same event that failed again.
+	Redrives int `json:"redrives,omitempty"`
+}
+
+// deadLetterStore persists dead letters so they survive controller
+// restarts and leader changes.
+type deadLetterStore interface {
+	// loadDeadLetters returns the stored dead letters, oldest first, or
+	// none if nothing has been stored yet.
+	loadDeadLetters(ctx context.Context) ([]deadLetter, error)
+	saveDeadLetters(ctx context.Context, letters []deadLetter) error
+}
+
+// deadLetters keeps the latest size events that failed every attempt, for
+// the admin API to list, inspect and re-drive once a fix is deployed. The
+// store is the source of truth: every operation reads it, so any replica
+// can serve the admin API. A nil store keeps dead letters in memory.
+type deadLetters struct {
+	mu      sync.Mutex
+	store   deadLetterStore
+	size    int
+	logger  *slog.Logger
+	now     func() time.Time
+	letters []deadLetter // in-memory letters when store is nil
+}
+
+func newDeadLetters(store deadLetterStore, size int, logger *slog.Logger) *deadLetters {
+	return &deadLetters{store: store, size: size, logger: logger, now: time.Now}
+}
+
+// load returns the current dead letters. Callers hold d.mu.
+func (d *deadLetters) load(ctx context.Context) ([]deadLetter, error) {
+	if d.store == nil {
+		return slices.Clone(d.letters), nil
+	}
+	return d.store.loadDeadLetters(ctx)
+}
+
+// save replaces the dead letters. Callers hold d.mu.
+func (d *deadLetters) save(ctx context.Context, letters []deadLetter) error {
+	if len(letters) > d.size {
+		letters = letters[len(letters)-d.size:]
+	}
+	if d.store == nil {
+		d.letters = letters
+		return nil
+	}
+	return d.store.saveDeadLetters(ctx, letters)
+}
+
+// add records event as dead after attempts failed with err. A re-driven
+// event that fails again replaces its earlier letter. Store failures are
+// logged; the event is then only in the log.
+func (d *deadLetters) add(ctx context.Context, event subscriber.Event, err error, attempts int) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	letters, lerr := d.load(ctx)
+	if lerr != nil {
+		d.logger.Error("failed to load dead letters, event lost", "type", event.Type,
+			"bead", eventBeadID(event), "event_id", event.ID, "error", lerr)
+		return
+	}
+	letter := deadLetter{ID: newDeadLetterID(), Event: event, Error: err.Error(), Attempts: attempts, At: d.now().UTC()}
+	kept := make([]deadLetter, 0, len(letters)+1)
+	for _, l := range letters {
+		if event.ID != "" && l.Event.ID == event.ID && l.Event.Type == event.Type {
+			letter.Redrives = l.Redrives + 1
+			continue
+		}
+		kept = append(kept, l)
+	}
+	if serr := d.save(ctx, append(kept, letter)); serr != nil {
+		d.logger.Error("failed to store dead letter, event lost", "type", event.Type,
+			"bead", eventBeadID(event), "event_id", event.ID, "error", serr)
+		return
+	}
+	d.logger.Warn("dead-lettered event", "id", letter.ID, "type", event.Type,
+		"bead", eventBeadID(event), "attempts", attempts, "error", err)
+}
+
+// list returns the dead letters, oldest first.
+func (d *deadLetters) list(ctx context.Context) ([]deadLetter, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.load(ctx)
+}
+
+// get returns the dead letter id.
+func (d *deadLetters) get(ctx context.Context, id string) (deadLetter, bool, error) {
+	letters, err := d.list(ctx)
+	if err != nil {
+		return deadLetter{}, false, err
+	}
+	for _, l := range letters {
+		if l.ID == id {
+			return l, true, nil
+		}
+	}
+	return deadLetter{}, false, nil
+}
+
+// remove deletes the dead letters ids, reporting how many there were.
+func (d *deadLetters) remove(ctx context.Context, ids ...string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	letters, err := d.load(ctx)
+	if err != nil {
+		return 0, err
+	}
+	drop := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		drop[id] = true
+	}
+	kept := make([]deadLetter, 0, len(letters))
+	for _, l := range letters {
+		if !drop[l.ID] {
+			kept = append(kept, l)
+		}
+	}
+	removed := len(letters) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, d.save(ctx, kept)
+}
+
+func newDeadLetterID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return "dl-" + hex.EncodeToString(b)
+}
+
+// daemonDeadLetterStore keeps dead letters in a daemon config entry, where
+// every controller replica can read them.
+type daemonDeadLetterStore struct {
+	configs interface {
+		GetConfig(ctx context.Context, key string) (*beadsapi.ConfigEntry, error)
+		SetConfig(ctx context.Context, key string, value []byte) error
+	}
+}
+
+func (s daemonDeadLetterStore) loadDeadLetters(ctx context.Context) ([]deadLetter, error) {
+	entry, err := s.configs.GetConfig(ctx, deadLetterConfigKey)
+	if err != nil {
+		var apiErr *beadsapi.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var letters []deadLetter
+	if err := json.Unmarshal(entry.Value, &letters); err != nil {
+		return nil, fmt.Errorf("decoding dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+func (s daemonDeadLetterStore) saveDeadLetters(ctx context.Context, letters []deadLetter) error {
+	value, err := json.Marshal(letters)
+	if err != nil {
+		return fmt.Errorf("encoding dead letters: %w", err)
+	}
+	return s.configs.SetConfig(ctx, deadLetterConfigKey, value)
+}
+
+// fileDeadLetterStore keeps dead letters in a local JSON file, for
+// controllers with a persistent volume.
+type fileDeadLetterStore struct {
+	path string
+}
+
+func (s fileDeadLetterStore) loadDeadLetters(context.Context) ([]deadLetter, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var letters []deadLetter
+	if err := json.Unmarshal(data, &letters); err != nil {
+		return nil, fmt.Errorf("decoding dead letters %s: %w", s.path, err)
+	}
+	return letters, nil
+}
+
+// saveDeadLetters replaces the file atomically so a crash mid-write leaves
+// the previous dead letters.
+func (s fileDeadLetterStore) saveDeadLetters(_ context.Context, letters []deadLetter) error {
+	data, err := json.Marshal(letters)
+	if err != nil {
+		return fmt.Errorf("encoding dead letters: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
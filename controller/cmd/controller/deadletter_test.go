@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/subscriber"
+)
+
+func TestEventQueue_DeadLettersAfterRetries(t *testing.T) {
+	q, err := newEventQueue(1, 4, overflowPark, quietLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := fileDeadLetterStore{path: filepath.Join(t.TempDir(), "dead-letters.json")}
+	dead := newDeadLetters(store, 10, quietLogger())
+	q.withRetries(3, time.Millisecond).withDeadLetters(dead)
+
+	var calls atomic.Int32
+	q.start(context.Background(), func(_ context.Context, ev subscriber.Event) error {
+		calls.Add(1)
+		if ev.BeadID == "broken" {
+			return errors.New("pod spec rejected")
+		}
+		return nil
+	})
+	ctx := context.Background()
+	broken := subscriber.Event{ID: "42", Type: subscriber.AgentSpawn, BeadID: "broken", AgentName: "builder"}
+	q.enqueue(ctx, broken)
+	q.enqueue(ctx, subscriber.Event{ID: "43", Type: subscriber.AgentSpawn, BeadID: "fine"})
+	q.stop()
+
+	if got := calls.Load(); got != 4 {
+		t.Errorf("handler calls = %d, want 3 attempts plus 1", got)
+	}
+	letters, err := newDeadLetters(store, 10, quietLogger()).list(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(letters) != 1 || letters[0].Event.ID != "42" || letters[0].Attempts != 3 || letters[0].Error != "pod spec rejected" {
+		t.Fatalf("stored dead letters = %+v", letters)
+	}
+
+	// The same event failing again after a re-drive replaces its letter.
+	dead.add(ctx, broken, errors.New("still rejected"), 3)
+	letters, _ = dead.list(ctx)
+	if len(letters) != 1 || letters[0].Redrives != 1 || letters[0].Error != "still rejected" {
+		t.Errorf("dead letters after a failed re-drive = %+v", letters)
+	}
+}
+
+func TestEventQueue_RedriveNeedsRunningWorkers(t *testing.T) {
+	q, err := newEventQueue(1, 1, overflowPark, quietLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.redrive(context.Background(), subscriber.Event{BeadID: "a"}); !errors.Is(err, errEventsNotRunning) {
+		t.Errorf("redrive on a stopped queue: err = %v", err)
+	}
+
+	q.start(context.Background(), func(context.Context, subscriber.Event) error { return nil })
+	q.stop()
+	if err := q.redrive(context.Background(), subscriber.Event{BeadID: "a"}); !errors.Is(err, errEventsNotRunning) {
+		t.Errorf("redrive after stop: err = %v", err)
+	}
+}
+
+// fakeRedriver records re-driven events, failing with err if set.
+type fakeRedriver struct {
+	err    error
+	events []subscriber.Event
+}
+
+func (f *fakeRedriver) redrive(_ context.Context, event subscriber.Event) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, event)
+	return nil
+}
+
+func TestAdminHandler_DeadLetters(t *testing.T) {
+	ctx := context.Background()
+	dead := newDeadLetters(nil, 10, quietLogger())
+	dead.add(ctx, subscriber.Event{ID: "1", Type: subscriber.AgentSpawn, BeadID: "kd-a", AgentName: "alpha"}, errors.New("boom"), 3)
+	dead.add(ctx, subscriber.Event{ID: "2", Type: subscriber.AgentStop, BeadID: "kd-b", AgentName: "beta"}, errors.New("boom"), 3)
+	letters, _ := dead.list(ctx)
+	alpha, beta := letters[0].ID, letters[1].ID
+
+	redriver := &fakeRedriver{err: errEventsNotRunning}
+	h := adminHandler(fake.NewSimpleClientset(), "gasboat", "s3cret", &fakeProjectStore{}, &fakeDiffer{},
+		make(syncTrigger, 1), dead, redriver, quietLogger())
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, adminRequest(method, path, "s3cret"))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/admin/dead-letters?agent=beta")
+	var listed struct {
+		DeadLetters []deadLetter `json:"dead_letters"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.DeadLetters) != 1 || listed.DeadLetters[0].ID != beta {
+		t.Errorf("filtered list = %+v", listed.DeadLetters)
+	}
+	if rec := serve(http.MethodGet, "/admin/dead-letters/"+alpha); rec.Code != http.StatusOK {
+		t.Errorf("inspect: expected 200, got %d", rec.Code)
+	}
+	if rec := serve(http.MethodGet, "/admin/dead-letters/dl-missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown letter: expected 404, got %d", rec.Code)
+	}
+
+	// Off the leader, nothing is re-driven or removed.
+	if rec := serve(http.MethodPost, "/admin/dead-letters/"+alpha+"/redrive"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("redrive off the leader: expected 503, got %d", rec.Code)
+	}
+	redriver.err = nil
+	if rec := serve(http.MethodPost, "/admin/dead-letters/"+alpha+"/redrive"); rec.Code != http.StatusOK {
+		t.Fatalf("redrive: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(redriver.events) != 1 || redriver.events[0].ID != "1" {
+		t.Errorf("re-driven events = %+v", redriver.events)
+	}
+	if rec := serve(http.MethodDelete, "/admin/dead-letters/"+beta); rec.Code != http.StatusOK {
+		t.Errorf("discard: expected 200, got %d", rec.Code)
+	}
+	if letters, _ := dead.list(ctx); len(letters) != 0 {
+		t.Errorf("dead letters left = %+v", letters)
+	}
+}
+
+func TestAdminHandler_DeadLettersDisabled(t *testing.T) {
+	h := newAdminTestHandler(fake.NewSimpleClientset(), &fakeProjectStore{}, &fakeDiffer{}, make(syncTrigger, 1))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, adminRequest(http.MethodGet, "/admin/dead-letters", "s3cret"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without dead-lettering, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/subscriber"
+)
+
+const (
+	// eventDedupBeads caps the beads whose recent events are remembered.
+	eventDedupBeads = 4096
+	// eventDedupIDs caps the event IDs remembered per bead.
+	eventDedupIDs = 16
+)
+
+// eventDedup remembers the events recently handled for each agent bead so
+// SSE replays and daemon retries of one are handled once. Beads are kept in
+// LRU order and forgotten ttl after their last handled event. A nil
+// *eventDedup suppresses nothing.
+type eventDedup struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	now   func() time.Time
+	lru   *list.List // of *dedupEntry, most recently handled first
+	beads map[string]*list.Element
+}
+
+// dedupEntry is what eventDedup remembers about one bead.
+type dedupEntry struct {
+	bead     string
+	at       time.Time
+	lastType subscriber.EventType
+	ids      []string // oldest first
+}
+
+// newEventDedup returns a dedup remembering events for ttl, or nil when
+// ttl is not positive.
+func newEventDedup(ttl time.Duration) *eventDedup {
+	if ttl <= 0 {
+		return nil
+	}
+	return &eventDedup{ttl: ttl, now: time.Now, lru: list.New(), beads: make(map[string]*list.Element)}
+}
+
+// duplicate reports whether event repeats one recently handled for its
+// bead, and why: it carries an event ID already handled (a replay), or it
+// is the same lifecycle transition as the bead's last one (a retry under a
+// new ID). A spawn suppressed wrongly is still caught by the reconciler.
+func (d *eventDedup) duplicate(event subscriber.Event) (string, bool) {
+	if d == nil || event.Type == subscriber.AgentUpdate {
+		return "", false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	el, ok := d.beads[eventBeadID(event)]
+	if !ok {
+		return "", false
+	}
+	e := el.Value.(*dedupEntry)
+	if d.now().Sub(e.at) > d.ttl {
+		d.lru.Remove(el)
+		delete(d.beads, e.bead)
+		return "", false
+	}
+	if event.ID != "" && slices.Contains(e.ids, event.ID) {
+		return "replayed event ID", true
+	}
+	if event.Type == e.lastType && event.Type != subscriber.AgentStuck {
+		return "repeated " + string(event.Type), true
+	}
+	return "", false
+}
+
+// record remembers that event was handled.
+func (d *eventDedup) record(event subscriber.Event) {
+	if d == nil || event.Type == subscriber.AgentUpdate {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	bead := eventBeadID(event)
+	var e *dedupEntry
+	if el, ok := d.beads[bead]; ok {
+		e = el.Value.(*dedupEntry)
+		d.lru.MoveToFront(el)
+	} else {
+		e = &dedupEntry{bead: bead}
+		d.beads[bead] = d.lru.PushFront(e)
+	}
+	e.at = d.now()
+	e.lastType = event.Type
+	if event.ID != "" {
+		e.ids = append(e.ids, event.ID)
+		if len(e.ids) > eventDedupIDs {
+			e.ids = e.ids[len(e.ids)-eventDedupIDs:]
+		}
+	}
+	for d.lru.Len() > eventDedupBeads {
+		oldest := d.lru.Back()
+		d.lru.Remove(oldest)
+		delete(d.beads, oldest.Value.(*dedupEntry).bead)
+	}
+}
+
+// createAgentPodOnce creates spec's pod. A live pod already running for
+// the same bead (left by an earlier spawn) counts as success; it reports
+// whether the pod was created by this call.
+func createAgentPodOnce(ctx context.Context, logger *slog.Logger, pods podmanager.Manager, spec podmanager.AgentPodSpec) (bool, error) {
+	err := pods.CreateAgentPod(ctx, spec)
+	if err == nil {
+		return true, nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return false, err
+	}
+	existing, getErr := pods.GetAgentPod(ctx, spec.PodName(), spec.Namespace)
+	if getErr != nil || existing.DeletionTimestamp != nil || isTerminalPhase(existing.Status.Phase) ||
+		existing.Annotations[podmanager.AnnotationBeadID] != spec.BeadID {
+		return false, err
+	}
+	logger.Info("agent pod already exists, skipping duplicate create", "pod", existing.Name, "bead", spec.BeadID)
+	return false, nil
+}
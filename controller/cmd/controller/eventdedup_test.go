@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/subscriber"
+)
+
+func TestEventDedup(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newEventDedup(10 * time.Minute)
+	d.now = func() time.Time { return now }
+
+	spawn := subscriber.Event{ID: "1", Type: subscriber.AgentSpawn, BeadID: "bd-1"}
+	if _, dup := d.duplicate(spawn); dup {
+		t.Fatal("first spawn reported as duplicate")
+	}
+	d.record(spawn)
+
+	if reason, dup := d.duplicate(spawn); !dup || reason != "replayed event ID" {
+		t.Errorf("replayed spawn: duplicate=%v reason=%q", dup, reason)
+	}
+	retry := subscriber.Event{ID: "2", Type: subscriber.AgentSpawn, BeadID: "bd-1"}
+	if _, dup := d.duplicate(retry); !dup {
+		t.Error("retried spawn under a new ID not reported as duplicate")
+	}
+	if _, dup := d.duplicate(subscriber.Event{ID: "3", Type: subscriber.AgentSpawn, BeadID: "bd-2"}); dup {
+		t.Error("another bead's spawn reported as duplicate")
+	}
+
+	// Updates are neither suppressed nor remembered; a stop after the
+	// spawn is a new transition, and so is the next spawn.
+	update := subscriber.Event{ID: "4", Type: subscriber.AgentUpdate, BeadID: "bd-1"}
+	d.record(update)
+	if _, dup := d.duplicate(update); dup {
+		t.Error("update reported as duplicate")
+	}
+	stop := subscriber.Event{ID: "5", Type: subscriber.AgentStop, BeadID: "bd-1"}
+	if _, dup := d.duplicate(stop); dup {
+		t.Fatal("stop after spawn reported as duplicate")
+	}
+	d.record(stop)
+	if _, dup := d.duplicate(subscriber.Event{ID: "6", Type: subscriber.AgentSpawn, BeadID: "bd-1"}); dup {
+		t.Error("respawn after stop reported as duplicate")
+	}
+
+	// Beads are forgotten after the TTL.
+	now = now.Add(11 * time.Minute)
+	if _, dup := d.duplicate(stop); dup {
+		t.Error("event reported as duplicate after the TTL")
+	}
+}
+
+func TestEventDedup_EvictsLeastRecentBead(t *testing.T) {
+	d := newEventDedup(time.Hour)
+	for i := range eventDedupBeads + 1 {
+		d.record(subscriber.Event{Type: subscriber.AgentSpawn, BeadID: fmt.Sprintf("bd-%d", i)})
+	}
+	if _, dup := d.duplicate(subscriber.Event{Type: subscriber.AgentSpawn, BeadID: "bd-0"}); dup {
+		t.Error("oldest bead still remembered past the cap")
+	}
+	if _, dup := d.duplicate(subscriber.Event{Type: subscriber.AgentSpawn, BeadID: fmt.Sprintf("bd-%d", eventDedupBeads)}); !dup {
+		t.Error("newest bead forgotten")
+	}
+	if newEventDedup(0) != nil {
+		t.Error("zero TTL should disable dedup")
+	}
+}
+
+func TestCreateAgentPodOnce(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	pods := podmanager.New(client, quietLogger())
+	spec := podmanager.AgentPodSpec{
+		Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha",
+		BeadID: "bd-1", Image: "img:v1", Namespace: "ns",
+	}
+
+	if created, err := createAgentPodOnce(ctx, quietLogger(), pods, spec); err != nil || !created {
+		t.Fatalf("first create: created=%v err=%v", created, err)
+	}
+	if created, err := createAgentPodOnce(ctx, quietLogger(), pods, spec); err != nil || created {
+		t.Errorf("duplicate create: created=%v err=%v, want skipped without error", created, err)
+	}
+
+	// A pod being deleted, or one for another bead, is still a conflict.
+	pod, err := client.CoreV1().Pods("ns").Get(ctx, spec.PodName(), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod.Status.Phase = corev1.PodFailed
+	if _, err := client.CoreV1().Pods("ns").Update(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createAgentPodOnce(ctx, quietLogger(), pods, spec); err == nil {
+		t.Error("create over a failed pod succeeded")
+	}
+	other := spec
+	other.BeadID = "bd-2"
+	pod.Status.Phase = corev1.PodRunning
+	if _, err := client.CoreV1().Pods("ns").Update(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createAgentPodOnce(ctx, quietLogger(), pods, other); err == nil {
+		t.Error("create over another bead's pod succeeded")
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gasboat/controller/internal/subscriber"
+)
+
+// Overflow behaviors of a saturated event queue (EVENT_QUEUE_OVERFLOW).
+const (
+	// overflowPark blocks the event loop until the agent's worker has
+	// room, pushing back on the SSE stream.
+	overflowPark = "park"
+	// overflowShed drops the event and requests a reconcile pass, which
+	// converges the agent's pod to its bead instead.
+	overflowShed = "shed"
+)
+
+// eventQueue handles beads events on a fixed pool of workers. Events are
+// sharded by bead ID, so each agent's events run in order on one worker
+// while different agents' run in parallel, and a slow K8s call only delays
+// the agents sharing its worker.
+type eventQueue struct {
+	shards   []chan subscriber.Event
+	overflow string
+	dedup    *eventDedup
+	attempts int
+	backoff  time.Duration
+	dead     *deadLetters
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+
+	// mu guards running, so redrive never sends on a shard stop closed.
+	mu      sync.RWMutex
+	running bool
+
+	busy       atomic.Int64
+	handled    atomic.Uint64
+	failed     atomic.Uint64
+	retried    atomic.Uint64
+	duplicates atomic.Uint64
+	shed       atomic.Uint64
+	parked     atomic.Uint64
+}
+
+// newEventQueue creates a queue of workers workers, each buffering up to
+// depth events.
+func newEventQueue(workers, depth int, overflow string, logger *slog.Logger) (*eventQueue, error) {
+	if workers < 1 || depth < 1 {
+		return nil, fmt.Errorf("event queue needs at least one worker and a depth of one (got %d workers, depth %d)", workers, depth)
+	}
+	if overflow != overflowPark && overflow != overflowShed {
+		return nil, fmt.Errorf("unknown event queue overflow behavior %q (want %q or %q)", overflow, overflowPark, overflowShed)
+	}
+	q := &eventQueue{overflow: overflow, attempts: 1, logger: logger}
+	for range workers {
+		q.shards = append(q.shards, make(chan subscriber.Event, depth))
+	}
+	return q, nil
+}
+
+// withDedup makes the workers skip events repeating one handled for the
+// same bead within ttl (see eventDedup). Zero disables suppression.
+func (q *eventQueue) withDedup(ttl time.Duration) *eventQueue {
+	q.dedup = newEventDedup(ttl)
+	return q
+}
+
+// withRetries makes the workers try a failing event up to attempts times,
+// waiting backoff before the first retry and doubling it for each next one.
+// The agent's later events wait meanwhile, so they keep their order.
+func (q *eventQueue) withRetries(attempts int, backoff time.Duration) *eventQueue {
+	q.attempts = max(attempts, 1)
+	q.backoff = backoff
+	return q
+}
+
+// withDeadLetters records events that failed every attempt in dead, for
+// the admin API to re-drive. Without it they are only logged.
+func (q *eventQueue) withDeadLetters(dead *deadLetters) *eventQueue {
+	q.dead = dead
+	return q
+}
+
+// start runs the workers, calling handle for each event. Events still
+// queued once ctx is done are dropped; startup reconciliation on the next
+// leader picks them up.
+func (q *eventQueue) start(ctx context.Context, handle func(context.Context, subscriber.Event) error) {
+	q.mu.Lock()
+	q.running = true
+	q.mu.Unlock()
+	for _, shard := range q.shards {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for event := range shard {
+				if ctx.Err() != nil {
+					continue
+				}
+				if reason, dup := q.dedup.duplicate(event); dup {
+					q.duplicates.Add(1)
+					q.logger.Info("suppressing duplicate event", "type", event.Type,
+						"bead", eventBeadID(event), "event_id", event.ID, "reason", reason)
+					continue
+				}
+				q.busy.Add(1)
+				attempts, err := q.handle(ctx, handle, event)
+				q.busy.Add(-1)
+				if err != nil {
+					q.failed.Add(1)
+					q.logger.Error("failed to handle event", "type", event.Type, "agent", event.AgentName,
+						"attempts", attempts, "error", err)
+					if ctx.Err() == nil {
+						q.dead.add(ctx, event, err, attempts)
+					}
+					continue
+				}
+				q.dedup.record(event)
+				q.handled.Add(1)
+			}
+		}()
+	}
+}
+
+// handle calls handle for event until it succeeds, the attempts run out
+// or ctx is done, returning the attempts made and the last error.
+func (q *eventQueue) handle(ctx context.Context, handle func(context.Context, subscriber.Event) error, event subscriber.Event) (int, error) {
+	wait := q.backoff
+	for attempt := 1; ; attempt++ {
+		err := handle(ctx, event)
+		if err == nil || attempt >= q.attempts {
+			return attempt, err
+		}
+		q.retried.Add(1)
+		q.logger.Warn("failed to handle event, retrying", "type", event.Type, "bead", eventBeadID(event),
+			"attempt", attempt, "retry_in", wait, "error", err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return attempt, err
+		}
+		wait *= 2
+	}
+}
+
+// redrive queues a dead-lettered event again. It fails unless this replica
+// runs the workers, i.e. is the leader, or when the agent's queue is full.
+func (q *eventQueue) redrive(ctx context.Context, event subscriber.Event) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if !q.running {
+		return errEventsNotRunning
+	}
+	select {
+	case q.shard(event) <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return fmt.Errorf("event queue of bead %s is full", eventBeadID(event))
+	}
+}
+
+// errEventsNotRunning is returned by redrive on replicas not handling
+// events.
+var errEventsNotRunning = errors.New("event workers are not running on this replica (not the leader?)")
+
+// stop waits for the workers to finish the queued events. No event may be
+// enqueued after stop; redrive fails once stop has begun.
+func (q *eventQueue) stop() {
+	q.mu.Lock()
+	q.running = false
+	for _, shard := range q.shards {
+		close(shard)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+// enqueue hands event to its agent's worker. When that worker's queue is
+// full the event is parked or shed; enqueue reports false when it was not
+// queued (shed, or ctx done while parked).
+func (q *eventQueue) enqueue(ctx context.Context, event subscriber.Event) bool {
+	key := eventBeadID(event)
+	shard := q.shard(event)
+
+	select {
+	case shard <- event:
+		return true
+	default:
+	}
+	if q.overflow == overflowShed {
+		q.shed.Add(1)
+		q.logger.Warn("event queue full, shedding event to the next reconcile pass",
+			"type", event.Type, "bead", key)
+		return false
+	}
+	q.parked.Add(1)
+	q.logger.Warn("event queue full, parking until the agent's worker catches up",
+		"type", event.Type, "bead", key)
+	select {
+	case shard <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// shard returns the queue of the worker handling event's bead.
+func (q *eventQueue) shard(event subscriber.Event) chan subscriber.Event {
+	h := fnv.New32a()
+	h.Write([]byte(eventBeadID(event)))
+	return q.shards[h.Sum32()%uint32(len(q.shards))]
+}
+
+// depth returns the number of queued events not yet picked up by a worker.
+func (q *eventQueue) depth() int {
+	n := 0
+	for _, shard := range q.shards {
+		n += len(shard)
+	}
+	return n
+}
+
+// metricsHandler serves the queue's metrics in the Prometheus text
+// exposition format.
+func (q *eventQueue) metricsHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	gauge := func(name, help string, v int64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", name, help, name, name, v)
+	}
+	gauge("gasboat_controller_event_queue_depth", "Beads events waiting for a worker.", int64(q.depth()))
+	gauge("gasboat_controller_event_queue_capacity", "Beads events the queue can hold before parking or shedding.",
+		int64(len(q.shards)*cap(q.shards[0])))
+	gauge("gasboat_controller_event_workers", "Event workers.", int64(len(q.shards)))
+	gauge("gasboat_controller_event_workers_busy", "Event workers handling an event.", q.busy.Load())
+
+	fmt.Fprintf(w, "# HELP gasboat_controller_events_total Beads events by result (handled, failed, duplicate or shed).\n")
+	fmt.Fprintf(w, "# TYPE gasboat_controller_events_total counter\n")
+	fmt.Fprintf(w, "gasboat_controller_events_total{result=\"handled\"} %d\n", q.handled.Load())
+	fmt.Fprintf(w, "gasboat_controller_events_total{result=\"failed\"} %d\n", q.failed.Load())
+	fmt.Fprintf(w, "gasboat_controller_events_total{result=\"duplicate\"} %d\n", q.duplicates.Load())
+	fmt.Fprintf(w, "gasboat_controller_events_total{result=\"shed\"} %d\n", q.shed.Load())
+	fmt.Fprintf(w, "# HELP gasboat_controller_event_retries_total Retries of beads events that failed.\n")
+	fmt.Fprintf(w, "# TYPE gasboat_controller_event_retries_total counter\n")
+	fmt.Fprintf(w, "gasboat_controller_event_retries_total %d\n", q.retried.Load())
+	fmt.Fprintf(w, "# HELP gasboat_controller_events_parked_total Beads events that waited for room in a full queue.\n")
+	fmt.Fprintf(w, "# TYPE gasboat_controller_events_parked_total counter\n")
+	fmt.Fprintf(w, "gasboat_controller_events_parked_total %d\n", q.parked.Load())
+}
+
+// eventBeadID returns the agent bead ID of event, constructing it from the
+// labels for older daemon versions that don't send it.
+func eventBeadID(event subscriber.Event) string {
+	if event.BeadID != "" {
+		return event.BeadID
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", event.Mode, event.Project, event.Role, event.AgentName)
+}
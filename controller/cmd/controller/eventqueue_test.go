@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/subscriber"
+)
+
+func quietLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestEventQueue_OrdersPerAgentAndRunsAgentsInParallel(t *testing.T) {
+	q, err := newEventQueue(4, 16, overflowPark, quietLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	seen := map[string][]subscriber.EventType{}
+	release := make(chan struct{})
+	q.start(context.Background(), func(_ context.Context, ev subscriber.Event) error {
+		if ev.BeadID == "slow" {
+			<-release
+		}
+		mu.Lock()
+		seen[ev.BeadID] = append(seen[ev.BeadID], ev.Type)
+		mu.Unlock()
+		return nil
+	})
+
+	ctx := context.Background()
+	q.enqueue(ctx, subscriber.Event{Type: subscriber.AgentSpawn, BeadID: "slow"})
+	for i := range 8 {
+		id := fmt.Sprintf("fast-%d", i)
+		q.enqueue(ctx, subscriber.Event{Type: subscriber.AgentSpawn, BeadID: id})
+		q.enqueue(ctx, subscriber.Event{Type: subscriber.AgentStop, BeadID: id})
+	}
+
+	// Agents on other workers finish while "slow" is still blocked.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		done := 0
+		for id, types := range seen {
+			if strings.HasPrefix(id, "fast-") && len(types) == 2 {
+				done++
+			}
+		}
+		mu.Unlock()
+		if done > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("no agent handled while another agent's event was slow")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(release)
+	q.stop()
+	for id, types := range seen {
+		if id == "slow" {
+			continue
+		}
+		if len(types) != 2 || types[0] != subscriber.AgentSpawn || types[1] != subscriber.AgentStop {
+			t.Errorf("%s: events handled as %v, want spawn then stop", id, types)
+		}
+	}
+	if got := q.handled.Load(); got != 17 {
+		t.Errorf("handled = %d, want 17", got)
+	}
+}
+
+func TestEventQueue_ShedsWhenFull(t *testing.T) {
+	q, err := newEventQueue(1, 1, overflowShed, quietLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	q.start(context.Background(), func(context.Context, subscriber.Event) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	})
+	ctx := context.Background()
+
+	if !q.enqueue(ctx, subscriber.Event{BeadID: "a"}) {
+		t.Fatal("first event shed")
+	}
+	<-started // the worker holds the first event
+	if !q.enqueue(ctx, subscriber.Event{BeadID: "a"}) {
+		t.Fatal("second event shed with room in the queue")
+	}
+	if q.enqueue(ctx, subscriber.Event{BeadID: "a"}) {
+		t.Fatal("event queued past the queue's depth")
+	}
+	if got := q.shed.Load(); got != 1 {
+		t.Errorf("shed = %d, want 1", got)
+	}
+
+	w := httptest.NewRecorder()
+	q.metricsHandler(w, httptest.NewRequest("GET", "/metrics", nil))
+	for _, want := range []string{
+		"gasboat_controller_event_queue_depth 1\n",
+		"gasboat_controller_event_workers_busy 1\n",
+		`gasboat_controller_events_total{result="shed"} 1` + "\n",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, w.Body.String())
+		}
+	}
+
+	close(release)
+	q.stop()
+}
+
+func TestEventQueue_ParksWhenFull(t *testing.T) {
+	q, err := newEventQueue(1, 1, overflowPark, quietLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	q.start(context.Background(), func(context.Context, subscriber.Event) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	})
+
+	q.enqueue(context.Background(), subscriber.Event{BeadID: "a"})
+	<-started
+	q.enqueue(context.Background(), subscriber.Event{BeadID: "a"})
+
+	// A third event waits for room; giving up when the context ends.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if q.enqueue(ctx, subscriber.Event{BeadID: "a"}) {
+		t.Fatal("parked event queued without room")
+	}
+	if got := q.parked.Load(); got != 1 {
+		t.Errorf("parked = %d, want 1", got)
+	}
+
+	// Once the worker catches up a parked event gets in.
+	queued := make(chan bool)
+	go func() { queued <- q.enqueue(context.Background(), subscriber.Event{BeadID: "a"}) }()
+	close(release)
+	if !<-queued {
+		t.Error("parked event dropped after the worker caught up")
+	}
+	q.stop()
+	if got := q.handled.Load(); got != 3 {
+		t.Errorf("handled = %d, want 3", got)
+	}
+}
+
+func TestNewEventQueue_RejectsBadConfig(t *testing.T) {
+	for _, tc := range []struct {
+		workers, depth int
+		overflow       string
+	}{
+		{0, 1, overflowPark},
+		{1, 0, overflowPark},
+		{1, 1, "drop"},
+	} {
+		if _, err := newEventQueue(tc.workers, tc.depth, tc.overflow, quietLogger()); err == nil {
+			t.Errorf("newEventQueue(%d, %d, %q): expected an error", tc.workers, tc.depth, tc.overflow)
+		}
+	}
+}
+
+func TestEventQueue_SuppressesDuplicates(t *testing.T) {
+	q, err := newEventQueue(2, 8, overflowPark, quietLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.withDedup(time.Minute)
+	var calls int
+	q.start(context.Background(), func(context.Context, subscriber.Event) error {
+		calls++
+		return nil
+	})
+	spawn := subscriber.Event{ID: "7", Type: subscriber.AgentSpawn, BeadID: "bd-1"}
+	q.enqueue(context.Background(), spawn)
+	q.enqueue(context.Background(), spawn)
+	q.stop()
+
+	if calls != 1 || q.duplicates.Load() != 1 {
+		t.Errorf("handled %d times with %d duplicates, want 1 and 1", calls, q.duplicates.Load())
+	}
+}
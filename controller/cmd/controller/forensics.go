@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+const (
+	// forensicsLogLines is how much of each container log a bundle holds.
+	forensicsLogLines = 200
+	// forensicsDecisions is how many of the agent's latest decisions a
+	// bundle lists.
+	forensicsDecisions = 10
+	// forensicsMaxBytes keeps a bundle under the bridge's artifact upload
+	// limit.
+	forensicsMaxBytes = 900 << 10
+)
+
+// crashLoopLabel marks the open incident bead of a crash-looping agent.
+func crashLoopLabel(beadID string) string {
+	return "crashloop:" + beadID
+}
+
+// forensicsStore reads agent history and files incidents (beadsapi.Client).
+type forensicsStore interface {
+	GetBead(ctx context.Context, beadID string) (*beadsapi.BeadDetail, error)
+	GetComments(ctx context.Context, beadID string) ([]beadsapi.Comment, error)
+	ListBeadsFiltered(ctx context.Context, q beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error)
+	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// crashForensics files an incident bead when an agent crash-loops: its
+// container restarts, or its pod fails and is recreated, restarts times
+// within window. The incident carries one forensic bundle — pod state,
+// events, the last logs of the crashed and current container, the agent
+// bead with its comments and the agent's recent decisions — as its
+// artifact, which the bridge uploads to Slack. One incident is filed per
+// agent until it is closed. Only home-cluster pods are inspected.
+// A nil *crashForensics collects nothing.
+type crashForensics struct {
+	client    kubernetes.Interface
+	namespace string
+	store     forensicsStore
+	restarts  int
+	window    time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+
+	failed  map[string]time.Time   // failed pod UID → when it was first seen
+	crashes map[string][]time.Time // agent bead ID → its failed pods' times
+}
+
+func newCrashForensics(client kubernetes.Interface, namespace string, store forensicsStore, restarts int, window time.Duration, logger *slog.Logger) *crashForensics {
+	return &crashForensics{
+		client:    client,
+		namespace: namespace,
+		store:     store,
+		restarts:  restarts,
+		window:    window,
+		logger:    logger,
+		now:       time.Now,
+		failed:    make(map[string]time.Time),
+		crashes:   make(map[string][]time.Time),
+	}
+}
+
+// sweep looks for crash-looping agents and files an incident for each one
+// that has none open. It runs before the reconciler replaces failed pods,
+// so their logs can still be read.
+func (f *crashForensics) sweep(ctx context.Context) error {
+	if f == nil {
+		return nil
+	}
+	pods, err := f.client.CoreV1().Pods(f.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: podmanager.LabelAgent,
+	})
+	if err != nil {
+		return fmt.Errorf("listing agent pods: %w", err)
+	}
+	now := f.now()
+	f.prune(now, pods.Items)
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		beadID := pod.Annotations[podmanager.AnnotationBeadID]
+		if beadID == "" {
+			continue
+		}
+		if pod.Status.Phase == corev1.PodFailed {
+			if _, seen := f.failed[string(pod.UID)]; !seen {
+				f.failed[string(pod.UID)] = now
+				f.crashes[beadID] = append(f.crashes[beadID], now)
+			}
+		}
+		if !crashLooping(pod) {
+			continue
+		}
+		crashes := len(f.crashes[beadID]) + int(containerRestarts(pod))
+		if crashes < f.restarts {
+			continue
+		}
+		if err := f.fileIncident(ctx, pod, beadID, crashes); err != nil {
+			f.logger.Warn("failed to file crash-loop incident", "pod", pod.Name, "bead", beadID, "error", err)
+		}
+	}
+	return nil
+}
+
+// prune forgets failed pods that are gone and crashes outside the window.
+func (f *crashForensics) prune(now time.Time, pods []corev1.Pod) {
+	present := make(map[string]bool, len(pods))
+	for _, p := range pods {
+		present[string(p.UID)] = true
+	}
+	for uid := range f.failed {
+		if !present[uid] {
+			delete(f.failed, uid)
+		}
+	}
+	for beadID, times := range f.crashes {
+		kept := times[:0]
+		for _, t := range times {
+			if now.Sub(t) <= f.window {
+				kept = append(kept, t)
+			}
+		}
+		if len(kept) == 0 {
+			delete(f.crashes, beadID)
+		} else {
+			f.crashes[beadID] = kept
+		}
+	}
+}
+
+// crashLooping reports whether pod failed or its agent container is
+// waiting out a crash-loop back-off.
+func crashLooping(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == podmanager.ContainerName && cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+	}
+	return false
+}
+
+// containerRestarts is how often pod's agent container was restarted.
+func containerRestarts(pod *corev1.Pod) int32 {
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name == podmanager.ContainerName {
+			return cs.RestartCount
+		}
+	}
+	return 0
+}
+
+// fileIncident creates the incident bead for the agent of beadID unless
+// one is open, and attaches the bundle.
+func (f *crashForensics) fileIncident(ctx context.Context, pod *corev1.Pod, beadID string, crashes int) error {
+	open, err := f.store.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{
+		Statuses: []string{"open", "in_progress", "blocked", "deferred"},
+		Labels:   []string{crashLoopLabel(beadID)},
+		Limit:    1,
+	})
+	if err != nil {
+		return fmt.Errorf("looking up open incident: %w", err)
+	}
+	if len(open.Beads) > 0 {
+		return nil
+	}
+
+	agent := pod.Labels[podmanager.LabelAgent]
+	project := pod.Labels[podmanager.LabelProject]
+	bundle := f.bundle(ctx, pod, beadID, crashes)
+	labels := []string{"incident", crashLoopLabel(beadID), "agent:" + agent}
+	if project != "" {
+		labels = append(labels, "project:"+project)
+	}
+	id, err := f.store.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title: fmt.Sprintf("Agent %s is crash-looping (%d crashes)", agent, crashes),
+		Type:  "bug",
+		Kind:  "issue",
+		Description: fmt.Sprintf("Pod %s of agent %s (%s) crashed %d times within %s. "+
+			"The forensic bundle (pod state, events, logs, bead history, recent decisions) is in this bead's artifact field.",
+			pod.Name, agent, beadID, crashes, f.window),
+		Labels:    labels,
+		Priority:  1,
+		CreatedBy: "controller",
+	})
+	if err != nil {
+		return fmt.Errorf("creating incident: %w", err)
+	}
+	// Attached by an update, not at creation: the bridge uploads artifacts
+	// from bead updates.
+	if err := f.store.UpdateBeadFields(ctx, id, map[string]string{
+		"artifact":      bundle,
+		"artifact_name": "forensics-" + pod.Name + ".md",
+		"artifact_type": "markdown",
+		"agent_bead":    beadID,
+		"pod":           pod.Name,
+	}); err != nil {
+		return fmt.Errorf("attaching bundle to incident %s: %w", id, err)
+	}
+	f.logger.Warn("agent crash-looping, filed incident with forensic bundle",
+		"agent", agent, "pod", pod.Name, "bead", beadID, "crashes", crashes, "incident", id)
+	return nil
+}
+
+// bundle renders everything known about the crashing agent as markdown.
+// Parts that cannot be read are noted instead of failing the bundle.
+func (f *crashForensics) bundle(ctx context.Context, pod *corev1.Pod, beadID string, crashes int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Crash-loop forensics: %s\n\n", pod.Labels[podmanager.LabelAgent])
+	fmt.Fprintf(&b, "Pod `%s`, agent bead `%s`, %d crashes, collected %s.\n\n",
+		pod.Name, beadID, crashes, f.now().UTC().Format(time.RFC3339))
+
+	b.WriteString("## Pod\n\n```\n" + describePod(pod) + "```\n\n")
+
+	b.WriteString("## Events\n\n```\n")
+	b.WriteString(f.podEvents(ctx, pod))
+	b.WriteString("```\n\n")
+
+	if containerRestarts(pod) > 0 {
+		b.WriteString("## Logs of the crashed container\n\n```\n")
+		b.WriteString(f.podLogs(ctx, pod, true))
+		b.WriteString("```\n\n")
+	}
+	b.WriteString("## Logs\n\n```\n")
+	b.WriteString(f.podLogs(ctx, pod, false))
+	b.WriteString("```\n\n")
+
+	b.WriteString("## Agent bead\n\n")
+	b.WriteString(f.beadHistory(ctx, beadID))
+
+	b.WriteString("## Recent decisions\n\n")
+	b.WriteString(f.recentDecisions(ctx, pod.Labels[podmanager.LabelAgent]))
+
+	out := b.String()
+	if len(out) > forensicsMaxBytes {
+		out = out[:forensicsMaxBytes] + "\n\n… bundle truncated\n"
+	}
+	return out
+}
+
+// describePod summarizes pod's status like kubectl describe.
+func describePod(pod *corev1.Pod) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Name:       %s\n", pod.Name)
+	fmt.Fprintf(&b, "Namespace:  %s\n", pod.Namespace)
+	fmt.Fprintf(&b, "Node:       %s\n", orNone(pod.Spec.NodeName))
+	fmt.Fprintf(&b, "Created:    %s\n", pod.CreationTimestamp.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Phase:      %s\n", pod.Status.Phase)
+	if pod.Status.Reason != "" || pod.Status.Message != "" {
+		fmt.Fprintf(&b, "Reason:     %s %s\n", pod.Status.Reason, pod.Status.Message)
+	}
+	for _, c := range pod.Status.Conditions {
+		fmt.Fprintf(&b, "Condition:  %s=%s %s\n", c.Type, c.Status, c.Reason)
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		fmt.Fprintf(&b, "Container %s:\n", cs.Name)
+		fmt.Fprintf(&b, "  Image:     %s\n", cs.Image)
+		fmt.Fprintf(&b, "  State:     %s\n", containerState(cs.State))
+		fmt.Fprintf(&b, "  Last:      %s\n", containerState(cs.LastTerminationState))
+		fmt.Fprintf(&b, "  Ready:     %v\n", cs.Ready)
+		fmt.Fprintf(&b, "  Restarts:  %d\n", cs.RestartCount)
+	}
+	return b.String()
+}
+
+func containerState(s corev1.ContainerState) string {
+	switch {
+	case s.Running != nil:
+		return "Running since " + s.Running.StartedAt.UTC().Format(time.RFC3339)
+	case s.Waiting != nil:
+		return strings.TrimSpace("Waiting " + s.Waiting.Reason + " " + s.Waiting.Message)
+	case s.Terminated != nil:
+		t := s.Terminated
+		return strings.TrimSpace(fmt.Sprintf("Terminated %s (exit %d) at %s %s",
+			t.Reason, t.ExitCode, t.FinishedAt.UTC().Format(time.RFC3339), t.Message))
+	}
+	return "<none>"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// podEvents lists the events regarding pod, oldest first.
+func (f *crashForensics) podEvents(ctx context.Context, pod *corev1.Pod) string {
+	events, err := f.client.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.name=" + pod.Name,
+	})
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v\n", err)
+	}
+	var items []corev1.Event
+	for _, e := range events.Items {
+		if e.InvolvedObject.Name == pod.Name {
+			items = append(items, e)
+		}
+	}
+	if len(items) == 0 {
+		return "none\n"
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return eventTime(items[i]).Before(eventTime(items[j]))
+	})
+	var b strings.Builder
+	for _, e := range items {
+		fmt.Fprintf(&b, "%s  %-7s  %-20s  x%d  %s\n",
+			eventTime(e).UTC().Format(time.RFC3339), e.Type, e.Reason, max(e.Count, 1), e.Message)
+	}
+	return b.String()
+}
+
+func eventTime(e corev1.Event) time.Time {
+	if !e.LastTimestamp.IsZero() {
+		return e.LastTimestamp.Time
+	}
+	if !e.EventTime.IsZero() {
+		return e.EventTime.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+// podLogs returns the tail of the agent container's log, or of the
+// container instance before the last restart when previous is set.
+func (f *crashForensics) podLogs(ctx context.Context, pod *corev1.Pod, previous bool) string {
+	lines := int64(forensicsLogLines)
+	raw, err := f.client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: podmanager.ContainerName,
+		TailLines: &lines,
+		Previous:  previous,
+	}).DoRaw(ctx)
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v\n", err)
+	}
+	if len(raw) == 0 {
+		return "empty\n"
+	}
+	out := string(raw)
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return strings.ReplaceAll(out, "```", "'''")
+}
+
+// beadHistory renders the agent bead's state and comments.
+func (f *crashForensics) beadHistory(ctx context.Context, beadID string) string {
+	bead, err := f.store.GetBead(ctx, beadID)
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v\n\n", err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "`%s` %s — status %s, created %s, updated %s\n\n", bead.ID, bead.Title, bead.Status,
+		bead.CreatedAt.UTC().Format(time.RFC3339), bead.UpdatedAt.UTC().Format(time.RFC3339))
+	keys := make([]string, 0, len(bead.Fields))
+	for k := range bead.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "- %s: `%s`\n", k, truncateField(bead.Fields[k]))
+	}
+	if bead.Notes != "" {
+		b.WriteString("\nNotes:\n\n```\n" + bead.Notes + "\n```\n")
+	}
+	b.WriteString("\n")
+
+	comments, err := f.store.GetComments(ctx, beadID)
+	if err != nil {
+		fmt.Fprintf(&b, "Comments unavailable: %v\n\n", err)
+		return b.String()
+	}
+	if len(comments) > 0 {
+		b.WriteString("### Comments\n\n")
+		for _, c := range comments {
+			fmt.Fprintf(&b, "- %s %s: %s\n", c.CreatedAt.UTC().Format(time.RFC3339), c.Author, c.Text)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// recentDecisions lists the latest decisions the agent asked for.
+func (f *crashForensics) recentDecisions(ctx context.Context, agent string) string {
+	res, err := f.store.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{
+		Types:    []string{"decision"},
+		Statuses: []string{"open", "in_progress", "closed"},
+		Assignee: agent,
+		Sort:     "-created_at",
+		Limit:    forensicsDecisions,
+	})
+	if err != nil {
+		return fmt.Sprintf("unavailable: %v\n", err)
+	}
+	if len(res.Beads) == 0 {
+		return "none\n"
+	}
+	var b strings.Builder
+	for _, d := range res.Beads {
+		fmt.Fprintf(&b, "- `%s` %s — %s, %s", d.ID, d.Title, d.Status, d.CreatedAt.UTC().Format(time.RFC3339))
+		if chosen := d.Fields["chosen"]; chosen != "" {
+			fmt.Fprintf(&b, ", chose %s", chosen)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// truncateField shortens long field values (e.g. JSON blobs) in a bundle.
+func truncateField(v string) string {
+	const limit = 200
+	v = strings.ReplaceAll(v, "`", "'")
+	if len(v) > limit {
+		return v[:limit] + "…"
+	}
+	return v
+}
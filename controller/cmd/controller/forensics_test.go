@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+// fakeForensicsStore serves one agent bead and its decisions, recording
+// the incidents filed.
+type fakeForensicsStore struct {
+	agent     *beadsapi.BeadDetail
+	decisions []*beadsapi.BeadDetail
+	incidents []beadsapi.CreateBeadRequest
+	fields    map[string]map[string]string
+}
+
+func (f *fakeForensicsStore) GetBead(context.Context, string) (*beadsapi.BeadDetail, error) {
+	return f.agent, nil
+}
+
+func (f *fakeForensicsStore) GetComments(context.Context, string) ([]beadsapi.Comment, error) {
+	return []beadsapi.Comment{{Author: "alice", Text: "restarted it by hand"}}, nil
+}
+
+func (f *fakeForensicsStore) ListBeadsFiltered(_ context.Context, q beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error) {
+	if len(q.Types) > 0 && q.Types[0] == "decision" {
+		return &beadsapi.ListBeadsResult{Beads: f.decisions}, nil
+	}
+	// Open incidents: every one filed so far.
+	var open []*beadsapi.BeadDetail
+	for _, req := range f.incidents {
+		open = append(open, &beadsapi.BeadDetail{Labels: req.Labels})
+	}
+	return &beadsapi.ListBeadsResult{Beads: open}, nil
+}
+
+func (f *fakeForensicsStore) CreateBead(_ context.Context, req beadsapi.CreateBeadRequest) (string, error) {
+	f.incidents = append(f.incidents, req)
+	return "kd-incident", nil
+}
+
+func (f *fakeForensicsStore) UpdateBeadFields(_ context.Context, id string, fields map[string]string) error {
+	if f.fields == nil {
+		f.fields = make(map[string]map[string]string)
+	}
+	f.fields[id] = fields
+	return nil
+}
+
+func crashingPod(name, uid string, phase corev1.PodPhase, restarts int32, waiting string) *corev1.Pod {
+	cs := corev1.ContainerStatus{Name: podmanager.ContainerName, RestartCount: restarts}
+	if waiting != "" {
+		cs.State.Waiting = &corev1.ContainerStateWaiting{Reason: waiting}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "gasboat",
+			UID:         types.UID(uid),
+			Labels:      map[string]string{podmanager.LabelAgent: "builder", podmanager.LabelProject: "api"},
+			Annotations: map[string]string{podmanager.AnnotationBeadID: "kd-builder"},
+		},
+		Status: corev1.PodStatus{Phase: phase, ContainerStatuses: []corev1.ContainerStatus{cs}},
+	}
+}
+
+func TestCrashForensics_CrashLoopBackOffFilesOneIncident(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		crashingPod("builder-0", "uid-0", corev1.PodRunning, 4, "CrashLoopBackOff"),
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "ev-1", Namespace: "gasboat"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "builder-0"},
+			Type:           "Warning",
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+		},
+	)
+	store := &fakeForensicsStore{
+		agent: &beadsapi.BeadDetail{ID: "kd-builder", Title: "builder", Status: "open",
+			Fields: map[string]string{"agent_state": "working"}},
+		decisions: []*beadsapi.BeadDetail{{ID: "kd-dec", Title: "Ship it?", Status: "closed",
+			Fields: map[string]string{"chosen": "yes"}}},
+	}
+	f := newCrashForensics(client, "gasboat", store, 3, time.Hour, slog.Default())
+
+	for range 2 {
+		if err := f.sweep(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(store.incidents) != 1 {
+		t.Fatalf("incidents = %d, want one while it is open", len(store.incidents))
+	}
+	inc := store.incidents[0]
+	if inc.Type != "bug" || !strings.Contains(inc.Title, "builder") ||
+		!slices.Contains(inc.Labels, crashLoopLabel("kd-builder")) || !slices.Contains(inc.Labels, "project:api") {
+		t.Errorf("incident = %+v", inc)
+	}
+
+	fields := store.fields["kd-incident"]
+	if fields["artifact_type"] != "markdown" || fields["artifact_name"] != "forensics-builder-0.md" {
+		t.Errorf("artifact fields = %v", fields)
+	}
+	bundle := fields["artifact"]
+	for _, want := range []string{
+		"Restarts:  4",
+		"Back-off restarting failed container",
+		"fake logs",
+		"agent_state: `working`",
+		"restarted it by hand",
+		"`kd-dec` Ship it?",
+		"chose yes",
+	} {
+		if !strings.Contains(bundle, want) {
+			t.Errorf("bundle lacks %q:\n%s", want, bundle)
+		}
+	}
+}
+
+func TestCrashForensics_CountsFailedPodsWithinWindow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	client := fake.NewSimpleClientset()
+	store := &fakeForensicsStore{agent: &beadsapi.BeadDetail{ID: "kd-builder"}}
+	f := newCrashForensics(client, "gasboat", store, 3, time.Hour, slog.Default())
+	f.now = func() time.Time { return now }
+
+	// A failed pod is replaced by the reconciler between passes.
+	fail := func(uid string) {
+		t.Helper()
+		pods := client.CoreV1().Pods("gasboat")
+		_ = pods.Delete(ctx, "builder-0", metav1.DeleteOptions{})
+		if _, err := pods.Create(ctx, crashingPod("builder-0", uid, corev1.PodFailed, 0, ""), metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.sweep(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fail("uid-1")
+	now = now.Add(2 * time.Hour) // the first failure leaves the window
+	fail("uid-2")
+	fail("uid-2") // the same pod is counted once
+	fail("uid-3")
+	if len(store.incidents) != 0 {
+		t.Fatalf("incident filed after %d failures in the window", len(f.crashes["kd-builder"]))
+	}
+	fail("uid-4")
+	if len(store.incidents) != 1 || !strings.Contains(store.incidents[0].Title, "3 crashes") {
+		t.Fatalf("incidents = %+v, want one for 3 crashes", store.incidents)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/configreconciler"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/statusreporter"
+	"gasboat/controller/internal/subscriber"
+)
+
+// handleEvent translates a beads lifecycle event into K8s pod operations.
+func handleEvent(ctx context.Context, logger *slog.Logger, cfg *config.Config, event subscriber.Event, pods podmanager.Manager, status statusreporter.Reporter, warm *warmPool, grace *terminationGrace, cfgRec *configreconciler.Reconciler) error {
+	logger.Info("handling beads event",
+		"type", event.Type, "project", event.Project, "role", event.Role,
+		"agent", event.AgentName, "bead", event.BeadID)
+
+	agentBeadID := eventBeadID(event)
+
+	// Fill in the fields of the agent's template, as the reconciler does.
+	metadata, resolved := beadsapi.ResolveTemplate(event.Metadata, cfg.TemplateCache.Load())
+	event.Metadata = metadata
+
+	switch event.Type {
+	case subscriber.AgentSpawn:
+		if !resolved {
+			logger.Info("agent template not found, leaving agent to the reconciler",
+				"template", event.Metadata[beadsapi.TemplateField], "agent", event.AgentName)
+			return nil
+		}
+		project := cfg.ProjectCache.Load()[event.Project]
+		if state := project.Onboarding; !beadsapi.OnboardingAllowsAgents(state) {
+			logger.Info("project not onboarded, leaving agent to the reconciler",
+				"project", event.Project, "onboarding", state, "agent", event.AgentName)
+			return nil
+		}
+		if project.BudgetPaused {
+			logger.Info("project over its daily budget, leaving agent to the reconciler",
+				"project", event.Project, "agent", event.AgentName)
+			return nil
+		}
+		spec := buildAgentPodSpec(cfg, event)
+		ensureAgentConfig(ctx, logger, cfgRec, event, agentBeadID, &spec)
+		podName := warm.adopt(ctx, spec)
+		if podName == "" {
+			created, err := createAgentPodOnce(ctx, logger, pods, spec)
+			if err != nil {
+				return err
+			}
+			if !created {
+				return nil // already running; SyncAll reports its status
+			}
+			podName = spec.PodName()
+		}
+		// Backend metadata (coop_url) is written by SyncAll once the pod has an IP.
+		// We skip writing it here because the pod IP isn't available at creation time.
+		// Report spawning status to beads.
+		_ = status.ReportPodStatus(ctx, agentBeadID, statusreporter.PodStatus{
+			PodName:   podName,
+			Namespace: spec.Namespace,
+			Phase:     string("Pending"),
+			Ready:     false,
+		})
+		status.RecordAgentEvent(ctx, statusreporter.AgentEvent{
+			PodName:   podName,
+			Namespace: spec.Namespace,
+			BeadID:    agentBeadID,
+			Reason:    statusreporter.ReasonAgentSpawned,
+			Note:      "agent spawned",
+		})
+		return nil
+
+	case subscriber.AgentDone, subscriber.AgentKill, subscriber.AgentStop:
+		ns := namespaceFromEvent(event, cfg.Namespace)
+		podName := warm.podName(ctx, pods, ns, event)
+		finish := func(ctx context.Context) error {
+			return deleteFinishedAgent(ctx, logger, pods, status, event.Type, podName, ns, agentBeadID)
+		}
+		// Done and stopped agents get a grace window to checkpoint (e.g.
+		// finish a git push) before their pod goes; a kill deletes at once.
+		if event.Type != subscriber.AgentKill && grace.begin(ctx, podName, ns, agentBeadID, func(ctx context.Context) {
+			if err := finish(ctx); err != nil {
+				logger.Warn("failed to delete agent pod after its grace window", "pod", podName, "error", err)
+			}
+		}) {
+			return nil
+		}
+		return finish(ctx)
+
+	case subscriber.AgentStuck:
+		// Delete and recreate the pod to restart the agent.
+		ns := namespaceFromEvent(event, cfg.Namespace)
+		podName := warm.podName(ctx, pods, ns, event)
+		if err := pods.DeleteAgentPod(ctx, podName, ns); err != nil {
+			logger.Warn("failed to delete stuck pod (may not exist)", "pod", podName, "error", err)
+		}
+		if cfg.ImageVerification() {
+			// Leave the replacement to the reconciler, which verifies its
+			// image before creating it.
+			status.RecordAgentEvent(ctx, statusreporter.AgentEvent{
+				PodName:   podName,
+				Namespace: ns,
+				BeadID:    agentBeadID,
+				Reason:    statusreporter.ReasonAgentRestarted,
+				Note:      "deleted due to stuck detection; reconciler recreates it",
+			})
+			return nil
+		}
+		spec := buildAgentPodSpec(cfg, event)
+		ensureAgentConfig(ctx, logger, cfgRec, event, agentBeadID, &spec)
+		if err := pods.CreateAgentPod(ctx, spec); err != nil {
+			return err
+		}
+		// Report restarting status.
+		_ = status.ReportPodStatus(ctx, agentBeadID, statusreporter.PodStatus{
+			PodName:   spec.PodName(),
+			Namespace: spec.Namespace,
+			Phase:     string("Pending"),
+			Ready:     false,
+			Message:   "restarted due to stuck detection",
+		})
+		status.RecordAgentEvent(ctx, statusreporter.AgentEvent{
+			PodName:   spec.PodName(),
+			Namespace: spec.Namespace,
+			BeadID:    agentBeadID,
+			Reason:    statusreporter.ReasonAgentRestarted,
+			Note:      "restarted due to stuck detection",
+		})
+		return nil
+
+	case subscriber.AgentUpdate:
+		// Metadata updates are handled by the reconciler during periodic sync.
+		// No immediate pod action needed.
+		return nil
+
+	default:
+		logger.Warn("unknown event type", "type", event.Type)
+		return nil
+	}
+}
+
+// deleteFinishedAgent deletes the pod of an agent that is done, stopped or
+// killed, and reports the agent's final state.
+func deleteFinishedAgent(ctx context.Context, logger *slog.Logger, pods podmanager.Manager, status statusreporter.Reporter, eventType subscriber.EventType, podName, ns, agentBeadID string) error {
+	err := pods.DeleteAgentPod(ctx, podName, ns)
+	if apierrors.IsNotFound(err) {
+		logger.Info("agent pod already deleted", "pod", podName)
+		err = nil
+	}
+	// Clear backend metadata so stale Coop URLs don't linger.
+	_ = status.ReportBackendMetadata(ctx, agentBeadID, statusreporter.BackendMetadata{})
+	// Report done status to beads regardless of delete error.
+	phase := "Succeeded"
+	if eventType == subscriber.AgentKill {
+		phase = "Failed"
+	}
+	if eventType == subscriber.AgentStop {
+		phase = "Stopped"
+	}
+	_ = status.ReportPodStatus(ctx, agentBeadID, statusreporter.PodStatus{
+		PodName:   podName,
+		Namespace: ns,
+		Phase:     phase,
+		Ready:     false,
+	})
+	return err
+}
+
+// ensureAgentConfig renders the agent's ConfigMap from the event's bead and
+// points spec at it. On failure the pod starts without it; the next sync
+// pass renders it.
+func ensureAgentConfig(ctx context.Context, logger *slog.Logger, cfgRec *configreconciler.Reconciler, event subscriber.Event, agentBeadID string, spec *podmanager.AgentPodSpec) {
+	if cfgRec == nil {
+		return
+	}
+	bead := beadsapi.AgentBead{
+		ID:        agentBeadID,
+		Project:   event.Project,
+		Mode:      event.Mode,
+		Role:      event.Role,
+		AgentName: event.AgentName,
+		Metadata:  event.Metadata,
+	}
+	if err := cfgRec.Ensure(ctx, bead); err != nil {
+		logger.Warn("failed to render agent ConfigMap", "agent", event.AgentName, "error", err)
+	}
+	cfgRec.Apply(spec)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/subscriber"
+)
+
+func TestHandleEvent_StuckLeavesVerifiedRecreateToReconciler(t *testing.T) {
+	ctx := context.Background()
+	for _, tt := range []struct {
+		name     string
+		keys     string
+		wantPods int
+	}{
+		{"recreates without verification", "", 1},
+		{"deletes only with verification", "cosign.pub", 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			pods := podmanager.New(client, slog.Default())
+			cfg := warmTestConfig()
+			cfg.ImageVerifyKeys = tt.keys
+			stuck := subscriber.Event{Type: subscriber.AgentStuck, Project: "gasboat", Mode: "crew", Role: "crew",
+				AgentName: "k8s", BeadID: "bd-k8s"}
+			if err := pods.CreateAgentPod(ctx, buildAgentPodSpec(cfg, stuck)); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := handleEvent(ctx, slog.Default(), cfg, stuck, pods, &recordingReporter{}, nil, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if n := len(listPods(t, client)); n != tt.wantPods {
+				t.Errorf("pods after stuck event = %d, want %d", n, tt.wantPods)
+			}
+		})
+	}
+}
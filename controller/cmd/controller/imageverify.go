@@ -0,0 +1,26 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/imagesig"
+)
+
+// newImageVerifier builds the cosign verifier from the IMAGE_VERIFY_* settings.
+func newImageVerifier(cfg *config.Config, logger *slog.Logger) (*imagesig.Verifier, error) {
+	var identities []imagesig.Identity
+	if cfg.ImageVerifyIdentities != "" {
+		if err := json.Unmarshal([]byte(cfg.ImageVerifyIdentities), &identities); err != nil {
+			return nil, fmt.Errorf("parsing IMAGE_VERIFY_IDENTITIES: %w", err)
+		}
+	}
+	return imagesig.New(imagesig.Config{
+		Keys:       []byte(cfg.ImageVerifyKeys),
+		Identities: identities,
+		Roots:      []byte(cfg.ImageVerifyRoots),
+		RekorKey:   []byte(cfg.ImageVerifyRekorKey),
+	}, logger)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+)
+
+const (
+	// taskIngestMaxBody caps request bodies.
+	taskIngestMaxBody = 256 << 10
+	// taskIngestMaxSkew bounds how old (or early) a signed request may be,
+	// so captured requests can't be replayed later.
+	taskIngestMaxSkew = 5 * time.Minute
+)
+
+// taskCreator is the subset of beadsapi.Client used by the ingest endpoint.
+type taskCreator interface {
+	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
+}
+
+// taskIngestRequest is the JSON body of POST /ingest/task.
+type taskIngestRequest struct {
+	Project     string   `json:"project"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Priority    *int     `json:"priority"` // 0 (critical) – 4 (lowest); default 2
+	Labels      []string `json:"labels"`
+	Assignee    string   `json:"assignee"`
+	Source      string   `json:"source"` // calling tool, recorded as source:<source>; default "ingest"
+}
+
+// validate checks the request against the known projects and fills defaults.
+func (req *taskIngestRequest) validate(projects map[string]config.ProjectCacheEntry) error {
+	req.Project = strings.TrimSpace(req.Project)
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		return fmt.Errorf("title is required")
+	}
+	if req.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+	if _, ok := projects[req.Project]; !ok {
+		return fmt.Errorf("unknown project %q", req.Project)
+	}
+	if req.Priority == nil {
+		p := 2
+		req.Priority = &p
+	}
+	if *req.Priority < 0 || *req.Priority > 4 {
+		return fmt.Errorf("priority must be between 0 and 4")
+	}
+	if req.Source == "" {
+		req.Source = "ingest"
+	}
+	return nil
+}
+
+// validIngestSignature checks X-Gasboat-Signature ("sha256=<hex>"), an
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the ingest key, where the
+// timestamp is the X-Gasboat-Timestamp header (Unix seconds) and must be
+// within taskIngestMaxSkew of now.
+func validIngestSignature(key []byte, timestamp, signature string, body []byte, now time.Time) bool {
+	if len(key) == 0 {
+		return false
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if skew := now.Sub(time.Unix(ts, 0)); skew > taskIngestMaxSkew || skew < -taskIngestMaxSkew {
+		return false
+	}
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// taskIngestHandler serves POST /ingest/task: a signed JSON request
+// describing work becomes a task bead in the named project, so internal
+// tools can dispatch work to agents without a dedicated bridge. Responds
+// 201 with {"id": "<bead id>"}.
+func taskIngestHandler(daemon taskCreator, cfg *config.Config, logger *slog.Logger) http.HandlerFunc {
+	key := []byte(cfg.TaskIngestKey)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, taskIngestMaxBody))
+		if err != nil {
+			http.Error(w, "read body", http.StatusBadRequest)
+			return
+		}
+		if !validIngestSignature(key, r.Header.Get("X-Gasboat-Timestamp"), r.Header.Get("X-Gasboat-Signature"), body, time.Now()) {
+			logger.Warn("rejected task ingest request with invalid signature", "remote", r.RemoteAddr)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		var req taskIngestRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := req.validate(cfg.ProjectCache.Load()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		labels := append([]string{"project:" + req.Project, "source:" + req.Source}, req.Labels...)
+		id, err := daemon.CreateBead(r.Context(), beadsapi.CreateBeadRequest{
+			Title:       req.Title,
+			Type:        "task",
+			Description: req.Description,
+			Assignee:    req.Assignee,
+			Labels:      labels,
+			Priority:    *req.Priority,
+			CreatedBy:   "ingest:" + req.Source,
+		})
+		if err != nil {
+			logger.Error("task ingest: failed to create bead", "project", req.Project, "source", req.Source, "error", err)
+			http.Error(w, "create bead failed", http.StatusBadGateway)
+			return
+		}
+		logger.Info("task ingested", "bead", id, "project", req.Project, "source", req.Source, "title", req.Title)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	}
+}
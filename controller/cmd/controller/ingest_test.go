@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+)
+
+type fakeTaskCreator struct {
+	reqs []beadsapi.CreateBeadRequest
+	err  error
+}
+
+func (f *fakeTaskCreator) CreateBead(_ context.Context, req beadsapi.CreateBeadRequest) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.reqs = append(f.reqs, req)
+	return fmt.Sprintf("bd-%d", len(f.reqs)), nil
+}
+
+func signedIngestRequest(key, body string, ts time.Time) *http.Request {
+	stamp := strconv.FormatInt(ts.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(stamp + "." + body))
+	req := httptest.NewRequest(http.MethodPost, "/ingest/task", strings.NewReader(body))
+	req.Header.Set("X-Gasboat-Timestamp", stamp)
+	req.Header.Set("X-Gasboat-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func newIngestTestHandler(daemon taskCreator) http.HandlerFunc {
+	cfg := &config.Config{
+		TaskIngestKey: "k3y",
+		ProjectCache:  config.NewSnapshot(map[string]config.ProjectCacheEntry{"gasboat": {}}),
+	}
+	return taskIngestHandler(daemon, cfg, slog.Default())
+}
+
+func TestTaskIngest_CreatesTaskBead(t *testing.T) {
+	daemon := &fakeTaskCreator{}
+	h := newIngestTestHandler(daemon)
+	body := `{"project":"gasboat","title":"Rotate staging certs","description":"expires Friday","priority":1,"labels":["ops"],"source":"certbot"}`
+
+	rec := httptest.NewRecorder()
+	h(rec, signedIngestRequest("k3y", body, time.Now()))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp map[string]string
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp["id"] != "bd-1" {
+		t.Errorf("response = %v, want id bd-1", resp)
+	}
+
+	req := daemon.reqs[0]
+	if req.Type != "task" || req.Priority != 1 || req.CreatedBy != "ingest:certbot" || req.Description != "expires Friday" {
+		t.Errorf("unexpected bead request: %+v", req)
+	}
+	if strings.Join(req.Labels, ",") != "project:gasboat,source:certbot,ops" {
+		t.Errorf("labels = %v", req.Labels)
+	}
+}
+
+func TestTaskIngest_DefaultsPriority(t *testing.T) {
+	daemon := &fakeTaskCreator{}
+	rec := httptest.NewRecorder()
+	newIngestTestHandler(daemon)(rec, signedIngestRequest("k3y", `{"project":"gasboat","title":"x"}`, time.Now()))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if daemon.reqs[0].Priority != 2 || daemon.reqs[0].Labels[1] != "source:ingest" {
+		t.Errorf("unexpected defaults: %+v", daemon.reqs[0])
+	}
+}
+
+func TestTaskIngest_RejectsBadRequests(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"wrong key", signedIngestRequest("nope", `{"project":"gasboat","title":"x"}`, time.Now()), http.StatusUnauthorized},
+		{"stale timestamp", signedIngestRequest("k3y", `{"project":"gasboat","title":"x"}`, time.Now().Add(-10*time.Minute)), http.StatusUnauthorized},
+		{"unsigned", httptest.NewRequest(http.MethodPost, "/ingest/task", strings.NewReader(`{}`)), http.StatusUnauthorized},
+		{"bad json", signedIngestRequest("k3y", `{`, time.Now()), http.StatusBadRequest},
+		{"unknown project", signedIngestRequest("k3y", `{"project":"other","title":"x"}`, time.Now()), http.StatusUnprocessableEntity},
+		{"missing title", signedIngestRequest("k3y", `{"project":"gasboat"}`, time.Now()), http.StatusUnprocessableEntity},
+		{"bad priority", signedIngestRequest("k3y", `{"project":"gasboat","title":"x","priority":7}`, time.Now()), http.StatusUnprocessableEntity},
+		{"wrong method", httptest.NewRequest(http.MethodGet, "/ingest/task", nil), http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			daemon := &fakeTaskCreator{}
+			rec := httptest.NewRecorder()
+			newIngestTestHandler(daemon)(rec, tt.req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+			if len(daemon.reqs) != 0 {
+				t.Errorf("created %d beads, want none", len(daemon.reqs))
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+//go:build integration
+
+package main
+
+// Integration tests run the controller binary against a real API server, so
+// admission and defaulting behave as in production. They use the cluster of
+// INTEGRATION_KUBECONFIG when set; otherwise they create a kind cluster
+// (reused if it already exists, kept if INTEGRATION_KEEP_CLUSTER is set).
+//
+//	go test -tags integration ./cmd/controller/ -run Integration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/fakedaemon"
+	"gasboat/controller/internal/podmanager"
+)
+
+const kindClusterName = "gasboat-integration"
+
+var (
+	clusterOnce       sync.Once
+	clusterKubeconfig string
+	clusterErr        error
+	controllerBin     string
+	controllerBinErr  error
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	teardownKindCluster()
+	os.Exit(code)
+}
+
+// kindCreated is set when this run created the kind cluster.
+var kindCreated bool
+
+// integrationKubeconfig returns the kubeconfig of the test cluster, skipping
+// the test when none is available.
+func integrationKubeconfig(t *testing.T) string {
+	t.Helper()
+	clusterOnce.Do(func() {
+		if kc := os.Getenv("INTEGRATION_KUBECONFIG"); kc != "" {
+			clusterKubeconfig = kc
+			return
+		}
+		if _, err := exec.LookPath("kind"); err != nil {
+			clusterErr = fmt.Errorf("neither INTEGRATION_KUBECONFIG nor kind is available")
+			return
+		}
+		dir, err := os.MkdirTemp("", "gasboat-integration")
+		if err != nil {
+			clusterErr = err
+			return
+		}
+		clusterKubeconfig = filepath.Join(dir, "kubeconfig")
+		out, err := exec.Command("kind", "get", "clusters").Output()
+		if err != nil {
+			clusterErr = fmt.Errorf("kind get clusters: %w", err)
+			return
+		}
+		if slices.Contains(strings.Fields(string(out)), kindClusterName) {
+			clusterErr = runCommand("kind", "export", "kubeconfig", "--name", kindClusterName, "--kubeconfig", clusterKubeconfig)
+			return
+		}
+		clusterErr = runCommand("kind", "create", "cluster", "--name", kindClusterName,
+			"--kubeconfig", clusterKubeconfig, "--wait", "120s")
+		kindCreated = clusterErr == nil
+	})
+	if clusterErr != nil {
+		t.Skipf("no integration cluster: %v", clusterErr)
+	}
+	return clusterKubeconfig
+}
+
+func teardownKindCluster() {
+	if !kindCreated || os.Getenv("INTEGRATION_KEEP_CLUSTER") != "" {
+		return
+	}
+	if err := runCommand("kind", "delete", "cluster", "--name", kindClusterName); err != nil {
+		fmt.Fprintf(os.Stderr, "deleting kind cluster: %v\n", err)
+	}
+}
+
+func runCommand(name string, args ...string) error {
+	out, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w\n%s", name, strings.Join(args, " "), err, out)
+	}
+	return nil
+}
+
+// buildController compiles the controller binary once per test run.
+func buildController(t *testing.T) string {
+	t.Helper()
+	if controllerBin == "" && controllerBinErr == nil {
+		dir, err := os.MkdirTemp("", "gasboat-controller")
+		if err != nil {
+			t.Fatal(err)
+		}
+		controllerBin = filepath.Join(dir, "controller")
+		controllerBinErr = runCommand("go", "build", "-o", controllerBin, ".")
+	}
+	if controllerBinErr != nil {
+		t.Fatalf("building controller: %v", controllerBinErr)
+	}
+	return controllerBin
+}
+
+// cluster runs the controller binary against a fake beads daemon and a
+// fresh namespace of the integration cluster.
+type cluster struct {
+	t         *testing.T
+	ctx       context.Context
+	daemon    *fakedaemon.Daemon
+	client    *beadsapi.Client
+	k8s       kubernetes.Interface
+	namespace string
+}
+
+func newCluster(t *testing.T) *cluster {
+	t.Helper()
+	kubeconfig := integrationKubeconfig(t)
+	bin := buildController(t)
+	k8s, err := buildK8sClient(kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ns := fmt.Sprintf("gasboat-it-%d", time.Now().UnixNano()%1_000_000)
+	if _, err := k8s.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: ns},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("creating namespace: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = k8s.CoreV1().Namespaces().Delete(context.Background(), ns, metav1.DeleteOptions{})
+	})
+
+	d := fakedaemon.New()
+	srv := httptest.NewServer(d)
+	t.Cleanup(srv.Close)
+	client, err := beadsapi.New(beadsapi.Config{HTTPAddr: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin)
+	cmd.Env = append(os.Environ(),
+		"KUBECONFIG="+kubeconfig,
+		"NAMESPACE="+ns,
+		"BEADS_HTTP_ADDR="+srv.URL,
+		"HEALTH_LISTEN_ADDR=127.0.0.1:0",
+		"COOP_IMAGE="+envOrDefault("INTEGRATION_AGENT_IMAGE", "busybox:1.36"),
+	)
+	cmd.Stdout, cmd.Stderr = &logs, &logs
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("starting controller: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		_ = cmd.Wait()
+		if t.Failed() {
+			t.Logf("controller logs:\n%s", logs.String())
+		}
+	})
+
+	return &cluster{t: t, ctx: ctx, daemon: d, client: client, k8s: k8s, namespace: ns}
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// eventually polls cond until it holds, failing the test after 60s; a real
+// API server is slower than the fake clientset.
+func (c *cluster) eventually(what string, cond func() bool) {
+	c.t.Helper()
+	deadline := time.Now().Add(60 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			c.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+func (c *cluster) pod(name string) (*corev1.Pod, bool) {
+	pod, err := c.k8s.CoreV1().Pods(c.namespace).Get(c.ctx, name, metav1.GetOptions{})
+	return pod, err == nil
+}
+
+func TestIntegration_SpawnCreatesLabeledPodAndPVC(t *testing.T) {
+	c := newCluster(t)
+	const podName = "crew-gasboat-crew-furiosa"
+
+	id, err := c.client.SpawnAgent(c.ctx, "furiosa", "gasboat", "", "crew")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pod *corev1.Pod
+	c.eventually("pod created on spawn", func() bool {
+		var ok bool
+		pod, ok = c.pod(podName)
+		return ok
+	})
+
+	for label, want := range map[string]string{
+		podmanager.LabelApp:     podmanager.LabelAppValue,
+		podmanager.LabelProject: "gasboat",
+		podmanager.LabelRole:    "crew",
+		podmanager.LabelAgent:   "furiosa",
+		podmanager.LabelMode:    "crew",
+	} {
+		if got := pod.Labels[label]; got != want {
+			t.Errorf("label %s = %q, want %q", label, got, want)
+		}
+	}
+	if got := pod.Annotations[podmanager.AnnotationBeadID]; got != id {
+		t.Errorf("bead-id annotation = %q, want %q", got, id)
+	}
+	// Set by the API server's ServiceAccount admission and defaulting, not
+	// by the controller.
+	if pod.Spec.ServiceAccountName == "" || pod.Spec.DNSPolicy == "" || pod.Spec.SchedulerName == "" {
+		t.Errorf("pod was not defaulted: serviceAccount=%q dnsPolicy=%q scheduler=%q",
+			pod.Spec.ServiceAccountName, pod.Spec.DNSPolicy, pod.Spec.SchedulerName)
+	}
+
+	const claim = podName + "-workspace"
+	pvc, err := c.k8s.CoreV1().PersistentVolumeClaims(c.namespace).Get(c.ctx, claim, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("workspace PVC: %v", err)
+	}
+	if got := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; got.String() != "10Gi" {
+		t.Errorf("PVC size = %s, want 10Gi", got.String())
+	}
+	if pvc.Labels[podmanager.LabelAgent] != "furiosa" {
+		t.Errorf("PVC labels = %v", pvc.Labels)
+	}
+	mounted := slices.ContainsFunc(pod.Spec.Volumes, func(v corev1.Volume) bool {
+		return v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == claim
+	})
+	if !mounted {
+		t.Errorf("pod volumes %v do not reference PVC %s", pod.Spec.Volumes, claim)
+	}
+
+	if err := c.client.CloseBead(c.ctx, id, nil); err != nil {
+		t.Fatal(err)
+	}
+	c.eventually("pod deleted on close", func() bool {
+		_, err := c.k8s.CoreV1().Pods(c.namespace).Get(c.ctx, podName, metav1.GetOptions{})
+		return apierrors.IsNotFound(err)
+	})
+}
+
+func TestIntegration_RespawnReusesPVC(t *testing.T) {
+	c := newCluster(t)
+	const podName = "crew-gasboat-crew-nux"
+
+	id, err := c.client.SpawnAgent(c.ctx, "nux", "gasboat", "", "crew")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first *corev1.Pod
+	c.eventually("pod created on spawn", func() bool {
+		var ok bool
+		first, ok = c.pod(podName)
+		return ok
+	})
+	pvc, err := c.k8s.CoreV1().PersistentVolumeClaims(c.namespace).Get(c.ctx, podName+"-workspace", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("workspace PVC: %v", err)
+	}
+
+	// A restart replaces the pod; the existing claim must be reused rather
+	// than failing creation with AlreadyExists.
+	if err := c.client.UpdateBeadFields(c.ctx, id, map[string]string{
+		"restart_requested": time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	c.eventually("pod replaced", func() bool {
+		p, ok := c.pod(podName)
+		return ok && p.UID != first.UID
+	})
+	again, err := c.k8s.CoreV1().PersistentVolumeClaims(c.namespace).Get(c.ctx, podName+"-workspace", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("workspace PVC after restart: %v", err)
+	}
+	if again.UID != pvc.UID {
+		t.Error("restart recreated the workspace PVC")
+	}
+}
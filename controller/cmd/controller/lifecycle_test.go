@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/fakedaemon"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/reconciler"
+	"gasboat/controller/internal/specbuilder"
+	"gasboat/controller/internal/statusreporter"
+	"gasboat/controller/internal/subscriber"
+)
+
+// harness runs the controller's event loop and sync loop against a fake
+// beads daemon and a fake K8s clientset.
+type harness struct {
+	t       *testing.T
+	ctx     context.Context
+	daemon  *fakedaemon.Daemon
+	client  *beadsapi.Client
+	k8s     *fake.Clientset
+	syncNow syncTrigger
+}
+
+const harnessNamespace = "gasboat"
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	d := fakedaemon.New()
+	srv := httptest.NewServer(d)
+	t.Cleanup(srv.Close)
+	client, err := beadsapi.New(beadsapi.Config{HTTPAddr: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Namespace:        harnessNamespace,
+		CoopSyncInterval: time.Hour, // passes run only when the test asks
+		ProjectCache:     config.NewSnapshot(make(map[string]config.ProjectCacheEntry)),
+		TemplateCache:    config.NewSnapshot[map[string]beadsapi.TemplateInfo](nil),
+		RoleCache:        config.NewSnapshot[map[string]config.RoleCacheEntry](nil),
+	}
+	k8s := fake.NewSimpleClientset()
+	watcher := subscriber.NewSSEWatcher(subscriber.SSEConfig{
+		BeadsHTTPAddr: srv.URL,
+		Topics:        "beads.bead.*",
+		Namespace:     cfg.Namespace,
+	}, logger)
+	pods := podmanager.New(k8s, logger)
+	status := statusreporter.NewHTTPReporter(client, k8s, cfg.Namespace, logger)
+	rec := reconciler.New(client, pods, cfg, logger, specbuilder.FromBead)
+	syncNow := make(syncTrigger, 1)
+	events, err := newEventQueue(4, 16, overflowPark, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, logger, cfg, k8s, watcher, pods, status, rec, client, nil, nil, nil, nil, syncNow, nil, nil, nil, newProjectCache(client, logger), events)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		// run may also return through the watcher, which reports the cancel.
+		if err := <-done; err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("run: %v", err)
+		}
+	})
+
+	h := &harness{t: t, ctx: ctx, daemon: d, client: client, k8s: k8s, syncNow: syncNow}
+	h.eventually("watcher connected", func() bool {
+		ok, _ := watcher.Connected()
+		return ok
+	})
+	return h
+}
+
+// eventually polls cond until it holds, failing the test after 10s.
+func (h *harness) eventually(what string, cond func() bool) {
+	h.t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// sync runs one periodic sync pass (status sync and reconcile) and waits
+// for it to finish.
+func (h *harness) sync() {
+	h.t.Helper()
+	reply := make(chan error, 1)
+	h.syncNow <- reply
+	if err := <-reply; err != nil {
+		h.t.Fatalf("sync pass: %v", err)
+	}
+}
+
+func (h *harness) spawn(agent string) string {
+	h.t.Helper()
+	id, err := h.client.SpawnAgent(h.ctx, agent, "gasboat", "", "crew")
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	return id
+}
+
+func (h *harness) pod(name string) (*corev1.Pod, bool) {
+	pod, err := h.k8s.CoreV1().Pods(harnessNamespace).Get(h.ctx, name, metav1.GetOptions{})
+	return pod, err == nil
+}
+
+// setPodPhase stands in for the kubelet.
+func (h *harness) setPodPhase(name string, phase corev1.PodPhase) {
+	h.t.Helper()
+	pod, ok := h.pod(name)
+	if !ok {
+		h.t.Fatalf("pod %s not found", name)
+	}
+	pod.Status.Phase = phase
+	if _, err := h.k8s.CoreV1().Pods(harnessNamespace).UpdateStatus(h.ctx, pod, metav1.UpdateOptions{}); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+func (h *harness) agentState(id string) string {
+	b, _ := h.daemon.Bead(id)
+	return b.Field("agent_state")
+}
+
+func TestLifecycle_SpawnWorkDone(t *testing.T) {
+	h := newHarness(t)
+	const pod = "crew-gasboat-crew-furiosa"
+
+	id := h.spawn("furiosa")
+	h.eventually("pod created on spawn", func() bool { _, ok := h.pod(pod); return ok })
+	h.eventually("agent spawning", func() bool { return h.agentState(id) == "spawning" })
+
+	h.setPodPhase(pod, corev1.PodRunning)
+	h.sync()
+	if got := h.agentState(id); got != "working" {
+		t.Fatalf("agent_state after pod running = %q, want working", got)
+	}
+
+	if err := h.client.CloseBead(h.ctx, id, nil); err != nil {
+		t.Fatal(err)
+	}
+	h.eventually("pod deleted on close", func() bool { _, ok := h.pod(pod); return !ok })
+	h.eventually("agent done", func() bool { return h.agentState(id) == "done" })
+
+	// A closed bead is not desired: reconcile must not bring the pod back.
+	h.sync()
+	if _, ok := h.pod(pod); ok {
+		t.Error("reconcile recreated the pod of a closed agent")
+	}
+}
+
+func TestLifecycle_StuckRestart(t *testing.T) {
+	h := newHarness(t)
+	const pod = "crew-gasboat-crew-nux"
+
+	id := h.spawn("nux")
+	h.eventually("pod created on spawn", func() bool { _, ok := h.pod(pod); return ok })
+	h.setPodPhase(pod, corev1.PodRunning)
+	h.sync()
+
+	// gb agent restart sets restart_requested; the controller replaces the pod.
+	if err := h.client.UpdateBeadFields(h.ctx, id, map[string]string{
+		"restart_requested": time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	h.eventually("pod replaced", func() bool {
+		p, ok := h.pod(pod)
+		return ok && p.Status.Phase != corev1.PodRunning
+	})
+	h.eventually("agent spawning again", func() bool { return h.agentState(id) == "spawning" })
+}
+
+func TestLifecycle_FailedPodRecreatedByReconcile(t *testing.T) {
+	h := newHarness(t)
+	const pod = "crew-gasboat-crew-slit"
+
+	id := h.spawn("slit")
+	h.eventually("pod created on spawn", func() bool { _, ok := h.pod(pod); return ok })
+	h.setPodPhase(pod, corev1.PodFailed)
+
+	h.sync()
+	if got := h.agentState(id); got != "failed" {
+		t.Errorf("agent_state after pod failed = %q, want failed", got)
+	}
+	p, ok := h.pod(pod)
+	if !ok || p.Status.Phase == corev1.PodFailed {
+		t.Fatalf("expected failed pod recreated by reconcile, got exists=%v", ok)
+	}
+}
+
+func TestLifecycle_MissedEventsReplayedOnReconnect(t *testing.T) {
+	h := newHarness(t)
+	const pod = "crew-gasboat-crew-capable"
+
+	id := h.spawn("capable")
+	h.eventually("pod created on spawn", func() bool { _, ok := h.pod(pod); return ok })
+
+	// The close happens while the stream is down; the watcher gets it on
+	// reconnect via Last-Event-ID, without waiting for a sync pass.
+	h.daemon.Disconnect()
+	if err := h.client.CloseBead(h.ctx, id, nil); err != nil {
+		t.Fatal(err)
+	}
+	h.eventually("pod deleted after reconnect", func() bool { _, ok := h.pod(pod); return !ok })
+}
@@ -17,9 +17,12 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -27,14 +30,20 @@ import (
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 
-	"k8s.io/client-go/dynamic"
-
 	"gasboat/controller/internal/beadsapi"
 	"gasboat/controller/internal/bridge"
 	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/configreconciler"
+	"gasboat/controller/internal/cooptoken"
+	"gasboat/controller/internal/errorreporter"
+	"gasboat/controller/internal/faults"
 	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/policy"
+	"gasboat/controller/internal/prestop"
+	"gasboat/controller/internal/rbacreconciler"
 	"gasboat/controller/internal/reconciler"
 	"gasboat/controller/internal/secretreconciler"
+	"gasboat/controller/internal/specbuilder"
 	"gasboat/controller/internal/statusreporter"
 	"gasboat/controller/internal/subscriber"
 )
@@ -52,61 +61,226 @@ func main() {
 		"beads_http", cfg.BeadsHTTPAddr,
 		"namespace", cfg.Namespace)
 
-	k8sClient, err := buildK8sClient(cfg.KubeConfig)
-	if err != nil {
-		logger.Error("failed to create K8s client", "error", err)
-		os.Exit(1)
+	// Fault injection for chaos testing in staging (FAULT_INJECTION=true).
+	var chaos *faults.Injector
+	daemonCfg := beadsapi.Config{HTTPAddr: cfg.BeadsHTTPAddr}
+	if cfg.FaultInjection {
+		chaos = faults.New(faults.Config{
+			K8sErrorRate:    cfg.FaultK8sErrorRate,
+			DaemonDelayRate: cfg.FaultDaemonDelayRate,
+			DaemonDelay:     cfg.FaultDaemonDelay,
+			SSEDropRate:     cfg.FaultSSEDropRate,
+			Seed:            uint64(cfg.FaultSeed),
+		}, logger)
+		daemonCfg.Transport = chaos.Transport(nil)
+		logger.Warn("FAULT INJECTION ENABLED — do not run in production",
+			"k8s_error_rate", cfg.FaultK8sErrorRate,
+			"daemon_delay_rate", cfg.FaultDaemonDelayRate,
+			"daemon_delay", cfg.FaultDaemonDelay,
+			"sse_drop_rate", cfg.FaultSSEDropRate)
 	}
 
-	// Build dynamic client for ExternalSecret reconciliation.
-	k8sCfg, err := buildK8sConfig(cfg.KubeConfig)
+	// Daemon client for HTTP access (used by reconciler, status reporter, and bridge).
+	daemon, err := beadsapi.New(daemonCfg)
 	if err != nil {
-		logger.Error("failed to build K8s config for dynamic client", "error", err)
+		logger.Error("failed to create beads daemon client", "error", err)
 		os.Exit(1)
 	}
-	dynClient, err := dynamic.NewForConfig(k8sCfg)
-	if err != nil {
-		logger.Error("failed to create dynamic K8s client", "error", err)
-		os.Exit(1)
+	defer daemon.Close()
+
+	// Roll up repeated warnings and errors into one event-bus report per
+	// window, which the slack-bridge posts as a single alert.
+	var errReports *errorreporter.Aggregator
+	if cfg.ErrorReportWindow > 0 {
+		errReports = errorreporter.New(errorreporter.Config{
+			Publisher: daemon,
+			Source:    "controller",
+			Window:    cfg.ErrorReportWindow,
+			Cooldown:  cfg.ErrorReportCooldown,
+			Logger:    logger,
+		})
+		logger = slog.New(errReports.Handler(logger.Handler()))
 	}
-	secretRec := secretreconciler.New(
-		dynClient, cfg.Namespace,
-		cfg.ExternalSecretStoreName,
-		cfg.ExternalSecretStoreKind,
-		cfg.ExternalSecretRefreshInterval,
-		logger,
-	)
 
-	watcher := subscriber.NewSSEWatcher(subscriber.SSEConfig{
+	sseCfg := subscriber.SSEConfig{
 		BeadsHTTPAddr: cfg.BeadsHTTPAddr,
 		Topics:        "beads.bead.*",
 		Namespace:     cfg.Namespace,
 		CoopImage:     cfg.CoopImage,
 		BeadsGRPCAddr: cfg.BeadsGRPCAddr,
-	}, logger)
+		StrictEvents:  cfg.StrictEventSchema,
+	}
+	backend, err := newAgentBackend(cfg, logger)
+	if err != nil {
+		logger.Error("failed to set up agent backend", "backend", cfg.AgentBackend, "error", err)
+		os.Exit(1)
+	}
+	k8sClient := backend.client
+	pods := backend.pods
+	if chaos != nil {
+		sseCfg.DropEvent = chaos.DropEvent
+		pods = chaos.Manager(pods)
+	}
+	// Pod policy admission runs before any pod is created, whichever path
+	// (event, reconciler) creates it.
+	var pol *policy.Enforcer
+	if cfg.PodPolicy {
+		static, err := policy.Parse([]byte(cfg.PodPolicyRules))
+		if err != nil {
+			logger.Error("invalid POD_POLICY_RULES", "error", err)
+			os.Exit(1)
+		}
+		pol = policy.NewEnforcer(static, logger)
+		if err := pol.Refresh(context.Background(), daemon); err != nil {
+			logger.Warn("failed to load pod policy config (will retry on next sync)", "error", err)
+		}
+		pods = pol.Manager(pods)
+		logger.Info("pod policy admission enabled", "static_rules", len(static.Rules))
+	}
+	watcher := subscriber.NewSSEWatcher(sseCfg, logger)
 	logger.Info("using SSE transport for beads events",
 		"beads_http", cfg.BeadsHTTPAddr)
-	pods := podmanager.New(k8sClient, logger)
 
-	// Daemon client for HTTP access (used by reconciler, status reporter, and bridge).
-	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: cfg.BeadsHTTPAddr})
-	if err != nil {
-		logger.Error("failed to create beads daemon client", "error", err)
-		os.Exit(1)
+	// The reporter also keeps the coop service registry that coopmux and the
+	// slack bridge resolve agent sessions from.
+	status := statusreporter.NewHTTPReporter(daemon, k8sClient, cfg.Namespace, logger).WithRegistry(daemon)
+	if backend.statusClusters != nil {
+		status.WithClusters(backend.statusClusters...)
+	}
+	if cfg.AgentEvents {
+		status.WithEvents(statusreporter.NewEventRecorder(cfg.LeaderElectionIdentity, logger))
 	}
-	defer daemon.Close()
-	status := statusreporter.NewHTTPReporter(daemon, k8sClient, cfg.Namespace, logger)
 
 	// Register bead types, views, and context configs with the daemon.
 	if err := bridge.EnsureConfigs(context.Background(), daemon, logger); err != nil {
 		logger.Warn("failed to ensure beads configs (will retry on next sync)", "error", err)
 	}
 
+	spot, err := cfg.SpotPolicy()
+	if err != nil {
+		logger.Error("invalid spot configuration", "error", err)
+		os.Exit(1)
+	}
+	cfg.Spot = spot
+	if spot != nil {
+		logger.Info("scheduling job agents on spot capacity",
+			"node_selector", spot.NodeSelector, "max_preemptions", spot.MaxPreemptions)
+	}
+
+	arch, err := cfg.ArchPolicy()
+	if err != nil {
+		logger.Error("invalid agent architecture configuration", "error", err)
+		os.Exit(1)
+	}
+	cfg.Arch = arch
+	if arch != nil {
+		logger.Info("multi-arch agents enabled", "images", arch.Images, "role_arch", arch.RoleArch)
+	}
+
 	// Populate project cache from daemon project beads.
-	cfg.ProjectCache = make(map[string]config.ProjectCacheEntry)
-	refreshProjectCache(context.Background(), logger, daemon, cfg)
+	cfg.ProjectCache = config.NewSnapshot(make(map[string]config.ProjectCacheEntry))
+	cfg.TemplateCache = config.NewSnapshot[map[string]beadsapi.TemplateInfo](nil)
+	cfg.RoleCache = config.NewSnapshot[map[string]config.RoleCacheEntry](nil)
+	projects := newProjectCache(daemon, logger)
+	projects.refresh(context.Background(), cfg)
+	refreshTemplateCache(context.Background(), logger, daemon, cfg)
+	refreshRoleCache(context.Background(), logger, daemon, cfg)
+
+	// Sites may run their own selection and order of pod spec stages.
+	if cfg.SpecStages != "" {
+		var stages []string
+		for _, name := range strings.Split(cfg.SpecStages, ",") {
+			stages = append(stages, strings.TrimSpace(name))
+		}
+		if err := specbuilder.Default.SetOrder(stages); err != nil {
+			logger.Error("invalid SPEC_STAGES", "error", err)
+			os.Exit(1)
+		}
+		logger.Info("custom pod spec stages", "stages", specbuilder.Default.Order())
+	}
+
+	// Agent ConfigMaps are rendered on every agent cluster; specs built by
+	// the reconciler mount them and carry their hash.
+	specBuilder := reconciler.SpecBuilder(specbuilder.FromBead)
+	var cfgRec *configreconciler.Reconciler
+	if cfg.AgentConfigMaps {
+		cfgRec = configreconciler.New(daemon, backend.clusterClients, cfg, logger)
+		specBuilder = func(cfg *config.Config, project, mode, role, agentName string, metadata map[string]string) podmanager.AgentPodSpec {
+			spec := specbuilder.FromBead(cfg, project, mode, role, agentName, metadata)
+			cfgRec.Apply(&spec)
+			return spec
+		}
+		logger.Info("rendering agent ConfigMaps from beads")
+	}
 
-	rec := reconciler.New(daemon, pods, cfg, logger, BuildSpecFromBeadInfo)
+	// Least-privilege mode: per-project agent ServiceAccounts and Roles.
+	var rbacRec *rbacreconciler.Reconciler
+	if cfg.AgentRBAC {
+		rules, err := cfg.AgentRoleRules()
+		if err != nil {
+			logger.Error("invalid AGENT_RBAC_RULES", "error", err)
+			os.Exit(1)
+		}
+		rbacRec = rbacreconciler.New(backend.clusterClients, cfg.Namespace, rules, logger)
+		if err := rbacRec.Reconcile(context.Background(), cfg.ProjectCache.Load()); err != nil {
+			logger.Warn("agent RBAC reconciliation failed (will retry on next sync)", "error", err)
+		}
+		logger.Info("least-privilege agent ServiceAccounts enabled")
+	}
+
+	rec := reconciler.New(daemon, pods, cfg, logger, specBuilder)
+	rec.SetCheckpointer(newCoopCheckpointer())
+	rec.SetActionRecorder(status)
+	if cfg.CapacityAdmission {
+		rec.SetCapacitySource(backend.multi)
+	}
+	if cfg.ImageVerification() {
+		verifier, err := newImageVerifier(cfg, logger)
+		if err != nil {
+			logger.Error("invalid image verification configuration", "error", err)
+			os.Exit(1)
+		}
+		rec.SetImageVerifier(verifier)
+		logger.Info("agent image signature verification enabled")
+	}
+	if cfg.ReconcileHistorySize > 0 {
+		var store reconciler.HistoryStore = reconciler.NewDaemonHistoryStore(daemon)
+		if cfg.ReconcileHistoryFile != "" {
+			store = reconciler.NewFileHistoryStore(cfg.ReconcileHistoryFile)
+		}
+		rec.SetHistory(reconciler.NewHistory(store, cfg.ReconcileHistorySize, logger))
+	}
+
+	// Warm pods live on the home cluster and are adopted by AgentSpawn events.
+	warmPools, err := cfg.WarmPools()
+	if err != nil {
+		logger.Error("invalid WARM_POOL", "error", err)
+		os.Exit(1)
+	}
+	var warm *warmPool
+	if len(warmPools) > 0 {
+		warm = newWarmPool(backend.home, warmPools, logger)
+		logger.Info("agent warm pool enabled", "pools", warmPools)
+	}
+
+	// Events are handled on a keyed worker pool so one slow agent doesn't
+	// hold up the rest.
+	events, err := newEventQueue(cfg.EventWorkers, cfg.EventQueueDepth, cfg.EventQueueOverflow, logger)
+	if err != nil {
+		logger.Error("invalid event queue configuration", "error", err)
+		os.Exit(1)
+	}
+	events.withDedup(cfg.EventDedupTTL).withRetries(cfg.EventMaxAttempts, cfg.EventRetryBackoff)
+	// Events failing every attempt are kept for re-driving via /admin/.
+	var dead *deadLetters
+	if cfg.DeadLetterSize > 0 {
+		var store deadLetterStore = daemonDeadLetterStore{configs: daemon}
+		if cfg.DeadLetterFile != "" {
+			store = fileDeadLetterStore{path: cfg.DeadLetterFile}
+		}
+		dead = newDeadLetters(store, cfg.DeadLetterSize, logger)
+		events.withDeadLetters(dead)
+	}
 
 	// Slack notifications, decision watcher, and mail watcher are now handled
 	// by the standalone slack-bridge binary (cmd/slack-bridge). The controller
@@ -131,6 +305,54 @@ func main() {
 			"namespace":  cfg.Namespace,
 		})
 	})
+	// active is set once this replica runs the controller loop (immediately,
+	// or on winning leader election).
+	var active atomic.Bool
+	healthMux.HandleFunc("/readyz", readyzHandler(append(controllerReadinessChecks(
+		watcher, rec, 3*periodicSyncInterval(cfg), daemon, k8sClient, cfg.Namespace, &active), backend.checks...)))
+	healthMux.HandleFunc("/metrics", events.metricsHandler)
+	healthMux.HandleFunc("/status", statusHandler(newStatusReporter(daemon, rec, &active, cfg.SlackBridgeURL)))
+	if cfg.SpawnPreviewToken != "" {
+		healthMux.HandleFunc("/spawn-preview", spawnPreviewHandler(cfg, cfg.SpawnPreviewToken))
+	}
+	if k8sClient != nil && cfg.AgentLogsToken != "" {
+		healthMux.HandleFunc("/agent-logs", agentLogsHandler(k8sClient, cfg.Namespace, cfg.AgentLogsToken))
+	}
+	if cfg.AgentExecToken != "" {
+		healthMux.HandleFunc("/agent-exec", agentExecHandler(k8sClient, cfg.Namespace, cfg.AgentExecToken, newPodExec(k8sClient, backend.restConfig), logger))
+	}
+	if cfg.TaskIngestKey != "" {
+		healthMux.HandleFunc("/ingest/task", taskIngestHandler(daemon, cfg, logger))
+	}
+	// Bridges reach agents' coop sessions through /coop/ with short-lived
+	// tokens minted by /coop-token.
+	if cfg.CoopTokenKey != "" {
+		signer, err := cooptoken.NewSigner([]byte(cfg.CoopTokenKey))
+		if err != nil {
+			logger.Error("invalid COOP_TOKEN_KEY", "error", err)
+			os.Exit(1)
+		}
+		healthMux.Handle("/coop/", newCoopProxy(signer, daemon, logger))
+		if cfg.CoopTokenClientToken != "" {
+			healthMux.HandleFunc("/coop-token", coopTokenHandler(signer, cfg.CoopTokenClientToken, cfg.CoopTokenTTL, logger))
+		}
+	}
+	// Forced sync passes requested via the admin API; consumed by
+	// runPeriodicSync on the leader only.
+	syncNow := make(syncTrigger, 1)
+	if cfg.AdminToken != "" {
+		healthMux.Handle("/admin/", adminHandler(k8sClient, cfg.Namespace, cfg.AdminToken, daemon, rec, syncNow, dead, events, logger))
+	}
+	// Smoke-test pods of onboarding projects run on the home cluster.
+	var onboard *onboarder
+	if backend.home != nil {
+		onboard = newOnboarder(backend.home, k8sClient, daemon, cfg.OnboardingTimeout, logger, syncNow.nudge)
+	}
+	// Crash-looping agents get an incident bead with a forensic bundle.
+	var forensics *crashForensics
+	if k8sClient != nil && cfg.CrashForensicsRestarts > 0 {
+		forensics = newCrashForensics(k8sClient, cfg.Namespace, daemon, cfg.CrashForensicsRestarts, cfg.CrashForensicsWindow, logger)
+	}
 	healthSrv := &http.Server{
 		Addr:              healthAddr,
 		Handler:           healthMux,
@@ -146,8 +368,13 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 
+	if errReports != nil {
+		go errReports.Run(ctx)
+	}
+
 	runFn := func(ctx context.Context) {
-		if err := run(ctx, logger, cfg, k8sClient, watcher, pods, status, rec, daemon, secretRec); err != nil {
+		active.Store(true)
+		if err := run(ctx, logger, cfg, k8sClient, watcher, pods, status, rec, daemon, backend.secretRec, cfgRec, rbacRec, pol, syncNow, warm, onboard, forensics, projects, events); err != nil {
 			logger.Error("controller stopped", "error", err)
 			os.Exit(1)
 		}
@@ -208,7 +435,23 @@ func runLeaderElection(ctx context.Context, logger *slog.Logger, cfg *config.Con
 
 // run is the main controller loop. It reads beads events and dispatches
 // pod operations. Separated from main() for testability.
-func run(ctx context.Context, logger *slog.Logger, cfg *config.Config, k8sClient kubernetes.Interface, watcher subscriber.Watcher, pods podmanager.Manager, status statusreporter.Reporter, rec *reconciler.Reconciler, daemon *beadsapi.Client, secretRec *secretreconciler.Reconciler) error {
+func run(ctx context.Context, logger *slog.Logger, cfg *config.Config, k8sClient kubernetes.Interface, watcher subscriber.Watcher, pods podmanager.Manager, status statusreporter.Reporter, rec *reconciler.Reconciler, daemon *beadsapi.Client, secretRec *secretreconciler.Reconciler, cfgRec *configreconciler.Reconciler, rbacRec *rbacreconciler.Reconciler, pol *policy.Enforcer, syncNow syncTrigger, warm *warmPool, onboard *onboarder, forensics *crashForensics, projects *projectCache, events *eventQueue) error {
+	// Render agent ConfigMaps first so pods created at startup mount them.
+	if cfgRec != nil {
+		if err := cfgRec.Reconcile(ctx); err != nil {
+			logger.Warn("startup agent config reconciliation failed", "error", err)
+		}
+	}
+	// Adopt pods of a manual deployment before the startup pass would
+	// delete them as orphans.
+	if rec != nil && cfg.AdoptPods {
+		n, err := rec.AdoptPods(ctx)
+		if err != nil {
+			logger.Warn("startup pod adoption failed", "adopted", n, "error", err)
+		} else {
+			logger.Info("startup pod adoption complete", "adopted", n)
+		}
+	}
 	// Run reconciler once at startup to catch beads created during downtime.
 	if rec != nil {
 		logger.Info("running startup reconciliation")
@@ -224,10 +467,7 @@ func run(ctx context.Context, logger *slog.Logger, cfg *config.Config, k8sClient
 	}()
 
 	// Start periodic SyncAll reconciliation.
-	syncInterval := 60 * time.Second
-	if cfg.CoopSyncInterval > 0 {
-		syncInterval = cfg.CoopSyncInterval
-	}
+	syncInterval := periodicSyncInterval(cfg)
 	// Seed the digest tracker with the default agent image so it starts
 	// tracking registry changes immediately.
 	if cfg.CoopImage != "" && rec != nil {
@@ -245,7 +485,19 @@ func run(ctx context.Context, logger *slog.Logger, cfg *config.Config, k8sClient
 			logger.Info("seeded image digest tracker", "image", cfg.CoopImage, "digest", truncForLog(digest))
 		}()
 	}
-	go runPeriodicSync(ctx, logger, status, rec, daemon, cfg, syncInterval, secretRec)
+	go runPeriodicSync(ctx, logger, status, rec, daemon, cfg, syncInterval, secretRec, cfgRec, rbacRec, pol, syncNow, warm, onboard, forensics, projects, newCronScheduler(daemon, logger), newBudgetTracker(daemon, logger))
+
+	grace := newTerminationGrace(cfg.AgentTerminationGrace, pods, status, newCoopCheckpointer(), logger)
+	events.start(ctx, func(ctx context.Context, event subscriber.Event) error {
+		err := handleEvent(ctx, logger, cfg, event, pods, status, warm, grace, cfgRec)
+		// With image verification, a stuck agent's replacement pod is
+		// created by the reconciler.
+		if event.Type == subscriber.AgentStuck && cfg.ImageVerification() {
+			syncNow.nudge()
+		}
+		return err
+	})
+	defer events.stop()
 
 	logger.Info("controller ready, waiting for beads events",
 		"sync_interval", syncInterval)
@@ -256,8 +508,15 @@ func run(ctx context.Context, logger *slog.Logger, cfg *config.Config, k8sClient
 			if !ok {
 				return nil // channel closed, watcher shut down
 			}
-			if err := handleEvent(ctx, logger, cfg, event, pods, status); err != nil {
-				logger.Error("failed to handle event", "type", event.Type, "agent", event.AgentName, "error", err)
+			// With capacity admission or image verification, spawns go
+			// through the reconciler so they are admitted like any other pod.
+			if event.Type == subscriber.AgentSpawn && (cfg.CapacityAdmission || cfg.ImageVerification()) && rec != nil {
+				syncNow.nudge()
+				continue
+			}
+			// A shed event is left to a reconcile pass.
+			if !events.enqueue(ctx, event) {
+				syncNow.nudge()
 			}
 
 		case err := <-watcherDone:
@@ -270,8 +529,17 @@ func run(ctx context.Context, logger *slog.Logger, cfg *config.Config, k8sClient
 	}
 }
 
-// runPeriodicSync runs SyncAll, project cache refresh, and reconciliation at a regular interval.
-func runPeriodicSync(ctx context.Context, logger *slog.Logger, status statusreporter.Reporter, rec *reconciler.Reconciler, daemon *beadsapi.Client, cfg *config.Config, interval time.Duration, secretRec *secretreconciler.Reconciler) {
+// periodicSyncInterval returns how often runPeriodicSync runs a pass.
+func periodicSyncInterval(cfg *config.Config) time.Duration {
+	if cfg.CoopSyncInterval > 0 {
+		return cfg.CoopSyncInterval
+	}
+	return 60 * time.Second
+}
+
+// runPeriodicSync runs SyncAll, project cache refresh, and reconciliation at a
+// regular interval, and immediately when requested through syncNow.
+func runPeriodicSync(ctx context.Context, logger *slog.Logger, status statusreporter.Reporter, rec *reconciler.Reconciler, daemon *beadsapi.Client, cfg *config.Config, interval time.Duration, secretRec *secretreconciler.Reconciler, cfgRec *configreconciler.Reconciler, rbacRec *rbacreconciler.Reconciler, pol *policy.Enforcer, syncNow syncTrigger, warm *warmPool, onboard *onboarder, forensics *crashForensics, projects *projectCache, crons *cronScheduler, budget *budgetTracker) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -280,20 +548,83 @@ func runPeriodicSync(ctx context.Context, logger *slog.Logger, status statusrepo
 	digestCheckCounter := 0
 	const digestCheckInterval = 5
 
+	// syncOnce runs one pass and returns the reconcile error, if any.
+	syncOnce := func() error {
+		if err := status.SyncAll(ctx); err != nil {
+			logger.Warn("periodic status sync failed", "error", err)
+		}
+		// Collect forensics before the reconciler replaces failed pods.
+		if err := forensics.sweep(ctx); err != nil {
+			logger.Warn("crash-loop forensics sweep failed", "error", err)
+		}
+		// Refresh project cache from daemon.
+		projects.refresh(ctx, cfg)
+		refreshTemplateCache(ctx, logger, daemon, cfg)
+		refreshRoleCache(ctx, logger, daemon, cfg)
+		// Start scheduled job runs, so this pass already creates their pods.
+		if err := crons.run(ctx, cfg.TemplateCache.Load()); err != nil {
+			logger.Warn("scheduled jobs failed", "error", err)
+		}
+		// Hold back new agents of projects over their daily budget.
+		if err := budget.check(ctx, cfg); err != nil {
+			logger.Warn("project budget check failed", "error", err)
+		}
+		// Validate projects awaiting onboarding; their agents wait for it.
+		if onboard != nil {
+			if err := onboard.sweep(ctx, cfg); err != nil {
+				logger.Warn("project onboarding sweep failed", "error", err)
+			}
+		}
+		// Reconcile ExternalSecrets from project bead secrets.
+		if secretRec != nil {
+			if err := secretRec.Reconcile(ctx, cfg.ProjectCache.Load()); err != nil {
+				logger.Warn("ExternalSecret reconciliation failed", "error", err)
+			}
+		}
+		// Per-project agent ServiceAccounts must exist before their pods.
+		if rbacRec != nil {
+			if err := rbacRec.Reconcile(ctx, cfg.ProjectCache.Load()); err != nil {
+				logger.Warn("agent RBAC reconciliation failed", "error", err)
+			}
+		}
+		// Pick up pod policy changes before the reconciler creates pods.
+		if pol != nil {
+			if err := pol.Refresh(ctx, daemon); err != nil {
+				logger.Warn("pod policy refresh failed", "error", err)
+			}
+		}
+		// Re-render agent ConfigMaps before the reconciler compares
+		// config hashes.
+		if cfgRec != nil {
+			if err := cfgRec.Reconcile(ctx); err != nil {
+				logger.Warn("agent config reconciliation failed", "error", err)
+			}
+		}
+		// Run reconciler to converge desired vs actual state.
+		var recErr error
+		if rec != nil {
+			if recErr = rec.Reconcile(ctx); recErr != nil {
+				logger.Warn("periodic reconciliation failed", "error", recErr)
+			}
+		}
+		// Top up the warm pool after agents adopted pods from it.
+		if err := warm.refill(ctx, cfg); err != nil {
+			logger.Warn("warm pool refill failed", "error", err)
+		}
+		// Log metrics snapshot after each sync.
+		m := status.Metrics()
+		logger.Info("metrics",
+			"reports_total", m.StatusReportsTotal,
+			"report_errors", m.StatusReportErrors,
+			"sync_runs", m.SyncAllRuns,
+			"sync_errors", m.SyncAllErrors,
+			"pods_by_cluster", m.PodsByCluster)
+		return recErr
+	}
+
 	for {
 		select {
 		case <-ticker.C:
-			if err := status.SyncAll(ctx); err != nil {
-				logger.Warn("periodic status sync failed", "error", err)
-			}
-			// Refresh project cache from daemon.
-			refreshProjectCache(ctx, logger, daemon, cfg)
-			// Reconcile ExternalSecrets from project bead secrets.
-			if secretRec != nil {
-				if err := secretRec.Reconcile(ctx, cfg.ProjectCache); err != nil {
-					logger.Warn("ExternalSecret reconciliation failed", "error", err)
-				}
-			}
 			// Periodically check the OCI registry for image digest updates.
 			digestCheckCounter++
 			if rec != nil && digestCheckCounter >= digestCheckInterval {
@@ -302,109 +633,19 @@ func runPeriodicSync(ctx context.Context, logger *slog.Logger, status statusrepo
 					dt.RefreshImages(ctx)
 				}
 			}
-			// Run reconciler to converge desired vs actual state.
-			if rec != nil {
-				if err := rec.Reconcile(ctx); err != nil {
-					logger.Warn("periodic reconciliation failed", "error", err)
-				}
+			syncOnce()
+		case reply := <-syncNow:
+			logger.Info("running requested sync pass")
+			err := syncOnce()
+			if reply != nil {
+				reply <- err
 			}
-			// Log metrics snapshot after each sync.
-			m := status.Metrics()
-			logger.Info("metrics",
-				"reports_total", m.StatusReportsTotal,
-				"report_errors", m.StatusReportErrors,
-				"sync_runs", m.SyncAllRuns,
-				"sync_errors", m.SyncAllErrors)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-// handleEvent translates a beads lifecycle event into K8s pod operations.
-func handleEvent(ctx context.Context, logger *slog.Logger, cfg *config.Config, event subscriber.Event, pods podmanager.Manager, status statusreporter.Reporter) error {
-	logger.Info("handling beads event",
-		"type", event.Type, "project", event.Project, "role", event.Role,
-		"agent", event.AgentName, "bead", event.BeadID)
-
-	// Use the canonical bead ID from the event. Fall back to constructing
-	// from labels for backwards compatibility with older daemon versions.
-	agentBeadID := event.BeadID
-	if agentBeadID == "" {
-		agentBeadID = fmt.Sprintf("%s-%s-%s-%s", event.Mode, event.Project, event.Role, event.AgentName)
-	}
-
-	switch event.Type {
-	case subscriber.AgentSpawn:
-		spec := buildAgentPodSpec(cfg, event)
-		if err := pods.CreateAgentPod(ctx, spec); err != nil {
-			return err
-		}
-		// Backend metadata (coop_url) is written by SyncAll once the pod has an IP.
-		// We skip writing it here because the pod IP isn't available at creation time.
-		// Report spawning status to beads.
-		_ = status.ReportPodStatus(ctx, agentBeadID, statusreporter.PodStatus{
-			PodName:   spec.PodName(),
-			Namespace: spec.Namespace,
-			Phase:     string("Pending"),
-			Ready:     false,
-		})
-		return nil
-
-	case subscriber.AgentDone, subscriber.AgentKill, subscriber.AgentStop:
-		podName := fmt.Sprintf("%s-%s-%s-%s", event.Mode, event.Project, event.Role, event.AgentName)
-		ns := namespaceFromEvent(event, cfg.Namespace)
-		err := pods.DeleteAgentPod(ctx, podName, ns)
-		// Clear backend metadata so stale Coop URLs don't linger.
-		_ = status.ReportBackendMetadata(ctx, agentBeadID, statusreporter.BackendMetadata{})
-		// Report done status to beads regardless of delete error.
-		phase := "Succeeded"
-		if event.Type == subscriber.AgentKill {
-			phase = "Failed"
-		}
-		if event.Type == subscriber.AgentStop {
-			phase = "Stopped"
-		}
-		_ = status.ReportPodStatus(ctx, agentBeadID, statusreporter.PodStatus{
-			PodName:   podName,
-			Namespace: ns,
-			Phase:     phase,
-			Ready:     false,
-		})
-		return err
-
-	case subscriber.AgentStuck:
-		// Delete and recreate the pod to restart the agent.
-		podName := fmt.Sprintf("%s-%s-%s-%s", event.Mode, event.Project, event.Role, event.AgentName)
-		ns := namespaceFromEvent(event, cfg.Namespace)
-		if err := pods.DeleteAgentPod(ctx, podName, ns); err != nil {
-			logger.Warn("failed to delete stuck pod (may not exist)", "pod", podName, "error", err)
-		}
-		spec := buildAgentPodSpec(cfg, event)
-		if err := pods.CreateAgentPod(ctx, spec); err != nil {
-			return err
-		}
-		// Report restarting status.
-		_ = status.ReportPodStatus(ctx, agentBeadID, statusreporter.PodStatus{
-			PodName:   spec.PodName(),
-			Namespace: spec.Namespace,
-			Phase:     string("Pending"),
-			Ready:     false,
-			Message:   "restarted due to stuck detection",
-		})
-		return nil
-
-	case subscriber.AgentUpdate:
-		// Metadata updates are handled by the reconciler during periodic sync.
-		// No immediate pod action needed.
-		return nil
-
-	default:
-		logger.Warn("unknown event type", "type", event.Type)
-		return nil
-	}
-}
-
 func buildK8sConfig(kubeconfig string) (*rest.Config, error) {
 	if kubeconfig != "" {
 		return clientcmd.BuildConfigFromFlags("", kubeconfig)
@@ -412,6 +653,24 @@ func buildK8sConfig(kubeconfig string) (*rest.Config, error) {
 	return rest.InClusterConfig()
 }
 
+// buildPodClient builds the client used for agent pod operations. It
+// impersonates cfg.ImpersonateUser (and ImpersonateGroups) when set.
+func buildPodClient(kubeconfig string, cfg *config.Config) (kubernetes.Interface, error) {
+	restCfg, err := buildK8sConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("building k8s config: %w", err)
+	}
+	if cfg.ImpersonateUser != "" {
+		restCfg.Impersonate = rest.ImpersonationConfig{UserName: cfg.ImpersonateUser}
+		for _, g := range strings.Split(cfg.ImpersonateGroups, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				restCfg.Impersonate.Groups = append(restCfg.Impersonate.Groups, g)
+			}
+		}
+	}
+	return kubernetes.NewForConfig(restCfg)
+}
+
 func buildK8sClient(kubeconfig string) (kubernetes.Interface, error) {
 	cfg, err := buildK8sConfig(kubeconfig)
 	if err != nil {
@@ -420,27 +679,66 @@ func buildK8sClient(kubeconfig string) (kubernetes.Interface, error) {
 	return kubernetes.NewForConfig(cfg)
 }
 
-// refreshProjectCache queries the daemon for project beads and updates cfg.ProjectCache.
-func refreshProjectCache(ctx context.Context, logger *slog.Logger, daemon *beadsapi.Client, cfg *config.Config) {
-	rigs, err := daemon.ListProjectBeads(ctx)
+// refreshTemplateCache queries the daemon for template beads and replaces
+// the cfg.TemplateCache snapshot. Malformed templates are logged and still cached; the
+// fields that don't parse are ignored when building pod specs.
+func refreshTemplateCache(ctx context.Context, logger *slog.Logger, daemon *beadsapi.Client, cfg *config.Config) {
+	templates, err := daemon.ListTemplateBeads(ctx)
+	if err != nil {
+		logger.Warn("failed to refresh template cache", "error", err)
+		return
+	}
+	for name, t := range templates {
+		if err := t.Validate(); err != nil {
+			logger.Warn("invalid agent template", "template", name, "bead", t.ID, "error", err)
+		}
+	}
+	cfg.TemplateCache.Store(templates)
+	logger.Info("refreshed template cache", "count", len(templates))
+}
+
+// refreshRoleCache queries the daemon for role beads and replaces the
+// cfg.RoleCache snapshot. Malformed fields of a role are logged and ignored.
+func refreshRoleCache(ctx context.Context, logger *slog.Logger, daemon *beadsapi.Client, cfg *config.Config) {
+	roles, err := daemon.ListRoleBeads(ctx)
 	if err != nil {
-		logger.Warn("failed to refresh project cache", "error", err)
+		logger.Warn("failed to refresh role cache", "error", err)
 		return
 	}
-	for name, info := range rigs {
-		cfg.ProjectCache[name] = config.ProjectCacheEntry{
-			Prefix:         info.Prefix,
-			GitURL:         info.GitURL,
-			DefaultBranch:  info.DefaultBranch,
-			Image:          info.Image,
-			StorageClass:   info.StorageClass,
-			ServiceAccount: info.ServiceAccount,
-			RTKEnabled:     info.RTKEnabled,
-			Secrets:        info.Secrets,
-			Repos:          info.Repos,
+	cache := make(map[string]config.RoleCacheEntry, len(roles))
+	for name, role := range roles {
+		if err := role.Validate(); err != nil {
+			logger.Warn("invalid role bead", "role", name, "bead", role.ID, "error", err)
 		}
+		cache[name] = roleCacheEntry(role)
+	}
+	cfg.RoleCache.Store(cache)
+	logger.Info("refreshed role cache", "count", len(roles))
+}
+
+// roleCacheEntry converts a role bead into its cache entry, dropping
+// malformed env, resources, storage size, termination grace and preStop
+// hook.
+func roleCacheEntry(role beadsapi.RoleInfo) config.RoleCacheEntry {
+	entry := config.RoleCacheEntry{
+		Image:        role.Fields[beadsapi.RoleImageField],
+		StorageClass: role.Fields[beadsapi.RoleStorageClassField],
+		Instructions: role.Fields[beadsapi.RoleInstructionsField],
+	}
+	if size := role.Fields[beadsapi.RoleStorageSizeField]; size != "" {
+		if _, err := resource.ParseQuantity(size); err == nil {
+			entry.StorageSize = size
+		}
+	}
+	entry.TerminationGrace, _ = beadsapi.ParseTerminationGrace(role.Fields[beadsapi.TerminationGraceField])
+	if hook := role.Fields[beadsapi.PreStopField]; prestop.Validate(hook) == nil {
+		entry.PreStop = hook
+	}
+	entry.Env, _ = beadsapi.TemplateEnv(role.Fields)
+	if res, err := beadsapi.TemplateResources(role.Fields); err == nil {
+		entry.Resources = res
 	}
-	logger.Info("refreshed project cache", "count", len(rigs))
+	return entry
 }
 
 func setupLogger(level string) *slog.Logger {
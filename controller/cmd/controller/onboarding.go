@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/reconciler"
+	"gasboat/controller/internal/specbuilder"
+)
+
+// Onboarding checks, in the order they are reported.
+const (
+	checkConfig  = "config"
+	checkSecrets = "secrets"
+	checkImage   = "image"
+	checkClone   = "clone"
+	checkSmoke   = "smoke"
+)
+
+const (
+	// onboardingPollInterval is how often the smoke-test pod is checked.
+	onboardingPollInterval = 5 * time.Second
+	// onboardingLogLines is how much of a failed clone's log is reported.
+	onboardingLogLines = 20
+)
+
+// onboardingStore reads and updates project beads (beadsapi.Client).
+type onboardingStore interface {
+	ListProjectBeads(ctx context.Context) (map[string]beadsapi.ProjectInfo, error)
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// onboarder validates projects whose bead asks for onboarding before any of
+// their agents are scheduled: the project config, a dry run resolving every
+// secret its pods reference, and a disposable smoke-test agent on the home
+// cluster that pulls the image, clones the repos with the project's
+// credentials and must come up healthy. The report is written onto the
+// project bead. A nil *onboarder onboards nothing.
+type onboarder struct {
+	pods     *podmanager.K8sManager
+	client   kubernetes.Interface
+	store    onboardingStore
+	timeout  time.Duration
+	interval time.Duration
+	logger   *slog.Logger
+	// done is called after each project finishes, to schedule its agents
+	// without waiting for the next tick.
+	done func()
+
+	mu     sync.Mutex
+	active map[string]bool // projects being onboarded
+}
+
+func newOnboarder(pods *podmanager.K8sManager, client kubernetes.Interface, store onboardingStore, timeout time.Duration, logger *slog.Logger, done func()) *onboarder {
+	return &onboarder{
+		pods:     pods,
+		client:   client,
+		store:    store,
+		timeout:  timeout,
+		interval: onboardingPollInterval,
+		logger:   logger,
+		done:     done,
+		active:   make(map[string]bool),
+	}
+}
+
+// sweep starts onboarding every project that asks for it. Projects left
+// running by a previous leader are started over.
+func (o *onboarder) sweep(ctx context.Context, cfg *config.Config) error {
+	if o == nil {
+		return nil
+	}
+	projects, err := o.store.ListProjectBeads(ctx)
+	if err != nil {
+		return err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for name, p := range projects {
+		if p.Onboarding != beadsapi.OnboardingPending && p.Onboarding != beadsapi.OnboardingRunning {
+			continue
+		}
+		if o.active[name] {
+			continue
+		}
+		o.active[name] = true
+		go func() {
+			o.onboard(ctx, cfg, p)
+			o.mu.Lock()
+			delete(o.active, name)
+			o.mu.Unlock()
+			o.done()
+		}()
+	}
+	return nil
+}
+
+// onboard validates p and records the outcome on its bead.
+func (o *onboarder) onboard(ctx context.Context, cfg *config.Config, p beadsapi.ProjectInfo) {
+	logger := o.logger.With("project", p.Name)
+	if err := o.store.UpdateBeadFields(ctx, p.ID, map[string]string{
+		beadsapi.OnboardingField:       beadsapi.OnboardingRunning,
+		beadsapi.OnboardingReportField: "",
+	}); err != nil {
+		logger.Warn("failed to mark project onboarding", "error", err)
+		return
+	}
+	logger.Info("onboarding project")
+
+	report := o.validate(ctx, cfg, p)
+	if ctx.Err() != nil {
+		return // lost leadership; the next leader starts over
+	}
+	state := beadsapi.OnboardingPassed
+	if !report.Passed() {
+		state = beadsapi.OnboardingFailed
+	}
+	raw, _ := json.Marshal(report)
+	if err := o.store.UpdateBeadFields(ctx, p.ID, map[string]string{
+		beadsapi.OnboardingField:       state,
+		beadsapi.OnboardingReportField: string(raw),
+	}); err != nil {
+		logger.Warn("failed to record project onboarding report", "error", err)
+		return
+	}
+	logger.Info("project onboarding finished", "result", state)
+}
+
+// validate runs the onboarding checks for p.
+func (o *onboarder) validate(ctx context.Context, cfg *config.Config, p beadsapi.ProjectInfo) *beadsapi.OnboardingReport {
+	report := &beadsapi.OnboardingReport{Started: time.Now()}
+	defer func() { report.Finished = time.Now() }()
+	add := func(name, status, detail string) {
+		report.Checks = append(report.Checks, beadsapi.OnboardingCheck{Name: name, Status: status, Detail: detail})
+	}
+	skipPod := func(why string) {
+		for _, name := range []string{checkImage, checkClone, checkSmoke} {
+			add(name, beadsapi.CheckSkipped, why)
+		}
+	}
+
+	if err := p.Validate(); err != nil {
+		add(checkConfig, beadsapi.CheckFailed, strings.ReplaceAll(err.Error(), "\n", "; "))
+		add(checkSecrets, beadsapi.CheckSkipped, "invalid project config")
+		skipPod("invalid project config")
+		return report
+	}
+	add(checkConfig, beadsapi.CheckPassed, "")
+
+	spec := onboardingSpec(cfg, p.Name)
+	missing := o.missingSecrets(ctx, spec)
+	// Agent pods silently drop project secrets outside the project's prefix.
+	for _, s := range p.Secrets {
+		if !strings.HasPrefix(s.Secret, p.Name+"-") {
+			missing = append(missing, fmt.Sprintf("%s:%s (secret must be named %s-*)", s.Secret, s.Key, p.Name))
+		}
+	}
+	if len(missing) > 0 {
+		add(checkSecrets, beadsapi.CheckFailed, "unresolved: "+strings.Join(missing, ", "))
+		skipPod("unresolved secrets")
+		return report
+	}
+	add(checkSecrets, beadsapi.CheckPassed, fmt.Sprintf("%d secret references resolved", len(secretRefs(spec))))
+
+	for _, check := range o.smokeTest(ctx, spec) {
+		add(check.Name, check.Status, check.Detail)
+	}
+	return report
+}
+
+// onboardingSpec is the pod spec of project's smoke-test agent: a job
+// agent built like the project's real ones, running a scripted mock
+// session instead of Claude.
+func onboardingSpec(cfg *config.Config, project string) podmanager.AgentPodSpec {
+	return specbuilder.FromBead(cfg, project, "job", "onboard", "smoke", map[string]string{"mock_scenario": "basic-echo"})
+}
+
+// secretRefs returns the "secret:key" references spec's pod resolves.
+func secretRefs(spec podmanager.AgentPodSpec) []string {
+	refs := make(map[string]bool)
+	for _, s := range spec.SecretEnv {
+		refs[s.SecretName+":"+s.SecretKey] = true
+	}
+	if spec.GitCredentialsSecret != "" {
+		refs[spec.GitCredentialsSecret+":username"] = true
+		refs[spec.GitCredentialsSecret+":token"] = true
+	}
+	if spec.GitlabTokenSecret != "" {
+		refs[spec.GitlabTokenSecret+":token"] = true
+	}
+	if spec.CredentialsSecret != "" {
+		refs[spec.CredentialsSecret+":credentials.json"] = true
+	}
+	if spec.DaemonTokenSecret != "" {
+		refs[spec.DaemonTokenSecret+":token"] = true
+	}
+	out := make([]string, 0, len(refs))
+	for ref := range refs {
+		out = append(out, ref)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// missingSecrets returns the secret references of spec that don't resolve
+// in its namespace, with why.
+func (o *onboarder) missingSecrets(ctx context.Context, spec podmanager.AgentPodSpec) []string {
+	secrets := make(map[string]*corev1.Secret)
+	errs := make(map[string]error)
+	var missing []string
+	for _, ref := range secretRefs(spec) {
+		name, key, _ := strings.Cut(ref, ":")
+		if _, seen := secrets[name]; !seen {
+			secrets[name], errs[name] = o.client.CoreV1().Secrets(spec.Namespace).Get(ctx, name, metav1.GetOptions{})
+		}
+		if err := errs[name]; err != nil {
+			missing = append(missing, fmt.Sprintf("%s (%v)", ref, err))
+			continue
+		}
+		if _, ok := secrets[name].Data[key]; !ok {
+			missing = append(missing, ref+" (no such key)")
+		}
+	}
+	return missing
+}
+
+// smokeTest runs spec's smoke-test pod until the agent is ready, fails or
+// the onboarding timeout passes, and deletes it.
+func (o *onboarder) smokeTest(ctx context.Context, spec podmanager.AgentPodSpec) []beadsapi.OnboardingCheck {
+	cloning := spec.GitURL != "" || len(spec.ReferenceRepos) > 0
+	fail := func(err error) []beadsapi.OnboardingCheck {
+		return []beadsapi.OnboardingCheck{
+			{Name: checkImage, Status: beadsapi.CheckSkipped, Detail: "smoke-test pod not started"},
+			{Name: checkClone, Status: beadsapi.CheckSkipped, Detail: "smoke-test pod not started"},
+			{Name: checkSmoke, Status: beadsapi.CheckFailed, Detail: err.Error()},
+		}
+	}
+
+	// Drop pods a previous leader left behind.
+	if stale, err := o.pods.ListOnboardingPods(ctx, spec.Namespace, spec.Project); err == nil {
+		for _, pod := range stale {
+			_ = o.pods.DeleteAgentPod(ctx, pod.Name, pod.Namespace)
+		}
+	}
+	pod, err := o.pods.CreateOnboardingPod(ctx, spec)
+	if err != nil {
+		return fail(err)
+	}
+	defer func() {
+		// Clean up even when the leader context is gone.
+		cleanup, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+		defer cancel()
+		if err := o.pods.DeleteAgentPod(cleanup, pod.Name, pod.Namespace); err != nil {
+			o.logger.Warn("failed to delete onboarding pod", "pod", pod.Name, "error", err)
+		}
+	}()
+
+	deadline := time.Now().Add(o.timeout)
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	for {
+		checks, done := assessOnboardingPod(pod, cloning)
+		if done {
+			return o.withCloneLog(ctx, pod, checks)
+		}
+		if time.Now().After(deadline) {
+			for i := range checks {
+				if checks[i].Status == "" {
+					checks[i].Status = beadsapi.CheckFailed
+					checks[i].Detail = fmt.Sprintf("not finished within %s", o.timeout)
+				}
+			}
+			return checks
+		}
+		select {
+		case <-ctx.Done():
+			return fail(ctx.Err())
+		case <-ticker.C:
+		}
+		latest, err := o.pods.GetAgentPod(ctx, pod.Name, pod.Namespace)
+		if err != nil {
+			return fail(fmt.Errorf("smoke-test pod disappeared: %w", err))
+		}
+		pod = latest
+	}
+}
+
+// assessOnboardingPod reads the image, clone and smoke checks off the
+// smoke-test pod's status. Checks still undecided have an empty status;
+// done is set once the smoke check is decided.
+func assessOnboardingPod(pod *corev1.Pod, cloning bool) ([]beadsapi.OnboardingCheck, bool) {
+	image := beadsapi.OnboardingCheck{Name: checkImage}
+	clone := beadsapi.OnboardingCheck{Name: checkClone}
+	smoke := beadsapi.OnboardingCheck{Name: checkSmoke}
+	if !cloning {
+		clone.Status, clone.Detail = beadsapi.CheckSkipped, "project has no repos"
+	}
+
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name != podmanager.InitCloneName {
+			continue
+		}
+		if t := cs.State.Terminated; t != nil {
+			if t.ExitCode == 0 {
+				clone.Status = beadsapi.CheckPassed
+			} else {
+				clone.Status = beadsapi.CheckFailed
+				clone.Detail = fmt.Sprintf("clone exited %d (%s)", t.ExitCode, t.Reason)
+				smoke.Status, smoke.Detail = beadsapi.CheckSkipped, "clone failed"
+			}
+		}
+		if w := cs.State.Waiting; w != nil && imagePullFailure(w.Reason) {
+			clone.Status = beadsapi.CheckFailed
+			clone.Detail = fmt.Sprintf("clone image: %s: %s", w.Reason, w.Message)
+			smoke.Status, smoke.Detail = beadsapi.CheckSkipped, "clone failed"
+		}
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.Name != podmanager.ContainerName {
+			continue
+		}
+		switch {
+		case cs.State.Running != nil || cs.State.Terminated != nil:
+			image.Status, image.Detail = beadsapi.CheckPassed, cs.ImageID
+		case cs.State.Waiting != nil && imagePullFailure(cs.State.Waiting.Reason):
+			image.Status = beadsapi.CheckFailed
+			image.Detail = fmt.Sprintf("%s: %s", cs.State.Waiting.Reason, cs.State.Waiting.Message)
+			smoke.Status, smoke.Detail = beadsapi.CheckSkipped, "image not pulled"
+		case cs.State.Waiting != nil && cs.State.Waiting.Reason == "CreateContainerConfigError":
+			smoke.Status = beadsapi.CheckFailed
+			smoke.Detail = cs.State.Waiting.Message
+		}
+		if t := cs.State.Terminated; t != nil && smoke.Status == "" {
+			smoke.Status = beadsapi.CheckFailed
+			smoke.Detail = fmt.Sprintf("agent exited %d (%s) before becoming ready", t.ExitCode, t.Reason)
+		}
+	}
+	if smoke.Status == "" && reconciler.IsPodReady(pod) {
+		smoke.Status, smoke.Detail = beadsapi.CheckPassed, "agent became ready"
+	}
+	if smoke.Status == "" && pod.Status.Phase == corev1.PodFailed {
+		smoke.Status, smoke.Detail = beadsapi.CheckFailed, "pod failed: "+pod.Status.Message
+	}
+
+	checks := []beadsapi.OnboardingCheck{image, clone, smoke}
+	if smoke.Status == "" {
+		return checks, false
+	}
+	// Nothing more will be learned about checks still undecided.
+	for i := range checks {
+		if checks[i].Status == "" {
+			checks[i].Status, checks[i].Detail = beadsapi.CheckSkipped, "not reached"
+		}
+	}
+	return checks, true
+}
+
+// withCloneLog appends the tail of the clone log to a failed clone check.
+func (o *onboarder) withCloneLog(ctx context.Context, pod *corev1.Pod, checks []beadsapi.OnboardingCheck) []beadsapi.OnboardingCheck {
+	for i, c := range checks {
+		if c.Name != checkClone || c.Status != beadsapi.CheckFailed {
+			continue
+		}
+		lines := int64(onboardingLogLines)
+		stream, err := o.client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: podmanager.InitCloneName,
+			TailLines: &lines,
+		}).Stream(ctx)
+		if err != nil {
+			continue
+		}
+		data, _ := io.ReadAll(io.LimitReader(stream, 8<<10))
+		stream.Close()
+		if log := strings.TrimSpace(string(data)); log != "" {
+			checks[i].Detail += "\n" + log
+		}
+	}
+	return checks
+}
+
+// imagePullFailure reports whether a container waiting reason means its
+// image can't be pulled.
+func imagePullFailure(reason string) bool {
+	switch reason {
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull":
+		return true
+	}
+	return false
+}
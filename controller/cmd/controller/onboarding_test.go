@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+// onboardingProjects is an onboardingStore over one in-memory project.
+type onboardingProjects struct {
+	mu      sync.Mutex
+	project beadsapi.ProjectInfo
+	fields  map[string]string
+}
+
+func (s *onboardingProjects) ListProjectBeads(context.Context) (map[string]beadsapi.ProjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]beadsapi.ProjectInfo{s.project.Name: s.project}, nil
+}
+
+func (s *onboardingProjects) UpdateBeadFields(_ context.Context, _ string, fields map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, v := range fields {
+		s.fields[k] = v
+	}
+	s.project.Onboarding = s.fields[beadsapi.OnboardingField]
+	return nil
+}
+
+func (s *onboardingProjects) state() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fields[beadsapi.OnboardingField]
+}
+
+func onboardingConfig() *config.Config {
+	return &config.Config{
+		Namespace:             "gasboat",
+		CoopImage:             "ghcr.io/org/agent:v1",
+		AnthropicApiKeySecret: "anthropic",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"api": {
+				GitURL:  "https://github.com/org/api.git",
+				Secrets: []beadsapi.SecretEntry{{Env: "NPM_TOKEN", Secret: "api-npm", Key: "token"}},
+			},
+		}),
+	}
+}
+
+func TestOnboarder_PassesHealthyProject(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "anthropic", Namespace: "gasboat"}, Data: map[string][]byte{"key": []byte("k")}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "api-npm", Namespace: "gasboat"}, Data: map[string][]byte{"token": []byte("t")}},
+	)
+	store := &onboardingProjects{
+		project: beadsapi.ProjectInfo{ID: "bd-api", Name: "api", GitURL: "https://github.com/org/api.git", Onboarding: beadsapi.OnboardingPending},
+		fields:  map[string]string{},
+	}
+	finished := make(chan struct{})
+	o := newOnboarder(podmanager.New(client, quietLogger()), client, store, time.Minute, quietLogger(), func() { close(finished) })
+	o.interval = 10 * time.Millisecond
+	ctx := context.Background()
+
+	if err := o.sweep(ctx, onboardingConfig()); err != nil {
+		t.Fatal(err)
+	}
+	// The smoke-test agent clones, starts and becomes ready.
+	var pod *corev1.Pod
+	for deadline := time.Now().Add(5 * time.Second); pod == nil; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("no onboarding pod created")
+		}
+		list, _ := client.CoreV1().Pods("gasboat").List(ctx, metav1.ListOptions{LabelSelector: podmanager.LabelOnboarding + "=true"})
+		if len(list.Items) == 1 {
+			pod = &list.Items[0]
+		}
+	}
+	if _, agent := pod.Labels[podmanager.LabelAgent]; agent {
+		t.Error("onboarding pod is labeled as an agent pod")
+	}
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{Name: podmanager.InitCloneName,
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: podmanager.ContainerName, ImageID: "sha256:abc",
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}}
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	if _, err := client.CoreV1().Pods("gasboat").UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("onboarding did not finish")
+	}
+	if got := store.state(); got != beadsapi.OnboardingPassed {
+		t.Fatalf("onboarding = %s; report %s", got, store.fields[beadsapi.OnboardingReportField])
+	}
+	report := beadsapi.OnboardingReportFromFields(store.fields)
+	if report == nil || len(report.Checks) != 5 {
+		t.Fatalf("report = %+v", report)
+	}
+	for _, c := range report.Checks {
+		if c.Status != beadsapi.CheckPassed {
+			t.Errorf("check %s = %s (%s)", c.Name, c.Status, c.Detail)
+		}
+	}
+	if list, _ := client.CoreV1().Pods("gasboat").List(ctx, metav1.ListOptions{}); len(list.Items) != 0 {
+		t.Error("smoke-test pod not deleted")
+	}
+}
+
+func TestOnboarder_FailsOnMissingSecrets(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "anthropic", Namespace: "gasboat"}, Data: map[string][]byte{"other": nil}},
+	)
+	o := newOnboarder(podmanager.New(client, quietLogger()), client, nil, time.Minute, quietLogger(), func() {})
+
+	project := beadsapi.ProjectInfo{Name: "api", Secrets: []beadsapi.SecretEntry{
+		{Env: "NPM_TOKEN", Secret: "api-npm", Key: "token"},
+		{Env: "SHARED", Secret: "shared-creds", Key: "token"},
+	}}
+	report := o.validate(context.Background(), onboardingConfig(), project)
+	if report.Passed() {
+		t.Fatal("onboarding passed with missing secrets")
+	}
+	secrets := report.Checks[1]
+	if secrets.Name != checkSecrets || secrets.Status != beadsapi.CheckFailed ||
+		!strings.Contains(secrets.Detail, "anthropic:key (no such key)") || !strings.Contains(secrets.Detail, "api-npm:token (secrets \"api-npm\" not found)") ||
+		!strings.Contains(secrets.Detail, "shared-creds:token (secret must be named api-*)") {
+		t.Errorf("secrets check = %+v", secrets)
+	}
+	if list, _ := client.CoreV1().Pods("gasboat").List(context.Background(), metav1.ListOptions{}); len(list.Items) != 0 {
+		t.Error("smoke-test pod started despite unresolved secrets")
+	}
+}
+
+func TestAssessOnboardingPod(t *testing.T) {
+	waiting := func(reason string) corev1.ContainerState {
+		return corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason, Message: "detail"}}
+	}
+	for _, tc := range []struct {
+		name    string
+		init    *corev1.ContainerState
+		main    *corev1.ContainerState
+		done    bool
+		results string // image/clone/smoke statuses
+	}{
+		{"starting", nil, nil, false, "//"},
+		{"clone failed", &corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 128, Reason: "Error"}}, nil, true, "skip/fail/skip"},
+		{"image not pulled", nil, ptr(waiting("ImagePullBackOff")), true, "fail/skip/skip"},
+		{"secret missing", nil, ptr(waiting("CreateContainerConfigError")), true, "skip/skip/fail"},
+		{"agent crashed", nil, &corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}, true, "pass/skip/fail"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{}
+			if tc.init != nil {
+				pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{Name: podmanager.InitCloneName, State: *tc.init}}
+			}
+			if tc.main != nil {
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: podmanager.ContainerName, State: *tc.main}}
+			}
+			checks, done := assessOnboardingPod(pod, true)
+			var got []string
+			for _, c := range checks {
+				got = append(got, c.Status)
+			}
+			if done != tc.done || strings.Join(got, "/") != tc.results {
+				t.Errorf("done = %v, checks = %s; want %v, %s", done, strings.Join(got, "/"), tc.done, tc.results)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/specbuilder"
+)
+
+// spawnPreview is the resolved pod configuration for a prospective agent,
+// served on /spawn-preview so the Slack bridge can show it before spawning.
+type spawnPreview struct {
+	Project        string `json:"project"`
+	Role           string `json:"role"`
+	Mode           string `json:"mode"`
+	Image          string `json:"image"`
+	Namespace      string `json:"namespace"`
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	StorageClass   string `json:"storageClass,omitempty"`
+	StorageSize    string `json:"storageSize,omitempty"`
+	CPURequest     string `json:"cpuRequest,omitempty"`
+	CPULimit       string `json:"cpuLimit,omitempty"`
+	MemoryRequest  string `json:"memoryRequest,omitempty"`
+	MemoryLimit    string `json:"memoryLimit,omitempty"`
+	GitURL         string `json:"gitURL,omitempty"`
+	GitBranch      string `json:"gitBranch,omitempty"`
+	KnownProject   bool   `json:"knownProject"`
+}
+
+// buildSpawnPreview resolves the pod spec an agent would get without creating
+// anything, using the same path as the reconciler.
+func buildSpawnPreview(cfg *config.Config, project, role, agentName string) spawnPreview {
+	spec := specbuilder.FromBead(cfg, project, "", role, agentName, nil)
+	_, known := cfg.ProjectCache.Load()[project]
+
+	p := spawnPreview{
+		Project:        project,
+		Role:           role,
+		Mode:           spec.Mode,
+		Image:          spec.Image,
+		Namespace:      spec.Namespace,
+		ServiceAccount: spec.ServiceAccountName,
+		GitURL:         spec.GitURL,
+		GitBranch:      spec.GitDefaultBranch,
+		KnownProject:   known,
+	}
+	if spec.WorkspaceStorage != nil {
+		p.StorageClass = spec.WorkspaceStorage.StorageClassName
+		p.StorageSize = spec.WorkspaceStorage.Size
+	}
+	if r := spec.Resources; r != nil {
+		if q, ok := r.Requests[corev1.ResourceCPU]; ok {
+			p.CPURequest = q.String()
+		}
+		if q, ok := r.Limits[corev1.ResourceCPU]; ok {
+			p.CPULimit = q.String()
+		}
+		if q, ok := r.Requests[corev1.ResourceMemory]; ok {
+			p.MemoryRequest = q.String()
+		}
+		if q, ok := r.Limits[corev1.ResourceMemory]; ok {
+			p.MemoryLimit = q.String()
+		}
+	}
+	return p
+}
+
+// spawnPreviewHandler serves GET /spawn-preview?project=&role=&agent=.
+// The preview exposes project git URLs and pod settings, so requests must
+// carry "Authorization: Bearer <token>".
+func spawnPreviewHandler(cfg *config.Config, token string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		q := r.URL.Query()
+		preview := buildSpawnPreview(cfg, q.Get("project"), q.Get("role"), q.Get("agent"))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(preview)
+	}
+}
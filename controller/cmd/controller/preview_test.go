@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gasboat/controller/internal/config"
+)
+
+func TestBuildSpawnPreview_AppliesProjectOverrides(t *testing.T) {
+	cfg := &config.Config{
+		CoopImage:         "ghcr.io/org/agent:latest",
+		Namespace:         "gasboat",
+		AgentStorageClass: "gp3",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"beads": {
+				Image:         "ghcr.io/org/beads-agent:v2",
+				StorageClass:  "fast-ssd",
+				GitURL:        "https://github.com/org/beads.git",
+				DefaultBranch: "develop",
+			},
+		}),
+	}
+
+	p := buildSpawnPreview(cfg, "beads", "crew", "my-bot")
+	if !p.KnownProject {
+		t.Error("expected known project")
+	}
+	if p.Image != "ghcr.io/org/beads-agent:v2" {
+		t.Errorf("expected project image override, got %s", p.Image)
+	}
+	if p.StorageClass != "fast-ssd" {
+		t.Errorf("expected project storage class, got %s", p.StorageClass)
+	}
+	if p.Mode != "crew" || p.StorageSize == "" {
+		t.Errorf("expected crew mode with workspace storage, got mode=%s size=%s", p.Mode, p.StorageSize)
+	}
+	if p.CPURequest == "" || p.MemoryLimit == "" {
+		t.Errorf("expected default resources, got %+v", p)
+	}
+	if p.GitURL != "https://github.com/org/beads.git" || p.GitBranch != "develop" {
+		t.Errorf("expected git info from project, got %s@%s", p.GitURL, p.GitBranch)
+	}
+}
+
+func TestBuildSpawnPreview_UnknownProjectUsesDefaults(t *testing.T) {
+	cfg := &config.Config{
+		CoopImage:    "ghcr.io/org/agent:latest",
+		Namespace:    "gasboat",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{}),
+	}
+
+	p := buildSpawnPreview(cfg, "nope", "job", "x")
+	if p.KnownProject {
+		t.Error("expected unknown project")
+	}
+	if p.Image != "ghcr.io/org/agent:latest" {
+		t.Errorf("expected controller default image, got %s", p.Image)
+	}
+	if p.Mode != "job" || p.StorageSize != "" {
+		t.Errorf("expected job mode without workspace storage, got mode=%s size=%s", p.Mode, p.StorageSize)
+	}
+}
+
+func TestSpawnPreviewHandler(t *testing.T) {
+	cfg := &config.Config{CoopImage: "img", Namespace: "ns", ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{})}
+	srv := httptest.NewServer(spawnPreviewHandler(cfg, "s3cret"))
+	defer srv.Close()
+
+	get := func(token string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"?project=p&role=crew&agent=a", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		resp := get(token)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, resp.StatusCode)
+		}
+	}
+
+	resp := get("s3cret")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var p spawnPreview
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Project != "p" || p.Image != "img" || p.Namespace != "ns" {
+		t.Errorf("unexpected preview: %+v", p)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/prestop"
+)
+
+// projectTombstoneTTL is how long a project missing from the daemon's
+// listing stays in the cache, so a listing that briefly drops a project
+// doesn't strip its agents' settings on the next spawn.
+const projectTombstoneTTL = 5 * time.Minute
+
+// projectLister lists project beads conditionally (beadsapi.Client).
+type projectLister interface {
+	ListProjectBeadsIfChanged(ctx context.Context, etag string) (map[string]beadsapi.ProjectInfo, string, error)
+}
+
+// projectCache keeps cfg.ProjectCache in step with the daemon's project
+// beads. It sends the ETag of the last listing, so passes where no project
+// changed cost a 304, rebuilds only the entries of projects that changed,
+// and logs each addition, change and removal.
+type projectCache struct {
+	lister projectLister
+	logger *slog.Logger
+	now    func() time.Time
+
+	etag      string
+	projects  map[string]beadsapi.ProjectInfo // Last listing; nil before the first
+	removedAt map[string]time.Time            // Tombstones of projects gone from the listing
+}
+
+func newProjectCache(lister projectLister, logger *slog.Logger) *projectCache {
+	return &projectCache{
+		lister:    lister,
+		logger:    logger,
+		now:       time.Now,
+		removedAt: make(map[string]time.Time),
+	}
+}
+
+// refresh lists project beads and applies the changes since the last
+// listing to a copy of cfg.ProjectCache, which it then publishes; event
+// workers and HTTP handlers read the published map concurrently.
+func (c *projectCache) refresh(ctx context.Context, cfg *config.Config) {
+	now := c.now()
+	projects, etag, err := c.lister.ListProjectBeadsIfChanged(ctx, c.etag)
+	cache := maps.Clone(cfg.ProjectCache.Load())
+	if cache == nil {
+		cache = make(map[string]config.ProjectCacheEntry)
+	}
+	switch {
+	case errors.Is(err, beadsapi.ErrNotModified):
+		c.logger.Debug("project cache unchanged", "count", len(c.projects))
+	case err != nil:
+		c.logger.Warn("failed to refresh project cache", "error", err)
+		return
+	default:
+		c.apply(cache, projects, now)
+		c.etag = etag
+	}
+
+	for name, at := range c.removedAt {
+		if now.Sub(at) >= projectTombstoneTTL {
+			delete(cache, name)
+			delete(c.removedAt, name)
+			c.logger.Info("dropped removed project from cache", "project", name)
+		}
+	}
+	// The budget pause lapses at the end of the day without a change to
+	// the project bead.
+	for name, info := range c.projects {
+		if entry, ok := cache[name]; ok {
+			entry.BudgetPaused = info.BudgetPaused(now)
+			cache[name] = entry
+		}
+	}
+	cfg.ProjectCache.Store(cache)
+}
+
+// apply updates cache from a full listing.
+func (c *projectCache) apply(cache map[string]config.ProjectCacheEntry, projects map[string]beadsapi.ProjectInfo, now time.Time) {
+	first := c.projects == nil
+	var added, changed, removed int
+	for name, info := range projects {
+		old, known := c.projects[name]
+		_, cached := cache[name]
+		if known && cached && reflect.DeepEqual(old, info) {
+			continue
+		}
+		cache[name] = projectCacheEntry(c.logger, name, info, now)
+		delete(c.removedAt, name)
+		switch {
+		case first:
+		case !known:
+			added++
+			c.logger.Info("project added", "project", name, "bead", info.ID)
+		default:
+			changed++
+			c.logger.Info("project changed", "project", name, "bead", info.ID, "fields", changedProjectFields(old, info))
+		}
+	}
+	for name := range c.projects {
+		if _, ok := projects[name]; ok {
+			continue
+		}
+		if _, ok := c.removedAt[name]; !ok {
+			removed++
+			c.removedAt[name] = now
+			c.logger.Info("project removed", "project", name, "dropped_in", projectTombstoneTTL)
+		}
+	}
+	c.projects = projects
+
+	if first || added+changed+removed > 0 {
+		c.logger.Info("refreshed project cache", "count", len(projects),
+			"added", added, "changed", changed, "removed", removed)
+	} else {
+		c.logger.Debug("project cache unchanged", "count", len(projects))
+	}
+}
+
+// changedProjectFields returns the names of the project bead fields that
+// differ between old and info, for logging, including the state the
+// controller keeps on the bead.
+func changedProjectFields(old, info beadsapi.ProjectInfo) []string {
+	before, after := old.Fields(), info.Fields()
+	var names []string
+	for name, v := range after {
+		if before[name] != v {
+			names = append(names, name)
+		}
+	}
+	if old.Onboarding != info.Onboarding {
+		names = append(names, beadsapi.OnboardingField)
+	}
+	for field, same := range map[string]bool{
+		beadsapi.BudgetStatusField:   reflect.DeepEqual(old.Budget, info.Budget),
+		beadsapi.BudgetResetField:    reflect.DeepEqual(old.BudgetReset, info.BudgetReset),
+		beadsapi.BudgetOverrideField: old.BudgetOverrideUntil == info.BudgetOverrideUntil,
+	} {
+		if !same {
+			names = append(names, field)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// projectCacheEntry converts a project bead into its cache entry, dropping
+// a malformed termination grace and preStop hook.
+func projectCacheEntry(logger *slog.Logger, name string, info beadsapi.ProjectInfo, now time.Time) config.ProjectCacheEntry {
+	grace, _ := beadsapi.ParseTerminationGrace(info.TerminationGrace)
+	hook := info.PreStop
+	if err := prestop.Validate(hook); err != nil {
+		logger.Warn("invalid project preStop hook, using the role's", "project", name, "error", err)
+		hook = ""
+	}
+	return config.ProjectCacheEntry{
+		Prefix:           info.Prefix,
+		GitURL:           info.GitURL,
+		DefaultBranch:    info.DefaultBranch,
+		Image:            info.Image,
+		StorageClass:     info.StorageClass,
+		ServiceAccount:   info.ServiceAccount,
+		RTKEnabled:       info.RTKEnabled,
+		ReconcilePaused:  info.ReconcilePaused,
+		Secrets:          info.Secrets,
+		Repos:            info.Repos,
+		Cluster:          info.Cluster,
+		Schedule:         info.Schedule,
+		Arch:             info.Arch,
+		Onboarding:       info.Onboarding,
+		BudgetPaused:     info.BudgetPaused(now),
+		TerminationGrace: grace,
+		PreStop:          hook,
+		HostAliases:      info.HostAliases,
+		DNSPolicy:        corev1.DNSPolicy(info.DNSPolicy),
+		DNSConfig:        info.DNSConfig,
+		HTTPProxy:        info.HTTPProxy,
+		HTTPSProxy:       info.HTTPSProxy,
+		NoProxy:          info.NoProxy,
+	}
+}
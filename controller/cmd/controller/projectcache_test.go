@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"strconv"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+)
+
+// fakeProjectLister serves projects under an ETag that changes with them,
+// answering ErrNotModified to the current one.
+type fakeProjectLister struct {
+	projects map[string]beadsapi.ProjectInfo
+	version  int
+	lists    int // Full listings served
+}
+
+func (f *fakeProjectLister) set(projects ...beadsapi.ProjectInfo) {
+	f.projects = make(map[string]beadsapi.ProjectInfo)
+	for _, p := range projects {
+		f.projects[p.Name] = p
+	}
+	f.version++
+}
+
+func (f *fakeProjectLister) ListProjectBeadsIfChanged(_ context.Context, etag string) (map[string]beadsapi.ProjectInfo, string, error) {
+	current := strconv.Itoa(f.version)
+	if etag == current {
+		return nil, etag, beadsapi.ErrNotModified
+	}
+	f.lists++
+	return f.projects, current, nil
+}
+
+func TestProjectCache_ConditionalRefresh(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	lister := &fakeProjectLister{}
+	lister.set(
+		beadsapi.ProjectInfo{ID: "bd-api", Name: "api", Image: "api:v1"},
+		beadsapi.ProjectInfo{ID: "bd-web", Name: "web", Image: "web:v1"},
+	)
+	c := newProjectCache(lister, slog.Default())
+	c.now = func() time.Time { return now }
+	cfg := &config.Config{ProjectCache: config.NewSnapshot(make(map[string]config.ProjectCacheEntry))}
+
+	c.refresh(context.Background(), cfg)
+	c.refresh(context.Background(), cfg)
+	if lister.lists != 1 {
+		t.Errorf("listed %d times, want the unchanged listing served once", lister.lists)
+	}
+	if cfg.ProjectCache.Load()["api"].Image != "api:v1" || cfg.ProjectCache.Load()["web"].Image != "web:v1" {
+		t.Fatalf("cache = %+v", cfg.ProjectCache.Load())
+	}
+
+	// Entries of unchanged projects are kept as they are.
+	cache := maps.Clone(cfg.ProjectCache.Load())
+	web := cache["web"]
+	web.BudgetPaused = true
+	cache["web"] = web
+	cfg.ProjectCache.Store(cache)
+	lister.set(
+		beadsapi.ProjectInfo{ID: "bd-api", Name: "api", Image: "api:v2"},
+		beadsapi.ProjectInfo{ID: "bd-web", Name: "web", Image: "web:v1"},
+	)
+	c.refresh(context.Background(), cfg)
+	if got := cfg.ProjectCache.Load()["api"].Image; got != "api:v2" {
+		t.Errorf("api image = %q, want api:v2", got)
+	}
+	if cfg.ProjectCache.Load()["web"].Image != "web:v1" || cfg.ProjectCache.Load()["web"].BudgetPaused {
+		t.Errorf("web entry = %+v, want unchanged with the budget pause recomputed", cfg.ProjectCache.Load()["web"])
+	}
+}
+
+func TestProjectCache_TombstonesRemovedProjects(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	lister := &fakeProjectLister{}
+	lister.set(
+		beadsapi.ProjectInfo{ID: "bd-api", Name: "api"},
+		beadsapi.ProjectInfo{ID: "bd-web", Name: "web"},
+	)
+	c := newProjectCache(lister, slog.Default())
+	c.now = func() time.Time { return now }
+	cfg := &config.Config{ProjectCache: config.NewSnapshot(make(map[string]config.ProjectCacheEntry))}
+	c.refresh(context.Background(), cfg)
+
+	// Both projects drop out of the listing; only api stays gone.
+	lister.set()
+	c.refresh(context.Background(), cfg)
+	if len(cfg.ProjectCache.Load()) != 2 {
+		t.Errorf("cache = %+v, want removed projects kept until their tombstones expire", cfg.ProjectCache.Load())
+	}
+	now = now.Add(time.Minute)
+	lister.set(beadsapi.ProjectInfo{ID: "bd-web", Name: "web"})
+	c.refresh(context.Background(), cfg)
+
+	now = now.Add(projectTombstoneTTL)
+	c.refresh(context.Background(), cfg)
+	if _, ok := cfg.ProjectCache.Load()["api"]; ok {
+		t.Error("removed project api still cached after its tombstone expired")
+	}
+	if _, ok := cfg.ProjectCache.Load()["web"]; !ok {
+		t.Error("project web, back in the listing, was dropped")
+	}
+}
+
+func TestChangedProjectFields(t *testing.T) {
+	old := beadsapi.ProjectInfo{Name: "api", Image: "api:v1", Onboarding: "pending"}
+	info := beadsapi.ProjectInfo{Name: "api", Image: "api:v2", HTTPProxy: "http://proxy:3128", Onboarding: "ready"}
+	got := changedProjectFields(old, info)
+	want := []string{beadsapi.HTTPProxyField, "image", beadsapi.OnboardingField}
+	if len(got) != len(want) {
+		t.Fatalf("changed = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("changed = %v, want %v", got, want)
+			break
+		}
+	}
+}
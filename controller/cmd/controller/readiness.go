@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// readinessCheckTimeout bounds each subsystem check so one hung dependency
+// can't stall /readyz.
+const readinessCheckTimeout = 3 * time.Second
+
+// readinessCheck reports one subsystem's health: a short detail, and a
+// non-nil error when the subsystem is unhealthy.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) (string, error)
+}
+
+// subsystemStatus is one subsystem's entry in the /readyz response.
+type subsystemStatus struct {
+	Status string `json:"status"` // "ok" or "failing"
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// readinessReport is the /readyz response body.
+type readinessReport struct {
+	Status     string                     `json:"status"` // "ready" or "not_ready"
+	Subsystems map[string]subsystemStatus `json:"subsystems"`
+}
+
+// checkReadiness runs all checks concurrently. The controller is ready only
+// if every subsystem is ok.
+func checkReadiness(ctx context.Context, checks []readinessCheck) readinessReport {
+	report := readinessReport{Status: "ready", Subsystems: make(map[string]subsystemStatus, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+			defer cancel()
+			detail, err := c.check(cctx)
+			st := subsystemStatus{Status: "ok", Detail: detail}
+			if err != nil {
+				st.Status = "failing"
+				st.Error = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			report.Subsystems[c.name] = st
+			if err != nil {
+				report.Status = "not_ready"
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// readyzHandler serves GET /readyz: 200 when every subsystem is ok, 503
+// otherwise, with per-subsystem detail either way. Unlike /healthz, which
+// only says the process is up, it fails while dependencies are unhealthy.
+func readyzHandler(checks []readinessCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := checkReadiness(r.Context(), checks)
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != "ready" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
+
+// connectionReporter reports whether the beads event stream is connected.
+type connectionReporter interface {
+	Connected() (bool, time.Time)
+}
+
+// reconcileReporter reports when reconciliation last succeeded.
+type reconcileReporter interface {
+	LastSuccess() time.Time
+}
+
+// daemonPinger checks that the beads daemon is reachable.
+type daemonPinger interface {
+	Health(ctx context.Context) error
+}
+
+// controllerReadinessChecks returns the controller's subsystem checks. The
+// watcher and reconcile loop only run on the leader, so while active is
+// false those two report standby rather than failing. The home cluster is
+// checked when client is set.
+func controllerReadinessChecks(watcher connectionReporter, rec reconcileReporter, maxReconcileAge time.Duration, daemon daemonPinger, client kubernetes.Interface, namespace string, active *atomic.Bool) []readinessCheck {
+	checks := []readinessCheck{
+		{name: "watcher", check: func(context.Context) (string, error) {
+			if !active.Load() {
+				return "standby (not the leader)", nil
+			}
+			ok, since := watcher.Connected()
+			if !ok {
+				return "", errors.New("beads event stream disconnected")
+			}
+			return fmt.Sprintf("connected for %s", time.Since(since).Round(time.Second)), nil
+		}},
+		{name: "reconcile", check: func(context.Context) (string, error) {
+			if !active.Load() {
+				return "standby (not the leader)", nil
+			}
+			last := rec.LastSuccess()
+			if last.IsZero() {
+				return "", errors.New("no successful reconcile yet")
+			}
+			age := time.Since(last).Round(time.Second)
+			if age > maxReconcileAge {
+				return "", fmt.Errorf("last successful reconcile %s ago (max %s)", age, maxReconcileAge)
+			}
+			return fmt.Sprintf("last successful reconcile %s ago", age), nil
+		}},
+		{name: "daemon", check: func(ctx context.Context) (string, error) {
+			if err := daemon.Health(ctx); err != nil {
+				return "", err
+			}
+			return "reachable", nil
+		}},
+	}
+	if client != nil {
+		checks = append(checks, clusterReadinessCheck("kubernetes", client, namespace))
+	}
+	return checks
+}
+
+// clusterReadinessCheck checks that pods in namespace can be listed.
+func clusterReadinessCheck(name string, client kubernetes.Interface, namespace string) readinessCheck {
+	return readinessCheck{name: name, check: func(ctx context.Context) (string, error) {
+		if _, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+			return "", fmt.Errorf("listing pods: %w", err)
+		}
+		return "reachable", nil
+	}}
+}
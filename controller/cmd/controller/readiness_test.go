@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type fakeConnection struct {
+	connected bool
+	since     time.Time
+}
+
+func (f fakeConnection) Connected() (bool, time.Time) { return f.connected, f.since }
+
+type fakeReconcileReporter struct{ last time.Time }
+
+func (f fakeReconcileReporter) LastSuccess() time.Time { return f.last }
+
+type fakePinger struct{ err error }
+
+func (f fakePinger) Health(context.Context) error { return f.err }
+
+func serveReadyz(t *testing.T, checks []readinessCheck) (int, readinessReport) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	readyzHandler(checks)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report readinessReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decoding report: %v", err)
+	}
+	return rec.Code, report
+}
+
+func TestReadyz_AllHealthy(t *testing.T) {
+	var active atomic.Bool
+	active.Store(true)
+	now := time.Now()
+	checks := controllerReadinessChecks(
+		fakeConnection{connected: true, since: now.Add(-time.Minute)},
+		fakeReconcileReporter{last: now.Add(-30 * time.Second)}, 3*time.Minute,
+		fakePinger{}, fake.NewSimpleClientset(), "gasboat", &active)
+
+	code, report := serveReadyz(t, checks)
+	if code != http.StatusOK || report.Status != "ready" {
+		t.Fatalf("expected ready, got %d %+v", code, report)
+	}
+	for _, name := range []string{"watcher", "reconcile", "daemon", "kubernetes"} {
+		if st := report.Subsystems[name]; st.Status != "ok" || st.Detail == "" {
+			t.Errorf("%s: unexpected status %+v", name, st)
+		}
+	}
+}
+
+func TestReadyz_ReportsFailingSubsystems(t *testing.T) {
+	var active atomic.Bool
+	active.Store(true)
+	checks := controllerReadinessChecks(
+		fakeConnection{},
+		fakeReconcileReporter{last: time.Now().Add(-10 * time.Minute)}, 3*time.Minute,
+		fakePinger{err: errors.New("connection refused")}, fake.NewSimpleClientset(), "gasboat", &active)
+
+	code, report := serveReadyz(t, checks)
+	if code != http.StatusServiceUnavailable || report.Status != "not_ready" {
+		t.Fatalf("expected not ready, got %d %+v", code, report)
+	}
+	for _, name := range []string{"watcher", "reconcile", "daemon"} {
+		if st := report.Subsystems[name]; st.Status != "failing" || st.Error == "" {
+			t.Errorf("%s: expected failing with error, got %+v", name, st)
+		}
+	}
+	if st := report.Subsystems["kubernetes"]; st.Status != "ok" {
+		t.Errorf("kubernetes: expected ok, got %+v", st)
+	}
+}
+
+func TestReadyz_StandbyReplica(t *testing.T) {
+	var active atomic.Bool // not the leader: watcher and reconciler never start
+	checks := controllerReadinessChecks(
+		fakeConnection{}, fakeReconcileReporter{}, 3*time.Minute,
+		fakePinger{}, fake.NewSimpleClientset(), "gasboat", &active)
+
+	code, report := serveReadyz(t, checks)
+	if code != http.StatusOK {
+		t.Fatalf("standby replica should be ready, got %d %+v", code, report)
+	}
+	if st := report.Subsystems["reconcile"]; st.Detail != "standby (not the leader)" {
+		t.Errorf("unexpected reconcile status %+v", st)
+	}
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// statusProbeTimeout bounds each probe of the /status overview.
+const statusProbeTimeout = 3 * time.Second
+
+// statusStore is what the /status overview reads from the daemon
+// (beadsapi.Client).
+type statusStore interface {
+	Health(ctx context.Context) error
+	ListAgentBeads(ctx context.Context) ([]beadsapi.AgentBead, error)
+	ListBeadsFiltered(ctx context.Context, q beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error)
+}
+
+// systemStatus is the /status response: an overview of the whole system
+// for embedding in a status page.
+type systemStatus struct {
+	Status      string            `json:"status"` // "ok" or "degraded"
+	Version     string            `json:"version"`
+	GeneratedAt time.Time         `json:"generated_at"`
+	Components  map[string]string `json:"components"` // name → "ok", "standby", "unconfigured" or the failure
+	Agents      map[string]int    `json:"agents"`     // agent_state → count
+	Decisions   *int              `json:"pending_decisions"`
+	Incidents   *int              `json:"open_incidents"`
+	Reconcile   reconcileStatus   `json:"reconcile"`
+}
+
+// reconcileStatus describes the last successful reconcile pass.
+type reconcileStatus struct {
+	Leader     bool       `json:"leader"`
+	LastPass   *time.Time `json:"last_success,omitempty"`
+	AgeSeconds int64      `json:"age_seconds,omitempty"`
+}
+
+// statusReporter gathers the /status overview. Only the leader reconciles,
+// so on other replicas the reconcile entry says so.
+type statusReporter struct {
+	store     statusStore
+	rec       reconcileReporter
+	active    *atomic.Bool
+	bridgeURL string // slack-bridge base URL; empty = not probed
+	client    *http.Client
+	now       func() time.Time
+}
+
+func newStatusReporter(store statusStore, rec reconcileReporter, active *atomic.Bool, bridgeURL string) *statusReporter {
+	return &statusReporter{
+		store:     store,
+		rec:       rec,
+		active:    active,
+		bridgeURL: strings.TrimRight(bridgeURL, "/"),
+		client:    &http.Client{Timeout: statusProbeTimeout},
+		now:       time.Now,
+	}
+}
+
+// collect probes every part of the system concurrently. A probe that
+// fails marks the system degraded and leaves its counts out.
+func (s *statusReporter) collect(ctx context.Context) systemStatus {
+	st := systemStatus{
+		Status:      "ok",
+		Version:     version,
+		GeneratedAt: s.now().UTC(),
+		Components:  make(map[string]string),
+	}
+	var mu sync.Mutex
+	component := func(name string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			st.Components[name] = err.Error()
+			st.Status = "degraded"
+			return
+		}
+		st.Components[name] = "ok"
+	}
+
+	probes := []func(ctx context.Context){
+		func(ctx context.Context) {
+			component("daemon", s.store.Health(ctx))
+		},
+		func(ctx context.Context) {
+			if s.bridgeURL == "" {
+				mu.Lock()
+				st.Components["slack-bridge"] = "unconfigured"
+				mu.Unlock()
+				return
+			}
+			component("slack-bridge", s.probeBridge(ctx))
+		},
+		func(ctx context.Context) {
+			agents, err := s.store.ListAgentBeads(ctx)
+			if err != nil {
+				component("agents", err)
+				return
+			}
+			counts := make(map[string]int)
+			for _, a := range agents {
+				state := a.AgentState
+				if state == "" {
+					state = "unknown"
+				}
+				counts[state]++
+			}
+			mu.Lock()
+			st.Agents = counts
+			mu.Unlock()
+		},
+		func(ctx context.Context) {
+			n, err := s.count(ctx, beadsapi.ListBeadsQuery{Types: []string{"decision"}, Statuses: []string{"open"}})
+			if err != nil {
+				component("decisions", err)
+				return
+			}
+			mu.Lock()
+			st.Decisions = &n
+			mu.Unlock()
+		},
+		func(ctx context.Context) {
+			n, err := s.count(ctx, beadsapi.ListBeadsQuery{
+				Labels:   []string{"incident"},
+				Statuses: []string{"open", "in_progress", "blocked"},
+			})
+			if err != nil {
+				component("incidents", err)
+				return
+			}
+			mu.Lock()
+			st.Incidents = &n
+			mu.Unlock()
+		},
+	}
+	var wg sync.WaitGroup
+	for _, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, statusProbeTimeout)
+			defer cancel()
+			probe(pctx)
+		}()
+	}
+	wg.Wait()
+
+	st.Reconcile.Leader = s.active.Load()
+	if !st.Reconcile.Leader {
+		st.Components["reconcile"] = "standby"
+	} else if last := s.rec.LastSuccess(); last.IsZero() {
+		component("reconcile", fmt.Errorf("no successful reconcile yet"))
+	} else {
+		last = last.UTC()
+		st.Reconcile.LastPass = &last
+		st.Reconcile.AgeSeconds = int64(s.now().Sub(last).Seconds())
+		component("reconcile", nil)
+	}
+	return st
+}
+
+// count returns how many beads match q.
+func (s *statusReporter) count(ctx context.Context, q beadsapi.ListBeadsQuery) (int, error) {
+	q.Limit = 1
+	res, err := s.store.ListBeadsFiltered(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	return res.Total, nil
+}
+
+// probeBridge checks the slack-bridge's readiness, which fails while its
+// Slack connection is down.
+func (s *statusReporter) probeBridge(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.bridgeURL+"/readyz", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		if body.Reason != "" {
+			return fmt.Errorf("not ready: %s", body.Reason)
+		}
+		return fmt.Errorf("not ready: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// statusHandler serves GET /status: the system overview as JSON, or as an
+// HTML fragment with ?format=html or an Accept header preferring HTML. It
+// responds 200 even when degraded; the status field says which.
+func statusHandler(s *statusReporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		st := s.collect(r.Context())
+		if wantsHTML(r) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = statusPage.Execute(w, st)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(st)
+	}
+}
+
+func wantsHTML(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "html":
+		return true
+	case "json":
+		return false
+	}
+	return strings.HasPrefix(r.Header.Get("Accept"), "text/html")
+}
+
+// statusPage renders the overview for embedding; maps range in key order.
+var statusPage = template.Must(template.New("status").Parse(`<div class="gasboat-status gasboat-status-{{.Status}}">
+<h2>Gasboat: {{.Status}}</h2>
+<table>
+{{- range $name, $state := .Components}}
+<tr><th>{{$name}}</th><td>{{$state}}</td></tr>
+{{- end}}
+</table>
+<table>
+<tr><th>Pending decisions</th><td>{{with .Decisions}}{{.}}{{else}}unknown{{end}}</td></tr>
+<tr><th>Open incidents</th><td>{{with .Incidents}}{{.}}{{else}}unknown{{end}}</td></tr>
+<tr><th>Last reconcile</th><td>{{with .Reconcile.LastPass}}{{.Format "2006-01-02 15:04:05 UTC"}} ({{$.Reconcile.AgeSeconds}}s ago){{else}}none on this replica{{end}}</td></tr>
+</table>
+<table>
+<tr><th>Agent state</th><th>Agents</th></tr>
+{{- range $state, $n := .Agents}}
+<tr><td>{{$state}}</td><td>{{$n}}</td></tr>
+{{- end}}
+</table>
+<p>{{.Version}}, {{.GeneratedAt.Format "2006-01-02 15:04:05 UTC"}}</p>
+</div>
+`))
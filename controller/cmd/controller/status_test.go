@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// fakeStatusStore serves fixed agents and counts open decisions and
+// incidents by query type.
+type fakeStatusStore struct {
+	healthErr error
+	agents    []beadsapi.AgentBead
+	decisions int
+	incidents int
+}
+
+func (f *fakeStatusStore) Health(context.Context) error { return f.healthErr }
+
+func (f *fakeStatusStore) ListAgentBeads(context.Context) ([]beadsapi.AgentBead, error) {
+	return f.agents, nil
+}
+
+func (f *fakeStatusStore) ListBeadsFiltered(_ context.Context, q beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error) {
+	if len(q.Types) > 0 && q.Types[0] == "decision" {
+		return &beadsapi.ListBeadsResult{Total: f.decisions}, nil
+	}
+	return &beadsapi.ListBeadsResult{Total: f.incidents}, nil
+}
+
+func serveStatus(t *testing.T, s *statusReporter, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	statusHandler(s)(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status code = %d, want 200", rec.Code)
+	}
+	return rec
+}
+
+func TestStatus_Overview(t *testing.T) {
+	bridge := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"status":"not_ready","reason":"socket_mode_disconnected"}`))
+	}))
+	defer bridge.Close()
+
+	now := time.Now()
+	var active atomic.Bool
+	active.Store(true)
+	store := &fakeStatusStore{
+		agents: []beadsapi.AgentBead{
+			{AgentState: "working"}, {AgentState: "working"}, {AgentState: "done"}, {},
+		},
+		decisions: 4,
+		incidents: 1,
+	}
+	s := newStatusReporter(store, fakeReconcileReporter{last: now.Add(-time.Minute)}, &active, bridge.URL+"/")
+
+	var st systemStatus
+	if err := json.Unmarshal(serveStatus(t, s, "/status").Body.Bytes(), &st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Status != "degraded" || !strings.Contains(st.Components["slack-bridge"], "socket_mode_disconnected") {
+		t.Errorf("disconnected bridge not reported: %+v", st)
+	}
+	if st.Components["daemon"] != "ok" || st.Components["reconcile"] != "ok" {
+		t.Errorf("components = %v", st.Components)
+	}
+	if st.Agents["working"] != 2 || st.Agents["done"] != 1 || st.Agents["unknown"] != 1 {
+		t.Errorf("agents = %v", st.Agents)
+	}
+	if st.Decisions == nil || *st.Decisions != 4 || st.Incidents == nil || *st.Incidents != 1 {
+		t.Errorf("decisions = %v, incidents = %v", st.Decisions, st.Incidents)
+	}
+	if st.Reconcile.LastPass == nil || st.Reconcile.AgeSeconds < 59 {
+		t.Errorf("reconcile = %+v", st.Reconcile)
+	}
+
+	page := serveStatus(t, s, "/status?format=html")
+	if ct := page.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Errorf("content type = %q", ct)
+	}
+	for _, want := range []string{"Gasboat: degraded", "<td>working</td><td>2</td>", "Pending decisions</th><td>4"} {
+		if !strings.Contains(page.Body.String(), want) {
+			t.Errorf("page lacks %q:\n%s", want, page.Body.String())
+		}
+	}
+}
+
+func TestStatus_StandbyWithoutBridge(t *testing.T) {
+	var active atomic.Bool
+	store := &fakeStatusStore{healthErr: errors.New("connection refused")}
+	s := newStatusReporter(store, fakeReconcileReporter{}, &active, "")
+
+	st := s.collect(context.Background())
+	if st.Components["slack-bridge"] != "unconfigured" || st.Components["reconcile"] != "standby" {
+		t.Errorf("components = %v", st.Components)
+	}
+	if st.Status != "degraded" || st.Components["daemon"] != "connection refused" {
+		t.Errorf("unreachable daemon not reported: %+v", st)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/statusreporter"
+)
+
+// terminationNudge tells an agent its pod is about to be deleted; %s is how
+// long it has.
+const terminationNudge = "This agent is finished and its pod will be deleted in %s. " +
+	"Finish any in-flight git push now, start no new work, and run " +
+	"`gb yield --checkpoint --note \"terminating\"` so nothing is lost."
+
+// agentNudger sends a message to the agent in a pod (coopCheckpointer).
+type agentNudger interface {
+	Nudge(ctx context.Context, pod *corev1.Pod, message string) error
+}
+
+// podAnnotator is implemented by pod managers that can annotate running
+// pods (podmanager.K8sManager, podmanager.MultiCluster).
+type podAnnotator interface {
+	AnnotateAgentPod(ctx context.Context, pod *corev1.Pod, annotations map[string]string) error
+}
+
+// terminationGrace is the soft-delete handshake for agents that are done or
+// stopped: their bead is marked terminating, the agent is nudged to
+// checkpoint, and the pod is deleted once the grace window ends. The pod's
+// terminate-after annotation keeps the reconciler from deleting it early,
+// and lets it finish the job if the controller restarts mid-window. A nil
+// *terminationGrace deletes pods at once.
+type terminationGrace struct {
+	grace     time.Duration
+	pods      podmanager.Manager
+	annotator podAnnotator // pods, asserted once at construction
+	status    statusreporter.Reporter
+	nudger    agentNudger
+	logger    *slog.Logger
+	now       func() time.Time
+	after     func(d time.Duration, f func())
+
+	mu      sync.Mutex
+	pending map[string]bool // namespace/pod → deletion scheduled
+}
+
+// newTerminationGrace returns a handshake giving agents grace to checkpoint,
+// or nil if grace is not positive or pods can't be annotated.
+func newTerminationGrace(grace time.Duration, pods podmanager.Manager, status statusreporter.Reporter, nudger agentNudger, logger *slog.Logger) *terminationGrace {
+	if grace <= 0 {
+		return nil
+	}
+	annotator, ok := pods.(podAnnotator)
+	if !ok {
+		logger.Warn("agent termination grace is set but pods can't be annotated; deleting agent pods at once",
+			"grace", grace)
+		return nil
+	}
+	return &terminationGrace{
+		grace:     grace,
+		pods:      pods,
+		annotator: annotator,
+		status:    status,
+		nudger:    nudger,
+		logger:    logger,
+		now:       time.Now,
+		after:     func(d time.Duration, f func()) { time.AfterFunc(d, f) },
+		pending:   make(map[string]bool),
+	}
+}
+
+// begin starts or continues the grace window of a running agent pod and
+// schedules finish, which deletes the pod, for when it ends. It reports
+// false when the pod should be deleted now instead: it isn't running, its
+// window is over, or it couldn't be marked.
+func (g *terminationGrace) begin(ctx context.Context, name, namespace, beadID string, finish func(context.Context)) bool {
+	if g == nil {
+		return false
+	}
+	pod, err := g.pods.GetAgentPod(ctx, name, namespace)
+	if err != nil || pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+
+	until, terminating := podmanager.TerminateAfter(pod)
+	if !terminating {
+		until = g.now().Add(g.grace).Truncate(time.Second)
+		if err := g.annotator.AnnotateAgentPod(ctx, pod, map[string]string{
+			podmanager.AnnotationTerminateAfter: until.UTC().Format(time.RFC3339),
+		}); err != nil {
+			g.logger.Warn("failed to mark agent pod terminating, deleting it now", "pod", name, "error", err)
+			return false
+		}
+		_ = g.status.ReportPodStatus(ctx, beadID, statusreporter.PodStatus{
+			PodName:   name,
+			Namespace: namespace,
+			Phase:     statusreporter.PhaseTerminating,
+		})
+		// The window runs whether or not the agent hears about it.
+		if err := g.nudger.Nudge(ctx, pod, fmt.Sprintf(terminationNudge, g.grace)); err != nil {
+			g.logger.Warn("failed to nudge terminating agent", "pod", name, "error", err)
+		}
+		g.status.RecordAgentEvent(ctx, statusreporter.AgentEvent{
+			PodName:   name,
+			Namespace: namespace,
+			BeadID:    beadID,
+			Reason:    statusreporter.ReasonAgentTerminating,
+			Note:      "agent given until " + until.UTC().Format(time.RFC3339) + " to checkpoint",
+		})
+		g.logger.Info("agent pod terminating", "pod", name, "bead", beadID, "until", until)
+	}
+	left := until.Sub(g.now())
+	if left <= 0 {
+		return false
+	}
+
+	key := namespace + "/" + name
+	g.mu.Lock()
+	scheduled := g.pending[key]
+	g.pending[key] = true
+	g.mu.Unlock()
+	if !scheduled {
+		ctx := context.WithoutCancel(ctx)
+		uid := pod.UID
+		g.after(left, func() {
+			g.mu.Lock()
+			delete(g.pending, key)
+			g.mu.Unlock()
+			if !g.samePod(ctx, name, namespace, uid) {
+				return
+			}
+			finish(ctx)
+		})
+	}
+	return true
+}
+
+// samePod reports whether the pod named name is still the one given grace,
+// not a replacement created since.
+func (g *terminationGrace) samePod(ctx context.Context, name, namespace string, uid types.UID) bool {
+	pod, err := g.pods.GetAgentPod(ctx, name, namespace)
+	return err != nil || pod.UID == uid
+}
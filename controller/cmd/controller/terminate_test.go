@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/faults"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/policy"
+	"gasboat/controller/internal/statusreporter"
+	"gasboat/controller/internal/subscriber"
+)
+
+// recordingNudger records the messages sent to agents.
+type recordingNudger struct {
+	messages []string
+}
+
+func (n *recordingNudger) Nudge(_ context.Context, pod *corev1.Pod, message string) error {
+	n.messages = append(n.messages, pod.Name+": "+message)
+	return nil
+}
+
+func TestHandleEvent_DoneAgentGetsGraceWindow(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "crew-gasboat-crew-max", Namespace: "gasboat", UID: "uid-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	})
+	pods := podmanager.New(client, slog.Default())
+	status := &recordingReporter{}
+	nudger := &recordingNudger{}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var timers []func()
+	grace := newTerminationGrace(time.Minute, pods, status, nudger, slog.Default())
+	grace.now = func() time.Time { return now }
+	grace.after = func(d time.Duration, f func()) {
+		if d != time.Minute {
+			t.Errorf("deletion scheduled in %s, want 1m", d)
+		}
+		timers = append(timers, f)
+	}
+
+	done := subscriber.Event{Type: subscriber.AgentDone, Mode: "crew", Project: "gasboat", Role: "crew", AgentName: "max", BeadID: "bd-max"}
+	for range 2 { // a repeated event neither restarts the window nor schedules twice
+		if err := handleEvent(ctx, slog.Default(), warmTestConfig(), done, pods, status, nil, grace, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pod, err := client.CoreV1().Pods("gasboat").Get(ctx, "crew-gasboat-crew-max", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("pod deleted during its grace window: %v", err)
+	}
+	if got := pod.Annotations[podmanager.AnnotationTerminateAfter]; got != "2026-03-01T12:01:00Z" {
+		t.Errorf("terminate-after = %q", got)
+	}
+	if len(status.reports) != 1 || status.reports[0].Phase != statusreporter.PhaseTerminating {
+		t.Errorf("reports = %+v, want one Terminating", status.reports)
+	}
+	if len(nudger.messages) != 1 || !strings.Contains(nudger.messages[0], "deleted in 1m0s") {
+		t.Errorf("nudges = %q", nudger.messages)
+	}
+	if len(status.events) != 1 || status.events[0].Reason != statusreporter.ReasonAgentTerminating {
+		t.Errorf("agent events = %+v", status.events)
+	}
+	if len(timers) != 1 {
+		t.Fatalf("scheduled %d deletions, want 1", len(timers))
+	}
+
+	timers[0]()
+	if _, err := client.CoreV1().Pods("gasboat").Get(ctx, "crew-gasboat-crew-max", metav1.GetOptions{}); err == nil {
+		t.Error("pod not deleted when its grace window ended")
+	}
+	if last := status.reports[len(status.reports)-1]; last.Phase != "Succeeded" {
+		t.Errorf("final phase = %q, want Succeeded", last.Phase)
+	}
+}
+
+func TestHandleEvent_KillSkipsGraceWindow(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "crew-gasboat-crew-max", Namespace: "gasboat"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "crew-gasboat-crew-new", Namespace: "gasboat"},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	)
+	pods := podmanager.New(client, slog.Default())
+	nudger := &recordingNudger{}
+	grace := newTerminationGrace(time.Minute, pods, &recordingReporter{}, nudger, slog.Default())
+	grace.after = func(time.Duration, func()) { t.Error("deletion scheduled") }
+
+	for _, ev := range []subscriber.Event{
+		{Type: subscriber.AgentKill, Mode: "crew", Project: "gasboat", Role: "crew", AgentName: "max"},
+		// A pod that never started has nothing to checkpoint.
+		{Type: subscriber.AgentDone, Mode: "crew", Project: "gasboat", Role: "crew", AgentName: "new"},
+	} {
+		if err := handleEvent(ctx, slog.Default(), warmTestConfig(), ev, pods, &recordingReporter{}, nil, grace, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(listPods(t, client)); n != 0 || len(nudger.messages) != 0 {
+		t.Errorf("%d pods left, nudges %q; want both deleted at once", n, nudger.messages)
+	}
+}
+
+func TestNewTerminationGrace_WrappedManager(t *testing.T) {
+	pods := podmanager.New(fake.NewSimpleClientset(), slog.Default())
+	wrapped := policy.NewEnforcer(nil, slog.Default()).Manager(faults.New(faults.Config{}, slog.Default()).Manager(pods))
+	if newTerminationGrace(time.Minute, wrapped, &recordingReporter{}, &recordingNudger{}, slog.Default()) == nil {
+		t.Error("grace window disabled for a pod manager wrapped by policy and faults")
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/specbuilder"
+	"gasboat/controller/internal/subscriber"
+)
+
+// warmPool keeps idle, pre-pulled and pre-cloned pods running on the home
+// cluster so an AgentSpawn event can adopt one instead of waiting minutes for
+// a cold start. A nil *warmPool disables pooling.
+type warmPool struct {
+	pods   *podmanager.K8sManager
+	pools  []config.WarmPoolConfig
+	logger *slog.Logger
+	// inject hands an adopted pod its agent identity.
+	inject func(ctx context.Context, pod *corev1.Pod, spec podmanager.AgentPodSpec) error
+}
+
+func newWarmPool(pods *podmanager.K8sManager, pools []config.WarmPoolConfig, logger *slog.Logger) *warmPool {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return &warmPool{
+		pods:   pods,
+		pools:  pools,
+		logger: logger,
+		inject: func(ctx context.Context, pod *corev1.Pod, spec podmanager.AgentPodSpec) error {
+			return injectIdentity(ctx, client, pod, spec)
+		},
+	}
+}
+
+// refill tops each pool up to its size. Warm pods that stopped, run an
+// outdated image, exceed their pool's size, or belong to no pool are deleted.
+func (w *warmPool) refill(ctx context.Context, cfg *config.Config) error {
+	if w == nil {
+		return nil
+	}
+	idle, err := w.pods.ListWarmPods(ctx, cfg.Namespace)
+	if err != nil {
+		return err
+	}
+	byPool := make(map[string][]corev1.Pod)
+	for _, pod := range idle {
+		key := pod.Labels[podmanager.LabelProject] + "/" + pod.Labels[podmanager.LabelRole]
+		byPool[key] = append(byPool[key], pod)
+	}
+
+	var stale []corev1.Pod
+	for _, p := range w.pools {
+		key := p.Project + "/" + p.Role
+		pods := byPool[key]
+		delete(byPool, key)
+
+		spec := specbuilder.FromBead(cfg, p.Project, "", p.Role, "", nil)
+		if !podmanager.Warmable(spec) {
+			w.logger.Warn("warm pool skipped: role uses a persistent workspace", "pool", key)
+			stale = append(stale, pods...)
+			continue
+		}
+		have := 0
+		for _, pod := range pods {
+			if have >= p.Size || isTerminalPhase(pod.Status.Phase) || podmanager.AgentImage(&pod) != spec.Image {
+				stale = append(stale, pod)
+				continue
+			}
+			have++
+		}
+		for ; have < p.Size; have++ {
+			if err := w.pods.CreateWarmPod(ctx, spec); err != nil {
+				return fmt.Errorf("warm pool %s: %w", key, err)
+			}
+		}
+	}
+	for _, pods := range byPool {
+		stale = append(stale, pods...)
+	}
+
+	for _, pod := range stale {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		w.logger.Info("deleting stale warm pod", "pod", pod.Name)
+		if err := w.pods.DeleteAgentPod(ctx, pod.Name, pod.Namespace); err != nil {
+			return fmt.Errorf("deleting warm pod %s: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// adopt gives spec's agent a ready warm pod, returning the pod's name. It
+// returns "" if none is available or adoption fails, and the caller creates
+// the pod cold.
+func (w *warmPool) adopt(ctx context.Context, spec podmanager.AgentPodSpec) string {
+	if w == nil {
+		return ""
+	}
+	pod, err := w.pods.AdoptWarmPod(ctx, spec)
+	if err != nil {
+		w.logger.Warn("warm pod adoption failed, creating pod cold", "agent", spec.PodName(), "error", err)
+		return ""
+	}
+	if pod == nil {
+		w.logger.Info("warm pool empty, creating pod cold", "agent", spec.PodName())
+		return ""
+	}
+	if err := w.inject(ctx, pod, spec); err != nil {
+		// The pod is already labeled as the agent's but never learned who it
+		// is; drop it so the agent starts cold instead.
+		w.logger.Warn("failed to inject identity into warm pod, creating pod cold",
+			"pod", pod.Name, "agent", spec.PodName(), "error", err)
+		if err := w.pods.DeleteAgentPod(ctx, pod.Name, pod.Namespace); err != nil {
+			w.logger.Warn("failed to delete unusable warm pod", "pod", pod.Name, "error", err)
+		}
+		return ""
+	}
+	return pod.Name
+}
+
+// podName returns the name of the pod running event's agent. It differs from
+// the canonical {mode}-{project}-{role}-{agent} name for adopted warm pods.
+func (w *warmPool) podName(ctx context.Context, pods podmanager.Manager, namespace string, event subscriber.Event) string {
+	canonical := fmt.Sprintf("%s-%s-%s-%s", event.Mode, event.Project, event.Role, event.AgentName)
+	if w == nil {
+		return canonical
+	}
+	list, err := pods.ListAgentPods(ctx, namespace, map[string]string{
+		podmanager.LabelMode:    specbuilder.ModeForRole(event.Mode, event.Role),
+		podmanager.LabelProject: event.Project,
+		podmanager.LabelRole:    event.Role,
+		podmanager.LabelAgent:   event.AgentName,
+	})
+	if err != nil {
+		w.logger.Warn("failed to look up agent pod", "agent", canonical, "error", err)
+		return canonical
+	}
+	if len(list) == 0 {
+		return canonical
+	}
+	return list[0].Name
+}
+
+// injectIdentity tells the coop session in an adopted warm pod which agent it
+// now runs. Coop holds a warm pod (BOAT_WARM=1) idle until it receives this.
+func injectIdentity(ctx context.Context, client *http.Client, pod *corev1.Pod, spec podmanager.AgentPodSpec) error {
+	if pod.Status.PodIP == "" {
+		return fmt.Errorf("pod %s has no IP", pod.Name)
+	}
+	env := map[string]string{
+		"BOAT_AGENT": spec.AgentName,
+		"BOAT_MODE":  spec.Mode,
+	}
+	for _, e := range podmanager.IdentityEnv(spec) {
+		env[e.Name] = e.Value
+	}
+	body, err := json.Marshal(map[string]any{"env": env})
+	if err != nil {
+		return fmt.Errorf("marshal identity body: %w", err)
+	}
+	url := fmt.Sprintf("http://%s:%d/api/v1/agent/identity", pod.Status.PodIP, podmanager.CoopDefaultPort)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create identity request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("identity request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("identity request returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func isTerminalPhase(phase corev1.PodPhase) bool {
+	return phase == corev1.PodSucceeded || phase == corev1.PodFailed
+}
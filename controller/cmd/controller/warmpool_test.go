@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/specbuilder"
+	"gasboat/controller/internal/statusreporter"
+	"gasboat/controller/internal/subscriber"
+)
+
+// recordingReporter records pod status reports and agent events.
+type recordingReporter struct {
+	reports []statusreporter.PodStatus
+	events  []statusreporter.AgentEvent
+}
+
+func (r *recordingReporter) ReportPodStatus(_ context.Context, _ string, status statusreporter.PodStatus) error {
+	r.reports = append(r.reports, status)
+	return nil
+}
+
+func (r *recordingReporter) ReportBackendMetadata(context.Context, string, statusreporter.BackendMetadata) error {
+	return nil
+}
+
+func (r *recordingReporter) SyncAll(context.Context) error { return nil }
+
+func (r *recordingReporter) RecordAgentEvent(_ context.Context, ev statusreporter.AgentEvent) {
+	r.events = append(r.events, ev)
+}
+
+func (r *recordingReporter) Metrics() statusreporter.MetricsSnapshot {
+	return statusreporter.MetricsSnapshot{}
+}
+
+func warmTestConfig() *config.Config {
+	return &config.Config{Namespace: "gasboat", CoopImage: "agent:v1"}
+}
+
+func newTestWarmPool(client *fake.Clientset, size int) (*warmPool, *[]string) {
+	w := newWarmPool(podmanager.New(client, slog.Default()),
+		[]config.WarmPoolConfig{{Project: "gasboat", Role: "job", Size: size}}, slog.Default())
+	var injected []string
+	w.inject = func(_ context.Context, pod *corev1.Pod, spec podmanager.AgentPodSpec) error {
+		injected = append(injected, pod.Name+"="+spec.AgentName)
+		return nil
+	}
+	return w, &injected
+}
+
+func listPods(t *testing.T, client *fake.Clientset) []corev1.Pod {
+	t.Helper()
+	list, err := client.CoreV1().Pods("gasboat").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list.Items
+}
+
+func readyAll(t *testing.T, client *fake.Clientset) {
+	t.Helper()
+	for _, pod := range listPods(t, client) {
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		if _, err := client.CoreV1().Pods("gasboat").UpdateStatus(context.Background(), &pod, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestWarmPool_RefillAndReplaceStaleImage(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	w, _ := newTestWarmPool(client, 2)
+	cfg := warmTestConfig()
+	ctx := context.Background()
+
+	for range 2 {
+		if err := w.refill(ctx, cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(listPods(t, client)); n != 2 {
+		t.Fatalf("warm pods = %d, want 2", n)
+	}
+
+	// A new agent image replaces the pool.
+	cfg.CoopImage = "agent:v2"
+	if err := w.refill(ctx, cfg); err != nil {
+		t.Fatal(err)
+	}
+	pods := listPods(t, client)
+	if len(pods) != 2 {
+		t.Fatalf("warm pods after image change = %d, want 2", len(pods))
+	}
+	for _, pod := range pods {
+		if img := podmanager.AgentImage(&pod); img != "agent:v2" {
+			t.Errorf("pod %s runs %s, want agent:v2", pod.Name, img)
+		}
+	}
+}
+
+func TestHandleEvent_SpawnAdoptsWarmPod(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	w, injected := newTestWarmPool(client, 1)
+	cfg := warmTestConfig()
+	ctx := context.Background()
+	if err := w.refill(ctx, cfg); err != nil {
+		t.Fatal(err)
+	}
+	readyAll(t, client)
+	warmName := listPods(t, client)[0].Name
+
+	pods := podmanager.New(client, slog.Default())
+	status := &recordingReporter{}
+	spawn := subscriber.Event{Type: subscriber.AgentSpawn, Project: "gasboat", Role: "job", AgentName: "j1",
+		BeadID: "bd-j1", Metadata: map[string]string{"image": "agent:v1"}}
+	if err := handleEvent(ctx, slog.Default(), cfg, spawn, pods, status, w, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*injected) != 1 || (*injected)[0] != warmName+"=j1" {
+		t.Errorf("identity injections = %v, want [%s=j1]", *injected, warmName)
+	}
+	if n := len(listPods(t, client)); n != 1 {
+		t.Errorf("pods = %d, want only the adopted warm pod", n)
+	}
+	if len(status.reports) != 1 || status.reports[0].PodName != warmName {
+		t.Errorf("reported pod status %+v, want pod %s", status.reports, warmName)
+	}
+	if len(status.events) != 1 || status.events[0].Reason != statusreporter.ReasonAgentSpawned || status.events[0].PodName != warmName {
+		t.Errorf("agent events %+v, want AgentSpawned on %s", status.events, warmName)
+	}
+
+	// The pool is empty: the next spawn starts cold.
+	spawn.AgentName, spawn.BeadID = "j2", "bd-j2"
+	if err := handleEvent(ctx, slog.Default(), cfg, spawn, pods, status, w, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Pods("gasboat").Get(ctx, "job-gasboat-job-j2", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected cold pod for j2: %v", err)
+	}
+
+	// Done deletes the adopted pod by its pool name.
+	done := subscriber.Event{Type: subscriber.AgentDone, Mode: "job", Project: "gasboat", Role: "job", AgentName: "j1"}
+	if err := handleEvent(ctx, slog.Default(), cfg, done, pods, status, w, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Pods("gasboat").Get(ctx, warmName, metav1.GetOptions{}); err == nil {
+		t.Error("adopted pod not deleted on AgentDone")
+	}
+}
+
+func TestWarmPool_FailedInjectionFallsBackCold(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	w, _ := newTestWarmPool(client, 1)
+	w.inject = func(context.Context, *corev1.Pod, podmanager.AgentPodSpec) error {
+		return errors.New("connection refused")
+	}
+	ctx := context.Background()
+	if err := w.refill(ctx, warmTestConfig()); err != nil {
+		t.Fatal(err)
+	}
+	readyAll(t, client)
+
+	spec := specbuilder.FromBead(warmTestConfig(), "gasboat", "", "job", "j1", nil)
+	if name := w.adopt(ctx, spec); name != "" {
+		t.Errorf("adopt = %q, want cold fallback", name)
+	}
+	if n := len(listPods(t, client)); n != 0 {
+		t.Errorf("unusable warm pod not deleted, %d pods left", n)
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/bridge"
+)
+
+// pendingStatuses are the decision statuses listed as awaiting a response.
+const pendingStatuses = "open,in_progress"
+
+// listLimit caps the number of pending decisions shown.
+const listLimit = 100
+
+// artifactTypes are the artifact types a custom response may require, as
+// accepted by gb decision create.
+var artifactTypes = []string{"report", "plan", "checklist", "diff-summary", "epic", "bug"}
+
+// Server handles HTTP requests for the decision viewer.
+type Server struct {
+	daemon    *beadsapi.Client
+	templates *bridge.NotificationTemplates
+	logger    *slog.Logger
+	pages     map[string]*template.Template
+	basePath  string // external URL prefix (e.g. "/decisions"), empty for root
+	locale    string // locale for shared notification strings
+}
+
+// NewServer creates a decision viewer server.
+func NewServer(daemon *beadsapi.Client, templates *bridge.NotificationTemplates, logger *slog.Logger, basePath, locale string) *Server {
+	funcMap := template.FuncMap{
+		"tr":  bridge.Tr,
+		"add": func(a, b int) int { return a + b },
+	}
+	// Parse each page template together with the layout so {{define "content"}}
+	// blocks don't collide across pages.
+	pageNames := []string{"decision_list.html", "decision_show.html"}
+	pages := make(map[string]*template.Template, len(pageNames))
+	for _, name := range pageNames {
+		pages[name] = template.Must(
+			template.New("").Funcs(funcMap).ParseFS(templateFS, "templates/layout.html", "templates/"+name),
+		)
+	}
+	return &Server{
+		daemon:    daemon,
+		templates: templates,
+		logger:    logger,
+		pages:     pages,
+		basePath:  basePath,
+		locale:    locale,
+	}
+}
+
+// RegisterRoutes adds all application routes to the mux.
+func (s *Server) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /{$}", s.handleList)
+	mux.HandleFunc("GET /decisions/{id}", s.handleShow)
+	mux.HandleFunc("POST /decisions/{id}/resolve", s.handleResolve)
+	mux.HandleFunc("POST /decisions/{id}/dismiss", s.handleDismiss)
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data any) {
+	tmpl, ok := s.pages[name]
+	if !ok {
+		s.logger.Error("template not found", "template", name)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	// Inject BasePath and Locale into all template data.
+	m, _ := data.(map[string]any)
+	if m == nil {
+		m = make(map[string]any)
+	}
+	m["BasePath"] = s.basePath
+	m["Locale"] = s.locale
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, "layout", m); err != nil {
+		s.logger.Error("template render error", "template", name, "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+// decisionView is a decision bead prepared for display.
+type decisionView struct {
+	ID          string
+	Status      string
+	Summary     string // the Slack notification text, from the shared templates
+	Question    string
+	Context     []bridge.DecisionContext
+	Agent       string
+	RequestedBy string
+	Priority    int
+	Options     []bridge.DecisionOption
+	Predecessor string
+	Iteration   string
+	Issue       *beadsapi.BeadDetail
+	UpdatedAt   time.Time
+
+	Chosen      string // set once resolved
+	Rationale   string
+	RespondedBy string
+}
+
+// Open reports whether the decision still awaits a response.
+func (d decisionView) Open() bool {
+	return d.Status != "closed"
+}
+
+func (s *Server) newDecisionView(detail *beadsapi.DecisionDetail) decisionView {
+	b := detail.Decision
+	question := b.Fields["prompt"]
+	if question == "" {
+		question = b.Fields["question"]
+	}
+	if question == "" {
+		question = b.Title
+	}
+	return decisionView{
+		ID:          b.ID,
+		Status:      b.Status,
+		Summary:     s.templates.RenderDecisionText(b, s.locale),
+		Question:    question,
+		Context:     bridge.ParseDecisionContext(b.Fields["context"]),
+		Agent:       b.Assignee,
+		RequestedBy: b.Fields["requested_by"],
+		Priority:    b.Priority,
+		Options:     bridge.ParseDecisionOptions(b.Fields["options"]),
+		Predecessor: b.Fields["predecessor_id"],
+		Iteration:   b.Fields["iteration"],
+		Issue:       detail.Issue,
+		UpdatedAt:   b.UpdatedAt,
+		Chosen:      b.Fields["chosen"],
+		Rationale:   b.Fields["rationale"],
+		RespondedBy: b.Fields["responded_by"],
+	}
+}
+
+// handleList shows pending decisions, most urgent first.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	details, err := s.daemon.ListDecisions(r.Context(), pendingStatuses, listLimit)
+	if err != nil {
+		s.logger.Error("listing decisions", "error", err)
+		http.Error(w, "Failed to list decisions", http.StatusInternalServerError)
+		return
+	}
+	var decisions []decisionView
+	for i := range details {
+		if details[i].Decision != nil {
+			decisions = append(decisions, s.newDecisionView(&details[i]))
+		}
+	}
+	// Lower priority number is more urgent; oldest first within a priority.
+	sort.SliceStable(decisions, func(i, j int) bool {
+		if decisions[i].Priority != decisions[j].Priority {
+			return decisions[i].Priority < decisions[j].Priority
+		}
+		return decisions[i].UpdatedAt.Before(decisions[j].UpdatedAt)
+	})
+	s.render(w, "decision_list.html", map[string]any{
+		"Decisions": decisions,
+		"Resolved":  r.URL.Query().Get("resolved"),
+		"Dismissed": r.URL.Query().Get("dismissed"),
+	})
+}
+
+// handleShow shows one decision with its options and response forms.
+func (s *Server) handleShow(w http.ResponseWriter, r *http.Request) {
+	d, ok := s.getDecision(w, r)
+	if !ok {
+		return
+	}
+	s.render(w, "decision_show.html", map[string]any{
+		"Decision":      d,
+		"ArtifactTypes": artifactTypes,
+	})
+}
+
+// handleResolve records a response: either a numbered option or, with
+// option "other", a custom response.
+func (s *Server) handleResolve(w http.ResponseWriter, r *http.Request) {
+	d, ok := s.getOpenDecision(w, r)
+	if !ok {
+		return
+	}
+	fields, err := resolutionFields(d, r.Form, responder(r), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.daemon.CloseBead(r.Context(), d.ID, fields); err != nil {
+		s.logger.Error("resolving decision", "id", d.ID, "error", err)
+		http.Error(w, "Failed to resolve decision", http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info("decision resolved via decision-viewer",
+		"id", d.ID, "chosen", fields["chosen"], "user", fields["responded_by"])
+	http.Redirect(w, r, s.basePath+"/?resolved="+d.ID, http.StatusSeeOther)
+}
+
+// handleDismiss closes a decision without choosing an option.
+func (s *Server) handleDismiss(w http.ResponseWriter, r *http.Request) {
+	d, ok := s.getOpenDecision(w, r)
+	if !ok {
+		return
+	}
+	who := responder(r)
+	rationale := fmt.Sprintf("Dismissed by %s via decision-viewer", who.Name)
+	if reason := strings.TrimSpace(r.FormValue("reason")); reason != "" {
+		rationale += ": " + reason
+	}
+	fields := who.AuditFields(time.Now())
+	fields["chosen"] = "dismissed"
+	fields["rationale"] = rationale
+	if err := s.daemon.CloseBead(r.Context(), d.ID, fields); err != nil {
+		s.logger.Error("dismissing decision", "id", d.ID, "error", err)
+		http.Error(w, "Failed to dismiss decision", http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info("decision dismissed via decision-viewer", "id", d.ID, "user", who.Identity)
+	http.Redirect(w, r, s.basePath+"/?dismissed="+d.ID, http.StatusSeeOther)
+}
+
+// getDecision loads the decision named in the path, writing a 404 if it
+// cannot be found.
+func (s *Server) getDecision(w http.ResponseWriter, r *http.Request) (decisionView, bool) {
+	id := r.PathValue("id")
+	detail, err := s.daemon.GetDecision(r.Context(), id)
+	if err != nil || detail.Decision == nil {
+		s.logger.Error("getting decision", "id", id, "error", err)
+		http.Error(w, "Decision not found", http.StatusNotFound)
+		return decisionView{}, false
+	}
+	return s.newDecisionView(detail), true
+}
+
+// getOpenDecision parses the form and loads the decision for a response,
+// rejecting decisions that have already been answered.
+func (s *Server) getOpenDecision(w http.ResponseWriter, r *http.Request) (decisionView, bool) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return decisionView{}, false
+	}
+	d, ok := s.getDecision(w, r)
+	if !ok {
+		return decisionView{}, false
+	}
+	if !d.Open() {
+		http.Error(w, "Decision already resolved", http.StatusConflict)
+		return decisionView{}, false
+	}
+	return d, true
+}
+
+// resolutionFields builds the fields written when closing a decision,
+// matching what the Slack bridge and gb decision respond record. The form
+// carries option (a 1-based index or "other"), response and artifact_type
+// for a custom response, and an optional rationale.
+func resolutionFields(d decisionView, form url.Values, who beadsapi.Responder, now time.Time) (map[string]string, error) {
+	get := func(key string) string { return strings.TrimSpace(form.Get(key)) }
+
+	fields := who.AuditFields(now)
+	var artifact, action string
+	switch choice := get("option"); choice {
+	case "":
+		return nil, errors.New("choose an option or give a custom response")
+	case "other":
+		response := get("response")
+		if response == "" {
+			return nil, errors.New("a custom response needs response text")
+		}
+		fields["chosen"] = response
+		fields["response_text"] = response
+		if at := get("artifact_type"); at != "" && at != "none" {
+			if !slices.Contains(artifactTypes, at) {
+				return nil, fmt.Errorf("unknown artifact type %q", at)
+			}
+			artifact = at
+		}
+		action = "Custom response"
+	default:
+		n, err := strconv.Atoi(choice)
+		if err != nil || n < 1 || n > len(d.Options) {
+			return nil, fmt.Errorf("unknown option %q", choice)
+		}
+		opt := d.Options[n-1]
+		fields["chosen"] = opt.DisplayLabel()
+		artifact = opt.ArtifactType
+		action = "Chosen"
+	}
+	if artifact != "" {
+		fields["required_artifact"] = artifact
+		fields["artifact_status"] = "pending"
+	}
+
+	// Attribution mirrors the Slack bridge: "<rationale> — <user> via ..."
+	// or "<action> by <user> via ..." when no rationale is given.
+	if rationale := get("rationale"); rationale != "" {
+		fields["rationale"] = fmt.Sprintf("%s — %s via decision-viewer", rationale, who.Name)
+	} else {
+		fields["rationale"] = fmt.Sprintf("%s by %s via decision-viewer", action, who.Name)
+	}
+	return fields, nil
+}
+
+// responder identifies who answered. The identity is the email forwarded
+// by an authenticating proxy in front of the viewer (X-Auth-Request-Email
+// or X-Forwarded-Email) when present, else "decision-viewer:<user>" for
+// the basic-auth user, or "decision-viewer:anonymous" without auth.
+func responder(r *http.Request) beadsapi.Responder {
+	name := "anonymous"
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		name = user
+	}
+	var email string
+	for _, h := range []string{"X-Auth-Request-Email", "X-Forwarded-Email"} {
+		if email = r.Header.Get(h); email != "" {
+			break
+		}
+	}
+	if email != "" && name == "anonymous" {
+		name = email
+	}
+	return beadsapi.Responder{
+		Identity: beadsapi.NormalizeIdentity("decision-viewer", name, email),
+		Name:     name,
+		Via:      "decision-viewer",
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/bridge"
+)
+
+// mockDaemon serves two decisions and records close requests.
+type mockDaemon struct {
+	mu     sync.Mutex
+	closed map[string]map[string]any
+}
+
+func (d *mockDaemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	decision := map[string]any{
+		"id":       "dec-1",
+		"title":    "Which database?",
+		"type":     "decision",
+		"status":   "open",
+		"assignee": "gasboat/crews/alpha",
+		"priority": 1,
+		"fields": map[string]any{
+			"prompt": "Which database should we use?",
+			"context": []map[string]string{
+				{"kind": "text", "content": "The service needs durable storage."},
+				{"kind": "diff", "title": "store.go", "content": "+db.Open(dsn)\n"},
+				{"kind": "link", "title": "Load test", "url": "https://ci.example.com/run/7"},
+			},
+			"requested_by": "alpha",
+			"options": []map[string]string{
+				{"id": "pg", "label": "Postgres", "description": "Managed instance", "artifact_type": "plan"},
+				{"id": "sqlite", "short": "SQLite"},
+			},
+		},
+	}
+	other := map[string]any{
+		"id":       "dec-2",
+		"title":    "Ship it?",
+		"type":     "decision",
+		"status":   "open",
+		"priority": 2,
+		"fields":   map[string]any{"prompt": "Ship it?"},
+	}
+	if f, ok := d.closed["dec-1"]; ok {
+		decision["status"] = "closed"
+		fields := decision["fields"].(map[string]any)
+		fields["chosen"] = f["chosen"]
+		fields["rationale"] = f["rationale"]
+	}
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/v1/decisions":
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"decisions": []map[string]any{{"decision": other}, {"decision": decision}},
+		})
+	case r.Method == "GET" && r.URL.Path == "/v1/decisions/dec-1":
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"decision": decision,
+			"issue":    map[string]any{"id": "task-9", "title": "Pick storage", "status": "in_progress"},
+		})
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/close"):
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/beads/"), "/close")
+		d.closed[id] = body
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "not found"})
+	}
+}
+
+func testServer(t *testing.T) (*http.ServeMux, *mockDaemon) {
+	t.Helper()
+	d := &mockDaemon{closed: make(map[string]map[string]any)}
+	mock := httptest.NewServer(d)
+	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: mock.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		daemon.Close()
+		mock.Close()
+	})
+	templates := bridge.NewNotificationTemplates(bridge.NotificationTemplatesConfig{Logger: slog.Default()})
+	srv := NewServer(daemon, templates, slog.Default(), "", "en")
+	mux := http.NewServeMux()
+	srv.RegisterRoutes(mux)
+	return mux, d
+}
+
+func serve(mux *http.ServeMux, method, path string, form url.Values) *httptest.ResponseRecorder {
+	var req *http.Request
+	if form != nil {
+		req = httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		req = httptest.NewRequest(method, path, nil)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandleList(t *testing.T) {
+	mux, _ := testServer(t)
+	w := serve(mux, "GET", "/", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	first, second := strings.Index(body, "Which database should we use?"), strings.Index(body, "Ship it?")
+	if first < 0 || second < 0 {
+		t.Fatal("expected both decisions to be listed")
+	}
+	if first > second {
+		t.Error("expected the higher-priority decision first")
+	}
+}
+
+func TestHandleShow(t *testing.T) {
+	mux, _ := testServer(t)
+	w := serve(mux, "GET", "/decisions/dec-1", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	for _, want := range []string{
+		"Decision needed: Which database should we use?", // shared Slack fallback template
+		"The service needs durable storage.",
+		"<summary>store.go</summary>", "db.Open(dsn)",
+		`<a href="https://ci.example.com/run/7">Load test</a>`,
+		"1. Postgres", "Managed instance", "Requires: plan",
+		"2. SQLite",
+		"Pick storage",
+		"Dismiss",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("show page missing %q", want)
+		}
+	}
+
+	if w := serve(mux, "GET", "/decisions/missing", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing decision: expected 404, got %d", w.Code)
+	}
+}
+
+func TestHandleResolve(t *testing.T) {
+	mux, d := testServer(t)
+
+	form := url.Values{"option": {"1"}, "rationale": {"Team knows it"}}
+	req := httptest.NewRequest("POST", "/decisions/dec-1/resolve", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("sam", "secret")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d: %s", w.Code, w.Body.String())
+	}
+
+	got := d.closed["dec-1"]
+	if got["chosen"] != "Postgres" || got["required_artifact"] != "plan" || got["responded_by"] != "decision-viewer:sam" {
+		t.Errorf("unexpected close fields: %v", got)
+	}
+	if got["rationale"] != "Team knows it — sam via decision-viewer" {
+		t.Errorf("rationale = %v", got["rationale"])
+	}
+
+	// A resolved decision cannot be answered again.
+	if w := serve(mux, "POST", "/decisions/dec-1/dismiss", url.Values{}); w.Code != http.StatusConflict {
+		t.Errorf("second response: expected 409, got %d", w.Code)
+	}
+}
+
+func TestHandleDismiss(t *testing.T) {
+	mux, d := testServer(t)
+	w := serve(mux, "POST", "/decisions/dec-1/dismiss", url.Values{"reason": {"obsolete"}})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", w.Code)
+	}
+	got := d.closed["dec-1"]
+	if got["chosen"] != "dismissed" || got["rationale"] != "Dismissed by anonymous via decision-viewer: obsolete" {
+		t.Errorf("unexpected close fields: %v", got)
+	}
+}
+
+func TestResolutionFields(t *testing.T) {
+	d := decisionView{Options: bridge.ParseDecisionOptions(`["Left","Right"]`)}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	sam := beadsapi.Responder{Identity: "sam@example.com", Name: "sam", Via: "decision-viewer"}
+
+	fields, err := resolutionFields(d, url.Values{"option": {"2"}}, sam, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields["chosen"] != "Right" || fields["rationale"] != "Chosen by sam via decision-viewer" || fields["responded_at"] != "2026-01-02T03:04:05Z" ||
+		fields["responded_by"] != "sam@example.com" || fields["responded_via"] != "decision-viewer" {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+
+	fields, err = resolutionFields(d, url.Values{"option": {"other"}, "response": {"Neither"}, "artifact_type": {"report"}}, sam, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields["chosen"] != "Neither" || fields["required_artifact"] != "report" || fields["rationale"] != "Custom response by sam via decision-viewer" {
+		t.Errorf("unexpected custom fields: %v", fields)
+	}
+
+	for _, form := range []url.Values{
+		{},
+		{"option": {"3"}},
+		{"option": {"other"}},
+		{"option": {"other"}, "response": {"x"}, "artifact_type": {"essay"}},
+	} {
+		if _, err := resolutionFields(d, form, sam, now); err == nil {
+			t.Errorf("form %v: expected an error", form)
+		}
+	}
+}
+
+func TestResponder(t *testing.T) {
+	req := httptest.NewRequest("POST", "/decisions/dec-1/dismiss", nil)
+	if got := responder(req); got.Identity != "decision-viewer:anonymous" || got.Name != "anonymous" {
+		t.Errorf("unauthenticated responder = %+v", got)
+	}
+
+	req.SetBasicAuth("sam", "secret")
+	if got := responder(req); got.Identity != "decision-viewer:sam" || got.Name != "sam" {
+		t.Errorf("basic-auth responder = %+v", got)
+	}
+
+	req.Header.Set("X-Auth-Request-Email", "Sam@Example.com")
+	if got := responder(req); got.Identity != "sam@example.com" || got.Name != "sam" || got.Via != "decision-viewer" {
+		t.Errorf("proxy-authenticated responder = %+v", got)
+	}
+}
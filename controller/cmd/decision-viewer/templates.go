@@ -0,0 +1,6 @@
+package main
+
+import "embed"
+
+//go:embed templates/*.html
+var templateFS embed.FS
@@ -0,0 +1,214 @@
+// Command discord-bridge is a standalone service that posts decision and
+// agent notifications to Discord, mirroring what slack-bridge does for Slack.
+//
+// It runs three subsystems:
+//   - Discord gateway: slash commands (/decisions, /roster) and decision buttons
+//   - SSE watchers: the shared Decisions and Agents watchers → Discord notifier
+//   - HTTP server: health/readiness endpoints
+//
+// This service has ZERO K8s dependencies and can run as a lightweight
+// standalone container alongside the gasboat controller.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/bridge"
+)
+
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+func main() {
+	cfg := parseConfig()
+
+	logger := setupLogger(cfg.logLevel)
+	logger.Info("starting discord-bridge",
+		"version", version,
+		"commit", commit,
+		"beads_http", cfg.beadsHTTPAddr,
+		"discord_channel", cfg.discordChannelID,
+		"discord_guild", cfg.discordGuildID,
+		"threading_mode", cfg.threadingMode,
+		"listen_addr", cfg.listenAddr)
+
+	if cfg.discordToken == "" || cfg.discordChannelID == "" {
+		logger.Error("DISCORD_BOT_TOKEN and DISCORD_CHANNEL_ID are required")
+		os.Exit(1)
+	}
+
+	// Create beads daemon HTTP client.
+	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: cfg.beadsHTTPAddr})
+	if err != nil {
+		logger.Error("failed to create beads daemon client", "error", err)
+		os.Exit(1)
+	}
+	defer daemon.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	// State persistence for message references and SSE last-event-ID.
+	state, err := bridge.NewStateManager(cfg.statePath)
+	if err != nil {
+		logger.Error("failed to load state", "path", cfg.statePath, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("state manager loaded", "path", cfg.statePath)
+
+	// Create Discord notifier.
+	discord, err := bridge.NewDiscord(bridge.DiscordConfig{
+		Token:         cfg.discordToken,
+		ChannelID:     cfg.discordChannelID,
+		GuildID:       cfg.discordGuildID,
+		ThreadingMode: cfg.threadingMode,
+		Daemon:        daemon,
+		State:         state,
+		Logger:        logger,
+	})
+	if err != nil {
+		logger.Error("failed to create discord session", "error", err)
+		os.Exit(1)
+	}
+
+	// HTTP server with health endpoints.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok","version":"%s"}`, version)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok"}`)
+	})
+
+	srv := &http.Server{
+		Addr:              cfg.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("starting HTTP server", "addr", cfg.listenAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server failed", "error", err)
+		}
+	}()
+
+	// Start the Discord gateway (interactions and slash commands).
+	go func() {
+		if err := discord.Run(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("discord gateway stopped", "error", err)
+			cancel()
+		}
+	}()
+
+	// Create event deduplicator.
+	dedup := bridge.NewDedup(logger)
+
+	// Create SSE event stream for the decisions and agents watchers.
+	sseStream := bridge.NewSSEStream(bridge.SSEStreamConfig{
+		BeadsHTTPAddr: cfg.beadsHTTPAddr,
+		Topics:        []string{"beads.bead.created", "beads.bead.closed", "beads.bead.updated"},
+		Logger:        logger,
+		Dedup:         dedup,
+		State:         state,
+	})
+
+	// Register the shared watchers with the Discord notifier.
+	decisions := bridge.NewDecisions(bridge.DecisionsConfig{
+		Daemon:   daemon,
+		Notifier: discord,
+		Logger:   logger,
+	})
+	decisions.RegisterHandlers(sseStream)
+
+	agents := bridge.NewAgents(bridge.AgentsConfig{
+		Notifier: discord,
+		Logger:   logger,
+	})
+	agents.RegisterHandlers(sseStream)
+
+	// Start the SSE stream.
+	go func() {
+		if err := sseStream.Start(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("SSE event stream stopped", "error", err)
+		}
+	}()
+
+	logger.Info("discord-bridge ready")
+
+	// Block until shutdown signal.
+	<-ctx.Done()
+	logger.Info("shutting down discord-bridge")
+
+	// Graceful HTTP server shutdown.
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown error", "error", err)
+	}
+}
+
+// config holds parsed environment configuration for the discord-bridge service.
+type config struct {
+	beadsHTTPAddr    string
+	discordToken     string
+	discordChannelID string
+	discordGuildID   string
+	threadingMode    string // "agent" or "" (flat)
+	listenAddr       string
+	logLevel         string
+	statePath        string
+}
+
+func parseConfig() *config {
+	return &config{
+		beadsHTTPAddr:    envOrDefault("BEADS_HTTP_ADDR", "http://localhost:8080"),
+		discordToken:     os.Getenv("DISCORD_BOT_TOKEN"),
+		discordChannelID: os.Getenv("DISCORD_CHANNEL_ID"),
+		discordGuildID:   os.Getenv("DISCORD_GUILD_ID"),
+		threadingMode:    os.Getenv("DISCORD_THREADING_MODE"),
+		listenAddr:       envOrDefault("DISCORD_LISTEN_ADDR", ":8092"),
+		logLevel:         envOrDefault("LOG_LEVEL", "info"),
+		statePath:        envOrDefault("STATE_PATH", "/tmp/discord-bridge-state.json"),
+	}
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func setupLogger(level string) *slog.Logger {
+	var logLevel slog.Level
+	switch level {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	default:
+		logLevel = slog.LevelInfo
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+}
+
+func init() {
+	if v := os.Getenv("VERSION"); v != "" {
+		version = v
+	}
+}
@@ -171,6 +171,9 @@ var adviceShowCmd = &cobra.Command{
 		if v := bead.Fields["hook_trigger"]; v != "" {
 			fmt.Printf("Hook When:   %s\n", v)
 		}
+		if e := advice.EffectivenessOf(bead); e.Rated() {
+			fmt.Printf("Score:       %s\n", formatEffectiveness(e))
+		}
 		return nil
 	},
 }
@@ -200,8 +203,65 @@ var adviceRemoveCmd = &cobra.Command{
 	},
 }
 
+// ── advice feedback ────────────────────────────────────────────────────
+
+var adviceFeedbackCmd = &cobra.Command{
+	Use:   "feedback <id>",
+	Short: "Rate an advice bead or record that you applied it",
+	Long: `Records this agent's feedback on an advice bead. Each agent has one vote
+per advice (a new vote replaces the old one); --applied counts each time the
+agent acted on the advice. Feedback feeds the effectiveness score that gb
+prime uses to rank advice.`,
+	Example: `  gb advice feedback kd-abc123 --up
+  gb advice feedback kd-abc123 --applied
+  gb advice feedback kd-abc123 --clear-vote`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		up, _ := cmd.Flags().GetBool("up")
+		down, _ := cmd.Flags().GetBool("down")
+		clearVote, _ := cmd.Flags().GetBool("clear-vote")
+		applied, _ := cmd.Flags().GetBool("applied")
+		agentFlag, _ := cmd.Flags().GetString("agent-id")
+
+		vote := 0
+		switch {
+		case up:
+			vote = 1
+		case down:
+			vote = -1
+		}
+		u := advice.FeedbackUpdate{Applied: applied}
+		if up || down || clearVote {
+			u.Vote = &vote
+		}
+		if u.Vote == nil && !applied {
+			return fmt.Errorf("nothing to record: pass --up, --down, --clear-vote, or --applied")
+		}
+
+		agentID, err := resolveAgentIDWithFallback(cmd.Context(), agentFlag)
+		if err != nil {
+			return err
+		}
+		e, err := advice.RecordFeedback(cmd.Context(), daemon, args[0], agentID, u)
+		if err != nil {
+			return fmt.Errorf("recording feedback on %s: %w", args[0], err)
+		}
+
+		if jsonOutput {
+			printJSON(map[string]any{"id": args[0], "agent_id": agentID, "effectiveness": e})
+		} else {
+			fmt.Printf("Recorded feedback on %s: %s\n", args[0], formatEffectiveness(e))
+		}
+		return nil
+	},
+}
+
 // ── helpers ────────────────────────────────────────────────────────────
 
+func formatEffectiveness(e advice.Effectiveness) string {
+	return fmt.Sprintf("%.2f (%d up, %d down, applied by %d)", e.Score, e.Up, e.Down, e.Applied)
+}
+
 func printAdviceList(beads []*beadsapi.BeadDetail, total int) {
 	if len(beads) == 0 {
 		fmt.Println("No advice beads found.")
@@ -227,6 +287,7 @@ func init() {
 	adviceCmd.AddCommand(adviceListCmd)
 	adviceCmd.AddCommand(adviceShowCmd)
 	adviceCmd.AddCommand(adviceRemoveCmd)
+	adviceCmd.AddCommand(adviceFeedbackCmd)
 
 	adviceAddCmd.Flags().StringP("title", "t", "", "override title")
 	adviceAddCmd.Flags().StringP("description", "d", "", "detailed description")
@@ -242,4 +303,11 @@ func init() {
 	adviceListCmd.Flags().String("for", "", "match advice for agent context")
 
 	adviceRemoveCmd.Flags().Bool("hard", false, "permanently delete instead of closing")
+
+	adviceFeedbackCmd.Flags().Bool("up", false, "mark the advice helpful")
+	adviceFeedbackCmd.Flags().Bool("down", false, "mark the advice unhelpful")
+	adviceFeedbackCmd.Flags().Bool("clear-vote", false, "withdraw this agent's vote")
+	adviceFeedbackCmd.Flags().Bool("applied", false, "record that the agent acted on the advice")
+	adviceFeedbackCmd.Flags().String("agent-id", "", "agent bead ID (default: KD_AGENT_ID)")
+	adviceFeedbackCmd.MarkFlagsMutuallyExclusive("up", "down", "clear-vote")
 }
@@ -12,6 +12,12 @@ var agentCmd = &cobra.Command{
 
 func init() {
 	agentCmd.AddCommand(agentRosterCmd)
+	agentCmd.AddCommand(agentSpawnCmd)
+	agentCmd.AddCommand(agentStopCmd)
+	agentCmd.AddCommand(agentRestartCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+	agentCmd.AddCommand(agentLogsCmd)
+	agentCmd.AddCommand(agentExecCmd)
 }
 
 var agentRosterCmd = &cobra.Command{
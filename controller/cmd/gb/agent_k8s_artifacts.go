@@ -0,0 +1,61 @@
+package main
+
+// agent_k8s_artifacts.go — export session artifacts to object storage when
+// the agent finishes or fails, before the pod (and eventually its PVC) goes.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"gasboat/controller/internal/artifacts"
+)
+
+// sessionLogPattern selects Claude session logs, relative to the workspace.
+const sessionLogPattern = ".state/claude/projects/*/*.jsonl"
+
+// exportArtifacts uploads the workspace files selected by BOAT_ARTIFACT_PATHS
+// and the agent's session logs, then records their URLs on the agent bead
+// (artifacts field, a JSON list). It is best-effort: failures are logged and
+// never block the agent from stopping.
+func exportArtifacts(cfg k8sConfig, agentBeadID, outcome string) {
+	store, err := artifacts.FromEnv()
+	if err != nil {
+		fmt.Printf("[gb agent start] warning: artifact export disabled: %v\n", err)
+		return
+	}
+	if store == nil {
+		return
+	}
+
+	patterns := append(artifacts.ParsePatterns(os.Getenv("BOAT_ARTIFACT_PATHS")), sessionLogPattern)
+	files := artifacts.Select(cfg.workspace, patterns)
+	if len(files) == 0 {
+		fmt.Printf("[gb agent start] no artifacts to export\n")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s/%s/%s/%s-%s", orStr(cfg.project, "none"), cfg.agent,
+		orStr(agentBeadID, cfg.hostname), time.Now().UTC().Format("20060102T150405Z"), outcome)
+	if base := os.Getenv("BOAT_ARTIFACT_PREFIX"); base != "" {
+		prefix = base + "/" + prefix
+	}
+	urls, err := store.Export(ctx, prefix, cfg.workspace, files)
+	if err != nil {
+		fmt.Printf("[gb agent start] warning: artifact export: %v\n", err)
+	}
+	fmt.Printf("[gb agent start] exported %d/%d artifact(s) to %s\n", len(urls), len(files), store.Bucket)
+	if len(urls) == 0 || agentBeadID == "" || daemon == nil {
+		return
+	}
+
+	value, _ := json.Marshal(urls)
+	if err := daemon.UpdateBeadFields(ctx, agentBeadID, map[string]string{"artifacts": string(value)}); err != nil {
+		fmt.Printf("[gb agent start] warning: record artifacts on agent bead: %v\n", err)
+	}
+}
@@ -148,7 +148,8 @@ func writeClaudeSettings(claudeDir string) error {
 }
 
 // writeClaudeMD writes CLAUDE.md into the workspace if it doesn't already
-// exist, then appends the dev-tools table if the guard string is absent.
+// exist, then appends the dev-tools table and role instructions if their
+// guard strings are absent.
 func writeClaudeMD(cfg k8sConfig) {
 	path := filepath.Join(cfg.workspace, "CLAUDE.md")
 
@@ -215,8 +216,23 @@ All tools are installed directly in the agent image — use them from the comman
 			f.Close()
 		}
 	}
+
+	// Import the role instructions from the agent's ConfigMap. An import
+	// rather than a copy, so edits to the source bead reach CLAUDE.md on a
+	// persistent workspace.
+	if _, err := os.Stat(agentConfigInstructions); err == nil && !fileContains(path, "## Role Instructions") {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, _ = f.WriteString("\n## Role Instructions\n\n@" + agentConfigInstructions + "\n")
+			f.Close()
+		}
+	}
 }
 
+// agentConfigInstructions holds the role instructions the controller renders
+// into the agent's ConfigMap (AGENT_CONFIGMAPS).
+const agentConfigInstructions = "/etc/agent-pod/instructions.md"
+
 // writeOnboardingSkip writes ~/.claude.json to bypass the onboarding wizard.
 func writeOnboardingSkip() {
 	data := []byte(`{"hasCompletedOnboarding":true,"lastOnboardingVersion":"2.1.37","preferredTheme":"dark","bypassPermissionsModeAccepted":true}` + "\n")
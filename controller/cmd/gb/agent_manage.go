@@ -0,0 +1,311 @@
+package main
+
+// gb agent spawn/stop/restart/status — operator-side agent lifecycle.
+//
+// These commands act on other agents' beads so operators don't have to
+// hand-edit fields with kd: spawn creates the agent bead the controller
+// schedules, stop closes it, restart sets restart_requested (the controller
+// recreates the pod), and status summarizes bead and pod state.
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+var agentSpawnCmd = &cobra.Command{
+	Use:   "spawn <name>",
+	Short: "Spawn a new agent",
+	Long: `Create an agent bead; the controller schedules its pod.
+
+When more agents are waiting than the controller may start at once, pods are
+created by priority (0 = critical), then oldest first. The priority defaults
+to that of the --task bead.
+
+Examples:
+  gb agent spawn k8s --project gasboat
+  gb agent spawn fixer --project gasboat --role crew --task kd-abc12
+  gb agent spawn pager --project gasboat --role job --priority 0
+  gb agent spawn canary --project gasboat --image-pin ghcr.io/org/agent:v1.2.3
+  gb agent spawn builder --project gasboat --role job --arch arm64
+  gb agent spawn reviewer-2 --project gasboat --template reviewer
+
+A --template names a template bead holding a reusable agent configuration
+(role, image, resources, env, hooks, ...). Its role applies unless --role
+is given; the controller fills in the rest whenever it builds the pod.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentSpawn,
+}
+
+var agentStopCmd = &cobra.Command{
+	Use:   "stop <name>",
+	Short: "Stop an agent and remove its pod",
+	Long: `Mark the agent stop-requested and close its bead, so the controller
+deletes the pod and the entrypoint does not restart it.
+
+Refuses if the agent has claimed in-progress work unless --force is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentStop,
+}
+
+var agentRestartCmd = &cobra.Command{
+	Use:   "restart <name>",
+	Short: "Restart an agent's pod",
+	Long: `Set restart_requested on the agent bead. The controller deletes and
+recreates the agent's pod; the session resumes from its workspace.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentRestart,
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status [name]",
+	Short: "Show agent bead state, pod phase, and coop URL",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runAgentStatus,
+}
+
+var (
+	agentSpawnProject  string
+	agentSpawnRole     string
+	agentSpawnTask     string
+	agentSpawnImagePin string
+	agentSpawnArch     string
+	agentSpawnTemplate string
+	agentSpawnPriority int
+
+	agentStopForce  bool
+	agentStopReason string
+)
+
+func init() {
+	agentSpawnCmd.Flags().StringVar(&agentSpawnProject, "project", "", "project the agent works in (required)")
+	agentSpawnCmd.Flags().StringVar(&agentSpawnRole, "role", "crew", "agent role (captain, crew, job)")
+	agentSpawnCmd.Flags().StringVar(&agentSpawnTask, "task", "", "task bead ID to assign to the agent")
+	agentSpawnCmd.Flags().StringVar(&agentSpawnImagePin, "image-pin", "", "pin the agent image instead of the project/controller default")
+	agentSpawnCmd.Flags().StringVar(&agentSpawnArch, "arch", "", "CPU architecture (amd64, arm64, any) instead of the project/role default")
+	agentSpawnCmd.Flags().StringVar(&agentSpawnTemplate, "template", "", "template bead to base the agent's configuration on")
+	agentSpawnCmd.Flags().IntVar(&agentSpawnPriority, "priority", 2, "spawn queue priority, 0 (critical) to 4 (backlog); defaults to the task's")
+	_ = agentSpawnCmd.MarkFlagRequired("project")
+
+	agentStopCmd.Flags().BoolVar(&agentStopForce, "force", false, "stop even if the agent has claimed in-progress work")
+	agentStopCmd.Flags().StringVar(&agentStopReason, "reason", "", "reason for stopping (added as a comment on the agent bead)")
+}
+
+func runAgentSpawn(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	name := args[0]
+
+	if existing, err := daemon.FindAgentBead(ctx, name); err == nil {
+		return fmt.Errorf("agent %q is already active (%s)", name, existing.ID)
+	}
+	if agentSpawnArch != "" && !beadsapi.ValidArch(agentSpawnArch) {
+		return fmt.Errorf("--arch must be one of %s", strings.Join(beadsapi.Archs, ", "))
+	}
+	var priority *int
+	if cmd.Flags().Changed("priority") {
+		if agentSpawnPriority < 0 || agentSpawnPriority > 4 {
+			return fmt.Errorf("--priority must be between 0 and 4")
+		}
+		priority = &agentSpawnPriority
+	}
+	if agentSpawnTask != "" {
+		task, err := daemon.GetBead(ctx, agentSpawnTask)
+		if err != nil {
+			return fmt.Errorf("looking up task: %w", err)
+		}
+		if priority == nil {
+			priority = &task.Priority
+		}
+	}
+
+	role := agentSpawnRole
+	if agentSpawnTemplate != "" && !cmd.Flags().Changed("role") {
+		role = "" // the template's
+	}
+
+	id, err := daemon.SpawnAgentWith(ctx, beadsapi.SpawnAgentRequest{
+		AgentName: name,
+		Project:   agentSpawnProject,
+		TaskID:    agentSpawnTask,
+		Role:      role,
+		Image:     agentSpawnImagePin,
+		Arch:      agentSpawnArch,
+		Template:  agentSpawnTemplate,
+		Priority:  priority,
+	})
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		if bead, err := daemon.GetBead(ctx, id); err == nil {
+			role = bead.Fields["role"]
+		}
+	}
+
+	if jsonOutput {
+		printJSON(map[string]string{"id": id, "agent": name, "project": agentSpawnProject, "role": role, "template": agentSpawnTemplate})
+		return nil
+	}
+	fmt.Printf("Spawned agent %s (%s) in project %s as %s.\n", name, id, agentSpawnProject, role)
+	if agentSpawnTemplate != "" {
+		fmt.Printf("Based on template %s.\n", agentSpawnTemplate)
+	}
+	if agentSpawnImagePin != "" {
+		fmt.Printf("Image pinned to %s.\n", agentSpawnImagePin)
+	}
+	return nil
+}
+
+func runAgentStop(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	bead, err := findAgent(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	name := bead.Fields["agent"]
+
+	if !agentStopForce {
+		if task, taskErr := daemon.ListAssignedTask(ctx, name); taskErr == nil && task != nil {
+			return fmt.Errorf("agent %s has claimed in-progress work %s (%s) — use --force to stop anyway", name, task.ID, task.Title)
+		}
+	}
+
+	reason := agentStopReason
+	if reason == "" {
+		reason = "stopped by operator"
+	}
+	if err := daemon.AddComment(ctx, bead.ID, actor, "gb agent stop: "+reason); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not add stop comment: %v\n", err)
+	}
+	if err := daemon.UpdateBeadFields(ctx, bead.ID, map[string]string{"stop_requested": "true"}); err != nil {
+		return fmt.Errorf("setting stop_requested on bead %s: %w", bead.ID, err)
+	}
+	if err := daemon.CloseBead(ctx, bead.ID, nil); err != nil {
+		return err
+	}
+
+	fmt.Printf("Stopped agent %s (%s).\n", name, bead.ID)
+	return nil
+}
+
+func runAgentRestart(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	bead, err := findAgent(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if err := daemon.UpdateBeadFields(ctx, bead.ID, map[string]string{"restart_requested": now}); err != nil {
+		return fmt.Errorf("setting restart_requested on bead %s: %w", bead.ID, err)
+	}
+	_ = daemon.AddComment(ctx, bead.ID, actor, "gb agent restart: restart requested")
+
+	fmt.Printf("Restart requested for agent %s (%s); the controller will recreate its pod.\n", bead.Fields["agent"], bead.ID)
+	return nil
+}
+
+// agentStatus is one row of gb agent status.
+type agentStatus struct {
+	ID         string `json:"id"`
+	Agent      string `json:"agent"`
+	Project    string `json:"project"`
+	Role       string `json:"role"`
+	Mode       string `json:"mode"`
+	AgentState string `json:"agent_state"`
+	PodPhase   string `json:"pod_phase"`
+	PodName    string `json:"pod_name,omitempty"`
+	CoopURL    string `json:"coop_url,omitempty"`
+	Image      string `json:"image,omitempty"`
+}
+
+func runAgentStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	var rows []agentStatus
+	if len(args) == 1 {
+		bead, err := findAgent(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		meta := make(map[string]string, len(bead.Fields))
+		for k, v := range bead.Fields {
+			meta[k] = v
+		}
+		for k, v := range beadsapi.ParseNotes(bead.Notes) {
+			meta[k] = v
+		}
+		rows = append(rows, agentStatusFrom(bead.ID, meta))
+	} else {
+		agents, err := daemon.ListAgentBeads(ctx)
+		if err != nil {
+			return err
+		}
+		for _, a := range agents {
+			rows = append(rows, agentStatusFrom(a.ID, a.Metadata))
+		}
+	}
+
+	if jsonOutput {
+		printJSON(rows)
+		return nil
+	}
+	if len(rows) == 0 {
+		fmt.Println("No active agents.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "AGENT\tPROJECT\tROLE\tSTATE\tPOD\tCOOP URL\tID")
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Agent, r.Project, r.Role, orDash(r.AgentState), orDash(r.PodPhase), orDash(r.CoopURL), r.ID)
+	}
+	return w.Flush()
+}
+
+// agentStatusFrom builds a status row from merged agent fields and notes.
+func agentStatusFrom(id string, meta map[string]string) agentStatus {
+	mode := meta["mode"]
+	if mode == "" {
+		mode = "crew"
+	}
+	return agentStatus{
+		ID:         id,
+		Agent:      meta["agent"],
+		Project:    meta["project"],
+		Role:       meta["role"],
+		Mode:       mode,
+		AgentState: meta["agent_state"],
+		PodPhase:   meta["pod_phase"],
+		PodName:    meta["pod_name"],
+		CoopURL:    meta["coop_url"],
+		Image:      meta["image"],
+	}
+}
+
+// findAgent resolves an active agent by name, falling back to a bead ID.
+func findAgent(ctx context.Context, ref string) (*beadsapi.BeadDetail, error) {
+	if bead, err := daemon.FindAgentBead(ctx, ref); err == nil {
+		return bead, nil
+	}
+	bead, err := daemon.GetBead(ctx, ref)
+	if err != nil || bead.Type != "agent" {
+		return nil, fmt.Errorf("no active agent named %q", ref)
+	}
+	return bead, nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
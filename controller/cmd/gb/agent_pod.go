@@ -0,0 +1,198 @@
+package main
+
+// gb agent logs/exec — reach an agent's pod through the controller.
+//
+// The controller resolves the agent's newest pod by label, so operators need
+// neither kubectl nor knowledge of pod naming. Logs use GET /agent-logs and
+// exec uses POST /agent-exec; each requires its own controller token.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var agentLogsCmd = &cobra.Command{
+	Use:   "logs <name>",
+	Short: "Show an agent's pod logs",
+	Long: `Print the agent container's log via the controller.
+
+Requires AGENT_LOGS_TOKEN (the controller's agentLogs token).
+
+Examples:
+  gb agent logs k8s
+  gb agent logs k8s --lines 200
+  gb agent logs k8s --follow`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentLogs,
+}
+
+var agentExecCmd = &cobra.Command{
+	Use:   "exec <name> -- <cmd> [args...]",
+	Short: "Run a one-off command in an agent's pod",
+	Long: `Run a command in the agent container via the controller and print its
+output. gb exits with the command's exit code.
+
+Requires AGENT_EXEC_TOKEN (the controller's agentExec token).
+
+Examples:
+  gb agent exec k8s -- git -C /home/agent/workspace status
+  gb agent exec k8s --timeout 5m -- df -h`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.ArgsLenAtDash() != 1 || len(args) < 2 {
+			return fmt.Errorf("usage: gb agent exec <name> -- <cmd> [args...]")
+		}
+		return nil
+	},
+	RunE: runAgentExec,
+}
+
+var (
+	agentControllerURL string
+	agentLogsFollow    bool
+	agentLogsLines     int
+	agentExecTimeout   time.Duration
+)
+
+func defaultControllerURL() string {
+	if s := os.Getenv("GB_CONTROLLER_URL"); s != "" {
+		return s
+	}
+	if s := os.Getenv("CONTROLLER_URL"); s != "" {
+		return s
+	}
+	return "http://localhost:8091"
+}
+
+func init() {
+	for _, c := range []*cobra.Command{agentLogsCmd, agentExecCmd} {
+		c.Flags().StringVar(&agentControllerURL, "controller-url", defaultControllerURL(), "controller health/API URL")
+	}
+	agentLogsCmd.Flags().BoolVarP(&agentLogsFollow, "follow", "f", false, "stream new log output until interrupted")
+	agentLogsCmd.Flags().IntVarP(&agentLogsLines, "lines", "n", 50, "number of trailing lines to show")
+	agentExecCmd.Flags().DurationVar(&agentExecTimeout, "timeout", time.Minute, "maximum time the command may run (max 10m)")
+}
+
+func runAgentLogs(cmd *cobra.Command, args []string) error {
+	token := os.Getenv("AGENT_LOGS_TOKEN")
+	if token == "" {
+		return fmt.Errorf("AGENT_LOGS_TOKEN is not set")
+	}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	q := url.Values{}
+	q.Set("agent", args[0])
+	q.Set("lines", strconv.Itoa(agentLogsLines))
+	if agentLogsFollow {
+		q.Set("follow", "true")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimRight(agentControllerURL, "/")+"/agent-logs?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{}
+	if !agentLogsFollow {
+		client.Timeout = 30 * time.Second
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("contacting controller at %s: %w", agentControllerURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return controllerError(resp)
+	}
+
+	if _, err := io.Copy(os.Stdout, resp.Body); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("reading logs: %w", err)
+	}
+	return nil
+}
+
+// agentExecResult mirrors the controller's /agent-exec response.
+type agentExecResult struct {
+	Pod       string `json:"pod"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	ExitCode  int    `json:"exit_code"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+func runAgentExec(cmd *cobra.Command, args []string) error {
+	token := os.Getenv("AGENT_EXEC_TOKEN")
+	if token == "" {
+		return fmt.Errorf("AGENT_EXEC_TOKEN is not set")
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"agent":           args[0],
+		"command":         args[1:],
+		"timeout_seconds": int(agentExecTimeout.Seconds()),
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), agentExecTimeout+30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(agentControllerURL, "/")+"/agent-exec", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("contacting controller at %s: %w", agentControllerURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return controllerError(resp)
+	}
+
+	var result agentExecResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decoding exec response: %w", err)
+	}
+	if jsonOutput {
+		printJSON(result)
+	} else {
+		fmt.Fprint(os.Stdout, result.Stdout)
+		fmt.Fprint(os.Stderr, result.Stderr)
+		if result.Truncated {
+			fmt.Fprintln(os.Stderr, "(output truncated)")
+		}
+	}
+	if result.ExitCode != 0 {
+		os.Exit(result.ExitCode)
+	}
+	return nil
+}
+
+// controllerError turns a non-2xx controller response into an error.
+func controllerError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	msg := strings.TrimSpace(string(body))
+	if resp.StatusCode == http.StatusNotFound && msg == "404 page not found" {
+		return fmt.Errorf("controller does not serve %s (is it enabled?)", resp.Request.URL.Path)
+	}
+	return fmt.Errorf("controller returned %d: %s", resp.StatusCode, msg)
+}
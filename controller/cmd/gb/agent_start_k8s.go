@@ -125,12 +125,14 @@ func runAgentStartK8s(cmd *cobra.Command, args []string) error {
 
 	const minRuntimeSecs = 30
 	restarts := 0
+	agentBeadID := envOr("KD_AGENT_ID", os.Getenv("BOAT_AGENT_BEAD_ID"))
 
 	for {
 		if ctx.Err() != nil {
 			return nil // SIGTERM/SIGINT — clean exit
 		}
 		if restarts >= cfg.maxRestarts {
+			exportArtifacts(cfg, agentBeadID, "failed")
 			return fmt.Errorf("max restarts (%d) reached", cfg.maxRestarts)
 		}
 
@@ -142,6 +144,9 @@ func runAgentStartK8s(cmd *cobra.Command, args []string) error {
 		elapsed := time.Since(start)
 
 		if ctx.Err() != nil {
+			// The controller deletes the pod when the agent is done or
+			// killed; save what it did before the pod goes.
+			exportArtifacts(cfg, agentBeadID, "stopped")
 			return nil // clean SIGTERM exit
 		}
 
@@ -156,9 +161,9 @@ func runAgentStartK8s(cmd *cobra.Command, args []string) error {
 		// queryable for historical purposes — closed beads are not deleted).
 		// The reconciler excludes closed beads from desired state, so no
 		// new pod will be spawned.
-		agentBeadID := envOr("KD_AGENT_ID", os.Getenv("BOAT_AGENT_BEAD_ID"))
 		if agentBeadID != "" && isStopRequested(ctx, agentBeadID) {
 			fmt.Printf("[gb agent start] stop requested — closing bead and exiting cleanly\n")
+			exportArtifacts(cfg, agentBeadID, "done")
 			closeCtx, closeCancel := context.WithTimeout(context.Background(), 10*time.Second)
 			defer closeCancel()
 			if err := daemon.CloseBead(closeCtx, agentBeadID, map[string]string{"agent_state": "done"}); err != nil {
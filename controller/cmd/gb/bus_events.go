@@ -0,0 +1,264 @@
+package main
+
+// gb bus tail / gb bus publish — scriptable access to the event stream.
+//
+// tail prints daemon SSE events as line-delimited JSON. publish emits a
+// custom event by creating (and immediately closing) an event bead; tail
+// surfaces those as "bus.<topic>" events, so scripts can subscribe to both
+// bead lifecycle and custom events the same way.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+// busTopicPrefix namespaces custom events published with gb bus publish.
+const busTopicPrefix = "bus."
+
+var busTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Stream events as line-delimited JSON",
+	Long: `Streams events from the kbeads SSE endpoint, one JSON object per line:
+
+  {"topic":"beads.bead.closed","data":{...},"received_at":"..."}
+
+Topics use NATS-style patterns ("*" matches one token, ">" the rest).
+Custom events from 'gb bus publish <topic>' appear as "bus.<topic>" with the
+published payload as data. Reconnects automatically until interrupted.
+
+Examples:
+  gb bus tail
+  gb bus tail --topic beads.bead.closed
+  gb bus tail --topic 'bus.deploy.>' | jq -r .data.version`,
+	RunE: runBusTail,
+}
+
+var busTailTopics []string
+
+func init() {
+	busTailCmd.Flags().StringSliceVar(&busTailTopics, "topic", nil, "topic pattern to subscribe to (repeatable; default: all)")
+	busCmd.AddCommand(busTailCmd)
+}
+
+// busLine is one line of gb bus tail output.
+type busLine struct {
+	Topic      string          `json:"topic"`
+	Data       json.RawMessage `json:"data"`
+	BeadID     string          `json:"bead_id,omitempty"`
+	Source     string          `json:"source,omitempty"`
+	ReceivedAt string          `json:"received_at"`
+}
+
+func runBusTail(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverTopics, wantCustom := splitBusTopics(busTailTopics)
+	enc := json.NewEncoder(os.Stdout)
+
+	for {
+		events, err := daemon.EventStream(ctx, strings.Join(serverTopics, ","))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gb bus tail: %v (retrying)\n", err)
+		} else {
+			for evt := range events {
+				for _, line := range busLinesFor(evt, busTailTopics, wantCustom) {
+					if err := enc.Encode(line); err != nil {
+						return nil // stdout closed (e.g. piped to head)
+					}
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// splitBusTopics returns the topic filter to send to the daemon and whether
+// custom bus events are wanted. Custom events ride on bead creation, so any
+// bus.* pattern subscribes the daemon stream to beads.bead.created.
+func splitBusTopics(patterns []string) ([]string, bool) {
+	if len(patterns) == 0 {
+		return nil, true
+	}
+	var server []string
+	custom := false
+	for _, p := range patterns {
+		if p == ">" {
+			return nil, true
+		}
+		if strings.HasPrefix(p, busTopicPrefix) {
+			custom = true
+			p = "beads.bead.created"
+		}
+		server = append(server, p)
+	}
+	return server, custom
+}
+
+// busLinesFor converts one SSE event into output lines: the raw event when
+// it matches the requested patterns, plus the custom event it carries.
+func busLinesFor(evt beadsapi.SSEEvent, patterns []string, wantCustom bool) []busLine {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var lines []busLine
+
+	if topicMatchesAny(patterns, evt.Event) {
+		lines = append(lines, busLine{Topic: evt.Event, Data: evt.Data, ReceivedAt: now})
+	}
+
+	if wantCustom && evt.Event == "beads.bead.created" {
+		var payload struct {
+			Bead struct {
+				ID        string                     `json:"id"`
+				Type      string                     `json:"type"`
+				CreatedBy string                     `json:"created_by"`
+				Fields    map[string]json.RawMessage `json:"fields"`
+			} `json:"bead"`
+		}
+		if json.Unmarshal(evt.Data, &payload) == nil && payload.Bead.Type == "event" {
+			var topic string
+			_ = json.Unmarshal(payload.Bead.Fields["topic"], &topic)
+			data := decodeEventPayload(payload.Bead.Fields["payload"])
+			if topic != "" && topicMatchesAny(patterns, busTopicPrefix+topic) {
+				lines = append(lines, busLine{
+					Topic:      busTopicPrefix + topic,
+					Data:       data,
+					BeadID:     payload.Bead.ID,
+					Source:     payload.Bead.CreatedBy,
+					ReceivedAt: now,
+				})
+			}
+		}
+	}
+	return lines
+}
+
+// decodeEventPayload returns the published payload. The daemon may hand a
+// json-typed field back either as the value itself or as a JSON string.
+func decodeEventPayload(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil && json.Valid([]byte(s)) {
+		return json.RawMessage(s)
+	}
+	return raw
+}
+
+// topicMatchesAny reports whether topic matches any NATS-style pattern; an
+// empty pattern list matches everything.
+func topicMatchesAny(patterns []string, topic string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if topicMatches(p, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func topicMatches(pattern, topic string) bool {
+	pt := strings.Split(pattern, ".")
+	tt := strings.Split(topic, ".")
+	for i, p := range pt {
+		if p == ">" {
+			return i < len(tt)
+		}
+		if i >= len(tt) || (p != "*" && p != tt[i]) {
+			return false
+		}
+	}
+	return len(pt) == len(tt)
+}
+
+var busPublishCmd = &cobra.Command{
+	Use:   "publish <topic> [json]",
+	Short: "Publish a custom event",
+	Long: `Publishes a custom event that 'gb bus tail' subscribers receive as
+"bus.<topic>". The payload is a JSON value given as an argument, or read from
+stdin when the argument is "-". Defaults to {}.
+
+The event is recorded as a closed event bead, so it is also auditable with kd.
+
+Examples:
+  gb bus publish deploy.finished '{"version":"1.4.2"}'
+  kubectl get pods -o json | gb bus publish cluster.snapshot -`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		topic := args[0]
+		if topic == "" || strings.ContainsAny(topic, "*> ") {
+			return fmt.Errorf("invalid topic %q: use dot-separated tokens without wildcards", topic)
+		}
+		data := []byte("{}")
+		if len(args) == 2 {
+			data = []byte(args[1])
+			if args[1] == "-" {
+				var err error
+				if data, err = io.ReadAll(os.Stdin); err != nil {
+					return fmt.Errorf("reading payload: %w", err)
+				}
+			}
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("payload is not valid JSON")
+		}
+
+		id, err := publishBusEvent(cmd.Context(), topic, data)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			printJSON(map[string]string{"id": id, "topic": busTopicPrefix + topic})
+			return nil
+		}
+		fmt.Printf("Published %s%s (%s)\n", busTopicPrefix, topic, id)
+		return nil
+	},
+}
+
+func init() {
+	busCmd.AddCommand(busPublishCmd)
+}
+
+// publishBusEvent records a custom event as an event bead and closes it
+// right away; subscribers see it via the bead's creation event.
+func publishBusEvent(ctx context.Context, topic string, payload []byte) (string, error) {
+	fields, err := json.Marshal(map[string]any{
+		"topic":   topic,
+		"payload": json.RawMessage(payload),
+	})
+	if err != nil {
+		return "", err
+	}
+	id, err := daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title:     "event: " + topic,
+		Type:      "event",
+		Labels:    []string{"bus:" + topic},
+		CreatedBy: actor,
+		Fields:    fields,
+	})
+	if err != nil {
+		return "", fmt.Errorf("publishing %s: %w", topic, err)
+	}
+	if err := daemon.CloseBead(ctx, id, nil); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: event %s published but not closed: %v\n", id, err)
+	}
+	return id, nil
+}
@@ -31,11 +31,17 @@ var decisionCreateCmd = &cobra.Command{
 		optionsJSON, _ := cmd.Flags().GetString("options")
 		requestedBy, _ := cmd.Flags().GetString("requested-by")
 		decisionCtx, _ := cmd.Flags().GetString("context")
+		attach, _ := cmd.Flags().GetStringArray("attach")
 		noWait, _ := cmd.Flags().GetBool("no-wait")
+		deadline, _ := cmd.Flags().GetString("deadline")
 
 		if prompt == "" {
 			return fmt.Errorf("--prompt is required")
 		}
+		dueAt, err := parseDeadline(deadline, time.Now())
+		if err != nil {
+			return err
+		}
 
 		fields := map[string]any{
 			"prompt": prompt,
@@ -60,7 +66,13 @@ var decisionCreateCmd = &cobra.Command{
 			}
 			fields["options"] = json.RawMessage(optionsJSON)
 		}
-		if decisionCtx != "" {
+		if len(attach) > 0 {
+			attachments, err := decisionAttachments(decisionCtx, attach)
+			if err != nil {
+				return err
+			}
+			fields["context"] = attachments
+		} else if decisionCtx != "" {
 			fields["context"] = decisionCtx
 		}
 		if requestedBy == "" {
@@ -87,6 +99,7 @@ var decisionCreateCmd = &cobra.Command{
 			Priority:  priority,
 			Assignee:  actor,
 			CreatedBy: actor,
+			DueAt:     dueAt,
 			Fields:    fieldsJSON,
 		})
 		if err != nil {
@@ -181,15 +194,18 @@ var decisionRespondCmd = &cobra.Command{
 			return fmt.Errorf("--select or --text is required")
 		}
 
-		fields := map[string]string{}
+		responder := beadsapi.Responder{
+			Identity: beadsapi.NormalizeIdentity("gb", actor, actorEmail()),
+			Name:     actor,
+			Via:      "gb",
+		}
+		fields := responder.AuditFields(time.Now())
 		if selected != "" {
 			fields["chosen"] = selected
 		}
 		if text != "" {
 			fields["response_text"] = text
 		}
-		fields["responded_by"] = actor
-		fields["responded_at"] = time.Now().UTC().Format(time.RFC3339)
 
 		// Look up artifact_type from the chosen option.
 		if selected != "" {
@@ -263,7 +279,23 @@ func printDecisionDetail(b *beadsapi.BeadDetail) {
 	}
 
 	if ctx := b.Fields["context"]; ctx != "" {
-		fmt.Printf("Context:  %s\n", ctx)
+		var attachments []decisionAttachment
+		if err := json.Unmarshal([]byte(ctx), &attachments); err != nil || len(attachments) == 0 {
+			fmt.Printf("Context:  %s\n", ctx)
+		} else {
+			fmt.Println("Context:")
+			for _, a := range attachments {
+				switch {
+				case a.URL != "" && a.Content == "":
+					fmt.Printf("  [%s] %s\n", a.Kind, a.URL)
+				default:
+					fmt.Printf("  [%s] %s (%d lines)\n", a.Kind, a.Title, strings.Count(strings.TrimRight(a.Content, "\n"), "\n")+1)
+				}
+			}
+		}
+	}
+	if b.DueAt != "" {
+		fmt.Printf("Due:      %s\n", b.DueAt)
 	}
 
 	optionsRaw := b.Fields["options"]
@@ -521,6 +553,82 @@ func senderFromLabels(labels []string) string {
 	return ""
 }
 
+// parseDeadline converts a --deadline value, a duration from now or an
+// RFC 3339 time, to the RFC 3339 due_at sent to the daemon.
+func parseDeadline(s string, now time.Time) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return "", fmt.Errorf("--deadline must be in the future")
+		}
+		return now.Add(d).UTC().Format(time.RFC3339), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return "", fmt.Errorf("invalid --deadline %q: want a duration (2h) or an RFC 3339 time", s)
+	}
+	return t.UTC().Format(time.RFC3339), nil
+}
+
+// maxAttachmentBytes caps one context attachment so a decision bead stays a
+// reasonable size; logs keep their end, everything else its start.
+const maxAttachmentBytes = 64 << 10
+
+// decisionAttachment is one entry of a decision's context field, as the
+// Slack bridge and decision viewers render it.
+type decisionAttachment struct {
+	Kind    string `json:"kind"`
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// decisionAttachments builds the context field from --context text and
+// --attach specs of the form kind:source, where source is a file ("-" for
+// stdin) or, for links, a URL.
+func decisionAttachments(text string, specs []string) ([]decisionAttachment, error) {
+	var attachments []decisionAttachment
+	if text != "" {
+		attachments = append(attachments, decisionAttachment{Kind: "text", Content: text})
+	}
+	for _, spec := range specs {
+		kind, source, ok := strings.Cut(spec, ":")
+		if !ok || source == "" {
+			return nil, fmt.Errorf("invalid --attach %q: want kind:source", spec)
+		}
+		switch kind {
+		case "link":
+			attachments = append(attachments, decisionAttachment{Kind: kind, URL: source})
+			continue
+		case "diff", "log", "text":
+		default:
+			return nil, fmt.Errorf("unknown --attach kind %q (allowed: diff, log, text, link)", kind)
+		}
+		var data []byte
+		var err error
+		if source == "-" {
+			data, err = io.ReadAll(os.Stdin)
+			source = "stdin"
+		} else {
+			data, err = os.ReadFile(source)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading --attach %s: %w", spec, err)
+		}
+		if len(data) > maxAttachmentBytes {
+			if kind == "log" {
+				data = data[len(data)-maxAttachmentBytes:]
+			} else {
+				data = data[:maxAttachmentBytes]
+			}
+		}
+		attachments = append(attachments, decisionAttachment{Kind: kind, Title: source, Content: string(data)})
+	}
+	return attachments, nil
+}
+
 func init() {
 	decisionCmd.AddCommand(decisionCreateCmd)
 	decisionCmd.AddCommand(decisionListCmd)
@@ -532,8 +640,10 @@ func init() {
 	decisionCreateCmd.Flags().String("options", "", "options JSON array")
 	decisionCreateCmd.Flags().String("requested-by", "", "who is requesting (default: actor)")
 	decisionCreateCmd.Flags().String("context", "", "background context for the decision")
+	decisionCreateCmd.Flags().StringArray("attach", nil, "context attachment: diff:<file>, log:<file>, text:<file> or link:<url> (repeatable; - reads stdin)")
 	decisionCreateCmd.Flags().Bool("no-wait", false, "return immediately without waiting for response")
 	decisionCreateCmd.Flags().Int("priority", 2, "decision priority: 0=critical, 1=high, 2=normal, 3=low, 4=backlog")
+	decisionCreateCmd.Flags().String("deadline", "", "respond-by deadline: a duration from now (e.g. 2h) or an RFC 3339 time")
 
 	decisionListCmd.Flags().StringSliceP("status", "s", nil, "filter by status")
 	decisionListCmd.Flags().Int("limit", 20, "maximum number of results")
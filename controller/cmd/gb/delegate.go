@@ -0,0 +1,205 @@
+package main
+
+// gb delegate — agent-to-agent delegation.
+//
+// An agent hands a subtask to another agent: delegate creates a task bead
+// assigned to the delegate (a child of the delegating agent's own task) and,
+// with --spawn, a job-mode agent to work it. The delegate closes the task
+// with "gb delegate done", which mails the result back. A spawned delegate
+// records its parent agent, so the controller stops it when the parent is
+// stopped.
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+var delegateCmd = &cobra.Command{
+	Use:   "delegate <title>",
+	Short: "Delegate a subtask to another agent",
+	Long: `Create a task bead assigned to another agent. With --spawn, a new
+job-mode agent of that name is spawned to work it; it is stopped when you are.
+
+The task becomes a child of your in-progress task (or --task). The delegate
+reports back with "gb delegate done", which closes the task and mails you
+the result.
+
+Examples:
+  gb delegate "Bisect the flaky e2e test" --to bisector --spawn
+  gb delegate "Review the API change" --to reviewer -d "PR #42, focus on errors"
+  gb delegate list
+  gb delegate done kd-abc12 --result "Culprit is 3f2a1c: races the watcher"`,
+	GroupID: "orchestration",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runDelegate,
+}
+
+var delegateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tasks you delegated",
+	Args:  cobra.NoArgs,
+	RunE:  runDelegateList,
+}
+
+var delegateDoneCmd = &cobra.Command{
+	Use:   "done <task>",
+	Short: "Close a delegated task and send the result back",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDelegateDone,
+}
+
+var (
+	delegateTo          string
+	delegateSpawn       bool
+	delegateDescription string
+	delegateTask        string
+	delegateProject     string
+	delegatePriority    int
+
+	delegateListAll bool
+
+	delegateResult string
+)
+
+func init() {
+	delegateCmd.Flags().StringVar(&delegateTo, "to", "", "agent to delegate to (required)")
+	delegateCmd.Flags().BoolVar(&delegateSpawn, "spawn", false, "spawn --to as a new job-mode agent for the task")
+	delegateCmd.Flags().StringVarP(&delegateDescription, "description", "d", "", "task description")
+	delegateCmd.Flags().StringVar(&delegateTask, "task", "", "parent task (default: your in-progress task)")
+	delegateCmd.Flags().StringVar(&delegateProject, "project", defaultGBProject(), "project of the task and spawned agent (default: $KD_PROJECT or $BOAT_PROJECT)")
+	delegateCmd.Flags().IntVar(&delegatePriority, "priority", 2, "task priority, 0 (critical) to 4 (backlog); defaults to the parent task's")
+	_ = delegateCmd.MarkFlagRequired("to")
+
+	delegateListCmd.Flags().BoolVar(&delegateListAll, "all", false, "include completed delegations")
+
+	delegateDoneCmd.Flags().StringVar(&delegateResult, "result", "", "result to send to the delegating agent (required)")
+	_ = delegateDoneCmd.MarkFlagRequired("result")
+
+	delegateCmd.AddCommand(delegateListCmd)
+	delegateCmd.AddCommand(delegateDoneCmd)
+}
+
+func runDelegate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	me := resolveMailActor()
+
+	if delegateSpawn {
+		if existing, err := daemon.FindAgentBead(ctx, delegateTo); err == nil {
+			return fmt.Errorf("agent %q is already active (%s); drop --spawn to delegate to it", delegateTo, existing.ID)
+		}
+		if delegateProject == "" {
+			return fmt.Errorf("--project is required with --spawn")
+		}
+	}
+	var priority *int
+	if cmd.Flags().Changed("priority") {
+		if delegatePriority < 0 || delegatePriority > 4 {
+			return fmt.Errorf("--priority must be between 0 and 4")
+		}
+		priority = &delegatePriority
+	}
+
+	parentTask := delegateTask
+	if parentTask == "" {
+		if task, err := daemon.ListAssignedTask(ctx, me); err == nil && task != nil {
+			parentTask = task.ID
+		}
+	}
+	if parentTask != "" && priority == nil {
+		if task, err := daemon.GetBead(ctx, parentTask); err == nil {
+			priority = &task.Priority
+		}
+	}
+	// Without an agent bead (an operator delegating), a spawned delegate
+	// has no parent and outlives nothing.
+	parentAgent, _ := resolveAgentIDWithFallback(ctx, "")
+
+	d, err := daemon.Delegate(ctx, beadsapi.DelegateRequest{
+		Title:         args[0],
+		Description:   delegateDescription,
+		Project:       delegateProject,
+		Priority:      priority,
+		From:          me,
+		FromAgentID:   parentAgent,
+		ParentTask:    parentTask,
+		To:            delegateTo,
+		SpawnDelegate: delegateSpawn,
+	})
+	if err != nil {
+		if d != nil {
+			return fmt.Errorf("task %s created: %w", d.TaskID, err)
+		}
+		return err
+	}
+
+	if jsonOutput {
+		printJSON(d)
+		return nil
+	}
+	fmt.Printf("Delegated %s to %s.\n", d.TaskID, d.Agent)
+	if parentTask != "" {
+		fmt.Printf("Subtask of %s.\n", parentTask)
+	}
+	if d.AgentID != "" {
+		fmt.Printf("Spawned job agent %s (%s) in project %s.\n", d.Agent, d.AgentID, delegateProject)
+	}
+	return nil
+}
+
+func runDelegateList(cmd *cobra.Command, _ []string) error {
+	me := resolveMailActor()
+	statuses := []string{"open", "in_progress", "blocked", "deferred"}
+	if delegateListAll {
+		statuses = append(statuses, "closed")
+	}
+	result, err := daemon.ListBeadsFiltered(cmd.Context(), beadsapi.ListBeadsQuery{
+		Types:    []string{"task"},
+		Statuses: statuses,
+		Labels:   []string{beadsapi.DelegatedLabel},
+		Sort:     "-created_at",
+		Limit:    100,
+	})
+	if err != nil {
+		return fmt.Errorf("listing delegated tasks: %w", err)
+	}
+	var mine []*beadsapi.BeadDetail
+	for _, b := range result.Beads {
+		if b.Fields[beadsapi.DelegatedByField] == me {
+			mine = append(mine, b)
+		}
+	}
+
+	if jsonOutput {
+		printJSON(mine)
+		return nil
+	}
+	if len(mine) == 0 {
+		fmt.Println("No delegated tasks")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TASK\tAGENT\tSTATUS\tTITLE\tRESULT")
+	for _, b := range mine {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", b.ID, orDash(b.Assignee), b.Status, b.Title,
+			orDash(b.Fields[beadsapi.DelegationResultField]))
+	}
+	return w.Flush()
+}
+
+func runDelegateDone(cmd *cobra.Command, args []string) error {
+	me := resolveMailActor()
+	if err := daemon.CompleteDelegation(cmd.Context(), args[0], delegateResult, me); err != nil {
+		return err
+	}
+	if jsonOutput {
+		printJSON(map[string]string{"task_id": args[0], "status": "closed"})
+		return nil
+	}
+	fmt.Printf("Closed %s and sent the result back.\n", args[0])
+	return nil
+}
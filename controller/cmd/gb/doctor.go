@@ -0,0 +1,314 @@
+package main
+
+// gb doctor — diagnose an agent's environment.
+//
+// Runs the checks operators otherwise do by hand when an agent misbehaves:
+// daemon reachability, agent bead consistency, coop health, and git
+// credentials. Each finding carries a suggested fix; gb doctor exits 1 when
+// any check fails.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose daemon, agent bead, coop, and git credential problems",
+	Long: `Checks, in order:
+  daemon    — kbeads HTTP API reachable and answering queries
+  agent     — agent bead exists, is open, and its fields are consistent
+  project   — the agent's project is registered
+  coop      — coop API healthy and reporting an agent state
+  git       — git identity configured and the project repo reachable
+
+Agent identity comes from --agent-id, KD_AGENT_ID, or KD_ACTOR.
+
+Examples:
+  gb doctor
+  gb doctor --agent-id kd-abc12 --skip-git
+  gb doctor --json`,
+	GroupID: "agent",
+	RunE:    runDoctor,
+}
+
+var (
+	doctorAgentID string
+	doctorCoopURL string
+	doctorSkipGit bool
+)
+
+func init() {
+	doctorCmd.Flags().StringVar(&doctorAgentID, "agent-id", "", "agent bead ID (default: KD_AGENT_ID env)")
+	doctorCmd.Flags().StringVar(&doctorCoopURL, "coop-url", "", "coop API URL (default: coop_url from the agent bead, then http://localhost:8080)")
+	doctorCmd.Flags().BoolVar(&doctorSkipGit, "skip-git", false, "skip the git remote check (no network access to the repo)")
+}
+
+// doctorFinding is the result of one check.
+type doctorFinding struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"` // ok, warn, fail
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+type doctorReport struct {
+	findings []doctorFinding
+}
+
+func (r *doctorReport) ok(check, msg string) {
+	r.findings = append(r.findings, doctorFinding{Check: check, Status: "ok", Message: msg})
+}
+
+func (r *doctorReport) warn(check, msg, fix string) {
+	r.findings = append(r.findings, doctorFinding{Check: check, Status: "warn", Message: msg, Fix: fix})
+}
+
+func (r *doctorReport) fail(check, msg, fix string) {
+	r.findings = append(r.findings, doctorFinding{Check: check, Status: "fail", Message: msg, Fix: fix})
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Minute)
+	defer cancel()
+
+	r := &doctorReport{}
+	if doctorCheckDaemon(ctx, r) {
+		bead := doctorCheckAgent(ctx, r)
+		project := doctorCheckProject(ctx, r, bead)
+		doctorCheckCoop(ctx, r, bead)
+		doctorCheckGit(ctx, r, project)
+	}
+
+	failed := 0
+	for _, f := range r.findings {
+		if f.Status == "fail" {
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		printJSON(r.findings)
+	} else {
+		for _, f := range r.findings {
+			mark := "✓"
+			switch f.Status {
+			case "warn":
+				mark = "!"
+			case "fail":
+				mark = "✗"
+			}
+			fmt.Printf("%s %-8s %s\n", mark, f.Check, f.Message)
+			if f.Fix != "" {
+				fmt.Printf("           → %s\n", f.Fix)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// doctorCheckDaemon reports whether the daemon is usable; later checks are
+// skipped when it is not.
+func doctorCheckDaemon(ctx context.Context, r *doctorReport) bool {
+	if err := daemon.Health(ctx); err != nil {
+		r.fail("daemon", fmt.Sprintf("%s unreachable: %v", httpURL, err),
+			"check BEADS_HTTP_URL / --http-url and that kd serve is running")
+		return false
+	}
+	if _, err := daemon.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{Types: []string{"agent"}, Limit: 1}); err != nil {
+		r.fail("daemon", fmt.Sprintf("health OK but queries fail: %v", err),
+			"check the daemon's auth configuration and logs")
+		return false
+	}
+	r.ok("daemon", httpURL+" reachable")
+	return true
+}
+
+// doctorCheckAgent verifies the agent bead and returns it, or nil.
+func doctorCheckAgent(ctx context.Context, r *doctorReport) *beadsapi.BeadDetail {
+	agentID, err := resolveAgentIDWithFallback(ctx, doctorAgentID)
+	if err != nil {
+		r.warn("agent", "no agent identity: "+err.Error(),
+			"set KD_AGENT_ID (agent pods get it from the controller) or pass --agent-id")
+		return nil
+	}
+	bead, err := daemon.GetBead(ctx, agentID)
+	if err != nil {
+		r.fail("agent", fmt.Sprintf("agent bead %s not found: %v", agentID, err),
+			"the bead was deleted or KD_AGENT_ID is stale; respawn with gb agent spawn")
+		return nil
+	}
+	if bead.Type != "agent" {
+		r.fail("agent", fmt.Sprintf("%s is a %s bead, not an agent", agentID, bead.Type),
+			"point KD_AGENT_ID at the agent's own bead")
+		return nil
+	}
+	if bead.Status == "closed" {
+		r.fail("agent", fmt.Sprintf("agent bead %s is closed", agentID),
+			"the controller will not keep this pod; respawn with gb agent spawn")
+	} else {
+		r.ok("agent", fmt.Sprintf("bead %s (%s) is %s", bead.ID, bead.Fields["agent"], bead.Status))
+	}
+
+	for _, f := range []string{"agent", "project", "role"} {
+		if bead.Fields[f] == "" {
+			r.fail("agent", "field "+f+" is empty",
+				fmt.Sprintf("kd update %s --field %s=<value>", bead.ID, f))
+		}
+	}
+	if role := bead.Fields["role"]; role != "" && !slices.Contains([]string{"captain", "crew", "job"}, role) {
+		r.warn("agent", "unknown role "+role, "use captain, crew, or job")
+	}
+	if name := bead.Fields["agent"]; name != "" && actor != "" && actor != "unknown" && actor != name {
+		r.warn("agent", fmt.Sprintf("KD_ACTOR is %q but the bead's agent is %q", actor, name),
+			"claims and assignments use KD_ACTOR; align it with the agent name")
+	}
+	if bead.Fields["agent_state"] == "failed" {
+		r.warn("agent", "agent_state is failed", "gb agent restart "+bead.Fields["agent"])
+	}
+	if bead.Fields["stop_requested"] == "true" && bead.Status != "closed" {
+		r.warn("agent", "stop_requested is set on an open bead; the next exit will close it",
+			fmt.Sprintf("kd update %s --field stop_requested= to cancel the stop", bead.ID))
+	}
+	if host, _ := os.Hostname(); host != "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		if pod := bead.Fields["pod_name"]; pod != "" && pod != host {
+			r.warn("agent", fmt.Sprintf("bead pod_name %s differs from this pod %s", pod, host),
+				"another pod may own this agent; check gb agent status")
+		}
+	}
+	if phase := bead.Fields["pod_phase"]; phase == "failed" {
+		r.warn("agent", "pod_phase is failed", "gb agent logs "+bead.Fields["agent"])
+	}
+	return bead
+}
+
+// doctorCheckProject verifies the agent's project and returns its metadata.
+func doctorCheckProject(ctx context.Context, r *doctorReport, bead *beadsapi.BeadDetail) *beadsapi.ProjectInfo {
+	name := defaultGBProject()
+	if bead != nil && bead.Fields["project"] != "" {
+		name = bead.Fields["project"]
+	}
+	if name == "" {
+		return nil
+	}
+	projects, err := daemon.ListProjectBeads(ctx)
+	if err != nil {
+		r.warn("project", "could not list projects: "+err.Error(), "")
+		return nil
+	}
+	info, ok := projects[name]
+	if !ok {
+		r.fail("project", "project "+name+" is not registered",
+			"create a project bead for it (kd create --type project) or fix the agent's project field")
+		return nil
+	}
+	if info.GitURL == "" {
+		r.warn("project", "project "+name+" has no git_url", "set git_url on the project bead")
+	} else {
+		r.ok("project", name+" → "+info.GitURL)
+	}
+	return &info
+}
+
+// doctorCheckCoop checks the coop API's health and agent state.
+func doctorCheckCoop(ctx context.Context, r *doctorReport, bead *beadsapi.BeadDetail) {
+	coopURL := doctorCoopURL
+	if coopURL == "" && bead != nil {
+		coopURL = beadsapi.ParseNotes(bead.Notes)["coop_url"]
+	}
+	if coopURL == "" {
+		coopURL = "http://localhost:8080"
+	}
+	base := strings.TrimRight(coopURL, "/") + "/api/v1"
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, base+"/health", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		r.fail("coop", fmt.Sprintf("%s unreachable: %v", coopURL, err),
+			"the coop process is down or the pod is restarting; check gb agent logs")
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		r.fail("coop", fmt.Sprintf("health returned HTTP %d", resp.StatusCode), "check gb agent logs")
+		return
+	}
+
+	state, err := getDoctorCoopState(ctx, client, base)
+	switch {
+	case err != nil:
+		r.warn("coop", "healthy but agent state unavailable: "+err.Error(), "")
+	case state == "exited" || state == "error":
+		r.fail("coop", "agent state is "+state, "gb agent restart to start a fresh session")
+	default:
+		r.ok("coop", fmt.Sprintf("%s healthy, agent %s", coopURL, state))
+	}
+}
+
+func getDoctorCoopState(ctx context.Context, client *http.Client, base string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/agent", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	return body.State, nil
+}
+
+// doctorCheckGit checks the git identity and that the project repo accepts
+// the configured credentials.
+func doctorCheckGit(ctx context.Context, r *doctorReport, project *beadsapi.ProjectInfo) {
+	if _, err := exec.LookPath("git"); err != nil {
+		r.warn("git", "git not installed", "")
+		return
+	}
+	for _, key := range []string{"user.name", "user.email"} {
+		out, _ := exec.CommandContext(ctx, "git", "config", "--get", key).Output()
+		if strings.TrimSpace(string(out)) == "" {
+			r.warn("git", key+" is not set", "git config --global "+key+" <value>")
+		}
+	}
+
+	if doctorSkipGit || project == nil || project.GitURL == "" {
+		return
+	}
+	lsCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	defer cancel()
+	ls := exec.CommandContext(lsCtx, "git", "ls-remote", "--heads", project.GitURL)
+	ls.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := ls.CombinedOutput(); err != nil {
+		msg := strings.TrimSpace(string(out))
+		if i := strings.IndexByte(msg, '\n'); i > 0 {
+			msg = msg[:i]
+		}
+		r.fail("git", fmt.Sprintf("cannot read %s: %s", project.GitURL, msg),
+			"check GIT_USERNAME/GIT_TOKEN (or GITLAB_TOKEN) and ~/.git-credentials")
+		return
+	}
+	r.ok("git", "credentials accepted by "+project.GitURL)
+}
@@ -13,7 +13,7 @@ var gateAgentID string
 
 var gateCmd = &cobra.Command{
 	Use:     "gate",
-	Short:   "Manage session gates",
+	Short:   "Manage session gates and named gates",
 	GroupID: "orchestration",
 }
 
@@ -0,0 +1,260 @@
+package main
+
+// Named gates: coordination points stored as gate beads.
+//
+// A gate is closed while its bead is open. gb gate wait blocks until someone
+// runs gb gate open, which closes the bead (recording who opened it and why).
+// gb gate close re-arms a gate by creating a fresh gate bead. Unlike the
+// per-agent session gates (status/mark/clear), named gates are shared: any
+// agent or human may wait on or open them.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// gateWaitMinBackoff and gateWaitMaxBackoff bound the polling interval
+	// used alongside (or instead of) the SSE stream.
+	gateWaitMinBackoff = time.Second
+	gateWaitMaxBackoff = 30 * time.Second
+)
+
+var gateCloseCmd = &cobra.Command{
+	Use:     "close <name>",
+	Aliases: []string{"create"},
+	Short:   "Create or re-arm a named gate so waiters block",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		description, _ := cmd.Flags().GetString("description")
+		gate, created, err := armGate(cmd.Context(), args[0], description)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			printJSON(gate)
+			return nil
+		}
+		if created {
+			fmt.Printf("○ Gate %s closed (%s)\n", args[0], gate.ID)
+		} else {
+			fmt.Printf("○ Gate %s already closed (%s)\n", args[0], gate.ID)
+		}
+		return nil
+	},
+}
+
+var gateOpenCmd = &cobra.Command{
+	Use:   "open <name>",
+	Short: "Open a named gate, releasing everyone waiting on it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		reason, _ := cmd.Flags().GetString("reason")
+
+		gate, err := findArmedGate(cmd.Context(), name)
+		if err != nil {
+			return err
+		}
+		if gate == nil {
+			fmt.Printf("● Gate %s is already open\n", name)
+			return nil
+		}
+		if err := daemon.CloseBead(cmd.Context(), gate.ID, map[string]string{
+			"opened_by":   actor,
+			"opened_at":   time.Now().UTC().Format(time.RFC3339),
+			"open_reason": reason,
+		}); err != nil {
+			return fmt.Errorf("opening gate %s: %w", name, err)
+		}
+		fmt.Printf("● Gate %s opened (%s)\n", name, gate.ID)
+		return nil
+	},
+}
+
+var gateWaitCmd = &cobra.Command{
+	Use:   "wait <name>",
+	Short: "Block until a named gate is opened",
+	Long: `Blocks until the named gate is opened with 'gb gate open'. If the gate
+does not exist yet it is created closed, so a waiter can arm its own gate.
+Returns immediately when the gate is already open.
+
+Wakes on the daemon's SSE stream, with exponential-backoff polling (1s up
+to 30s) as a fallback. Exits non-zero on timeout or interrupt.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer stop()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		gate, err := findArmedGate(ctx, name)
+		if err != nil {
+			return err
+		}
+		if gate == nil {
+			latest, err := latestGate(ctx, name)
+			if err != nil {
+				return err
+			}
+			if latest != nil {
+				return printGateOpened(name, latest)
+			}
+			if gate, _, err = armGate(ctx, name, ""); err != nil {
+				return err
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "Waiting on gate %s (%s)...\n", name, gate.ID)
+		opened, err := waitGateOpen(ctx, gate.ID)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("timed out waiting for gate %s", name)
+		}
+		if err != nil {
+			return err
+		}
+		return printGateOpened(name, opened)
+	},
+}
+
+func init() {
+	gateCloseCmd.Flags().String("description", "", "what the gate is waiting for")
+	gateOpenCmd.Flags().String("reason", "", "why the gate was opened")
+	gateWaitCmd.Flags().Duration("timeout", time.Hour, "maximum time to wait (0 = no limit)")
+
+	gateCmd.AddCommand(gateCloseCmd)
+	gateCmd.AddCommand(gateOpenCmd)
+	gateCmd.AddCommand(gateWaitCmd)
+}
+
+// armGate returns the named gate's armed bead, creating one if none exists.
+func armGate(ctx context.Context, name, description string) (*beadsapi.BeadDetail, bool, error) {
+	if gate, err := findArmedGate(ctx, name); err != nil || gate != nil {
+		return gate, false, err
+	}
+	fields, _ := json.Marshal(map[string]string{"gate_name": name})
+	labels := []string{"gate:" + name}
+	if project := defaultGBProject(); project != "" {
+		labels = append(labels, "project:"+project)
+	}
+	id, err := daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title:       "gate: " + name,
+		Type:        "gate",
+		Description: description,
+		Labels:      labels,
+		CreatedBy:   actor,
+		Fields:      fields,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("creating gate %s: %w", name, err)
+	}
+	gate, err := daemon.GetBead(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	return gate, true, nil
+}
+
+// findArmedGate returns the named gate's open (blocking) bead, or nil.
+func findArmedGate(ctx context.Context, name string) (*beadsapi.BeadDetail, error) {
+	return queryGate(ctx, name, []string{"open", "in_progress", "blocked", "deferred"})
+}
+
+// latestGate returns the most recently opened bead of the named gate, or nil
+// if the gate has never existed.
+func latestGate(ctx context.Context, name string) (*beadsapi.BeadDetail, error) {
+	return queryGate(ctx, name, []string{"closed"})
+}
+
+func queryGate(ctx context.Context, name string, statuses []string) (*beadsapi.BeadDetail, error) {
+	result, err := daemon.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{
+		Types:    []string{"gate"},
+		Statuses: statuses,
+		Labels:   []string{"gate:" + name},
+		Sort:     "-created_at",
+		Limit:    1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("looking up gate %s: %w", name, err)
+	}
+	if len(result.Beads) == 0 {
+		return nil, nil
+	}
+	return result.Beads[0], nil
+}
+
+// waitGateOpen blocks until the gate bead is closed. SSE close events wake
+// it immediately; polling with exponential backoff covers a missing or
+// dropped stream.
+func waitGateOpen(ctx context.Context, gateID string) (*beadsapi.BeadDetail, error) {
+	events, err := daemon.EventStream(ctx, "beads.bead.closed")
+	if err != nil {
+		events = nil // poll only
+	}
+
+	backoff := gateWaitMinBackoff
+	for {
+		bead, err := daemon.GetBead(ctx, gateID)
+		if err == nil && bead.Status == "closed" {
+			return bead, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+	wake:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case evt, ok := <-events:
+				if !ok {
+					events = nil
+					continue
+				}
+				var data struct {
+					Bead struct {
+						ID string `json:"id"`
+					} `json:"bead"`
+				}
+				if json.Unmarshal(evt.Data, &data) == nil && data.Bead.ID == gateID {
+					break wake
+				}
+			case <-time.After(backoff):
+				backoff = min(backoff*2, gateWaitMaxBackoff)
+				break wake
+			}
+		}
+	}
+}
+
+func printGateOpened(name string, gate *beadsapi.BeadDetail) error {
+	if jsonOutput {
+		printJSON(gate)
+		return nil
+	}
+	msg := fmt.Sprintf("● Gate %s is open", name)
+	if by := gate.Fields["opened_by"]; by != "" {
+		msg += " (opened by " + by + ")"
+	}
+	if reason := gate.Fields["open_reason"]; reason != "" {
+		msg += ": " + reason
+	}
+	fmt.Println(msg)
+	return nil
+}
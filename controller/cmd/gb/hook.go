@@ -12,7 +12,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -68,17 +67,6 @@ var hookPrimeCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		agentID := resolvePrimeAgentFromEnv(actor)
 		outputPrimeForHook(os.Stdout, agentID)
-		// Show this agent's assignment bead (BOAT_AGENT_BEAD_ID set by controller).
-		beadID := os.Getenv("BOAT_AGENT_BEAD_ID")
-		if beadID == "" {
-			beadID, _ = resolveAgentID("")
-		}
-		if beadID != "" {
-			out, err := exec.CommandContext(cmd.Context(), "kd", "show", beadID).Output()
-			if err == nil && len(out) > 0 {
-				fmt.Printf("<system-reminder>\n## Assignment\n\n%s</system-reminder>\n", out)
-			}
-		}
 		// Warn if agent has no claimed work and no open decision.
 		outputClaimReminder(cmd.Context(), actor)
 		return nil
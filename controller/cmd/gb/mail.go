@@ -41,7 +41,7 @@ var mailSendCmd = &cobra.Command{
 
 		sender := resolveMailActor()
 
-		id, err := daemon.CreateBead(cmd.Context(), beadsapi.CreateBeadRequest{
+		id, err := createMailQueued(cmd.Context(), beadsapi.CreateBeadRequest{
 			Title:       subject,
 			Type:        "mail",
 			Kind:        "data",
@@ -57,6 +57,8 @@ var mailSendCmd = &cobra.Command{
 
 		if jsonOutput {
 			printJSON(map[string]string{"id": id})
+		} else if id == "" {
+			fmt.Printf("Queued: %s → %s (delivered when the daemon is reachable)\n", sender, recipient)
 		} else {
 			fmt.Printf("Sent: %s → %s (id: %s)\n", sender, recipient, id)
 		}
@@ -40,6 +40,19 @@ func defaultActor() string {
 	return "unknown"
 }
 
+// actorEmail returns the email recorded as the actor's identity when
+// resolving decisions: KD_ACTOR_EMAIL, else git's user.email, else "".
+func actorEmail() string {
+	if s := os.Getenv("KD_ACTOR_EMAIL"); s != "" {
+		return s
+	}
+	out, err := exec.Command("git", "config", "user.email").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
 func defaultHTTPURL() string {
 	if s := os.Getenv("BEADS_HTTP_URL"); s != "" {
 		return s
@@ -66,6 +79,9 @@ Use kd for bead CRUD (create, show, list, close); use gb for agent orchestration
 			return fmt.Errorf("failed to connect to beads daemon: %w", err)
 		}
 		daemon = c
+		if cmd != queueCmd && cmd.Parent() != queueCmd {
+			flushQueueQuietly(cmd.Context())
+		}
 		return nil
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
@@ -79,6 +95,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&httpURL, "http-url", defaultHTTPURL(), "kbeads HTTP server URL")
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "output as JSON")
 	rootCmd.PersistentFlags().StringVar(&actor, "actor", defaultActor(), "actor name for operations")
+	rootCmd.PersistentFlags().BoolVar(&offlineQueue, "offline-queue", defaultOfflineQueue(), "queue writes locally while the daemon is unreachable (env GB_OFFLINE_QUEUE)")
 
 	rootCmd.AddGroup(
 		&cobra.Group{ID: "agent", Title: "Agent Lifecycle:"},
@@ -90,6 +107,8 @@ func init() {
 
 	// Agent Lifecycle
 	rootCmd.AddCommand(agentCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(watchCmd)
 
 	// Orchestration
 	rootCmd.AddCommand(gateCmd)
@@ -97,9 +116,12 @@ func init() {
 	rootCmd.AddCommand(busCmd)
 	rootCmd.AddCommand(hookCmd)
 	rootCmd.AddCommand(mailCmd)
+	rootCmd.AddCommand(delegateCmd)
+	rootCmd.AddCommand(spendCmd)
 	rootCmd.AddCommand(inboxCmd)
 	rootCmd.AddCommand(newsCmd)
 	rootCmd.AddCommand(adviceCmd)
+	rootCmd.AddCommand(projectCmd)
 
 	// Session Control
 	rootCmd.AddCommand(setupCmd)
@@ -108,6 +130,7 @@ func init() {
 	rootCmd.AddCommand(primeCmd)
 	rootCmd.AddCommand(stopCmd)
 	rootCmd.AddCommand(workspaceCmd)
+	rootCmd.AddCommand(queueCmd)
 }
 
 func main() {
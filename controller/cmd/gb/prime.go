@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -18,21 +20,30 @@ var primeCmd = &cobra.Command{
 	Short: "Output AI-optimized workflow context",
 	Long: `Output essential workflow context in AI-optimized markdown format.
 
-Outputs 5 sections:
+Outputs these sections:
 1. Workflow context — session close protocol, core rules, essential commands
-2. Advice — scoped advice beads matching agent subscriptions
-3. Jack awareness — active/expired infrastructure jacks
-4. Agent roster — live agents with tasks, idle times, crash state
-5. Auto-assign — assigns highest-priority ready task if agent is idle
+2. Agent and assignment — this agent's bead and its assigned tasks
+3. Project — repository URL, default branch, and repos
+4. Advice — scoped advice beads matching agent subscriptions, ranked by
+   effectiveness feedback (gb advice feedback)
+5. Jack awareness — active/expired infrastructure jacks
+6. Agent roster — live agents with tasks, idle times, crash state
+7. Auto-assign — assigns highest-priority ready task if agent is idle
+8. Hooks — Claude Code hooks installed in the workspace
 
 Agent identity is resolved from KD_ACTOR or KD_AGENT_ID env vars,
-or the --for flag.
+or the --for flag; the agent bead from BOAT_AGENT_BEAD_ID or KD_AGENT_ID.
+
+With --json, the agent, tasks, project, advice, and hooks are emitted as
+structured fields alongside the rendered markdown.
 
 Examples:
   gb prime
   gb prime --for beads/crew/test-agent
   gb prime --no-advice
-  gb prime --json`,
+  gb prime --max-advice 10
+  gb prime --json
+  gb prime --output /tmp/prime.md`,
 	GroupID: "session",
 	RunE:   runPrime,
 }
@@ -40,38 +51,44 @@ Examples:
 var (
 	primeForAgent string
 	primeNoAdvice bool
+	primeOutput   string
 )
 
 func init() {
 	primeCmd.Flags().StringVar(&primeForAgent, "for", "", "agent ID to inject matching advice for")
 	primeCmd.Flags().BoolVar(&primeNoAdvice, "no-advice", false, "suppress advice output")
+	primeCmd.Flags().IntVar(&primeMaxAdvice, "max-advice", primeMaxAdvice, "include only the N most effective advice items, 0 for all (env: GB_PRIME_MAX_ADVICE)")
+	primeCmd.Flags().StringVarP(&primeOutput, "output", "o", "", "write the context document to a file instead of stdout")
 }
 
 func runPrime(cmd *cobra.Command, args []string) error {
-	w := os.Stdout
-
 	agentID := resolvePrimeAgentIdentity(cmd)
 
-	// 1. Workflow context.
-	outputWorkflowContext(w)
+	var buf bytes.Buffer
+	doc := renderPrime(&buf, agentID, !primeNoAdvice)
 
-	// 2. Advice.
-	if !primeNoAdvice && agentID != "" {
-		outputAdvice(w, agentID)
+	out := buf.Bytes()
+	if jsonOutput {
+		if !primeNoAdvice && agentID != "" {
+			doc.Advice = collectPrimeAdvice(cmd.Context(), agentID)
+		}
+		doc.Markdown = buf.String()
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshalling prime context: %w", err)
+		}
+		out = append(data, '\n')
 	}
 
-	// 3. Jack awareness.
-	outputJackSection(w)
-
-	// 4. Agent roster.
-	outputRosterSection(w, agentID)
-
-	// 5. Auto-assign (if agent has no in_progress bead).
-	if agentID != "" {
-		outputAutoAssign(w, agentID)
+	if primeOutput != "" {
+		if err := os.WriteFile(primeOutput, out, 0644); err != nil {
+			return fmt.Errorf("writing prime context: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote prime context to %s\n", primeOutput)
+		return nil
 	}
-
-	return nil
+	_, err := os.Stdout.Write(out)
+	return err
 }
 
 // resolvePrimeAgentIdentity resolves the agent identity for prime output.
@@ -186,6 +203,8 @@ gb yield  # blocks until human responds
 
 **Artifact types:** ` + "`report`" + ` (work summary), ` + "`plan`" + ` (implementation plan), ` + "`checklist`" + ` (verification steps), ` + "`diff-summary`" + ` (code changes), ` + "`epic`" + ` (feature breakdown), ` + "`bug`" + ` (bug report)
 
+**Attach the context** the human needs to answer without digging: ` + "`--attach diff:<file>`" + `, ` + "`--attach log:<file>`" + `, ` + "`--attach link:<url>`" + ` (repeatable; ` + "`-`" + ` reads stdin, e.g. ` + "`git diff | gb decision create ... --attach diff:-`" + `).
+
 If the chosen option requires an artifact, ` + "`gb yield`" + ` will tell you — submit it with:
 ` + "`gb decision report <decision-id> --content '...'`" + `
 
@@ -1,49 +1,49 @@
 package main
 
 // prime_advice.go contains outputAdvice which renders matched advice for an agent.
-// Subscription matching logic lives in internal/advice/.
+// Subscription matching and effectiveness scoring live in internal/advice/.
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
 
 	"gasboat/controller/internal/advice"
 )
 
+// primeMaxAdvice caps how many advice items prime includes, keeping the
+// most effective; 0 includes all. Defaults from GB_PRIME_MAX_ADVICE.
+var primeMaxAdvice = defaultPrimeMaxAdvice()
+
+func defaultPrimeMaxAdvice() int {
+	n, _ := strconv.Atoi(os.Getenv("GB_PRIME_MAX_ADVICE"))
+	return max(n, 0)
+}
+
+// selectPrimeAdvice ranks matched advice by effectiveness score and applies
+// the primeMaxAdvice cap.
+func selectPrimeAdvice(matched []advice.MatchedAdvice) []advice.MatchedAdvice {
+	advice.RankByEffectiveness(matched)
+	if primeMaxAdvice > 0 && len(matched) > primeMaxAdvice {
+		matched = matched[:primeMaxAdvice]
+	}
+	return matched
+}
+
 // outputAdvice fetches open advice beads, filters by agent subscriptions,
-// groups by scope, and writes markdown to w.
+// keeps the most effective, groups by scope, and writes markdown to w.
+// Within a scope, higher-scoring advice comes first.
 func outputAdvice(w io.Writer, agentID string) {
 	matched, _, err := advice.ListAdviceForAgent(context.Background(), daemon, agentID)
 	if err != nil || len(matched) == 0 {
 		return
 	}
-
-	if jsonOutput {
-		type jsonItem struct {
-			ID            string   `json:"id"`
-			Title         string   `json:"title"`
-			Description   string   `json:"description,omitempty"`
-			Labels        []string `json:"labels"`
-			MatchedLabels []string `json:"matched_labels"`
-		}
-		items := make([]jsonItem, len(matched))
-		for i, m := range matched {
-			items[i] = jsonItem{
-				ID:            m.Bead.ID,
-				Title:         m.Bead.Title,
-				Description:   m.Bead.Description,
-				Labels:        m.Bead.Labels,
-				MatchedLabels: m.MatchedLabels,
-			}
-		}
-		data, _ := json.MarshalIndent(items, "", "  ")
-		fmt.Fprintln(w, string(data))
-		return
-	}
+	total := len(matched)
+	matched = selectPrimeAdvice(matched)
 
 	type scopeGroup struct {
 		Scope  string
@@ -75,10 +75,14 @@ func outputAdvice(w io.Writer, agentID string) {
 		return advice.GroupSortKey(groups[i].Scope, groups[i].Target) < advice.GroupSortKey(groups[j].Scope, groups[j].Target)
 	})
 
-	fmt.Fprintf(w, "\n## Advice (%d items)\n\n", len(matched))
+	if len(matched) < total {
+		fmt.Fprintf(w, "\n## Advice (%d most effective of %d)\n\n", len(matched), total)
+	} else {
+		fmt.Fprintf(w, "\n## Advice (%d items)\n\n", len(matched))
+	}
 	for _, g := range groups {
 		for _, item := range g.Items {
-			fmt.Fprintf(w, "**[%s]** %s\n", g.Header, item.Bead.Title)
+			fmt.Fprintf(w, "**[%s]** %s (%s)\n", g.Header, item.Bead.Title, item.Bead.ID)
 			desc := item.Bead.Description
 			if desc != "" && desc != item.Bead.Title {
 				for _, line := range strings.Split(desc, "\n") {
@@ -88,4 +92,5 @@ func outputAdvice(w io.Writer, agentID string) {
 			fmt.Fprintln(w)
 		}
 	}
+	fmt.Fprintln(w, "Rate advice with `gb advice feedback <id> --up` or `--down`; run it with `--applied` when you act on one.")
 }
@@ -0,0 +1,274 @@
+package main
+
+// prime_context.go assembles the agent-specific parts of gb prime: the agent
+// bead, its assigned work, project metadata, and installed hooks. These used
+// to be stitched together by prime.sh with kd show.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gasboat/controller/internal/advice"
+	"gasboat/controller/internal/beadsapi"
+)
+
+// primeDocument is the gb prime --json output.
+type primeDocument struct {
+	Agent    *primeAgent         `json:"agent,omitempty"`
+	Tasks    []primeTask         `json:"tasks,omitempty"`
+	Project  *primeProject       `json:"project,omitempty"`
+	Advice   []primeAdvice       `json:"advice,omitempty"`
+	Hooks    map[string][]string `json:"hooks,omitempty"`
+	Markdown string              `json:"markdown"`
+}
+
+type primeAgent struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Role        string `json:"role,omitempty"`
+	Project     string `json:"project,omitempty"`
+	State       string `json:"state,omitempty"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+type primeTask struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Status      string `json:"status"`
+	Priority    int    `json:"priority"`
+	Description string `json:"description,omitempty"`
+}
+
+type primeProject struct {
+	Name          string               `json:"name"`
+	GitURL        string               `json:"git_url,omitempty"`
+	DefaultBranch string               `json:"default_branch,omitempty"`
+	Repos         []beadsapi.RepoEntry `json:"repos,omitempty"`
+}
+
+type primeAdvice struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description,omitempty"`
+	Labels        []string `json:"labels"`
+	MatchedLabels []string `json:"matched_labels"`
+	Score         float64  `json:"score"`
+}
+
+// collectPrimeContext gathers the agent bead, assigned tasks, project, and
+// hooks. Missing pieces are left empty; prime never fails on them.
+func collectPrimeContext(ctx context.Context, beadID, agentName, workspace string) *primeDocument {
+	doc := &primeDocument{}
+
+	project := defaultGBProject()
+	if beadID != "" {
+		if bead, err := daemon.GetBead(ctx, beadID); err == nil {
+			doc.Agent = &primeAgent{
+				ID:          bead.ID,
+				Name:        bead.Fields["agent"],
+				Role:        bead.Fields["role"],
+				Project:     bead.Fields["project"],
+				State:       bead.Fields["agent_state"],
+				Title:       bead.Title,
+				Description: bead.Description,
+			}
+			if doc.Agent.Project != "" {
+				project = doc.Agent.Project
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	addTask := func(b *beadsapi.BeadDetail) {
+		if b == nil || seen[b.ID] || b.Status == "closed" {
+			return
+		}
+		seen[b.ID] = true
+		doc.Tasks = append(doc.Tasks, primeTask{
+			ID:          b.ID,
+			Title:       b.Title,
+			Status:      b.Status,
+			Priority:    b.Priority,
+			Description: b.Description,
+		})
+	}
+	if agentName != "" {
+		if task, err := daemon.ListAssignedTask(ctx, agentName); err == nil {
+			addTask(task)
+		}
+	}
+	if beadID != "" {
+		if deps, err := daemon.GetDependencies(ctx, beadID); err == nil {
+			for _, d := range deps {
+				if d.Type != "assigned" {
+					continue
+				}
+				if task, err := daemon.GetBead(ctx, d.DependsOnID); err == nil {
+					addTask(task)
+				}
+			}
+		}
+	}
+
+	if project != "" {
+		if projects, err := daemon.ListProjectBeads(ctx); err == nil {
+			if info, ok := projects[project]; ok {
+				doc.Project = &primeProject{
+					Name:          project,
+					GitURL:        info.GitURL,
+					DefaultBranch: info.DefaultBranch,
+					Repos:         info.Repos,
+				}
+			}
+		}
+	}
+
+	doc.Hooks = installedHooks(workspace)
+	return doc
+}
+
+// collectPrimeAdvice returns the advice matched for agentID as JSON items,
+// most effective first.
+func collectPrimeAdvice(ctx context.Context, agentID string) []primeAdvice {
+	matched, _, err := advice.ListAdviceForAgent(ctx, daemon, agentID)
+	if err != nil {
+		return nil
+	}
+	matched = selectPrimeAdvice(matched)
+	items := make([]primeAdvice, len(matched))
+	for i, m := range matched {
+		items[i] = primeAdvice{
+			ID:            m.Bead.ID,
+			Title:         m.Bead.Title,
+			Description:   m.Bead.Description,
+			Labels:        m.Bead.Labels,
+			MatchedLabels: m.MatchedLabels,
+			Score:         m.Effectiveness.Score,
+		}
+	}
+	return items
+}
+
+// installedHooks reads the workspace's .claude/settings.json and returns the
+// configured hook commands by event.
+func installedHooks(workspace string) map[string][]string {
+	if workspace == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(workspace, ".claude", "settings.json"))
+	if err != nil {
+		return nil
+	}
+	var settings struct {
+		Hooks map[string][]struct {
+			Hooks []struct {
+				Command string `json:"command"`
+			} `json:"hooks"`
+		} `json:"hooks"`
+	}
+	if json.Unmarshal(data, &settings) != nil || len(settings.Hooks) == 0 {
+		return nil
+	}
+	hooks := make(map[string][]string, len(settings.Hooks))
+	for event, entries := range settings.Hooks {
+		for _, e := range entries {
+			for _, h := range e.Hooks {
+				if h.Command != "" {
+					hooks[event] = append(hooks[event], h.Command)
+				}
+			}
+		}
+	}
+	return hooks
+}
+
+// outputAgentSection writes the agent identity and its assigned work.
+func outputAgentSection(w io.Writer, doc *primeDocument) {
+	if a := doc.Agent; a != nil {
+		fmt.Fprintf(w, "\n## Agent\n\n")
+		fmt.Fprintf(w, "- **Bead**: %s\n", a.ID)
+		if a.Name != "" {
+			fmt.Fprintf(w, "- **Name**: %s\n", a.Name)
+		}
+		if a.Role != "" {
+			fmt.Fprintf(w, "- **Role**: %s\n", a.Role)
+		}
+		if a.Project != "" {
+			fmt.Fprintf(w, "- **Project**: %s\n", a.Project)
+		}
+		if a.Description != "" {
+			fmt.Fprintf(w, "\n%s\n", strings.TrimSpace(a.Description))
+		}
+	}
+
+	if len(doc.Tasks) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n## Assignment\n\n")
+	for _, t := range doc.Tasks {
+		fmt.Fprintf(w, "### %s [P%d, %s]: %s\n", t.ID, t.Priority, t.Status, t.Title)
+		if t.Description != "" {
+			fmt.Fprintf(w, "\n%s\n", strings.TrimSpace(t.Description))
+		}
+		fmt.Fprintln(w)
+	}
+	for _, t := range doc.Tasks {
+		if t.Status == "open" {
+			fmt.Fprintf(w, "Run `kd claim %s` before starting work on it.\n", t.ID)
+		}
+	}
+}
+
+// outputProjectSection writes the project's repository metadata.
+func outputProjectSection(w io.Writer, doc *primeDocument) {
+	p := doc.Project
+	if p == nil {
+		return
+	}
+	fmt.Fprintf(w, "\n## Project: %s\n\n", p.Name)
+	if p.GitURL != "" {
+		fmt.Fprintf(w, "- **Repository**: %s\n", p.GitURL)
+	}
+	if p.DefaultBranch != "" {
+		fmt.Fprintf(w, "- **Default branch**: %s\n", p.DefaultBranch)
+	}
+	for _, r := range p.Repos {
+		name := r.Name
+		if name == "" {
+			name = r.URL
+		}
+		role := r.Role
+		if role == "" {
+			role = "reference"
+		}
+		fmt.Fprintf(w, "- **Repo** (%s): %s", role, name)
+		if r.Name != "" {
+			fmt.Fprintf(w, " — %s", r.URL)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// outputHooksSection lists the installed Claude Code hooks.
+func outputHooksSection(w io.Writer, doc *primeDocument) {
+	if len(doc.Hooks) == 0 {
+		return
+	}
+	events := make([]string, 0, len(doc.Hooks))
+	for event := range doc.Hooks {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+
+	fmt.Fprintf(w, "\n## Hooks\n\n")
+	for _, event := range events {
+		fmt.Fprintf(w, "- **%s**: `%s`\n", event, strings.Join(doc.Hooks[event], "`, `"))
+	}
+}
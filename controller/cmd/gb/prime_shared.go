@@ -1,19 +1,25 @@
 package main
 
-// prime_shared.go contains the outputPrimeForHook function shared by
-// bus_emit.go (SessionStart injection) and hook.go (hook prime command).
+// prime_shared.go contains renderPrime and outputPrimeForHook, shared by
+// prime.go (gb prime), bus_emit.go (SessionStart injection), and hook.go
+// (hook prime command).
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
 )
 
-// outputPrimeForHook generates prime output wrapped in a system-reminder tag.
-// This is called by bus emit on SessionStart and by hook prime.
-func outputPrimeForHook(w io.Writer, agentID string) {
-	fmt.Fprintln(w, "<system-reminder>")
+// renderPrime writes the full prime context document as markdown to w and
+// returns the structured agent context it was built from.
+func renderPrime(w io.Writer, agentID string, withAdvice bool) *primeDocument {
+	doc := collectPrimeContext(context.Background(), primeAgentBeadID(), agentID, primeWorkspace())
+
 	outputWorkflowContext(w)
-	if agentID != "" {
+	outputAgentSection(w, doc)
+	outputProjectSection(w, doc)
+	if withAdvice && agentID != "" {
 		outputAdvice(w, agentID)
 	}
 	outputJackSection(w)
@@ -21,5 +27,31 @@ func outputPrimeForHook(w io.Writer, agentID string) {
 	if agentID != "" {
 		outputAutoAssign(w, agentID)
 	}
+	outputHooksSection(w, doc)
+	return doc
+}
+
+// outputPrimeForHook generates prime output wrapped in a system-reminder tag.
+// This is called by bus emit on SessionStart and by hook prime.
+func outputPrimeForHook(w io.Writer, agentID string) {
+	fmt.Fprintln(w, "<system-reminder>")
+	renderPrime(w, agentID, true)
 	fmt.Fprintln(w, "</system-reminder>")
 }
+
+// primeAgentBeadID returns this agent's own bead ID, set by the controller.
+func primeAgentBeadID() string {
+	if v := os.Getenv("BOAT_AGENT_BEAD_ID"); v != "" {
+		return v
+	}
+	return os.Getenv("KD_AGENT_ID")
+}
+
+// primeWorkspace returns the workspace whose installed hooks prime reports.
+func primeWorkspace() string {
+	if v := os.Getenv("KD_WORKSPACE"); v != "" {
+		return v
+	}
+	wd, _ := os.Getwd()
+	return wd
+}
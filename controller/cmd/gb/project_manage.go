@@ -0,0 +1,625 @@
+package main
+
+// gb project list/show/create/update — project bead management (onboard is
+// in project_onboard.go).
+//
+// Project beads carry the per-project controller config (git_url, image,
+// storage class, secrets, repos). Editing them with kd means hand-writing
+// the secrets and repos JSON; these commands build the fields from flags
+// and validate the result before anything is written.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var projectCmd = &cobra.Command{
+	Use:     "project",
+	Short:   "Manage project beads",
+	GroupID: "orchestration",
+}
+
+var projectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered projects",
+	Args:  cobra.NoArgs,
+	RunE:  runProjectList,
+}
+
+var projectShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a project's configuration",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProjectShow,
+}
+
+var projectCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Register a new project",
+	Long: `Create a project bead. Fields are validated before the bead is written.
+
+Secrets map a K8s Secret key to an env var as ENV=secret:key. Repos are
+given as a URL followed by optional branch=, role= (primary or reference),
+and name= settings, comma separated.
+
+Examples:
+  gb project create gasboat --prefix kd --git-url https://github.com/org/gasboat.git
+  gb project create api --git-url git@gitlab.com:org/api.git \
+    --secret GITLAB_TOKEN=gitlab-creds:token \
+    --repo https://github.com/org/docs,role=reference,name=docs`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProjectCreate,
+}
+
+var projectUpdateCmd = &cobra.Command{
+	Use:   "update <name>",
+	Short: "Update a project's configuration",
+	Long: `Update fields on a project bead. Only the flags given are changed;
+--secret and --repo add or replace entries (matched by env var and URL).
+The merged result is validated before it is written.
+
+Examples:
+  gb project update gasboat --image ghcr.io/org/agent:v2
+  gb project update gasboat --secret NPM_TOKEN=npm-creds:token
+  gb project update gasboat --remove-repo docs --clear storage_class
+  gb project update gasboat --rtk=false --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProjectUpdate,
+}
+
+// projectClearable lists the fields --clear accepts.
+var projectClearable = []string{"prefix", "git_url", "default_branch", "image", "storage_class", "service_account", "cluster", "schedule", "arch", "termination_grace", "prestop", "host_aliases", "dns_policy", "dns_config", "http_proxy", "https_proxy", "no_proxy", "budget_soft_usd", "budget_hard_usd", "secrets", "repos"}
+
+func init() {
+	for _, c := range []*cobra.Command{projectCreateCmd, projectUpdateCmd, projectOnboardCmd} {
+		c.Flags().String("prefix", "", "beads ID prefix (e.g. kd)")
+		c.Flags().String("git-url", "", "primary repository URL")
+		c.Flags().String("default-branch", "", "default branch (e.g. main)")
+		c.Flags().String("image", "", "agent image override")
+		c.Flags().String("storage-class", "", "workspace PVC storage class override")
+		c.Flags().String("service-account", "", "agent ServiceAccount override")
+		c.Flags().String("cluster", "", "run the project's agents on this cluster")
+		c.Flags().String("schedule", "", `agent active hours, e.g. "Mon-Fri 08:00-19:00 America/New_York"; pods hibernate outside them`)
+		c.Flags().String("arch", "", "CPU architecture for the project's agents (amd64, arm64, any)")
+		c.Flags().String("termination-grace", "", `how long the project's agent pods get to shut down, e.g. "5m"`)
+		c.Flags().String("prestop", "", `preStop hook template for the project's agent pods, e.g. "gb yield --checkpoint --agent-id {{.BeadID}}"; "none" disables it`)
+		c.Flags().StringArray("host-alias", nil, "/etc/hosts entry IP=host[,host] for the project's agent pods (repeatable)")
+		c.Flags().String("dns-policy", "", "DNS policy for the project's agent pods (ClusterFirst, Default, None, ...)")
+		c.Flags().String("dns-config", "", `DNS config JSON for the project's agent pods, e.g. '{"nameservers":["10.0.0.2"],"searches":["corp.example"]}'`)
+		c.Flags().String("http-proxy", "", "egress proxy URL for the project's agent pods, e.g. http://proxy.corp:3128")
+		c.Flags().String("https-proxy", "", "egress proxy URL for HTTPS (default: --http-proxy)")
+		c.Flags().String("no-proxy", "", "comma-separated hosts, domains and CIDRs agents reach without the proxy")
+		c.Flags().String("budget-soft", "", "daily API spend in USD that notifies the bridge")
+		c.Flags().String("budget-hard", "", "daily API spend in USD that pauses new agents until the next UTC day")
+		c.Flags().Bool("rtk", false, "enable RTK token optimization")
+		c.Flags().StringArray("secret", nil, "secret env mapping ENV=secret:key (repeatable)")
+		c.Flags().StringArray("repo", nil, "extra repo URL[,branch=B][,role=R][,name=N] (repeatable)")
+		c.Flags().Bool("dry-run", false, "validate and print the fields without writing")
+	}
+	projectCreateCmd.Flags().String("description", "", "project description")
+	projectOnboardCmd.Flags().String("description", "", "project description (new projects)")
+	projectOnboardCmd.Flags().Bool("no-input", false, "don't ask for settings not given as flags")
+	projectOnboardCmd.Flags().Bool("no-wait", false, "request onboarding without waiting for the report")
+	projectOnboardCmd.Flags().Duration("timeout", 15*time.Minute, "how long to wait for the report")
+	projectUpdateCmd.Flags().StringArray("remove-secret", nil, "remove the secret mapping for ENV (repeatable)")
+	projectUpdateCmd.Flags().StringArray("remove-repo", nil, "remove a repo by URL or name (repeatable)")
+	projectUpdateCmd.Flags().StringArray("clear", nil, "clear a field: "+strings.Join(projectClearable, ", ")+" (repeatable)")
+
+	projectCmd.AddCommand(projectListCmd)
+	projectCmd.AddCommand(projectShowCmd)
+	projectCmd.AddCommand(projectCreateCmd)
+	projectCmd.AddCommand(projectUpdateCmd)
+	projectCmd.AddCommand(projectOnboardCmd)
+}
+
+// projectView is the JSON form of a project.
+type projectView struct {
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	Prefix           string                 `json:"prefix,omitempty"`
+	GitURL           string                 `json:"git_url,omitempty"`
+	DefaultBranch    string                 `json:"default_branch,omitempty"`
+	Image            string                 `json:"image,omitempty"`
+	StorageClass     string                 `json:"storage_class,omitempty"`
+	ServiceAccount   string                 `json:"service_account,omitempty"`
+	Cluster          string                 `json:"cluster,omitempty"`
+	Schedule         string                 `json:"schedule,omitempty"`
+	Arch             string                 `json:"arch,omitempty"`
+	TerminationGrace string                 `json:"termination_grace,omitempty"`
+	PreStop          string                 `json:"prestop,omitempty"`
+	HostAliases      []corev1.HostAlias     `json:"host_aliases,omitempty"`
+	DNSPolicy        string                 `json:"dns_policy,omitempty"`
+	DNSConfig        *corev1.PodDNSConfig   `json:"dns_config,omitempty"`
+	HTTPProxy        string                 `json:"http_proxy,omitempty"`
+	HTTPSProxy       string                 `json:"https_proxy,omitempty"`
+	NoProxy          string                 `json:"no_proxy,omitempty"`
+	RTKEnabled       bool                   `json:"rtk_enabled,omitempty"`
+	Secrets          []beadsapi.SecretEntry `json:"secrets,omitempty"`
+	Repos            []beadsapi.RepoEntry   `json:"repos,omitempty"`
+	Onboarding       string                 `json:"onboarding,omitempty"`
+	BudgetSoft       string                 `json:"budget_soft_usd,omitempty"`
+	BudgetHard       string                 `json:"budget_hard_usd,omitempty"`
+	Budget           *beadsapi.BudgetStatus `json:"budget_status,omitempty"`
+	Problems         []string               `json:"problems,omitempty"`
+
+	OnboardingReport *beadsapi.OnboardingReport `json:"onboarding_report,omitempty"`
+}
+
+func newProjectView(p beadsapi.ProjectInfo) projectView {
+	v := projectView{
+		ID:               p.ID,
+		Name:             p.Name,
+		Prefix:           p.Prefix,
+		GitURL:           p.GitURL,
+		DefaultBranch:    p.DefaultBranch,
+		Image:            p.Image,
+		StorageClass:     p.StorageClass,
+		ServiceAccount:   p.ServiceAccount,
+		Cluster:          p.Cluster,
+		Schedule:         p.Schedule,
+		Arch:             p.Arch,
+		TerminationGrace: p.TerminationGrace,
+		PreStop:          p.PreStop,
+		HostAliases:      p.HostAliases,
+		DNSPolicy:        p.DNSPolicy,
+		DNSConfig:        p.DNSConfig,
+		HTTPProxy:        p.HTTPProxy,
+		HTTPSProxy:       p.HTTPSProxy,
+		NoProxy:          p.NoProxy,
+		RTKEnabled:       p.RTKEnabled,
+		Secrets:          p.Secrets,
+		Repos:            p.Repos,
+		Onboarding:       p.Onboarding,
+		BudgetSoft:       p.BudgetSoft,
+		BudgetHard:       p.BudgetHard,
+	}
+	if p.Budget != nil && p.Budget.Day == beadsapi.SpendDay(time.Now()) {
+		v.Budget = p.Budget
+	}
+	if err := p.Validate(); err != nil {
+		v.Problems = strings.Split(err.Error(), "\n")
+	}
+	return v
+}
+
+func runProjectList(cmd *cobra.Command, args []string) error {
+	projects, err := daemon.ListProjectBeads(cmd.Context())
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(projects))
+	for name := range projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if jsonOutput {
+		views := make([]projectView, 0, len(names))
+		for _, name := range names {
+			views = append(views, newProjectView(projects[name]))
+		}
+		printJSON(views)
+		return nil
+	}
+	if len(names) == 0 {
+		fmt.Println("No projects registered.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tPREFIX\tGIT URL\tBRANCH\tIMAGE\tID")
+	for _, name := range names {
+		p := projects[name]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			p.Name, orDash(p.Prefix), orDash(p.GitURL), orDash(p.DefaultBranch), orDash(p.Image), p.ID)
+	}
+	return w.Flush()
+}
+
+func runProjectShow(cmd *cobra.Command, args []string) error {
+	p, err := findProject(cmd.Context(), args[0])
+	if err != nil {
+		return err
+	}
+	v := newProjectView(*p)
+	if p.Onboarding != "" {
+		bead, err := daemon.GetBead(cmd.Context(), p.ID)
+		if err != nil {
+			return err
+		}
+		v.OnboardingReport = beadsapi.OnboardingReportFromFields(bead.Fields)
+	}
+	if jsonOutput {
+		printJSON(v)
+		return nil
+	}
+
+	fmt.Printf("%s (%s)\n", v.Name, v.ID)
+	for _, kv := range [][2]string{
+		{"prefix", v.Prefix},
+		{"git_url", v.GitURL},
+		{"default_branch", v.DefaultBranch},
+		{"image", v.Image},
+		{"storage_class", v.StorageClass},
+		{"service_account", v.ServiceAccount},
+		{"cluster", v.Cluster},
+		{"schedule", v.Schedule},
+		{"arch", v.Arch},
+		{"termination_grace", v.TerminationGrace},
+		{"prestop", v.PreStop},
+		{"dns_policy", v.DNSPolicy},
+		{"http_proxy", v.HTTPProxy},
+		{"https_proxy", v.HTTPSProxy},
+		{"no_proxy", v.NoProxy},
+		{"budget_soft_usd", v.BudgetSoft},
+		{"budget_hard_usd", v.BudgetHard},
+	} {
+		fmt.Printf("  %-17s %s\n", kv[0], orDash(kv[1]))
+	}
+	fmt.Printf("  %-17s %v\n", "rtk_enabled", v.RTKEnabled)
+	if len(v.Secrets) > 0 {
+		fmt.Println("  secrets:")
+		for _, s := range v.Secrets {
+			fmt.Printf("    %s ← %s:%s\n", s.Env, s.Secret, s.Key)
+		}
+	}
+	if len(v.HostAliases) > 0 {
+		fmt.Println("  host_aliases:")
+		for _, a := range v.HostAliases {
+			fmt.Printf("    %s %s\n", a.IP, strings.Join(a.Hostnames, " "))
+		}
+	}
+	if c := v.DNSConfig; c != nil {
+		fmt.Println("  dns_config:")
+		for _, kv := range [][2]string{{"nameservers", strings.Join(c.Nameservers, " ")}, {"searches", strings.Join(c.Searches, " ")}} {
+			if kv[1] != "" {
+				fmt.Printf("    %s: %s\n", kv[0], kv[1])
+			}
+		}
+		for _, o := range c.Options {
+			if o.Value != nil {
+				fmt.Printf("    option: %s:%s\n", o.Name, *o.Value)
+			} else {
+				fmt.Printf("    option: %s\n", o.Name)
+			}
+		}
+	}
+	if len(v.Repos) > 0 {
+		fmt.Println("  repos:")
+		for _, r := range v.Repos {
+			fmt.Printf("    %s", r.URL)
+			for _, kv := range [][2]string{{"branch", r.Branch}, {"role", r.Role}, {"name", r.Name}} {
+				if kv[1] != "" {
+					fmt.Printf(" %s=%s", kv[0], kv[1])
+				}
+			}
+			fmt.Println()
+		}
+	}
+	if b := v.Budget; b != nil {
+		line := fmt.Sprintf("$%.2f, %d tokens", b.SpentUSD, b.Tokens)
+		if b.Paused {
+			line += " (hard cap reached; new agents paused)"
+		} else if b.Level != "" {
+			line += " (over " + b.Level + " cap)"
+		}
+		fmt.Printf("  %-17s %s\n", "spent_today", line)
+	}
+	if v.Onboarding != "" {
+		fmt.Print("  ")
+		printOnboardingReport(v.Onboarding, v.OnboardingReport)
+	}
+	if len(v.Problems) > 0 {
+		fmt.Println("  problems:")
+		for _, p := range v.Problems {
+			fmt.Printf("    ✗ %s\n", p)
+		}
+	}
+	return nil
+}
+
+func runProjectCreate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	name := args[0]
+
+	p := beadsapi.ProjectInfo{Name: name}
+	if err := applyProjectFlags(cmd, &p); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid project:\n%w", err)
+	}
+
+	fields := p.Fields()
+	for k, v := range fields {
+		if v == "" {
+			delete(fields, k)
+		}
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		printJSON(fields)
+		return nil
+	}
+
+	projects, err := daemon.ListProjectBeads(ctx)
+	if err != nil {
+		return err
+	}
+	if existing, ok := projects[name]; ok {
+		return fmt.Errorf("project %s already exists (%s); use gb project update", name, existing.ID)
+	}
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("encoding fields: %w", err)
+	}
+	description, _ := cmd.Flags().GetString("description")
+	id, err := daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title:       name,
+		Type:        "project",
+		Description: description,
+		CreatedBy:   actor,
+		Fields:      raw,
+	})
+	if err != nil {
+		return fmt.Errorf("creating project %s: %w", name, err)
+	}
+	p.ID = id
+
+	if jsonOutput {
+		printJSON(newProjectView(p))
+		return nil
+	}
+	fmt.Printf("Created project %s (%s)\n", name, id)
+	return nil
+}
+
+func runProjectUpdate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	p, err := findProject(ctx, args[0])
+	if err != nil {
+		return err
+	}
+
+	clearFields, _ := cmd.Flags().GetStringArray("clear")
+	for _, f := range clearFields {
+		if !slices.Contains(projectClearable, f) {
+			return fmt.Errorf("--clear %s: must be one of %s", f, strings.Join(projectClearable, ", "))
+		}
+	}
+
+	// ProjectInfoFromFields drops malformed JSON; refuse to silently
+	// overwrite it unless the caller is replacing or clearing it.
+	bead, err := daemon.GetBead(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	for _, f := range []string{"secrets", "repos"} {
+		if raw := bead.Fields[f]; raw != "" && !json.Valid([]byte(raw)) && !slices.Contains(clearFields, f) {
+			return fmt.Errorf("project %s has malformed %s JSON; pass --clear %s to discard it", p.Name, f, f)
+		}
+	}
+
+	for _, f := range clearFields {
+		switch f {
+		case "prefix":
+			p.Prefix = ""
+		case "git_url":
+			p.GitURL = ""
+		case "default_branch":
+			p.DefaultBranch = ""
+		case "image":
+			p.Image = ""
+		case "storage_class":
+			p.StorageClass = ""
+		case "service_account":
+			p.ServiceAccount = ""
+		case "cluster":
+			p.Cluster = ""
+		case "schedule":
+			p.Schedule = ""
+		case "arch":
+			p.Arch = ""
+		case "termination_grace":
+			p.TerminationGrace = ""
+		case "prestop":
+			p.PreStop = ""
+		case "host_aliases":
+			p.HostAliases = nil
+		case "dns_policy":
+			p.DNSPolicy = ""
+		case "dns_config":
+			p.DNSConfig = nil
+		case "http_proxy":
+			p.HTTPProxy = ""
+		case "https_proxy":
+			p.HTTPSProxy = ""
+		case "no_proxy":
+			p.NoProxy = ""
+		case "budget_soft_usd":
+			p.BudgetSoft = ""
+		case "budget_hard_usd":
+			p.BudgetHard = ""
+		case "secrets":
+			p.Secrets = nil
+		case "repos":
+			p.Repos = nil
+		}
+	}
+
+	removeSecrets, _ := cmd.Flags().GetStringArray("remove-secret")
+	for _, env := range removeSecrets {
+		n := len(p.Secrets)
+		p.Secrets = slices.DeleteFunc(p.Secrets, func(s beadsapi.SecretEntry) bool { return s.Env == env })
+		if len(p.Secrets) == n {
+			return fmt.Errorf("--remove-secret %s: no such secret mapping", env)
+		}
+	}
+	removeRepos, _ := cmd.Flags().GetStringArray("remove-repo")
+	for _, ref := range removeRepos {
+		n := len(p.Repos)
+		p.Repos = slices.DeleteFunc(p.Repos, func(r beadsapi.RepoEntry) bool { return r.URL == ref || r.Name == ref })
+		if len(p.Repos) == n {
+			return fmt.Errorf("--remove-repo %s: no such repo", ref)
+		}
+	}
+
+	if err := applyProjectFlags(cmd, p); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid project:\n%w", err)
+	}
+
+	fields := p.Fields()
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		printJSON(fields)
+		return nil
+	}
+	if err := daemon.UpdateBeadFields(ctx, p.ID, fields); err != nil {
+		return fmt.Errorf("updating project %s: %w", p.Name, err)
+	}
+
+	if jsonOutput {
+		printJSON(newProjectView(*p))
+		return nil
+	}
+	fmt.Printf("Updated project %s (%s)\n", p.Name, p.ID)
+	return nil
+}
+
+// findProject looks up a registered project by name.
+func findProject(ctx context.Context, name string) (*beadsapi.ProjectInfo, error) {
+	projects, err := daemon.ListProjectBeads(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := projects[name]
+	if !ok {
+		return nil, fmt.Errorf("project %s not found", name)
+	}
+	return &p, nil
+}
+
+// applyProjectFlags copies the flags the user set onto p. --secret and
+// --repo entries replace existing ones with the same env var or URL.
+func applyProjectFlags(cmd *cobra.Command, p *beadsapi.ProjectInfo) error {
+	flags := cmd.Flags()
+	for flag, dst := range map[string]*string{
+		"prefix":            &p.Prefix,
+		"git-url":           &p.GitURL,
+		"default-branch":    &p.DefaultBranch,
+		"image":             &p.Image,
+		"storage-class":     &p.StorageClass,
+		"service-account":   &p.ServiceAccount,
+		"cluster":           &p.Cluster,
+		"schedule":          &p.Schedule,
+		"arch":              &p.Arch,
+		"termination-grace": &p.TerminationGrace,
+		"prestop":           &p.PreStop,
+		"dns-policy":        &p.DNSPolicy,
+		"http-proxy":        &p.HTTPProxy,
+		"https-proxy":       &p.HTTPSProxy,
+		"no-proxy":          &p.NoProxy,
+		"budget-soft":       &p.BudgetSoft,
+		"budget-hard":       &p.BudgetHard,
+	} {
+		if flags.Changed(flag) {
+			*dst, _ = flags.GetString(flag)
+		}
+	}
+	if flags.Changed("rtk") {
+		p.RTKEnabled, _ = flags.GetBool("rtk")
+	}
+	if flags.Changed("dns-config") {
+		raw, _ := flags.GetString("dns-config")
+		var cfg corev1.PodDNSConfig
+		if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+			return fmt.Errorf("--dns-config: %w", err)
+		}
+		p.DNSConfig = &cfg
+	}
+
+	aliases, _ := flags.GetStringArray("host-alias")
+	for _, spec := range aliases {
+		a, err := parseHostAliasSpec(spec)
+		if err != nil {
+			return err
+		}
+		p.HostAliases = slices.DeleteFunc(p.HostAliases, func(e corev1.HostAlias) bool { return e.IP == a.IP })
+		p.HostAliases = append(p.HostAliases, a)
+	}
+
+	secrets, _ := flags.GetStringArray("secret")
+	for _, spec := range secrets {
+		s, err := parseSecretSpec(spec)
+		if err != nil {
+			return err
+		}
+		p.Secrets = slices.DeleteFunc(p.Secrets, func(e beadsapi.SecretEntry) bool { return e.Env == s.Env })
+		p.Secrets = append(p.Secrets, s)
+	}
+	repos, _ := flags.GetStringArray("repo")
+	for _, spec := range repos {
+		r, err := parseRepoSpec(spec)
+		if err != nil {
+			return err
+		}
+		p.Repos = slices.DeleteFunc(p.Repos, func(e beadsapi.RepoEntry) bool { return e.URL == r.URL })
+		p.Repos = append(p.Repos, r)
+	}
+	return nil
+}
+
+// parseSecretSpec parses ENV=secret:key.
+func parseSecretSpec(spec string) (beadsapi.SecretEntry, error) {
+	env, ref, ok := strings.Cut(spec, "=")
+	secret, key, ok2 := strings.Cut(ref, ":")
+	if !ok || !ok2 || env == "" || secret == "" || key == "" {
+		return beadsapi.SecretEntry{}, fmt.Errorf("--secret %q: want ENV=secret:key", spec)
+	}
+	return beadsapi.SecretEntry{Env: env, Secret: secret, Key: key}, nil
+}
+
+// parseHostAliasSpec parses IP=host[,host].
+func parseHostAliasSpec(spec string) (corev1.HostAlias, error) {
+	ip, hosts, ok := strings.Cut(spec, "=")
+	if !ok || ip == "" || hosts == "" {
+		return corev1.HostAlias{}, fmt.Errorf("--host-alias %q: want IP=host[,host]", spec)
+	}
+	return corev1.HostAlias{IP: ip, Hostnames: strings.Split(hosts, ",")}, nil
+}
+
+// parseRepoSpec parses URL[,branch=B][,role=R][,name=N].
+func parseRepoSpec(spec string) (beadsapi.RepoEntry, error) {
+	parts := strings.Split(spec, ",")
+	r := beadsapi.RepoEntry{URL: parts[0]}
+	for _, part := range parts[1:] {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, fmt.Errorf("--repo %q: %q is not key=value", spec, part)
+		}
+		switch k {
+		case "branch":
+			r.Branch = v
+		case "role":
+			r.Role = v
+		case "name":
+			r.Name = v
+		default:
+			return r, fmt.Errorf("--repo %q: unknown setting %q (want branch, role, or name)", spec, k)
+		}
+	}
+	return r, nil
+}
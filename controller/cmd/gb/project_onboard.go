@@ -0,0 +1,252 @@
+package main
+
+// gb project onboard — register or re-validate a project end to end.
+//
+// The controller holds back a project's agents until onboarding passes: it
+// checks the config, resolves every secret the pods reference, and runs a
+// disposable smoke-test agent that pulls the image and clones the repos
+// with the project's credentials. This command fills in the project (asking
+// for anything not given as a flag when run on a terminal), requests the
+// validation, and waits for the report.
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+var projectOnboardCmd = &cobra.Command{
+	Use:   "onboard <name>",
+	Short: "Register a project and validate it before agents are scheduled",
+	Long: `Create (or update) a project bead and have the controller validate it
+end to end before any of its agents are scheduled:
+
+  config   the project fields are valid
+  secrets  every secret and key the agent pods reference exists
+  image    the agent image can be pulled
+  clone    the repos clone with the project's credentials
+  smoke    a disposable smoke-test agent starts and becomes ready
+
+Results are written onto the project bead (onboarding, onboarding_report)
+and printed here. Run on a terminal, missing settings are asked for;
+--no-input turns the questions off. Run it again on an existing project to
+re-validate it after fixing a problem.
+
+Examples:
+  gb project onboard api
+  gb project onboard api --git-url git@gitlab.com:org/api.git \
+    --secret GITLAB_TOKEN=gitlab-creds:token --no-input
+  gb project onboard api --no-wait`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProjectOnboard,
+}
+
+func runProjectOnboard(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	name := args[0]
+
+	projects, err := daemon.ListProjectBeads(ctx)
+	if err != nil {
+		return err
+	}
+	existing, exists := projects[name]
+	p := beadsapi.ProjectInfo{Name: name}
+	if exists {
+		p = existing
+	}
+	if err := applyProjectFlags(cmd, &p); err != nil {
+		return err
+	}
+	noInput, _ := cmd.Flags().GetBool("no-input")
+	if !exists && !noInput && stdinIsTerminal() {
+		if err := askProjectSettings(cmd, &p, bufio.NewReader(os.Stdin), os.Stdout); err != nil {
+			return err
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return fmt.Errorf("invalid project:\n%w", err)
+	}
+
+	fields := p.Fields()
+	fields[beadsapi.OnboardingField] = beadsapi.OnboardingPending
+	fields[beadsapi.OnboardingReportField] = ""
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		printJSON(fields)
+		return nil
+	}
+
+	if exists {
+		if err := daemon.UpdateBeadFields(ctx, p.ID, fields); err != nil {
+			return fmt.Errorf("updating project %s: %w", name, err)
+		}
+	} else {
+		for k, v := range fields {
+			if v == "" {
+				delete(fields, k)
+			}
+		}
+		raw, err := json.Marshal(fields)
+		if err != nil {
+			return fmt.Errorf("encoding fields: %w", err)
+		}
+		description, _ := cmd.Flags().GetString("description")
+		p.ID, err = daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
+			Title:       name,
+			Type:        "project",
+			Description: description,
+			CreatedBy:   actor,
+			Fields:      raw,
+		})
+		if err != nil {
+			return fmt.Errorf("creating project %s: %w", name, err)
+		}
+	}
+	if !jsonOutput {
+		fmt.Printf("Requested onboarding of project %s (%s); its agents wait until it passes.\n", name, p.ID)
+	}
+
+	if noWait, _ := cmd.Flags().GetBool("no-wait"); noWait {
+		return nil
+	}
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	state, report, err := waitForOnboarding(ctx, p.ID, timeout)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		printJSON(map[string]any{"project": name, "id": p.ID, "onboarding": state, "report": report})
+	} else {
+		printOnboardingReport(state, report)
+	}
+	if state != beadsapi.OnboardingPassed {
+		return fmt.Errorf("onboarding of project %s failed", name)
+	}
+	return nil
+}
+
+// askProjectSettings fills in the settings of a new project that weren't
+// given as flags.
+func askProjectSettings(cmd *cobra.Command, p *beadsapi.ProjectInfo, in *bufio.Reader, out io.Writer) error {
+	flags := cmd.Flags()
+	ask := func(flag, label string, dst *string) error {
+		if flags.Changed(flag) {
+			return nil
+		}
+		v, err := promptLine(in, out, label, *dst)
+		*dst = v
+		return err
+	}
+	for _, q := range []struct {
+		flag, label string
+		dst         *string
+	}{
+		{"git-url", "Git URL of the primary repository", &p.GitURL},
+		{"default-branch", "Default branch", &p.DefaultBranch},
+		{"prefix", "Beads ID prefix", &p.Prefix},
+		{"image", "Agent image (blank for the controller default)", &p.Image},
+	} {
+		if err := ask(q.flag, q.label, q.dst); err != nil {
+			return err
+		}
+	}
+	if !flags.Changed("secret") {
+		for {
+			spec, err := promptLine(in, out, "Secret env mapping ENV=secret:key (blank to finish)", "")
+			if err != nil || spec == "" {
+				return err
+			}
+			s, err := parseSecretSpec(spec)
+			if err != nil {
+				fmt.Fprintf(out, "  %v\n", err)
+				continue
+			}
+			p.Secrets = append(p.Secrets, s)
+		}
+	}
+	return nil
+}
+
+// promptLine asks for one line of input, returning def when it is blank.
+func promptLine(in *bufio.Reader, out io.Writer, label, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(out, "%s [%s]: ", label, def)
+	} else {
+		fmt.Fprintf(out, "%s: ", label)
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	if line = strings.TrimSpace(line); line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// stdinIsTerminal reports whether gb can ask questions.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// waitForOnboarding polls the project bead until the controller has
+// finished validating it, returning the final state and report.
+func waitForOnboarding(ctx context.Context, id string, timeout time.Duration) (string, *beadsapi.OnboardingReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		bead, err := daemon.GetBead(ctx, id)
+		if err != nil {
+			return "", nil, err
+		}
+		switch state := bead.Fields[beadsapi.OnboardingField]; state {
+		case beadsapi.OnboardingPassed, beadsapi.OnboardingFailed:
+			return state, beadsapi.OnboardingReportFromFields(bead.Fields), nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", nil, fmt.Errorf("onboarding not finished after %s (is the controller running?); check later with gb project show", timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// printOnboardingReport prints an onboarding state and its checks.
+func printOnboardingReport(state string, report *beadsapi.OnboardingReport) {
+	fmt.Printf("onboarding: %s\n", state)
+	if report == nil {
+		return
+	}
+	for _, c := range report.Checks {
+		mark := "✓"
+		switch c.Status {
+		case beadsapi.CheckFailed:
+			mark = "✗"
+		case beadsapi.CheckSkipped:
+			mark = "-"
+		}
+		fmt.Printf("  %s %-8s", mark, c.Name)
+		if c.Detail != "" {
+			lines := strings.Split(c.Detail, "\n")
+			fmt.Printf(" %s", lines[0])
+			for _, l := range lines[1:] {
+				fmt.Printf("\n             %s", l)
+			}
+		}
+		fmt.Println()
+	}
+	if !report.Finished.IsZero() {
+		fmt.Printf("  (took %s)\n", report.Finished.Sub(report.Started).Round(time.Second))
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+// Offline write queue.
+//
+// With GB_OFFLINE_QUEUE=1 (or --offline-queue), idempotent writes — field
+// updates, comments, and mail — that fail because the daemon is unreachable
+// are appended to $XDG_STATE_HOME/gb/queue.jsonl instead of failing the
+// command. Every gb invocation replays the queue, in order, once the daemon
+// answers again. Only connection failures are queued; a write the daemon
+// rejects still fails.
+//
+// Enqueues append one line with O_APPEND. Flushes hold queue.lock (an
+// O_EXCL file, treated as stale after queueLockStale) so concurrent gb
+// processes — hooks often run in parallel — replay each entry once.
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	queueFlushTimeout = 10 * time.Second
+	queueLockStale    = time.Minute
+)
+
+// offlineQueue enables queueing; defaults from GB_OFFLINE_QUEUE.
+var offlineQueue bool
+
+// queuedWrite is one line of the queue file.
+type queuedWrite struct {
+	Kind     string                      `json:"kind"` // fields, comment, mail
+	BeadID   string                      `json:"bead_id,omitempty"`
+	Fields   map[string]string           `json:"fields,omitempty"`
+	Author   string                      `json:"author,omitempty"`
+	Text     string                      `json:"text,omitempty"`
+	Create   *beadsapi.CreateBeadRequest `json:"create,omitempty"`
+	QueuedAt time.Time                   `json:"queued_at"`
+}
+
+func (w queuedWrite) String() string {
+	switch w.Kind {
+	case "fields":
+		return fmt.Sprintf("update fields on %s", w.BeadID)
+	case "comment":
+		return fmt.Sprintf("comment on %s", w.BeadID)
+	case "mail":
+		if w.Create != nil {
+			return fmt.Sprintf("mail to %s: %s", w.Create.Assignee, w.Create.Title)
+		}
+	}
+	return w.Kind
+}
+
+func defaultOfflineQueue() bool {
+	v, _ := strconv.ParseBool(os.Getenv("GB_OFFLINE_QUEUE"))
+	return v
+}
+
+// queueDir returns $XDG_STATE_HOME/gb, defaulting to ~/.local/state/gb.
+func queueDir() string {
+	state := os.Getenv("XDG_STATE_HOME")
+	if state == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			home = os.TempDir()
+		}
+		state = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(state, "gb")
+}
+
+func queuePath() string { return filepath.Join(queueDir(), "queue.jsonl") }
+
+// isUnreachable reports whether err means the request never got an answer
+// from the daemon (connection refused, DNS failure, timeout), as opposed to
+// the daemon rejecting it.
+func isUnreachable(err error) bool {
+	var apiErr *beadsapi.APIError
+	if errors.As(err, &apiErr) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// queueOrFail enqueues w when err is a connection failure and queueing is
+// enabled; otherwise it returns err unchanged.
+func queueOrFail(err error, w queuedWrite) error {
+	if err == nil || !offlineQueue || !isUnreachable(err) {
+		return err
+	}
+	w.QueuedAt = time.Now().UTC()
+	if qerr := enqueueWrite(w); qerr != nil {
+		return fmt.Errorf("%w (queueing failed: %v)", err, qerr)
+	}
+	fmt.Fprintf(os.Stderr, "gb: daemon unreachable; queued %s for later delivery\n", w)
+	return nil
+}
+
+// updateFieldsQueued is daemon.UpdateBeadFields, queued while offline.
+func updateFieldsQueued(ctx context.Context, beadID string, fields map[string]string) error {
+	err := daemon.UpdateBeadFields(ctx, beadID, fields)
+	return queueOrFail(err, queuedWrite{Kind: "fields", BeadID: beadID, Fields: fields})
+}
+
+// addCommentQueued is daemon.AddComment, queued while offline.
+func addCommentQueued(ctx context.Context, beadID, author, text string) error {
+	err := daemon.AddComment(ctx, beadID, author, text)
+	return queueOrFail(err, queuedWrite{Kind: "comment", BeadID: beadID, Author: author, Text: text})
+}
+
+// createMailQueued is daemon.CreateBead for mail, queued while offline. The
+// returned ID is empty when the mail was queued.
+func createMailQueued(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error) {
+	id, err := daemon.CreateBead(ctx, req)
+	return id, queueOrFail(err, queuedWrite{Kind: "mail", Create: &req})
+}
+
+func enqueueWrite(w queuedWrite) error {
+	line, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(queueDir(), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(queuePath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readQueue returns the queued writes and the byte length they occupy.
+// Malformed lines are skipped.
+func readQueue() ([]queuedWrite, int64, error) {
+	data, err := os.ReadFile(queuePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	var writes []queuedWrite
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		var w queuedWrite
+		if json.Unmarshal(sc.Bytes(), &w) == nil && w.Kind != "" {
+			writes = append(writes, w)
+		}
+	}
+	return writes, int64(len(data)), sc.Err()
+}
+
+// lockQueue takes the flush lock, returning false if another process holds it.
+func lockQueue() (unlock func(), ok bool) {
+	lock := filepath.Join(queueDir(), "queue.lock")
+	for range 2 {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lock) }, true
+		}
+		info, serr := os.Stat(lock)
+		if serr != nil || time.Since(info.ModTime()) < queueLockStale {
+			return nil, false
+		}
+		os.Remove(lock) // stale: holder crashed
+	}
+	return nil, false
+}
+
+// flushQueue replays queued writes in order. It stops at the first write
+// that still cannot reach the daemon; writes the daemon rejects are dropped
+// with a warning. Returns the number delivered and the number remaining.
+func flushQueue(ctx context.Context) (sent, remaining int, err error) {
+	writes, size, err := readQueue()
+	if err != nil || len(writes) == 0 {
+		return 0, 0, err
+	}
+	unlock, ok := lockQueue()
+	if !ok {
+		return 0, len(writes), nil
+	}
+	defer unlock()
+
+	// Re-read under the lock: another process may have flushed meanwhile.
+	if writes, size, err = readQueue(); err != nil || len(writes) == 0 {
+		return 0, 0, err
+	}
+
+	done := 0
+	for _, w := range writes {
+		if err := replayWrite(ctx, w); err != nil {
+			if isUnreachable(err) || ctx.Err() != nil {
+				break
+			}
+			fmt.Fprintf(os.Stderr, "gb: dropping queued %s: %v\n", w, err)
+		} else {
+			sent++
+		}
+		done++
+	}
+	if done == 0 {
+		return 0, len(writes), nil
+	}
+	return sent, len(writes) - done, rewriteQueue(writes[done:], size)
+}
+
+// rewriteQueue replaces the queue with pending followed by anything appended
+// after the first size bytes were read.
+func rewriteQueue(pending []queuedWrite, size int64) error {
+	data, err := os.ReadFile(queuePath())
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for _, w := range pending {
+		line, _ := json.Marshal(w)
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	if int64(len(data)) > size {
+		buf.Write(data[size:])
+	}
+	if buf.Len() == 0 {
+		return os.Remove(queuePath())
+	}
+	tmp := queuePath() + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, queuePath())
+}
+
+func replayWrite(ctx context.Context, w queuedWrite) error {
+	switch w.Kind {
+	case "fields":
+		return daemon.UpdateBeadFields(ctx, w.BeadID, w.Fields)
+	case "comment":
+		return daemon.AddComment(ctx, w.BeadID, w.Author, w.Text)
+	case "mail":
+		if w.Create == nil {
+			return fmt.Errorf("mail entry has no bead")
+		}
+		_, err := daemon.CreateBead(ctx, *w.Create)
+		return err
+	}
+	return fmt.Errorf("unknown kind %q", w.Kind)
+}
+
+// flushQueueQuietly runs before every command. It is a no-op unless the
+// queue file has entries, and never fails the command.
+func flushQueueQuietly(ctx context.Context) {
+	if info, err := os.Stat(queuePath()); err != nil || info.Size() == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, queueFlushTimeout)
+	defer cancel()
+	sent, remaining, err := flushQueue(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gb: flushing offline queue: %v\n", err)
+	}
+	if sent > 0 {
+		fmt.Fprintf(os.Stderr, "gb: delivered %d queued write(s); %d still queued\n", sent, remaining)
+	}
+}
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Inspect and flush the offline write queue",
+	Long: `Writes that fail because the daemon is unreachable are queued locally when
+GB_OFFLINE_QUEUE=1 (or --offline-queue) is set, and delivered in order by
+the next gb command that reaches the daemon.
+
+Queued: agent field updates (gb stop, gb yield --checkpoint, gb setup
+session, gb ready --claim, gb workspace), their comments, and gb mail send.
+
+Queue file: $XDG_STATE_HOME/gb/queue.jsonl (default ~/.local/state/gb).`,
+	GroupID: "session",
+}
+
+var queueStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "List queued writes",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		writes, _, err := readQueue()
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			if writes == nil {
+				writes = []queuedWrite{}
+			}
+			printJSON(writes)
+			return nil
+		}
+		if len(writes) == 0 {
+			fmt.Println("Offline queue is empty.")
+			return nil
+		}
+		fmt.Printf("%d queued write(s) in %s:\n", len(writes), queuePath())
+		for i, w := range writes {
+			fmt.Printf("  %d. %s  (queued %s)\n", i+1, w, w.QueuedAt.Local().Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+var queueFlushCmd = &cobra.Command{
+	Use:   "flush",
+	Short: "Deliver queued writes now",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+		defer cancel()
+		sent, remaining, err := flushQueue(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Delivered %d queued write(s); %d still queued.\n", sent, remaining)
+		if remaining > 0 {
+			return fmt.Errorf("daemon unreachable or queue locked by another gb process")
+		}
+		return nil
+	},
+}
+
+var queueClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Discard all queued writes",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		writes, _, err := readQueue()
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(queuePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		fmt.Printf("Discarded %d queued write(s).\n", len(writes))
+		return nil
+	},
+}
+
+func init() {
+	queueCmd.AddCommand(queueStatusCmd)
+	queueCmd.AddCommand(queueFlushCmd)
+	queueCmd.AddCommand(queueClearCmd)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"gasboat/controller/internal/beadsapi"
 
@@ -9,10 +10,19 @@ import (
 )
 
 var readyCmd = &cobra.Command{
-	Use:     "ready",
-	Short:   "Show beads ready to work on (open, not blocked)",
+	Use:   "ready",
+	Short: "Show beads ready to work on (open, not blocked)",
+	Long: `Lists open, unblocked beads.
+
+With --claim, marks this agent available (ready_since) and claims the next
+task assigned to it — the task linked at spawn time, or an open task whose
+assignee is this agent. Add --wait to poll until one arrives.`,
 	GroupID: "session",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if claim, _ := cmd.Flags().GetBool("claim"); claim {
+			return runReadyClaim(cmd)
+		}
+
 		beadType, _ := cmd.Flags().GetStringSlice("type")
 		assignee, _ := cmd.Flags().GetString("assignee")
 		limit, _ := cmd.Flags().GetInt("limit")
@@ -60,4 +70,9 @@ func init() {
 	readyCmd.Flags().Int("limit", 20, "maximum number of results")
 	readyCmd.Flags().String("project", defaultGBProject(), "filter by project label (default: $KD_PROJECT or $BOAT_PROJECT)")
 	readyCmd.Flags().Bool("all-projects", false, "show beads from all projects (disables project filter)")
+	readyCmd.Flags().Bool("claim", false, "mark this agent available and claim its next assigned task")
+	readyCmd.Flags().Bool("wait", false, "with --claim, poll until an assigned task arrives")
+	readyCmd.Flags().Duration("poll-interval", 30*time.Second, "with --wait, how often to check for assigned work")
+	readyCmd.Flags().Duration("timeout", time.Hour, "with --wait, maximum time to wait (0 = no limit)")
+	readyCmd.Flags().String("agent-id", "", "agent bead ID (default: KD_AGENT_ID env)")
 }
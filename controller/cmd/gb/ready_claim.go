@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+// runReadyClaim implements gb ready --claim: mark the agent available, then
+// claim the next task assigned to it. With --wait it polls until a task
+// arrives or the timeout expires.
+//
+// Candidates, in order: a task the agent already has in progress (resumed,
+// not re-claimed), the task linked by an "assigned" dependency at spawn
+// time, then open unblocked tasks whose assignee is this agent.
+func runReadyClaim(cmd *cobra.Command) error {
+	wait, _ := cmd.Flags().GetBool("wait")
+	interval, _ := cmd.Flags().GetDuration("poll-interval")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	agentIDFlag, _ := cmd.Flags().GetString("agent-id")
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	if wait && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	agentID, err := resolveAgentIDWithFallback(ctx, agentIDFlag)
+	if err != nil {
+		return fmt.Errorf("agent identity required for ready --claim: %w", err)
+	}
+
+	if current, err := daemon.ListAssignedTask(ctx, actor); err == nil && current != nil {
+		return printClaimed(current, true)
+	}
+
+	if err := updateFieldsQueued(ctx, agentID, map[string]string{
+		"ready_since": time.Now().UTC().Format(time.RFC3339),
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to mark agent ready: %v\n", err)
+	}
+
+poll:
+	for {
+		task, err := nextClaimableTask(ctx, agentID)
+		if err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if task != nil {
+			if err := claimTask(ctx, agentID, task); err != nil {
+				return err
+			}
+			return printClaimed(task, false)
+		}
+		if !wait {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break poll
+		case <-time.After(interval):
+		}
+	}
+
+	if jsonOutput {
+		printJSON(map[string]any{"claimed": nil})
+	} else {
+		fmt.Println("No assigned work to claim")
+	}
+	return nil
+}
+
+// nextClaimableTask returns the first open task assigned to the agent, or
+// nil when there is none.
+func nextClaimableTask(ctx context.Context, agentID string) (*beadsapi.BeadDetail, error) {
+	deps, err := daemon.GetDependencies(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("listing agent dependencies: %w", err)
+	}
+	for _, d := range deps {
+		if d.Type != "assigned" {
+			continue
+		}
+		task, err := daemon.GetBead(ctx, d.DependsOnID)
+		if err == nil && task.Status == "open" {
+			return task, nil
+		}
+	}
+
+	result, err := daemon.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{
+		Statuses:   []string{"open"},
+		Assignee:   actor,
+		NoOpenDeps: true,
+		Sort:       "priority",
+		Limit:      10,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing assigned beads: %w", err)
+	}
+	if beads := filterToIssueKind(result.Beads); len(beads) > 0 {
+		return beads[0], nil
+	}
+	return nil, nil
+}
+
+// claimTask marks task in_progress under this agent, the same transition as
+// kd claim, and clears the agent's ready marker.
+func claimTask(ctx context.Context, agentID string, task *beadsapi.BeadDetail) error {
+	status := "in_progress"
+	assignee := actor
+	if err := daemon.UpdateBead(ctx, task.ID, beadsapi.UpdateBeadRequest{
+		Status:   &status,
+		Assignee: &assignee,
+	}); err != nil {
+		return fmt.Errorf("claiming %s: %w", task.ID, err)
+	}
+	if err := updateFieldsQueued(ctx, agentID, map[string]string{"ready_since": ""}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to clear ready marker: %v\n", err)
+	}
+	return nil
+}
+
+func printClaimed(task *beadsapi.BeadDetail, resumed bool) error {
+	if jsonOutput {
+		printJSON(map[string]any{"claimed": task, "resumed": resumed})
+		return nil
+	}
+	if resumed {
+		fmt.Printf("Already working on %s: %s\n", task.ID, task.Title)
+	} else {
+		fmt.Printf("Claimed %s: %s\n", task.ID, task.Title)
+	}
+	fmt.Printf("Run `kd show %s` for details.\n", task.ID)
+	return nil
+}
@@ -333,19 +333,14 @@ func runSetupClaudeRemove(workspace string) error {
 	return nil
 }
 
-func runSetupClaude(ctx context.Context, workspace, role string) error {
-	var layers []json.RawMessage
+// agentConfigHooks holds the claude-hooks layers the controller renders into
+// the agent's ConfigMap (AGENT_CONFIGMAPS), as a JSON list.
+const agentConfigHooks = "/etc/agent-pod/hooks.json"
 
-	if cfg, err := daemon.GetConfig(ctx, "claude-hooks:global"); err == nil && cfg != nil {
-		layers = append(layers, cfg.Value)
-		fmt.Fprintf(os.Stderr, "[setup] loaded claude-hooks:global\n")
-	}
-
-	if role != "" {
-		if cfg, err := daemon.GetConfig(ctx, "claude-hooks:"+role); err == nil && cfg != nil {
-			layers = append(layers, cfg.Value)
-			fmt.Fprintf(os.Stderr, "[setup] loaded claude-hooks:%s\n", role)
-		}
+func runSetupClaude(ctx context.Context, workspace, role string) error {
+	layers := mountedHookLayers()
+	if layers == nil {
+		layers = daemonHookLayers(ctx, role)
 	}
 
 	if len(layers) == 0 {
@@ -375,6 +370,41 @@ func runSetupClaude(ctx context.Context, workspace, role string) error {
 	return nil
 }
 
+// mountedHookLayers returns the hook layers from the agent's ConfigMap, or
+// nil if it isn't mounted.
+func mountedHookLayers() []json.RawMessage {
+	data, err := os.ReadFile(agentConfigHooks)
+	if err != nil {
+		return nil
+	}
+	var layers []json.RawMessage
+	if err := json.Unmarshal(data, &layers); err != nil {
+		fmt.Fprintf(os.Stderr, "[setup] warning: ignoring %s: %v\n", agentConfigHooks, err)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "[setup] loaded %s\n", agentConfigHooks)
+	return layers
+}
+
+// daemonHookLayers fetches the claude-hooks:global and claude-hooks:<role>
+// config beads from the daemon.
+func daemonHookLayers(ctx context.Context, role string) []json.RawMessage {
+	var layers []json.RawMessage
+
+	if cfg, err := daemon.GetConfig(ctx, "claude-hooks:global"); err == nil && cfg != nil {
+		layers = append(layers, cfg.Value)
+		fmt.Fprintf(os.Stderr, "[setup] loaded claude-hooks:global\n")
+	}
+
+	if role != "" {
+		if cfg, err := daemon.GetConfig(ctx, "claude-hooks:"+role); err == nil && cfg != nil {
+			layers = append(layers, cfg.Value)
+			fmt.Fprintf(os.Stderr, "[setup] loaded claude-hooks:%s\n", role)
+		}
+	}
+	return layers
+}
+
 func mergeHookLayers(layers []json.RawMessage) map[string]any {
 	result := make(map[string]any)
 	for _, raw := range layers {
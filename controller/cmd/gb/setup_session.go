@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var setupSessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Prime the workspace and register this agent session",
+	Long: `Prepares a fresh agent session. This is the entrypoint's single call
+before launching Claude:
+
+  1. Materializes Claude Code hooks from config beads, falling back to the
+     hardcoded defaults when none exist or the daemon is unreachable.
+  2. Registers the session on the agent bead: agent_state=working,
+     session_started_at, and session_host. A stale ready_since marker from
+     the previous session is cleared.
+
+Registration is skipped (with a warning) when no agent identity is set, so
+the command also works in local workspaces.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, _ := cmd.Flags().GetString("workspace")
+		if workspace == "" {
+			workspace, _ = os.Getwd()
+		}
+		role, _ := cmd.Flags().GetString("role")
+		if role == "" {
+			role = os.Getenv("BOAT_ROLE")
+		}
+		if role == "" {
+			role = os.Getenv("KD_ROLE")
+		}
+		agentIDFlag, _ := cmd.Flags().GetString("agent-id")
+
+		if err := setupSessionHooks(cmd.Context(), workspace, role); err != nil {
+			return err
+		}
+
+		agentID, err := resolveAgentIDWithFallback(cmd.Context(), agentIDFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[setup] session not registered: %v\n", err)
+			return nil
+		}
+		host, _ := os.Hostname()
+		if err := updateFieldsQueued(cmd.Context(), agentID, map[string]string{
+			"agent_state":        "working",
+			"session_started_at": time.Now().UTC().Format(time.RFC3339),
+			"session_host":       host,
+			"ready_since":        "",
+		}); err != nil {
+			return fmt.Errorf("registering session on agent %s: %w", agentID, err)
+		}
+		fmt.Fprintf(os.Stderr, "[setup] session registered on agent %s\n", agentID)
+		return nil
+	},
+}
+
+func init() {
+	setupSessionCmd.Flags().String("workspace", os.Getenv("KD_WORKSPACE"), "workspace directory")
+	setupSessionCmd.Flags().String("role", "", "agent role (default: $BOAT_ROLE or $KD_ROLE)")
+	setupSessionCmd.Flags().String("agent-id", "", "agent bead ID (default: KD_AGENT_ID env)")
+	setupCmd.AddCommand(setupSessionCmd)
+}
+
+// setupSessionHooks installs hooks from config beads, or the defaults when
+// there are none or the daemon is unreachable.
+func setupSessionHooks(ctx context.Context, workspace, role string) error {
+	err := runSetupClaude(ctx, workspace, role)
+	if err == nil {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "[setup] config-bead hooks unavailable (%v), installing default gb hooks\n", err)
+	return runSetupClaudeDefaults(workspace)
+}
@@ -0,0 +1,118 @@
+package main
+
+// gb spend — report an agent's API spend.
+//
+// Agents (or their hooks) add what each model call cost to their agent
+// bead. The controller sums it per project per UTC day and enforces the
+// project's budget: past budget_soft_usd the bridge is notified, past
+// budget_hard_usd no new agents of the project are spawned that day.
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/spf13/cobra"
+)
+
+var spendCmd = &cobra.Command{
+	Use:   "spend",
+	Short: "Show or report this agent's API spend",
+	Long: `Show the API spend recorded on your agent bead, by UTC day.
+
+Use "gb spend add" to report spend; the controller totals it per project
+and enforces the project's daily budget.`,
+	GroupID: "orchestration",
+	Args:    cobra.NoArgs,
+	RunE:    runSpend,
+}
+
+var spendAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add API spend to this agent's total for today",
+	Example: `  gb spend add --usd 0.42 --tokens 18000
+  gb spend add --usd 1.10 --agent-id kd-abc12`,
+	Args: cobra.NoArgs,
+	RunE: runSpendAdd,
+}
+
+var (
+	spendAgentID string
+	spendUSD     float64
+	spendTokens  int64
+)
+
+func init() {
+	spendCmd.PersistentFlags().StringVar(&spendAgentID, "agent-id", "", "agent bead ID (default: this agent)")
+	spendAddCmd.Flags().Float64Var(&spendUSD, "usd", 0, "cost in USD (required)")
+	spendAddCmd.Flags().Int64Var(&spendTokens, "tokens", 0, "tokens used")
+	_ = spendAddCmd.MarkFlagRequired("usd")
+
+	spendCmd.AddCommand(spendAddCmd)
+}
+
+func runSpend(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	agentID, err := resolveAgentIDWithFallback(ctx, spendAgentID)
+	if err != nil {
+		return err
+	}
+	bead, err := daemon.GetBead(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	days := beadsapi.SpendFromFields(bead.Fields)
+
+	if jsonOutput {
+		printJSON(days)
+		return nil
+	}
+	if len(days) == 0 {
+		fmt.Println("No spend reported")
+		return nil
+	}
+	keys := make([]string, 0, len(days))
+	for day := range days {
+		keys = append(keys, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DAY\tUSD\tTOKENS")
+	for _, day := range keys {
+		fmt.Fprintf(w, "%s\t%.2f\t%d\n", day, days[day].USD, days[day].Tokens)
+	}
+	return w.Flush()
+}
+
+func runSpendAdd(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	agentID, err := resolveAgentIDWithFallback(ctx, spendAgentID)
+	if err != nil {
+		return err
+	}
+	bead, err := daemon.GetBead(ctx, agentID)
+	if err != nil {
+		return err
+	}
+	day := beadsapi.SpendDay(time.Now())
+	raw, err := beadsapi.AddSpend(bead.Fields[beadsapi.SpendField], day,
+		beadsapi.Spend{USD: spendUSD, Tokens: spendTokens})
+	if err != nil {
+		return err
+	}
+	if err := daemon.UpdateBeadFields(ctx, agentID, map[string]string{beadsapi.SpendField: raw}); err != nil {
+		return fmt.Errorf("recording spend: %w", err)
+	}
+
+	today := beadsapi.SpendFromFields(map[string]string{beadsapi.SpendField: raw})[day]
+	if jsonOutput {
+		printJSON(map[string]any{"agent_id": agentID, "day": day, "usd": today.USD, "tokens": today.Tokens})
+		return nil
+	}
+	fmt.Printf("Spent today: $%.2f, %d tokens\n", today.USD, today.Tokens)
+	return nil
+}
@@ -80,7 +80,7 @@ func runStop(cmd *cobra.Command, args []string) error {
 	if commentAuthor == "" || commentAuthor == "unknown" {
 		commentAuthor = agentID
 	}
-	if err := daemon.AddComment(ctx, agentID, commentAuthor, "gb stop: "+reason); err != nil {
+	if err := addCommentQueued(ctx, agentID, commentAuthor, "gb stop: "+reason); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not add stop comment: %v\n", err)
 	}
 
@@ -94,7 +94,7 @@ func runStop(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if err := daemon.UpdateBeadFields(ctx, agentID, map[string]string{
+	if err := updateFieldsQueued(ctx, agentID, map[string]string{
 		"stop_requested": "true",
 	}); err != nil {
 		return fmt.Errorf("setting stop_requested on bead %s: %w", agentID, err)
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gasboat/controller/internal/tui/watch"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Live terminal dashboard of agents, decisions, and events",
+	Long: `Launch a terminal dashboard showing the agent roster (working, idle,
+dead), pending decisions, and a feed of recent bead events. It updates live
+from the daemon's SSE stream and re-polls every 15s as a fallback — the
+terminal version of the Slack agent dashboard.
+
+Key bindings:
+  r          Refresh now
+  p          Pause/resume the event feed
+  c          Clear the event feed
+  ?          Toggle help
+  q/Ctrl+C   Quit`,
+	GroupID: "agent",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		model := watch.New(daemon)
+
+		project, _ := cmd.Flags().GetString("project")
+		model.SetProject(project)
+
+		p := tea.NewProgram(model, tea.WithAltScreen())
+		if _, err := p.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error running watch: %v\n", err)
+			return err
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	watchCmd.Flags().String("project", "", "Show only agents in this project")
+}
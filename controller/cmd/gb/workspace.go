@@ -122,7 +122,7 @@ func runWorkspaceSetup(ctx context.Context, beadID, repoPath, baseBranch, branch
 	if err != nil {
 		return fmt.Errorf("marshalling workspace metadata: %w", err)
 	}
-	if err := daemon.UpdateBeadFields(ctx, beadID, map[string]string{
+	if err := updateFieldsQueued(ctx, beadID, map[string]string{
 		"workspace": string(metaJSON),
 	}); err != nil {
 		// Non-fatal: worktree was created; warn but continue.
@@ -182,7 +182,7 @@ func runWorkspaceTeardown(ctx context.Context, beadID, repoPath string, force bo
 	}
 
 	// Clear workspace metadata from bead.
-	if err := daemon.UpdateBeadFields(ctx, beadID, map[string]string{
+	if err := updateFieldsQueued(ctx, beadID, map[string]string{
 		"workspace": "",
 	}); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to clear workspace metadata from bead %s: %v\n", beadID, err)
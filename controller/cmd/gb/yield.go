@@ -27,9 +27,17 @@ Uses HTTP SSE for real-time notification, with 2-second polling as fallback.
 
 Requires an open decision bead created with 'gb decision create' before
 calling yield. After the decision resolves, gb yield calls
-POST /v1/agents/{id}/gates/decision/satisfy to release the Stop gate.`,
+POST /v1/agents/{id}/gates/decision/satisfy to release the Stop gate.
+
+With --checkpoint, yield does not wait: it records the session checkpoint
+(claimed task, workspace branch and HEAD) on the agent bead and signals the
+controller that it is safe to restart the pod.`,
 	GroupID: "session",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if checkpoint, _ := cmd.Flags().GetBool("checkpoint"); checkpoint {
+			return runYieldCheckpoint(cmd)
+		}
+
 		timeout, _ := cmd.Flags().GetDuration("timeout")
 
 		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
@@ -222,4 +230,7 @@ func printYieldResult(id string) error {
 func init() {
 	yieldCmd.Flags().Duration("timeout", 24*time.Hour, "maximum time to wait")
 	yieldCmd.Flags().StringVar(&yieldAgentID, "agent-id", "", "agent bead ID (default: KD_AGENT_ID env)")
+	yieldCmd.Flags().Bool("checkpoint", false, "record a checkpoint and signal the controller it may restart the pod")
+	yieldCmd.Flags().String("workspace", os.Getenv("KD_WORKSPACE"), "with --checkpoint, workspace to record the git ref of")
+	yieldCmd.Flags().String("note", "", "with --checkpoint, free-form note for the next session")
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// runYieldCheckpoint implements gb yield --checkpoint: record where the
+// session stands and tell the controller the pod may be restarted now.
+//
+// The checkpoint (claimed task, workspace branch and HEAD) is written to the
+// agent bead as fields and a comment so the next session can pick up from
+// it. Setting restart_requested is the same signal gb agent restart sends;
+// the controller recreates the pod and the entrypoint resumes the session.
+func runYieldCheckpoint(cmd *cobra.Command) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), 15*time.Second)
+	defer cancel()
+
+	agentID, err := resolveAgentIDWithFallback(ctx, yieldAgentID)
+	if err != nil {
+		return fmt.Errorf("agent identity required for yield --checkpoint: %w", err)
+	}
+	workspace, _ := cmd.Flags().GetString("workspace")
+	if workspace == "" {
+		workspace, _ = os.Getwd()
+	}
+	note, _ := cmd.Flags().GetString("note")
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	fields := map[string]string{
+		"checkpoint_at":     now,
+		"restart_requested": now,
+	}
+
+	var summary []string
+	if task, err := daemon.ListAssignedTask(ctx, actor); err == nil && task != nil {
+		fields["checkpoint_task"] = task.ID
+		summary = append(summary, fmt.Sprintf("task %s (%s)", task.ID, task.Title))
+	}
+	if branch := gitOutput(workspace, "rev-parse", "--abbrev-ref", "HEAD"); branch != "" {
+		ref := branch
+		if head := gitOutput(workspace, "rev-parse", "--short", "HEAD"); head != "" {
+			ref += "@" + head
+		}
+		fields["checkpoint_ref"] = ref
+		summary = append(summary, "ref "+ref)
+	}
+	if note != "" {
+		summary = append(summary, note)
+	}
+
+	comment := "gb yield --checkpoint: safe to restart"
+	if len(summary) > 0 {
+		comment += " — " + strings.Join(summary, "; ")
+	}
+	if err := addCommentQueued(ctx, agentID, actor, comment); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record checkpoint comment: %v\n", err)
+	}
+	if err := updateFieldsQueued(ctx, agentID, fields); err != nil {
+		return fmt.Errorf("writing checkpoint to agent %s: %w", agentID, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Checkpoint recorded on %s; the controller will restart this pod.\n", agentID)
+	return nil
+}
+
+// gitOutput runs a git command in dir and returns its trimmed output, or ""
+// when dir is not a repository or git fails.
+func gitOutput(dir string, args ...string) string {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
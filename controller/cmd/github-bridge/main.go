@@ -0,0 +1,308 @@
+// Command github-bridge is a standalone service that imports labeled GitHub
+// issues as task beads and reports agent progress back to GitHub, mirroring
+// the jira-bridge.
+//
+// It runs four subsystems:
+//   - GitHub webhook: issues opened/labeled → task bead creation; issues
+//     closed → bead closure
+//   - GitHub poller: periodic labeled-issue listing → task bead creation
+//     (reconciliation fallback for missed webhook deliveries)
+//   - GitHub sync: SSE subscription for bead updates → issue comments
+//     (progress notes, PR links, closure) and check runs on agent PRs
+//   - HTTP server: health/readiness endpoints and the webhook receiver
+//
+// It authenticates as a GitHub App installation. This service has ZERO K8s
+// dependencies and can run as a lightweight standalone container alongside
+// the gasboat controller.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/bridge"
+)
+
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+func main() {
+	cfg := parseConfig()
+
+	logger := setupLogger(cfg.logLevel)
+	logger.Info("starting github-bridge",
+		"version", version,
+		"commit", commit,
+		"beads_http", cfg.beadsHTTPAddr,
+		"github_api_url", cfg.apiURL,
+		"github_app_id", cfg.appID,
+		"github_repos", len(cfg.repos),
+		"github_label", cfg.label,
+		"github_webhook", cfg.webhookSecret != "",
+		"listen_addr", cfg.listenAddr)
+
+	privateKey, err := loadPrivateKey(cfg)
+	if err != nil {
+		logger.Error("failed to load GitHub App private key", "error", err)
+		os.Exit(1)
+	}
+	app, err := bridge.NewGitHubAppAuth(bridge.GitHubAppConfig{
+		AppID:          cfg.appID,
+		InstallationID: cfg.installationID,
+		PrivateKey:     privateKey,
+		BaseURL:        cfg.apiURL,
+	})
+	if err != nil {
+		logger.Error("failed to configure GitHub App auth", "error", err)
+		os.Exit(1)
+	}
+	github := bridge.NewGitHubAppClient(app, cfg.apiURL, logger)
+
+	// Create beads daemon HTTP client.
+	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: cfg.beadsHTTPAddr})
+	if err != nil {
+		logger.Error("failed to create beads daemon client", "error", err)
+		os.Exit(1)
+	}
+	defer daemon.Close()
+
+	// Register bead types, views, and context configs with the daemon.
+	if err := bridge.EnsureConfigs(context.Background(), daemon, logger); err != nil {
+		logger.Warn("failed to ensure beads configs (non-fatal)", "error", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	// State persistence for SSE last-event-ID.
+	state, err := bridge.NewStateManager(cfg.statePath)
+	if err != nil {
+		logger.Error("failed to load state", "path", cfg.statePath, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("state manager loaded", "path", cfg.statePath)
+
+	// GitHub sync: bead updates → issue comments and PR check runs (SSE), and
+	// issue closure → bead closure (webhook).
+	githubSync := bridge.NewGitHubSync(bridge.GitHubSyncConfig{
+		GitHub:    github,
+		Daemon:    daemon,
+		CheckName: cfg.checkName,
+		Logger:    logger,
+	})
+
+	// GitHub poller; also the ingestion path for webhook deliveries.
+	poller := bridge.NewGitHubPoller(github, daemon, bridge.GitHubPollerConfig{
+		Repos:        cfg.repos,
+		Label:        cfg.label,
+		PollInterval: cfg.pollInterval,
+		ProjectMap:   cfg.projectMap,
+		Logger:       logger,
+	})
+
+	// HTTP server with health endpoints.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok","version":"%s"}`, version)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok"}`)
+	})
+	if cfg.webhookSecret != "" {
+		mux.Handle("/webhooks/github", bridge.NewGitHubWebhook(poller, bridge.GitHubWebhookConfig{
+			Secret: cfg.webhookSecret,
+			Sync:   githubSync,
+			Logger: logger,
+		}))
+	}
+
+	srv := &http.Server{
+		Addr:              cfg.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("starting HTTP server", "addr", cfg.listenAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server failed", "error", err)
+		}
+	}()
+
+	// Start GitHub poller goroutine.
+	go func() {
+		if err := poller.Run(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("GitHub poller stopped", "error", err)
+		}
+	}()
+
+	// Create SSE event stream for GitHub sync-back.
+	sseStream := bridge.NewSSEStream(bridge.SSEStreamConfig{
+		BeadsHTTPAddr: cfg.beadsHTTPAddr,
+		Topics:        []string{"beads.bead.updated", "beads.bead.closed"},
+		Logger:        logger,
+		Dedup:         bridge.NewDedup(logger),
+		State:         state,
+	})
+	githubSync.RegisterHandlers(sseStream)
+
+	go func() {
+		if err := sseStream.Start(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("SSE event stream stopped", "error", err)
+		}
+	}()
+
+	logger.Info("github-bridge ready",
+		"repos", len(cfg.repos),
+		"poll_interval", cfg.pollInterval)
+
+	// Block until shutdown signal.
+	<-ctx.Done()
+	logger.Info("shutting down github-bridge")
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown error", "error", err)
+	}
+}
+
+// config holds parsed environment configuration for the github-bridge service.
+type config struct {
+	beadsHTTPAddr  string
+	apiURL         string
+	appID          int64
+	installationID int64
+	privateKey     string // inline PEM
+	privateKeyFile string // path to a PEM file
+	repos          []bridge.RepoRef
+	projectMap     map[string]string // "owner/repo" (lower) → boat project name
+	label          string
+	checkName      string
+	pollInterval   time.Duration
+	webhookSecret  string
+	listenAddr     string
+	logLevel       string
+	statePath      string
+}
+
+func parseConfig() *config {
+	// With webhooks delivering new issues, polling only reconciles missed
+	// deliveries and can run much less often.
+	webhookSecret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+	pollInterval := 5 * time.Minute
+	if webhookSecret != "" {
+		pollInterval = 15 * time.Minute
+	}
+	if v := os.Getenv("GITHUB_POLL_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			pollInterval = d
+		}
+	}
+	repos, projectMap := parseRepos(os.Getenv("GITHUB_REPOS"))
+
+	return &config{
+		beadsHTTPAddr:  envOrDefault("BEADS_HTTP_ADDR", "http://localhost:8080"),
+		apiURL:         os.Getenv("GITHUB_API_URL"),
+		appID:          envInt64("GITHUB_APP_ID"),
+		installationID: envInt64("GITHUB_APP_INSTALLATION_ID"),
+		privateKey:     os.Getenv("GITHUB_APP_PRIVATE_KEY"),
+		privateKeyFile: os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE"),
+		repos:          repos,
+		projectMap:     projectMap,
+		label:          os.Getenv("GITHUB_LABEL"),
+		checkName:      os.Getenv("GITHUB_CHECK_NAME"),
+		pollInterval:   pollInterval,
+		webhookSecret:  webhookSecret,
+		listenAddr:     envOrDefault("GITHUB_LISTEN_ADDR", ":8092"),
+		logLevel:       envOrDefault("LOG_LEVEL", "info"),
+		statePath:      envOrDefault("STATE_PATH", "/tmp/github-bridge-state.json"),
+	}
+}
+
+// loadPrivateKey returns the App's PEM private key from the file or the
+// inline env var.
+func loadPrivateKey(cfg *config) ([]byte, error) {
+	switch {
+	case cfg.privateKeyFile != "":
+		return os.ReadFile(cfg.privateKeyFile)
+	case cfg.privateKey != "":
+		return []byte(cfg.privateKey), nil
+	}
+	return nil, errors.New("set GITHUB_APP_PRIVATE_KEY or GITHUB_APP_PRIVATE_KEY_FILE")
+}
+
+// parseRepos parses the GITHUB_REPOS env var: comma-separated entries of the
+// form {owner}/{repo} or {owner}/{repo}={project_name}. Repos without a
+// project map to their repo name.
+//
+// Example: "groblegark/gasboat,org/web-app=monorepo"
+// Result:  [groblegark/gasboat org/web-app], {"org/web-app": "monorepo"}
+func parseRepos(s string) ([]bridge.RepoRef, map[string]string) {
+	var repos []bridge.RepoRef
+	projects := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, project, _ := strings.Cut(entry, "=")
+		owner, repo, ok := strings.Cut(strings.TrimSpace(name), "/")
+		if !ok || owner == "" || repo == "" {
+			continue
+		}
+		ref := bridge.RepoRef{Owner: owner, Repo: repo}
+		repos = append(repos, ref)
+		if project = strings.TrimSpace(project); project != "" {
+			projects[strings.ToLower(ref.String())] = project
+		}
+	}
+	return repos, projects
+}
+
+// envInt64 parses an int64 env var; unset or invalid values return 0.
+func envInt64(key string) int64 {
+	v, _ := strconv.ParseInt(os.Getenv(key), 10, 64)
+	return v
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func setupLogger(level string) *slog.Logger {
+	var logLevel slog.Level
+	switch level {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	default:
+		logLevel = slog.LevelInfo
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+}
+
+func init() {
+	if v := os.Getenv("VERSION"); v != "" {
+		version = v
+	}
+}
@@ -0,0 +1,258 @@
+// Command incident-bridge is a standalone service that turns PagerDuty or
+// Opsgenie incidents into high-priority task beads for the on-call ops agent.
+//
+// It runs three subsystems:
+//   - Incident webhook: incident triggered → task bead assigned to the ops
+//     agent; incident resolved upstream → bead closure
+//   - Incident sync: SSE subscription for bead updates → incident timeline
+//     notes (agent progress, MR links); bead closed → incident resolved
+//   - HTTP server: health/readiness endpoints and the webhook receiver
+//
+// This service has ZERO K8s dependencies and can run as a lightweight
+// standalone container alongside the gasboat controller.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/bridge"
+)
+
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+func main() {
+	cfg := parseConfig()
+
+	logger := setupLogger(cfg.logLevel)
+	logger.Info("starting incident-bridge",
+		"version", version,
+		"commit", commit,
+		"beads_http", cfg.beadsHTTPAddr,
+		"provider", cfg.provider,
+		"assignee", cfg.assignee,
+		"project", cfg.project,
+		"listen_addr", cfg.listenAddr)
+
+	provider, err := newProvider(cfg)
+	if err != nil {
+		logger.Error("failed to configure incident provider", "error", err)
+		os.Exit(1)
+	}
+
+	// Create beads daemon HTTP client.
+	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: cfg.beadsHTTPAddr})
+	if err != nil {
+		logger.Error("failed to create beads daemon client", "error", err)
+		os.Exit(1)
+	}
+	defer daemon.Close()
+
+	// Register bead types, views, and context configs with the daemon.
+	if err := bridge.EnsureConfigs(context.Background(), daemon, logger); err != nil {
+		logger.Warn("failed to ensure beads configs (non-fatal)", "error", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	// State persistence for SSE last-event-ID.
+	state, err := bridge.NewStateManager(cfg.statePath)
+	if err != nil {
+		logger.Error("failed to load state", "path", cfg.statePath, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("state manager loaded", "path", cfg.statePath)
+
+	incidents := bridge.NewIncidentBridge(bridge.IncidentBridgeConfig{
+		Provider:        provider,
+		Daemon:          daemon,
+		Assignee:        cfg.assignee,
+		Project:         cfg.project,
+		ServiceProjects: cfg.serviceProjects,
+		Logger:          logger,
+	})
+	incidents.CatchUp(ctx)
+
+	// HTTP server with health endpoints.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok","version":"%s"}`, version)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok"}`)
+	})
+	mux.Handle("/webhooks/"+provider.Name(), incidents)
+
+	srv := &http.Server{
+		Addr:              cfg.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("starting HTTP server", "addr", cfg.listenAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server failed", "error", err)
+		}
+	}()
+
+	// Create SSE event stream for incident sync-back.
+	sseStream := bridge.NewSSEStream(bridge.SSEStreamConfig{
+		BeadsHTTPAddr: cfg.beadsHTTPAddr,
+		Topics:        []string{"beads.bead.updated", "beads.bead.closed"},
+		Logger:        logger,
+		Dedup:         bridge.NewDedup(logger),
+		State:         state,
+	})
+	incidents.RegisterHandlers(sseStream)
+
+	go func() {
+		if err := sseStream.Start(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("SSE event stream stopped", "error", err)
+		}
+	}()
+
+	logger.Info("incident-bridge ready", "webhook", "/webhooks/"+provider.Name())
+
+	// Block until shutdown signal.
+	<-ctx.Done()
+	logger.Info("shutting down incident-bridge")
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown error", "error", err)
+	}
+}
+
+// config holds parsed environment configuration for the incident-bridge service.
+type config struct {
+	beadsHTTPAddr   string
+	provider        string // "pagerduty" or "opsgenie"
+	assignee        string
+	project         string
+	serviceProjects map[string]string // provider service (lower) → boat project name
+
+	pagerDutyToken  string
+	pagerDutyFrom   string
+	pagerDutySecret string
+	pagerDutyURL    string
+
+	opsgenieKey    string
+	opsgenieToken  string
+	opsgenieURL    string
+	opsgenieAppURL string
+
+	listenAddr string
+	logLevel   string
+	statePath  string
+}
+
+func parseConfig() *config {
+	return &config{
+		beadsHTTPAddr:   envOrDefault("BEADS_HTTP_ADDR", "http://localhost:8080"),
+		provider:        strings.ToLower(envOrDefault("INCIDENT_PROVIDER", "pagerduty")),
+		assignee:        envOrDefault("INCIDENT_ASSIGNEE", "ops"),
+		project:         envOrDefault("INCIDENT_PROJECT", "ops"),
+		serviceProjects: parseServiceProjects(os.Getenv("INCIDENT_SERVICE_PROJECTS")),
+		pagerDutyToken:  os.Getenv("PAGERDUTY_API_TOKEN"),
+		pagerDutyFrom:   os.Getenv("PAGERDUTY_FROM_EMAIL"),
+		pagerDutySecret: os.Getenv("PAGERDUTY_WEBHOOK_SECRET"),
+		pagerDutyURL:    os.Getenv("PAGERDUTY_API_URL"),
+		opsgenieKey:     os.Getenv("OPSGENIE_API_KEY"),
+		opsgenieToken:   os.Getenv("OPSGENIE_WEBHOOK_TOKEN"),
+		opsgenieURL:     os.Getenv("OPSGENIE_API_URL"),
+		opsgenieAppURL:  os.Getenv("OPSGENIE_APP_URL"),
+		listenAddr:      envOrDefault("INCIDENT_LISTEN_ADDR", ":8093"),
+		logLevel:        envOrDefault("LOG_LEVEL", "info"),
+		statePath:       envOrDefault("STATE_PATH", "/tmp/incident-bridge-state.json"),
+	}
+}
+
+// newProvider builds the configured incident provider, checking that its
+// required settings are present.
+func newProvider(cfg *config) (bridge.IncidentProvider, error) {
+	switch cfg.provider {
+	case "pagerduty":
+		if cfg.pagerDutyToken == "" || cfg.pagerDutyFrom == "" || cfg.pagerDutySecret == "" {
+			return nil, errors.New("PAGERDUTY_API_TOKEN, PAGERDUTY_FROM_EMAIL, and PAGERDUTY_WEBHOOK_SECRET are required")
+		}
+		return bridge.NewPagerDutyProvider(bridge.PagerDutyConfig{
+			APIToken:      cfg.pagerDutyToken,
+			From:          cfg.pagerDutyFrom,
+			WebhookSecret: cfg.pagerDutySecret,
+			BaseURL:       cfg.pagerDutyURL,
+		}), nil
+	case "opsgenie":
+		if cfg.opsgenieKey == "" || cfg.opsgenieToken == "" {
+			return nil, errors.New("OPSGENIE_API_KEY and OPSGENIE_WEBHOOK_TOKEN are required")
+		}
+		return bridge.NewOpsgenieProvider(bridge.OpsgenieConfig{
+			APIKey:       cfg.opsgenieKey,
+			WebhookToken: cfg.opsgenieToken,
+			BaseURL:      cfg.opsgenieURL,
+			AppURL:       cfg.opsgenieAppURL,
+		}), nil
+	}
+	return nil, fmt.Errorf("unknown INCIDENT_PROVIDER %q (want pagerduty or opsgenie)", cfg.provider)
+}
+
+// parseServiceProjects parses the INCIDENT_SERVICE_PROJECTS env var:
+// comma-separated {service}={project_name} pairs routing incidents from a
+// provider service to a boat project.
+//
+// Example: "Checkout API=shop,Billing=billing"
+// Result:  {"checkout api": "shop", "billing": "billing"}
+func parseServiceProjects(s string) map[string]string {
+	projects := make(map[string]string)
+	for _, entry := range strings.Split(s, ",") {
+		service, project, ok := strings.Cut(entry, "=")
+		service, project = strings.TrimSpace(service), strings.TrimSpace(project)
+		if ok && service != "" && project != "" {
+			projects[strings.ToLower(service)] = project
+		}
+	}
+	return projects
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func setupLogger(level string) *slog.Logger {
+	var logLevel slog.Level
+	switch level {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	default:
+		logLevel = slog.LevelInfo
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+}
+
+func init() {
+	if v := os.Getenv("VERSION"); v != "" {
+		version = v
+	}
+}
@@ -2,10 +2,20 @@
 // creates task beads in the beads daemon, and syncs bead updates (MR links,
 // closures) back to JIRA as comments, remote links, and transitions.
 //
-// It runs three subsystems:
-//   - JIRA poller: periodic JIRA search → task bead creation
+// It runs four subsystems:
+//   - JIRA webhook: issue created/updated webhooks → task bead creation
+//   - JIRA poller: periodic JIRA search → task bead creation (reconciliation
+//     fallback when webhooks are enabled)
 //   - JIRA sync: SSE subscription for bead updates → JIRA sync-back
-//   - HTTP server: health/readiness endpoints
+//   - HTTP server: health/readiness and metrics endpoints and the webhook
+//     receiver
+//
+// Flags:
+//   - --dry-run: print the beads and JIRA changes that would be made instead
+//     of writing them
+//   - --backfill SINCE: import issues created since SINCE (a date such as
+//     2025-01-31 or a duration such as 720h) once and exit; combine with
+//     --dry-run to preview an onboarding
 //
 // This service has ZERO K8s dependencies and can run as a lightweight
 // standalone container alongside the gasboat controller.
@@ -14,11 +24,14 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -34,6 +47,10 @@ var (
 
 func main() {
 	cfg := parseConfig()
+	if err := parseFlags(cfg, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "jira-bridge:", err)
+		os.Exit(2)
+	}
 
 	logger := setupLogger(cfg.logLevel)
 	logger.Info("starting jira-bridge",
@@ -43,7 +60,12 @@ func main() {
 		"jira_base_url", cfg.jiraBaseURL,
 		"jira_projects", cfg.jiraProjects,
 		"jira_disable_transitions", cfg.jiraDisableTransitions,
-		"listen_addr", cfg.listenAddr)
+		"jira_webhook", cfg.jiraWebhookSecret != "",
+		"jira_comment_sync", cfg.jiraCommentProjects,
+		"jira_upload_reports", cfg.jiraUploadReports,
+		"listen_addr", cfg.listenAddr,
+		"dry_run", cfg.dryRun,
+		"backfill_since", cfg.backfillSince)
 
 	// Create beads daemon HTTP client.
 	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: cfg.beadsHTTPAddr})
@@ -53,40 +75,126 @@ func main() {
 	}
 	defer daemon.Close()
 
-	// Register bead types, views, and context configs with the daemon.
-	if err := bridge.EnsureConfigs(context.Background(), daemon, logger); err != nil {
-		logger.Warn("failed to ensure beads configs (non-fatal)", "error", err)
+	// In dry-run mode reads go to the daemon and writes are printed.
+	var (
+		beadClient bridge.JiraBeadClient = daemon
+		syncClient bridge.JiraSyncClient = daemon
+		jiraDryRun io.Writer
+	)
+	if cfg.dryRun {
+		dry := bridge.NewJiraDryRunDaemon(daemon, os.Stdout)
+		beadClient, syncClient, jiraDryRun = dry, dry, os.Stdout
+	} else {
+		// Register bead types, views, and context configs with the daemon.
+		if err := bridge.EnsureConfigs(context.Background(), daemon, logger); err != nil {
+			logger.Warn("failed to ensure beads configs (non-fatal)", "error", err)
+		}
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
 	defer cancel()
 
-	// State persistence for SSE last-event-ID.
-	state, err := bridge.NewStateManager(cfg.statePath)
+	// State persistence for SSE last-event-ID. Dry runs keep their own file
+	// so they never advance the live service's position.
+	statePath := cfg.statePath
+	if cfg.dryRun {
+		statePath += ".dry-run"
+	}
+	state, err := bridge.NewStateManager(statePath)
 	if err != nil {
-		logger.Error("failed to load state", "path", cfg.statePath, "error", err)
+		logger.Error("failed to load state", "path", statePath, "error", err)
 		os.Exit(1)
 	}
-	logger.Info("state manager loaded", "path", cfg.statePath)
+	logger.Info("state manager loaded", "path", statePath)
 
 	// Create JIRA client.
 	jiraClient := bridge.NewJiraClient(bridge.JiraClientConfig{
-		BaseURL:  cfg.jiraBaseURL,
-		Email:    cfg.jiraEmail,
-		APIToken: cfg.jiraAPIToken,
-		Logger:   logger,
+		BaseURL:           cfg.jiraBaseURL,
+		Email:             cfg.jiraEmail,
+		APIToken:          cfg.jiraAPIToken,
+		RequestsPerSecond: cfg.jiraRateLimit,
+		Burst:             cfg.jiraBurst,
+		MaxConcurrent:     cfg.jiraMaxConcurrent,
+		DryRun:            jiraDryRun,
+		Logger:            logger,
 	})
 
+	// JIRA sync: bead status → JIRA transitions (SSE) and JIRA status → bead
+	// status (webhook), both following the configured workflow.
+	workflow, err := loadWorkflow(cfg)
+	if err != nil {
+		logger.Error("failed to load JIRA workflow", "error", err)
+		os.Exit(1)
+	}
+	assignment, err := loadAssignment(cfg)
+	if err != nil {
+		logger.Error("failed to load JIRA assignment mapping", "error", err)
+		os.Exit(1)
+	}
+	jiraSync := bridge.NewJiraSync(bridge.JiraSyncConfig{
+		Jira:               jiraClient,
+		Daemon:             syncClient,
+		Workflow:           workflow,
+		Assignment:         assignment,
+		CommentProjects:    cfg.jiraCommentProjects,
+		Logger:             logger,
+		DisableTransitions: cfg.jiraDisableTransitions,
+		UploadReports:      cfg.jiraUploadReports,
+	})
+
+	// JIRA poller; also the ingestion path for webhook deliveries.
+	poller := bridge.NewJiraPoller(jiraClient, beadClient, bridge.JiraPollerConfig{
+		Projects:     cfg.jiraProjects,
+		Statuses:     cfg.jiraStatuses,
+		IssueTypes:   cfg.jiraIssueTypes,
+		ProjectMap:   cfg.jiraProjectMap,
+		PollInterval: cfg.jiraPollInterval,
+		ConfigClient: daemon,
+		ConfigKey:    cfg.jiraProjectsConfigKey,
+		Logger:       logger,
+	})
+
+	if !cfg.backfillSince.IsZero() {
+		res, err := poller.Backfill(ctx, cfg.backfillSince)
+		logger.Info("JIRA backfill complete",
+			"since", cfg.backfillSince,
+			"found", res.Found,
+			"created", res.Created,
+			"skipped", res.Skipped,
+			"failed", res.Failed,
+			"dry_run", cfg.dryRun)
+		if err != nil {
+			logger.Error("JIRA backfill failed", "error", err)
+			os.Exit(1)
+		}
+		if res.Failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// HTTP server with health endpoints.
 	mux := http.NewServeMux()
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, `{"status":"ok","version":"%s"}`, version)
 	})
+	// JIRA client metrics (Prometheus text format).
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		jiraClient.WriteMetrics(w)
+	})
 	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintf(w, `{"status":"ok"}`)
 	})
+	if cfg.jiraWebhookSecret != "" {
+		mux.Handle("/webhooks/jira", bridge.NewJiraWebhook(poller, bridge.JiraWebhookConfig{
+			Secret: cfg.jiraWebhookSecret,
+			Sync:   jiraSync,
+			Logger: logger,
+		}))
+	}
 
 	srv := &http.Server{
 		Addr:              cfg.listenAddr,
@@ -101,14 +209,6 @@ func main() {
 	}()
 
 	// Start JIRA poller goroutine.
-	poller := bridge.NewJiraPoller(jiraClient, daemon, bridge.JiraPollerConfig{
-		Projects:     cfg.jiraProjects,
-		Statuses:     cfg.jiraStatuses,
-		IssueTypes:   cfg.jiraIssueTypes,
-		ProjectMap:   cfg.jiraProjectMap,
-		PollInterval: cfg.jiraPollInterval,
-		Logger:       logger,
-	})
 	go func() {
 		if err := poller.Run(ctx); err != nil && ctx.Err() == nil {
 			logger.Error("JIRA poller stopped", "error", err)
@@ -128,11 +228,6 @@ func main() {
 	})
 
 	// Register JIRA sync handler on the SSE stream.
-	jiraSync := bridge.NewJiraSync(bridge.JiraSyncConfig{
-		Jira:               jiraClient,
-		Logger:             logger,
-		DisableTransitions: cfg.jiraDisableTransitions,
-	})
 	jiraSync.RegisterHandlers(sseStream)
 
 	// Start the SSE stream.
@@ -160,23 +255,42 @@ func main() {
 
 // config holds parsed environment configuration for the jira-bridge service.
 type config struct {
-	beadsHTTPAddr    string
-	jiraBaseURL      string
-	jiraEmail        string
-	jiraAPIToken     string
-	jiraProjects     []string
-	jiraStatuses     []string
-	jiraIssueTypes   []string
+	beadsHTTPAddr          string
+	jiraBaseURL            string
+	jiraEmail              string
+	jiraAPIToken           string
+	jiraProjects           []string
+	jiraStatuses           []string
+	jiraIssueTypes         []string
 	jiraProjectMap         map[string]string // JIRA prefix (upper) → boat project name
 	jiraPollInterval       time.Duration
 	jiraDisableTransitions bool
+	jiraWebhookSecret      string
+	jiraWorkflow           string // inline JSON/YAML status mapping
+	jiraWorkflowFile       string // path to a JSON/YAML status mapping
+	jiraAssignment         string // inline JSON/YAML account mapping
+	jiraAssignmentFile     string // path to a JSON/YAML account mapping
+	jiraCommentProjects    []string
+	jiraProjectsConfigKey  string  // daemon config key with per-project JQL and field mapping
+	jiraRateLimit          float64 // JIRA requests per second (0 = client default)
+	jiraBurst              int
+	jiraMaxConcurrent      int
+	jiraUploadReports      bool
 	listenAddr             string
 	logLevel               string
 	statePath              string
+	dryRun                 bool      // print writes instead of performing them
+	backfillSince          time.Time // non-zero: import issues created since, then exit
 }
 
 func parseConfig() *config {
+	// With webhooks delivering new issues, polling only reconciles missed
+	// deliveries and can run much less often.
+	webhookSecret := os.Getenv("JIRA_WEBHOOK_SECRET")
 	pollInterval := 60 * time.Second
+	if webhookSecret != "" {
+		pollInterval = 15 * time.Minute
+	}
 	if v := os.Getenv("JIRA_POLL_INTERVAL"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
 			pollInterval = d
@@ -184,6 +298,7 @@ func parseConfig() *config {
 	}
 
 	disableTransitions := os.Getenv("JIRA_DISABLE_TRANSITIONS")
+	uploadReports := os.Getenv("JIRA_UPLOAD_REPORTS")
 
 	return &config{
 		beadsHTTPAddr:          envOrDefault("BEADS_HTTP_ADDR", "http://localhost:8080"),
@@ -196,12 +311,103 @@ func parseConfig() *config {
 		jiraProjectMap:         parseBoatProjects(os.Getenv("BOAT_PROJECTS")),
 		jiraPollInterval:       pollInterval,
 		jiraDisableTransitions: disableTransitions == "true" || disableTransitions == "1",
+		jiraWebhookSecret:      webhookSecret,
+		jiraWorkflow:           os.Getenv("JIRA_WORKFLOW"),
+		jiraWorkflowFile:       os.Getenv("JIRA_WORKFLOW_FILE"),
+		jiraAssignment:         os.Getenv("JIRA_ASSIGNMENT"),
+		jiraAssignmentFile:     os.Getenv("JIRA_ASSIGNMENT_FILE"),
+		jiraCommentProjects:    splitCSV(os.Getenv("JIRA_COMMENT_SYNC")),
+		jiraProjectsConfigKey:  os.Getenv("JIRA_PROJECTS_CONFIG_KEY"),
+		jiraRateLimit:          envFloat("JIRA_RATE_LIMIT"),
+		jiraBurst:              envInt("JIRA_RATE_BURST"),
+		jiraMaxConcurrent:      envInt("JIRA_MAX_CONCURRENT"),
+		jiraUploadReports:      uploadReports == "true" || uploadReports == "1",
 		listenAddr:             envOrDefault("JIRA_LISTEN_ADDR", ":8091"),
 		logLevel:               envOrDefault("LOG_LEVEL", "info"),
 		statePath:              envOrDefault("STATE_PATH", "/tmp/jira-bridge-state.json"),
 	}
 }
 
+// parseFlags applies command-line flags on top of the environment config.
+func parseFlags(cfg *config, args []string) error {
+	fs := flag.NewFlagSet("jira-bridge", flag.ContinueOnError)
+	fs.BoolVar(&cfg.dryRun, "dry-run", false,
+		"print the beads and JIRA changes that would be made without writing them")
+	backfill := fs.String("backfill", "",
+		"import issues created since `SINCE` (date like 2025-01-31 or duration like 720h) once and exit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *backfill != "" {
+		since, err := parseSince(*backfill, time.Now())
+		if err != nil {
+			return err
+		}
+		cfg.backfillSince = since
+	}
+	return nil
+}
+
+// parseSince parses a backfill bound given as a date (2006-01-02), an
+// RFC 3339 timestamp, or a duration before now (e.g. 720h).
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --backfill %q: want a date (2006-01-02), RFC 3339 time, or duration (720h)", s)
+}
+
+// loadWorkflow returns the configured status mapping, or nil to use
+// bridge.DefaultJiraWorkflow.
+func loadWorkflow(cfg *config) (*bridge.JiraWorkflow, error) {
+	var (
+		wf  bridge.JiraWorkflow
+		err error
+	)
+	switch {
+	case cfg.jiraWorkflowFile != "":
+		wf, err = bridge.LoadJiraWorkflow(cfg.jiraWorkflowFile)
+	case cfg.jiraWorkflow != "":
+		wf, err = bridge.ParseJiraWorkflow([]byte(cfg.jiraWorkflow))
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wf, nil
+}
+
+// loadAssignment returns the configured JIRA account mapping; the zero value
+// disables assignment sync.
+func loadAssignment(cfg *config) (bridge.JiraAssignment, error) {
+	switch {
+	case cfg.jiraAssignmentFile != "":
+		return bridge.LoadJiraAssignment(cfg.jiraAssignmentFile)
+	case cfg.jiraAssignment != "":
+		return bridge.ParseJiraAssignment([]byte(cfg.jiraAssignment))
+	}
+	return bridge.JiraAssignment{}, nil
+}
+
+// envFloat parses a float env var; unset or invalid values return 0.
+func envFloat(key string) float64 {
+	v, _ := strconv.ParseFloat(os.Getenv(key), 64)
+	return v
+}
+
+// envInt parses an int env var; unset or invalid values return 0.
+func envInt(key string) int {
+	v, _ := strconv.Atoi(os.Getenv(key))
+	return v
+}
+
 func envOrDefault(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -0,0 +1,261 @@
+// Command linear-bridge is a standalone service that imports Linear issues
+// as task beads and syncs agent results back to Linear, mirroring the
+// jira-bridge.
+//
+// It runs three subsystems:
+//   - Linear poller: periodic GraphQL query → task bead creation for issues
+//     in the selected workflow states
+//   - Linear sync: SSE subscription for bead updates → MR link attachments,
+//     comments, and completing the issue when its bead closes
+//   - HTTP server: health/readiness endpoints
+//
+// This service has ZERO K8s dependencies and can run as a lightweight
+// standalone container alongside the gasboat controller.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/bridge"
+)
+
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
+func main() {
+	cfg := parseConfig()
+
+	logger := setupLogger(cfg.logLevel)
+	logger.Info("starting linear-bridge",
+		"version", version,
+		"commit", commit,
+		"beads_http", cfg.beadsHTTPAddr,
+		"linear_teams", cfg.teams,
+		"linear_states", cfg.states,
+		"listen_addr", cfg.listenAddr)
+
+	if cfg.apiKey == "" || len(cfg.teams) == 0 {
+		logger.Error("LINEAR_API_KEY and LINEAR_TEAMS are required")
+		os.Exit(1)
+	}
+	linear := bridge.NewLinearClient(bridge.LinearClientConfig{
+		APIKey: cfg.apiKey,
+		URL:    cfg.apiURL,
+		Logger: logger,
+	})
+
+	// Create beads daemon HTTP client.
+	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: cfg.beadsHTTPAddr})
+	if err != nil {
+		logger.Error("failed to create beads daemon client", "error", err)
+		os.Exit(1)
+	}
+	defer daemon.Close()
+
+	// Register bead types, views, and context configs with the daemon.
+	if err := bridge.EnsureConfigs(context.Background(), daemon, logger); err != nil {
+		logger.Warn("failed to ensure beads configs (non-fatal)", "error", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	// State persistence for SSE last-event-ID.
+	state, err := bridge.NewStateManager(cfg.statePath)
+	if err != nil {
+		logger.Error("failed to load state", "path", cfg.statePath, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("state manager loaded", "path", cfg.statePath)
+
+	poller := bridge.NewLinearPoller(linear, daemon, bridge.LinearPollerConfig{
+		Teams:        cfg.teams,
+		States:       cfg.states,
+		PollInterval: cfg.pollInterval,
+		ProjectMap:   cfg.projectMap,
+		Logger:       logger,
+	})
+
+	// HTTP server with health endpoints.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok","version":"%s"}`, version)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"status":"ok"}`)
+	})
+
+	srv := &http.Server{
+		Addr:              cfg.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		logger.Info("starting HTTP server", "addr", cfg.listenAddr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server failed", "error", err)
+		}
+	}()
+
+	// Start Linear poller goroutine.
+	go func() {
+		if err := poller.Run(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Linear poller stopped", "error", err)
+		}
+	}()
+
+	// Create SSE event stream for Linear sync-back.
+	sseStream := bridge.NewSSEStream(bridge.SSEStreamConfig{
+		BeadsHTTPAddr: cfg.beadsHTTPAddr,
+		Topics:        []string{"beads.bead.updated", "beads.bead.closed"},
+		Logger:        logger,
+		Dedup:         bridge.NewDedup(logger),
+		State:         state,
+	})
+	linearSync := bridge.NewLinearSync(bridge.LinearSyncConfig{
+		Linear:             linear,
+		Logger:             logger,
+		DisableTransitions: cfg.disableTransitions,
+	})
+	linearSync.RegisterHandlers(sseStream)
+
+	go func() {
+		if err := sseStream.Start(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("SSE event stream stopped", "error", err)
+		}
+	}()
+
+	logger.Info("linear-bridge ready",
+		"teams", len(cfg.teams),
+		"poll_interval", cfg.pollInterval)
+
+	// Block until shutdown signal.
+	<-ctx.Done()
+	logger.Info("shutting down linear-bridge")
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("HTTP server shutdown error", "error", err)
+	}
+}
+
+// config holds parsed environment configuration for the linear-bridge service.
+type config struct {
+	beadsHTTPAddr      string
+	apiKey             string
+	apiURL             string
+	teams              []string
+	projectMap         map[string]string // team key (upper) → boat project name
+	states             []string
+	pollInterval       time.Duration
+	disableTransitions bool
+	listenAddr         string
+	logLevel           string
+	statePath          string
+}
+
+func parseConfig() *config {
+	pollInterval := 60 * time.Second
+	if v := os.Getenv("LINEAR_POLL_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			pollInterval = d
+		}
+	}
+	teams, projectMap := parseTeams(os.Getenv("LINEAR_TEAMS"))
+	disableTransitions := os.Getenv("LINEAR_DISABLE_TRANSITIONS")
+
+	return &config{
+		beadsHTTPAddr:      envOrDefault("BEADS_HTTP_ADDR", "http://localhost:8080"),
+		apiKey:             os.Getenv("LINEAR_API_KEY"),
+		apiURL:             os.Getenv("LINEAR_API_URL"),
+		teams:              teams,
+		projectMap:         projectMap,
+		states:             splitCSV(envOrDefault("LINEAR_STATES", "Todo")),
+		pollInterval:       pollInterval,
+		disableTransitions: disableTransitions == "true" || disableTransitions == "1",
+		listenAddr:         envOrDefault("LINEAR_LISTEN_ADDR", ":8094"),
+		logLevel:           envOrDefault("LOG_LEVEL", "info"),
+		statePath:          envOrDefault("STATE_PATH", "/tmp/linear-bridge-state.json"),
+	}
+}
+
+// parseTeams parses the LINEAR_TEAMS env var: comma-separated entries of the
+// form {team_key} or {team_key}={project_name}. Teams without a project map
+// to their lowercased key.
+//
+// Example: "ENG=gasboat,OPS"
+// Result:  [ENG OPS], {"ENG": "gasboat"}
+func parseTeams(s string) ([]string, map[string]string) {
+	var teams []string
+	projects := make(map[string]string)
+	for _, entry := range splitCSV(s) {
+		team, project, _ := strings.Cut(entry, "=")
+		team = strings.ToUpper(strings.TrimSpace(team))
+		if team == "" {
+			continue
+		}
+		teams = append(teams, team)
+		if project = strings.TrimSpace(project); project != "" {
+			projects[team] = project
+		}
+	}
+	return teams, projects
+}
+
+func splitCSV(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func setupLogger(level string) *slog.Logger {
+	var logLevel slog.Level
+	switch level {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	default:
+		logLevel = slog.LevelInfo
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
+}
+
+func init() {
+	if v := os.Getenv("VERSION"); v != "" {
+		version = v
+	}
+}
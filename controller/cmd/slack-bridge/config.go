@@ -0,0 +1,246 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/bridge"
+)
+
+// config holds parsed environment configuration for the slack-bridge service.
+type config struct {
+	beadsHTTPAddr      string
+	slackBotToken      string
+	slackAppToken      string
+	slackSigningSecret string
+	slackChannel       string
+	listenAddr         string
+	logLevel           string
+	statePath          string
+	debug              bool
+
+	// Drop SSE bead events with fields unknown to the event schema.
+	strictEvents bool
+
+	// State backend: "file" (default, STATE_PATH), "sqlite" or "redis".
+	stateBackend       string
+	stateSQLitePath    string
+	stateRedisAddr     string
+	stateRedisPassword string
+	stateRedisDB       int
+	stateRedisKey      string
+
+	// Leader election for multi-replica deployments.
+	leaderElection bool
+	leaderKey      string
+	identity       string
+
+	// Threading
+	threadingMode string
+
+	// Notification template overrides (<name>.tmpl files, e.g. a ConfigMap).
+	templatesDir string
+
+	// Reaction shortcuts: "emoji=option,..." (number emoji always map to indexes).
+	reactionShortcuts string
+
+	// Locale for user-facing strings: workspace default and per-channel overrides.
+	locale         string
+	channelLocales string
+
+	// Notification severity routes (JSON) and digest interval (zero = default).
+	severityRoutes string
+	digestInterval time.Duration
+
+	// Dashboard
+	dashboardEnabled  bool
+	dashboardChannel  string
+	dashboardInterval time.Duration
+
+	// Dashboard trend charts (zero interval/days = defaults).
+	dashboardCharts         bool
+	dashboardChartsInterval time.Duration
+	dashboardChartsDays     int
+
+	// Agent thread compaction (zero durations/count = defaults).
+	threadCompaction         bool
+	threadCompactionAge      time.Duration
+	threadCompactionInterval time.Duration
+	threadCompactionMin      int
+
+	// Decision deadlines (zero = defaults / no default deadline).
+	decisionSLAWarnAt       float64
+	decisionDefaultDeadline time.Duration
+
+	// Minimum time between nudges to one agent (zero = default).
+	nudgeCooldown time.Duration
+
+	// Deep links in jack notifications.
+	beadURL      string // bead page URL with an {id} placeholder
+	dashboardURL string
+
+	// Coop terminal links through the controller's proxy.
+	coopTokenClientToken string // bearer token for the controller's /coop-token
+	coopProxyURL         string // public base URL of the controller's /coop/; empty = controllerURL
+
+	spawnPreviewToken string // bearer token for the controller's /spawn-preview
+	agentLogsToken    string // bearer token for the controller's /agent-logs
+
+	// GitHub /unreleased
+	githubToken   string
+	repos         []bridge.RepoRef
+	controllerURL string
+}
+
+func parseConfig() *config {
+	dashInterval := 15 * time.Second
+	if v := os.Getenv("SLACK_DASHBOARD_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			dashInterval = d
+		}
+	}
+
+	dashChannel := os.Getenv("SLACK_DASHBOARD_CHANNEL")
+	dashEnabled := os.Getenv("SLACK_DASHBOARD") == "true"
+	// Auto-enable if channel is set.
+	if dashChannel != "" && os.Getenv("SLACK_DASHBOARD") == "" {
+		dashEnabled = true
+	}
+
+	var compactAge, compactInterval time.Duration
+	if v := os.Getenv("SLACK_THREAD_COMPACTION_AGE"); v != "" {
+		compactAge, _ = time.ParseDuration(v)
+	}
+	if v := os.Getenv("SLACK_THREAD_COMPACTION_INTERVAL"); v != "" {
+		compactInterval, _ = time.ParseDuration(v)
+	}
+	compactMin, _ := strconv.Atoi(os.Getenv("SLACK_THREAD_COMPACTION_MIN"))
+
+	var chartsInterval time.Duration
+	if v := os.Getenv("SLACK_DASHBOARD_CHARTS_INTERVAL"); v != "" {
+		chartsInterval, _ = time.ParseDuration(v)
+	}
+	chartsDays, _ := strconv.Atoi(os.Getenv("SLACK_DASHBOARD_CHARTS_DAYS"))
+
+	slaWarnAt, _ := strconv.ParseFloat(os.Getenv("SLACK_DECISION_SLA_WARN_AT"), 64)
+	var defaultDeadline time.Duration
+	if v := os.Getenv("SLACK_DECISION_DEFAULT_DEADLINE"); v != "" {
+		defaultDeadline, _ = time.ParseDuration(v)
+	}
+
+	var digestInterval time.Duration
+	if v := os.Getenv("SLACK_DIGEST_INTERVAL"); v != "" {
+		digestInterval, _ = time.ParseDuration(v)
+	}
+
+	var nudgeCooldown time.Duration
+	if v := os.Getenv("NUDGE_COOLDOWN"); v != "" {
+		nudgeCooldown, _ = time.ParseDuration(v)
+	}
+
+	threadingMode := os.Getenv("SLACK_THREADING_MODE")
+	if threadingMode == "" {
+		threadingMode = "agent"
+	}
+
+	redisDB, _ := strconv.Atoi(os.Getenv("STATE_REDIS_DB"))
+
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+
+	repos := parseRepoList(envOrDefault("UNRELEASED_REPOS", "groblegark/gasboat,groblegark/kbeads,groblegark/coop"))
+
+	return &config{
+		beadsHTTPAddr:      envOrDefault("BEADS_HTTP_ADDR", "http://localhost:8080"),
+		slackBotToken:      os.Getenv("SLACK_BOT_TOKEN"),
+		slackAppToken:      os.Getenv("SLACK_APP_TOKEN"),
+		slackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+		slackChannel:       os.Getenv("SLACK_CHANNEL"),
+		listenAddr:         envOrDefault("SLACK_LISTEN_ADDR", ":8090"),
+		logLevel:           envOrDefault("LOG_LEVEL", "info"),
+		statePath:          envOrDefault("STATE_PATH", "/tmp/slack-bridge-state.json"),
+		debug:              os.Getenv("DEBUG") == "true" || os.Getenv("LOG_LEVEL") == "debug",
+		strictEvents:       os.Getenv("STRICT_EVENT_SCHEMA") == "true",
+
+		stateBackend:       envOrDefault("STATE_BACKEND", "file"),
+		stateSQLitePath:    envOrDefault("STATE_SQLITE_PATH", "/tmp/slack-bridge-state.db"),
+		stateRedisAddr:     envOrDefault("STATE_REDIS_ADDR", "localhost:6379"),
+		stateRedisPassword: os.Getenv("STATE_REDIS_PASSWORD"),
+		stateRedisDB:       redisDB,
+		stateRedisKey:      envOrDefault("STATE_REDIS_KEY", "slack-bridge:state"),
+
+		leaderElection: os.Getenv("LEADER_ELECTION") == "true",
+		leaderKey:      envOrDefault("LEADER_LEASE_KEY", "slack-bridge:leader"),
+		identity:       identity,
+
+		threadingMode: threadingMode,
+		templatesDir:  os.Getenv("SLACK_TEMPLATES_DIR"),
+
+		reactionShortcuts: os.Getenv("SLACK_REACTION_SHORTCUTS"),
+
+		locale:         envOrDefault("SLACK_LOCALE", "en"),
+		channelLocales: os.Getenv("SLACK_CHANNEL_LOCALES"),
+
+		severityRoutes: os.Getenv("SLACK_SEVERITY_ROUTES"),
+		digestInterval: digestInterval,
+
+		dashboardEnabled:  dashEnabled,
+		dashboardChannel:  dashChannel,
+		dashboardInterval: dashInterval,
+
+		dashboardCharts:         os.Getenv("SLACK_DASHBOARD_CHARTS") == "true",
+		dashboardChartsInterval: chartsInterval,
+		dashboardChartsDays:     chartsDays,
+
+		decisionSLAWarnAt:       slaWarnAt,
+		decisionDefaultDeadline: defaultDeadline,
+
+		threadCompaction:         os.Getenv("SLACK_THREAD_COMPACTION") == "true",
+		threadCompactionAge:      compactAge,
+		threadCompactionInterval: compactInterval,
+		threadCompactionMin:      compactMin,
+
+		nudgeCooldown: nudgeCooldown,
+
+		beadURL:      os.Getenv("SLACK_BEAD_URL"),
+		dashboardURL: os.Getenv("SLACK_DASHBOARD_URL"),
+
+		coopTokenClientToken: os.Getenv("COOP_TOKEN_CLIENT_TOKEN"),
+		coopProxyURL:         os.Getenv("COOP_PROXY_URL"),
+
+		spawnPreviewToken: os.Getenv("SPAWN_PREVIEW_TOKEN"),
+		agentLogsToken:    os.Getenv("AGENT_LOGS_TOKEN"),
+
+		githubToken:   os.Getenv("GITHUB_TOKEN"),
+		repos:         repos,
+		controllerURL: os.Getenv("CONTROLLER_URL"),
+	}
+}
+
+// parseRepoList parses a comma-separated list of "owner/repo" strings.
+func parseRepoList(s string) []bridge.RepoRef {
+	var repos []bridge.RepoRef
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		repos = append(repos, bridge.RepoRef{Owner: parts[0], Repo: parts[1]})
+	}
+	return repos
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -3,9 +3,15 @@
 //
 // It runs three subsystems:
 //   - Decisions watcher: SSE subscription for decision beads → Slack notifications
-//   - Mail watcher: SSE subscription for mail beads → agent nudges
+//   - Mail watcher: SSE subscription for mail beads → agent nudges, rate
+//     limited per agent by the nudge service (also served on /api/nudge)
 //   - HTTP server: Slack interaction webhook handler (/slack/interactions)
 //
+// With LEADER_ELECTION=true several replicas can share a Redis state backend:
+// one replica (holding a lease in the daemon config) runs the SSE watchers,
+// dashboard, and outbox retries, while every replica serves Socket Mode
+// interactions.
+//
 // This service has ZERO K8s dependencies and can run as a lightweight sidecar
 // or standalone container alongside the gasboat controller.
 package main
@@ -21,8 +27,6 @@ import (
 	"syscall"
 	"time"
 
-	"strings"
-
 	"gasboat/controller/internal/beadsapi"
 	"gasboat/controller/internal/bridge"
 )
@@ -61,18 +65,47 @@ func main() {
 	defer cancel()
 
 	// State persistence for Slack message tracking.
-	state, err := bridge.NewStateManager(cfg.statePath)
+	state, err := openStateManager(cfg, logger)
 	if err != nil {
-		logger.Error("failed to load state", "path", cfg.statePath, "error", err)
+		logger.Error("failed to load state", "backend", cfg.stateBackend, "error", err)
 		os.Exit(1)
 	}
-	logger.Info("state manager loaded", "path", cfg.statePath)
+	logger.Info("state manager loaded", "store", state.Describe())
+
+	// Leader election: only the lease holder runs SSE watchers and other
+	// background posting. Without LEADER_ELECTION this replica always leads.
+	if cfg.leaderElection && cfg.stateBackend != "redis" {
+		logger.Warn("LEADER_ELECTION without a shared STATE_BACKEND — replicas will not share message refs or dedup")
+	}
+	if cfg.stateBackend == "redis" {
+		state.SetShared(true)
+	}
+	leader := bridge.NewLeader(bridge.LeaderConfig{
+		Client:   daemon,
+		Key:      cfg.leaderKey,
+		Identity: cfg.identity,
+		Disabled: !cfg.leaderElection,
+		Logger:   logger,
+	})
+	go leader.Run(ctx)
+	if cfg.leaderElection {
+		go reloadStateWhileFollower(ctx, leader, state, logger)
+	}
+
+	// Prune refs for decisions resolved while the bridge was down.
+	go leader.RunWhileLeader(ctx, "state-compaction", func(ctx context.Context) {
+		bridge.RunStateCompaction(ctx, state, daemon, logger)
+	})
 
 	// Slack notifier (optional — decisions still tracked even without Slack).
 	var notifier bridge.Notifier
 	var bot *bridge.Bot
 	mux := http.NewServeMux()
 
+	// Delivery metrics served on /metrics (Prometheus text format).
+	metrics := bridge.NewMetrics()
+	metricsWriters := []bridge.MetricsWriter{}
+
 	// Health endpoints — always available regardless of Slack config.
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -96,29 +129,79 @@ func main() {
 		Version:       version,
 	}))
 
+	// Nudge API — rate-limited, coalesced agent nudges, shared with the
+	// mail watcher.
+	nudger := bridge.NewNudger(bridge.NudgerConfig{
+		Daemon:   daemon,
+		Cooldown: cfg.nudgeCooldown,
+		Logger:   logger,
+	})
+	mux.HandleFunc("/api/nudge", nudger.Handler())
+
 	// Decisions web UI and API.
 	decisionAPI := bridge.NewDecisionAPI(daemon, logger)
 	decisionAPI.RegisterRoutes(mux)
 	mux.Handle("/api/decisions/events", bridge.NewDecisionSSEProxy(cfg.beadsHTTPAddr, logger))
 	mux.Handle("/ui/", http.StripPrefix("/ui/", bridge.WebHandler()))
 
+	// Notification templates — defaults plus hot-reloaded overrides from
+	// SLACK_TEMPLATES_DIR and the daemon config key.
+	templates := bridge.NewNotificationTemplates(bridge.NotificationTemplatesConfig{
+		Dir:    cfg.templatesDir,
+		Client: daemon,
+		Logger: logger,
+	})
+	go templates.Run(ctx)
+
+	// Severity routing: per-project rules for where notifications go.
+	severity, err := bridge.ParseSeverityRouting(cfg.severityRoutes)
+	if err != nil {
+		logger.Error("invalid SLACK_SEVERITY_ROUTES", "error", err)
+		os.Exit(1)
+	}
+
+	// Coop terminal links go through the controller's proxy with
+	// short-lived tokens when the controller mints them for us.
+	var coopTokens *bridge.CoopTokenClient
+	if cfg.controllerURL != "" && cfg.coopTokenClientToken != "" {
+		coopTokens = bridge.NewCoopTokenClient(bridge.CoopTokenConfig{
+			ControllerURL: cfg.controllerURL,
+			PublicURL:     cfg.coopProxyURL,
+			ClientToken:   cfg.coopTokenClientToken,
+			Subject:       "slack-bridge",
+		})
+	}
+
 	if cfg.slackBotToken != "" && cfg.slackAppToken != "" {
 		// Socket Mode: real-time WebSocket connection for events, interactions, slash commands.
 		bot = bridge.NewBot(bridge.BotConfig{
-			BotToken:      cfg.slackBotToken,
-			AppToken:      cfg.slackAppToken,
-			Channel:       cfg.slackChannel,
-			ThreadingMode: cfg.threadingMode,
-			Daemon:        daemon,
-			State:         state,
-			Logger:        logger,
-			Debug:         cfg.debug,
-			GitHubToken:   cfg.githubToken,
-			Repos:         cfg.repos,
-			Version:       version,
-			ControllerURL: cfg.controllerURL,
+			BotToken:          cfg.slackBotToken,
+			AppToken:          cfg.slackAppToken,
+			Channel:           cfg.slackChannel,
+			ThreadingMode:     cfg.threadingMode,
+			Daemon:            daemon,
+			State:             state,
+			Templates:         templates,
+			ReactionShortcuts: bridge.ParseReactionShortcuts(cfg.reactionShortcuts),
+			Locales:           bridge.NewLocalizer(cfg.locale, bridge.ParseChannelLocales(cfg.channelLocales)),
+			Severity:          severity,
+			Metrics:           metrics,
+			Logger:            logger,
+			Debug:             cfg.debug,
+			GitHubToken:       cfg.githubToken,
+			Repos:             cfg.repos,
+			Version:           version,
+			ControllerURL:     cfg.controllerURL,
+			SpawnPreviewToken: cfg.spawnPreviewToken,
+			AgentLogsToken:    cfg.agentLogsToken,
+			BeadURL:           cfg.beadURL,
+			DashboardURL:      cfg.dashboardURL,
+			CoopTokens:        coopTokens,
 		})
 		notifier = bot
+		go leader.RunWhileLeader(ctx, "notification-digest", func(ctx context.Context) {
+			bot.RunNotificationDigest(ctx, cfg.digestInterval)
+		})
 		logger.Info("Slack Socket Mode bot enabled", "channel", cfg.slackChannel)
 	} else if cfg.slackBotToken != "" {
 		// Webhook fallback: raw HTTP Slack notifier with interaction webhook handler.
@@ -136,6 +219,38 @@ func main() {
 		logger.Warn("SLACK_BOT_TOKEN not set — running without Slack notifications")
 	}
 
+	// Wrap the notifier in a durable outbox so decision notifications survive
+	// Slack outages and rate limits (persisted in state, retried with backoff).
+	if notifier != nil {
+		outbox := bridge.NewOutbox(bridge.OutboxConfig{
+			Notifier: notifier,
+			State:    state,
+			Metrics:  metrics,
+			Logger:   logger,
+		})
+		notifier = outbox
+		metricsWriters = append(metricsWriters, outbox)
+		go leader.RunWhileLeader(ctx, "outbox", outbox.Run)
+	}
+
+	// Decision deadlines: reminders, escalation of overdue decisions and
+	// latency percentiles per project on /metrics.
+	var reminder bridge.DecisionReminder
+	if bot != nil {
+		reminder = bot
+	}
+	decisionSLA := bridge.NewDecisionSLA(bridge.DecisionSLAConfig{
+		Daemon:          daemon,
+		Reminder:        reminder,
+		Logger:          logger,
+		WarnAt:          cfg.decisionSLAWarnAt,
+		DefaultDeadline: cfg.decisionDefaultDeadline,
+	})
+	metricsWriters = append(metricsWriters, decisionSLA)
+	go leader.RunWhileLeader(ctx, "decision-sla", decisionSLA.Run)
+
+	mux.HandleFunc("/metrics", metrics.Handler(metricsWriters...))
+
 	// Start HTTP server (always — serves health endpoints + optional webhook handler).
 	srv := &http.Server{
 		Addr:              cfg.listenAddr,
@@ -173,6 +288,9 @@ func main() {
 
 	// Create event deduplicator for preventing duplicate Slack notifications.
 	dedup := bridge.NewDedup(logger)
+	if cfg.leaderElection {
+		dedup.UseSharedState(state)
+	}
 
 	// Create SSE event stream for decisions, mail, agents, and jacks watchers.
 	sseStream := bridge.NewSSEStream(bridge.SSEStreamConfig{
@@ -181,12 +299,20 @@ func main() {
 		Logger:        logger,
 		Dedup:         dedup,
 		State:         state,
+		Metrics:       metrics,
+		StrictEvents:  cfg.strictEvents,
 	})
 
 	// Register decisions handler on the SSE stream.
+	// Mute rules set from Slack (/mute, agent card menu) silence watcher
+	// notifications; the leader expires them on schedule.
+	mutes := bridge.NewMutes(state, logger)
+	go leader.RunWhileLeader(ctx, "mute-expiry", mutes.Run)
+
 	decisions := bridge.NewDecisions(bridge.DecisionsConfig{
 		Daemon:   daemon,
 		Notifier: notifier,
+		Mutes:    mutes,
 		Logger:   logger,
 	})
 	decisions.RegisterHandlers(sseStream)
@@ -194,6 +320,7 @@ func main() {
 	// Register mail handler on the SSE stream.
 	mail := bridge.NewMail(bridge.MailConfig{
 		Daemon: daemon,
+		Nudger: nudger,
 		Logger: logger,
 	})
 	mail.RegisterHandlers(sseStream)
@@ -205,6 +332,8 @@ func main() {
 	}
 	agents := bridge.NewAgents(bridge.AgentsConfig{
 		Notifier: agentNotifier,
+		Mutes:    mutes,
+		Metrics:  metrics,
 		Logger:   logger,
 	})
 	agents.RegisterHandlers(sseStream)
@@ -216,10 +345,41 @@ func main() {
 	}
 	jacks := bridge.NewJacks(bridge.JacksConfig{
 		Notifier: jackNotifier,
+		Mutes:    mutes,
+		Metrics:  metrics,
 		Logger:   logger,
 	})
 	jacks.RegisterHandlers(sseStream)
 
+	// Register artifact watcher — uploads bead artifacts as Slack file snippets.
+	if bot != nil {
+		artifacts := bridge.NewArtifacts(bridge.ArtifactsConfig{
+			Uploader: bot,
+			Logger:   logger,
+		})
+		artifacts.RegisterHandlers(sseStream)
+	}
+
+	// Register error report watcher — one alert per rolled-up controller
+	// error report instead of one per log line.
+	if bot != nil {
+		errorReports := bridge.NewErrorReports(bridge.ErrorReportsConfig{
+			Notifier: bot,
+			Logger:   logger,
+		})
+		errorReports.RegisterHandlers(sseStream)
+	}
+
+	// Register budget alert watcher — one alert per project crossing its
+	// daily soft or hard API budget.
+	if bot != nil {
+		budgetAlerts := bridge.NewBudgetAlerts(bridge.BudgetAlertsConfig{
+			Notifier: bot,
+			Logger:   logger,
+		})
+		budgetAlerts.RegisterHandlers(sseStream)
+	}
+
 	// Register chat forwarding handler (Slack→agent→Slack relay).
 	if bot != nil {
 		chat := bridge.NewChat(bridge.ChatConfig{
@@ -231,20 +391,59 @@ func main() {
 		chat.RegisterHandlers(sseStream)
 	}
 
-	// Start live agent dashboard if configured.
-	if cfg.dashboardEnabled && bot != nil {
+	// Start live agent dashboards: the default one if configured, plus any
+	// channel dashboards from the slack-bridge:dashboards config entry.
+	if bot != nil {
 		dashChannel := cfg.dashboardChannel
 		if dashChannel == "" {
 			dashChannel = cfg.slackChannel
 		}
-		dash := bridge.NewDashboard(bot.API(), daemon, state, logger, bridge.DashboardConfig{
-			Enabled:   true,
-			ChannelID: dashChannel,
-			Interval:  cfg.dashboardInterval,
+		dashboards := bridge.NewDashboardSet(bridge.DashboardSetConfig{
+			API:    bot.API(),
+			Daemon: daemon,
+			State:  state,
+			Logger: logger,
+			Default: bridge.DashboardConfig{
+				Enabled:   cfg.dashboardEnabled,
+				ChannelID: dashChannel,
+				Interval:  cfg.dashboardInterval,
+			},
+			Client: daemon,
+		})
+		dashboards.RegisterHandlers(sseStream)
+		go leader.RunWhileLeader(ctx, "dashboard", dashboards.Run)
+		if cfg.dashboardEnabled {
+			logger.Info("dashboard enabled", "channel", dashChannel, "interval", cfg.dashboardInterval)
+		}
+
+		// Weekly trend charts (tasks closed, decision latency, agent
+		// utilization) uploaded to the dashboard channel.
+		if cfg.dashboardCharts {
+			charts := bridge.NewDashboardCharts(bridge.DashboardChartsConfig{
+				API:       bot.API(),
+				Daemon:    daemon,
+				State:     state,
+				Logger:    logger,
+				ChannelID: dashChannel,
+				Interval:  cfg.dashboardChartsInterval,
+				Days:      cfg.dashboardChartsDays,
+			})
+			go leader.RunWhileLeader(ctx, "dashboard-charts", charts.Run)
+			logger.Info("dashboard charts enabled", "channel", dashChannel)
+		}
+	}
+
+	// Compact old resolved decisions in agent threads into digests.
+	if cfg.threadCompaction && bot != nil && cfg.threadingMode == "agent" {
+		compaction := bridge.ThreadCompactionConfig{
+			Age:         cfg.threadCompactionAge,
+			Interval:    cfg.threadCompactionInterval,
+			MinMessages: cfg.threadCompactionMin,
+		}
+		go leader.RunWhileLeader(ctx, "thread-compaction", func(ctx context.Context) {
+			bot.RunThreadCompaction(ctx, compaction)
 		})
-		dash.RegisterHandlers(sseStream)
-		go dash.Run(ctx)
-		logger.Info("dashboard enabled", "channel", dashChannel, "interval", cfg.dashboardInterval)
+		logger.Info("agent thread compaction enabled")
 	}
 
 	// Register claimed bead update watcher — nudges agents when their claimed work is updated.
@@ -255,19 +454,30 @@ func main() {
 	claimed.RegisterHandlers(sseStream)
 
 	// Catch-up: notify pending decisions that may have been missed during downtime.
-	// Run before SSE stream starts to pre-populate dedup map.
-	go dedup.CatchUpDecisions(ctx, daemon, notifier, logger)
+	// Run before SSE stream starts to pre-populate dedup map. Re-runs on every
+	// leadership acquisition to cover events missed during the handoff.
+	go leader.RunWhileLeader(ctx, "catch-up", func(ctx context.Context) {
+		dedup.CatchUpDecisions(ctx, daemon, notifier, logger)
+	})
 
-	// Start the shared SSE stream (delivers events to all watchers).
-	go func() {
+	// Start the shared SSE stream (delivers events to all watchers). A new
+	// leader reloads state first so it resumes from the shared event ID.
+	go leader.RunWhileLeader(ctx, "sse-stream", func(ctx context.Context) {
+		if cfg.leaderElection {
+			if err := state.Reload(); err != nil {
+				logger.Warn("failed to reload state before SSE stream", "error", err)
+			}
+		}
 		if err := sseStream.Start(ctx); err != nil && ctx.Err() == nil {
 			logger.Error("SSE event stream stopped", "error", err)
 		}
-	}()
+	})
 
 	logger.Info("slack-bridge ready",
 		"socket_mode", bot != nil,
-		"webhook_mode", bot == nil && notifier != nil)
+		"webhook_mode", bot == nil && notifier != nil,
+		"leader_election", cfg.leaderElection,
+		"identity", cfg.identity)
 
 	// Block until shutdown signal.
 	<-ctx.Done()
@@ -281,99 +491,66 @@ func main() {
 	}
 }
 
-// config holds parsed environment configuration for the slack-bridge service.
-type config struct {
-	beadsHTTPAddr      string
-	slackBotToken      string
-	slackAppToken      string
-	slackSigningSecret string
-	slackChannel       string
-	listenAddr         string
-	logLevel           string
-	statePath          string
-	debug              bool
-
-	// Threading
-	threadingMode string
-
-	// Dashboard
-	dashboardEnabled  bool
-	dashboardChannel  string
-	dashboardInterval time.Duration
-
-	// GitHub /unreleased
-	githubToken   string
-	repos         []bridge.RepoRef
-	controllerURL string
-}
-
-func parseConfig() *config {
-	dashInterval := 15 * time.Second
-	if v := os.Getenv("SLACK_DASHBOARD_INTERVAL"); v != "" {
-		if d, err := time.ParseDuration(v); err == nil {
-			dashInterval = d
+// openStateManager creates the state manager for the configured backend.
+// When switching to SQLite or Redis, an existing JSON state file at
+// STATE_PATH is imported on first start so Slack message refs carry over.
+func openStateManager(cfg *config, logger *slog.Logger) (*bridge.StateManager, error) {
+	switch cfg.stateBackend {
+	case "", "file":
+		return bridge.NewStateManager(cfg.statePath)
+	case "sqlite":
+		store, err := bridge.OpenSQLiteStateStore(cfg.stateSQLitePath)
+		if err != nil {
+			return nil, err
 		}
+		return migrateAndOpen(cfg, store, logger)
+	case "redis":
+		return migrateAndOpen(cfg, bridge.NewRedisStateStore(bridge.RedisStateConfig{
+			Addr:     cfg.stateRedisAddr,
+			Password: cfg.stateRedisPassword,
+			DB:       cfg.stateRedisDB,
+			Key:      cfg.stateRedisKey,
+		}), logger)
+	default:
+		return nil, fmt.Errorf("unknown STATE_BACKEND %q (want file, sqlite or redis)", cfg.stateBackend)
 	}
+}
 
-	dashChannel := os.Getenv("SLACK_DASHBOARD_CHANNEL")
-	dashEnabled := os.Getenv("SLACK_DASHBOARD") == "true"
-	// Auto-enable if channel is set.
-	if dashChannel != "" && os.Getenv("SLACK_DASHBOARD") == "" {
-		dashEnabled = true
+// migrateAndOpen imports the legacy JSON state file into store, if any, and
+// returns a state manager backed by store.
+func migrateAndOpen(cfg *config, store bridge.StateStore, logger *slog.Logger) (*bridge.StateManager, error) {
+	migrated, err := bridge.MigrateFileState(cfg.statePath, store)
+	if err != nil {
+		return nil, fmt.Errorf("migrate %s to %s: %w", cfg.statePath, cfg.stateBackend, err)
 	}
-
-	threadingMode := os.Getenv("SLACK_THREADING_MODE")
-	if threadingMode == "" {
-		threadingMode = "agent"
-	}
-
-	repos := parseRepoList(envOrDefault("UNRELEASED_REPOS", "groblegark/gasboat,groblegark/kbeads,groblegark/coop"))
-
-	return &config{
-		beadsHTTPAddr:      envOrDefault("BEADS_HTTP_ADDR", "http://localhost:8080"),
-		slackBotToken:      os.Getenv("SLACK_BOT_TOKEN"),
-		slackAppToken:      os.Getenv("SLACK_APP_TOKEN"),
-		slackSigningSecret: os.Getenv("SLACK_SIGNING_SECRET"),
-		slackChannel:       os.Getenv("SLACK_CHANNEL"),
-		listenAddr:         envOrDefault("SLACK_LISTEN_ADDR", ":8090"),
-		logLevel:           envOrDefault("LOG_LEVEL", "info"),
-		statePath:          envOrDefault("STATE_PATH", "/tmp/slack-bridge-state.json"),
-		debug:              os.Getenv("DEBUG") == "true" || os.Getenv("LOG_LEVEL") == "debug",
-
-		threadingMode: threadingMode,
-
-		dashboardEnabled:  dashEnabled,
-		dashboardChannel:  dashChannel,
-		dashboardInterval: dashInterval,
-
-		githubToken:   os.Getenv("GITHUB_TOKEN"),
-		repos:         repos,
-		controllerURL: os.Getenv("CONTROLLER_URL"),
+	if migrated {
+		logger.Info("migrated JSON state file", "path", cfg.statePath, "store", store.Describe())
 	}
+	return bridge.NewStateManagerWithStore(store)
 }
 
-// parseRepoList parses a comma-separated list of "owner/repo" strings.
-func parseRepoList(s string) []bridge.RepoRef {
-	var repos []bridge.RepoRef
-	for _, entry := range strings.Split(s, ",") {
-		entry = strings.TrimSpace(entry)
-		if entry == "" {
-			continue
-		}
-		parts := strings.SplitN(entry, "/", 2)
-		if len(parts) != 2 {
-			continue
+// stateReloadInterval is how often followers refresh state from the shared
+// backend so Socket Mode handlers see message refs written by the leader.
+const stateReloadInterval = 10 * time.Second
+
+// reloadStateWhileFollower periodically reloads shared state while this
+// replica is not the leader. The leader's own writes keep it current.
+func reloadStateWhileFollower(ctx context.Context, leader *bridge.Leader, state *bridge.StateManager, logger *slog.Logger) {
+	ticker := time.NewTicker(stateReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if leader.IsLeader() {
+				continue
+			}
+			if err := state.Reload(); err != nil {
+				logger.Warn("failed to reload shared state", "error", err)
+			}
+		case <-ctx.Done():
+			return
 		}
-		repos = append(repos, bridge.RepoRef{Owner: parts[0], Repo: parts[1]})
 	}
-	return repos
-}
-
-func envOrDefault(key, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
 }
 
 func setupLogger(level string) *slog.Logger {
@@ -396,4 +573,3 @@ func init() {
 		version = v
 	}
 }
-
@@ -13,6 +13,7 @@ type MatchedAdvice struct {
 	Scope         string
 	ScopeTarget   string
 	ScopeHeader   string
+	Effectiveness Effectiveness
 }
 
 // ListAdviceForAgent fetches open advice beads and filters them by the agent's
@@ -37,6 +38,7 @@ func ListAdviceForAgent(ctx context.Context, daemon *beadsapi.Client, agentID st
 				Scope:         scope,
 				ScopeTarget:   target,
 				ScopeHeader:   BuildScopeHeader(scope, target),
+				Effectiveness: EffectivenessOf(bead),
 			})
 		}
 	}
@@ -0,0 +1,126 @@
+package advice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// FeedbackField is the advice bead field holding per-agent feedback as a
+// JSON object keyed by agent ID.
+const FeedbackField = "feedback"
+
+// Feedback is one agent's feedback on one advice bead. A later vote from the
+// same agent replaces its earlier one, so each agent counts once.
+type Feedback struct {
+	Vote    int       `json:"vote,omitempty"`    // 1 helpful, -1 unhelpful, 0 no vote
+	Applied int       `json:"applied,omitempty"` // times the agent reported acting on it
+	At      time.Time `json:"at"`
+}
+
+// ParseFeedback returns the per-agent feedback stored on an advice bead.
+// A missing or malformed field yields an empty map.
+func ParseFeedback(fields map[string]string) map[string]Feedback {
+	fb := make(map[string]Feedback)
+	if raw := fields[FeedbackField]; raw != "" {
+		_ = json.Unmarshal([]byte(raw), &fb)
+	}
+	return fb
+}
+
+// Effectiveness aggregates feedback on one advice bead.
+type Effectiveness struct {
+	Up      int     `json:"up"`
+	Down    int     `json:"down"`
+	Applied int     `json:"applied"` // agents that applied it at least once
+	Score   float64 `json:"score"`
+}
+
+// Rated reports whether any agent has left feedback.
+func (e Effectiveness) Rated() bool {
+	return e.Up+e.Down+e.Applied > 0
+}
+
+// ScoreFeedback aggregates feedback into an effectiveness score in (0, 1).
+// Votes and applications count as evidence for or against the advice,
+// smoothed toward 0.5 so a single vote does not dominate: unrated advice
+// scores 0.5, one thumbs-up 0.67, one thumbs-down 0.33.
+func ScoreFeedback(fb map[string]Feedback) Effectiveness {
+	var e Effectiveness
+	for _, f := range fb {
+		switch {
+		case f.Vote > 0:
+			e.Up++
+		case f.Vote < 0:
+			e.Down++
+		}
+		if f.Applied > 0 {
+			e.Applied++
+		}
+	}
+	positive := float64(e.Up + e.Applied)
+	e.Score = (positive + 1) / (positive + float64(e.Down) + 2)
+	return e
+}
+
+// EffectivenessOf scores an advice bead from its fields.
+func EffectivenessOf(bead *beadsapi.BeadDetail) Effectiveness {
+	return ScoreFeedback(ParseFeedback(bead.Fields))
+}
+
+// FeedbackUpdate is a change to one agent's feedback. Vote, when non-nil,
+// replaces the agent's vote (0 clears it); Applied records one more use.
+type FeedbackUpdate struct {
+	Vote    *int
+	Applied bool
+}
+
+// RecordFeedback applies u to agentID's feedback on an advice bead and
+// returns the bead's updated effectiveness.
+func RecordFeedback(ctx context.Context, daemon *beadsapi.Client, adviceID, agentID string, u FeedbackUpdate) (Effectiveness, error) {
+	if agentID == "" {
+		return Effectiveness{}, fmt.Errorf("feedback requires an agent ID")
+	}
+	if u.Vote != nil && (*u.Vote < -1 || *u.Vote > 1) {
+		return Effectiveness{}, fmt.Errorf("vote must be -1, 0, or 1")
+	}
+	bead, err := daemon.GetBead(ctx, adviceID)
+	if err != nil {
+		return Effectiveness{}, err
+	}
+	if bead.Type != "advice" {
+		return Effectiveness{}, fmt.Errorf("%s is a %s bead, not advice", adviceID, bead.Type)
+	}
+
+	fb := ParseFeedback(bead.Fields)
+	f := fb[agentID]
+	if u.Vote != nil {
+		f.Vote = *u.Vote
+	}
+	if u.Applied {
+		f.Applied++
+	}
+	f.At = time.Now().UTC()
+	fb[agentID] = f
+
+	data, err := json.Marshal(fb)
+	if err != nil {
+		return Effectiveness{}, err
+	}
+	if err := daemon.UpdateBeadFields(ctx, adviceID, map[string]string{FeedbackField: string(data)}); err != nil {
+		return Effectiveness{}, err
+	}
+	return ScoreFeedback(fb), nil
+}
+
+// RankByEffectiveness orders matched advice by effectiveness score, highest
+// first. Ties keep their original order.
+func RankByEffectiveness(matched []MatchedAdvice) {
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Effectiveness.Score > matched[j].Effectiveness.Score
+	})
+}
@@ -0,0 +1,62 @@
+package advice
+
+import (
+	"math"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestScoreFeedback(t *testing.T) {
+	tests := []struct {
+		name string
+		fb   map[string]Feedback
+		want Effectiveness
+	}{
+		{"unrated", nil, Effectiveness{Score: 0.5}},
+		{"one up", map[string]Feedback{"a": {Vote: 1}}, Effectiveness{Up: 1, Score: 2.0 / 3}},
+		{"one down", map[string]Feedback{"a": {Vote: -1}}, Effectiveness{Down: 1, Score: 1.0 / 3}},
+		{"applied counts once per agent", map[string]Feedback{"a": {Applied: 5}}, Effectiveness{Applied: 1, Score: 2.0 / 3}},
+		{"mixed", map[string]Feedback{
+			"a": {Vote: 1, Applied: 1},
+			"b": {Vote: -1},
+			"c": {Vote: -1},
+		}, Effectiveness{Up: 1, Down: 2, Applied: 1, Score: 3.0 / 6}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScoreFeedback(tt.fb)
+			if got.Up != tt.want.Up || got.Down != tt.want.Down || got.Applied != tt.want.Applied {
+				t.Errorf("counts = %+v, want %+v", got, tt.want)
+			}
+			if math.Abs(got.Score-tt.want.Score) > 1e-9 {
+				t.Errorf("score = %v, want %v", got.Score, tt.want.Score)
+			}
+		})
+	}
+}
+
+func TestParseFeedback_Malformed(t *testing.T) {
+	if fb := ParseFeedback(map[string]string{FeedbackField: "not json"}); len(fb) != 0 {
+		t.Errorf("expected empty feedback, got %v", fb)
+	}
+	fb := ParseFeedback(map[string]string{FeedbackField: `{"kd-1":{"vote":-1,"applied":2,"at":"2026-01-02T03:04:05Z"}}`})
+	if f := fb["kd-1"]; f.Vote != -1 || f.Applied != 2 || f.At.IsZero() {
+		t.Errorf("unexpected feedback: %+v", f)
+	}
+}
+
+func TestRankByEffectiveness(t *testing.T) {
+	m := func(id string, score float64) MatchedAdvice {
+		return MatchedAdvice{Bead: &beadsapi.BeadDetail{ID: id}, Effectiveness: Effectiveness{Score: score}}
+	}
+	matched := []MatchedAdvice{m("a", 0.5), m("b", 0.8), m("c", 0.5), m("d", 0.2)}
+	RankByEffectiveness(matched)
+	var got string
+	for _, x := range matched {
+		got += x.Bead.ID
+	}
+	if got != "bacd" {
+		t.Errorf("order = %s, want bacd (stable for ties)", got)
+	}
+}
@@ -0,0 +1,56 @@
+package artifacts
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultPatterns selects what is exported when no patterns are configured.
+var DefaultPatterns = []string{"*.patch", "*.diff", "reports/*", ".reports/*"}
+
+// MaxFileBytes caps the size of a single exported file; larger files are
+// skipped.
+const MaxFileBytes = 64 << 20
+
+// Select returns the regular files under root matching any of patterns
+// (filepath.Match globs relative to root, e.g. "reports/*.md"), sorted and
+// relative to root. Files above MaxFileBytes are skipped.
+func Select(root string, patterns []string) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" || filepath.IsAbs(pattern) || strings.HasPrefix(filepath.Clean(pattern), "..") {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			continue
+		}
+		for _, m := range matches {
+			info, err := os.Lstat(m)
+			if err != nil || !info.Mode().IsRegular() || info.Size() > MaxFileBytes {
+				continue
+			}
+			rel, err := filepath.Rel(root, m)
+			if err != nil || seen[rel] {
+				continue
+			}
+			seen[rel] = true
+			files = append(files, rel)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// ParsePatterns splits a comma-separated pattern list, falling back to
+// DefaultPatterns when s is empty.
+func ParsePatterns(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return append([]string(nil), DefaultPatterns...)
+	}
+	return strings.Split(s, ",")
+}
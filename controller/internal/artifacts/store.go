@@ -0,0 +1,159 @@
+// Package artifacts exports agent session artifacts (reports, patches,
+// session logs) to S3-compatible object storage: AWS S3, MinIO, or GCS
+// through its XML API with HMAC keys. Requests are signed with AWS
+// Signature Version 4 and use path-style bucket addressing, which all three
+// accept.
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Store is an S3-compatible bucket.
+type Store struct {
+	Endpoint  string // e.g. "https://s3.us-east-1.amazonaws.com", "http://minio:9000"
+	Bucket    string
+	Region    string // "us-east-1" if empty; GCS accepts "auto"
+	AccessKey string
+	SecretKey string
+	Client    *http.Client
+
+	now func() time.Time
+}
+
+// FromEnv builds a Store from the BOAT_ARTIFACT_* variables the controller
+// sets on agent pods. It returns nil if no bucket is configured.
+func FromEnv() (*Store, error) {
+	bucket := os.Getenv("BOAT_ARTIFACT_BUCKET")
+	if bucket == "" {
+		return nil, nil
+	}
+	s := &Store{
+		Endpoint:  os.Getenv("BOAT_ARTIFACT_ENDPOINT"),
+		Bucket:    bucket,
+		Region:    os.Getenv("BOAT_ARTIFACT_REGION"),
+		AccessKey: os.Getenv("BOAT_ARTIFACT_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("BOAT_ARTIFACT_SECRET_ACCESS_KEY"),
+	}
+	if s.Endpoint == "" {
+		return nil, fmt.Errorf("BOAT_ARTIFACT_BUCKET is set but BOAT_ARTIFACT_ENDPOINT is not")
+	}
+	if s.AccessKey == "" || s.SecretKey == "" {
+		return nil, fmt.Errorf("artifact store credentials are not set")
+	}
+	return s, nil
+}
+
+// ObjectURL returns the URL of the object stored under key.
+func (s *Store) ObjectURL(key string) string {
+	return strings.TrimSuffix(s.Endpoint, "/") + "/" + escapePath(s.Bucket) + "/" + escapePath(key)
+}
+
+// Put uploads body under key and returns the object's URL.
+func (s *Store) Put(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	objectURL := s.ObjectURL(key)
+	u, err := url.Parse(objectURL)
+	if err != nil {
+		return "", fmt.Errorf("artifact endpoint: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create upload request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	sum := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sum[:]))
+
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	region := s.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	sign(req, region, s.AccessKey, s.SecretKey, now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("upload %s: status %d: %s", key, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return objectURL, nil
+}
+
+// escapePath percent-encodes an object key for a URL path, leaving "/"
+// separators and RFC 3986 unreserved characters as they are (the encoding
+// SigV4 expects in the canonical URI).
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// Export uploads files, given relative to root, under prefix and returns the
+// URLs of those uploaded. A file that can't be read or uploaded is skipped;
+// the first such error is returned with the URLs of the rest.
+func (s *Store) Export(ctx context.Context, prefix, root string, files []string) ([]string, error) {
+	var urls []string
+	var firstErr error
+	for _, rel := range files {
+		body, err := os.ReadFile(filepath.Join(root, rel))
+		if err == nil {
+			var u string
+			u, err = s.Put(ctx, path.Join(prefix, filepath.ToSlash(rel)), body, contentType(rel))
+			if err == nil {
+				urls = append(urls, u)
+				continue
+			}
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return urls, firstErr
+}
+
+func contentType(name string) string {
+	switch filepath.Ext(name) {
+	case ".jsonl":
+		return "application/x-ndjson"
+	case ".json":
+		return "application/json"
+	case ".md":
+		return "text/markdown; charset=utf-8"
+	case ".patch", ".diff", ".txt", ".log":
+		return "text/plain; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
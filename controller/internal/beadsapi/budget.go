@@ -0,0 +1,197 @@
+package beadsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// SpendField is the agent bead field where an agent reports its API spend:
+// a JSON object mapping UTC day (SpendDay) to that day's Spend.
+const SpendField = "spend"
+
+// spendDaysKept is how many days of spend AddSpend keeps on an agent bead.
+const spendDaysKept = 7
+
+// Project bead fields configuring and tracking the daily API budget.
+const (
+	// BudgetSoftField is the daily spend in USD at which the controller
+	// notifies the project's channel.
+	BudgetSoftField = "budget_soft_usd"
+	// BudgetHardField is the daily spend in USD at which the controller
+	// stops spawning the project's agents until the next UTC day.
+	BudgetHardField = "budget_hard_usd"
+	// BudgetOverrideField lets new agents spawn past the hard cap until
+	// the RFC 3339 time it holds (set via the controller admin API).
+	BudgetOverrideField = "budget_override_until"
+	// BudgetResetField is the JSON BudgetReset a reset leaves behind
+	// (set via the controller admin API).
+	BudgetResetField = "budget_reset"
+	// BudgetStatusField is the JSON BudgetStatus the controller keeps.
+	BudgetStatusField = "budget_status"
+)
+
+// Values of BudgetStatus.Level.
+const (
+	BudgetSoft = "soft"
+	BudgetHard = "hard"
+)
+
+// BudgetTopic is the bus topic the controller publishes BudgetAlerts on.
+const BudgetTopic = "controller.budget"
+
+// Spend is API usage reported by an agent.
+type Spend struct {
+	USD    float64 `json:"usd"`
+	Tokens int64   `json:"tokens,omitempty"`
+}
+
+// SpendDay returns the key of t's day in SpendField.
+func SpendDay(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// SpendFromFields decodes an agent bead's spend by day, returning nil if it
+// has none or it is malformed.
+func SpendFromFields(fields map[string]string) map[string]Spend {
+	raw := fields[SpendField]
+	if raw == "" {
+		return nil
+	}
+	var days map[string]Spend
+	if json.Unmarshal([]byte(raw), &days) != nil {
+		return nil
+	}
+	return days
+}
+
+// AddSpend adds s to day in raw, a SpendField value, and returns the new
+// value. Only the latest days are kept.
+func AddSpend(raw, day string, s Spend) (string, error) {
+	if s.USD < 0 || s.Tokens < 0 || math.IsNaN(s.USD) || math.IsInf(s.USD, 0) {
+		return "", fmt.Errorf("spend must be a non-negative amount")
+	}
+	days := SpendFromFields(map[string]string{SpendField: raw})
+	if days == nil {
+		days = make(map[string]Spend)
+	}
+	total := days[day]
+	total.USD += s.USD
+	total.Tokens += s.Tokens
+	days[day] = total
+
+	keys := make([]string, 0, len(days))
+	for k := range days {
+		keys = append(keys, k)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	for _, k := range keys[min(len(keys), spendDaysKept):] {
+		delete(days, k)
+	}
+	data, err := json.Marshal(days)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// BudgetReset discounts spend already made today from a project's budget.
+type BudgetReset struct {
+	Day string  `json:"day"`
+	USD float64 `json:"usd"`
+}
+
+// BudgetStatus is a project's spend today as last counted by the
+// controller.
+type BudgetStatus struct {
+	Day      string  `json:"day"`
+	SpentUSD float64 `json:"spent_usd"` // less any reset
+	Tokens   int64   `json:"tokens,omitempty"`
+	Level    string  `json:"level,omitempty"` // "", BudgetSoft, or BudgetHard
+	Paused   bool    `json:"paused,omitempty"`
+}
+
+// BudgetAlert is the payload of a BudgetTopic event: a project crossed a
+// cap.
+type BudgetAlert struct {
+	Project  string  `json:"project"`
+	Day      string  `json:"day"`
+	Level    string  `json:"level"`
+	SpentUSD float64 `json:"spent_usd"`
+	CapUSD   float64 `json:"cap_usd"`
+	Tokens   int64   `json:"tokens,omitempty"`
+}
+
+// Summary is a one-line description of the alert.
+func (a BudgetAlert) Summary() string {
+	if a.Level == BudgetHard {
+		return fmt.Sprintf("Project %s reached its hard budget: $%.2f of $%.2f spent today; new agents are paused",
+			a.Project, a.SpentUSD, a.CapUSD)
+	}
+	return fmt.Sprintf("Project %s passed its soft budget: $%.2f of $%.2f spent today", a.Project, a.SpentUSD, a.CapUSD)
+}
+
+// BudgetCaps parses p's soft and hard caps; zero means no cap.
+func (p ProjectInfo) BudgetCaps() (soft, hard float64, err error) {
+	if soft, err = parseBudget(p.BudgetSoft); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", BudgetSoftField, err)
+	}
+	if hard, err = parseBudget(p.BudgetHard); err != nil {
+		return 0, 0, fmt.Errorf("%s: %w", BudgetHardField, err)
+	}
+	return soft, hard, nil
+}
+
+func parseBudget(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, fmt.Errorf("%q is not a non-negative amount in USD", s)
+	}
+	return v, nil
+}
+
+// BudgetOverridden reports whether an override lets p spawn past its hard
+// cap at now.
+func (p ProjectInfo) BudgetOverridden(now time.Time) bool {
+	until, err := time.Parse(time.RFC3339, p.BudgetOverrideUntil)
+	return err == nil && now.Before(until)
+}
+
+// BudgetPaused reports whether the controller holds back new agents of p
+// at now: today's status says paused and no override is active.
+func (p ProjectInfo) BudgetPaused(now time.Time) bool {
+	b := p.Budget
+	return b != nil && b.Paused && b.Day == SpendDay(now) && !p.BudgetOverridden(now)
+}
+
+// BudgetResetToday returns the USD a reset discounts from day's spend.
+func (p ProjectInfo) BudgetResetToday(day string) float64 {
+	if p.BudgetReset != nil && p.BudgetReset.Day == day {
+		return p.BudgetReset.USD
+	}
+	return 0
+}
+
+func budgetFromFields(info *ProjectInfo, fields map[string]string) {
+	info.BudgetSoft = fields[BudgetSoftField]
+	info.BudgetHard = fields[BudgetHardField]
+	info.BudgetOverrideUntil = fields[BudgetOverrideField]
+	if raw := fields[BudgetResetField]; raw != "" {
+		var r BudgetReset
+		if json.Unmarshal([]byte(raw), &r) == nil {
+			info.BudgetReset = &r
+		}
+	}
+	if raw := fields[BudgetStatusField]; raw != "" {
+		var s BudgetStatus
+		if json.Unmarshal([]byte(raw), &s) == nil {
+			info.Budget = &s
+		}
+	}
+}
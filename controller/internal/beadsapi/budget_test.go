@@ -0,0 +1,81 @@
+package beadsapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAddSpend_AccumulatesAndPrunes(t *testing.T) {
+	raw, err := AddSpend("", "2026-03-02", Spend{USD: 1.25, Tokens: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ = AddSpend(raw, "2026-03-02", Spend{USD: 0.75, Tokens: 500})
+	days := SpendFromFields(map[string]string{SpendField: raw})
+	if got := days["2026-03-02"]; got.USD != 2 || got.Tokens != 1500 {
+		t.Errorf("spend = %+v, want $2 and 1500 tokens", got)
+	}
+
+	for d := 3; d <= 12; d++ {
+		raw, _ = AddSpend(raw, time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC).Format(time.DateOnly), Spend{USD: 1})
+	}
+	days = SpendFromFields(map[string]string{SpendField: raw})
+	if len(days) != spendDaysKept {
+		t.Errorf("kept %d days, want %d", len(days), spendDaysKept)
+	}
+	if _, ok := days["2026-03-12"]; !ok {
+		t.Error("latest day pruned")
+	}
+
+	if _, err := AddSpend(raw, "2026-03-12", Spend{USD: -1}); err == nil {
+		t.Error("negative spend accepted")
+	}
+	if SpendFromFields(map[string]string{SpendField: "{"}) != nil {
+		t.Error("malformed spend decoded")
+	}
+}
+
+func TestProjectBudget_FieldsAndPause(t *testing.T) {
+	p := ProjectInfo{Name: "api", BudgetSoft: "20", BudgetHard: "50"}
+	fields := p.Fields()
+	if fields[BudgetHardField] != "50" {
+		t.Errorf("hard cap = %q", fields[BudgetHardField])
+	}
+	if _, ok := fields[BudgetStatusField]; ok {
+		t.Error("Fields writes the controller-owned budget status")
+	}
+
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	fields[BudgetStatusField] = `{"day":"2026-03-02","spent_usd":51,"level":"hard","paused":true}`
+	p = ProjectInfoFromFields("api", fields)
+	if !p.BudgetPaused(now) {
+		t.Error("project over its hard cap not paused")
+	}
+	if p.BudgetPaused(now.AddDate(0, 0, 1)) {
+		t.Error("yesterday's pause still applies")
+	}
+	fields[BudgetOverrideField] = now.Add(time.Hour).Format(time.RFC3339)
+	p = ProjectInfoFromFields("api", fields)
+	if p.BudgetPaused(now) || !p.BudgetPaused(now.Add(2*time.Hour)) {
+		t.Error("override not applied until it expires")
+	}
+}
+
+func TestProjectBudget_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		soft, hard string
+		ok         bool
+	}{
+		{"", "", true},
+		{"10", "", true},
+		{"10", "25.50", true},
+		{"30", "25", false},
+		{"-1", "", false},
+		{"", "lots", false},
+	} {
+		err := ProjectInfo{Name: "api", BudgetSoft: tc.soft, BudgetHard: tc.hard}.Validate()
+		if (err == nil) != tc.ok {
+			t.Errorf("soft %q hard %q: err = %v, want ok %v", tc.soft, tc.hard, err, tc.ok)
+		}
+	}
+}
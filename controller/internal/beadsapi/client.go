@@ -11,6 +11,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 // AgentBead represents an active agent bead from the daemon.
@@ -39,6 +41,12 @@ type AgentBead struct {
 	// PodPhase is the pod_phase field (pending, running, succeeded, failed).
 	PodPhase string
 
+	// Priority is the bead priority (0 = critical .. 4 = backlog).
+	Priority int
+
+	// CreatedAt is when the bead was created; zero if the daemon omitted it.
+	CreatedAt time.Time
+
 	// Metadata contains additional bead metadata from the daemon.
 	Metadata map[string]string
 }
@@ -53,6 +61,10 @@ type Config struct {
 	// HTTPAddr is the daemon HTTP address (e.g., "http://daemon:8080").
 	// If the value does not start with "http", it is prefixed with "http://".
 	HTTPAddr string
+
+	// Transport overrides the HTTP transport (e.g., for fault injection).
+	// Nil uses http.DefaultTransport.
+	Transport http.RoundTripper
 }
 
 // Client queries the beads daemon via HTTP/JSON.
@@ -75,7 +87,8 @@ func New(cfg Config) (*Client, error) {
 	return &Client{
 		baseURL: addr,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: cfg.Transport,
 		},
 	}, nil
 }
@@ -118,6 +131,7 @@ func (c *Client) ListAgentBeads(ctx context.Context) ([]AgentBead, error) {
 		for k, v := range ParseNotes(b.Notes) {
 			meta[k] = v
 		}
+		MergePodLabels(meta, b.Labels)
 		beads = append(beads, AgentBead{
 			ID:         b.ID,
 			Title:      b.Title,
@@ -127,6 +141,8 @@ func (c *Client) ListAgentBeads(ctx context.Context) ([]AgentBead, error) {
 			AgentName:  name,
 			AgentState: fields["agent_state"],
 			PodPhase:   fields["pod_phase"],
+			Priority:   b.Priority,
+			CreatedAt:  parseTimestamp(b.CreatedAt),
 			Metadata:   meta,
 		})
 	}
@@ -152,62 +168,39 @@ type RepoEntry struct {
 
 // ProjectInfo represents a registered project from daemon project beads.
 type ProjectInfo struct {
-	Name           string // Project name (from bead title)
-	Prefix         string // Beads prefix (e.g., "kd", "bot")
-	GitURL         string // Repository URL
-	DefaultBranch  string // Default branch (e.g., "main")
-	Image          string // Per-project agent image override
-	StorageClass   string // Per-project PVC storage class override
-	ServiceAccount string // Per-project K8s ServiceAccount override
-	RTKEnabled     bool   // Enable RTK token optimization for this project
-	Secrets        []SecretEntry // Per-project secret overrides
-	Repos          []RepoEntry   // Multi-repo definitions
-}
-
-// ListProjectBeads queries the daemon for project beads (type=project) and extracts
-// project metadata from fields. Returns a map of project name -> ProjectInfo.
-func (c *Client) ListProjectBeads(ctx context.Context) (map[string]ProjectInfo, error) {
-	resp, err := c.listBeads(ctx, []string{"project"}, activeStatuses)
-	if err != nil {
-		return nil, fmt.Errorf("listing project beads: %w", err)
-	}
-
-	rigs := make(map[string]ProjectInfo)
-	for _, b := range resp.Beads {
-		// Strip "Project: " prefix from title -- legacy project beads may have titles
-		// like "Project: beads" instead of just "beads".
-		name := strings.TrimPrefix(b.Title, "Project: ")
-		fields := b.fieldsMap()
-		info := ProjectInfo{
-			Name:           name,
-			Prefix:         fields["prefix"],
-			GitURL:         fields["git_url"],
-			DefaultBranch:  fields["default_branch"],
-			Image:          fields["image"],
-			StorageClass:   fields["storage_class"],
-			ServiceAccount: fields["service_account"],
-			RTKEnabled:     fields["rtk_enabled"] == "true",
-		}
-		// Parse per-project secrets from JSON field.
-		if raw := fields["secrets"]; raw != "" {
-			var secrets []SecretEntry
-			if json.Unmarshal([]byte(raw), &secrets) == nil {
-				info.Secrets = secrets
-			}
-		}
-		// Parse multi-repo definitions from JSON field.
-		if raw := fields["repos"]; raw != "" {
-			var repos []RepoEntry
-			if json.Unmarshal([]byte(raw), &repos) == nil {
-				info.Repos = repos
-			}
-		}
-		if name != "" {
-			rigs[name] = info
-		}
-	}
-
-	return rigs, nil
+	ID                  string        // Project bead ID
+	Name                string        // Project name (from bead title)
+	Prefix              string        // Beads prefix (e.g., "kd", "bot")
+	GitURL              string        // Repository URL
+	DefaultBranch       string        // Default branch (e.g., "main")
+	Image               string        // Per-project agent image override
+	StorageClass        string        // Per-project PVC storage class override
+	ServiceAccount      string        // Per-project K8s ServiceAccount override
+	RTKEnabled          bool          // Enable RTK token optimization for this project
+	ReconcilePaused     bool          // Controller leaves this project's pods alone
+	Cluster             string        // Pins the project's agents to a named cluster
+	Schedule            string        // Active hours for the project's agents (see ScheduleField)
+	Arch                string        // CPU architecture for the project's agents (see ArchField)
+	TerminationGrace    string        // Shutdown time of the project's agent pods (see TerminationGraceField)
+	PreStop             string        // PreStop hook template of the project's agent pods (see PreStopField)
+	Onboarding          string        // Onboarding state, set by the controller (see OnboardingField)
+	BudgetSoft          string        // Daily spend in USD that notifies (see BudgetSoftField)
+	BudgetHard          string        // Daily spend in USD that pauses new agents (see BudgetHardField)
+	BudgetOverrideUntil string        // Hard cap override expiry, RFC 3339, set via the admin API
+	BudgetReset         *BudgetReset  // Spend discounted by a reset, set via the admin API
+	Budget              *BudgetStatus // Spend today, set by the controller
+	Secrets             []SecretEntry // Per-project secret overrides
+	Repos               []RepoEntry   // Multi-repo definitions
+
+	// Name resolution in the project's agent pods (see HostAliasesField).
+	HostAliases []corev1.HostAlias
+	DNSPolicy   string
+	DNSConfig   *corev1.PodDNSConfig
+
+	// Egress proxy of the project's agent pods (see HTTPProxyField).
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
 }
 
 // ListTaskBeads queries the daemon for active task beads (type=task).
@@ -264,6 +257,7 @@ type CreateBeadRequest struct {
 	Labels      []string        `json:"labels,omitempty"`
 	Priority    int             `json:"priority,omitempty"`
 	CreatedBy   string          `json:"created_by,omitempty"`
+	DueAt       string          `json:"due_at,omitempty"` // RFC 3339
 	Fields      json.RawMessage `json:"fields,omitempty"`
 }
 
@@ -288,31 +282,109 @@ func (c *Client) CreateBead(ctx context.Context, req CreateBeadRequest) (string,
 // (type "assigned") linking the agent bead to the task. The dependency is best-effort:
 // if it fails the agent bead is still returned.
 func (c *Client) SpawnAgent(ctx context.Context, agentName, project, taskID, role string) (string, error) {
+	return c.SpawnAgentWith(ctx, SpawnAgentRequest{
+		AgentName: agentName,
+		Project:   project,
+		TaskID:    taskID,
+		Role:      role,
+	})
+}
+
+// SpawnAgentRequest describes an agent to spawn. See SpawnAgent.
+type SpawnAgentRequest struct {
+	AgentName string
+	Project   string
+	TaskID    string
+	Role      string
+	Image     string // pins the agent image instead of the project/controller default
+	Arch      string // CPU architecture instead of the project/role default (see ArchField)
+	// Template names a template bead to base the agent on (see
+	// TemplateField). Its role and mode apply unless Role is set; the
+	// rest of its fields are resolved by the controller.
+	Template string
+	// Priority orders the agent in the controller's spawn queue (0 =
+	// critical). Nil leaves the daemon default.
+	Priority *int
+	// Mode overrides the agent mode ("crew", or the template's mode).
+	Mode string
+	// Parent is the bead ID of the agent that delegated this one its work
+	// (see ParentAgentField).
+	Parent string
+}
+
+// SpawnAgentWith creates a new agent bead from req. It behaves like
+// SpawnAgent; the image pin is set at creation so the first pod uses it.
+func (c *Client) SpawnAgentWith(ctx context.Context, req SpawnAgentRequest) (string, error) {
+	agentName, project, taskID, role := req.AgentName, req.Project, req.TaskID, req.Role
+	mode := "crew"
+	if req.Template != "" {
+		// Role and mode identify the agent's pod, so they are copied onto
+		// the bead rather than resolved later.
+		templates, err := c.ListTemplateBeads(ctx)
+		if err != nil {
+			return "", fmt.Errorf("spawning agent %q: %w", agentName, err)
+		}
+		t, ok := templates[req.Template]
+		if !ok {
+			return "", fmt.Errorf("spawning agent %q: template %q not found", agentName, req.Template)
+		}
+		if role == "" {
+			role = t.Fields["role"]
+			if m := t.Fields["mode"]; m != "" {
+				mode = m
+			}
+		}
+	}
 	if role == "" {
 		role = "crew"
 	}
+	if req.Mode != "" {
+		mode = req.Mode
+	}
 	fields := map[string]string{
 		"agent":   agentName,
-		"mode":    "crew",
+		"mode":    mode,
 		"role":    role,
 		"project": project,
 	}
+	if req.Template != "" {
+		fields[TemplateField] = req.Template
+	}
+	if req.Image != "" {
+		fields["image"] = req.Image
+	}
+	if req.Arch != "" {
+		fields[ArchField] = req.Arch
+	}
+	if req.Parent != "" {
+		fields[ParentAgentField] = req.Parent
+	}
 	fieldsJSON, err := json.Marshal(fields)
 	if err != nil {
 		return "", fmt.Errorf("marshalling agent fields: %w", err)
 	}
-	req := CreateBeadRequest{
+	create := CreateBeadRequest{
 		Title:  agentName,
 		Type:   "agent",
 		Fields: json.RawMessage(fieldsJSON),
 	}
 	if taskID != "" {
-		req.Description = "Assigned to task: " + taskID
+		create.Description = "Assigned to task: " + taskID
+	}
+	if req.Priority != nil {
+		create.Priority = *req.Priority
 	}
-	id, err := c.CreateBead(ctx, req)
+	id, err := c.CreateBead(ctx, create)
 	if err != nil {
 		return "", fmt.Errorf("spawning agent %q: %w", agentName, err)
 	}
+	if req.Priority != nil && *req.Priority == 0 {
+		// CreateBeadRequest omits a zero priority, leaving the daemon
+		// default; set P0 explicitly.
+		if err := c.UpdateBead(ctx, id, UpdateBeadRequest{Priority: req.Priority}); err != nil {
+			return id, fmt.Errorf("setting agent %q priority: %w", agentName, err)
+		}
+	}
 	if project != "" {
 		// Best-effort: label the agent bead with its project so it appears in
 		// project-scoped listings (kd list, gb ready with --project filter).
@@ -343,7 +415,9 @@ type BeadDetail struct {
 	Description string            `json:"description"`
 	CreatedBy   string            `json:"created_by"`
 	DueAt       string            `json:"due_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at,omitempty"`
 	UpdatedAt   time.Time         `json:"updated_at,omitempty"`
+	ClosedAt    time.Time         `json:"closed_at,omitempty"`
 }
 
 // GetBead fetches a single bead by ID from the daemon.
@@ -464,7 +538,9 @@ type beadJSON struct {
 	Description string          `json:"description"`
 	CreatedBy   string          `json:"created_by"`
 	DueAt       string          `json:"due_at,omitempty"`
+	CreatedAt   string          `json:"created_at,omitempty"`
 	UpdatedAt   string          `json:"updated_at,omitempty"`
+	ClosedAt    string          `json:"closed_at,omitempty"`
 }
 
 // ParseFieldsJSON decodes a raw JSON object into a map[string]string.
@@ -543,7 +619,9 @@ func (b *beadJSON) toDetail() *BeadDetail {
 		Description: b.Description,
 		CreatedBy:   b.CreatedBy,
 		DueAt:       b.DueAt,
+		CreatedAt:   parseTimestamp(b.CreatedAt),
 		UpdatedAt:   parseTimestamp(b.UpdatedAt),
+		ClosedAt:    parseTimestamp(b.ClosedAt),
 	}
 }
 
@@ -555,24 +633,8 @@ type listBeadsResponse struct {
 
 // listBeads queries the daemon for beads matching the given type and status filters.
 func (c *Client) listBeads(ctx context.Context, types, statuses []string) (*listBeadsResponse, error) {
-	q := url.Values{}
-	if len(types) > 0 {
-		q.Set("type", strings.Join(types, ","))
-	}
-	if len(statuses) > 0 {
-		q.Set("status", strings.Join(statuses, ","))
-	}
-
-	path := "/v1/beads"
-	if len(q) > 0 {
-		path += "?" + q.Encode()
-	}
-
-	var resp listBeadsResponse
-	if err := c.doJSON(ctx, http.MethodGet, path, nil, &resp); err != nil {
-		return nil, err
-	}
-	return &resp, nil
+	resp, _, err := c.listBeadsIfChanged(ctx, types, statuses, "")
+	return resp, err
 }
 
 // APIError represents an error response from the daemon HTTP API.
@@ -588,18 +650,28 @@ func (e *APIError) Error() string {
 // doJSON performs an HTTP request with optional JSON body and decodes the JSON response.
 // If result is nil, the response body is discarded (for responses where we don't need the body).
 func (c *Client) doJSON(ctx context.Context, method, path string, body any, result any) error {
+	_, err := c.doJSONHeader(ctx, method, path, body, result, nil)
+	return err
+}
+
+// doJSONHeader is doJSON sending the extra request headers reqHeader and
+// returning the response headers. A 304 response returns ErrNotModified.
+func (c *Client) doJSONHeader(ctx context.Context, method, path string, body any, result any, reqHeader http.Header) (http.Header, error) {
 	var bodyReader io.Reader
 	if body != nil {
 		data, err := json.Marshal(body)
 		if err != nil {
-			return fmt.Errorf("marshaling request body: %w", err)
+			return nil, fmt.Errorf("marshaling request body: %w", err)
 		}
 		bodyReader = bytes.NewReader(data)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for key, values := range reqHeader {
+		req.Header[key] = values
 	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -607,18 +679,21 @@ func (c *Client) doJSON(ctx context.Context, method, path string, body any, resu
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("performing request: %w", err)
+		return nil, fmt.Errorf("performing request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// 204 No Content -- success with no body.
 	if resp.StatusCode == http.StatusNoContent {
-		return nil
+		return resp.Header, nil
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return resp.Header, ErrNotModified
 	}
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("reading response: %w", err)
+		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
@@ -626,18 +701,18 @@ func (c *Client) doJSON(ctx context.Context, method, path string, body any, resu
 			Error string `json:"error"`
 		}
 		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error != "" {
-			return &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
+			return nil, &APIError{StatusCode: resp.StatusCode, Message: errResp.Error}
 		}
-		return &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: string(respBody)}
 	}
 
 	if result != nil {
 		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("decoding response: %w", err)
+			return nil, fmt.Errorf("decoding response: %w", err)
 		}
 	}
 
-	return nil
+	return resp.Header, nil
 }
 
 // ParseNotes parses "key: value" lines from a bead's notes field into a map.
@@ -0,0 +1,64 @@
+package beadsapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CoopRegistryKey is the daemon config key holding the coop service
+// registry. The controller is its only writer and rewrites it whole from
+// the live agent pods, so readers (coopmux, the slack bridge) never see a
+// half-updated registry or a URL left behind by a pod that is gone.
+const CoopRegistryKey = "coopmux:registry"
+
+// CoopEndpoint is how to reach one agent's coop session.
+type CoopEndpoint struct {
+	Agent     string `json:"agent"`
+	URL       string `json:"url"`
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster,omitempty"`
+}
+
+// CoopRegistry maps agent bead IDs to their coop endpoints.
+type CoopRegistry struct {
+	// Revision increases with every write, so readers can tell whether
+	// anything changed since they last looked.
+	Revision  int64                   `json:"revision"`
+	UpdatedAt time.Time               `json:"updated_at"`
+	Agents    map[string]CoopEndpoint `json:"agents"`
+}
+
+// GetCoopRegistry fetches the coop service registry. It returns (nil, nil)
+// if no controller has published one yet.
+func (c *Client) GetCoopRegistry(ctx context.Context) (*CoopRegistry, error) {
+	entry, err := c.GetConfig(ctx, CoopRegistryKey)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var reg CoopRegistry
+	if err := json.Unmarshal(entry.Value, &reg); err != nil {
+		return nil, fmt.Errorf("decoding coop registry: %w", err)
+	}
+	if reg.Agents == nil {
+		reg.Agents = make(map[string]CoopEndpoint)
+	}
+	return &reg, nil
+}
+
+// SetCoopRegistry replaces the coop service registry.
+func (c *Client) SetCoopRegistry(ctx context.Context, reg *CoopRegistry) error {
+	value, err := json.Marshal(reg)
+	if err != nil {
+		return fmt.Errorf("encoding coop registry: %w", err)
+	}
+	return c.SetConfig(ctx, CoopRegistryKey, value)
+}
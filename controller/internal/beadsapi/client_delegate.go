@@ -0,0 +1,140 @@
+package beadsapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Delegation fields. A delegated task records who delegated it, so its
+// result can be sent back; an agent spawned for the task records its
+// parent agent, so the controller stops it once the parent is gone.
+const (
+	// ParentAgentField is the agent bead field holding the bead ID of the
+	// agent that delegated this agent its work.
+	ParentAgentField = "parent_agent"
+	// DelegatedByField is the task bead field naming the delegating agent.
+	DelegatedByField = "delegated_by"
+	// ParentTaskField is the task bead field holding the delegating
+	// agent's own task, if any.
+	ParentTaskField = "parent_task"
+	// DelegationResultField holds the delegate's result on the closed task.
+	DelegationResultField = "result"
+)
+
+// DelegatedLabel marks task beads created by Delegate.
+const DelegatedLabel = "delegated"
+
+// DelegateRequest describes a subtask one agent hands to another.
+type DelegateRequest struct {
+	Title       string
+	Description string
+	Project     string
+	Priority    *int // nil leaves the daemon default
+
+	From          string // delegating agent's name
+	FromAgentID   string // delegating agent's bead ID (parent of a spawned delegate)
+	ParentTask    string // delegating agent's task; the subtask becomes its child
+	To            string // delegate agent's name
+	SpawnDelegate bool   // spawn To as a new job-mode agent for the subtask
+}
+
+// Delegation is the result of Delegate.
+type Delegation struct {
+	TaskID  string `json:"task_id"`
+	Agent   string `json:"agent"`
+	AgentID string `json:"agent_id,omitempty"` // set when a delegate was spawned
+}
+
+// Delegate creates a task bead assigned to req.To and, with SpawnDelegate,
+// a job-mode agent to work it. The spawned agent's parent is the delegating
+// agent, so closing the parent cancels it.
+func (c *Client) Delegate(ctx context.Context, req DelegateRequest) (*Delegation, error) {
+	if req.To == "" {
+		return nil, fmt.Errorf("delegating %q: no delegate agent", req.Title)
+	}
+	fields := map[string]string{DelegatedByField: req.From}
+	if req.ParentTask != "" {
+		fields[ParentTaskField] = req.ParentTask
+	}
+	if req.FromAgentID != "" {
+		fields[ParentAgentField] = req.FromAgentID
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling task fields: %w", err)
+	}
+	labels := []string{DelegatedLabel}
+	if req.Project != "" {
+		labels = append(labels, "project:"+req.Project)
+	}
+	taskID, err := c.CreateBead(ctx, CreateBeadRequest{
+		Title:       req.Title,
+		Type:        "task",
+		Kind:        "issue",
+		Description: req.Description,
+		Assignee:    req.To,
+		Labels:      labels,
+		CreatedBy:   req.From,
+		Fields:      json.RawMessage(fieldsJSON),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("delegating %q: %w", req.Title, err)
+	}
+	d := &Delegation{TaskID: taskID, Agent: req.To}
+	if req.Priority != nil {
+		if err := c.UpdateBead(ctx, taskID, UpdateBeadRequest{Priority: req.Priority}); err != nil {
+			return d, fmt.Errorf("setting task %s priority: %w", taskID, err)
+		}
+	}
+	if req.ParentTask != "" {
+		if err := c.AddDependency(ctx, taskID, req.ParentTask, "parent-child", req.From); err != nil {
+			return d, fmt.Errorf("linking task %s to parent %s: %w", taskID, req.ParentTask, err)
+		}
+	}
+	if !req.SpawnDelegate {
+		return d, nil
+	}
+	d.AgentID, err = c.SpawnAgentWith(ctx, SpawnAgentRequest{
+		AgentName: req.To,
+		Project:   req.Project,
+		TaskID:    taskID,
+		Role:      "job",
+		Mode:      "job",
+		Parent:    req.FromAgentID,
+		Priority:  req.Priority,
+	})
+	return d, err
+}
+
+// CompleteDelegation closes a delegated task with result and mails the
+// result to the agent that delegated it, by whom naming the delegate.
+func (c *Client) CompleteDelegation(ctx context.Context, taskID, result, by string) error {
+	task, err := c.GetBead(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	from := task.Fields[DelegatedByField]
+	if from == "" {
+		return fmt.Errorf("task %s was not delegated", taskID)
+	}
+	if err := c.UpdateBeadFields(ctx, taskID, map[string]string{DelegationResultField: result}); err != nil {
+		return err
+	}
+	if err := c.CloseBead(ctx, taskID, nil); err != nil {
+		return err
+	}
+	if _, err := c.CreateBead(ctx, CreateBeadRequest{
+		Title:       fmt.Sprintf("Delegated task done: %s", task.Title),
+		Type:        "mail",
+		Kind:        "data",
+		Description: fmt.Sprintf("%s finished %s (%s).\n\n%s", by, taskID, task.Title, result),
+		Assignee:    from,
+		Labels:      []string{"from:" + by, "delegation:" + taskID},
+		CreatedBy:   by,
+		Priority:    2,
+	}); err != nil {
+		return fmt.Errorf("mailing result of %s to %s: %w", taskID, from, err)
+	}
+	return nil
+}
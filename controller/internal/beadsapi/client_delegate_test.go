@@ -0,0 +1,90 @@
+package beadsapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDelegate_SpawnsJobAgentWithParent(t *testing.T) {
+	type request struct {
+		path string
+		body map[string]json.RawMessage
+	}
+	var requests []request
+	created := 0
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var parsed map[string]json.RawMessage
+		_ = json.Unmarshal(body, &parsed)
+		requests = append(requests, request{r.URL.Path, parsed})
+		if r.URL.Path == "/v1/beads" {
+			created++
+			id := "kd-task-1"
+			if created > 1 {
+				id = "bd-agent-2"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": id})
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := &Client{baseURL: srv.URL, httpClient: srv.Client()}
+	d, err := c.Delegate(context.Background(), DelegateRequest{
+		Title:         "Bisect the flaky test",
+		Project:       "gasboat",
+		From:          "lead",
+		FromAgentID:   "bd-agent-1",
+		ParentTask:    "kd-parent",
+		To:            "bisector",
+		SpawnDelegate: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.TaskID != "kd-task-1" || d.AgentID != "bd-agent-2" || d.Agent != "bisector" {
+		t.Errorf("delegation = %+v", d)
+	}
+
+	var taskFields, agentFields map[string]string
+	var assignee string
+	_ = json.Unmarshal(requests[0].body["fields"], &taskFields)
+	_ = json.Unmarshal(requests[0].body["assignee"], &assignee)
+	if assignee != "bisector" || taskFields[DelegatedByField] != "lead" || taskFields[ParentTaskField] != "kd-parent" {
+		t.Errorf("task assignee = %q, fields = %v", assignee, taskFields)
+	}
+
+	linked := false
+	for _, req := range requests {
+		if req.path == "/v1/beads/kd-task-1/dependencies" {
+			var depType string
+			_ = json.Unmarshal(req.body["type"], &depType)
+			linked = depType == "parent-child"
+		}
+	}
+	if !linked {
+		t.Error("task not linked to its parent task")
+	}
+
+	for _, req := range requests[1:] {
+		if req.path == "/v1/beads" {
+			_ = json.Unmarshal(req.body["fields"], &agentFields)
+		}
+	}
+	if agentFields["mode"] != "job" || agentFields["role"] != "job" || agentFields[ParentAgentField] != "bd-agent-1" {
+		t.Errorf("agent fields = %v, want job mode with parent bd-agent-1", agentFields)
+	}
+}
+
+func TestDelegate_RequiresDelegate(t *testing.T) {
+	c := &Client{baseURL: "http://unused", httpClient: http.DefaultClient}
+	if _, err := c.Delegate(context.Background(), DelegateRequest{Title: "x", From: "lead"}); err == nil {
+		t.Fatal("expected error without a delegate agent")
+	}
+}
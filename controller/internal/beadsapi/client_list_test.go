@@ -3,10 +3,12 @@ package beadsapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 // --- ListAgentBeads tests ---
@@ -42,12 +44,15 @@ func TestListAgentBeads_ParsesBeads(t *testing.T) {
 		resp := listBeadsResponse{
 			Beads: []beadJSON{
 				{
-					ID:     "crew-town-crew-hq",
-					Title:  "Agent: hq",
-					Type:   "agent",
-					Status: "open",
-					Notes:  "coop_url: http://coop:9090\npod_name: agent-hq-0",
-					Fields: json.RawMessage(`{"project":"town","mode":"crew","role":"crew","agent":"hq"}`),
+					ID:        "crew-town-crew-hq",
+					Title:     "Agent: hq",
+					Type:      "agent",
+					Status:    "open",
+					Notes:     "coop_url: http://coop:9090\npod_name: agent-hq-0",
+					Fields:    json.RawMessage(`{"project":"town","mode":"crew","role":"crew","agent":"hq"}`),
+					Labels:    []string{"k8s/team=town"},
+					Priority:  1,
+					CreatedAt: "2026-03-01T12:00:00Z",
 				},
 				{
 					ID:     "crew-gasboat-crew-k8s",
@@ -97,6 +102,15 @@ func TestListAgentBeads_ParsesBeads(t *testing.T) {
 	if b0.Metadata["pod_name"] != "agent-hq-0" {
 		t.Errorf("expected pod_name metadata, got %v", b0.Metadata)
 	}
+	if b0.Metadata[PodLabelsField] != `{"team":"town"}` {
+		t.Errorf("expected pod_labels from k8s/ bead label, got %v", b0.Metadata)
+	}
+	if b0.Priority != 1 {
+		t.Errorf("expected priority 1, got %d", b0.Priority)
+	}
+	if want := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC); !b0.CreatedAt.Equal(want) {
+		t.Errorf("expected created_at %v, got %v", want, b0.CreatedAt)
+	}
 
 	// Second bead -- mode defaults to "crew" when empty.
 	b1 := beads[1]
@@ -412,3 +426,40 @@ func TestListProjectBeads_SkipsEmptyTitle(t *testing.T) {
 		t.Errorf("expected 0 projects for empty title, got %d", len(projects))
 	}
 }
+
+func TestListProjectBeadsIfChanged_ETag(t *testing.T) {
+	var gotIfNoneMatch string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotIfNoneMatch = r.Header.Get("If-None-Match")
+		if gotIfNoneMatch == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_ = json.NewEncoder(w).Encode(listBeadsResponse{
+			Beads: []beadJSON{{ID: "proj-beads", Title: "beads", Fields: json.RawMessage(`{"prefix":"bd"}`)}},
+			Total: 1,
+		})
+	}))
+	defer srv.Close()
+
+	c := &Client{baseURL: srv.URL, httpClient: srv.Client()}
+	projects, etag, err := c.ListProjectBeadsIfChanged(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotIfNoneMatch != "" {
+		t.Errorf("If-None-Match = %q on the first listing, want none", gotIfNoneMatch)
+	}
+	if etag != `"v1"` || projects["beads"].Prefix != "bd" {
+		t.Errorf("got etag %q, projects %+v", etag, projects)
+	}
+
+	projects, etag, err = c.ListProjectBeadsIfChanged(context.Background(), etag)
+	if !errors.Is(err, ErrNotModified) {
+		t.Fatalf("err = %v, want ErrNotModified", err)
+	}
+	if etag != `"v1"` || projects != nil {
+		t.Errorf("got etag %q, projects %+v; want the last etag and no projects", etag, projects)
+	}
+}
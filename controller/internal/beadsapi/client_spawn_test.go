@@ -252,3 +252,40 @@ func TestSpawnAgent_WithTaskID_SetsDescriptionAndLinksDependency(t *testing.T) {
 		t.Errorf("expected dep type=assigned, got %s", depType)
 	}
 }
+
+func TestSpawnAgentWith_Priority(t *testing.T) {
+	var creates, patches []map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var parsed map[string]json.RawMessage
+		_ = json.Unmarshal(body, &parsed)
+		switch {
+		case r.URL.Path == "/v1/beads":
+			creates = append(creates, parsed)
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "bd-agent-7"})
+		case r.Method == http.MethodPatch:
+			patches = append(patches, parsed)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{baseURL: srv.URL, httpClient: srv.Client()}
+	for _, p := range []int{1, 0} {
+		if _, err := c.SpawnAgentWith(context.Background(), SpawnAgentRequest{
+			AgentName: "fixer", Project: "gasboat", Priority: &p,
+		}); err != nil {
+			t.Fatalf("priority %d: %v", p, err)
+		}
+	}
+
+	if string(creates[0]["priority"]) != "1" {
+		t.Errorf("P1 create sent priority %s, want 1", creates[0]["priority"])
+	}
+	// A zero priority is dropped from the create and set with a PATCH.
+	if len(patches) != 1 || string(patches[0]["priority"]) != "0" {
+		t.Errorf("patches = %v, want one setting priority 0", patches)
+	}
+}
@@ -417,3 +417,63 @@ func TestToDetail_ZeroUpdatedAtWhenMissing(t *testing.T) {
 	}
 }
 
+func TestToDetail_ParsesCreatedAndClosedAt(t *testing.T) {
+	b := beadJSON{
+		ID:        "bd-closed",
+		CreatedAt: "2026-02-25T08:00:00Z",
+		ClosedAt:  "2026-02-25 09:30:00",
+	}
+	detail := b.toDetail()
+	if got := detail.ClosedAt.Sub(detail.CreatedAt).Minutes(); got != 90 {
+		t.Errorf("ClosedAt - CreatedAt = %vm, want 90m", got)
+	}
+}
+
+
+func TestCoopRegistry_RoundTrip(t *testing.T) {
+	stored := map[string]json.RawMessage{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/v1/configs/")
+		switch r.Method {
+		case http.MethodPut:
+			var body struct {
+				Value json.RawMessage `json:"value"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			stored[key] = body.Value
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodGet:
+			value, ok := stored[key]
+			if !ok {
+				http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(ConfigEntry{Key: key, Value: value})
+		}
+	}))
+	defer srv.Close()
+	c := &Client{baseURL: srv.URL, httpClient: srv.Client()}
+	ctx := context.Background()
+
+	reg, err := c.GetCoopRegistry(ctx)
+	if err != nil || reg != nil {
+		t.Fatalf("before publishing: got %+v, %v; want nil, nil", reg, err)
+	}
+
+	want := &CoopRegistry{Revision: 3, Agents: map[string]CoopEndpoint{
+		"kd-1": {Agent: "alpha", URL: "http://10.0.0.1:8080", Pod: "crew-p-dev-alpha", Namespace: "ns"},
+	}}
+	if err := c.SetCoopRegistry(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := stored[CoopRegistryKey]; !ok {
+		t.Fatalf("registry not stored under %s: %v", CoopRegistryKey, stored)
+	}
+	got, err := c.GetCoopRegistry(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Revision != 3 || got.Agents["kd-1"] != want.Agents["kd-1"] {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
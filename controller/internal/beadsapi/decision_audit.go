@@ -0,0 +1,55 @@
+package beadsapi
+
+import (
+	"strings"
+	"time"
+)
+
+// Decision resolution audit fields, recorded on the decision bead by every
+// resolution path (Slack, Discord, the web UIs, gb).
+const (
+	// FieldRespondedBy is the normalized identity of the responder: an
+	// email address when known, else "<provider>:<subject>".
+	FieldRespondedBy = "responded_by"
+	// FieldRespondedByName is the responder's display name at the time.
+	FieldRespondedByName = "responded_by_name"
+	// FieldRespondedVia names the resolution path, e.g. "slack" or "gb".
+	FieldRespondedVia = "responded_via"
+	// FieldRespondedAt is the resolution time (RFC 3339, UTC).
+	FieldRespondedAt = "responded_at"
+)
+
+// Responder identifies who resolved a decision.
+type Responder struct {
+	Identity string // see NormalizeIdentity
+	Name     string
+	Via      string
+}
+
+// NormalizeIdentity returns the identity recorded for a responder: the
+// lowercased email when known, else "<provider>:<subject>" (e.g.
+// "slack:U012AB3CD"), else "<provider>:unknown".
+func NormalizeIdentity(provider, subject, email string) string {
+	if email = strings.ToLower(strings.TrimSpace(email)); strings.Contains(email, "@") {
+		return email
+	}
+	if subject = strings.TrimSpace(subject); subject == "" {
+		subject = "unknown"
+	}
+	return provider + ":" + subject
+}
+
+// AuditFields returns the audit fields recording r's resolution at now.
+func (r Responder) AuditFields(now time.Time) map[string]string {
+	fields := map[string]string{
+		FieldRespondedBy: r.Identity,
+		FieldRespondedAt: now.UTC().Format(time.RFC3339),
+	}
+	if r.Name != "" {
+		fields[FieldRespondedByName] = r.Name
+	}
+	if r.Via != "" {
+		fields[FieldRespondedVia] = r.Via
+	}
+	return fields
+}
@@ -0,0 +1,45 @@
+package beadsapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeIdentity(t *testing.T) {
+	tests := []struct {
+		provider, subject, email, want string
+	}{
+		{"slack", "U123", " Alice@Example.COM ", "alice@example.com"},
+		{"slack", "U123", "", "slack:U123"},
+		{"discord", "42", "not-an-email", "discord:42"},
+		{"web", "", "", "web:unknown"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeIdentity(tt.provider, tt.subject, tt.email); got != tt.want {
+			t.Errorf("NormalizeIdentity(%q, %q, %q) = %q, want %q", tt.provider, tt.subject, tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestResponderAuditFields(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.FixedZone("CET", 3600))
+	got := Responder{Identity: "alice@example.com", Name: "alice", Via: "gb"}.AuditFields(now)
+	want := map[string]string{
+		FieldRespondedBy:     "alice@example.com",
+		FieldRespondedByName: "alice",
+		FieldRespondedVia:    "gb",
+		FieldRespondedAt:     "2026-03-01T09:00:00Z",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("AuditFields = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if got := (Responder{Identity: "slack:U1"}).AuditFields(now); len(got) != 2 {
+		t.Errorf("AuditFields without name or via = %v, want only identity and time", got)
+	}
+}
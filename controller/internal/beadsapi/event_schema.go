@@ -0,0 +1,245 @@
+package beadsapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Bead event payload schema versions. Payloads without a "version" key are v1.
+//
+// v1: {"bead": {...}, "changes": {"field": new, ...}, "closed_by": "..."}
+// and {"bead_id": "..."} for deletes.
+//
+// v2: {"version": 2, "bead": {...}, "changes": [{"field", "old", "new"}, ...],
+// "actor": "..."}; deletes carry a bead with only "id".
+const (
+	EventSchemaV1 = 1
+	EventSchemaV2 = 2
+)
+
+// ErrUnsupportedEventVersion is returned for payloads newer than this client.
+var ErrUnsupportedEventVersion = errors.New("unsupported bead event schema version")
+
+// ErrUnknownEventFields is returned in strict mode when a payload carries
+// fields this schema does not know.
+var ErrUnknownEventFields = errors.New("unknown bead event fields")
+
+// EventBead is the bead carried by a bead lifecycle event.
+type EventBead struct {
+	ID          string          `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Kind        string          `json:"kind"`
+	Type        string          `json:"type"`
+	Status      string          `json:"status"`
+	Priority    int             `json:"priority"`
+	Assignee    string          `json:"assignee"`
+	CreatedBy   string          `json:"created_by"`
+	Labels      []string        `json:"labels"`
+	Notes       string          `json:"notes"`
+	Fields      json.RawMessage `json:"fields"`
+	AgentState  string          `json:"agent_state"`
+	CreatedAt   string          `json:"created_at"`
+	UpdatedAt   string          `json:"updated_at"`
+	ClosedAt    string          `json:"closed_at"`
+	DueAt       string          `json:"due_at"`
+}
+
+// FieldsMap decodes the bead's fields into a string map (see ParseFieldsJSON).
+func (b *EventBead) FieldsMap() map[string]string {
+	return ParseFieldsJSON(b.Fields)
+}
+
+// BeadEventPayload is a bead lifecycle event normalized to one shape,
+// whatever schema version the daemon sent.
+type BeadEventPayload struct {
+	Version int
+	Bead    *EventBead     // nil for v1 deletes
+	BeadID  string         // always set
+	Changes map[string]any // changed field → new value (updated events)
+	Actor   string         // who closed the bead (v1 closed_by)
+}
+
+// knownEventKeys lists the keys each schema defines, for unknown-field
+// detection. Bead keys are shared by both versions.
+var (
+	knownV1Keys   = keySet("bead", "changes", "closed_by", "bead_id")
+	knownV2Keys   = keySet("version", "bead", "changes", "actor")
+	knownBeadKeys = keySet("id", "title", "description", "kind", "type", "status", "priority",
+		"assignee", "owner", "created_by", "labels", "notes", "fields", "agent_state",
+		"created_at", "updated_at", "closed_at", "closed_by", "due_at", "defer_until")
+)
+
+func keySet(keys ...string) map[string]bool {
+	m := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		m[k] = true
+	}
+	return m
+}
+
+// DecodeBeadEvent decodes a bead event payload of any supported version,
+// ignoring unknown fields.
+func DecodeBeadEvent(data []byte) (*BeadEventPayload, error) {
+	p, _, err := decodeBeadEvent(data)
+	return p, err
+}
+
+// EventDecoder decodes bead event payloads and counts fields the schema does
+// not know, so daemon changes show up in metrics instead of mis-parsing
+// silently. The zero value is a lenient decoder.
+type EventDecoder struct {
+	// Strict rejects payloads with unknown fields (ErrUnknownEventFields).
+	Strict bool
+	// OnUnknown, when set, is called once per unknown field, e.g. ("beads.bead.updated", "bead.owner_team").
+	OnUnknown func(topic, field string)
+
+	mu      sync.Mutex
+	unknown map[string]int64 // field → occurrences
+}
+
+// Decode decodes one payload received on topic.
+func (d *EventDecoder) Decode(topic string, data []byte) (*BeadEventPayload, error) {
+	p, unknown, err := decodeBeadEvent(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		d.mu.Lock()
+		if d.unknown == nil {
+			d.unknown = make(map[string]int64)
+		}
+		for _, f := range unknown {
+			d.unknown[f]++
+		}
+		d.mu.Unlock()
+		if d.OnUnknown != nil {
+			for _, f := range unknown {
+				d.OnUnknown(topic, f)
+			}
+		}
+		if d.Strict {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownEventFields, strings.Join(unknown, ", "))
+		}
+	}
+	return p, nil
+}
+
+// UnknownFields returns how often each unknown field has been seen.
+func (d *EventDecoder) UnknownFields() map[string]int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make(map[string]int64, len(d.unknown))
+	for k, v := range d.unknown {
+		out[k] = v
+	}
+	return out
+}
+
+// decodeBeadEvent decodes data and returns the unknown fields it carried
+// ("top" keys as-is, bead keys as "bead.<key>"), sorted.
+func decodeBeadEvent(data []byte) (*BeadEventPayload, []string, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, nil, fmt.Errorf("decoding bead event: %w", err)
+	}
+
+	version := EventSchemaV1
+	if raw, ok := top["version"]; ok {
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, nil, fmt.Errorf("decoding bead event version: %w", err)
+		}
+	}
+
+	var p *BeadEventPayload
+	var known map[string]bool
+	var err error
+	switch version {
+	case EventSchemaV1:
+		p, err = decodeV1(data)
+		known = knownV1Keys
+	case EventSchemaV2:
+		p, err = decodeV2(data)
+		known = knownV2Keys
+	default:
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnsupportedEventVersion, version)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unknown []string
+	for k := range top {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	var bead map[string]json.RawMessage
+	if raw := top["bead"]; len(raw) > 0 && json.Unmarshal(raw, &bead) == nil {
+		for k := range bead {
+			if !knownBeadKeys[k] {
+				unknown = append(unknown, "bead."+k)
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return p, unknown, nil
+}
+
+func decodeV1(data []byte) (*BeadEventPayload, error) {
+	var v1 struct {
+		Bead     *EventBead     `json:"bead"`
+		Changes  map[string]any `json:"changes"`
+		ClosedBy string         `json:"closed_by"`
+		BeadID   string         `json:"bead_id"`
+	}
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, fmt.Errorf("decoding v1 bead event: %w", err)
+	}
+	p := &BeadEventPayload{
+		Version: EventSchemaV1,
+		Bead:    v1.Bead,
+		BeadID:  v1.BeadID,
+		Changes: v1.Changes,
+		Actor:   v1.ClosedBy,
+	}
+	if p.Bead != nil && p.BeadID == "" {
+		p.BeadID = p.Bead.ID
+	}
+	return p, nil
+}
+
+func decodeV2(data []byte) (*BeadEventPayload, error) {
+	var v2 struct {
+		Bead    *EventBead `json:"bead"`
+		Changes []struct {
+			Field string `json:"field"`
+			Old   any    `json:"old"`
+			New   any    `json:"new"`
+		} `json:"changes"`
+		Actor string `json:"actor"`
+	}
+	if err := json.Unmarshal(data, &v2); err != nil {
+		return nil, fmt.Errorf("decoding v2 bead event: %w", err)
+	}
+	if v2.Bead == nil || v2.Bead.ID == "" {
+		return nil, errors.New("decoding v2 bead event: missing bead id")
+	}
+	p := &BeadEventPayload{
+		Version: EventSchemaV2,
+		Bead:    v2.Bead,
+		BeadID:  v2.Bead.ID,
+		Actor:   v2.Actor,
+	}
+	if len(v2.Changes) > 0 {
+		p.Changes = make(map[string]any, len(v2.Changes))
+		for _, c := range v2.Changes {
+			p.Changes[c.Field] = c.New
+		}
+	}
+	return p, nil
+}
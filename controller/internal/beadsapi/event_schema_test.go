@@ -0,0 +1,87 @@
+package beadsapi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDecodeBeadEvent_V1(t *testing.T) {
+	p, err := DecodeBeadEvent([]byte(`{
+		"bead": {"id": "kd-1", "type": "agent", "status": "in_progress", "fields": {"agent": "a1"}},
+		"changes": {"status": "in_progress"},
+		"closed_by": "gasboat"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Version != EventSchemaV1 || p.BeadID != "kd-1" || p.Actor != "gasboat" {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+	if p.Bead.FieldsMap()["agent"] != "a1" || p.Changes["status"] != "in_progress" {
+		t.Errorf("unexpected bead/changes: %+v %v", p.Bead, p.Changes)
+	}
+
+	del, err := DecodeBeadEvent([]byte(`{"bead_id": "kd-2"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if del.Bead != nil || del.BeadID != "kd-2" {
+		t.Errorf("unexpected v1 delete: %+v", del)
+	}
+}
+
+func TestDecodeBeadEvent_V2(t *testing.T) {
+	p, err := DecodeBeadEvent([]byte(`{
+		"version": 2,
+		"bead": {"id": "kd-1", "type": "agent", "status": "in_progress"},
+		"changes": [{"field": "status", "old": "open", "new": "in_progress"}],
+		"actor": "human"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Version != EventSchemaV2 || p.BeadID != "kd-1" || p.Actor != "human" {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+	if p.Changes["status"] != "in_progress" {
+		t.Errorf("expected v2 changes normalized to new values, got %v", p.Changes)
+	}
+
+	if _, err := DecodeBeadEvent([]byte(`{"version": 2, "changes": []}`)); err == nil {
+		t.Error("expected error for v2 payload without a bead id")
+	}
+}
+
+func TestDecodeBeadEvent_RejectsUnsupportedVersion(t *testing.T) {
+	_, err := DecodeBeadEvent([]byte(`{"version": 3, "bead": {"id": "kd-1"}}`))
+	if !errors.Is(err, ErrUnsupportedEventVersion) {
+		t.Errorf("expected ErrUnsupportedEventVersion, got %v", err)
+	}
+}
+
+func TestEventDecoder_CountsAndRejectsUnknownFields(t *testing.T) {
+	data := []byte(`{"bead": {"id": "kd-1", "owner_team": "infra"}, "trace_id": "abc"}`)
+
+	var seen []string
+	lenient := &EventDecoder{OnUnknown: func(topic, field string) { seen = append(seen, topic+" "+field) }}
+	for range 2 {
+		if _, err := lenient.Decode("beads.bead.created", data); err != nil {
+			t.Fatalf("lenient decode failed: %v", err)
+		}
+	}
+	got := lenient.UnknownFields()
+	if got["bead.owner_team"] != 2 || got["trace_id"] != 2 || len(got) != 2 {
+		t.Errorf("UnknownFields() = %v", got)
+	}
+	if len(seen) != 4 || seen[0] != "beads.bead.created bead.owner_team" {
+		t.Errorf("OnUnknown calls = %v", seen)
+	}
+
+	strict := &EventDecoder{Strict: true}
+	if _, err := strict.Decode("beads.bead.created", data); !errors.Is(err, ErrUnknownEventFields) {
+		t.Errorf("expected ErrUnknownEventFields in strict mode, got %v", err)
+	}
+	if _, err := strict.Decode("beads.bead.created", []byte(`{"bead": {"id": "kd-1", "updated_at": "2026-03-01T12:00:00Z"}}`)); err != nil {
+		t.Errorf("strict mode rejected a known payload: %v", err)
+	}
+}
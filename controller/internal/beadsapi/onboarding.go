@@ -0,0 +1,81 @@
+package beadsapi
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OnboardingField is the project bead field tracking end-to-end validation
+// of a new project. While it is set to anything but OnboardingPassed the
+// controller schedules none of the project's agents. Projects without it
+// predate onboarding and are scheduled as before.
+const OnboardingField = "onboarding"
+
+// Values of OnboardingField.
+const (
+	// OnboardingPending asks the controller to validate the project.
+	OnboardingPending = "pending"
+	// OnboardingRunning is set by the controller while it validates.
+	OnboardingRunning = "running"
+	// OnboardingPassed lets the project's agents be scheduled.
+	OnboardingPassed = "passed"
+	// OnboardingFailed holds the project back until onboarding is
+	// requested again.
+	OnboardingFailed = "failed"
+)
+
+// OnboardingReportField is the project bead field holding the JSON
+// OnboardingReport of the latest validation.
+const OnboardingReportField = "onboarding_report"
+
+// Statuses of an OnboardingCheck.
+const (
+	CheckPassed  = "pass"
+	CheckFailed  = "fail"
+	CheckSkipped = "skip"
+)
+
+// OnboardingCheck is the outcome of one onboarding step.
+type OnboardingCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// OnboardingReport is what the controller found validating a project.
+type OnboardingReport struct {
+	Started  time.Time         `json:"started"`
+	Finished time.Time         `json:"finished"`
+	Checks   []OnboardingCheck `json:"checks"`
+}
+
+// Passed reports whether no check failed.
+func (r *OnboardingReport) Passed() bool {
+	for _, c := range r.Checks {
+		if c.Status == CheckFailed {
+			return false
+		}
+	}
+	return true
+}
+
+// OnboardingReportFromFields decodes a project bead's onboarding report,
+// returning nil if it has none or it is malformed.
+func OnboardingReportFromFields(fields map[string]string) *OnboardingReport {
+	raw := fields[OnboardingReportField]
+	if raw == "" {
+		return nil
+	}
+	var r OnboardingReport
+	if json.Unmarshal([]byte(raw), &r) != nil {
+		return nil
+	}
+	return &r
+}
+
+// OnboardingAllowsAgents reports whether the controller may schedule the
+// agents of a project in onboarding state: it was never onboarded, or
+// onboarding passed.
+func OnboardingAllowsAgents(state string) bool {
+	return state == "" || state == OnboardingPassed
+}
@@ -0,0 +1,30 @@
+package beadsapi
+
+import "testing"
+
+func TestOnboarding_FieldsAndReport(t *testing.T) {
+	fields := ProjectInfo{Name: "api"}.Fields()
+	if _, ok := fields[OnboardingField]; ok {
+		t.Error("Fields writes the controller-owned onboarding state")
+	}
+
+	fields[OnboardingField] = OnboardingFailed
+	fields[OnboardingReportField] = `{"checks":[{"name":"config","status":"pass"},{"name":"clone","status":"fail","detail":"exit 128"}]}`
+	p := ProjectInfoFromFields("api", fields)
+	if p.Onboarding != OnboardingFailed || OnboardingAllowsAgents(p.Onboarding) {
+		t.Errorf("onboarding = %q; agents allowed = %v", p.Onboarding, OnboardingAllowsAgents(p.Onboarding))
+	}
+	report := OnboardingReportFromFields(fields)
+	if report == nil || len(report.Checks) != 2 || report.Passed() {
+		t.Errorf("report = %+v", report)
+	}
+
+	for _, state := range []string{"", OnboardingPassed} {
+		if !OnboardingAllowsAgents(state) {
+			t.Errorf("agents held back in state %q", state)
+		}
+	}
+	if OnboardingReportFromFields(map[string]string{OnboardingReportField: "{"}) != nil {
+		t.Error("malformed report decoded")
+	}
+}
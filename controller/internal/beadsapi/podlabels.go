@@ -0,0 +1,102 @@
+package beadsapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Agent bead fields holding extra Kubernetes labels and annotations for the
+// agent's pod, as JSON objects of strings, e.g. {"team":"payments"}. Teams
+// use them for the cost-center, team and compliance labels their cluster
+// tooling selects on.
+const (
+	PodLabelsField      = "pod_labels"
+	PodAnnotationsField = "pod_annotations"
+)
+
+// Bead label prefixes that set pod labels and annotations:
+// "k8s/team=payments" labels the pod team=payments, and
+// "k8s-annotation/owner=alice@example.com" annotates it.
+const (
+	K8sLabelPrefix      = "k8s/"
+	K8sAnnotationPrefix = "k8s-annotation/"
+)
+
+// MergePodLabels folds the k8s/ and k8s-annotation/ labels of a bead into
+// the pod_labels and pod_annotations fields of its metadata. Entries
+// already in the fields take precedence over bead labels.
+func MergePodLabels(meta map[string]string, beadLabels []string) {
+	fromLabels := map[string]map[string]string{PodLabelsField: {}, PodAnnotationsField: {}}
+	for _, l := range beadLabels {
+		field, kv := PodLabelsField, ""
+		switch {
+		case strings.HasPrefix(l, K8sAnnotationPrefix):
+			field, kv = PodAnnotationsField, strings.TrimPrefix(l, K8sAnnotationPrefix)
+		case strings.HasPrefix(l, K8sLabelPrefix):
+			kv = strings.TrimPrefix(l, K8sLabelPrefix)
+		default:
+			continue
+		}
+		k, v, _ := strings.Cut(kv, "=")
+		if k != "" {
+			fromLabels[field][k] = v
+		}
+	}
+	for field, entries := range fromLabels {
+		if len(entries) == 0 {
+			continue
+		}
+		if raw := meta[field]; raw != "" {
+			var set map[string]string
+			if err := json.Unmarshal([]byte(raw), &set); err != nil {
+				continue // leave the malformed field for PodLabels to report
+			}
+			maps.Copy(entries, set)
+		}
+		raw, _ := json.Marshal(entries)
+		meta[field] = string(raw)
+	}
+}
+
+// PodLabels decodes the pod_labels field of agent metadata. Labels with an
+// invalid key or value are left out and reported in the error; the valid
+// ones are returned either way.
+func PodLabels(meta map[string]string) (map[string]string, error) {
+	return decodePodMetadata(meta, PodLabelsField, validation.IsValidLabelValue)
+}
+
+// PodAnnotations decodes the pod_annotations field of agent metadata, like
+// PodLabels.
+func PodAnnotations(meta map[string]string) (map[string]string, error) {
+	return decodePodMetadata(meta, PodAnnotationsField, nil)
+}
+
+func decodePodMetadata(meta map[string]string, field string, validValue func(string) []string) (map[string]string, error) {
+	raw := meta[field]
+	if raw == "" {
+		return nil, nil
+	}
+	var set map[string]string
+	if err := json.Unmarshal([]byte(raw), &set); err != nil {
+		return nil, fmt.Errorf("%s: must be a JSON object of strings: %w", field, err)
+	}
+	var errs []error
+	for _, k := range slices.Sorted(maps.Keys(set)) {
+		v := set[k]
+		msgs := validation.IsQualifiedName(k)
+		if validValue != nil {
+			msgs = append(msgs, validValue(v)...)
+		}
+		if len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("%s: %q: %s", field, k, strings.Join(msgs, "; ")))
+			delete(set, k)
+		}
+	}
+	return set, errors.Join(errs...)
+}
@@ -0,0 +1,60 @@
+package beadsapi
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestMergePodLabels(t *testing.T) {
+	meta := map[string]string{PodLabelsField: `{"team":"payments"}`}
+	MergePodLabels(meta, []string{
+		"k8s/team=platform", // the field wins
+		"k8s/cost-center=cc-42",
+		"k8s-annotation/example.com/owner=alice",
+		"priority:high",
+	})
+
+	labels, err := PodLabels(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"team": "payments", "cost-center": "cc-42"}; !maps.Equal(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+	annotations, err := PodAnnotations(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"example.com/owner": "alice"}; !maps.Equal(annotations, want) {
+		t.Errorf("annotations = %v, want %v", annotations, want)
+	}
+
+	plain := map[string]string{}
+	MergePodLabels(plain, []string{"priority:high"})
+	if len(plain) != 0 {
+		t.Errorf("metadata without k8s/ labels = %v, want untouched", plain)
+	}
+}
+
+func TestPodLabels_DropsInvalid(t *testing.T) {
+	labels, err := PodLabels(map[string]string{
+		PodLabelsField: `{"team":"payments","bad key":"x","compliance":"not a valid value"}`,
+	})
+	if err == nil || !strings.Contains(err.Error(), `"bad key"`) || !strings.Contains(err.Error(), `"compliance"`) {
+		t.Errorf("err = %v, want both invalid labels reported", err)
+	}
+	if want := map[string]string{"team": "payments"}; !maps.Equal(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+
+	// Annotation values are free-form.
+	annotations, err := PodAnnotations(map[string]string{PodAnnotationsField: `{"note":"any text at all"}`})
+	if err != nil || annotations["note"] != "any text at all" {
+		t.Errorf("PodAnnotations = %v, %v", annotations, err)
+	}
+
+	if _, err := PodLabels(map[string]string{PodLabelsField: `[1]`}); err == nil {
+		t.Error("expected an error for a non-object field")
+	}
+}
@@ -0,0 +1,364 @@
+package beadsapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"gasboat/controller/internal/prestop"
+	"gasboat/controller/internal/schedule"
+)
+
+// ReconcilePausedField is the project bead field that, when "true", stops
+// the controller from creating, deleting, or upgrading the project's pods.
+const ReconcilePausedField = "reconcile_paused"
+
+// ScheduleField is the project or agent bead field declaring when agents
+// run (see package schedule). Outside it the controller hibernates their
+// pods; an agent's own schedule overrides its project's.
+const ScheduleField = "schedule"
+
+// ArchField is the project or agent bead field choosing the CPU architecture
+// agents run on: one of Archs. An agent's own arch overrides its project's.
+const ArchField = "arch"
+
+// ArchAny schedules agents on any architecture, for images published as
+// multi-arch manifest lists.
+const ArchAny = "any"
+
+// Archs lists the values ArchField accepts.
+var Archs = []string{"amd64", "arm64", ArchAny}
+
+// ValidArch reports whether arch is one of Archs.
+func ValidArch(arch string) bool {
+	return slices.Contains(Archs, arch)
+}
+
+// TerminationGraceField is the role or project bead field setting how long
+// agent pods get to shut down once deleted (their termination grace
+// period), as a duration such as "5m". A project's setting overrides the
+// role's.
+const TerminationGraceField = "termination_grace"
+
+// PreStopField is the role or project bead field holding the preStop hook
+// of agent pods, a template rendered by package prestop. A project's hook
+// replaces the role's; "none" disables it.
+const PreStopField = "prestop"
+
+// ParseTerminationGrace parses a TerminationGraceField value, returning 0
+// if it is empty.
+func ParseTerminationGrace(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s %q: %w", TerminationGraceField, s, err)
+	}
+	if d < time.Second {
+		return 0, fmt.Errorf("%s %q must be at least 1s", TerminationGraceField, s)
+	}
+	return d, nil
+}
+
+// ProjectInfoFromFields builds a ProjectInfo from a project bead's fields.
+// Malformed secrets or repos JSON is ignored, matching how the controller
+// reads project beads.
+func ProjectInfoFromFields(name string, fields map[string]string) ProjectInfo {
+	info := ProjectInfo{
+		Name:             name,
+		Prefix:           fields["prefix"],
+		GitURL:           fields["git_url"],
+		DefaultBranch:    fields["default_branch"],
+		Image:            fields["image"],
+		StorageClass:     fields["storage_class"],
+		ServiceAccount:   fields["service_account"],
+		RTKEnabled:       fields["rtk_enabled"] == "true",
+		ReconcilePaused:  fields[ReconcilePausedField] == "true",
+		Cluster:          fields["cluster"],
+		Schedule:         fields[ScheduleField],
+		Arch:             fields[ArchField],
+		Onboarding:       fields[OnboardingField],
+		TerminationGrace: fields[TerminationGraceField],
+		PreStop:          fields[PreStopField],
+	}
+	budgetFromFields(&info, fields)
+	dnsFromFields(&info, fields)
+	proxyFromFields(&info, fields)
+	// Parse per-project secrets from JSON field.
+	if raw := fields["secrets"]; raw != "" {
+		var secrets []SecretEntry
+		if json.Unmarshal([]byte(raw), &secrets) == nil {
+			info.Secrets = secrets
+		}
+	}
+	// Parse multi-repo definitions from JSON field.
+	if raw := fields["repos"]; raw != "" {
+		var repos []RepoEntry
+		if json.Unmarshal([]byte(raw), &repos) == nil {
+			info.Repos = repos
+		}
+	}
+	return info
+}
+
+// ListProjectBeads queries the daemon for project beads (type=project) and extracts
+// project metadata from fields. Returns a map of project name -> ProjectInfo.
+func (c *Client) ListProjectBeads(ctx context.Context) (map[string]ProjectInfo, error) {
+	rigs, _, err := c.ListProjectBeadsIfChanged(ctx, "")
+	return rigs, err
+}
+
+// ListProjectBeadsIfChanged is ListProjectBeads for pollers: it also
+// returns the ETag of the listing, and given the ETag of the last one,
+// returns ErrNotModified instead if no project bead changed since. Daemons
+// without ETag support always return the full listing and an empty ETag.
+func (c *Client) ListProjectBeadsIfChanged(ctx context.Context, etag string) (map[string]ProjectInfo, string, error) {
+	resp, etag, err := c.listBeadsIfChanged(ctx, []string{"project"}, activeStatuses, etag)
+	if errors.Is(err, ErrNotModified) {
+		return nil, etag, err
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("listing project beads: %w", err)
+	}
+
+	rigs := make(map[string]ProjectInfo)
+	for _, b := range resp.Beads {
+		// Strip "Project: " prefix from title -- legacy project beads may have titles
+		// like "Project: beads" instead of just "beads".
+		name := strings.TrimPrefix(b.Title, "Project: ")
+		info := ProjectInfoFromFields(name, b.fieldsMap())
+		info.ID = b.ID
+		if name != "" {
+			rigs[name] = info
+		}
+	}
+
+	return rigs, etag, nil
+}
+
+// ErrNotModified is returned by conditional requests when the daemon
+// answers 304 Not Modified.
+var ErrNotModified = errors.New("not modified")
+
+// listBeadsIfChanged is listBeads sending If-None-Match with etag, when
+// set. It returns the ETag of the listing, or etag and ErrNotModified.
+func (c *Client) listBeadsIfChanged(ctx context.Context, types, statuses []string, etag string) (*listBeadsResponse, string, error) {
+	q := url.Values{}
+	if len(types) > 0 {
+		q.Set("type", strings.Join(types, ","))
+	}
+	if len(statuses) > 0 {
+		q.Set("status", strings.Join(statuses, ","))
+	}
+
+	path := "/v1/beads"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	reqHeader := http.Header{}
+	if etag != "" {
+		reqHeader.Set("If-None-Match", etag)
+	}
+	var resp listBeadsResponse
+	respHeader, err := c.doJSONHeader(ctx, http.MethodGet, path, nil, &resp, reqHeader)
+	if errors.Is(err, ErrNotModified) {
+		return nil, etag, err
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return &resp, respHeader.Get("ETag"), nil
+}
+
+// Fields returns the project bead fields for p, the inverse of
+// ProjectInfoFromFields. Empty values are included so that an update clears
+// them. The onboarding and budget state belong to the controller and are
+// left out.
+func (p ProjectInfo) Fields() map[string]string {
+	fields := map[string]string{
+		"prefix":              p.Prefix,
+		"git_url":             p.GitURL,
+		"default_branch":      p.DefaultBranch,
+		"image":               p.Image,
+		"storage_class":       p.StorageClass,
+		"service_account":     p.ServiceAccount,
+		"cluster":             p.Cluster,
+		ScheduleField:         p.Schedule,
+		ArchField:             p.Arch,
+		TerminationGraceField: p.TerminationGrace,
+		PreStopField:          p.PreStop,
+		BudgetSoftField:       p.BudgetSoft,
+		BudgetHardField:       p.BudgetHard,
+		"secrets":             "",
+		"repos":               "",
+	}
+	if p.RTKEnabled {
+		fields["rtk_enabled"] = "true"
+	} else {
+		fields["rtk_enabled"] = ""
+	}
+	if p.ReconcilePaused {
+		fields[ReconcilePausedField] = "true"
+	} else {
+		fields[ReconcilePausedField] = ""
+	}
+	if len(p.Secrets) > 0 {
+		data, _ := json.Marshal(p.Secrets)
+		fields["secrets"] = string(data)
+	}
+	if len(p.Repos) > 0 {
+		data, _ := json.Marshal(p.Repos)
+		fields["repos"] = string(data)
+	}
+	p.dnsFields(fields)
+	p.proxyFields(fields)
+	return fields
+}
+
+var (
+	projectNameRe   = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	projectPrefixRe = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	envNameRe       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	imageRefRe      = regexp.MustCompile(`^[a-z0-9][a-z0-9._/:@-]*[a-zA-Z0-9]$`)
+)
+
+// Validate checks that p can be written to a project bead and consumed by the
+// controller. All problems are reported together.
+func (p ProjectInfo) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !projectNameRe.MatchString(p.Name) {
+		add("name %q must be lowercase letters, digits, and dashes", p.Name)
+	}
+	if p.Prefix != "" && !projectPrefixRe.MatchString(p.Prefix) {
+		add("prefix %q must be lowercase alphanumeric, starting with a letter", p.Prefix)
+	}
+	if p.GitURL != "" && !validGitURL(p.GitURL) {
+		add("git_url %q must be an https://, ssh://, or git@host:path URL", p.GitURL)
+	}
+	if p.DefaultBranch != "" && strings.ContainsAny(p.DefaultBranch, " ~^:?*[\\") {
+		add("default_branch %q is not a valid branch name", p.DefaultBranch)
+	}
+	if p.Image != "" && !imageRefRe.MatchString(p.Image) {
+		add("image %q is not a valid image reference", p.Image)
+	}
+	if p.StorageClass != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(p.StorageClass) {
+			add("storage_class %q: %s", p.StorageClass, msg)
+		}
+	}
+	if p.ServiceAccount != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(p.ServiceAccount) {
+			add("service_account %q: %s", p.ServiceAccount, msg)
+		}
+	}
+
+	if p.Cluster != "" {
+		for _, msg := range validation.IsDNS1123Label(p.Cluster) {
+			add("cluster %q: %s", p.Cluster, msg)
+		}
+	}
+
+	if p.Schedule != "" {
+		if _, err := schedule.Parse(p.Schedule); err != nil {
+			add("schedule: %v", err)
+		}
+	}
+
+	if p.Arch != "" && !ValidArch(p.Arch) {
+		add("arch %q must be one of %s", p.Arch, strings.Join(Archs, ", "))
+	}
+
+	if _, err := ParseTerminationGrace(p.TerminationGrace); err != nil {
+		add("%v", err)
+	}
+	if p.PreStop != "" {
+		if err := prestop.Validate(p.PreStop); err != nil {
+			add("%v", err)
+		}
+	}
+	errs = append(errs, p.validateDNS()...)
+	errs = append(errs, p.validateProxy()...)
+
+	if soft, hard, err := p.BudgetCaps(); err != nil {
+		add("%v", err)
+	} else if soft > 0 && hard > 0 && soft > hard {
+		add("%s %s is above %s %s", BudgetSoftField, p.BudgetSoft, BudgetHardField, p.BudgetHard)
+	}
+
+	envs := make(map[string]bool)
+	for i, s := range p.Secrets {
+		if !envNameRe.MatchString(s.Env) {
+			add("secrets[%d]: env %q is not a valid environment variable name", i, s.Env)
+		} else if envs[s.Env] {
+			add("secrets[%d]: env %s is set more than once", i, s.Env)
+		}
+		envs[s.Env] = true
+		for _, msg := range validation.IsDNS1123Subdomain(s.Secret) {
+			add("secrets[%d]: secret %q: %s", i, s.Secret, msg)
+		}
+		for _, msg := range validation.IsConfigMapKey(s.Key) {
+			add("secrets[%d]: key %q: %s", i, s.Key, msg)
+		}
+	}
+
+	primaries := 0
+	names := make(map[string]bool)
+	for i, r := range p.Repos {
+		if !validGitURL(r.URL) {
+			add("repos[%d]: url %q must be an https://, ssh://, or git@host:path URL", i, r.URL)
+		}
+		switch r.Role {
+		case "", "reference":
+		case "primary":
+			primaries++
+		default:
+			add("repos[%d]: role %q must be primary or reference", i, r.Role)
+		}
+		if r.Name != "" {
+			if strings.ContainsAny(r.Name, "/\\ ") || r.Name == "." || r.Name == ".." {
+				add("repos[%d]: name %q must be a plain directory name", i, r.Name)
+			} else if names[r.Name] {
+				add("repos[%d]: name %s is used more than once", i, r.Name)
+			}
+			names[r.Name] = true
+		}
+	}
+	if primaries > 1 {
+		add("repos: at most one repo may have role primary (found %d)", primaries)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validGitURL accepts https://, http://, ssh://, and scp-style git@host:path
+// remotes.
+func validGitURL(raw string) bool {
+	if strings.HasPrefix(raw, "git@") {
+		host, path, ok := strings.Cut(strings.TrimPrefix(raw, "git@"), ":")
+		return ok && host != "" && path != ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "https", "http", "ssh":
+		return u.Host != "" && strings.Trim(u.Path, "/") != ""
+	}
+	return false
+}
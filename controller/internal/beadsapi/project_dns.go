@@ -0,0 +1,121 @@
+package beadsapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Project bead fields configuring name resolution in the project's agent
+// pods, for git hosts and internal APIs outside cluster DNS. Host aliases
+// and DNS config hold JSON in the format of the pod spec fields of the
+// same name:
+//
+//	host_aliases  [{"ip": "10.0.0.5", "hostnames": ["git.corp.example"]}]
+//	dns_policy    ClusterFirst, ClusterFirstWithHostNet, Default or None
+//	dns_config    {"nameservers": ["10.0.0.2"], "searches": ["corp.example"],
+//	               "options": [{"name": "ndots", "value": "2"}]}
+const (
+	HostAliasesField = "host_aliases"
+	DNSPolicyField   = "dns_policy"
+	DNSConfigField   = "dns_config"
+)
+
+// DNSPolicies lists the values DNSPolicyField accepts.
+var DNSPolicies = []string{
+	string(corev1.DNSClusterFirst),
+	string(corev1.DNSClusterFirstWithHostNet),
+	string(corev1.DNSDefault),
+	string(corev1.DNSNone),
+}
+
+// maxNameservers is the Kubernetes limit on dns_config nameservers.
+const maxNameservers = 3
+
+// dnsFromFields reads the DNS fields of a project bead. Malformed JSON is
+// ignored, as for secrets and repos.
+func dnsFromFields(info *ProjectInfo, fields map[string]string) {
+	info.DNSPolicy = fields[DNSPolicyField]
+	if raw := fields[HostAliasesField]; raw != "" {
+		var aliases []corev1.HostAlias
+		if json.Unmarshal([]byte(raw), &aliases) == nil {
+			info.HostAliases = aliases
+		}
+	}
+	if raw := fields[DNSConfigField]; raw != "" {
+		var cfg corev1.PodDNSConfig
+		if json.Unmarshal([]byte(raw), &cfg) == nil {
+			info.DNSConfig = &cfg
+		}
+	}
+}
+
+// dnsFields writes the DNS fields of p into fields, empty when unset.
+func (p ProjectInfo) dnsFields(fields map[string]string) {
+	fields[DNSPolicyField] = p.DNSPolicy
+	fields[HostAliasesField] = ""
+	fields[DNSConfigField] = ""
+	if len(p.HostAliases) > 0 {
+		data, _ := json.Marshal(p.HostAliases)
+		fields[HostAliasesField] = string(data)
+	}
+	if p.DNSConfig != nil {
+		data, _ := json.Marshal(p.DNSConfig)
+		fields[DNSConfigField] = string(data)
+	}
+}
+
+// validateDNS reports every problem with the DNS fields of p.
+func (p ProjectInfo) validateDNS() []error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	for i, a := range p.HostAliases {
+		if net.ParseIP(a.IP) == nil {
+			add("%s[%d]: ip %q is not an IP address", HostAliasesField, i, a.IP)
+		}
+		if len(a.Hostnames) == 0 {
+			add("%s[%d]: no hostnames", HostAliasesField, i)
+		}
+		for _, h := range a.Hostnames {
+			for _, msg := range validation.IsDNS1123Subdomain(h) {
+				add("%s[%d]: hostname %q: %s", HostAliasesField, i, h, msg)
+			}
+		}
+	}
+
+	if p.DNSPolicy != "" && !slices.Contains(DNSPolicies, p.DNSPolicy) {
+		add("%s %q must be one of %s", DNSPolicyField, p.DNSPolicy, strings.Join(DNSPolicies, ", "))
+	}
+	if p.DNSPolicy == string(corev1.DNSNone) && (p.DNSConfig == nil || len(p.DNSConfig.Nameservers) == 0) {
+		add("%s None needs %s nameservers", DNSPolicyField, DNSConfigField)
+	}
+	if cfg := p.DNSConfig; cfg != nil {
+		if len(cfg.Nameservers) > maxNameservers {
+			add("%s: at most %d nameservers", DNSConfigField, maxNameservers)
+		}
+		for _, ns := range cfg.Nameservers {
+			if net.ParseIP(ns) == nil {
+				add("%s: nameserver %q is not an IP address", DNSConfigField, ns)
+			}
+		}
+		for _, s := range cfg.Searches {
+			for _, msg := range validation.IsDNS1123Subdomain(strings.TrimSuffix(s, ".")) {
+				add("%s: search domain %q: %s", DNSConfigField, s, msg)
+			}
+		}
+		for _, o := range cfg.Options {
+			if o.Name == "" {
+				add("%s: option without a name", DNSConfigField)
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,67 @@
+package beadsapi
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestProjectInfo_DNSFieldsRoundTrip(t *testing.T) {
+	ndots := "2"
+	p := ProjectInfo{
+		Name:        "gasboat",
+		HostAliases: []corev1.HostAlias{{IP: "10.0.0.5", Hostnames: []string{"git.corp.example"}}},
+		DNSPolicy:   "None",
+		DNSConfig: &corev1.PodDNSConfig{
+			Nameservers: []string{"10.0.0.2"},
+			Searches:    []string{"corp.example"},
+			Options:     []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}},
+		},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	got := ProjectInfoFromFields("gasboat", p.Fields())
+	if len(got.HostAliases) != 1 || got.HostAliases[0].IP != "10.0.0.5" || got.HostAliases[0].Hostnames[0] != "git.corp.example" {
+		t.Errorf("host aliases = %+v", got.HostAliases)
+	}
+	if got.DNSPolicy != "None" || got.DNSConfig == nil || got.DNSConfig.Nameservers[0] != "10.0.0.2" ||
+		*got.DNSConfig.Options[0].Value != "2" {
+		t.Errorf("dns = %q %+v", got.DNSPolicy, got.DNSConfig)
+	}
+
+	fields := ProjectInfo{Name: "gasboat"}.Fields()
+	for _, k := range []string{HostAliasesField, DNSPolicyField, DNSConfigField} {
+		if v, ok := fields[k]; !ok || v != "" {
+			t.Errorf("fields[%q] = %q (present=%v), want empty string", k, v, ok)
+		}
+	}
+}
+
+func TestProjectInfo_ValidateDNSRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		p    ProjectInfo
+		want string
+	}{
+		{"bad alias ip", ProjectInfo{Name: "p", HostAliases: []corev1.HostAlias{{IP: "git", Hostnames: []string{"git.corp"}}}}, "not an IP"},
+		{"no hostnames", ProjectInfo{Name: "p", HostAliases: []corev1.HostAlias{{IP: "10.0.0.5"}}}, "no hostnames"},
+		{"bad hostname", ProjectInfo{Name: "p", HostAliases: []corev1.HostAlias{{IP: "10.0.0.5", Hostnames: []string{"Git_Host"}}}}, "hostname"},
+		{"bad policy", ProjectInfo{Name: "p", DNSPolicy: "Custom"}, "dns_policy"},
+		{"none without nameservers", ProjectInfo{Name: "p", DNSPolicy: "None"}, "needs dns_config nameservers"},
+		{"too many nameservers", ProjectInfo{Name: "p", DNSConfig: &corev1.PodDNSConfig{
+			Nameservers: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"},
+		}}, "at most 3"},
+		{"bad nameserver", ProjectInfo{Name: "p", DNSConfig: &corev1.PodDNSConfig{Nameservers: []string{"dns.corp"}}}, "nameserver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package beadsapi
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Project bead fields routing the project's agent pods, and their repo
+// clones, through an egress proxy. The proxies are URLs such as
+// http://proxy.corp.example:3128; https_proxy defaults to http_proxy.
+// no_proxy is a comma-separated list of hosts, domains and CIDRs reached
+// directly, on top of localhost and the cluster's own services.
+const (
+	HTTPProxyField  = "http_proxy"
+	HTTPSProxyField = "https_proxy"
+	NoProxyField    = "no_proxy"
+)
+
+// proxySchemes are the proxy URL schemes agents' tools commonly support.
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// proxyFromFields reads the proxy fields of a project bead.
+func proxyFromFields(info *ProjectInfo, fields map[string]string) {
+	info.HTTPProxy = fields[HTTPProxyField]
+	info.HTTPSProxy = fields[HTTPSProxyField]
+	info.NoProxy = fields[NoProxyField]
+}
+
+// proxyFields writes the proxy fields of p into fields.
+func (p ProjectInfo) proxyFields(fields map[string]string) {
+	fields[HTTPProxyField] = p.HTTPProxy
+	fields[HTTPSProxyField] = p.HTTPSProxy
+	fields[NoProxyField] = p.NoProxy
+}
+
+// validateProxy reports every problem with the proxy fields of p.
+func (p ProjectInfo) validateProxy() []error {
+	var errs []error
+	for _, f := range [][2]string{{HTTPProxyField, p.HTTPProxy}, {HTTPSProxyField, p.HTTPSProxy}} {
+		if f[1] == "" {
+			continue
+		}
+		u, err := url.Parse(f[1])
+		if err != nil || u.Host == "" || !slices.Contains(proxySchemes, u.Scheme) {
+			errs = append(errs, fmt.Errorf("%s %q must be a URL such as http://proxy:3128 (schemes: %s)",
+				f[0], f[1], strings.Join(proxySchemes, ", ")))
+		}
+	}
+	if p.NoProxy != "" && p.HTTPProxy == "" && p.HTTPSProxy == "" {
+		errs = append(errs, fmt.Errorf("%s is set without %s or %s", NoProxyField, HTTPProxyField, HTTPSProxyField))
+	}
+	if strings.ContainsAny(p.NoProxy, " \t\n") {
+		errs = append(errs, fmt.Errorf("%s %q must be comma-separated without spaces", NoProxyField, p.NoProxy))
+	}
+	return errs
+}
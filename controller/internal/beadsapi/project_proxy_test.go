@@ -0,0 +1,38 @@
+package beadsapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProjectInfo_ProxyFieldsRoundTrip(t *testing.T) {
+	p := ProjectInfo{Name: "gasboat", HTTPProxy: "http://proxy.corp:3128", NoProxy: "git.corp,10.0.0.0/8"}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	got := ProjectInfoFromFields("gasboat", p.Fields())
+	if got.HTTPProxy != p.HTTPProxy || got.HTTPSProxy != "" || got.NoProxy != p.NoProxy {
+		t.Errorf("proxy = %q %q %q", got.HTTPProxy, got.HTTPSProxy, got.NoProxy)
+	}
+}
+
+func TestProjectInfo_ValidateProxyRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		p    ProjectInfo
+		want string
+	}{
+		{"no scheme", ProjectInfo{Name: "p", HTTPProxy: "proxy.corp:3128"}, "http_proxy"},
+		{"bad scheme", ProjectInfo{Name: "p", HTTPSProxy: "ftp://proxy.corp"}, "https_proxy"},
+		{"no_proxy alone", ProjectInfo{Name: "p", NoProxy: "git.corp"}, "without http_proxy"},
+		{"no_proxy spaces", ProjectInfo{Name: "p", HTTPProxy: "http://proxy:3128", NoProxy: "a, b"}, "without spaces"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package beadsapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProjectInfo_FieldsRoundTrip(t *testing.T) {
+	p := ProjectInfo{
+		Name:             "gasboat",
+		Prefix:           "kd",
+		GitURL:           "https://github.com/org/gasboat.git",
+		DefaultBranch:    "main",
+		RTKEnabled:       true,
+		ReconcilePaused:  true,
+		Cluster:          "burst",
+		Schedule:         "Mon-Fri 08:00-19:00 America/New_York",
+		Arch:             "arm64",
+		TerminationGrace: "5m",
+		PreStop:          "gb yield --checkpoint --agent-id {{.BeadID}}",
+		Secrets:          []SecretEntry{{Env: "GITLAB_TOKEN", Secret: "gitlab-creds", Key: "token"}},
+		Repos:            []RepoEntry{{URL: "https://github.com/org/docs", Role: "reference", Name: "docs"}},
+	}
+
+	got := ProjectInfoFromFields("gasboat", p.Fields())
+
+	if got.Prefix != "kd" || got.GitURL != p.GitURL || got.DefaultBranch != "main" || !got.RTKEnabled || !got.ReconcilePaused || got.Cluster != "burst" ||
+		got.Schedule != p.Schedule || got.Arch != "arm64" || got.TerminationGrace != "5m" ||
+		got.PreStop != p.PreStop {
+		t.Errorf("scalar fields not preserved: %+v", got)
+	}
+	if len(got.Secrets) != 1 || got.Secrets[0] != p.Secrets[0] {
+		t.Errorf("secrets = %+v, want %+v", got.Secrets, p.Secrets)
+	}
+	if len(got.Repos) != 1 || got.Repos[0] != p.Repos[0] {
+		t.Errorf("repos = %+v, want %+v", got.Repos, p.Repos)
+	}
+}
+
+func TestProjectInfo_FieldsClearsEmptyValues(t *testing.T) {
+	fields := ProjectInfo{Name: "gasboat"}.Fields()
+	for _, k := range []string{"image", "secrets", "repos", "rtk_enabled", ReconcilePausedField} {
+		v, ok := fields[k]
+		if !ok || v != "" {
+			t.Errorf("fields[%q] = %q (present=%v), want empty string", k, v, ok)
+		}
+	}
+}
+
+func TestProjectInfo_ValidateAccepts(t *testing.T) {
+	p := ProjectInfo{
+		Name:           "my-project",
+		Prefix:         "mp",
+		GitURL:         "git@gitlab.com:org/repo.git",
+		DefaultBranch:  "release/1.0",
+		Image:          "ghcr.io/org/agent:v1.2.3",
+		StorageClass:   "gp3",
+		ServiceAccount: "agent-sa",
+		Secrets:        []SecretEntry{{Env: "API_KEY", Secret: "api-creds", Key: "api-key"}},
+		Repos: []RepoEntry{
+			{URL: "https://github.com/org/a", Role: "primary"},
+			{URL: "ssh://git@github.com/org/b", Name: "b"},
+		},
+	}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestProjectInfo_ValidateRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		p    ProjectInfo
+		want string
+	}{
+		{"bad name", ProjectInfo{Name: "My Project"}, "name"},
+		{"bad prefix", ProjectInfo{Name: "p", Prefix: "K-D"}, "prefix"},
+		{"bad git url", ProjectInfo{Name: "p", GitURL: "github.com/org/repo"}, "git_url"},
+		{"bad branch", ProjectInfo{Name: "p", DefaultBranch: "my branch"}, "default_branch"},
+		{"bad image", ProjectInfo{Name: "p", Image: "Not An Image"}, "image"},
+		{"bad storage class", ProjectInfo{Name: "p", StorageClass: "GP3_fast"}, "storage_class"},
+		{"bad cluster", ProjectInfo{Name: "p", Cluster: "us.east"}, "cluster"},
+		{"bad schedule", ProjectInfo{Name: "p", Schedule: "weekdays 9-5"}, "schedule"},
+		{"bad arch", ProjectInfo{Name: "p", Arch: "x86"}, "arch"},
+		{"bad termination grace", ProjectInfo{Name: "p", TerminationGrace: "5"}, "termination_grace"},
+		{"short termination grace", ProjectInfo{Name: "p", TerminationGrace: "500ms"}, "at least 1s"},
+		{"bad prestop", ProjectInfo{Name: "p", PreStop: "gb yield --agent-id {{.Bead}}"}, "prestop"},
+		{"bad env", ProjectInfo{Name: "p", Secrets: []SecretEntry{{Env: "1BAD", Secret: "s", Key: "k"}}}, "secrets[0]: env"},
+		{"duplicate env", ProjectInfo{Name: "p", Secrets: []SecretEntry{
+			{Env: "A", Secret: "s", Key: "k"}, {Env: "A", Secret: "t", Key: "k"},
+		}}, "more than once"},
+		{"missing secret name", ProjectInfo{Name: "p", Secrets: []SecretEntry{{Env: "A", Key: "k"}}}, "secrets[0]: secret"},
+		{"bad repo role", ProjectInfo{Name: "p", Repos: []RepoEntry{{URL: "https://h/o/r", Role: "main"}}}, "role"},
+		{"two primaries", ProjectInfo{Name: "p", Repos: []RepoEntry{
+			{URL: "https://h/o/a", Role: "primary"}, {URL: "https://h/o/b", Role: "primary"},
+		}}, "at most one"},
+		{"bad repo name", ProjectInfo{Name: "p", Repos: []RepoEntry{{URL: "https://h/o/r", Name: "../x"}}}, "plain directory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.Validate()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+}
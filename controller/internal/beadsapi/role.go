@@ -0,0 +1,70 @@
+package beadsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"gasboat/controller/internal/prestop"
+)
+
+// Role bead fields. Env and resources hold JSON in the format of the
+// template fields of the same name (TemplateEnvField, TemplateResourcesField).
+const (
+	RoleImageField        = "image"
+	RoleStorageClassField = "storage_class"
+	RoleStorageSizeField  = "storage_size"
+	RoleInstructionsField = "instructions"
+)
+
+// RoleInfo is a role bead (type=role): the pod defaults and instructions of
+// every agent with the role, on top of the controller's per-mode defaults.
+type RoleInfo struct {
+	ID     string            // Role bead ID
+	Name   string            // Role name (from bead title), e.g. "crew"
+	Fields map[string]string // Role defaults
+}
+
+// ListRoleBeads queries the daemon for role beads (type=role).
+// Returns a map of role name -> RoleInfo.
+func (c *Client) ListRoleBeads(ctx context.Context) (map[string]RoleInfo, error) {
+	resp, err := c.listBeads(ctx, []string{"role"}, activeStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("listing role beads: %w", err)
+	}
+	roles := make(map[string]RoleInfo)
+	for _, b := range resp.Beads {
+		if b.Title == "" {
+			continue
+		}
+		roles[b.Title] = RoleInfo{ID: b.ID, Name: b.Title, Fields: b.fieldsMap()}
+	}
+	return roles, nil
+}
+
+// Validate reports every malformed field of r.
+func (r RoleInfo) Validate() error {
+	var errs []error
+	if _, err := TemplateEnv(r.Fields); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := TemplateResources(r.Fields); err != nil {
+		errs = append(errs, err)
+	}
+	if size := r.Fields[RoleStorageSizeField]; size != "" {
+		if _, err := resource.ParseQuantity(size); err != nil {
+			errs = append(errs, fmt.Errorf("storage_size %q: %w", size, err))
+		}
+	}
+	if _, err := ParseTerminationGrace(r.Fields[TerminationGraceField]); err != nil {
+		errs = append(errs, err)
+	}
+	if hook := r.Fields[PreStopField]; hook != "" {
+		if err := prestop.Validate(hook); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,58 @@
+package beadsapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestListRoleBeads(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("type"); got != "role" {
+			t.Errorf("type = %q, want role", got)
+		}
+		_ = json.NewEncoder(w).Encode(listBeadsResponse{Beads: []beadJSON{
+			{ID: "bd-r1", Title: "crew", Type: "role", Fields: json.RawMessage(`{"image":"ghcr.io/org/crew:v3","storage_size":"20Gi"}`)},
+			{ID: "bd-r2", Title: "", Type: "role"},
+		}})
+	}))
+	defer srv.Close()
+
+	c := &Client{baseURL: srv.URL, httpClient: srv.Client()}
+	roles, err := c.ListRoleBeads(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 {
+		t.Fatalf("roles = %v, want crew only", roles)
+	}
+	crew := roles["crew"]
+	if crew.ID != "bd-r1" || crew.Fields[RoleImageField] != "ghcr.io/org/crew:v3" || crew.Fields[RoleStorageSizeField] != "20Gi" {
+		t.Errorf("crew = %+v", crew)
+	}
+}
+
+func TestRoleInfo_Validate(t *testing.T) {
+	valid := RoleInfo{Fields: map[string]string{
+		"env":          `{"A":"b"}`,
+		"resources":    `{"requests":{"cpu":"1"}}`,
+		"storage_size": "20Gi",
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid role: %v", err)
+	}
+
+	err := RoleInfo{Fields: map[string]string{
+		"env":          `[]`,
+		"resources":    `{"limits":{"memory":"much"}}`,
+		"storage_size": "big",
+	}}.Validate()
+	for _, want := range []string{"env", "resources", "storage_size"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %s", err, want)
+		}
+	}
+}
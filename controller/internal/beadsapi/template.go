@@ -0,0 +1,225 @@
+package beadsapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/schedule"
+)
+
+// TemplateField is the agent bead field naming the template bead the
+// agent's configuration is based on.
+const TemplateField = "template"
+
+// Template bead fields holding JSON rather than plain values. Any other
+// template field (role, mode, image, arch, mock_scenario, instructions, ...)
+// is an agent bead field default.
+const (
+	// TemplateEnvField holds extra pod environment variables as a JSON
+	// object. An agent's own env is merged over its template's, key by key.
+	TemplateEnvField = "env"
+	// TemplateResourcesField holds the agent container's compute resources
+	// as a JSON corev1.ResourceRequirements ({"requests": {...}, "limits": {...}}).
+	TemplateResourcesField = "resources"
+	// TemplateHooksField holds a Claude hooks layer in the format of the
+	// claude-hooks:{role} config, applied on top of the role's layer.
+	TemplateHooksField = "hooks"
+)
+
+// Template bead fields of recurring jobs. A template with a cron schedule
+// gets a fresh job agent at each tick (see schedule.ParseCron). These fields
+// configure and record the scheduling; they are not agent bead defaults.
+const (
+	// TemplateCronField holds the cron expression.
+	TemplateCronField = "cron"
+	// TemplateCronConcurrencyField is one of CronConcurrencyPolicies: what
+	// a tick does while an earlier run is still active.
+	TemplateCronConcurrencyField = "cron_concurrency"
+	// TemplateCronHistoryField is how many finished runs to keep
+	// (default DefaultCronHistoryLimit); older run beads are deleted.
+	TemplateCronHistoryField = "cron_history_limit"
+	// TemplateCronLastRunField is the RFC 3339 time of the last tick
+	// handled, whether it ran or was skipped.
+	TemplateCronLastRunField = "cron_last_run"
+	// TemplateCronSkippedField counts skipped runs.
+	TemplateCronSkippedField = "cron_skipped"
+	// TemplateCronLastSkipField describes the last skipped run.
+	TemplateCronLastSkipField = "cron_last_skip"
+)
+
+// Cron concurrency policies, as in Kubernetes CronJobs.
+const (
+	CronForbid  = "Forbid"  // skip the tick (default)
+	CronReplace = "Replace" // close the active runs and start a new one
+	CronAllow   = "Allow"   // run concurrently
+)
+
+// CronConcurrencyPolicies lists the values TemplateCronConcurrencyField accepts.
+var CronConcurrencyPolicies = []string{CronForbid, CronReplace, CronAllow}
+
+// DefaultCronHistoryLimit is the number of finished runs kept by default.
+const DefaultCronHistoryLimit = 3
+
+// CronRunLabel labels the agent beads of template's scheduled runs.
+func CronRunLabel(template string) string {
+	return "cron:" + template
+}
+
+// cronFields are the template fields not copied onto agents.
+var cronFields = []string{
+	TemplateCronField, TemplateCronConcurrencyField, TemplateCronHistoryField,
+	TemplateCronLastRunField, TemplateCronSkippedField, TemplateCronLastSkipField,
+}
+
+// TemplateInfo is a template bead: a reusable agent configuration that
+// agent beads reference by name in TemplateField.
+type TemplateInfo struct {
+	ID     string            // Template bead ID
+	Name   string            // Template name (from bead title)
+	Fields map[string]string // Agent bead field defaults
+}
+
+// ListTemplateBeads queries the daemon for template beads (type=template).
+// Returns a map of template name -> TemplateInfo.
+func (c *Client) ListTemplateBeads(ctx context.Context) (map[string]TemplateInfo, error) {
+	resp, err := c.listBeads(ctx, []string{"template"}, activeStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("listing template beads: %w", err)
+	}
+	templates := make(map[string]TemplateInfo)
+	for _, b := range resp.Beads {
+		if b.Title == "" {
+			continue
+		}
+		templates[b.Title] = TemplateInfo{ID: b.ID, Name: b.Title, Fields: b.fieldsMap()}
+	}
+	return templates, nil
+}
+
+// Validate reports every malformed field of t.
+func (t TemplateInfo) Validate() error {
+	var errs []error
+	if role := t.Fields["role"]; role != "" && !slices.Contains([]string{"captain", "crew", "job"}, role) {
+		errs = append(errs, fmt.Errorf("role %q: must be captain, crew or job", role))
+	}
+	if arch := t.Fields[ArchField]; arch != "" && !ValidArch(arch) {
+		errs = append(errs, fmt.Errorf("arch %q: must be one of %s", arch, strings.Join(Archs, ", ")))
+	}
+	if _, err := TemplateEnv(t.Fields); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := TemplateResources(t.Fields); err != nil {
+		errs = append(errs, err)
+	}
+	if raw := t.Fields[TemplateHooksField]; raw != "" && !json.Valid([]byte(raw)) {
+		errs = append(errs, errors.New("hooks: not valid JSON"))
+	}
+	if raw := t.Fields[TemplateCronField]; raw != "" {
+		if _, err := schedule.ParseCron(raw); err != nil {
+			errs = append(errs, fmt.Errorf("cron %q: %w", raw, err))
+		}
+		if t.Fields["project"] == "" {
+			errs = append(errs, errors.New("cron: scheduled templates need a project"))
+		}
+	}
+	if p := t.Fields[TemplateCronConcurrencyField]; p != "" && !slices.Contains(CronConcurrencyPolicies, p) {
+		errs = append(errs, fmt.Errorf("cron_concurrency %q: must be one of %s", p, strings.Join(CronConcurrencyPolicies, ", ")))
+	}
+	if _, err := t.CronHistoryLimit(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// CronConcurrency returns the template's cron concurrency policy.
+func (t TemplateInfo) CronConcurrency() string {
+	if p := t.Fields[TemplateCronConcurrencyField]; slices.Contains(CronConcurrencyPolicies, p) {
+		return p
+	}
+	return CronForbid
+}
+
+// CronHistoryLimit returns how many finished scheduled runs to keep.
+func (t TemplateInfo) CronHistoryLimit() (int, error) {
+	raw := t.Fields[TemplateCronHistoryField]
+	if raw == "" {
+		return DefaultCronHistoryLimit, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return DefaultCronHistoryLimit, fmt.Errorf("cron_history_limit %q: must be a non-negative integer", raw)
+	}
+	return n, nil
+}
+
+// ResolveTemplate returns the fields of an agent bead with its template's
+// fields filled in: the agent's own non-empty fields win, and env is merged
+// key by key. Agents without a template are returned unchanged; ok is false
+// if the template they name doesn't exist.
+func ResolveTemplate(fields map[string]string, templates map[string]TemplateInfo) (resolved map[string]string, ok bool) {
+	name := fields[TemplateField]
+	if name == "" {
+		return fields, true
+	}
+	t, ok := templates[name]
+	if !ok {
+		return fields, false
+	}
+	resolved = maps.Clone(t.Fields)
+	if resolved == nil {
+		resolved = make(map[string]string)
+	}
+	for _, k := range cronFields {
+		delete(resolved, k)
+	}
+	for k, v := range fields {
+		if v != "" {
+			resolved[k] = v
+		}
+	}
+	if fields[TemplateEnvField] != "" && t.Fields[TemplateEnvField] != "" {
+		env, _ := TemplateEnv(t.Fields)
+		own, err := TemplateEnv(fields)
+		if env != nil && err == nil {
+			maps.Copy(env, own)
+			raw, _ := json.Marshal(env)
+			resolved[TemplateEnvField] = string(raw)
+		}
+	}
+	return resolved, true
+}
+
+// TemplateEnv decodes the env field of agent or template fields.
+func TemplateEnv(fields map[string]string) (map[string]string, error) {
+	raw := fields[TemplateEnvField]
+	if raw == "" {
+		return nil, nil
+	}
+	var env map[string]string
+	if err := json.Unmarshal([]byte(raw), &env); err != nil {
+		return nil, fmt.Errorf("env: must be a JSON object of strings: %w", err)
+	}
+	return env, nil
+}
+
+// TemplateResources decodes the resources field of agent or template
+// fields, returning nil if there is none.
+func TemplateResources(fields map[string]string) (*corev1.ResourceRequirements, error) {
+	raw := fields[TemplateResourcesField]
+	if raw == "" {
+		return nil, nil
+	}
+	var res corev1.ResourceRequirements
+	if err := json.Unmarshal([]byte(raw), &res); err != nil {
+		return nil, fmt.Errorf("resources: %w", err)
+	}
+	return &res, nil
+}
@@ -0,0 +1,148 @@
+package beadsapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveTemplate(t *testing.T) {
+	templates := map[string]TemplateInfo{
+		"reviewer": {Name: "reviewer", Fields: map[string]string{
+			"role":          "job",
+			"image":         "ghcr.io/org/reviewer:v2",
+			"mock_scenario": "review",
+			"env":           `{"LINT":"strict","TIMEOUT":"30m"}`,
+		}},
+	}
+
+	agent := map[string]string{"agent": "r1", "template": "reviewer", "image": "", "env": `{"TIMEOUT":"1h"}`}
+	got, ok := ResolveTemplate(agent, templates)
+	if !ok {
+		t.Fatal("template not resolved")
+	}
+	if got["image"] != "ghcr.io/org/reviewer:v2" || got["mock_scenario"] != "review" || got["agent"] != "r1" {
+		t.Errorf("resolved = %v", got)
+	}
+	if got["env"] != `{"LINT":"strict","TIMEOUT":"1h"}` {
+		t.Errorf("env = %s, want template env with the agent's TIMEOUT", got["env"])
+	}
+	if agent["image"] != "" {
+		t.Error("agent fields modified")
+	}
+
+	templates["nightly"] = TemplateInfo{Name: "nightly", Fields: map[string]string{"role": "job", "cron": "@daily", "cron_last_run": "2026-03-02T00:00:00Z"}}
+	got, _ = ResolveTemplate(map[string]string{"template": "nightly"}, templates)
+	if got["cron"] != "" || got["cron_last_run"] != "" || got["role"] != "job" {
+		t.Errorf("resolved scheduled template = %v, want cron fields dropped", got)
+	}
+
+	if _, ok := ResolveTemplate(map[string]string{"template": "gone"}, templates); ok {
+		t.Error("unknown template resolved")
+	}
+	plain := map[string]string{"agent": "a"}
+	if got, ok := ResolveTemplate(plain, nil); !ok || len(got) != 1 {
+		t.Errorf("agent without template = %v, %v", got, ok)
+	}
+}
+
+func TestTemplateInfo_Validate(t *testing.T) {
+	valid := TemplateInfo{Fields: map[string]string{
+		"role":      "crew",
+		"env":       `{"A":"b"}`,
+		"resources": `{"requests":{"cpu":"500m","memory":"1Gi"},"limits":{"memory":"2Gi"}}`,
+		"hooks":     `{"hooks":{"Stop":[]}}`,
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid template: %v", err)
+	}
+	res, _ := TemplateResources(valid.Fields)
+	if res.Requests.Cpu().String() != "500m" || res.Limits.Memory().String() != "2Gi" {
+		t.Errorf("resources = %+v", res)
+	}
+
+	err := TemplateInfo{Fields: map[string]string{
+		"role":      "admiral",
+		"env":       `["A"]`,
+		"resources": `{"requests":{"cpu":"lots"}}`,
+		"hooks":     `{`,
+	}}.Validate()
+	for _, want := range []string{"role", "env", "resources", "hooks"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %s", err, want)
+		}
+	}
+}
+
+func TestTemplateInfo_ValidateCron(t *testing.T) {
+	valid := TemplateInfo{Fields: map[string]string{
+		"role": "job", "project": "gasboat", "cron": "0 3 * * *",
+		"cron_concurrency": "Replace", "cron_history_limit": "5",
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("valid template: %v", err)
+	}
+	if valid.CronConcurrency() != CronReplace {
+		t.Errorf("concurrency = %s", valid.CronConcurrency())
+	}
+	if n, _ := valid.CronHistoryLimit(); n != 5 {
+		t.Errorf("history limit = %d", n)
+	}
+
+	err := TemplateInfo{Fields: map[string]string{
+		"cron": "every night", "cron_concurrency": "Sometimes", "cron_history_limit": "-1",
+	}}.Validate()
+	for _, want := range []string{"cron \"every night\"", "need a project", "cron_concurrency", "cron_history_limit"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %s", err, want)
+		}
+	}
+	if p := (TemplateInfo{}).CronConcurrency(); p != CronForbid {
+		t.Errorf("default concurrency = %s, want Forbid", p)
+	}
+}
+
+func TestSpawnAgentWith_Template(t *testing.T) {
+	var created map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/beads":
+			_ = json.NewEncoder(w).Encode(listBeadsResponse{Beads: []beadJSON{{
+				ID: "bd-tpl", Title: "reviewer", Type: "template",
+				Fields: json.RawMessage(`{"role":"job","mode":"job","image":"ghcr.io/org/reviewer:v2"}`),
+			}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/beads":
+			body, _ := io.ReadAll(r.Body)
+			var req struct {
+				Fields json.RawMessage `json:"fields"`
+			}
+			_ = json.Unmarshal(body, &req)
+			_ = json.Unmarshal(req.Fields, &created)
+			_ = json.NewEncoder(w).Encode(map[string]string{"id": "bd-agent-9"})
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{baseURL: srv.URL, httpClient: srv.Client()}
+	if _, err := c.SpawnAgentWith(context.Background(), SpawnAgentRequest{
+		AgentName: "r1", Project: "gasboat", Template: "reviewer",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Role and mode are copied; the image is left to the controller.
+	if created["role"] != "job" || created["mode"] != "job" || created["template"] != "reviewer" || created["image"] != "" {
+		t.Errorf("agent fields = %v", created)
+	}
+
+	if _, err := c.SpawnAgentWith(context.Background(), SpawnAgentRequest{
+		AgentName: "r2", Template: "missing",
+	}); err == nil || !strings.Contains(err.Error(), `template "missing" not found`) {
+		t.Errorf("err = %v, want template not found", err)
+	}
+}
@@ -30,12 +30,16 @@ type AgentNotifier interface {
 // AgentsConfig holds configuration for the Agents watcher.
 type AgentsConfig struct {
 	Notifier AgentNotifier // nil = no notifications
+	Mutes    *Mutes        // optional mute rules checked before spawn/crash posts
+	Metrics  *Metrics      // optional notification metrics
 	Logger   *slog.Logger
 }
 
 // Agents watches the kbeads SSE event stream for agent bead lifecycle events.
 type Agents struct {
 	notifier AgentNotifier
+	mutes    *Mutes
+	metrics  *Metrics
 	logger   *slog.Logger
 
 	mu   sync.Mutex
@@ -46,6 +50,8 @@ type Agents struct {
 func NewAgents(cfg AgentsConfig) *Agents {
 	return &Agents{
 		notifier: cfg.Notifier,
+		mutes:    cfg.Mutes,
+		metrics:  cfg.Metrics,
 		logger:   cfg.Logger,
 		seen:     make(map[string]bool),
 	}
@@ -73,6 +79,11 @@ func (a *Agents) handleCreated(ctx context.Context, data []byte) {
 	a.logger.Info("agent bead created",
 		"id", bead.ID, "assignee", bead.Assignee, "title", bead.Title)
 
+	if a.mutes.IsMuted(MuteKindAgents, *bead) {
+		a.logger.Info("agent spawn notification muted", "id", bead.ID)
+		return
+	}
+
 	if a.notifier != nil {
 		a.notifier.NotifyAgentSpawn(ctx, *bead)
 	}
@@ -165,8 +176,15 @@ func (a *Agents) notifyCrash(ctx context.Context, bead BeadEvent) {
 		"agent_state", bead.Fields["agent_state"],
 		"pod_phase", bead.Fields["pod_phase"])
 
+	if a.mutes.IsMuted(MuteKindAgents, bead) {
+		a.logger.Info("agent crash notification muted", "id", bead.ID)
+		return
+	}
+
 	if a.notifier != nil {
-		if err := a.notifier.NotifyAgentCrash(ctx, bead); err != nil {
+		err := a.notifier.NotifyAgentCrash(ctx, bead)
+		a.metrics.ObserveNotification("agent_crash", err)
+		if err != nil {
 			a.logger.Error("failed to notify agent crash",
 				"id", bead.ID, "error", err)
 		}
@@ -32,6 +32,7 @@ func NewDecisionAPI(client DecisionAPIClient, logger *slog.Logger) *DecisionAPI
 // RegisterRoutes registers decision API routes on the given mux.
 func (a *DecisionAPI) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/decisions", a.handleList)
+	mux.HandleFunc("/api/decisions/audit", a.handleAudit)
 	mux.HandleFunc("/api/decisions/", a.handleByID)
 }
 
@@ -122,10 +123,7 @@ func (a *DecisionAPI) handleResolve(w http.ResponseWriter, r *http.Request, id s
 		return
 	}
 
-	respondedBy := req.RespondedBy
-	if respondedBy == "" {
-		respondedBy = "web-ui"
-	}
+	respondedBy := webIdentity(r, req.RespondedBy)
 
 	err := a.client.ResolveDecision(r.Context(), id, beadsapi.ResolveDecisionRequest{
 		SelectedOption: req.Chosen,
@@ -161,10 +159,7 @@ func (a *DecisionAPI) handleDismiss(w http.ResponseWriter, r *http.Request, id s
 		return
 	}
 
-	canceledBy := req.CanceledBy
-	if canceledBy == "" {
-		canceledBy = "web-ui"
-	}
+	canceledBy := webIdentity(r, req.CanceledBy)
 
 	err := a.client.CancelDecision(r.Context(), id, req.Reason, canceledBy)
 	if err != nil {
@@ -0,0 +1,119 @@
+package bridge
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// auditListLimit caps the resolved decisions scanned by an audit query.
+const auditListLimit = 1000
+
+// webIdentity returns the identity recorded for a web UI user: the email
+// an authenticating proxy in front of the UI forwards (oauth2-proxy's
+// X-Auth-Request-Email or X-Forwarded-Email), else the name the UI
+// claims, as "web:<name>" (unverified, so never taken as an email).
+func webIdentity(r *http.Request, claimed string) string {
+	for _, h := range []string{"X-Auth-Request-Email", "X-Forwarded-Email"} {
+		if email := r.Header.Get(h); email != "" {
+			return beadsapi.NormalizeIdentity("web", "", email)
+		}
+	}
+	if claimed == "" {
+		claimed = "web-ui"
+	}
+	return beadsapi.NormalizeIdentity("web", claimed, "")
+}
+
+// auditEntry is one decision resolution in an audit query result.
+type auditEntry struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Chosen        string    `json:"chosen"`
+	RespondedBy   string    `json:"respondedBy"`
+	RespondedName string    `json:"respondedByName,omitempty"`
+	RespondedVia  string    `json:"respondedVia,omitempty"`
+	RespondedAt   time.Time `json:"respondedAt"`
+}
+
+// handleAudit handles GET /api/decisions/audit, listing resolved decisions
+// newest first. Query parameters: user (identity, case-insensitive), since
+// and until (RFC 3339), and limit.
+func (a *DecisionAPI) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	user := strings.ToLower(strings.TrimSpace(q.Get("user")))
+	var since, until time.Time
+	for key, t := range map[string]*time.Time{"since": &since, "until": &until} {
+		if v := q.Get(key); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeJSONError(w, http.StatusBadRequest, key+" must be an RFC 3339 time")
+				return
+			}
+			*t = parsed
+		}
+	}
+	limit := 100
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeJSONError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = n
+	}
+
+	decisions, err := a.client.ListDecisions(r.Context(), "closed", auditListLimit)
+	if err != nil {
+		a.logger.Error("failed to list decisions for audit", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to list decisions")
+		return
+	}
+
+	entries := []auditEntry{}
+	for _, d := range decisions {
+		e, ok := newAuditEntry(d.Decision)
+		if !ok || (user != "" && strings.ToLower(e.RespondedBy) != user) {
+			continue
+		}
+		if (!since.IsZero() && e.RespondedAt.Before(since)) || (!until.IsZero() && !e.RespondedAt.Before(until)) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].RespondedAt.After(entries[j].RespondedAt) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	writeJSONResponse(w, map[string]any{"resolutions": entries})
+}
+
+// newAuditEntry builds the audit entry of a resolved decision. Decisions
+// without a responded_at (resolved before it was recorded, or through the
+// daemon's resolve endpoint) fall back to their last update time.
+func newAuditEntry(b *beadsapi.BeadDetail) (auditEntry, bool) {
+	if b == nil || b.Fields[beadsapi.FieldRespondedBy] == "" {
+		return auditEntry{}, false
+	}
+	e := auditEntry{
+		ID:            b.ID,
+		Title:         b.Title,
+		Chosen:        b.Fields["chosen"],
+		RespondedBy:   b.Fields[beadsapi.FieldRespondedBy],
+		RespondedName: b.Fields[beadsapi.FieldRespondedByName],
+		RespondedVia:  b.Fields[beadsapi.FieldRespondedVia],
+		RespondedAt:   b.UpdatedAt,
+	}
+	if t, err := time.Parse(time.RFC3339, b.Fields[beadsapi.FieldRespondedAt]); err == nil {
+		e.RespondedAt = t
+	}
+	return e, true
+}
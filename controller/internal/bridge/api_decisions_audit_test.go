@@ -0,0 +1,97 @@
+package bridge
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"log/slog"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func resolvedDecision(id, by, at string) beadsapi.DecisionDetail {
+	return beadsapi.DecisionDetail{Decision: &beadsapi.BeadDetail{
+		ID:     id,
+		Type:   "decision",
+		Status: "closed",
+		Fields: map[string]string{
+			"chosen":                      "yes",
+			beadsapi.FieldRespondedBy:     by,
+			beadsapi.FieldRespondedVia:    "slack",
+			beadsapi.FieldRespondedAt:     at,
+			beadsapi.FieldRespondedByName: "someone",
+		},
+	}}
+}
+
+func TestDecisionAPI_Audit(t *testing.T) {
+	client := &mockDecisionClient{decisions: []beadsapi.DecisionDetail{
+		resolvedDecision("kd-1", "alice@example.com", "2026-03-01T10:00:00Z"),
+		resolvedDecision("kd-2", "bob@example.com", "2026-03-02T10:00:00Z"),
+		resolvedDecision("kd-3", "alice@example.com", "2026-03-03T10:00:00Z"),
+		{Decision: &beadsapi.BeadDetail{ID: "kd-4", Status: "closed"}}, // no responder recorded
+	}}
+	api := NewDecisionAPI(client, slog.Default())
+	mux := http.NewServeMux()
+	api.RegisterRoutes(mux)
+
+	query := func(q string) []auditEntry {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/decisions/audit"+q, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status=%d, want 200; body=%s", q, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Resolutions []auditEntry `json:"resolutions"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp.Resolutions
+	}
+	ids := func(entries []auditEntry) (out []string) {
+		for _, e := range entries {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	if got := ids(query("")); len(got) != 3 || got[0] != "kd-3" || got[2] != "kd-1" {
+		t.Errorf("all resolutions = %v, want newest first without kd-4", got)
+	}
+	if got := ids(query("?user=Alice@Example.com")); len(got) != 2 || got[0] != "kd-3" || got[1] != "kd-1" {
+		t.Errorf("alice's resolutions = %v", got)
+	}
+	if got := ids(query("?since=2026-03-02T00:00:00Z&until=2026-03-03T10:00:00Z")); len(got) != 1 || got[0] != "kd-2" {
+		t.Errorf("resolutions in range = %v, want [kd-2]", got)
+	}
+	if got := query("?limit=1"); len(got) != 1 || got[0].RespondedVia != "slack" || !got[0].RespondedAt.Equal(time.Date(2026, 3, 3, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("limited resolutions = %+v", got)
+	}
+
+	for _, q := range []string{"?since=yesterday", "?limit=0"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/decisions/audit"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status=%d, want 400", q, w.Code)
+		}
+	}
+}
+
+func TestWebIdentity(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/decisions/kd-1/resolve", nil)
+	if got := webIdentity(req, ""); got != "web:web-ui" {
+		t.Errorf("anonymous identity = %q, want web:web-ui", got)
+	}
+	if got := webIdentity(req, "alice"); got != "web:alice" {
+		t.Errorf("claimed identity = %q, want web:alice", got)
+	}
+	req.Header.Set("X-Forwarded-Email", "Alice@Example.com")
+	if got := webIdentity(req, "mallory"); got != "alice@example.com" {
+		t.Errorf("proxy identity = %q, want alice@example.com", got)
+	}
+}
@@ -0,0 +1,238 @@
+// Package bridge provides the artifact upload watcher.
+//
+// Artifacts watches the kbeads SSE event stream for bead updates that carry an
+// artifact (report, diff, log excerpt) in the "artifact" field and uploads it
+// to Slack as a file snippet in the relevant thread, instead of truncating it
+// into a text block. Uploads are deduplicated per bead and content hash so
+// unrelated updates to the same bead do not re-upload the artifact.
+package bridge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// artifactMaxBytes caps the size of an uploaded artifact. Larger content is
+// truncated on a line boundary with a trailer noting the omitted bytes.
+const artifactMaxBytes = 1 << 20
+
+// artifactInlineLimit is the largest content that is still inlined into a
+// Block Kit section. Slack rejects section text over 3000 characters, so
+// anything larger is uploaded as a snippet instead.
+const artifactInlineLimit = 2800
+
+// Artifact is a piece of agent output destined for a Slack file snippet.
+type Artifact struct {
+	Name    string // file name shown in Slack (optional)
+	Type    string // markdown, patch, junit, log, ...
+	Content string
+}
+
+// artifactFromFields extracts an artifact from bead fields.
+// Returns nil if the bead carries no artifact content.
+func artifactFromFields(fields map[string]string) *Artifact {
+	content := fields["artifact"]
+	if content == "" {
+		return nil
+	}
+	return &Artifact{
+		Name:    fields["artifact_name"],
+		Type:    fields["artifact_type"],
+		Content: content,
+	}
+}
+
+// snippetType maps an artifact type to a Slack snippet type for syntax
+// highlighting.
+func (a Artifact) snippetType() string {
+	switch a.Type {
+	case "markdown", "md", "plan", "checklist", "report":
+		return "markdown"
+	case "patch", "diff", "diff-summary":
+		return "diff"
+	case "junit", "xml":
+		return "xml"
+	case "json":
+		return "javascript"
+	default:
+		return "text"
+	}
+}
+
+// filename returns the artifact's file name, deriving one from its type when
+// no name was supplied.
+func (a Artifact) filename() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	base := a.Type
+	if base == "" {
+		base = "artifact"
+	}
+	switch a.snippetType() {
+	case "markdown":
+		return base + ".md"
+	case "diff":
+		return base + ".patch"
+	case "xml":
+		return base + ".xml"
+	case "javascript":
+		return base + ".json"
+	default:
+		return base + ".txt"
+	}
+}
+
+// clampArtifact truncates content to at most max bytes, cutting on the last
+// line boundary and appending a trailer. Returns the (possibly) truncated
+// content and whether truncation happened.
+func clampArtifact(content string, max int) (string, bool) {
+	if len(content) <= max {
+		return content, false
+	}
+	cut := content[:max]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i+1]
+	}
+	return cut + fmt.Sprintf("\n… truncated %d bytes\n", len(content)-len(cut)), true
+}
+
+// artifactSummary returns a one-line mrkdwn summary for the artifact, used as
+// the upload's initial comment. JUnit reports are parsed for pass/fail counts.
+func artifactSummary(a Artifact) string {
+	label := a.Type
+	if label == "" {
+		label = "artifact"
+	}
+	summary := fmt.Sprintf("%s *%s*", reportEmoji(a.Type), label)
+	if a.Type == "junit" {
+		if s, ok := summarizeJUnit(a.Content); ok {
+			summary += " — " + s
+		}
+	}
+	return summary
+}
+
+// junitSuite captures the counters shared by <testsuite> and <testsuites>.
+type junitSuite struct {
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+// summarizeJUnit parses a JUnit XML report and returns a short pass/fail
+// summary. Returns false if the content is not parseable JUnit.
+func summarizeJUnit(content string) (string, bool) {
+	var root junitSuite
+	if err := xml.Unmarshal([]byte(content), &root); err != nil {
+		return "", false
+	}
+	// A <testsuites> root may omit aggregate counters; sum the children.
+	if root.Tests == 0 {
+		for _, s := range root.Suites {
+			root.Tests += s.Tests
+			root.Failures += s.Failures
+			root.Errors += s.Errors
+			root.Skipped += s.Skipped
+		}
+	}
+	if root.Tests == 0 {
+		return "", false
+	}
+	failed := root.Failures + root.Errors
+	passed := root.Tests - failed - root.Skipped
+	emoji := ":white_check_mark:"
+	if failed > 0 {
+		emoji = ":x:"
+	}
+	s := fmt.Sprintf("%s %d passed, %d failed", emoji, passed, failed)
+	if root.Skipped > 0 {
+		s += fmt.Sprintf(", %d skipped", root.Skipped)
+	}
+	return s, true
+}
+
+// ArtifactUploader uploads bead artifacts to Slack.
+type ArtifactUploader interface {
+	PostArtifact(ctx context.Context, bead BeadEvent, artifact Artifact) error
+}
+
+// ArtifactsConfig holds configuration for the Artifacts watcher.
+type ArtifactsConfig struct {
+	Uploader ArtifactUploader
+	Logger   *slog.Logger
+}
+
+// Artifacts watches the kbeads SSE event stream for beads carrying artifacts.
+type Artifacts struct {
+	uploader ArtifactUploader
+	logger   *slog.Logger
+
+	mu       sync.Mutex
+	uploaded map[string]string // bead ID → content hash of last upload
+}
+
+// NewArtifacts creates a new artifact upload watcher.
+func NewArtifacts(cfg ArtifactsConfig) *Artifacts {
+	return &Artifacts{
+		uploader: cfg.Uploader,
+		logger:   cfg.Logger,
+		uploaded: make(map[string]string),
+	}
+}
+
+// RegisterHandlers registers SSE event handlers on the given stream for
+// bead updated and closed events.
+func (a *Artifacts) RegisterHandlers(stream *SSEStream) {
+	stream.On("beads.bead.updated", a.handleEvent)
+	stream.On("beads.bead.closed", a.handleEvent)
+	a.logger.Info("artifacts watcher registered SSE handlers",
+		"topics", []string{"beads.bead.updated", "beads.bead.closed"})
+}
+
+func (a *Artifacts) handleEvent(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	// Report beads are rendered by the decisions watcher via PostReport.
+	if bead.Type == "report" {
+		return
+	}
+	artifact := artifactFromFields(bead.Fields)
+	if artifact == nil {
+		return
+	}
+
+	sum := sha256.Sum256([]byte(artifact.Content))
+	hash := hex.EncodeToString(sum[:8])
+	a.mu.Lock()
+	if a.uploaded[bead.ID] == hash {
+		a.mu.Unlock()
+		return
+	}
+	a.uploaded[bead.ID] = hash
+	a.mu.Unlock()
+
+	a.logger.Info("bead artifact received",
+		"id", bead.ID, "type", artifact.Type, "bytes", len(artifact.Content), "assignee", bead.Assignee)
+
+	if a.uploader == nil {
+		return
+	}
+	if err := a.uploader.PostArtifact(ctx, *bead, *artifact); err != nil {
+		a.logger.Error("failed to upload artifact to Slack", "bead", bead.ID, "error", err)
+		// Allow a retry on the next update carrying the same artifact.
+		a.mu.Lock()
+		delete(a.uploaded, bead.ID)
+		a.mu.Unlock()
+	}
+}
@@ -0,0 +1,128 @@
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type mockArtifactUploader struct {
+	mu    sync.Mutex
+	calls []Artifact
+}
+
+func (m *mockArtifactUploader) PostArtifact(_ context.Context, _ BeadEvent, a Artifact) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, a)
+	return nil
+}
+
+func TestArtifacts_UploadsOncePerContent(t *testing.T) {
+	up := &mockArtifactUploader{}
+	a := NewArtifacts(ArtifactsConfig{Uploader: up, Logger: slog.Default()})
+
+	event := marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-1", Type: "task", Assignee: "gasboat/crew/bot",
+		Fields: map[string]string{"artifact": "--- a\n+++ b\n", "artifact_type": "patch"},
+	})
+	a.handleEvent(context.Background(), event)
+	a.handleEvent(context.Background(), event)
+
+	if len(up.calls) != 1 {
+		t.Fatalf("expected 1 upload, got %d", len(up.calls))
+	}
+	if up.calls[0].Type != "patch" {
+		t.Errorf("expected patch type, got %q", up.calls[0].Type)
+	}
+
+	changed := marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-1", Type: "task",
+		Fields: map[string]string{"artifact": "--- a\n+++ c\n", "artifact_type": "patch"},
+	})
+	a.handleEvent(context.Background(), changed)
+	if len(up.calls) != 2 {
+		t.Errorf("expected re-upload on changed content, got %d uploads", len(up.calls))
+	}
+}
+
+func TestArtifacts_SkipsReportsAndEmpty(t *testing.T) {
+	up := &mockArtifactUploader{}
+	a := NewArtifacts(ArtifactsConfig{Uploader: up, Logger: slog.Default()})
+
+	a.handleEvent(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "rpt-1", Type: "report", Fields: map[string]string{"artifact": "x"},
+	}))
+	a.handleEvent(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-2", Type: "task", Fields: map[string]string{"other": "x"},
+	}))
+	if len(up.calls) != 0 {
+		t.Errorf("expected no uploads, got %d", len(up.calls))
+	}
+}
+
+func TestArtifact_SnippetTypeAndFilename(t *testing.T) {
+	tests := []struct {
+		a        Artifact
+		snippet  string
+		filename string
+	}{
+		{Artifact{Type: "markdown"}, "markdown", "markdown.md"},
+		{Artifact{Type: "patch"}, "diff", "patch.patch"},
+		{Artifact{Type: "junit"}, "xml", "junit.xml"},
+		{Artifact{Type: "log", Name: "build.log"}, "text", "build.log"},
+		{Artifact{}, "text", "artifact.txt"},
+	}
+	for _, tt := range tests {
+		if got := tt.a.snippetType(); got != tt.snippet {
+			t.Errorf("snippetType(%q) = %q, want %q", tt.a.Type, got, tt.snippet)
+		}
+		if got := tt.a.filename(); got != tt.filename {
+			t.Errorf("filename(%q) = %q, want %q", tt.a.Type, got, tt.filename)
+		}
+	}
+}
+
+func TestClampArtifact(t *testing.T) {
+	content := "line1\nline2\nline3\n"
+	got, truncated := clampArtifact(content, 100)
+	if truncated || got != content {
+		t.Errorf("expected content unchanged, got %q", got)
+	}
+
+	got, truncated = clampArtifact(content, 9)
+	if !truncated {
+		t.Fatal("expected truncation")
+	}
+	if !strings.HasPrefix(got, "line1\n") || strings.Contains(got, "line2") {
+		t.Errorf("expected cut on line boundary, got %q", got)
+	}
+	if !strings.Contains(got, "truncated 12 bytes") {
+		t.Errorf("expected truncation trailer, got %q", got)
+	}
+}
+
+func TestSummarizeJUnit(t *testing.T) {
+	suites := `<testsuites>
+  <testsuite name="a" tests="3" failures="1" errors="0" skipped="1"></testsuite>
+  <testsuite name="b" tests="2" failures="0" errors="1"></testsuite>
+</testsuites>`
+	got, ok := summarizeJUnit(suites)
+	if !ok {
+		t.Fatal("expected parse success")
+	}
+	if got != ":x: 2 passed, 2 failed, 1 skipped" {
+		t.Errorf("unexpected summary %q", got)
+	}
+
+	got, ok = summarizeJUnit(`<testsuite tests="4" failures="0"></testsuite>`)
+	if !ok || got != ":white_check_mark: 4 passed, 0 failed" {
+		t.Errorf("unexpected summary %q (ok=%v)", got, ok)
+	}
+
+	if _, ok := summarizeJUnit("not xml"); ok {
+		t.Error("expected parse failure for non-XML")
+	}
+}
@@ -10,7 +10,13 @@
 //   - bot_commands.go — slash command handlers (/spawn, /decisions, /roster)
 //   - bot_decisions.go — decision notifications, modals, resolve/dismiss
 //   - bot_decisions_modal.go — decision modal rendering
+//   - bot_home.go — App Home tab view and spawn modal
+//   - bot_agent_command.go — /agent spawn (with confirmation modal), /agent stop, and /agent logs
+//   - bot_artifacts.go — artifact uploads as Slack file snippets
 //   - bot_mentions.go — @mention handling in agent threads
+//   - bot_mutes.go — /mute, /unmute, and agent card mute menu
+//   - bot_reactions.go — emoji reaction shortcuts for decision resolution
+//   - bot_thread_summary.go — discussion thread summary on decision resolution
 //   - bot_notifications.go — agent crash, jack on/off/expired alerts
 package bridge
 
@@ -18,6 +24,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -39,21 +46,46 @@ type Bot struct {
 	router *Router
 	logger *slog.Logger
 
+	// Notification message templates; nil renders the embedded defaults.
+	templates *NotificationTemplates
+
+	// Emoji name → decision option (index or label) for reaction resolution.
+	reactionShortcuts map[string]string
+
+	// Per-channel locale selection for user-facing strings; nil = English.
+	locales *Localizer
+
+	// Severity routing of notifications, and those waiting for the digest.
+	severity SeverityRouting
+	digestMu sync.Mutex
+	digest   map[string][]digestLine // channel → queued notifications
+
+	// Delivery metrics (Slack API latency, reconnects); nil = disabled.
+	metrics *Metrics
+
 	channel   string // default channel ID
 	botUserID string // bot's own user ID (set on connect)
 
 	// Health state.
-	connected        atomic.Bool
-	numConnections   atomic.Int32
+	connected      atomic.Bool
+	everConnected  atomic.Bool // set on first connect; later connects are reconnects
+	numConnections atomic.Int32
 
 	// Threading mode: "" / "flat" = flat messages, "agent" = threaded under agent cards.
 	threadingMode string
 
 	// GitHub client for /unreleased command.
-	github        *GitHubClient
-	repos         []RepoRef
-	version       string
-	controllerURL string
+	github            *GitHubClient
+	repos             []RepoRef
+	version           string
+	controllerURL     string
+	spawnPreviewToken string // bearer token for /spawn-preview
+	agentLogsToken    string // bearer token for /agent-logs
+
+	// Deep links in jack notifications; empty omits the link.
+	beadURL      string // bead page URL with an {id} placeholder
+	dashboardURL string
+	coopTokens   *CoopTokenClient // coop terminal links via the controller's proxy; nil = in-cluster URL
 
 	// In-memory decision tracking (augments StateManager).
 	mu           sync.Mutex
@@ -62,25 +94,48 @@ type Bot struct {
 	agentPending map[string]int        // agent identity → pending decision count
 	agentState   map[string]string     // agent identity → last known agent_state
 	agentSeen    map[string]time.Time  // agent identity → last activity timestamp
+
+	threadSummarized map[string]bool // decision bead IDs whose thread was summarized
+
+	identities sync.Map // Slack user ID → beadsapi.Responder (decision audit)
 }
 
 // BotConfig holds configuration for the Socket Mode bot.
 type BotConfig struct {
-	BotToken       string
-	AppToken       string
-	Channel        string
-	ThreadingMode  string // "agent" (default) or "flat" — controls decision threading
-	Daemon         BeadClient
-	State          *StateManager
-	Router         *Router // optional channel router; nil = all to Channel
-	Logger         *slog.Logger
-	Debug          bool
+	BotToken          string
+	AppToken          string
+	Channel           string
+	ThreadingMode     string // "agent" (default) or "flat" — controls decision threading
+	Daemon            BeadClient
+	State             *StateManager
+	Router            *Router                // optional channel router; nil = all to Channel
+	Templates         *NotificationTemplates // optional message templates; nil = defaults
+	ReactionShortcuts map[string]string      // emoji → option index/label; merged over :one:–:nine:
+	Locales           *Localizer             // optional per-channel locales; nil = English
+	Severity          SeverityRouting        // notification routing; zero = DefaultSeverityRules
+	Metrics           *Metrics               // optional delivery metrics
+	Logger            *slog.Logger
+	Debug             bool
 
 	// GitHub /unreleased command support.
 	GitHubToken   string
 	Repos         []RepoRef
 	Version       string
 	ControllerURL string
+	// SpawnPreviewToken is the bearer token the controller requires on
+	// /spawn-preview (SPAWN_PREVIEW_TOKEN).
+	SpawnPreviewToken string
+	// AgentLogsToken is the bearer token the controller requires on
+	// /agent-logs (AGENT_LOGS_TOKEN).
+	AgentLogsToken string
+
+	// Jack notification links: BeadURL is a bead page URL containing an
+	// {id} placeholder, e.g. "https://beads.example.com/beads/{id}".
+	BeadURL      string
+	DashboardURL string
+	// CoopTokens mints the tokens of coop terminal links; nil links the
+	// session's in-cluster URL.
+	CoopTokens *CoopTokenClient
 }
 
 // NewBot creates a new Socket Mode bot.
@@ -88,6 +143,7 @@ func NewBot(cfg BotConfig) *Bot {
 	api := slack.New(
 		cfg.BotToken,
 		slack.OptionAppLevelToken(cfg.AppToken),
+		slack.OptionHTTPClient(cfg.Metrics.HTTPClient(nil)),
 	)
 
 	socket := socketmode.New(
@@ -101,23 +157,33 @@ func NewBot(cfg BotConfig) *Bot {
 	}
 
 	b := &Bot{
-		api:           api,
-		socket:        socket,
-		state:         cfg.State,
-		daemon:        cfg.Daemon,
-		router:        cfg.Router,
-		logger:        cfg.Logger,
-		channel:       cfg.Channel,
-		threadingMode: cfg.ThreadingMode,
-		messages:      make(map[string]MessageRef),
-		agentCards:    make(map[string]MessageRef),
-		agentPending:  make(map[string]int),
-		agentState:    make(map[string]string),
-		agentSeen:     make(map[string]time.Time),
-		github:        gh,
-		repos:         cfg.Repos,
-		version:       cfg.Version,
-		controllerURL: cfg.ControllerURL,
+		api:               api,
+		socket:            socket,
+		state:             cfg.State,
+		daemon:            cfg.Daemon,
+		router:            cfg.Router,
+		logger:            cfg.Logger,
+		templates:         cfg.Templates,
+		reactionShortcuts: cfg.ReactionShortcuts,
+		locales:           cfg.Locales,
+		severity:          cfg.Severity,
+		metrics:           cfg.Metrics,
+		channel:           cfg.Channel,
+		threadingMode:     cfg.ThreadingMode,
+		messages:          make(map[string]MessageRef),
+		agentCards:        make(map[string]MessageRef),
+		agentPending:      make(map[string]int),
+		agentState:        make(map[string]string),
+		agentSeen:         make(map[string]time.Time),
+		github:            gh,
+		repos:             cfg.Repos,
+		version:           cfg.Version,
+		controllerURL:     cfg.ControllerURL,
+		spawnPreviewToken: cfg.SpawnPreviewToken,
+		agentLogsToken:    cfg.AgentLogsToken,
+		beadURL:           cfg.BeadURL,
+		dashboardURL:      cfg.DashboardURL,
+		coopTokens:        cfg.CoopTokens,
 	}
 
 	// Hydrate hot caches from persisted state.
@@ -207,6 +273,9 @@ func (b *Bot) handleEvent(ctx context.Context, evt socketmode.Event) {
 
 	case socketmode.EventTypeConnected:
 		b.connected.Store(true)
+		if b.everConnected.Swap(true) {
+			b.metrics.IncSocketReconnect()
+		}
 		b.logger.Info("Slack Socket Mode connected")
 
 	case socketmode.EventTypeConnectionError:
@@ -248,6 +317,10 @@ func (b *Bot) handleEventsAPI(ctx context.Context, event slackevents.EventsAPIEv
 			b.handleMessageEvent(ctx, ev)
 		case *slackevents.AppMentionEvent:
 			b.handleAppMention(ctx, ev)
+		case *slackevents.AppHomeOpenedEvent:
+			b.handleAppHomeOpened(ctx, ev)
+		case *slackevents.ReactionAddedEvent:
+			b.handleReactionAdded(ctx, ev)
 		}
 	}
 }
@@ -409,6 +482,7 @@ func (b *Bot) handleThreadReply(ctx context.Context, ev *slackevents.MessageEven
 			"chosen":    ev.Text,
 			"rationale": fmt.Sprintf("Thread reply by %s via Slack", username),
 		}
+		maps.Copy(fields, b.slackResponder(ctx, ev.User, username).AuditFields(time.Now()))
 		if err := b.daemon.CloseBead(ctx, beadID, fields); err != nil {
 			b.logger.Error("failed to resolve decision via thread reply",
 				"bead", beadID, "error", err)
@@ -445,6 +519,12 @@ func (b *Bot) handleInteraction(ctx context.Context, evt socketmode.Event, callb
 		b.handleBlockActions(ctx, callback)
 
 	case slack.InteractionTypeViewSubmission:
+		// Invalid input is answered in the ack so Slack keeps the modal
+		// open with the errors shown next to the fields.
+		if resp := validateViewSubmission(callback); resp != nil {
+			b.socket.Ack(*evt.Request, resp)
+			return
+		}
 		b.socket.Ack(*evt.Request)
 		b.handleViewSubmission(ctx, callback)
 
@@ -494,7 +574,8 @@ func (b *Bot) updateMessageResolved(ctx context.Context, beadID, chosen, rationa
 		return
 	}
 
-	text := fmt.Sprintf(":white_check_mark: *Resolved*: %s", chosen)
+	locale := b.locales.LocaleFor(channelID)
+	text := fmt.Sprintf(":white_check_mark: *%s*: %s", Tr(locale, "decision.resolved"), chosen)
 	if rationale != "" {
 		text += fmt.Sprintf("\n_%s_", rationale)
 	}
@@ -507,7 +588,7 @@ func (b *Bot) updateMessageResolved(ctx context.Context, beadID, chosen, rationa
 	}
 
 	_, _, _, err := b.api.UpdateMessageContext(ctx, channelID, messageTS,
-		slack.MsgOptionText(fmt.Sprintf("Decision resolved: %s", chosen), false),
+		slack.MsgOptionText(Tr(locale, "decision.resolved_text", chosen), false),
 		slack.MsgOptionBlocks(blocks...),
 	)
 	if err != nil {
@@ -515,6 +596,9 @@ func (b *Bot) updateMessageResolved(ctx context.Context, beadID, chosen, rationa
 		return
 	}
 
+	// Summarize any discussion thread and record its permalink on the bead.
+	b.summarizeDecisionThread(ctx, beadID, chosen, rationale, channelID, messageTS)
+
 	// Decrement agent pending count and update card. Clear the Agent field
 	// on the cached ref to prevent double-decrement when the SSE close event
 	// triggers UpdateDecision after the modal submit already resolved it.
@@ -533,7 +617,11 @@ func (b *Bot) updateMessageResolved(ctx context.Context, beadID, chosen, rationa
 	b.mu.Unlock()
 
 	// Remove from persisted state so pending count doesn't re-inflate on restart.
+	// Messages in an agent thread are remembered for thread compaction.
 	if b.state != nil {
+		if hadRef && b.agentThreadingEnabled() && agent != "" {
+			_ = b.state.SetResolvedMessage(beadID, MessageRef{ChannelID: channelID, Timestamp: messageTS, Agent: agent})
+		}
 		_ = b.state.RemoveDecisionMessage(beadID)
 	}
 
@@ -0,0 +1,369 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// spawnPreviewInfo is the response from the controller's /spawn-preview endpoint.
+type spawnPreviewInfo struct {
+	Project        string `json:"project"`
+	Role           string `json:"role"`
+	Mode           string `json:"mode"`
+	Image          string `json:"image"`
+	Namespace      string `json:"namespace"`
+	ServiceAccount string `json:"serviceAccount"`
+	StorageClass   string `json:"storageClass"`
+	StorageSize    string `json:"storageSize"`
+	CPURequest     string `json:"cpuRequest"`
+	CPULimit       string `json:"cpuLimit"`
+	MemoryRequest  string `json:"memoryRequest"`
+	MemoryLimit    string `json:"memoryLimit"`
+	GitURL         string `json:"gitURL"`
+	GitBranch      string `json:"gitBranch"`
+}
+
+// handleAgentCommand processes the /agent slash command.
+// Usage: /agent spawn <project> <role> <name> | /agent stop <name> | /agent logs <name> [lines]
+func (b *Bot) handleAgentCommand(ctx context.Context, cmd slack.SlashCommand) {
+	args := strings.Fields(strings.TrimSpace(cmd.Text))
+	if len(args) == 0 {
+		b.postAgentUsage(cmd)
+		return
+	}
+	switch args[0] {
+	case "spawn":
+		b.handleAgentSpawn(ctx, cmd, args[1:])
+	case "stop":
+		b.handleAgentStop(ctx, cmd, args[1:])
+	case "logs":
+		b.handleAgentLogs(ctx, cmd, args[1:])
+	default:
+		b.postAgentUsage(cmd)
+	}
+}
+
+func (b *Bot) postAgentUsage(cmd slack.SlashCommand) {
+	_, _ = b.api.PostEphemeral(cmd.ChannelID, cmd.UserID,
+		slack.MsgOptionText(":x: Usage: `/agent spawn <project> <role> <name>`, `/agent stop <name>`, or `/agent logs <name> [lines]`", false))
+}
+
+// handleAgentSpawn validates the spawn arguments and opens a confirmation
+// modal with the resolved pod configuration. Nothing is created until the
+// modal is submitted.
+func (b *Bot) handleAgentSpawn(ctx context.Context, cmd slack.SlashCommand, args []string) {
+	if len(args) != 3 {
+		b.postAgentUsage(cmd)
+		return
+	}
+	project, role, agentName := args[0], args[1], args[2]
+	if !isValidAgentName(agentName) {
+		_, _ = b.api.PostEphemeral(cmd.ChannelID, cmd.UserID,
+			slack.MsgOptionText(fmt.Sprintf(":x: Invalid agent name %q — use lowercase letters, digits, and hyphens only", agentName), false))
+		return
+	}
+
+	projects, err := b.daemon.ListProjectBeads(ctx)
+	if err != nil {
+		b.logger.Error("agent spawn: failed to list projects", "error", err)
+		_, _ = b.api.PostEphemeral(cmd.ChannelID, cmd.UserID,
+			slack.MsgOptionText(":x: Failed to look up projects", false))
+		return
+	}
+	info, ok := projects[project]
+	if !ok {
+		names := make([]string, 0, len(projects))
+		for name := range projects {
+			names = append(names, name)
+		}
+		_, _ = b.api.PostEphemeral(cmd.ChannelID, cmd.UserID,
+			slack.MsgOptionText(fmt.Sprintf(":x: Unknown project %q — available: %s", project, strings.Join(names, ", ")), false))
+		return
+	}
+
+	preview, err := fetchSpawnPreview(ctx, b.controllerURL, b.spawnPreviewToken, project, role, agentName)
+	if err != nil {
+		b.logger.Warn("spawn preview: fetch failed", "agent", agentName, "error", err)
+	}
+	if preview == nil {
+		// Controller unreachable: fall back to what the project bead declares.
+		preview = &spawnPreviewInfo{
+			Project:        project,
+			Role:           role,
+			Image:          info.Image,
+			ServiceAccount: info.ServiceAccount,
+			StorageClass:   info.StorageClass,
+			GitURL:         info.GitURL,
+			GitBranch:      info.DefaultBranch,
+		}
+	}
+
+	// Encode metadata: project|role|agentName|channelID
+	metadata := fmt.Sprintf("%s|%s|%s|%s", project, role, agentName, cmd.ChannelID)
+	modal := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Title:           slack.NewTextBlockObject("plain_text", "Confirm Spawn", false, false),
+		Submit:          slack.NewTextBlockObject("plain_text", "Spawn", false, false),
+		Close:           slack.NewTextBlockObject("plain_text", "Cancel", false, false),
+		Blocks:          slack.Blocks{BlockSet: buildSpawnPreviewBlocks(agentName, preview)},
+		PrivateMetadata: metadata,
+		CallbackID:      "agent_spawn_confirm",
+	}
+	if _, err := b.api.OpenViewContext(ctx, cmd.TriggerID, modal); err != nil {
+		b.logger.Error("failed to open agent spawn confirmation", "agent", agentName, "error", err)
+	}
+}
+
+// buildSpawnPreviewBlocks renders the resolved spawn configuration.
+func buildSpawnPreviewBlocks(agentName string, p *spawnPreviewInfo) []slack.Block {
+	orDefault := func(s string) string {
+		if s == "" {
+			return "_controller default_"
+		}
+		return "`" + s + "`"
+	}
+
+	header := fmt.Sprintf(":rocket: Spawn *%s* in project *%s* with role *%s*?", agentName, p.Project, p.Role)
+
+	resources := "_controller default_"
+	if p.CPURequest != "" || p.MemoryRequest != "" {
+		resources = fmt.Sprintf("cpu `%s`/`%s` · memory `%s`/`%s` (request/limit)",
+			p.CPURequest, p.CPULimit, p.MemoryRequest, p.MemoryLimit)
+	}
+
+	lines := []string{
+		fmt.Sprintf("*Image:* %s", orDefault(p.Image)),
+		fmt.Sprintf("*Resources:* %s", resources),
+	}
+	if p.Mode != "" {
+		lines = append(lines, fmt.Sprintf("*Mode:* `%s`", p.Mode))
+	}
+	if p.Namespace != "" {
+		lines = append(lines, fmt.Sprintf("*Namespace:* `%s`", p.Namespace))
+	}
+	lines = append(lines, fmt.Sprintf("*Service account:* %s", orDefault(p.ServiceAccount)))
+	storage := orDefault(p.StorageClass)
+	if p.StorageSize != "" {
+		storage += fmt.Sprintf(" (%s)", p.StorageSize)
+	}
+	lines = append(lines, fmt.Sprintf("*Storage class:* %s", storage))
+	if p.GitURL != "" {
+		branch := p.GitBranch
+		if branch == "" {
+			branch = "main"
+		}
+		lines = append(lines, fmt.Sprintf("*Repo:* %s @ `%s`", p.GitURL, branch))
+	}
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", header, false, false), nil, nil),
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", strings.Join(lines, "\n"), false, false), nil, nil),
+	}
+	if p.Mode == "" {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject("mrkdwn", ":warning: Controller unreachable — showing project bead config only.", false, false)))
+	}
+	return blocks
+}
+
+// handleAgentSpawnConfirm processes the spawn confirmation modal submission.
+func (b *Bot) handleAgentSpawnConfirm(ctx context.Context, callback slack.InteractionCallback) {
+	metadata := callback.View.PrivateMetadata
+	// Metadata format: project|role|agentName|channelID
+	parts := strings.SplitN(metadata, "|", 4)
+	if len(parts) < 4 {
+		b.logger.Error("invalid agent spawn modal metadata", "metadata", metadata)
+		return
+	}
+	project, role, agentName, channelID := parts[0], parts[1], parts[2], parts[3]
+
+	beadID, err := b.daemon.SpawnAgent(ctx, agentName, project, "", role)
+	if err != nil {
+		b.logger.Error("agent spawn: failed to spawn agent", "agent", agentName, "project", project, "role", role, "error", err)
+		_, _ = b.api.PostEphemeral(channelID, callback.User.ID,
+			slack.MsgOptionText(fmt.Sprintf(":x: Failed to spawn agent %q: %s", agentName, err.Error()), false))
+		return
+	}
+	if b.state != nil {
+		_ = b.state.SetAgentSpawner(agentName, callback.User.ID)
+	}
+	b.logger.Info("spawned agent via /agent", "agent", agentName, "project", project, "role", role, "bead", beadID, "user", callback.User.ID)
+
+	_, _ = b.api.PostEphemeral(channelID, callback.User.ID,
+		slack.MsgOptionText(fmt.Sprintf(":rocket: Spawning agent *%s* in project *%s* with role *%s*\nBead: `%s` · Use `/roster` to check status.",
+			agentName, project, role, beadID), false))
+}
+
+// handleAgentStop closes the named agent's bead after a graceful coop shutdown.
+func (b *Bot) handleAgentStop(ctx context.Context, cmd slack.SlashCommand, args []string) {
+	if len(args) != 1 {
+		b.postAgentUsage(cmd)
+		return
+	}
+	agentName := args[0]
+	if err := b.killAgent(ctx, agentName, false); err != nil {
+		b.logger.Error("agent stop: failed to stop agent", "agent", agentName, "error", err)
+		_, _ = b.api.PostEphemeral(cmd.ChannelID, cmd.UserID,
+			slack.MsgOptionText(fmt.Sprintf(":x: Failed to stop agent %q: %s", agentName, err.Error()), false))
+		return
+	}
+	b.logger.Info("stopped agent via /agent", "agent", agentName, "user", cmd.UserID)
+	_, _ = b.api.PostEphemeral(cmd.ChannelID, cmd.UserID,
+		slack.MsgOptionText(fmt.Sprintf(":octagonal_sign: Agent *%s* stopped.", agentName), false))
+}
+
+// fetchSpawnPreview queries the controller's /spawn-preview endpoint with
+// its bearer token. Returns nil without an error if the controller URL is
+// unset.
+func fetchSpawnPreview(ctx context.Context, baseURL, token, project, role, agentName string) (*spawnPreviewInfo, error) {
+	if baseURL == "" {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	q := url.Values{"project": {project}, "role": {role}, "agent": {agentName}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/spawn-preview?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("controller returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var info spawnPreviewInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("decoding preview: %w", err)
+	}
+	return &info, nil
+}
+
+// agentLogsMaxChars keeps the log snippet under Slack's 3000-character
+// section text limit once wrapped in a code block.
+const agentLogsMaxChars = 2900
+
+// handleAgentLogs posts the tail of the agent's container log as an
+// ephemeral snippet with a refresh button. Logs come from the controller's
+// /agent-logs proxy so users need no cluster access.
+func (b *Bot) handleAgentLogs(ctx context.Context, cmd slack.SlashCommand, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		b.postAgentUsage(cmd)
+		return
+	}
+	agentName, lines := args[0], 0
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			b.postAgentUsage(cmd)
+			return
+		}
+		lines = n
+	}
+	_, _ = b.api.PostEphemeral(cmd.ChannelID, cmd.UserID,
+		slack.MsgOptionBlocks(b.agentLogsBlocks(ctx, agentName, lines)...),
+		slack.MsgOptionText(fmt.Sprintf("Logs for %s", agentName), false))
+}
+
+// handleAgentLogsRefresh re-fetches logs and replaces the ephemeral snippet.
+// value is "agentName|lines".
+func (b *Bot) handleAgentLogsRefresh(ctx context.Context, value string, callback slack.InteractionCallback) {
+	agentName, linesStr, _ := strings.Cut(value, "|")
+	lines, _ := strconv.Atoi(linesStr)
+	_, _ = b.api.PostEphemeralContext(ctx, callback.Channel.ID, callback.User.ID,
+		slack.MsgOptionReplaceOriginal(callback.ResponseURL),
+		slack.MsgOptionBlocks(b.agentLogsBlocks(ctx, agentName, lines)...),
+		slack.MsgOptionText(fmt.Sprintf("Logs for %s", agentName), false))
+}
+
+// agentLogsBlocks fetches the log tail and renders it, or an error message
+// if the controller is unreachable or the agent has no pod.
+func (b *Bot) agentLogsBlocks(ctx context.Context, agentName string, lines int) []slack.Block {
+	logs, podName, err := fetchAgentLogs(ctx, b.controllerURL, b.agentLogsToken, agentName, lines)
+	if err != nil {
+		b.logger.Warn("agent logs: fetch failed", "agent", agentName, "error", err)
+		return []slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn",
+			fmt.Sprintf(":x: Failed to fetch logs for *%s*: %s", agentName, err.Error()), false, false), nil, nil)}
+	}
+
+	logs = strings.TrimRight(logs, "\n")
+	truncated := false
+	if len(logs) > agentLogsMaxChars {
+		logs = logs[len(logs)-agentLogsMaxChars:]
+		if i := strings.IndexByte(logs, '\n'); i >= 0 {
+			logs = logs[i+1:]
+		}
+		truncated = true
+	}
+	if logs == "" {
+		logs = "(no output)"
+	}
+
+	footer := fmt.Sprintf("Pod `%s` · fetched %s", podName, time.Now().UTC().Format("15:04:05 UTC"))
+	if truncated {
+		footer += " · truncated to fit Slack"
+	}
+	refresh := slack.NewButtonBlockElement("agent_logs_refresh", fmt.Sprintf("%s|%d", agentName, lines),
+		slack.NewTextBlockObject("plain_text", "Refresh", false, false))
+	return []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn",
+			fmt.Sprintf(":scroll: Logs for *%s*\n```%s```", agentName, logs), false, false), nil, nil),
+		slack.NewContextBlock("", slack.NewTextBlockObject("mrkdwn", footer, false, false)),
+		slack.NewActionBlock("", refresh),
+	}
+}
+
+// fetchAgentLogs queries the controller's /agent-logs endpoint with its
+// bearer token. lines <= 0 uses the controller default. Returns the log text
+// and pod name.
+func fetchAgentLogs(ctx context.Context, baseURL, token, agentName string, lines int) (string, string, error) {
+	if baseURL == "" {
+		return "", "", fmt.Errorf("controller URL not configured")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	q := url.Values{"agent": {agentName}}
+	if lines > 0 {
+		q.Set("lines", strconv.Itoa(lines))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/agent-logs?"+q.Encode(), nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("controller unreachable")
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("%s", strings.TrimSpace(string(body)))
+	}
+	return string(body), resp.Header.Get("X-Pod-Name"), nil
+}
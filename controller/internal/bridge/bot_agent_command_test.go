@@ -0,0 +1,194 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+)
+
+func TestHandleAgentSpawn_OpensConfirmationWithoutSpawning(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.seedProject("gasboat")
+
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/spawn-preview" || r.URL.Query().Get("project") != "gasboat" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(spawnPreviewInfo{
+			Project: "gasboat", Role: "crew", Mode: "crew", Image: "ghcr.io/org/agent:v1",
+			CPURequest: "500m", CPULimit: "2", MemoryRequest: "1Gi", MemoryLimit: "4Gi",
+		})
+	}))
+	defer controller.Close()
+
+	var mu sync.Mutex
+	var opened string
+	slackSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "views.open") {
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			raw, _ := json.Marshal(body["view"])
+			mu.Lock()
+			opened = string(raw)
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	defer slackSrv.Close()
+
+	bot := newTestBot(daemon, slackSrv)
+	bot.controllerURL = controller.URL
+	bot.spawnPreviewToken = "s3cret"
+
+	bot.handleAgentCommand(context.Background(), slack.SlashCommand{
+		Command: "/agent", Text: "spawn gasboat crew my-bot",
+		ChannelID: "C1", UserID: "U1", TriggerID: "T1",
+	})
+
+	if n := len(filterAgentBeads(daemon.beads)); n != 0 {
+		t.Fatalf("expected no agent bead before confirmation, got %d", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, want := range []string{"agent_spawn_confirm", "ghcr.io/org/agent:v1", "500m", "4Gi", "gasboat|crew|my-bot|C1"} {
+		if !strings.Contains(opened, want) {
+			t.Errorf("expected modal to contain %q, got %s", want, opened)
+		}
+	}
+}
+
+func TestHandleAgentSpawnConfirm_SpawnsAgent(t *testing.T) {
+	daemon := newMockDaemon()
+	slackSrv := newFakeSlackServer(t)
+	defer slackSrv.Close()
+
+	bot := newTestBot(daemon, slackSrv)
+	bot.handleAgentSpawnConfirm(context.Background(), slack.InteractionCallback{
+		User: slack.User{ID: "U1"},
+		View: slack.View{PrivateMetadata: "gasboat|crew|my-bot|C1"},
+	})
+
+	agentBeads := filterAgentBeads(daemon.beads)
+	if len(agentBeads) != 1 {
+		t.Fatalf("expected 1 agent bead, got %d", len(agentBeads))
+	}
+	for _, b := range agentBeads {
+		if b.Title != "my-bot" {
+			t.Errorf("expected title=my-bot, got %s", b.Title)
+		}
+	}
+}
+
+func TestBuildSpawnPreviewBlocks_ControllerUnreachable(t *testing.T) {
+	blocks := buildSpawnPreviewBlocks("my-bot", &spawnPreviewInfo{Project: "gasboat", Role: "crew", GitURL: "https://github.com/org/repo"})
+	raw, _ := json.Marshal(blocks)
+	s := string(raw)
+	for _, want := range []string{"controller default", "Controller unreachable", "https://github.com/org/repo"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected blocks to contain %q", want)
+		}
+	}
+}
+
+func TestFetchSpawnPreview_ReportsRejection(t *testing.T) {
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer controller.Close()
+
+	p, err := fetchSpawnPreview(context.Background(), controller.URL, "wrong", "gasboat", "crew", "my-bot")
+	if p != nil || err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected a 401 error, got preview=%v err=%v", p, err)
+	}
+	if p, err := fetchSpawnPreview(context.Background(), "", "", "gasboat", "crew", "my-bot"); p != nil || err != nil {
+		t.Errorf("expected no preview and no error without a controller URL, got %v, %v", p, err)
+	}
+}
+
+func TestHandleAgentCommand_UnknownProjectRejected(t *testing.T) {
+	daemon := newMockDaemon()
+	slackSrv := newFakeSlackServer(t)
+	defer slackSrv.Close()
+
+	bot := newTestBot(daemon, slackSrv)
+	bot.handleAgentCommand(context.Background(), slack.SlashCommand{
+		Command: "/agent", Text: "spawn nope crew my-bot", ChannelID: "C1", UserID: "U1",
+	})
+	if n := len(filterAgentBeads(daemon.beads)); n != 0 {
+		t.Fatalf("expected no agent bead, got %d", n)
+	}
+}
+
+func TestAgentLogsBlocks_RendersTailWithRefresh(t *testing.T) {
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agent-logs" || r.URL.Query().Get("agent") != "my-bot" || r.URL.Query().Get("lines") != "20" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer logs-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Pod-Name", "crew-gasboat-my-bot")
+		_, _ = w.Write([]byte("line one\nline two\n"))
+	}))
+	defer controller.Close()
+
+	slackSrv := newFakeSlackServer(t)
+	defer slackSrv.Close()
+	bot := newTestBot(newMockDaemon(), slackSrv)
+	bot.controllerURL = controller.URL
+	bot.agentLogsToken = "logs-token"
+
+	raw, _ := json.Marshal(bot.agentLogsBlocks(context.Background(), "my-bot", 20))
+	s := string(raw)
+	for _, want := range []string{"line one\\nline two", "crew-gasboat-my-bot", "agent_logs_refresh", "my-bot|20"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected blocks to contain %q, got %s", want, s)
+		}
+	}
+}
+
+func TestAgentLogsBlocks_TruncatesAndReportsErrors(t *testing.T) {
+	long := strings.Repeat("0123456789abcdef\n", 400)
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("agent") == "missing" {
+			http.Error(w, `no pod found for agent "missing"`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(long))
+	}))
+	defer controller.Close()
+
+	slackSrv := newFakeSlackServer(t)
+	defer slackSrv.Close()
+	bot := newTestBot(newMockDaemon(), slackSrv)
+	bot.controllerURL = controller.URL
+
+	blocks := bot.agentLogsBlocks(context.Background(), "my-bot", 0)
+	section := blocks[0].(*slack.SectionBlock).Text.Text
+	if len(section) > 3000 {
+		t.Errorf("expected section under Slack limit, got %d chars", len(section))
+	}
+	raw, _ := json.Marshal(blocks)
+	if !strings.Contains(string(raw), "truncated") {
+		t.Error("expected truncation note")
+	}
+
+	raw, _ = json.Marshal(bot.agentLogsBlocks(context.Background(), "missing", 0))
+	if !strings.Contains(string(raw), "no pod found") {
+		t.Errorf("expected not-found error, got %s", raw)
+	}
+}
@@ -239,6 +239,15 @@ func buildAgentCardBlocks(agent string, pendingCount int, agentState, taskTitle
 	case agentState == "failed":
 		indicator = ":x:"
 		status = "failed"
+	case agentState == "waiting_capacity":
+		indicator = ":hourglass:"
+		status = "waiting for cluster capacity"
+	case agentState == "preempted":
+		indicator = ":recycle:"
+		status = "preempted, rescheduling"
+	case agentState == "hibernating":
+		indicator = ":zzz:"
+		status = "hibernating (outside schedule)"
 	default:
 		indicator = ":white_circle:"
 		status = "idle"
@@ -261,7 +270,7 @@ func buildAgentCardBlocks(agent string, pendingCount int, agentState, taskTitle
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
 			slack.NewTextBlockObject("mrkdwn", headerText, false, false),
-			nil, nil),
+			nil, slack.NewAccessory(buildMuteOverflow(agent, project))),
 		slack.NewContextBlock("",
 			slack.NewTextBlockObject("mrkdwn", contextText, false, false)),
 	}
@@ -0,0 +1,60 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// PostArtifact uploads a bead's artifact as a file snippet. Decisions get the
+// upload in their own message thread; other beads go to the assignee's agent
+// card thread when agent threading is enabled, or the agent's channel otherwise.
+func (b *Bot) PostArtifact(ctx context.Context, bead BeadEvent, artifact Artifact) error {
+	title := beadTitle(bead.ID, bead.Title)
+
+	if ref, ok := b.lookupMessage(bead.ID); ok {
+		return b.uploadArtifact(ctx, ref.ChannelID, ref.Timestamp, title, artifact)
+	}
+
+	agent := bead.Assignee
+	channelID := b.resolveChannel(agent)
+	threadTS := ""
+	if b.agentThreadingEnabled() && agent != "" {
+		ts, err := b.ensureAgentCard(ctx, agent, channelID)
+		if err != nil {
+			b.logger.Warn("artifact: failed to ensure agent card, uploading to channel", "agent", agent, "error", err)
+		} else {
+			threadTS = ts
+		}
+	}
+	return b.uploadArtifact(ctx, channelID, threadTS, title, artifact)
+}
+
+// uploadArtifact uploads artifact content as a Slack file snippet, enforcing
+// artifactMaxBytes and picking the snippet type from the artifact type.
+func (b *Bot) uploadArtifact(ctx context.Context, channelID, threadTS, title string, artifact Artifact) error {
+	content, truncated := clampArtifact(artifact.Content, artifactMaxBytes)
+	comment := artifactSummary(artifact)
+	if truncated {
+		comment += fmt.Sprintf(" _(truncated to %d KB)_", artifactMaxBytes>>10)
+	}
+
+	_, err := b.api.UploadFileContext(ctx, slack.UploadFileParameters{
+		Channel:         channelID,
+		ThreadTimestamp: threadTS,
+		Content:         content,
+		FileSize:        len(content),
+		Filename:        artifact.filename(),
+		Title:           title,
+		SnippetType:     artifact.snippetType(),
+		InitialComment:  comment,
+	})
+	if err != nil {
+		return fmt.Errorf("upload artifact: %w", err)
+	}
+
+	b.logger.Info("uploaded artifact to Slack",
+		"title", title, "type", artifact.Type, "bytes", len(content), "channel", channelID, "thread", threadTS)
+	return nil
+}
@@ -19,8 +19,14 @@ func (b *Bot) handleSlashCommand(ctx context.Context, cmd slack.SlashCommand) {
 		b.handleSpawnCommand(ctx, cmd)
 	case "/kill":
 		b.handleKillCommand(ctx, cmd)
+	case "/agent":
+		b.handleAgentCommand(ctx, cmd)
 	case "/unreleased":
 		b.handleUnreleasedCommand(ctx, cmd)
+	case "/mute":
+		b.handleMuteCommand(ctx, cmd)
+	case "/unmute":
+		b.handleUnmuteCommand(ctx, cmd)
 	default:
 		b.logger.Debug("unhandled slash command", "command", cmd.Command)
 	}
@@ -91,6 +97,10 @@ func (b *Bot) handleSpawnCommand(ctx context.Context, cmd slack.SlashCommand) {
 		return
 	}
 
+	if b.state != nil {
+		_ = b.state.SetAgentSpawner(agentName, cmd.UserID)
+	}
+
 	b.logger.Info("spawned agent via Slack", "agent", agentName, "project", project, "task", taskID, "role", role, "bead", beadID, "user", cmd.UserID)
 
 	text := fmt.Sprintf(":rocket: Spawning agent *%s*", agentName)
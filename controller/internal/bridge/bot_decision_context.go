@@ -0,0 +1,144 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// decisionContextPreviewLines is how many lines of a diff or log a
+	// decision message shows; the rest is behind a "Show all" button.
+	decisionContextPreviewLines = 8
+
+	// decisionContextTextLen caps plain-text context in a decision message.
+	decisionContextTextLen = 600
+
+	// decisionContextChunk is the content length of one modal section,
+	// under Slack's 3000 character limit for section text once escaped.
+	decisionContextChunk = 2500
+
+	// decisionContextMaxChunks caps the sections of the full-context modal,
+	// under Slack's 100 block limit for views.
+	decisionContextMaxChunks = 40
+)
+
+// slackEscaper escapes the characters Slack treats as markup in mrkdwn.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// codeBlock renders s as a Slack code block. Backtick fences inside s
+// would end the block early, so they are broken up.
+func codeBlock(s string) string {
+	return "```" + slackEscaper.Replace(strings.ReplaceAll(s, "```", "`\u200b``")) + "```"
+}
+
+// decisionContextBlocks renders a decision's context attachments. Diffs and
+// logs show their first lines with a button opening the rest in a modal
+// (Block Kit has no collapsible sections); links are a context line.
+func decisionContextBlocks(beadID string, attachments []DecisionContext, locale string) []slack.Block {
+	var blocks []slack.Block
+	for i, c := range attachments {
+		switch {
+		case c.Kind == ContextLink:
+			link := slackEscaper.Replace(c.DisplayTitle())
+			if c.URL != "" {
+				link = fmt.Sprintf("<%s|%s>", c.URL, link)
+			}
+			blocks = append(blocks, slack.NewContextBlock("",
+				slack.NewTextBlockObject("mrkdwn", ":link: "+link, false, false)))
+
+		case c.Snippet():
+			preview, cut := c.Preview(decisionContextPreviewLines)
+			text := fmt.Sprintf("%s *%s*", decisionContextEmoji(c.Kind), slackEscaper.Replace(c.DisplayTitle()))
+			text += "\n" + codeBlock(truncateText(preview, decisionContextChunk))
+			var accessory *slack.Accessory
+			if cut {
+				more := strings.Count(strings.TrimRight(c.Content, "\n"), "\n") + 1 - decisionContextPreviewLines
+				text += "\n_" + Tr(locale, "decision.context_more", more) + "_"
+				accessory = slack.NewAccessory(slack.NewButtonBlockElement(
+					fmt.Sprintf("decision_context_%s_%d", beadID, i),
+					fmt.Sprintf("%s:%d", beadID, i),
+					slack.NewTextBlockObject("plain_text", Tr(locale, "decision.context_show"), false, false)))
+			}
+			blocks = append(blocks, slack.NewSectionBlock(
+				slack.NewTextBlockObject("mrkdwn", text, false, false), nil, accessory))
+
+		default:
+			text := slackEscaper.Replace(truncateText(c.Content, decisionContextTextLen))
+			if c.Title != "" {
+				text = fmt.Sprintf("*%s*\n%s", slackEscaper.Replace(c.Title), text)
+			}
+			blocks = append(blocks, slack.NewSectionBlock(
+				slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil))
+		}
+	}
+	return blocks
+}
+
+// decisionContextEmoji returns the emoji marking an attachment kind.
+func decisionContextEmoji(kind string) string {
+	if kind == ContextDiff {
+		return ":memo:"
+	}
+	return ":scroll:"
+}
+
+// openDecisionContextModal shows one context attachment of a decision in
+// full. value is "{beadID}:{index}".
+func (b *Bot) openDecisionContextModal(ctx context.Context, value string, callback slack.InteractionCallback) {
+	beadID, indexStr, ok := strings.Cut(value, ":")
+	index, err := strconv.Atoi(indexStr)
+	if !ok || err != nil {
+		b.logger.Warn("invalid decision context action", "value", value)
+		return
+	}
+	bead, err := b.daemon.GetBead(ctx, beadID)
+	if err != nil {
+		b.logger.Error("failed to get decision for context", "bead", beadID, "error", err)
+		return
+	}
+	attachments := ParseDecisionContext(bead.Fields["context"])
+	if index < 0 || index >= len(attachments) {
+		b.logger.Warn("decision context attachment not found", "bead", beadID, "index", index)
+		return
+	}
+	c := attachments[index]
+
+	locale := b.locales.LocaleFor(callback.Channel.ID)
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("%s *%s*", decisionContextEmoji(c.Kind), slackEscaper.Replace(c.DisplayTitle())), false, false),
+			nil, nil),
+	}
+	content := c.Content
+	for len(content) > 0 && len(blocks) <= decisionContextMaxChunks {
+		chunk := content
+		if len(chunk) > decisionContextChunk {
+			// Split at a line boundary where there is one.
+			chunk = chunk[:decisionContextChunk]
+			if nl := strings.LastIndexByte(chunk, '\n'); nl > 0 {
+				chunk = chunk[:nl+1]
+			}
+		}
+		content = content[len(chunk):]
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", codeBlock(strings.TrimRight(chunk, "\n")), false, false), nil, nil))
+	}
+	if len(content) > 0 {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject("mrkdwn", "_"+Tr(locale, "decision.context_more", strings.Count(content, "\n")+1)+"_", false, false)))
+	}
+
+	modal := slack.ModalViewRequest{
+		Type:   slack.VTModal,
+		Title:  slack.NewTextBlockObject("plain_text", Tr(locale, "modal.context_title"), false, false),
+		Close:  slack.NewTextBlockObject("plain_text", Tr(locale, "modal.close"), false, false),
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+	if _, err := b.api.OpenViewContext(ctx, callback.TriggerID, modal); err != nil {
+		b.logger.Error("failed to open decision context modal", "bead", beadID, "error", err)
+	}
+}
@@ -2,7 +2,6 @@ package bridge
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -37,32 +36,21 @@ func decisionQuestion(fields map[string]string) string {
 // Layout matches the beads implementation: each option is a Section block
 // with numbered label, description, and right-aligned accessory button.
 func (b *Bot) NotifyDecision(ctx context.Context, bead BeadEvent) error {
-	question := decisionQuestion(bead.Fields)
 	optionsRaw := bead.Fields["options"]
 	agent := bead.Assignee
 
-	// Parse options — try JSON array of objects first, then strings.
-	type optionObj struct {
-		ID           string `json:"id"`
-		Short        string `json:"short"`
-		Label        string `json:"label"`
-		Description  string `json:"description"`
-		ArtifactType string `json:"artifact_type,omitempty"`
-	}
-	var optObjs []optionObj
-	var optStrings []string
+	opts := ParseDecisionOptions(optionsRaw)
 
-	if err := json.Unmarshal([]byte(optionsRaw), &optObjs); err != nil || len(optObjs) == 0 {
-		if err := json.Unmarshal([]byte(optionsRaw), &optStrings); err != nil {
-			optStrings = []string{optionsRaw}
-		}
-	}
+	// Resolve target channel for this agent; its locale selects message strings.
+	targetChannel := b.resolveChannel(agent)
+	locale := b.locales.LocaleFor(targetChannel)
+	view := notificationView(bead)
+	view.Locale = locale
 
 	// Build Block Kit blocks — header section with priority-colored indicator.
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
-			slack.NewTextBlockObject("mrkdwn",
-				fmt.Sprintf("%s *Decision Needed*\n%s", decisionPriorityEmoji(bead.Priority), question), false, false),
+			slack.NewTextBlockObject("mrkdwn", b.templates.Render(tmplDecision, view), false, false),
 			nil, nil,
 		),
 	}
@@ -84,6 +72,13 @@ func (b *Bot) NotifyDecision(ctx context.Context, bead BeadEvent) error {
 		))
 	}
 
+	// Deadline countdown, rendered by Slack in each reader's timezone.
+	if due, ok := decisionDueAt(bead); ok {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject("mrkdwn", ":alarm_clock: "+Tr(locale, "decision.due", slackDate(due)), false, false),
+		))
+	}
+
 	// Context block — skip entirely in threaded mode since the parent card shows it.
 	if b.agentThreadingEnabled() && agent != "" {
 		// No context block needed — the thread parent card provides agent context.
@@ -99,96 +94,66 @@ func (b *Bot) NotifyDecision(ctx context.Context, bead BeadEvent) error {
 		))
 	}
 
+	// Context attachments: text, links, and previews of diffs and logs.
+	blocks = append(blocks, decisionContextBlocks(bead.ID, ParseDecisionContext(bead.Fields["context"]), locale)...)
+
 	// Option blocks — each option is a Section with accessory button.
-	if len(optObjs) > 0 || len(optStrings) > 0 {
-		blocks = append(blocks, slack.NewDividerBlock())
-
-		if len(optObjs) > 0 {
-			for i, opt := range optObjs {
-				label := opt.Label
-				if label == "" {
-					label = opt.Short
-				}
-				if label == "" {
-					label = opt.ID
-				}
-
-				optText := fmt.Sprintf("*%d. %s*", i+1, label)
-				if opt.Description != "" {
-					desc := opt.Description
-					if len(desc) > 150 {
-						desc = desc[:147] + "..."
-					}
-					optText += fmt.Sprintf("\n%s", desc)
-				}
-				if opt.ArtifactType != "" {
-					optText += fmt.Sprintf("\n_Requires: %s_", opt.ArtifactType)
-				}
-
-				buttonLabel := "Choose"
-				if len(optObjs) <= 4 {
-					buttonLabel = fmt.Sprintf("Choose %d", i+1)
-				}
-
-				blocks = append(blocks,
-					slack.NewSectionBlock(
-						slack.NewTextBlockObject("mrkdwn", optText, false, false),
-						nil,
-						slack.NewAccessory(
-							slack.NewButtonBlockElement(
-								fmt.Sprintf("resolve_%s_%d", bead.ID, i+1),
-								fmt.Sprintf("%s:%d", bead.ID, i+1),
-								slack.NewTextBlockObject("plain_text", buttonLabel, false, false)))))
-			}
-		} else {
-			for i, opt := range optStrings {
-				optText := fmt.Sprintf("*%d. %s*", i+1, opt)
-
-				buttonLabel := "Choose"
-				if len(optStrings) <= 4 {
-					buttonLabel = fmt.Sprintf("Choose %d", i+1)
-				}
-
-				blocks = append(blocks,
-					slack.NewSectionBlock(
-						slack.NewTextBlockObject("mrkdwn", optText, false, false),
-						nil,
-						slack.NewAccessory(
-							slack.NewButtonBlockElement(
-								fmt.Sprintf("resolve_%s_%d", bead.ID, i+1),
-								fmt.Sprintf("%s:%d", bead.ID, i+1),
-								slack.NewTextBlockObject("plain_text", buttonLabel, false, false)))))
+	// "Other" is always offered, so decisions without options can still be
+	// answered with a custom response.
+	blocks = append(blocks, slack.NewDividerBlock())
+	for i, opt := range opts {
+		optText := fmt.Sprintf("*%d. %s*", i+1, opt.DisplayLabel())
+		if opt.Description != "" {
+			desc := opt.Description
+			if len(desc) > 150 {
+				desc = desc[:147] + "..."
 			}
+			optText += fmt.Sprintf("\n%s", desc)
+		}
+		if opt.ArtifactType != "" {
+			optText += fmt.Sprintf("\n_%s_", Tr(locale, "decision.requires", opt.ArtifactType))
+		}
+
+		buttonLabel := Tr(locale, "decision.choose")
+		if len(opts) <= 4 {
+			buttonLabel = Tr(locale, "decision.choose_n", i+1)
 		}
 
-		// "Other" option — own section with accessory button.
 		blocks = append(blocks,
 			slack.NewSectionBlock(
-				slack.NewTextBlockObject("mrkdwn",
-					"*Other*\n_None of the above? Provide a custom response and choose the required artifact type._", false, false),
+				slack.NewTextBlockObject("mrkdwn", optText, false, false),
 				nil,
 				slack.NewAccessory(
 					slack.NewButtonBlockElement(
-						fmt.Sprintf("resolve_other_%s", bead.ID),
-						bead.ID,
-						slack.NewTextBlockObject("plain_text", "Other...", false, false)))))
+						fmt.Sprintf("resolve_%s_%d", bead.ID, i+1),
+						fmt.Sprintf("%s:%d", bead.ID, i+1),
+						slack.NewTextBlockObject("plain_text", buttonLabel, false, false)))))
 	}
 
+	// "Other" option — own section with accessory button.
+	blocks = append(blocks,
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn",
+				fmt.Sprintf("*%s*\n_%s_", Tr(locale, "decision.other"), Tr(locale, "decision.other_hint")), false, false),
+			nil,
+			slack.NewAccessory(
+				slack.NewButtonBlockElement(
+					fmt.Sprintf("resolve_other_%s", bead.ID),
+					bead.ID,
+					slack.NewTextBlockObject("plain_text", Tr(locale, "decision.other_button"), false, false)))))
+
 	// Action buttons: Dismiss at the bottom.
 	dismissBtn := slack.NewButtonBlockElement("dismiss_decision", bead.ID,
-		slack.NewTextBlockObject("plain_text", "Dismiss", false, false))
+		slack.NewTextBlockObject("plain_text", Tr(locale, "decision.dismiss"), false, false))
 	blocks = append(blocks,
 		slack.NewActionBlock("", dismissBtn))
 
 	// Build message options.
 	msgOpts := []slack.MsgOption{
-		slack.MsgOptionText(fmt.Sprintf("Decision needed: %s", question), false),
+		slack.MsgOptionText(b.templates.Render(tmplDecision+".fallback", view), false),
 		slack.MsgOptionBlocks(blocks...),
 	}
 
-	// Resolve target channel for this agent.
-	targetChannel := b.resolveChannel(agent)
-
 	// Thread under agent card or predecessor decision.
 	var threadTS string
 	var threadSource string
@@ -269,10 +234,12 @@ func (b *Bot) UpdateDecision(ctx context.Context, beadID, chosen string) error {
 
 // NotifyEscalation posts a highlighted notification for an escalated decision.
 func (b *Bot) NotifyEscalation(ctx context.Context, bead BeadEvent) error {
-	question := decisionQuestion(bead.Fields)
 	agent := bead.Assignee
+	targetChannel := b.resolveChannel(agent)
+	view := notificationView(bead)
+	view.Locale = b.locales.LocaleFor(targetChannel)
 
-	text := fmt.Sprintf(":rotating_light: *ESCALATED: %s*\n%s", beadTitle(bead.ID, bead.Title), question)
+	text := b.templates.Render(tmplEscalation, view)
 
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
@@ -291,10 +258,8 @@ func (b *Bot) NotifyEscalation(ctx context.Context, bead BeadEvent) error {
 	blocks = append(blocks, slack.NewContextBlock("",
 		slack.NewTextBlockObject("mrkdwn", strings.Join(contextParts, " | "), false, false)))
 
-	targetChannel := b.resolveChannel(agent)
-
 	msgOpts := []slack.MsgOption{
-		slack.MsgOptionText(fmt.Sprintf("ESCALATED: %s — %s", beadTitle(bead.ID, bead.Title), question), false),
+		slack.MsgOptionText(b.templates.Render(tmplEscalation+".fallback", view), false),
 		slack.MsgOptionBlocks(blocks...),
 	}
 
@@ -370,9 +335,41 @@ func reportEmoji(reportType string) string {
 	}
 }
 
+// NotifyDecisionDue posts a deadline reminder in the thread of a decision's
+// message. Decisions without a message are skipped.
+func (b *Bot) NotifyDecisionDue(ctx context.Context, beadID string, due time.Time) error {
+	ref, ok := b.lookupMessage(beadID)
+	if !ok {
+		return nil
+	}
+	// A decision posted inside an agent thread is reminded in that thread.
+	threadTS := ref.Timestamp
+	if b.agentThreadingEnabled() && ref.Agent != "" {
+		b.mu.Lock()
+		if card, ok := b.agentCards[ref.Agent]; ok && card.ChannelID == ref.ChannelID {
+			threadTS = card.Timestamp
+		}
+		b.mu.Unlock()
+	}
+
+	text := ":hourglass_flowing_sand: " + Tr(b.locales.LocaleFor(ref.ChannelID), "decision.due_soon", slackDate(due))
+	if link, err := b.api.GetPermalinkContext(ctx, &slack.PermalinkParameters{Channel: ref.ChannelID, Ts: ref.Timestamp}); err == nil {
+		text += " <" + link + "|" + beadID + ">"
+	}
+	_, _, err := b.api.PostMessageContext(ctx, ref.ChannelID,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionTS(threadTS),
+	)
+	if err != nil {
+		return fmt.Errorf("post decision deadline reminder: %w", err)
+	}
+	return nil
+}
+
 // PostReport inlines the report into the resolved decision message.
-// Slack's Block Kit automatically renders a "Show more" link for long content,
-// so we send the full report as blocks and let the platform handle truncation.
+// Slack's Block Kit automatically renders a "Show more" link for long content.
+// Reports too large for a section block are uploaded as a file snippet in the
+// decision thread instead, with a pointer left in the message.
 func (b *Bot) PostReport(ctx context.Context, decisionID, reportType, content string) error {
 	ref, ok := b.lookupMessage(decisionID)
 	if !ok {
@@ -434,12 +431,29 @@ func (b *Bot) PostReport(ctx context.Context, decisionID, reportType, content st
 		slack.NewContextBlock("",
 			slack.NewTextBlockObject("mrkdwn",
 				fmt.Sprintf("Decision _%s_ · %s", decisionTitle, reportType), false, false)))
-	// Wrap content in a code block so Slack collapses it with "Show more".
-	blocks = append(blocks,
-		slack.NewSectionBlock(
-			slack.NewTextBlockObject("mrkdwn",
-				fmt.Sprintf("```\n%s\n```", content), false, false),
-			nil, nil))
+	if len(content) > artifactInlineLimit {
+		// Too large for a section block — upload as a snippet in the decision
+		// thread and leave a pointer in the message.
+		artifact := Artifact{Type: reportType, Content: content}
+		if err := b.uploadArtifact(ctx, ref.ChannelID, ref.Timestamp, "Report: "+decisionTitle, artifact); err != nil {
+			b.logger.Error("failed to upload report snippet", "decision", decisionID, "error", err)
+			content = truncateText(content, artifactInlineLimit)
+		} else {
+			blocks = append(blocks,
+				slack.NewContextBlock("",
+					slack.NewTextBlockObject("mrkdwn",
+						fmt.Sprintf(":paperclip: Full report attached in thread (%d KB)", (len(content)+1023)/1024), false, false)))
+			content = ""
+		}
+	}
+	if content != "" {
+		// Wrap content in a code block so Slack collapses it with "Show more".
+		blocks = append(blocks,
+			slack.NewSectionBlock(
+				slack.NewTextBlockObject("mrkdwn",
+					fmt.Sprintf("```\n%s\n```", content), false, false),
+				nil, nil))
+	}
 
 	_, _, _, updateErr := b.api.UpdateMessageContext(ctx, ref.ChannelID, ref.Timestamp,
 		slack.MsgOptionBlocks(blocks...),
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"strings"
+	"time"
 
 	"github.com/slack-go/slack"
 )
@@ -20,11 +22,31 @@ func (b *Bot) handleBlockActions(ctx context.Context, callback slack.Interaction
 			b.handleClearAgent(ctx, action.Value, callback)
 			return
 
+		// Home tab "Spawn agent" button: opens the spawn modal.
+		case actionID == "home_spawn_agent":
+			b.openSpawnModal(ctx, callback)
+			return
+
+		// Agent card overflow menu: value = "scope|target|duration".
+		case actionID == "agent_mute":
+			b.handleMuteOverflow(ctx, action.SelectedOption.Value, callback)
+			return
+
+		// /agent logs "Refresh" button: value = "agentName|lines".
+		case actionID == "agent_logs_refresh":
+			b.handleAgentLogsRefresh(ctx, action.Value, callback)
+			return
+
 		// Dismiss button: action_id = "dismiss_decision", value = beadID.
 		case actionID == "dismiss_decision":
 			b.handleDismiss(ctx, action.Value, callback)
 			return
 
+		// Context "Show all" button: action_id = "decision_context_{beadID}_{n}", value = "{beadID}:{n}".
+		case strings.HasPrefix(actionID, "decision_context_"):
+			b.openDecisionContextModal(ctx, action.Value, callback)
+			return
+
 		// "Other..." button: action_id = "resolve_other_{beadID}", value = beadID.
 		case strings.HasPrefix(actionID, "resolve_other_"):
 			beadID := strings.TrimPrefix(actionID, "resolve_other_")
@@ -113,9 +135,10 @@ func (b *Bot) lookupArtifactType(ctx context.Context, beadID, chosenLabel string
 // openResolveModal opens a modal for confirming a decision choice with optional rationale.
 func (b *Bot) openResolveModal(ctx context.Context, beadID, chosen string, callback slack.InteractionCallback) {
 	// Build and open the modal immediately (trigger_id expires in 3s).
-	titleText := slack.NewTextBlockObject("plain_text", "Resolve Decision", false, false)
-	submitText := slack.NewTextBlockObject("plain_text", "Confirm", false, false)
-	closeText := slack.NewTextBlockObject("plain_text", "Cancel", false, false)
+	locale := b.locales.LocaleFor(callback.Channel.ID)
+	titleText := slack.NewTextBlockObject("plain_text", Tr(locale, "modal.resolve_title"), false, false)
+	submitText := slack.NewTextBlockObject("plain_text", Tr(locale, "modal.confirm"), false, false)
+	closeText := slack.NewTextBlockObject("plain_text", Tr(locale, "modal.cancel"), false, false)
 
 	// Fetch the decision question for display.
 	question := beadID // fallback
@@ -134,15 +157,15 @@ func (b *Bot) openResolveModal(ctx context.Context, beadID, chosen string, callb
 			),
 			slack.NewDividerBlock(),
 			slack.NewSectionBlock(
-				slack.NewTextBlockObject("mrkdwn", fmt.Sprintf(":white_check_mark: Selected: *%s*", chosen), false, false),
+				slack.NewTextBlockObject("mrkdwn", ":white_check_mark: "+Tr(locale, "modal.selected", chosen), false, false),
 				nil, nil,
 			),
 			slack.NewInputBlock(
 				"rationale",
-				slack.NewTextBlockObject("plain_text", "Rationale (optional)", false, false),
+				slack.NewTextBlockObject("plain_text", Tr(locale, "modal.rationale"), false, false),
 				nil,
 				slack.NewPlainTextInputBlockElement(
-					slack.NewTextBlockObject("plain_text", "Why this choice?", false, false),
+					slack.NewTextBlockObject("plain_text", Tr(locale, "modal.rationale_placeholder"), false, false),
 					"rationale_input",
 				),
 			),
@@ -173,9 +196,10 @@ func (b *Bot) openResolveModal(ctx context.Context, beadID, chosen string, callb
 
 // openOtherModal opens a modal for custom freeform text response.
 func (b *Bot) openOtherModal(ctx context.Context, beadID string, callback slack.InteractionCallback) {
-	titleText := slack.NewTextBlockObject("plain_text", "Custom Response", false, false)
-	submitText := slack.NewTextBlockObject("plain_text", "Submit", false, false)
-	closeText := slack.NewTextBlockObject("plain_text", "Cancel", false, false)
+	locale := b.locales.LocaleFor(callback.Channel.ID)
+	titleText := slack.NewTextBlockObject("plain_text", Tr(locale, "modal.other_title"), false, false)
+	submitText := slack.NewTextBlockObject("plain_text", Tr(locale, "modal.submit"), false, false)
+	closeText := slack.NewTextBlockObject("plain_text", Tr(locale, "modal.cancel"), false, false)
 
 	// Fetch the decision question for display.
 	question := beadID
@@ -189,7 +213,7 @@ func (b *Bot) openOtherModal(ctx context.Context, beadID string, callback slack.
 	// Build artifact_type select options: "none" plus all valid types.
 	artifactTypeOpts := []*slack.OptionBlockObject{
 		slack.NewOptionBlockObject("none",
-			slack.NewTextBlockObject("plain_text", "None (no artifact required)", false, false), nil),
+			slack.NewTextBlockObject("plain_text", Tr(locale, "modal.artifact_none"), false, false), nil),
 	}
 	for _, at := range []string{"report", "plan", "checklist", "diff-summary", "epic", "bug"} {
 		artifactTypeOpts = append(artifactTypeOpts,
@@ -206,22 +230,22 @@ func (b *Bot) openOtherModal(ctx context.Context, beadID string, callback slack.
 			slack.NewDividerBlock(),
 			slack.NewInputBlock(
 				"response",
-				slack.NewTextBlockObject("plain_text", "Your Response", false, false),
+				slack.NewTextBlockObject("plain_text", Tr(locale, "modal.response"), false, false),
 				nil,
 				&slack.PlainTextInputBlockElement{
 					Type:        slack.METPlainTextInput,
 					ActionID:    "response_input",
 					Multiline:   true,
-					Placeholder: slack.NewTextBlockObject("plain_text", "Type your response...", false, false),
+					Placeholder: slack.NewTextBlockObject("plain_text", Tr(locale, "modal.response_placeholder"), false, false),
 				},
 			),
 			slack.NewInputBlock(
 				"artifact_type",
-				slack.NewTextBlockObject("plain_text", "Required Artifact Type", false, false),
-				slack.NewTextBlockObject("plain_text", "What artifact will you produce?", false, false),
+				slack.NewTextBlockObject("plain_text", Tr(locale, "modal.artifact_type"), false, false),
+				slack.NewTextBlockObject("plain_text", Tr(locale, "modal.artifact_type_hint"), false, false),
 				slack.NewOptionsSelectBlockElement(
 					slack.OptTypeStatic,
-					slack.NewTextBlockObject("plain_text", "Choose artifact type...", false, false),
+					slack.NewTextBlockObject("plain_text", Tr(locale, "modal.artifact_type_choose"), false, false),
 					"artifact_type_input",
 					artifactTypeOpts...,
 				),
@@ -253,6 +277,7 @@ func (b *Bot) handleDismiss(ctx context.Context, beadID string, callback slack.I
 		"chosen":    "dismissed",
 		"rationale": fmt.Sprintf("Dismissed by @%s via Slack", callback.User.Name),
 	}
+	maps.Copy(fields, b.slackResponder(ctx, callback.User.ID, callback.User.Name).AuditFields(time.Now()))
 	if err := b.daemon.CloseBead(ctx, beadID, fields); err != nil {
 		b.logger.Error("failed to dismiss decision", "bead", beadID, "error", err)
 		return
@@ -264,6 +289,17 @@ func (b *Bot) handleDismiss(ctx context.Context, beadID string, callback slack.I
 	b.logger.Info("decision dismissed", "bead", beadID, "user", callback.User.Name)
 }
 
+// validateViewSubmission checks a modal submission before it is
+// acknowledged. It returns a response_action "errors" response when the
+// input is invalid, or nil to accept it.
+func validateViewSubmission(callback slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	switch callback.View.CallbackID {
+	case "spawn_agent":
+		return validateSpawnSubmission(callback)
+	}
+	return nil
+}
+
 // handleViewSubmission processes modal form submissions.
 func (b *Bot) handleViewSubmission(ctx context.Context, callback slack.InteractionCallback) {
 	switch callback.View.CallbackID {
@@ -271,6 +307,10 @@ func (b *Bot) handleViewSubmission(ctx context.Context, callback slack.Interacti
 		b.handleResolveSubmission(ctx, callback)
 	case "resolve_other":
 		b.handleOtherSubmission(ctx, callback)
+	case "spawn_agent":
+		b.handleSpawnSubmission(ctx, callback)
+	case "agent_spawn_confirm":
+		b.handleAgentSpawnConfirm(ctx, callback)
 	}
 }
 
@@ -310,6 +350,7 @@ func (b *Bot) handleResolveSubmission(ctx context.Context, callback slack.Intera
 		"chosen":    chosen,
 		"rationale": rationale,
 	}
+	maps.Copy(fields, b.slackResponder(ctx, callback.User.ID, user).AuditFields(time.Now()))
 
 	// Look up artifact_type from the chosen option.
 	if at := b.lookupArtifactType(ctx, beadID, chosen); at != "" {
@@ -358,6 +399,7 @@ func (b *Bot) handleOtherSubmission(ctx context.Context, callback slack.Interact
 		"chosen":    response,
 		"rationale": rationale,
 	}
+	maps.Copy(fields, b.slackResponder(ctx, callback.User.ID, user).AuditFields(time.Now()))
 
 	// Extract artifact_type from the dropdown; set required_artifact if not "none".
 	if v, ok := callback.View.State.Values["artifact_type"]["artifact_type_input"]; ok {
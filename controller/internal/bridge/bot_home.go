@@ -0,0 +1,300 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+)
+
+// homeMaxDecisions caps the number of decisions rendered on the Home tab.
+// Each decision costs a permalink lookup, so keep this small.
+const homeMaxDecisions = 10
+
+// homeMaxAgents caps the number of agents rendered on the Home tab.
+const homeMaxAgents = 20
+
+// handleAppHomeOpened publishes the user's personal Home tab view.
+func (b *Bot) handleAppHomeOpened(ctx context.Context, ev *slackevents.AppHomeOpenedEvent) {
+	if ev.Tab != "home" {
+		return
+	}
+	if err := b.publishHome(ctx, ev.User); err != nil {
+		b.logger.Error("failed to publish home tab", "user", ev.User, "error", err)
+	}
+}
+
+// publishHome builds and publishes the Home tab for a Slack user. The view
+// shows agents the user spawned via Slack and the pending decisions raised
+// by those agents.
+func (b *Bot) publishHome(ctx context.Context, userID string) error {
+	var spawned []string
+	if b.state != nil {
+		spawned = b.state.AgentsSpawnedBy(userID)
+	}
+	mine := make(map[string]bool, len(spawned))
+	for _, name := range spawned {
+		mine[name] = true
+	}
+
+	var myAgents []beadsapi.AgentBead
+	if len(mine) > 0 {
+		agents, err := b.daemon.ListAgentBeads(ctx)
+		if err != nil {
+			return fmt.Errorf("list agents: %w", err)
+		}
+		for _, a := range agents {
+			if mine[a.AgentName] {
+				myAgents = append(myAgents, a)
+			}
+		}
+	}
+
+	var myDecisions []*beadsapi.BeadDetail
+	if len(mine) > 0 {
+		decisions, err := b.daemon.ListDecisionBeads(ctx)
+		if err != nil {
+			return fmt.Errorf("list decisions: %w", err)
+		}
+		for _, d := range decisions {
+			if d.Fields["chosen"] == "" && mine[extractAgentName(d.Assignee)] {
+				myDecisions = append(myDecisions, d)
+			}
+		}
+	}
+
+	// Resolve permalinks so "Open" buttons jump straight to the decision message.
+	links := make(map[string]string)
+	for i, d := range myDecisions {
+		if i >= homeMaxDecisions {
+			break
+		}
+		ref, ok := b.lookupMessage(d.ID)
+		if !ok {
+			continue
+		}
+		link, err := b.api.GetPermalinkContext(ctx, &slack.PermalinkParameters{
+			Channel: ref.ChannelID,
+			Ts:      ref.Timestamp,
+		})
+		if err == nil {
+			links[d.ID] = link
+		}
+	}
+
+	view := buildHomeView(myAgents, myDecisions, links)
+	if _, err := b.api.PublishViewContext(ctx, slack.PublishViewContextRequest{
+		UserID: userID,
+		View:   view,
+	}); err != nil {
+		return fmt.Errorf("publish view: %w", err)
+	}
+	return nil
+}
+
+// buildHomeView renders the Home tab for the given agents and decisions.
+// links maps decision bead IDs to Slack message permalinks.
+func buildHomeView(agents []beadsapi.AgentBead, decisions []*beadsapi.BeadDetail, links map[string]string) slack.HomeTabViewRequest {
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", ":house: *Gasboat*", false, false),
+			nil, nil),
+		slack.NewActionBlock("home_actions",
+			slack.NewButtonBlockElement("home_spawn_agent", "spawn",
+				slack.NewTextBlockObject("plain_text", "Spawn agent", false, false)).
+				WithStyle(slack.StylePrimary)),
+		slack.NewDividerBlock(),
+	}
+
+	// Decisions awaiting the user.
+	sort.Slice(decisions, func(i, j int) bool {
+		if decisions[i].Priority != decisions[j].Priority {
+			return decisions[i].Priority < decisions[j].Priority
+		}
+		return decisions[i].ID < decisions[j].ID
+	})
+	blocks = append(blocks, slack.NewSectionBlock(
+		slack.NewTextBlockObject("mrkdwn",
+			fmt.Sprintf(":clipboard: *Decisions awaiting you (%d)*", len(decisions)), false, false),
+		nil, nil))
+	if len(decisions) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject("mrkdwn", "_Nothing waiting on you._", false, false)))
+	}
+	for i, d := range decisions {
+		if i >= homeMaxDecisions {
+			blocks = append(blocks, slack.NewContextBlock("",
+				slack.NewTextBlockObject("mrkdwn",
+					fmt.Sprintf("_...and %d more_", len(decisions)-homeMaxDecisions), false, false)))
+			break
+		}
+		question := decisionQuestion(d.Fields)
+		if question == "" {
+			question = d.Title
+		}
+		line := fmt.Sprintf("%s %s\n_%s_", decisionPriorityEmoji(d.Priority),
+			truncateText(question, 150), extractAgentName(d.Assignee))
+
+		var accessory *slack.Accessory
+		if link := links[d.ID]; link != "" {
+			btn := slack.NewButtonBlockElement("home_open_decision_"+d.ID, d.ID,
+				slack.NewTextBlockObject("plain_text", "Open", false, false))
+			btn.URL = link
+			accessory = slack.NewAccessory(btn)
+		}
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", line, false, false), nil, accessory))
+	}
+
+	// Agents the user spawned.
+	sort.Slice(agents, func(i, j int) bool { return agents[i].AgentName < agents[j].AgentName })
+	blocks = append(blocks, slack.NewDividerBlock())
+	blocks = append(blocks, slack.NewSectionBlock(
+		slack.NewTextBlockObject("mrkdwn",
+			fmt.Sprintf(":busts_in_silhouette: *Your agents (%d)*", len(agents)), false, false),
+		nil, nil))
+	if len(agents) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject("mrkdwn", "_You haven't spawned any agents. Use *Spawn agent* or `/spawn`._", false, false)))
+	}
+	var lines []string
+	for i, a := range agents {
+		if i >= homeMaxAgents {
+			lines = append(lines, fmt.Sprintf("_...and %d more_", len(agents)-homeMaxAgents))
+			break
+		}
+		state := a.AgentState
+		if state == "" {
+			state = "idle"
+		}
+		line := fmt.Sprintf("• *%s*", a.AgentName)
+		if a.Project != "" {
+			line += fmt.Sprintf(" · _%s_", a.Project)
+		}
+		line += fmt.Sprintf(" · %s", state)
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", strings.Join(lines, "\n"), false, false), nil, nil))
+	}
+
+	return slack.HomeTabViewRequest{
+		Type:   slack.VTHomeTab,
+		Blocks: slack.Blocks{BlockSet: blocks},
+	}
+}
+
+// openSpawnModal opens the spawn-agent modal from the Home tab quick action.
+func (b *Bot) openSpawnModal(ctx context.Context, callback slack.InteractionCallback) {
+	nameInput := slack.NewInputBlock(
+		"agent_name",
+		slack.NewTextBlockObject("plain_text", "Agent name", false, false),
+		slack.NewTextBlockObject("plain_text", "Lowercase letters, digits, and hyphens", false, false),
+		slack.NewPlainTextInputBlockElement(
+			slack.NewTextBlockObject("plain_text", "my-bot", false, false),
+			"agent_name_input"),
+	)
+	roleInput := slack.NewInputBlock(
+		"role",
+		slack.NewTextBlockObject("plain_text", "Role", false, false),
+		nil,
+		slack.NewPlainTextInputBlockElement(
+			slack.NewTextBlockObject("plain_text", "crew", false, false),
+			"role_input"),
+	)
+	roleInput.Optional = true
+
+	blocks := []slack.Block{nameInput}
+
+	projects, err := b.daemon.ListProjectBeads(ctx)
+	if err != nil {
+		b.logger.Error("failed to list projects for spawn modal", "error", err)
+	}
+	if len(projects) > 0 {
+		names := make([]string, 0, len(projects))
+		for name := range projects {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		opts := make([]*slack.OptionBlockObject, 0, len(names))
+		for _, name := range names {
+			opts = append(opts, slack.NewOptionBlockObject(name,
+				slack.NewTextBlockObject("plain_text", name, false, false), nil))
+		}
+		projectInput := slack.NewInputBlock(
+			"project",
+			slack.NewTextBlockObject("plain_text", "Project", false, false),
+			nil,
+			slack.NewOptionsSelectBlockElement(slack.OptTypeStatic,
+				slack.NewTextBlockObject("plain_text", "Choose project...", false, false),
+				"project_input", opts...),
+		)
+		projectInput.Optional = true
+		blocks = append(blocks, projectInput)
+	}
+	blocks = append(blocks, roleInput)
+
+	modal := slack.ModalViewRequest{
+		Type:       slack.VTModal,
+		Title:      slack.NewTextBlockObject("plain_text", "Spawn Agent", false, false),
+		Submit:     slack.NewTextBlockObject("plain_text", "Spawn", false, false),
+		Close:      slack.NewTextBlockObject("plain_text", "Cancel", false, false),
+		Blocks:     slack.Blocks{BlockSet: blocks},
+		CallbackID: "spawn_agent",
+	}
+	if _, err := b.api.OpenViewContext(ctx, callback.TriggerID, modal); err != nil {
+		b.logger.Error("failed to open spawn modal", "error", err)
+	}
+}
+
+// validateSpawnSubmission rejects a spawn-agent modal submission with an
+// invalid agent name, keeping the modal open with the error on the field.
+func validateSpawnSubmission(callback slack.InteractionCallback) *slack.ViewSubmissionResponse {
+	agentName := strings.TrimSpace(callback.View.State.Values["agent_name"]["agent_name_input"].Value)
+	if isValidAgentName(agentName) {
+		return nil
+	}
+	return slack.NewErrorsViewSubmissionResponse(map[string]string{
+		"agent_name": "Use lowercase letters, digits, and hyphens only",
+	})
+}
+
+// handleSpawnSubmission processes the spawn-agent modal submission. The
+// name was checked by validateSpawnSubmission; spawn failures are reported
+// to the user ephemerally in the bot's channel, as the modal is closed by
+// then.
+func (b *Bot) handleSpawnSubmission(ctx context.Context, callback slack.InteractionCallback) {
+	values := callback.View.State.Values
+	agentName := strings.TrimSpace(values["agent_name"]["agent_name_input"].Value)
+	project := values["project"]["project_input"].SelectedOption.Value
+	role := strings.TrimSpace(values["role"]["role_input"].Value)
+
+	if !isValidAgentName(agentName) {
+		b.logger.Warn("spawn modal: invalid agent name", "agent", agentName, "user", callback.User.ID)
+		return
+	}
+
+	beadID, err := b.daemon.SpawnAgent(ctx, agentName, project, "", role)
+	if err != nil {
+		b.logger.Error("spawn modal: failed to spawn agent", "agent", agentName, "project", project, "error", err)
+		if b.channel != "" {
+			_, _ = b.api.PostEphemeral(b.channel, callback.User.ID,
+				slack.MsgOptionText(fmt.Sprintf(":x: Failed to spawn agent %q: %s", agentName, err.Error()), false))
+		}
+		return
+	}
+	if b.state != nil {
+		_ = b.state.SetAgentSpawner(agentName, callback.User.ID)
+	}
+	b.logger.Info("spawned agent via Home tab", "agent", agentName, "project", project, "role", role, "bead", beadID, "user", callback.User.ID)
+
+	if err := b.publishHome(ctx, callback.User.ID); err != nil {
+		b.logger.Error("failed to refresh home tab after spawn", "user", callback.User.ID, "error", err)
+	}
+}
@@ -0,0 +1,202 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/slack-go/slack"
+)
+
+func TestBuildHomeView_EmptyState(t *testing.T) {
+	view := buildHomeView(nil, nil, nil)
+	if view.Type != slack.VTHomeTab {
+		t.Fatalf("expected home view type, got %q", view.Type)
+	}
+	raw, _ := json.Marshal(view)
+	s := string(raw)
+	for _, want := range []string{"home_spawn_agent", "Decisions awaiting you (0)", "Your agents (0)", "Nothing waiting on you"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected view to contain %q", want)
+		}
+	}
+}
+
+func TestBuildHomeView_DecisionsAndAgents(t *testing.T) {
+	agents := []beadsapi.AgentBead{
+		{AgentName: "zeta", Project: "gasboat", AgentState: "working"},
+		{AgentName: "alpha", Project: "beads"},
+	}
+	decisions := []*beadsapi.BeadDetail{
+		{ID: "dec-2", Priority: 2, Assignee: "gasboat/crew/zeta", Fields: map[string]string{"prompt": "Low prio?"}},
+		{ID: "dec-1", Priority: 0, Assignee: "zeta", Fields: map[string]string{"prompt": "Ship it?"}},
+	}
+	links := map[string]string{"dec-1": "https://slack.example/archives/C1/p1"}
+
+	view := buildHomeView(agents, decisions, links)
+	raw, _ := json.Marshal(view)
+	s := string(raw)
+
+	if !strings.Contains(s, "Decisions awaiting you (2)") {
+		t.Error("expected decision count of 2")
+	}
+	if !strings.Contains(s, "https://slack.example/archives/C1/p1") {
+		t.Error("expected Open button with permalink for dec-1")
+	}
+	if strings.Contains(s, "home_open_decision_dec-2") {
+		t.Error("expected no Open button for dec-2 (no permalink)")
+	}
+	// Higher priority (lower number) decisions render first.
+	if strings.Index(s, "Ship it?") > strings.Index(s, "Low prio?") {
+		t.Error("expected P0 decision before P2 decision")
+	}
+	// Agents sorted by name; missing state shown as idle.
+	agentsSection := s[strings.Index(s, "Your agents"):]
+	if strings.Index(agentsSection, "alpha") > strings.Index(agentsSection, "zeta") {
+		t.Error("expected agents sorted by name")
+	}
+	if !strings.Contains(s, "*alpha* · _beads_ · idle") {
+		t.Errorf("expected idle fallback state for alpha, got %s", s)
+	}
+}
+
+func TestPublishHome_FiltersToSpawnedAgents(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["agent-mine"] = &beadsapi.BeadDetail{ID: "agent-mine", Type: "agent", Fields: map[string]string{"agent": "mine", "project": "gasboat", "role": "crew"}}
+	daemon.beads["agent-other"] = &beadsapi.BeadDetail{ID: "agent-other", Type: "agent", Fields: map[string]string{"agent": "other", "project": "gasboat", "role": "crew"}}
+	daemon.beads["dec-mine"] = &beadsapi.BeadDetail{ID: "dec-mine", Type: "decision", Assignee: "gasboat/crew/mine", Fields: map[string]string{"prompt": "Mine?"}}
+	daemon.beads["dec-other"] = &beadsapi.BeadDetail{ID: "dec-other", Type: "decision", Assignee: "other", Fields: map[string]string{"prompt": "Other?"}}
+
+	var mu sync.Mutex
+	var published string
+	slackSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "views.publish") {
+			_ = r.ParseForm()
+			mu.Lock()
+			published = r.FormValue("view")
+			if published == "" {
+				var body map[string]any
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				raw, _ := json.Marshal(body["view"])
+				published = string(raw)
+			}
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	defer slackSrv.Close()
+
+	state, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = state.SetAgentSpawner("mine", "U1")
+	_ = state.SetAgentSpawner("other", "U2")
+
+	bot := newTestBot(daemon, slackSrv)
+	bot.state = state
+
+	if err := bot.publishHome(context.Background(), "U1"); err != nil {
+		t.Fatalf("publishHome: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(published, "Mine?") {
+		t.Errorf("expected own decision in published view, got %s", published)
+	}
+	if strings.Contains(published, "Other?") {
+		t.Error("expected other user's decision to be filtered out")
+	}
+	if !strings.Contains(published, "Your agents (1)") {
+		t.Errorf("expected exactly one agent, got %s", published)
+	}
+}
+
+// spawnSubmission builds a spawn-agent modal submission from user U1.
+func spawnSubmission(agentName string) slack.InteractionCallback {
+	var cb slack.InteractionCallback
+	cb.User.ID = "U1"
+	cb.View.CallbackID = "spawn_agent"
+	cb.View.State = &slack.ViewState{Values: map[string]map[string]slack.BlockAction{
+		"agent_name": {"agent_name_input": {Value: agentName}},
+	}}
+	return cb
+}
+
+func TestValidateViewSubmission_SpawnAgentName(t *testing.T) {
+	if resp := validateViewSubmission(spawnSubmission("my-bot")); resp != nil {
+		t.Errorf("valid name rejected: %+v", resp)
+	}
+	resp := validateViewSubmission(spawnSubmission("My Bot"))
+	if resp == nil || resp.ResponseAction != slack.RAErrors || resp.Errors["agent_name"] == "" {
+		t.Fatalf("expected errors response on agent_name, got %+v", resp)
+	}
+}
+
+// failingSpawnDaemon is a mockDaemon whose spawns fail.
+type failingSpawnDaemon struct {
+	*mockDaemon
+}
+
+func (d *failingSpawnDaemon) SpawnAgent(context.Context, string, string, string, string) (string, error) {
+	return "", errors.New("daemon unavailable")
+}
+
+func TestHandleSpawnSubmission_ReportsFailure(t *testing.T) {
+	var mu sync.Mutex
+	var ephemeral []string
+	slackSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "chat.postEphemeral") {
+			_ = r.ParseForm()
+			mu.Lock()
+			ephemeral = append(ephemeral, r.FormValue("channel")+" "+r.FormValue("user")+" "+r.FormValue("text"))
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	defer slackSrv.Close()
+
+	bot := newTestBot(&failingSpawnDaemon{newMockDaemon()}, slackSrv)
+	bot.channel = "C1"
+	bot.handleSpawnSubmission(context.Background(), spawnSubmission("my-bot"))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ephemeral) != 1 || !strings.HasPrefix(ephemeral[0], "C1 U1 ") || !strings.Contains(ephemeral[0], "daemon unavailable") {
+		t.Errorf("expected one ephemeral failure message to U1 in C1, got %q", ephemeral)
+	}
+}
+
+func TestStateManager_AgentSpawnersPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	sm, err := NewStateManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetAgentSpawner("bot-a", "U1"); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewStateManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reloaded.AgentsSpawnedBy("U1")
+	if len(got) != 1 || got[0] != "bot-a" {
+		t.Errorf("expected [bot-a], got %v", got)
+	}
+	if len(reloaded.AgentsSpawnedBy("U2")) != 0 {
+		t.Error("expected no agents for U2")
+	}
+}
@@ -0,0 +1,41 @@
+package bridge
+
+import (
+	"context"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// slackResponder identifies a Slack user resolving a decision. The user's
+// email (which needs the users:read.email scope) is recorded as identity,
+// falling back to "slack:<user ID>". name is the display name to record;
+// empty uses the Slack user name. Successful lookups are cached.
+func (b *Bot) slackResponder(ctx context.Context, userID, name string) beadsapi.Responder {
+	if cached, ok := b.identities.Load(userID); ok {
+		r := cached.(beadsapi.Responder)
+		if name != "" {
+			r.Name = name
+		}
+		return r
+	}
+
+	r := beadsapi.Responder{
+		Identity: beadsapi.NormalizeIdentity("slack", userID, ""),
+		Name:     userID,
+		Via:      "slack",
+	}
+	info, err := b.api.GetUserInfoContext(ctx, userID)
+	if err != nil {
+		b.logger.Warn("failed to look up Slack user for decision audit", "user", userID, "error", err)
+	} else {
+		r.Identity = beadsapi.NormalizeIdentity("slack", userID, info.Profile.Email)
+		if info.Name != "" {
+			r.Name = info.Name
+		}
+		b.identities.Store(userID, r)
+	}
+	if name != "" {
+		r.Name = name
+	}
+	return r
+}
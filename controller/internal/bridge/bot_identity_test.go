@@ -0,0 +1,55 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSlackResponder_UsesProfileEmail(t *testing.T) {
+	var lookups atomic.Int32
+	slackSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"ok": true,
+			"user": map[string]any{
+				"id":      "U123",
+				"name":    "alice",
+				"profile": map[string]any{"email": "Alice@Example.com"},
+			},
+		})
+	}))
+	defer slackSrv.Close()
+	bot := newTestBot(newMockDaemon(), slackSrv)
+
+	r := bot.slackResponder(context.Background(), "U123", "")
+	if r.Identity != "alice@example.com" || r.Name != "alice" || r.Via != "slack" {
+		t.Errorf("responder = %+v", r)
+	}
+
+	// Cached: no second lookup, and an explicit name still wins.
+	r = bot.slackResponder(context.Background(), "U123", "Alice A.")
+	if r.Identity != "alice@example.com" || r.Name != "Alice A." {
+		t.Errorf("cached responder = %+v", r)
+	}
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("users.info called %d times, want 1", n)
+	}
+}
+
+func TestSlackResponder_FallsBackToUserID(t *testing.T) {
+	slackSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": "missing_scope"})
+	}))
+	defer slackSrv.Close()
+	bot := newTestBot(newMockDaemon(), slackSrv)
+
+	if r := bot.slackResponder(context.Background(), "U999", ""); r.Identity != "slack:U999" || r.Name != "U999" {
+		t.Errorf("responder = %+v", r)
+	}
+}
@@ -152,7 +152,7 @@ func (b *Bot) nudgeAgentForMention(ctx context.Context, agent, text, beadID stri
 		return
 	}
 
-	coopURL := beadsapi.ParseNotes(agentBead.Notes)["coop_url"]
+	coopURL := agentCoopURL(ctx, b.daemon, agentBead)
 	if coopURL == "" {
 		b.logger.Debug("agent bead has no coop_url for mention nudge",
 			"agent", agentName, "bead", beadID)
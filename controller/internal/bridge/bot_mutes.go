@@ -0,0 +1,190 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const muteUsage = ":x: Usage: `/mute agent|project <name> [duration] [decisions|agents|jacks]`, `/mute list`, or `/unmute agent|project <name> [kind]`"
+
+// handleMuteCommand processes the /mute slash command.
+// Usage: /mute agent|project <name> [duration] [kind] | /mute list
+func (b *Bot) handleMuteCommand(ctx context.Context, cmd slack.SlashCommand) {
+	args := strings.Fields(strings.TrimSpace(cmd.Text))
+	if len(args) == 0 || args[0] == "list" {
+		b.postMuteList(cmd)
+		return
+	}
+	if len(args) < 2 || (args[0] != MuteScopeAgent && args[0] != MuteScopeProject) {
+		b.postEphemeralText(cmd.ChannelID, cmd.UserID, muteUsage)
+		return
+	}
+
+	duration, kind := defaultMuteDuration, ""
+	for _, arg := range args[2:] {
+		if k, ok := parseMuteKind(arg); ok {
+			kind = k
+			continue
+		}
+		d, err := time.ParseDuration(arg)
+		if err != nil || d <= 0 {
+			b.postEphemeralText(cmd.ChannelID, cmd.UserID,
+				fmt.Sprintf(":x: Invalid duration or kind %q — use e.g. `30m`, `2h`, or one of decisions, agents, jacks", arg))
+			return
+		}
+		duration = d
+	}
+	b.applyMute(ctx, cmd.ChannelID, cmd.UserID, args[0], args[1], kind, duration)
+}
+
+// handleUnmuteCommand processes the /unmute slash command.
+// Usage: /unmute agent|project <name> [kind]
+func (b *Bot) handleUnmuteCommand(_ context.Context, cmd slack.SlashCommand) {
+	args := strings.Fields(strings.TrimSpace(cmd.Text))
+	if len(args) < 2 || len(args) > 3 || (args[0] != MuteScopeAgent && args[0] != MuteScopeProject) {
+		b.postEphemeralText(cmd.ChannelID, cmd.UserID, muteUsage)
+		return
+	}
+	kind := ""
+	if len(args) == 3 {
+		k, ok := parseMuteKind(args[2])
+		if !ok {
+			b.postEphemeralText(cmd.ChannelID, cmd.UserID, muteUsage)
+			return
+		}
+		kind = k
+	}
+	if b.state == nil {
+		b.postEphemeralText(cmd.ChannelID, cmd.UserID, ":x: Mute rules require bridge state")
+		return
+	}
+
+	rule := MuteRule{Scope: args[0], Target: args[1], Kind: kind}
+	removed, err := b.state.RemoveMute(rule.Scope, rule.Target, rule.Kind, cmd.UserID)
+	if err != nil {
+		b.logger.Error("failed to remove mute rule", "rule", rule.key(), "error", err)
+		b.postEphemeralText(cmd.ChannelID, cmd.UserID, ":x: Failed to unmute: "+err.Error())
+		return
+	}
+	if !removed {
+		b.postEphemeralText(cmd.ChannelID, cmd.UserID, fmt.Sprintf("No mute set for %s", rule.describe()))
+		return
+	}
+	b.logger.Info("notifications unmuted via Slack", "rule", rule.key(), "user", cmd.UserID)
+	_, _, _ = b.api.PostMessage(cmd.ChannelID,
+		slack.MsgOptionText(fmt.Sprintf(":bell: <@%s> unmuted %s", cmd.UserID, rule.describe()), false))
+}
+
+// handleMuteOverflow processes the agent card overflow menu. The selected
+// option value is "scope|target|duration".
+func (b *Bot) handleMuteOverflow(ctx context.Context, value string, callback slack.InteractionCallback) {
+	parts := strings.SplitN(value, "|", 3)
+	if len(parts) != 3 {
+		b.logger.Error("invalid mute overflow value", "value", value)
+		return
+	}
+	duration, err := time.ParseDuration(parts[2])
+	if err != nil {
+		b.logger.Error("invalid mute overflow duration", "value", value)
+		return
+	}
+	b.applyMute(ctx, callback.Channel.ID, callback.User.ID, parts[0], parts[1], "", duration)
+}
+
+// applyMute stores a mute rule and announces it in the channel so others can
+// see who muted what.
+func (b *Bot) applyMute(_ context.Context, channelID, userID, scope, target, kind string, duration time.Duration) {
+	if b.state == nil {
+		b.postEphemeralText(channelID, userID, ":x: Mute rules require bridge state")
+		return
+	}
+	duration = min(duration, maxMuteDuration)
+	now := time.Now()
+	rule := MuteRule{
+		Scope:   scope,
+		Target:  target,
+		Kind:    kind,
+		Until:   now.Add(duration),
+		MutedBy: userID,
+		MutedAt: now,
+	}
+	if err := b.state.SetMute(rule); err != nil {
+		b.logger.Error("failed to store mute rule", "rule", rule.key(), "error", err)
+		b.postEphemeralText(channelID, userID, ":x: Failed to mute: "+err.Error())
+		return
+	}
+	b.logger.Info("notifications muted via Slack", "rule", rule.key(), "until", rule.Until, "user", userID)
+	_, _, _ = b.api.PostMessage(channelID,
+		slack.MsgOptionText(fmt.Sprintf(":no_bell: <@%s> muted %s for %s (until <!date^%d^{time}|%s>)",
+			userID, rule.describe(), formatMuteDuration(duration), rule.Until.Unix(), rule.Until.UTC().Format(time.RFC3339)), false))
+}
+
+// postMuteList shows active mute rules and recent audit entries.
+func (b *Bot) postMuteList(cmd slack.SlashCommand) {
+	if b.state == nil {
+		b.postEphemeralText(cmd.ChannelID, cmd.UserID, ":x: Mute rules require bridge state")
+		return
+	}
+	now := time.Now()
+	var sb strings.Builder
+	active := b.state.ActiveMutes(now)
+	if len(active) == 0 {
+		sb.WriteString("No active mutes.")
+	} else {
+		sb.WriteString("*Active mutes*\n")
+		for _, r := range active {
+			fmt.Fprintf(&sb, "• %s — %s left, by <@%s>\n", r.describe(), formatMuteDuration(r.Until.Sub(now)), r.MutedBy)
+		}
+	}
+
+	audit := b.state.MuteAuditLog()
+	if n := len(audit); n > 0 {
+		sb.WriteString("\n*Recent changes*\n")
+		for i := n - 1; i >= 0 && i >= n-10; i-- {
+			e := audit[i]
+			by := "expired"
+			if e.By != "" {
+				by = fmt.Sprintf("<@%s>", e.By)
+			}
+			fmt.Fprintf(&sb, "• %s %s %s · %s ago\n", e.Action, e.Rule.describe(), by, formatMuteDuration(now.Sub(e.At)))
+		}
+	}
+	b.postEphemeralText(cmd.ChannelID, cmd.UserID, sb.String())
+}
+
+func (b *Bot) postEphemeralText(channelID, userID, text string) {
+	_, _ = b.api.PostEphemeral(channelID, userID, slack.MsgOptionText(text, false))
+}
+
+// buildMuteOverflow returns the agent card overflow menu offering to mute
+// the agent or its project.
+func buildMuteOverflow(agent, project string) *slack.OverflowBlockElement {
+	opts := []*slack.OptionBlockObject{
+		slack.NewOptionBlockObject(fmt.Sprintf("%s|%s|2h", MuteScopeAgent, agent),
+			slack.NewTextBlockObject("plain_text", "Mute agent for 2h", false, false), nil),
+		slack.NewOptionBlockObject(fmt.Sprintf("%s|%s|24h", MuteScopeAgent, agent),
+			slack.NewTextBlockObject("plain_text", "Mute agent for 24h", false, false), nil),
+	}
+	if project != "" {
+		opts = append(opts, slack.NewOptionBlockObject(fmt.Sprintf("%s|%s|2h", MuteScopeProject, project),
+			slack.NewTextBlockObject("plain_text", "Mute project for 2h", false, false), nil))
+	}
+	return slack.NewOverflowBlockElement("agent_mute", opts...)
+}
+
+// formatMuteDuration renders a duration rounded to minutes, e.g. "1h30m".
+func formatMuteDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d < time.Minute {
+		return "<1m"
+	}
+	s := strings.TrimSuffix(d.String(), "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
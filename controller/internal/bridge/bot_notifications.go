@@ -3,11 +3,17 @@ package bridge
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strings"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/errorreporter"
 
 	"github.com/slack-go/slack"
 )
 
-// NotifyAgentCrash posts a crash alert to the agent's resolved Slack channel.
+// NotifyAgentCrash posts a critical crash alert, by default to the agent's
+// resolved Slack channel.
 func (b *Bot) NotifyAgentCrash(ctx context.Context, bead BeadEvent) error {
 	agent := bead.Assignee
 	if agent == "" {
@@ -18,18 +24,11 @@ func (b *Bot) NotifyAgentCrash(ctx context.Context, bead BeadEvent) error {
 		name = bead.ID
 	}
 
-	text := fmt.Sprintf(":warning: *Agent crashed: %s*", name)
-
-	// Add reason from fields.
-	reason := bead.Fields["agent_state"]
-	podPhase := bead.Fields["pod_phase"]
-	podName := bead.Fields["pod_name"]
-	if podPhase == "failed" && reason != "failed" {
-		text += fmt.Sprintf("\n> Pod phase: `%s`", podPhase)
-	}
-	if podName != "" {
-		text += fmt.Sprintf("\n> Pod: `%s`", podName)
-	}
+	targetChannel := b.resolveChannel(agent)
+	view := notificationView(bead)
+	view.Agent = name
+	view.Locale = b.locales.LocaleFor(targetChannel)
+	text := b.templates.Render(tmplAgentCrash, view)
 
 	blocks := []slack.Block{
 		slack.NewSectionBlock(
@@ -40,56 +39,50 @@ func (b *Bot) NotifyAgentCrash(ctx context.Context, bead BeadEvent) error {
 				fmt.Sprintf("Agent: `%s`", name), false, false)),
 	}
 
-	targetChannel := b.resolveChannel(agent)
-
-	_, _, err := b.api.PostMessageContext(ctx, targetChannel,
-		slack.MsgOptionText(fmt.Sprintf("Agent crashed: %s", name), false),
-		slack.MsgOptionBlocks(blocks...),
-	)
+	channelID, _, err := b.notify(ctx, notification{
+		severity: SeverityCritical,
+		project:  beadProject(bead),
+		agent:    agent,
+		summary:  b.templates.Render(tmplAgentCrash+".fallback", view),
+		blocks:   blocks,
+	})
 	if err != nil {
 		return fmt.Errorf("post agent crash to Slack: %w", err)
 	}
 
 	b.logger.Info("posted agent crash to Slack",
-		"agent", name, "bead", bead.ID, "channel", targetChannel)
+		"agent", name, "bead", bead.ID, "channel", channelID)
 	return nil
 }
 
-// NotifyJackOn posts a jack-raised alert to Slack.
+// NotifyJackOn posts a jack-raised warning to Slack. The message is
+// remembered so NotifyJackOff can edit it rather than post a second one.
 func (b *Bot) NotifyJackOn(ctx context.Context, bead BeadEvent) error {
 	target := bead.Fields["target"]
-	agent := bead.Assignee
-	ttl := bead.Fields["ttl"]
-	reason := bead.Fields["reason"]
-
-	text := fmt.Sprintf(":wrench: *Jack Raised: %s*\nTarget: `%s`", beadTitle(bead.ID, bead.Title), target)
-	if agent != "" {
-		text += fmt.Sprintf("\nAgent: `%s`", agent)
-	}
-	if ttl != "" {
-		text += fmt.Sprintf("\nTTL: %s", ttl)
-	}
-	if reason != "" {
-		text += fmt.Sprintf("\n> %s", reason)
-	}
+	targetChannel := b.resolveChannel(bead.Assignee)
+	view := b.jackView(ctx, bead, targetChannel)
+	text := b.templates.Render(tmplJackOn, view)
 
-	targetChannel := b.resolveChannel(agent)
-	_, _, err := b.api.PostMessageContext(ctx, targetChannel,
-		slack.MsgOptionText(fmt.Sprintf("Jack raised: %s on %s", bead.ID, target), false),
-		slack.MsgOptionBlocks(
-			slack.NewSectionBlock(
-				slack.NewTextBlockObject("mrkdwn", text, false, false),
-				nil, nil),
-		),
-	)
+	channelID, ts, err := b.notify(ctx, notification{
+		severity: SeverityWarn,
+		project:  beadProject(bead),
+		agent:    bead.Assignee,
+		summary:  b.templates.Render(tmplJackOn+".fallback", view),
+		blocks:   jackBlocks(text),
+	})
 	if err != nil {
 		return fmt.Errorf("post jack on to Slack: %w", err)
 	}
+	if b.state != nil && ts != "" {
+		if err := b.state.SetJackMessage(bead.ID, MessageRef{ChannelID: channelID, Timestamp: ts}); err != nil {
+			b.logger.Warn("failed to persist jack message ref", "jack", bead.ID, "error", err)
+		}
+	}
 	b.logger.Info("posted jack raised to Slack", "jack", bead.ID, "target", target)
 	return nil
 }
 
-// NotifyJackOnBatch posts a batch summary of jack-raised events.
+// NotifyJackOnBatch posts a batch summary of jack-raised events as a warning.
 func (b *Bot) NotifyJackOnBatch(ctx context.Context, beads []BeadEvent) error {
 	text := fmt.Sprintf(":wrench: *%d additional jacks raised* (batch)\n", len(beads))
 
@@ -110,14 +103,15 @@ func (b *Bot) NotifyJackOnBatch(ctx context.Context, beads []BeadEvent) error {
 		text += fmt.Sprintf("_...and %d more_\n", len(beads)-limit)
 	}
 
-	_, _, err := b.api.PostMessageContext(ctx, b.channel,
-		slack.MsgOptionText(fmt.Sprintf("%d additional jacks raised", len(beads)), false),
-		slack.MsgOptionBlocks(
+	_, _, err := b.notify(ctx, notification{
+		severity: SeverityWarn,
+		summary:  fmt.Sprintf("%d additional jacks raised", len(beads)),
+		blocks: []slack.Block{
 			slack.NewSectionBlock(
 				slack.NewTextBlockObject("mrkdwn", text, false, false),
 				nil, nil),
-		),
-	)
+		},
+	})
 	if err != nil {
 		return fmt.Errorf("post jack batch to Slack: %w", err)
 	}
@@ -125,30 +119,44 @@ func (b *Bot) NotifyJackOnBatch(ctx context.Context, beads []BeadEvent) error {
 	return nil
 }
 
-// NotifyJackOff posts a jack-lowered alert to Slack.
+// NotifyJackOff reports a lowered jack. The raised message is edited into
+// the lowered one, with duration and outcome; jacks raised before the bridge
+// tracked them (or announced in a batch or digest) get a new info
+// notification instead.
 func (b *Bot) NotifyJackOff(ctx context.Context, bead BeadEvent) error {
 	target := bead.Fields["target"]
-	agent := bead.Assignee
-	reason := bead.Fields["reason"]
-
-	text := fmt.Sprintf(":white_check_mark: *Jack Lowered: %s*\nTarget: `%s`", beadTitle(bead.ID, bead.Title), target)
-	if agent != "" {
-		text += fmt.Sprintf("\nAgent: `%s`", agent)
+	targetChannel := b.resolveChannel(bead.Assignee)
+	view := b.jackView(ctx, bead, targetChannel)
+	text := b.templates.Render(tmplJackOff, view)
+	fallback := b.templates.Render(tmplJackOff+".fallback", view)
+	opts := []slack.MsgOption{
+		slack.MsgOptionText(fallback, false),
+		slack.MsgOptionBlocks(jackBlocks(text)...),
 	}
-	if reason != "" {
-		text += fmt.Sprintf("\n> %s", reason)
+
+	if b.state != nil {
+		if ref, ok := b.state.GetJackMessage(bead.ID); ok {
+			_, _, _, err := b.api.UpdateMessageContext(ctx, ref.ChannelID, ref.Timestamp, opts...)
+			if err == nil {
+				if err := b.state.RemoveJackMessage(bead.ID); err != nil {
+					b.logger.Warn("failed to remove jack message ref", "jack", bead.ID, "error", err)
+				}
+				b.logger.Info("updated jack message to lowered", "jack", bead.ID, "target", target)
+				return nil
+			}
+			// The raised message may have been deleted; post a new one.
+			b.logger.Warn("failed to update jack message, posting instead", "jack", bead.ID, "error", err)
+			_ = b.state.RemoveJackMessage(bead.ID)
+		}
 	}
 
-	targetChannel := b.resolveChannel(agent)
-	_, _, err := b.api.PostMessageContext(ctx, targetChannel,
-		slack.MsgOptionText(fmt.Sprintf("Jack lowered: %s", bead.ID), false),
-		slack.MsgOptionBlocks(
-			slack.NewSectionBlock(
-				slack.NewTextBlockObject("mrkdwn", text, false, false),
-				nil, nil),
-		),
-	)
-	if err != nil {
+	if _, _, err := b.notify(ctx, notification{
+		severity: SeverityInfo,
+		project:  beadProject(bead),
+		agent:    bead.Assignee,
+		summary:  fallback,
+		blocks:   jackBlocks(text),
+	}); err != nil {
 		return fmt.Errorf("post jack off to Slack: %w", err)
 	}
 	b.logger.Info("posted jack lowered to Slack", "jack", bead.ID, "target", target)
@@ -158,30 +166,153 @@ func (b *Bot) NotifyJackOff(ctx context.Context, bead BeadEvent) error {
 // NotifyJackExpired posts a jack-expired warning to Slack.
 func (b *Bot) NotifyJackExpired(ctx context.Context, bead BeadEvent) error {
 	target := bead.Fields["target"]
-	agent := bead.Assignee
-	reason := bead.Fields["reason"]
+	targetChannel := b.resolveChannel(bead.Assignee)
+	view := b.jackView(ctx, bead, targetChannel)
+	text := b.templates.Render(tmplJackExpired, view)
+
+	_, _, err := b.notify(ctx, notification{
+		severity: SeverityWarn,
+		project:  beadProject(bead),
+		agent:    bead.Assignee,
+		summary:  b.templates.Render(tmplJackExpired+".fallback", view),
+		blocks:   jackBlocks(text),
+	})
+	if err != nil {
+		return fmt.Errorf("post jack expired to Slack: %w", err)
+	}
+	b.logger.Info("posted jack expired to Slack", "jack", bead.ID, "target", target)
+	return nil
+}
 
-	text := fmt.Sprintf(":warning: *Jack Expired: %s*\nTarget: `%s`", beadTitle(bead.ID, bead.Title), target)
-	if agent != "" {
-		text += fmt.Sprintf("\nAgent: `%s`", agent)
+// jackView builds the template view of a jack notification for channel,
+// with deep links to the bead, the agent's coop terminal and the dashboard.
+func (b *Bot) jackView(ctx context.Context, bead BeadEvent, channel string) NotificationView {
+	view := notificationView(bead)
+	view.Locale = b.locales.LocaleFor(channel)
+	if b.beadURL != "" {
+		view.BeadURL = strings.ReplaceAll(b.beadURL, "{id}", url.PathEscape(bead.ID))
 	}
-	if reason != "" {
-		text += fmt.Sprintf("\n> %s", reason)
+	view.DashboardURL = b.dashboardURL
+	if bead.Assignee != "" && b.daemon != nil {
+		if agentBead, err := b.daemon.FindAgentBead(ctx, bead.Assignee); err == nil {
+			view.CoopURL = b.coopLink(ctx, agentBead)
+		} else {
+			b.logger.Debug("no agent bead for jack link", "jack", bead.ID, "agent", bead.Assignee, "error", err)
+		}
 	}
-	text += fmt.Sprintf("\n_Review revert plan and close with_ `bd jack off %s`", bead.ID)
+	return view
+}
 
-	targetChannel := b.resolveChannel(agent)
-	_, _, err := b.api.PostMessageContext(ctx, targetChannel,
-		slack.MsgOptionText(fmt.Sprintf("Jack expired: %s on %s", bead.ID, target), false),
-		slack.MsgOptionBlocks(
-			slack.NewSectionBlock(
-				slack.NewTextBlockObject("mrkdwn", text, false, false),
-				nil, nil),
-		),
-	)
+// coopLink returns a link to agentBead's coop session: through the
+// controller's proxy with a fresh token when coop tokens are configured,
+// else its in-cluster URL. It is empty when the agent has no session or no
+// token could be minted.
+func (b *Bot) coopLink(ctx context.Context, agentBead *beadsapi.BeadDetail) string {
+	coopURL := agentCoopURL(ctx, b.daemon, agentBead)
+	if coopURL == "" || b.coopTokens == nil {
+		return coopURL
+	}
+	link, err := b.coopTokens.Link(ctx, agentBead.ID)
 	if err != nil {
-		return fmt.Errorf("post jack expired to Slack: %w", err)
+		b.logger.Warn("failed to mint coop token, omitting terminal link", "agent", agentBead.ID, "error", err)
+		return ""
 	}
-	b.logger.Info("posted jack expired to Slack", "jack", bead.ID, "target", target)
+	return link
+}
+
+// beadProject returns the project of bead: its project field, or the
+// project of its assigned agent.
+func beadProject(bead BeadEvent) string {
+	if p := bead.Fields["project"]; p != "" {
+		return p
+	}
+	return extractAgentProject(bead.Assignee)
+}
+
+func jackBlocks(text string) []slack.Block {
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", text, false, false),
+			nil, nil),
+	}
+}
+
+// NotifyErrorReport posts a rolled-up error report as a warning, by default
+// to the default channel: one message listing each distinct error with its
+// count.
+func (b *Bot) NotifyErrorReport(ctx context.Context, report errorreporter.Report) error {
+	summary := report.Summary()
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", ":rotating_light: *"+summary+"*", false, false),
+			nil, nil),
+	}
+	for _, g := range report.Groups {
+		text := fmt.Sprintf("*%s* ×%d (%s)", g.Message, g.Count, strings.ToLower(g.Level))
+		if g.Error != "" {
+			text += "\n```" + truncateText(g.Error, 300) + "```"
+		}
+		text += fmt.Sprintf("\nFirst seen %s, last seen %s",
+			g.FirstSeen.UTC().Format("15:04:05"), g.LastSeen.UTC().Format("15:04:05 MST"))
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", text, false, false),
+			nil, nil))
+	}
+	if report.Omitted > 0 || report.Dropped > 0 {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject("mrkdwn",
+				fmt.Sprintf("%d more distinct errors omitted, %d occurrences not grouped", report.Omitted, report.Dropped),
+				false, false)))
+	}
+
+	channelID, _, err := b.notify(ctx, notification{
+		severity: SeverityWarn,
+		summary:  summary,
+		blocks:   blocks,
+	})
+	if err != nil {
+		return fmt.Errorf("post error report to Slack: %w", err)
+	}
+	b.logger.Info("posted error report to Slack",
+		"source", report.Source, "total", report.Total, "channel", channelID)
+	return nil
+}
+
+// NotifyBudgetAlert posts a project crossing its daily API budget, with how
+// to lift a hard cap: a warning at the soft cap, critical at the hard one.
+func (b *Bot) NotifyBudgetAlert(ctx context.Context, alert beadsapi.BudgetAlert) error {
+	summary := alert.Summary()
+	icon := ":money_with_wings:"
+	if alert.Level == beadsapi.BudgetHard {
+		icon = ":no_entry:"
+	}
+	blocks := []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", icon+" *"+summary+"*", false, false),
+			nil, nil),
+	}
+	note := fmt.Sprintf("%d tokens on %s (UTC)", alert.Tokens, alert.Day)
+	if alert.Level == beadsapi.BudgetHard {
+		note += "; running agents continue. Lift with the controller admin API: " +
+			"`POST /admin/projects/" + alert.Project + "/budget/reset` or `.../budget/override?until=4h`"
+	}
+	blocks = append(blocks, slack.NewContextBlock("",
+		slack.NewTextBlockObject("mrkdwn", note, false, false)))
+
+	severity := SeverityWarn
+	if alert.Level == beadsapi.BudgetHard {
+		severity = SeverityCritical
+	}
+	channelID, _, err := b.notify(ctx, notification{
+		severity: severity,
+		project:  alert.Project,
+		summary:  summary,
+		blocks:   blocks,
+	})
+	if err != nil {
+		return fmt.Errorf("post budget alert to Slack: %w", err)
+	}
+	b.logger.Info("posted budget alert to Slack",
+		"project", alert.Project, "level", alert.Level, "channel", channelID)
 	return nil
 }
@@ -0,0 +1,107 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// recordingSlackServer is a fake Slack API that records the methods called
+// and the text of each message.
+type recordingSlackServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	calls []string // "<method> <ts> <text>"
+}
+
+func newRecordingSlackServer(t *testing.T) *recordingSlackServer {
+	t.Helper()
+	s := &recordingSlackServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		s.mu.Lock()
+		s.calls = append(s.calls, strings.TrimPrefix(r.URL.Path, "/")+" "+r.Form.Get("ts")+" "+r.Form.Get("blocks"))
+		s.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "channel": "C1", "ts": "1700000000.000100"})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestNotifyJack_LoweredEditsRaisedMessage(t *testing.T) {
+	slackSrv := newRecordingSlackServer(t)
+	daemon := newMockDaemon()
+	daemon.beads["bot"] = &beadsapi.BeadDetail{ID: "a-1", Notes: "coop_url: http://coop.bot:8080"}
+	state, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := newTestBot(daemon, slackSrv.Server)
+	bot.state = state
+	bot.channel = "C1"
+	bot.beadURL = "https://beads.example.com/beads/{id}"
+
+	ctx := context.Background()
+	jack := BeadEvent{ID: "j-1", Type: "jack", Assignee: "bot", Fields: map[string]string{"target": "deploy/x"}}
+	if err := bot.NotifyJackOn(ctx, jack); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := state.GetJackMessage("j-1"); !ok {
+		t.Fatal("raised message ref was not stored")
+	}
+
+	jack.Fields["close_reason"] = "done"
+	if err := bot.NotifyJackOff(ctx, jack); err != nil {
+		t.Fatal(err)
+	}
+	if len(slackSrv.calls) != 2 {
+		t.Fatalf("calls = %q, want one post and one update", slackSrv.calls)
+	}
+	if !strings.HasPrefix(slackSrv.calls[0], "chat.postMessage") {
+		t.Errorf("first call = %q, want chat.postMessage", slackSrv.calls[0])
+	}
+	update := slackSrv.calls[1]
+	if !strings.HasPrefix(update, "chat.update 1700000000.000100 ") {
+		t.Errorf("second call = %q, want chat.update of the raised message", update)
+	}
+	for _, want := range []string{"Jack Lowered", "Outcome: done", "https://beads.example.com/beads/j-1", "http://coop.bot:8080"} {
+		if !strings.Contains(update, want) {
+			t.Errorf("update %q lacks %q", update, want)
+		}
+	}
+	if _, ok := state.GetJackMessage("j-1"); ok {
+		t.Error("ref kept after the jack was lowered")
+	}
+}
+
+func TestNotifyJackOff_PostsWithoutRaisedMessage(t *testing.T) {
+	slackSrv := newRecordingSlackServer(t)
+	state, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bot := newTestBot(newMockDaemon(), slackSrv.Server)
+	bot.state = state
+	bot.channel = "C1"
+
+	ctx := context.Background()
+	if err := bot.NotifyJackOff(ctx, BeadEvent{ID: "j-2", Type: "jack"}); err != nil {
+		t.Fatal(err)
+	}
+	// A lowered jack is info, which only goes to the digest.
+	if len(slackSrv.calls) != 0 {
+		t.Fatalf("calls = %q, want the jack queued for the digest", slackSrv.calls)
+	}
+	bot.flushNotificationDigest(ctx)
+	if len(slackSrv.calls) != 1 || !strings.HasPrefix(slackSrv.calls[0], "chat.postMessage") || !strings.Contains(slackSrv.calls[0], "j-2") {
+		t.Errorf("calls = %q, want a single digest chat.postMessage", slackSrv.calls)
+	}
+}
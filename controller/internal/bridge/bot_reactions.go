@@ -0,0 +1,153 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+// defaultReactionShortcuts maps number emoji to option indexes. Configured
+// shortcuts (BotConfig.ReactionShortcuts) are merged on top.
+var defaultReactionShortcuts = map[string]string{
+	"one": "1", "two": "2", "three": "3", "four": "4", "five": "5",
+	"six": "6", "seven": "7", "eight": "8", "nine": "9",
+}
+
+// ParseReactionShortcuts parses "emoji=option" pairs separated by commas,
+// e.g. "+1=approve,-1=reject,white_check_mark=1". Emoji names may be written
+// with or without surrounding colons. The option is a 1-based index or an
+// option label/short/id.
+func ParseReactionShortcuts(s string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		emoji, option, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		emoji = strings.Trim(strings.TrimSpace(emoji), ":")
+		option = strings.TrimSpace(option)
+		if emoji == "" || option == "" {
+			continue
+		}
+		m[emoji] = option
+	}
+	return m
+}
+
+// handleReactionAdded resolves a decision when a user reacts to its message
+// with a configured shortcut emoji. Attribution matches button resolution.
+func (b *Bot) handleReactionAdded(ctx context.Context, ev *slackevents.ReactionAddedEvent) {
+	if ev.Item.Type != "message" || ev.User == "" || ev.User == b.botUserID {
+		return
+	}
+	// Skin-tone variants ("+1::skin-tone-3") map to the base emoji.
+	reaction, _, _ := strings.Cut(ev.Reaction, "::")
+	target, ok := b.reactionShortcuts[reaction]
+	if !ok {
+		target, ok = defaultReactionShortcuts[reaction]
+	}
+	if !ok {
+		return
+	}
+
+	beadID := b.getDecisionByThread(ev.Item.Channel, ev.Item.Timestamp)
+	if beadID == "" {
+		return // Not a decision message we're tracking
+	}
+
+	bead, err := b.daemon.GetBead(ctx, beadID)
+	if err != nil {
+		b.logger.Error("failed to get decision for reaction", "bead", beadID, "error", err)
+		return
+	}
+	if bead.Status != "open" && bead.Status != "in_progress" {
+		return
+	}
+
+	chosen, ok := matchDecisionOption(bead.Fields["options"], target)
+	if !ok {
+		b.logger.Debug("reaction shortcut matches no option",
+			"bead", beadID, "reaction", reaction, "target", target)
+		return
+	}
+
+	responder := b.slackResponder(ctx, ev.User, "")
+	user := responder.Name
+	rationale := fmt.Sprintf("Chosen by @%s via Slack", user)
+
+	fields := map[string]string{
+		"chosen":    chosen,
+		"rationale": rationale,
+	}
+	maps.Copy(fields, responder.AuditFields(time.Now()))
+	if at := b.lookupArtifactType(ctx, beadID, chosen); at != "" {
+		fields["required_artifact"] = at
+		fields["artifact_status"] = "pending"
+	}
+
+	if err := b.daemon.CloseBead(ctx, beadID, fields); err != nil {
+		b.logger.Error("failed to resolve decision from reaction",
+			"bead", beadID, "error", err)
+		return
+	}
+
+	b.updateMessageResolved(ctx, beadID, chosen, rationale, ev.Item.Channel, ev.Item.Timestamp)
+
+	b.logger.Info("decision resolved via reaction",
+		"bead", beadID, "chosen", chosen, "reaction", reaction, "user", user)
+}
+
+// matchDecisionOption returns the label of the option selected by target,
+// which is either a 1-based index or a case-insensitive label/short/id.
+// Options may be a JSON array of objects or of strings.
+func matchDecisionOption(optionsRaw, target string) (string, bool) {
+	type optionObj struct {
+		ID    string `json:"id"`
+		Short string `json:"short"`
+		Label string `json:"label"`
+	}
+	var labels []string
+	var names [][]string // per option: candidate names for label matching
+
+	var objs []optionObj
+	var strs []string
+	if err := json.Unmarshal([]byte(optionsRaw), &objs); err == nil && len(objs) > 0 {
+		for _, o := range objs {
+			label := o.Label
+			if label == "" {
+				label = o.Short
+			}
+			if label == "" {
+				label = o.ID
+			}
+			labels = append(labels, label)
+			names = append(names, []string{o.Label, o.Short, o.ID})
+		}
+	} else if err := json.Unmarshal([]byte(optionsRaw), &strs); err == nil {
+		for _, s := range strs {
+			labels = append(labels, s)
+			names = append(names, []string{s})
+		}
+	}
+
+	if idx, err := strconv.Atoi(target); err == nil {
+		if idx >= 1 && idx <= len(labels) {
+			return labels[idx-1], true
+		}
+		return "", false
+	}
+	for i, candidates := range names {
+		for _, name := range candidates {
+			if name != "" && strings.EqualFold(name, target) {
+				return labels[i], true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,103 @@
+package bridge
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/slack-go/slack/slackevents"
+)
+
+func newReactionTestBot(t *testing.T, daemon *mockDaemon) *Bot {
+	t.Helper()
+	slackSrv := newFakeSlackServer(t)
+	t.Cleanup(slackSrv.Close)
+
+	state, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = state.SetDecisionMessage("dec-1", MessageRef{ChannelID: "C1", Timestamp: "111.222"})
+
+	bot := newTestBot(daemon, slackSrv)
+	bot.state = state
+	bot.reactionShortcuts = ParseReactionShortcuts("+1=approve, :x:=Reject")
+	return bot
+}
+
+func reactionEvent(reaction, ts string) *slackevents.ReactionAddedEvent {
+	return &slackevents.ReactionAddedEvent{
+		User:     "U123",
+		Reaction: reaction,
+		Item:     slackevents.Item{Type: "message", Channel: "C1", Timestamp: ts},
+	}
+}
+
+func TestHandleReactionAdded_ResolvesByLabel(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["dec-1"] = &beadsapi.BeadDetail{
+		ID: "dec-1", Type: "decision", Status: "open",
+		Fields: map[string]string{"options": `[{"id":"approve","label":"Approve"},{"id":"reject","label":"Reject"}]`},
+	}
+	bot := newReactionTestBot(t, daemon)
+
+	bot.handleReactionAdded(context.Background(), reactionEvent("+1::skin-tone-2", "111.222"))
+
+	closed := daemon.getClosed()
+	if len(closed) != 1 {
+		t.Fatalf("expected decision closed, got %d closes", len(closed))
+	}
+	if closed[0].Fields["chosen"] != "Approve" {
+		t.Errorf("expected chosen=Approve, got %q", closed[0].Fields["chosen"])
+	}
+	if closed[0].Fields["rationale"] != "Chosen by @U123 via Slack" {
+		t.Errorf("unexpected rationale %q", closed[0].Fields["rationale"])
+	}
+}
+
+func TestHandleReactionAdded_NumberEmojiSelectsIndex(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["dec-1"] = &beadsapi.BeadDetail{
+		ID: "dec-1", Type: "decision", Status: "open",
+		Fields: map[string]string{"options": `["Left","Right"]`},
+	}
+	bot := newReactionTestBot(t, daemon)
+
+	bot.handleReactionAdded(context.Background(), reactionEvent("two", "111.222"))
+
+	closed := daemon.getClosed()
+	if len(closed) != 1 || closed[0].Fields["chosen"] != "Right" {
+		t.Fatalf("expected chosen=Right, got %+v", closed)
+	}
+}
+
+func TestHandleReactionAdded_Ignored(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["dec-1"] = &beadsapi.BeadDetail{
+		ID: "dec-1", Type: "decision", Status: "open",
+		Fields: map[string]string{"options": `["Left","Right"]`},
+	}
+	bot := newReactionTestBot(t, daemon)
+
+	// Unmapped emoji, untracked message, out-of-range index.
+	bot.handleReactionAdded(context.Background(), reactionEvent("tada", "111.222"))
+	bot.handleReactionAdded(context.Background(), reactionEvent("one", "999.000"))
+	bot.handleReactionAdded(context.Background(), reactionEvent("five", "111.222"))
+
+	// Already-resolved decisions are not re-closed.
+	daemon.beads["dec-1"].Status = "closed"
+	bot.handleReactionAdded(context.Background(), reactionEvent("one", "111.222"))
+
+	if closed := daemon.getClosed(); len(closed) != 0 {
+		t.Errorf("expected no resolutions, got %+v", closed)
+	}
+}
+
+func TestParseReactionShortcuts(t *testing.T) {
+	got := ParseReactionShortcuts(" :+1:=approve ,-1=2,bad,=x,y=")
+	if len(got) != 2 || got["+1"] != "approve" || got["-1"] != "2" {
+		t.Errorf("unexpected shortcuts %v", got)
+	}
+}
@@ -0,0 +1,147 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+const (
+	// DefaultNotificationDigestInterval is how often digested
+	// notifications are posted.
+	DefaultNotificationDigestInterval = time.Hour
+
+	// notificationDigestMaxLines caps the notifications listed in one
+	// digest message; the rest are counted.
+	notificationDigestMaxLines = 30
+)
+
+// notification is a Slack message routed by its severity.
+type notification struct {
+	severity Severity
+	project  string // selects the project's routing rules; empty = default
+	agent    string // the agent's routed channel is used when the rule names none
+	summary  string // fallback text, and the line listed in a digest
+	blocks   []slack.Block
+}
+
+// digestLine is a notification waiting for the next digest.
+type digestLine struct {
+	at       time.Time
+	severity Severity
+	text     string
+}
+
+// notify posts n where its severity routes it, mentioning whom the route
+// says, or queues it for the channel's next digest. It returns the posted
+// message's channel and timestamp, both empty when digested.
+func (b *Bot) notify(ctx context.Context, n notification) (channelID, ts string, err error) {
+	route := b.severity.Route(n.project, n.severity)
+	channel := route.Channel
+	if channel == "" {
+		channel = b.resolveChannel(n.agent)
+	}
+	if route.Digest {
+		b.digestMu.Lock()
+		if b.digest == nil {
+			b.digest = make(map[string][]digestLine)
+		}
+		b.digest[channel] = append(b.digest[channel], digestLine{at: time.Now(), severity: n.severity, text: n.summary})
+		b.digestMu.Unlock()
+		b.logger.Debug("queued notification for digest", "severity", n.severity, "project", n.project, "channel", channel)
+		return "", "", nil
+	}
+
+	text, blocks := n.summary, n.blocks
+	if mention := mentionText(route.Mention); mention != "" {
+		text = mention + " " + text
+		blocks = append([]slack.Block{slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", mention, false, false), nil, nil)}, blocks...)
+	}
+	return b.api.PostMessageContext(ctx, channel,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(blocks...),
+	)
+}
+
+// RunNotificationDigest posts the digested notifications every interval
+// (DefaultNotificationDigestInterval when zero) until ctx is cancelled, and
+// once more on the way out. Only the leader should run it; notifications
+// queued on a replica that loses leadership are posted by it then.
+func (b *Bot) RunNotificationDigest(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultNotificationDigestInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Post what is queued rather than dropping it.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			b.flushNotificationDigest(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			b.flushNotificationDigest(ctx)
+		}
+	}
+}
+
+// flushNotificationDigest posts one digest message per channel with queued
+// notifications. A channel whose post fails keeps them for the next digest.
+func (b *Bot) flushNotificationDigest(ctx context.Context) {
+	b.digestMu.Lock()
+	pending := b.digest
+	b.digest = nil
+	b.digestMu.Unlock()
+
+	channels := make([]string, 0, len(pending))
+	for channel := range pending {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	for _, channel := range channels {
+		lines := pending[channel]
+		summary, text := notificationDigestText(lines)
+		_, _, err := b.api.PostMessageContext(ctx, channel,
+			slack.MsgOptionText(summary, false),
+			slack.MsgOptionBlocks(slack.NewSectionBlock(
+				slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil)),
+		)
+		if err != nil {
+			b.logger.Warn("failed to post notification digest, keeping it for the next one",
+				"channel", channel, "notifications", len(lines), "error", err)
+			b.digestMu.Lock()
+			if b.digest == nil {
+				b.digest = make(map[string][]digestLine)
+			}
+			b.digest[channel] = append(lines, b.digest[channel]...)
+			b.digestMu.Unlock()
+			continue
+		}
+		b.logger.Info("posted notification digest", "channel", channel, "notifications", len(lines))
+	}
+}
+
+// notificationDigestText renders lines as a digest message, oldest first.
+func notificationDigestText(lines []digestLine) (summary, text string) {
+	summary = fmt.Sprintf("%d notifications since %s", len(lines), lines[0].at.UTC().Format("15:04 MST"))
+	var sb strings.Builder
+	sb.WriteString(":newspaper: *" + summary + "*")
+	for i, l := range lines {
+		if i == notificationDigestMaxLines {
+			fmt.Fprintf(&sb, "\n_...and %d more_", len(lines)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "\n• %s %s", l.at.UTC().Format("15:04"), truncateText(l.text, 200))
+		if l.severity != SeverityInfo {
+			fmt.Fprintf(&sb, " _(%s)_", l.severity)
+		}
+	}
+	return summary, sb.String()
+}
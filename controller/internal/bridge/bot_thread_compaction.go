@@ -0,0 +1,283 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Thread compaction defaults.
+const (
+	defaultThreadCompactionAge      = 7 * 24 * time.Hour
+	defaultThreadCompactionInterval = 6 * time.Hour
+	defaultThreadCompactionMin      = 10
+
+	// threadDigestMaxLines caps the decisions listed in one digest message.
+	threadDigestMaxLines = 20
+)
+
+// ThreadCompactionConfig configures agent thread compaction.
+type ThreadCompactionConfig struct {
+	Age         time.Duration // compact resolved decisions posted longer ago (default 7d)
+	Interval    time.Duration // pass interval (default 6h)
+	MinMessages int           // per thread; smaller batches wait for a later pass (default 10)
+}
+
+func (c *ThreadCompactionConfig) setDefaults() {
+	if c.Age <= 0 {
+		c.Age = defaultThreadCompactionAge
+	}
+	if c.Interval <= 0 {
+		c.Interval = defaultThreadCompactionInterval
+	}
+	if c.MinMessages <= 0 {
+		c.MinMessages = defaultThreadCompactionMin
+	}
+}
+
+// RunThreadCompaction compacts agent threads every interval until ctx is
+// cancelled. Only the leader should run it.
+func (b *Bot) RunThreadCompaction(ctx context.Context, cfg ThreadCompactionConfig) {
+	cfg.setDefaults()
+	compact := func() {
+		n, err := b.CompactAgentThreads(ctx, cfg)
+		if err != nil {
+			b.logger.Warn("agent thread compaction failed", "error", err)
+		}
+		if n > 0 {
+			b.logger.Info("compacted agent threads", "messages", n)
+		}
+	}
+
+	compact()
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			compact()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// resolvedMessage is a resolved decision message awaiting compaction.
+type resolvedMessage struct {
+	beadID string
+	ref    MessageRef
+}
+
+// CompactAgentThreads keeps agent threads small: in each thread with at
+// least MinMessages resolved-decision messages older than Age, the messages
+// are replaced by a single digest reply. Each message's content is archived
+// on its decision bead (slack_archive) before the message is deleted.
+// It returns the number of messages compacted.
+func (b *Bot) CompactAgentThreads(ctx context.Context, cfg ThreadCompactionConfig) (int, error) {
+	if b.state == nil {
+		return 0, nil
+	}
+	cfg.setDefaults()
+	cutoff := time.Now().Add(-cfg.Age)
+
+	byAgent := make(map[string][]resolvedMessage)
+	for id, ref := range b.state.AllResolvedMessages() {
+		if slackTSTime(ref.Timestamp).After(cutoff) {
+			continue
+		}
+		byAgent[ref.Agent] = append(byAgent[ref.Agent], resolvedMessage{beadID: id, ref: ref})
+	}
+
+	agents := make([]string, 0, len(byAgent))
+	for agent := range byAgent {
+		agents = append(agents, agent)
+	}
+	sort.Strings(agents)
+
+	total := 0
+	for _, agent := range agents {
+		msgs := byAgent[agent]
+		if len(msgs) < cfg.MinMessages {
+			continue
+		}
+		sort.Slice(msgs, func(i, j int) bool { return msgs[i].ref.Timestamp < msgs[j].ref.Timestamp })
+		n, err := b.compactAgentThread(ctx, agent, msgs)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("compact thread of %s: %w", agent, err)
+		}
+	}
+	return total, nil
+}
+
+// compactAgentThread archives and deletes msgs, all resolved decisions of
+// one agent, and posts a digest of them to each thread they were in.
+func (b *Bot) compactAgentThread(ctx context.Context, agent string, msgs []resolvedMessage) (int, error) {
+	// Read each thread once; conversations.replies accepts the ts of any
+	// message in a thread.
+	posted := make(map[string]slack.Message) // message ts → message
+	for _, m := range msgs {
+		if _, ok := posted[m.ref.Timestamp]; ok {
+			continue
+		}
+		thread, err := b.threadMessages(ctx, m.ref.ChannelID, m.ref.Timestamp)
+		if err != nil {
+			return 0, err
+		}
+		for _, tm := range thread {
+			posted[tm.Timestamp] = tm
+		}
+	}
+
+	type digest struct {
+		channelID string
+		lines     []string
+	}
+	digests := make(map[string]*digest) // thread ts → digest
+	var threads []string
+	var done []string
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, m := range msgs {
+		msg, ok := posted[m.ref.Timestamp]
+		if !ok {
+			// Deleted from Slack already; nothing left to compact.
+			done = append(done, m.beadID)
+			continue
+		}
+		if err := b.daemon.UpdateBeadFields(ctx, m.beadID, map[string]string{
+			"slack_archive":     slackMessageContent(msg),
+			"slack_archived_at": now,
+		}); err != nil {
+			b.logger.Warn("failed to archive decision message", "bead", m.beadID, "error", err)
+			continue
+		}
+		if _, _, err := b.api.DeleteMessageContext(ctx, m.ref.ChannelID, m.ref.Timestamp); err != nil {
+			b.logger.Warn("failed to delete compacted decision message", "bead", m.beadID, "error", err)
+			continue
+		}
+		done = append(done, m.beadID)
+
+		threadTS := msg.ThreadTimestamp
+		d, ok := digests[threadTS]
+		if !ok {
+			d = &digest{channelID: m.ref.ChannelID}
+			digests[threadTS] = d
+			threads = append(threads, threadTS)
+		}
+		d.lines = append(d.lines, b.digestLine(ctx, m.beadID))
+	}
+
+	compacted := 0
+	for _, threadTS := range threads {
+		d := digests[threadTS]
+		compacted += len(d.lines)
+		if err := b.postThreadDigest(ctx, d.channelID, threadTS, d.lines); err != nil {
+			b.logger.Warn("failed to post thread digest", "agent", agent, "error", err)
+		}
+	}
+
+	b.mu.Lock()
+	for _, id := range done {
+		delete(b.messages, id) // PostReport must not thread under a deleted message
+	}
+	b.mu.Unlock()
+	if len(done) > 0 {
+		if err := b.state.RemoveResolvedMessages(done...); err != nil {
+			return compacted, err
+		}
+	}
+	return compacted, nil
+}
+
+// threadMessages returns every message of the thread containing ts.
+func (b *Bot) threadMessages(ctx context.Context, channelID, ts string) ([]slack.Message, error) {
+	var out []slack.Message
+	params := &slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: ts, Limit: 200}
+	for {
+		msgs, hasMore, cursor, err := b.api.GetConversationRepliesContext(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, msgs...)
+		if !hasMore || cursor == "" {
+			return out, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+// digestLine describes one compacted decision: its title and the choice.
+func (b *Bot) digestLine(ctx context.Context, beadID string) string {
+	bead, err := b.daemon.GetBead(ctx, beadID)
+	if err != nil {
+		return "• " + beadID
+	}
+	line := "• " + beadTitle(bead.ID, bead.Title)
+	if chosen := bead.Fields["chosen"]; chosen != "" {
+		line += " → " + chosen
+	}
+	return line
+}
+
+// postThreadDigest posts the digest replacing compacted messages as a reply
+// in the thread.
+func (b *Bot) postThreadDigest(ctx context.Context, channelID, threadTS string, lines []string) error {
+	locale := b.locales.LocaleFor(channelID)
+	title := Tr(locale, "thread.compacted", len(lines))
+	shown := lines
+	if len(shown) > threadDigestMaxLines {
+		shown = shown[:threadDigestMaxLines]
+	}
+	text := ":package: *" + title + "*\n" + strings.Join(shown, "\n")
+	if more := len(lines) - len(shown); more > 0 {
+		text += "\n_" + Tr(locale, "thread.more", more) + "_"
+	}
+	_, _, err := b.api.PostMessageContext(ctx, channelID,
+		slack.MsgOptionText(title, false),
+		slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil),
+		),
+		slack.MsgOptionTS(threadTS),
+	)
+	return err
+}
+
+// slackMessageContent returns the text of a message's section and context
+// blocks, or its fallback text when it has none.
+func slackMessageContent(m slack.Message) string {
+	var parts []string
+	for _, block := range m.Blocks.BlockSet {
+		switch blk := block.(type) {
+		case *slack.SectionBlock:
+			if blk.Text != nil {
+				parts = append(parts, blk.Text.Text)
+			}
+		case *slack.ContextBlock:
+			for _, el := range blk.ContextElements.Elements {
+				if t, ok := el.(*slack.TextBlockObject); ok {
+					parts = append(parts, t.Text)
+				}
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return m.Text
+	}
+	return strings.Join(parts, "\n")
+}
+
+// slackTSTime converts a Slack message timestamp ("1700000000.000100") to
+// the time it was posted.
+func slackTSTime(ts string) time.Time {
+	sec, _, _ := strings.Cut(ts, ".")
+	n, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(n, 0)
+}
@@ -0,0 +1,142 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// fakeThreadSlack serves one agent thread over conversations.replies and
+// records deletes and posts.
+type fakeThreadSlack struct {
+	mu      sync.Mutex
+	thread  []map[string]any
+	deleted []string
+	posts   []string // "<thread_ts> <blocks>"
+}
+
+func (f *fakeThreadSlack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := map[string]any{"ok": true, "channel": "C1", "ts": "1800000000.000001"}
+	switch strings.TrimPrefix(r.URL.Path, "/") {
+	case "conversations.replies":
+		resp["messages"] = f.thread
+	case "chat.delete":
+		f.deleted = append(f.deleted, r.Form.Get("ts"))
+	case "chat.postMessage":
+		f.posts = append(f.posts, r.Form.Get("thread_ts")+" "+r.Form.Get("blocks"))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newCompactionTest(t *testing.T, old int) (*Bot, *fakeThreadSlack, *mockDaemon, *StateManager) {
+	t.Helper()
+	fake := &fakeThreadSlack{thread: []map[string]any{{"ts": "1000000000.000000", "text": "card"}}}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	state, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon := newMockDaemon()
+	for i := 1; i <= old; i++ {
+		id := fmt.Sprintf("dec-%d", i)
+		ts := fmt.Sprintf("1000000000.00000%d", i)
+		daemon.beads[id] = &beadsapi.BeadDetail{ID: id, Title: "Q" + id, Fields: map[string]string{"chosen": "yes"}}
+		fake.thread = append(fake.thread, map[string]any{
+			"ts": ts, "thread_ts": "1000000000.000000", "text": "fallback",
+			"blocks": []map[string]any{{
+				"type": "section",
+				"text": map[string]any{"type": "mrkdwn", "text": ":white_check_mark: *Resolved*: yes " + id},
+			}},
+		})
+		if err := state.SetResolvedMessage(id, MessageRef{ChannelID: "C1", Timestamp: ts, Agent: "bot"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A recent resolution stays in the thread.
+	recent := fmt.Sprintf("%d.000000", time.Now().Unix())
+	if err := state.SetResolvedMessage("dec-new", MessageRef{ChannelID: "C1", Timestamp: recent, Agent: "bot"}); err != nil {
+		t.Fatal(err)
+	}
+
+	bot := newTestBot(daemon, srv)
+	bot.state = state
+	return bot, fake, daemon, state
+}
+
+func TestCompactAgentThreads_ReplacesOldResolvedWithDigest(t *testing.T) {
+	bot, fake, daemon, state := newCompactionTest(t, 3)
+
+	n, err := bot.CompactAgentThreads(context.Background(), ThreadCompactionConfig{MinMessages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("compacted %d messages, want 3", n)
+	}
+	if len(fake.deleted) != 3 {
+		t.Errorf("deleted %q, want the 3 old messages", fake.deleted)
+	}
+	if len(fake.posts) != 1 {
+		t.Fatalf("posts = %q, want one digest", fake.posts)
+	}
+	digest := fake.posts[0]
+	if !strings.HasPrefix(digest, "1000000000.000000 ") {
+		t.Errorf("digest %q not posted in the agent thread", digest)
+	}
+	for _, want := range []string{"3 resolved decisions compacted", "Qdec-1 → yes", "Qdec-3 → yes"} {
+		if !strings.Contains(digest, want) {
+			t.Errorf("digest %q lacks %q", digest, want)
+		}
+	}
+
+	if got := daemon.beads["dec-2"].Fields["slack_archive"]; got != ":white_check_mark: *Resolved*: yes dec-2" {
+		t.Errorf("archived content = %q", got)
+	}
+	if daemon.beads["dec-2"].Fields["slack_archived_at"] == "" {
+		t.Error("slack_archived_at not set")
+	}
+
+	left := state.AllResolvedMessages()
+	if _, ok := left["dec-new"]; !ok || len(left) != 1 {
+		t.Errorf("resolved refs left = %v, want only dec-new", left)
+	}
+}
+
+func TestCompactAgentThreads_WaitsForMinMessages(t *testing.T) {
+	bot, fake, _, state := newCompactionTest(t, 2)
+
+	n, err := bot.CompactAgentThreads(context.Background(), ThreadCompactionConfig{MinMessages: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 || len(fake.deleted) != 0 || len(fake.posts) != 0 {
+		t.Errorf("compacted %d (deleted %q, posts %q), want nothing below the minimum", n, fake.deleted, fake.posts)
+	}
+	if got := len(state.AllResolvedMessages()); got != 3 {
+		t.Errorf("resolved refs = %d, want all 3 kept", got)
+	}
+}
+
+func TestSlackTSTime(t *testing.T) {
+	if got := slackTSTime("1700000000.000100"); !got.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("slackTSTime = %v", got)
+	}
+	if got := slackTSTime("bogus"); !got.IsZero() {
+		t.Errorf("slackTSTime(bogus) = %v, want zero", got)
+	}
+}
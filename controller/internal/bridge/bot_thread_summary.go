@@ -0,0 +1,112 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// threadSummaryMinReplies is the reply count at which a resolved decision's
+// discussion is summarized back to the parent channel. Shorter threads only
+// get their permalink recorded on the bead.
+const threadSummaryMinReplies = 5
+
+// threadStats describes the discussion thread under a decision message.
+type threadStats struct {
+	Replies      int
+	Participants int
+}
+
+// summarizeDecisionThread records the decision thread permalink on the bead
+// and, for long discussions, posts a compact summary to the parent channel.
+// Decisions that were themselves posted as thread replies (agent threading)
+// have no discussion thread of their own and are skipped. Runs at most once
+// per decision.
+func (b *Bot) summarizeDecisionThread(ctx context.Context, beadID, chosen, rationale, channelID, messageTS string) {
+	b.mu.Lock()
+	if b.threadSummarized == nil {
+		b.threadSummarized = make(map[string]bool)
+	}
+	if b.threadSummarized[beadID] {
+		b.mu.Unlock()
+		return
+	}
+	b.threadSummarized[beadID] = true
+	b.mu.Unlock()
+
+	stats, ok, err := b.decisionThreadStats(ctx, channelID, messageTS)
+	if err != nil {
+		b.logger.Warn("failed to read decision thread", "bead", beadID, "error", err)
+		return
+	}
+	if !ok || stats.Replies == 0 {
+		return
+	}
+
+	permalink, err := b.api.GetPermalinkContext(ctx, &slack.PermalinkParameters{Channel: channelID, Ts: messageTS})
+	if err != nil {
+		b.logger.Warn("failed to get decision thread permalink", "bead", beadID, "error", err)
+		return
+	}
+	if err := b.daemon.UpdateBeadFields(ctx, beadID, map[string]string{"thread_permalink": permalink}); err != nil {
+		b.logger.Warn("failed to record thread permalink on decision", "bead", beadID, "error", err)
+	}
+
+	if stats.Replies < threadSummaryMinReplies {
+		return
+	}
+
+	locale := b.locales.LocaleFor(channelID)
+	text := fmt.Sprintf(":memo: *%s:* %s", Tr(locale, "thread.summary_title"), chosen)
+	if rationale != "" {
+		text += fmt.Sprintf("\n_%s_", rationale)
+	}
+	text += fmt.Sprintf("\n%s · <%s|%s>",
+		Tr(locale, "thread.summary_stats", stats.Replies, stats.Participants), permalink, Tr(locale, "thread.view"))
+
+	_, _, err = b.api.PostMessageContext(ctx, channelID,
+		slack.MsgOptionText(fmt.Sprintf("%s: %s", Tr(locale, "thread.summary_title"), chosen), false),
+		slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", text, false, false), nil, nil),
+		),
+	)
+	if err != nil {
+		b.logger.Warn("failed to post decision thread summary", "bead", beadID, "error", err)
+		return
+	}
+	b.logger.Info("posted decision thread summary",
+		"bead", beadID, "replies", stats.Replies, "participants", stats.Participants)
+}
+
+// decisionThreadStats counts replies and distinct human participants under
+// a message. ok is false when the message is itself a reply in another thread.
+func (b *Bot) decisionThreadStats(ctx context.Context, channelID, messageTS string) (threadStats, bool, error) {
+	var stats threadStats
+	participants := make(map[string]bool)
+	params := &slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: messageTS, Limit: 200}
+	for {
+		msgs, hasMore, cursor, err := b.api.GetConversationRepliesContext(ctx, params)
+		if err != nil {
+			return stats, false, err
+		}
+		for _, m := range msgs {
+			if m.Timestamp == messageTS {
+				if m.ThreadTimestamp != "" && m.ThreadTimestamp != messageTS {
+					return stats, false, nil
+				}
+				continue
+			}
+			stats.Replies++
+			if m.User != "" && m.User != b.botUserID && m.BotID == "" {
+				participants[m.User] = true
+			}
+		}
+		if !hasMore || cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+	stats.Participants = len(participants)
+	return stats, true, nil
+}
@@ -0,0 +1,116 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// threadSlackServer fakes the Slack endpoints used by thread summarization.
+type threadSlackServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	replies []map[string]any
+	posts   []string // chat.postMessage blocks payloads
+}
+
+func newThreadSlackServer(t *testing.T, parentTS string, threadTS string, users ...string) *threadSlackServer {
+	t.Helper()
+	s := &threadSlackServer{}
+	parent := map[string]any{"ts": parentTS, "user": "UBOT"}
+	if threadTS != "" {
+		parent["thread_ts"] = threadTS
+	}
+	s.replies = append(s.replies, parent)
+	for i, u := range users {
+		s.replies = append(s.replies, map[string]any{"ts": fmt.Sprintf("%s%d", parentTS, i+1), "user": u})
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "conversations.replies"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "messages": s.replies})
+		case strings.HasSuffix(r.URL.Path, "chat.getPermalink"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "permalink": "https://slack.test/p" + r.Form.Get("message_ts")})
+		case strings.HasSuffix(r.URL.Path, "chat.postMessage"):
+			s.mu.Lock()
+			s.posts = append(s.posts, r.Form.Get("blocks"))
+			s.mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "ts": "999.1"})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *threadSlackServer) getPosts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.posts...)
+}
+
+func TestSummarizeDecisionThread_LongDiscussion(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["dec-1"] = &beadsapi.BeadDetail{ID: "dec-1", Type: "decision"}
+	srv := newThreadSlackServer(t, "100.1", "100.1", "U1", "U2", "U1", "U3", "U2", "UBOT")
+	bot := newTestBot(daemon, srv.Server)
+	bot.botUserID = "UBOT"
+
+	bot.updateMessageResolved(context.Background(), "dec-1", "Approve", "Chosen by @ana via Slack", "C1", "100.1")
+	// A second resolution path (SSE close) must not post again.
+	bot.updateMessageResolved(context.Background(), "dec-1", "Approve", "", "C1", "100.1")
+
+	if got := daemon.beads["dec-1"].Fields["thread_permalink"]; got != "https://slack.test/p100.1" {
+		t.Errorf("expected permalink recorded, got %q", got)
+	}
+	posts := srv.getPosts()
+	if len(posts) != 1 {
+		t.Fatalf("expected 1 summary post, got %d", len(posts))
+	}
+	for _, want := range []string{"Approve", "6 replies", "3 participants", "View thread"} {
+		if !strings.Contains(posts[0], want) {
+			t.Errorf("summary missing %q: %s", want, posts[0])
+		}
+	}
+}
+
+func TestSummarizeDecisionThread_ShortThreadOnlyRecordsPermalink(t *testing.T) {
+	daemon := newMockDaemon()
+	srv := newThreadSlackServer(t, "100.1", "100.1", "U1")
+	bot := newTestBot(daemon, srv.Server)
+
+	bot.summarizeDecisionThread(context.Background(), "dec-1", "Approve", "", "C1", "100.1")
+
+	if daemon.beads["dec-1"].Fields["thread_permalink"] == "" {
+		t.Error("expected permalink recorded for short thread")
+	}
+	if len(srv.getPosts()) != 0 {
+		t.Error("expected no summary post for short thread")
+	}
+}
+
+func TestSummarizeDecisionThread_SkipsThreadedDecision(t *testing.T) {
+	daemon := newMockDaemon()
+	// Decision message is itself a reply under an agent card (thread_ts differs).
+	srv := newThreadSlackServer(t, "200.1", "100.0", "U1", "U2", "U3", "U4", "U5")
+	bot := newTestBot(daemon, srv.Server)
+
+	bot.summarizeDecisionThread(context.Background(), "dec-1", "Approve", "", "C1", "200.1")
+
+	if _, ok := daemon.beads["dec-1"]; ok {
+		t.Error("expected no bead update for threaded decision")
+	}
+	if len(srv.getPosts()) != 0 {
+		t.Error("expected no summary post for threaded decision")
+	}
+}
@@ -0,0 +1,73 @@
+// Package bridge provides the budget alert watcher.
+//
+// BudgetAlerts subscribes to kbeads SSE event stream for bead create events,
+// filters for controller.budget bus events (event beads published when a
+// project crosses its daily soft or hard API budget), and posts one alert
+// per crossing.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// BudgetAlertNotifier posts project budget alerts.
+type BudgetAlertNotifier interface {
+	NotifyBudgetAlert(ctx context.Context, alert beadsapi.BudgetAlert) error
+}
+
+// BudgetAlertsConfig holds configuration for the BudgetAlerts watcher.
+type BudgetAlertsConfig struct {
+	Notifier BudgetAlertNotifier // nil = no notifications
+	Logger   *slog.Logger
+}
+
+// BudgetAlerts watches the kbeads SSE event stream for budget alert events.
+type BudgetAlerts struct {
+	notifier BudgetAlertNotifier
+	logger   *slog.Logger
+}
+
+// NewBudgetAlerts creates a new budget alert watcher.
+func NewBudgetAlerts(cfg BudgetAlertsConfig) *BudgetAlerts {
+	return &BudgetAlerts{
+		notifier: cfg.Notifier,
+		logger:   cfg.Logger,
+	}
+}
+
+// RegisterHandlers registers SSE event handlers on the given stream for
+// event bead created events.
+func (b *BudgetAlerts) RegisterHandlers(stream *SSEStream) {
+	stream.On("beads.bead.created", b.handleCreated)
+	b.logger.Info("budget alerts watcher registered SSE handlers",
+		"topics", []string{"beads.bead.created"})
+}
+
+func (b *BudgetAlerts) handleCreated(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	if bead.Type != "event" || bead.Fields["topic"] != beadsapi.BudgetTopic {
+		return
+	}
+
+	var alert beadsapi.BudgetAlert
+	if err := json.Unmarshal([]byte(bead.Fields["payload"]), &alert); err != nil {
+		b.logger.Warn("skipping malformed budget alert", "id", bead.ID, "error", err)
+		return
+	}
+	b.logger.Info("budget alert received",
+		"id", bead.ID, "project", alert.Project, "level", alert.Level, "spent_usd", alert.SpentUSD)
+
+	if b.notifier == nil {
+		return
+	}
+	if err := b.notifier.NotifyBudgetAlert(ctx, alert); err != nil {
+		b.logger.Error("failed to post budget alert", "id", bead.ID, "error", err)
+	}
+}
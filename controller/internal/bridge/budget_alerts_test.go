@@ -0,0 +1,51 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+type mockBudgetAlertNotifier struct {
+	alerts []beadsapi.BudgetAlert
+}
+
+func (m *mockBudgetAlertNotifier) NotifyBudgetAlert(_ context.Context, alert beadsapi.BudgetAlert) error {
+	m.alerts = append(m.alerts, alert)
+	return nil
+}
+
+func TestBudgetAlerts_HandleCreated(t *testing.T) {
+	notif := &mockBudgetAlertNotifier{}
+	b := NewBudgetAlerts(BudgetAlertsConfig{Notifier: notif, Logger: slog.Default()})
+
+	payload, _ := json.Marshal(beadsapi.BudgetAlert{
+		Project: "gasboat", Day: "2026-03-02", Level: beadsapi.BudgetHard, SpentUSD: 51.2, CapUSD: 50,
+	})
+
+	// Other beads and other bus topics are ignored.
+	b.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{ID: "kd-1", Type: "task"}))
+	b.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-2", Type: "event", Fields: map[string]string{"topic": "controller.errors", "payload": string(payload)},
+	}))
+	// Malformed payloads are skipped.
+	b.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-3", Type: "event", Fields: map[string]string{"topic": beadsapi.BudgetTopic, "payload": "{"},
+	}))
+	if len(notif.alerts) != 0 {
+		t.Fatalf("expected no notifications, got %d", len(notif.alerts))
+	}
+
+	b.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-4", Type: "event", Fields: map[string]string{"topic": beadsapi.BudgetTopic, "payload": string(payload)},
+	}))
+	if len(notif.alerts) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notif.alerts))
+	}
+	if a := notif.alerts[0]; a.Project != "gasboat" || a.Level != beadsapi.BudgetHard || a.CapUSD != 50 {
+		t.Errorf("unexpected alert: %+v", a)
+	}
+}
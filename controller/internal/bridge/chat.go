@@ -201,7 +201,7 @@ func (c *Chat) nudgeAgent(ctx context.Context, bead BeadEvent) {
 		return
 	}
 
-	coopURL := beadsapi.ParseNotes(agentBead.Notes)["coop_url"]
+	coopURL := agentCoopURL(ctx, c.daemon, agentBead)
 	if coopURL == "" {
 		return
 	}
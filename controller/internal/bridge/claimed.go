@@ -16,8 +16,6 @@ import (
 	"net/http"
 	"sync"
 	"time"
-
-	"gasboat/controller/internal/beadsapi"
 )
 
 // claimedNudgeTTL is the minimum interval between nudges for the same bead.
@@ -132,7 +130,7 @@ func (c *Claimed) nudgeAgent(ctx context.Context, bead BeadEvent) {
 		return
 	}
 
-	coopURL := beadsapi.ParseNotes(agentBead.Notes)["coop_url"]
+	coopURL := agentCoopURL(ctx, c.daemon, agentBead)
 	if coopURL == "" {
 		c.logger.Warn("agent bead has no coop_url, cannot nudge",
 			"agent", bead.Assignee, "bead", bead.ID)
@@ -0,0 +1,71 @@
+// Package bridge provides coop links backed by short-lived tokens.
+//
+// Links the bridges post to an agent's coop session go through the
+// controller's coop proxy with a token the controller mints for that one
+// agent, instead of exposing the session's in-cluster URL. A posted link
+// stops working when its token expires.
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CoopTokenConfig configures a CoopTokenClient.
+type CoopTokenConfig struct {
+	ControllerURL string // controller health endpoint serving /coop-token
+	PublicURL     string // base URL humans reach the controller's /coop/ proxy at; empty = ControllerURL
+	ClientToken   string // bearer token for /coop-token
+	Subject       string // who tokens are minted for, e.g. "slack-bridge"
+}
+
+// CoopTokenClient mints coop tokens from the controller.
+type CoopTokenClient struct {
+	cfg        CoopTokenConfig
+	httpClient *http.Client
+}
+
+// NewCoopTokenClient returns a client minting tokens with cfg.
+func NewCoopTokenClient(cfg CoopTokenConfig) *CoopTokenClient {
+	if cfg.PublicURL == "" {
+		cfg.PublicURL = cfg.ControllerURL
+	}
+	return &CoopTokenClient{cfg: cfg, httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Link returns a proxy URL opening the coop session of agentBeadID, valid
+// until the token minted for it expires.
+func (c *CoopTokenClient) Link(ctx context.Context, agentBeadID string) (string, error) {
+	body, _ := json.Marshal(map[string]string{"agent": agentBeadID, "subject": c.cfg.Subject})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(c.cfg.ControllerURL, "/")+"/coop-token", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.ClientToken)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("minting coop token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("minting coop token: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var minted struct {
+		Token string `json:"token"`
+		Path  string `json:"path"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&minted); err != nil {
+		return "", fmt.Errorf("decoding coop token: %w", err)
+	}
+	return strings.TrimRight(c.cfg.PublicURL, "/") + minted.Path + "?token=" + url.QueryEscape(minted.Token), nil
+}
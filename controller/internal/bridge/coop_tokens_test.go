@@ -0,0 +1,70 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestCoopTokenClient_Link(t *testing.T) {
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coop-token" || r.Header.Get("Authorization") != "Bearer client-secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"token": "v1.tok+" + req["agent"] + "." + req["subject"],
+			"path":  "/coop/" + req["agent"] + "/",
+		})
+	}))
+	defer controller.Close()
+
+	client := NewCoopTokenClient(CoopTokenConfig{
+		ControllerURL: controller.URL,
+		PublicURL:     "https://gasboat.example.com/",
+		ClientToken:   "client-secret",
+		Subject:       "slack-bridge",
+	})
+	link, err := client.Link(context.Background(), "kd-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://gasboat.example.com/coop/kd-a/?token=v1.tok%2Bkd-a.slack-bridge"; link != want {
+		t.Errorf("link = %q, want %q", link, want)
+	}
+
+	bad := NewCoopTokenClient(CoopTokenConfig{ControllerURL: controller.URL, ClientToken: "wrong"})
+	if _, err := bad.Link(context.Background(), "kd-a"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("rejected client: err = %v", err)
+	}
+}
+
+func TestJackView_CoopLinkThroughProxy(t *testing.T) {
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "v1.t.s", "path": "/coop/a-1/"})
+	}))
+	defer controller.Close()
+
+	daemon := newMockDaemon()
+	daemon.beads["bot"] = &beadsapi.BeadDetail{ID: "a-1", Notes: "coop_url: http://coop.bot:8080"}
+	daemon.beads["idle"] = &beadsapi.BeadDetail{ID: "a-2"}
+	bot := newTestBot(daemon, controller)
+	bot.coopTokens = NewCoopTokenClient(CoopTokenConfig{ControllerURL: controller.URL, ClientToken: "c"})
+
+	ctx := context.Background()
+	view := bot.jackView(ctx, BeadEvent{ID: "j-1", Type: "jack", Assignee: "bot"}, "C1")
+	if view.CoopURL != controller.URL+"/coop/a-1/?token=v1.t.s" {
+		t.Errorf("coop link = %q, want the proxy link", view.CoopURL)
+	}
+	// No session, no link and no token.
+	if view := bot.jackView(ctx, BeadEvent{ID: "j-2", Type: "jack", Assignee: "idle"}, "C1"); view.CoopURL != "" {
+		t.Errorf("coop link without a session = %q", view.CoopURL)
+	}
+}
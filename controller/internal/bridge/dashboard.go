@@ -32,6 +32,8 @@ type DashboardConfig struct {
 	MaxIdleShown      int // Max idle agents to display (default 5).
 	MaxDeadShown      int // Max dead agents to display (default 5).
 	MaxDecisionsShown int // Max pending decisions to display (default 5).
+
+	Layout DashboardLayout // Columns, sort order and project filter.
 }
 
 // dashboardMessage tracks the posted dashboard message for later updates.
@@ -54,6 +56,11 @@ type Dashboard struct {
 	logger *slog.Logger
 	cfg    DashboardConfig
 
+	// perChannel dashboards come from the dashboards config and persist
+	// their message ref by channel; the default dashboard uses the single
+	// legacy ref.
+	perChannel bool
+
 	mu          sync.Mutex
 	msg         *dashboardMessage
 	lastUpdate  time.Time
@@ -117,6 +124,35 @@ func (d *Dashboard) MarkDirty() {
 	d.mu.Unlock()
 }
 
+// SetLayout replaces the dashboard layout; the message is re-rendered on
+// the next cycle.
+func (d *Dashboard) SetLayout(layout DashboardLayout) {
+	d.mu.Lock()
+	d.cfg.Layout = layout
+	d.dirty = true
+	if d.msg != nil {
+		d.msg.LastHash = ""
+	}
+	d.mu.Unlock()
+}
+
+// getRef returns the persisted message ref of this dashboard.
+func (d *Dashboard) getRef() (*DashboardRef, bool) {
+	if d.perChannel {
+		return d.state.GetChannelDashboard(d.cfg.ChannelID)
+	}
+	return d.state.GetDashboard()
+}
+
+// setRef persists the message ref of this dashboard.
+func (d *Dashboard) setRef(ref DashboardRef) {
+	if d.perChannel {
+		_ = d.state.SetChannelDashboard(d.cfg.ChannelID, ref)
+		return
+	}
+	_ = d.state.SetDashboard(ref)
+}
+
 // RegisterHandlers registers SSE event handlers to mark the dashboard dirty
 // on agent lifecycle events (create/close/update of agent beads).
 func (d *Dashboard) RegisterHandlers(stream *SSEStream) {
@@ -149,12 +185,30 @@ func (d *Dashboard) buildBlocks(ctx context.Context) ([]slack.Block, string, err
 		return nil, "", fmt.Errorf("listing decisions: %w", err)
 	}
 
-	blocks, hash := d.renderBlocks(agents, decisions)
+	d.mu.Lock()
+	layout := d.cfg.Layout
+	d.mu.Unlock()
+	agents, decisions = layout.filter(agents, decisions)
+
+	// Task titles cost a daemon call per agent; fetch them only when shown.
+	var tasks map[string]string
+	if layout.hasColumn(DashboardColumnTask) {
+		tasks = make(map[string]string, len(agents))
+		for _, a := range agents {
+			if task, err := d.daemon.ListAssignedTask(ctx, a.AgentName); err == nil && task != nil {
+				tasks[a.ID] = task.Title
+			}
+		}
+	}
+
+	blocks, hash := d.renderBlocks(agents, decisions, tasks)
 	return blocks, hash, nil
 }
 
-func (d *Dashboard) renderBlocks(agents []beadsapi.AgentBead, decisions []*beadsapi.BeadDetail) ([]slack.Block, string) {
+func (d *Dashboard) renderBlocks(agents []beadsapi.AgentBead, decisions []*beadsapi.BeadDetail, tasks map[string]string) ([]slack.Block, string) {
+	d.mu.Lock()
 	cfg := d.cfg
+	d.mu.Unlock()
 	if cfg.MaxWorkingShown == 0 {
 		cfg.MaxWorkingShown = 10
 	}
@@ -181,21 +235,34 @@ func (d *Dashboard) renderBlocks(agents []beadsapi.AgentBead, decisions []*beads
 		}
 	}
 
-	// Sort: working by project/name, idle by name, dead by name.
-	sort.Slice(working, func(i, j int) bool {
-		if working[i].Project != working[j].Project {
-			return working[i].Project < working[j].Project
+	cols := newDashboardColumns(cfg.Layout, decisions, tasks)
+
+	// Sort: working by project/name, idle by name, dead by name, unless the
+	// layout sets an order.
+	if cfg.Layout.Sort == "" {
+		sort.Slice(working, func(i, j int) bool {
+			if working[i].Project != working[j].Project {
+				return working[i].Project < working[j].Project
+			}
+			return working[i].AgentName < working[j].AgentName
+		})
+		sort.Slice(idle, func(i, j int) bool { return idle[i].AgentName < idle[j].AgentName })
+		sort.Slice(dead, func(i, j int) bool { return dead[i].AgentName < dead[j].AgentName })
+	} else {
+		for _, list := range [][]beadsapi.AgentBead{working, idle, dead} {
+			cols.sort(list, cfg.Layout.Sort)
 		}
-		return working[i].AgentName < working[j].AgentName
-	})
-	sort.Slice(idle, func(i, j int) bool { return idle[i].AgentName < idle[j].AgentName })
-	sort.Slice(dead, func(i, j int) bool { return dead[i].AgentName < dead[j].AgentName })
+	}
 
 	var blocks []slack.Block
 
 	// Header.
+	header := dashboardMarker
+	if len(cfg.Layout.Projects) > 0 {
+		header += " · " + strings.Join(cfg.Layout.Projects, ", ")
+	}
 	headerText := fmt.Sprintf("%s · %d agents · Updated %s",
-		dashboardMarker, len(agents), time.Now().UTC().Format("15:04 UTC"))
+		header, len(agents), time.Now().UTC().Format("15:04 UTC"))
 	blocks = append(blocks, slack.NewSectionBlock(
 		slack.NewTextBlockObject("mrkdwn", headerText, false, false), nil, nil))
 
@@ -219,7 +286,7 @@ func (d *Dashboard) renderBlocks(agents []beadsapi.AgentBead, decisions []*beads
 			shown = shown[:cfg.MaxWorkingShown]
 		}
 		for _, a := range shown {
-			blocks = append(blocks, dashboardAgentWorkingBlock(a))
+			blocks = append(blocks, dashboardAgentWorkingBlock(a, cols))
 		}
 		if overflow := len(working) - cfg.MaxWorkingShown; overflow > 0 {
 			blocks = append(blocks, slack.NewContextBlock("",
@@ -239,7 +306,7 @@ func (d *Dashboard) renderBlocks(agents []beadsapi.AgentBead, decisions []*beads
 			shown = shown[:cfg.MaxIdleShown]
 		}
 		for _, a := range shown {
-			blocks = append(blocks, dashboardAgentIdleBlock(a))
+			blocks = append(blocks, dashboardAgentIdleBlock(a, cols))
 		}
 		if overflow := len(idle) - cfg.MaxIdleShown; overflow > 0 {
 			blocks = append(blocks, slack.NewContextBlock("",
@@ -259,7 +326,7 @@ func (d *Dashboard) renderBlocks(agents []beadsapi.AgentBead, decisions []*beads
 			shown = shown[:cfg.MaxDeadShown]
 		}
 		for _, a := range shown {
-			blocks = append(blocks, dashboardAgentDeadBlock(a))
+			blocks = append(blocks, dashboardAgentDeadBlock(a, cols))
 		}
 		if overflow := len(dead) - cfg.MaxDeadShown; overflow > 0 {
 			blocks = append(blocks, slack.NewContextBlock("",
@@ -311,11 +378,11 @@ func (d *Dashboard) renderBlocks(agents []beadsapi.AgentBead, decisions []*beads
 		}
 	}
 
-	hash := buildDashboardHash(agents, decisions)
+	hash := buildDashboardHash(agents, decisions) + cols.hash(agents)
 	return blocks, hash
 }
 
-func dashboardAgentWorkingBlock(a beadsapi.AgentBead) slack.Block {
+func dashboardAgentWorkingBlock(a beadsapi.AgentBead, cols *dashboardColumns) slack.Block {
 	line := fmt.Sprintf(":large_green_circle: *%s*", a.AgentName)
 	if a.Project != "" {
 		line += fmt.Sprintf(" · %s", a.Project)
@@ -323,20 +390,22 @@ func dashboardAgentWorkingBlock(a beadsapi.AgentBead) slack.Block {
 	if a.Role != "" {
 		line += fmt.Sprintf(" (%s/%s)", a.Mode, a.Role)
 	}
+	line += cols.render(a)
 	return slack.NewSectionBlock(
 		slack.NewTextBlockObject("mrkdwn", line, false, false), nil, nil)
 }
 
-func dashboardAgentIdleBlock(a beadsapi.AgentBead) slack.Block {
+func dashboardAgentIdleBlock(a beadsapi.AgentBead, cols *dashboardColumns) slack.Block {
 	line := fmt.Sprintf(":white_circle: *%s*", a.AgentName)
 	if a.Project != "" {
 		line += fmt.Sprintf(" · %s", a.Project)
 	}
+	line += cols.render(a)
 	return slack.NewSectionBlock(
 		slack.NewTextBlockObject("mrkdwn", line, false, false), nil, nil)
 }
 
-func dashboardAgentDeadBlock(a beadsapi.AgentBead) slack.Block {
+func dashboardAgentDeadBlock(a beadsapi.AgentBead, cols *dashboardColumns) slack.Block {
 	line := fmt.Sprintf(":red_circle: *%s*", a.AgentName)
 	if a.Project != "" {
 		line += fmt.Sprintf(" · %s", a.Project)
@@ -346,6 +415,7 @@ func dashboardAgentDeadBlock(a beadsapi.AgentBead) slack.Block {
 		state = a.PodPhase
 	}
 	line += fmt.Sprintf(" · %s", state)
+	line += cols.render(a)
 	return slack.NewSectionBlock(
 		slack.NewTextBlockObject("mrkdwn", line, false, false), nil, nil)
 }
@@ -375,7 +445,7 @@ func buildDashboardHash(agents []beadsapi.AgentBead, decisions []*beadsapi.BeadD
 func (d *Dashboard) initMessage(ctx context.Context) {
 	// Try restore from state file.
 	if d.state != nil {
-		if ref, ok := d.state.GetDashboard(); ok && ref.ChannelID != "" && ref.Timestamp != "" {
+		if ref, ok := d.getRef(); ok && ref.ChannelID != "" && ref.Timestamp != "" {
 			blocks, hash, err := d.buildBlocks(ctx)
 			if err != nil {
 				d.logger.Error("dashboard: init build blocks failed", "error", err)
@@ -409,7 +479,7 @@ func (d *Dashboard) initMessage(ctx context.Context) {
 				d.lastUpdate = time.Now()
 				d.mu.Unlock()
 				if d.state != nil {
-					_ = d.state.SetDashboard(DashboardRef{ChannelID: d.cfg.ChannelID, Timestamp: ts, LastHash: hash})
+					d.setRef(DashboardRef{ChannelID: d.cfg.ChannelID, Timestamp: ts, LastHash: hash})
 				}
 				d.logger.Info("dashboard: recovered pinned message", "channel", d.cfg.ChannelID, "ts", ts)
 				return
@@ -471,7 +541,7 @@ func (d *Dashboard) postNew(ctx context.Context) {
 	d.mu.Unlock()
 
 	if d.state != nil {
-		_ = d.state.SetDashboard(DashboardRef{ChannelID: chID, Timestamp: ts, LastHash: hash})
+		d.setRef(DashboardRef{ChannelID: chID, Timestamp: ts, LastHash: hash})
 	}
 
 	d.logger.Info("dashboard: posted new message", "channel", chID, "ts", ts)
@@ -554,6 +624,6 @@ func (d *Dashboard) refresh(ctx context.Context) {
 	d.mu.Unlock()
 
 	if d.state != nil {
-		_ = d.state.SetDashboard(DashboardRef{ChannelID: msg.ChannelID, Timestamp: msg.Timestamp, LastHash: hash})
+		d.setRef(DashboardRef{ChannelID: msg.ChannelID, Timestamp: msg.Timestamp, LastHash: hash})
 	}
 }
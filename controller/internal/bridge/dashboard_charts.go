@@ -0,0 +1,456 @@
+// Package bridge provides weekly trend charts for the agent dashboard.
+//
+// DashboardCharts uploads simple bar charts built from bead history — tasks
+// closed per day, decision latency and agent utilization — as PNGs to the
+// dashboard channel, giving a glanceable trend without a separate BI tool.
+// The charts are drawn with the standard image packages, so the bridge needs
+// no plotting library; exact figures go in each upload's comment.
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/slack-go/slack"
+)
+
+// Trend chart defaults.
+const (
+	defaultChartsInterval = 7 * 24 * time.Hour
+	defaultChartsDays     = 14
+
+	// chartsCheckInterval is how often Run checks whether an upload is due.
+	chartsCheckInterval = time.Hour
+
+	// chartsPageSize and chartsMaxBeads bound the closed-bead history scan.
+	chartsPageSize = 200
+	chartsMaxBeads = 5000
+)
+
+// DashboardChartsConfig configures the periodic trend chart uploads.
+type DashboardChartsConfig struct {
+	API       *slack.Client
+	Daemon    *beadsapi.Client
+	State     *StateManager // remembers the last upload across restarts; may be nil
+	Logger    *slog.Logger
+	ChannelID string
+	Interval  time.Duration // between uploads (default 7d)
+	Days      int           // whole days shown per chart (default 14)
+}
+
+// DashboardCharts uploads trend charts to the dashboard channel.
+type DashboardCharts struct {
+	cfg DashboardChartsConfig
+
+	lastPosted time.Time // used when there is no state manager
+}
+
+// NewDashboardCharts creates a DashboardCharts uploader.
+func NewDashboardCharts(cfg DashboardChartsConfig) *DashboardCharts {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultChartsInterval
+	}
+	if cfg.Days <= 0 {
+		cfg.Days = defaultChartsDays
+	}
+	return &DashboardCharts{cfg: cfg}
+}
+
+// Run uploads the charts whenever an interval has passed since the last
+// upload, until ctx is cancelled. Only the leader should run it.
+func (c *DashboardCharts) Run(ctx context.Context) {
+	if c.cfg.ChannelID == "" {
+		c.cfg.Logger.Warn("dashboard charts: no channel configured, disabled")
+		return
+	}
+	ticker := time.NewTicker(chartsCheckInterval)
+	defer ticker.Stop()
+	for {
+		if now := time.Now(); now.Sub(c.postedAt()) >= c.cfg.Interval {
+			if err := c.Post(ctx, now); err != nil {
+				c.cfg.Logger.Warn("dashboard charts upload failed", "error", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *DashboardCharts) postedAt() time.Time {
+	if c.cfg.State != nil {
+		return c.cfg.State.GetChartsPostedAt()
+	}
+	return c.lastPosted
+}
+
+// Post builds the charts for the days before now and uploads them to the
+// dashboard channel. A chart that fails to upload is logged and skipped;
+// Post fails only if none could be uploaded.
+func (c *DashboardCharts) Post(ctx context.Context, now time.Time) error {
+	charts, err := c.Collect(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	posted := 0
+	for _, ch := range charts {
+		if err := c.upload(ctx, ch); err != nil {
+			c.cfg.Logger.Warn("failed to upload dashboard chart", "chart", ch.name, "error", err)
+			lastErr = err
+			continue
+		}
+		posted++
+	}
+	if posted == 0 && lastErr != nil {
+		return lastErr
+	}
+
+	c.lastPosted = now
+	if c.cfg.State != nil {
+		if err := c.cfg.State.SetChartsPostedAt(now); err != nil {
+			return fmt.Errorf("record chart upload: %w", err)
+		}
+	}
+	c.cfg.Logger.Info("uploaded dashboard charts", "channel", c.cfg.ChannelID, "charts", posted)
+	return nil
+}
+
+// upload renders ch and uploads it as a PNG with its summary as comment.
+func (c *DashboardCharts) upload(ctx context.Context, ch trendChart) error {
+	data, err := ch.render()
+	if err != nil {
+		return err
+	}
+	_, err = c.cfg.API.UploadFileContext(ctx, slack.UploadFileParameters{
+		Channel:        c.cfg.ChannelID,
+		Reader:         bytes.NewReader(data),
+		FileSize:       len(data),
+		Filename:       ch.name + ".png",
+		Title:          ch.title,
+		AltTxt:         ch.title,
+		InitialComment: ch.summary(),
+	})
+	return err
+}
+
+// Collect builds the trend charts for the Days whole days before now from
+// the daemon's bead history.
+func (c *DashboardCharts) Collect(ctx context.Context, now time.Time) ([]trendChart, error) {
+	days := chartDays(now, c.cfg.Days)
+	closed, err := c.closedSince(ctx, days[0])
+	if err != nil {
+		return nil, err
+	}
+	active, err := c.cfg.Daemon.ListAgentBeads(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list agents: %w", err)
+	}
+
+	var spans []agentSpan
+	for _, a := range active {
+		spans = append(spans, agentSpan{start: a.CreatedAt, end: now})
+	}
+	for _, b := range closed {
+		if b.Type == "agent" {
+			spans = append(spans, agentSpan{start: b.CreatedAt, end: closedTime(b)})
+		}
+	}
+	return buildTrendCharts(days, closed, spans), nil
+}
+
+// closedSince returns closed beads, most recently updated first, down to
+// those last updated before since.
+func (c *DashboardCharts) closedSince(ctx context.Context, since time.Time) ([]*beadsapi.BeadDetail, error) {
+	var out []*beadsapi.BeadDetail
+	for offset := 0; offset < chartsMaxBeads; offset += chartsPageSize {
+		res, err := c.cfg.Daemon.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{
+			Statuses: []string{"closed"},
+			Sort:     "-updated_at",
+			Limit:    chartsPageSize,
+			Offset:   offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list closed beads: %w", err)
+		}
+		for _, b := range res.Beads {
+			if !b.UpdatedAt.IsZero() && b.UpdatedAt.Before(since) {
+				return out, nil
+			}
+			out = append(out, b)
+		}
+		if len(res.Beads) < chartsPageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+// agentSpan is the time an agent was running.
+type agentSpan struct {
+	start, end time.Time
+}
+
+// trendChart is one bar chart: a value per day.
+type trendChart struct {
+	name   string // file name stem
+	title  string
+	detail string // headline figure for the upload comment
+	days   []time.Time
+	values []float64
+	color  color.RGBA
+}
+
+// summary is the upload comment: title, date range and headline figure.
+func (ch trendChart) summary() string {
+	from, to := ch.days[0], ch.days[len(ch.days)-1]
+	return fmt.Sprintf("*%s* · %s – %s · %s", ch.title, from.Format("Jan 2"), to.Format("Jan 2"), ch.detail)
+}
+
+// buildTrendCharts aggregates closed beads and agent run spans into the
+// tasks closed, decision latency and agent utilization charts.
+func buildTrendCharts(days []time.Time, closed []*beadsapi.BeadDetail, spans []agentSpan) []trendChart {
+	tasks := make([]float64, len(days))
+	latencies := make([][]float64, len(days))
+	var allLatencies []float64
+	var totalTasks float64
+	for _, b := range closed {
+		i := dayIndex(days, closedTime(b))
+		if i < 0 {
+			continue
+		}
+		switch {
+		case b.Kind == "issue":
+			tasks[i]++
+			totalTasks++
+		case b.Type == "decision" && !b.CreatedAt.IsZero():
+			m := closedTime(b).Sub(b.CreatedAt).Minutes()
+			latencies[i] = append(latencies[i], m)
+			allLatencies = append(allLatencies, m)
+		}
+	}
+	latency := make([]float64, len(days))
+	for i, l := range latencies {
+		latency[i] = median(l)
+	}
+
+	utilization := agentUtilization(days, spans)
+	var avgAgents float64
+	for _, u := range utilization {
+		avgAgents += u / float64(len(days))
+	}
+
+	return []trendChart{
+		{
+			name:   "tasks-closed",
+			title:  "Tasks closed per day",
+			detail: fmt.Sprintf("%.0f total", totalTasks),
+			days:   days,
+			values: tasks,
+			color:  color.RGBA{0x2e, 0xb6, 0x7d, 0xff},
+		},
+		{
+			name:   "decision-latency",
+			title:  "Decision latency (median minutes to resolve)",
+			detail: fmt.Sprintf("%d decisions, median %s", len(allLatencies), formatMuteDuration(time.Duration(median(allLatencies)*float64(time.Minute)))),
+			days:   days,
+			values: latency,
+			color:  color.RGBA{0xec, 0xb2, 0x2e, 0xff},
+		},
+		{
+			name:   "agent-utilization",
+			title:  "Agent utilization (average running agents)",
+			detail: fmt.Sprintf("%.1f agents on average", avgAgents),
+			days:   days,
+			values: utilization,
+			color:  color.RGBA{0x36, 0xc5, 0xf0, 0xff},
+		},
+	}
+}
+
+// agentUtilization returns, for each day, the average number of agents
+// running: the agent-hours that fall within the day divided by 24.
+func agentUtilization(days []time.Time, spans []agentSpan) []float64 {
+	out := make([]float64, len(days))
+	for i, day := range days {
+		end := day.Add(24 * time.Hour)
+		for _, s := range spans {
+			if s.start.IsZero() {
+				continue
+			}
+			from, to := s.start, s.end
+			if from.Before(day) {
+				from = day
+			}
+			if to.After(end) {
+				to = end
+			}
+			if to.After(from) {
+				out[i] += to.Sub(from).Hours() / 24
+			}
+		}
+	}
+	return out
+}
+
+// chartDays returns the UTC midnight starting each of the n whole days
+// before now, oldest first.
+func chartDays(now time.Time, n int) []time.Time {
+	y, m, d := now.UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	days := make([]time.Time, n)
+	for i := range days {
+		days[i] = today.AddDate(0, 0, i-n)
+	}
+	return days
+}
+
+// dayIndex returns the index of the day in days containing t, or -1.
+func dayIndex(days []time.Time, t time.Time) int {
+	if len(days) == 0 || t.Before(days[0]) {
+		return -1
+	}
+	i := int(t.Sub(days[0]) / (24 * time.Hour))
+	if i >= len(days) {
+		return -1
+	}
+	return i
+}
+
+// closedTime returns when a closed bead was closed, falling back to its
+// last update for daemons that do not report closed_at.
+func closedTime(b *beadsapi.BeadDetail) time.Time {
+	if !b.ClosedAt.IsZero() {
+		return b.ClosedAt
+	}
+	return b.UpdatedAt
+}
+
+func median(vals []float64) float64 {
+	if len(vals) == 0 {
+		return 0
+	}
+	s := append([]float64(nil), vals...)
+	sort.Float64s(s)
+	mid := len(s) / 2
+	if len(s)%2 == 0 {
+		return (s[mid-1] + s[mid]) / 2
+	}
+	return s[mid]
+}
+
+// Chart geometry, in pixels.
+const (
+	chartWidth  = 640
+	chartHeight = 320
+	chartMargin = 24
+	glyphScale  = 2
+	glyphW      = 3 * glyphScale
+	glyphH      = 5 * glyphScale
+)
+
+var (
+	chartAxisColor  = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	chartLabelColor = color.RGBA{0x33, 0x33, 0x33, 0xff}
+)
+
+// render draws the chart as a PNG: a bar per day with its value above and
+// the day of the month below.
+func (ch trendChart) render() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	top := chartMargin + glyphH + 4
+	bottom := chartHeight - chartMargin - glyphH - 6
+	fillRect(img, chartMargin, bottom, chartWidth-chartMargin, bottom+2, chartAxisColor)
+
+	peak := 0.0
+	for _, v := range ch.values {
+		peak = max(peak, v)
+	}
+	slot := (chartWidth - 2*chartMargin) / max(len(ch.values), 1)
+	barW := slot * 2 / 3
+	for i, v := range ch.values {
+		x := chartMargin + i*slot + (slot-barW)/2
+		h := 0
+		if peak > 0 {
+			h = int(v / peak * float64(bottom-top))
+		}
+		fillRect(img, x, bottom-h, x+barW, bottom, ch.color)
+
+		center := x + barW/2
+		drawDigits(img, formatChartValue(v), center, bottom-h-4-glyphH, chartLabelColor)
+		if i < len(ch.days) {
+			drawDigits(img, strconv.Itoa(ch.days[i].Day()), center, bottom+6, chartAxisColor)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode chart: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatChartValue labels a bar: whole numbers, or one decimal below 10.
+func formatChartValue(v float64) string {
+	if v >= 10 || v == float64(int(v)) {
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+func fillRect(img *image.RGBA, x0, y0, x1, y1 int, c color.Color) {
+	draw.Draw(img, image.Rect(x0, y0, x1, y1), &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+// chartGlyphs is a 3x5 bitmap font for bar labels, one row per string.
+var chartGlyphs = map[rune][5]string{
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+}
+
+// drawDigits draws s horizontally centered on cx with its top at y.
+// Characters without a glyph are skipped.
+func drawDigits(img *image.RGBA, s string, cx, y int, c color.Color) {
+	const advance = glyphW + glyphScale
+	x := cx - (len(s)*advance-glyphScale)/2
+	for _, r := range s {
+		glyph, ok := chartGlyphs[r]
+		if !ok {
+			continue
+		}
+		for row, line := range glyph {
+			for col, px := range line {
+				if px == '#' {
+					fillRect(img, x+col*glyphScale, y+row*glyphScale,
+						x+(col+1)*glyphScale, y+(row+1)*glyphScale, c)
+				}
+			}
+		}
+		x += advance
+	}
+}
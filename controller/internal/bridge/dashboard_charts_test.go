@@ -0,0 +1,110 @@
+package bridge
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestChartDays_WholeDaysBeforeNow(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)
+	days := chartDays(now, 3)
+	want := []string{"2026-10-12", "2026-10-13", "2026-10-14"}
+	for i, d := range days {
+		if got := d.Format(time.DateOnly); got != want[i] {
+			t.Errorf("days[%d] = %s, want %s", i, got, want[i])
+		}
+	}
+	if i := dayIndex(days, now); i != -1 {
+		t.Errorf("dayIndex(today) = %d, want -1", i)
+	}
+	if i := dayIndex(days, days[1].Add(23*time.Hour)); i != 1 {
+		t.Errorf("dayIndex = %d, want 1", i)
+	}
+}
+
+func TestBuildTrendCharts(t *testing.T) {
+	days := chartDays(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), 2)
+	d0, d1 := days[0], days[1]
+	closed := []*beadsapi.BeadDetail{
+		{ID: "t1", Kind: "issue", Type: "task", ClosedAt: d0.Add(time.Hour)},
+		{ID: "t2", Kind: "issue", Type: "bug", ClosedAt: d1.Add(time.Hour)},
+		{ID: "t3", Kind: "issue", Type: "task", UpdatedAt: d1.Add(2 * time.Hour)}, // no closed_at
+		{ID: "old", Kind: "issue", Type: "task", ClosedAt: d0.Add(-time.Hour)},
+		{ID: "dec1", Type: "decision", CreatedAt: d0, ClosedAt: d0.Add(10 * time.Minute)},
+		{ID: "dec2", Type: "decision", CreatedAt: d0, ClosedAt: d0.Add(30 * time.Minute)},
+		{ID: "dec3", Type: "decision", CreatedAt: d0, ClosedAt: d0.Add(50 * time.Minute)},
+	}
+	spans := []agentSpan{
+		{start: d0.Add(-time.Hour), end: d1.Add(12 * time.Hour)}, // all of d0, half of d1
+		{start: d1, end: d1.Add(24 * time.Hour)},
+		{end: d1.Add(time.Hour)}, // unknown start: ignored
+	}
+
+	charts := buildTrendCharts(days, closed, spans)
+	if len(charts) != 3 {
+		t.Fatalf("got %d charts, want 3", len(charts))
+	}
+	check := func(ch trendChart, want ...float64) {
+		t.Helper()
+		for i, v := range want {
+			if ch.values[i] != v {
+				t.Errorf("%s: values = %v, want %v", ch.name, ch.values, want)
+				return
+			}
+		}
+	}
+	check(charts[0], 1, 2)
+	check(charts[1], 30, 0)
+	check(charts[2], 1, 1.5)
+
+	if s := charts[0].summary(); s != "*Tasks closed per day* · Oct 13 – Oct 14 · 3 total" {
+		t.Errorf("summary = %q", s)
+	}
+	if !strings.Contains(charts[1].detail, "3 decisions, median 30m") {
+		t.Errorf("latency detail = %q", charts[1].detail)
+	}
+}
+
+func TestTrendChartRender(t *testing.T) {
+	ch := trendChart{
+		name:   "tasks-closed",
+		days:   chartDays(time.Now(), 3),
+		values: []float64{2, 0, 4.5},
+		color:  color.RGBA{0x2e, 0xb6, 0x7d, 0xff},
+	}
+	data, err := ch.render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != chartWidth || b.Dy() != chartHeight {
+		t.Errorf("size = %v", b)
+	}
+
+	// The tallest bar reaches the top of the plot area in the chart color.
+	slot := (chartWidth - 2*chartMargin) / 3
+	x := chartMargin + 2*slot + slot/2
+	y := chartMargin + glyphH + 5
+	r, g, b, _ := img.At(x, y).RGBA()
+	cr, cg, cb, _ := ch.color.RGBA()
+	if r != cr || g != cg || b != cb {
+		t.Errorf("pixel at top of tallest bar = %v, want the bar color", img.At(x, y))
+	}
+}
+
+func TestFormatChartValue(t *testing.T) {
+	for v, want := range map[float64]string{0: "0", 3: "3", 2.46: "2.5", 12.6: "13"} {
+		if got := formatChartValue(v); got != want {
+			t.Errorf("formatChartValue(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
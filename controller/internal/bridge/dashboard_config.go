@@ -0,0 +1,457 @@
+// Package bridge provides dashboard layouts and per-channel dashboards.
+//
+// A dashboard's layout (extra columns, sort order, project filter) and any
+// further dashboards, one per channel, are read from a daemon config entry
+// (default key "slack-bridge:dashboards") and reloaded periodically, so
+// changes apply without a redeploy. Without that entry only the default
+// dashboard (SLACK_DASHBOARD_CHANNEL) runs, with the fixed layout.
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/slack-go/slack"
+)
+
+// defaultDashboardsConfigKey is the daemon config key for dashboard settings.
+const defaultDashboardsConfigKey = "slack-bridge:dashboards"
+
+// Dashboard columns: extra values appended to each agent's roster line.
+const (
+	DashboardColumnState     = "state"     // agent state, or pod phase
+	DashboardColumnTask      = "task"      // title of the claimed task
+	DashboardColumnDecisions = "decisions" // pending decision count
+	DashboardColumnUptime    = "uptime"    // time since the agent bead was created
+	DashboardColumnImage     = "image"     // agent image
+	DashboardColumnNode      = "node"      // node the pod runs on
+)
+
+// Dashboard sort orders, applied within each roster section.
+const (
+	DashboardSortName      = "name"
+	DashboardSortProject   = "project"   // then name
+	DashboardSortUptime    = "uptime"    // longest running first
+	DashboardSortDecisions = "decisions" // most pending decisions first
+)
+
+var (
+	knownDashboardColumns = []string{DashboardColumnState, DashboardColumnTask, DashboardColumnDecisions,
+		DashboardColumnUptime, DashboardColumnImage, DashboardColumnNode}
+	knownDashboardSorts = []string{DashboardSortName, DashboardSortProject, DashboardSortUptime, DashboardSortDecisions}
+)
+
+// DashboardLayout selects what a dashboard shows. The zero value is the
+// fixed layout: no extra columns, working agents by project, the rest by
+// name, every project.
+type DashboardLayout struct {
+	Columns  []string `json:"columns,omitempty"`
+	Sort     string   `json:"sort,omitempty"`
+	Projects []string `json:"projects,omitempty"` // empty = all projects
+}
+
+func (l DashboardLayout) validate() error {
+	for _, c := range l.Columns {
+		if !slices.Contains(knownDashboardColumns, c) {
+			return fmt.Errorf("unknown column %q (want one of %s)", c, strings.Join(knownDashboardColumns, ", "))
+		}
+	}
+	if l.Sort != "" && !slices.Contains(knownDashboardSorts, l.Sort) {
+		return fmt.Errorf("unknown sort %q (want one of %s)", l.Sort, strings.Join(knownDashboardSorts, ", "))
+	}
+	return nil
+}
+
+func (l DashboardLayout) hasColumn(column string) bool {
+	return slices.Contains(l.Columns, column)
+}
+
+// filter keeps the agents and decisions of the layout's projects. A
+// decision belongs to the project in its fields or, failing that, to the
+// project of the agent it is assigned to.
+func (l DashboardLayout) filter(agents []beadsapi.AgentBead, decisions []*beadsapi.BeadDetail) ([]beadsapi.AgentBead, []*beadsapi.BeadDetail) {
+	if len(l.Projects) == 0 {
+		return agents, decisions
+	}
+	agentProject := make(map[string]string, len(agents))
+	var keptAgents []beadsapi.AgentBead
+	for _, a := range agents {
+		agentProject[a.AgentName] = a.Project
+		if slices.Contains(l.Projects, a.Project) {
+			keptAgents = append(keptAgents, a)
+		}
+	}
+	var keptDecisions []*beadsapi.BeadDetail
+	for _, dec := range decisions {
+		project := dec.Fields["project"]
+		if project == "" {
+			project = agentProject[extractAgentName(dec.Assignee)]
+		}
+		if project == "" {
+			project = extractAgentProject(dec.Assignee)
+		}
+		if slices.Contains(l.Projects, project) {
+			keptDecisions = append(keptDecisions, dec)
+		}
+	}
+	return keptAgents, keptDecisions
+}
+
+// DashboardsConfig is the value of the slack-bridge:dashboards config entry.
+//
+// Example:
+//
+//	{"default": {"columns": ["task", "decisions", "uptime"], "sort": "uptime"},
+//	 "dashboards": [{"channel": "C0GASBOAT", "projects": ["gasboat"],
+//	                 "columns": ["state", "image", "node"]}]}
+type DashboardsConfig struct {
+	Default    DashboardLayout          `json:"default"`    // layout of the SLACK_DASHBOARD_CHANNEL dashboard
+	Dashboards []ChannelDashboardConfig `json:"dashboards"` // further dashboards, one per channel
+}
+
+// ChannelDashboardConfig configures a dashboard posted to Channel.
+type ChannelDashboardConfig struct {
+	Channel string `json:"channel"`
+	DashboardLayout
+}
+
+// ParseDashboardsConfig parses and validates a dashboards configuration.
+func ParseDashboardsConfig(data []byte) (DashboardsConfig, error) {
+	var cfg DashboardsConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return DashboardsConfig{}, fmt.Errorf("parse dashboards config: %w", err)
+	}
+	if err := cfg.Default.validate(); err != nil {
+		return DashboardsConfig{}, fmt.Errorf("parse dashboards config: default: %w", err)
+	}
+	seen := make(map[string]bool)
+	for _, d := range cfg.Dashboards {
+		if d.Channel == "" {
+			return DashboardsConfig{}, fmt.Errorf("parse dashboards config: dashboard without channel")
+		}
+		if seen[d.Channel] {
+			return DashboardsConfig{}, fmt.Errorf("parse dashboards config: duplicate channel %q", d.Channel)
+		}
+		seen[d.Channel] = true
+		if err := d.validate(); err != nil {
+			return DashboardsConfig{}, fmt.Errorf("parse dashboards config: %s: %w", d.Channel, err)
+		}
+	}
+	return cfg, nil
+}
+
+// dashboardColumns renders a layout's extra columns for one refresh.
+type dashboardColumns struct {
+	columns []string
+	pending map[string]int    // agent name → pending decisions
+	tasks   map[string]string // agent bead ID → claimed task title
+	now     time.Time
+}
+
+func newDashboardColumns(layout DashboardLayout, decisions []*beadsapi.BeadDetail, tasks map[string]string) *dashboardColumns {
+	c := &dashboardColumns{columns: layout.Columns, pending: make(map[string]int), tasks: tasks, now: time.Now()}
+	for _, dec := range decisions {
+		if dec.Assignee != "" {
+			c.pending[extractAgentName(dec.Assignee)]++
+		}
+	}
+	return c
+}
+
+// value returns the text of column for agent a, or "" when unknown.
+func (c *dashboardColumns) value(a beadsapi.AgentBead, column string) string {
+	switch column {
+	case DashboardColumnState:
+		if a.AgentState != "" {
+			return a.AgentState
+		}
+		return a.PodPhase
+	case DashboardColumnTask:
+		if title := c.tasks[a.ID]; title != "" {
+			return "_" + truncateText(title, 40) + "_"
+		}
+	case DashboardColumnDecisions:
+		if n := c.pending[a.AgentName]; n > 0 {
+			return fmt.Sprintf(":clipboard: %d", n)
+		}
+	case DashboardColumnUptime:
+		if !a.CreatedAt.IsZero() {
+			return "up " + dashboardUptime(c.now.Sub(a.CreatedAt))
+		}
+	case DashboardColumnImage:
+		if img := a.Metadata["image"]; img != "" {
+			return "`" + img[strings.LastIndex(img, "/")+1:] + "`"
+		}
+	case DashboardColumnNode:
+		if node := a.Metadata["pod_node"]; node != "" {
+			return "on " + node
+		}
+	}
+	return ""
+}
+
+// render returns the column values of a, each prefixed with " · ".
+func (c *dashboardColumns) render(a beadsapi.AgentBead) string {
+	var b strings.Builder
+	for _, column := range c.columns {
+		if v := c.value(a, column); v != "" {
+			b.WriteString(" · " + v)
+		}
+	}
+	return b.String()
+}
+
+// hash extends the dashboard content hash with the column values, so a
+// changed value triggers an update.
+func (c *dashboardColumns) hash(agents []beadsapi.AgentBead) string {
+	if len(c.columns) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(agents))
+	for _, a := range agents {
+		parts = append(parts, a.AgentName+c.render(a))
+	}
+	sort.Strings(parts)
+	return "|cols:" + strings.Join(parts, "|")
+}
+
+// sort orders agents by the given sort order, then by name.
+func (c *dashboardColumns) sort(agents []beadsapi.AgentBead, by string) {
+	sort.SliceStable(agents, func(i, j int) bool {
+		a, b := agents[i], agents[j]
+		switch by {
+		case DashboardSortProject:
+			if a.Project != b.Project {
+				return a.Project < b.Project
+			}
+		case DashboardSortUptime:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				if a.CreatedAt.IsZero() || b.CreatedAt.IsZero() {
+					return b.CreatedAt.IsZero()
+				}
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		case DashboardSortDecisions:
+			if pa, pb := c.pending[a.AgentName], c.pending[b.AgentName]; pa != pb {
+				return pa > pb
+			}
+		}
+		return a.AgentName < b.AgentName
+	})
+}
+
+// dashboardUptime renders an uptime in its largest unit: "5m", "3h", "2d".
+func dashboardUptime(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "<1m"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// DashboardConfigClient reads the dashboards configuration from the beads
+// daemon. *beadsapi.Client satisfies it.
+type DashboardConfigClient interface {
+	GetConfig(ctx context.Context, key string) (*beadsapi.ConfigEntry, error)
+}
+
+// DashboardSetConfig configures a DashboardSet.
+type DashboardSetConfig struct {
+	API    *slack.Client
+	Daemon *beadsapi.Client
+	State  *StateManager
+	Logger *slog.Logger
+
+	// Default is the dashboard configured by environment; a disabled one
+	// is left out, but channel dashboards still run.
+	Default DashboardConfig
+
+	Client    DashboardConfigClient // dashboards config source; nil = Default only
+	ConfigKey string                // daemon config key (default "slack-bridge:dashboards")
+	Reload    time.Duration         // config reload interval (default 30s)
+}
+
+// DashboardSet runs the default dashboard and the channel dashboards of the
+// dashboards config, applying config changes as they are reloaded.
+type DashboardSet struct {
+	cfg         DashboardSetConfig
+	defaultDash *Dashboard
+
+	mu       sync.Mutex
+	channels map[string]*channelDashboard
+}
+
+// channelDashboard is a running dashboard from the dashboards config.
+type channelDashboard struct {
+	dash   *Dashboard
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDashboardSet creates a dashboard set; call Run to start it.
+func NewDashboardSet(cfg DashboardSetConfig) *DashboardSet {
+	if cfg.ConfigKey == "" {
+		cfg.ConfigKey = defaultDashboardsConfigKey
+	}
+	if cfg.Reload == 0 {
+		cfg.Reload = 30 * time.Second
+	}
+	s := &DashboardSet{cfg: cfg, channels: make(map[string]*channelDashboard)}
+	if cfg.Default.Enabled {
+		s.defaultDash = NewDashboard(cfg.API, cfg.Daemon, cfg.State, cfg.Logger, cfg.Default)
+	}
+	return s
+}
+
+// RegisterHandlers marks every dashboard dirty on agent and decision events.
+func (s *DashboardSet) RegisterHandlers(stream *SSEStream) {
+	handler := func(_ context.Context, data []byte) {
+		bead := ParseBeadEvent(data)
+		if bead == nil || (bead.Type != "agent" && bead.Type != "decision") {
+			return
+		}
+		if s.defaultDash != nil {
+			s.defaultDash.MarkDirty()
+		}
+		s.mu.Lock()
+		for _, c := range s.channels {
+			c.dash.MarkDirty()
+		}
+		s.mu.Unlock()
+	}
+	stream.On("beads.bead.created", handler)
+	stream.On("beads.bead.closed", handler)
+	stream.On("beads.bead.updated", handler)
+}
+
+// Run runs the dashboards and reloads the config until ctx is cancelled.
+// Dashboard messages are left in place on return so the next leader picks
+// them up.
+func (s *DashboardSet) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	if s.defaultDash != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.defaultDash.Run(ctx)
+		}()
+	}
+
+	s.reload(ctx)
+	ticker := time.NewTicker(s.cfg.Reload)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.reload(ctx)
+		case <-ctx.Done():
+			s.mu.Lock()
+			for channel, c := range s.channels {
+				c.cancel()
+				<-c.done
+				delete(s.channels, channel)
+			}
+			s.mu.Unlock()
+			wg.Wait()
+			return
+		}
+	}
+}
+
+// reload re-reads the dashboards config. A missing entry means no channel
+// dashboards and the fixed layout; on any other error the previous
+// configuration is kept.
+func (s *DashboardSet) reload(ctx context.Context) {
+	if s.cfg.Client == nil {
+		return
+	}
+	var cfg DashboardsConfig
+	entry, err := s.cfg.Client.GetConfig(ctx, s.cfg.ConfigKey)
+	if err != nil {
+		var apiErr *beadsapi.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+			s.cfg.Logger.Warn("failed to read dashboards config, keeping previous",
+				"key", s.cfg.ConfigKey, "error", err)
+			return
+		}
+	} else if cfg, err = ParseDashboardsConfig(entry.Value); err != nil {
+		s.cfg.Logger.Warn("invalid dashboards config, keeping previous",
+			"key", s.cfg.ConfigKey, "error", err)
+		return
+	}
+	s.apply(ctx, cfg)
+}
+
+// apply starts, updates and stops dashboards to match cfg.
+func (s *DashboardSet) apply(ctx context.Context, cfg DashboardsConfig) {
+	if s.defaultDash != nil {
+		s.defaultDash.SetLayout(cfg.Default)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wanted := make(map[string]bool, len(cfg.Dashboards))
+	for _, dc := range cfg.Dashboards {
+		if s.defaultDash != nil && dc.Channel == s.cfg.Default.ChannelID {
+			s.cfg.Logger.Warn("dashboards config: channel already has the default dashboard, skipped",
+				"channel", dc.Channel)
+			continue
+		}
+		wanted[dc.Channel] = true
+		if c, ok := s.channels[dc.Channel]; ok {
+			c.dash.SetLayout(dc.DashboardLayout)
+			continue
+		}
+		dashCfg := s.cfg.Default
+		dashCfg.Enabled = true
+		dashCfg.ChannelID = dc.Channel
+		dashCfg.Layout = dc.DashboardLayout
+		dash := NewDashboard(s.cfg.API, s.cfg.Daemon, s.cfg.State, s.cfg.Logger, dashCfg)
+		dash.perChannel = true
+		dashCtx, cancel := context.WithCancel(ctx)
+		c := &channelDashboard{dash: dash, cancel: cancel, done: make(chan struct{})}
+		go func() {
+			defer close(c.done)
+			dash.Run(dashCtx)
+		}()
+		s.channels[dc.Channel] = c
+		s.cfg.Logger.Info("dashboard started", "channel", dc.Channel, "projects", dc.Projects)
+	}
+
+	for channel, c := range s.channels {
+		if wanted[channel] {
+			continue
+		}
+		c.cancel()
+		<-c.done
+		delete(s.channels, channel)
+		c.dash.mu.Lock()
+		msg := c.dash.msg
+		c.dash.mu.Unlock()
+		if s.cfg.API != nil {
+			c.dash.cleanupOldMessage(msg)
+		}
+		if s.cfg.State != nil {
+			_ = s.cfg.State.RemoveChannelDashboard(channel)
+		}
+		s.cfg.Logger.Info("dashboard removed", "channel", channel)
+	}
+}
@@ -0,0 +1,181 @@
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/slack-go/slack"
+)
+
+func TestParseDashboardsConfig(t *testing.T) {
+	cfg, err := ParseDashboardsConfig([]byte(`{
+		"default": {"columns": ["task", "uptime"], "sort": "uptime"},
+		"dashboards": [{"channel": "C1", "projects": ["gasboat"], "columns": ["node"]}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Default.Sort != DashboardSortUptime || len(cfg.Default.Columns) != 2 {
+		t.Errorf("default layout = %+v", cfg.Default)
+	}
+	if len(cfg.Dashboards) != 1 || cfg.Dashboards[0].Channel != "C1" || cfg.Dashboards[0].Projects[0] != "gasboat" {
+		t.Errorf("dashboards = %+v", cfg.Dashboards)
+	}
+
+	for name, raw := range map[string]string{
+		"unknown column":    `{"default": {"columns": ["cpu"]}}`,
+		"unknown sort":      `{"default": {"sort": "age"}}`,
+		"missing channel":   `{"dashboards": [{"projects": ["a"]}]}`,
+		"duplicate channel": `{"dashboards": [{"channel": "C1"}, {"channel": "C1"}]}`,
+		"unknown field":     `{"dashbaords": []}`,
+	} {
+		if _, err := ParseDashboardsConfig([]byte(raw)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDashboardLayout_FilterByProject(t *testing.T) {
+	agents := []beadsapi.AgentBead{
+		{AgentName: "furiosa", Project: "gasboat"},
+		{AgentName: "nux", Project: "beads"},
+	}
+	decisions := []*beadsapi.BeadDetail{
+		{ID: "d1", Assignee: "gasboat/crew/furiosa"},
+		{ID: "d2", Assignee: "nux"},
+		{ID: "d3", Fields: map[string]string{"project": "gasboat"}},
+	}
+	layout := DashboardLayout{Projects: []string{"gasboat"}}
+	gotAgents, gotDecisions := layout.filter(agents, decisions)
+	if len(gotAgents) != 1 || gotAgents[0].AgentName != "furiosa" {
+		t.Errorf("agents = %+v", gotAgents)
+	}
+	var ids []string
+	for _, d := range gotDecisions {
+		ids = append(ids, d.ID)
+	}
+	if strings.Join(ids, ",") != "d1,d3" {
+		t.Errorf("decisions = %v, want d1,d3", ids)
+	}
+}
+
+// dashboardLines returns the text of the agent lines of rendered blocks.
+func dashboardLines(blocks []slack.Block) []string {
+	var lines []string
+	for _, b := range blocks {
+		if s, ok := b.(*slack.SectionBlock); ok && s.Text != nil && strings.Contains(s.Text.Text, "_circle: *") {
+			lines = append(lines, s.Text.Text)
+		}
+	}
+	return lines
+}
+
+func TestDashboardRender_ColumnsAndSort(t *testing.T) {
+	now := time.Now()
+	d := NewDashboard(nil, nil, nil, slog.Default(), DashboardConfig{
+		ChannelID: "C1",
+		Layout: DashboardLayout{
+			Columns: []string{DashboardColumnTask, DashboardColumnDecisions, DashboardColumnUptime,
+				DashboardColumnImage, DashboardColumnNode},
+			Sort: DashboardSortUptime,
+		},
+	})
+	agents := []beadsapi.AgentBead{
+		{ID: "a-1", AgentName: "alpha", AgentState: "idle", CreatedAt: now.Add(-5 * time.Minute)},
+		{ID: "a-2", AgentName: "zeta", AgentState: "idle", CreatedAt: now.Add(-3 * time.Hour),
+			Metadata: map[string]string{"image": "ghcr.io/groblegark/coop:v1", "pod_node": "node-a"}},
+	}
+	decisions := []*beadsapi.BeadDetail{{ID: "d1", Assignee: "zeta"}}
+	tasks := map[string]string{"a-2": "Fix the flaky test"}
+
+	blocks, hash := d.renderBlocks(agents, decisions, tasks)
+	lines := dashboardLines(blocks)
+	if len(lines) != 2 {
+		t.Fatalf("agent lines = %q", lines)
+	}
+	want := ":white_circle: *zeta* · _Fix the flaky test_ · :clipboard: 1 · up 3h · `coop:v1` · on node-a"
+	if lines[0] != want {
+		t.Errorf("first line = %q, want %q (longest uptime first)", lines[0], want)
+	}
+	if lines[1] != ":white_circle: *alpha* · up 5m" {
+		t.Errorf("second line = %q", lines[1])
+	}
+
+	d.SetLayout(DashboardLayout{})
+	_, plain := d.renderBlocks(agents, decisions, tasks)
+	if plain == hash {
+		t.Error("hash does not change with the column values")
+	}
+}
+
+// fakeDashboardsClient serves a dashboards config entry.
+type fakeDashboardsClient struct{ value string }
+
+func (f *fakeDashboardsClient) GetConfig(_ context.Context, key string) (*beadsapi.ConfigEntry, error) {
+	if f.value == "" {
+		return nil, &beadsapi.APIError{StatusCode: 404}
+	}
+	return &beadsapi.ConfigEntry{Key: key, Value: []byte(f.value)}, nil
+}
+
+func TestDashboardSet_ReloadStartsAndStopsChannelDashboards(t *testing.T) {
+	slackSrv := newFakeSlackServer(t)
+	defer slackSrv.Close()
+	daemon, err := beadsapi.New(beadsapi.Config{HTTPAddr: slackSrv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeDashboardsClient{value: `{"dashboards": [{"channel": "C1"}, {"channel": "C2", "projects": ["gasboat"]}]}`}
+	set := NewDashboardSet(DashboardSetConfig{
+		API:    slack.New("xoxb-test", slack.OptionAPIURL(slackSrv.URL+"/")),
+		Daemon: daemon,
+		Logger: slog.Default(),
+		Client: client,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	channels := func() []string {
+		set.mu.Lock()
+		defer set.mu.Unlock()
+		var out []string
+		for ch := range set.channels {
+			out = append(out, ch)
+		}
+		return out
+	}
+
+	set.reload(ctx)
+	if got := channels(); len(got) != 2 {
+		t.Fatalf("running dashboards = %v, want C1 and C2", got)
+	}
+
+	client.value = `{"dashboards": [{"channel": "C2", "columns": ["node"]}]}`
+	set.reload(ctx)
+	if got := channels(); len(got) != 1 || got[0] != "C2" {
+		t.Fatalf("running dashboards = %v, want only C2", got)
+	}
+	set.mu.Lock()
+	layout := set.channels["C2"].dash.cfg.Layout
+	set.mu.Unlock()
+	if len(layout.Columns) != 1 || len(layout.Projects) != 0 {
+		t.Errorf("C2 layout not updated: %+v", layout)
+	}
+
+	client.value = `{"dashboards": [{"channel": "C3", "columns": ["bogus"]}]}`
+	set.reload(ctx)
+	if got := channels(); len(got) != 1 || got[0] != "C2" {
+		t.Errorf("invalid config replaced dashboards: %v", got)
+	}
+
+	client.value = ""
+	set.reload(ctx)
+	if got := channels(); len(got) != 0 {
+		t.Errorf("running dashboards = %v after the config was deleted", got)
+	}
+}
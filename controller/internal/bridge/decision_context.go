@@ -0,0 +1,75 @@
+package bridge
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Decision context attachment kinds.
+const (
+	ContextText = "text"
+	ContextDiff = "diff"
+	ContextLog  = "log"
+	ContextLink = "link"
+)
+
+// DecisionContext is one piece of background a decision bead attaches so
+// the human answering it need not go looking: a diff snippet, a log
+// excerpt, a link, or plain text.
+type DecisionContext struct {
+	Kind    string `json:"kind"`
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+// DisplayTitle returns the attachment's title, falling back to its URL for
+// links and its kind otherwise.
+func (c DecisionContext) DisplayTitle() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	if c.Kind == ContextLink && c.URL != "" {
+		return c.URL
+	}
+	return c.Kind
+}
+
+// Snippet reports whether the attachment is shown as preformatted text.
+func (c DecisionContext) Snippet() bool {
+	return c.Kind == ContextDiff || c.Kind == ContextLog
+}
+
+// Preview returns at most lines lines of the content, and whether any were
+// cut.
+func (c DecisionContext) Preview(lines int) (string, bool) {
+	all := strings.Split(strings.TrimRight(c.Content, "\n"), "\n")
+	if len(all) <= lines {
+		return strings.Join(all, "\n"), false
+	}
+	return strings.Join(all[:lines], "\n"), true
+}
+
+// ParseDecisionContext parses a decision's context field: a JSON array of
+// attachment objects, or plain text, which becomes a single text
+// attachment. Attachments without a kind are links when they only carry a
+// URL, and text otherwise.
+func ParseDecisionContext(raw string) []DecisionContext {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	var attachments []DecisionContext
+	if err := json.Unmarshal([]byte(raw), &attachments); err != nil || len(attachments) == 0 {
+		return []DecisionContext{{Kind: ContextText, Content: raw}}
+	}
+	for i, c := range attachments {
+		if c.Kind == "" {
+			if c.URL != "" && c.Content == "" {
+				attachments[i].Kind = ContextLink
+			} else {
+				attachments[i].Kind = ContextText
+			}
+		}
+	}
+	return attachments
+}
@@ -0,0 +1,137 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/slack-go/slack"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestParseDecisionContext(t *testing.T) {
+	if got := ParseDecisionContext("  "); got != nil {
+		t.Errorf("empty context = %+v", got)
+	}
+	got := ParseDecisionContext("The service needs durable storage.")
+	if len(got) != 1 || got[0].Kind != ContextText || got[0].Content != "The service needs durable storage." {
+		t.Errorf("plain context = %+v", got)
+	}
+
+	got = ParseDecisionContext(`[
+		{"kind":"diff","title":"store.go","content":"+a\n-b\n"},
+		{"url":"https://ci.example.com/run/7"},
+		{"title":"note","content":"flaky since Tuesday"}
+	]`)
+	if len(got) != 3 {
+		t.Fatalf("attachments = %+v", got)
+	}
+	if got[0].Kind != ContextDiff || !got[0].Snippet() || got[0].DisplayTitle() != "store.go" {
+		t.Errorf("diff = %+v", got[0])
+	}
+	if got[1].Kind != ContextLink || got[1].DisplayTitle() != "https://ci.example.com/run/7" {
+		t.Errorf("link = %+v", got[1])
+	}
+	if got[2].Kind != ContextText || got[2].Snippet() {
+		t.Errorf("text = %+v", got[2])
+	}
+}
+
+func TestDecisionContext_Preview(t *testing.T) {
+	c := DecisionContext{Kind: ContextLog, Content: "1\n2\n3\n"}
+	if p, cut := c.Preview(3); p != "1\n2\n3" || cut {
+		t.Errorf("Preview(3) = %q, %v", p, cut)
+	}
+	if p, cut := c.Preview(2); p != "1\n2" || !cut {
+		t.Errorf("Preview(2) = %q, %v", p, cut)
+	}
+}
+
+func TestDecisionContextBlocks(t *testing.T) {
+	var log strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&log, "line %d <err>\n", i)
+	}
+	blocks := decisionContextBlocks("dec-1", []DecisionContext{
+		{Kind: ContextLog, Title: "pod logs", Content: log.String()},
+		{Kind: ContextDiff, Title: "fix", Content: "+ok\n"},
+		{Kind: ContextLink, Title: "CI run", URL: "https://ci.example.com/run/7"},
+	}, "en")
+	if len(blocks) != 3 {
+		t.Fatalf("got %d blocks, want 3", len(blocks))
+	}
+	raw, _ := json.Marshal(blocks)
+	out := string(raw)
+	for _, want := range []string{
+		"line 8 \\u0026lt;err\\u0026gt;", "12 more lines", "decision_context_dec-1_0", "dec-1:0",
+		"\\u003chttps://ci.example.com/run/7|CI run\\u003e",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("blocks lack %q: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "line 9 ") || strings.Contains(out, "decision_context_dec-1_1") {
+		t.Errorf("preview not cut, or short diff got a button: %s", out)
+	}
+}
+
+func TestOpenDecisionContextModal(t *testing.T) {
+	daemon := newMockDaemon()
+	attachments, _ := json.Marshal([]DecisionContext{
+		{Kind: ContextText, Content: "why"},
+		{Kind: ContextLog, Title: "pod logs", Content: strings.Repeat("x", 3000) + "\nlast line\n"},
+	})
+	daemon.beads["dec-1"] = &beadsapi.BeadDetail{ID: "dec-1", Fields: map[string]string{"context": string(attachments)}}
+
+	var mu sync.Mutex
+	var opened string
+	slackSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "views.open") {
+			var body map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			raw, _ := json.Marshal(body["view"])
+			mu.Lock()
+			opened = string(raw)
+			mu.Unlock()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+	}))
+	defer slackSrv.Close()
+
+	bot := newTestBot(daemon, slackSrv)
+	callback := slack.InteractionCallback{TriggerID: "T1"}
+	callback.Channel.ID = "C1"
+	bot.openDecisionContextModal(context.Background(), "dec-1:1", callback)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, want := range []string{"Decision Context", "pod logs", "last line"} {
+		if !strings.Contains(opened, want) {
+			t.Errorf("modal lacks %q: %s", want, opened)
+		}
+	}
+	// The 3000-character line is split across sections under Slack's limit.
+	var view struct {
+		Blocks []struct {
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"blocks"`
+	}
+	_ = json.Unmarshal([]byte(opened), &view)
+	if len(view.Blocks) < 3 {
+		t.Fatalf("modal blocks = %d, want the title and at least two sections", len(view.Blocks))
+	}
+	for _, b := range view.Blocks {
+		if len(b.Text.Text) > 3000 {
+			t.Errorf("section of %d characters exceeds Slack's limit", len(b.Text.Text))
+		}
+	}
+}
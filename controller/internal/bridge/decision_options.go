@@ -0,0 +1,58 @@
+package bridge
+
+import (
+	"encoding/json"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// DecisionOption is one choice offered by a decision bead.
+type DecisionOption struct {
+	ID           string `json:"id"`
+	Short        string `json:"short"`
+	Label        string `json:"label"`
+	Description  string `json:"description"`
+	ArtifactType string `json:"artifact_type,omitempty"`
+}
+
+// DisplayLabel returns the option's label, falling back to its short form
+// and then its ID. It is the value recorded as "chosen" on resolution.
+func (o DecisionOption) DisplayLabel() string {
+	if o.Label != "" {
+		return o.Label
+	}
+	if o.Short != "" {
+		return o.Short
+	}
+	return o.ID
+}
+
+// ParseDecisionOptions parses a decision's options field: a JSON array of
+// option objects or of strings. Any other non-empty value is treated as a
+// single option label.
+func ParseDecisionOptions(raw string) []DecisionOption {
+	var objs []DecisionOption
+	if err := json.Unmarshal([]byte(raw), &objs); err == nil && len(objs) > 0 {
+		return objs
+	}
+	var strs []string
+	if err := json.Unmarshal([]byte(raw), &strs); err == nil {
+		opts := make([]DecisionOption, len(strs))
+		for i, s := range strs {
+			opts[i] = DecisionOption{Label: s}
+		}
+		return opts
+	}
+	if raw == "" {
+		return nil
+	}
+	return []DecisionOption{{Label: raw}}
+}
+
+// RenderDecisionText renders the plain-text decision summary that Slack uses
+// as its notification fallback, so other surfaces show the same wording.
+func (n *NotificationTemplates) RenderDecisionText(d *beadsapi.BeadDetail, locale string) string {
+	view := notificationView(beadEventFromDetail(d))
+	view.Locale = locale
+	return n.Render(tmplDecision+".fallback", view)
+}
@@ -0,0 +1,44 @@
+package bridge
+
+import (
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestParseDecisionOptions(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{`[{"id":"y","label":"Yes"},{"id":"n","short":"No"},{"id":"m"}]`, []string{"Yes", "No", "m"}},
+		{`["red","blue"]`, []string{"red", "blue"}},
+		{`pick one`, []string{"pick one"}},
+		{``, nil},
+	}
+	for _, tt := range tests {
+		opts := ParseDecisionOptions(tt.raw)
+		if len(opts) != len(tt.want) {
+			t.Errorf("%q: got %d options, want %d", tt.raw, len(opts), len(tt.want))
+			continue
+		}
+		for i, o := range opts {
+			if o.DisplayLabel() != tt.want[i] {
+				t.Errorf("%q option %d = %q, want %q", tt.raw, i, o.DisplayLabel(), tt.want[i])
+			}
+		}
+	}
+
+	opts := ParseDecisionOptions(`[{"id":"a","label":"A","description":"d","artifact_type":"plan"}]`)
+	if opts[0].Description != "d" || opts[0].ArtifactType != "plan" {
+		t.Errorf("unexpected option: %+v", opts[0])
+	}
+}
+
+func TestRenderDecisionText(t *testing.T) {
+	var n *NotificationTemplates // nil renders the embedded defaults
+	d := &beadsapi.BeadDetail{ID: "dec-1", Fields: map[string]string{"question": "Ship it?"}}
+	if got := n.RenderDecisionText(d, "es"); got != "Decisión requerida: Ship it?" {
+		t.Errorf("got %q", got)
+	}
+}
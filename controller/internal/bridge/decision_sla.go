@@ -0,0 +1,321 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// Decision SLA defaults.
+const (
+	defaultSLAInterval = time.Minute
+	defaultSLAWarnAt   = 0.75
+	defaultSLAWindow   = 7 * 24 * time.Hour
+
+	// slaNoProject labels decisions that belong to no project in metrics.
+	slaNoProject = "none"
+)
+
+// slaQuantiles are the decision latency percentiles exported per project.
+var slaQuantiles = []float64{0.5, 0.9, 0.99}
+
+// DecisionSLAClient is the subset of beadsapi.Client used for deadline
+// tracking. *beadsapi.Client satisfies it.
+type DecisionSLAClient interface {
+	ListDecisionBeads(ctx context.Context) ([]*beadsapi.BeadDetail, error)
+	ListBeadsFiltered(ctx context.Context, q beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error)
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+	AddLabel(ctx context.Context, beadID, label string) error
+}
+
+// DecisionReminder posts a reminder that a decision's deadline is near.
+// *Bot satisfies it.
+type DecisionReminder interface {
+	NotifyDecisionDue(ctx context.Context, beadID string, due time.Time) error
+}
+
+// DecisionSLAConfig configures decision deadline tracking.
+type DecisionSLAConfig struct {
+	Daemon   DecisionSLAClient
+	Reminder DecisionReminder // nil = no reminders
+	Logger   *slog.Logger
+
+	Interval        time.Duration // check interval (default 1m)
+	WarnAt          float64       // fraction of the time to the deadline after which to remind (default 0.75)
+	DefaultDeadline time.Duration // deadline for decisions without due_at (0 = none)
+	Window          time.Duration // closed decisions included in latency percentiles (default 7d)
+}
+
+// DecisionSLA tracks decision deadlines: it reminds the decision's thread
+// once WarnAt of the time to the deadline has passed, escalates the decision
+// (the "escalated" label) when the deadline passes, and exports decision
+// latency percentiles and overdue counts per project as metrics.
+//
+// Reminders and escalations are recorded on the decision bead (sla_warned_at
+// and the label), so they fire once even across restarts and replicas.
+type DecisionSLA struct {
+	cfg DecisionSLAConfig
+
+	mu    sync.Mutex
+	stats map[string]*projectSLA // project → stats from the last pass
+}
+
+// projectSLA holds the per-project figures exported as metrics.
+type projectSLA struct {
+	pending   int
+	overdue   int
+	latencies []float64 // seconds, sorted
+}
+
+// NewDecisionSLA creates a decision deadline tracker.
+func NewDecisionSLA(cfg DecisionSLAConfig) *DecisionSLA {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultSLAInterval
+	}
+	if cfg.WarnAt <= 0 || cfg.WarnAt >= 1 {
+		cfg.WarnAt = defaultSLAWarnAt
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultSLAWindow
+	}
+	return &DecisionSLA{cfg: cfg}
+}
+
+// Run checks deadlines every interval until ctx is cancelled. Only the
+// leader should run it; other replicas export no SLA metrics.
+func (s *DecisionSLA) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Check(ctx, time.Now()); err != nil {
+			s.cfg.Logger.Warn("decision SLA check failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check reminds and escalates pending decisions whose thresholds have
+// passed at now and refreshes the exported statistics.
+func (s *DecisionSLA) Check(ctx context.Context, now time.Time) error {
+	pending, err := s.cfg.Daemon.ListDecisionBeads(ctx)
+	if err != nil {
+		return fmt.Errorf("list decisions: %w", err)
+	}
+	stats := make(map[string]*projectSLA)
+	project := func(b *beadsapi.BeadDetail) *projectSLA {
+		name := decisionProject(b)
+		p, ok := stats[name]
+		if !ok {
+			p = &projectSLA{}
+			stats[name] = p
+		}
+		return p
+	}
+
+	for _, b := range pending {
+		p := project(b)
+		p.pending++
+		due, ok := s.deadline(b)
+		if !ok {
+			continue
+		}
+		if !now.Before(due) {
+			p.overdue++
+			s.escalate(ctx, b, due)
+			continue
+		}
+		if warn, ok := s.warnTime(b, due); ok && !now.Before(warn) {
+			s.remind(ctx, b, due, now)
+		}
+	}
+
+	closed, err := s.closedSince(ctx, now.Add(-s.cfg.Window))
+	if err != nil {
+		return err
+	}
+	for _, b := range closed {
+		if b.CreatedAt.IsZero() {
+			continue
+		}
+		if latency := closedTime(b).Sub(b.CreatedAt); latency >= 0 {
+			p := project(b)
+			p.latencies = append(p.latencies, latency.Seconds())
+		}
+	}
+	for _, p := range stats {
+		sort.Float64s(p.latencies)
+	}
+
+	s.mu.Lock()
+	s.stats = stats
+	s.mu.Unlock()
+	return nil
+}
+
+// deadline returns the decision's due_at, or its creation time plus the
+// default deadline when it has none.
+func (s *DecisionSLA) deadline(b *beadsapi.BeadDetail) (time.Time, bool) {
+	if b.DueAt != "" {
+		due, err := time.Parse(time.RFC3339, b.DueAt)
+		return due, err == nil
+	}
+	if s.cfg.DefaultDeadline > 0 && !b.CreatedAt.IsZero() {
+		return b.CreatedAt.Add(s.cfg.DefaultDeadline), true
+	}
+	return time.Time{}, false
+}
+
+// warnTime returns when the reminder for a decision due at due is sent.
+func (s *DecisionSLA) warnTime(b *beadsapi.BeadDetail, due time.Time) (time.Time, bool) {
+	if b.CreatedAt.IsZero() || !due.After(b.CreatedAt) {
+		return time.Time{}, false
+	}
+	span := due.Sub(b.CreatedAt)
+	return b.CreatedAt.Add(time.Duration(float64(span) * s.cfg.WarnAt)), true
+}
+
+// remind posts the deadline reminder once per decision.
+func (s *DecisionSLA) remind(ctx context.Context, b *beadsapi.BeadDetail, due, now time.Time) {
+	if b.Fields["sla_warned_at"] != "" || s.cfg.Reminder == nil {
+		return
+	}
+	if err := s.cfg.Reminder.NotifyDecisionDue(ctx, b.ID, due); err != nil {
+		s.cfg.Logger.Warn("failed to post decision deadline reminder", "id", b.ID, "error", err)
+		return
+	}
+	if err := s.cfg.Daemon.UpdateBeadFields(ctx, b.ID, map[string]string{
+		"sla_warned_at": now.UTC().Format(time.RFC3339),
+	}); err != nil {
+		s.cfg.Logger.Warn("failed to record decision deadline reminder", "id", b.ID, "error", err)
+	}
+	s.cfg.Logger.Info("decision deadline reminder sent", "id", b.ID, "due", due)
+}
+
+// escalate labels an overdue decision "escalated"; the decisions watcher
+// then posts the escalation notification.
+func (s *DecisionSLA) escalate(ctx context.Context, b *beadsapi.BeadDetail, due time.Time) {
+	for _, l := range b.Labels {
+		if l == "escalated" {
+			return
+		}
+	}
+	if err := s.cfg.Daemon.AddLabel(ctx, b.ID, "escalated"); err != nil {
+		s.cfg.Logger.Warn("failed to escalate overdue decision", "id", b.ID, "error", err)
+		return
+	}
+	s.cfg.Logger.Info("overdue decision escalated", "id", b.ID, "due", due)
+}
+
+// closedSince returns decisions closed since the given time.
+func (s *DecisionSLA) closedSince(ctx context.Context, since time.Time) ([]*beadsapi.BeadDetail, error) {
+	var out []*beadsapi.BeadDetail
+	for offset := 0; offset < chartsMaxBeads; offset += chartsPageSize {
+		res, err := s.cfg.Daemon.ListBeadsFiltered(ctx, beadsapi.ListBeadsQuery{
+			Types:    []string{"decision"},
+			Statuses: []string{"closed"},
+			Sort:     "-updated_at",
+			Limit:    chartsPageSize,
+			Offset:   offset,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list closed decisions: %w", err)
+		}
+		for _, b := range res.Beads {
+			if !b.UpdatedAt.IsZero() && b.UpdatedAt.Before(since) {
+				return out, nil
+			}
+			if !closedTime(b).Before(since) {
+				out = append(out, b)
+			}
+		}
+		if len(res.Beads) < chartsPageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+// decisionProject returns the project a decision belongs to: its project
+// field or, failing that, the project in its assignee's identity.
+func decisionProject(b *beadsapi.BeadDetail) string {
+	if p := b.Fields["project"]; p != "" {
+		return p
+	}
+	if p := extractAgentProject(b.Assignee); p != "" {
+		return p
+	}
+	return slaNoProject
+}
+
+// WriteMetrics writes the decision SLA metrics from the last check.
+func (s *DecisionSLA) WriteMetrics(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stats == nil {
+		return
+	}
+	projects := sortedSeriesKeys(s.stats)
+
+	fmt.Fprint(w, "# HELP slack_bridge_decisions_pending Pending decisions by project.\n")
+	fmt.Fprint(w, "# TYPE slack_bridge_decisions_pending gauge\n")
+	for _, p := range projects {
+		fmt.Fprintf(w, "slack_bridge_decisions_pending{project=%q} %d\n", p, s.stats[p].pending)
+	}
+	fmt.Fprint(w, "# HELP slack_bridge_decisions_overdue Pending decisions past their deadline, by project.\n")
+	fmt.Fprint(w, "# TYPE slack_bridge_decisions_overdue gauge\n")
+	for _, p := range projects {
+		fmt.Fprintf(w, "slack_bridge_decisions_overdue{project=%q} %d\n", p, s.stats[p].overdue)
+	}
+	fmt.Fprint(w, "# HELP slack_bridge_decision_latency_seconds Time from creation to resolution of recently closed decisions, by project.\n")
+	fmt.Fprint(w, "# TYPE slack_bridge_decision_latency_seconds summary\n")
+	for _, p := range projects {
+		lat := s.stats[p].latencies
+		if len(lat) == 0 {
+			continue
+		}
+		var sum float64
+		for _, v := range lat {
+			sum += v
+		}
+		for _, q := range slaQuantiles {
+			fmt.Fprintf(w, "slack_bridge_decision_latency_seconds{project=%q,quantile=\"%g\"} %g\n", p, q, percentile(lat, q))
+		}
+		fmt.Fprintf(w, "slack_bridge_decision_latency_seconds_sum{project=%q} %g\n", p, sum)
+		fmt.Fprintf(w, "slack_bridge_decision_latency_seconds_count{project=%q} %d\n", p, len(lat))
+	}
+}
+
+// percentile returns the nearest-rank q-quantile of sorted values.
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
+
+// decisionDueAt returns a decision event's deadline, if it has one.
+func decisionDueAt(bead BeadEvent) (time.Time, bool) {
+	if bead.DueAt == "" {
+		return time.Time{}, false
+	}
+	due, err := time.Parse(time.RFC3339, bead.DueAt)
+	return due, err == nil
+}
+
+// slackDate formats t for Slack so each reader sees it in their own
+// timezone, with a live relative countdown ("in 2 hours").
+func slackDate(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} {time} ({ago})|%s>", t.Unix(), t.UTC().Format("Jan 2 15:04 UTC"))
+}
@@ -0,0 +1,176 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// slaDaemon adds closed-decision listing and labels to mockDaemon.
+type slaDaemon struct {
+	*mockDaemon
+	closed []*beadsapi.BeadDetail
+	added  []string // "<bead> <label>"
+}
+
+func (d *slaDaemon) ListBeadsFiltered(_ context.Context, q beadsapi.ListBeadsQuery) (*beadsapi.ListBeadsResult, error) {
+	if q.Offset > 0 {
+		return &beadsapi.ListBeadsResult{}, nil
+	}
+	return &beadsapi.ListBeadsResult{Beads: d.closed, Total: len(d.closed)}, nil
+}
+
+func (d *slaDaemon) AddLabel(_ context.Context, beadID, label string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.added = append(d.added, beadID+" "+label)
+	if b, ok := d.beads[beadID]; ok {
+		b.Labels = append(b.Labels, label)
+	}
+	return nil
+}
+
+type recordingReminder struct{ ids []string }
+
+func (r *recordingReminder) NotifyDecisionDue(_ context.Context, beadID string, _ time.Time) error {
+	r.ids = append(r.ids, beadID)
+	return nil
+}
+
+func TestDecisionSLA_RemindsOnceAndEscalatesOverdue(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	daemon := &slaDaemon{mockDaemon: newMockDaemon()}
+	daemon.beads["d-warn"] = &beadsapi.BeadDetail{ID: "d-warn", Type: "decision", Assignee: "gasboat/crew/bot",
+		CreatedAt: now.Add(-80 * time.Minute), DueAt: now.Add(20 * time.Minute).Format(time.RFC3339)}
+	daemon.beads["d-fresh"] = &beadsapi.BeadDetail{ID: "d-fresh", Type: "decision",
+		CreatedAt: now.Add(-10 * time.Minute), DueAt: now.Add(time.Hour).Format(time.RFC3339)}
+	daemon.beads["d-over"] = &beadsapi.BeadDetail{ID: "d-over", Type: "decision", Fields: map[string]string{"project": "beads"},
+		CreatedAt: now.Add(-time.Hour), DueAt: now.Add(-time.Minute).Format(time.RFC3339)}
+	daemon.beads["d-default"] = &beadsapi.BeadDetail{ID: "d-default", Type: "decision", CreatedAt: now.Add(-3 * time.Hour)}
+	reminder := &recordingReminder{}
+	sla := NewDecisionSLA(DecisionSLAConfig{
+		Daemon:          daemon,
+		Reminder:        reminder,
+		Logger:          slog.Default(),
+		DefaultDeadline: 2 * time.Hour,
+	})
+
+	ctx := context.Background()
+	for range 2 {
+		if err := sla.Check(ctx, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if strings.Join(reminder.ids, ",") != "d-warn" {
+		t.Errorf("reminders = %v, want one for d-warn", reminder.ids)
+	}
+	if daemon.beads["d-warn"].Fields["sla_warned_at"] == "" {
+		t.Error("sla_warned_at not recorded")
+	}
+	added := strings.Join(daemon.added, ",")
+	if added != "d-default escalated,d-over escalated" && added != "d-over escalated,d-default escalated" {
+		t.Errorf("labels added = %v, want d-over and d-default escalated once", daemon.added)
+	}
+
+	var buf bytes.Buffer
+	sla.WriteMetrics(&buf)
+	for _, want := range []string{
+		`slack_bridge_decisions_pending{project="gasboat"} 1`,
+		`slack_bridge_decisions_overdue{project="beads"} 1`,
+		`slack_bridge_decisions_overdue{project="none"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics lack %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestDecisionSLA_LatencyPercentiles(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	daemon := &slaDaemon{mockDaemon: newMockDaemon()}
+	for i, mins := range []int{1, 2, 3, 4} {
+		created := now.Add(-time.Duration(i+1) * time.Hour)
+		daemon.closed = append(daemon.closed, &beadsapi.BeadDetail{
+			ID: "c", Type: "decision", Fields: map[string]string{"project": "gasboat"},
+			CreatedAt: created, ClosedAt: created.Add(time.Duration(mins) * time.Minute),
+			UpdatedAt: created.Add(time.Duration(mins) * time.Minute),
+		})
+	}
+	// Closed before the window: excluded.
+	old := now.Add(-30 * 24 * time.Hour)
+	daemon.closed = append(daemon.closed, &beadsapi.BeadDetail{ID: "old", Type: "decision",
+		CreatedAt: old, ClosedAt: old.Add(time.Hour), UpdatedAt: old.Add(time.Hour)})
+
+	sla := NewDecisionSLA(DecisionSLAConfig{Daemon: daemon, Logger: slog.Default()})
+	if err := sla.Check(context.Background(), now); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	sla.WriteMetrics(&buf)
+	for _, want := range []string{
+		`slack_bridge_decision_latency_seconds{project="gasboat",quantile="0.5"} 120`,
+		`slack_bridge_decision_latency_seconds{project="gasboat",quantile="0.9"} 240`,
+		`slack_bridge_decision_latency_seconds_sum{project="gasboat"} 600`,
+		`slack_bridge_decision_latency_seconds_count{project="gasboat"} 4`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("metrics lack %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `project="none"`) {
+		t.Errorf("decision closed before the window was counted:\n%s", buf.String())
+	}
+}
+
+func TestNotifyDecision_ShowsDeadlineCountdown(t *testing.T) {
+	slackSrv := newRecordingSlackServer(t)
+	bot := newTestBot(newMockDaemon(), slackSrv.Server)
+	bot.channel = "C1"
+
+	due := time.Date(2026, 10, 15, 14, 0, 0, 0, time.UTC)
+	err := bot.NotifyDecision(context.Background(), BeadEvent{
+		ID: "d-1", Type: "decision", Fields: map[string]string{"prompt": "Ship it?"},
+		DueAt: due.Format(time.RFC3339),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Due \\u003c!date^1792072800^{date_short_pretty} {time} ({ago})|Oct 15 14:00 UTC\\u003e"
+	found := false
+	for _, c := range slackSrv.calls {
+		if strings.HasPrefix(c, "chat.postMessage") && strings.Contains(c, want) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no decision message with the deadline; calls = %q", slackSrv.calls)
+	}
+}
+
+func TestNotifyDecisionDue_RepliesInDecisionThread(t *testing.T) {
+	slackSrv := newRecordingSlackServer(t)
+	bot := newTestBot(newMockDaemon(), slackSrv.Server)
+	bot.messages["d-1"] = MessageRef{ChannelID: "C1", Timestamp: "1700000000.000001"}
+
+	if err := bot.NotifyDecisionDue(context.Background(), "d-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bot.NotifyDecisionDue(context.Background(), "d-unknown", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	var posts []string
+	for _, c := range slackSrv.calls {
+		if strings.HasPrefix(c, "chat.postMessage") {
+			posts = append(posts, c)
+		}
+	}
+	if len(posts) != 1 {
+		t.Errorf("posts = %q, want one reminder for the known decision", posts)
+	}
+}
@@ -25,6 +25,7 @@ type BeadClient interface {
 	GetBead(ctx context.Context, beadID string) (*beadsapi.BeadDetail, error)
 	FindAgentBead(ctx context.Context, agentName string) (*beadsapi.BeadDetail, error)
 	CloseBead(ctx context.Context, beadID string, fields map[string]string) error
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
 	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
 	SpawnAgent(ctx context.Context, agentName, project, taskID, role string) (string, error)
 	ListDecisionBeads(ctx context.Context) ([]*beadsapi.BeadDetail, error)
@@ -44,6 +45,10 @@ type BeadEvent struct {
 	Labels    []string          `json:"labels"`
 	Fields    map[string]string `json:"fields"`
 	Priority  int               `json:"priority"`
+	Notes     string            `json:"notes,omitempty"`
+	CreatedAt string            `json:"created_at,omitempty"` // RFC 3339, as sent by the daemon
+	ClosedAt  string            `json:"closed_at,omitempty"`
+	DueAt     string            `json:"due_at,omitempty"` // decision deadline, RFC 3339
 }
 
 // Notifier sends decision lifecycle notifications to an external system.
@@ -67,6 +72,7 @@ const escalationTTL = 1 * time.Hour
 type Decisions struct {
 	daemon     BeadClient
 	notifier   Notifier // nil = no notifications
+	mutes      *Mutes   // nil = nothing muted
 	logger     *slog.Logger
 	httpClient *http.Client // reused for nudge requests
 
@@ -78,6 +84,7 @@ type Decisions struct {
 type DecisionsConfig struct {
 	Daemon   BeadClient
 	Notifier Notifier
+	Mutes    *Mutes // optional mute rules checked before notifying
 	Logger   *slog.Logger
 }
 
@@ -86,6 +93,7 @@ func NewDecisions(cfg DecisionsConfig) *Decisions {
 	return &Decisions{
 		daemon:     cfg.Daemon,
 		notifier:   cfg.Notifier,
+		mutes:      cfg.Mutes,
 		logger:     cfg.Logger,
 		httpClient: &http.Client{Timeout: 10 * time.Second},
 		escalated:  make(map[string]time.Time),
@@ -118,6 +126,11 @@ func (d *Decisions) handleCreated(ctx context.Context, data []byte) {
 		"title", bead.Title,
 		"assignee", bead.Assignee)
 
+	if d.mutes.IsMuted(MuteKindDecisions, *bead) {
+		d.logger.Info("decision notification muted", "id", bead.ID, "assignee", bead.Assignee)
+		return
+	}
+
 	if d.notifier != nil {
 		if err := d.notifier.NotifyDecision(ctx, *bead); err != nil {
 			d.logger.Error("failed to notify decision", "id", bead.ID, "error", err)
@@ -225,6 +238,11 @@ func (d *Decisions) handleUpdated(ctx context.Context, data []byte) {
 		"priority", bead.Priority,
 		"assignee", bead.Assignee)
 
+	if d.mutes.IsMuted(MuteKindDecisions, *bead) {
+		d.logger.Info("escalation notification muted", "id", bead.ID, "assignee", bead.Assignee)
+		return
+	}
+
 	if d.notifier != nil {
 		if err := d.notifier.NotifyEscalation(ctx, *bead); err != nil {
 			d.logger.Error("failed to notify escalation", "id", bead.ID, "error", err)
@@ -265,7 +283,7 @@ func (d *Decisions) nudgeAgent(ctx context.Context, bead BeadEvent) {
 		return
 	}
 
-	coopURL := beadsapi.ParseNotes(agentBead.Notes)["coop_url"]
+	coopURL := agentCoopURL(ctx, d.daemon, agentBead)
 	if coopURL == "" {
 		d.logger.Warn("agent bead has no coop_url, cannot nudge",
 			"agent", agentName, "decision", bead.ID)
@@ -300,6 +318,25 @@ type nudgeCoopResult struct {
 	Reason    string `json:"reason"`
 }
 
+// coopRegistryReader is implemented by bead clients that can read the
+// controller's coop service registry (*beadsapi.Client).
+type coopRegistryReader interface {
+	GetCoopRegistry(ctx context.Context) (*beadsapi.CoopRegistry, error)
+}
+
+// agentCoopURL returns the coop URL of agentBead's session. The controller's
+// coop registry is authoritative once published, so a URL left in the bead's
+// notes by a pod that is gone is never used; the notes are only consulted
+// when there is no registry to read.
+func agentCoopURL(ctx context.Context, daemon BeadClient, agentBead *beadsapi.BeadDetail) string {
+	if reader, ok := daemon.(coopRegistryReader); ok {
+		if reg, err := reader.GetCoopRegistry(ctx); err == nil && reg != nil {
+			return reg.Agents[agentBead.ID].URL
+		}
+	}
+	return beadsapi.ParseNotes(agentBead.Notes)["coop_url"]
+}
+
 // nudgeCoop POSTs a nudge message to a coop agent endpoint.
 // Returns an error if the HTTP request fails or the nudge was not delivered.
 // Coop can return {"delivered":false,"reason":"..."} with status 200 when the
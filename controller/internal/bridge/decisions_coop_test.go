@@ -0,0 +1,43 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// registryDaemon is a mockDaemon that also serves a coop registry.
+type registryDaemon struct {
+	*mockDaemon
+	reg *beadsapi.CoopRegistry
+}
+
+func (d *registryDaemon) GetCoopRegistry(context.Context) (*beadsapi.CoopRegistry, error) {
+	return d.reg, nil
+}
+
+func TestAgentCoopURL_PrefersRegistry(t *testing.T) {
+	ctx := context.Background()
+	bead := &beadsapi.BeadDetail{ID: "kd-agent-1", Notes: "coop_url: http://10.0.0.1:8080"}
+
+	if got := agentCoopURL(ctx, newMockDaemon(), bead); got != "http://10.0.0.1:8080" {
+		t.Errorf("without registry support: got %q, want the notes URL", got)
+	}
+	// No registry published yet: fall back to the notes.
+	if got := agentCoopURL(ctx, &registryDaemon{mockDaemon: newMockDaemon()}, bead); got != "http://10.0.0.1:8080" {
+		t.Errorf("without a published registry: got %q, want the notes URL", got)
+	}
+
+	daemon := &registryDaemon{mockDaemon: newMockDaemon(), reg: &beadsapi.CoopRegistry{
+		Agents: map[string]beadsapi.CoopEndpoint{"kd-agent-1": {URL: "http://10.0.0.2:8080"}},
+	}}
+	if got := agentCoopURL(ctx, daemon, bead); got != "http://10.0.0.2:8080" {
+		t.Errorf("with registry: got %q, want the registry URL", got)
+	}
+	// An agent missing from the registry has no live session; stale notes are ignored.
+	delete(daemon.reg.Agents, "kd-agent-1")
+	if got := agentCoopURL(ctx, daemon, bead); got != "" {
+		t.Errorf("agent absent from registry: got %q, want empty", got)
+	}
+}
@@ -68,6 +68,23 @@ func (m *mockDaemon) CloseBead(_ context.Context, beadID string, fields map[stri
 	return nil
 }
 
+func (m *mockDaemon) UpdateBeadFields(_ context.Context, beadID string, fields map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.beads[beadID]
+	if !ok {
+		b = &beadsapi.BeadDetail{ID: beadID}
+		m.beads[beadID] = b
+	}
+	if b.Fields == nil {
+		b.Fields = make(map[string]string)
+	}
+	for k, v := range fields {
+		b.Fields[k] = v
+	}
+	return nil
+}
+
 func (m *mockDaemon) CreateBead(_ context.Context, req beadsapi.CreateBeadRequest) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -145,10 +162,11 @@ func (m *mockDaemon) ListAgentBeads(_ context.Context) ([]beadsapi.AgentBead, er
 	for _, b := range m.beads {
 		if b.Type == "agent" {
 			result = append(result, beadsapi.AgentBead{
-				ID:      b.ID,
-				Project: b.Fields["project"],
-				Mode:    "crew",
-				Role:    b.Fields["role"],
+				ID:        b.ID,
+				Project:   b.Fields["project"],
+				Mode:      "crew",
+				Role:      b.Fields["role"],
+				AgentName: b.Fields["agent"],
 			})
 		}
 	}
@@ -3,7 +3,9 @@
 // Dedup tracks seen events by prefixed keys to prevent duplicate Slack
 // notifications. It is used by all watchers (decisions, agents, jacks) and
 // handles both in-session dedup (same event replayed by SSE reconnect) and
-// cross-session dedup (state persisted via StateManager).
+// cross-session dedup (state persisted via StateManager). When several
+// replicas share a state backend, UseSharedState makes keys visible to all of
+// them so a leader handoff does not re-post events the old leader handled.
 package bridge
 
 import (
@@ -20,6 +22,7 @@ type Dedup struct {
 	mu   sync.Mutex
 	seen map[string]bool // prefixed key → true
 
+	state  *StateManager // optional shared backing; nil = in-memory only
 	logger *slog.Logger
 }
 
@@ -31,6 +34,15 @@ func NewDedup(logger *slog.Logger) *Dedup {
 	}
 }
 
+// UseSharedState backs the dedup map with the state store so seen keys are
+// shared between replicas. Keys missing locally are checked (and recorded)
+// in state; a state error falls back to in-memory dedup.
+func (d *Dedup) UseSharedState(state *StateManager) {
+	d.mu.Lock()
+	d.state = state
+	d.mu.Unlock()
+}
+
 // Seen returns true if the key has already been processed. If not, marks it as seen.
 // Keys should be prefixed by event type, e.g., "created:dec-1", "resolved:dec-1".
 func (d *Dedup) Seen(key string) bool {
@@ -40,14 +52,27 @@ func (d *Dedup) Seen(key string) bool {
 		return true
 	}
 	d.seen[key] = true
+	if d.state != nil {
+		seen, err := d.state.MarkEventSeen(key)
+		if err != nil {
+			d.logger.Warn("dedup: shared state unavailable, using local dedup", "key", key, "error", err)
+			return false
+		}
+		return seen
+	}
 	return false
 }
 
 // Mark records a key as seen without checking.
 func (d *Dedup) Mark(key string) {
 	d.mu.Lock()
+	defer d.mu.Unlock()
 	d.seen[key] = true
-	d.mu.Unlock()
+	if d.state != nil {
+		if _, err := d.state.MarkEventSeen(key); err != nil {
+			d.logger.Warn("dedup: failed to record shared key", "key", key, "error", err)
+		}
+	}
 }
 
 // CatchUpDecisions fetches pending decisions from the daemon and pre-populates
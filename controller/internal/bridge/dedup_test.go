@@ -3,6 +3,7 @@ package bridge
 import (
 	"context"
 	"log/slog"
+	"path/filepath"
 	"testing"
 
 	"gasboat/controller/internal/beadsapi"
@@ -50,6 +51,32 @@ func TestDedup_DifferentKeys(t *testing.T) {
 	}
 }
 
+func TestDedup_SharedStateAcrossReplicas(t *testing.T) {
+	store := NewFileStateStore(filepath.Join(t.TempDir(), "state.json"))
+	newReplica := func() *Dedup {
+		sm, err := NewStateManagerWithStore(store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sm.SetShared(true)
+		d := NewDedup(slog.Default())
+		d.UseSharedState(sm)
+		return d
+	}
+	a, b := newReplica(), newReplica()
+
+	if a.Seen("created:dec-1") {
+		t.Fatal("expected first replica to see key as new")
+	}
+	if !b.Seen("created:dec-1") {
+		t.Fatal("expected second replica to see key recorded by the first")
+	}
+	b.Mark("resolved:dec-1")
+	if !a.Seen("resolved:dec-1") {
+		t.Fatal("expected marked key to be shared")
+	}
+}
+
 func TestDedup_CatchUpDecisions_Empty(t *testing.T) {
 	d := NewDedup(slog.Default())
 	daemon := newMockDaemon()
@@ -0,0 +1,407 @@
+// Package bridge provides a Discord notifier for decision and agent events.
+//
+// Discord implements Notifier and AgentNotifier on top of discordgo so the
+// same Decisions and Agents watchers that drive the Slack bot can post to a
+// Discord channel instead. Decisions are posted as embeds with one button per
+// option; clicking a button resolves the decision bead. In "agent" threading
+// mode each agent gets its own thread and its decisions are posted there.
+// Message references reuse StateManager (decision messages and agent cards),
+// with the Discord message ID stored in MessageRef.Timestamp.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord embed colours.
+const (
+	discordColorPending   = 0xF2C744
+	discordColorEscalated = 0xE01E5A
+	discordColorResolved  = 0x2EB67D
+	discordColorDismissed = 0x868686
+)
+
+// discordMaxButtons is the most buttons Discord allows on one message
+// (5 action rows of 5 buttons).
+const discordMaxButtons = 25
+
+// discordSession is the subset of *discordgo.Session used by Discord, so
+// tests can substitute a fake.
+type discordSession interface {
+	ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ChannelMessageEditComplex(m *discordgo.MessageEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	ThreadStart(channelID, name string, typ discordgo.ChannelType, archiveDuration int, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error
+}
+
+// DiscordConfig holds configuration for the Discord notifier.
+type DiscordConfig struct {
+	Token         string // bot token
+	ChannelID     string // channel for decisions and agent threads
+	GuildID       string // guild for slash command registration ("" = global)
+	ThreadingMode string // "agent" (thread per agent) or "" (flat)
+	Daemon        BeadClient
+	State         *StateManager
+	Logger        *slog.Logger
+}
+
+// Discord posts decision and agent notifications to a Discord channel.
+type Discord struct {
+	session   discordSession
+	raw       *discordgo.Session // nil in tests
+	channelID string
+	guildID   string
+	threading string
+	daemon    BeadClient
+	state     *StateManager
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	messages map[string]MessageRef // bead ID → decision message (fallback when state is nil)
+	threads  map[string]string     // agent name → thread channel ID (fallback when state is nil)
+}
+
+// Compile-time checks.
+var (
+	_ Notifier      = (*Discord)(nil)
+	_ AgentNotifier = (*Discord)(nil)
+)
+
+// NewDiscord creates a Discord notifier with a bot session.
+func NewDiscord(cfg DiscordConfig) (*Discord, error) {
+	session, err := discordgo.New("Bot " + cfg.Token)
+	if err != nil {
+		return nil, fmt.Errorf("create discord session: %w", err)
+	}
+	session.Identify.Intents = discordgo.IntentsGuilds
+	d := newDiscord(session, cfg)
+	d.raw = session
+	return d, nil
+}
+
+func newDiscord(session discordSession, cfg DiscordConfig) *Discord {
+	return &Discord{
+		session:   session,
+		channelID: cfg.ChannelID,
+		guildID:   cfg.GuildID,
+		threading: cfg.ThreadingMode,
+		daemon:    cfg.Daemon,
+		state:     cfg.State,
+		logger:    cfg.Logger,
+		messages:  make(map[string]MessageRef),
+		threads:   make(map[string]string),
+	}
+}
+
+// Run opens the gateway connection, registers slash commands, and handles
+// interactions until ctx is cancelled.
+func (d *Discord) Run(ctx context.Context) error {
+	d.raw.AddHandler(func(_ *discordgo.Session, i *discordgo.InteractionCreate) {
+		d.handleInteraction(ctx, i.Interaction)
+	})
+	if err := d.raw.Open(); err != nil {
+		return fmt.Errorf("open discord gateway: %w", err)
+	}
+	defer d.raw.Close()
+
+	if _, err := d.raw.ApplicationCommandBulkOverwrite(d.raw.State.User.ID, d.guildID, discordCommands); err != nil {
+		d.logger.Error("failed to register discord slash commands", "error", err)
+	}
+	d.logger.Info("discord gateway connected", "user", d.raw.State.User.Username, "guild", d.guildID)
+
+	<-ctx.Done()
+	return nil
+}
+
+func (d *Discord) agentThreadingEnabled() bool {
+	return d.threading == "agent"
+}
+
+// NotifyDecision posts a decision embed with one button per option.
+func (d *Discord) NotifyDecision(ctx context.Context, bead BeadEvent) error {
+	agent := extractAgentName(bead.Assignee)
+	channelID := d.channelID
+	if d.agentThreadingEnabled() && agent != "" {
+		thread, err := d.ensureAgentThread(agent)
+		if err != nil {
+			d.logger.Warn("failed to open agent thread, posting to channel", "agent", agent, "error", err)
+		} else {
+			channelID = thread
+		}
+	}
+
+	msg, err := d.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{decisionEmbed(bead)},
+		Components: decisionButtons(bead.ID, decisionOptionLabels(bead.Fields["options"])),
+	})
+	if err != nil {
+		return fmt.Errorf("post discord decision %s: %w", bead.ID, err)
+	}
+	d.setDecisionMessage(bead.ID, MessageRef{ChannelID: channelID, Timestamp: msg.ID, Agent: bead.Assignee})
+	d.logger.Info("posted decision to discord", "bead", bead.ID, "channel", channelID, "message", msg.ID)
+	return nil
+}
+
+// UpdateDecision marks the decision message resolved and removes its buttons.
+func (d *Discord) UpdateDecision(_ context.Context, beadID, chosen string) error {
+	return d.editDecision(beadID, fmt.Sprintf("Resolved: **%s**", chosen), discordColorResolved)
+}
+
+// NotifyEscalation posts an escalation notice to the main channel, which
+// stays visible even when the decision itself sits in an agent thread.
+func (d *Discord) NotifyEscalation(_ context.Context, bead BeadEvent) error {
+	embed := decisionEmbed(bead)
+	embed.Title = "Decision escalated"
+	embed.Color = discordColorEscalated
+	if _, err := d.session.ChannelMessageSendComplex(d.channelID, &discordgo.MessageSend{
+		Content: "@here",
+		Embeds:  []*discordgo.MessageEmbed{embed},
+	}); err != nil {
+		return fmt.Errorf("post discord escalation %s: %w", bead.ID, err)
+	}
+	return nil
+}
+
+// DismissDecision marks the decision message dismissed and removes its buttons.
+func (d *Discord) DismissDecision(_ context.Context, beadID string) error {
+	return d.editDecision(beadID, "Dismissed", discordColorDismissed)
+}
+
+// PostReport posts a report as a reply to the decision message.
+func (d *Discord) PostReport(_ context.Context, decisionID, reportType, content string) error {
+	ref, ok := d.decisionMessage(decisionID)
+	if !ok {
+		return nil
+	}
+	_, err := d.session.ChannelMessageSendComplex(ref.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{{
+			Title:       fmt.Sprintf("Report: %s", reportType),
+			Description: truncateDiscord(content, 4000),
+		}},
+		Reference: &discordgo.MessageReference{MessageID: ref.Timestamp, ChannelID: ref.ChannelID},
+	})
+	if err != nil {
+		return fmt.Errorf("post discord report for %s: %w", decisionID, err)
+	}
+	return nil
+}
+
+// NotifyAgentCrash posts a crash notice to the agent's thread (or channel).
+func (d *Discord) NotifyAgentCrash(_ context.Context, bead BeadEvent) error {
+	agent := bead.Fields["agent"]
+	if agent == "" {
+		agent = bead.Title
+	}
+	reason := bead.Fields["agent_state"]
+	if bead.Fields["pod_phase"] == "failed" {
+		reason = "pod failed"
+	}
+	_, err := d.session.ChannelMessageSendComplex(d.agentChannel(agent), &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{{
+			Title:       fmt.Sprintf("Agent %s crashed", agent),
+			Description: reason,
+			Color:       discordColorEscalated,
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("post discord crash for %s: %w", agent, err)
+	}
+	return nil
+}
+
+// NotifyAgentSpawn opens the agent's thread in agent threading mode.
+func (d *Discord) NotifyAgentSpawn(_ context.Context, bead BeadEvent) {
+	if !d.agentThreadingEnabled() {
+		return
+	}
+	agent := bead.Fields["agent"]
+	if agent == "" {
+		return
+	}
+	if _, err := d.ensureAgentThread(agent); err != nil {
+		d.logger.Error("failed to create discord agent thread", "agent", agent, "error", err)
+	}
+}
+
+// NotifyAgentState posts terminal state changes to the agent's thread.
+func (d *Discord) NotifyAgentState(_ context.Context, bead BeadEvent) {
+	agent := bead.Fields["agent"]
+	state := bead.Fields["agent_state"]
+	if agent == "" || state != "done" || !d.agentThreadingEnabled() {
+		return
+	}
+	if _, ok := d.agentThread(agent); !ok {
+		return
+	}
+	_, _ = d.session.ChannelMessageSendComplex(d.agentChannel(agent), &discordgo.MessageSend{
+		Content: fmt.Sprintf("Agent **%s** finished.", agent),
+	})
+}
+
+// NotifyAgentTaskUpdate is a no-op; Discord has no live agent card.
+func (d *Discord) NotifyAgentTaskUpdate(context.Context, string) {}
+
+// editDecision replaces a decision message's status and drops its buttons.
+func (d *Discord) editDecision(beadID, status string, color int) error {
+	ref, ok := d.decisionMessage(beadID)
+	if !ok {
+		return nil
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:  "Decision",
+		Color:  color,
+		Fields: []*discordgo.MessageEmbedField{{Name: "Status", Value: status}},
+		Footer: &discordgo.MessageEmbedFooter{Text: beadID},
+	}
+	edit := discordgo.NewMessageEdit(ref.ChannelID, ref.Timestamp).SetEmbeds([]*discordgo.MessageEmbed{embed})
+	edit.Components = &[]discordgo.MessageComponent{}
+	if _, err := d.session.ChannelMessageEditComplex(edit); err != nil {
+		return fmt.Errorf("edit discord decision %s: %w", beadID, err)
+	}
+	d.removeDecisionMessage(beadID)
+	return nil
+}
+
+// ensureAgentThread returns the agent's thread, creating it if needed.
+func (d *Discord) ensureAgentThread(agent string) (string, error) {
+	if id, ok := d.agentThread(agent); ok {
+		return id, nil
+	}
+	thread, err := d.session.ThreadStart(d.channelID, "agent-"+agent, discordgo.ChannelTypeGuildPublicThread, 10080)
+	if err != nil {
+		return "", err
+	}
+	d.mu.Lock()
+	d.threads[agent] = thread.ID
+	d.mu.Unlock()
+	if d.state != nil {
+		_ = d.state.SetAgentCard(agent, MessageRef{ChannelID: thread.ID, Agent: agent})
+	}
+	return thread.ID, nil
+}
+
+func (d *Discord) agentThread(agent string) (string, bool) {
+	if d.state != nil {
+		if ref, ok := d.state.GetAgentCard(agent); ok && ref.ChannelID != "" {
+			return ref.ChannelID, true
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	id, ok := d.threads[agent]
+	return id, ok
+}
+
+// agentChannel returns the agent's thread, or the main channel if it has none.
+func (d *Discord) agentChannel(agent string) string {
+	if id, ok := d.agentThread(agent); ok {
+		return id
+	}
+	return d.channelID
+}
+
+func (d *Discord) decisionMessage(beadID string) (MessageRef, bool) {
+	if d.state != nil {
+		return d.state.GetDecisionMessage(beadID)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	ref, ok := d.messages[beadID]
+	return ref, ok
+}
+
+func (d *Discord) setDecisionMessage(beadID string, ref MessageRef) {
+	if d.state != nil {
+		_ = d.state.SetDecisionMessage(beadID, ref)
+		return
+	}
+	d.mu.Lock()
+	d.messages[beadID] = ref
+	d.mu.Unlock()
+}
+
+func (d *Discord) removeDecisionMessage(beadID string) {
+	if d.state != nil {
+		_ = d.state.RemoveDecisionMessage(beadID)
+		return
+	}
+	d.mu.Lock()
+	delete(d.messages, beadID)
+	d.mu.Unlock()
+}
+
+// decisionEmbed renders a pending decision as a Discord embed.
+func decisionEmbed(bead BeadEvent) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       "Decision needed",
+		Description: truncateDiscord(decisionQuestion(bead.Fields), 4000),
+		Color:       discordColorPending,
+		Footer:      &discordgo.MessageEmbedFooter{Text: bead.ID},
+	}
+	if bead.Assignee != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Agent", Value: bead.Assignee, Inline: true})
+	}
+	if ctx := bead.Fields["context"]; ctx != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Context", Value: truncateDiscord(ctx, 1024)})
+	}
+	return embed
+}
+
+// decisionButtons lays out one button per option plus a dismiss button,
+// five to a row. Button custom IDs are "resolve:<beadID>:<index>" (1-based)
+// and "dismiss:<beadID>".
+func decisionButtons(beadID string, labels []string) []discordgo.MessageComponent {
+	var buttons []discordgo.MessageComponent
+	for i, label := range labels {
+		if i == discordMaxButtons-1 {
+			break
+		}
+		buttons = append(buttons, discordgo.Button{
+			Label:    truncateDiscord(label, 80),
+			Style:    discordgo.PrimaryButton,
+			CustomID: fmt.Sprintf("resolve:%s:%d", beadID, i+1),
+		})
+	}
+	buttons = append(buttons, discordgo.Button{
+		Label:    "Dismiss",
+		Style:    discordgo.SecondaryButton,
+		CustomID: "dismiss:" + beadID,
+	})
+
+	var rows []discordgo.MessageComponent
+	for len(buttons) > 0 {
+		n := min(5, len(buttons))
+		rows = append(rows, discordgo.ActionsRow{Components: buttons[:n]})
+		buttons = buttons[n:]
+	}
+	return rows
+}
+
+// decisionOptionLabels parses a decision's options field (JSON array of
+// option objects or strings) into button labels.
+func decisionOptionLabels(raw string) []string {
+	opts := ParseDecisionOptions(raw)
+	if opts == nil {
+		return nil
+	}
+	labels := make([]string, len(opts))
+	for i, o := range opts {
+		labels[i] = o.DisplayLabel()
+	}
+	return labels
+}
+
+// truncateDiscord shortens s to at most n runes, Discord's field limits.
+func truncateDiscord(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(r[:n-1])) + "…"
+}
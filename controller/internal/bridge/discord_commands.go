@@ -0,0 +1,230 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// discordCommands are the slash commands registered on startup.
+var discordCommands = []*discordgo.ApplicationCommand{
+	{Name: "decisions", Description: "List pending decisions"},
+	{Name: "roster", Description: "List active agents"},
+}
+
+// handleInteraction dispatches slash commands and button clicks.
+func (d *Discord) handleInteraction(ctx context.Context, i *discordgo.Interaction) {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		switch i.ApplicationCommandData().Name {
+		case "decisions":
+			d.handleDecisionsCommand(ctx, i)
+		case "roster":
+			d.handleRosterCommand(ctx, i)
+		}
+	case discordgo.InteractionMessageComponent:
+		customID := i.MessageComponentData().CustomID
+		switch {
+		case strings.HasPrefix(customID, "resolve:"):
+			d.handleResolveButton(ctx, i, strings.TrimPrefix(customID, "resolve:"))
+		case strings.HasPrefix(customID, "dismiss:"):
+			d.handleDismissButton(ctx, i, strings.TrimPrefix(customID, "dismiss:"))
+		}
+	}
+}
+
+// handleResolveButton resolves a decision from an option button. The value
+// is "<beadID>:<index>" with a 1-based option index.
+func (d *Discord) handleResolveButton(ctx context.Context, i *discordgo.Interaction, value string) {
+	sep := strings.LastIndex(value, ":")
+	if sep < 0 {
+		d.logger.Error("invalid discord resolve button", "value", value)
+		return
+	}
+	beadID := value[:sep]
+	idx, err := strconv.Atoi(value[sep+1:])
+	if err != nil || idx < 1 {
+		d.logger.Error("invalid discord resolve button", "value", value)
+		return
+	}
+
+	bead, err := d.daemon.GetBead(ctx, beadID)
+	if err != nil {
+		d.respondEphemeral(i, ":x: Failed to load decision: "+err.Error())
+		return
+	}
+	labels := decisionOptionLabels(bead.Fields["options"])
+	if idx > len(labels) {
+		d.respondEphemeral(i, ":x: That option no longer exists")
+		return
+	}
+	chosen := labels[idx-1]
+	user := discordUserName(i)
+	fields := map[string]string{
+		"chosen":    chosen,
+		"rationale": fmt.Sprintf("Chosen by @%s via Discord", user),
+	}
+	maps.Copy(fields, discordResponder(i).AuditFields(time.Now()))
+	if err := d.daemon.CloseBead(ctx, beadID, fields); err != nil {
+		d.logger.Error("failed to resolve decision from discord", "bead", beadID, "error", err)
+		d.respondEphemeral(i, ":x: Failed to resolve decision: "+err.Error())
+		return
+	}
+	d.respondResolved(i, beadID, fmt.Sprintf("Resolved: **%s** by @%s", chosen, user), discordColorResolved)
+	d.logger.Info("decision resolved via discord", "bead", beadID, "chosen", chosen, "user", user)
+}
+
+// handleDismissButton dismisses a decision (closes the bead, greys the message).
+func (d *Discord) handleDismissButton(ctx context.Context, i *discordgo.Interaction, beadID string) {
+	user := discordUserName(i)
+	fields := map[string]string{
+		"chosen":    "dismissed",
+		"rationale": fmt.Sprintf("Dismissed by @%s via Discord", user),
+	}
+	maps.Copy(fields, discordResponder(i).AuditFields(time.Now()))
+	if err := d.daemon.CloseBead(ctx, beadID, fields); err != nil {
+		d.logger.Error("failed to dismiss decision from discord", "bead", beadID, "error", err)
+		d.respondEphemeral(i, ":x: Failed to dismiss decision: "+err.Error())
+		return
+	}
+	d.respondResolved(i, beadID, fmt.Sprintf("Dismissed by @%s", user), discordColorDismissed)
+	d.logger.Info("decision dismissed via discord", "bead", beadID, "user", user)
+}
+
+// respondResolved updates the clicked message in place and forgets it, so
+// the follow-up UpdateDecision from the SSE close event is a no-op.
+func (d *Discord) respondResolved(i *discordgo.Interaction, beadID, status string, color int) {
+	var embeds []*discordgo.MessageEmbed
+	if i.Message != nil && len(i.Message.Embeds) > 0 {
+		embed := *i.Message.Embeds[0]
+		embed.Color = color
+		embed.Fields = append(append([]*discordgo.MessageEmbedField{}, embed.Fields...),
+			&discordgo.MessageEmbedField{Name: "Status", Value: status})
+		embeds = []*discordgo.MessageEmbed{&embed}
+	}
+	if err := d.session.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     embeds,
+			Components: []discordgo.MessageComponent{},
+		},
+	}); err != nil {
+		d.logger.Error("failed to update discord decision message", "bead", beadID, "error", err)
+	}
+	d.removeDecisionMessage(beadID)
+}
+
+// handleDecisionsCommand lists pending decisions as an ephemeral reply.
+func (d *Discord) handleDecisionsCommand(ctx context.Context, i *discordgo.Interaction) {
+	decisions, err := d.daemon.ListDecisionBeads(ctx)
+	if err != nil {
+		d.logger.Error("failed to list decisions", "error", err)
+		d.respondEphemeral(i, ":x: Failed to fetch decisions")
+		return
+	}
+	if len(decisions) == 0 {
+		d.respondEphemeral(i, ":white_check_mark: No pending decisions!")
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Pending decisions** — %d\n", len(decisions))
+	for n, dec := range decisions {
+		if n == 15 {
+			fmt.Fprintf(&sb, "_…and %d more_\n", len(decisions)-n)
+			break
+		}
+		fmt.Fprintf(&sb, "• `%s` %s", dec.ID, truncateDiscord(decisionQuestion(dec.Fields), 120))
+		if dec.Assignee != "" {
+			fmt.Fprintf(&sb, " — %s", extractAgentName(dec.Assignee))
+		}
+		sb.WriteString("\n")
+	}
+	d.respondEphemeral(i, sb.String())
+}
+
+// handleRosterCommand lists active agents as an ephemeral reply.
+func (d *Discord) handleRosterCommand(ctx context.Context, i *discordgo.Interaction) {
+	agents, err := d.daemon.ListAgentBeads(ctx)
+	if err != nil {
+		d.logger.Error("failed to list agents", "error", err)
+		d.respondEphemeral(i, ":x: Failed to fetch agent roster")
+		return
+	}
+	if len(agents) == 0 {
+		d.respondEphemeral(i, ":busts_in_silhouette: No active agents")
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**Agent roster** — %d active\n", len(agents))
+	for n, a := range agents {
+		if n == 20 {
+			fmt.Fprintf(&sb, "_…and %d more_\n", len(agents)-n)
+			break
+		}
+		name := a.AgentName
+		if name == "" {
+			name = a.ID
+		}
+		fmt.Fprintf(&sb, "• **%s**", name)
+		if a.Project != "" {
+			fmt.Fprintf(&sb, " · _%s_", a.Project)
+		}
+		if a.AgentState != "" {
+			fmt.Fprintf(&sb, " · %s", a.AgentState)
+		}
+		if thread, ok := d.agentThread(name); ok {
+			fmt.Fprintf(&sb, " · <#%s>", thread)
+		}
+		sb.WriteString("\n")
+	}
+	d.respondEphemeral(i, sb.String())
+}
+
+func (d *Discord) respondEphemeral(i *discordgo.Interaction, content string) {
+	if err := d.session.InteractionRespond(i, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: truncateDiscord(content, 2000),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}); err != nil {
+		d.logger.Error("failed to respond to discord interaction", "error", err)
+	}
+}
+
+// discordUserName returns the interacting user's name, whether the
+// interaction came from a guild (Member) or a DM (User).
+// discordResponder identifies the user of an interaction for the decision
+// audit trail: their email when Discord shares it, else "discord:<user ID>".
+func discordResponder(i *discordgo.Interaction) beadsapi.Responder {
+	u := i.User
+	if i.Member != nil && i.Member.User != nil {
+		u = i.Member.User
+	}
+	r := beadsapi.Responder{Name: discordUserName(i), Via: "discord"}
+	if u == nil {
+		r.Identity = beadsapi.NormalizeIdentity("discord", "", "")
+		return r
+	}
+	r.Identity = beadsapi.NormalizeIdentity("discord", u.ID, u.Email)
+	return r
+}
+
+func discordUserName(i *discordgo.Interaction) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.Username
+	}
+	if i.User != nil {
+		return i.User.Username
+	}
+	return "unknown"
+}
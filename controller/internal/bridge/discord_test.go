@@ -0,0 +1,198 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// fakeDiscordSession records Discord API calls.
+type fakeDiscordSession struct {
+	mu        sync.Mutex
+	sent      map[string][]*discordgo.MessageSend // channel → messages
+	edits     []*discordgo.MessageEdit
+	threads   []string
+	responses []*discordgo.InteractionResponse
+	nextID    int
+}
+
+func newFakeDiscordSession() *fakeDiscordSession {
+	return &fakeDiscordSession{sent: make(map[string][]*discordgo.MessageSend)}
+}
+
+func (f *fakeDiscordSession) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent[channelID] = append(f.sent[channelID], data)
+	f.nextID++
+	return &discordgo.Message{ID: fmt.Sprintf("m%d", f.nextID), ChannelID: channelID}, nil
+}
+
+func (f *fakeDiscordSession) ChannelMessageEditComplex(m *discordgo.MessageEdit, _ ...discordgo.RequestOption) (*discordgo.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.edits = append(f.edits, m)
+	return &discordgo.Message{ID: m.ID, ChannelID: m.Channel}, nil
+}
+
+func (f *fakeDiscordSession) ThreadStart(_, name string, _ discordgo.ChannelType, _ int, _ ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.threads = append(f.threads, name)
+	return &discordgo.Channel{ID: "T-" + name, Name: name}, nil
+}
+
+func (f *fakeDiscordSession) InteractionRespond(_ *discordgo.Interaction, resp *discordgo.InteractionResponse, _ ...discordgo.RequestOption) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = append(f.responses, resp)
+	return nil
+}
+
+func newTestDiscord(t *testing.T, daemon BeadClient, threading string) (*Discord, *fakeDiscordSession) {
+	t.Helper()
+	fake := newFakeDiscordSession()
+	d := newDiscord(fake, DiscordConfig{
+		ChannelID:     "C1",
+		ThreadingMode: threading,
+		Daemon:        daemon,
+		State:         newMuteTestState(t),
+		Logger:        slog.Default(),
+	})
+	return d, fake
+}
+
+func TestDiscord_NotifyDecisionPostsEmbedWithButtons(t *testing.T) {
+	d, fake := newTestDiscord(t, newMockDaemon(), "")
+	err := d.NotifyDecision(context.Background(), BeadEvent{
+		ID:       "dec-1",
+		Assignee: "gasboat/crew/test-bot",
+		Fields: map[string]string{
+			"question": "Ship it?",
+			"options":  `[{"id":"y","label":"Yes"},{"id":"n","short":"No"}]`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NotifyDecision: %v", err)
+	}
+
+	msgs := fake.sent["C1"]
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message in C1, got %d", len(msgs))
+	}
+	if got := msgs[0].Embeds[0].Description; got != "Ship it?" {
+		t.Errorf("embed description = %q", got)
+	}
+	row := msgs[0].Components[0].(discordgo.ActionsRow)
+	var ids []string
+	for _, c := range row.Components {
+		ids = append(ids, c.(discordgo.Button).CustomID)
+	}
+	if want := "resolve:dec-1:1,resolve:dec-1:2,dismiss:dec-1"; strings.Join(ids, ",") != want {
+		t.Errorf("button IDs = %v, want %s", ids, want)
+	}
+	if ref, ok := d.decisionMessage("dec-1"); !ok || ref.Timestamp != "m1" {
+		t.Errorf("expected message ref stored, got %+v", ref)
+	}
+
+	if err := d.UpdateDecision(context.Background(), "dec-1", "Yes"); err != nil {
+		t.Fatalf("UpdateDecision: %v", err)
+	}
+	if len(fake.edits) != 1 || len(*fake.edits[0].Components) != 0 {
+		t.Fatalf("expected one edit removing buttons, got %+v", fake.edits)
+	}
+	if _, ok := d.decisionMessage("dec-1"); ok {
+		t.Error("expected message ref removed after resolve")
+	}
+}
+
+func TestDiscord_AgentThreadingPostsIntoThread(t *testing.T) {
+	d, fake := newTestDiscord(t, newMockDaemon(), "agent")
+	d.NotifyAgentSpawn(context.Background(), BeadEvent{Type: "agent", Fields: map[string]string{"agent": "test-bot"}})
+	_ = d.NotifyDecision(context.Background(), BeadEvent{ID: "dec-1", Assignee: "gasboat/crew/test-bot", Fields: map[string]string{"options": `["a"]`}})
+	_ = d.NotifyDecision(context.Background(), BeadEvent{ID: "dec-2", Assignee: "gasboat/crew/test-bot", Fields: map[string]string{"options": `["b"]`}})
+
+	if len(fake.threads) != 1 || fake.threads[0] != "agent-test-bot" {
+		t.Fatalf("expected one agent thread, got %v", fake.threads)
+	}
+	if n := len(fake.sent["T-agent-test-bot"]); n != 2 {
+		t.Errorf("expected 2 decisions in agent thread, got %d", n)
+	}
+	if n := len(fake.sent["C1"]); n != 0 {
+		t.Errorf("expected nothing in main channel, got %d", n)
+	}
+}
+
+func TestDiscord_ResolveButtonClosesBead(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["dec-1"] = &beadsapi.BeadDetail{ID: "dec-1", Fields: map[string]string{"options": `["Yes","No"]`}}
+	d, fake := newTestDiscord(t, daemon, "")
+
+	d.handleInteraction(context.Background(), &discordgo.Interaction{
+		Type:    discordgo.InteractionMessageComponent,
+		Data:    discordgo.MessageComponentInteractionData{CustomID: "resolve:dec-1:2"},
+		Member:  &discordgo.Member{User: &discordgo.User{Username: "alice"}},
+		Message: &discordgo.Message{Embeds: []*discordgo.MessageEmbed{{Title: "Decision needed"}}},
+	})
+
+	closed := daemon.getClosed()
+	if len(closed) != 1 {
+		t.Fatalf("expected bead closed, got %+v", closed)
+	}
+	if f := closed[0].Fields; f["chosen"] != "No" || f["rationale"] != "Chosen by @alice via Discord" {
+		t.Errorf("unexpected close fields %+v", f)
+	}
+	if len(fake.responses) != 1 || fake.responses[0].Type != discordgo.InteractionResponseUpdateMessage {
+		t.Fatalf("expected update-message response, got %+v", fake.responses)
+	}
+	if embeds := fake.responses[0].Data.Embeds; len(embeds) != 1 || embeds[0].Color != discordColorResolved {
+		t.Errorf("expected resolved embed, got %+v", embeds)
+	}
+}
+
+func TestDiscord_RosterCommandIsEphemeral(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["crew-gasboat-crew-k8s"] = &beadsapi.BeadDetail{
+		ID: "crew-gasboat-crew-k8s", Type: "agent",
+		Fields: map[string]string{"agent": "k8s", "project": "gasboat"},
+	}
+	d, fake := newTestDiscord(t, daemon, "")
+
+	d.handleInteraction(context.Background(), &discordgo.Interaction{
+		Type: discordgo.InteractionApplicationCommand,
+		Data: discordgo.ApplicationCommandInteractionData{Name: "roster"},
+	})
+
+	if len(fake.responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(fake.responses))
+	}
+	data := fake.responses[0].Data
+	if data.Flags&discordgo.MessageFlagsEphemeral == 0 {
+		t.Error("expected ephemeral response")
+	}
+	if !strings.Contains(data.Content, "**k8s** · _gasboat_") {
+		t.Errorf("unexpected roster content %q", data.Content)
+	}
+}
+
+func TestDecisionButtons_CapsAtDiscordLimit(t *testing.T) {
+	labels := make([]string, 30)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("opt %d", i)
+	}
+	rows := decisionButtons("dec-1", labels)
+	if len(rows) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(rows))
+	}
+	last := rows[4].(discordgo.ActionsRow).Components
+	if id := last[len(last)-1].(discordgo.Button).CustomID; id != "dismiss:dec-1" {
+		t.Errorf("expected dismiss button last, got %s", id)
+	}
+}
@@ -0,0 +1,72 @@
+// Package bridge provides the error report watcher.
+//
+// ErrorReports subscribes to kbeads SSE event stream for bead create events,
+// filters for controller.errors bus events (event beads published by the
+// controller's error aggregator), and posts one alert per report.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"gasboat/controller/internal/errorreporter"
+)
+
+// ErrorReportNotifier posts rolled-up error reports.
+type ErrorReportNotifier interface {
+	NotifyErrorReport(ctx context.Context, report errorreporter.Report) error
+}
+
+// ErrorReportsConfig holds configuration for the ErrorReports watcher.
+type ErrorReportsConfig struct {
+	Notifier ErrorReportNotifier // nil = no notifications
+	Logger   *slog.Logger
+}
+
+// ErrorReports watches the kbeads SSE event stream for error report events.
+type ErrorReports struct {
+	notifier ErrorReportNotifier
+	logger   *slog.Logger
+}
+
+// NewErrorReports creates a new error report watcher.
+func NewErrorReports(cfg ErrorReportsConfig) *ErrorReports {
+	return &ErrorReports{
+		notifier: cfg.Notifier,
+		logger:   cfg.Logger,
+	}
+}
+
+// RegisterHandlers registers SSE event handlers on the given stream for
+// event bead created events.
+func (e *ErrorReports) RegisterHandlers(stream *SSEStream) {
+	stream.On("beads.bead.created", e.handleCreated)
+	e.logger.Info("error reports watcher registered SSE handlers",
+		"topics", []string{"beads.bead.created"})
+}
+
+func (e *ErrorReports) handleCreated(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	if bead.Type != "event" || bead.Fields["topic"] != errorreporter.Topic {
+		return
+	}
+
+	var report errorreporter.Report
+	if err := json.Unmarshal([]byte(bead.Fields["payload"]), &report); err != nil {
+		e.logger.Warn("skipping malformed error report", "id", bead.ID, "error", err)
+		return
+	}
+	e.logger.Info("error report received",
+		"id", bead.ID, "source", report.Source, "total", report.Total, "groups", len(report.Groups))
+
+	if e.notifier == nil {
+		return
+	}
+	if err := e.notifier.NotifyErrorReport(ctx, report); err != nil {
+		e.logger.Error("failed to post error report", "id", bead.ID, "error", err)
+	}
+}
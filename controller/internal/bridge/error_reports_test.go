@@ -0,0 +1,53 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"gasboat/controller/internal/errorreporter"
+)
+
+type mockErrorReportNotifier struct {
+	reports []errorreporter.Report
+}
+
+func (m *mockErrorReportNotifier) NotifyErrorReport(_ context.Context, report errorreporter.Report) error {
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+func TestErrorReports_HandleCreated(t *testing.T) {
+	notif := &mockErrorReportNotifier{}
+	e := NewErrorReports(ErrorReportsConfig{Notifier: notif, Logger: slog.Default()})
+
+	payload, _ := json.Marshal(errorreporter.Report{
+		Source: "controller",
+		Total:  200,
+		Groups: []errorreporter.Group{{Level: "ERROR", Message: "creating pod failed", Error: "quota exceeded", Count: 200}},
+	})
+
+	// Other beads and other bus topics are ignored.
+	e.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{ID: "kd-1", Type: "task"}))
+	e.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-2", Type: "event", Fields: map[string]string{"topic": "deploy.done", "payload": string(payload)},
+	}))
+	// Malformed payloads are skipped.
+	e.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-3", Type: "event", Fields: map[string]string{"topic": errorreporter.Topic, "payload": "{"},
+	}))
+	if len(notif.reports) != 0 {
+		t.Fatalf("expected no notifications, got %d", len(notif.reports))
+	}
+
+	e.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "kd-4", Type: "event", Fields: map[string]string{"topic": errorreporter.Topic, "payload": string(payload)},
+	}))
+	if len(notif.reports) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notif.reports))
+	}
+	if r := notif.reports[0]; r.Total != 200 || r.Groups[0].Error != "quota exceeded" {
+		t.Errorf("unexpected report: %+v", r)
+	}
+}
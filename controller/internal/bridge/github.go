@@ -1,12 +1,14 @@
 package bridge
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -15,6 +17,7 @@ type GitHubClient struct {
 	httpClient *http.Client
 	baseURL    string // defaults to "https://api.github.com"
 	token      string
+	app        *GitHubAppAuth // when set, requests use installation tokens instead of token
 	logger     *slog.Logger
 }
 
@@ -72,6 +75,20 @@ func NewGitHubClient(token string, logger *slog.Logger) *GitHubClient {
 	}
 }
 
+// NewGitHubAppClient creates a GitHub REST API client authenticated as a
+// GitHub App installation. baseURL is optional (GitHub Enterprise).
+func NewGitHubAppClient(app *GitHubAppAuth, baseURL string, logger *slog.Logger) *GitHubClient {
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+	return &GitHubClient{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		app:        app,
+		logger:     logger,
+	}
+}
+
 // GetLatestTag returns the most recent tag for the repo.
 func (c *GitHubClient) GetLatestTag(ctx context.Context, repo RepoRef) (string, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s/tags?per_page=1", c.baseURL, repo.Owner, repo.Repo)
@@ -130,13 +147,36 @@ func (c *GitHubClient) GetUnreleased(ctx context.Context, repo RepoRef, branch s
 
 // doJSON performs a GET request and decodes the JSON response.
 func (c *GitHubClient) doJSON(ctx context.Context, url string, result any) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	return c.do(ctx, http.MethodGet, url, nil, result)
+}
+
+// do performs a request with an optional JSON body and decodes the JSON
+// response into result (if non-nil).
+func (c *GitHubClient) do(ctx context.Context, method, url string, body, result any) error {
+	var bodyReader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal GitHub request: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
-	if c.token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	token := c.token
+	if c.app != nil {
+		if token, err = c.app.Token(ctx); err != nil {
+			return err
+		}
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
 	resp, err := c.httpClient.Do(req)
@@ -145,16 +185,19 @@ func (c *GitHubClient) doJSON(ctx context.Context, url string, result any) error
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("read GitHub response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("GitHub API %s returned %d: %s", url, resp.StatusCode, truncate(string(body), 256))
+		return fmt.Errorf("GitHub API %s %s returned %d: %s", method, url, resp.StatusCode, truncate(string(respBody), 256))
 	}
 
-	if err := json.Unmarshal(body, result); err != nil {
+	if result == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, result); err != nil {
 		return fmt.Errorf("decode GitHub response: %w", err)
 	}
 	return nil
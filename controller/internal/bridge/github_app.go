@@ -0,0 +1,161 @@
+// Package bridge provides GitHub App authentication.
+//
+// GitHubAppAuth signs short-lived app JWTs with the App's private key and
+// exchanges them for installation access tokens, which it caches until
+// shortly before they expire. GitHubClient uses it in place of a static
+// token when created with NewGitHubAppClient; check runs in particular can
+// only be written by an App.
+package bridge
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// githubTokenRefreshSkew renews installation tokens this long before they
+// expire so in-flight requests never carry a stale token.
+const githubTokenRefreshSkew = 5 * time.Minute
+
+// GitHubAppConfig holds configuration for GitHub App authentication.
+type GitHubAppConfig struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     []byte // PEM-encoded RSA key (PKCS#1 or PKCS#8)
+	BaseURL        string // API base URL (default "https://api.github.com")
+}
+
+// GitHubAppAuth mints installation access tokens for a GitHub App.
+type GitHubAppAuth struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	baseURL        string
+	httpClient     *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewGitHubAppAuth parses the App's private key and returns an
+// authenticator for the installation.
+func NewGitHubAppAuth(cfg GitHubAppConfig) (*GitHubAppAuth, error) {
+	if cfg.AppID == 0 || cfg.InstallationID == 0 {
+		return nil, errors.New("GitHub App ID and installation ID are required")
+	}
+	key, err := parseRSAPrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.github.com"
+	}
+	return &GitHubAppAuth{
+		appID:          cfg.AppID,
+		installationID: cfg.InstallationID,
+		key:            key,
+		baseURL:        strings.TrimRight(cfg.BaseURL, "/"),
+		httpClient:     &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// Token returns a valid installation access token, minting a new one when
+// the cached token is missing or about to expire.
+func (a *GitHubAppAuth) Token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token != "" && time.Until(a.expires) > githubTokenRefreshSkew {
+		return a.token, nil
+	}
+
+	jwt, err := a.appJWT(time.Now())
+	if err != nil {
+		return "", err
+	}
+	url := fmt.Sprintf("%s/app/installations/%d/access_tokens", a.baseURL, a.installationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("create installation token request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("GitHub installation token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read installation token response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("GitHub installation token returned %d: %s", resp.StatusCode, truncate(string(body), 256))
+	}
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("decode installation token: %w", err)
+	}
+	a.token, a.expires = result.Token, result.ExpiresAt
+	return a.token, nil
+}
+
+// appJWT returns an RS256-signed JWT identifying the App. GitHub accepts
+// at most ten minutes of validity; iat is backdated for clock drift.
+func (a *GitHubAppAuth) appJWT(now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": fmt.Sprint(a.appID),
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshal JWT claims: %w", err)
+	}
+	signingInput := header + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign JWT: %w", err)
+	}
+	return signingInput + "." + enc.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKey decodes a PEM RSA private key in PKCS#1 or PKCS#8 form.
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
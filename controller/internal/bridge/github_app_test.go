@@ -0,0 +1,88 @@
+package bridge
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGitHubAppAuth_MintsAndCachesInstallationToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	var mints atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app/installations/99/access_tokens":
+			mints.Add(1)
+			jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			parts := strings.Split(jwt, ".")
+			if len(parts) != 3 {
+				t.Errorf("malformed JWT %q", jwt)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+			digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+			if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+				t.Errorf("JWT signature: %v", err)
+			}
+			claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+			var c struct {
+				Iss string `json:"iss"`
+			}
+			_ = json.Unmarshal(claims, &c)
+			if c.Iss != "42" {
+				t.Errorf("iss = %q, want 42", c.Iss)
+			}
+			writeJSON(w, map[string]any{"token": "ghs_abc", "expires_at": time.Now().Add(time.Hour)})
+		case "/repos/org/web/issues/1/comments":
+			if got := r.Header.Get("Authorization"); got != "Bearer ghs_abc" {
+				t.Errorf("Authorization = %q", got)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	app, err := NewGitHubAppAuth(GitHubAppConfig{AppID: 42, InstallationID: 99, PrivateKey: keyPEM, BaseURL: srv.URL})
+	if err != nil {
+		t.Fatalf("NewGitHubAppAuth: %v", err)
+	}
+	c := NewGitHubAppClient(app, srv.URL, nil)
+	for range 2 {
+		if err := c.CreateIssueComment(context.Background(), RepoRef{Owner: "org", Repo: "web"}, 1, "hi"); err != nil {
+			t.Fatalf("CreateIssueComment: %v", err)
+		}
+	}
+	if n := mints.Load(); n != 1 {
+		t.Errorf("expected the installation token to be minted once, got %d", n)
+	}
+}
+
+func TestNewGitHubAppAuth_RejectsBadKey(t *testing.T) {
+	if _, err := NewGitHubAppAuth(GitHubAppConfig{AppID: 1, InstallationID: 2, PrivateKey: []byte("not a key")}); err == nil {
+		t.Error("expected error for non-PEM key")
+	}
+	if _, err := NewGitHubAppAuth(GitHubAppConfig{PrivateKey: []byte("x")}); err == nil {
+		t.Error("expected error without app and installation IDs")
+	}
+}
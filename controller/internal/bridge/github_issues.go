@@ -0,0 +1,178 @@
+// Package bridge provides the GitHub issue, pull request, and check run API
+// calls used by the github-bridge.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// githubPageSize is the per_page used when listing issues.
+const githubPageSize = 100
+
+// GitHubIssue is a GitHub issue from the REST API. Pull requests also appear
+// in the issues API; they carry a non-nil PullRequest.
+type GitHubIssue struct {
+	Number      int           `json:"number"`
+	Title       string        `json:"title"`
+	Body        string        `json:"body"`
+	State       string        `json:"state"` // "open" or "closed"
+	HTMLURL     string        `json:"html_url"`
+	Labels      []GitHubLabel `json:"labels"`
+	User        *GitHubUser   `json:"user"`
+	PullRequest *struct{}     `json:"pull_request,omitempty"`
+}
+
+// GitHubLabel is an issue label.
+type GitHubLabel struct {
+	Name string `json:"name"`
+}
+
+// GitHubUser is a GitHub account.
+type GitHubUser struct {
+	Login string `json:"login"`
+}
+
+// HasLabel reports whether the issue carries the label (case-insensitive).
+func (i GitHubIssue) HasLabel(name string) bool {
+	for _, l := range i.Labels {
+		if strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GitHubPullRequest is the subset of a pull request used for check runs.
+type GitHubPullRequest struct {
+	Number int    `json:"number"`
+	State  string `json:"state"`
+	Head   struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+// GitHubCheckRun is the writable part of a check run. Status is "queued",
+// "in_progress", or "completed"; Conclusion is required when completed.
+type GitHubCheckRun struct {
+	Name       string                `json:"name,omitempty"`
+	HeadSHA    string                `json:"head_sha,omitempty"`
+	Status     string                `json:"status,omitempty"`
+	Conclusion string                `json:"conclusion,omitempty"`
+	DetailsURL string                `json:"details_url,omitempty"`
+	Output     *GitHubCheckRunOutput `json:"output,omitempty"`
+}
+
+// GitHubCheckRunOutput is the title and summary shown on a check run.
+type GitHubCheckRunOutput struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+}
+
+// ListLabeledIssues returns the open issues (not pull requests) in repo that
+// carry label, following pagination.
+func (c *GitHubClient) ListLabeledIssues(ctx context.Context, repo RepoRef, label string) ([]GitHubIssue, error) {
+	var all []GitHubIssue
+	for page := 1; ; page++ {
+		q := url.Values{}
+		q.Set("state", "open")
+		q.Set("labels", label)
+		q.Set("per_page", strconv.Itoa(githubPageSize))
+		q.Set("page", strconv.Itoa(page))
+		u := fmt.Sprintf("%s/repos/%s/%s/issues?%s", c.baseURL, repo.Owner, repo.Repo, q.Encode())
+
+		var issues []GitHubIssue
+		if err := c.doJSON(ctx, u, &issues); err != nil {
+			return all, fmt.Errorf("list %s issues: %w", repo, err)
+		}
+		for _, issue := range issues {
+			if issue.PullRequest == nil {
+				all = append(all, issue)
+			}
+		}
+		if len(issues) < githubPageSize {
+			return all, nil
+		}
+	}
+}
+
+// CreateIssueComment posts a comment on an issue or pull request.
+func (c *GitHubClient) CreateIssueComment(ctx context.Context, repo RepoRef, number int, body string) error {
+	u := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", c.baseURL, repo.Owner, repo.Repo, number)
+	if err := c.do(ctx, http.MethodPost, u, map[string]string{"body": body}, nil); err != nil {
+		return fmt.Errorf("comment on %s#%d: %w", repo, number, err)
+	}
+	return nil
+}
+
+// GetPullRequest fetches a pull request.
+func (c *GitHubClient) GetPullRequest(ctx context.Context, repo RepoRef, number int) (*GitHubPullRequest, error) {
+	u := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", c.baseURL, repo.Owner, repo.Repo, number)
+	var pr GitHubPullRequest
+	if err := c.doJSON(ctx, u, &pr); err != nil {
+		return nil, fmt.Errorf("get %s#%d: %w", repo, number, err)
+	}
+	return &pr, nil
+}
+
+// CreateCheckRun creates a check run and returns its ID.
+func (c *GitHubClient) CreateCheckRun(ctx context.Context, repo RepoRef, run GitHubCheckRun) (int64, error) {
+	u := fmt.Sprintf("%s/repos/%s/%s/check-runs", c.baseURL, repo.Owner, repo.Repo)
+	var result struct {
+		ID int64 `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, u, run, &result); err != nil {
+		return 0, fmt.Errorf("create check run on %s: %w", repo, err)
+	}
+	return result.ID, nil
+}
+
+// UpdateCheckRun updates an existing check run.
+func (c *GitHubClient) UpdateCheckRun(ctx context.Context, repo RepoRef, id int64, run GitHubCheckRun) error {
+	u := fmt.Sprintf("%s/repos/%s/%s/check-runs/%d", c.baseURL, repo.Owner, repo.Repo, id)
+	if err := c.do(ctx, http.MethodPatch, u, run, nil); err != nil {
+		return fmt.Errorf("update check run %d on %s: %w", id, repo, err)
+	}
+	return nil
+}
+
+// githubPullURL matches GitHub pull request URLs, e.g.
+// https://github.com/org/repo/pull/42.
+var githubPullURL = regexp.MustCompile(`^https://[^/]+/([^/]+)/([^/]+)/pull/(\d+)`)
+
+// ParseGitHubPullURL extracts the repository and number from a pull request
+// URL. It reports false for other URLs (e.g. GitLab merge requests).
+func ParseGitHubPullURL(u string) (RepoRef, int, bool) {
+	m := githubPullURL.FindStringSubmatch(u)
+	if m == nil {
+		return RepoRef{}, 0, false
+	}
+	n, _ := strconv.Atoi(m[3])
+	return RepoRef{Owner: m[1], Repo: m[2]}, n, true
+}
+
+// ParseGitHubIssueRef parses an "owner/repo#123" issue reference.
+func ParseGitHubIssueRef(ref string) (RepoRef, int, bool) {
+	repoPart, num, ok := strings.Cut(ref, "#")
+	if !ok {
+		return RepoRef{}, 0, false
+	}
+	owner, name, ok := strings.Cut(repoPart, "/")
+	n, err := strconv.Atoi(num)
+	if !ok || owner == "" || name == "" || err != nil {
+		return RepoRef{}, 0, false
+	}
+	return RepoRef{Owner: owner, Repo: name}, n, true
+}
+
+// githubIssueRef formats an "owner/repo#123" issue reference. GitHub names
+// are case-insensitive, so the reference is lowercased to give one key per
+// issue whether it came from config, a poll, or a webhook.
+func githubIssueRef(repo RepoRef, number int) string {
+	return strings.ToLower(fmt.Sprintf("%s#%d", repo, number))
+}
@@ -0,0 +1,283 @@
+// Package bridge provides the GitHub issue polling loop.
+//
+// GitHubPoller imports open issues carrying the configured label from each
+// watched repository as task beads, mirroring JiraPoller: it deduplicates by
+// tracking "owner/repo#N" → bead ID, runs a CatchUp pass on startup, and
+// serves as the reconciliation fallback for GitHubWebhook, which creates
+// beads through the same Ingest path.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// defaultGitHubLabel is the issue label that opts an issue into import.
+const defaultGitHubLabel = "gasboat"
+
+// GitHubBeadClient is the subset of beadsapi.Client used by the GitHub poller.
+type GitHubBeadClient interface {
+	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
+	ListTaskBeads(ctx context.Context) ([]*beadsapi.BeadDetail, error)
+}
+
+// GitHubPollerConfig holds configuration for the GitHub poller.
+type GitHubPollerConfig struct {
+	Repos        []RepoRef         // repositories to watch
+	Label        string            // issue label to import (default "gasboat")
+	PollInterval time.Duration     // polling interval (default 5m)
+	ProjectMap   map[string]string // "owner/repo" (lower) → boat project name
+	Logger       *slog.Logger
+}
+
+// GitHubPoller polls GitHub for labeled issues and creates task beads.
+type GitHubPoller struct {
+	github *GitHubClient
+	daemon GitHubBeadClient
+	cfg    GitHubPollerConfig
+
+	ingestMu sync.Mutex // serializes check-and-create so webhook and poll don't race
+	mu       sync.Mutex
+	tracked  map[string]string // "owner/repo#N" → bead ID
+}
+
+// NewGitHubPoller creates a new GitHub polling loop.
+func NewGitHubPoller(github *GitHubClient, daemon GitHubBeadClient, cfg GitHubPollerConfig) *GitHubPoller {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Minute
+	}
+	if cfg.Label == "" {
+		cfg.Label = defaultGitHubLabel
+	}
+	return &GitHubPoller{
+		github:  github,
+		daemon:  daemon,
+		cfg:     cfg,
+		tracked: make(map[string]string),
+	}
+}
+
+// Run starts the polling loop. It runs CatchUp once, then polls at the
+// configured interval until ctx is canceled.
+func (p *GitHubPoller) Run(ctx context.Context) error {
+	p.CatchUp(ctx)
+
+	p.cfg.Logger.Info("GitHub poller started",
+		"repos", len(p.cfg.Repos),
+		"label", p.cfg.Label,
+		"interval", p.cfg.PollInterval)
+
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	p.poll(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+// CatchUp populates the tracked map from existing task beads so restarts
+// don't create duplicates.
+func (p *GitHubPoller) CatchUp(ctx context.Context) {
+	beads, err := p.daemon.ListTaskBeads(ctx)
+	if err != nil {
+		p.cfg.Logger.Warn("GitHub poller catch-up: failed to list task beads", "error", err)
+		return
+	}
+	count := p.trackBeads(beads)
+	p.cfg.Logger.Info("GitHub poller catch-up complete", "tracked", count)
+}
+
+// trackBeads records the github_issue field of each bead and returns how
+// many beads carried one.
+func (p *GitHubPoller) trackBeads(beads []*beadsapi.BeadDetail) int {
+	count := 0
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, b := range beads {
+		if ref := b.Fields["github_issue"]; ref != "" {
+			if _, exists := p.tracked[ref]; !exists {
+				p.tracked[ref] = b.ID
+			}
+			count++
+		}
+	}
+	return count
+}
+
+// poll executes a single GitHub poll cycle.
+func (p *GitHubPoller) poll(ctx context.Context) {
+	// Self-heal the tracked map from live data, as JiraPoller does.
+	if beads, err := p.daemon.ListTaskBeads(ctx); err == nil {
+		p.trackBeads(beads)
+	}
+
+	found, created, skipped := 0, 0, 0
+	for _, repo := range p.cfg.Repos {
+		issues, err := p.github.ListLabeledIssues(ctx, repo, p.cfg.Label)
+		if err != nil {
+			p.cfg.Logger.Error("GitHub poll failed", "repo", repo.String(), "error", err)
+			continue
+		}
+		found += len(issues)
+		for _, issue := range issues {
+			ok, err := p.Ingest(ctx, repo, issue)
+			switch {
+			case err != nil:
+				continue
+			case ok:
+				created++
+			default:
+				skipped++
+			}
+		}
+	}
+
+	if created > 0 || p.cfg.Logger.Enabled(ctx, slog.LevelDebug) {
+		p.cfg.Logger.Info("GitHub poll complete",
+			"found", found, "created", created, "skipped", skipped)
+	}
+}
+
+// Matches reports whether an issue should be imported: an open issue (not a
+// pull request) in a watched repository carrying the import label.
+func (p *GitHubPoller) Matches(repo RepoRef, issue GitHubIssue) bool {
+	if issue.PullRequest != nil || issue.State != "open" || !issue.HasLabel(p.cfg.Label) {
+		return false
+	}
+	for _, r := range p.cfg.Repos {
+		if strings.EqualFold(r.String(), repo.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ingest creates a task bead for the issue unless one is already tracked.
+// It reports whether a bead was created.
+func (p *GitHubPoller) Ingest(ctx context.Context, repo RepoRef, issue GitHubIssue) (bool, error) {
+	p.ingestMu.Lock()
+	defer p.ingestMu.Unlock()
+
+	ref := githubIssueRef(repo, issue.Number)
+	if _, ok := p.TrackedBead(ref); ok {
+		return false, nil
+	}
+
+	beadID, err := p.createBeadFromIssue(ctx, repo, issue)
+	if err != nil {
+		p.cfg.Logger.Error("failed to create bead for GitHub issue",
+			"issue", ref, "error", err)
+		return false, err
+	}
+
+	p.mu.Lock()
+	p.tracked[ref] = beadID
+	p.mu.Unlock()
+
+	p.cfg.Logger.Info("created bead for GitHub issue",
+		"issue", ref, "bead_id", beadID, "title", issue.Title)
+	return true, nil
+}
+
+// createBeadFromIssue creates a task bead from a GitHub issue. Caller must
+// hold p.ingestMu.
+func (p *GitHubPoller) createBeadFromIssue(ctx context.Context, repo RepoRef, issue GitHubIssue) (string, error) {
+	ref := githubIssueRef(repo, issue.Number)
+
+	// Map the repository to a boat project, falling back to the repo name.
+	project, ok := p.cfg.ProjectMap[strings.ToLower(repo.String())]
+	if !ok {
+		project = strings.ToLower(repo.Repo)
+	}
+
+	labels := []string{
+		"source:github",
+		"github:" + ref,
+		"project:" + project,
+	}
+	for _, l := range issue.Labels {
+		if !strings.EqualFold(l.Name, p.cfg.Label) {
+			labels = append(labels, "github-label:"+l.Name)
+		}
+	}
+
+	fields := map[string]string{
+		"github_issue": ref,
+		"github_repo":  repo.String(),
+		"github_url":   issue.HTMLURL,
+	}
+	if issue.User != nil {
+		fields["github_author"] = issue.User.Login
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("marshal fields: %w", err)
+	}
+
+	beadID, err := p.daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title:       fmt.Sprintf("[%s] %s", ref, issue.Title),
+		Type:        "task",
+		Description: issue.Body,
+		Labels:      labels,
+		Priority:    githubIssuePriority(issue),
+		CreatedBy:   "github-bridge",
+		Fields:      fieldsJSON,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create bead: %w", err)
+	}
+	return beadID, nil
+}
+
+// githubIssuePriority maps priority labels ("P0".."P4" or "priority:high"
+// style) to a bead priority, defaulting to medium (2).
+func githubIssuePriority(issue GitHubIssue) int {
+	for _, l := range issue.Labels {
+		name := strings.ToLower(l.Name)
+		name = strings.TrimPrefix(name, "priority:")
+		name = strings.TrimPrefix(name, "priority/")
+		name = strings.TrimSpace(name)
+		switch name {
+		case "p0", "critical":
+			return 0
+		case "p1", "high":
+			return 1
+		case "p2", "medium":
+			return 2
+		case "p3", "low":
+			return 3
+		case "p4", "lowest":
+			return 4
+		}
+	}
+	return 2
+}
+
+// TrackedBead returns the bead ID tracked for an "owner/repo#N" reference.
+func (p *GitHubPoller) TrackedBead(ref string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id, ok := p.tracked[ref]
+	return id, ok
+}
+
+// TrackedCount returns the number of tracked GitHub issues.
+func (p *GitHubPoller) TrackedCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.tracked)
+}
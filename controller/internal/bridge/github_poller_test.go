@@ -0,0 +1,87 @@
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestGitHubPoller_ImportsLabeledIssues(t *testing.T) {
+	f, gh := newFakeGitHub(t)
+	f.issues = []GitHubIssue{
+		{Number: 12, Title: "Fix login", Body: "Steps...", State: "open", HTMLURL: "https://github.com/Org/Web/issues/12",
+			Labels: []GitHubLabel{{Name: "gasboat"}, {Name: "P1"}, {Name: "bug"}}, User: &GitHubUser{Login: "alice"}},
+		{Number: 13, Title: "A pull request", State: "open", Labels: []GitHubLabel{{Name: "gasboat"}}, PullRequest: &struct{}{}},
+	}
+	daemon := newMockJiraDaemon()
+	poller := NewGitHubPoller(gh, daemon, GitHubPollerConfig{
+		Repos:      []RepoRef{{Owner: "Org", Repo: "Web"}},
+		ProjectMap: map[string]string{"org/web": "monorepo"},
+		Logger:     slog.Default(),
+	})
+
+	poller.poll(context.Background())
+	poller.poll(context.Background()) // second poll must not duplicate
+
+	beads := daemon.getBeads()
+	if len(beads) != 1 {
+		t.Fatalf("expected 1 bead, got %d", len(beads))
+	}
+	for _, b := range beads {
+		if b.Title != "[org/web#12] Fix login" {
+			t.Errorf("title = %q", b.Title)
+		}
+		if b.Fields["github_issue"] != "org/web#12" || b.Fields["github_author"] != "alice" || b.Fields["_priority"] != "1" {
+			t.Errorf("fields = %v", b.Fields)
+		}
+		for _, want := range []string{"source:github", "github:org/web#12", "project:monorepo", "github-label:bug"} {
+			if !hasLabel(b.Labels, want) {
+				t.Errorf("missing label %q in %v", want, b.Labels)
+			}
+		}
+		if hasLabel(b.Labels, "github-label:gasboat") {
+			t.Error("import label should not be copied")
+		}
+	}
+	if _, ok := poller.TrackedBead("org/web#12"); !ok {
+		t.Error("issue should be tracked")
+	}
+}
+
+func TestGitHubPoller_Matches(t *testing.T) {
+	poller := NewGitHubPoller(nil, newMockJiraDaemon(), GitHubPollerConfig{
+		Repos: []RepoRef{{Owner: "org", Repo: "web"}}, Label: "agent", Logger: slog.Default(),
+	})
+	labeled := GitHubIssue{Number: 1, State: "open", Labels: []GitHubLabel{{Name: "Agent"}}}
+	tests := []struct {
+		name  string
+		repo  RepoRef
+		issue GitHubIssue
+		want  bool
+	}{
+		{"labeled open issue", RepoRef{Owner: "Org", Repo: "Web"}, labeled, true},
+		{"unwatched repo", RepoRef{Owner: "org", Repo: "api"}, labeled, false},
+		{"unlabeled", RepoRef{Owner: "org", Repo: "web"}, GitHubIssue{State: "open"}, false},
+		{"closed", RepoRef{Owner: "org", Repo: "web"}, GitHubIssue{State: "closed", Labels: labeled.Labels}, false},
+	}
+	for _, tt := range tests {
+		if got := poller.Matches(tt.repo, tt.issue); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseGitHubRefs(t *testing.T) {
+	if repo, n, ok := ParseGitHubPullURL("https://github.com/org/web/pull/42/files"); !ok || repo.String() != "org/web" || n != 42 {
+		t.Errorf("ParseGitHubPullURL = %v, %d, %v", repo, n, ok)
+	}
+	if _, _, ok := ParseGitHubPullURL("https://gitlab.com/org/web/-/merge_requests/42"); ok {
+		t.Error("GitLab MR URL should not parse as a GitHub PR")
+	}
+	if repo, n, ok := ParseGitHubIssueRef("org/web#12"); !ok || repo.String() != "org/web" || n != 12 {
+		t.Errorf("ParseGitHubIssueRef = %v, %d, %v", repo, n, ok)
+	}
+	if _, _, ok := ParseGitHubIssueRef("PE-12"); ok {
+		t.Error("JIRA key should not parse as a GitHub issue")
+	}
+}
@@ -0,0 +1,274 @@
+// Package bridge provides the GitHub sync-back watcher.
+//
+// GitHubSync subscribes to kbeads SSE bead updated/closed events for beads
+// imported by GitHubPoller (those with a github_issue field) and reports
+// agent activity back to GitHub: progress notes and pull request links are
+// posted as issue comments, and when the bead's mr_url is a GitHub pull
+// request a check run on the PR's head commit tracks the bead's status.
+// In the other direction, ApplyIssueClosed (called by the webhook receiver)
+// closes the bead when its issue is closed.
+package bridge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// githubCommentMarker prefixes every comment the bridge posts to GitHub.
+const githubCommentMarker = "[gasboat]"
+
+// githubNotesSyncedField records a hash of the bead notes last posted to
+// GitHub, so restarts and unrelated bead updates don't repost them.
+const githubNotesSyncedField = "github_notes_synced"
+
+// defaultGitHubCheckName names the check run shown on agent pull requests.
+const defaultGitHubCheckName = "gasboat/agent"
+
+// GitHubSyncClient is the subset of beadsapi.Client used by GitHubSync.
+type GitHubSyncClient interface {
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+	CloseBead(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// GitHubSyncConfig holds configuration for the GitHubSync watcher.
+type GitHubSyncConfig struct {
+	GitHub    *GitHubClient
+	Daemon    GitHubSyncClient // nil = no writes back to beads
+	CheckName string           // check run name (default "gasboat/agent")
+	Logger    *slog.Logger
+}
+
+// GitHubSync watches bead SSE events and syncs agent progress to GitHub.
+type GitHubSync struct {
+	github    *GitHubClient
+	daemon    GitHubSyncClient
+	checkName string
+	logger    *slog.Logger
+
+	mu        sync.Mutex
+	seen      map[string]time.Time      // dedup key → last sync time
+	closed    map[string]bool           // bead IDs closed because their issue closed
+	checkRuns map[string]githubCheckRef // bead ID → check run on its PR
+}
+
+// githubCheckRef is a check run created for a bead's pull request.
+type githubCheckRef struct {
+	repo   RepoRef
+	sha    string
+	id     int64
+	status string // bead status the check run reflects
+}
+
+// NewGitHubSync creates a new GitHub sync-back watcher.
+func NewGitHubSync(cfg GitHubSyncConfig) *GitHubSync {
+	if cfg.CheckName == "" {
+		cfg.CheckName = defaultGitHubCheckName
+	}
+	return &GitHubSync{
+		github:    cfg.GitHub,
+		daemon:    cfg.Daemon,
+		checkName: cfg.CheckName,
+		logger:    cfg.Logger,
+		seen:      make(map[string]time.Time),
+		closed:    make(map[string]bool),
+		checkRuns: make(map[string]githubCheckRef),
+	}
+}
+
+// RegisterHandlers registers SSE event handlers on the given stream for
+// bead updated and closed events.
+func (s *GitHubSync) RegisterHandlers(stream *SSEStream) {
+	stream.On("beads.bead.updated", s.handleUpdated)
+	stream.On("beads.bead.closed", s.handleClosed)
+	s.logger.Info("GitHub sync watcher registered SSE handlers",
+		"topics", []string{"beads.bead.updated", "beads.bead.closed"})
+}
+
+func (s *GitHubSync) handleUpdated(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	repo, number, ok := ParseGitHubIssueRef(bead.Fields["github_issue"])
+	if !ok {
+		return
+	}
+
+	s.syncNotes(ctx, *bead, repo, number)
+	s.syncCheckRun(ctx, *bead)
+
+	mrURL := bead.Fields["mr_url"]
+	if mrURL == "" || s.isDuplicate("mr:"+bead.ID+":"+mrURL) {
+		return
+	}
+	comment := githubCommentMarker + " Pull request opened: " + mrURL
+	if summary := bead.Fields["mr_diff_summary"]; summary != "" {
+		comment += "\n\n" + summary
+	}
+	if err := s.github.CreateIssueComment(ctx, repo, number, comment); err != nil {
+		s.logger.Error("failed to post PR link to GitHub issue",
+			"issue", bead.Fields["github_issue"], "mr_url", mrURL, "error", err)
+		return
+	}
+	s.logger.Info("posted PR link to GitHub issue",
+		"bead", bead.ID, "issue", bead.Fields["github_issue"], "mr_url", mrURL)
+}
+
+func (s *GitHubSync) handleClosed(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	repo, number, ok := ParseGitHubIssueRef(bead.Fields["github_issue"])
+	if !ok || s.isDuplicate("close:"+bead.ID) {
+		return
+	}
+	bead.Status = "closed"
+	s.syncCheckRun(ctx, *bead)
+
+	// Closed because the issue closed — nothing to report back.
+	s.mu.Lock()
+	byIssue := s.closed[bead.ID]
+	s.mu.Unlock()
+	if byIssue {
+		return
+	}
+
+	comment := fmt.Sprintf("%s Task bead %s closed.", githubCommentMarker, bead.ID)
+	if mrURL := bead.Fields["mr_url"]; mrURL != "" {
+		comment += " PR: " + mrURL
+	}
+	if err := s.github.CreateIssueComment(ctx, repo, number, comment); err != nil {
+		s.logger.Error("failed to post closing comment to GitHub issue",
+			"issue", bead.Fields["github_issue"], "error", err)
+	}
+}
+
+// syncNotes posts a bead's progress notes to the issue when they change.
+func (s *GitHubSync) syncNotes(ctx context.Context, bead BeadEvent, repo RepoRef, number int) {
+	notes := strings.TrimSpace(bead.Notes)
+	if notes == "" || s.daemon == nil {
+		return
+	}
+	sum := sha256.Sum256([]byte(notes))
+	hash := hex.EncodeToString(sum[:8])
+	if bead.Fields[githubNotesSyncedField] == hash || s.isDuplicate("notes:"+bead.ID+":"+hash) {
+		return
+	}
+
+	if err := s.github.CreateIssueComment(ctx, repo, number, githubCommentMarker+" Progress update:\n\n"+notes); err != nil {
+		s.logger.Error("failed to post bead notes to GitHub", "issue", bead.Fields["github_issue"], "bead", bead.ID, "error", err)
+		return
+	}
+	if err := s.daemon.UpdateBeadFields(ctx, bead.ID, map[string]string{githubNotesSyncedField: hash}); err != nil {
+		s.logger.Warn("failed to record synced notes hash", "bead", bead.ID, "error", err)
+	}
+	s.logger.Info("posted bead notes to GitHub", "issue", bead.Fields["github_issue"], "bead", bead.ID)
+}
+
+// syncCheckRun mirrors the bead's status onto a check run on its GitHub pull
+// request's head commit (best-effort). A new head commit gets a new check
+// run; otherwise the existing one is updated.
+func (s *GitHubSync) syncCheckRun(ctx context.Context, bead BeadEvent) {
+	repo, number, ok := ParseGitHubPullURL(bead.Fields["mr_url"])
+	if !ok || bead.Status == "" {
+		return
+	}
+	pr, err := s.github.GetPullRequest(ctx, repo, number)
+	if err != nil {
+		s.logger.Warn("failed to fetch GitHub PR for check run", "bead", bead.ID, "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	prev, exists := s.checkRuns[bead.ID]
+	s.mu.Unlock()
+	sameCommit := exists && prev.sha == pr.Head.SHA
+	if sameCommit && prev.status == bead.Status {
+		return
+	}
+
+	run := githubCheckRunFor(bead)
+	ref := githubCheckRef{repo: repo, sha: pr.Head.SHA, id: prev.id, status: bead.Status}
+	if sameCommit {
+		err = s.github.UpdateCheckRun(ctx, repo, prev.id, run)
+	} else {
+		run.Name, run.HeadSHA = s.checkName, pr.Head.SHA
+		ref.id, err = s.github.CreateCheckRun(ctx, repo, run)
+	}
+	if err != nil {
+		s.logger.Warn("failed to sync GitHub check run", "bead", bead.ID, "pr", bead.Fields["mr_url"], "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	s.checkRuns[bead.ID] = ref
+	s.mu.Unlock()
+	s.logger.Info("synced GitHub check run", "bead", bead.ID, "pr", bead.Fields["mr_url"], "status", bead.Status)
+}
+
+// githubCheckRunFor maps a bead status to check run state: closed beads
+// complete successfully, everything else shows the agent still working.
+func githubCheckRunFor(bead BeadEvent) GitHubCheckRun {
+	run := GitHubCheckRun{
+		Status: "in_progress",
+		Output: &GitHubCheckRunOutput{
+			Title:   "Agent " + strings.ReplaceAll(bead.Status, "_", " "),
+			Summary: fmt.Sprintf("Bead %s: %s", bead.ID, bead.Title),
+		},
+	}
+	if bead.Status == "closed" {
+		run.Status, run.Conclusion = "completed", "success"
+		run.Output.Title = "Agent finished"
+	}
+	return run
+}
+
+// ApplyIssueClosed closes the bead for an issue that was closed on GitHub.
+// It reports whether the bead was changed.
+func (s *GitHubSync) ApplyIssueClosed(ctx context.Context, beadID, ref string) (bool, error) {
+	if s.daemon == nil {
+		return false, nil
+	}
+	s.mu.Lock()
+	if s.closed[beadID] {
+		s.mu.Unlock()
+		return false, nil
+	}
+	// Record first so the resulting bead event doesn't comment back.
+	s.closed[beadID] = true
+	s.mu.Unlock()
+
+	if err := s.daemon.CloseBead(ctx, beadID, map[string]string{"github_state": "closed"}); err != nil {
+		s.mu.Lock()
+		delete(s.closed, beadID)
+		s.mu.Unlock()
+		return false, fmt.Errorf("close bead %s for GitHub issue %s: %w", beadID, ref, err)
+	}
+	s.logger.Info("closed bead for closed GitHub issue", "bead", beadID, "issue", ref)
+	return true, nil
+}
+
+// isDuplicate returns true if the key was seen within the syncTTL window.
+// If not, records the key and returns false.
+func (s *GitHubSync) isDuplicate(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, t := range s.seen {
+		if now.Sub(t) > syncTTL {
+			delete(s.seen, k)
+		}
+	}
+	if t, ok := s.seen[key]; ok && now.Sub(t) < syncTTL {
+		return true
+	}
+	s.seen[key] = now
+	return false
+}
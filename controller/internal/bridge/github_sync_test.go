@@ -0,0 +1,74 @@
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestGitHubSync_CommentsAndCheckRun(t *testing.T) {
+	f, gh := newFakeGitHub(t)
+	daemon := newMockJiraSyncDaemon()
+	s := NewGitHubSync(GitHubSyncConfig{GitHub: gh, Daemon: daemon, Logger: slog.Default()})
+
+	bead := BeadEvent{ID: "bd-1", Title: "Fix login", Status: "in_progress", Notes: "Found the bug",
+		Fields: map[string]string{"github_issue": "org/web#12", "mr_url": "https://github.com/org/web/pull/7"}}
+	s.handleUpdated(context.Background(), marshalSSEBeadPayload(bead))
+	s.handleUpdated(context.Background(), marshalSSEBeadPayload(bead)) // replay: no new writes
+
+	writes := f.getWrites()
+	if len(writes) != 3 {
+		t.Fatalf("expected 3 writes, got %d: %q", len(writes), writes)
+	}
+	if !strings.HasPrefix(writes[0], "POST /repos/org/web/issues/12/comments") || !strings.Contains(writes[0], "Found the bug") {
+		t.Errorf("notes comment = %q", writes[0])
+	}
+	if !strings.HasPrefix(writes[1], "POST /repos/org/web/check-runs") ||
+		!strings.Contains(writes[1], `"head_sha":"sha-1"`) || !strings.Contains(writes[1], `"status":"in_progress"`) {
+		t.Errorf("check run create = %q", writes[1])
+	}
+	if !strings.Contains(writes[2], "Pull request opened: https://github.com/org/web/pull/7") {
+		t.Errorf("PR comment = %q", writes[2])
+	}
+	if daemon.fields["bd-1"][githubNotesSyncedField] == "" {
+		t.Error("synced notes hash should be recorded")
+	}
+
+	// Closing completes the same check run and comments the closure.
+	bead.Status = "closed"
+	s.handleClosed(context.Background(), marshalSSEBeadPayload(bead))
+	writes = f.getWrites()[3:]
+	if len(writes) != 2 {
+		t.Fatalf("expected 2 writes on close, got %q", writes)
+	}
+	if !strings.HasPrefix(writes[0], "PATCH /repos/org/web/check-runs/2") || !strings.Contains(writes[0], `"conclusion":"success"`) {
+		t.Errorf("check run update = %q", writes[0])
+	}
+	if !strings.Contains(writes[1], "Task bead bd-1 closed.") {
+		t.Errorf("closing comment = %q", writes[1])
+	}
+}
+
+func TestGitHubSync_IssueClosedClosesBeadWithoutEcho(t *testing.T) {
+	f, gh := newFakeGitHub(t)
+	daemon := newMockJiraSyncDaemon()
+	s := NewGitHubSync(GitHubSyncConfig{GitHub: gh, Daemon: daemon, Logger: slog.Default()})
+
+	changed, err := s.ApplyIssueClosed(context.Background(), "bd-1", "org/web#12")
+	if err != nil || !changed {
+		t.Fatalf("ApplyIssueClosed = %v, %v", changed, err)
+	}
+	if daemon.statuses["bd-1"] != "closed" {
+		t.Errorf("bead status = %q, want closed", daemon.statuses["bd-1"])
+	}
+	if again, _ := s.ApplyIssueClosed(context.Background(), "bd-1", "org/web#12"); again {
+		t.Error("second close should be a no-op")
+	}
+
+	bead := BeadEvent{ID: "bd-1", Status: "closed", Fields: map[string]string{"github_issue": "org/web#12"}}
+	s.handleClosed(context.Background(), marshalSSEBeadPayload(bead))
+	if writes := f.getWrites(); len(writes) != 0 {
+		t.Errorf("closure from GitHub must not comment back: %q", writes)
+	}
+}
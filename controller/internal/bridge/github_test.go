@@ -3,9 +3,12 @@ package bridge
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -228,3 +231,44 @@ func TestFirstLine(t *testing.T) {
 		t.Errorf("firstLine(single) = %q, want single", got)
 	}
 }
+
+// fakeGitHub is a GitHub API stand-in for the github-bridge tests. It serves
+// the issues list, pull requests, and check runs, and records every write as
+// "METHOD path body".
+type fakeGitHub struct {
+	mu      sync.Mutex
+	issues  []GitHubIssue
+	headSHA string
+	writes  []string
+	nextID  int64
+}
+
+func newFakeGitHub(t *testing.T) (*fakeGitHub, *GitHubClient) {
+	t.Helper()
+	f := &fakeGitHub{headSHA: "sha-1"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/issues"):
+			writeJSON(w, f.issues)
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/pulls/"):
+			writeJSON(w, map[string]any{"number": 7, "state": "open", "head": map[string]string{"sha": f.headSHA}})
+		case r.Method == http.MethodGet:
+			http.NotFound(w, r)
+		default:
+			body, _ := io.ReadAll(r.Body)
+			f.writes = append(f.writes, r.Method+" "+r.URL.Path+" "+strings.TrimSpace(string(body)))
+			f.nextID++
+			writeJSON(w, map[string]int64{"id": f.nextID})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return f, newTestGitHubClient(srv.URL, "tok")
+}
+
+func (f *fakeGitHub) getWrites() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.writes...)
+}
@@ -0,0 +1,138 @@
+// Package bridge provides the GitHub webhook receiver.
+//
+// GitHubWebhook accepts GitHub App "issues" webhooks. Opened, labeled, and
+// reopened issues that match the poller's filters are fed into
+// GitHubPoller.Ingest, so labeling an issue creates its task bead within
+// seconds; closing a tracked issue closes its bead via
+// GitHubSync.ApplyIssueClosed. Requests are authenticated with the App's
+// webhook secret, which GitHub uses to sign the body with HMAC-SHA256 in the
+// X-Hub-Signature-256 header.
+package bridge
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// githubWebhookMaxBody caps webhook payloads; issue events are a few KB.
+const githubWebhookMaxBody = 1 << 20
+
+// GitHubWebhookConfig holds configuration for the GitHub webhook receiver.
+type GitHubWebhookConfig struct {
+	Secret string      // webhook secret (required)
+	Sync   *GitHubSync // nil = don't close beads for closed issues
+	Logger *slog.Logger
+}
+
+// GitHubWebhook receives GitHub issue webhooks and creates task beads.
+type GitHubWebhook struct {
+	poller *GitHubPoller
+	sync   *GitHubSync
+	secret []byte
+	logger *slog.Logger
+}
+
+// githubWebhookEvent is the subset of an issues webhook payload we use.
+type githubWebhookEvent struct {
+	Action     string       `json:"action"`
+	Issue      *GitHubIssue `json:"issue"`
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// NewGitHubWebhook creates a webhook receiver that ingests through poller.
+func NewGitHubWebhook(poller *GitHubPoller, cfg GitHubWebhookConfig) *GitHubWebhook {
+	return &GitHubWebhook{
+		poller: poller,
+		sync:   cfg.Sync,
+		secret: []byte(cfg.Secret),
+		logger: cfg.Logger,
+	}
+}
+
+// ServeHTTP handles a single webhook delivery.
+func (h *GitHubWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, githubWebhookMaxBody))
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+	if !validHubSignature(h.secret, r.Header.Get("X-Hub-Signature-256"), body) {
+		h.logger.Warn("rejected GitHub webhook with invalid signature", "remote", r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get("X-GitHub-Event") != "issues" {
+		w.WriteHeader(http.StatusNoContent) // ping and events we don't use
+		return
+	}
+
+	var event githubWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if event.Issue == nil || event.Repository == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	owner, name, _ := strings.Cut(event.Repository.FullName, "/")
+	repo := RepoRef{Owner: owner, Repo: name}
+	issue := *event.Issue
+	ref := githubIssueRef(repo, issue.Number)
+
+	switch event.Action {
+	case "closed":
+		h.handleClosed(w, r, ref)
+		return
+	case "opened", "labeled", "reopened":
+	default:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if !h.poller.Matches(repo, issue) {
+		h.logger.Debug("GitHub webhook issue does not match filters",
+			"issue", ref, "action", event.Action)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	created, err := h.poller.Ingest(r.Context(), repo, issue)
+	if err != nil {
+		// Let GitHub's redelivery or the reconciliation poll retry.
+		http.Error(w, "create bead failed", http.StatusBadGateway)
+		return
+	}
+	h.logger.Info("GitHub webhook processed",
+		"issue", ref, "action", event.Action, "created", created)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleClosed closes the tracked bead of a closed issue.
+func (h *GitHubWebhook) handleClosed(w http.ResponseWriter, r *http.Request, ref string) {
+	beadID, ok := h.poller.TrackedBead(ref)
+	if !ok || h.sync == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	changed, err := h.sync.ApplyIssueClosed(r.Context(), beadID, ref)
+	if err != nil {
+		h.logger.Error("failed to close bead from GitHub webhook",
+			"issue", ref, "bead", beadID, "error", err)
+		http.Error(w, "close bead failed", http.StatusBadGateway)
+		return
+	}
+	if !changed {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
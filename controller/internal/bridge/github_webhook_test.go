@@ -0,0 +1,75 @@
+package bridge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func postGitHubWebhook(h http.Handler, event, body, secret string) int {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func githubIssuesPayload(action string, issue map[string]any) string {
+	body, _ := json.Marshal(map[string]any{
+		"action":     action,
+		"issue":      issue,
+		"repository": map[string]string{"full_name": "org/web"},
+	})
+	return string(body)
+}
+
+func TestGitHubWebhook_IngestsAndCloses(t *testing.T) {
+	_, gh := newFakeGitHub(t)
+	beads := newMockJiraDaemon()
+	syncDaemon := newMockJiraSyncDaemon()
+	poller := NewGitHubPoller(gh, beads, GitHubPollerConfig{Repos: []RepoRef{{Owner: "org", Repo: "web"}}, Logger: slog.Default()})
+	h := NewGitHubWebhook(poller, GitHubWebhookConfig{
+		Secret: "s3cret",
+		Sync:   NewGitHubSync(GitHubSyncConfig{GitHub: gh, Daemon: syncDaemon, Logger: slog.Default()}),
+		Logger: slog.Default(),
+	})
+
+	labeled := githubIssuesPayload("labeled", map[string]any{
+		"number": 12, "title": "Fix login", "state": "open", "labels": []map[string]string{{"name": "gasboat"}},
+	})
+	if code := postGitHubWebhook(h, "issues", labeled, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("bad signature: expected 401, got %d", code)
+	}
+	if code := postGitHubWebhook(h, "ping", `{"zen":"hi"}`, "s3cret"); code != http.StatusNoContent {
+		t.Errorf("ping: expected 204, got %d", code)
+	}
+	if code := postGitHubWebhook(h, "issues", labeled, "s3cret"); code != http.StatusAccepted {
+		t.Fatalf("labeled: expected 202, got %d", code)
+	}
+	beadID, ok := poller.TrackedBead("org/web#12")
+	if !ok || len(beads.getBeads()) != 1 {
+		t.Fatal("labeled issue should create a tracked bead")
+	}
+
+	unlabeled := githubIssuesPayload("opened", map[string]any{"number": 13, "title": "Other", "state": "open"})
+	if code := postGitHubWebhook(h, "issues", unlabeled, "s3cret"); code != http.StatusNoContent {
+		t.Errorf("unlabeled: expected 204, got %d", code)
+	}
+
+	closed := githubIssuesPayload("closed", map[string]any{"number": 12, "state": "closed"})
+	if code := postGitHubWebhook(h, "issues", closed, "s3cret"); code != http.StatusAccepted {
+		t.Fatalf("closed: expected 202, got %d", code)
+	}
+	if syncDaemon.statuses[beadID] != "closed" {
+		t.Errorf("bead %s status = %q, want closed", beadID, syncDaemon.statuses[beadID])
+	}
+}
@@ -0,0 +1,429 @@
+// Package bridge provides the incident bridge core.
+//
+// IncidentBridge turns incidents from an on-call provider (PagerDuty or
+// Opsgenie, see IncidentProvider) into high-priority task beads assigned to
+// the on-call ops agent. Incident webhooks create beads (and close them when
+// the incident resolves upstream); bead SSE events flow back as timeline
+// notes — progress notes and MR links — and closing the bead resolves the
+// incident. Beads are tracked by their incident_key field
+// ("<provider>:<id>"), populated on startup by CatchUp.
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// incidentWebhookMaxBody caps webhook payloads.
+const incidentWebhookMaxBody = 1 << 20
+
+// incidentNotesSyncedField records a hash of the bead notes last posted to
+// the incident timeline, so restarts and unrelated updates don't repost them.
+const incidentNotesSyncedField = "incident_notes_synced"
+
+// Incident is a provider-neutral incident.
+type Incident struct {
+	ID          string // provider incident/alert ID
+	Number      string // human-facing number, e.g. PagerDuty #123 or Opsgenie tiny ID
+	Title       string
+	Description string
+	Service     string
+	URL         string
+	Urgent      bool // high urgency (PagerDuty) or P1/P2 (Opsgenie)
+}
+
+// IncidentEventType is a normalized incident lifecycle event.
+type IncidentEventType string
+
+const (
+	IncidentTriggered IncidentEventType = "triggered"
+	IncidentResolved  IncidentEventType = "resolved"
+)
+
+// IncidentEvent is a webhook delivery normalized by a provider.
+type IncidentEvent struct {
+	Type     IncidentEventType
+	Incident Incident
+}
+
+// IncidentProvider is an on-call system the bridge syncs with.
+type IncidentProvider interface {
+	// Name is the provider's short name, used in labels and keys.
+	Name() string
+	// ParseWebhook authenticates a webhook delivery and returns the events
+	// it carries; events the bridge doesn't act on are omitted.
+	ParseWebhook(r *http.Request, body []byte) ([]IncidentEvent, error)
+	// AddNote appends a note to the incident's timeline.
+	AddNote(ctx context.Context, incidentID, note string) error
+	// Resolve resolves the incident with a resolution note.
+	Resolve(ctx context.Context, incidentID, note string) error
+}
+
+// errIncidentUnauthorized is returned by ParseWebhook for deliveries that
+// fail authentication.
+var errIncidentUnauthorized = errors.New("incident webhook authentication failed")
+
+// IncidentBeadClient is the subset of beadsapi.Client used by IncidentBridge.
+type IncidentBeadClient interface {
+	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
+	ListTaskBeads(ctx context.Context) ([]*beadsapi.BeadDetail, error)
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+	CloseBead(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// IncidentBridgeConfig holds configuration for the incident bridge.
+type IncidentBridgeConfig struct {
+	Provider        IncidentProvider
+	Daemon          IncidentBeadClient
+	Assignee        string            // on-call agent the beads are assigned to (default "ops")
+	Project         string            // boat project for incident beads (default "ops")
+	ServiceProjects map[string]string // provider service name (lower) → boat project
+	Logger          *slog.Logger
+}
+
+// IncidentBridge creates beads for incidents and syncs them back.
+type IncidentBridge struct {
+	provider        IncidentProvider
+	daemon          IncidentBeadClient
+	assignee        string
+	project         string
+	serviceProjects map[string]string
+	logger          *slog.Logger
+
+	ingestMu sync.Mutex // serializes check-and-create for webhook retries
+	mu       sync.Mutex
+	tracked  map[string]string    // incident key → bead ID
+	resolved map[string]bool      // bead IDs closed because the incident resolved
+	seen     map[string]time.Time // dedup key → last sync time
+}
+
+// NewIncidentBridge creates an incident bridge.
+func NewIncidentBridge(cfg IncidentBridgeConfig) *IncidentBridge {
+	if cfg.Assignee == "" {
+		cfg.Assignee = "ops"
+	}
+	if cfg.Project == "" {
+		cfg.Project = "ops"
+	}
+	return &IncidentBridge{
+		provider:        cfg.Provider,
+		daemon:          cfg.Daemon,
+		assignee:        cfg.Assignee,
+		project:         cfg.Project,
+		serviceProjects: cfg.ServiceProjects,
+		logger:          cfg.Logger,
+		tracked:         make(map[string]string),
+		resolved:        make(map[string]bool),
+		seen:            make(map[string]time.Time),
+	}
+}
+
+// incidentKey returns the tracking key for an incident.
+func (b *IncidentBridge) incidentKey(id string) string {
+	return b.provider.Name() + ":" + id
+}
+
+// CatchUp populates the tracked map from existing task beads so webhook
+// retries after a restart don't create duplicates.
+func (b *IncidentBridge) CatchUp(ctx context.Context) {
+	beads, err := b.daemon.ListTaskBeads(ctx)
+	if err != nil {
+		b.logger.Warn("incident bridge catch-up: failed to list task beads", "error", err)
+		return
+	}
+	count := 0
+	b.mu.Lock()
+	for _, bead := range beads {
+		if key := bead.Fields["incident_key"]; key != "" {
+			b.tracked[key] = bead.ID
+			count++
+		}
+	}
+	b.mu.Unlock()
+	b.logger.Info("incident bridge catch-up complete", "tracked", count)
+}
+
+// TrackedBead returns the bead ID tracked for an incident ID.
+func (b *IncidentBridge) TrackedBead(incidentID string) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id, ok := b.tracked[b.incidentKey(incidentID)]
+	return id, ok
+}
+
+// Ingest creates a task bead for the incident unless one is already
+// tracked. It reports whether a bead was created.
+func (b *IncidentBridge) Ingest(ctx context.Context, inc Incident) (bool, error) {
+	b.ingestMu.Lock()
+	defer b.ingestMu.Unlock()
+
+	if _, ok := b.TrackedBead(inc.ID); ok {
+		return false, nil
+	}
+	key := b.incidentKey(inc.ID)
+
+	project := b.project
+	if p, ok := b.serviceProjects[strings.ToLower(inc.Service)]; ok {
+		project = p
+	}
+	fields := map[string]string{
+		"incident_key":      key,
+		"incident_provider": b.provider.Name(),
+		"incident_id":       inc.ID,
+		"incident_url":      inc.URL,
+	}
+	if inc.Service != "" {
+		fields["incident_service"] = inc.Service
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return false, fmt.Errorf("marshal fields: %w", err)
+	}
+
+	// Urgent incidents are P0; everything paged is at least P1.
+	priority := 1
+	if inc.Urgent {
+		priority = 0
+	}
+	title := inc.Title
+	if inc.Number != "" {
+		title = fmt.Sprintf("[%s #%s] %s", b.provider.Name(), inc.Number, inc.Title)
+	}
+	beadID, err := b.daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title:       title,
+		Type:        "task",
+		Description: inc.Description,
+		Assignee:    b.assignee,
+		Labels:      []string{"source:" + b.provider.Name(), "incident", "project:" + project},
+		Priority:    priority,
+		CreatedBy:   "incident-bridge",
+		Fields:      fieldsJSON,
+	})
+	if err != nil {
+		return false, fmt.Errorf("create bead for incident %s: %w", key, err)
+	}
+
+	b.mu.Lock()
+	b.tracked[key] = beadID
+	b.mu.Unlock()
+	b.logger.Info("created bead for incident",
+		"incident", key, "bead", beadID, "title", inc.Title, "assignee", b.assignee)
+
+	b.addNote(ctx, inc.ID, fmt.Sprintf("Gasboat task bead %s created and assigned to %s.", beadID, b.assignee))
+	return true, nil
+}
+
+// ServeHTTP handles a provider webhook delivery.
+func (b *IncidentBridge) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, incidentWebhookMaxBody))
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+	events, err := b.provider.ParseWebhook(r, body)
+	if errors.Is(err, errIncidentUnauthorized) {
+		b.logger.Warn("rejected incident webhook", "provider", b.provider.Name(), "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusNoContent
+	for _, ev := range events {
+		switch ev.Type {
+		case IncidentTriggered:
+			created, err := b.Ingest(r.Context(), ev.Incident)
+			if err != nil {
+				b.logger.Error("failed to create bead for incident", "incident", ev.Incident.ID, "error", err)
+				http.Error(w, "create bead failed", http.StatusBadGateway)
+				return
+			}
+			if created {
+				status = http.StatusAccepted
+			}
+		case IncidentResolved:
+			closed, err := b.applyResolved(r.Context(), ev.Incident.ID)
+			if err != nil {
+				b.logger.Error("failed to close bead for resolved incident", "incident", ev.Incident.ID, "error", err)
+				http.Error(w, "close bead failed", http.StatusBadGateway)
+				return
+			}
+			if closed {
+				status = http.StatusAccepted
+			}
+		}
+	}
+	w.WriteHeader(status)
+}
+
+// applyResolved closes the bead of an incident resolved upstream.
+func (b *IncidentBridge) applyResolved(ctx context.Context, incidentID string) (bool, error) {
+	beadID, ok := b.TrackedBead(incidentID)
+	if !ok {
+		return false, nil
+	}
+	b.mu.Lock()
+	if b.resolved[beadID] {
+		b.mu.Unlock()
+		return false, nil
+	}
+	// Record first so the resulting bead event doesn't resolve back.
+	b.resolved[beadID] = true
+	b.mu.Unlock()
+
+	if err := b.daemon.CloseBead(ctx, beadID, map[string]string{"incident_status": "resolved"}); err != nil {
+		b.mu.Lock()
+		delete(b.resolved, beadID)
+		b.mu.Unlock()
+		return false, err
+	}
+	b.logger.Info("closed bead for resolved incident", "incident", b.incidentKey(incidentID), "bead", beadID)
+	return true, nil
+}
+
+// RegisterHandlers registers SSE event handlers on the given stream for
+// bead updated and closed events.
+func (b *IncidentBridge) RegisterHandlers(stream *SSEStream) {
+	stream.On("beads.bead.updated", b.handleUpdated)
+	stream.On("beads.bead.closed", b.handleClosed)
+	b.logger.Info("incident sync registered SSE handlers",
+		"topics", []string{"beads.bead.updated", "beads.bead.closed"})
+}
+
+// incidentIDFromBead returns the incident ID of a bead from this provider.
+func (b *IncidentBridge) incidentIDFromBead(bead BeadEvent) string {
+	if bead.Fields["incident_provider"] != b.provider.Name() {
+		return ""
+	}
+	return bead.Fields["incident_id"]
+}
+
+func (b *IncidentBridge) handleUpdated(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	incidentID := b.incidentIDFromBead(*bead)
+	if incidentID == "" {
+		return
+	}
+
+	// Progress notes go on the incident timeline.
+	if notes := strings.TrimSpace(bead.Notes); notes != "" {
+		sum := sha256.Sum256([]byte(notes))
+		hash := hex.EncodeToString(sum[:8])
+		if bead.Fields[incidentNotesSyncedField] != hash && !b.isDuplicate("notes:"+bead.ID+":"+hash) {
+			if b.addNote(ctx, incidentID, "Agent progress: "+notes) {
+				if err := b.daemon.UpdateBeadFields(ctx, bead.ID, map[string]string{incidentNotesSyncedField: hash}); err != nil {
+					b.logger.Warn("failed to record synced notes hash", "bead", bead.ID, "error", err)
+				}
+			}
+		}
+	}
+
+	if mrURL := bead.Fields["mr_url"]; mrURL != "" && !b.isDuplicate("mr:"+bead.ID+":"+mrURL) {
+		b.addNote(ctx, incidentID, "Fix proposed: "+mrURL)
+	}
+}
+
+func (b *IncidentBridge) handleClosed(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	incidentID := b.incidentIDFromBead(*bead)
+	if incidentID == "" || b.isDuplicate("close:"+bead.ID) {
+		return
+	}
+	b.mu.Lock()
+	byIncident := b.resolved[bead.ID]
+	b.mu.Unlock()
+	if byIncident {
+		return
+	}
+
+	note := fmt.Sprintf("Resolved by gasboat: task bead %s closed.", bead.ID)
+	if mrURL := bead.Fields["mr_url"]; mrURL != "" {
+		note += " Fix: " + mrURL
+	}
+	if err := b.provider.Resolve(ctx, incidentID, note); err != nil {
+		b.logger.Error("failed to resolve incident", "incident", b.incidentKey(incidentID), "bead", bead.ID, "error", err)
+		return
+	}
+	b.logger.Info("resolved incident for closed bead", "incident", b.incidentKey(incidentID), "bead", bead.ID)
+}
+
+// addNote adds a timeline note (best-effort) and reports success.
+func (b *IncidentBridge) addNote(ctx context.Context, incidentID, note string) bool {
+	if err := b.provider.AddNote(ctx, incidentID, note); err != nil {
+		b.logger.Warn("failed to add incident note", "incident", b.incidentKey(incidentID), "error", err)
+		return false
+	}
+	return true
+}
+
+// isDuplicate returns true if the key was seen within the syncTTL window.
+// If not, records the key and returns false.
+func (b *IncidentBridge) isDuplicate(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for k, t := range b.seen {
+		if now.Sub(t) > syncTTL {
+			delete(b.seen, k)
+		}
+	}
+	if t, ok := b.seen[key]; ok && now.Sub(t) < syncTTL {
+		return true
+	}
+	b.seen[key] = now
+	return false
+}
+
+// doIncidentRequest sends a JSON request to a provider API with the given
+// headers and discards the response body. provider names the API in errors.
+func doIncidentRequest(ctx context.Context, client *http.Client, provider, method, url string, headers map[string]string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal %s request: %w", provider, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("%s API %s %s returned %d: %s", provider, method, url, resp.StatusCode, truncate(string(respBody), 256))
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package bridge provides the Opsgenie incident provider.
+//
+// OpsgenieProvider implements IncidentProvider against the Opsgenie Alert
+// API and its outgoing webhook integration: Create actions create beads and
+// Close actions close them. Opsgenie doesn't sign webhooks, so deliveries
+// must carry a shared token (configured as a custom header on the
+// integration) in the X-Gasboat-Token header.
+package bridge
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OpsgenieConfig holds configuration for the Opsgenie provider.
+type OpsgenieConfig struct {
+	APIKey       string // API integration key (GenieKey)
+	WebhookToken string // shared token expected in X-Gasboat-Token
+	BaseURL      string // defaults to "https://api.opsgenie.com" (EU: api.eu.opsgenie.com)
+	AppURL       string // web UI base for alert links, e.g. "https://acme.app.opsgenie.com" (optional)
+}
+
+// OpsgenieProvider syncs alerts with Opsgenie.
+type OpsgenieProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	appURL     string
+	apiKey     string
+	token      []byte
+}
+
+// NewOpsgenieProvider creates an Opsgenie incident provider.
+func NewOpsgenieProvider(cfg OpsgenieConfig) *OpsgenieProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.opsgenie.com"
+	}
+	return &OpsgenieProvider{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		appURL:     strings.TrimRight(cfg.AppURL, "/"),
+		apiKey:     cfg.APIKey,
+		token:      []byte(cfg.WebhookToken),
+	}
+}
+
+// Name implements IncidentProvider.
+func (p *OpsgenieProvider) Name() string { return "opsgenie" }
+
+// opsgenieWebhook is the subset of an outgoing webhook payload we use.
+type opsgenieWebhook struct {
+	Action string `json:"action"`
+	Alert  struct {
+		AlertID     string `json:"alertId"`
+		TinyID      string `json:"tinyId"`
+		Message     string `json:"message"`
+		Description string `json:"description"`
+		Entity      string `json:"entity"`
+		Priority    string `json:"priority"`
+	} `json:"alert"`
+}
+
+// ParseWebhook implements IncidentProvider.
+func (p *OpsgenieProvider) ParseWebhook(r *http.Request, body []byte) ([]IncidentEvent, error) {
+	got := []byte(r.Header.Get("X-Gasboat-Token"))
+	if len(p.token) == 0 || subtle.ConstantTimeCompare(got, p.token) != 1 {
+		return nil, errIncidentUnauthorized
+	}
+	var payload opsgenieWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode Opsgenie webhook: %w", err)
+	}
+	alert := payload.Alert
+	if alert.AlertID == "" {
+		return nil, nil
+	}
+
+	var typ IncidentEventType
+	switch payload.Action {
+	case "Create":
+		typ = IncidentTriggered
+	case "Close":
+		typ = IncidentResolved
+	default:
+		return nil, nil
+	}
+
+	inc := Incident{
+		ID:          alert.AlertID,
+		Number:      alert.TinyID,
+		Title:       alert.Message,
+		Description: alert.Description,
+		Service:     alert.Entity,
+		Urgent:      alert.Priority == "P1" || alert.Priority == "P2",
+	}
+	if p.appURL != "" {
+		inc.URL = p.appURL + "/alert/detail/" + url.PathEscape(alert.AlertID) + "/details"
+	}
+	if inc.Description == "" {
+		inc.Description = "Opsgenie alert: " + inc.Title
+	}
+	return []IncidentEvent{{Type: typ, Incident: inc}}, nil
+}
+
+// AddNote implements IncidentProvider.
+func (p *OpsgenieProvider) AddNote(ctx context.Context, incidentID, note string) error {
+	return p.do(ctx, incidentID, "notes", map[string]string{"note": note, "source": "gasboat"})
+}
+
+// Resolve implements IncidentProvider by closing the alert.
+func (p *OpsgenieProvider) Resolve(ctx context.Context, incidentID, note string) error {
+	return p.do(ctx, incidentID, "close", map[string]string{"note": note, "source": "gasboat"})
+}
+
+// do POSTs an alert action. Opsgenie processes actions asynchronously and
+// answers 202 once the request is queued.
+func (p *OpsgenieProvider) do(ctx context.Context, alertID, action string, body any) error {
+	u := fmt.Sprintf("%s/v2/alerts/%s/%s?identifierType=id", p.baseURL, url.PathEscape(alertID), action)
+	return doIncidentRequest(ctx, p.httpClient, "Opsgenie", http.MethodPost, u, map[string]string{
+		"Authorization": "GenieKey " + p.apiKey,
+	}, body)
+}
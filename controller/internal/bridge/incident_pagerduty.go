@@ -0,0 +1,169 @@
+// Package bridge provides the PagerDuty incident provider.
+//
+// PagerDutyProvider implements IncidentProvider against PagerDuty's REST API
+// and V3 webhooks. incident.triggered webhooks create beads and
+// incident.resolved webhooks close them; webhook bodies are signed with
+// HMAC-SHA256 in the X-PagerDuty-Signature header ("v1=<hex>", with several
+// comma-separated signatures during secret rotation).
+package bridge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PagerDutyConfig holds configuration for the PagerDuty provider.
+type PagerDutyConfig struct {
+	APIToken      string // REST API key
+	From          string // email of a valid PagerDuty user, required for writes
+	WebhookSecret string // V3 webhook subscription signing secret
+	BaseURL       string // defaults to "https://api.pagerduty.com"
+}
+
+// PagerDutyProvider syncs incidents with PagerDuty.
+type PagerDutyProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+	from       string
+	secret     []byte
+}
+
+// NewPagerDutyProvider creates a PagerDuty incident provider.
+func NewPagerDutyProvider(cfg PagerDutyConfig) *PagerDutyProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.pagerduty.com"
+	}
+	return &PagerDutyProvider{
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
+		token:      cfg.APIToken,
+		from:       cfg.From,
+		secret:     []byte(cfg.WebhookSecret),
+	}
+}
+
+// Name implements IncidentProvider.
+func (p *PagerDutyProvider) Name() string { return "pagerduty" }
+
+// pagerDutyWebhook is the subset of a V3 webhook payload we use.
+type pagerDutyWebhook struct {
+	Event struct {
+		EventType string `json:"event_type"`
+		Data      struct {
+			ID          string `json:"id"`
+			Type        string `json:"type"`
+			Number      int    `json:"number"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			HTMLURL     string `json:"html_url"`
+			Urgency     string `json:"urgency"`
+			Service     *struct {
+				Summary string `json:"summary"`
+			} `json:"service"`
+			Priority *struct {
+				Summary string `json:"summary"`
+			} `json:"priority"`
+		} `json:"data"`
+	} `json:"event"`
+}
+
+// ParseWebhook implements IncidentProvider.
+func (p *PagerDutyProvider) ParseWebhook(r *http.Request, body []byte) ([]IncidentEvent, error) {
+	if !p.validSignature(r.Header.Get("X-PagerDuty-Signature"), body) {
+		return nil, errIncidentUnauthorized
+	}
+	var payload pagerDutyWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode PagerDuty webhook: %w", err)
+	}
+	data := payload.Event.Data
+	if data.Type != "incident" || data.ID == "" {
+		return nil, nil
+	}
+
+	var typ IncidentEventType
+	switch payload.Event.EventType {
+	case "incident.triggered":
+		typ = IncidentTriggered
+	case "incident.resolved":
+		typ = IncidentResolved
+	default:
+		return nil, nil
+	}
+
+	inc := Incident{
+		ID:          data.ID,
+		Title:       data.Title,
+		Description: data.Description,
+		URL:         data.HTMLURL,
+		Urgent:      data.Urgency == "high",
+	}
+	if data.Number > 0 {
+		inc.Number = strconv.Itoa(data.Number)
+	}
+	if data.Service != nil {
+		inc.Service = data.Service.Summary
+	}
+	if data.Priority != nil && (data.Priority.Summary == "P1" || data.Priority.Summary == "SEV-1") {
+		inc.Urgent = true
+	}
+	if inc.Description == "" {
+		inc.Description = fmt.Sprintf("PagerDuty incident: %s\n\n%s", inc.Title, inc.URL)
+	}
+	return []IncidentEvent{{Type: typ, Incident: inc}}, nil
+}
+
+// validSignature checks the X-PagerDuty-Signature header; any one of the
+// listed v1 signatures matching is sufficient.
+func (p *PagerDutyProvider) validSignature(header string, body []byte) bool {
+	if len(p.secret) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(body)
+	want := mac.Sum(nil)
+	for _, sig := range strings.Split(header, ",") {
+		hexSig, ok := strings.CutPrefix(strings.TrimSpace(sig), "v1=")
+		if !ok {
+			continue
+		}
+		if got, err := hex.DecodeString(hexSig); err == nil && hmac.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddNote implements IncidentProvider.
+func (p *PagerDutyProvider) AddNote(ctx context.Context, incidentID, note string) error {
+	body := map[string]any{"note": map[string]string{"content": note}}
+	return p.do(ctx, http.MethodPost, "/incidents/"+url.PathEscape(incidentID)+"/notes", body)
+}
+
+// Resolve implements IncidentProvider.
+func (p *PagerDutyProvider) Resolve(ctx context.Context, incidentID, note string) error {
+	body := map[string]any{"incident": map[string]string{
+		"type":       "incident_reference",
+		"status":     "resolved",
+		"resolution": note,
+	}}
+	return p.do(ctx, http.MethodPut, "/incidents/"+url.PathEscape(incidentID), body)
+}
+
+func (p *PagerDutyProvider) do(ctx context.Context, method, path string, body any) error {
+	return doIncidentRequest(ctx, p.httpClient, "PagerDuty", method, p.baseURL+path, map[string]string{
+		"Accept":        "application/vnd.pagerduty+json;version=2",
+		"Authorization": "Token token=" + p.token,
+		"From":          p.from,
+	}, body)
+}
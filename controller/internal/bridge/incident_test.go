@@ -0,0 +1,277 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// fakeIncidentProvider records notes and resolutions; ParseWebhook decodes
+// the body as a []IncidentEvent and rejects requests without "X-Ok".
+type fakeIncidentProvider struct {
+	mu       sync.Mutex
+	notes    []string // "id|note"
+	resolved []string // "id|note"
+}
+
+func (f *fakeIncidentProvider) Name() string { return "fake" }
+
+func (f *fakeIncidentProvider) ParseWebhook(r *http.Request, body []byte) ([]IncidentEvent, error) {
+	if r.Header.Get("X-Ok") == "" {
+		return nil, errIncidentUnauthorized
+	}
+	var events []IncidentEvent
+	err := json.Unmarshal(body, &events)
+	return events, err
+}
+
+func (f *fakeIncidentProvider) AddNote(_ context.Context, id, note string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.notes = append(f.notes, id+"|"+note)
+	return nil
+}
+
+func (f *fakeIncidentProvider) Resolve(_ context.Context, id, note string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resolved = append(f.resolved, id+"|"+note)
+	return nil
+}
+
+// mockIncidentDaemon adds CreateBead to mockJiraSyncDaemon.
+type mockIncidentDaemon struct {
+	*mockJiraSyncDaemon
+	created []beadsapi.CreateBeadRequest
+}
+
+func (m *mockIncidentDaemon) CreateBead(_ context.Context, req beadsapi.CreateBeadRequest) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.created = append(m.created, req)
+	return fmt.Sprintf("bd-inc-%d", len(m.created)), nil
+}
+
+func newTestIncidentBridge(serviceProjects map[string]string) (*IncidentBridge, *fakeIncidentProvider, *mockIncidentDaemon) {
+	provider := &fakeIncidentProvider{}
+	daemon := &mockIncidentDaemon{mockJiraSyncDaemon: newMockJiraSyncDaemon()}
+	b := NewIncidentBridge(IncidentBridgeConfig{
+		Provider:        provider,
+		Daemon:          daemon,
+		ServiceProjects: serviceProjects,
+		Logger:          slog.Default(),
+	})
+	return b, provider, daemon
+}
+
+func postIncidentEvents(t *testing.T, h http.Handler, events []IncidentEvent) int {
+	t.Helper()
+	body, _ := json.Marshal(events)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/fake", bytes.NewReader(body))
+	req.Header.Set("X-Ok", "1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestIncidentBridge_TriggeredCreatesAssignedBead(t *testing.T) {
+	b, provider, daemon := newTestIncidentBridge(map[string]string{"checkout api": "shop"})
+	inc := Incident{ID: "P1", Number: "42", Title: "Checkout down", Service: "Checkout API", URL: "https://pd/P1", Urgent: true}
+
+	if code := postIncidentEvents(t, b, []IncidentEvent{{Type: IncidentTriggered, Incident: inc}}); code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", code)
+	}
+	// Redelivery doesn't create a duplicate.
+	if code := postIncidentEvents(t, b, []IncidentEvent{{Type: IncidentTriggered, Incident: inc}}); code != http.StatusNoContent {
+		t.Fatalf("redelivery status = %d, want 204", code)
+	}
+
+	if len(daemon.created) != 1 {
+		t.Fatalf("created %d beads, want 1", len(daemon.created))
+	}
+	req := daemon.created[0]
+	if req.Assignee != "ops" || req.Priority != 0 || req.Title != "[fake #42] Checkout down" {
+		t.Errorf("unexpected bead request: assignee=%q priority=%d title=%q", req.Assignee, req.Priority, req.Title)
+	}
+	if !hasLabel(req.Labels, "project:shop") || !hasLabel(req.Labels, "source:fake") {
+		t.Errorf("labels = %v", req.Labels)
+	}
+	var fields map[string]string
+	_ = json.Unmarshal(req.Fields, &fields)
+	if fields["incident_key"] != "fake:P1" || fields["incident_id"] != "P1" || fields["incident_service"] != "Checkout API" {
+		t.Errorf("fields = %v", fields)
+	}
+	if len(provider.notes) != 1 || !strings.Contains(provider.notes[0], "bd-inc-1") {
+		t.Errorf("notes = %v, want bead-created note", provider.notes)
+	}
+}
+
+func TestIncidentBridge_RejectsUnauthenticated(t *testing.T) {
+	b, _, _ := newTestIncidentBridge(nil)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/fake", strings.NewReader("[]"))
+	rec := httptest.NewRecorder()
+	b.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rec.Code)
+	}
+}
+
+func TestIncidentBridge_CatchUpAndResolvedClosesBead(t *testing.T) {
+	b, provider, daemon := newTestIncidentBridge(nil)
+	daemon.beads["bd-old"] = &beadsapi.BeadDetail{ID: "bd-old", Type: "task", Fields: map[string]string{"incident_key": "fake:P9"}}
+	b.CatchUp(context.Background())
+
+	if _, ok := b.TrackedBead("P9"); !ok {
+		t.Fatal("P9 not tracked after catch-up")
+	}
+	if code := postIncidentEvents(t, b, []IncidentEvent{{Type: IncidentResolved, Incident: Incident{ID: "P9"}}}); code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", code)
+	}
+	if daemon.statuses["bd-old"] != "closed" {
+		t.Errorf("bead status = %q, want closed", daemon.statuses["bd-old"])
+	}
+
+	// The resulting close event must not resolve the incident again.
+	b.handleClosed(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "bd-old", Fields: map[string]string{"incident_provider": "fake", "incident_id": "P9"},
+	}))
+	if len(provider.resolved) != 0 {
+		t.Errorf("resolved = %v, want none", provider.resolved)
+	}
+}
+
+func TestIncidentBridge_SyncNotesAndResolveOnClose(t *testing.T) {
+	b, provider, daemon := newTestIncidentBridge(nil)
+	ctx := context.Background()
+	fields := map[string]string{"incident_provider": "fake", "incident_id": "P2", "mr_url": "https://gl/mr/1"}
+	bead := BeadEvent{ID: "bd-1", Notes: "restarted the pods", Fields: fields}
+
+	b.handleUpdated(ctx, marshalSSEBeadPayload(bead))
+	b.handleUpdated(ctx, marshalSSEBeadPayload(bead)) // duplicate event
+	if len(provider.notes) != 2 {
+		t.Fatalf("notes = %v, want progress + MR notes", provider.notes)
+	}
+	if !strings.Contains(provider.notes[0], "restarted the pods") || !strings.Contains(provider.notes[1], "https://gl/mr/1") {
+		t.Errorf("notes = %v", provider.notes)
+	}
+	if daemon.fields["bd-1"][incidentNotesSyncedField] == "" {
+		t.Error("notes hash not recorded")
+	}
+
+	b.handleClosed(ctx, marshalSSEBeadPayload(bead))
+	if len(provider.resolved) != 1 || !strings.HasPrefix(provider.resolved[0], "P2|") {
+		t.Fatalf("resolved = %v, want P2", provider.resolved)
+	}
+
+	// Beads from another provider are ignored.
+	b.handleClosed(ctx, marshalSSEBeadPayload(BeadEvent{ID: "bd-2", Fields: map[string]string{"incident_provider": "other", "incident_id": "X"}}))
+	if len(provider.resolved) != 1 {
+		t.Errorf("resolved = %v, want only P2", provider.resolved)
+	}
+}
+
+func signPagerDuty(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestPagerDutyProvider_ParseWebhook(t *testing.T) {
+	p := NewPagerDutyProvider(PagerDutyConfig{WebhookSecret: "s3cret"})
+	body := []byte(`{"event":{"event_type":"incident.triggered","data":{"id":"Q1","type":"incident","number":7,
+		"title":"DB latency","html_url":"https://acme.pagerduty.com/incidents/Q1","urgency":"high","service":{"summary":"Database"}}}}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-PagerDuty-Signature", "v1=deadbeef, "+signPagerDuty("s3cret", body))
+	events, err := p.ParseWebhook(req, body)
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != IncidentTriggered {
+		t.Fatalf("events = %+v", events)
+	}
+	inc := events[0].Incident
+	if inc.ID != "Q1" || inc.Number != "7" || inc.Service != "Database" || !inc.Urgent {
+		t.Errorf("incident = %+v", inc)
+	}
+
+	req.Header.Set("X-PagerDuty-Signature", signPagerDuty("wrong", body))
+	if _, err := p.ParseWebhook(req, body); err != errIncidentUnauthorized {
+		t.Errorf("err = %v, want unauthorized", err)
+	}
+}
+
+func TestPagerDutyProvider_NoteAndResolve(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization")+" "+r.Header.Get("From")+" "+string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	p := NewPagerDutyProvider(PagerDutyConfig{APIToken: "tok", From: "ops@acme.io", BaseURL: srv.URL})
+	if err := p.AddNote(context.Background(), "Q1", "working on it"); err != nil {
+		t.Fatalf("AddNote: %v", err)
+	}
+	if err := p.Resolve(context.Background(), "Q1", "fixed"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	want := []string{
+		`POST /incidents/Q1/notes Token token=tok ops@acme.io {"note":{"content":"working on it"}}`,
+		`PUT /incidents/Q1 Token token=tok ops@acme.io {"incident":{"resolution":"fixed","status":"resolved","type":"incident_reference"}}`,
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls:\n%s\nwant:\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestOpsgenieProvider_WebhookAndClose(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("Authorization") + " " + string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	p := NewOpsgenieProvider(OpsgenieConfig{APIKey: "key", WebhookToken: "tok", BaseURL: srv.URL, AppURL: "https://acme.app.opsgenie.com"})
+	body := []byte(`{"action":"Create","alert":{"alertId":"a-1","tinyId":"12","message":"Disk full","entity":"Storage","priority":"P2"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	if _, err := p.ParseWebhook(req, body); err != errIncidentUnauthorized {
+		t.Fatalf("err = %v, want unauthorized without token", err)
+	}
+	req.Header.Set("X-Gasboat-Token", "tok")
+	events, err := p.ParseWebhook(req, body)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("events = %+v, err = %v", events, err)
+	}
+	inc := events[0].Incident
+	if inc.ID != "a-1" || inc.Number != "12" || !inc.Urgent || inc.URL != "https://acme.app.opsgenie.com/alert/detail/a-1/details" {
+		t.Errorf("incident = %+v", inc)
+	}
+
+	if err := p.Resolve(context.Background(), "a-1", "done"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	want := `POST /v2/alerts/a-1/close?identifierType=id GenieKey key {"note":"done","source":"gasboat"}`
+	if got != want {
+		t.Errorf("request = %s, want %s", got, want)
+	}
+}
@@ -78,20 +78,41 @@ func configs() map[string]any {
 				{Name: "role", Type: "enum", Values: []string{"captain", "crew", "job"}},
 				{Name: "agent", Type: "string"},
 				// Agent lifecycle state written back by the controller.
-				{Name: "agent_state", Type: "enum", Values: []string{"spawning", "working", "done", "failed"}},
+				{Name: "agent_state", Type: "enum", Values: []string{"spawning", "working", "done", "failed", "preempted", "waiting_capacity", "hibernating"}},
 				// Pod lifecycle state written back by the controller.
 				{Name: "pod_phase", Type: "enum", Values: []string{"pending", "running", "succeeded", "failed"}},
 				{Name: "pod_name", Type: "string"},
 				{Name: "pod_namespace", Type: "string"},
 				{Name: "pod_ready", Type: "boolean"},
 				{Name: "coop_url", Type: "string"},
-				{Name: "coop_token", Type: "string"},
+				// Spot preemption count kept by the controller's reconciler.
+				{Name: "preemptions", Type: "string"},
+				// Position in the controller's spawn queue while the pod is deferred.
+				{Name: "queue_position", Type: "string"},
+				// Object URLs of the session artifacts exported when the agent stopped (JSON list).
+				{Name: "artifacts", Type: "string"},
 				// Per-agent overrides (optional).
 				{Name: "image", Type: "string"},
 				{Name: "mock_scenario", Type: "string"},
-				// Agent stop/gate control written by gb stop and gb yield.
+				{Name: "schedule", Type: "string"},
+				{Name: "arch", Type: "enum", Values: []string{"amd64", "arm64", "any"}},
+				{Name: "env", Type: "json"},
+				{Name: "resources", Type: "json"},
+				// Template bead the agent's configuration is based on.
+				{Name: "template", Type: "string"},
+				// Agent stop/restart/gate control written by gb stop, gb agent
+				// restart, and gb yield.
 				{Name: "stop_requested", Type: "string"},
+				{Name: "restart_requested", Type: "string"},
 				{Name: "gate_satisfied_by", Type: "string"},
+				// Session bookkeeping written by gb setup session, gb ready
+				// --claim, and gb yield --checkpoint.
+				{Name: "session_started_at", Type: "string"},
+				{Name: "session_host", Type: "string"},
+				{Name: "ready_since", Type: "string"},
+				{Name: "checkpoint_at", Type: "string"},
+				{Name: "checkpoint_task", Type: "string"},
+				{Name: "checkpoint_ref", Type: "string"},
 				// Advice subscription overrides.
 				{Name: "advice_subscriptions", Type: "string[]"},
 				{Name: "advice_subscriptions_exclude", Type: "string[]"},
@@ -127,11 +148,54 @@ func configs() map[string]any {
 				{Name: "image", Type: "string"},
 				{Name: "storage_class", Type: "string"},
 				{Name: "service_account", Type: "string"},
+				{Name: "termination_grace", Type: "string"},
+				{Name: "prestop", Type: "string"},
+				{Name: "host_aliases", Type: "json"},
+				{Name: "dns_policy", Type: "enum", Values: []string{"ClusterFirst", "ClusterFirstWithHostNet", "Default", "None"}},
+				{Name: "dns_config", Type: "json"},
+				{Name: "http_proxy", Type: "string"},
+				{Name: "https_proxy", Type: "string"},
+				{Name: "no_proxy", Type: "string"},
 				{Name: "secrets", Type: "json"},
 				{Name: "repos", Type: "json"},
 			},
 		},
 
+		// Agent templates: reusable agent configurations that agent beads
+		// name in their template field. Any agent field may be set; env,
+		// resources and hooks hold JSON.
+		"type:template": TypeConfig{
+			Kind: "config",
+			Fields: []FieldDef{
+				{Name: "role", Type: "enum", Values: []string{"captain", "crew", "job"}},
+				{Name: "mode", Type: "string"},
+				{Name: "image", Type: "string"},
+				{Name: "arch", Type: "enum", Values: []string{"amd64", "arm64", "any"}},
+				{Name: "mock_scenario", Type: "string"},
+				{Name: "rtk_enabled", Type: "boolean"},
+				{Name: "instructions", Type: "string"},
+				{Name: "env", Type: "json"},
+				{Name: "resources", Type: "json"},
+				{Name: "hooks", Type: "json"},
+			},
+		},
+
+		// Role beads: pod defaults and instructions shared by every agent
+		// with the role (bead title). env and resources hold JSON.
+		"type:role": TypeConfig{
+			Kind: "config",
+			Fields: []FieldDef{
+				{Name: "image", Type: "string"},
+				{Name: "storage_class", Type: "string"},
+				{Name: "storage_size", Type: "string"},
+				{Name: "termination_grace", Type: "string"},
+				{Name: "prestop", Type: "string"},
+				{Name: "instructions", Type: "string"},
+				{Name: "env", Type: "json"},
+				{Name: "resources", Type: "json"},
+			},
+		},
+
 		"type:task": TypeConfig{
 			Kind: "data",
 			Fields: []FieldDef{
@@ -141,10 +205,46 @@ func configs() map[string]any {
 				{Name: "jira_status", Type: "string"},
 				{Name: "jira_url", Type: "string"},
 				{Name: "jira_epic", Type: "string"},
+				{Name: "jira_parent", Type: "string"},
 				{Name: "jira_reporter", Type: "string"},
+				{Name: "github_issue", Type: "string"},
+				{Name: "github_repo", Type: "string"},
+				{Name: "github_url", Type: "string"},
+				{Name: "github_author", Type: "string"},
+				{Name: "linear_id", Type: "string"},
+				{Name: "linear_key", Type: "string"},
+				{Name: "linear_team", Type: "string"},
+				{Name: "linear_url", Type: "string"},
+				{Name: "linear_state", Type: "string"},
+				{Name: "linear_creator", Type: "string"},
+				{Name: "incident_key", Type: "string"},
+				{Name: "incident_provider", Type: "string"},
+				{Name: "incident_id", Type: "string"},
+				{Name: "incident_url", Type: "string"},
+				{Name: "incident_service", Type: "string"},
 				{Name: "mr_url", Type: "string"},
 			},
 		},
+		// Named gates (gb gate close/open/wait): the gate blocks while its
+		// bead is open; opening it closes the bead.
+		"type:gate": TypeConfig{
+			Kind: "data",
+			Fields: []FieldDef{
+				{Name: "gate_name", Type: "string", Required: true},
+				{Name: "opened_by", Type: "string"},
+				{Name: "opened_at", Type: "string"},
+				{Name: "open_reason", Type: "string"},
+			},
+		},
+		// Custom bus events (gb bus publish): created and closed at once;
+		// gb bus tail surfaces the creation as "bus.<topic>".
+		"type:event": TypeConfig{
+			Kind: "data",
+			Fields: []FieldDef{
+				{Name: "topic", Type: "string", Required: true},
+				{Name: "payload", Type: "json"},
+			},
+		},
 		"type:report": TypeConfig{
 			Kind: "data",
 			Fields: []FieldDef{
@@ -30,6 +30,8 @@ type JackNotifier interface {
 // Jacks watches the kbeads SSE event stream for jack bead lifecycle events.
 type Jacks struct {
 	notifier JackNotifier
+	mutes    *Mutes
+	metrics  *Metrics
 	logger   *slog.Logger
 
 	mu          sync.Mutex
@@ -45,6 +47,8 @@ type Jacks struct {
 // JacksConfig holds configuration for the Jacks watcher.
 type JacksConfig struct {
 	Notifier JackNotifier
+	Mutes    *Mutes   // optional mute rules checked before notifying
+	Metrics  *Metrics // optional notification metrics
 	Logger   *slog.Logger
 }
 
@@ -52,6 +56,8 @@ type JacksConfig struct {
 func NewJacks(cfg JacksConfig) *Jacks {
 	return &Jacks{
 		notifier:    cfg.Notifier,
+		mutes:       cfg.Mutes,
+		metrics:     cfg.Metrics,
 		logger:      cfg.Logger,
 		offSeen:     make(map[string]bool),
 		expiredSeen: make(map[string]time.Time),
@@ -89,6 +95,10 @@ func (j *Jacks) handleCreated(ctx context.Context, data []byte) {
 	if j.notifier == nil {
 		return
 	}
+	if j.mutes.IsMuted(MuteKindJacks, *bead) {
+		j.logger.Info("jack notification muted", "id", bead.ID)
+		return
+	}
 
 	j.batchMu.Lock()
 	j.batch = append(j.batch, *bead)
@@ -97,7 +107,9 @@ func (j *Jacks) handleCreated(ctx context.Context, data []byte) {
 	// Send individual notifications for first N.
 	if batchLen <= batchThreshold {
 		j.batchMu.Unlock()
-		if err := j.notifier.NotifyJackOn(ctx, *bead); err != nil {
+		err := j.notifier.NotifyJackOn(ctx, *bead)
+		j.metrics.ObserveNotification("jack_on", err)
+		if err != nil {
 			j.logger.Error("failed to notify jack on", "id", bead.ID, "error", err)
 		}
 		return
@@ -127,7 +139,9 @@ func (j *Jacks) flushBatch() {
 	// The first batchThreshold were already notified individually.
 	overflow := batch[batchThreshold:]
 	if j.notifier != nil {
-		if err := j.notifier.NotifyJackOnBatch(ctx, overflow); err != nil {
+		err := j.notifier.NotifyJackOnBatch(ctx, overflow)
+		j.metrics.ObserveNotification("jack_on_batch", err)
+		if err != nil {
 			j.logger.Error("failed to notify jack batch", "count", len(overflow), "error", err)
 		}
 	}
@@ -153,8 +167,15 @@ func (j *Jacks) handleClosed(ctx context.Context, data []byte) {
 	j.offSeen[bead.ID] = true
 	j.mu.Unlock()
 
+	if j.mutes.IsMuted(MuteKindJacks, *bead) {
+		j.logger.Info("jack notification muted", "id", bead.ID)
+		return
+	}
+
 	if j.notifier != nil {
-		if err := j.notifier.NotifyJackOff(ctx, *bead); err != nil {
+		err := j.notifier.NotifyJackOff(ctx, *bead)
+		j.metrics.ObserveNotification("jack_off", err)
+		if err != nil {
 			j.logger.Error("failed to notify jack off", "id", bead.ID, "error", err)
 		}
 	}
@@ -194,8 +215,15 @@ func (j *Jacks) handleUpdated(ctx context.Context, data []byte) {
 	j.expiredSeen[bead.ID] = time.Now()
 	j.mu.Unlock()
 
+	if j.mutes.IsMuted(MuteKindJacks, *bead) {
+		j.logger.Info("jack notification muted", "id", bead.ID)
+		return
+	}
+
 	if j.notifier != nil {
-		if err := j.notifier.NotifyJackExpired(ctx, *bead); err != nil {
+		err := j.notifier.NotifyJackExpired(ctx, *bead)
+		j.metrics.ObserveNotification("jack_expired", err)
+		if err != nil {
 			j.logger.Error("failed to notify jack expired", "id", bead.ID, "error", err)
 		}
 	}
@@ -1,8 +1,11 @@
 // Package bridge provides the JIRA REST API v3 HTTP client.
 //
 // JiraClient wraps JIRA Cloud REST API methods needed by the jira-bridge:
-// search, get issue, transition, comment, and remote link operations.
-// It uses basic auth (email:apiToken) and returns typed Go structs.
+// search, get issue, transition, assign, comment, remote link, and
+// attachment operations. Requests are rate limited and retried (see
+// jira_ratelimit.go), and writes can be suppressed for dry runs (see
+// jira_dryrun.go). It uses basic auth (email:apiToken) and returns typed
+// Go structs.
 package bridge
 
 import (
@@ -13,10 +16,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 // JiraClient is an HTTP client for the JIRA REST API v3.
@@ -25,24 +32,59 @@ type JiraClient struct {
 	authHeader string
 	httpClient *http.Client
 	logger     *slog.Logger
+
+	// Rate limiting and backoff; see jira_ratelimit.go.
+	limiter    *rate.Limiter
+	sem        chan struct{}
+	maxRetries int
+	retryBase  time.Duration
+	metrics    *jiraClientMetrics
+
+	dryRun io.Writer // non-nil: print writes instead of sending; see jira_dryrun.go
+
+	mu          sync.Mutex
+	pausedUntil time.Time
 }
 
 // JiraClientConfig holds configuration for creating a JiraClient.
 type JiraClientConfig struct {
-	BaseURL  string // e.g., "https://pihealth.atlassian.net"
-	Email    string
-	APIToken string
-	Logger   *slog.Logger
+	BaseURL           string // e.g., "https://pihealth.atlassian.net"
+	Email             string
+	APIToken          string
+	RequestsPerSecond float64   // sustained request rate (default 5)
+	Burst             int       // requests allowed above the rate in a burst (default 10)
+	MaxConcurrent     int       // in-flight request cap (default 4)
+	MaxRetries        int       // retries for 429/5xx responses (default 3)
+	DryRun            io.Writer // non-nil: print mutating requests here instead of sending them
+	Logger            *slog.Logger
 }
 
 // NewJiraClient creates a new JIRA REST API client.
 func NewJiraClient(cfg JiraClientConfig) *JiraClient {
+	if cfg.RequestsPerSecond <= 0 {
+		cfg.RequestsPerSecond = defaultJiraRequestsPerSecond
+	}
+	if cfg.Burst <= 0 {
+		cfg.Burst = defaultJiraBurst
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = defaultJiraMaxConcurrent
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = defaultJiraMaxRetries
+	}
 	auth := base64.StdEncoding.EncodeToString([]byte(cfg.Email + ":" + cfg.APIToken))
 	return &JiraClient{
 		baseURL:    strings.TrimRight(cfg.BaseURL, "/"),
 		authHeader: "Basic " + auth,
 		httpClient: &http.Client{Timeout: 30 * time.Second},
 		logger:     cfg.Logger,
+		limiter:    rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), cfg.Burst),
+		sem:        make(chan struct{}, cfg.MaxConcurrent),
+		maxRetries: cfg.MaxRetries,
+		retryBase:  jiraRetryBase,
+		metrics:    newJiraClientMetrics(),
+		dryRun:     cfg.DryRun,
 	}
 }
 
@@ -55,17 +97,52 @@ type JiraIssue struct {
 
 // JiraIssueFields contains the fields of a JIRA issue.
 type JiraIssueFields struct {
-	Summary     string          `json:"summary"`
-	Description json.RawMessage `json:"description"` // ADF format
-	Status      *JiraNamedRef   `json:"status"`
-	IssueType   *JiraNamedRef   `json:"issuetype"`
-	Priority    *JiraNamedRef   `json:"priority"`
-	Reporter    *JiraUser       `json:"reporter"`
-	Assignee    *JiraUser       `json:"assignee"`
-	Labels      []string        `json:"labels"`
-	Parent      *JiraParentRef  `json:"parent"` // epic link
-	Created     string          `json:"created"`
-	Updated     string          `json:"updated"`
+	Summary     string           `json:"summary"`
+	Description json.RawMessage  `json:"description"` // ADF format
+	Status      *JiraNamedRef    `json:"status"`
+	IssueType   *JiraNamedRef    `json:"issuetype"`
+	Priority    *JiraNamedRef    `json:"priority"`
+	Reporter    *JiraUser        `json:"reporter"`
+	Assignee    *JiraUser        `json:"assignee"`
+	Labels      []string         `json:"labels"`
+	Parent      *JiraParentRef   `json:"parent"` // epic link
+	Created     string           `json:"created"`
+	Updated     string           `json:"updated"`
+	Attachments []JiraAttachment `json:"attachment"`
+	Components  []JiraNamedRef   `json:"components"`
+
+	// Custom holds customfield_* values, which vary per JIRA instance.
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the standard fields and collects custom fields.
+func (f *JiraIssueFields) UnmarshalJSON(data []byte) error {
+	type plain JiraIssueFields
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	f.Custom = nil
+	for k, v := range all {
+		if strings.HasPrefix(k, "customfield_") && string(v) != "null" {
+			if f.Custom == nil {
+				f.Custom = make(map[string]json.RawMessage)
+			}
+			f.Custom[k] = v
+		}
+	}
+	return nil
+}
+
+// JiraAttachment is a file attached to a JIRA issue.
+type JiraAttachment struct {
+	ID       string `json:"id"`
+	Filename string `json:"filename"`
+	MimeType string `json:"mimeType"`
+	Size     int64  `json:"size"`
 }
 
 // JiraNamedRef is a JIRA object with a name field (status, priority, issuetype).
@@ -115,6 +192,38 @@ func (c *JiraClient) SearchIssues(ctx context.Context, jql string, fields []stri
 	return result.Issues, nil
 }
 
+// SearchAllIssues searches JIRA issues using JQL, following nextPageToken
+// until every matching issue has been fetched.
+func (c *JiraClient) SearchAllIssues(ctx context.Context, jql string, fields []string) ([]JiraIssue, error) {
+	var all []JiraIssue
+	pageToken := ""
+	for {
+		q := url.Values{}
+		q.Set("jql", jql)
+		if len(fields) > 0 {
+			q.Set("fields", strings.Join(fields, ","))
+		}
+		q.Set("maxResults", "100")
+		if pageToken != "" {
+			q.Set("nextPageToken", pageToken)
+		}
+
+		var result struct {
+			Issues        []JiraIssue `json:"issues"`
+			NextPageToken string      `json:"nextPageToken"`
+			IsLast        bool        `json:"isLast"`
+		}
+		if err := c.doJSON(ctx, http.MethodGet, "/rest/api/3/search/jql?"+q.Encode(), nil, &result); err != nil {
+			return all, fmt.Errorf("JIRA search: %w", err)
+		}
+		all = append(all, result.Issues...)
+		if result.IsLast || result.NextPageToken == "" {
+			return all, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
 // GetIssue fetches a single JIRA issue by key.
 func (c *JiraClient) GetIssue(ctx context.Context, key string) (*JiraIssue, error) {
 	var issue JiraIssue
@@ -170,14 +279,17 @@ func (c *JiraClient) AddComment(ctx context.Context, key, text string) error {
 	return nil
 }
 
-// AddRemoteLink adds a remote link to a JIRA issue.
-func (c *JiraClient) AddRemoteLink(ctx context.Context, key, linkURL, title string) error {
-	body := map[string]any{
-		"object": map[string]any{
-			"url":   linkURL,
-			"title": title,
-		},
+// AddRemoteLink adds a remote link to a JIRA issue. summary, if non-empty, is
+// shown under the link title (e.g. a diff summary for an MR).
+func (c *JiraClient) AddRemoteLink(ctx context.Context, key, linkURL, title, summary string) error {
+	object := map[string]any{
+		"url":   linkURL,
+		"title": title,
 	}
+	if summary != "" {
+		object["summary"] = summary
+	}
+	body := map[string]any{"object": object}
 	path := "/rest/api/3/issue/" + url.PathEscape(key) + "/remotelink"
 	if err := c.doJSON(ctx, http.MethodPost, path, body, nil); err != nil {
 		return fmt.Errorf("JIRA add remote link to %s: %w", key, err)
@@ -185,31 +297,127 @@ func (c *JiraClient) AddRemoteLink(ctx context.Context, key, linkURL, title stri
 	return nil
 }
 
-// doJSON performs an HTTP request against the JIRA API with JSON body/response.
-func (c *JiraClient) doJSON(ctx context.Context, method, path string, body any, result any) error {
-	var bodyReader io.Reader
-	if body != nil {
-		data, err := json.Marshal(body)
+// AssignIssue sets the assignee of a JIRA issue by account ID.
+func (c *JiraClient) AssignIssue(ctx context.Context, key, accountID string) error {
+	body := map[string]any{"accountId": accountID}
+	path := "/rest/api/3/issue/" + url.PathEscape(key) + "/assignee"
+	if err := c.doJSON(ctx, http.MethodPut, path, body, nil); err != nil {
+		return fmt.Errorf("JIRA assign %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetIssueField sets a single field (e.g. a custom field) on a JIRA issue.
+func (c *JiraClient) SetIssueField(ctx context.Context, key, field string, value any) error {
+	body := map[string]any{"fields": map[string]any{field: value}}
+	path := "/rest/api/3/issue/" + url.PathEscape(key)
+	if err := c.doJSON(ctx, http.MethodPut, path, body, nil); err != nil {
+		return fmt.Errorf("JIRA set %s on %s: %w", field, key, err)
+	}
+	return nil
+}
+
+// AddAttachment uploads a file to a JIRA issue.
+func (c *JiraClient) AddAttachment(ctx context.Context, key, filename string, content []byte) error {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	part, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		return fmt.Errorf("JIRA add attachment to %s: %w", key, err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return fmt.Errorf("JIRA add attachment to %s: %w", key, err)
+	}
+	if err := mw.Close(); err != nil {
+		return fmt.Errorf("JIRA add attachment to %s: %w", key, err)
+	}
+
+	path := "/rest/api/3/issue/" + url.PathEscape(key) + "/attachments"
+	resp, err := c.send(ctx, c.httpClient, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(buf.Bytes()))
 		if err != nil {
-			return fmt.Errorf("marshal JIRA request: %w", err)
+			return nil, err
 		}
-		bodyReader = bytes.NewReader(data)
+		req.Header.Set("Authorization", c.authHeader)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		req.Header.Set("X-Atlassian-Token", "no-check") // required by JIRA for uploads
+		return req, nil
+	})
+	if err != nil {
+		return fmt.Errorf("JIRA add attachment to %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("JIRA add attachment to %s returned %d: %s", key, resp.StatusCode, truncate(string(respBody), 512))
 	}
+	return nil
+}
 
-	reqURL := c.baseURL + path
-	req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
+// AttachmentURL returns a pre-signed download URL for an attachment. JIRA
+// answers the content endpoint with a redirect to short-lived storage, which
+// is returned instead of being followed, so the caller needs no JIRA
+// credentials to fetch it.
+func (c *JiraClient) AttachmentURL(ctx context.Context, id string) (string, error) {
+	path := "/rest/api/3/attachment/content/" + url.PathEscape(id)
+	client := *c.httpClient
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := c.send(ctx, &client, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", c.authHeader)
+		return req, nil
+	})
 	if err != nil {
-		return fmt.Errorf("create JIRA request: %w", err)
+		return "", fmt.Errorf("JIRA attachment %s: %w", id, err)
 	}
-	req.Header.Set("Authorization", c.authHeader)
-	req.Header.Set("Accept", "application/json")
+	defer resp.Body.Close()
+
+	loc := resp.Header.Get("Location")
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 || loc == "" {
+		return "", fmt.Errorf("JIRA attachment %s: expected redirect, got %d", id, resp.StatusCode)
+	}
+	u, err := resp.Request.URL.Parse(loc)
+	if err != nil {
+		return "", fmt.Errorf("JIRA attachment %s: bad redirect %q: %w", id, loc, err)
+	}
+	return u.String(), nil
+}
+
+// doJSON performs an HTTP request against the JIRA API with JSON body/response.
+func (c *JiraClient) doJSON(ctx context.Context, method, path string, body any, result any) error {
+	var data []byte
 	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("marshal JIRA request: %w", err)
+		}
 	}
 
-	resp, err := c.httpClient.Do(req)
+	reqURL := c.baseURL + path
+	resp, err := c.send(ctx, c.httpClient, func() (*http.Request, error) {
+		var bodyReader io.Reader
+		if data != nil {
+			bodyReader = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, reqURL, bodyReader)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", c.authHeader)
+		req.Header.Set("Accept", "application/json")
+		if data != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, nil
+	})
 	if err != nil {
-		return fmt.Errorf("JIRA request failed: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
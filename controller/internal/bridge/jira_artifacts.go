@@ -0,0 +1,120 @@
+// Package bridge provides JIRA artifact mirroring.
+//
+// Inbound, the poller writes the attachments of a new JIRA issue onto its
+// task bead as a jira_attachments JSON list of pre-signed download URLs, so
+// the agent can pull specs and screenshots into its workspace without JIRA
+// credentials. Outbound, JiraSync attaches an agent's MR with its diff summary
+// as a remote link (see handleUpdated) and, when enabled, uploads the content
+// of report beads as markdown attachments on the JIRA issue of the task the
+// reporting agent is working on.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// jiraAttachmentsField holds the JSON list of issue attachments on a bead.
+const jiraAttachmentsField = "jira_attachments"
+
+// jiraMaxAttachments caps how many attachments are resolved per issue.
+const jiraMaxAttachments = 20
+
+// beadAttachment is an entry in the jira_attachments bead field.
+type beadAttachment struct {
+	Filename string `json:"filename"`
+	MimeType string `json:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	URL      string `json:"url"`
+}
+
+// attachmentList resolves pre-signed URLs for an issue's attachments and
+// returns them as JSON, or "" when there are none. Attachments whose URL
+// can't be resolved are skipped.
+func (p *JiraPoller) attachmentList(ctx context.Context, issue JiraIssue) string {
+	var list []beadAttachment
+	for _, a := range issue.Fields.Attachments {
+		if len(list) == jiraMaxAttachments {
+			p.cfg.Logger.Warn("JIRA issue has too many attachments, truncating",
+				"key", issue.Key, "total", len(issue.Fields.Attachments), "kept", jiraMaxAttachments)
+			break
+		}
+		u, err := p.jira.AttachmentURL(ctx, a.ID)
+		if err != nil {
+			p.cfg.Logger.Warn("failed to resolve JIRA attachment URL",
+				"key", issue.Key, "attachment", a.Filename, "error", err)
+			continue
+		}
+		list = append(list, beadAttachment{Filename: a.Filename, MimeType: a.MimeType, Size: a.Size, URL: u})
+	}
+	if len(list) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(list)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// unsafeFilenameChars matches characters replaced in attachment filenames.
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// syncReport uploads a closed report bead's content to the JIRA issue of the
+// task the reporting decision's agent is working on.
+func (s *JiraSync) syncReport(ctx context.Context, bead BeadEvent) {
+	if !s.uploadReports || s.daemon == nil {
+		return
+	}
+	if s.isDuplicate("report:" + bead.ID) {
+		return
+	}
+
+	// SSE close events may strip large fields; fetch the full report.
+	fields := bead.Fields
+	if fields["content"] == "" || fields["decision_id"] == "" {
+		detail, err := s.daemon.GetBead(ctx, bead.ID)
+		if err != nil {
+			s.logger.Warn("failed to fetch report bead", "report", bead.ID, "error", err)
+			return
+		}
+		fields = detail.Fields
+	}
+	content, decisionID := fields["content"], fields["decision_id"]
+	if content == "" || decisionID == "" {
+		return
+	}
+
+	decision, err := s.daemon.GetBead(ctx, decisionID)
+	if err != nil {
+		s.logger.Warn("failed to fetch report decision", "report", bead.ID, "decision", decisionID, "error", err)
+		return
+	}
+	agent := extractAgentName(decision.Assignee)
+	if agent == "" {
+		return
+	}
+	task, err := s.daemon.ListAssignedTask(ctx, agent)
+	if err != nil || task == nil {
+		return
+	}
+	jiraKey := task.Fields["jira_key"]
+	if jiraKey == "" {
+		return
+	}
+
+	reportType := fields["report_type"]
+	if reportType == "" {
+		reportType = "report"
+	}
+	filename := unsafeFilenameChars.ReplaceAllString(fmt.Sprintf("%s-%s.md", reportType, bead.ID), "-")
+	if err := s.jira.AddAttachment(ctx, jiraKey, filename, []byte(content)); err != nil {
+		s.logger.Error("failed to upload report to JIRA",
+			"jira_key", jiraKey, "report", bead.ID, "error", err)
+		return
+	}
+	s.logger.Info("uploaded report to JIRA",
+		"jira_key", jiraKey, "report", bead.ID, "file", filename, "task", task.ID)
+}
@@ -0,0 +1,133 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestJiraPoller_WritesAttachmentURLs(t *testing.T) {
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/attachment/content/100":
+			http.Redirect(w, r, "https://media.example.com/file/spec?token=abc", http.StatusSeeOther)
+		default:
+			// Unresolvable attachments are skipped.
+			http.NotFound(w, r)
+		}
+	}))
+	defer jiraServer.Close()
+
+	daemon := newMockJiraDaemon()
+	poller := NewJiraPoller(newTestJiraClient(jiraServer.URL), daemon, JiraPollerConfig{Logger: slog.Default()})
+
+	issue := JiraIssue{Key: "PE-1", Fields: JiraIssueFields{
+		Summary: "Spec attached",
+		Attachments: []JiraAttachment{
+			{ID: "100", Filename: "spec.pdf", MimeType: "application/pdf", Size: 2048},
+			{ID: "101", Filename: "gone.png"},
+		},
+	}}
+	if _, err := poller.Ingest(context.Background(), issue); err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+
+	var got []beadAttachment
+	for _, b := range daemon.getBeads() {
+		if err := json.Unmarshal([]byte(b.Fields[jiraAttachmentsField]), &got); err != nil {
+			t.Fatalf("parse %s: %v", jiraAttachmentsField, err)
+		}
+	}
+	want := beadAttachment{Filename: "spec.pdf", MimeType: "application/pdf", Size: 2048, URL: "https://media.example.com/file/spec?token=abc"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("attachments = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestJiraSync_MRLinkIncludesDiffSummary(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		summary string
+	)
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/api/3/issue/PE-1/remotelink" {
+			var body struct {
+				Object map[string]string `json:"object"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			summary = body.Object["summary"]
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer jiraServer.Close()
+
+	s := NewJiraSync(JiraSyncConfig{Jira: newTestJiraClient(jiraServer.URL), Logger: slog.Default()})
+	s.handleUpdated(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "bd-1", Type: "task",
+		Fields: map[string]string{"jira_key": "PE-1", "mr_url": "https://gitlab.example.com/mr/1", "mr_diff_summary": "3 files, +40 -7"},
+	}))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if summary != "3 files, +40 -7" {
+		t.Errorf("remote link summary = %q", summary)
+	}
+}
+
+func TestJiraSync_UploadsReportToAgentTask(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		uploads []string
+	)
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/3/issue/PE-7/attachments" || r.Header.Get("X-Atlassian-Token") != "no-check" {
+			http.NotFound(w, r)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		mu.Lock()
+		uploads = append(uploads, header.Filename+"|"+string(data))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer jiraServer.Close()
+
+	daemon := newMockJiraSyncDaemon()
+	daemon.tasks["k8s"] = &beadsapi.BeadDetail{ID: "bd-task-1", Fields: map[string]string{"jira_key": "PE-7"}}
+	daemon.beads["dec-1"] = &beadsapi.BeadDetail{ID: "dec-1", Assignee: "gasboat/crew/k8s"}
+	daemon.beads["rpt-1"] = &beadsapi.BeadDetail{ID: "rpt-1", Fields: map[string]string{
+		"decision_id": "dec-1", "report_type": "summary", "content": "# Findings\nAll good.",
+	}}
+
+	ctx := context.Background()
+	report := marshalSSEBeadPayload(BeadEvent{ID: "rpt-1", Type: "report"})
+
+	// Disabled by default.
+	NewJiraSync(JiraSyncConfig{Jira: newTestJiraClient(jiraServer.URL), Daemon: daemon, Logger: slog.Default()}).handleClosed(ctx, report)
+
+	s := NewJiraSync(JiraSyncConfig{
+		Jira: newTestJiraClient(jiraServer.URL), Daemon: daemon, UploadReports: true, Logger: slog.Default(),
+	})
+	s.handleClosed(ctx, report)
+	s.handleClosed(ctx, report) // duplicate close event
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(uploads) != 1 || uploads[0] != "summary-rpt-1.md|# Findings\nAll good." {
+		t.Errorf("uploads = %q", uploads)
+	}
+}
@@ -0,0 +1,129 @@
+// Package bridge provides assignment sync between JIRA users and agents.
+//
+// JiraAssignment maps gasboat agents and humans to JIRA accounts. When a
+// JIRA-sourced bead is claimed, JiraSync assigns the issue to the claimant's
+// mapped account and optionally records the agent name in a custom field.
+// When a JIRA webhook reports a tracked issue assigned to one of the mapped
+// bot accounts, the bead is made ready (open, unassigned) so the crew of its
+// project picks it up.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	"sigs.k8s.io/yaml"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// JiraAssignment maps gasboat identities to JIRA accounts.
+//
+// Example (YAML):
+//
+//	accounts:             # agent or human name → JIRA account ID
+//	  alice: 557058:f1d2...
+//	  "*": 5b10ac8d82e0...   # any other agent: the shared bot account
+//	agent_field: customfield_10100   # optional: records the claiming agent's name
+//	bot_accounts:         # JIRA accounts whose assignment readies the bead
+//	  - 5b10ac8d82e0...
+type JiraAssignment struct {
+	Accounts    map[string]string `json:"accounts,omitempty"`
+	AgentField  string            `json:"agent_field,omitempty"`
+	BotAccounts []string          `json:"bot_accounts,omitempty"`
+}
+
+// ParseJiraAssignment parses a JSON or YAML assignment mapping.
+func ParseJiraAssignment(data []byte) (JiraAssignment, error) {
+	var a JiraAssignment
+	if err := yaml.UnmarshalStrict(data, &a); err != nil {
+		return JiraAssignment{}, fmt.Errorf("parse JIRA assignment: %w", err)
+	}
+	return a, nil
+}
+
+// LoadJiraAssignment reads an assignment mapping from a JSON or YAML file.
+func LoadJiraAssignment(path string) (JiraAssignment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return JiraAssignment{}, fmt.Errorf("read JIRA assignment: %w", err)
+	}
+	return ParseJiraAssignment(data)
+}
+
+// AccountFor returns the JIRA account for a bead assignee. The full
+// identity is tried first, then the bare agent name, then the "*" entry.
+func (a JiraAssignment) AccountFor(assignee string) (string, bool) {
+	for _, k := range []string{assignee, extractAgentName(assignee), "*"} {
+		if id := a.Accounts[k]; id != "" {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// IsBot reports whether a JIRA account is one of the bot accounts.
+func (a JiraAssignment) IsBot(accountID string) bool {
+	return accountID != "" && slices.Contains(a.BotAccounts, accountID)
+}
+
+// syncAssignee assigns the JIRA issue to the account mapped to a claimed
+// bead's assignee (best-effort).
+func (s *JiraSync) syncAssignee(ctx context.Context, bead BeadEvent, jiraKey string) {
+	if bead.Status != "in_progress" || bead.Assignee == "" {
+		return
+	}
+	s.mu.Lock()
+	if s.assigned[bead.ID] == bead.Assignee {
+		s.mu.Unlock()
+		return
+	}
+	s.assigned[bead.ID] = bead.Assignee
+	s.mu.Unlock()
+
+	if accountID, ok := s.assignment.AccountFor(bead.Assignee); ok {
+		if err := s.jira.AssignIssue(ctx, jiraKey, accountID); err != nil {
+			s.logger.Warn("failed to assign JIRA issue",
+				"jira_key", jiraKey, "assignee", bead.Assignee, "error", err)
+		} else {
+			s.logger.Info("assigned JIRA issue for bead claim",
+				"jira_key", jiraKey, "bead", bead.ID, "assignee", bead.Assignee)
+		}
+	}
+	if field := s.assignment.AgentField; field != "" {
+		if err := s.jira.SetIssueField(ctx, jiraKey, field, extractAgentName(bead.Assignee)); err != nil {
+			s.logger.Warn("failed to set JIRA agent field",
+				"jira_key", jiraKey, "field", field, "error", err)
+		}
+	}
+}
+
+// ApplyJiraAssignee makes a tracked bead ready for pickup when its JIRA issue
+// is assigned to a bot account. Beads already claimed or closed are left
+// alone, which also ignores the echo of syncAssignee. It reports whether the
+// bead was changed.
+func (s *JiraSync) ApplyJiraAssignee(ctx context.Context, beadID string, issue JiraIssue) (bool, error) {
+	if s.daemon == nil || issue.Fields.Assignee == nil || !s.assignment.IsBot(issue.Fields.Assignee.AccountID) {
+		return false, nil
+	}
+	bead, err := s.daemon.GetBead(ctx, beadID)
+	if err != nil {
+		return false, fmt.Errorf("get bead %s: %w", beadID, err)
+	}
+	switch {
+	case bead.Status == "in_progress" || bead.Status == "closed":
+		return false, nil
+	case bead.Status == "open" && bead.Assignee == "":
+		return false, nil // already ready
+	}
+
+	status, assignee := "open", ""
+	if err := s.daemon.UpdateBead(ctx, beadID, beadsapi.UpdateBeadRequest{Status: &status, Assignee: &assignee}); err != nil {
+		return false, fmt.Errorf("ready bead %s for JIRA bot assignment: %w", beadID, err)
+	}
+	s.logger.Info("bead ready for crew pickup after JIRA bot assignment",
+		"bead", beadID, "jira_key", issue.Key, "was", bead.Status)
+	return true, nil
+}
@@ -0,0 +1,97 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestJiraAssignment_AccountFor(t *testing.T) {
+	a, err := ParseJiraAssignment([]byte("accounts:\n  alice: acct-alice\n  k8s: acct-k8s\n  \"*\": acct-bot\n"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for assignee, want := range map[string]string{
+		"alice":            "acct-alice",
+		"gasboat/crew/k8s": "acct-k8s",
+		"gasboat/crew/web": "acct-bot",
+	} {
+		if got, _ := a.AccountFor(assignee); got != want {
+			t.Errorf("AccountFor(%q) = %q, want %q", assignee, got, want)
+		}
+	}
+	if _, err := ParseJiraAssignment([]byte("acounts: {}")); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestJiraSync_ClaimAssignsJiraIssue(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path+" "+string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer jiraServer.Close()
+
+	s := NewJiraSync(JiraSyncConfig{
+		Jira:       newTestJiraClient(jiraServer.URL),
+		Assignment: JiraAssignment{Accounts: map[string]string{"*": "acct-bot"}, AgentField: "customfield_10100"},
+		Logger:     slog.Default(),
+	})
+	bead := BeadEvent{ID: "bd-1", Status: "in_progress", Assignee: "gasboat/crew/k8s", Fields: map[string]string{"jira_key": "PE-1"}}
+	s.handleUpdated(context.Background(), marshalSSEBeadPayload(bead))
+	s.handleUpdated(context.Background(), marshalSSEBeadPayload(bead)) // unrelated later update
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		`PUT /rest/api/3/issue/PE-1/assignee {"accountId":"acct-bot"}`,
+		`PUT /rest/api/3/issue/PE-1 {"fields":{"customfield_10100":"k8s"}}`,
+	}
+	if len(calls) != 2 || calls[0] != want[0] || calls[1] != want[1] {
+		t.Errorf("calls = %q, want %q", calls, want)
+	}
+}
+
+func TestJiraWebhook_BotAssignmentReadiesBead(t *testing.T) {
+	daemon := newMockJiraSyncDaemon()
+	daemon.beads["bd-1"] = &beadsapi.BeadDetail{ID: "bd-1", Status: "deferred"}
+	daemon.beads["bd-2"] = &beadsapi.BeadDetail{ID: "bd-2", Status: "in_progress", Assignee: "gasboat/crew/k8s"}
+
+	h := newTestJiraWebhook(newMockJiraDaemon())
+	h.sync = NewJiraSync(JiraSyncConfig{
+		Daemon: daemon, Assignment: JiraAssignment{BotAccounts: []string{"acct-bot"}}, Logger: slog.Default(),
+	})
+	h.poller.tracked["PE-1"] = "bd-1"
+	h.poller.tracked["PE-2"] = "bd-2"
+
+	for _, key := range []string{"PE-1", "PE-2"} {
+		body, _ := json.Marshal(map[string]any{
+			"webhookEvent": "jira:issue_updated",
+			"issue":        map[string]any{"key": key, "fields": map[string]any{"assignee": map[string]string{"accountId": "acct-bot"}}},
+		})
+		if code := postJiraWebhook(h, string(body), signJiraWebhook("s3cret", string(body))); code != http.StatusAccepted {
+			t.Errorf("%s: expected 202, got %d", key, code)
+		}
+	}
+
+	if got := daemon.statuses["bd-1"]; got != "open" {
+		t.Errorf("bd-1 status = %q, want open", got)
+	}
+	if _, changed := daemon.statuses["bd-2"]; changed {
+		t.Error("claimed bead must not be readied")
+	}
+}
@@ -0,0 +1,100 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// jiraPagedSearchServer serves PE-1 and PE-2 on the first search page and
+// PE-3 on the second, recording each query's JQL.
+func jiraPagedSearchServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		jqls []string
+	)
+	issue := func(key string) map[string]any {
+		return map[string]any{"key": key, "fields": map[string]any{
+			"summary": "Issue " + key, "issuetype": map[string]string{"name": "Task"},
+		}}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/3/search/jql" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		jqls = append(jqls, r.URL.Query().Get("jql"))
+		mu.Unlock()
+		page := map[string]any{"issues": []any{issue("PE-1"), issue("PE-2")}, "nextPageToken": "p2"}
+		if r.URL.Query().Get("nextPageToken") == "p2" {
+			page = map[string]any{"issues": []any{issue("PE-3")}, "isLast": true}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), jqls...)
+	}
+}
+
+func TestJiraPoller_BackfillPagesAndSkipsTracked(t *testing.T) {
+	srv, jqls := jiraPagedSearchServer(t)
+	daemon := newMockJiraDaemon()
+	daemon.beads["bd-existing"] = &beadsapi.BeadDetail{ID: "bd-existing", Type: "task", Fields: map[string]string{"jira_key": "PE-2"}}
+
+	poller := NewJiraPoller(newTestJiraClient(srv.URL), daemon, JiraPollerConfig{
+		Projects: []string{"PE"}, Statuses: []string{"To Do"}, Logger: slog.Default(),
+	})
+	since := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	res, err := poller.Backfill(context.Background(), since)
+	if err != nil {
+		t.Fatalf("Backfill: %v", err)
+	}
+	if res != (JiraBackfillResult{Found: 3, Created: 2, Skipped: 1}) {
+		t.Errorf("result = %+v", res)
+	}
+
+	got := jqls()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 search pages, got %d", len(got))
+	}
+	want := `project = "PE" AND (status IN ("To Do")) AND created >= "2025-01-31 00:00" ORDER BY created DESC`
+	if got[0] != want {
+		t.Errorf("jql = %q, want %q", got[0], want)
+	}
+	if !poller.IsTracked("PE-1") || !poller.IsTracked("PE-3") {
+		t.Error("backfilled issues should be tracked")
+	}
+	if n := len(daemon.getBeads()); n != 3 {
+		t.Errorf("expected 3 beads, got %d", n)
+	}
+}
+
+func TestJiraPoller_BackfillSearchError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errorMessages":["bad jql"]}`))
+	}))
+	defer srv.Close()
+
+	poller := NewJiraPoller(newTestJiraClient(srv.URL), newMockJiraDaemon(), JiraPollerConfig{
+		Projects: []string{"PE"}, Logger: slog.Default(),
+	})
+	if _, err := poller.Backfill(context.Background(), time.Now()); err == nil || !strings.Contains(err.Error(), "bad jql") {
+		t.Errorf("expected search error, got %v", err)
+	}
+}
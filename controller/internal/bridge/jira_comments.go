@@ -0,0 +1,139 @@
+// Package bridge provides two-way JIRA comment sync.
+//
+// For JIRA projects with comment sync enabled, JIRA comments (from webhooks)
+// are copied onto the linked task bead, and bead progress notes and decision
+// outcomes are posted back as JIRA comments. Bridge-authored JIRA comments
+// start with jiraCommentMarker so they are never copied back, and a hash of
+// the last posted notes is stored on the bead so notes aren't reposted.
+package bridge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// jiraCommentMarker prefixes every comment the bridge posts to JIRA. Incoming
+// JIRA comments carrying it are the bridge's own and are not copied back.
+const jiraCommentMarker = "[gasboat]"
+
+// jiraCommentAuthorPrefix prefixes the author of bead comments copied from
+// JIRA, e.g. "jira:Jane Doe".
+const jiraCommentAuthorPrefix = "jira:"
+
+// jiraNotesSyncedField records a hash of the bead notes last posted to JIRA,
+// so restarts and unrelated bead updates don't repost them.
+const jiraNotesSyncedField = "jira_notes_synced"
+
+// JiraComment is a comment in a JIRA comment webhook payload. Body is ADF on
+// the v3 API and a plain string on older webhook formats.
+type JiraComment struct {
+	ID     string          `json:"id"`
+	Body   json.RawMessage `json:"body"`
+	Author *JiraUser       `json:"author"`
+}
+
+// text returns the comment body as markdown.
+func (c JiraComment) text() string {
+	var s string
+	if err := json.Unmarshal(c.Body, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	return adfToMarkdown(c.Body)
+}
+
+// commentsEnabled reports whether comment sync is on for the issue's project.
+func (s *JiraSync) commentsEnabled(jiraKey string) bool {
+	if s.daemon == nil {
+		return false
+	}
+	project, _, _ := strings.Cut(jiraKey, "-")
+	for _, p := range s.commentProjects {
+		if p == "*" || strings.EqualFold(p, project) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyJiraComment copies a JIRA comment onto the linked bead. Comments the
+// bridge itself posted are skipped. It reports whether a comment was added.
+func (s *JiraSync) ApplyJiraComment(ctx context.Context, beadID, jiraKey string, c JiraComment) (bool, error) {
+	if !s.commentsEnabled(jiraKey) {
+		return false, nil
+	}
+	text := c.text()
+	if text == "" || strings.HasPrefix(text, jiraCommentMarker) {
+		return false, nil
+	}
+	if c.ID != "" && s.isDuplicate("jira-comment:"+c.ID) {
+		return false, nil
+	}
+	author := "unknown"
+	if c.Author != nil && c.Author.DisplayName != "" {
+		author = c.Author.DisplayName
+	}
+	if err := s.daemon.AddComment(ctx, beadID, jiraCommentAuthorPrefix+author, text); err != nil {
+		return false, fmt.Errorf("copy JIRA comment on %s to bead %s: %w", jiraKey, beadID, err)
+	}
+	s.logger.Info("copied JIRA comment to bead", "jira_key", jiraKey, "bead", beadID, "author", author)
+	return true, nil
+}
+
+// syncNotes posts a bead's progress notes to JIRA when they change.
+func (s *JiraSync) syncNotes(ctx context.Context, bead BeadEvent, jiraKey string) {
+	notes := strings.TrimSpace(bead.Notes)
+	if notes == "" || !s.commentsEnabled(jiraKey) {
+		return
+	}
+	sum := sha256.Sum256([]byte(notes))
+	hash := hex.EncodeToString(sum[:8])
+	if bead.Fields[jiraNotesSyncedField] == hash || s.isDuplicate("notes:"+bead.ID+":"+hash) {
+		return
+	}
+
+	if err := s.jira.AddComment(ctx, jiraKey, jiraCommentMarker+" Progress update:\n"+notes); err != nil {
+		s.logger.Error("failed to post bead notes to JIRA", "jira_key", jiraKey, "bead", bead.ID, "error", err)
+		return
+	}
+	if err := s.daemon.UpdateBeadFields(ctx, bead.ID, map[string]string{jiraNotesSyncedField: hash}); err != nil {
+		s.logger.Warn("failed to record synced notes hash", "bead", bead.ID, "error", err)
+	}
+	s.logger.Info("posted bead notes to JIRA", "jira_key", jiraKey, "bead", bead.ID)
+}
+
+// syncDecisionOutcome comments a resolved decision on the JIRA issue of the
+// task its agent is working on.
+func (s *JiraSync) syncDecisionOutcome(ctx context.Context, bead BeadEvent) {
+	chosen := bead.Fields["chosen"]
+	agent := extractAgentName(bead.Assignee)
+	if chosen == "" || agent == "" || s.daemon == nil {
+		return
+	}
+	task, err := s.daemon.ListAssignedTask(ctx, agent)
+	if err != nil || task == nil {
+		return
+	}
+	jiraKey := task.Fields["jira_key"]
+	if jiraKey == "" || !s.commentsEnabled(jiraKey) {
+		return
+	}
+	if s.isDuplicate("decision:" + bead.ID) {
+		return
+	}
+
+	comment := fmt.Sprintf("%s Decision resolved for %s: %s\nChosen: %s",
+		jiraCommentMarker, agent, decisionQuestion(bead.Fields), chosen)
+	if rationale := bead.Fields["rationale"]; rationale != "" {
+		comment += "\nRationale: " + rationale
+	}
+	if err := s.jira.AddComment(ctx, jiraKey, comment); err != nil {
+		s.logger.Error("failed to post decision outcome to JIRA",
+			"jira_key", jiraKey, "decision", bead.ID, "error", err)
+		return
+	}
+	s.logger.Info("posted decision outcome to JIRA", "jira_key", jiraKey, "decision", bead.ID, "task", task.ID)
+}
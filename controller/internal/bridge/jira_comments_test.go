@@ -0,0 +1,108 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// jiraCommentServer records comment bodies posted to any issue.
+func jiraCommentServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		comments []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/comment") {
+			http.NotFound(w, r)
+			return
+		}
+		var body struct {
+			Body json.RawMessage `json:"body"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		comments = append(comments, strings.TrimPrefix(r.URL.Path, "/rest/api/3/issue/")+"|"+adfToMarkdown(body.Body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, comments...)
+	}
+}
+
+func TestJiraSync_NotesPostedOnceWhenEnabled(t *testing.T) {
+	srv, posted := jiraCommentServer(t)
+	daemon := newMockJiraSyncDaemon()
+	s := NewJiraSync(JiraSyncConfig{
+		Jira: newTestJiraClient(srv.URL), Daemon: daemon, CommentProjects: []string{"PE"}, Logger: slog.Default(),
+	})
+	ctx := context.Background()
+
+	bead := BeadEvent{ID: "bd-1", Type: "task", Notes: "Reproduced; fix in review.", Fields: map[string]string{"jira_key": "PE-1"}}
+	s.handleUpdated(ctx, marshalSSEBeadPayload(bead))
+	// The hash field write triggers another update; it must not repost.
+	bead.Fields[jiraNotesSyncedField] = daemon.fields["bd-1"][jiraNotesSyncedField]
+	s.handleUpdated(ctx, marshalSSEBeadPayload(bead))
+
+	// Comment sync is off for other projects.
+	s.handleUpdated(ctx, marshalSSEBeadPayload(BeadEvent{ID: "bd-2", Notes: "x", Fields: map[string]string{"jira_key": "OPS-1"}}))
+
+	got := posted()
+	if len(got) != 1 || got[0] != "PE-1/comment|[gasboat] Progress update:\nReproduced; fix in review." {
+		t.Errorf("posted = %q", got)
+	}
+}
+
+func TestJiraSync_DecisionOutcomeCommentsOnAgentTask(t *testing.T) {
+	srv, posted := jiraCommentServer(t)
+	daemon := newMockJiraSyncDaemon()
+	daemon.tasks["k8s"] = &beadsapi.BeadDetail{ID: "bd-task-1", Fields: map[string]string{"jira_key": "PE-7"}}
+	s := NewJiraSync(JiraSyncConfig{
+		Jira: newTestJiraClient(srv.URL), Daemon: daemon, CommentProjects: []string{"*"}, Logger: slog.Default(),
+	})
+
+	s.handleClosed(context.Background(), marshalSSEBeadPayload(BeadEvent{
+		ID: "dec-1", Type: "decision", Assignee: "gasboat/crew/k8s",
+		Fields: map[string]string{"prompt": "Which DB?", "chosen": "Postgres", "rationale": "Chosen by @alice via Slack"},
+	}))
+
+	got := posted()
+	want := "PE-7/comment|[gasboat] Decision resolved for k8s: Which DB?\nChosen: Postgres\nRationale: Chosen by @alice via Slack"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("posted = %q, want %q", got, want)
+	}
+}
+
+func TestJiraWebhook_CopiesCommentsSkippingOwn(t *testing.T) {
+	daemon := newMockJiraDaemon()
+	syncDaemon := newMockJiraSyncDaemon()
+	h := newTestJiraWebhook(daemon)
+	h.sync = NewJiraSync(JiraSyncConfig{
+		Jira: newTestJiraClient("https://jira.example.com"), Daemon: syncDaemon, CommentProjects: []string{"PE"}, Logger: slog.Default(),
+	})
+	_ = postJiraWebhook(h, jiraWebhookCreated, signJiraWebhook("s3cret", jiraWebhookCreated))
+	beadID, _ := h.poller.TrackedBead("PE-7001")
+
+	for _, body := range []string{
+		`{"webhookEvent":"comment_created","issue":{"key":"PE-7001"},"comment":{"id":"1","body":"Customer confirmed the fix.","author":{"displayName":"Jane Doe"}}}`,
+		`{"webhookEvent":"comment_created","issue":{"key":"PE-7001"},"comment":{"id":"2","body":"[gasboat] Progress update:\nDone","author":{"displayName":"Bot"}}}`,
+	} {
+		postJiraWebhook(h, body, signJiraWebhook("s3cret", body))
+	}
+
+	if want := beadID + "|jira:Jane Doe|Customer confirmed the fix."; len(syncDaemon.comments) != 1 || syncDaemon.comments[0] != want {
+		t.Errorf("comments = %q, want [%q]", syncDaemon.comments, want)
+	}
+}
@@ -0,0 +1,125 @@
+// Package bridge provides dry-run support for the jira-bridge.
+//
+// In dry-run mode nothing is written to either side: JiraClient prints each
+// mutating JIRA request (transitions, comments, links, assignments,
+// attachments) instead of sending it, and JiraDryRunDaemon wraps the beads
+// daemon so reads pass through while bead creates and updates are printed.
+// Created beads get placeholder IDs so parent/child imports and tracking
+// behave as they would in a real run.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// dryRunWrite prints a mutating request instead of sending it when the
+// client is in dry-run mode, returning a synthetic 204 response. Reads are
+// not intercepted.
+func (c *JiraClient) dryRunWrite(newReq func() (*http.Request, error)) (*http.Response, bool) {
+	if c.dryRun == nil {
+		return nil, false
+	}
+	req, err := newReq()
+	if err != nil || req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return nil, false
+	}
+
+	line := fmt.Sprintf("dry-run: would %s %s", req.Method, req.URL.Path)
+	if req.Body != nil {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if mediaType == "application/json" {
+			body, _ := io.ReadAll(req.Body)
+			line += " " + truncate(string(body), 200)
+		} else if mediaType != "" {
+			line += " (" + mediaType + ")"
+		}
+		req.Body.Close()
+	}
+	fmt.Fprintln(c.dryRun, line)
+
+	return &http.Response{
+		StatusCode: http.StatusNoContent,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    req,
+	}, true
+}
+
+// JiraDaemonReader is the read side of the beads daemon used by the
+// jira-bridge.
+type JiraDaemonReader interface {
+	JiraConfigClient
+	ListTaskBeads(ctx context.Context) ([]*beadsapi.BeadDetail, error)
+	ListAssignedTask(ctx context.Context, agentName string) (*beadsapi.BeadDetail, error)
+	GetBead(ctx context.Context, beadID string) (*beadsapi.BeadDetail, error)
+}
+
+// JiraDryRunDaemon satisfies JiraBeadClient and JiraSyncClient without
+// writing: reads go to the wrapped daemon and writes are printed to out.
+type JiraDryRunDaemon struct {
+	JiraDaemonReader
+	out io.Writer
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewJiraDryRunDaemon wraps daemon for a dry run, printing writes to out.
+func NewJiraDryRunDaemon(daemon JiraDaemonReader, out io.Writer) *JiraDryRunDaemon {
+	return &JiraDryRunDaemon{JiraDaemonReader: daemon, out: out}
+}
+
+// CreateBead prints the bead that would be created and returns a
+// placeholder ID.
+func (d *JiraDryRunDaemon) CreateBead(_ context.Context, req beadsapi.CreateBeadRequest) (string, error) {
+	d.mu.Lock()
+	d.next++
+	id := fmt.Sprintf("dry-run-%d", d.next)
+	d.mu.Unlock()
+
+	fmt.Fprintf(d.out, "dry-run: would create %s bead %s %q priority=%d labels=%s fields=%s\n",
+		req.Type, id, req.Title, req.Priority, strings.Join(req.Labels, ","), string(req.Fields))
+	return id, nil
+}
+
+// AddDependency prints the dependency that would be added.
+func (d *JiraDryRunDaemon) AddDependency(_ context.Context, beadID, dependsOnID, depType, _ string) error {
+	fmt.Fprintf(d.out, "dry-run: would add %s dependency %s → %s\n", depType, beadID, dependsOnID)
+	return nil
+}
+
+// UpdateBead prints the update that would be applied.
+func (d *JiraDryRunDaemon) UpdateBead(_ context.Context, beadID string, req beadsapi.UpdateBeadRequest) error {
+	data, _ := json.Marshal(req)
+	fmt.Fprintf(d.out, "dry-run: would update bead %s %s\n", beadID, data)
+	return nil
+}
+
+// UpdateBeadFields prints the fields that would be set.
+func (d *JiraDryRunDaemon) UpdateBeadFields(_ context.Context, beadID string, fields map[string]string) error {
+	data, _ := json.Marshal(fields)
+	fmt.Fprintf(d.out, "dry-run: would set fields on bead %s %s\n", beadID, data)
+	return nil
+}
+
+// CloseBead prints the close that would be applied.
+func (d *JiraDryRunDaemon) CloseBead(_ context.Context, beadID string, fields map[string]string) error {
+	data, _ := json.Marshal(fields)
+	fmt.Fprintf(d.out, "dry-run: would close bead %s %s\n", beadID, data)
+	return nil
+}
+
+// AddComment prints the comment that would be added.
+func (d *JiraDryRunDaemon) AddComment(_ context.Context, beadID, author, text string) error {
+	fmt.Fprintf(d.out, "dry-run: would comment on bead %s as %s: %s\n", beadID, author, truncate(text, 200))
+	return nil
+}
@@ -0,0 +1,77 @@
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJiraClient_DryRunSkipsWrites(t *testing.T) {
+	var writes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"key":"PE-1"}`))
+	}))
+	defer srv.Close()
+
+	var out strings.Builder
+	c := NewJiraClient(JiraClientConfig{BaseURL: srv.URL, DryRun: &out, Logger: slog.Default()})
+	if issue, err := c.GetIssue(context.Background(), "PE-1"); err != nil || issue.Key != "PE-1" {
+		t.Fatalf("GetIssue = %+v, %v", issue, err)
+	}
+	if err := c.AddComment(context.Background(), "PE-1", "hello"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := c.AddAttachment(context.Background(), "PE-1", "report.md", []byte("# hi")); err != nil {
+		t.Fatalf("AddAttachment: %v", err)
+	}
+
+	if n := writes.Load(); n != 0 {
+		t.Errorf("expected no writes to reach JIRA, got %d", n)
+	}
+	for _, want := range []string{
+		"dry-run: would POST /rest/api/3/issue/PE-1/comment {",
+		"hello",
+		"dry-run: would POST /rest/api/3/issue/PE-1/attachments (multipart/form-data)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestJiraDryRunDaemon_BackfillPrintsWithoutCreating(t *testing.T) {
+	srv, _ := jiraPagedSearchServer(t)
+	reader := struct {
+		*mockJiraSyncDaemon
+		*fakeLeaseStore
+	}{newMockJiraSyncDaemon(), newFakeLeaseStore()}
+
+	var out strings.Builder
+	dry := NewJiraDryRunDaemon(reader, &out)
+	poller := NewJiraPoller(newTestJiraClient(srv.URL), dry, JiraPollerConfig{
+		Projects: []string{"PE"}, ConfigClient: dry, Logger: slog.Default(),
+	})
+	res, err := poller.Backfill(context.Background(), time.Now().Add(-time.Hour))
+	if err != nil || res.Created != 3 {
+		t.Fatalf("Backfill = %+v, %v", res, err)
+	}
+
+	if got := strings.Count(out.String(), "dry-run: would create task bead"); got != 3 {
+		t.Errorf("expected 3 create lines, got %d:\n%s", got, out.String())
+	}
+	if !strings.Contains(out.String(), `"[PE-3] Issue PE-3"`) {
+		t.Errorf("output missing issue title:\n%s", out.String())
+	}
+	if len(reader.tasks) != 0 || len(reader.statuses) != 0 {
+		t.Error("dry run must not write to the daemon")
+	}
+}
@@ -0,0 +1,88 @@
+// Package bridge provides JIRA epic/subtask hierarchy import.
+//
+// When the poller ingests an issue with a parent (an epic, or the story of a
+// subtask), the parent is imported first — even if it falls outside the
+// project filters — and the child bead gets a parent-child dependency on it,
+// so agents working the child can read the parent's context. The child
+// records the parent key in its jira_parent field. When the last open child
+// of a parent closes, JiraSync closes the parent's bead too; that closure
+// syncs back to JIRA like any other and rolls up further for nested levels.
+package bridge
+
+import (
+	"context"
+)
+
+// jiraMaxParentDepth bounds how many parent levels are imported above an
+// issue (subtask → story → epic, plus slack for custom hierarchies).
+const jiraMaxParentDepth = 3
+
+// ensureParentLocked returns the bead ID for a parent issue, importing the
+// issue from JIRA when it isn't tracked yet. It returns "" when the parent
+// can't be imported. Caller must hold p.ingestMu.
+func (p *JiraPoller) ensureParentLocked(ctx context.Context, key string, depth int) string {
+	if id, ok := p.TrackedBead(key); ok {
+		return id
+	}
+	if depth > jiraMaxParentDepth {
+		p.cfg.Logger.Warn("JIRA hierarchy too deep, not importing parent", "parent", key, "depth", depth)
+		return ""
+	}
+
+	parent, err := p.jira.GetIssue(ctx, key)
+	if err != nil {
+		p.cfg.Logger.Warn("failed to fetch JIRA parent issue", "parent", key, "error", err)
+		return ""
+	}
+	beadID, err := p.createBeadFromIssue(ctx, *parent, depth)
+	if err != nil {
+		p.cfg.Logger.Warn("failed to create bead for JIRA parent issue", "parent", key, "error", err)
+		return ""
+	}
+
+	p.mu.Lock()
+	p.tracked[key] = beadID
+	p.mu.Unlock()
+
+	p.cfg.Logger.Info("imported JIRA parent issue", "key", key, "bead_id", beadID)
+	return beadID
+}
+
+// rollUpParent closes the parent's bead once none of its children remain
+// open. Only imported children count; JIRA children outside the project
+// filters have no bead and don't hold the parent open.
+func (s *JiraSync) rollUpParent(ctx context.Context, child BeadEvent) {
+	parentKey := child.Fields["jira_parent"]
+	if parentKey == "" || s.daemon == nil {
+		return
+	}
+
+	beads, err := s.daemon.ListTaskBeads(ctx)
+	if err != nil {
+		s.logger.Warn("failed to list task beads for JIRA roll-up", "parent", parentKey, "error", err)
+		return
+	}
+	parentBead := ""
+	for _, b := range beads {
+		if b.ID == child.ID {
+			continue
+		}
+		if b.Fields["jira_parent"] == parentKey {
+			return // a sibling is still open
+		}
+		if b.Fields["jira_key"] == parentKey {
+			parentBead = b.ID
+		}
+	}
+	if parentBead == "" {
+		return // parent already closed or never imported
+	}
+
+	if err := s.daemon.CloseBead(ctx, parentBead, nil); err != nil {
+		s.logger.Error("failed to roll up JIRA parent bead",
+			"parent", parentKey, "bead", parentBead, "error", err)
+		return
+	}
+	s.logger.Info("closed JIRA parent bead after last child closed",
+		"parent", parentKey, "bead", parentBead, "child", child.ID)
+}
@@ -0,0 +1,84 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestJiraPoller_ImportsParentChain(t *testing.T) {
+	issues := map[string]map[string]any{
+		"PE-1": {"key": "PE-1", "fields": map[string]any{"summary": "Search epic", "issuetype": map[string]string{"name": "Epic"}}},
+		"PE-2": {"key": "PE-2", "fields": map[string]any{"summary": "Faster search", "parent": map[string]string{"key": "PE-1"}}},
+	}
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rest/api/3/issue/PE-1", "/rest/api/3/issue/PE-2":
+			_ = json.NewEncoder(w).Encode(issues[r.URL.Path[len("/rest/api/3/issue/"):]])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer jiraServer.Close()
+
+	daemon := newMockJiraDaemon()
+	poller := NewJiraPoller(newTestJiraClient(jiraServer.URL), daemon, JiraPollerConfig{Logger: slog.Default()})
+	subtask := JiraIssue{Key: "PE-3", Fields: JiraIssueFields{Summary: "Add index", Parent: &JiraParentRef{Key: "PE-2"}}}
+	if _, err := poller.Ingest(context.Background(), subtask); err != nil {
+		t.Fatalf("Ingest: %v", err)
+	}
+
+	byKey := make(map[string]*beadsapi.BeadDetail)
+	for _, b := range daemon.getBeads() {
+		byKey[b.Fields["jira_key"]] = b
+	}
+	if len(byKey) != 3 {
+		t.Fatalf("expected beads for PE-1..3, got %v", byKey)
+	}
+	if byKey["PE-3"].Fields["jira_parent"] != "PE-2" || byKey["PE-2"].Fields["jira_parent"] != "PE-1" {
+		t.Errorf("unexpected jira_parent fields")
+	}
+	want := []string{
+		byKey["PE-2"].ID + "→" + byKey["PE-1"].ID + ":parent-child",
+		byKey["PE-3"].ID + "→" + byKey["PE-2"].ID + ":parent-child",
+	}
+	if len(daemon.deps) != 2 || daemon.deps[0] != want[0] || daemon.deps[1] != want[1] {
+		t.Errorf("deps = %v, want %v", daemon.deps, want)
+	}
+	for _, key := range []string{"PE-1", "PE-2", "PE-3"} {
+		if !poller.IsTracked(key) {
+			t.Errorf("expected %s tracked", key)
+		}
+	}
+}
+
+func TestJiraSync_ClosingLastChildClosesParent(t *testing.T) {
+	srv, _ := jiraCommentServer(t)
+	daemon := newMockJiraSyncDaemon()
+	daemon.beads["bd-epic"] = &beadsapi.BeadDetail{ID: "bd-epic", Type: "task", Fields: map[string]string{"jira_key": "PE-1"}}
+	for _, id := range []string{"bd-1", "bd-2"} {
+		daemon.beads[id] = &beadsapi.BeadDetail{ID: id, Type: "task", Fields: map[string]string{"jira_key": "PE-" + id, "jira_parent": "PE-1"}}
+	}
+	s := NewJiraSync(JiraSyncConfig{Jira: newTestJiraClient(srv.URL), Daemon: daemon, DisableTransitions: true, Logger: slog.Default()})
+	ctx := context.Background()
+
+	closeChild := func(id string) {
+		daemon.statuses[id] = "closed"
+		s.handleClosed(ctx, marshalSSEBeadPayload(BeadEvent{ID: id, Type: "task", Fields: daemon.beads[id].Fields}))
+	}
+
+	closeChild("bd-1")
+	if daemon.statuses["bd-epic"] == "closed" {
+		t.Fatal("parent closed while a child is still open")
+	}
+	closeChild("bd-2")
+	if daemon.statuses["bd-epic"] != "closed" {
+		t.Error("expected parent closed after last child")
+	}
+}
@@ -1,14 +1,18 @@
 // Package bridge provides the JIRA polling loop.
 //
-// JiraPoller periodically queries JIRA for new issues matching configured
-// JQL criteria and creates task beads in the beads daemon. It deduplicates
+// JiraPoller periodically queries JIRA for new issues matching each configured
+// project's JQL (see jira_projects.go) and creates task beads in the beads
+// daemon. It deduplicates
 // by tracking JIRA key → bead ID mappings, and on startup runs a CatchUp
-// pass to populate the tracked map from existing beads.
+// pass to populate the tracked map from existing beads. When webhooks are
+// enabled (see JiraWebhook) the poller runs less often as a reconciliation
+// fallback; both paths create beads through Ingest.
 package bridge
 
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
@@ -22,6 +26,7 @@ import (
 type JiraBeadClient interface {
 	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
 	ListTaskBeads(ctx context.Context) ([]*beadsapi.BeadDetail, error)
+	AddDependency(ctx context.Context, beadID, dependsOnID, depType, createdBy string) error
 }
 
 // JiraPollerConfig holds configuration for the JIRA poller.
@@ -31,6 +36,8 @@ type JiraPollerConfig struct {
 	IssueTypes   []string          // JIRA issue types to ingest
 	PollInterval time.Duration     // Polling interval (default 60s)
 	ProjectMap   map[string]string // JIRA prefix (upper) → boat project name (e.g., "PE" → "monorepo")
+	ConfigClient JiraConfigClient  // nil = use only the flat filters above
+	ConfigKey    string            // daemon config key (default "jira-bridge:projects")
 	Logger       *slog.Logger
 }
 
@@ -40,8 +47,10 @@ type JiraPoller struct {
 	daemon JiraBeadClient
 	cfg    JiraPollerConfig
 
-	mu      sync.Mutex
-	tracked map[string]string // JIRA key → bead ID
+	ingestMu sync.Mutex // serializes check-and-create so webhook and poll don't race
+	mu       sync.Mutex
+	tracked  map[string]string // JIRA key → bead ID
+	projects []JiraProjectConfig
 }
 
 // NewJiraPoller creates a new JIRA polling loop.
@@ -49,12 +58,17 @@ func NewJiraPoller(jira *JiraClient, daemon JiraBeadClient, cfg JiraPollerConfig
 	if cfg.PollInterval <= 0 {
 		cfg.PollInterval = 60 * time.Second
 	}
-	return &JiraPoller{
+	if cfg.ConfigKey == "" {
+		cfg.ConfigKey = defaultJiraProjectsConfigKey
+	}
+	p := &JiraPoller{
 		jira:    jira,
 		daemon:  daemon,
 		cfg:     cfg,
 		tracked: make(map[string]string),
 	}
+	p.projects = p.envProjects()
+	return p
 }
 
 // Run starts the polling loop. It runs CatchUp once, then polls at the
@@ -62,11 +76,11 @@ func NewJiraPoller(jira *JiraClient, daemon JiraBeadClient, cfg JiraPollerConfig
 func (p *JiraPoller) Run(ctx context.Context) error {
 	// Populate tracked map from existing beads.
 	p.CatchUp(ctx)
+	p.reloadProjects(ctx)
 
 	p.cfg.Logger.Info("JIRA poller started",
-		"projects", p.cfg.Projects,
-		"statuses", p.cfg.Statuses,
-		"issue_types", p.cfg.IssueTypes,
+		"projects", len(p.currentProjects()),
+		"config_key", p.cfg.ConfigKey,
 		"interval", p.cfg.PollInterval)
 
 	ticker := time.NewTicker(p.cfg.PollInterval)
@@ -128,67 +142,140 @@ func (p *JiraPoller) poll(ctx context.Context) {
 		p.mu.Unlock()
 	}
 
-	jql := p.buildJQL()
-	fields := []string{"summary", "description", "status", "issuetype", "priority", "reporter", "labels", "parent", "created", "updated"}
+	p.reloadProjects(ctx)
 
-	issues, err := p.jira.SearchIssues(ctx, jql, fields, 50)
-	if err != nil {
-		p.cfg.Logger.Error("JIRA poll failed", "error", err)
+	// JIRA is rate limiting us; skip the cycle rather than add to the load.
+	if until := p.jira.PausedUntil(); time.Now().Before(until) {
+		p.cfg.Logger.Warn("JIRA poll skipped while rate limited", "until", until)
 		return
 	}
 
+	found := 0
 	created := 0
 	skipped := 0
-	for _, issue := range issues {
-		p.mu.Lock()
-		_, exists := p.tracked[issue.Key]
-		p.mu.Unlock()
-
-		if exists {
-			skipped++
+	for _, proj := range p.currentProjects() {
+		issues, err := p.jira.SearchIssues(ctx, proj.searchJQL(), proj.searchFields(), 50)
+		if err != nil {
+			p.cfg.Logger.Error("JIRA poll failed", "project", proj.Key, "error", err)
+			var rl *JiraRateLimitError
+			if errors.As(err, &rl) {
+				break
+			}
 			continue
 		}
+		found += len(issues)
+		for _, issue := range issues {
+			ok, err := p.Ingest(ctx, issue)
+			switch {
+			case err != nil:
+				continue
+			case ok:
+				created++
+			default:
+				skipped++
+			}
+		}
+	}
+
+	if created > 0 || p.cfg.Logger.Enabled(ctx, slog.LevelDebug) {
+		p.cfg.Logger.Info("JIRA poll complete",
+			"found", found, "created", created, "skipped", skipped)
+	}
+}
+
+// Ingest creates a task bead for the issue unless one is already tracked.
+// It reports whether a bead was created.
+func (p *JiraPoller) Ingest(ctx context.Context, issue JiraIssue) (bool, error) {
+	p.ingestMu.Lock()
+	defer p.ingestMu.Unlock()
+
+	if p.IsTracked(issue.Key) {
+		return false, nil
+	}
+
+	beadID, err := p.createBeadFromIssue(ctx, issue, 0)
+	if err != nil {
+		p.cfg.Logger.Error("failed to create bead for JIRA issue",
+			"key", issue.Key, "error", err)
+		return false, err
+	}
 
-		beadID, err := p.createBeadFromIssue(ctx, issue)
+	p.mu.Lock()
+	p.tracked[issue.Key] = beadID
+	p.mu.Unlock()
+
+	p.cfg.Logger.Info("created bead for JIRA issue",
+		"key", issue.Key, "bead_id", beadID,
+		"summary", issue.Fields.Summary)
+	return true, nil
+}
+
+// Matches reports whether an issue satisfies its project's filter — the same
+// criteria polls apply. Status and issue type filters are checked locally;
+// projects with custom JQL are checked by asking JIRA whether the issue
+// matches it.
+func (p *JiraPoller) Matches(ctx context.Context, issue JiraIssue) bool {
+	proj, ok := p.projectFor(issue.Key)
+	if !ok {
+		return false
+	}
+	if proj.JQL != "" {
+		jql := `key = "` + issue.Key + `" AND (` + proj.JQL + `)`
+		issues, err := p.jira.SearchIssues(ctx, jql, []string{"summary"}, 1)
 		if err != nil {
-			p.cfg.Logger.Error("failed to create bead for JIRA issue",
+			p.cfg.Logger.Warn("failed to check JIRA issue against project JQL",
 				"key", issue.Key, "error", err)
-			continue
+			return false
+		}
+		return len(issues) > 0
+	}
+	if len(proj.Statuses) > 0 {
+		if issue.Fields.Status == nil || !containsFold(proj.Statuses, issue.Fields.Status.Name) {
+			return false
+		}
+	}
+	if len(proj.IssueTypes) > 0 {
+		if issue.Fields.IssueType == nil || !containsFold(proj.IssueTypes, issue.Fields.IssueType.Name) {
+			return false
 		}
-
-		p.mu.Lock()
-		p.tracked[issue.Key] = beadID
-		p.mu.Unlock()
-		created++
-
-		p.cfg.Logger.Info("created bead for JIRA issue",
-			"key", issue.Key, "bead_id", beadID,
-			"summary", issue.Fields.Summary)
 	}
+	return true
+}
 
-	if created > 0 || p.cfg.Logger.Enabled(ctx, slog.LevelDebug) {
-		p.cfg.Logger.Info("JIRA poll complete",
-			"found", len(issues), "created", created, "skipped", skipped)
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
 	}
+	return false
 }
 
-// createBeadFromIssue creates a task bead from a JIRA issue.
-func (p *JiraPoller) createBeadFromIssue(ctx context.Context, issue JiraIssue) (string, error) {
+// createBeadFromIssue creates a task bead from a JIRA issue, importing its
+// parent first (see ensureParentLocked). depth counts the parent levels
+// already imported above the issue. Caller must hold p.ingestMu.
+func (p *JiraPoller) createBeadFromIssue(ctx context.Context, issue JiraIssue, depth int) (string, error) {
 	// Build labels.
 	labels := []string{
 		"source:jira",
 		"jira:" + issue.Key,
 	}
 
+	proj, _ := p.projectFor(issue.Key)
+	mapping := proj.Fields
+
 	// Extract project key from issue key (e.g., "PE" from "PE-7001") and map
-	// to the boat project name via ProjectMap. Falls back to the lowercased
-	// JIRA prefix when no mapping is configured.
+	// to the boat project name via the project config or ProjectMap. Falls
+	// back to the lowercased JIRA prefix when no mapping is configured.
 	project := ""
 	if parts := strings.SplitN(issue.Key, "-", 2); len(parts) == 2 {
 		jiraPrefix := strings.ToUpper(parts[0])
-		boatProject, ok := p.cfg.ProjectMap[jiraPrefix]
-		if !ok {
-			boatProject = strings.ToLower(jiraPrefix)
+		boatProject := proj.BoatProject
+		if boatProject == "" {
+			var ok bool
+			if boatProject, ok = p.cfg.ProjectMap[jiraPrefix]; !ok {
+				boatProject = strings.ToLower(jiraPrefix)
+			}
 		}
 		project = jiraPrefix
 		labels = append(labels, "project:"+boatProject)
@@ -198,6 +285,7 @@ func (p *JiraPoller) createBeadFromIssue(ctx context.Context, issue JiraIssue) (
 	for _, l := range issue.Fields.Labels {
 		labels = append(labels, "jira-label:"+l)
 	}
+	labels = append(labels, mapping.componentLabels(issue)...)
 
 	// Build fields.
 	fields := map[string]string{
@@ -211,12 +299,19 @@ func (p *JiraPoller) createBeadFromIssue(ctx context.Context, issue JiraIssue) (
 	if issue.Fields.Status != nil {
 		fields["jira_status"] = issue.Fields.Status.Name
 	}
-	if issue.Fields.Parent != nil {
-		fields["jira_epic"] = issue.Fields.Parent.Key
+	epic := mapping.epicKey(issue)
+	parentBead := ""
+	if epic != "" {
+		fields["jira_epic"] = epic
+		fields["jira_parent"] = epic
+		parentBead = p.ensureParentLocked(ctx, epic, depth+1)
 	}
 	if issue.Fields.Reporter != nil {
 		fields["jira_reporter"] = issue.Fields.Reporter.DisplayName
 	}
+	if attachments := p.attachmentList(ctx, issue); attachments != "" {
+		fields[jiraAttachmentsField] = attachments
+	}
 
 	fieldsJSON, err := json.Marshal(fields)
 	if err != nil {
@@ -228,6 +323,9 @@ func (p *JiraPoller) createBeadFromIssue(ctx context.Context, issue JiraIssue) (
 	if issue.Fields.Priority != nil {
 		priority = MapJiraPriority(issue.Fields.Priority.Name)
 	}
+	if pri, ok := mapping.storyPointsPriority(issue); ok {
+		priority = pri
+	}
 
 	// Convert description from ADF to markdown.
 	description := adfToMarkdown(issue.Fields.Description)
@@ -247,26 +345,15 @@ func (p *JiraPoller) createBeadFromIssue(ctx context.Context, issue JiraIssue) (
 		return "", fmt.Errorf("create bead: %w", err)
 	}
 
-	return beadID, nil
-}
-
-// buildJQL constructs the JQL query for polling.
-func (p *JiraPoller) buildJQL() string {
-	var parts []string
-
-	if len(p.cfg.Projects) > 0 {
-		parts = append(parts, "project IN ("+quoteJQL(p.cfg.Projects)+")")
-	}
-	if len(p.cfg.Statuses) > 0 {
-		parts = append(parts, "status IN ("+quoteJQL(p.cfg.Statuses)+")")
-	}
-	if len(p.cfg.IssueTypes) > 0 {
-		parts = append(parts, "issuetype IN ("+quoteJQL(p.cfg.IssueTypes)+")")
+	// Link to the parent's bead (best-effort).
+	if parentBead != "" {
+		if err := p.daemon.AddDependency(ctx, beadID, parentBead, "parent-child", "jira-bridge"); err != nil {
+			p.cfg.Logger.Warn("failed to link bead to JIRA parent bead",
+				"key", issue.Key, "parent", epic, "error", err)
+		}
 	}
 
-	jql := strings.Join(parts, " AND ")
-	jql += " ORDER BY created DESC"
-	return jql
+	return beadID, nil
 }
 
 // quoteJQL wraps each value in double quotes for JQL IN clauses.
@@ -286,6 +373,14 @@ func (p *JiraPoller) IsTracked(key string) bool {
 	return ok
 }
 
+// TrackedBead returns the bead ID tracked for a JIRA key.
+func (p *JiraPoller) TrackedBead(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id, ok := p.tracked[key]
+	return id, ok
+}
+
 // TrackedCount returns the number of tracked JIRA issues.
 func (p *JiraPoller) TrackedCount() int {
 	p.mu.Lock()
@@ -0,0 +1,275 @@
+// Package bridge provides per-project JIRA ingestion configuration.
+//
+// Each JIRA project is polled with its own JQL and may map custom fields onto
+// the task bead: story points to bead priority, components to labels, and the
+// epic link to a parent bead. The project list is read from a daemon config
+// entry (default key "jira-bridge:projects") on every poll, so changes apply
+// without a redeploy. Without that entry the poller falls back to the flat
+// JIRA_PROJECTS / JIRA_STATUSES / JIRA_ISSUE_TYPES environment filters.
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// defaultJiraProjectsConfigKey is the daemon config key for project settings.
+const defaultJiraProjectsConfigKey = "jira-bridge:projects"
+
+// JiraConfigClient reads the project configuration from the beads daemon.
+// *beadsapi.Client satisfies it.
+type JiraConfigClient interface {
+	GetConfig(ctx context.Context, key string) (*beadsapi.ConfigEntry, error)
+}
+
+// JiraProjectsConfig is the value of the jira-bridge:projects config entry.
+//
+// Example:
+//
+//	{"projects": [{
+//	  "key": "PE",
+//	  "boat_project": "monorepo",
+//	  "jql": "status = \"To Do\" AND labels = agent-ready",
+//	  "fields": {
+//	    "story_points": "customfield_10016",
+//	    "components": true,
+//	    "epic_link": "customfield_10014"
+//	  }
+//	}]}
+type JiraProjectsConfig struct {
+	Projects []JiraProjectConfig `json:"projects"`
+}
+
+// JiraProjectConfig configures ingestion for one JIRA project.
+type JiraProjectConfig struct {
+	Key         string           `json:"key"`                    // JIRA project key; "" = any project
+	BoatProject string           `json:"boat_project,omitempty"` // default: ProjectMap entry, then lowercased key
+	JQL         string           `json:"jql,omitempty"`          // replaces the status/issue type filter
+	Statuses    []string         `json:"statuses,omitempty"`
+	IssueTypes  []string         `json:"issue_types,omitempty"`
+	Fields      JiraFieldMapping `json:"fields,omitempty"`
+}
+
+// JiraFieldMapping maps JIRA fields onto task beads.
+type JiraFieldMapping struct {
+	StoryPoints    string               `json:"story_points,omitempty"` // custom field ID holding story points
+	PointsPriority []JiraPointsPriority `json:"points_priority,omitempty"`
+	Components     bool                 `json:"components,omitempty"` // add component:<name> labels
+	EpicLink       string               `json:"epic_link,omitempty"`  // custom field ID holding the epic key
+}
+
+// JiraPointsPriority assigns Priority to issues with at least Min points.
+type JiraPointsPriority struct {
+	Min      float64 `json:"min"`
+	Priority int     `json:"priority"`
+}
+
+// defaultPointsPriority is used when story points are mapped without
+// explicit thresholds: bigger issues get higher priority.
+var defaultPointsPriority = []JiraPointsPriority{{Min: 8, Priority: 1}, {Min: 3, Priority: 2}, {Min: 0, Priority: 3}}
+
+// ParseJiraProjectsConfig parses and validates a project configuration.
+func ParseJiraProjectsConfig(data []byte) (JiraProjectsConfig, error) {
+	var cfg JiraProjectsConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return JiraProjectsConfig{}, fmt.Errorf("parse JIRA projects config: %w", err)
+	}
+	seen := make(map[string]bool)
+	for i := range cfg.Projects {
+		p := &cfg.Projects[i]
+		p.Key = strings.ToUpper(strings.TrimSpace(p.Key))
+		if seen[p.Key] {
+			return JiraProjectsConfig{}, fmt.Errorf("parse JIRA projects config: duplicate project %q", p.Key)
+		}
+		seen[p.Key] = true
+		for _, pp := range p.Fields.PointsPriority {
+			if pp.Priority < 0 || pp.Priority > 4 {
+				return JiraProjectsConfig{}, fmt.Errorf("parse JIRA projects config: %s: priority %d out of range 0-4", p.Key, pp.Priority)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// envProjects builds the project list from the flat environment filters.
+func (p *JiraPoller) envProjects() []JiraProjectConfig {
+	if len(p.cfg.Projects) == 0 {
+		return []JiraProjectConfig{{Statuses: p.cfg.Statuses, IssueTypes: p.cfg.IssueTypes}}
+	}
+	projects := make([]JiraProjectConfig, len(p.cfg.Projects))
+	for i, key := range p.cfg.Projects {
+		projects[i] = JiraProjectConfig{Key: strings.ToUpper(key), Statuses: p.cfg.Statuses, IssueTypes: p.cfg.IssueTypes}
+	}
+	return projects
+}
+
+// reloadProjects re-reads the project configuration from the daemon. A
+// missing entry reverts to the environment filters; on any other error the
+// previous configuration is kept.
+func (p *JiraPoller) reloadProjects(ctx context.Context) {
+	if p.cfg.ConfigClient == nil {
+		return
+	}
+	projects := p.envProjects()
+	entry, err := p.cfg.ConfigClient.GetConfig(ctx, p.cfg.ConfigKey)
+	if err != nil {
+		var apiErr *beadsapi.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+			p.cfg.Logger.Warn("failed to read JIRA projects config, keeping previous",
+				"key", p.cfg.ConfigKey, "error", err)
+			return
+		}
+	} else {
+		cfg, err := ParseJiraProjectsConfig(entry.Value)
+		if err != nil {
+			p.cfg.Logger.Warn("invalid JIRA projects config, keeping previous",
+				"key", p.cfg.ConfigKey, "error", err)
+			return
+		}
+		projects = cfg.Projects
+	}
+	p.mu.Lock()
+	p.projects = projects
+	p.mu.Unlock()
+}
+
+// currentProjects returns the active project configuration.
+func (p *JiraPoller) currentProjects() []JiraProjectConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.projects
+}
+
+// projectFor returns the configuration that applies to an issue key.
+func (p *JiraPoller) projectFor(issueKey string) (JiraProjectConfig, bool) {
+	prefix, _, _ := strings.Cut(issueKey, "-")
+	var wildcard *JiraProjectConfig
+	projects := p.currentProjects()
+	for i, proj := range projects {
+		if proj.Key == "" {
+			wildcard = &projects[i]
+		} else if strings.EqualFold(proj.Key, prefix) {
+			return proj, true
+		}
+	}
+	if wildcard != nil {
+		return *wildcard, true
+	}
+	return JiraProjectConfig{}, false
+}
+
+// filterJQL returns the project's filter without the project clause.
+func (proj JiraProjectConfig) filterJQL() string {
+	if proj.JQL != "" {
+		return proj.JQL
+	}
+	var parts []string
+	if len(proj.Statuses) > 0 {
+		parts = append(parts, "status IN ("+quoteJQL(proj.Statuses)+")")
+	}
+	if len(proj.IssueTypes) > 0 {
+		parts = append(parts, "issuetype IN ("+quoteJQL(proj.IssueTypes)+")")
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// searchJQL constructs the poll query for a project. Extra clauses (e.g. a
+// backfill's created bound) are ANDed onto the filter.
+func (proj JiraProjectConfig) searchJQL(extra ...string) string {
+	var parts []string
+	if proj.Key != "" {
+		parts = append(parts, `project = "`+proj.Key+`"`)
+	}
+	if filter := proj.filterJQL(); filter != "" {
+		parts = append(parts, "("+filter+")")
+	}
+	parts = append(parts, extra...)
+	return strings.Join(parts, " AND ") + " ORDER BY created DESC"
+}
+
+// searchFields returns the issue fields to fetch for a project.
+func (proj JiraProjectConfig) searchFields() []string {
+	fields := []string{"summary", "description", "status", "issuetype", "priority", "reporter", "labels", "parent", "created", "updated", "attachment"}
+	if proj.Fields.Components {
+		fields = append(fields, "components")
+	}
+	for _, custom := range []string{proj.Fields.StoryPoints, proj.Fields.EpicLink} {
+		if custom != "" {
+			fields = append(fields, custom)
+		}
+	}
+	return fields
+}
+
+// storyPointsPriority maps an issue's story points to a bead priority.
+func (m JiraFieldMapping) storyPointsPriority(issue JiraIssue) (int, bool) {
+	if m.StoryPoints == "" {
+		return 0, false
+	}
+	raw, ok := issue.Fields.Custom[m.StoryPoints]
+	if !ok {
+		return 0, false
+	}
+	var points float64
+	if err := json.Unmarshal(raw, &points); err != nil {
+		// Some JIRA instances return numeric custom fields as strings.
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return 0, false
+		}
+		if points, err = strconv.ParseFloat(s, 64); err != nil {
+			return 0, false
+		}
+	}
+	thresholds := m.PointsPriority
+	if len(thresholds) == 0 {
+		thresholds = defaultPointsPriority
+	}
+	thresholds = append([]JiraPointsPriority(nil), thresholds...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i].Min > thresholds[j].Min })
+	for _, t := range thresholds {
+		if points >= t.Min {
+			return t.Priority, true
+		}
+	}
+	return 0, false
+}
+
+// epicKey returns the issue's epic: the configured epic link custom field
+// (company-managed projects) or the parent issue.
+func (m JiraFieldMapping) epicKey(issue JiraIssue) string {
+	if m.EpicLink != "" {
+		var key string
+		if err := json.Unmarshal(issue.Fields.Custom[m.EpicLink], &key); err == nil && key != "" {
+			return key
+		}
+	}
+	if issue.Fields.Parent != nil {
+		return issue.Fields.Parent.Key
+	}
+	return ""
+}
+
+// componentLabels returns component:<name> labels for the issue.
+func (m JiraFieldMapping) componentLabels(issue JiraIssue) []string {
+	if !m.Components {
+		return nil
+	}
+	var labels []string
+	for _, c := range issue.Fields.Components {
+		if c.Name != "" {
+			labels = append(labels, "component:"+c.Name)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,152 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestParseJiraProjectsConfig(t *testing.T) {
+	cfg, err := ParseJiraProjectsConfig([]byte(`{"projects":[{"key":" pe ","jql":"labels = agent"}]}`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(cfg.Projects) != 1 || cfg.Projects[0].Key != "PE" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	for name, data := range map[string]string{
+		"unknown field": `{"projects":[{"key":"PE","jqll":"x"}]}`,
+		"duplicate":     `{"projects":[{"key":"PE"},{"key":"pe"}]}`,
+		"bad priority":  `{"projects":[{"key":"PE","fields":{"points_priority":[{"min":1,"priority":9}]}}]}`,
+	} {
+		if _, err := ParseJiraProjectsConfig([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestJiraProjectConfig_SearchJQL(t *testing.T) {
+	tests := []struct {
+		proj JiraProjectConfig
+		want string
+	}{
+		{JiraProjectConfig{Key: "PE", JQL: "labels = agent"}, `project = "PE" AND (labels = agent) ORDER BY created DESC`},
+		{JiraProjectConfig{Key: "PE", Statuses: []string{"To Do"}, IssueTypes: []string{"Bug"}},
+			`project = "PE" AND (status IN ("To Do") AND issuetype IN ("Bug")) ORDER BY created DESC`},
+		{JiraProjectConfig{}, ` ORDER BY created DESC`},
+	}
+	for _, tt := range tests {
+		if got := tt.proj.searchJQL(); got != tt.want {
+			t.Errorf("searchJQL(%+v) = %q, want %q", tt.proj, got, tt.want)
+		}
+	}
+}
+
+func TestJiraPoller_ProjectConfigFromDaemon(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		jqls []string
+	)
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		jqls = append(jqls, r.URL.Query().Get("jql"))
+		mu.Unlock()
+		resp := map[string]any{"issues": []map[string]any{{
+			"key": "PE-7001",
+			"fields": map[string]any{
+				"summary":           "Slow search",
+				"priority":          map[string]string{"name": "Low"},
+				"components":        []map[string]string{{"name": "API"}},
+				"customfield_10016": 13,
+				"customfield_10014": "PE-1",
+			},
+		}}}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer jiraServer.Close()
+
+	store := newFakeLeaseStore()
+	store.configs[defaultJiraProjectsConfigKey] = []byte(`{"projects":[{
+		"key": "PE", "boat_project": "monorepo", "jql": "labels = agent-ready",
+		"fields": {"story_points": "customfield_10016", "components": true, "epic_link": "customfield_10014"}}]}`)
+
+	daemon := newMockJiraDaemon()
+	poller := NewJiraPoller(newTestJiraClient(jiraServer.URL), daemon, JiraPollerConfig{
+		Projects:     []string{"PE", "DEVOPS"}, // replaced by the config entry
+		ConfigClient: store,
+		Logger:       slog.Default(),
+	})
+	poller.tracked["PE-1"] = "bd-epic"
+	poller.poll(context.Background())
+
+	if want := `project = "PE" AND (labels = agent-ready) ORDER BY created DESC`; len(jqls) != 1 || jqls[0] != want {
+		t.Errorf("JQL = %q, want [%q]", jqls, want)
+	}
+	var bead *beadsapi.BeadDetail
+	for _, b := range daemon.getBeads() {
+		bead = b
+	}
+	if bead == nil {
+		t.Fatal("expected a bead")
+	}
+	if bead.Fields["_priority"] != "1" {
+		t.Errorf("priority = %s, want 1 from 13 story points", bead.Fields["_priority"])
+	}
+	if bead.Fields["jira_epic"] != "PE-1" {
+		t.Errorf("jira_epic = %s", bead.Fields["jira_epic"])
+	}
+	for _, l := range []string{"component:API", "project:monorepo"} {
+		if !slices.Contains(bead.Labels, l) {
+			t.Errorf("missing label %s in %v", l, bead.Labels)
+		}
+	}
+	if want := bead.ID + "→bd-epic:parent-child"; len(daemon.deps) != 1 || daemon.deps[0] != want {
+		t.Errorf("deps = %v, want [%s]", daemon.deps, want)
+	}
+
+	// Deleting the config entry reverts to the environment filters.
+	delete(store.configs, defaultJiraProjectsConfigKey)
+	poller.reloadProjects(context.Background())
+	if got := poller.currentProjects(); len(got) != 2 || got[1].Key != "DEVOPS" {
+		t.Errorf("expected env projects after delete, got %+v", got)
+	}
+}
+
+func TestJiraPoller_MatchesCustomJQLAsksJira(t *testing.T) {
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issues := []map[string]string{}
+		if r.URL.Query().Get("jql") == `key = "PE-1" AND (labels = agent-ready)` {
+			issues = append(issues, map[string]string{"key": "PE-1"})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"issues": issues})
+	}))
+	defer jiraServer.Close()
+
+	store := newFakeLeaseStore()
+	store.configs[defaultJiraProjectsConfigKey] = []byte(`{"projects":[{"key":"PE","jql":"labels = agent-ready"}]}`)
+	poller := NewJiraPoller(newTestJiraClient(jiraServer.URL), newMockJiraDaemon(), JiraPollerConfig{
+		ConfigClient: store, Logger: slog.Default(),
+	})
+	ctx := context.Background()
+	poller.reloadProjects(ctx)
+
+	if !poller.Matches(ctx, JiraIssue{Key: "PE-1"}) {
+		t.Error("expected PE-1 to match")
+	}
+	if poller.Matches(ctx, JiraIssue{Key: "PE-2"}) {
+		t.Error("expected PE-2 not to match")
+	}
+	if poller.Matches(ctx, JiraIssue{Key: "OPS-1"}) {
+		t.Error("expected unconfigured project not to match")
+	}
+}
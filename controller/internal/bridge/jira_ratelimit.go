@@ -0,0 +1,220 @@
+// Package bridge provides rate limiting and backoff for the JIRA client.
+//
+// Every JiraClient request passes through send, which caps concurrency,
+// waits on a token bucket, and retries 429 and 5xx responses — honoring
+// Retry-After when JIRA sends it and backing off exponentially otherwise.
+// When JIRA keeps rate limiting (or asks for a long wait), the client opens a
+// circuit: requests fail fast with *JiraRateLimitError until the cooldown
+// ends, and the poller skips its cycles instead of hammering the API.
+// Request counts, latency, retries, and the circuit state are exported in
+// Prometheus text format via WriteMetrics.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultJiraRequestsPerSecond = 5
+	defaultJiraBurst             = 10
+	defaultJiraMaxConcurrent     = 4
+	defaultJiraMaxRetries        = 3
+
+	// jiraRetryBase is the first 5xx/429 retry delay without Retry-After;
+	// it doubles per attempt.
+	jiraRetryBase = time.Second
+	// jiraMaxRetryWait is the longest Retry-After honored inline. Longer
+	// waits open the circuit instead of blocking the caller.
+	jiraMaxRetryWait = 30 * time.Second
+	// jiraCircuitCooldown is how long the circuit stays open when JIRA gave
+	// no Retry-After.
+	jiraCircuitCooldown = time.Minute
+)
+
+var jiraLatencyBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// JiraRateLimitError is returned while the client's circuit is open.
+type JiraRateLimitError struct {
+	Until time.Time
+}
+
+func (e *JiraRateLimitError) Error() string {
+	return fmt.Sprintf("JIRA rate limited until %s", e.Until.Format(time.RFC3339))
+}
+
+// jiraClientMetrics holds the JIRA client's metric families.
+type jiraClientMetrics struct {
+	requests    *counterVec
+	latency     *histogramVec
+	retries     *counterVec
+	rateLimited *counterVec
+}
+
+func newJiraClientMetrics() *jiraClientMetrics {
+	return &jiraClientMetrics{
+		requests: &counterVec{name: "jira_bridge_jira_requests_total",
+			help: "JIRA API requests by operation and HTTP status.", labels: []string{"op", "code"},
+			values: make(map[string]*counterSeries)},
+		latency: &histogramVec{name: "jira_bridge_jira_request_duration_seconds",
+			help: "JIRA API request latency by operation.", labels: []string{"op"}, buckets: jiraLatencyBuckets,
+			series: make(map[string]*histogramSeries)},
+		retries: &counterVec{name: "jira_bridge_jira_retries_total",
+			help: "JIRA API requests retried after a 429 or 5xx, by operation.", labels: []string{"op"},
+			values: make(map[string]*counterSeries)},
+		rateLimited: &counterVec{name: "jira_bridge_jira_rate_limited_total",
+			help: "JIRA API responses with HTTP 429, by operation.", labels: []string{"op"},
+			values: make(map[string]*counterSeries)},
+	}
+}
+
+// WriteMetrics writes the client's metrics in Prometheus text format.
+func (c *JiraClient) WriteMetrics(w io.Writer) {
+	c.metrics.requests.write(w)
+	c.metrics.latency.write(w)
+	c.metrics.retries.write(w)
+	c.metrics.rateLimited.write(w)
+	open := 0
+	if time.Now().Before(c.PausedUntil()) {
+		open = 1
+	}
+	fmt.Fprint(w, "# HELP jira_bridge_jira_circuit_open Whether JIRA requests are paused after rate limiting.\n")
+	fmt.Fprint(w, "# TYPE jira_bridge_jira_circuit_open gauge\n")
+	fmt.Fprintf(w, "jira_bridge_jira_circuit_open %d\n", open)
+}
+
+// PausedUntil returns when the circuit closes; the zero time (or a past
+// time) means requests are allowed.
+func (c *JiraClient) PausedUntil() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pausedUntil
+}
+
+// pause opens the circuit for d.
+func (c *JiraClient) pause(d time.Duration) time.Time {
+	until := time.Now().Add(d)
+	c.mu.Lock()
+	if until.After(c.pausedUntil) {
+		c.pausedUntil = until
+	}
+	until = c.pausedUntil
+	c.mu.Unlock()
+	c.logger.Warn("JIRA rate limited, pausing requests", "until", until)
+	return until
+}
+
+// send performs a JIRA request built by newReq, applying the concurrency
+// cap, token bucket, retries, and circuit breaker. newReq is called once per
+// attempt so request bodies can be replayed. The caller closes the body of
+// the returned response.
+func (c *JiraClient) send(ctx context.Context, client *http.Client, newReq func() (*http.Request, error)) (*http.Response, error) {
+	if resp, ok := c.dryRunWrite(newReq); ok {
+		return resp, nil
+	}
+	for attempt := 0; ; attempt++ {
+		if until := c.PausedUntil(); time.Now().Before(until) {
+			return nil, &JiraRateLimitError{Until: until}
+		}
+		req, err := newReq()
+		if err != nil {
+			return nil, fmt.Errorf("create JIRA request: %w", err)
+		}
+		op := jiraOp(req.URL.Path)
+
+		resp, err := c.roundTrip(ctx, client, req, op)
+		if err != nil {
+			return nil, fmt.Errorf("JIRA request failed: %w", err)
+		}
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable {
+			return resp, nil
+		}
+
+		wait, hasRetryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !hasRetryAfter {
+			wait = c.retryBase << attempt
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			c.metrics.rateLimited.add(1, op)
+			if attempt >= c.maxRetries || wait > jiraMaxRetryWait {
+				resp.Body.Close()
+				cooldown := jiraCircuitCooldown
+				if hasRetryAfter {
+					cooldown = wait
+				}
+				return nil, &JiraRateLimitError{Until: c.pause(cooldown)}
+			}
+		} else if attempt >= c.maxRetries {
+			return resp, nil // let the caller report the 5xx
+		}
+		resp.Body.Close()
+
+		c.metrics.retries.add(1, op)
+		c.logger.Debug("retrying JIRA request", "op", op, "status", resp.StatusCode, "attempt", attempt+1, "wait", wait)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// roundTrip performs one request under the concurrency cap and rate limit.
+func (c *JiraClient) roundTrip(ctx context.Context, client *http.Client, req *http.Request, op string) (*http.Response, error) {
+	select {
+	case c.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-c.sem }()
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	c.metrics.latency.observe(time.Since(start).Seconds(), op)
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	c.metrics.requests.add(1, op, code)
+	return resp, err
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date.
+func parseRetryAfter(h string, now time.Time) (time.Duration, bool) {
+	if h == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(h); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// jiraOp names a request for metrics by its API path without issue keys and
+// IDs, e.g. "/rest/api/3/issue/PE-1/comment" → "issue/comment".
+func jiraOp(path string) string {
+	path = strings.TrimPrefix(path, "/rest/api/3/")
+	var parts []string
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" && !strings.ContainsAny(seg, "0123456789") {
+			parts = append(parts, seg)
+		}
+	}
+	if len(parts) == 0 {
+		return "unknown"
+	}
+	return strings.Join(parts, "/")
+}
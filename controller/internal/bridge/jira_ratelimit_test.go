@@ -0,0 +1,158 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJiraClient_RetriesRateLimitWithRetryAfter(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"key":"PE-1"}`))
+	}))
+	defer srv.Close()
+
+	c := newTestJiraClient(srv.URL)
+	issue, err := c.GetIssue(context.Background(), "PE-1")
+	if err != nil || issue.Key != "PE-1" {
+		t.Fatalf("GetIssue = %+v, %v", issue, err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+
+	var b strings.Builder
+	c.WriteMetrics(&b)
+	for _, want := range []string{
+		`jira_bridge_jira_requests_total{op="issue",code="429"} 1`,
+		`jira_bridge_jira_requests_total{op="issue",code="200"} 1`,
+		`jira_bridge_jira_retries_total{op="issue"} 1`,
+		`jira_bridge_jira_circuit_open 0`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics missing %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestJiraClient_LongRetryAfterPausesPoller(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c := newTestJiraClient(srv.URL)
+	_, err := c.GetIssue(context.Background(), "PE-1")
+	var rl *JiraRateLimitError
+	if !errors.As(err, &rl) || time.Until(rl.Until) < 100*time.Second {
+		t.Fatalf("expected rate limit error ~120s out, got %v", err)
+	}
+
+	// The open circuit fails fast and the poller skips its cycle.
+	poller := NewJiraPoller(c, newMockJiraDaemon(), JiraPollerConfig{Projects: []string{"PE"}, Logger: slog.Default()})
+	poller.poll(context.Background())
+	if _, err := c.GetIssue(context.Background(), "PE-2"); !errors.As(err, &rl) {
+		t.Errorf("expected fast failure, got %v", err)
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("expected JIRA hit once, got %d", n)
+	}
+}
+
+func TestJiraClient_RetriesServerErrors(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := newTestJiraClient(srv.URL)
+	c.retryBase = time.Millisecond
+	if err := c.AddComment(context.Background(), "PE-1", "hi"); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected 502 error, got %v", err)
+	}
+	if n := hits.Load(); n != int32(defaultJiraMaxRetries+1) {
+		t.Errorf("expected %d attempts, got %d", defaultJiraMaxRetries+1, n)
+	}
+}
+
+func TestJiraClient_CapsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		inFlight.Add(-1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewJiraClient(JiraClientConfig{BaseURL: srv.URL, MaxConcurrent: 2, RequestsPerSecond: 1000, Burst: 100, Logger: slog.Default()})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = c.AddComment(context.Background(), "PE-1", "hi")
+		}()
+	}
+	wg.Wait()
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency %d exceeds cap 2", p)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{now.Add(90 * time.Second).Format(http.TimeFormat), 90 * time.Second, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestJiraOp(t *testing.T) {
+	for path, want := range map[string]string{
+		"/rest/api/3/issue/PE-1/comment":      "issue/comment",
+		"/rest/api/3/search/jql":              "search/jql",
+		"/rest/api/3/attachment/content/1001": "attachment/content",
+	} {
+		if got := jiraOp(path); got != want {
+			t.Errorf("jiraOp(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
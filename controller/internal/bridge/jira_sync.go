@@ -2,44 +2,88 @@
 //
 // JiraSync subscribes to kbeads SSE bead updated/closed events, filters for
 // beads with the source:jira label, and syncs status changes, MR links, and
-// closing comments back to the originating JIRA issue.
+// closing comments back to the originating JIRA issue. Closed report beads
+// can be uploaded as attachments (see jira_artifacts.go), closing the last
+// child of a JIRA parent closes the parent (see jira_hierarchy.go), and bead
+// claims assign the issue to the mapped account (see jira_assignment.go). Status changes follow
+// the configured JiraWorkflow in both directions: bead status → JIRA
+// transition here, and JIRA status → bead status via ApplyJiraStatus (called
+// by the webhook receiver).
 package bridge
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"strings"
 	"sync"
 	"time"
+
+	"gasboat/controller/internal/beadsapi"
 )
 
 // syncTTL is the dedup window for sync-back operations.
 const syncTTL = 10 * time.Minute
 
+// JiraSyncClient is the subset of beadsapi.Client used by JiraSync to write
+// JIRA status and comments back to beads.
+type JiraSyncClient interface {
+	UpdateBead(ctx context.Context, beadID string, req beadsapi.UpdateBeadRequest) error
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+	CloseBead(ctx context.Context, beadID string, fields map[string]string) error
+	AddComment(ctx context.Context, beadID, author, text string) error
+	ListAssignedTask(ctx context.Context, agentName string) (*beadsapi.BeadDetail, error)
+	GetBead(ctx context.Context, beadID string) (*beadsapi.BeadDetail, error)
+	ListTaskBeads(ctx context.Context) ([]*beadsapi.BeadDetail, error)
+}
+
 // JiraSync watches bead SSE events and syncs MR links and status back to JIRA.
 type JiraSync struct {
 	jira               *JiraClient
+	daemon             JiraSyncClient
+	workflow           JiraWorkflow
+	assignment         JiraAssignment
+	commentProjects    []string // JIRA project keys with comment sync ("*" = all)
 	logger             *slog.Logger
 	disableTransitions bool
+	uploadReports      bool
 
-	mu   sync.Mutex
-	seen map[string]time.Time // dedup key → last sync time
+	mu       sync.Mutex
+	seen     map[string]time.Time // dedup key → last sync time
+	synced   map[string]string    // bead ID → bead status JIRA already reflects
+	assigned map[string]string    // bead ID → assignee JIRA already reflects
 }
 
 // JiraSyncConfig holds configuration for the JiraSync watcher.
 type JiraSyncConfig struct {
 	Jira               *JiraClient
+	Daemon             JiraSyncClient // nil = no writes back to beads (status, comments)
+	Workflow           *JiraWorkflow  // nil = DefaultJiraWorkflow()
+	Assignment         JiraAssignment // zero = no assignment sync
+	CommentProjects    []string       // JIRA project keys with comment sync enabled; "*" = all
 	Logger             *slog.Logger
 	DisableTransitions bool
+	UploadReports      bool // attach closed report beads to the agent's JIRA task (requires Daemon)
 }
 
 // NewJiraSync creates a new JIRA sync-back watcher.
 func NewJiraSync(cfg JiraSyncConfig) *JiraSync {
+	wf := DefaultJiraWorkflow()
+	if cfg.Workflow != nil {
+		wf = *cfg.Workflow
+	}
 	return &JiraSync{
 		jira:               cfg.Jira,
+		daemon:             cfg.Daemon,
+		workflow:           wf,
+		assignment:         cfg.Assignment,
+		commentProjects:    cfg.CommentProjects,
 		logger:             cfg.Logger,
 		disableTransitions: cfg.DisableTransitions,
+		uploadReports:      cfg.UploadReports,
 		seen:               make(map[string]time.Time),
+		synced:             make(map[string]string),
+		assigned:           make(map[string]string),
 	}
 }
 
@@ -64,6 +108,18 @@ func (s *JiraSync) handleUpdated(ctx context.Context, data []byte) {
 		return
 	}
 
+	// Closure is handled by handleClosed; other status changes map to a
+	// transition if the workflow says so.
+	if bead.Status != "" && bead.Status != "closed" {
+		s.syncStatus(ctx, bead.ID, jiraKey, bead.Status)
+	}
+
+	// Claims assign the JIRA issue to the claimant's account.
+	s.syncAssignee(ctx, *bead, jiraKey)
+
+	// Progress notes become JIRA comments.
+	s.syncNotes(ctx, *bead, jiraKey)
+
 	// Check for MR URL field — sync it as a remote link to JIRA.
 	mrURL := bead.Fields["mr_url"]
 	if mrURL == "" {
@@ -79,9 +135,10 @@ func (s *JiraSync) handleUpdated(ctx context.Context, data []byte) {
 	s.logger.Info("syncing MR link to JIRA",
 		"bead", bead.ID, "jira_key", jiraKey, "mr_url", mrURL)
 
-	// Add remote link.
+	// Add remote link, with the diff summary if the agent recorded one.
 	title := "Merge Request: " + bead.Title
-	if err := s.jira.AddRemoteLink(ctx, jiraKey, mrURL, title); err != nil {
+	diffSummary := bead.Fields["mr_diff_summary"]
+	if err := s.jira.AddRemoteLink(ctx, jiraKey, mrURL, title, diffSummary); err != nil {
 		s.logger.Error("failed to add JIRA remote link",
 			"jira_key", jiraKey, "mr_url", mrURL, "error", err)
 		return
@@ -89,6 +146,9 @@ func (s *JiraSync) handleUpdated(ctx context.Context, data []byte) {
 
 	// Add comment.
 	comment := "Automated MR created: " + mrURL
+	if diffSummary != "" {
+		comment += "\n" + diffSummary
+	}
 	if err := s.jira.AddComment(ctx, jiraKey, comment); err != nil {
 		s.logger.Error("failed to add JIRA comment for MR",
 			"jira_key", jiraKey, "error", err)
@@ -101,6 +161,16 @@ func (s *JiraSync) handleClosed(ctx context.Context, data []byte) {
 		return
 	}
 
+	// Decision outcomes are reported on the deciding agent's JIRA task.
+	if bead.Type == "decision" {
+		s.syncDecisionOutcome(ctx, *bead)
+		return
+	}
+	if bead.Type == "report" {
+		s.syncReport(ctx, *bead)
+		return
+	}
+
 	jiraKey := jiraKeyFromBead(*bead)
 	if jiraKey == "" {
 		return
@@ -112,6 +182,14 @@ func (s *JiraSync) handleClosed(ctx context.Context, data []byte) {
 		return
 	}
 
+	// Last child closed: close the parent's bead too.
+	s.rollUpParent(ctx, *bead)
+
+	// Closed because JIRA said so — nothing to report back.
+	if s.reflects(bead.ID, "closed") {
+		return
+	}
+
 	s.logger.Info("syncing bead closure to JIRA",
 		"bead", bead.ID, "jira_key", jiraKey)
 
@@ -125,13 +203,81 @@ func (s *JiraSync) handleClosed(ctx context.Context, data []byte) {
 			"jira_key", jiraKey, "error", err)
 	}
 
-	// Attempt to transition to "Review" (best-effort).
-	if !s.disableTransitions {
-		if err := s.jira.TransitionIssue(ctx, jiraKey, "Review"); err != nil {
-			s.logger.Warn("failed to transition JIRA issue to Review (may not be available)",
-				"jira_key", jiraKey, "error", err)
+	s.syncStatus(ctx, bead.ID, jiraKey, "closed")
+}
+
+// syncStatus applies the workflow transition for a bead status change
+// (best-effort). Statuses JIRA already reflects are skipped, which also
+// suppresses echoes of changes that ApplyJiraStatus made.
+func (s *JiraSync) syncStatus(ctx context.Context, beadID, jiraKey, status string) {
+	if s.disableTransitions {
+		return
+	}
+	transition, ok := s.workflow.TransitionFor(status)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	if s.synced[beadID] == status {
+		s.mu.Unlock()
+		return
+	}
+	s.synced[beadID] = status
+	s.mu.Unlock()
+
+	if err := s.jira.TransitionIssue(ctx, jiraKey, transition); err != nil {
+		s.logger.Warn("failed to transition JIRA issue (may not be available)",
+			"jira_key", jiraKey, "status", status, "transition", transition, "error", err)
+		return
+	}
+	s.logger.Info("transitioned JIRA issue for bead status",
+		"bead", beadID, "jira_key", jiraKey, "status", status, "transition", transition)
+}
+
+// ApplyJiraStatus sets a tracked bead's status from its JIRA issue's status
+// via the workflow mapping. It reports whether the bead was changed.
+func (s *JiraSync) ApplyJiraStatus(ctx context.Context, beadID string, issue JiraIssue) (bool, error) {
+	if s.daemon == nil || issue.Fields.Status == nil {
+		return false, nil
+	}
+	jiraStatus := issue.Fields.Status.Name
+	status, ok := s.workflow.BeadStatusFor(jiraStatus)
+	if !ok || s.reflects(beadID, status) {
+		return false, nil
+	}
+
+	// Record first so the resulting bead event doesn't transition JIRA back.
+	s.mu.Lock()
+	prev, hadPrev := s.synced[beadID]
+	s.synced[beadID] = status
+	s.mu.Unlock()
+
+	var err error
+	if status == "closed" {
+		err = s.daemon.CloseBead(ctx, beadID, map[string]string{"jira_status": jiraStatus})
+	} else {
+		err = s.daemon.UpdateBead(ctx, beadID, beadsapi.UpdateBeadRequest{Status: &status})
+	}
+	if err != nil {
+		s.mu.Lock()
+		if hadPrev {
+			s.synced[beadID] = prev
+		} else {
+			delete(s.synced, beadID)
 		}
+		s.mu.Unlock()
+		return false, fmt.Errorf("apply JIRA status %q to bead %s: %w", jiraStatus, beadID, err)
 	}
+	s.logger.Info("applied JIRA status to bead",
+		"bead", beadID, "jira_key", issue.Key, "jira_status", jiraStatus, "status", status)
+	return true, nil
+}
+
+// reflects reports whether JIRA is known to already show the bead status.
+func (s *JiraSync) reflects(beadID, status string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.synced[beadID] == status
 }
 
 // jiraKeyFromBead extracts the JIRA key from a bead's labels or fields.
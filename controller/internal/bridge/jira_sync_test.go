@@ -7,8 +7,11 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
+
+	"gasboat/controller/internal/beadsapi"
 )
 
 func TestJiraSync_MRLink(t *testing.T) {
@@ -209,3 +212,147 @@ func TestAdfToMarkdown(t *testing.T) {
 		})
 	}
 }
+
+// mockJiraSyncDaemon implements JiraSyncClient for testing.
+type mockJiraSyncDaemon struct {
+	mu       sync.Mutex
+	statuses map[string]string // bead ID → status set
+	fields   map[string]map[string]string
+	comments []string                        // "beadID|author|text"
+	tasks    map[string]*beadsapi.BeadDetail // agent name → assigned task
+	beads    map[string]*beadsapi.BeadDetail // bead ID → detail for GetBead
+}
+
+func newMockJiraSyncDaemon() *mockJiraSyncDaemon {
+	return &mockJiraSyncDaemon{
+		statuses: make(map[string]string),
+		fields:   make(map[string]map[string]string),
+		tasks:    make(map[string]*beadsapi.BeadDetail),
+		beads:    make(map[string]*beadsapi.BeadDetail),
+	}
+}
+
+func (m *mockJiraSyncDaemon) UpdateBeadFields(_ context.Context, beadID string, fields map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.fields[beadID] == nil {
+		m.fields[beadID] = make(map[string]string)
+	}
+	for k, v := range fields {
+		m.fields[beadID][k] = v
+	}
+	return nil
+}
+
+func (m *mockJiraSyncDaemon) AddComment(_ context.Context, beadID, author, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.comments = append(m.comments, beadID+"|"+author+"|"+text)
+	return nil
+}
+
+func (m *mockJiraSyncDaemon) ListAssignedTask(_ context.Context, agentName string) (*beadsapi.BeadDetail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tasks[agentName], nil
+}
+
+func (m *mockJiraSyncDaemon) GetBead(_ context.Context, beadID string) (*beadsapi.BeadDetail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if b, ok := m.beads[beadID]; ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("bead %s not found", beadID)
+}
+
+func (m *mockJiraSyncDaemon) ListTaskBeads(_ context.Context) ([]*beadsapi.BeadDetail, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*beadsapi.BeadDetail
+	for _, b := range m.beads {
+		if b.Type == "task" && m.statuses[b.ID] != "closed" {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockJiraSyncDaemon) UpdateBead(_ context.Context, beadID string, req beadsapi.UpdateBeadRequest) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if req.Status != nil {
+		m.statuses[beadID] = *req.Status
+	}
+	return nil
+}
+
+func (m *mockJiraSyncDaemon) CloseBead(_ context.Context, beadID string, _ map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statuses[beadID] = "closed"
+	return nil
+}
+
+func TestJiraSync_WorkflowBothWays(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		transitions []string
+	)
+	jiraServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/rest/api/3/issue/PE-1/transitions":
+			resp := map[string]any{"transitions": []map[string]any{
+				{"id": "21", "name": "Start Progress", "to": map[string]string{"name": "In Progress"}},
+				{"id": "31", "name": "Block", "to": map[string]string{"name": "Blocked"}},
+			}}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.Method == "POST" && r.URL.Path == "/rest/api/3/issue/PE-1/transitions":
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			transitions = append(transitions, body.Transition.ID)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer jiraServer.Close()
+
+	daemon := newMockJiraSyncDaemon()
+	wf := JiraWorkflow{
+		ToJira:   map[string]string{"in_progress": "Start Progress", "blocked": "Block"},
+		FromJira: map[string]string{"In Progress": "in_progress", "Done": "closed"},
+	}
+	s := NewJiraSync(JiraSyncConfig{Jira: newTestJiraClient(jiraServer.URL), Daemon: daemon, Workflow: &wf, Logger: slog.Default()})
+	ctx := context.Background()
+	bead := BeadEvent{ID: "bd-1", Type: "task", Fields: map[string]string{"jira_key": "PE-1"}}
+
+	// JIRA → bead: the transition is applied, and its SSE echo is not sent back.
+	issue := JiraIssue{Key: "PE-1", Fields: JiraIssueFields{Status: &JiraNamedRef{Name: "In Progress"}}}
+	if changed, err := s.ApplyJiraStatus(ctx, "bd-1", issue); err != nil || !changed {
+		t.Fatalf("ApplyJiraStatus = %v, %v", changed, err)
+	}
+	if daemon.statuses["bd-1"] != "in_progress" {
+		t.Errorf("bead status = %q, want in_progress", daemon.statuses["bd-1"])
+	}
+	bead.Status = "in_progress"
+	s.handleUpdated(ctx, marshalSSEBeadPayload(bead))
+
+	// bead → JIRA: a new status triggers its mapped transition once.
+	bead.Status = "blocked"
+	s.handleUpdated(ctx, marshalSSEBeadPayload(bead))
+	s.handleUpdated(ctx, marshalSSEBeadPayload(bead))
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(transitions, ",") != "31" {
+		t.Errorf("transitions = %v, want [31]", transitions)
+	}
+}
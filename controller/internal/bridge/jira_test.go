@@ -17,6 +17,7 @@ import (
 type mockJiraDaemon struct {
 	mu     sync.Mutex
 	beads  map[string]*beadsapi.BeadDetail
+	deps   []string // "beadID→dependsOnID:type"
 	nextID int
 }
 
@@ -57,6 +58,13 @@ func (m *mockJiraDaemon) ListTaskBeads(_ context.Context) ([]*beadsapi.BeadDetai
 	return result, nil
 }
 
+func (m *mockJiraDaemon) AddDependency(_ context.Context, beadID, dependsOnID, depType, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deps = append(m.deps, beadID+"→"+dependsOnID+":"+depType)
+	return nil
+}
+
 func (m *mockJiraDaemon) getBeads() map[string]*beadsapi.BeadDetail {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -0,0 +1,188 @@
+// Package bridge provides the JIRA webhook receiver.
+//
+// JiraWebhook accepts JIRA Cloud issue webhooks (created, updated, and
+// transitioned — transitions arrive as jira:issue_updated) and feeds matching
+// issues into JiraPoller.Ingest, so new tickets become task beads within
+// seconds instead of waiting for the next poll. Events for issues that are
+// already tracked go to JiraSync.ApplyJiraStatus, so JIRA transitions update
+// the linked bead per the configured workflow, and to JiraSync.ApplyJiraAssignee,
+// so assigning a bot account readies the bead; comment_created events are
+// copied onto the bead via JiraSync.ApplyJiraComment. Requests are authenticated
+// with the webhook secret: JIRA signs the body with HMAC-SHA256 and sends it
+// in the X-Hub-Signature header as "sha256=<hex>".
+package bridge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// jiraWebhookMaxBody caps webhook payloads; issue events are a few KB.
+const jiraWebhookMaxBody = 1 << 20
+
+// JiraWebhookConfig holds configuration for the JIRA webhook receiver.
+type JiraWebhookConfig struct {
+	Secret string    // shared webhook secret (required)
+	Sync   *JiraSync // nil = don't apply JIRA status changes to beads
+	Logger *slog.Logger
+}
+
+// JiraWebhook receives JIRA issue webhooks and creates task beads.
+type JiraWebhook struct {
+	poller *JiraPoller
+	sync   *JiraSync
+	secret []byte
+	logger *slog.Logger
+}
+
+// jiraWebhookEvent is the subset of a JIRA webhook payload we use.
+type jiraWebhookEvent struct {
+	WebhookEvent string       `json:"webhookEvent"`
+	Issue        *JiraIssue   `json:"issue"`
+	Comment      *JiraComment `json:"comment"`
+}
+
+// NewJiraWebhook creates a webhook receiver that ingests through poller.
+func NewJiraWebhook(poller *JiraPoller, cfg JiraWebhookConfig) *JiraWebhook {
+	return &JiraWebhook{
+		poller: poller,
+		sync:   cfg.Sync,
+		secret: []byte(cfg.Secret),
+		logger: cfg.Logger,
+	}
+}
+
+// ServeHTTP handles a single webhook delivery.
+func (h *JiraWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, jiraWebhookMaxBody))
+	if err != nil {
+		http.Error(w, "read body", http.StatusBadRequest)
+		return
+	}
+	if !h.validSignature(r.Header.Get("X-Hub-Signature"), body) {
+		h.logger.Warn("rejected JIRA webhook with invalid signature", "remote", r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var event jiraWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if event.Issue == nil || event.Issue.Key == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	issue := *event.Issue
+
+	switch event.WebhookEvent {
+	case "jira:issue_created", "jira:issue_updated":
+	case "comment_created":
+		h.handleComment(w, r, issue, event.Comment)
+		return
+	default:
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Tracked issue: mirror its JIRA status onto the bead.
+	if beadID, ok := h.poller.TrackedBead(issue.Key); ok {
+		if h.sync == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		changed, err := h.sync.ApplyJiraStatus(r.Context(), beadID, issue)
+		if err != nil {
+			h.logger.Error("failed to apply JIRA status from webhook",
+				"key", issue.Key, "bead", beadID, "error", err)
+			http.Error(w, "update bead failed", http.StatusBadGateway)
+			return
+		}
+		readied, err := h.sync.ApplyJiraAssignee(r.Context(), beadID, issue)
+		if err != nil {
+			h.logger.Error("failed to apply JIRA assignee from webhook",
+				"key", issue.Key, "bead", beadID, "error", err)
+			http.Error(w, "update bead failed", http.StatusBadGateway)
+			return
+		}
+		h.logger.Info("JIRA webhook processed",
+			"key", issue.Key, "event", event.WebhookEvent, "bead", beadID,
+			"status_changed", changed, "readied", readied)
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if !h.poller.Matches(r.Context(), issue) {
+		h.logger.Debug("JIRA webhook issue does not match filters",
+			"key", issue.Key, "event", event.WebhookEvent)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	created, err := h.poller.Ingest(r.Context(), issue)
+	if err != nil {
+		// Let JIRA retry; the reconciliation poll is the backstop.
+		http.Error(w, "create bead failed", http.StatusBadGateway)
+		return
+	}
+	h.logger.Info("JIRA webhook processed",
+		"key", issue.Key, "event", event.WebhookEvent, "created", created)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleComment copies a new JIRA comment onto the tracked bead.
+func (h *JiraWebhook) handleComment(w http.ResponseWriter, r *http.Request, issue JiraIssue, comment *JiraComment) {
+	beadID, ok := h.poller.TrackedBead(issue.Key)
+	if !ok || h.sync == nil || comment == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	added, err := h.sync.ApplyJiraComment(r.Context(), beadID, issue.Key, *comment)
+	if err != nil {
+		h.logger.Error("failed to copy JIRA comment from webhook",
+			"key", issue.Key, "bead", beadID, "error", err)
+		http.Error(w, "add comment failed", http.StatusBadGateway)
+		return
+	}
+	if !added {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// validSignature checks an X-Hub-Signature header ("sha256=<hex>") against
+// the HMAC-SHA256 of body. An unset secret rejects every request.
+func (h *JiraWebhook) validSignature(header string, body []byte) bool {
+	return validHubSignature(h.secret, header, body)
+}
+
+// validHubSignature checks a "sha256=<hex>" HMAC-SHA256 signature header, as
+// sent by JIRA and GitHub webhooks. An empty secret rejects every request.
+func validHubSignature(secret []byte, header string, body []byte) bool {
+	if len(secret) == 0 {
+		return false
+	}
+	sig, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
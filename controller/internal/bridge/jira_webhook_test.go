@@ -0,0 +1,105 @@
+package bridge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func signJiraWebhook(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func postJiraWebhook(h http.Handler, body, signature string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/jira", strings.NewReader(body))
+	if signature != "" {
+		req.Header.Set("X-Hub-Signature", signature)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func newTestJiraWebhook(daemon *mockJiraDaemon) *JiraWebhook {
+	poller := NewJiraPoller(newTestJiraClient("https://jira.example.com"), daemon, JiraPollerConfig{
+		Projects:   []string{"PE"},
+		Statuses:   []string{"To Do"},
+		IssueTypes: []string{"Bug"},
+		Logger:     slog.Default(),
+	})
+	return NewJiraWebhook(poller, JiraWebhookConfig{Secret: "s3cret", Logger: slog.Default()})
+}
+
+const jiraWebhookCreated = `{"webhookEvent":"jira:issue_created","issue":{"key":"PE-7001","id":"10001","fields":{
+	"summary":"Upload fails","status":{"name":"To Do"},"issuetype":{"name":"Bug"},"priority":{"name":"High"}}}}`
+
+func TestJiraWebhook_CreatesBeadOnce(t *testing.T) {
+	daemon := newMockJiraDaemon()
+	h := newTestJiraWebhook(daemon)
+
+	sig := signJiraWebhook("s3cret", jiraWebhookCreated)
+	if code := postJiraWebhook(h, jiraWebhookCreated, sig); code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", code)
+	}
+	// Redelivery (or a later update event) must not create a duplicate.
+	if code := postJiraWebhook(h, jiraWebhookCreated, sig); code != http.StatusNoContent {
+		t.Fatalf("expected 204 on redelivery, got %d", code)
+	}
+
+	beads := daemon.getBeads()
+	if len(beads) != 1 {
+		t.Fatalf("expected 1 bead, got %d", len(beads))
+	}
+	for _, b := range beads {
+		if b.Title != "[PE-7001] Upload fails" || b.Fields["jira_key"] != "PE-7001" {
+			t.Errorf("unexpected bead %+v", b)
+		}
+	}
+}
+
+func TestJiraWebhook_RejectsBadSignature(t *testing.T) {
+	daemon := newMockJiraDaemon()
+	h := newTestJiraWebhook(daemon)
+
+	for name, sig := range map[string]string{
+		"missing":   "",
+		"wrong key": signJiraWebhook("other", jiraWebhookCreated),
+		"not hex":   "sha256=zz",
+		"no prefix": strings.TrimPrefix(signJiraWebhook("s3cret", jiraWebhookCreated), "sha256="),
+	} {
+		if code := postJiraWebhook(h, jiraWebhookCreated, sig); code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, code)
+		}
+	}
+	if n := len(daemon.getBeads()); n != 0 {
+		t.Errorf("expected no beads, got %d", n)
+	}
+}
+
+func TestJiraWebhook_IgnoresNonMatchingIssues(t *testing.T) {
+	daemon := newMockJiraDaemon()
+	h := newTestJiraWebhook(daemon)
+
+	for _, body := range []string{
+		// Status outside the configured filter.
+		`{"webhookEvent":"jira:issue_updated","issue":{"key":"PE-1","fields":{"status":{"name":"Done"},"issuetype":{"name":"Bug"}}}}`,
+		// Project outside the configured filter.
+		`{"webhookEvent":"jira:issue_created","issue":{"key":"OPS-1","fields":{"status":{"name":"To Do"},"issuetype":{"name":"Bug"}}}}`,
+		// Unrelated event type.
+		`{"webhookEvent":"comment_created","issue":{"key":"PE-2","fields":{"status":{"name":"To Do"},"issuetype":{"name":"Bug"}}}}`,
+	} {
+		if code := postJiraWebhook(h, body, signJiraWebhook("s3cret", body)); code != http.StatusNoContent {
+			t.Errorf("expected 204 for %s, got %d", body, code)
+		}
+	}
+	if n := len(daemon.getBeads()); n != 0 {
+		t.Errorf("expected no beads, got %d", n)
+	}
+}
@@ -0,0 +1,89 @@
+// Package bridge provides the JIRA ↔ bead status workflow mapping.
+//
+// JiraWorkflow maps bead statuses to JIRA transitions (applied by JiraSync
+// when a linked bead changes status) and JIRA statuses to bead statuses
+// (applied when a JIRA webhook reports a transition on a tracked issue).
+// The mapping is loaded from JSON or YAML; without one, DefaultJiraWorkflow
+// reproduces the original behaviour of moving closed beads to "Review".
+package bridge
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// JiraWorkflow is a bidirectional status mapping between beads and JIRA.
+//
+// Example (YAML):
+//
+//	to_jira:          # bead status → JIRA transition (or target status) name
+//	  in_progress: Start Progress
+//	  closed: Review
+//	from_jira:        # JIRA status name → bead status
+//	  In Progress: in_progress
+//	  Done: closed
+type JiraWorkflow struct {
+	ToJira   map[string]string `json:"to_jira,omitempty"`
+	FromJira map[string]string `json:"from_jira,omitempty"`
+}
+
+// DefaultJiraWorkflow returns the mapping used when none is configured.
+func DefaultJiraWorkflow() JiraWorkflow {
+	return JiraWorkflow{ToJira: map[string]string{"closed": "Review"}}
+}
+
+// ParseJiraWorkflow parses a JSON or YAML workflow mapping.
+func ParseJiraWorkflow(data []byte) (JiraWorkflow, error) {
+	var wf JiraWorkflow
+	if err := yaml.UnmarshalStrict(data, &wf); err != nil {
+		return JiraWorkflow{}, fmt.Errorf("parse JIRA workflow: %w", err)
+	}
+	for status := range wf.ToJira {
+		if !validBeadStatus(status) {
+			return JiraWorkflow{}, fmt.Errorf("parse JIRA workflow: unknown bead status %q in to_jira", status)
+		}
+	}
+	for jiraStatus, status := range wf.FromJira {
+		if !validBeadStatus(status) {
+			return JiraWorkflow{}, fmt.Errorf("parse JIRA workflow: unknown bead status %q for JIRA status %q", status, jiraStatus)
+		}
+	}
+	return wf, nil
+}
+
+// LoadJiraWorkflow reads a workflow mapping from a JSON or YAML file.
+func LoadJiraWorkflow(path string) (JiraWorkflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return JiraWorkflow{}, fmt.Errorf("read JIRA workflow: %w", err)
+	}
+	return ParseJiraWorkflow(data)
+}
+
+// TransitionFor returns the JIRA transition for a bead status, if mapped.
+func (wf JiraWorkflow) TransitionFor(beadStatus string) (string, bool) {
+	t, ok := wf.ToJira[beadStatus]
+	return t, ok && t != ""
+}
+
+// BeadStatusFor returns the bead status for a JIRA status, if mapped.
+// JIRA status names match case-insensitively.
+func (wf JiraWorkflow) BeadStatusFor(jiraStatus string) (string, bool) {
+	for name, status := range wf.FromJira {
+		if strings.EqualFold(name, jiraStatus) {
+			return status, true
+		}
+	}
+	return "", false
+}
+
+func validBeadStatus(s string) bool {
+	switch s {
+	case "open", "in_progress", "blocked", "deferred", "closed":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package bridge
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseJiraWorkflow_YAMLAndJSON(t *testing.T) {
+	for name, data := range map[string]string{
+		"yaml": "to_jira:\n  in_progress: Start Progress\n  closed: Review\nfrom_jira:\n  Done: closed\n",
+		"json": `{"to_jira":{"in_progress":"Start Progress","closed":"Review"},"from_jira":{"Done":"closed"}}`,
+	} {
+		wf, err := ParseJiraWorkflow([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if tr, ok := wf.TransitionFor("in_progress"); !ok || tr != "Start Progress" {
+			t.Errorf("%s: TransitionFor(in_progress) = %q, %v", name, tr, ok)
+		}
+		if st, ok := wf.BeadStatusFor("done"); !ok || st != "closed" {
+			t.Errorf("%s: BeadStatusFor(done) = %q, %v", name, st, ok)
+		}
+		if _, ok := wf.TransitionFor("open"); ok {
+			t.Errorf("%s: expected open unmapped", name)
+		}
+	}
+}
+
+func TestParseJiraWorkflow_RejectsUnknownStatus(t *testing.T) {
+	for _, data := range []string{
+		`{"to_jira":{"finished":"Done"}}`,
+		`{"from_jira":{"Done":"finished"}}`,
+		`{"to_jira":{"closed":"Done"},"typo":{}}`,
+	} {
+		if _, err := ParseJiraWorkflow([]byte(data)); err == nil || !strings.Contains(err.Error(), "JIRA workflow") {
+			t.Errorf("expected error for %s, got %v", data, err)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// Package bridge provides leader election for multi-replica slack-bridge
+// deployments.
+//
+// Leader holds a lease stored as a beads daemon config key. Only the leader
+// runs the SSE watchers, dashboard updates, outbox retries, and catch-up;
+// Socket Mode interaction handling stays active on every replica because
+// Slack delivers each interaction to exactly one connection.
+//
+// The lease is best-effort: the daemon config API has no compare-and-swap, so
+// two replicas racing for an expired lease can briefly both believe they are
+// leader. Acquisition writes then re-reads the lease to narrow that window,
+// and shared dedup (Dedup.UseSharedState) absorbs events processed during it.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// LeaseClient is the subset of the beads daemon API used for the lease.
+// *beadsapi.Client satisfies it.
+type LeaseClient interface {
+	GetConfig(ctx context.Context, key string) (*beadsapi.ConfigEntry, error)
+	SetConfig(ctx context.Context, key string, value []byte) error
+}
+
+// LeaderConfig configures leader election.
+type LeaderConfig struct {
+	Client   LeaseClient
+	Key      string        // daemon config key holding the lease (default "slack-bridge:leader")
+	Identity string        // unique replica identity, e.g. the pod name
+	TTL      time.Duration // lease duration (default 30s); renewed every TTL/3
+	Disabled bool          // single-replica mode: always leader, no lease traffic
+	Logger   *slog.Logger
+}
+
+// leaseRecord is the JSON value stored under the lease key.
+type leaseRecord struct {
+	Holder    string    `json:"holder"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Leader tracks whether this replica currently holds the lease.
+type Leader struct {
+	client   LeaseClient
+	key      string
+	identity string
+	ttl      time.Duration
+	disabled bool
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	leader  bool
+	changed chan struct{} // closed and replaced on every leadership change
+}
+
+// NewLeader creates a leader elector.
+func NewLeader(cfg LeaderConfig) *Leader {
+	if cfg.Key == "" {
+		cfg.Key = "slack-bridge:leader"
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = 30 * time.Second
+	}
+	return &Leader{
+		client:   cfg.Client,
+		key:      cfg.Key,
+		identity: cfg.Identity,
+		ttl:      cfg.TTL,
+		disabled: cfg.Disabled,
+		logger:   cfg.Logger,
+		now:      time.Now,
+		leader:   cfg.Disabled,
+		changed:  make(chan struct{}),
+	}
+}
+
+// IsLeader reports whether this replica currently holds the lease.
+func (l *Leader) IsLeader() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leader
+}
+
+// watch returns the current leadership state and a channel that is closed on
+// the next change.
+func (l *Leader) watch() (bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.leader, l.changed
+}
+
+// setLeader records a leadership change and wakes watchers.
+func (l *Leader) setLeader(leader bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leader == leader {
+		return
+	}
+	l.leader = leader
+	close(l.changed)
+	l.changed = make(chan struct{})
+	if leader {
+		l.logger.Info("acquired slack-bridge leadership", "identity", l.identity, "key", l.key)
+	} else {
+		l.logger.Warn("lost slack-bridge leadership", "identity", l.identity, "key", l.key)
+	}
+}
+
+// Run acquires and renews the lease until ctx is cancelled. On shutdown the
+// lease is released so a standby can take over without waiting for expiry.
+func (l *Leader) Run(ctx context.Context) {
+	if l.disabled {
+		<-ctx.Done()
+		return
+	}
+
+	l.tick(ctx)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.tick(ctx)
+		case <-ctx.Done():
+			l.release()
+			return
+		}
+	}
+}
+
+// tick attempts to acquire or renew the lease and updates leadership state.
+// A lease that cannot be renewed is given up before it expires elsewhere.
+func (l *Leader) tick(ctx context.Context) {
+	ok, err := l.tryAcquire(ctx)
+	if err != nil {
+		l.logger.Warn("leader lease check failed", "key", l.key, "error", err)
+	}
+	l.setLeader(ok)
+}
+
+// tryAcquire takes the lease if it is free, expired, or already ours, then
+// reads it back to confirm no other replica overwrote it.
+func (l *Leader) tryAcquire(ctx context.Context) (bool, error) {
+	current, err := l.readLease(ctx)
+	if err != nil {
+		return false, err
+	}
+	now := l.now()
+	if current != nil && current.Holder != l.identity && now.Before(current.ExpiresAt) {
+		return false, nil
+	}
+
+	value, err := json.Marshal(leaseRecord{Holder: l.identity, ExpiresAt: now.Add(l.ttl)})
+	if err != nil {
+		return false, err
+	}
+	if err := l.client.SetConfig(ctx, l.key, value); err != nil {
+		return false, fmt.Errorf("write lease: %w", err)
+	}
+
+	confirmed, err := l.readLease(ctx)
+	if err != nil {
+		return false, err
+	}
+	return confirmed != nil && confirmed.Holder == l.identity, nil
+}
+
+// readLease fetches the lease record. A missing key returns (nil, nil).
+func (l *Leader) readLease(ctx context.Context) (*leaseRecord, error) {
+	entry, err := l.client.GetConfig(ctx, l.key)
+	if err != nil {
+		var apiErr *beadsapi.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read lease: %w", err)
+	}
+	var rec leaseRecord
+	if err := json.Unmarshal(entry.Value, &rec); err != nil {
+		// A corrupt lease is treated as free rather than blocking election.
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+// release expires the lease if this replica holds it.
+func (l *Leader) release() {
+	if !l.IsLeader() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	value, _ := json.Marshal(leaseRecord{Holder: l.identity, ExpiresAt: l.now()})
+	if err := l.client.SetConfig(ctx, l.key, value); err != nil {
+		l.logger.Warn("failed to release leader lease", "error", err)
+	}
+	l.setLeader(false)
+}
+
+// RunWhileLeader runs fn whenever this replica is leader. fn receives a
+// context that is cancelled when leadership is lost or ctx ends, and is
+// restarted on the next acquisition. Blocks until ctx is cancelled.
+func (l *Leader) RunWhileLeader(ctx context.Context, name string, fn func(ctx context.Context)) {
+	for {
+		leader, changed := l.watch()
+		if !leader {
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		l.logger.Info("starting leader task", "task", name)
+		taskCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			fn(taskCtx)
+		}()
+
+		// A one-shot task (e.g. catch-up) that returns by itself is not
+		// restarted until leadership changes.
+		select {
+		case <-changed:
+		case <-ctx.Done():
+		}
+		cancel()
+		<-done
+		if ctx.Err() != nil {
+			return
+		}
+		l.logger.Info("stopped leader task", "task", name)
+	}
+}
@@ -0,0 +1,149 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// fakeLeaseStore is an in-memory daemon config store for lease tests.
+type fakeLeaseStore struct {
+	mu      sync.Mutex
+	configs map[string][]byte
+}
+
+func newFakeLeaseStore() *fakeLeaseStore {
+	return &fakeLeaseStore{configs: make(map[string][]byte)}
+}
+
+func (f *fakeLeaseStore) GetConfig(_ context.Context, key string) (*beadsapi.ConfigEntry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.configs[key]
+	if !ok {
+		return nil, &beadsapi.APIError{StatusCode: 404, Message: "not found"}
+	}
+	return &beadsapi.ConfigEntry{Key: key, Value: v}, nil
+}
+
+func (f *fakeLeaseStore) SetConfig(_ context.Context, key string, value []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.configs[key] = value
+	return nil
+}
+
+func newTestLeader(store LeaseClient, identity string, now *time.Time) *Leader {
+	l := NewLeader(LeaderConfig{Client: store, Identity: identity, Logger: slog.Default()})
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestLeader_AcquireAndContention(t *testing.T) {
+	store := newFakeLeaseStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestLeader(store, "pod-a", &now)
+	b := newTestLeader(store, "pod-b", &now)
+	ctx := context.Background()
+
+	a.tick(ctx)
+	b.tick(ctx)
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("expected pod-a leader only, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+
+	// Renewal by the holder keeps leadership.
+	now = now.Add(20 * time.Second)
+	a.tick(ctx)
+	b.tick(ctx)
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatal("expected pod-a to keep leadership after renewal")
+	}
+}
+
+func TestLeader_TakeoverAfterExpiry(t *testing.T) {
+	store := newFakeLeaseStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestLeader(store, "pod-a", &now)
+	b := newTestLeader(store, "pod-b", &now)
+	ctx := context.Background()
+
+	a.tick(ctx)
+	// pod-a stops renewing; the lease expires.
+	now = now.Add(31 * time.Second)
+	b.tick(ctx)
+	if !b.IsLeader() {
+		t.Fatal("expected pod-b to take over expired lease")
+	}
+
+	// pod-a notices on its next tick.
+	a.tick(ctx)
+	if a.IsLeader() {
+		t.Error("expected pod-a to step down")
+	}
+
+	var rec leaseRecord
+	_ = json.Unmarshal(store.configs["slack-bridge:leader"], &rec)
+	if rec.Holder != "pod-b" {
+		t.Errorf("expected lease held by pod-b, got %q", rec.Holder)
+	}
+}
+
+func TestLeader_Release(t *testing.T) {
+	store := newFakeLeaseStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := newTestLeader(store, "pod-a", &now)
+	b := newTestLeader(store, "pod-b", &now)
+
+	a.tick(context.Background())
+	a.release()
+	b.tick(context.Background())
+	if !b.IsLeader() {
+		t.Error("expected pod-b to acquire released lease immediately")
+	}
+}
+
+func TestLeader_DisabledAlwaysLeads(t *testing.T) {
+	l := NewLeader(LeaderConfig{Disabled: true, Logger: slog.Default()})
+	if !l.IsLeader() {
+		t.Error("expected disabled election to always lead")
+	}
+}
+
+func TestLeader_RunWhileLeaderCancelsOnLoss(t *testing.T) {
+	store := newFakeLeaseStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := newTestLeader(store, "pod-a", &now)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan struct{}, 2)
+	stopped := make(chan struct{}, 2)
+	go l.RunWhileLeader(ctx, "test", func(ctx context.Context) {
+		started <- struct{}{}
+		<-ctx.Done()
+		stopped <- struct{}{}
+	})
+
+	l.setLeader(true)
+	waitFor(t, started, "task start")
+	l.setLeader(false)
+	waitFor(t, stopped, "task stop on leadership loss")
+	l.setLeader(true)
+	waitFor(t, started, "task restart on re-acquisition")
+}
+
+func waitFor(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+	}
+}
@@ -0,0 +1,266 @@
+// Package bridge provides the Linear GraphQL client.
+//
+// LinearClient is a minimal client for the Linear GraphQL API covering what
+// the linear-bridge needs: listing team issues in given workflow states,
+// commenting, linking merge requests as attachments, and moving an issue to
+// its team's completed state.
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLinearURL is the Linear GraphQL endpoint.
+const defaultLinearURL = "https://api.linear.app/graphql"
+
+// LinearClientConfig holds configuration for the Linear client.
+type LinearClientConfig struct {
+	APIKey string // personal API key or OAuth token ("Bearer ..." prefix kept as-is)
+	URL    string // GraphQL endpoint (default https://api.linear.app/graphql)
+	Logger *slog.Logger
+}
+
+// LinearClient is a lightweight Linear GraphQL client.
+type LinearClient struct {
+	httpClient *http.Client
+	url        string
+	apiKey     string
+	logger     *slog.Logger
+
+	mu          sync.Mutex
+	doneStateID map[string]string // team key → completed workflow state ID
+}
+
+// LinearIssue is a Linear issue as returned by ListIssues.
+type LinearIssue struct {
+	ID          string `json:"id"`
+	Identifier  string `json:"identifier"` // e.g. "ENG-123"
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	Priority    int    `json:"priority"` // 0 none, 1 urgent, 2 high, 3 medium, 4 low
+	State       struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"state"`
+	Team struct {
+		Key string `json:"key"`
+	} `json:"team"`
+	Creator *struct {
+		Name string `json:"name"`
+	} `json:"creator"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+}
+
+// NewLinearClient creates a new Linear GraphQL client.
+func NewLinearClient(cfg LinearClientConfig) *LinearClient {
+	if cfg.URL == "" {
+		cfg.URL = defaultLinearURL
+	}
+	return &LinearClient{
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		url:         cfg.URL,
+		apiKey:      cfg.APIKey,
+		logger:      cfg.Logger,
+		doneStateID: make(map[string]string),
+	}
+}
+
+const linearIssuesQuery = `query Issues($team: String!, $states: [String!], $after: String) {
+  issues(first: 100, after: $after, filter: {team: {key: {eq: $team}}, state: {name: {in: $states}}}) {
+    nodes {
+      id identifier title description url priority
+      state { name type }
+      team { key }
+      creator { name }
+      labels { nodes { name } }
+    }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+// ListIssues returns every issue of the team whose workflow state name is
+// one of states, following pagination.
+func (c *LinearClient) ListIssues(ctx context.Context, teamKey string, states []string) ([]LinearIssue, error) {
+	var all []LinearIssue
+	var after *string
+	for {
+		var result struct {
+			Issues struct {
+				Nodes    []LinearIssue `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"issues"`
+		}
+		vars := map[string]any{"team": teamKey, "states": states, "after": after}
+		if err := c.graphql(ctx, linearIssuesQuery, vars, &result); err != nil {
+			return nil, err
+		}
+		all = append(all, result.Issues.Nodes...)
+		if !result.Issues.PageInfo.HasNextPage || result.Issues.PageInfo.EndCursor == "" {
+			return all, nil
+		}
+		cursor := result.Issues.PageInfo.EndCursor
+		after = &cursor
+	}
+}
+
+// AddComment posts a markdown comment on an issue.
+func (c *LinearClient) AddComment(ctx context.Context, issueID, body string) error {
+	const q = `mutation Comment($issue: String!, $body: String!) {
+  commentCreate(input: {issueId: $issue, body: $body}) { success }
+}`
+	var result struct {
+		CommentCreate struct {
+			Success bool `json:"success"`
+		} `json:"commentCreate"`
+	}
+	if err := c.graphql(ctx, q, map[string]any{"issue": issueID, "body": body}, &result); err != nil {
+		return err
+	}
+	if !result.CommentCreate.Success {
+		return fmt.Errorf("linear commentCreate on %s was not successful", issueID)
+	}
+	return nil
+}
+
+// AttachLink attaches a URL (e.g. a merge request) to an issue.
+func (c *LinearClient) AttachLink(ctx context.Context, issueID, url, title string) error {
+	const q = `mutation Attach($issue: String!, $url: String!, $title: String) {
+  attachmentLinkURL(issueId: $issue, url: $url, title: $title) { success }
+}`
+	var result struct {
+		AttachmentLinkURL struct {
+			Success bool `json:"success"`
+		} `json:"attachmentLinkURL"`
+	}
+	if err := c.graphql(ctx, q, map[string]any{"issue": issueID, "url": url, "title": title}, &result); err != nil {
+		return err
+	}
+	if !result.AttachmentLinkURL.Success {
+		return fmt.Errorf("linear attachmentLinkURL on %s was not successful", issueID)
+	}
+	return nil
+}
+
+// CompleteIssue moves an issue to its team's first "completed" workflow
+// state (usually "Done").
+func (c *LinearClient) CompleteIssue(ctx context.Context, issueID, teamKey string) error {
+	stateID, err := c.completedState(ctx, teamKey)
+	if err != nil {
+		return err
+	}
+	const q = `mutation Complete($issue: String!, $state: String!) {
+  issueUpdate(id: $issue, input: {stateId: $state}) { success }
+}`
+	var result struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
+	}
+	if err := c.graphql(ctx, q, map[string]any{"issue": issueID, "state": stateID}, &result); err != nil {
+		return err
+	}
+	if !result.IssueUpdate.Success {
+		return fmt.Errorf("linear issueUpdate on %s was not successful", issueID)
+	}
+	return nil
+}
+
+// completedState returns (and caches) the ID of the team's completed state.
+func (c *LinearClient) completedState(ctx context.Context, teamKey string) (string, error) {
+	c.mu.Lock()
+	id, ok := c.doneStateID[teamKey]
+	c.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	const q = `query Done($team: String!) {
+  workflowStates(first: 1, filter: {team: {key: {eq: $team}}, type: {eq: "completed"}}) { nodes { id name } }
+}`
+	var result struct {
+		WorkflowStates struct {
+			Nodes []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"nodes"`
+		} `json:"workflowStates"`
+	}
+	if err := c.graphql(ctx, q, map[string]any{"team": teamKey}, &result); err != nil {
+		return "", err
+	}
+	if len(result.WorkflowStates.Nodes) == 0 {
+		return "", fmt.Errorf("linear team %s has no completed workflow state", teamKey)
+	}
+	id = result.WorkflowStates.Nodes[0].ID
+	c.mu.Lock()
+	c.doneStateID[teamKey] = id
+	c.mu.Unlock()
+	return id, nil
+}
+
+// graphql executes a query and decodes its data into result. GraphQL-level
+// errors are returned as an error.
+func (c *LinearClient) graphql(ctx context.Context, query string, vars map[string]any, result any) error {
+	payload, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("marshal linear request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("linear request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read linear response: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("linear API returned %d: %s", resp.StatusCode, truncate(string(body), 256))
+	}
+
+	var envelope struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("decode linear response: %w", err)
+	}
+	if len(envelope.Errors) > 0 {
+		msgs := make([]string, len(envelope.Errors))
+		for i, e := range envelope.Errors {
+			msgs[i] = e.Message
+		}
+		return fmt.Errorf("linear GraphQL error: %s", strings.Join(msgs, "; "))
+	}
+	if result == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, result)
+}
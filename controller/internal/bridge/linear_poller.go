@@ -0,0 +1,255 @@
+// Package bridge provides the Linear issue polling loop.
+//
+// LinearPoller imports issues from each configured Linear team that sit in
+// one of the selected workflow states as task beads, mirroring JiraPoller:
+// it deduplicates by tracking issue identifier ("ENG-123") → bead ID, runs a
+// CatchUp pass on startup, and self-heals the tracked map on every poll.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// LinearBeadClient is the subset of beadsapi.Client used by the Linear poller.
+type LinearBeadClient interface {
+	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
+	ListTaskBeads(ctx context.Context) ([]*beadsapi.BeadDetail, error)
+}
+
+// LinearPollerConfig holds configuration for the Linear poller.
+type LinearPollerConfig struct {
+	Teams        []string          // Linear team keys (e.g., ["ENG", "OPS"])
+	States       []string          // workflow state names to ingest (default ["Todo"])
+	PollInterval time.Duration     // polling interval (default 60s)
+	ProjectMap   map[string]string // team key (upper) → boat project name
+	Logger       *slog.Logger
+}
+
+// LinearPoller polls Linear for issues and creates task beads.
+type LinearPoller struct {
+	linear *LinearClient
+	daemon LinearBeadClient
+	cfg    LinearPollerConfig
+
+	ingestMu sync.Mutex // serializes check-and-create
+	mu       sync.Mutex
+	tracked  map[string]string // issue identifier → bead ID
+}
+
+// NewLinearPoller creates a new Linear polling loop.
+func NewLinearPoller(linear *LinearClient, daemon LinearBeadClient, cfg LinearPollerConfig) *LinearPoller {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 60 * time.Second
+	}
+	if len(cfg.States) == 0 {
+		cfg.States = []string{"Todo"}
+	}
+	return &LinearPoller{
+		linear:  linear,
+		daemon:  daemon,
+		cfg:     cfg,
+		tracked: make(map[string]string),
+	}
+}
+
+// Run starts the polling loop. It runs CatchUp once, then polls at the
+// configured interval until ctx is canceled.
+func (p *LinearPoller) Run(ctx context.Context) error {
+	p.CatchUp(ctx)
+
+	p.cfg.Logger.Info("Linear poller started",
+		"teams", p.cfg.Teams,
+		"states", p.cfg.States,
+		"interval", p.cfg.PollInterval)
+
+	ticker := time.NewTicker(p.cfg.PollInterval)
+	defer ticker.Stop()
+
+	p.poll(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			p.poll(ctx)
+		}
+	}
+}
+
+// CatchUp populates the tracked map from existing task beads so restarts
+// don't create duplicates.
+func (p *LinearPoller) CatchUp(ctx context.Context) {
+	beads, err := p.daemon.ListTaskBeads(ctx)
+	if err != nil {
+		p.cfg.Logger.Warn("Linear poller catch-up: failed to list task beads", "error", err)
+		return
+	}
+	count := p.trackBeads(beads)
+	p.cfg.Logger.Info("Linear poller catch-up complete", "tracked", count)
+}
+
+// trackBeads records the linear_key field of each bead and returns how many
+// beads carried one.
+func (p *LinearPoller) trackBeads(beads []*beadsapi.BeadDetail) int {
+	count := 0
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, b := range beads {
+		if key := b.Fields["linear_key"]; key != "" {
+			if _, exists := p.tracked[key]; !exists {
+				p.tracked[key] = b.ID
+			}
+			count++
+		}
+	}
+	return count
+}
+
+// poll executes a single Linear poll cycle.
+func (p *LinearPoller) poll(ctx context.Context) {
+	// Self-heal the tracked map from live data, as JiraPoller does.
+	if beads, err := p.daemon.ListTaskBeads(ctx); err == nil {
+		p.trackBeads(beads)
+	}
+
+	found, created, skipped := 0, 0, 0
+	for _, team := range p.cfg.Teams {
+		issues, err := p.linear.ListIssues(ctx, team, p.cfg.States)
+		if err != nil {
+			p.cfg.Logger.Error("Linear poll failed", "team", team, "error", err)
+			continue
+		}
+		found += len(issues)
+		for _, issue := range issues {
+			ok, err := p.Ingest(ctx, issue)
+			switch {
+			case err != nil:
+				continue
+			case ok:
+				created++
+			default:
+				skipped++
+			}
+		}
+	}
+
+	if created > 0 || p.cfg.Logger.Enabled(ctx, slog.LevelDebug) {
+		p.cfg.Logger.Info("Linear poll complete",
+			"found", found, "created", created, "skipped", skipped)
+	}
+}
+
+// Ingest creates a task bead for the issue unless one is already tracked.
+// It reports whether a bead was created.
+func (p *LinearPoller) Ingest(ctx context.Context, issue LinearIssue) (bool, error) {
+	p.ingestMu.Lock()
+	defer p.ingestMu.Unlock()
+
+	if _, ok := p.TrackedBead(issue.Identifier); ok {
+		return false, nil
+	}
+
+	beadID, err := p.createBeadFromIssue(ctx, issue)
+	if err != nil {
+		p.cfg.Logger.Error("failed to create bead for Linear issue",
+			"linear_key", issue.Identifier, "error", err)
+		return false, err
+	}
+
+	p.mu.Lock()
+	p.tracked[issue.Identifier] = beadID
+	p.mu.Unlock()
+
+	p.cfg.Logger.Info("created bead for Linear issue",
+		"linear_key", issue.Identifier, "bead_id", beadID, "title", issue.Title)
+	return true, nil
+}
+
+// createBeadFromIssue creates a task bead from a Linear issue. Caller must
+// hold p.ingestMu.
+func (p *LinearPoller) createBeadFromIssue(ctx context.Context, issue LinearIssue) (string, error) {
+	team := strings.ToUpper(issue.Team.Key)
+
+	// Map the team to a boat project, falling back to the team key.
+	project, ok := p.cfg.ProjectMap[team]
+	if !ok {
+		project = strings.ToLower(team)
+	}
+
+	labels := []string{
+		"source:linear",
+		"linear:" + issue.Identifier,
+		"project:" + project,
+	}
+	for _, l := range issue.Labels.Nodes {
+		labels = append(labels, "linear-label:"+l.Name)
+	}
+
+	fields := map[string]string{
+		"linear_id":    issue.ID,
+		"linear_key":   issue.Identifier,
+		"linear_team":  team,
+		"linear_url":   issue.URL,
+		"linear_state": issue.State.Name,
+	}
+	if issue.Creator != nil {
+		fields["linear_creator"] = issue.Creator.Name
+	}
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("marshal fields: %w", err)
+	}
+
+	beadID, err := p.daemon.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title:       fmt.Sprintf("[%s] %s", issue.Identifier, issue.Title),
+		Type:        "task",
+		Description: issue.Description,
+		Labels:      labels,
+		Priority:    linearPriority(issue.Priority),
+		CreatedBy:   "linear-bridge",
+		Fields:      fieldsJSON,
+	})
+	if err != nil {
+		return "", fmt.Errorf("create bead: %w", err)
+	}
+	return beadID, nil
+}
+
+// linearPriority maps Linear's priority (1 urgent .. 4 low, 0 none) to a bead
+// priority, defaulting to medium (2).
+func linearPriority(p int) int {
+	switch p {
+	case 1:
+		return 0
+	case 2:
+		return 1
+	case 4:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// TrackedBead returns the bead ID tracked for a Linear issue identifier.
+func (p *LinearPoller) TrackedBead(key string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id, ok := p.tracked[key]
+	return id, ok
+}
+
+// TrackedCount returns the number of tracked Linear issues.
+func (p *LinearPoller) TrackedCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.tracked)
+}
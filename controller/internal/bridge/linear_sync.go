@@ -0,0 +1,133 @@
+// Package bridge provides the Linear sync-back watcher.
+//
+// LinearSync subscribes to kbeads SSE bead updated/closed events for beads
+// imported by LinearPoller (those with a linear_id field) and reports back to
+// Linear: merge request links are attached to the issue with a comment, and
+// closing the bead comments and moves the issue to its team's completed
+// workflow state.
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// LinearSyncConfig holds configuration for the LinearSync watcher.
+type LinearSyncConfig struct {
+	Linear             *LinearClient
+	Logger             *slog.Logger
+	DisableTransitions bool // comment on closure but leave the issue's state alone
+}
+
+// LinearSync watches bead SSE events and syncs MR links and closure to Linear.
+type LinearSync struct {
+	linear             *LinearClient
+	logger             *slog.Logger
+	disableTransitions bool
+
+	mu   sync.Mutex
+	seen map[string]time.Time // dedup key → last sync time
+}
+
+// NewLinearSync creates a new Linear sync-back watcher.
+func NewLinearSync(cfg LinearSyncConfig) *LinearSync {
+	return &LinearSync{
+		linear:             cfg.Linear,
+		logger:             cfg.Logger,
+		disableTransitions: cfg.DisableTransitions,
+		seen:               make(map[string]time.Time),
+	}
+}
+
+// RegisterHandlers registers SSE event handlers on the given stream for
+// bead updated and closed events.
+func (s *LinearSync) RegisterHandlers(stream *SSEStream) {
+	stream.On("beads.bead.updated", s.handleUpdated)
+	stream.On("beads.bead.closed", s.handleClosed)
+	s.logger.Info("Linear sync watcher registered SSE handlers",
+		"topics", []string{"beads.bead.updated", "beads.bead.closed"})
+}
+
+func (s *LinearSync) handleUpdated(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	issueID := bead.Fields["linear_id"]
+	mrURL := bead.Fields["mr_url"]
+	if issueID == "" || mrURL == "" {
+		return
+	}
+	if s.isDuplicate("mr:" + bead.ID + ":" + mrURL) {
+		return
+	}
+	key := bead.Fields["linear_key"]
+
+	s.logger.Info("syncing MR link to Linear",
+		"bead", bead.ID, "linear_key", key, "mr_url", mrURL)
+
+	if err := s.linear.AttachLink(ctx, issueID, mrURL, "Merge Request: "+bead.Title); err != nil {
+		s.logger.Error("failed to attach MR link to Linear issue",
+			"linear_key", key, "mr_url", mrURL, "error", err)
+		return
+	}
+	comment := "Automated MR created: " + mrURL
+	if summary := bead.Fields["mr_diff_summary"]; summary != "" {
+		comment += "\n\n" + summary
+	}
+	if err := s.linear.AddComment(ctx, issueID, comment); err != nil {
+		s.logger.Error("failed to add Linear comment for MR",
+			"linear_key", key, "error", err)
+	}
+}
+
+func (s *LinearSync) handleClosed(ctx context.Context, data []byte) {
+	bead := ParseBeadEvent(data)
+	if bead == nil {
+		return
+	}
+	issueID := bead.Fields["linear_id"]
+	if issueID == "" || s.isDuplicate("close:"+bead.ID) {
+		return
+	}
+	key := bead.Fields["linear_key"]
+
+	s.logger.Info("syncing bead closure to Linear", "bead", bead.ID, "linear_key", key)
+
+	comment := "Task bead closed in beads system (bead " + bead.ID + ")."
+	if mrURL := bead.Fields["mr_url"]; mrURL != "" {
+		comment += " MR: " + mrURL
+	}
+	if err := s.linear.AddComment(ctx, issueID, comment); err != nil {
+		s.logger.Error("failed to add Linear closing comment",
+			"linear_key", key, "error", err)
+	}
+
+	if s.disableTransitions {
+		return
+	}
+	if err := s.linear.CompleteIssue(ctx, issueID, bead.Fields["linear_team"]); err != nil {
+		s.logger.Error("failed to complete Linear issue",
+			"linear_key", key, "error", err)
+	}
+}
+
+// isDuplicate returns true if the key was seen within the syncTTL window.
+// If not, records the key and returns false.
+func (s *LinearSync) isDuplicate(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, t := range s.seen {
+		if now.Sub(t) > syncTTL {
+			delete(s.seen, k)
+		}
+	}
+	if t, ok := s.seen[key]; ok && now.Sub(t) < syncTTL {
+		return true
+	}
+	s.seen[key] = now
+	return false
+}
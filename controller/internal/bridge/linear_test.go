@@ -0,0 +1,181 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// fakeLinear is a GraphQL server that answers by operation name and records
+// each request's variables.
+type fakeLinear struct {
+	mu     sync.Mutex
+	issues map[string][]LinearIssue // team → issues (served one per page)
+	calls  []string                 // "Op vars-json"
+}
+
+func newFakeLinear(t *testing.T) (*fakeLinear, *LinearClient) {
+	t.Helper()
+	f := &fakeLinear{issues: make(map[string][]LinearIssue)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		op := strings.Fields(req.Query)[1]
+		op, _, _ = strings.Cut(op, "(")
+		vars, _ := json.Marshal(req.Variables)
+		f.mu.Lock()
+		f.calls = append(f.calls, op+" "+string(vars))
+		f.mu.Unlock()
+
+		switch op {
+		case "Issues":
+			page := 0
+			if after, ok := req.Variables["after"].(string); ok {
+				page = int(after[0] - '0')
+			}
+			all := f.issues[req.Variables["team"].(string)]
+			var nodes []LinearIssue
+			if page < len(all) {
+				nodes = all[page : page+1]
+			}
+			writeJSON(w, map[string]any{"data": map[string]any{"issues": map[string]any{
+				"nodes":    nodes,
+				"pageInfo": map[string]any{"hasNextPage": page+1 < len(all), "endCursor": string(rune('0' + page + 1))},
+			}}})
+		case "Done":
+			writeJSON(w, map[string]any{"data": map[string]any{"workflowStates": map[string]any{
+				"nodes": []map[string]string{{"id": "state-done", "name": "Done"}},
+			}}})
+		case "Comment":
+			writeJSON(w, map[string]any{"data": map[string]any{"commentCreate": map[string]bool{"success": true}}})
+		case "Attach":
+			writeJSON(w, map[string]any{"data": map[string]any{"attachmentLinkURL": map[string]bool{"success": true}}})
+		case "Complete":
+			writeJSON(w, map[string]any{"data": map[string]any{"issueUpdate": map[string]bool{"success": true}}})
+		default:
+			writeJSON(w, map[string]any{"errors": []map[string]string{{"message": "unknown operation " + op}}})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return f, NewLinearClient(LinearClientConfig{APIKey: "lin_api_test", URL: srv.URL, Logger: slog.Default()})
+}
+
+func (f *fakeLinear) getCalls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func linearTestIssue(id, key, title string, priority int) LinearIssue {
+	issue := LinearIssue{ID: id, Identifier: key, Title: title, URL: "https://linear.app/acme/issue/" + key, Priority: priority}
+	issue.State.Name = "Todo"
+	issue.Team.Key, _, _ = strings.Cut(key, "-")
+	return issue
+}
+
+func TestLinearClient_ListIssuesPaginates(t *testing.T) {
+	f, client := newFakeLinear(t)
+	f.issues["ENG"] = []LinearIssue{
+		linearTestIssue("u1", "ENG-1", "First", 1),
+		linearTestIssue("u2", "ENG-2", "Second", 3),
+	}
+	issues, err := client.ListIssues(context.Background(), "ENG", []string{"Todo"})
+	if err != nil {
+		t.Fatalf("ListIssues: %v", err)
+	}
+	if len(issues) != 2 || issues[1].Identifier != "ENG-2" {
+		t.Fatalf("issues = %+v", issues)
+	}
+	if calls := f.getCalls(); len(calls) != 2 || !strings.Contains(calls[0], `"states":["Todo"]`) {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestLinearClient_GraphQLError(t *testing.T) {
+	_, client := newFakeLinear(t)
+	err := client.graphql(context.Background(), "query Bogus { x }", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "unknown operation Bogus") {
+		t.Fatalf("err = %v, want GraphQL error", err)
+	}
+}
+
+func TestLinearPoller_IngestsWithProjectMap(t *testing.T) {
+	f, client := newFakeLinear(t)
+	issue := linearTestIssue("u1", "ENG-7", "Fix login", 1)
+	issue.Labels.Nodes = append(issue.Labels.Nodes, struct {
+		Name string `json:"name"`
+	}{Name: "bug"})
+	f.issues["ENG"] = []LinearIssue{issue}
+	f.issues["OPS"] = []LinearIssue{linearTestIssue("u2", "OPS-1", "Rotate certs", 0)}
+
+	daemon := newMockJiraDaemon()
+	daemon.beads["bd-existing"] = &beadsapi.BeadDetail{ID: "bd-existing", Type: "task", Fields: map[string]string{"linear_key": "OPS-1"}}
+	poller := NewLinearPoller(client, daemon, LinearPollerConfig{
+		Teams:      []string{"ENG", "OPS"},
+		ProjectMap: map[string]string{"ENG": "gasboat"},
+		Logger:     slog.Default(),
+	})
+	poller.poll(context.Background())
+
+	beads := daemon.getBeads()
+	if len(beads) != 2 {
+		t.Fatalf("got %d beads, want existing + ENG-7", len(beads))
+	}
+	bead := beads["bd-task-1"]
+	if bead == nil || bead.Title != "[ENG-7] Fix login" {
+		t.Fatalf("bead = %+v", bead)
+	}
+	if bead.Fields["linear_id"] != "u1" || bead.Fields["linear_team"] != "ENG" || bead.Fields["_priority"] != "0" {
+		t.Errorf("fields = %v", bead.Fields)
+	}
+	for _, l := range []string{"source:linear", "linear:ENG-7", "project:gasboat", "linear-label:bug"} {
+		if !hasLabel(bead.Labels, l) {
+			t.Errorf("missing label %q in %v", l, bead.Labels)
+		}
+	}
+	if poller.TrackedCount() != 2 {
+		t.Errorf("tracked = %d, want 2", poller.TrackedCount())
+	}
+}
+
+func TestLinearSync_MRLinkAndClosure(t *testing.T) {
+	f, client := newFakeLinear(t)
+	s := NewLinearSync(LinearSyncConfig{Linear: client, Logger: slog.Default()})
+	ctx := context.Background()
+	bead := BeadEvent{ID: "bd-1", Title: "Fix login", Fields: map[string]string{
+		"linear_id": "u1", "linear_key": "ENG-7", "linear_team": "ENG", "mr_url": "https://gl/mr/9",
+	}}
+
+	s.handleUpdated(ctx, marshalSSEBeadPayload(bead))
+	s.handleUpdated(ctx, marshalSSEBeadPayload(bead)) // duplicate
+	s.handleClosed(ctx, marshalSSEBeadPayload(bead))
+	// Beads not imported from Linear are ignored.
+	s.handleClosed(ctx, marshalSSEBeadPayload(BeadEvent{ID: "bd-2"}))
+
+	var ops []string
+	for _, c := range f.getCalls() {
+		op, _, _ := strings.Cut(c, " ")
+		ops = append(ops, op)
+	}
+	want := "Attach Comment Comment Done Complete"
+	if strings.Join(ops, " ") != want {
+		t.Errorf("ops = %v, want %s", ops, want)
+	}
+	if calls := f.getCalls(); !strings.Contains(calls[4], `"state":"state-done"`) {
+		t.Errorf("complete call = %s", calls[4])
+	}
+}
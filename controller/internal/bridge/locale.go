@@ -0,0 +1,222 @@
+// Package bridge provides localized user-facing strings for the slack-bridge.
+//
+// Strings shown in Slack (buttons, modal labels, notification headers) are
+// looked up by key in a per-locale catalog. The locale is chosen per channel
+// (SLACK_CHANNEL_LOCALES) with a workspace default (SLACK_LOCALE); missing
+// translations fall back to English. Text written to beads (rationales,
+// audit fields) stays in English so it remains greppable across teams.
+package bridge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultLocale is used when no locale is configured or a key is missing.
+const defaultLocale = "en"
+
+// localeCatalogs maps locale → message key → format string.
+var localeCatalogs = map[string]map[string]string{
+	"en": {
+		// Decision messages.
+		"decision.needed":        "Decision Needed",
+		"decision.needed_text":   "Decision needed",
+		"decision.choose":        "Choose",
+		"decision.choose_n":      "Choose %d",
+		"decision.other":         "Other",
+		"decision.other_hint":    "None of the above? Provide a custom response and choose the required artifact type.",
+		"decision.other_button":  "Other...",
+		"decision.dismiss":       "Dismiss",
+		"decision.requires":      "Requires: %s",
+		"decision.resolved":      "Resolved",
+		"decision.resolved_text": "Decision resolved: %s",
+		"decision.escalated":     "ESCALATED",
+		"decision.due":           "Due %s",
+		"decision.due_soon":      "Decision due %s",
+		"decision.context_show":  "Show all",
+		"decision.context_more":  "%d more lines",
+
+		// Resolve / custom response modals.
+		"modal.resolve_title":         "Resolve Decision",
+		"modal.confirm":               "Confirm",
+		"modal.submit":                "Submit",
+		"modal.cancel":                "Cancel",
+		"modal.selected":              "Selected: *%s*",
+		"modal.rationale":             "Rationale (optional)",
+		"modal.rationale_placeholder": "Why this choice?",
+		"modal.other_title":           "Custom Response",
+		"modal.response":              "Your Response",
+		"modal.response_placeholder":  "Type your response...",
+		"modal.artifact_type":         "Required Artifact Type",
+		"modal.artifact_type_hint":    "What artifact will you produce?",
+		"modal.artifact_type_choose":  "Choose artifact type...",
+		"modal.artifact_none":         "None (no artifact required)",
+		"modal.context_title":         "Decision Context",
+		"modal.close":                 "Close",
+
+		// Agent and jack notifications.
+		"agent.crashed":      "Agent crashed",
+		"agent.pod_phase":    "Pod phase",
+		"agent.pod":          "Pod",
+		"jack.raised":        "Jack Raised",
+		"jack.lowered":       "Jack Lowered",
+		"jack.expired":       "Jack Expired",
+		"jack.target":        "Target",
+		"jack.agent":         "Agent",
+		"jack.review_revert": "Review revert plan and close with",
+		"jack.raised_text":   "Jack raised",
+		"jack.lowered_text":  "Jack lowered",
+		"jack.expired_text":  "Jack expired",
+		"jack.duration":      "Raised for",
+		"jack.outcome":       "Outcome",
+		"jack.bead":          "Bead",
+		"jack.terminal":      "Agent terminal",
+		"jack.dashboard":     "Dashboard",
+
+		// Decision thread summaries.
+		"thread.summary_title": "Decision resolved",
+		"thread.summary_stats": "%d replies · %d participants",
+		"thread.view":          "View thread",
+		"thread.compacted":     "%d resolved decisions compacted",
+		"thread.more":          "...and %d more",
+	},
+	"es": {
+		"decision.needed":        "Decisión requerida",
+		"decision.needed_text":   "Decisión requerida",
+		"decision.choose":        "Elegir",
+		"decision.choose_n":      "Elegir %d",
+		"decision.other":         "Otra",
+		"decision.other_hint":    "¿Ninguna de las anteriores? Escribe una respuesta propia y elige el tipo de artefacto requerido.",
+		"decision.other_button":  "Otra...",
+		"decision.dismiss":       "Descartar",
+		"decision.requires":      "Requiere: %s",
+		"decision.resolved":      "Resuelta",
+		"decision.resolved_text": "Decisión resuelta: %s",
+		"decision.escalated":     "ESCALADA",
+		"decision.due":           "Vence %s",
+		"decision.due_soon":      "La decisión vence %s",
+		"decision.context_show":  "Ver todo",
+		"decision.context_more":  "%d líneas más",
+
+		"modal.resolve_title":         "Resolver decisión",
+		"modal.confirm":               "Confirmar",
+		"modal.submit":                "Enviar",
+		"modal.cancel":                "Cancelar",
+		"modal.selected":              "Seleccionado: *%s*",
+		"modal.rationale":             "Justificación (opcional)",
+		"modal.rationale_placeholder": "¿Por qué esta opción?",
+		"modal.other_title":           "Respuesta propia",
+		"modal.response":              "Tu respuesta",
+		"modal.response_placeholder":  "Escribe tu respuesta...",
+		"modal.artifact_type":         "Tipo de artefacto requerido",
+		"modal.artifact_type_hint":    "¿Qué artefacto vas a producir?",
+		"modal.artifact_type_choose":  "Elige el tipo de artefacto...",
+		"modal.artifact_none":         "Ninguno (sin artefacto)",
+		"modal.context_title":         "Contexto",
+		"modal.close":                 "Cerrar",
+
+		"agent.crashed":      "Agente caído",
+		"agent.pod_phase":    "Fase del pod",
+		"agent.pod":          "Pod",
+		"jack.raised":        "Jack activado",
+		"jack.lowered":       "Jack desactivado",
+		"jack.expired":       "Jack expirado",
+		"jack.target":        "Objetivo",
+		"jack.agent":         "Agente",
+		"jack.review_revert": "Revisa el plan de reversión y ciérralo con",
+		"jack.raised_text":   "Jack activado",
+		"jack.lowered_text":  "Jack desactivado",
+		"jack.expired_text":  "Jack expirado",
+		"jack.duration":      "Activo durante",
+		"jack.outcome":       "Resultado",
+		"jack.bead":          "Bead",
+		"jack.terminal":      "Terminal del agente",
+		"jack.dashboard":     "Panel",
+
+		"thread.summary_title": "Decisión resuelta",
+		"thread.summary_stats": "%d respuestas · %d participantes",
+		"thread.view":          "Ver hilo",
+		"thread.compacted":     "%d decisiones resueltas compactadas",
+		"thread.more":          "...y %d más",
+	},
+}
+
+// SupportedLocales returns the locales with a message catalog.
+func SupportedLocales() []string {
+	locales := make([]string, 0, len(localeCatalogs))
+	for l := range localeCatalogs {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Tr returns the message for key in locale, formatted with args. Unknown
+// locales and missing keys fall back to English, then to the key itself.
+func Tr(locale, key string, args ...any) string {
+	msg, ok := localeCatalogs[normalizeLocale(locale)][key]
+	if !ok {
+		msg, ok = localeCatalogs[defaultLocale][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// normalizeLocale maps "es-MX" / "es_MX" / "ES" to a catalog key ("es").
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if _, ok := localeCatalogs[locale]; ok {
+		return locale
+	}
+	if base, _, ok := strings.Cut(strings.ReplaceAll(locale, "_", "-"), "-"); ok {
+		return base
+	}
+	return locale
+}
+
+// Localizer selects the locale for a Slack channel.
+type Localizer struct {
+	workspace string            // default locale for all channels
+	channels  map[string]string // channel ID → locale override
+}
+
+// NewLocalizer creates a localizer with a workspace default and optional
+// per-channel overrides. An empty default means English.
+func NewLocalizer(workspace string, channels map[string]string) *Localizer {
+	if workspace == "" {
+		workspace = defaultLocale
+	}
+	return &Localizer{workspace: normalizeLocale(workspace), channels: channels}
+}
+
+// LocaleFor returns the locale for a channel. A nil Localizer returns English.
+func (l *Localizer) LocaleFor(channelID string) string {
+	if l == nil {
+		return defaultLocale
+	}
+	if loc, ok := l.channels[channelID]; ok && loc != "" {
+		return normalizeLocale(loc)
+	}
+	return l.workspace
+}
+
+// ParseChannelLocales parses "channelID=locale" pairs separated by commas,
+// e.g. "C0123=es,C0456=en".
+func ParseChannelLocales(s string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		channel, locale, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		channel, locale = strings.TrimSpace(channel), strings.TrimSpace(locale)
+		if !ok || channel == "" || locale == "" {
+			continue
+		}
+		m[channel] = locale
+	}
+	return m
+}
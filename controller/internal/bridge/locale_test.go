@@ -0,0 +1,53 @@
+package bridge
+
+import "testing"
+
+func TestTr_FallsBackToEnglishThenKey(t *testing.T) {
+	if got := Tr("es", "decision.dismiss"); got != "Descartar" {
+		t.Errorf("es dismiss = %q", got)
+	}
+	if got := Tr("es-MX", "decision.choose_n", 2); got != "Elegir 2" {
+		t.Errorf("es-MX choose_n = %q", got)
+	}
+	if got := Tr("fr", "decision.dismiss"); got != "Dismiss" {
+		t.Errorf("unknown locale should fall back to English, got %q", got)
+	}
+	if got := Tr("en", "no.such.key"); got != "no.such.key" {
+		t.Errorf("missing key should return key, got %q", got)
+	}
+}
+
+func TestLocaleCatalogs_Complete(t *testing.T) {
+	for _, locale := range SupportedLocales() {
+		for key := range localeCatalogs[defaultLocale] {
+			if _, ok := localeCatalogs[locale][key]; !ok {
+				t.Errorf("locale %q missing key %q", locale, key)
+			}
+		}
+	}
+}
+
+func TestLocalizer_LocaleFor(t *testing.T) {
+	var nilLoc *Localizer
+	if got := nilLoc.LocaleFor("C1"); got != "en" {
+		t.Errorf("nil localizer = %q", got)
+	}
+
+	l := NewLocalizer("ES", ParseChannelLocales("C1=en, C2 = es_AR ,bad"))
+	tests := map[string]string{"C1": "en", "C2": "es", "C3": "es"}
+	for channel, want := range tests {
+		if got := l.LocaleFor(channel); got != want {
+			t.Errorf("LocaleFor(%s) = %q, want %q", channel, got, want)
+		}
+	}
+}
+
+func TestNotificationTemplates_Localized(t *testing.T) {
+	view := notificationView(BeadEvent{ID: "j-1", Fields: map[string]string{"target": "deploy/x"}})
+	view.Locale = "es"
+	var n *NotificationTemplates
+	want := ":white_check_mark: *Jack desactivado: j-1*\nObjetivo: `deploy/x`"
+	if got := n.Render(tmplJackOff, view); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
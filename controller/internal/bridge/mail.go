@@ -3,38 +3,41 @@
 // Mail subscribes to kbeads SSE event stream for bead create events,
 // filters for type=mail beads, and nudges agents when a message
 // requires immediate attention (delivery:interrupt label or high priority).
+// Nudges go through the Nudger, so a burst of mail reaches an agent as one
+// nudge per cooldown.
 package bridge
 
 import (
 	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"strings"
-	"time"
-
-	"gasboat/controller/internal/beadsapi"
 )
 
 // MailConfig holds configuration for the Mail watcher.
 type MailConfig struct {
 	Daemon BeadClient
+	Nudger *Nudger // nil = a private Nudger with the default cooldown
 	Logger *slog.Logger
 }
 
 // Mail watches the kbeads SSE event stream for mail bead lifecycle events.
 type Mail struct {
-	daemon     BeadClient
-	logger     *slog.Logger
-	httpClient *http.Client // reused for nudge requests
+	daemon BeadClient
+	nudger *Nudger
+	logger *slog.Logger
 }
 
 // NewMail creates a new mail lifecycle watcher.
 func NewMail(cfg MailConfig) *Mail {
+	nudger := cfg.Nudger
+	if nudger == nil {
+		nudger = NewNudger(NudgerConfig{Daemon: cfg.Daemon, Logger: cfg.Logger})
+	}
 	return &Mail{
-		daemon:     cfg.Daemon,
-		logger:     cfg.Logger,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		daemon: cfg.Daemon,
+		nudger: nudger,
+		logger: cfg.Logger,
 	}
 }
 
@@ -83,7 +86,7 @@ func (m *Mail) shouldNudge(bead BeadEvent) bool {
 	return bead.Priority <= 1
 }
 
-// nudgeAgent looks up the agent's coop_url and POSTs a nudge.
+// nudgeAgent nudges the mail's assignee to read it.
 func (m *Mail) nudgeAgent(ctx context.Context, bead BeadEvent) {
 	agentName := bead.Assignee
 	if agentName == "" {
@@ -91,20 +94,6 @@ func (m *Mail) nudgeAgent(ctx context.Context, bead BeadEvent) {
 		return
 	}
 
-	agentBead, err := m.daemon.FindAgentBead(ctx, agentName)
-	if err != nil {
-		m.logger.Error("failed to get agent bead for mail nudge",
-			"agent", agentName, "mail", bead.ID, "error", err)
-		return
-	}
-
-	coopURL := beadsapi.ParseNotes(agentBead.Notes)["coop_url"]
-	if coopURL == "" {
-		m.logger.Warn("agent bead has no coop_url, cannot nudge",
-			"agent", agentName, "mail", bead.ID)
-		return
-	}
-
 	// Build sender info from labels.
 	sender := "unknown"
 	for _, label := range bead.Labels {
@@ -116,12 +105,13 @@ func (m *Mail) nudgeAgent(ctx context.Context, bead BeadEvent) {
 
 	message := fmt.Sprintf("New mail from %s: %s — run 'kd show %s' to read", sender, bead.Title, bead.ID)
 
-	if err := nudgeCoop(ctx, m.httpClient, coopURL, message); err != nil {
+	queued, err := m.nudger.Nudge(ctx, agentName, Nudge{Message: message, BeadID: bead.ID})
+	if err != nil {
 		m.logger.Error("failed to nudge agent for mail",
-			"agent", agentName, "coop_url", coopURL, "error", err)
+			"agent", agentName, "mail", bead.ID, "error", err)
 		return
 	}
 
 	m.logger.Info("nudged agent for urgent mail",
-		"agent", agentName, "mail", bead.ID, "sender", sender)
+		"agent", agentName, "mail", bead.ID, "sender", sender, "queued", queued)
 }
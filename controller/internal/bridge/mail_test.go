@@ -51,9 +51,9 @@ func TestMail_HandleCreated_InterruptLabel_Nudges(t *testing.T) {
 	}
 
 	m := &Mail{
-		daemon:     daemon,
-		logger:     slog.Default(),
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		daemon: daemon,
+		logger: slog.Default(),
+		nudger: NewNudger(NudgerConfig{Daemon: daemon, Logger: slog.Default()}),
 	}
 
 	event := marshalSSEBeadPayload(BeadEvent{
@@ -103,9 +103,9 @@ func TestMail_HandleCreated_HighPriority_Nudges(t *testing.T) {
 	}
 
 	m := &Mail{
-		daemon:     daemon,
-		logger:     slog.Default(),
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		daemon: daemon,
+		logger: slog.Default(),
+		nudger: NewNudger(NudgerConfig{Daemon: daemon, Logger: slog.Default()}),
 	}
 
 	// Priority 1 (high) should nudge even without interrupt label.
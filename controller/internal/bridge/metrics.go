@@ -0,0 +1,389 @@
+// Package bridge provides delivery metrics for the slack-bridge.
+//
+// Metrics counts notifications posted and failed per type, times Slack Web
+// API calls (and counts rate-limit responses), measures SSE lag between a
+// bead change and its processing, and counts dedup suppressions, SSE stream
+// reconnects and Socket Mode reconnects. It renders the Prometheus text exposition format directly
+// so the bridge needs no client library; the handler is served on the
+// existing /metrics endpoint alongside the outbox gauges.
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/sse"
+)
+
+// Default histogram buckets, in seconds.
+var (
+	slackLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	sseLagBuckets       = []float64{0.1, 0.5, 1, 5, 15, 30, 60, 300, 900}
+)
+
+// MetricsWriter writes additional metric families in exposition format.
+// *Outbox satisfies it.
+type MetricsWriter interface {
+	WriteMetrics(w io.Writer)
+}
+
+// Metrics collects slack-bridge delivery metrics. A nil *Metrics records
+// nothing, so components can be built without instrumentation.
+type Metrics struct {
+	mu       sync.Mutex
+	counters []*counterVec
+	hists    []*histogramVec
+
+	notifications    *counterVec
+	slackCalls       *histogramVec
+	slackRateLimited *counterVec
+	sseLag           *histogramVec
+	dedupSuppressed  *counterVec
+	socketReconnects *counterVec
+	unknownFields    *counterVec
+	sseStats         func() sse.Stats // set by WatchSSE
+}
+
+// NewMetrics creates an empty metrics registry.
+func NewMetrics() *Metrics {
+	m := &Metrics{}
+	m.notifications = m.counter("slack_bridge_notifications_total",
+		"Slack notifications by type and result (posted or failed).", "type", "result")
+	m.slackCalls = m.histogram("slack_bridge_slack_api_duration_seconds",
+		"Slack Web API call latency by method.", slackLatencyBuckets, "method")
+	m.slackRateLimited = m.counter("slack_bridge_slack_rate_limited_total",
+		"Slack Web API calls rejected with HTTP 429, by method.", "method")
+	m.sseLag = m.histogram("slack_bridge_sse_lag_seconds",
+		"Delay between a bead change and the bridge processing its SSE event.", sseLagBuckets, "topic")
+	m.dedupSuppressed = m.counter("slack_bridge_dedup_suppressed_total",
+		"SSE events dropped as duplicates, by topic.", "topic")
+	m.socketReconnects = m.counter("slack_bridge_socket_reconnects_total",
+		"Slack Socket Mode reconnections after the first connection.")
+	m.unknownFields = m.counter("slack_bridge_sse_unknown_fields_total",
+		"Fields in SSE bead events unknown to the event schema, by topic and field.", "topic", "field")
+	return m
+}
+
+// ObserveNotification records a notification attempt of the given type.
+func (m *Metrics) ObserveNotification(kind string, err error) {
+	if m == nil {
+		return
+	}
+	result := "posted"
+	if err != nil {
+		result = "failed"
+	}
+	m.notifications.add(1, kind, result)
+}
+
+// ObserveSlackCall records the latency and status of a Slack Web API call.
+func (m *Metrics) ObserveSlackCall(method string, d time.Duration, status int) {
+	if m == nil {
+		return
+	}
+	m.slackCalls.observe(d.Seconds(), method)
+	if status == http.StatusTooManyRequests {
+		m.slackRateLimited.add(1, method)
+	}
+}
+
+// ObserveSSELag records how long after the bead change an event was handled.
+func (m *Metrics) ObserveSSELag(topic string, lag time.Duration) {
+	if m == nil || lag < 0 {
+		return
+	}
+	m.sseLag.observe(lag.Seconds(), topic)
+}
+
+// IncDedupSuppressed counts an SSE event dropped as a duplicate.
+func (m *Metrics) IncDedupSuppressed(topic string) {
+	if m == nil {
+		return
+	}
+	m.dedupSuppressed.add(1, topic)
+}
+
+// IncUnknownEventField counts a field in an SSE bead event that the event
+// schema does not know, a sign the daemon's payload has changed.
+func (m *Metrics) IncUnknownEventField(topic, field string) {
+	if m == nil {
+		return
+	}
+	m.unknownFields.add(1, topic, field)
+}
+
+// WatchSSE exports the counters of the SSE stream whose stats returns.
+func (m *Metrics) WatchSSE(stats func() sse.Stats) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.sseStats = stats
+	m.mu.Unlock()
+}
+
+// IncSocketReconnect counts a Socket Mode reconnection.
+func (m *Metrics) IncSocketReconnect() {
+	if m == nil {
+		return
+	}
+	m.socketReconnects.add(1)
+}
+
+// HTTPClient returns an HTTP client that times every request through base's
+// transport and records it as a Slack API call. Pass it to slack.New via
+// slack.OptionHTTPClient.
+func (m *Metrics) HTTPClient(base *http.Client) *http.Client {
+	if base == nil {
+		base = &http.Client{}
+	}
+	if m == nil {
+		return base
+	}
+	rt := base.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	c := *base
+	c.Transport = &slackMetricsTransport{next: rt, metrics: m}
+	return &c
+}
+
+// slackMetricsTransport records Slack Web API call latency and status.
+type slackMetricsTransport struct {
+	next    http.RoundTripper
+	metrics *Metrics
+}
+
+func (t *slackMetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	t.metrics.ObserveSlackCall(slackAPIMethod(req.URL.Path), time.Since(start), status)
+	return resp, err
+}
+
+// slackAPIMethod extracts the Web API method from a request path,
+// e.g. "/api/chat.postMessage" → "chat.postMessage".
+func slackAPIMethod(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		path = path[i+1:]
+	}
+	if path == "" {
+		return "unknown"
+	}
+	return path
+}
+
+// Handler serves all metrics, followed by any extra writers, in Prometheus
+// text exposition format.
+func (m *Metrics) Handler(extra ...MetricsWriter) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		var b strings.Builder
+		m.WriteMetrics(&b)
+		for _, x := range extra {
+			x.WriteMetrics(&b)
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(b.String()))
+	}
+}
+
+// WriteMetrics writes every registered metric family.
+func (m *Metrics) WriteMetrics(w io.Writer) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	counters, hists, sseStats := m.counters, m.hists, m.sseStats
+	m.mu.Unlock()
+	for _, c := range counters {
+		c.write(w)
+	}
+	for _, h := range hists {
+		h.write(w)
+	}
+	if sseStats != nil {
+		writeSSEStats(w, sseStats())
+	}
+}
+
+// writeSSEStats writes the SSE stream counters.
+func writeSSEStats(w io.Writer, s sse.Stats) {
+	for _, c := range []struct {
+		name, help string
+		value      int64
+	}{
+		{"slack_bridge_sse_connects_total", "SSE stream connections established.", s.Connects},
+		{"slack_bridge_sse_disconnects_total", "SSE stream connections or attempts that failed.", s.Disconnects},
+		{"slack_bridge_sse_events_total", "SSE events delivered to handlers.", s.Events},
+		{"slack_bridge_sse_replayed_events_total", "SSE events skipped as already delivered before a reconnect.", s.Duplicates},
+		{"slack_bridge_sse_heartbeat_timeouts_total", "SSE connections dropped after receiving no data or keepalive.", s.HeartbeatTimeouts},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n", c.name, c.help)
+		fmt.Fprintf(w, "# TYPE %s counter\n", c.name)
+		fmt.Fprintf(w, "%s %d\n", c.name, c.value)
+	}
+}
+
+func (m *Metrics) counter(name, help string, labels ...string) *counterVec {
+	c := &counterVec{name: name, help: help, labels: labels, values: make(map[string]*counterSeries)}
+	m.counters = append(m.counters, c)
+	return c
+}
+
+func (m *Metrics) histogram(name, help string, buckets []float64, labels ...string) *histogramVec {
+	h := &histogramVec{name: name, help: help, labels: labels, buckets: buckets, series: make(map[string]*histogramSeries)}
+	m.hists = append(m.hists, h)
+	return h
+}
+
+// counterVec is a counter family keyed by label values.
+type counterVec struct {
+	name, help string
+	labels     []string
+
+	mu     sync.Mutex
+	values map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+func (c *counterVec) add(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.values[key]
+	if !ok {
+		s = &counterSeries{labelValues: labelValues}
+		c.values[key] = s
+	}
+	s.value += v
+}
+
+func (c *counterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if len(c.labels) == 0 && len(c.values) == 0 {
+		fmt.Fprintf(w, "%s 0\n", c.name)
+		return
+	}
+	for _, key := range sortedSeriesKeys(c.values) {
+		s := c.values[key]
+		fmt.Fprintf(w, "%s%s %g\n", c.name, formatLabels(c.labels, s.labelValues, "", ""), s.value)
+	}
+}
+
+// histogramVec is a cumulative histogram family keyed by label values.
+type histogramVec struct {
+	name, help string
+	labels     []string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // per bucket, non-cumulative
+	count       uint64
+	sum         float64
+}
+
+func (h *histogramVec) observe(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	for i, ub := range h.buckets {
+		if v <= ub {
+			s.counts[i]++
+			break
+		}
+	}
+	s.count++
+	s.sum += v
+}
+
+func (h *histogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range sortedSeriesKeys(h.series) {
+		s := h.series[key]
+		var cum uint64
+		for i, ub := range h.buckets {
+			cum += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "le", fmt.Sprintf("%g", ub)), cum)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %g\n", h.name, formatLabels(h.labels, s.labelValues, "", ""), s.sum)
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, s.labelValues, "", ""), s.count)
+	}
+}
+
+// formatLabels renders {k="v",...}, appending extraName=extraValue if set.
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	var parts []string
+	for i, n := range names {
+		if i < len(values) {
+			parts = append(parts, fmt.Sprintf("%s=%q", n, values[i]))
+		}
+	}
+	if extraName != "" {
+		parts = append(parts, fmt.Sprintf("%s=%q", extraName, extraValue))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func sortedSeriesKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// beadEventTime returns the bead's last-change time from an SSE payload, or
+// the zero time if the payload carries no timestamp.
+func beadEventTime(data []byte) time.Time {
+	var wrapper struct {
+		Bead struct {
+			UpdatedAt time.Time `json:"updated_at"`
+			ClosedAt  time.Time `json:"closed_at"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"bead"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return time.Time{}
+	}
+	b := wrapper.Bead
+	for _, t := range []time.Time{b.UpdatedAt, b.ClosedAt, b.CreatedAt} {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
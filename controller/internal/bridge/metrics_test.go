@@ -0,0 +1,133 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/sse"
+
+	"github.com/slack-go/slack"
+)
+
+func scrapeMetrics(t *testing.T, h http.HandlerFunc) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	return rec.Body.String()
+}
+
+func TestMetrics_ExpositionFormat(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveNotification("jack_on", nil)
+	m.ObserveNotification("jack_on", nil)
+	m.ObserveNotification("notify_decision", errors.New("slack down"))
+	m.ObserveSSELag("beads.bead.created", 2*time.Second)
+	m.IncDedupSuppressed("beads.bead.created")
+	m.IncSocketReconnect()
+
+	out := scrapeMetrics(t, m.Handler())
+	for _, want := range []string{
+		"# TYPE slack_bridge_notifications_total counter",
+		`slack_bridge_notifications_total{type="jack_on",result="posted"} 2`,
+		`slack_bridge_notifications_total{type="notify_decision",result="failed"} 1`,
+		`slack_bridge_sse_lag_seconds_bucket{topic="beads.bead.created",le="1"} 0`,
+		`slack_bridge_sse_lag_seconds_bucket{topic="beads.bead.created",le="5"} 1`,
+		`slack_bridge_sse_lag_seconds_bucket{topic="beads.bead.created",le="+Inf"} 1`,
+		`slack_bridge_sse_lag_seconds_count{topic="beads.bead.created"} 1`,
+		`slack_bridge_dedup_suppressed_total{topic="beads.bead.created"} 1`,
+		"slack_bridge_socket_reconnects_total 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetrics_SlackHTTPClientRecordsLatencyAndRateLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "chat.update") {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"ok":false,"error":"ratelimited"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"ts":"1.1","channel":"C1"}`))
+	}))
+	defer srv.Close()
+
+	m := NewMetrics()
+	api := slack.New("xoxb-test", slack.OptionAPIURL(srv.URL+"/"), slack.OptionHTTPClient(m.HTTPClient(nil)))
+	_, _, _ = api.PostMessage("C1", slack.MsgOptionText("hi", false))
+	_, _, _, _ = api.UpdateMessage("C1", "1.1", slack.MsgOptionText("hi", false))
+
+	out := scrapeMetrics(t, m.Handler())
+	for _, want := range []string{
+		`slack_bridge_slack_api_duration_seconds_count{method="chat.postMessage"} 1`,
+		`slack_bridge_slack_api_duration_seconds_count{method="chat.update"} 1`,
+		`slack_bridge_slack_rate_limited_total{method="chat.update"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `slack_bridge_slack_rate_limited_total{method="chat.postMessage"}`) {
+		t.Error("postMessage should not be counted as rate limited")
+	}
+}
+
+func TestMetrics_HandlerIncludesOutboxAndNilSafe(t *testing.T) {
+	var nilMetrics *Metrics
+	nilMetrics.ObserveNotification("jack_on", nil)
+	nilMetrics.IncSocketReconnect()
+	if c := nilMetrics.HTTPClient(nil); c == nil {
+		t.Fatal("nil Metrics should still return a client")
+	}
+
+	outbox := NewOutbox(OutboxConfig{Notifier: &mockNotifier{}, State: newMuteTestState(t), Metrics: NewMetrics(), Logger: slog.Default()})
+	_ = outbox.NotifyDecision(context.Background(), BeadEvent{ID: "dec-1", Assignee: "bot"})
+
+	out := scrapeMetrics(t, outbox.metrics.Handler(outbox))
+	for _, want := range []string{
+		`slack_bridge_notifications_total{type="notify_decision",result="posted"} 1`,
+		"slack_bridge_outbox_depth 0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestBeadEventTime(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	data := []byte(`{"bead":{"id":"b1","created_at":"2026-01-01T00:00:00Z","updated_at":"` + ts.Format(time.RFC3339) + `"}}`)
+	if got := beadEventTime(data); !got.Equal(ts) {
+		t.Errorf("expected updated_at %s, got %s", ts, got)
+	}
+	if got := beadEventTime([]byte(`{"bead":{"id":"b1"}}`)); !got.IsZero() {
+		t.Errorf("expected zero time, got %s", got)
+	}
+}
+
+func TestMetrics_WatchSSEExportsStreamStats(t *testing.T) {
+	m := NewMetrics()
+	m.WatchSSE(func() sse.Stats { return sse.Stats{Connects: 3, Duplicates: 2, HeartbeatTimeouts: 1} })
+
+	out := scrapeMetrics(t, m.Handler())
+	for _, want := range []string{
+		"# TYPE slack_bridge_sse_connects_total counter",
+		"slack_bridge_sse_connects_total 3",
+		"slack_bridge_sse_replayed_events_total 2",
+		"slack_bridge_sse_heartbeat_timeouts_total 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
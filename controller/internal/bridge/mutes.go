@@ -0,0 +1,173 @@
+// Package bridge provides notification mute rules for the slack-bridge.
+//
+// A mute rule silences Slack notifications for one agent or a whole project,
+// optionally for a single kind (decisions, agents, jacks), until it expires.
+// Rules are kept in StateData so they survive restarts and are shared between
+// replicas. The Decisions, Agents, and Jacks watchers check them before
+// notifying. Every mute, unmute, and expiry is recorded in a bounded audit log.
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Mute rule scopes.
+const (
+	MuteScopeAgent   = "agent"
+	MuteScopeProject = "project"
+)
+
+// Mute rule kinds. An empty kind mutes every kind.
+const (
+	MuteKindDecisions = "decisions"
+	MuteKindAgents    = "agents"
+	MuteKindJacks     = "jacks"
+)
+
+// defaultMuteDuration applies when a mute is set without a duration.
+const defaultMuteDuration = 2 * time.Hour
+
+// maxMuteDuration caps how long a single mute can last.
+const maxMuteDuration = 7 * 24 * time.Hour
+
+// MuteRule silences notifications for an agent or project until Until.
+type MuteRule struct {
+	Scope   string    `json:"scope"`          // MuteScopeAgent or MuteScopeProject
+	Target  string    `json:"target"`         // agent name/identity or project name
+	Kind    string    `json:"kind,omitempty"` // notification kind; "" = all
+	Until   time.Time `json:"until"`
+	MutedBy string    `json:"muted_by"` // Slack user ID
+	MutedAt time.Time `json:"muted_at"`
+}
+
+// key identifies the rule in StateData.Mutes; re-muting the same target and
+// kind replaces the earlier rule.
+func (r MuteRule) key() string {
+	return r.Scope + ":" + r.Target + ":" + r.Kind
+}
+
+// describe renders the rule for Slack, e.g. "agent *my-bot* (jacks)".
+func (r MuteRule) describe() string {
+	s := fmt.Sprintf("%s *%s*", r.Scope, r.Target)
+	if r.Kind != "" {
+		s += fmt.Sprintf(" (%s)", r.Kind)
+	}
+	return s
+}
+
+// matches reports whether the rule applies to a notification of kind about
+// the given agent identity and project.
+func (r MuteRule) matches(kind, agent, project string) bool {
+	if r.Kind != "" && r.Kind != kind {
+		return false
+	}
+	switch r.Scope {
+	case MuteScopeAgent:
+		return agent != "" && (r.Target == agent || extractAgentName(r.Target) == extractAgentName(agent))
+	case MuteScopeProject:
+		return project != "" && r.Target == project
+	}
+	return false
+}
+
+// MuteAuditEntry records a change to the mute rules.
+type MuteAuditEntry struct {
+	Action string    `json:"action"` // "mute", "unmute", or "expire"
+	Rule   MuteRule  `json:"rule"`
+	By     string    `json:"by,omitempty"` // Slack user ID; empty for expiry
+	At     time.Time `json:"at"`
+}
+
+// muteAuditMax bounds the audit log kept in state.
+const muteAuditMax = 200
+
+// Mutes evaluates mute rules for the watchers and expires them on schedule.
+// A nil *Mutes mutes nothing.
+type Mutes struct {
+	state    *StateManager
+	logger   *slog.Logger
+	interval time.Duration
+}
+
+// NewMutes creates a mute rule evaluator backed by state.
+func NewMutes(state *StateManager, logger *slog.Logger) *Mutes {
+	return &Mutes{state: state, logger: logger, interval: time.Minute}
+}
+
+// IsMuted reports whether a notification of kind about bead is muted.
+func (m *Mutes) IsMuted(kind string, bead BeadEvent) bool {
+	if m == nil || m.state == nil {
+		return false
+	}
+	agent, project := muteSubject(bead)
+	for _, r := range m.state.ActiveMutes(time.Now()) {
+		if r.matches(kind, agent, project) {
+			m.logger.Debug("notification muted",
+				"kind", kind, "bead", bead.ID, "rule", r.key(), "until", r.Until)
+			return true
+		}
+	}
+	return false
+}
+
+// Run expires mute rules every interval until ctx is cancelled.
+func (m *Mutes) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			expired, err := m.state.ExpireMutes(time.Now())
+			if err != nil {
+				m.logger.Warn("failed to expire mute rules", "error", err)
+			}
+			for _, r := range expired {
+				m.logger.Info("mute expired", "rule", r.key(), "muted_by", r.MutedBy)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// muteSubject returns the agent identity and project a bead notification is
+// about. Agent beads carry the agent in fields or the title; other beads use
+// the assignee. The project comes from the "project" field, a "project:"
+// label, or the first segment of the agent identity.
+func muteSubject(bead BeadEvent) (agent, project string) {
+	agent = bead.Assignee
+	if agent == "" && bead.Type == "agent" {
+		agent = bead.Fields["agent"]
+		if agent == "" {
+			agent = bead.Title
+		}
+	}
+	project = bead.Fields["project"]
+	if project == "" {
+		for _, l := range bead.Labels {
+			if p, ok := strings.CutPrefix(l, "project:"); ok {
+				project = p
+				break
+			}
+		}
+	}
+	if project == "" {
+		project = extractAgentProject(agent)
+	}
+	return agent, project
+}
+
+// parseMuteKind validates a kind argument; "all" and "" mean every kind.
+func parseMuteKind(s string) (string, bool) {
+	switch s {
+	case "", "all":
+		return "", true
+	case MuteKindDecisions, MuteKindAgents, MuteKindJacks:
+		return s, true
+	}
+	return "", false
+}
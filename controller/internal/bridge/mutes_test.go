@@ -0,0 +1,147 @@
+package bridge
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+func newMuteTestState(t *testing.T) *StateManager {
+	t.Helper()
+	state, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("NewStateManager: %v", err)
+	}
+	return state
+}
+
+func TestMutes_IsMutedMatchesAgentProjectAndKind(t *testing.T) {
+	state := newMuteTestState(t)
+	now := time.Now()
+	_ = state.SetMute(MuteRule{Scope: MuteScopeAgent, Target: "test-bot", Until: now.Add(time.Hour), MutedBy: "U1", MutedAt: now})
+	_ = state.SetMute(MuteRule{Scope: MuteScopeProject, Target: "beads", Kind: MuteKindJacks, Until: now.Add(time.Hour), MutedBy: "U2", MutedAt: now})
+	mutes := NewMutes(state, slog.Default())
+
+	tests := []struct {
+		name string
+		kind string
+		bead BeadEvent
+		want bool
+	}{
+		{"agent by full identity", MuteKindDecisions, BeadEvent{Assignee: "gasboat/crew/test-bot"}, true},
+		{"agent bead by field", MuteKindAgents, BeadEvent{Type: "agent", Fields: map[string]string{"agent": "test-bot"}}, true},
+		{"other agent", MuteKindDecisions, BeadEvent{Assignee: "gasboat/crew/other"}, false},
+		{"project jacks", MuteKindJacks, BeadEvent{Assignee: "beads/crew/other"}, true},
+		{"project other kind", MuteKindDecisions, BeadEvent{Assignee: "beads/crew/other"}, false},
+		{"project via field", MuteKindJacks, BeadEvent{Fields: map[string]string{"project": "beads"}}, true},
+		{"project via label", MuteKindJacks, BeadEvent{Labels: []string{"project:beads"}}, true},
+	}
+	for _, tt := range tests {
+		if got := mutes.IsMuted(tt.kind, tt.bead); got != tt.want {
+			t.Errorf("%s: IsMuted = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	var nilMutes *Mutes
+	if nilMutes.IsMuted(MuteKindDecisions, BeadEvent{Assignee: "test-bot"}) {
+		t.Error("nil Mutes should mute nothing")
+	}
+}
+
+func TestStateManager_MuteExpiryAndAudit(t *testing.T) {
+	state := newMuteTestState(t)
+	now := time.Now()
+	_ = state.SetMute(MuteRule{Scope: MuteScopeAgent, Target: "a", Until: now.Add(-time.Minute), MutedBy: "U1", MutedAt: now.Add(-time.Hour)})
+	_ = state.SetMute(MuteRule{Scope: MuteScopeAgent, Target: "b", Until: now.Add(time.Hour), MutedBy: "U1", MutedAt: now})
+
+	if active := state.ActiveMutes(now); len(active) != 1 || active[0].Target != "b" {
+		t.Fatalf("expected only b active, got %+v", active)
+	}
+	expired, err := state.ExpireMutes(now)
+	if err != nil || len(expired) != 1 || expired[0].Target != "a" {
+		t.Fatalf("expected a expired, got %+v (err %v)", expired, err)
+	}
+	removed, err := state.RemoveMute(MuteScopeAgent, "b", "", "U2")
+	if err != nil || !removed {
+		t.Fatalf("expected b removed, got %v (err %v)", removed, err)
+	}
+
+	var actions []string
+	for _, e := range state.MuteAuditLog() {
+		actions = append(actions, e.Action+":"+e.Rule.Target+":"+e.By)
+	}
+	want := "mute:a:U1,mute:b:U1,expire:a:,unmute:b:U2"
+	if got := strings.Join(actions, ","); got != want {
+		t.Errorf("audit = %s, want %s", got, want)
+	}
+}
+
+func TestWatchers_SkipMutedNotifications(t *testing.T) {
+	state := newMuteTestState(t)
+	now := time.Now()
+	_ = state.SetMute(MuteRule{Scope: MuteScopeAgent, Target: "muted-bot", Until: now.Add(time.Hour), MutedAt: now})
+	mutes := NewMutes(state, slog.Default())
+
+	notif := &mockNotifier{}
+	d := NewDecisions(DecisionsConfig{Notifier: notif, Mutes: mutes, Logger: slog.Default()})
+	d.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{ID: "dec-1", Type: "decision", Assignee: "gasboat/crew/muted-bot"}))
+	d.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{ID: "dec-2", Type: "decision", Assignee: "gasboat/crew/loud-bot"}))
+	if created := notif.getCreated(); len(created) != 1 || created[0].ID != "dec-2" {
+		t.Errorf("expected only dec-2 notified, got %+v", created)
+	}
+
+	jackNotif := &mockJackNotifier{}
+	j := NewJacks(JacksConfig{Notifier: jackNotif, Mutes: mutes, Logger: slog.Default()})
+	j.handleCreated(context.Background(), marshalSSEBeadPayload(BeadEvent{ID: "jack-1", Type: "jack", Assignee: "muted-bot"}))
+	if len(jackNotif.getRaised()) != 0 {
+		t.Error("expected muted jack not notified")
+	}
+}
+
+func TestHandleMuteCommand_SetsRuleAndAnnounces(t *testing.T) {
+	slackSrv := newFakeSlackServer(t)
+	defer slackSrv.Close()
+	bot := newTestBot(newMockDaemon(), slackSrv)
+	bot.state = newMuteTestState(t)
+
+	bot.handleMuteCommand(context.Background(), slack.SlashCommand{
+		Command: "/mute", Text: "project beads 30m jacks", ChannelID: "C1", UserID: "U1",
+	})
+
+	active := bot.state.ActiveMutes(time.Now())
+	if len(active) != 1 {
+		t.Fatalf("expected 1 active mute, got %d", len(active))
+	}
+	r := active[0]
+	if r.Scope != MuteScopeProject || r.Target != "beads" || r.Kind != MuteKindJacks || r.MutedBy != "U1" {
+		t.Errorf("unexpected rule %+v", r)
+	}
+	if d := time.Until(r.Until); d > 30*time.Minute || d < 29*time.Minute {
+		t.Errorf("expected ~30m mute, got %s", d)
+	}
+
+	bot.handleUnmuteCommand(context.Background(), slack.SlashCommand{
+		Command: "/unmute", Text: "project beads jacks", ChannelID: "C1", UserID: "U2",
+	})
+	if len(bot.state.ActiveMutes(time.Now())) != 0 {
+		t.Error("expected mute removed")
+	}
+}
+
+func TestFormatMuteDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		2 * time.Hour:                 "2h",
+		90 * time.Minute:              "1h30m",
+		10 * time.Second:              "<1m",
+		24*time.Hour + 29*time.Second: "24h",
+	} {
+		if got := formatMuteDuration(d); got != want {
+			t.Errorf("formatMuteDuration(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Package bridge provides the nudge service.
+//
+// Nudger is the one place agents are nudged through coop for bead events.
+// It nudges each agent at most once per cooldown: nudges arriving within it
+// are queued and coalesced into a single nudge when it ends, so a burst of
+// mail does not interrupt an agent mid-task once per message. Delivery is
+// recorded on the bead that caused the nudge.
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultNudgeCooldown is the minimum time between nudges to one agent.
+const DefaultNudgeCooldown = 2 * time.Minute
+
+// nudgeSummaryLimit bounds how many coalesced nudges are listed in one
+// message; the rest are counted.
+const nudgeSummaryLimit = 5
+
+// Bead fields recording the outcome of a nudge.
+const (
+	// NudgeDeliveredField holds when coop confirmed the nudge (RFC 3339).
+	NudgeDeliveredField = "nudge_delivered_at"
+	// NudgeErrorField holds why the nudge was not delivered.
+	NudgeErrorField = "nudge_error"
+)
+
+// NudgerConfig holds configuration for the Nudger.
+type NudgerConfig struct {
+	Daemon   BeadClient
+	Cooldown time.Duration // zero = DefaultNudgeCooldown
+	Logger   *slog.Logger
+}
+
+// Nudge is a message for an agent.
+type Nudge struct {
+	Message string `json:"message"`
+	BeadID  string `json:"bead_id,omitempty"` // bead to record delivery on; empty = none
+}
+
+// Nudger delivers rate-limited, coalesced nudges to agents.
+type Nudger struct {
+	daemon     BeadClient
+	cooldown   time.Duration
+	logger     *slog.Logger
+	httpClient *http.Client // reused for nudge requests
+
+	mu     sync.Mutex
+	agents map[string]*agentNudges // agent name → nudge state
+}
+
+// agentNudges is the nudge state of one agent.
+type agentNudges struct {
+	last    time.Time // last delivery attempt
+	pending []Nudge
+	timer   *time.Timer // set while pending nudges wait for the cooldown
+}
+
+// NewNudger creates a nudge service.
+func NewNudger(cfg NudgerConfig) *Nudger {
+	cooldown := cfg.Cooldown
+	if cooldown <= 0 {
+		cooldown = DefaultNudgeCooldown
+	}
+	return &Nudger{
+		daemon:     cfg.Daemon,
+		cooldown:   cooldown,
+		logger:     cfg.Logger,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		agents:     make(map[string]*agentNudges),
+	}
+}
+
+// Nudge delivers nudge to agent now if it was not nudged within the
+// cooldown, and otherwise queues it to be coalesced with any others into
+// one nudge when the cooldown ends. It reports whether the nudge was
+// queued, and why an immediate delivery failed.
+func (n *Nudger) Nudge(ctx context.Context, agent string, nudge Nudge) (queued bool, err error) {
+	n.mu.Lock()
+	now := time.Now()
+	n.prune(now)
+	st := n.agents[agent]
+	if st == nil {
+		st = &agentNudges{}
+		n.agents[agent] = st
+	}
+	st.pending = append(st.pending, nudge)
+	if st.timer != nil {
+		pending := len(st.pending)
+		n.mu.Unlock()
+		n.logger.Info("coalescing agent nudge", "agent", agent, "bead", nudge.BeadID, "pending", pending)
+		return true, nil
+	}
+	if wait := st.last.Add(n.cooldown).Sub(now); wait > 0 {
+		st.timer = time.AfterFunc(wait, func() { n.flush(agent) })
+		n.mu.Unlock()
+		n.logger.Info("agent nudged recently, delaying nudge", "agent", agent, "bead", nudge.BeadID, "wait", wait)
+		return true, nil
+	}
+	batch := st.pending
+	st.pending, st.last = nil, now
+	n.mu.Unlock()
+
+	return false, n.deliver(ctx, agent, batch)
+}
+
+// flush delivers the nudges an agent's cooldown held back.
+func (n *Nudger) flush(agent string) {
+	n.mu.Lock()
+	st := n.agents[agent]
+	if st == nil {
+		n.mu.Unlock()
+		return
+	}
+	batch := st.pending
+	st.pending, st.timer, st.last = nil, nil, time.Now()
+	n.mu.Unlock()
+
+	if len(batch) > 0 {
+		_ = n.deliver(context.Background(), agent, batch) // logged and recorded
+	}
+}
+
+// prune forgets agents whose cooldown has ended with nothing pending.
+// Callers hold n.mu.
+func (n *Nudger) prune(now time.Time) {
+	for agent, st := range n.agents {
+		if st.timer == nil && now.Sub(st.last) > n.cooldown {
+			delete(n.agents, agent)
+		}
+	}
+}
+
+// deliver sends batch to agent as one nudge and records the outcome on
+// each nudge's bead.
+func (n *Nudger) deliver(ctx context.Context, agent string, batch []Nudge) error {
+	err := n.send(ctx, agent, nudgeMessage(batch))
+	fields := map[string]string{NudgeDeliveredField: time.Now().UTC().Format(time.RFC3339), NudgeErrorField: ""}
+	if err != nil {
+		n.logger.Error("failed to nudge agent", "agent", agent, "nudges", len(batch), "error", err)
+		fields = map[string]string{NudgeErrorField: err.Error()}
+	} else {
+		n.logger.Info("nudged agent", "agent", agent, "nudges", len(batch))
+	}
+	for _, nudge := range batch {
+		if nudge.BeadID == "" {
+			continue
+		}
+		if err := n.daemon.UpdateBeadFields(ctx, nudge.BeadID, fields); err != nil {
+			n.logger.Warn("failed to record nudge delivery", "agent", agent, "bead", nudge.BeadID, "error", err)
+		}
+	}
+	return err
+}
+
+// send looks up the agent's coop_url and POSTs the nudge.
+func (n *Nudger) send(ctx context.Context, agent, message string) error {
+	agentBead, err := n.daemon.FindAgentBead(ctx, agent)
+	if err != nil {
+		return fmt.Errorf("finding agent bead: %w", err)
+	}
+	coopURL := agentCoopURL(ctx, n.daemon, agentBead)
+	if coopURL == "" {
+		return fmt.Errorf("agent bead has no coop_url")
+	}
+	return nudgeCoop(ctx, n.httpClient, coopURL, message)
+}
+
+// nudgeMessage is the text of one nudge delivering batch.
+func nudgeMessage(batch []Nudge) string {
+	if len(batch) == 1 {
+		return batch[0].Message
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d new notifications:", len(batch))
+	for i, nudge := range batch {
+		if i == nudgeSummaryLimit {
+			fmt.Fprintf(&b, "\n- …and %d more", len(batch)-i)
+			break
+		}
+		b.WriteString("\n- " + nudge.Message)
+	}
+	return b.String()
+}
+
+// nudgeRequest is the body of POST /api/nudge.
+type nudgeRequest struct {
+	Agent string `json:"agent"`
+	Nudge
+}
+
+// Handler serves POST /api/nudge for other components to nudge an agent
+// through the service. It responds with the delivery state, "sent" or
+// "queued", or 502 if an immediate delivery failed.
+func (n *Nudger) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req nudgeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if req.Agent == "" || req.Message == "" {
+			http.Error(w, "agent and message are required", http.StatusBadRequest)
+			return
+		}
+		queued, err := n.Nudge(r.Context(), req.Agent, req.Nudge)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		state := "sent"
+		if queued {
+			state = "queued"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]string{"agent": req.Agent, "status": state})
+	}
+}
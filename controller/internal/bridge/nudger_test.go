@@ -0,0 +1,107 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// coopNudgeRecorder is a coop endpoint recording the nudges it receives.
+type coopNudgeRecorder struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (c *coopNudgeRecorder) server(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		c.mu.Lock()
+		c.messages = append(c.messages, body["message"])
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (c *coopNudgeRecorder) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.messages...)
+}
+
+func TestNudger_CoalescesWithinCooldown(t *testing.T) {
+	coop := &coopNudgeRecorder{}
+	srv := coop.server(t)
+	daemon := newMockDaemon()
+	daemon.beads["builder"] = &beadsapi.BeadDetail{ID: "builder", Notes: "coop_url: " + srv.URL}
+
+	n := NewNudger(NudgerConfig{Daemon: daemon, Cooldown: 100 * time.Millisecond, Logger: slog.Default()})
+	ctx := context.Background()
+
+	if queued, err := n.Nudge(ctx, "builder", Nudge{Message: "mail 1", BeadID: "mail-1"}); queued || err != nil {
+		t.Fatalf("first nudge: queued = %v, err = %v; want sent", queued, err)
+	}
+	for _, id := range []string{"mail-2", "mail-3"} {
+		if queued, _ := n.Nudge(ctx, "builder", Nudge{Message: id, BeadID: id}); !queued {
+			t.Errorf("%s not queued within the cooldown", id)
+		}
+	}
+	if got := coop.received(); len(got) != 1 || got[0] != "mail 1" {
+		t.Fatalf("nudges during cooldown = %q, want only the first", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(coop.received()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	got := coop.received()
+	if len(got) != 2 || !strings.HasPrefix(got[1], "2 new notifications:") ||
+		!strings.Contains(got[1], "mail-2") || !strings.Contains(got[1], "mail-3") {
+		t.Fatalf("nudges = %q, want the queued ones coalesced into one", got)
+	}
+
+	daemon.mu.Lock()
+	defer daemon.mu.Unlock()
+	for _, id := range []string{"mail-1", "mail-2", "mail-3"} {
+		if b := daemon.beads[id]; b == nil || b.Fields[NudgeDeliveredField] == "" {
+			t.Errorf("delivery not recorded on %s", id)
+		}
+	}
+}
+
+func TestNudger_RecordsFailedDelivery(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["builder"] = &beadsapi.BeadDetail{ID: "builder"} // no coop_url
+
+	n := NewNudger(NudgerConfig{Daemon: daemon, Logger: slog.Default()})
+	if _, err := n.Nudge(context.Background(), "builder", Nudge{Message: "hi", BeadID: "mail-1"}); err == nil {
+		t.Fatal("expected an error nudging an agent without coop")
+	}
+	if got := daemon.beads["mail-1"].Fields[NudgeErrorField]; got == "" {
+		t.Error("failure not recorded on the mail bead")
+	}
+}
+
+func TestNudgeMessage_ListsAtMostLimit(t *testing.T) {
+	var batch []Nudge
+	for i := 0; i < nudgeSummaryLimit+3; i++ {
+		batch = append(batch, Nudge{Message: "m"})
+	}
+	msg := nudgeMessage(batch)
+	if lines := strings.Count(msg, "\n"); lines != nudgeSummaryLimit+1 {
+		t.Errorf("message has %d list lines, want %d:\n%s", lines, nudgeSummaryLimit+1, msg)
+	}
+	if !strings.Contains(msg, "…and 3 more") {
+		t.Errorf("message does not count the rest:\n%s", msg)
+	}
+}
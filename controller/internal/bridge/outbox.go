@@ -0,0 +1,358 @@
+// Package bridge provides a durable outbox for Slack notifications.
+//
+// Outbox wraps a Notifier so that decision notifications are not lost when
+// Slack is rate limiting or unavailable. Failed deliveries are persisted in
+// the StateManager and retried with exponential backoff (honoring Slack's
+// Retry-After on rate limits). Entries sharing an ordering key — the agent
+// whose thread the message lands in — or a decision bead are delivered
+// strictly in order: a newer notification is never sent while an older one
+// for the same thread is still pending.
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// Outbox entry kinds, one per queued Notifier operation.
+const (
+	outboxNotifyDecision   = "notify_decision"
+	outboxUpdateDecision   = "update_decision"
+	outboxNotifyEscalation = "notify_escalation"
+	outboxDismissDecision  = "dismiss_decision"
+)
+
+const (
+	// outboxPollInterval is how often pending entries are checked for retry.
+	outboxPollInterval = 5 * time.Second
+	// outboxBaseBackoff is the delay after the first failed attempt; it
+	// doubles per attempt up to outboxMaxBackoff.
+	outboxBaseBackoff = 5 * time.Second
+	outboxMaxBackoff  = 5 * time.Minute
+	// outboxMaxAttempts bounds retries so a permanently failing entry does not
+	// block its thread forever (~4h at max backoff).
+	outboxMaxAttempts = 50
+)
+
+// OutboxConfig holds configuration for the notification outbox.
+type OutboxConfig struct {
+	Notifier Notifier // delivery target (e.g. *Bot)
+	State    *StateManager
+	Metrics  *Metrics // optional delivery metrics
+	Logger   *slog.Logger
+}
+
+// Outbox is a Notifier that persists failed notifications and retries them.
+type Outbox struct {
+	inner   Notifier
+	state   *StateManager
+	metrics *Metrics
+	logger  *slog.Logger
+	now     func() time.Time
+
+	// mu guards the pending-entry check and inflight. Entries are recorded
+	// before delivery, so a later one for the same thread queues behind
+	// them; Slack is called without holding mu.
+	mu       sync.Mutex
+	inflight map[string]bool // IDs of entries being delivered
+	// flushMu serializes Flush passes.
+	flushMu sync.Mutex
+}
+
+// OutboxStats is a snapshot of the outbox for metrics.
+type OutboxStats struct {
+	Depth     int
+	OldestAge time.Duration
+}
+
+// NewOutbox creates a notification outbox backed by the state manager.
+func NewOutbox(cfg OutboxConfig) *Outbox {
+	return &Outbox{
+		inner:    cfg.Notifier,
+		state:    cfg.State,
+		metrics:  cfg.Metrics,
+		logger:   cfg.Logger,
+		now:      time.Now,
+		inflight: make(map[string]bool),
+	}
+}
+
+// NotifyDecision delivers or queues a new-decision notification.
+func (o *Outbox) NotifyDecision(ctx context.Context, bead BeadEvent) error {
+	return o.submit(ctx, OutboxEntry{
+		Kind:   outboxNotifyDecision,
+		Key:    outboxKey(bead),
+		BeadID: bead.ID,
+		Bead:   &bead,
+	})
+}
+
+// UpdateDecision delivers or queues a resolved-decision update.
+func (o *Outbox) UpdateDecision(ctx context.Context, beadID, chosen string) error {
+	return o.submit(ctx, OutboxEntry{
+		Kind:   outboxUpdateDecision,
+		BeadID: beadID,
+		Chosen: chosen,
+	})
+}
+
+// NotifyEscalation delivers or queues an escalation notification.
+func (o *Outbox) NotifyEscalation(ctx context.Context, bead BeadEvent) error {
+	return o.submit(ctx, OutboxEntry{
+		Kind:   outboxNotifyEscalation,
+		Key:    outboxKey(bead),
+		BeadID: bead.ID,
+		Bead:   &bead,
+	})
+}
+
+// DismissDecision delivers or queues a decision dismissal.
+func (o *Outbox) DismissDecision(ctx context.Context, beadID string) error {
+	return o.submit(ctx, OutboxEntry{
+		Kind:   outboxDismissDecision,
+		BeadID: beadID,
+	})
+}
+
+// PostReport is passed straight through; reports are re-derivable from the
+// report bead and are not queued.
+func (o *Outbox) PostReport(ctx context.Context, decisionID, reportType, content string) error {
+	return o.inner.PostReport(ctx, decisionID, reportType, content)
+}
+
+// outboxKey returns the ordering key for a bead: the agent whose thread the
+// message lands in, or the bead itself when unassigned.
+func outboxKey(bead BeadEvent) string {
+	if bead.Assignee != "" {
+		return bead.Assignee
+	}
+	return bead.ID
+}
+
+// submit attempts immediate delivery unless an earlier entry for the same
+// thread is still pending, in which case the entry is queued behind it.
+// Returns nil once the notification is either delivered or durably queued.
+func (o *Outbox) submit(ctx context.Context, e OutboxEntry) error {
+	o.mu.Lock()
+	pending := o.state.OutboxEntries()
+	if e.Key == "" {
+		// Updates and dismissals follow the thread of the original notification.
+		e.Key = e.BeadID
+		for _, p := range pending {
+			if p.BeadID == e.BeadID {
+				e.Key = p.Key
+				break
+			}
+		}
+	}
+
+	now := o.now()
+	e.ID = fmt.Sprintf("%d-%s-%s", now.UnixNano(), e.Kind, e.BeadID)
+	e.EnqueuedAt = now
+	e.NextAttempt = now
+
+	blocked := false
+	for _, p := range pending {
+		if p.Key == e.Key || p.BeadID == e.BeadID {
+			blocked = true
+			break
+		}
+	}
+
+	if err := o.state.EnqueueOutbox(e); err != nil {
+		o.mu.Unlock()
+		return fmt.Errorf("enqueue outbox: %w", err)
+	}
+	if blocked {
+		o.mu.Unlock()
+		o.logger.Info("notification queued behind pending entry",
+			"kind", e.Kind, "bead", e.BeadID, "key", e.Key)
+		return nil
+	}
+	o.inflight[e.ID] = true
+	o.mu.Unlock()
+
+	err := o.deliver(ctx, e)
+	defer o.release(e.ID)
+	if err == nil {
+		_ = o.state.RemoveOutboxEntry(e.ID)
+		return nil
+	}
+	e.Attempts = 1
+	e.LastError = err.Error()
+	e.NextAttempt = now.Add(outboxBackoff(err, e.Attempts))
+	if err := o.state.UpdateOutboxEntry(e); err != nil {
+		return fmt.Errorf("update outbox: %w", err)
+	}
+	o.logger.Warn("notification failed, queued for retry",
+		"kind", e.Kind, "bead", e.BeadID, "retry_at", e.NextAttempt, "error", err)
+	return nil
+}
+
+// claim marks a pending entry as being delivered, reporting false if it
+// already is.
+func (o *Outbox) claim(id string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.inflight[id] {
+		return false
+	}
+	o.inflight[id] = true
+	return true
+}
+
+// release ends the delivery of an entry marked by submit or claim.
+func (o *Outbox) release(id string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.inflight, id)
+}
+
+// Run retries pending entries until ctx is cancelled.
+func (o *Outbox) Run(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			o.Flush(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Flush attempts delivery of every due entry in FIFO order. Once an entry for
+// a key fails, is not yet due or is still being delivered by submit, later
+// entries for that key (or bead) wait.
+func (o *Outbox) Flush(ctx context.Context) {
+	o.flushMu.Lock()
+	defer o.flushMu.Unlock()
+
+	now := o.now()
+	blockedKeys := make(map[string]bool)
+	blockedBeads := make(map[string]bool)
+	for _, e := range o.state.OutboxEntries() {
+		if ctx.Err() != nil {
+			return
+		}
+		if blockedKeys[e.Key] || blockedBeads[e.BeadID] {
+			continue
+		}
+		if now.Before(e.NextAttempt) || !o.claim(e.ID) {
+			blockedKeys[e.Key] = true
+			blockedBeads[e.BeadID] = true
+			continue
+		}
+
+		err := o.deliver(ctx, e)
+		if err == nil {
+			_ = o.state.RemoveOutboxEntry(e.ID)
+			o.release(e.ID)
+			o.logger.Info("delivered queued notification",
+				"kind", e.Kind, "bead", e.BeadID, "attempts", e.Attempts+1, "queued_for", now.Sub(e.EnqueuedAt))
+			continue
+		}
+
+		e.Attempts++
+		e.LastError = err.Error()
+		if e.Attempts >= outboxMaxAttempts {
+			_ = o.state.RemoveOutboxEntry(e.ID)
+			o.release(e.ID)
+			o.logger.Error("dropping notification after max attempts",
+				"kind", e.Kind, "bead", e.BeadID, "attempts", e.Attempts, "error", err)
+			continue
+		}
+		e.NextAttempt = now.Add(outboxBackoff(err, e.Attempts))
+		_ = o.state.UpdateOutboxEntry(e)
+		o.release(e.ID)
+		blockedKeys[e.Key] = true
+		blockedBeads[e.BeadID] = true
+		o.logger.Warn("queued notification retry failed",
+			"kind", e.Kind, "bead", e.BeadID, "attempts", e.Attempts, "retry_at", e.NextAttempt, "error", err)
+	}
+}
+
+// deliver dispatches an entry to the wrapped notifier and records the
+// attempt in metrics.
+func (o *Outbox) deliver(ctx context.Context, e OutboxEntry) error {
+	err := o.deliverEntry(ctx, e)
+	o.metrics.ObserveNotification(e.Kind, err)
+	return err
+}
+
+func (o *Outbox) deliverEntry(ctx context.Context, e OutboxEntry) error {
+	switch e.Kind {
+	case outboxNotifyDecision:
+		if e.Bead == nil {
+			return nil
+		}
+		return o.inner.NotifyDecision(ctx, *e.Bead)
+	case outboxUpdateDecision:
+		return o.inner.UpdateDecision(ctx, e.BeadID, e.Chosen)
+	case outboxNotifyEscalation:
+		if e.Bead == nil {
+			return nil
+		}
+		return o.inner.NotifyEscalation(ctx, *e.Bead)
+	case outboxDismissDecision:
+		return o.inner.DismissDecision(ctx, e.BeadID)
+	default:
+		o.logger.Warn("dropping outbox entry with unknown kind", "kind", e.Kind, "id", e.ID)
+		return nil
+	}
+}
+
+// outboxBackoff returns the retry delay for an entry after the given number
+// of failed attempts. Slack rate-limit responses use their Retry-After.
+func outboxBackoff(err error, attempts int) time.Duration {
+	var rl *slack.RateLimitedError
+	if errors.As(err, &rl) && rl.RetryAfter > 0 {
+		return rl.RetryAfter
+	}
+	d := outboxBaseBackoff
+	for i := 1; i < attempts && d < outboxMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, outboxMaxBackoff)
+}
+
+// Stats returns the current queue depth and the age of the oldest entry.
+func (o *Outbox) Stats() OutboxStats {
+	entries := o.state.OutboxEntries()
+	stats := OutboxStats{Depth: len(entries)}
+	if len(entries) > 0 {
+		stats.OldestAge = o.now().Sub(entries[0].EnqueuedAt)
+	}
+	return stats
+}
+
+// MetricsHandler serves outbox gauges in Prometheus text exposition format.
+func (o *Outbox) MetricsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		var b strings.Builder
+		o.WriteMetrics(&b)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(b.String()))
+	}
+}
+
+// WriteMetrics writes the outbox gauges in Prometheus text exposition format.
+func (o *Outbox) WriteMetrics(w io.Writer) {
+	s := o.Stats()
+	fmt.Fprint(w, "# HELP slack_bridge_outbox_depth Pending Slack notifications awaiting delivery.\n")
+	fmt.Fprint(w, "# TYPE slack_bridge_outbox_depth gauge\n")
+	fmt.Fprintf(w, "slack_bridge_outbox_depth %d\n", s.Depth)
+	fmt.Fprint(w, "# HELP slack_bridge_outbox_oldest_age_seconds Age of the oldest pending notification.\n")
+	fmt.Fprint(w, "# TYPE slack_bridge_outbox_oldest_age_seconds gauge\n")
+	fmt.Fprintf(w, "slack_bridge_outbox_oldest_age_seconds %g\n", s.OldestAge.Seconds())
+}
@@ -0,0 +1,212 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// flakyNotifier fails every call while down is true, then delegates to mockNotifier.
+type flakyNotifier struct {
+	mockNotifier
+	down bool
+	err  error
+}
+
+func (f *flakyNotifier) NotifyDecision(ctx context.Context, bead BeadEvent) error {
+	if f.down {
+		return f.err
+	}
+	return f.mockNotifier.NotifyDecision(ctx, bead)
+}
+
+func (f *flakyNotifier) UpdateDecision(ctx context.Context, beadID, chosen string) error {
+	if f.down {
+		return f.err
+	}
+	return f.mockNotifier.UpdateDecision(ctx, beadID, chosen)
+}
+
+func newTestOutbox(t *testing.T, n Notifier) (*Outbox, *StateManager, *time.Time) {
+	t.Helper()
+	state, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := NewOutbox(OutboxConfig{Notifier: n, State: state, Logger: slog.Default()})
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	o.now = func() time.Time { return now }
+	return o, state, &now
+}
+
+func TestOutbox_DeliversImmediatelyWhenHealthy(t *testing.T) {
+	n := &flakyNotifier{}
+	o, state, _ := newTestOutbox(t, n)
+
+	if err := o.NotifyDecision(context.Background(), BeadEvent{ID: "dec-1", Assignee: "bot"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(n.getCreated()) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(n.getCreated()))
+	}
+	if len(state.OutboxEntries()) != 0 {
+		t.Error("expected empty outbox")
+	}
+}
+
+func TestOutbox_QueuesAndRetriesInOrder(t *testing.T) {
+	n := &flakyNotifier{down: true, err: errors.New("slack down")}
+	o, state, now := newTestOutbox(t, n)
+	ctx := context.Background()
+
+	_ = o.NotifyDecision(ctx, BeadEvent{ID: "dec-1", Assignee: "bot"})
+	_ = o.UpdateDecision(ctx, "dec-1", "yes")
+	_ = o.NotifyDecision(ctx, BeadEvent{ID: "dec-2", Assignee: "bot"})
+
+	entries := state.OutboxEntries()
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 queued entries, got %d", len(entries))
+	}
+	if entries[1].Key != "bot" {
+		t.Errorf("expected update to inherit thread key, got %q", entries[1].Key)
+	}
+
+	// Slack recovers, but the first entry is not yet due — nothing is sent.
+	n.down = false
+	o.Flush(ctx)
+	if len(n.getCreated()) != 0 {
+		t.Fatal("expected no delivery before backoff elapses")
+	}
+
+	*now = now.Add(outboxBaseBackoff)
+	o.Flush(ctx)
+	created := n.getCreated()
+	if len(created) != 2 || created[0].ID != "dec-1" || created[1].ID != "dec-2" {
+		t.Fatalf("expected dec-1 then dec-2, got %+v", created)
+	}
+	if u := n.getUpdated(); len(u) != 1 || u[0].Chosen != "yes" {
+		t.Errorf("expected update delivered, got %+v", u)
+	}
+	if len(state.OutboxEntries()) != 0 {
+		t.Error("expected empty outbox after flush")
+	}
+}
+
+func TestOutbox_OtherThreadsNotBlocked(t *testing.T) {
+	n := &flakyNotifier{down: true, err: errors.New("slack down")}
+	o, _, _ := newTestOutbox(t, n)
+	ctx := context.Background()
+
+	_ = o.NotifyDecision(ctx, BeadEvent{ID: "dec-1", Assignee: "a"})
+	n.down = false
+	_ = o.NotifyDecision(ctx, BeadEvent{ID: "dec-2", Assignee: "b"})
+
+	created := n.getCreated()
+	if len(created) != 1 || created[0].ID != "dec-2" {
+		t.Errorf("expected dec-2 delivered immediately, got %+v", created)
+	}
+}
+
+// blockingNotifier holds NotifyDecision for bead "slow" until release closes.
+type blockingNotifier struct {
+	mockNotifier
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingNotifier) NotifyDecision(ctx context.Context, bead BeadEvent) error {
+	if bead.ID == "slow" {
+		close(b.started)
+		<-b.release
+	}
+	return b.mockNotifier.NotifyDecision(ctx, bead)
+}
+
+func TestOutbox_SlowDeliveryDoesNotHoldOtherThreads(t *testing.T) {
+	n := &blockingNotifier{started: make(chan struct{}), release: make(chan struct{})}
+	o, state, _ := newTestOutbox(t, n)
+	ctx := context.Background()
+
+	done := make(chan error)
+	go func() { done <- o.NotifyDecision(ctx, BeadEvent{ID: "slow", Assignee: "a"}) }()
+	<-n.started
+
+	if err := o.NotifyDecision(ctx, BeadEvent{ID: "dec-2", Assignee: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if created := n.getCreated(); len(created) != 1 || created[0].ID != "dec-2" {
+		t.Fatalf("expected dec-2 delivered while slow is in flight, got %+v", created)
+	}
+	// A later entry on the slow thread queues behind it, and Flush leaves both alone.
+	_ = o.UpdateDecision(ctx, "slow", "yes")
+	o.Flush(ctx)
+	if u := n.getUpdated(); len(u) != 0 {
+		t.Fatalf("expected update held behind the in-flight notification, got %+v", u)
+	}
+
+	close(n.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	o.Flush(ctx)
+	if u := n.getUpdated(); len(u) != 1 || u[0].BeadID != "slow" {
+		t.Errorf("expected queued update delivered after flush, got %+v", u)
+	}
+	if len(state.OutboxEntries()) != 0 {
+		t.Error("expected empty outbox")
+	}
+}
+
+func TestOutbox_PersistsAcrossRestart(t *testing.T) {
+	n := &flakyNotifier{down: true, err: errors.New("slack down")}
+	o, state, _ := newTestOutbox(t, n)
+	_ = o.NotifyDecision(context.Background(), BeadEvent{ID: "dec-1"})
+
+	reloaded, err := NewStateManagerWithStore(state.store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := reloaded.OutboxEntries()
+	if len(entries) != 1 || entries[0].Bead == nil || entries[0].Bead.ID != "dec-1" {
+		t.Errorf("expected persisted entry for dec-1, got %+v", entries)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	if got := outboxBackoff(errors.New("x"), 1); got != outboxBaseBackoff {
+		t.Errorf("attempt 1: got %v", got)
+	}
+	if got := outboxBackoff(errors.New("x"), 3); got != 4*outboxBaseBackoff {
+		t.Errorf("attempt 3: got %v", got)
+	}
+	if got := outboxBackoff(errors.New("x"), 40); got != outboxMaxBackoff {
+		t.Errorf("attempt 40: got %v", got)
+	}
+	rl := &slack.RateLimitedError{RetryAfter: 42 * time.Second}
+	if got := outboxBackoff(rl, 1); got != 42*time.Second {
+		t.Errorf("rate limited: got %v", got)
+	}
+}
+
+func TestOutbox_MetricsHandler(t *testing.T) {
+	n := &flakyNotifier{down: true, err: errors.New("slack down")}
+	o, _, now := newTestOutbox(t, n)
+	_ = o.NotifyDecision(context.Background(), BeadEvent{ID: "dec-1"})
+	*now = now.Add(90 * time.Second)
+
+	rec := httptest.NewRecorder()
+	o.MetricsHandler()(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{"slack_bridge_outbox_depth 1", "slack_bridge_outbox_oldest_age_seconds 90"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in metrics, got:\n%s", want, body)
+		}
+	}
+}
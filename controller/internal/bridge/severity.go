@@ -0,0 +1,109 @@
+// Package bridge provides severity routing for Slack notifications.
+//
+// Every notification the bot posts outside decision threads (agent
+// crashes, jacks, error reports, budget alerts) carries a severity. Routing
+// rules, set per project with a default, decide per severity which channel
+// it goes to, whom it mentions, and whether it is posted at all or only
+// listed in the periodic digest.
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Severity is how urgently a notification needs a human.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarn     Severity = "warn"
+	SeverityCritical Severity = "critical"
+)
+
+// SeverityRoute is where notifications of one severity go.
+type SeverityRoute struct {
+	// Channel is the Slack channel ID to post to; empty = the agent's
+	// routed channel, or the default channel.
+	Channel string `json:"channel,omitempty"`
+	// Mention is prepended to the message: "here", "channel", or a user
+	// group ID (S…). Empty mentions nobody.
+	Mention string `json:"mention,omitempty"`
+	// Digest lists the notification in the next digest instead of posting it.
+	Digest bool `json:"digest,omitempty"`
+}
+
+// SeverityRules maps severities to routes.
+type SeverityRules map[Severity]SeverityRoute
+
+// SeverityRouting holds the routing rules: per project, falling back to
+// Default per severity, then to DefaultSeverityRules.
+type SeverityRouting struct {
+	Default  SeverityRules            `json:"default,omitempty"`
+	Projects map[string]SeverityRules `json:"projects,omitempty"`
+}
+
+// DefaultSeverityRules apply to severities no configured rule covers:
+// critical notifications mention @here, info ones only go to the digest.
+var DefaultSeverityRules = SeverityRules{
+	SeverityInfo:     {Digest: true},
+	SeverityWarn:     {},
+	SeverityCritical: {Mention: "here"},
+}
+
+// Route returns the route of a notification of sev about project.
+func (r SeverityRouting) Route(project string, sev Severity) SeverityRoute {
+	if route, ok := r.Projects[project][sev]; ok {
+		return route
+	}
+	if route, ok := r.Default[sev]; ok {
+		return route
+	}
+	return DefaultSeverityRules[sev]
+}
+
+// ParseSeverityRouting parses routing rules from JSON, e.g.
+//
+//	{"default": {"critical": {"channel": "C0PAGER", "mention": "here"}},
+//	 "projects": {"api": {"info": {"channel": "C0API"}}}}
+//
+// Empty input yields no rules, so DefaultSeverityRules apply.
+func ParseSeverityRouting(s string) (SeverityRouting, error) {
+	var r SeverityRouting
+	if strings.TrimSpace(s) == "" {
+		return r, nil
+	}
+	if err := json.Unmarshal([]byte(s), &r); err != nil {
+		return r, fmt.Errorf("parsing severity routes: %w", err)
+	}
+	check := func(where string, rules SeverityRules) error {
+		for sev := range rules {
+			if _, ok := DefaultSeverityRules[sev]; !ok {
+				return fmt.Errorf("severity routes %s: unknown severity %q (want info, warn or critical)", where, sev)
+			}
+		}
+		return nil
+	}
+	if err := check("default", r.Default); err != nil {
+		return r, err
+	}
+	for project, rules := range r.Projects {
+		if err := check("of project "+project, rules); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// mentionText renders a route's mention as Slack markup.
+func mentionText(mention string) string {
+	switch mention {
+	case "":
+		return ""
+	case "here", "channel", "everyone":
+		return "<!" + mention + ">"
+	default:
+		return "<!subteam^" + mention + ">"
+	}
+}
@@ -0,0 +1,96 @@
+package bridge
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseSeverityRouting(t *testing.T) {
+	r, err := ParseSeverityRouting(`{
+		"default": {"critical": {"channel": "C0PAGER", "mention": "here"}},
+		"projects": {"api": {"info": {"channel": "C0API"}, "critical": {"mention": "S0ONCALL"}}}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		project string
+		sev     Severity
+		want    SeverityRoute
+	}{
+		{"", SeverityCritical, SeverityRoute{Channel: "C0PAGER", Mention: "here"}},
+		{"web", SeverityCritical, SeverityRoute{Channel: "C0PAGER", Mention: "here"}},
+		{"api", SeverityCritical, SeverityRoute{Mention: "S0ONCALL"}},
+		{"api", SeverityInfo, SeverityRoute{Channel: "C0API"}},
+		{"web", SeverityInfo, SeverityRoute{Digest: true}},
+		{"web", SeverityWarn, SeverityRoute{}},
+	}
+	for _, tt := range tests {
+		if got := r.Route(tt.project, tt.sev); got != tt.want {
+			t.Errorf("Route(%q, %s) = %+v, want %+v", tt.project, tt.sev, got, tt.want)
+		}
+	}
+
+	if r, err := ParseSeverityRouting(" "); err != nil || r.Default != nil || r.Projects != nil {
+		t.Errorf("empty routes = %+v, %v", r, err)
+	}
+	for _, bad := range []string{`{"default": {"urgent": {}}}`, `{"projects": {"api": {"page": {}}}}`, `not json`} {
+		if _, err := ParseSeverityRouting(bad); err == nil {
+			t.Errorf("ParseSeverityRouting(%s) succeeded", bad)
+		}
+	}
+}
+
+func TestMentionText(t *testing.T) {
+	for mention, want := range map[string]string{"": "", "here": "<!here>", "channel": "<!channel>", "S0ONCALL": "<!subteam^S0ONCALL>"} {
+		if got := mentionText(mention); got != want {
+			t.Errorf("mentionText(%q) = %q, want %q", mention, got, want)
+		}
+	}
+}
+
+func TestNotify_RoutesBySeverity(t *testing.T) {
+	slackSrv := newRecordingSlackServer(t)
+	bot := newTestBot(newMockDaemon(), slackSrv.Server)
+	bot.channel = "C1"
+	ctx := context.Background()
+
+	// Critical mentions @here by default.
+	_, ts, err := bot.notify(ctx, notification{severity: SeverityCritical, summary: "agent crashed"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts == "" || len(slackSrv.calls) != 1 || !strings.Contains(slackSrv.calls[0], "\\u003c!here\\u003e") {
+		t.Fatalf("critical: ts = %q, calls = %q, want a post mentioning @here", ts, slackSrv.calls)
+	}
+
+	// Info is held for the digest until it is flushed.
+	for _, s := range []string{"jack lowered", "another jack lowered"} {
+		if _, ts, err := bot.notify(ctx, notification{severity: SeverityInfo, summary: s}); err != nil || ts != "" {
+			t.Fatalf("info: ts = %q, err = %v", ts, err)
+		}
+	}
+	if len(slackSrv.calls) != 1 {
+		t.Fatalf("info posted before the digest: %q", slackSrv.calls)
+	}
+	bot.flushNotificationDigest(ctx)
+	if len(slackSrv.calls) != 2 {
+		t.Fatalf("calls = %q, want one digest post", slackSrv.calls)
+	}
+	for _, want := range []string{"2 notifications since", "jack lowered", "another jack lowered"} {
+		if !strings.Contains(slackSrv.calls[1], want) {
+			t.Errorf("digest %q lacks %q", slackSrv.calls[1], want)
+		}
+	}
+	bot.flushNotificationDigest(ctx)
+	if len(slackSrv.calls) != 2 {
+		t.Errorf("empty digest was posted: %q", slackSrv.calls)
+	}
+
+	// A project rule overrides the default.
+	bot.severity = SeverityRouting{Projects: map[string]SeverityRules{"api": {SeverityInfo: {}}}}
+	if _, ts, err := bot.notify(ctx, notification{severity: SeverityInfo, project: "api", summary: "api jack lowered"}); err != nil || ts == "" {
+		t.Errorf("project info: ts = %q, err = %v, want it posted", ts, err)
+	}
+}
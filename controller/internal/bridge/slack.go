@@ -10,12 +10,15 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"gasboat/controller/internal/beadsapi"
 )
 
 // SlackNotifier implements Notifier by posting decision beads to Slack
@@ -354,6 +357,12 @@ func (s *SlackNotifier) handleBlockAction(ctx context.Context, interaction slack
 			"chosen":    chosen,
 			"rationale": rationale,
 		}
+		responder := beadsapi.Responder{
+			Identity: beadsapi.NormalizeIdentity("slack", interaction.User.ID, ""),
+			Name:     interaction.User.Username,
+			Via:      "slack",
+		}
+		maps.Copy(fields, responder.AuditFields(time.Now()))
 		if err := s.daemon.CloseBead(ctx, beadID, fields); err != nil {
 			s.logger.Error("failed to close decision bead from Slack",
 				"bead", beadID, "error", err)
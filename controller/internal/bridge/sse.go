@@ -6,34 +6,27 @@
 package bridge
 
 import (
-	"bufio"
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/sse"
 )
 
 // SSEStream connects to the kbeads SSE endpoint and dispatches bead lifecycle
-// events to registered topic handlers. It auto-reconnects with exponential
-// backoff and uses Last-Event-ID for reconnection replay.
+// events to registered topic handlers. The shared sse.Client handles
+// reconnection, Last-Event-ID replay and heartbeat detection.
 type SSEStream struct {
-	baseURL    string
-	topics     []string
-	logger     *slog.Logger
-	httpClient *http.Client // reused across reconnections (long-lived, no timeout)
+	client *sse.Client
+	logger *slog.Logger
 
 	handlers map[string][]SSEHandler // topic -> handlers
-	mu       sync.RWMutex
-	lastID   string        // last event ID for reconnection; protected by mu
-	dedup    *Dedup        // optional event deduplicator
-	state    *StateManager // optional state for persisting last event ID
+	dedup    *Dedup                  // optional event deduplicator
+	state    *StateManager           // optional state for persisting last event ID
+	metrics  *Metrics                // optional lag and dedup metrics
+	decoder  *beadsapi.EventDecoder
 }
 
 // SSEHandler is a callback for SSE events on a specific topic.
@@ -51,23 +44,36 @@ type SSEStreamConfig struct {
 	Dedup *Dedup
 	// State is an optional state manager for persisting the last SSE event ID.
 	State *StateManager
+	// Metrics optionally records event lag, dedup suppressions and stream stats.
+	Metrics *Metrics
+	// StrictEvents drops bead events carrying fields the event schema does
+	// not know, instead of only counting them.
+	StrictEvents bool
 }
 
 // NewSSEStream creates a new SSE event stream for the slack-bridge.
 func NewSSEStream(cfg SSEStreamConfig) *SSEStream {
 	s := &SSEStream{
-		baseURL:    strings.TrimRight(cfg.BeadsHTTPAddr, "/"),
-		topics:     cfg.Topics,
-		logger:     cfg.Logger,
-		httpClient: &http.Client{Timeout: 0}, // no timeout for long-lived SSE
-		handlers:   make(map[string][]SSEHandler),
-		dedup:      cfg.Dedup,
-		state:      cfg.State,
-	}
+		client: sse.New(sse.Config{
+			BaseURL: cfg.BeadsHTTPAddr,
+			Topics:  strings.Join(cfg.Topics, ","),
+			Logger:  cfg.Logger,
+		}),
+		logger:   cfg.Logger,
+		handlers: make(map[string][]SSEHandler),
+		dedup:    cfg.Dedup,
+		state:    cfg.State,
+		metrics:  cfg.Metrics,
+		decoder: &beadsapi.EventDecoder{
+			Strict:    cfg.StrictEvents,
+			OnUnknown: cfg.Metrics.IncUnknownEventField,
+		},
+	}
+	cfg.Metrics.WatchSSE(s.client.Stats)
 	// Restore last event ID from persisted state.
 	if cfg.State != nil {
 		if id := cfg.State.GetLastEventID(); id != "" {
-			s.lastID = id
+			s.client.SetLastEventID(id)
 			s.logger.Info("restored SSE last event ID from state", "last_id", id)
 		}
 	}
@@ -76,16 +82,7 @@ func NewSSEStream(cfg SSEStreamConfig) *SSEStream {
 
 // LastID returns the last received SSE event ID, safe for concurrent use.
 func (s *SSEStream) LastID() string {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return s.lastID
-}
-
-// setLastID sets the last received SSE event ID.
-func (s *SSEStream) setLastID(id string) {
-	s.mu.Lock()
-	s.lastID = id
-	s.mu.Unlock()
+	return s.client.LastEventID()
 }
 
 // On registers a handler for a specific SSE topic (e.g., "beads.bead.created").
@@ -94,128 +91,21 @@ func (s *SSEStream) On(topic string, handler SSEHandler) {
 }
 
 // Start connects to the SSE endpoint and streams events to registered handlers.
-// Blocks until ctx is canceled. Reconnects with exponential backoff on errors.
+// Blocks until ctx is canceled. Reconnects with jittered exponential backoff
+// on errors.
 func (s *SSEStream) Start(ctx context.Context) error {
-	backoff := time.Second
-	maxBackoff := 30 * time.Second
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		err := s.stream(ctx)
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-		s.logger.Warn("SSE connection lost, reconnecting",
-			"error", err, "backoff", backoff, "last_id", s.LastID())
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(backoff):
-		}
-		backoff *= 2
-		if backoff > maxBackoff {
-			backoff = maxBackoff
+	// Resume from the persisted cursor: when replicas share state, another
+	// replica may have advanced it since this stream was created.
+	if s.state != nil {
+		if id := s.state.GetLastEventID(); id != "" && id != s.LastID() {
+			s.client.SetLastEventID(id)
+			s.logger.Info("resuming SSE from shared last event ID", "last_id", id)
 		}
 	}
-}
-
-// stream opens a single SSE connection and reads events until error or context cancellation.
-func (s *SSEStream) stream(ctx context.Context) error {
-	url := s.baseURL + "/v1/events/stream"
-	if len(s.topics) > 0 {
-		url += "?topics=" + strings.Join(s.topics, ",")
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return fmt.Errorf("create SSE request: %w", err)
-	}
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	lastID := s.LastID()
-	if lastID != "" {
-		req.Header.Set("Last-Event-ID", lastID)
-	}
-
-	resp, err := s.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("SSE connect: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		return fmt.Errorf("SSE endpoint returned %d: %s", resp.StatusCode, string(body))
-	}
-
-	s.logger.Info("SSE stream connected",
-		"url", url, "last_id", lastID)
 
-	return s.readEvents(ctx, resp.Body)
-}
-
-// readEvents reads and dispatches SSE events from the response body.
-func (s *SSEStream) readEvents(ctx context.Context, body io.Reader) error {
-	scanner := bufio.NewScanner(body)
-	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024)
-
-	var currentID, currentEvent string
-	var currentData strings.Builder
-
-	for scanner.Scan() {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-
-		line := scanner.Text()
-
-		// Empty line = end of event.
-		if line == "" {
-			if currentData.Len() > 0 && currentEvent != "" {
-				s.dispatch(ctx, currentID, currentEvent, currentData.String())
-			}
-			if currentID != "" {
-				s.setLastID(currentID)
-			}
-			currentID = ""
-			currentEvent = ""
-			currentData.Reset()
-			continue
-		}
-
-		// Comment lines (keepalive).
-		if strings.HasPrefix(line, ":") {
-			continue
-		}
-
-		// Parse SSE field:value.
-		field, value, _ := strings.Cut(line, ":")
-		value = strings.TrimPrefix(value, " ")
-
-		switch field {
-		case "id":
-			currentID = value
-		case "event":
-			currentEvent = value
-		case "data":
-			if currentData.Len() > 0 {
-				currentData.WriteByte('\n')
-			}
-			currentData.WriteString(value)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("SSE read: %w", err)
-	}
-	return fmt.Errorf("SSE stream ended (EOF)")
+	return s.client.Run(ctx, func(ctx context.Context, ev sse.Event) {
+		s.dispatch(ctx, ev.ID, ev.Topic, string(ev.Data))
+	})
 }
 
 // dispatch calls all registered handlers for the given topic.
@@ -226,19 +116,41 @@ func (s *SSEStream) dispatch(ctx context.Context, id, topic, data string) {
 		return
 	}
 
+	// Validate bead events against the versioned schema once, before any
+	// handler sees them; unknown fields are counted. Handlers still get the
+	// raw payload, so only strict mode drops events that fail validation.
+	var payload *beadsapi.BeadEventPayload
+	if strings.HasPrefix(topic, "beads.bead.") {
+		p, err := s.decoder.Decode(topic, []byte(data))
+		if err != nil {
+			if s.decoder.Strict {
+				s.logger.Warn("strict mode: skipping invalid SSE event",
+					"topic", topic, "sse_id", id, "error", err)
+				return
+			}
+			s.logger.Debug("SSE event failed schema validation",
+				"topic", topic, "sse_id", id, "error", err)
+		}
+		payload = p
+	}
+
 	// Deduplicate created/closed events by bead ID. Updated events are
 	// exempt — the same bead can be updated many times with different state
 	// (e.g., agent_state transitions from spawning→working→done) and each
 	// change must be processed.
-	if s.dedup != nil && topic != "beads.bead.updated" {
-		bead := ParseBeadEvent([]byte(data))
-		if bead != nil {
-			key := topic + ":" + bead.ID
-			if s.dedup.Seen(key) {
-				s.logger.Debug("dedup: skipping duplicate event",
-					"topic", topic, "bead", bead.ID, "sse_id", id)
-				return
-			}
+	if s.dedup != nil && topic != "beads.bead.updated" && payload != nil && payload.Bead != nil {
+		key := topic + ":" + payload.BeadID
+		if s.dedup.Seen(key) {
+			s.logger.Debug("dedup: skipping duplicate event",
+				"topic", topic, "bead", payload.BeadID, "sse_id", id)
+			s.metrics.IncDedupSuppressed(topic)
+			return
+		}
+	}
+
+	if s.metrics != nil {
+		if t := beadEventTime([]byte(data)); !t.IsZero() {
+			s.metrics.ObserveSSELag(topic, time.Since(t))
 		}
 	}
 
@@ -254,45 +166,19 @@ func (s *SSEStream) dispatch(ctx context.Context, id, topic, data string) {
 	}
 }
 
-// sseBeadWrapper is the kbeads SSE payload for bead lifecycle events.
-// BeadCreated: {"bead": {...}}
-// BeadClosed:  {"bead": {...}, "closed_by": "..."}
-type sseBeadWrapper struct {
-	Bead json.RawMessage `json:"bead"`
-}
-
-// sseBeadData mirrors the kbeads model.Bead fields used by the bridge.
-type sseBeadData struct {
-	ID        string          `json:"id"`
-	Type      string          `json:"type"`
-	Title     string          `json:"title"`
-	Status    string          `json:"status"`
-	Assignee  string          `json:"assignee"`
-	CreatedBy string          `json:"created_by"`
-	Labels    []string        `json:"labels"`
-	Priority  int             `json:"priority"`
-	Fields    json.RawMessage `json:"fields"`
-}
-
 // ParseBeadEvent extracts a bridge BeadEvent from a kbeads SSE event payload.
 // Returns nil if the payload is malformed or missing a bead.
 func ParseBeadEvent(data []byte) *BeadEvent {
-	var wrapper sseBeadWrapper
-	if err := json.Unmarshal(data, &wrapper); err != nil {
+	payload, err := beadsapi.DecodeBeadEvent(data)
+	if err != nil || payload.Bead == nil {
 		return nil
 	}
-	if len(wrapper.Bead) == 0 {
-		return nil
-	}
-
-	var bead sseBeadData
-	if err := json.Unmarshal(wrapper.Bead, &bead); err != nil {
-		return nil
-	}
-
-	// Parse fields from json.RawMessage to map[string]string.
-	fields := beadsapi.ParseFieldsJSON(bead.Fields)
+	return beadEventFromPayload(payload)
+}
 
+// beadEventFromPayload converts a decoded payload (with a bead) to a BeadEvent.
+func beadEventFromPayload(payload *beadsapi.BeadEventPayload) *BeadEvent {
+	bead := payload.Bead
 	return &BeadEvent{
 		ID:        bead.ID,
 		Type:      bead.Type,
@@ -301,7 +187,11 @@ func ParseBeadEvent(data []byte) *BeadEvent {
 		Assignee:  bead.Assignee,
 		CreatedBy: bead.CreatedBy,
 		Labels:    bead.Labels,
-		Fields:    fields,
+		Fields:    bead.FieldsMap(),
 		Priority:  bead.Priority,
+		Notes:     bead.Notes,
+		CreatedAt: bead.CreatedAt,
+		ClosedAt:  bead.ClosedAt,
+		DueAt:     bead.DueAt,
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -206,3 +207,27 @@ func TestSSEStream_DispatchesEvents(t *testing.T) {
 		}
 	}
 }
+
+func TestSSEStream_CountsUnknownFieldsAndStrictModeDrops(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		metrics := NewMetrics()
+		s := NewSSEStream(SSEStreamConfig{Logger: slog.Default(), Metrics: metrics, StrictEvents: strict})
+		var got int
+		s.On("beads.bead.created", func(context.Context, []byte) { got++ })
+
+		s.dispatch(context.Background(), "1", "beads.bead.created", `{"bead": {"id": "kd-1", "owner_team": "infra"}}`)
+		s.dispatch(context.Background(), "2", "beads.bead.created", `{"version": 2, "bead": {"id": "kd-2"}}`)
+
+		want := 2
+		if strict {
+			want = 1
+		}
+		if got != want {
+			t.Errorf("strict=%v: handler called %d times, want %d", strict, got, want)
+		}
+		out := scrapeMetrics(t, metrics.Handler())
+		if !strings.Contains(out, `slack_bridge_sse_unknown_fields_total{topic="beads.bead.created",field="bead.owner_team"} 1`) {
+			t.Errorf("strict=%v: unknown field not counted:\n%s", strict, out)
+		}
+	}
+}
@@ -1,16 +1,16 @@
 // Package bridge provides state persistence for the slack-bridge.
 //
 // StateManager persists message references (decision messages, dashboard)
-// to a JSON file so that Slack message threading and update-in-place
-// survive pod restarts.
+// through a StateStore (JSON file or Redis) so that Slack message threading
+// and update-in-place survive pod restarts.
 package bridge
 
 import (
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
 	"sync"
+	"time"
 )
 
 // MessageRef tracks a Slack message by channel and timestamp.
@@ -27,39 +27,129 @@ type DashboardRef struct {
 	LastHash  string `json:"last_hash,omitempty"` // content hash for change detection
 }
 
+// OutboxEntry is a pending Slack notification awaiting (re)delivery.
+type OutboxEntry struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`             // notifier operation, e.g. "notify_decision"
+	Key         string     `json:"key"`              // ordering key (agent thread); entries sharing a key deliver in order
+	BeadID      string     `json:"bead_id"`          // decision bead the notification refers to
+	Bead        *BeadEvent `json:"bead,omitempty"`   // payload for notify/escalate
+	Chosen      string     `json:"chosen,omitempty"` // payload for update
+	Attempts    int        `json:"attempts"`
+	EnqueuedAt  time.Time  `json:"enqueued_at"`
+	NextAttempt time.Time  `json:"next_attempt"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
 // StateData is the JSON-serialized state structure.
 type StateData struct {
-	DecisionMessages map[string]MessageRef `json:"decision_messages,omitempty"` // bead ID → message ref
-	ChatMessages     map[string]MessageRef `json:"chat_messages,omitempty"`     // bead ID → message ref (chat forwarding)
-	AgentCards       map[string]MessageRef `json:"agent_cards,omitempty"`       // agent identity → status card message ref
-	Dashboard        *DashboardRef         `json:"dashboard,omitempty"`
-	LastEventID      string                `json:"last_event_id,omitempty"` // SSE event ID for reconnection
+	DecisionMessages map[string]MessageRef   `json:"decision_messages,omitempty"` // bead ID → message ref
+	ChatMessages     map[string]MessageRef   `json:"chat_messages,omitempty"`     // bead ID → message ref (chat forwarding)
+	AgentCards       map[string]MessageRef   `json:"agent_cards,omitempty"`       // agent identity → status card message ref
+	JackMessages     map[string]MessageRef   `json:"jack_messages,omitempty"`     // jack bead ID → raised message ref, edited when lowered
+	ResolvedMessages map[string]MessageRef   `json:"resolved_messages,omitempty"` // decision bead ID → resolved message in an agent thread, until compacted
+	AgentSpawners    map[string]string       `json:"agent_spawners,omitempty"`    // agent name → Slack user ID that spawned it
+	Dashboard        *DashboardRef           `json:"dashboard,omitempty"`
+	Dashboards       map[string]DashboardRef `json:"dashboards,omitempty"`    // channel ID → dashboard from the dashboards config
+	ChartsPostedAt   time.Time               `json:"charts_posted_at"`        // last dashboard trend chart upload
+	Outbox           []OutboxEntry           `json:"outbox,omitempty"`        // pending notifications, FIFO
+	LastEventID      string                  `json:"last_event_id,omitempty"` // SSE event ID for reconnection
+	Mutes            map[string]MuteRule     `json:"mutes,omitempty"`         // rule key → notification mute rule
+	MuteAudit        []MuteAuditEntry        `json:"mute_audit,omitempty"`    // recent mute/unmute/expire actions, oldest first
 }
 
 // StateManager provides thread-safe persistence of Slack message references.
 type StateManager struct {
-	mu   sync.RWMutex
-	path string
-	data StateData
+	mu    sync.RWMutex
+	store StateStore
+	data  StateData
+
+	// shared is set when several replicas write the same store. Every
+	// mutation then applies to the latest stored state (see updateLocked)
+	// so that one replica does not clobber entries written by another.
+	shared bool
 }
 
 // NewStateManager creates a state manager that persists to the given path.
 // If the file exists, its contents are loaded.
 func NewStateManager(path string) (*StateManager, error) {
+	return NewStateManagerWithStore(NewFileStateStore(path))
+}
+
+// NewStateManagerWithStore creates a state manager backed by the given store
+// and loads any existing state from it.
+func NewStateManagerWithStore(store StateStore) (*StateManager, error) {
 	sm := &StateManager{
-		path: path,
+		store: store,
 		data: StateData{
 			DecisionMessages: make(map[string]MessageRef),
 			ChatMessages:     make(map[string]MessageRef),
 			AgentCards:       make(map[string]MessageRef),
+			JackMessages:     make(map[string]MessageRef),
+			ResolvedMessages: make(map[string]MessageRef),
+			AgentSpawners:    make(map[string]string),
+			Mutes:            make(map[string]MuteRule),
+			Dashboards:       make(map[string]DashboardRef),
 		},
 	}
-	if err := sm.load(); err != nil && !os.IsNotExist(err) {
+	if err := sm.load(); err != nil {
 		return nil, fmt.Errorf("load state: %w", err)
 	}
 	return sm, nil
 }
 
+// Describe returns the backing store location for logging.
+func (sm *StateManager) Describe() string {
+	return sm.store.Describe()
+}
+
+// SetShared makes every mutation apply to the latest stored state, for
+// stores shared between replicas.
+func (sm *StateManager) SetShared(shared bool) {
+	sm.mu.Lock()
+	sm.shared = shared
+	sm.mu.Unlock()
+}
+
+// Reload re-reads state from the backing store, replacing the in-memory copy.
+// Followers call this periodically so a replica that becomes leader starts
+// from current message refs.
+func (sm *StateManager) Reload() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.load()
+}
+
+// updateLocked applies mutate to the state and persists it if mutate
+// reports a change. On a shared store, mutate runs on the latest stored
+// state: inside the store's transaction for an AtomicStateStore, otherwise
+// after a reload, whose failure keeps the in-memory copy. Caller must hold
+// sm.mu.
+func (sm *StateManager) updateLocked(mutate func() bool) error {
+	if !sm.shared {
+		if !mutate() {
+			return nil
+		}
+		return sm.saveLocked()
+	}
+	if atomic, ok := sm.store.(AtomicStateStore); ok {
+		return atomic.Update(func(current []byte) ([]byte, error) {
+			if err := sm.decode(current); err != nil {
+				return nil, err
+			}
+			if !mutate() {
+				return nil, nil
+			}
+			return sm.marshal()
+		})
+	}
+	_ = sm.load()
+	if !mutate() {
+		return nil
+	}
+	return sm.saveLocked()
+}
+
 // --- Decision Messages ---
 
 // GetDecisionMessage returns the message ref for a decision bead.
@@ -74,16 +164,20 @@ func (sm *StateManager) GetDecisionMessage(beadID string) (MessageRef, bool) {
 func (sm *StateManager) SetDecisionMessage(beadID string, ref MessageRef) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	sm.data.DecisionMessages[beadID] = ref
-	return sm.saveLocked()
+	return sm.updateLocked(func() bool {
+		sm.data.DecisionMessages[beadID] = ref
+		return true
+	})
 }
 
 // RemoveDecisionMessage removes a message ref for a decision bead and persists.
 func (sm *StateManager) RemoveDecisionMessage(beadID string) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	delete(sm.data.DecisionMessages, beadID)
-	return sm.saveLocked()
+	return sm.updateLocked(func() bool {
+		delete(sm.data.DecisionMessages, beadID)
+		return true
+	})
 }
 
 // AllDecisionMessages returns a copy of all tracked decision messages.
@@ -97,6 +191,67 @@ func (sm *StateManager) AllDecisionMessages() map[string]MessageRef {
 	return out
 }
 
+// CompactDecisionMessages removes decision message refs for which resolved
+// returns true and persists once. resolved is called without the lock held.
+// Returns the number of entries removed.
+func (sm *StateManager) CompactDecisionMessages(resolved func(beadID string) bool) (int, error) {
+	var stale []string
+	for id := range sm.AllDecisionMessages() {
+		if resolved(id) {
+			stale = append(stale, id)
+		}
+	}
+	if len(stale) == 0 {
+		return 0, nil
+	}
+
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return len(stale), sm.updateLocked(func() bool {
+		for _, id := range stale {
+			delete(sm.data.DecisionMessages, id)
+		}
+		return true
+	})
+}
+
+// --- Resolved Messages ---
+
+// SetResolvedMessage records the resolved message of a decision posted in an
+// agent thread, for thread compaction, and persists.
+func (sm *StateManager) SetResolvedMessage(beadID string, ref MessageRef) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		sm.data.ResolvedMessages[beadID] = ref
+		return true
+	})
+}
+
+// RemoveResolvedMessages forgets the resolved messages of the given
+// decisions and persists.
+func (sm *StateManager) RemoveResolvedMessages(beadIDs ...string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		for _, id := range beadIDs {
+			delete(sm.data.ResolvedMessages, id)
+		}
+		return true
+	})
+}
+
+// AllResolvedMessages returns a copy of all tracked resolved messages.
+func (sm *StateManager) AllResolvedMessages() map[string]MessageRef {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	out := make(map[string]MessageRef, len(sm.data.ResolvedMessages))
+	for k, v := range sm.data.ResolvedMessages {
+		out[k] = v
+	}
+	return out
+}
+
 // --- Chat Messages ---
 
 // GetChatMessage returns the message ref for a chat bead.
@@ -111,16 +266,20 @@ func (sm *StateManager) GetChatMessage(beadID string) (MessageRef, bool) {
 func (sm *StateManager) SetChatMessage(beadID string, ref MessageRef) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	sm.data.ChatMessages[beadID] = ref
-	return sm.saveLocked()
+	return sm.updateLocked(func() bool {
+		sm.data.ChatMessages[beadID] = ref
+		return true
+	})
 }
 
 // RemoveChatMessage removes a message ref for a chat bead and persists.
 func (sm *StateManager) RemoveChatMessage(beadID string) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	delete(sm.data.ChatMessages, beadID)
-	return sm.saveLocked()
+	return sm.updateLocked(func() bool {
+		delete(sm.data.ChatMessages, beadID)
+		return true
+	})
 }
 
 // AllChatMessages returns a copy of all tracked chat messages.
@@ -134,6 +293,36 @@ func (sm *StateManager) AllChatMessages() map[string]MessageRef {
 	return out
 }
 
+// --- Jack Messages ---
+
+// GetJackMessage returns the raised message ref for a jack.
+func (sm *StateManager) GetJackMessage(beadID string) (MessageRef, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	ref, ok := sm.data.JackMessages[beadID]
+	return ref, ok
+}
+
+// SetJackMessage stores the raised message ref for a jack and persists.
+func (sm *StateManager) SetJackMessage(beadID string, ref MessageRef) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		sm.data.JackMessages[beadID] = ref
+		return true
+	})
+}
+
+// RemoveJackMessage removes the raised message ref for a jack and persists.
+func (sm *StateManager) RemoveJackMessage(beadID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		delete(sm.data.JackMessages, beadID)
+		return true
+	})
+}
+
 // --- Agent Cards ---
 
 // GetAgentCard returns the status card message ref for an agent.
@@ -148,16 +337,20 @@ func (sm *StateManager) GetAgentCard(agent string) (MessageRef, bool) {
 func (sm *StateManager) SetAgentCard(agent string, ref MessageRef) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	sm.data.AgentCards[agent] = ref
-	return sm.saveLocked()
+	return sm.updateLocked(func() bool {
+		sm.data.AgentCards[agent] = ref
+		return true
+	})
 }
 
 // RemoveAgentCard removes a status card message ref for an agent and persists.
 func (sm *StateManager) RemoveAgentCard(agent string) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	delete(sm.data.AgentCards, agent)
-	return sm.saveLocked()
+	return sm.updateLocked(func() bool {
+		delete(sm.data.AgentCards, agent)
+		return true
+	})
 }
 
 // AllAgentCards returns a copy of all tracked agent status card messages.
@@ -171,6 +364,182 @@ func (sm *StateManager) AllAgentCards() map[string]MessageRef {
 	return out
 }
 
+// --- Agent Spawners ---
+
+// SetAgentSpawner records the Slack user who spawned an agent and persists.
+func (sm *StateManager) SetAgentSpawner(agent, userID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		sm.data.AgentSpawners[agent] = userID
+		return true
+	})
+}
+
+// AgentsSpawnedBy returns the names of agents spawned by the given Slack user.
+func (sm *StateManager) AgentsSpawnedBy(userID string) []string {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	var out []string
+	for agent, uid := range sm.data.AgentSpawners {
+		if uid == userID {
+			out = append(out, agent)
+		}
+	}
+	return out
+}
+
+// --- Outbox ---
+
+// OutboxEntries returns a copy of the pending outbox entries in FIFO order.
+func (sm *StateManager) OutboxEntries() []OutboxEntry {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	out := make([]OutboxEntry, len(sm.data.Outbox))
+	copy(out, sm.data.Outbox)
+	return out
+}
+
+// EnqueueOutbox appends an entry to the outbox and persists.
+func (sm *StateManager) EnqueueOutbox(e OutboxEntry) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		sm.data.Outbox = append(sm.data.Outbox, e)
+		return true
+	})
+}
+
+// UpdateOutboxEntry replaces the entry with the same ID and persists.
+func (sm *StateManager) UpdateOutboxEntry(e OutboxEntry) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		for i := range sm.data.Outbox {
+			if sm.data.Outbox[i].ID == e.ID {
+				sm.data.Outbox[i] = e
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RemoveOutboxEntry removes the entry with the given ID and persists.
+func (sm *StateManager) RemoveOutboxEntry(id string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		for i := range sm.data.Outbox {
+			if sm.data.Outbox[i].ID == id {
+				sm.data.Outbox = append(sm.data.Outbox[:i], sm.data.Outbox[i+1:]...)
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// --- Seen Events ---
+
+// seenEventTTL bounds how long shared dedup keys are retained.
+const seenEventTTL = 24 * time.Hour
+
+// MarkEventSeen records a dedup key in the store, outside the state blob.
+// Returns true if the key was already recorded, by this or another replica.
+func (sm *StateManager) MarkEventSeen(key string) (bool, error) {
+	return sm.store.MarkSeen(key, seenEventTTL)
+}
+
+// --- Mutes ---
+
+// SetMute stores a mute rule, replacing any rule for the same target and
+// kind, records it in the audit log, and persists.
+func (sm *StateManager) SetMute(rule MuteRule) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		sm.data.Mutes[rule.key()] = rule
+		sm.appendMuteAuditLocked(MuteAuditEntry{Action: "mute", Rule: rule, By: rule.MutedBy, At: rule.MutedAt})
+		return true
+	})
+}
+
+// RemoveMute deletes the rule for the given target and kind, records who
+// removed it, and persists. Returns false if no such rule existed.
+func (sm *StateManager) RemoveMute(scope, target, kind, by string) (bool, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	key := MuteRule{Scope: scope, Target: target, Kind: kind}.key()
+	var removed bool
+	err := sm.updateLocked(func() bool {
+		rule, ok := sm.data.Mutes[key]
+		removed = ok
+		if ok {
+			delete(sm.data.Mutes, key)
+			sm.appendMuteAuditLocked(MuteAuditEntry{Action: "unmute", Rule: rule, By: by, At: time.Now()})
+		}
+		return ok
+	})
+	return removed, err
+}
+
+// ActiveMutes returns the rules that have not expired at now, soonest
+// expiry first.
+func (sm *StateManager) ActiveMutes(now time.Time) []MuteRule {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	var out []MuteRule
+	for _, r := range sm.data.Mutes {
+		if now.Before(r.Until) {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Until.Before(out[j].Until) })
+	return out
+}
+
+// ExpireMutes removes rules whose expiry has passed, records them in the
+// audit log, and persists. Returns the expired rules.
+func (sm *StateManager) ExpireMutes(now time.Time) ([]MuteRule, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	var expired []MuteRule
+	err := sm.updateLocked(func() bool {
+		expired = nil
+		for key, r := range sm.data.Mutes {
+			if !now.Before(r.Until) {
+				delete(sm.data.Mutes, key)
+				expired = append(expired, r)
+				sm.appendMuteAuditLocked(MuteAuditEntry{Action: "expire", Rule: r, At: now})
+			}
+		}
+		return len(expired) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// MuteAuditLog returns a copy of the mute audit log, oldest first.
+func (sm *StateManager) MuteAuditLog() []MuteAuditEntry {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	out := make([]MuteAuditEntry, len(sm.data.MuteAudit))
+	copy(out, sm.data.MuteAudit)
+	return out
+}
+
+// appendMuteAuditLocked appends to the audit log, dropping the oldest
+// entries beyond muteAuditMax. Caller must hold sm.mu.
+func (sm *StateManager) appendMuteAuditLocked(e MuteAuditEntry) {
+	sm.data.MuteAudit = append(sm.data.MuteAudit, e)
+	if n := len(sm.data.MuteAudit) - muteAuditMax; n > 0 {
+		sm.data.MuteAudit = sm.data.MuteAudit[n:]
+	}
+}
+
 // --- Dashboard ---
 
 // GetDashboard returns the dashboard message ref.
@@ -188,8 +557,62 @@ func (sm *StateManager) GetDashboard() (*DashboardRef, bool) {
 func (sm *StateManager) SetDashboard(ref DashboardRef) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	sm.data.Dashboard = &ref
-	return sm.saveLocked()
+	return sm.updateLocked(func() bool {
+		sm.data.Dashboard = &ref
+		return true
+	})
+}
+
+// GetChannelDashboard returns the message ref of the configured dashboard
+// in channelID.
+func (sm *StateManager) GetChannelDashboard(channelID string) (*DashboardRef, bool) {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	ref, ok := sm.data.Dashboards[channelID]
+	if !ok {
+		return nil, false
+	}
+	return &ref, true
+}
+
+// SetChannelDashboard stores the message ref of the configured dashboard in
+// channelID and persists.
+func (sm *StateManager) SetChannelDashboard(channelID string, ref DashboardRef) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		sm.data.Dashboards[channelID] = ref
+		return true
+	})
+}
+
+// RemoveChannelDashboard forgets the configured dashboard in channelID and
+// persists.
+func (sm *StateManager) RemoveChannelDashboard(channelID string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		delete(sm.data.Dashboards, channelID)
+		return true
+	})
+}
+
+// GetChartsPostedAt returns when the dashboard trend charts were last
+// uploaded, or the zero time if they never were.
+func (sm *StateManager) GetChartsPostedAt() time.Time {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.data.ChartsPostedAt
+}
+
+// SetChartsPostedAt records a trend chart upload and persists.
+func (sm *StateManager) SetChartsPostedAt(t time.Time) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.updateLocked(func() bool {
+		sm.data.ChartsPostedAt = t
+		return true
+	})
 }
 
 // --- SSE Event ID ---
@@ -205,51 +628,75 @@ func (sm *StateManager) GetLastEventID() string {
 func (sm *StateManager) SetLastEventID(id string) error {
 	sm.mu.Lock()
 	defer sm.mu.Unlock()
-	sm.data.LastEventID = id
-	return sm.saveLocked()
+	return sm.updateLocked(func() bool {
+		sm.data.LastEventID = id
+		return true
+	})
 }
 
 // --- Persistence ---
 
 func (sm *StateManager) load() error {
-	data, err := os.ReadFile(sm.path)
+	data, err := sm.store.Load()
 	if err != nil {
 		return err
 	}
-	if err := json.Unmarshal(data, &sm.data); err != nil {
+	return sm.decode(data)
+}
+
+// decode replaces the in-memory state with the serialized state in data.
+// Empty data keeps the current state. Caller must hold sm.mu.
+func (sm *StateManager) decode(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var loaded StateData
+	if err := json.Unmarshal(data, &loaded); err != nil {
 		return fmt.Errorf("unmarshal state: %w", err)
 	}
 	// Ensure maps are initialized.
-	if sm.data.DecisionMessages == nil {
-		sm.data.DecisionMessages = make(map[string]MessageRef)
+	if loaded.DecisionMessages == nil {
+		loaded.DecisionMessages = make(map[string]MessageRef)
+	}
+	if loaded.ChatMessages == nil {
+		loaded.ChatMessages = make(map[string]MessageRef)
+	}
+	if loaded.AgentCards == nil {
+		loaded.AgentCards = make(map[string]MessageRef)
+	}
+	if loaded.JackMessages == nil {
+		loaded.JackMessages = make(map[string]MessageRef)
+	}
+	if loaded.ResolvedMessages == nil {
+		loaded.ResolvedMessages = make(map[string]MessageRef)
 	}
-	if sm.data.ChatMessages == nil {
-		sm.data.ChatMessages = make(map[string]MessageRef)
+	if loaded.AgentSpawners == nil {
+		loaded.AgentSpawners = make(map[string]string)
 	}
-	if sm.data.AgentCards == nil {
-		sm.data.AgentCards = make(map[string]MessageRef)
+	if loaded.Mutes == nil {
+		loaded.Mutes = make(map[string]MuteRule)
 	}
+	if loaded.Dashboards == nil {
+		loaded.Dashboards = make(map[string]DashboardRef)
+	}
+	sm.data = loaded
 	return nil
 }
 
-// saveLocked writes state to disk atomically. Caller must hold sm.mu.
+// saveLocked writes state to the backing store. Caller must hold sm.mu.
 func (sm *StateManager) saveLocked() error {
-	data, err := json.MarshalIndent(sm.data, "", "  ")
+	data, err := sm.marshal()
 	if err != nil {
-		return fmt.Errorf("marshal state: %w", err)
-	}
-
-	dir := filepath.Dir(sm.path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return fmt.Errorf("create state dir: %w", err)
+		return err
 	}
+	return sm.store.Save(data)
+}
 
-	tmp := sm.path + ".tmp"
-	if err := os.WriteFile(tmp, data, 0o644); err != nil {
-		return fmt.Errorf("write state tmp: %w", err)
-	}
-	if err := os.Rename(tmp, sm.path); err != nil {
-		return fmt.Errorf("rename state: %w", err)
+// marshal serializes the in-memory state. Caller must hold sm.mu.
+func (sm *StateManager) marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(sm.data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal state: %w", err)
 	}
-	return nil
+	return data, nil
 }
@@ -0,0 +1,565 @@
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" database/sql driver
+)
+
+// StateStore persists the serialized StateData blob. Load returns (nil, nil)
+// when no state has been saved yet.
+type StateStore interface {
+	Load() ([]byte, error)
+	Save(data []byte) error
+	// MarkSeen records a dedup key for ttl and reports whether it was already
+	// recorded. It must be atomic across replicas sharing the store.
+	MarkSeen(key string, ttl time.Duration) (bool, error)
+	// Describe returns a human-readable location for logging.
+	Describe() string
+}
+
+// AtomicStateStore is a StateStore that can apply a read-modify-write of the
+// blob atomically. StateManager uses it when the store is shared, so that
+// replicas writing at the same time never overwrite each other's changes.
+type AtomicStateStore interface {
+	StateStore
+	// Update calls fn with the current blob (nil when none is saved) and
+	// stores the blob it returns. If another writer changes the blob in
+	// between, fn is called again on the new one. A nil result from fn
+	// leaves the blob unchanged.
+	Update(fn func(current []byte) ([]byte, error)) error
+}
+
+// --- File store ---
+
+// FileStateStore persists state to a JSON file, written atomically via
+// tmp+rename. Mount the directory on a PVC to survive pod rescheduling.
+type FileStateStore struct {
+	path string
+
+	// A file store is not shared between replicas, so dedup keys stay in
+	// memory rather than in the state file.
+	mu   sync.Mutex
+	seen map[string]time.Time // dedup key → expiry
+}
+
+// NewFileStateStore creates a file-backed state store.
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path, seen: make(map[string]time.Time)}
+}
+
+// Load reads the state file. A missing file is not an error.
+func (f *FileStateStore) Load() ([]byte, error) {
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Save writes the state file atomically.
+func (f *FileStateStore) Save(data []byte) error {
+	dir := filepath.Dir(f.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write state tmp: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("rename state: %w", err)
+	}
+	return nil
+}
+
+// MarkSeen records a dedup key in memory, dropping expired keys.
+func (f *FileStateStore) MarkSeen(key string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := time.Now()
+	if exp, ok := f.seen[key]; ok && now.Before(exp) {
+		return true, nil
+	}
+	for k, exp := range f.seen {
+		if !now.Before(exp) {
+			delete(f.seen, k)
+		}
+	}
+	f.seen[key] = now.Add(ttl)
+	return false, nil
+}
+
+// Describe returns the file path.
+func (f *FileStateStore) Describe() string { return "file:" + f.path }
+
+// --- SQLite store ---
+
+// SQLiteStateStore persists state in a SQLite database, typically on a PVC so
+// that it survives pod rescheduling. Unlike the file store, dedup keys are
+// kept in the database too and so survive restarts. SQLite suits a single
+// replica; replicas sharing state need the Redis store.
+type SQLiteStateStore struct {
+	path string
+	db   *sql.DB
+}
+
+// sqliteSchema creates the state table (a single row holding the blob) and
+// the dedup table.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS state (
+	id   INTEGER PRIMARY KEY CHECK (id = 1),
+	data BLOB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS seen (
+	key        TEXT PRIMARY KEY,
+	expires_at INTEGER NOT NULL
+);`
+
+// OpenSQLiteStateStore opens (creating if needed) the SQLite database at path.
+func OpenSQLiteStateStore(path string) (*SQLiteStateStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create state dir: %w", err)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite %s: %w", path, err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init sqlite %s: %w", path, err)
+	}
+	return &SQLiteStateStore{path: path, db: db}, nil
+}
+
+// Load reads the state blob. An empty database is not an error.
+func (s *SQLiteStateStore) Load() ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT data FROM state WHERE id = 1`).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sqlite load state: %w", err)
+	}
+	return data, nil
+}
+
+// Save replaces the state blob.
+func (s *SQLiteStateStore) Save(data []byte) error {
+	_, err := s.db.Exec(`INSERT INTO state (id, data) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data`, data)
+	if err != nil {
+		return fmt.Errorf("sqlite save state: %w", err)
+	}
+	return nil
+}
+
+// MarkSeen records a dedup key unless an unexpired one exists. Expired keys
+// are pruned first, so a key whose TTL has passed counts as new again.
+func (s *SQLiteStateStore) MarkSeen(key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	if _, err := s.db.Exec(`DELETE FROM seen WHERE expires_at <= ?`, now.UnixNano()); err != nil {
+		return false, fmt.Errorf("sqlite prune seen: %w", err)
+	}
+	res, err := s.db.Exec(`INSERT INTO seen (key, expires_at) VALUES (?, ?)
+		ON CONFLICT (key) DO NOTHING`, key, now.Add(ttl).UnixNano())
+	if err != nil {
+		return false, fmt.Errorf("sqlite mark seen %s: %w", key, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("sqlite mark seen %s: %w", key, err)
+	}
+	return n == 0, nil
+}
+
+// Describe returns the database path.
+func (s *SQLiteStateStore) Describe() string { return "sqlite:" + s.path }
+
+// Close closes the database.
+func (s *SQLiteStateStore) Close() error { return s.db.Close() }
+
+// --- Redis store ---
+
+// RedisStateConfig configures the Redis state backend.
+type RedisStateConfig struct {
+	Addr     string // host:port
+	Password string // optional AUTH password
+	DB       int    // SELECT index
+	Key      string // key holding the state blob
+	Timeout  time.Duration
+}
+
+// RedisStateStore persists state as a single Redis string so that it survives
+// pod rescheduling and can be shared between replicas, which update it with
+// WATCH/MULTI/EXEC transactions. It speaks the RESP protocol directly to
+// avoid a client dependency, over one connection that is redialed when it
+// breaks.
+type RedisStateStore struct {
+	cfg RedisStateConfig
+
+	mu   sync.Mutex // serializes commands on conn
+	conn net.Conn   // nil until the first command or after a failure
+	rd   *bufio.Reader
+}
+
+// NewRedisStateStore creates a Redis-backed state store.
+func NewRedisStateStore(cfg RedisStateConfig) *RedisStateStore {
+	if cfg.Key == "" {
+		cfg.Key = "slack-bridge:state"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	return &RedisStateStore{cfg: cfg}
+}
+
+// Load fetches the state blob. A missing key returns (nil, nil).
+func (r *RedisStateStore) Load() ([]byte, error) {
+	reply, err := r.do("GET", r.cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("redis GET %s: %w", r.cfg.Key, err)
+	}
+	if reply == nil {
+		return nil, nil
+	}
+	return []byte(*reply), nil
+}
+
+// Save stores the state blob.
+func (r *RedisStateStore) Save(data []byte) error {
+	if _, err := r.do("SET", r.cfg.Key, string(data)); err != nil {
+		return fmt.Errorf("redis SET %s: %w", r.cfg.Key, err)
+	}
+	return nil
+}
+
+// MarkSeen records a dedup key as its own Redis key with SET NX EX, so two
+// replicas racing on the same event agree on which one saw it first.
+func (r *RedisStateStore) MarkSeen(key string, ttl time.Duration) (bool, error) {
+	k := r.cfg.Key + ":seen:" + key
+	secs := max(int(ttl/time.Second), 1)
+	reply, err := r.do("SET", k, "1", "NX", "EX", strconv.Itoa(secs))
+	if err != nil {
+		return false, fmt.Errorf("redis SET NX %s: %w", k, err)
+	}
+	// A nil reply means the key already existed.
+	return reply == nil, nil
+}
+
+// redisUpdateAttempts bounds how often Update retries a transaction that
+// another writer aborted; redisUpdateBackoff is the base of the jittered
+// wait between attempts, so that contending replicas stop colliding.
+const (
+	redisUpdateAttempts = 10
+	redisUpdateBackoff  = 5 * time.Millisecond
+)
+
+// Update applies fn to the state blob in a WATCH/MULTI/EXEC transaction. If
+// another replica writes the key between the read and EXEC, the transaction
+// aborts and fn runs again on the new blob.
+func (r *RedisStateStore) Update(fn func(current []byte) ([]byte, error)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for attempt := range redisUpdateAttempts {
+		if attempt > 0 {
+			time.Sleep(rand.N(redisUpdateBackoff * time.Duration(attempt)))
+		}
+		done, err := r.updateLocked(fn)
+		if err != nil || done {
+			return err
+		}
+	}
+	return fmt.Errorf("redis update %s: aborted by concurrent writers %d times", r.cfg.Key, redisUpdateAttempts)
+}
+
+// updateLocked makes one attempt of Update. It reports false, without an
+// error, when another writer aborted the transaction. Caller must hold r.mu.
+func (r *RedisStateStore) updateLocked(fn func(current []byte) ([]byte, error)) (bool, error) {
+	// WATCH opens the attempt, so like do it is retried once on a fresh
+	// connection if the reused one was closed while idle.
+	watch := []string{"WATCH", r.cfg.Key}
+	reused := r.conn != nil
+	_, err := r.doLocked(watch)
+	var redisErr redisError
+	if err != nil && reused && !errors.As(err, &redisErr) {
+		_, err = r.doLocked(watch)
+	}
+	if err != nil {
+		return false, fmt.Errorf("redis WATCH %s: %w", r.cfg.Key, err)
+	}
+
+	reply, err := r.doLocked([]string{"GET", r.cfg.Key})
+	if err != nil {
+		r.unwatchLocked()
+		return false, fmt.Errorf("redis GET %s: %w", r.cfg.Key, err)
+	}
+	var current []byte
+	if reply != nil {
+		current = []byte(*reply)
+	}
+	next, err := fn(current)
+	if err != nil || next == nil {
+		r.unwatchLocked()
+		return true, err
+	}
+
+	for _, cmd := range [][]string{{"MULTI"}, {"SET", r.cfg.Key, string(next)}} {
+		if _, err := r.doLocked(cmd); err != nil {
+			r.closeLocked() // drops the open transaction with the connection
+			return false, fmt.Errorf("redis %s %s: %w", cmd[0], r.cfg.Key, err)
+		}
+	}
+	reply, err = r.doLocked([]string{"EXEC"})
+	if err != nil {
+		return false, fmt.Errorf("redis EXEC %s: %w", r.cfg.Key, err)
+	}
+	// A nil reply means a watched key changed and nothing was written.
+	return reply != nil, nil
+}
+
+// unwatchLocked ends a WATCH without a transaction. Caller must hold r.mu.
+func (r *RedisStateStore) unwatchLocked() {
+	if r.conn != nil {
+		_, _ = r.doLocked([]string{"UNWATCH"})
+	}
+}
+
+// Describe returns the Redis address and key.
+func (r *RedisStateStore) Describe() string {
+	return fmt.Sprintf("redis:%s/%d/%s", r.cfg.Addr, r.cfg.DB, r.cfg.Key)
+}
+
+// do runs a single command on the store's connection, dialing it first when
+// needed. A command that fails on a reused connection, which Redis may have
+// closed while idle, is retried once on a fresh one.
+func (r *RedisStateStore) do(args ...string) (*string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reused := r.conn != nil
+	reply, err := r.doLocked(args)
+	var redisErr redisError
+	if err != nil && reused && !errors.As(err, &redisErr) {
+		reply, err = r.doLocked(args)
+	}
+	return reply, err
+}
+
+// doLocked runs a command, dialing if there is no connection. A network
+// error drops the connection; a Redis error reply keeps it. Caller must
+// hold r.mu.
+func (r *RedisStateStore) doLocked(args []string) (*string, error) {
+	if r.conn == nil {
+		if err := r.dialLocked(); err != nil {
+			return nil, err
+		}
+	}
+	_ = r.conn.SetDeadline(time.Now().Add(r.cfg.Timeout))
+	reply, err := redisCommand(r.conn, r.rd, args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		r.closeLocked()
+	}
+	return reply, err
+}
+
+// dialLocked connects and runs AUTH/SELECT when configured. Caller must
+// hold r.mu.
+func (r *RedisStateStore) dialLocked() error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Timeout)
+	defer cancel()
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", r.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(r.cfg.Timeout))
+	r.conn, r.rd = conn, bufio.NewReader(conn)
+
+	if r.cfg.Password != "" {
+		if _, err := redisCommand(conn, r.rd, "AUTH", r.cfg.Password); err != nil {
+			r.closeLocked()
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if r.cfg.DB != 0 {
+		if _, err := redisCommand(conn, r.rd, "SELECT", strconv.Itoa(r.cfg.DB)); err != nil {
+			r.closeLocked()
+			return fmt.Errorf("select: %w", err)
+		}
+	}
+	return nil
+}
+
+// closeLocked drops the connection. Caller must hold r.mu.
+func (r *RedisStateStore) closeLocked() {
+	if r.conn != nil {
+		_ = r.conn.Close()
+	}
+	r.conn, r.rd = nil, nil
+}
+
+// redisError is an error reply from the server, as opposed to a network or
+// protocol failure; the connection stays usable after one.
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+// redisCommand writes a RESP array command and reads a single reply.
+// Bulk and simple string replies are returned as strings; a nil bulk reply
+// returns nil.
+func redisCommand(w io.Writer, rd *bufio.Reader, args ...string) (*string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return nil, err
+	}
+	return redisReply(rd)
+}
+
+// redisReply reads one RESP reply. An array reply (from EXEC) is consumed
+// and returned as its element count, or nil for a nil array.
+func redisReply(rd *bufio.Reader) (*string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		s := line[1:]
+		return &s, nil
+	case '-':
+		return nil, redisError(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("bad bulk length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2) // payload + CRLF
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		s := string(buf[:n])
+		return &s, nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("bad array length %q", line)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		for range n {
+			if _, err := redisReply(rd); err != nil {
+				var redisErr redisError
+				if !errors.As(err, &redisErr) {
+					return nil, err
+				}
+			}
+		}
+		s := strconv.Itoa(n)
+		return &s, nil
+	default:
+		return nil, fmt.Errorf("unsupported reply type %q", line[0])
+	}
+}
+
+// --- Migration ---
+
+// MigrateFileState copies a legacy JSON state file into store when the store
+// is empty. The file is renamed with a ".migrated" suffix afterwards so the
+// import runs once. Returns true if a migration happened.
+func MigrateFileState(path string, store StateStore) (bool, error) {
+	existing, err := store.Load()
+	if err != nil {
+		return false, fmt.Errorf("load target store: %w", err)
+	}
+	if len(existing) > 0 {
+		return false, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read legacy state: %w", err)
+	}
+	if err := store.Save(data); err != nil {
+		return false, fmt.Errorf("save migrated state: %w", err)
+	}
+	if err := os.Rename(path, path+".migrated"); err != nil {
+		return true, fmt.Errorf("rename legacy state: %w", err)
+	}
+	return true, nil
+}
+
+// --- Compaction ---
+
+// stateCompactionInterval is how often resolved decision refs are pruned.
+const stateCompactionInterval = time.Hour
+
+// RunStateCompaction prunes decision message refs whose beads are closed or
+// deleted, once at startup and then every stateCompactionInterval. Refs for
+// decisions resolved while the bridge was down would otherwise accumulate.
+func RunStateCompaction(ctx context.Context, state *StateManager, daemon BeadClient, logger *slog.Logger) {
+	compact := func() {
+		n, err := state.CompactDecisionMessages(func(beadID string) bool {
+			bead, err := daemon.GetBead(ctx, beadID)
+			if err != nil {
+				var apiErr *beadsapi.APIError
+				return errors.As(err, &apiErr) && apiErr.StatusCode == 404
+			}
+			return bead.Status == "closed"
+		})
+		if err != nil {
+			logger.Warn("state compaction failed", "error", err)
+			return
+		}
+		if n > 0 {
+			logger.Info("compacted resolved decision refs from state", "removed", n)
+		}
+	}
+
+	compact()
+	ticker := time.NewTicker(stateCompactionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			compact()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// fakeRedis is a minimal in-memory RESP server supporting GET, SET (with NX),
+// AUTH, SELECT and WATCH/MULTI/EXEC transactions. Expiry is not modelled.
+type fakeRedis struct {
+	ln       net.Listener
+	mu       sync.Mutex
+	data     map[string]string
+	versions map[string]int // bumped on every write, for WATCH
+	conns    int            // connections accepted
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{ln: ln, data: make(map[string]string), versions: make(map[string]int)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.conns++
+			f.mu.Unlock()
+			go f.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return f
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	watched := make(map[string]int) // key -> version at WATCH
+	var queued [][]string
+	inMulti := false
+	for {
+		line, err := rd.ReadString('\n')
+		if err != nil {
+			return
+		}
+		n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := make([]string, n)
+		for i := range args {
+			hdr, _ := rd.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(hdr[1:]))
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(rd, buf); err != nil {
+				return
+			}
+			args[i] = string(buf[:size])
+		}
+		f.mu.Lock()
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "WATCH":
+			for _, k := range args[1:] {
+				watched[k] = f.versions[k]
+			}
+			io.WriteString(conn, "+OK\r\n")
+		case cmd == "UNWATCH":
+			clear(watched)
+			io.WriteString(conn, "+OK\r\n")
+		case cmd == "MULTI":
+			inMulti = true
+			io.WriteString(conn, "+OK\r\n")
+		case cmd == "EXEC":
+			aborted := false
+			for k, v := range watched {
+				aborted = aborted || f.versions[k] != v
+			}
+			if aborted {
+				io.WriteString(conn, "*-1\r\n")
+			} else {
+				fmt.Fprintf(conn, "*%d\r\n", len(queued))
+				for _, q := range queued {
+					f.exec(conn, q)
+				}
+			}
+			clear(watched)
+			queued, inMulti = nil, false
+		case inMulti:
+			queued = append(queued, args)
+			io.WriteString(conn, "+QUEUED\r\n")
+		default:
+			f.exec(conn, args)
+		}
+		f.mu.Unlock()
+	}
+}
+
+// exec runs a single non-transaction command. Caller must hold f.mu.
+func (f *fakeRedis) exec(w io.Writer, args []string) {
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		if v, ok := f.data[args[1]]; ok {
+			fmt.Fprintf(w, "$%d\r\n%s\r\n", len(v), v)
+		} else {
+			io.WriteString(w, "$-1\r\n")
+		}
+	case "SET":
+		if _, ok := f.data[args[1]]; ok && slices.Contains(args[3:], "NX") {
+			io.WriteString(w, "$-1\r\n")
+			return
+		}
+		f.data[args[1]] = args[2]
+		f.versions[args[1]]++
+		io.WriteString(w, "+OK\r\n")
+	case "AUTH":
+		if args[1] != "secret" {
+			io.WriteString(w, "-WRONGPASS invalid password\r\n")
+		} else {
+			io.WriteString(w, "+OK\r\n")
+		}
+	case "SELECT":
+		io.WriteString(w, "+OK\r\n")
+	default:
+		io.WriteString(w, "-ERR unknown command\r\n")
+	}
+}
+
+func TestRedisStateStore_RoundTrip(t *testing.T) {
+	srv := newFakeRedis(t)
+	store := NewRedisStateStore(RedisStateConfig{Addr: srv.ln.Addr().String(), Password: "secret", DB: 2})
+
+	data, err := store.Load()
+	if err != nil || data != nil {
+		t.Fatalf("expected empty load, got %q err=%v", data, err)
+	}
+
+	sm, err := NewStateManagerWithStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetDecisionMessage("dec-1", MessageRef{ChannelID: "C1", Timestamp: "1.2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewStateManagerWithStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref, ok := reloaded.GetDecisionMessage("dec-1"); !ok || ref.ChannelID != "C1" {
+		t.Errorf("expected dec-1 ref after reload, got %+v ok=%v", ref, ok)
+	}
+}
+
+func TestRedisStateStore_ReusesConnection(t *testing.T) {
+	srv := newFakeRedis(t)
+	store := NewRedisStateStore(RedisStateConfig{Addr: srv.ln.Addr().String(), Password: "secret"})
+
+	for i := range 3 {
+		if err := store.Save([]byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.MarkSeen("created:dec-1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// A dropped connection is redialed transparently.
+	store.mu.Lock()
+	store.conn.Close()
+	store.mu.Unlock()
+	data, err := store.Load()
+	if err != nil || string(data) != "2" {
+		t.Fatalf("expected reload after drop, got %q err=%v", data, err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.conns != 2 {
+		t.Errorf("expected 2 connections (initial + redial), got %d", srv.conns)
+	}
+}
+
+func TestRedisStateStore_UpdateRetriesOnConflict(t *testing.T) {
+	srv := newFakeRedis(t)
+	addr := srv.ln.Addr().String()
+	store := NewRedisStateStore(RedisStateConfig{Addr: addr})
+	other := NewRedisStateStore(RedisStateConfig{Addr: addr})
+	if err := store.Save([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	err := store.Update(func(current []byte) ([]byte, error) {
+		calls++
+		if calls == 1 {
+			// Another replica writes between our read and our commit.
+			if err := other.Save(append(current, 'b')); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return append(current, 'c'), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected update re-run after conflict, got %d calls", calls)
+	}
+	if data, _ := store.Load(); string(data) != "abc" {
+		t.Errorf("expected both writes kept, got %q", data)
+	}
+}
+
+func TestRedisStateStore_SharedManagersDoNotClobber(t *testing.T) {
+	srv := newFakeRedis(t)
+	newReplica := func() *StateManager {
+		sm, err := NewStateManagerWithStore(NewRedisStateStore(RedisStateConfig{Addr: srv.ln.Addr().String()}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sm.SetShared(true)
+		return sm
+	}
+	a, b := newReplica(), newReplica()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			id := "dec-" + strconv.Itoa(i)
+			sm := a
+			if i%2 == 1 {
+				sm = b
+			}
+			if err := sm.SetDecisionMessage(id, MessageRef{ChannelID: "C1", Timestamp: id}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	reloaded, err := NewStateManagerWithStore(NewRedisStateStore(RedisStateConfig{Addr: srv.ln.Addr().String()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 20 {
+		if _, ok := reloaded.GetDecisionMessage("dec-" + strconv.Itoa(i)); !ok {
+			t.Errorf("dec-%d lost to a concurrent write", i)
+		}
+	}
+}
+
+func TestRedisStateStore_AuthError(t *testing.T) {
+	srv := newFakeRedis(t)
+	store := NewRedisStateStore(RedisStateConfig{Addr: srv.ln.Addr().String(), Password: "wrong"})
+	if _, err := store.Load(); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Errorf("expected auth error, got %v", err)
+	}
+}
+
+func TestRedisStateStore_MarkSeenAcrossReplicas(t *testing.T) {
+	srv := newFakeRedis(t)
+	newReplica := func() *Dedup {
+		sm, err := NewStateManagerWithStore(NewRedisStateStore(RedisStateConfig{Addr: srv.ln.Addr().String()}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sm.SetShared(true)
+		d := NewDedup(slog.Default())
+		d.UseSharedState(sm)
+		return d
+	}
+	a, b := newReplica(), newReplica()
+
+	if a.Seen("created:dec-1") {
+		t.Fatal("expected first replica to see key as new")
+	}
+	if !b.Seen("created:dec-1") {
+		t.Fatal("expected second replica to see key recorded by the first")
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if _, ok := srv.data["slack-bridge:state:seen:created:dec-1"]; !ok {
+		t.Errorf("expected per-key dedup marker, got keys %v", slices.Collect(maps.Keys(srv.data)))
+	}
+	if strings.Contains(srv.data["slack-bridge:state"], "dec-1") {
+		t.Error("dedup key written into the state blob")
+	}
+}
+
+func TestMigrateFileState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	legacy, err := NewStateManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = legacy.SetAgentCard("bot", MessageRef{ChannelID: "C1", Timestamp: "9.9"})
+
+	srv := newFakeRedis(t)
+	store := NewRedisStateStore(RedisStateConfig{Addr: srv.ln.Addr().String()})
+
+	migrated, err := MigrateFileState(path, store)
+	if err != nil || !migrated {
+		t.Fatalf("expected migration, got migrated=%v err=%v", migrated, err)
+	}
+	if _, err := os.Stat(path + ".migrated"); err != nil {
+		t.Errorf("expected legacy file renamed: %v", err)
+	}
+
+	sm, err := NewStateManagerWithStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sm.GetAgentCard("bot"); !ok {
+		t.Error("expected agent card carried over")
+	}
+
+	// Second run is a no-op.
+	migrated, err = MigrateFileState(path, store)
+	if err != nil || migrated {
+		t.Errorf("expected no second migration, got migrated=%v err=%v", migrated, err)
+	}
+}
+
+func TestRunStateCompaction_RemovesClosedDecisions(t *testing.T) {
+	daemon := newMockDaemon()
+	daemon.beads["dec-open"] = &beadsapi.BeadDetail{ID: "dec-open", Type: "decision", Status: "open"}
+	daemon.beads["dec-closed"] = &beadsapi.BeadDetail{ID: "dec-closed", Type: "decision", Status: "closed"}
+
+	sm, err := NewStateManager(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = sm.SetDecisionMessage("dec-open", MessageRef{ChannelID: "C1", Timestamp: "1"})
+	_ = sm.SetDecisionMessage("dec-closed", MessageRef{ChannelID: "C1", Timestamp: "2"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // run the startup pass only
+	RunStateCompaction(ctx, sm, daemon, slog.Default())
+
+	if _, ok := sm.GetDecisionMessage("dec-closed"); ok {
+		t.Error("expected closed decision compacted")
+	}
+	if _, ok := sm.GetDecisionMessage("dec-open"); !ok {
+		t.Error("expected open decision kept")
+	}
+}
+
+func TestSQLiteStateStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := OpenSQLiteStateStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.Load(); err != nil || data != nil {
+		t.Fatalf("expected empty load, got %q err=%v", data, err)
+	}
+	sm, err := NewStateManagerWithStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sm.SetDecisionMessage("dec-1", MessageRef{ChannelID: "C1", Timestamp: "1.2"}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// Reopening the database (as after a reschedule) keeps the state.
+	reopened, err := OpenSQLiteStateStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	reloaded, err := NewStateManagerWithStore(reopened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref, ok := reloaded.GetDecisionMessage("dec-1"); !ok || ref.ChannelID != "C1" {
+		t.Errorf("expected dec-1 ref after reopen, got %+v ok=%v", ref, ok)
+	}
+}
+
+func TestSQLiteStateStore_MarkSeen(t *testing.T) {
+	store, err := OpenSQLiteStateStore(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	for _, tt := range []struct {
+		key  string
+		ttl  time.Duration
+		want bool
+	}{
+		{"created:dec-1", time.Hour, false},
+		{"created:dec-1", time.Hour, true},
+		{"created:dec-2", -time.Second, false}, // already expired
+		{"created:dec-2", time.Hour, false},
+	} {
+		if seen, err := store.MarkSeen(tt.key, tt.ttl); err != nil || seen != tt.want {
+			t.Errorf("MarkSeen(%s) = %v, %v; want %v", tt.key, seen, err, tt.want)
+		}
+	}
+}
+
+func TestMigrateFileState_SQLite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	legacy, err := NewStateManager(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = legacy.SetAgentCard("bot", MessageRef{ChannelID: "C1", Timestamp: "9.9"})
+
+	store, err := OpenSQLiteStateStore(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if migrated, err := MigrateFileState(path, store); err != nil || !migrated {
+		t.Fatalf("expected migration, got migrated=%v err=%v", migrated, err)
+	}
+	sm, err := NewStateManagerWithStore(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sm.GetAgentCard("bot"); !ok {
+		t.Error("expected agent card carried over")
+	}
+}
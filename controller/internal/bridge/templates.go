@@ -0,0 +1,314 @@
+// Package bridge provides Slack notification message templates.
+//
+// Notification wording for decisions, escalations, agent crashes, and jacks
+// is rendered from Go text/templates over a NotificationView. Defaults are
+// embedded (templates/notifications.tmpl); operators override individual
+// templates by name from a directory (typically a mounted ConfigMap) or from
+// a daemon config key, and changes are picked up without a redeploy.
+// Interactive elements (buttons, modals) remain built in Go.
+package bridge
+
+import (
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+//go:embed templates/notifications.tmpl
+var defaultTemplatesSrc string
+
+// defaultTemplates is parsed once; a parse failure is a build defect.
+var defaultTemplates = template.Must(template.New("notifications").
+	Funcs(template.FuncMap{"tr": Tr}).
+	Parse(defaultTemplatesSrc))
+
+// Notification template names.
+const (
+	tmplDecision    = "decision"
+	tmplEscalation  = "escalation"
+	tmplAgentCrash  = "agent_crash"
+	tmplJackOn      = "jack_on"
+	tmplJackOff     = "jack_off"
+	tmplJackExpired = "jack_expired"
+)
+
+// NotificationView is the data model passed to notification templates.
+type NotificationView struct {
+	Locale        string // message locale for {{tr .Locale "key"}}; empty = English
+	ID            string
+	Title         string
+	DisplayTitle  string // Title, or ID when untitled
+	Agent         string
+	Question      string
+	Priority      int
+	PriorityEmoji string
+	RequestedBy   string
+	Target        string // jack target
+	TTL           string // jack TTL
+	Reason        string
+	AgentState    string
+	PodPhase      string
+	PodName       string
+	Duration      string // how long a lowered jack was raised, e.g. "1h5m"
+	Outcome       string // close reason of a lowered jack
+	BeadURL       string // deep links; empty when not configured or unknown
+	CoopURL       string
+	DashboardURL  string
+	Fields        map[string]string // raw bead fields for custom templates
+}
+
+// notificationView builds the template view model for a bead.
+func notificationView(bead BeadEvent) NotificationView {
+	return NotificationView{
+		ID:            bead.ID,
+		Title:         bead.Title,
+		DisplayTitle:  beadTitle(bead.ID, bead.Title),
+		Agent:         bead.Assignee,
+		Question:      decisionQuestion(bead.Fields),
+		Priority:      bead.Priority,
+		PriorityEmoji: decisionPriorityEmoji(bead.Priority),
+		RequestedBy:   bead.Fields["requested_by"],
+		Target:        bead.Fields["target"],
+		TTL:           bead.Fields["ttl"],
+		Reason:        bead.Fields["reason"],
+		AgentState:    bead.Fields["agent_state"],
+		PodPhase:      bead.Fields["pod_phase"],
+		PodName:       bead.Fields["pod_name"],
+		Duration:      beadOpenDuration(bead),
+		Outcome:       bead.Fields["close_reason"],
+		Fields:        bead.Fields,
+	}
+}
+
+// beadOpenDuration returns how long a closed bead was open, or "" when the
+// daemon did not send both timestamps.
+func beadOpenDuration(bead BeadEvent) string {
+	created, err := time.Parse(time.RFC3339, bead.CreatedAt)
+	if err != nil {
+		return ""
+	}
+	closed, err := time.Parse(time.RFC3339, bead.ClosedAt)
+	if err != nil || closed.Before(created) {
+		return ""
+	}
+	return formatMuteDuration(closed.Sub(created))
+}
+
+// TemplateConfigClient reads template overrides from the beads daemon.
+// *beadsapi.Client satisfies it.
+type TemplateConfigClient interface {
+	GetConfig(ctx context.Context, key string) (*beadsapi.ConfigEntry, error)
+}
+
+// NotificationTemplatesConfig configures template override sources.
+type NotificationTemplatesConfig struct {
+	Dir       string               // directory of <name>.tmpl overrides; empty = none
+	Client    TemplateConfigClient // optional daemon config source
+	ConfigKey string               // daemon config key (default "slack-bridge:templates")
+	Interval  time.Duration        // reload poll interval (default 30s)
+	Logger    *slog.Logger
+}
+
+// NotificationTemplates holds the active template set and reloads overrides.
+// A nil *NotificationTemplates renders the embedded defaults.
+type NotificationTemplates struct {
+	dir       string
+	client    TemplateConfigClient
+	configKey string
+	interval  time.Duration
+	logger    *slog.Logger
+
+	mu          sync.RWMutex
+	active      *template.Template
+	fingerprint string // hash of the loaded overrides; skips no-op reloads
+}
+
+// NewNotificationTemplates creates a template set seeded with the defaults.
+// Call Reload (or Run) to apply overrides.
+func NewNotificationTemplates(cfg NotificationTemplatesConfig) *NotificationTemplates {
+	if cfg.ConfigKey == "" {
+		cfg.ConfigKey = "slack-bridge:templates"
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	return &NotificationTemplates{
+		dir:       cfg.Dir,
+		client:    cfg.Client,
+		configKey: cfg.ConfigKey,
+		interval:  cfg.Interval,
+		logger:    cfg.Logger,
+		active:    defaultTemplates,
+	}
+}
+
+// Run reloads overrides every interval until ctx is cancelled.
+func (n *NotificationTemplates) Run(ctx context.Context) {
+	if err := n.Reload(ctx); err != nil {
+		n.logger.Warn("failed to load notification templates, using defaults", "error", err)
+	}
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := n.Reload(ctx); err != nil {
+				n.logger.Warn("failed to reload notification templates, keeping previous", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reload reads overrides from the configured sources and swaps in the new
+// template set. Daemon config entries take precedence over directory files.
+// On any read or parse error the active set is left unchanged.
+func (n *NotificationTemplates) Reload(ctx context.Context) error {
+	overrides := make(map[string]string)
+	if n.dir != "" {
+		if err := readTemplateDir(n.dir, overrides); err != nil {
+			return err
+		}
+	}
+	if n.client != nil {
+		if err := n.readTemplateConfig(ctx, overrides); err != nil {
+			return err
+		}
+	}
+
+	fp := templatesFingerprint(overrides)
+	n.mu.RLock()
+	unchanged := fp == n.fingerprint
+	n.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	tmpl, err := parseTemplateOverrides(overrides)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.active = tmpl
+	n.fingerprint = fp
+	n.mu.Unlock()
+	n.logger.Info("loaded notification templates", "overrides", len(overrides))
+	return nil
+}
+
+// Render executes the named template. If an override fails to execute, the
+// embedded default is used so a bad edit never drops a notification.
+func (n *NotificationTemplates) Render(name string, view NotificationView) string {
+	tmpl := defaultTemplates
+	if n != nil {
+		n.mu.RLock()
+		tmpl = n.active
+		n.mu.RUnlock()
+	}
+
+	out, err := executeTemplate(tmpl, name, view)
+	if err == nil || tmpl == defaultTemplates {
+		return out
+	}
+	if n != nil {
+		n.logger.Warn("notification template failed, using default", "template", name, "error", err)
+	}
+	out, _ = executeTemplate(defaultTemplates, name, view)
+	return out
+}
+
+// executeTemplate renders a single named template.
+func executeTemplate(tmpl *template.Template, name string, view NotificationView) (string, error) {
+	var b strings.Builder
+	if err := tmpl.ExecuteTemplate(&b, name, view); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// parseTemplateOverrides returns the defaults with the given templates
+// redefined by name.
+func parseTemplateOverrides(overrides map[string]string) (*template.Template, error) {
+	tmpl, err := defaultTemplates.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(overrides) {
+		if _, err := tmpl.New(name).Parse(overrides[name]); err != nil {
+			return nil, fmt.Errorf("parse template %q: %w", name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+// readTemplateDir reads <name>.tmpl files into overrides. A missing
+// directory is not an error (the ConfigMap may be optional).
+func readTemplateDir(dir string, overrides map[string]string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return fmt.Errorf("list templates in %s: %w", dir, err)
+	}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("read template %s: %w", p, err)
+		}
+		overrides[strings.TrimSuffix(filepath.Base(p), ".tmpl")] = string(data)
+	}
+	return nil
+}
+
+// readTemplateConfig merges the daemon config entry (a JSON object of
+// template name → body) into overrides. A missing key is not an error.
+func (n *NotificationTemplates) readTemplateConfig(ctx context.Context, overrides map[string]string) error {
+	entry, err := n.client.GetConfig(ctx, n.configKey)
+	if err != nil {
+		var apiErr *beadsapi.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == 404 {
+			return nil
+		}
+		return fmt.Errorf("read template config %s: %w", n.configKey, err)
+	}
+	var m map[string]string
+	if err := json.Unmarshal(entry.Value, &m); err != nil {
+		return fmt.Errorf("decode template config %s: %w", n.configKey, err)
+	}
+	for name, body := range m {
+		overrides[name] = body
+	}
+	return nil
+}
+
+// templatesFingerprint hashes overrides so unchanged sources skip reparsing.
+func templatesFingerprint(overrides map[string]string) string {
+	h := sha256.New()
+	for _, name := range sortedKeys(overrides) {
+		fmt.Fprintf(h, "%s\x00%s\x00", name, overrides[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,141 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotificationTemplates_DefaultsMatchLegacyWording(t *testing.T) {
+	var n *NotificationTemplates // nil renders defaults
+
+	tests := []struct {
+		name string
+		bead BeadEvent
+		want string
+	}{
+		{
+			tmplDecision,
+			BeadEvent{ID: "dec-1", Priority: 1, Fields: map[string]string{"prompt": "Ship it?"}},
+			":red_circle: *Decision Needed*\nShip it?",
+		},
+		{
+			tmplEscalation + ".fallback",
+			BeadEvent{ID: "dec-1", Title: "Deploy", Fields: map[string]string{"question": "Now?"}},
+			"ESCALATED: Deploy — Now?",
+		},
+		{
+			tmplAgentCrash,
+			BeadEvent{ID: "a-1", Assignee: "bot", Fields: map[string]string{"pod_phase": "failed", "pod_name": "bot-0"}},
+			":warning: *Agent crashed: bot*\n> Pod phase: `failed`\n> Pod: `bot-0`",
+		},
+		{
+			tmplJackOn,
+			BeadEvent{ID: "j-1", Assignee: "bot", Fields: map[string]string{"target": "deploy/x", "ttl": "1h", "reason": "debug"}},
+			":wrench: *Jack Raised: j-1*\nTarget: `deploy/x`\nAgent: `bot`\nTTL: 1h\n> debug",
+		},
+		{
+			tmplJackOff,
+			BeadEvent{ID: "j-1", Fields: map[string]string{"target": "deploy/x"}},
+			":white_check_mark: *Jack Lowered: j-1*\nTarget: `deploy/x`",
+		},
+		{
+			tmplJackExpired,
+			BeadEvent{ID: "j-1", Fields: map[string]string{"target": "deploy/x", "reason": "debug"}},
+			":warning: *Jack Expired: j-1*\nTarget: `deploy/x`\n> debug\n_Review revert plan and close with_ `bd jack off j-1`",
+		},
+	}
+	for _, tt := range tests {
+		if got := n.Render(tt.name, notificationView(tt.bead)); got != tt.want {
+			t.Errorf("%s:\n got %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNotificationTemplates_JackOffDurationOutcomeAndLinks(t *testing.T) {
+	var n *NotificationTemplates
+	view := notificationView(BeadEvent{
+		ID:        "j-1",
+		CreatedAt: "2026-10-15T10:00:00Z",
+		ClosedAt:  "2026-10-15T11:05:30Z",
+		Fields:    map[string]string{"target": "deploy/x", "close_reason": "reverted"},
+	})
+	view.BeadURL = "https://beads.example.com/beads/j-1"
+	view.DashboardURL = "https://dash.example.com"
+
+	want := ":white_check_mark: *Jack Lowered: j-1*\nTarget: `deploy/x`\nRaised for: 1h6m\nOutcome: reverted\n" +
+		"<https://beads.example.com/beads/j-1|Bead> · <https://dash.example.com|Dashboard>"
+	if got := n.Render(tmplJackOff, view); got != want {
+		t.Errorf("got %q\nwant %q", got, want)
+	}
+}
+
+func TestNotificationTemplates_DirOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "jack_off.tmpl"), []byte("Jack {{.ID}} is down"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n := NewNotificationTemplates(NotificationTemplatesConfig{Dir: dir, Logger: slog.Default()})
+	if err := n.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	view := notificationView(BeadEvent{ID: "j-1"})
+	if got := n.Render(tmplJackOff, view); got != "Jack j-1 is down" {
+		t.Errorf("expected override, got %q", got)
+	}
+	if got := n.Render(tmplJackOff+".fallback", view); got != "Jack lowered: j-1" {
+		t.Errorf("expected untouched default fallback, got %q", got)
+	}
+
+	// Hot reload picks up edits.
+	_ = os.WriteFile(filepath.Join(dir, "jack_off.tmpl"), []byte("Jack {{.ID}} lowered!"), 0o644)
+	_ = n.Reload(context.Background())
+	if got := n.Render(tmplJackOff, view); got != "Jack j-1 lowered!" {
+		t.Errorf("expected reloaded override, got %q", got)
+	}
+}
+
+func TestNotificationTemplates_ConfigOverrideWinsOverDir(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "decision.tmpl"), []byte("from dir"), 0o644)
+
+	store := newFakeLeaseStore()
+	value, _ := json.Marshal(map[string]string{"decision": "from config: {{.Question}}"})
+	_ = store.SetConfig(context.Background(), "slack-bridge:templates", value)
+
+	n := NewNotificationTemplates(NotificationTemplatesConfig{Dir: dir, Client: store, Logger: slog.Default()})
+	if err := n.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	view := notificationView(BeadEvent{ID: "d", Fields: map[string]string{"prompt": "Go?"}})
+	if got := n.Render(tmplDecision, view); got != "from config: Go?" {
+		t.Errorf("expected config override, got %q", got)
+	}
+}
+
+func TestNotificationTemplates_BadOverrideKeepsPrevious(t *testing.T) {
+	dir := t.TempDir()
+	n := NewNotificationTemplates(NotificationTemplatesConfig{Dir: dir, Logger: slog.Default()})
+
+	_ = os.WriteFile(filepath.Join(dir, "jack_on.tmpl"), []byte("{{.ID"), 0o644)
+	if err := n.Reload(context.Background()); err == nil {
+		t.Fatal("expected parse error")
+	}
+	view := notificationView(BeadEvent{ID: "j-1", Fields: map[string]string{"target": "x"}})
+	if got := n.Render(tmplJackOn+".fallback", view); got != "Jack raised: j-1 on x" {
+		t.Errorf("expected defaults after failed reload, got %q", got)
+	}
+
+	// An override that fails at execution falls back to the default.
+	_ = os.WriteFile(filepath.Join(dir, "jack_on.tmpl"), []byte("{{.Missing}}"), 0o644)
+	if err := n.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := n.Render(tmplJackOn, view); got != ":wrench: *Jack Raised: j-1*\nTarget: `x`" {
+		t.Errorf("expected default on exec error, got %q", got)
+	}
+}
@@ -2,6 +2,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
 	"time"
@@ -20,6 +22,45 @@ type Config struct {
 	// Empty means use in-cluster config.
 	KubeConfig string
 
+	// ClusterName names the cluster the controller runs in, the home cluster
+	// for agent placement (env: CLUSTER_NAME). Default: "local".
+	ClusterName string
+
+	// ClusterMaxPods caps active agent pods on the home cluster under
+	// capacity placement (env: CLUSTER_MAX_PODS). 0 means unlimited.
+	ClusterMaxPods int
+
+	// ClusterPlacement selects the cluster for agents whose project bead
+	// does not pin one (env: CLUSTER_PLACEMENT): "project" keeps them on the
+	// home cluster, "capacity" fills clusters in order up to their max pods.
+	// Default: "project".
+	ClusterPlacement string
+
+	// AgentClusters is a JSON list of additional clusters agents may be
+	// placed on (env: AGENT_CLUSTERS), e.g.
+	// [{"name":"burst","kubeconfig":"/etc/gasboat/clusters/burst/kubeconfig","maxPods":40}].
+	// Use RemoteClusters to parse it.
+	AgentClusters string
+
+	// AgentBackend runs agents on "kubernetes" pods or on "docker"
+	// containers (env: AGENT_BACKEND), for installations without a
+	// cluster. See ValidateBackend for what the Docker backend lacks.
+	// Default: "kubernetes".
+	AgentBackend string
+
+	// DockerHost is the Docker (or Podman) engine agents run on with the
+	// docker backend (env: DOCKER_HOST). Default: "unix:///var/run/docker.sock".
+	DockerHost string
+
+	// DockerNetwork is the network agent containers join (env:
+	// DOCKER_NETWORK). It must reach the beads daemon. Empty uses the
+	// engine's default bridge.
+	DockerNetwork string
+
+	// DockerSecretsDir holds the secrets agent containers are given, one
+	// file per key at {dir}/{secret}/{key} (env: DOCKER_SECRETS_DIR).
+	DockerSecretsDir string
+
 	// --- Beads Daemon ---
 
 	// BeadsGRPCAddr is the beads daemon gRPC address, host:port (env: BEADS_GRPC_ADDR).
@@ -57,6 +98,37 @@ type Config struct {
 	// When set, all agent pods use this SA unless overridden by bead metadata.
 	CoopServiceAccount string
 
+	// AgentRBAC enables least-privilege mode (env: AGENT_RBAC): each project
+	// without a service_account override gets its own ServiceAccount and
+	// Role ("{project}-agent") for its agent pods, instead of
+	// COOP_SERVICE_ACCOUNT. Needs RBAC to manage ServiceAccounts, Roles,
+	// and RoleBindings in the agent namespace.
+	AgentRBAC bool
+
+	// AgentRBACRules is a JSON list of K8s RBAC policy rules granted to each
+	// project's agents in least-privilege mode (env: AGENT_RBAC_RULES).
+	// Empty grants read-only access to pods, pod logs, and events. Use
+	// AgentRoleRules to parse it.
+	AgentRBACRules string
+
+	// ImpersonateUser makes the controller impersonate this user for agent
+	// pod operations (env: IMPERSONATE_USER), so cluster audit logs attribute
+	// them to it rather than the controller's ServiceAccount.
+	ImpersonateUser string
+
+	// ImpersonateGroups is a comma-separated list of groups to impersonate
+	// along with ImpersonateUser (env: IMPERSONATE_GROUPS).
+	ImpersonateGroups string
+
+	// PodPolicy enables pod spec admission (env: POD_POLICY): every agent
+	// pod spec is checked against PodPolicyRules and the "pod-policy"
+	// config bead before the pod is created.
+	PodPolicy bool
+
+	// PodPolicyRules is the static pod policy, a JSON object with a "rules"
+	// list (env: POD_POLICY_RULES). See internal/policy.
+	PodPolicyRules string
+
 	// CoopMaxPods is the maximum number of agent pods that can exist
 	// simultaneously (env: COOP_MAX_PODS). 0 means unlimited.
 	// When the limit is reached, new pods are queued until existing ones finish.
@@ -80,6 +152,75 @@ type Config struct {
 	// Injected as CLAUDE_MODEL env var. When empty, Claude Code uses its default.
 	ClaudeModel string
 
+	// CapacityAdmission makes the reconciler check node capacity before
+	// creating agent pods (env: CAPACITY_ADMISSION). Pods that would not fit
+	// on any node are deferred and their beads marked waiting_capacity
+	// instead of sitting Pending. Needs RBAC to list nodes and pods in all
+	// namespaces.
+	CapacityAdmission bool
+
+	// AgentConfigMaps renders a ConfigMap per agent from its beads (role
+	// instructions, daemon address, project metadata, hooks) and restarts
+	// agents when it changes (env: AGENT_CONFIGMAPS). Needs RBAC to manage
+	// ConfigMaps in the agent namespace.
+	AgentConfigMaps bool
+
+	// WarmPool is a JSON list of idle pods to keep running per project and
+	// role (env: WARM_POOL), e.g. [{"project":"gasboat","role":"job","size":2}].
+	// An AgentSpawn event adopts a matching warm pod instead of creating one
+	// cold. Use WarmPools to parse it.
+	WarmPool string
+
+	// AgentTerminationGrace is how long a done or stopped agent has to
+	// checkpoint before its pod is deleted (env: AGENT_TERMINATION_GRACE).
+	// Default: 60s. The bead is marked terminating and the agent nudged
+	// through coop; agents see the window as BOAT_TERMINATION_GRACE. Killed
+	// agents, the docker backend, and zero delete pods at once.
+	AgentTerminationGrace time.Duration
+
+	// AdoptPods adopts agent pods the controller did not create, e.g. from
+	// manual deployments, at startup (env: ADOPT_PODS). A pod carrying the
+	// project, role, agent and mode labels but no matching agent bead gets
+	// one created from its labels, and its bead-id annotation is back-filled,
+	// instead of being deleted as an orphan.
+	AdoptPods bool
+
+	// SpotJobs schedules job-mode agents onto spot/preemptible nodes
+	// (env: SPOT_JOBS). Use SpotPolicy to parse the SPOT_* settings.
+	SpotJobs bool
+
+	// SpotNodeSelector is a JSON object of node labels selecting spot nodes
+	// (env: SPOT_NODE_SELECTOR). Default: {"karpenter.sh/capacity-type":"spot"}.
+	SpotNodeSelector string
+
+	// SpotTolerations is a JSON list of K8s tolerations for spot node taints
+	// (env: SPOT_TOLERATIONS), e.g.
+	// [{"key":"spot","operator":"Exists","effect":"NoSchedule"}].
+	SpotTolerations string
+
+	// OnDemandNodeSelector is a JSON object of node labels selecting
+	// on-demand nodes, used for job agents preempted too often to stay on
+	// spot (env: ON_DEMAND_NODE_SELECTOR).
+	// Default: {"karpenter.sh/capacity-type":"on-demand"}.
+	OnDemandNodeSelector string
+
+	// SpotMaxPreemptions is how many times a job agent may be preempted
+	// before it is rescheduled onto on-demand capacity
+	// (env: SPOT_MAX_PREEMPTIONS). Default: 2.
+	SpotMaxPreemptions int
+
+	// AgentArchImages is a JSON object mapping a CPU architecture to the
+	// agent image built for it (env: AGENT_ARCH_IMAGES), e.g.
+	// {"arm64":"ghcr.io/groblegark/gasboat/agent:latest-arm64"}. Used in place
+	// of COOP_IMAGE for agents running on that architecture. Use ArchPolicy
+	// to parse it.
+	AgentArchImages string
+
+	// AgentRoleArch is a JSON object choosing the architecture per role
+	// (env: AGENT_ROLE_ARCH), e.g. {"job":"arm64"}. A project or agent
+	// bead's arch field takes precedence.
+	AgentRoleArch string
+
 	// --- Secrets & Credentials ---
 
 	// ClaudeOAuthSecret is the K8s secret containing Claude OAuth credentials (env: CLAUDE_OAUTH_SECRET).
@@ -125,6 +266,54 @@ type Config struct {
 	// Injected as COOP_BROKER_TOKEN and COOP_MUX_TOKEN in agent pods.
 	CoopmuxTokenSecret string
 
+	// --- Artifact Export ---
+
+	// ArtifactBucket is the S3-compatible bucket agents export session
+	// artifacts to when they finish or fail (env: ARTIFACT_BUCKET). Empty
+	// disables export.
+	ArtifactBucket string
+
+	// ArtifactEndpoint is the object storage endpoint (env: ARTIFACT_ENDPOINT),
+	// e.g. "https://s3.us-east-1.amazonaws.com", "https://storage.googleapis.com",
+	// or a MinIO service URL.
+	ArtifactEndpoint string
+
+	// ArtifactRegion is the signing region (env: ARTIFACT_REGION). Default: "us-east-1".
+	ArtifactRegion string
+
+	// ArtifactPrefix is prepended to every exported object key (env: ARTIFACT_PREFIX).
+	ArtifactPrefix string
+
+	// ArtifactPaths is a comma-separated list of workspace globs to export
+	// (env: ARTIFACT_PATHS). Session logs are always exported. Default:
+	// patches and files under reports/.
+	ArtifactPaths string
+
+	// ArtifactCredentialsSecret is the K8s secret holding the bucket's HMAC
+	// keys "access_key_id" and "secret_access_key" (env: ARTIFACT_CREDENTIALS_SECRET).
+	ArtifactCredentialsSecret string
+
+	// --- Image Signature Verification ---
+
+	// ImageVerifyKeys holds PEM-encoded cosign public keys (env:
+	// IMAGE_VERIFY_KEYS). When it or ImageVerifyIdentities is set, agent
+	// pods are only created for images with a valid cosign signature; other
+	// beads are held as blocked_unsigned_image.
+	ImageVerifyKeys string
+
+	// ImageVerifyIdentities is a JSON list of trusted keyless signers,
+	// [{"issuer": "...", "subject": "<regexp>"}] (env: IMAGE_VERIFY_IDENTITIES).
+	// Keyless verification also needs ImageVerifyRoots and ImageVerifyRekorKey.
+	ImageVerifyIdentities string
+
+	// ImageVerifyRoots holds the PEM-encoded Fulcio root and intermediate
+	// certificates (env: IMAGE_VERIFY_ROOTS).
+	ImageVerifyRoots string
+
+	// ImageVerifyRekorKey holds the PEM-encoded Rekor public key (env:
+	// IMAGE_VERIFY_REKOR_KEY).
+	ImageVerifyRekorKey string
+
 	// --- Leader Election ---
 
 	// LeaderElection enables K8s lease-based leader election (env: ENABLE_LEADER_ELECTION).
@@ -144,6 +333,10 @@ type Config struct {
 	// Slack notifications are now handled by the standalone slack-bridge
 	// binary (cmd/slack-bridge). Slack config fields removed — see bd-8x8fy.
 
+	// SlackBridgeURL is the slack-bridge's base URL (env: SLACK_BRIDGE_URL).
+	// When set, /status reports whether the bridge is connected to Slack.
+	SlackBridgeURL string
+
 	// --- ExternalSecret Reconciliation ---
 
 	// ExternalSecretStoreName is the SecretStore name for auto-reconciled ExternalSecrets
@@ -160,40 +353,195 @@ type Config struct {
 
 	// --- Controller ---
 
+	// TaskIngestKey is the HMAC key that signs POST /ingest/task requests
+	// (env: TASK_INGEST_KEY). The endpoint is disabled when empty.
+	TaskIngestKey string
+
+	// AgentExecToken is the bearer token for POST /agent-exec, which runs
+	// commands in agent pods (env: AGENT_EXEC_TOKEN). Disabled when empty.
+	AgentExecToken string
+
+	// AgentLogsToken is the bearer token for GET /agent-logs, which serves
+	// agent container logs (env: AGENT_LOGS_TOKEN). Disabled when empty.
+	AgentLogsToken string
+
+	// SpawnPreviewToken is the bearer token for GET /spawn-preview, which
+	// resolves the pod configuration a prospective agent would get (env:
+	// SPAWN_PREVIEW_TOKEN). Disabled when empty.
+	SpawnPreviewToken string
+
+	// CoopTokenKey signs the short-lived tokens of the coop proxy,
+	// /coop/{agent}/ (env: COOP_TOKEN_KEY, at least 32 bytes). The proxy and
+	// POST /coop-token are disabled when empty.
+	CoopTokenKey string
+
+	// CoopTokenClientToken is the bearer token bridges present to
+	// POST /coop-token to mint coop tokens (env: COOP_TOKEN_CLIENT_TOKEN).
+	CoopTokenClientToken string
+
+	// CoopTokenTTL is how long minted coop tokens are valid (env:
+	// COOP_TOKEN_TTL).
+	CoopTokenTTL time.Duration
+
+	// AdminToken is the bearer token for the /admin/ API: forced reconciles,
+	// agent restarts, and per-project pause (env: ADMIN_TOKEN). Disabled
+	// when empty.
+	AdminToken string
+
+	// ErrorReportWindow is how often repeated warnings and errors are rolled
+	// up and published to the event bus as one controller.errors event
+	// (env: ERROR_REPORT_WINDOW). Default: 5m. Zero disables reporting.
+	ErrorReportWindow time.Duration
+
+	// ErrorReportCooldown is the minimum gap between reports of the same
+	// error (env: ERROR_REPORT_COOLDOWN). Default: 1h.
+	ErrorReportCooldown time.Duration
+
+	// StrictEventSchema drops beads SSE events carrying fields the event
+	// schema does not know instead of only counting them
+	// (env: STRICT_EVENT_SCHEMA). Default: false.
+	StrictEventSchema bool
+
+	// EventWorkers is the number of workers handling beads events
+	// (env: EVENT_WORKERS). Each agent's events run in order on one worker.
+	// Default: 8.
+	EventWorkers int
+
+	// EventQueueDepth is how many events each worker buffers
+	// (env: EVENT_QUEUE_DEPTH). Default: 64.
+	EventQueueDepth int
+
+	// EventQueueOverflow is what happens to an event whose worker's queue is
+	// full (env: EVENT_QUEUE_OVERFLOW): "park" waits for room, "shed" drops
+	// it and leaves the agent to the next reconcile pass. Default: park.
+	EventQueueOverflow string
+
+	// EventDedupTTL is how long handled beads events are remembered per bead
+	// to suppress SSE replays and daemon retries (env: EVENT_DEDUP_TTL).
+	// Default: 10m. Zero disables suppression.
+	EventDedupTTL time.Duration
+
+	// EventMaxAttempts is how often a failing beads event is tried before
+	// it is dead-lettered (env: EVENT_MAX_ATTEMPTS). Default: 3.
+	EventMaxAttempts int
+
+	// EventRetryBackoff is the wait before an event's first retry, doubled
+	// for each next one (env: EVENT_RETRY_BACKOFF). Default: 1s.
+	EventRetryBackoff time.Duration
+
+	// DeadLetterSize is how many events that failed every attempt are kept
+	// for /admin/dead-letters (env: DEAD_LETTER_SIZE). Default: 200. Zero
+	// disables dead-lettering; failed events are then only logged.
+	DeadLetterSize int
+
+	// DeadLetterFile persists dead letters to this local file instead of a
+	// daemon config entry (env: DEAD_LETTER_FILE).
+	DeadLetterFile string
+
+	// ReconcileHistorySize is how many reconcile passes that changed pods
+	// or failed are kept in the history served at /admin/history
+	// (env: RECONCILE_HISTORY_SIZE). Default: 200. Zero disables it.
+	ReconcileHistorySize int
+
+	// ReconcileHistoryFile persists the reconcile history to this local
+	// file instead of a daemon config entry (env: RECONCILE_HISTORY_FILE).
+	ReconcileHistoryFile string
+
+	// OnboardingTimeout bounds how long a project's onboarding smoke-test
+	// agent may take to become ready (env: ONBOARDING_TIMEOUT). Default: 10m.
+	OnboardingTimeout time.Duration
+
+	// CrashForensicsRestarts is how many times an agent's pod must crash
+	// within CrashForensicsWindow before a forensic bundle is collected
+	// onto an incident bead (env: CRASH_FORENSICS_RESTARTS). Default: 3.
+	// Zero disables collection.
+	CrashForensicsRestarts int
+
+	// CrashForensicsWindow is how far back failed pods of an agent count
+	// toward CrashForensicsRestarts (env: CRASH_FORENSICS_WINDOW).
+	// Default: 1h.
+	CrashForensicsWindow time.Duration
+
+	// SpecStages lists, comma-separated, the pod spec stages to run in order
+	// (env: SPEC_STAGES). Default: all of specbuilder.DefaultOrder.
+	SpecStages string
+
+	// AgentEvents writes Kubernetes Events on agent pods for spawns,
+	// restarts, drift upgrades and failures (env: AGENT_EVENTS). Default: true.
+	AgentEvents bool
+
 	// LogLevel controls log verbosity: debug, info, warn, error (env: LOG_LEVEL).
 	LogLevel string
 
-	// --- Runtime (not from env) ---
+	// --- Fault injection (testing only) ---
 
-	// ProjectCache maps project name → metadata, populated at runtime from project beads
-	// in the daemon. Not parsed from env.
-	ProjectCache map[string]ProjectCacheEntry
-}
+	// FaultInjection enables the chaos hooks below (env: FAULT_INJECTION).
+	// Never enable in production.
+	FaultInjection bool
+
+	// FaultK8sErrorRate is the probability a pod create/delete/list/get
+	// fails (env: FAULT_K8S_ERROR_RATE). Default: 0.
+	FaultK8sErrorRate float64
+
+	// FaultDaemonDelayRate is the probability a daemon request is delayed
+	// (env: FAULT_DAEMON_DELAY_RATE). Default: 0.
+	FaultDaemonDelayRate float64
+
+	// FaultDaemonDelay is the maximum injected daemon delay
+	// (env: FAULT_DAEMON_DELAY). Default: 5s.
+	FaultDaemonDelay time.Duration
+
+	// FaultSSEDropRate is the probability a beads SSE event is dropped
+	// (env: FAULT_SSE_DROP_RATE). Default: 0.
+	FaultSSEDropRate float64
 
-// ProjectCacheEntry holds project metadata from daemon project beads.
-type ProjectCacheEntry struct {
-	Prefix        string // e.g., "kd", "bot"
-	GitURL        string // e.g., "https://github.com/groblegark/kbeads.git"
-	DefaultBranch string // e.g., "main"
-
-	// Per-project pod customization (from project bead labels).
-	Image          string // Override agent image for this project
-	StorageClass   string // Override PVC storage class
-	ServiceAccount string // Override K8s ServiceAccount for this project's agents
-	RTKEnabled     bool   // Enable RTK token optimization for this project's agents
-
-	// Per-project secret overrides (merged with globals at pod creation).
-	Secrets []beadsapi.SecretEntry
-	// Multi-repo definitions (primary + reference repos).
-	Repos []beadsapi.RepoEntry
+	// FaultSeed seeds fault decisions for reproducible runs
+	// (env: FAULT_SEED). Default: 0 (random).
+	FaultSeed int
+
+	// --- Runtime (not from env) ---
+
+	// ProjectCache maps project name → metadata, populated at runtime from
+	// project beads in the daemon. Not parsed from env. Replaced by the
+	// periodic sync like TemplateCache; take one Load per operation.
+	ProjectCache *Snapshot[map[string]ProjectCacheEntry]
+
+	// TemplateCache maps template name → template bead, populated at
+	// runtime from template beads in the daemon. Agent beads naming a
+	// template (beadsapi.TemplateField) are resolved against it. The
+	// periodic sync replaces it while events are handled; take one Load
+	// per operation.
+	TemplateCache *Snapshot[map[string]beadsapi.TemplateInfo]
+
+	// RoleCache maps role name → pod defaults, populated at runtime from
+	// role beads in the daemon. Not parsed from env. Replaced by the
+	// periodic sync like TemplateCache; take one Load per operation.
+	RoleCache *Snapshot[map[string]RoleCacheEntry]
+
+	// Spot is the parsed spot placement policy for job agents, set at
+	// startup from SpotPolicy. Nil when SpotJobs is off.
+	Spot *SpotPolicy
+
+	// Arch is the parsed architecture policy, set at startup from
+	// ArchPolicy. Nil when neither AGENT_ARCH_IMAGES nor AGENT_ROLE_ARCH
+	// is set.
+	Arch *ArchPolicy
 }
 
 // Parse reads configuration from environment variables.
 func Parse() *Config {
 	return &Config{
 		// Kubernetes
-		Namespace:  envOr("NAMESPACE", "gasboat"),
-		KubeConfig: os.Getenv("KUBECONFIG"),
+		Namespace:        envOr("NAMESPACE", "gasboat"),
+		KubeConfig:       os.Getenv("KUBECONFIG"),
+		ClusterName:      envOr("CLUSTER_NAME", "local"),
+		ClusterMaxPods:   envIntOr("CLUSTER_MAX_PODS", 0),
+		ClusterPlacement: envOr("CLUSTER_PLACEMENT", "project"),
+		AgentClusters:    os.Getenv("AGENT_CLUSTERS"),
+		AgentBackend:     envOr("AGENT_BACKEND", BackendKubernetes),
+		DockerHost:       envOr("DOCKER_HOST", "unix:///var/run/docker.sock"),
+		DockerNetwork:    os.Getenv("DOCKER_NETWORK"),
+		DockerSecretsDir: os.Getenv("DOCKER_SECRETS_DIR"),
 
 		// Beads Daemon
 		BeadsGRPCAddr:    envOr("BEADS_GRPC_ADDR", "localhost:9090"),
@@ -208,11 +556,35 @@ func Parse() *Config {
 		// Agent Pods
 		CoopImage:          os.Getenv("COOP_IMAGE"),
 		CoopServiceAccount: os.Getenv("COOP_SERVICE_ACCOUNT"),
+		AgentRBAC:          envBoolOr("AGENT_RBAC", false),
+		AgentRBACRules:     os.Getenv("AGENT_RBAC_RULES"),
+		ImpersonateUser:    os.Getenv("IMPERSONATE_USER"),
+		ImpersonateGroups:  os.Getenv("IMPERSONATE_GROUPS"),
+		PodPolicy:          envBoolOr("POD_POLICY", false),
+		PodPolicyRules:     os.Getenv("POD_POLICY_RULES"),
 		CoopMaxPods:        envIntOr("COOP_MAX_PODS", 0),
 		CoopBurstLimit:     envIntOr("COOP_BURST_LIMIT", 3),
 		CoopSyncInterval:   envDurationOr("COOP_SYNC_INTERVAL", 60*time.Second),
 		AgentStorageClass:  os.Getenv("AGENT_STORAGE_CLASS"),
 		ClaudeModel:        os.Getenv("CLAUDE_MODEL"),
+		CapacityAdmission:  envBoolOr("CAPACITY_ADMISSION", false),
+		AgentConfigMaps:    envBoolOr("AGENT_CONFIGMAPS", false),
+		WarmPool:           os.Getenv("WARM_POOL"),
+		AdoptPods:          envBoolOr("ADOPT_PODS", false),
+
+		// Agent shutdown
+		AgentTerminationGrace: envDurationOr("AGENT_TERMINATION_GRACE", 60*time.Second),
+
+		// Spot capacity
+		SpotJobs:             envBoolOr("SPOT_JOBS", false),
+		SpotNodeSelector:     envOr("SPOT_NODE_SELECTOR", `{"karpenter.sh/capacity-type":"spot"}`),
+		SpotTolerations:      os.Getenv("SPOT_TOLERATIONS"),
+		OnDemandNodeSelector: envOr("ON_DEMAND_NODE_SELECTOR", `{"karpenter.sh/capacity-type":"on-demand"}`),
+		SpotMaxPreemptions:   envIntOr("SPOT_MAX_PREEMPTIONS", 2),
+
+		// Architecture
+		AgentArchImages: os.Getenv("AGENT_ARCH_IMAGES"),
+		AgentRoleArch:   os.Getenv("AGENT_ROLE_ARCH"),
 
 		// Secrets & Credentials
 		ClaudeOAuthSecret:      os.Getenv("CLAUDE_OAUTH_SECRET"),
@@ -227,12 +599,27 @@ func Parse() *Config {
 		CoopmuxURL:         os.Getenv("COOPMUX_URL"),
 		CoopmuxTokenSecret: os.Getenv("COOPMUX_TOKEN_SECRET"),
 
+		// Artifact Export
+		ArtifactBucket:            os.Getenv("ARTIFACT_BUCKET"),
+		ArtifactEndpoint:          os.Getenv("ARTIFACT_ENDPOINT"),
+		ArtifactRegion:            envOr("ARTIFACT_REGION", "us-east-1"),
+		ArtifactPrefix:            os.Getenv("ARTIFACT_PREFIX"),
+		ArtifactPaths:             os.Getenv("ARTIFACT_PATHS"),
+		ArtifactCredentialsSecret: os.Getenv("ARTIFACT_CREDENTIALS_SECRET"),
+
+		// Image Signature Verification
+		ImageVerifyKeys:       os.Getenv("IMAGE_VERIFY_KEYS"),
+		ImageVerifyIdentities: os.Getenv("IMAGE_VERIFY_IDENTITIES"),
+		ImageVerifyRoots:      os.Getenv("IMAGE_VERIFY_ROOTS"),
+		ImageVerifyRekorKey:   os.Getenv("IMAGE_VERIFY_REKOR_KEY"),
+
 		// Leader Election
 		LeaderElection:         envBoolOr("ENABLE_LEADER_ELECTION", false),
 		LeaderElectionID:       envOr("LEADER_ELECTION_ID", "agents-leader"),
 		LeaderElectionIdentity: envOr("POD_NAME", hostname()),
 
 		// Slack config removed — handled by standalone slack-bridge (bd-8x8fy).
+		SlackBridgeURL: os.Getenv("SLACK_BRIDGE_URL"),
 
 		// ExternalSecret Reconciliation
 		ExternalSecretStoreName:       envOr("EXTERNAL_SECRET_STORE_NAME", "secretstore"),
@@ -240,10 +627,90 @@ func Parse() *Config {
 		ExternalSecretRefreshInterval: envOr("EXTERNAL_SECRET_REFRESH_INTERVAL", "15m"),
 
 		// Controller
-		LogLevel: envOr("LOG_LEVEL", "info"),
+		TaskIngestKey:        os.Getenv("TASK_INGEST_KEY"),
+		AgentExecToken:       os.Getenv("AGENT_EXEC_TOKEN"),
+		AgentLogsToken:       os.Getenv("AGENT_LOGS_TOKEN"),
+		SpawnPreviewToken:    os.Getenv("SPAWN_PREVIEW_TOKEN"),
+		CoopTokenKey:         os.Getenv("COOP_TOKEN_KEY"),
+		CoopTokenClientToken: os.Getenv("COOP_TOKEN_CLIENT_TOKEN"),
+		CoopTokenTTL:         envDurationOr("COOP_TOKEN_TTL", time.Hour),
+		AdminToken:           os.Getenv("ADMIN_TOKEN"),
+		ErrorReportWindow:    envDurationOr("ERROR_REPORT_WINDOW", 5*time.Minute),
+		ErrorReportCooldown:  envDurationOr("ERROR_REPORT_COOLDOWN", time.Hour),
+		StrictEventSchema:    envBoolOr("STRICT_EVENT_SCHEMA", false),
+		EventWorkers:         envIntOr("EVENT_WORKERS", 8),
+		EventQueueDepth:      envIntOr("EVENT_QUEUE_DEPTH", 64),
+		EventQueueOverflow:   envOr("EVENT_QUEUE_OVERFLOW", "park"),
+		EventDedupTTL:        envDurationOr("EVENT_DEDUP_TTL", 10*time.Minute),
+		EventMaxAttempts:     envIntOr("EVENT_MAX_ATTEMPTS", 3),
+		EventRetryBackoff:    envDurationOr("EVENT_RETRY_BACKOFF", time.Second),
+		DeadLetterSize:       envIntOr("DEAD_LETTER_SIZE", 200),
+		DeadLetterFile:       os.Getenv("DEAD_LETTER_FILE"),
+		ReconcileHistorySize: envIntOr("RECONCILE_HISTORY_SIZE", 200),
+		ReconcileHistoryFile: os.Getenv("RECONCILE_HISTORY_FILE"),
+		OnboardingTimeout:    envDurationOr("ONBOARDING_TIMEOUT", 10*time.Minute),
+		SpecStages:           os.Getenv("SPEC_STAGES"),
+		AgentEvents:          envBoolOr("AGENT_EVENTS", true),
+		LogLevel:             envOr("LOG_LEVEL", "info"),
+
+		// Crash-loop forensics
+		CrashForensicsRestarts: envIntOr("CRASH_FORENSICS_RESTARTS", 3),
+		CrashForensicsWindow:   envDurationOr("CRASH_FORENSICS_WINDOW", time.Hour),
+
+		// Fault injection
+		FaultInjection:       envBoolOr("FAULT_INJECTION", false),
+		FaultK8sErrorRate:    envFloatOr("FAULT_K8S_ERROR_RATE", 0),
+		FaultDaemonDelayRate: envFloatOr("FAULT_DAEMON_DELAY_RATE", 0),
+		FaultDaemonDelay:     envDurationOr("FAULT_DAEMON_DELAY", 5*time.Second),
+		FaultSSEDropRate:     envFloatOr("FAULT_SSE_DROP_RATE", 0),
+		FaultSeed:            envIntOr("FAULT_SEED", 0),
 	}
 }
 
+// ImageVerification reports whether agent image signatures are verified.
+func (c *Config) ImageVerification() bool {
+	return c.ImageVerifyKeys != "" || c.ImageVerifyIdentities != ""
+}
+
+// Agent backends (AgentBackend).
+const (
+	BackendKubernetes = "kubernetes"
+	BackendDocker     = "docker"
+)
+
+// ValidateBackend checks AgentBackend and rejects settings its backend
+// can't honor. The docker backend has no cluster, so no remote clusters,
+// leader election, rendered ConfigMaps, RBAC, warm pools, pod adoption,
+// capacity admission or pod exec.
+func (c *Config) ValidateBackend() error {
+	switch c.AgentBackend {
+	case BackendKubernetes:
+		return nil
+	case BackendDocker:
+	default:
+		return fmt.Errorf("AGENT_BACKEND %q: must be %s or %s", c.AgentBackend, BackendKubernetes, BackendDocker)
+	}
+	var errs []error
+	for _, s := range []struct {
+		env string
+		set bool
+	}{
+		{"AGENT_CLUSTERS", c.AgentClusters != ""},
+		{"ENABLE_LEADER_ELECTION", c.LeaderElection},
+		{"AGENT_CONFIGMAPS", c.AgentConfigMaps},
+		{"AGENT_RBAC", c.AgentRBAC},
+		{"WARM_POOL", c.WarmPool != ""},
+		{"ADOPT_PODS", c.AdoptPods},
+		{"CAPACITY_ADMISSION", c.CapacityAdmission},
+		{"AGENT_EXEC_TOKEN", c.AgentExecToken != ""},
+	} {
+		if s.set {
+			errs = append(errs, fmt.Errorf("%s is not supported with AGENT_BACKEND=docker", s.env))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func envOr(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -260,6 +727,15 @@ func envIntOr(key string, fallback int) int {
 	return fallback
 }
 
+func envFloatOr(key string, fallback float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
+
 func envBoolOr(key string, fallback bool) bool {
 	if v := os.Getenv(key); v != "" {
 		b, err := strconv.ParseBool(v)
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// ProjectCacheEntry holds project metadata from daemon project beads.
+type ProjectCacheEntry struct {
+	Prefix        string // e.g., "kd", "bot"
+	GitURL        string // e.g., "https://github.com/groblegark/kbeads.git"
+	DefaultBranch string // e.g., "main"
+
+	// Per-project pod customization (from project bead labels).
+	Image          string // Override agent image for this project
+	StorageClass   string // Override PVC storage class
+	ServiceAccount string // Override K8s ServiceAccount for this project's agents
+	RTKEnabled     bool   // Enable RTK token optimization for this project's agents
+
+	// ReconcilePaused stops the reconciler from creating, deleting, or
+	// upgrading this project's pods (set via the controller admin API).
+	ReconcilePaused bool
+
+	// Cluster pins this project's agents to a named cluster. Empty leaves
+	// placement to the controller's placement policy.
+	Cluster string
+
+	// Schedule is the project's agent active hours (project bead
+	// "schedule" field). Empty means always active.
+	Schedule string
+
+	// Arch is the CPU architecture for the project's agents (project bead
+	// "arch" field). Empty leaves it to the role default.
+	Arch string
+
+	// TerminationGrace is how long the project's agent pods get to shut
+	// down once deleted (project bead "termination_grace" field). Zero
+	// leaves it to the role.
+	TerminationGrace time.Duration
+
+	// PreStop is the preStop hook template of the project's agent pods
+	// (project bead "prestop" field). Empty leaves it to the role.
+	PreStop string
+
+	// Name resolution in the project's agent pods (project bead
+	// host_aliases, dns_policy and dns_config fields).
+	HostAliases []corev1.HostAlias
+	DNSPolicy   corev1.DNSPolicy
+	DNSConfig   *corev1.PodDNSConfig
+
+	// Egress proxy of the project's agent pods (project bead http_proxy,
+	// https_proxy and no_proxy fields).
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+
+	// Onboarding is the project's onboarding state (project bead
+	// "onboarding" field). Agents are only scheduled once it has passed,
+	// or if the project was never onboarded.
+	Onboarding string
+
+	// BudgetPaused holds back new agents of a project that reached its
+	// daily hard budget (project bead "budget_status" field), until the
+	// next UTC day, a reset, or an override.
+	BudgetPaused bool
+
+	// Per-project secret overrides (merged with globals at pod creation).
+	Secrets []beadsapi.SecretEntry
+	// Multi-repo definitions (primary + reference repos).
+	Repos []beadsapi.RepoEntry
+}
+
+// RoleCacheEntry holds per-role pod defaults from daemon role beads. They
+// apply on top of the mode defaults; project and agent bead settings win.
+type RoleCacheEntry struct {
+	Image        string                       // Agent image for the role
+	StorageClass string                       // Workspace PVC storage class
+	StorageSize  string                       // Workspace PVC size; gives jobs a workspace PVC too
+	Env          map[string]string            // Extra pod env vars
+	Resources    *corev1.ResourceRequirements // Agent container resources
+
+	// Instructions are the role's instructions.md, used when the agent
+	// bead has none.
+	Instructions string
+
+	// TerminationGrace is how long the role's agent pods get to shut down
+	// once deleted. Zero leaves the mode default.
+	TerminationGrace time.Duration
+
+	// PreStop is the preStop hook template of the role's agent pods (see
+	// package prestop). Empty leaves the default hook.
+	PreStop string
+}
+
+// SpotPolicy places job-mode agents on spot nodes until they have been
+// preempted MaxPreemptions times, then on on-demand nodes.
+type SpotPolicy struct {
+	NodeSelector         map[string]string
+	Tolerations          []corev1.Toleration
+	OnDemandNodeSelector map[string]string
+	MaxPreemptions       int
+}
+
+// SpotPolicy parses the SPOT_* settings. It returns nil when SpotJobs is off.
+func (c *Config) SpotPolicy() (*SpotPolicy, error) {
+	if !c.SpotJobs {
+		return nil, nil
+	}
+	p := &SpotPolicy{MaxPreemptions: c.SpotMaxPreemptions}
+	if err := json.Unmarshal([]byte(c.SpotNodeSelector), &p.NodeSelector); err != nil {
+		return nil, fmt.Errorf("parsing SPOT_NODE_SELECTOR: %w", err)
+	}
+	if len(p.NodeSelector) == 0 {
+		return nil, fmt.Errorf("SPOT_NODE_SELECTOR must select at least one node label")
+	}
+	if c.SpotTolerations != "" {
+		if err := json.Unmarshal([]byte(c.SpotTolerations), &p.Tolerations); err != nil {
+			return nil, fmt.Errorf("parsing SPOT_TOLERATIONS: %w", err)
+		}
+	}
+	if c.OnDemandNodeSelector != "" {
+		if err := json.Unmarshal([]byte(c.OnDemandNodeSelector), &p.OnDemandNodeSelector); err != nil {
+			return nil, fmt.Errorf("parsing ON_DEMAND_NODE_SELECTOR: %w", err)
+		}
+	}
+	if p.MaxPreemptions < 1 {
+		return nil, fmt.Errorf("SPOT_MAX_PREEMPTIONS must be at least 1, got %d", p.MaxPreemptions)
+	}
+	return p, nil
+}
+
+// ArchPolicy chooses agent images and nodes by CPU architecture.
+type ArchPolicy struct {
+	Images   map[string]string // arch -> agent image
+	RoleArch map[string]string // role -> arch
+}
+
+// ArchPolicy parses AGENT_ARCH_IMAGES and AGENT_ROLE_ARCH. It returns nil
+// when neither is set.
+func (c *Config) ArchPolicy() (*ArchPolicy, error) {
+	if c.AgentArchImages == "" && c.AgentRoleArch == "" {
+		return nil, nil
+	}
+	p := &ArchPolicy{}
+	if c.AgentArchImages != "" {
+		if err := json.Unmarshal([]byte(c.AgentArchImages), &p.Images); err != nil {
+			return nil, fmt.Errorf("parsing AGENT_ARCH_IMAGES: %w", err)
+		}
+	}
+	if c.AgentRoleArch != "" {
+		if err := json.Unmarshal([]byte(c.AgentRoleArch), &p.RoleArch); err != nil {
+			return nil, fmt.Errorf("parsing AGENT_ROLE_ARCH: %w", err)
+		}
+	}
+	for arch, image := range p.Images {
+		if arch == beadsapi.ArchAny || !beadsapi.ValidArch(arch) {
+			return nil, fmt.Errorf("AGENT_ARCH_IMAGES: unknown architecture %q", arch)
+		}
+		if image == "" {
+			return nil, fmt.Errorf("AGENT_ARCH_IMAGES: empty image for %s", arch)
+		}
+	}
+	for role, arch := range p.RoleArch {
+		if !beadsapi.ValidArch(arch) {
+			return nil, fmt.Errorf("AGENT_ROLE_ARCH: role %s: unknown architecture %q", role, arch)
+		}
+	}
+	return p, nil
+}
+
+// AgentRoleRules parses AgentRBACRules. It returns nil when unset.
+func (c *Config) AgentRoleRules() ([]rbacv1.PolicyRule, error) {
+	if c.AgentRBACRules == "" {
+		return nil, nil
+	}
+	var rules []rbacv1.PolicyRule
+	if err := json.Unmarshal([]byte(c.AgentRBACRules), &rules); err != nil {
+		return nil, fmt.Errorf("parsing AGENT_RBAC_RULES: %w", err)
+	}
+	for i, r := range rules {
+		if len(r.Verbs) == 0 || (len(r.Resources) == 0 && len(r.NonResourceURLs) == 0) {
+			return nil, fmt.Errorf("AGENT_RBAC_RULES[%d]: verbs and resources are required", i)
+		}
+		if len(r.NonResourceURLs) > 0 {
+			return nil, fmt.Errorf("AGENT_RBAC_RULES[%d]: nonResourceURLs can't be granted by a Role", i)
+		}
+	}
+	return rules, nil
+}
+
+// ClusterConfig describes a remote cluster agents may be placed on.
+type ClusterConfig struct {
+	Name       string `json:"name"`
+	KubeConfig string `json:"kubeconfig"`
+	// MaxPods caps active agent pods on the cluster under capacity
+	// placement. 0 means unlimited.
+	MaxPods int `json:"maxPods"`
+}
+
+// RemoteClusters parses AgentClusters. Names must be unique and differ from
+// the home ClusterName.
+func (c *Config) RemoteClusters() ([]ClusterConfig, error) {
+	if c.AgentClusters == "" {
+		return nil, nil
+	}
+	var clusters []ClusterConfig
+	if err := json.Unmarshal([]byte(c.AgentClusters), &clusters); err != nil {
+		return nil, fmt.Errorf("parsing AGENT_CLUSTERS: %w", err)
+	}
+	seen := map[string]bool{c.ClusterName: true}
+	for i, cl := range clusters {
+		switch {
+		case cl.Name == "":
+			return nil, fmt.Errorf("AGENT_CLUSTERS[%d]: name is required", i)
+		case seen[cl.Name]:
+			return nil, fmt.Errorf("AGENT_CLUSTERS[%d]: cluster %q is defined more than once", i, cl.Name)
+		case cl.KubeConfig == "":
+			return nil, fmt.Errorf("AGENT_CLUSTERS[%d]: kubeconfig is required for cluster %q", i, cl.Name)
+		}
+		seen[cl.Name] = true
+	}
+	return clusters, nil
+}
+
+// WarmPoolConfig sizes the warm pool for one project and role.
+type WarmPoolConfig struct {
+	Project string `json:"project"`
+	Role    string `json:"role"`
+	Size    int    `json:"size"`
+}
+
+// WarmPools parses WarmPool. Each project/role pair may appear once and needs
+// a positive size.
+func (c *Config) WarmPools() ([]WarmPoolConfig, error) {
+	if c.WarmPool == "" {
+		return nil, nil
+	}
+	var pools []WarmPoolConfig
+	if err := json.Unmarshal([]byte(c.WarmPool), &pools); err != nil {
+		return nil, fmt.Errorf("parsing WARM_POOL: %w", err)
+	}
+	seen := make(map[string]bool)
+	for i, p := range pools {
+		key := p.Project + "/" + p.Role
+		switch {
+		case p.Project == "" || p.Role == "":
+			return nil, fmt.Errorf("WARM_POOL[%d]: project and role are required", i)
+		case p.Size <= 0:
+			return nil, fmt.Errorf("WARM_POOL[%d]: size must be positive for %s", i, key)
+		case seen[key]:
+			return nil, fmt.Errorf("WARM_POOL[%d]: %s is defined more than once", i, key)
+		}
+		seen[key] = true
+	}
+	return pools, nil
+}
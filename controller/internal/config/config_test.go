@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -111,6 +112,22 @@ func TestEnvBoolOr_Unset(t *testing.T) {
 	}
 }
 
+// --- envFloatOr tests ---
+
+func TestEnvFloatOr_Valid(t *testing.T) {
+	t.Setenv("TEST_FLOAT", "0.25")
+	if got := envFloatOr("TEST_FLOAT", 1); got != 0.25 {
+		t.Errorf("envFloatOr = %v, want 0.25", got)
+	}
+}
+
+func TestEnvFloatOr_Invalid(t *testing.T) {
+	t.Setenv("TEST_FLOAT_BAD", "often")
+	if got := envFloatOr("TEST_FLOAT_BAD", 0.5); got != 0.5 {
+		t.Errorf("envFloatOr with invalid should return fallback, got %v", got)
+	}
+}
+
 // --- envDurationOr tests ---
 
 func TestEnvDurationOr_Valid(t *testing.T) {
@@ -301,3 +318,172 @@ func TestParse_LeaderElectionIdentity_DefaultsToHostname(t *testing.T) {
 		t.Errorf("LeaderElectionIdentity = %s, want hostname %s", cfg.LeaderElectionIdentity, expected)
 	}
 }
+
+// --- RemoteClusters tests ---
+
+func TestRemoteClusters(t *testing.T) {
+	cfg := &Config{
+		ClusterName:   "local",
+		AgentClusters: `[{"name":"burst","kubeconfig":"/etc/gasboat/clusters/burst/kubeconfig","maxPods":40}]`,
+	}
+	clusters, err := cfg.RemoteClusters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "burst" || clusters[0].MaxPods != 40 {
+		t.Errorf("RemoteClusters() = %+v", clusters)
+	}
+
+	for _, bad := range []string{
+		`not json`,
+		`[{"kubeconfig":"/k"}]`,
+		`[{"name":"burst"}]`,
+		`[{"name":"local","kubeconfig":"/k"}]`,
+		`[{"name":"burst","kubeconfig":"/k"},{"name":"burst","kubeconfig":"/k2"}]`,
+	} {
+		cfg.AgentClusters = bad
+		if _, err := cfg.RemoteClusters(); err == nil {
+			t.Errorf("expected error for AGENT_CLUSTERS=%s", bad)
+		}
+	}
+}
+
+func TestValidateBackend(t *testing.T) {
+	if err := (&Config{AgentBackend: BackendKubernetes, AgentRBAC: true}).ValidateBackend(); err != nil {
+		t.Errorf("kubernetes: %v", err)
+	}
+	if err := (&Config{AgentBackend: BackendDocker}).ValidateBackend(); err != nil {
+		t.Errorf("docker: %v", err)
+	}
+	if err := (&Config{AgentBackend: "nomad"}).ValidateBackend(); err == nil {
+		t.Error("unknown backend accepted")
+	}
+	err := (&Config{AgentBackend: BackendDocker, LeaderElection: true, WarmPool: "[]"}).ValidateBackend()
+	for _, want := range []string{"ENABLE_LEADER_ELECTION", "WARM_POOL"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %s", err, want)
+		}
+	}
+}
+
+func TestWarmPools(t *testing.T) {
+	cfg := &Config{WarmPool: `[{"project":"gasboat","role":"job","size":2}]`}
+	pools, err := cfg.WarmPools()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pools) != 1 || pools[0].Project != "gasboat" || pools[0].Role != "job" || pools[0].Size != 2 {
+		t.Errorf("WarmPools() = %+v", pools)
+	}
+
+	for _, bad := range []string{
+		`not json`,
+		`[{"role":"job","size":1}]`,
+		`[{"project":"gasboat","role":"job"}]`,
+		`[{"project":"gasboat","role":"job","size":1},{"project":"gasboat","role":"job","size":3}]`,
+	} {
+		cfg.WarmPool = bad
+		if _, err := cfg.WarmPools(); err == nil {
+			t.Errorf("expected error for WARM_POOL=%s", bad)
+		}
+	}
+}
+
+func TestSpotPolicy(t *testing.T) {
+	cfg := &Config{}
+	if p, err := cfg.SpotPolicy(); p != nil || err != nil {
+		t.Fatalf("SpotPolicy() with SpotJobs off = %v, %v; want nil, nil", p, err)
+	}
+
+	cfg = &Config{
+		SpotJobs:             true,
+		SpotNodeSelector:     `{"karpenter.sh/capacity-type":"spot"}`,
+		SpotTolerations:      `[{"key":"spot","operator":"Exists","effect":"NoSchedule"}]`,
+		OnDemandNodeSelector: `{"karpenter.sh/capacity-type":"on-demand"}`,
+		SpotMaxPreemptions:   2,
+	}
+	p, err := cfg.SpotPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.NodeSelector["karpenter.sh/capacity-type"] != "spot" || len(p.Tolerations) != 1 ||
+		p.Tolerations[0].Key != "spot" || p.OnDemandNodeSelector["karpenter.sh/capacity-type"] != "on-demand" {
+		t.Errorf("SpotPolicy() = %+v", p)
+	}
+
+	for name, mutate := range map[string]func(*Config){
+		"bad selector":      func(c *Config) { c.SpotNodeSelector = "spot" },
+		"empty selector":    func(c *Config) { c.SpotNodeSelector = "{}" },
+		"bad tolerations":   func(c *Config) { c.SpotTolerations = "{}" },
+		"zero preemptions":  func(c *Config) { c.SpotMaxPreemptions = 0 },
+		"bad on-demand sel": func(c *Config) { c.OnDemandNodeSelector = "[" },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if _, err := bad.SpotPolicy(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestArchPolicy(t *testing.T) {
+	cfg := &Config{}
+	if p, err := cfg.ArchPolicy(); p != nil || err != nil {
+		t.Fatalf("ArchPolicy() with nothing set = %v, %v; want nil, nil", p, err)
+	}
+
+	cfg = &Config{
+		AgentArchImages: `{"arm64":"agent:v1-arm64"}`,
+		AgentRoleArch:   `{"job":"arm64","crew":"any"}`,
+	}
+	p, err := cfg.ArchPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Images["arm64"] != "agent:v1-arm64" || p.RoleArch["job"] != "arm64" || p.RoleArch["crew"] != "any" {
+		t.Errorf("ArchPolicy() = %+v", p)
+	}
+
+	for name, mutate := range map[string]func(*Config){
+		"bad images":        func(c *Config) { c.AgentArchImages = "arm64" },
+		"unknown arch":      func(c *Config) { c.AgentArchImages = `{"x86":"agent:v1"}` },
+		"image for any":     func(c *Config) { c.AgentArchImages = `{"any":"agent:v1"}` },
+		"empty image":       func(c *Config) { c.AgentArchImages = `{"arm64":""}` },
+		"bad role arch":     func(c *Config) { c.AgentRoleArch = `["job"]` },
+		"unknown role arch": func(c *Config) { c.AgentRoleArch = `{"job":"s390x"}` },
+	} {
+		bad := *cfg
+		mutate(&bad)
+		if _, err := bad.ArchPolicy(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestAgentRoleRules(t *testing.T) {
+	cfg := &Config{}
+	if rules, err := cfg.AgentRoleRules(); rules != nil || err != nil {
+		t.Fatalf("AgentRoleRules() with nothing set = %v, %v; want nil, nil", rules, err)
+	}
+
+	cfg.AgentRBACRules = `[{"apiGroups":[""],"resources":["configmaps"],"verbs":["get","list"]}]`
+	rules, err := cfg.AgentRoleRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].Resources[0] != "configmaps" || len(rules[0].Verbs) != 2 {
+		t.Errorf("AgentRoleRules() = %+v", rules)
+	}
+
+	for name, raw := range map[string]string{
+		"not json":     "configmaps",
+		"no verbs":     `[{"apiGroups":[""],"resources":["pods"]}]`,
+		"no resources": `[{"apiGroups":[""],"verbs":["get"]}]`,
+		"non-resource": `[{"nonResourceURLs":["/healthz"],"verbs":["get"]}]`,
+	} {
+		bad := Config{AgentRBACRules: raw}
+		if _, err := bad.AgentRoleRules(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package config
+
+import "sync/atomic"
+
+// Snapshot holds a runtime cache that one goroutine replaces whole while
+// others read it, such as the caches the periodic sync refreshes under the
+// event workers. Readers take one snapshot per operation with Load and must
+// not modify it.
+type Snapshot[T any] struct {
+	p atomic.Pointer[T]
+}
+
+// NewSnapshot returns a Snapshot holding v.
+func NewSnapshot[T any](v T) *Snapshot[T] {
+	s := &Snapshot[T]{}
+	s.Store(v)
+	return s
+}
+
+// Load returns the current value, or the zero value when s is nil or
+// nothing was stored yet.
+func (s *Snapshot[T]) Load() T {
+	var zero T
+	if s == nil {
+		return zero
+	}
+	if p := s.p.Load(); p != nil {
+		return *p
+	}
+	return zero
+}
+
+// Store replaces the value.
+func (s *Snapshot[T]) Store(v T) {
+	s.p.Store(&v)
+}
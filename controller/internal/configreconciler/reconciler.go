@@ -0,0 +1,386 @@
+// Package configreconciler renders per-agent ConfigMaps from beads.
+//
+// Every active agent bead gets a ConfigMap named "{project}-{role}-{agent}-config",
+// mounted by its pod at podmanager.MountBeadsConfig, with:
+//   - agent.json: agent identity, daemon address, and project metadata
+//   - instructions.md: role instructions (the agent bead's "instructions"
+//     field, else its role bead's, else the "role-instructions:{role}" config)
+//   - hooks.json: the claude-hooks:global and claude-hooks:{role} config
+//     layers, then the hooks of the agent's template bead
+//
+// The ConfigMap and the pods mounting it carry a hash of its data
+// (podmanager.AnnotationConfigHash), so the pod reconciler can restart
+// agents whose source beads changed.
+//
+// Agents whose bead names its own ConfigMap ("configmap" field) are left alone.
+package configreconciler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+const (
+	// LabelComponent marks ConfigMaps rendered by this reconciler.
+	LabelComponent = "gasboat.io/component"
+	componentValue = "agent-config"
+
+	// Data keys.
+	KeyAgent        = "agent.json"
+	KeyInstructions = "instructions.md"
+	KeyHooks        = "hooks.json"
+)
+
+var selector = "gasboat.io/managed-by=controller," + LabelComponent + "=" + componentValue
+
+// Source reads the beads a ConfigMap is rendered from.
+type Source interface {
+	beadsapi.BeadLister
+	GetConfig(ctx context.Context, key string) (*beadsapi.ConfigEntry, error)
+}
+
+// Reconciler keeps agent ConfigMaps in sync with their beads on every
+// agent cluster.
+type Reconciler struct {
+	source  Source
+	clients []kubernetes.Interface
+	cfg     *config.Config
+	logger  *slog.Logger
+
+	mu     sync.Mutex
+	hashes map[string]string // ConfigMap name -> hash of its current data
+}
+
+// New creates a ConfigMap reconciler writing to the namespace cfg.Namespace
+// on each of clients.
+func New(source Source, clients []kubernetes.Interface, cfg *config.Config, logger *slog.Logger) *Reconciler {
+	return &Reconciler{
+		source:  source,
+		clients: clients,
+		cfg:     cfg,
+		logger:  logger,
+		hashes:  make(map[string]string),
+	}
+}
+
+// Name returns the ConfigMap name for an agent.
+func Name(project, role, agentName string) string {
+	return fmt.Sprintf("%s-%s-%s-config", project, role, agentName)
+}
+
+// Reconcile renders a ConfigMap for every active agent bead, creates or
+// updates those whose data changed, and deletes ConfigMaps of agents that
+// no longer exist.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	beads, err := r.source.ListAgentBeads(ctx)
+	if err != nil {
+		return fmt.Errorf("listing agent beads: %w", err)
+	}
+
+	configs := make(map[string]json.RawMessage)
+	want := make(map[string]*corev1.ConfigMap)
+	for _, bead := range beads {
+		if bead.Metadata["configmap"] != "" {
+			continue
+		}
+		cm, err := r.render(ctx, configs, bead)
+		if err != nil {
+			return err
+		}
+		want[cm.Name] = cm
+	}
+
+	var errs []error
+	failed := make(map[string]bool)
+	for _, client := range r.clients {
+		for name, cm := range want {
+			if err := r.apply(ctx, client, cm); err != nil {
+				errs = append(errs, err)
+				failed[name] = true
+			}
+		}
+		// An empty bead list is more likely a daemon hiccup than every
+		// agent being gone; keep the ConfigMaps until the next pass.
+		if len(beads) > 0 {
+			if err := r.prune(ctx, client, want); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	r.mu.Lock()
+	for name := range r.hashes {
+		if want[name] == nil {
+			delete(r.hashes, name)
+		}
+	}
+	for name, cm := range want {
+		// Keep the previous hash until the new data reached every cluster,
+		// so pods aren't restarted onto a stale ConfigMap.
+		if !failed[name] {
+			r.hashes[name] = cm.Annotations[podmanager.AnnotationConfigHash]
+		}
+	}
+	r.mu.Unlock()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config reconciliation had %d errors: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+// Ensure renders and writes the ConfigMap for a single agent, so a pod
+// spawned between passes starts with its config.
+func (r *Reconciler) Ensure(ctx context.Context, bead beadsapi.AgentBead) error {
+	if bead.Metadata["configmap"] != "" {
+		return nil
+	}
+	cm, err := r.render(ctx, make(map[string]json.RawMessage), bead)
+	if err != nil {
+		return err
+	}
+	for _, client := range r.clients {
+		if err := r.apply(ctx, client, cm); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	r.hashes[cm.Name] = cm.Annotations[podmanager.AnnotationConfigHash]
+	r.mu.Unlock()
+	return nil
+}
+
+// Apply points spec at its agent's rendered ConfigMap and records the
+// config hash. Specs that already name a ConfigMap, or agents without a
+// rendered one, are left unchanged.
+func (r *Reconciler) Apply(spec *podmanager.AgentPodSpec) {
+	if spec.ConfigMapName != "" || spec.Namespace != r.cfg.Namespace {
+		return
+	}
+	name := Name(spec.Project, spec.Role, spec.AgentName)
+	r.mu.Lock()
+	hash, ok := r.hashes[name]
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+	spec.ConfigMapName = name
+	spec.ConfigHash = hash
+}
+
+// agentConfig is the agent.json document.
+type agentConfig struct {
+	Agent          string         `json:"agent"`
+	BeadID         string         `json:"bead_id"`
+	Project        string         `json:"project"`
+	Mode           string         `json:"mode"`
+	Role           string         `json:"role"`
+	DaemonHTTPAddr string         `json:"daemon_http_addr"`
+	DaemonGRPCAddr string         `json:"daemon_grpc_addr,omitempty"`
+	ProjectMeta    *projectConfig `json:"project_meta,omitempty"`
+}
+
+type projectConfig struct {
+	Prefix        string               `json:"prefix,omitempty"`
+	GitURL        string               `json:"git_url,omitempty"`
+	DefaultBranch string               `json:"default_branch,omitempty"`
+	Repos         []beadsapi.RepoEntry `json:"repos,omitempty"`
+}
+
+// render builds the ConfigMap for bead. configs caches config lookups
+// across agents within one pass.
+func (r *Reconciler) render(ctx context.Context, configs map[string]json.RawMessage, bead beadsapi.AgentBead) (*corev1.ConfigMap, error) {
+	ac := agentConfig{
+		Agent:          bead.AgentName,
+		BeadID:         bead.ID,
+		Project:        bead.Project,
+		Mode:           bead.Mode,
+		Role:           bead.Role,
+		DaemonHTTPAddr: r.cfg.BeadsHTTPAddr,
+		DaemonGRPCAddr: r.cfg.BeadsGRPCAddr,
+	}
+	if entry, ok := r.cfg.ProjectCache.Load()[bead.Project]; ok {
+		ac.ProjectMeta = &projectConfig{
+			Prefix:        entry.Prefix,
+			GitURL:        entry.GitURL,
+			DefaultBranch: entry.DefaultBranch,
+			Repos:         entry.Repos,
+		}
+	}
+	agentJSON, err := json.MarshalIndent(ac, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding agent config: %w", err)
+	}
+	data := map[string]string{KeyAgent: string(agentJSON) + "\n"}
+
+	meta, _ := beadsapi.ResolveTemplate(bead.Metadata, r.cfg.TemplateCache.Load())
+	instructions := meta["instructions"]
+	if instructions == "" {
+		instructions = r.cfg.RoleCache.Load()[bead.Role].Instructions
+	}
+	if instructions == "" {
+		raw, err := r.config(ctx, configs, "role-instructions:"+bead.Role)
+		if err != nil {
+			return nil, err
+		}
+		instructions = configText(raw)
+	}
+	if instructions != "" {
+		data[KeyInstructions] = instructions
+	}
+
+	var layers []json.RawMessage
+	for _, key := range []string{"claude-hooks:global", "claude-hooks:" + bead.Role} {
+		raw, err := r.config(ctx, configs, key)
+		if err != nil {
+			return nil, err
+		}
+		if raw != nil {
+			layers = append(layers, raw)
+		}
+	}
+	if raw := meta[beadsapi.TemplateHooksField]; raw != "" && json.Valid([]byte(raw)) {
+		layers = append(layers, json.RawMessage(raw))
+	}
+	if len(layers) > 0 {
+		hooks, err := json.Marshal(layers)
+		if err != nil {
+			return nil, fmt.Errorf("encoding hook layers: %w", err)
+		}
+		data[KeyHooks] = string(hooks)
+	}
+
+	name := Name(bead.Project, bead.Role, bead.AgentName)
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: r.cfg.Namespace,
+			Labels: map[string]string{
+				podmanager.LabelApp:     podmanager.LabelAppValue,
+				podmanager.LabelProject: bead.Project,
+				podmanager.LabelRole:    bead.Role,
+				podmanager.LabelAgent:   bead.AgentName,
+				"gasboat.io/managed-by": "controller",
+				LabelComponent:          componentValue,
+			},
+			Annotations: map[string]string{
+				podmanager.AnnotationConfigHash: hashData(data),
+				podmanager.AnnotationBeadID:     bead.ID,
+			},
+		},
+		Data: data,
+	}, nil
+}
+
+// config returns the value of a daemon config, or nil if it doesn't exist.
+// Other errors fail the render: treating them as "unset" would change the
+// hash and restart every agent on a daemon hiccup.
+func (r *Reconciler) config(ctx context.Context, configs map[string]json.RawMessage, key string) (json.RawMessage, error) {
+	if raw, ok := configs[key]; ok {
+		return raw, nil
+	}
+	var raw json.RawMessage
+	entry, err := r.source.GetConfig(ctx, key)
+	var apiErr *beadsapi.APIError
+	switch {
+	case err == nil && entry != nil:
+		raw = entry.Value
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+	case err != nil:
+		return nil, err
+	}
+	configs[key] = raw
+	return raw, nil
+}
+
+// configText unwraps a config value holding a JSON string; other values
+// are used as-is.
+func configText(raw json.RawMessage) string {
+	if raw == nil {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// hashData returns a short, order-independent hash of a ConfigMap's data.
+func hashData(data map[string]string) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", k, data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// apply creates cm, or updates it if its data hash differs.
+func (r *Reconciler) apply(ctx context.Context, client kubernetes.Interface, cm *corev1.ConfigMap) error {
+	cms := client.CoreV1().ConfigMaps(cm.Namespace)
+	existing, err := cms.Get(ctx, cm.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := cms.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("creating ConfigMap %s: %w", cm.Name, err)
+		}
+		r.logger.Info("created agent ConfigMap", "name", cm.Name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting ConfigMap %s: %w", cm.Name, err)
+	}
+	if existing.Annotations[podmanager.AnnotationConfigHash] == cm.Annotations[podmanager.AnnotationConfigHash] {
+		return nil
+	}
+	updated := cm.DeepCopy()
+	updated.ResourceVersion = existing.ResourceVersion
+	if _, err := cms.Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("updating ConfigMap %s: %w", cm.Name, err)
+	}
+	r.logger.Info("updated agent ConfigMap", "name", cm.Name,
+		"hash", cm.Annotations[podmanager.AnnotationConfigHash])
+	return nil
+}
+
+// prune deletes rendered ConfigMaps whose agent is not in want.
+func (r *Reconciler) prune(ctx context.Context, client kubernetes.Interface, want map[string]*corev1.ConfigMap) error {
+	cms := client.CoreV1().ConfigMaps(r.cfg.Namespace)
+	list, err := cms.List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("listing agent ConfigMaps: %w", err)
+	}
+	var errs []error
+	for _, cm := range list.Items {
+		if want[cm.Name] != nil {
+			continue
+		}
+		if err := cms.Delete(ctx, cm.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("deleting ConfigMap %s: %w", cm.Name, err))
+			continue
+		}
+		r.logger.Info("deleted agent ConfigMap", "name", cm.Name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,232 @@
+package configreconciler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+type fakeSource struct {
+	beads   []beadsapi.AgentBead
+	configs map[string]string
+	err     error
+}
+
+func (s *fakeSource) ListAgentBeads(context.Context) ([]beadsapi.AgentBead, error) {
+	return s.beads, nil
+}
+
+func (s *fakeSource) GetConfig(_ context.Context, key string) (*beadsapi.ConfigEntry, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	v, ok := s.configs[key]
+	if !ok {
+		return nil, &beadsapi.APIError{StatusCode: http.StatusNotFound, Message: "not found"}
+	}
+	return &beadsapi.ConfigEntry{Key: key, Value: json.RawMessage(v)}, nil
+}
+
+func newTestReconciler(src *fakeSource, objects ...*corev1.ConfigMap) (*Reconciler, *fake.Clientset) {
+	client := fake.NewSimpleClientset()
+	for _, cm := range objects {
+		_, _ = client.CoreV1().ConfigMaps(cm.Namespace).Create(context.Background(), cm, metav1.CreateOptions{})
+	}
+	cfg := &config.Config{
+		Namespace:     "test-ns",
+		BeadsHTTPAddr: "http://daemon:8080",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"gasboat": {Prefix: "kd", GitURL: "https://github.com/groblegark/gasboat.git", DefaultBranch: "main"},
+		}),
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	return New(src, []kubernetes.Interface{client}, cfg, logger), client
+}
+
+func agentBead(name string, meta map[string]string) beadsapi.AgentBead {
+	return beadsapi.AgentBead{
+		ID: "crew-gasboat-crew-" + name, Project: "gasboat", Mode: "crew", Role: "crew",
+		AgentName: name, Metadata: meta,
+	}
+}
+
+func getConfigMap(t *testing.T, client *fake.Clientset, name string) *corev1.ConfigMap {
+	t.Helper()
+	cm, err := client.CoreV1().ConfigMaps("test-ns").Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get ConfigMap %s: %v", name, err)
+	}
+	return cm
+}
+
+func TestReconcile_RendersAgentConfigMap(t *testing.T) {
+	src := &fakeSource{
+		beads: []beadsapi.AgentBead{agentBead("k8s", nil)},
+		configs: map[string]string{
+			"claude-hooks:global":    `{"hooks":{"Stop":[]}}`,
+			"role-instructions:crew": `"Ship small PRs."`,
+		},
+	}
+	r, client := newTestReconciler(src)
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	cm := getConfigMap(t, client, "gasboat-crew-k8s-config")
+	var ac agentConfig
+	if err := json.Unmarshal([]byte(cm.Data[KeyAgent]), &ac); err != nil {
+		t.Fatalf("agent.json: %v", err)
+	}
+	if ac.BeadID != "crew-gasboat-crew-k8s" || ac.DaemonHTTPAddr != "http://daemon:8080" ||
+		ac.ProjectMeta == nil || ac.ProjectMeta.Prefix != "kd" {
+		t.Errorf("agent.json = %+v", ac)
+	}
+	if cm.Data[KeyInstructions] != "Ship small PRs." {
+		t.Errorf("instructions.md = %q", cm.Data[KeyInstructions])
+	}
+	if cm.Data[KeyHooks] != `[{"hooks":{"Stop":[]}}]` {
+		t.Errorf("hooks.json = %q", cm.Data[KeyHooks])
+	}
+	if cm.Labels["gasboat.io/managed-by"] != "controller" || cm.Annotations[podmanager.AnnotationConfigHash] == "" {
+		t.Errorf("metadata = %v %v", cm.Labels, cm.Annotations)
+	}
+}
+
+func TestReconcile_RendersTemplateHooksAndInstructions(t *testing.T) {
+	src := &fakeSource{
+		beads: []beadsapi.AgentBead{agentBead("k8s", map[string]string{beadsapi.TemplateField: "reviewer"})},
+		configs: map[string]string{
+			"claude-hooks:crew": `{"hooks":{"Stop":[]}}`,
+		},
+	}
+	r, client := newTestReconciler(src)
+	r.cfg.TemplateCache = config.NewSnapshot(map[string]beadsapi.TemplateInfo{
+		"reviewer": {Name: "reviewer", Fields: map[string]string{
+			"instructions": "Review, don't write.",
+			"hooks":        `{"hooks":{"PreToolUse":[]}}`,
+		}},
+	})
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	cm := getConfigMap(t, client, "gasboat-crew-k8s-config")
+	if cm.Data[KeyInstructions] != "Review, don't write." {
+		t.Errorf("instructions.md = %q", cm.Data[KeyInstructions])
+	}
+	if cm.Data[KeyHooks] != `[{"hooks":{"Stop":[]}},{"hooks":{"PreToolUse":[]}}]` {
+		t.Errorf("hooks.json = %q, want the role layer then the template's", cm.Data[KeyHooks])
+	}
+}
+
+func TestReconcile_RoleBeadInstructions(t *testing.T) {
+	src := &fakeSource{
+		beads: []beadsapi.AgentBead{
+			agentBead("k8s", nil),
+			agentBead("own", map[string]string{"instructions": "Mine."}),
+		},
+		configs: map[string]string{"role-instructions:crew": `"From config."`},
+	}
+	r, client := newTestReconciler(src)
+	r.cfg.RoleCache = config.NewSnapshot(map[string]config.RoleCacheEntry{"crew": {Instructions: "From the role bead."}})
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if got := getConfigMap(t, client, "gasboat-crew-k8s-config").Data[KeyInstructions]; got != "From the role bead." {
+		t.Errorf("instructions.md = %q, want the role bead's", got)
+	}
+	if got := getConfigMap(t, client, "gasboat-crew-own-config").Data[KeyInstructions]; got != "Mine." {
+		t.Errorf("instructions.md = %q, want the agent's", got)
+	}
+}
+
+func TestReconcile_UpdatesOnChangeAndApplies(t *testing.T) {
+	src := &fakeSource{beads: []beadsapi.AgentBead{agentBead("k8s", map[string]string{"instructions": "v1"})}}
+	r, client := newTestReconciler(src)
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	spec := podmanager.AgentPodSpec{Project: "gasboat", Role: "crew", AgentName: "k8s", Namespace: "test-ns"}
+	r.Apply(&spec)
+	if spec.ConfigMapName != "gasboat-crew-k8s-config" || spec.ConfigHash == "" {
+		t.Fatalf("Apply = %q/%q", spec.ConfigMapName, spec.ConfigHash)
+	}
+	first := spec.ConfigHash
+
+	src.beads = []beadsapi.AgentBead{agentBead("k8s", map[string]string{"instructions": "v2"})}
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if got := getConfigMap(t, client, "gasboat-crew-k8s-config").Data[KeyInstructions]; got != "v2" {
+		t.Errorf("instructions.md = %q, want v2", got)
+	}
+	spec = podmanager.AgentPodSpec{Project: "gasboat", Role: "crew", AgentName: "k8s", Namespace: "test-ns"}
+	r.Apply(&spec)
+	if spec.ConfigHash == first {
+		t.Error("config hash did not change with the bead")
+	}
+}
+
+func TestReconcile_PrunesStaleConfigMaps(t *testing.T) {
+	stale := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "gasboat-crew-gone-config", Namespace: "test-ns",
+		Labels: map[string]string{"gasboat.io/managed-by": "controller", LabelComponent: componentValue},
+	}}
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "test-ns"}}
+	src := &fakeSource{beads: []beadsapi.AgentBead{
+		agentBead("k8s", nil),
+		agentBead("custom", map[string]string{"configmap": "my-config"}),
+	}}
+	r, client := newTestReconciler(src, stale, other)
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	list, _ := client.CoreV1().ConfigMaps("test-ns").List(context.Background(), metav1.ListOptions{})
+	var names []string
+	for _, cm := range list.Items {
+		names = append(names, cm.Name)
+	}
+	if got := strings.Join(names, ","); got != "gasboat-crew-k8s-config,unrelated" {
+		t.Errorf("ConfigMaps = %s", got)
+	}
+
+	spec := podmanager.AgentPodSpec{Project: "gasboat", Role: "crew", AgentName: "custom", Namespace: "test-ns"}
+	r.Apply(&spec)
+	if spec.ConfigMapName != "" {
+		t.Errorf("agent with its own ConfigMap got %q", spec.ConfigMapName)
+	}
+}
+
+func TestReconcile_DaemonErrorKeepsConfig(t *testing.T) {
+	src := &fakeSource{beads: []beadsapi.AgentBead{agentBead("k8s", nil)}, configs: map[string]string{}}
+	r, _ := newTestReconciler(src)
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	src.err = &beadsapi.APIError{StatusCode: http.StatusServiceUnavailable, Message: "unavailable"}
+	if err := r.Reconcile(context.Background()); err == nil {
+		t.Fatal("expected an error when configs can't be read")
+	}
+	spec := podmanager.AgentPodSpec{Project: "gasboat", Role: "crew", AgentName: "k8s", Namespace: "test-ns"}
+	r.Apply(&spec)
+	if spec.ConfigMapName == "" {
+		t.Error("previously rendered config was dropped")
+	}
+}
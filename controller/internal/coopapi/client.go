@@ -0,0 +1,187 @@
+// Package coopapi is a client for the coop API every agent pod serves on
+// podmanager.CoopDefaultPort: health, agent state, sessions, messages to the
+// agent, and its terminal screen.
+//
+// Inside the cluster the client talks to the pod directly (the agent bead's
+// coop_url). Bridges outside the cluster network go through the controller's
+// coop proxy instead, with URL set to <controller>/coop/<agent> and Token to
+// a coop token minted by POST /coop-token (see package cooptoken).
+package coopapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultTimeout bounds each request when Config.Timeout is zero.
+const defaultTimeout = 10 * time.Second
+
+// checkpointPrompt asks the agent to record a checkpoint; %s is the note.
+const checkpointPrompt = "Run `gb yield --checkpoint --note %q` now so the next session can resume from here."
+
+// ErrNotDelivered is returned when coop accepted a message but could not
+// deliver it to the agent, e.g. because the agent is mid-tool-call.
+var ErrNotDelivered = errors.New("message not delivered")
+
+// Config for the coop API client.
+type Config struct {
+	// URL is the coop base URL (e.g., "http://10.0.0.5:8080"), or the
+	// controller's proxy URL for the agent.
+	URL string
+
+	// Token is sent as "Authorization: Bearer <token>" when set.
+	Token string
+
+	// Timeout bounds each request. Default: 10s.
+	Timeout time.Duration
+}
+
+// Client talks to one agent's coop API.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// New creates a coop API client.
+func New(cfg Config) *Client {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(cfg.URL, "/"),
+		token:      cfg.Token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// AgentState is the agent's state as coop reports it.
+type AgentState struct {
+	// State is e.g. "starting", "working", "idle", "exited" or "error".
+	State string `json:"state"`
+}
+
+// Session is a coop session registered with a coop mux.
+type Session struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Metadata struct {
+		Role  string `json:"role"`
+		Agent string `json:"agent"`
+	} `json:"metadata"`
+}
+
+// APIError is a non-2xx response from the coop API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("coop HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Health checks that coop is up.
+func (c *Client) Health(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, "/api/v1/health", nil, nil)
+}
+
+// Agent returns the state of the agent coop runs.
+func (c *Client) Agent(ctx context.Context) (*AgentState, error) {
+	var state AgentState
+	if err := c.do(ctx, http.MethodGet, "/api/v1/agent", nil, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Sessions lists the sessions registered with a coop mux.
+func (c *Client) Sessions(ctx context.Context) ([]Session, error) {
+	var sessions []Session
+	if err := c.do(ctx, http.MethodGet, "/api/v1/sessions", nil, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// SendMessage types message into the agent's session. It returns an error
+// wrapping ErrNotDelivered when coop could not deliver it.
+func (c *Client) SendMessage(ctx context.Context, message string) error {
+	var result struct {
+		Delivered *bool  `json:"delivered"`
+		Reason    string `json:"reason"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/agent/nudge", map[string]string{"message": message}, &result); err != nil {
+		return err
+	}
+	if result.Delivered != nil && !*result.Delivered {
+		return fmt.Errorf("%w: %s", ErrNotDelivered, result.Reason)
+	}
+	return nil
+}
+
+// Checkpoint asks the agent to record a checkpoint with note.
+func (c *Client) Checkpoint(ctx context.Context, note string) error {
+	return c.SendMessage(ctx, fmt.Sprintf(checkpointPrompt, note))
+}
+
+// Logs returns the agent's terminal screen as plain text.
+func (c *Client) Logs(ctx context.Context) (string, error) {
+	var screen struct {
+		Lines []string `json:"lines"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/screen", nil, &screen); err != nil {
+		return "", err
+	}
+	return strings.Join(screen.Lines, "\n"), nil
+}
+
+// do sends a JSON request and decodes a JSON response into result, if set.
+func (c *Client) do(ctx context.Context, method, path string, body, result any) error {
+	var bodyReader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshaling request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("performing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(respBody))}
+	}
+	if result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package coopapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_SendMessage(t *testing.T) {
+	var got map[string]string
+	var auth string
+	delivered := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/agent/nudge" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(map[string]any{"delivered": delivered, "reason": "agent busy"})
+	}))
+	defer srv.Close()
+
+	c := New(Config{URL: srv.URL + "/", Token: "secret"})
+	if err := c.SendMessage(context.Background(), "hello"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	if got["message"] != "hello" || auth != "Bearer secret" {
+		t.Errorf("body %v, auth %q", got, auth)
+	}
+
+	delivered = false
+	err := c.Checkpoint(context.Background(), "draining")
+	if !errors.Is(err, ErrNotDelivered) {
+		t.Errorf("err = %v, want ErrNotDelivered", err)
+	}
+	if !strings.Contains(got["message"], `gb yield --checkpoint --note "draining"`) {
+		t.Errorf("checkpoint message = %q", got["message"])
+	}
+}
+
+func TestClient_AgentSessionsAndLogs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/health":
+		case "/api/v1/agent":
+			_, _ = w.Write([]byte(`{"state":"idle"}`))
+		case "/api/v1/sessions":
+			_, _ = w.Write([]byte(`[{"id":"s1","url":"http://10.0.0.5:8080","metadata":{"role":"crew","agent":"hq"}}]`))
+		case "/api/v1/screen":
+			_, _ = w.Write([]byte(`{"lines":["$ make test","ok"]}`))
+		default:
+			http.Error(w, "no such endpoint", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := New(Config{URL: srv.URL})
+	if err := c.Health(ctx); err != nil {
+		t.Errorf("Health: %v", err)
+	}
+	if state, err := c.Agent(ctx); err != nil || state.State != "idle" {
+		t.Errorf("Agent = %+v, %v", state, err)
+	}
+	if sessions, err := c.Sessions(ctx); err != nil || len(sessions) != 1 || sessions[0].Metadata.Agent != "hq" {
+		t.Errorf("Sessions = %+v, %v", sessions, err)
+	}
+	if logs, err := c.Logs(ctx); err != nil || logs != "$ make test\nok" {
+		t.Errorf("Logs = %q, %v", logs, err)
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "coop is starting", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	err := New(Config{URL: srv.URL}).Health(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Message != "coop is starting" {
+		t.Errorf("err = %v, want a 503 APIError", err)
+	}
+}
@@ -0,0 +1,117 @@
+// Package cooptoken mints and verifies short-lived tokens granting access
+// to one agent's coop endpoints through the controller's coop proxy.
+//
+// A token is "v1.<payload>.<signature>": the base64url-encoded JSON claims
+// and their HMAC-SHA256 under a key only the controller holds. Nothing is
+// stored; a token is valid until it expires, for the one agent it names.
+package cooptoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MinKeyLen is the shortest accepted signing key, in bytes.
+const MinKeyLen = 32
+
+const version = "v1"
+
+var (
+	// ErrInvalid is returned for tokens that are malformed or whose
+	// signature does not verify.
+	ErrInvalid = errors.New("invalid coop token")
+	// ErrExpired is returned for tokens past their expiry.
+	ErrExpired = errors.New("coop token expired")
+)
+
+// Claims is what a token grants.
+type Claims struct {
+	// Agent is the agent bead ID whose coop the token opens.
+	Agent string `json:"agent"`
+	// Subject is who the token was minted for, e.g. "slack-bridge".
+	Subject   string `json:"sub,omitempty"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Expires returns the token's expiry time.
+func (c Claims) Expires() time.Time {
+	return time.Unix(c.ExpiresAt, 0)
+}
+
+// Signer mints and verifies tokens with one key.
+type Signer struct {
+	key []byte
+	now func() time.Time
+}
+
+// NewSigner returns a Signer for key, which must be at least MinKeyLen
+// bytes.
+func NewSigner(key []byte) (*Signer, error) {
+	if len(key) < MinKeyLen {
+		return nil, fmt.Errorf("coop token key must be at least %d bytes, got %d", MinKeyLen, len(key))
+	}
+	return &Signer{key: key, now: time.Now}, nil
+}
+
+// Mint returns a token for agent, minted for subject, valid for ttl.
+func (s *Signer) Mint(agent, subject string, ttl time.Duration) (string, Claims, error) {
+	if agent == "" {
+		return "", Claims{}, errors.New("agent is required")
+	}
+	if ttl <= 0 {
+		return "", Claims{}, errors.New("ttl must be positive")
+	}
+	now := s.now()
+	claims := Claims{Agent: agent, Subject: subject, IssuedAt: now.Unix(), ExpiresAt: now.Add(ttl).Unix()}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", Claims{}, err
+	}
+	signed := version + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(s.sign(signed)), claims, nil
+}
+
+// Verify checks token's signature and expiry and returns its claims.
+func (s *Signer) Verify(token string) (Claims, error) {
+	signed, sig, ok := cutLast(token, ".")
+	if !ok || !strings.HasPrefix(signed, version+".") {
+		return Claims{}, ErrInvalid
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.sign(signed)) {
+		return Claims{}, ErrInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(signed, version+"."))
+	if err != nil {
+		return Claims{}, ErrInvalid
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Agent == "" {
+		return Claims{}, ErrInvalid
+	}
+	if !s.now().Before(claims.Expires()) {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
+
+func (s *Signer) sign(signed string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(signed))
+	return mac.Sum(nil)
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
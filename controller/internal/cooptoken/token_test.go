@@ -0,0 +1,70 @@
+package cooptoken
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSigner_MintVerify(t *testing.T) {
+	s, err := NewSigner(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	s.now = func() time.Time { return now }
+
+	token, minted, err := s.Mint("kd-agent-1", "slack-bridge", 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, "v1.") || minted.Expires() != now.Add(15*time.Minute) {
+		t.Errorf("token = %q, claims = %+v", token, minted)
+	}
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims != minted {
+		t.Errorf("verified claims = %+v, want %+v", claims, minted)
+	}
+
+	now = now.Add(15 * time.Minute)
+	if _, err := s.Verify(token); !errors.Is(err, ErrExpired) {
+		t.Errorf("expired token: err = %v", err)
+	}
+}
+
+func TestSigner_RejectsTampering(t *testing.T) {
+	s, _ := NewSigner(testKey)
+	token, _, err := s.Mint("kd-agent-1", "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := NewSigner([]byte(strings.Repeat("x", MinKeyLen)))
+	forged, _, _ := other.Mint("kd-agent-2", "", time.Hour)
+	_, payload, _ := strings.Cut(forged, ".")
+	payload, _, _ = strings.Cut(payload, ".")
+
+	for name, tok := range map[string]string{
+		"other key":     forged,
+		"swapped agent": "v1." + payload + "." + token[strings.LastIndex(token, ".")+1:],
+		"no signature":  token[:strings.LastIndex(token, ".")],
+		"bad version":   "v2" + strings.TrimPrefix(token, "v1"),
+		"garbage":       "not-a-token",
+		"empty":         "",
+	} {
+		if _, err := s.Verify(tok); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: err = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestNewSigner_ShortKey(t *testing.T) {
+	if _, err := NewSigner([]byte("short")); err == nil {
+		t.Error("short key accepted")
+	}
+}
@@ -0,0 +1,313 @@
+// Package errorreporter rolls up repeated errors and publishes them to the
+// beads event bus as one structured event per window, so the slack-bridge can
+// post a single actionable alert instead of either silence or one message
+// per log line.
+//
+// Errors are collected by wrapping the process's slog handler: every record
+// at or above the configured level is grouped by message and error text
+// (with numbers masked, so "quota exceeded (101/100)" and "(102/100)" group
+// together). Each window, groups not reported within the cooldown are
+// published as an event bead, exactly as gb bus publish does.
+package errorreporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// Topic is the bus topic reports are published on; gb bus tail shows them
+// as "bus.controller.errors".
+const Topic = "controller.errors"
+
+const (
+	// maxTrackedGroups bounds memory when errors don't repeat; further
+	// distinct errors are only counted.
+	maxTrackedGroups = 200
+	// maxAttrLen truncates attribute values copied into a report.
+	maxAttrLen = 200
+)
+
+// Group is one distinct error and how often it occurred.
+type Group struct {
+	Level     string            `json:"level"`
+	Message   string            `json:"message"`
+	Error     string            `json:"error,omitempty"` // from the most recent occurrence
+	Count     int               `json:"count"`
+	FirstSeen time.Time         `json:"first_seen"`
+	LastSeen  time.Time         `json:"last_seen"`
+	Attrs     map[string]string `json:"attrs,omitempty"` // from the most recent occurrence
+}
+
+// Report is the payload of a published error event.
+type Report struct {
+	Source  string  `json:"source"`
+	Total   int     `json:"total"`             // occurrences across Groups
+	Groups  []Group `json:"groups"`            // most frequent first
+	Omitted int     `json:"omitted,omitempty"` // groups left out to respect MaxGroups
+	Dropped int     `json:"dropped,omitempty"` // occurrences not grouped (too many distinct errors)
+}
+
+// Summary returns a one-line description of the report, e.g.
+// "212 errors in 3 groups from controller".
+func (r Report) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d errors in %d groups from %s", r.Total, len(r.Groups), r.Source)
+	if n := r.Omitted; n > 0 {
+		fmt.Fprintf(&b, " (+%d more groups)", n)
+	}
+	return b.String()
+}
+
+// Publisher creates and closes beads. *beadsapi.Client satisfies it.
+type Publisher interface {
+	CreateBead(ctx context.Context, req beadsapi.CreateBeadRequest) (string, error)
+	CloseBead(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// Config configures an Aggregator.
+type Config struct {
+	Publisher Publisher
+	Source    string        // who is reporting, e.g. "controller"
+	Window    time.Duration // how often pending errors are published
+	Cooldown  time.Duration // minimum gap between reports of the same error
+	MaxGroups int           // cap on groups per report (default 10)
+	Level     slog.Leveler  // minimum level collected (default: warn)
+	// Logger reports the aggregator's own failures. It must not be a logger
+	// built on Handler, or publish failures would be aggregated too.
+	Logger *slog.Logger
+}
+
+// Aggregator collects errors and publishes them in rate-limited reports.
+type Aggregator struct {
+	cfg Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	groups   map[string]*Group    // pending, by key
+	reported map[string]time.Time // key → when last published
+	dropped  int
+}
+
+// New creates an Aggregator. Call Run to publish reports.
+func New(cfg Config) *Aggregator {
+	if cfg.MaxGroups <= 0 {
+		cfg.MaxGroups = 10
+	}
+	if cfg.Level == nil {
+		cfg.Level = slog.LevelWarn
+	}
+	return &Aggregator{
+		cfg:      cfg,
+		now:      time.Now,
+		groups:   make(map[string]*Group),
+		reported: make(map[string]time.Time),
+	}
+}
+
+// Run publishes pending errors every window until ctx is canceled.
+func (a *Aggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := a.Flush(ctx); err != nil {
+				a.cfg.Logger.Warn("publishing error report failed", "error", err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// digits masks numbers so errors differing only in counts, IDs, or
+// durations share a group.
+var digits = regexp.MustCompile(`[0-9]+`)
+
+// Record adds one occurrence of an error.
+func (a *Aggregator) Record(level slog.Level, msg, errText string, attrs map[string]string) {
+	key := level.String() + "\x00" + msg + "\x00" + digits.ReplaceAllString(errText, "#")
+	now := a.now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	g, ok := a.groups[key]
+	if !ok {
+		if len(a.groups) >= maxTrackedGroups {
+			a.dropped++
+			return
+		}
+		g = &Group{Level: level.String(), Message: msg, FirstSeen: now}
+		a.groups[key] = g
+	}
+	g.Count++
+	g.LastSeen = now
+	g.Error = errText
+	g.Attrs = attrs
+}
+
+// Flush publishes pending errors that are not in cooldown, most frequent
+// first. Errors in cooldown keep accumulating and are reported, with their
+// full count, once it ends. Nothing is published when nothing is eligible.
+func (a *Aggregator) Flush(ctx context.Context) error {
+	report, ok := a.take()
+	if !ok {
+		return nil
+	}
+	return a.publish(ctx, report)
+}
+
+// take removes the groups due for reporting and builds a report from them.
+func (a *Aggregator) take() (Report, bool) {
+	now := a.now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, at := range a.reported {
+		if now.Sub(at) >= a.cfg.Cooldown {
+			delete(a.reported, key)
+		}
+	}
+
+	type due struct {
+		key string
+		g   *Group
+	}
+	var eligible []due
+	for key, g := range a.groups {
+		if _, cooling := a.reported[key]; !cooling {
+			eligible = append(eligible, due{key, g})
+		}
+	}
+	if len(eligible) == 0 && a.dropped == 0 {
+		return Report{}, false
+	}
+	sort.Slice(eligible, func(i, j int) bool {
+		if eligible[i].g.Count != eligible[j].g.Count {
+			return eligible[i].g.Count > eligible[j].g.Count
+		}
+		return eligible[i].g.FirstSeen.Before(eligible[j].g.FirstSeen)
+	})
+
+	report := Report{Source: a.cfg.Source, Groups: []Group{}, Dropped: a.dropped}
+	for i, d := range eligible {
+		delete(a.groups, d.key)
+		if i >= a.cfg.MaxGroups {
+			report.Omitted++
+			continue
+		}
+		a.reported[d.key] = now
+		report.Groups = append(report.Groups, *d.g)
+		report.Total += d.g.Count
+	}
+	a.dropped = 0
+	return report, true
+}
+
+// publish records the report as an event bead and closes it right away;
+// subscribers see it via the bead's creation event.
+func (a *Aggregator) publish(ctx context.Context, report Report) error {
+	fields, err := json.Marshal(map[string]any{
+		"topic":   Topic,
+		"payload": report,
+	})
+	if err != nil {
+		return err
+	}
+	id, err := a.cfg.Publisher.CreateBead(ctx, beadsapi.CreateBeadRequest{
+		Title:     fmt.Sprintf("event: %s (%d from %s)", Topic, report.Total, report.Source),
+		Type:      "event",
+		Labels:    []string{"bus:" + Topic},
+		CreatedBy: report.Source,
+		Fields:    fields,
+	})
+	if err != nil {
+		return fmt.Errorf("publishing %s: %w", Topic, err)
+	}
+	if err := a.cfg.Publisher.CloseBead(ctx, id, nil); err != nil {
+		a.cfg.Logger.Warn("error report published but not closed", "id", id, "error", err)
+	}
+	return nil
+}
+
+// Handler wraps next so that records at or above the configured level are
+// also recorded by the aggregator. The "error" (or "err") attribute is the
+// error text; other attributes are kept as context.
+func (a *Aggregator) Handler(next slog.Handler) slog.Handler {
+	return &handler{agg: a, next: next}
+}
+
+type handler struct {
+	agg    *Aggregator
+	next   slog.Handler
+	attrs  []slog.Attr // from WithAttrs, keys already group-qualified
+	prefix string      // from WithGroup, e.g. "req."
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= h.agg.cfg.Level.Level() {
+		attrs := make(map[string]string, len(h.attrs)+r.NumAttrs())
+		var errText string
+		add := func(key string, v slog.Value) {
+			s := v.Resolve().String()
+			if key == "error" || key == "err" {
+				errText = s
+				return
+			}
+			if len(s) > maxAttrLen {
+				s = s[:maxAttrLen] + "…"
+			}
+			attrs[key] = s
+		}
+		for _, at := range h.attrs {
+			add(at.Key, at.Value)
+		}
+		r.Attrs(func(at slog.Attr) bool {
+			add(h.prefix+at.Key, at.Value)
+			return true
+		})
+		h.agg.Record(r.Level, r.Message, errText, attrs)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	c.attrs = append(append([]slog.Attr(nil), h.attrs...), qualify(h.prefix, attrs)...)
+	return &c
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.next = h.next.WithGroup(name)
+	c.prefix = h.prefix + name + "."
+	return &c
+}
+
+func qualify(prefix string, attrs []slog.Attr) []slog.Attr {
+	if prefix == "" {
+		return attrs
+	}
+	out := make([]slog.Attr, len(attrs))
+	for i, at := range attrs {
+		out[i] = slog.Attr{Key: prefix + at.Key, Value: at.Value}
+	}
+	return out
+}
@@ -0,0 +1,146 @@
+package errorreporter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+type fakePublisher struct {
+	created []beadsapi.CreateBeadRequest
+	closed  []string
+}
+
+func (f *fakePublisher) CreateBead(_ context.Context, req beadsapi.CreateBeadRequest) (string, error) {
+	f.created = append(f.created, req)
+	return fmt.Sprintf("kd-ev%d", len(f.created)), nil
+}
+
+func (f *fakePublisher) CloseBead(_ context.Context, id string, _ map[string]string) error {
+	f.closed = append(f.closed, id)
+	return nil
+}
+
+// reports decodes the payload of every published event.
+func (f *fakePublisher) reports(t *testing.T) []Report {
+	t.Helper()
+	var out []Report
+	for _, req := range f.created {
+		var fields struct {
+			Topic   string `json:"topic"`
+			Payload Report `json:"payload"`
+		}
+		if err := json.Unmarshal(req.Fields, &fields); err != nil {
+			t.Fatal(err)
+		}
+		if req.Type != "event" || fields.Topic != Topic {
+			t.Fatalf("unexpected event bead: type %q topic %q", req.Type, fields.Topic)
+		}
+		out = append(out, fields.Payload)
+	}
+	return out
+}
+
+func newTestAggregator(pub Publisher, clock *time.Time) *Aggregator {
+	a := New(Config{
+		Publisher: pub,
+		Source:    "controller",
+		Window:    time.Minute,
+		Cooldown:  time.Hour,
+		MaxGroups: 2,
+		Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	a.now = func() time.Time { return *clock }
+	return a
+}
+
+func TestHandler_RollsUpRepeatedErrors(t *testing.T) {
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pub := &fakePublisher{}
+	a := newTestAggregator(pub, &clock)
+	logger := slog.New(a.Handler(slog.NewTextHandler(io.Discard, nil))).With("component", "reconciler")
+
+	for i := range 200 {
+		logger.Error("creating pod failed", "pod", "crew-x", "error", fmt.Errorf("quota exceeded (%d/100)", 100+i))
+	}
+	logger.Warn("periodic status sync failed", "error", errors.New("daemon unreachable"))
+	logger.Info("creating pod", "pod", "crew-y") // below the level: ignored
+
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reports := pub.reports(t)
+	if len(reports) != 1 || len(pub.closed) != 1 {
+		t.Fatalf("expected one published and closed event, got %d/%d", len(reports), len(pub.closed))
+	}
+	r := reports[0]
+	if r.Source != "controller" || r.Total != 201 || len(r.Groups) != 2 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	top := r.Groups[0]
+	if top.Message != "creating pod failed" || top.Count != 200 || top.Level != "ERROR" {
+		t.Errorf("unexpected top group: %+v", top)
+	}
+	if top.Error != "quota exceeded (299/100)" || top.Attrs["pod"] != "crew-x" || top.Attrs["component"] != "reconciler" {
+		t.Errorf("expected latest error and attrs, got %+v", top)
+	}
+
+	// Nothing new: nothing published.
+	if err := a.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(pub.created) != 1 {
+		t.Errorf("expected no event for an empty window, got %d", len(pub.created))
+	}
+}
+
+func TestFlush_CooldownHoldsRepeatsUntilItEnds(t *testing.T) {
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pub := &fakePublisher{}
+	a := newTestAggregator(pub, &clock)
+
+	a.Record(slog.LevelError, "sync failed", "timeout", nil)
+	_ = a.Flush(context.Background())
+
+	// The same error keeps happening during the cooldown: held, not posted.
+	for range 3 {
+		clock = clock.Add(10 * time.Minute)
+		a.Record(slog.LevelError, "sync failed", "timeout", nil)
+		_ = a.Flush(context.Background())
+	}
+	if len(pub.created) != 1 {
+		t.Fatalf("expected repeats held during cooldown, got %d events", len(pub.created))
+	}
+
+	clock = clock.Add(time.Hour)
+	_ = a.Flush(context.Background())
+	reports := pub.reports(t)
+	if len(reports) != 2 || reports[1].Groups[0].Count != 3 {
+		t.Fatalf("expected held repeats reported after cooldown, got %+v", reports)
+	}
+}
+
+func TestFlush_CapsGroupsAndCountsOverflow(t *testing.T) {
+	clock := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pub := &fakePublisher{}
+	a := newTestAggregator(pub, &clock)
+
+	for i := range maxTrackedGroups + 5 {
+		a.Record(slog.LevelError, fmt.Sprintf("failure %c%c", 'a'+i%26, 'a'+i/26), "", nil)
+	}
+	_ = a.Flush(context.Background())
+	r := pub.reports(t)[0]
+	if len(r.Groups) != 2 || r.Omitted != maxTrackedGroups-2 || r.Dropped != 5 {
+		t.Errorf("unexpected caps: groups %d omitted %d dropped %d", len(r.Groups), r.Omitted, r.Dropped)
+	}
+	if got := r.Summary(); got != "2 errors in 2 groups from controller (+198 more groups)" {
+		t.Errorf("Summary() = %q", got)
+	}
+}
@@ -0,0 +1,582 @@
+// Package fakedaemon is an in-memory stand-in for the beads daemon, for
+// integration tests. It serves the subset of the HTTP API that beadsapi and
+// the SSE watcher use — bead CRUD, close, labels, configs, health — and emits
+// beads.bead.* events on /v1/events/stream exactly as the daemon does,
+// including Last-Event-ID replay.
+//
+// Use it with httptest:
+//
+//	d := fakedaemon.New()
+//	srv := httptest.NewServer(d)
+//	client, _ := beadsapi.New(beadsapi.Config{HTTPAddr: srv.URL})
+package fakedaemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bead is a bead as stored by the fake daemon.
+type Bead struct {
+	ID          string         `json:"id"`
+	Title       string         `json:"title"`
+	Kind        string         `json:"kind"`
+	Type        string         `json:"type"`
+	Status      string         `json:"status"`
+	Assignee    string         `json:"assignee"`
+	Priority    int            `json:"priority"`
+	Labels      []string       `json:"labels"`
+	Notes       string         `json:"notes"`
+	Fields      map[string]any `json:"fields"`
+	Description string         `json:"description"`
+	CreatedBy   string         `json:"created_by"`
+	UpdatedAt   string         `json:"updated_at,omitempty"`
+}
+
+// clone returns a deep enough copy of b to hand out without the lock.
+func (b *Bead) clone() Bead {
+	c := *b
+	c.Labels = slices.Clone(b.Labels)
+	c.Fields = maps.Clone(b.Fields)
+	return c
+}
+
+// Field returns a field as a string ("" when unset).
+func (b Bead) Field(key string) string {
+	switch v := b.Fields[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		bs, _ := json.Marshal(v)
+		return string(bs)
+	}
+}
+
+// event is one entry in the SSE event log.
+type event struct {
+	id    int
+	topic string
+	data  []byte
+}
+
+// Daemon is the fake beads daemon. It implements http.Handler.
+type Daemon struct {
+	mux *http.ServeMux
+
+	mu      sync.Mutex
+	beads   map[string]*Bead
+	configs map[string]json.RawMessage
+	nextID  int
+	events  []event
+	changed chan struct{} // closed and replaced whenever an event is appended
+	kick    chan struct{} // closed and replaced by Disconnect
+}
+
+// New creates an empty fake daemon.
+func New() *Daemon {
+	d := &Daemon{
+		mux:     http.NewServeMux(),
+		beads:   make(map[string]*Bead),
+		configs: make(map[string]json.RawMessage),
+		changed: make(chan struct{}),
+		kick:    make(chan struct{}),
+	}
+	d.mux.HandleFunc("GET /v1/health", d.handleHealth)
+	d.mux.HandleFunc("GET /v1/beads", d.handleList)
+	d.mux.HandleFunc("POST /v1/beads", d.handleCreate)
+	d.mux.HandleFunc("GET /v1/beads/{id}", d.handleGet)
+	d.mux.HandleFunc("PATCH /v1/beads/{id}", d.handleUpdate)
+	d.mux.HandleFunc("DELETE /v1/beads/{id}", d.handleDelete)
+	d.mux.HandleFunc("POST /v1/beads/{id}/close", d.handleClose)
+	d.mux.HandleFunc("POST /v1/beads/{id}/labels", d.handleAddLabel)
+	d.mux.HandleFunc("DELETE /v1/beads/{id}/labels/{label}", d.handleRemoveLabel)
+	d.mux.HandleFunc("GET /v1/configs", d.handleListConfigs)
+	d.mux.HandleFunc("GET /v1/configs/{key}", d.handleGetConfig)
+	d.mux.HandleFunc("PUT /v1/configs/{key}", d.handleSetConfig)
+	d.mux.HandleFunc("DELETE /v1/configs/{key}", d.handleDeleteConfig)
+	d.mux.HandleFunc("GET /v1/events/stream", d.handleStream)
+	return d
+}
+
+func (d *Daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mux.ServeHTTP(w, r)
+}
+
+// Bead returns a copy of the bead with the given ID.
+func (d *Daemon) Bead(id string) (Bead, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, ok := d.beads[id]
+	if !ok {
+		return Bead{}, false
+	}
+	return b.clone(), true
+}
+
+// Topics returns the topics of all events emitted so far, in order.
+func (d *Daemon) Topics() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]string, len(d.events))
+	for i, e := range d.events {
+		out[i] = e.topic
+	}
+	return out
+}
+
+// Disconnect ends every open event stream, as a daemon restart would.
+// Clients that reconnect with Last-Event-ID get the events they missed.
+func (d *Daemon) Disconnect() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	close(d.kick)
+	d.kick = make(chan struct{})
+}
+
+// emitLocked appends an event to the log and wakes streams. d.mu must be held.
+func (d *Daemon) emitLocked(topic string, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		// PANIC: payloads are beads and JSON-decoded change maps, which
+		// always marshal; a failure is a bug in this test fake.
+		panic(fmt.Sprintf("fakedaemon: marshaling %s event: %v", topic, err))
+	}
+	d.events = append(d.events, event{id: len(d.events) + 1, topic: topic, data: data})
+	close(d.changed)
+	d.changed = make(chan struct{})
+}
+
+// touchLocked stamps b as modified now. d.mu must be held.
+func touchLocked(b *Bead) {
+	b.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+// --- beads ---
+
+func (d *Daemon) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (d *Daemon) handleList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	types := splitList(q.Get("type"))
+	statuses := splitList(q.Get("status"))
+	labels := splitList(q.Get("labels"))
+	assignee := q.Get("assignee")
+	kind := q.Get("kind")
+
+	d.mu.Lock()
+	out := []Bead{}
+	for _, b := range d.beads {
+		if len(types) > 0 && !slices.Contains(types, b.Type) ||
+			len(statuses) > 0 && !slices.Contains(statuses, b.Status) ||
+			assignee != "" && b.Assignee != assignee ||
+			kind != "" && b.Kind != kind {
+			continue
+		}
+		if !containsAll(b.Labels, labels) {
+			continue
+		}
+		out = append(out, b.clone())
+	}
+	d.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return beadSeq(out[i].ID) < beadSeq(out[j].ID) })
+	total := len(out)
+	if n, err := strconv.Atoi(q.Get("offset")); err == nil && n > 0 {
+		out = out[min(n, len(out)):]
+	}
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 && n < len(out) {
+		out = out[:n]
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"beads": out, "total": total})
+}
+
+func (d *Daemon) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title       string          `json:"title"`
+		Description string          `json:"description"`
+		Kind        string          `json:"kind"`
+		Type        string          `json:"type"`
+		Priority    int             `json:"priority"`
+		Labels      []string        `json:"labels"`
+		Assignee    string          `json:"assignee"`
+		CreatedBy   string          `json:"created_by"`
+		Fields      json.RawMessage `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Title == "" || req.Type == "" {
+		writeError(w, http.StatusBadRequest, "title and type are required")
+		return
+	}
+	fields, err := decodeFields(req.Fields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	kind := req.Kind
+	if kind == "" {
+		kind = "issue"
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID++
+	b := &Bead{
+		ID:          fmt.Sprintf("kd-%d", d.nextID),
+		Title:       req.Title,
+		Kind:        kind,
+		Type:        req.Type,
+		Status:      "open",
+		Assignee:    req.Assignee,
+		Priority:    req.Priority,
+		Labels:      slices.Clone(req.Labels),
+		Fields:      fields,
+		Description: req.Description,
+		CreatedBy:   req.CreatedBy,
+	}
+	touchLocked(b)
+	d.beads[b.ID] = b
+	d.emitLocked("beads.bead.created", map[string]any{"bead": b})
+	writeJSON(w, http.StatusCreated, b)
+}
+
+func (d *Daemon) handleGet(w http.ResponseWriter, r *http.Request) {
+	b, ok := d.Bead(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "bead not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+// handleUpdate applies a PATCH. Like the daemon, "fields" replaces the whole
+// fields object; callers merge first.
+func (d *Daemon) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title       *string         `json:"title"`
+		Description *string         `json:"description"`
+		Assignee    *string         `json:"assignee"`
+		Status      *string         `json:"status"`
+		Notes       *string         `json:"notes"`
+		Priority    *int            `json:"priority"`
+		Fields      json.RawMessage `json:"fields"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	var fields map[string]any
+	if len(req.Fields) > 0 {
+		var err error
+		if fields, err = decodeFields(req.Fields); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, ok := d.beads[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "bead not found")
+		return
+	}
+	changes := make(map[string]any)
+	set := func(name string, dst *string, v *string) {
+		if v != nil && *dst != *v {
+			*dst = *v
+			changes[name] = *v
+		}
+	}
+	set("title", &b.Title, req.Title)
+	set("description", &b.Description, req.Description)
+	set("assignee", &b.Assignee, req.Assignee)
+	set("status", &b.Status, req.Status)
+	set("notes", &b.Notes, req.Notes)
+	if req.Priority != nil && b.Priority != *req.Priority {
+		b.Priority = *req.Priority
+		changes["priority"] = *req.Priority
+	}
+	if fields != nil && !fieldsEqual(b.Fields, fields) {
+		b.Fields = fields
+		changes["fields"] = fields
+	}
+	if len(changes) > 0 {
+		touchLocked(b)
+		d.emitLocked("beads.bead.updated", map[string]any{"bead": b, "changes": changes})
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (d *Daemon) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.beads[id]; !ok {
+		writeError(w, http.StatusNotFound, "bead not found")
+		return
+	}
+	delete(d.beads, id)
+	d.emitLocked("beads.bead.deleted", map[string]any{"bead_id": id})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleClose closes a bead. Body keys other than closed_by are merged into
+// the bead's fields, as the daemon does.
+func (d *Daemon) handleClose(w http.ResponseWriter, r *http.Request) {
+	body := make(map[string]any)
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+			return
+		}
+	}
+	closedBy, _ := body["closed_by"].(string)
+	delete(body, "closed_by")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, ok := d.beads[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "bead not found")
+		return
+	}
+	if len(body) > 0 {
+		if b.Fields == nil {
+			b.Fields = make(map[string]any)
+		}
+		maps.Copy(b.Fields, body)
+	}
+	b.Status = "closed"
+	touchLocked(b)
+	d.emitLocked("beads.bead.closed", map[string]any{"bead": b, "closed_by": closedBy})
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (d *Daemon) handleAddLabel(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Label string `json:"label"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Label == "" {
+		writeError(w, http.StatusBadRequest, "label is required")
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, ok := d.beads[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "bead not found")
+		return
+	}
+	if !slices.Contains(b.Labels, req.Label) {
+		b.Labels = append(b.Labels, req.Label)
+		touchLocked(b)
+		d.emitLocked("beads.bead.updated", map[string]any{"bead": b, "changes": map[string]any{"labels": b.Labels}})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *Daemon) handleRemoveLabel(w http.ResponseWriter, r *http.Request) {
+	label := r.PathValue("label")
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, ok := d.beads[r.PathValue("id")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "bead not found")
+		return
+	}
+	if i := slices.Index(b.Labels, label); i >= 0 {
+		b.Labels = slices.Delete(b.Labels, i, i+1)
+		touchLocked(b)
+		d.emitLocked("beads.bead.updated", map[string]any{"bead": b, "changes": map[string]any{"labels": b.Labels}})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- configs ---
+
+type configEntry struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+func (d *Daemon) handleListConfigs(w http.ResponseWriter, r *http.Request) {
+	ns := r.URL.Query().Get("namespace")
+	d.mu.Lock()
+	out := []configEntry{}
+	for k, v := range d.configs {
+		if ns == "" || strings.HasPrefix(k, ns+":") {
+			out = append(out, configEntry{Key: k, Value: v})
+		}
+	}
+	d.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	writeJSON(w, http.StatusOK, map[string]any{"configs": out})
+}
+
+func (d *Daemon) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	d.mu.Lock()
+	v, ok := d.configs[key]
+	d.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "config not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, configEntry{Key: key, Value: v})
+}
+
+func (d *Daemon) handleSetConfig(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Value) == 0 {
+		writeError(w, http.StatusBadRequest, "value is required")
+		return
+	}
+	key := r.PathValue("key")
+	d.mu.Lock()
+	d.configs[key] = req.Value
+	d.mu.Unlock()
+	writeJSON(w, http.StatusOK, configEntry{Key: key, Value: req.Value})
+}
+
+func (d *Daemon) handleDeleteConfig(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	delete(d.configs, r.PathValue("key"))
+	d.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// --- events ---
+
+// handleStream serves the SSE event stream. The topics parameter is a
+// comma-separated list of NATS-style patterns ("beads.bead.*", "beads.>").
+// New streams start at the current event; a Last-Event-ID header instead
+// replays every later event before streaming live.
+func (d *Daemon) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+	patterns := splitList(r.URL.Query().Get("topics"))
+	d.mu.Lock()
+	next := len(d.events) // index into d.events of the next event to send
+	d.mu.Unlock()
+	if id, err := strconv.Atoi(r.Header.Get("Last-Event-ID")); err == nil && id >= 0 {
+		next = id
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		d.mu.Lock()
+		pending := d.events[min(next, len(d.events)):]
+		next = len(d.events)
+		changed, kick := d.changed, d.kick
+		d.mu.Unlock()
+
+		for _, e := range pending {
+			if len(patterns) > 0 && !slices.ContainsFunc(patterns, func(p string) bool { return topicMatches(p, e.topic) }) {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.id, e.topic, e.data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-kick:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// topicMatches reports whether topic matches a NATS-style pattern, where "*"
+// matches one token and a trailing ">" matches one or more.
+func topicMatches(pattern, topic string) bool {
+	pt := strings.Split(pattern, ".")
+	tt := strings.Split(topic, ".")
+	for i, p := range pt {
+		if p == ">" {
+			return len(tt) > i
+		}
+		if i >= len(tt) || (p != "*" && p != tt[i]) {
+			return false
+		}
+	}
+	return len(pt) == len(tt)
+}
+
+// --- helpers ---
+
+func decodeFields(raw json.RawMessage) (map[string]any, error) {
+	fields := make(map[string]any)
+	if len(raw) == 0 || string(raw) == "null" {
+		return fields, nil
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("fields must be a JSON object: %w", err)
+	}
+	return fields, nil
+}
+
+func fieldsEqual(a, b map[string]any) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+// beadSeq extracts the sequence number from a "kd-N" ID for stable ordering.
+func beadSeq(id string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(id, "kd-"))
+	return n
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func containsAll(have, want []string) bool {
+	for _, w := range want {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
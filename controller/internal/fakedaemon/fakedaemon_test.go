@@ -0,0 +1,198 @@
+package fakedaemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func newTestClient(t *testing.T) (*Daemon, *beadsapi.Client, string) {
+	t.Helper()
+	d := New()
+	srv := httptest.NewServer(d)
+	t.Cleanup(srv.Close)
+	client, err := beadsapi.New(beadsapi.Config{HTTPAddr: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, client, srv.URL
+}
+
+func TestDaemon_AgentBeadRoundTrip(t *testing.T) {
+	d, client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	id, err := client.SpawnAgent(ctx, "furiosa", "gasboat", "", "crew")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UpdateAgentState(ctx, id, "working"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.UpdateBeadNotes(ctx, id, "pod_name: crew-gasboat-crew-furiosa"); err != nil {
+		t.Fatal(err)
+	}
+
+	agents, err := client.ListAgentBeads(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected 1 agent bead, got %d", len(agents))
+	}
+	a := agents[0]
+	if a.ID != id || a.Project != "gasboat" || a.AgentName != "furiosa" || a.AgentState != "working" {
+		t.Errorf("unexpected agent bead: %+v", a)
+	}
+	if a.Metadata["pod_name"] != "crew-gasboat-crew-furiosa" {
+		t.Errorf("expected notes merged into metadata, got %v", a.Metadata)
+	}
+
+	if err := client.CloseBead(ctx, id, map[string]string{"reason": "done"}); err != nil {
+		t.Fatal(err)
+	}
+	if agents, _ := client.ListAgentBeads(ctx); len(agents) != 0 {
+		t.Errorf("expected closed bead excluded from active list, got %d", len(agents))
+	}
+	b, _ := d.Bead(id)
+	if b.Status != "closed" || b.Field("reason") != "done" || b.Field("agent_state") != "working" {
+		t.Errorf("unexpected closed bead: %+v", b)
+	}
+
+	want := []string{
+		"beads.bead.created",
+		"beads.bead.updated", // project label
+		"beads.bead.updated", // role label
+		"beads.bead.updated", // agent_state
+		"beads.bead.updated", // notes
+		"beads.bead.closed",
+	}
+	if got := d.Topics(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Topics() = %v, want %v", got, want)
+	}
+}
+
+func TestDaemon_ProjectsAndConfigs(t *testing.T) {
+	_, client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	fields, _ := json.Marshal(map[string]any{
+		"prefix":  "gb",
+		"git_url": "https://github.com/example/gasboat.git",
+		"secrets": []map[string]string{{"env": "TOKEN", "secret": "s", "key": "k"}},
+	})
+	if _, err := client.CreateBead(ctx, beadsapi.CreateBeadRequest{Title: "gasboat", Type: "project", Fields: fields}); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := client.ListProjectBeads(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := projects["gasboat"]
+	if p.Prefix != "gb" || len(p.Secrets) != 1 || p.Secrets[0].Env != "TOKEN" {
+		t.Errorf("unexpected project: %+v", p)
+	}
+
+	if err := client.SetConfig(ctx, "view:ready", []byte(`{"limit":5}`)); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := client.GetConfig(ctx, "view:ready")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(entry.Value) != `{"limit":5}` {
+		t.Errorf("GetConfig value = %s", entry.Value)
+	}
+	if _, err := client.GetConfig(ctx, "missing"); err == nil {
+		t.Error("expected error for a missing config")
+	}
+}
+
+// readEvent reads one SSE event (id and topic) from r.
+func readEvent(t *testing.T, r *bufio.Reader) (id, topic string) {
+	t.Helper()
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" && topic != "":
+			return id, topic
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			topic = strings.TrimPrefix(line, "event: ")
+		}
+	}
+}
+
+func openStream(t *testing.T, url, lastEventID string) *bufio.Reader {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url+"/v1/events/stream?topics=beads.bead.*", nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("stream returned %d", resp.StatusCode)
+	}
+	return bufio.NewReader(resp.Body)
+}
+
+func TestDaemon_StreamReplaysAfterLastEventID(t *testing.T) {
+	d, client, url := newTestClient(t)
+	ctx := context.Background()
+
+	stream := openStream(t, url, "")
+	id, _ := client.CreateBead(ctx, beadsapi.CreateBeadRequest{Title: "t", Type: "task"})
+	if eid, topic := readEvent(t, stream); eid != "1" || topic != "beads.bead.created" {
+		t.Fatalf("got event %s %s", eid, topic)
+	}
+
+	// Events emitted while disconnected are replayed on reconnect.
+	d.Disconnect()
+	_ = client.UpdateAgentState(ctx, id, "working")
+	_ = client.CloseBead(ctx, id, nil)
+
+	stream = openStream(t, url, "1")
+	if eid, topic := readEvent(t, stream); eid != "2" || topic != "beads.bead.updated" {
+		t.Errorf("got event %s %s, want 2 beads.bead.updated", eid, topic)
+	}
+	if eid, topic := readEvent(t, stream); eid != "3" || topic != "beads.bead.closed" {
+		t.Errorf("got event %s %s, want 3 beads.bead.closed", eid, topic)
+	}
+}
+
+func TestTopicMatches(t *testing.T) {
+	cases := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"beads.bead.*", "beads.bead.created", true},
+		{"beads.bead.*", "beads.bead", false},
+		{"beads.>", "beads.bead.closed", true},
+		{"beads.>", "beads", false},
+		{"decisions.>", "beads.bead.created", false},
+		{"beads.bead.created", "beads.bead.created", true},
+	}
+	for _, c := range cases {
+		if got := topicMatches(c.pattern, c.topic); got != c.want {
+			t.Errorf("topicMatches(%q, %q) = %v, want %v", c.pattern, c.topic, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package faults injects failures into the controller's dependencies so that
+// orphan protection and recovery paths can be exercised in staging. It is
+// for testing only and is off unless FAULT_INJECTION=true.
+//
+// Three faults are supported, each with its own probability:
+//   - K8s: pod manager calls (create, delete, list, get) fail.
+//   - Daemon: HTTP requests to the beads daemon are delayed.
+//   - SSE: events on the beads event stream are dropped.
+package faults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+// ErrInjected is wrapped by every failure this package injects.
+var ErrInjected = errors.New("injected fault")
+
+// Config holds fault probabilities (0 disables a fault, 1 always fires).
+type Config struct {
+	K8sErrorRate    float64       // probability a pod manager call fails
+	DaemonDelayRate float64       // probability a daemon request is delayed
+	DaemonDelay     time.Duration // maximum added delay; each delay is uniform in (0, DaemonDelay]
+	SSEDropRate     float64       // probability an SSE event is dropped
+	Seed            uint64        // 0 = random
+}
+
+// Injector decides, per call, whether to inject a fault.
+type Injector struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// New creates an Injector.
+func New(cfg Config, logger *slog.Logger) *Injector {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &Injector{
+		cfg:    cfg,
+		logger: logger,
+		rng:    rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// roll returns true with probability p, and a uniform value in [0, 1).
+func (i *Injector) roll(p float64) (bool, float64) {
+	if p <= 0 {
+		return false, 0
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rng.Float64() < p, i.rng.Float64()
+}
+
+// k8sFault returns an injected error for op, or nil.
+func (i *Injector) k8sFault(op string) error {
+	if hit, _ := i.roll(i.cfg.K8sErrorRate); !hit {
+		return nil
+	}
+	i.logger.Info("fault injection: failing K8s call", "op", op)
+	return fmt.Errorf("%s: %w", op, ErrInjected)
+}
+
+// DropEvent reports whether an SSE event on topic should be dropped.
+func (i *Injector) DropEvent(topic string) bool {
+	hit, _ := i.roll(i.cfg.SSEDropRate)
+	if hit {
+		i.logger.Info("fault injection: dropping SSE event", "topic", topic)
+	}
+	return hit
+}
+
+// Manager wraps m so that its calls fail with probability K8sErrorRate.
+func (i *Injector) Manager(m podmanager.Manager) podmanager.Manager {
+	return &faultyManager{next: m, inj: i}
+}
+
+type faultyManager struct {
+	next podmanager.Manager
+	inj  *Injector
+}
+
+func (f *faultyManager) CreateAgentPod(ctx context.Context, spec podmanager.AgentPodSpec) error {
+	if err := f.inj.k8sFault("create pod"); err != nil {
+		return err
+	}
+	return f.next.CreateAgentPod(ctx, spec)
+}
+
+func (f *faultyManager) DeleteAgentPod(ctx context.Context, name, namespace string) error {
+	if err := f.inj.k8sFault("delete pod"); err != nil {
+		return err
+	}
+	return f.next.DeleteAgentPod(ctx, name, namespace)
+}
+
+// DeleteClusterPod forwards cluster-specific deletes (podmanager.MultiCluster)
+// so wrapping a multi-cluster manager doesn't hide them from the reconciler.
+func (f *faultyManager) DeleteClusterPod(ctx context.Context, cluster, name, namespace string) error {
+	if err := f.inj.k8sFault("delete pod"); err != nil {
+		return err
+	}
+	if d, ok := f.next.(interface {
+		DeleteClusterPod(ctx context.Context, cluster, name, namespace string) error
+	}); ok {
+		return d.DeleteClusterPod(ctx, cluster, name, namespace)
+	}
+	return f.next.DeleteAgentPod(ctx, name, namespace)
+}
+
+// AnnotateAgentPod forwards pod annotation (podmanager.K8sManager,
+// podmanager.MultiCluster), which pod adoption and the termination grace
+// window need.
+func (f *faultyManager) AnnotateAgentPod(ctx context.Context, pod *corev1.Pod, annotations map[string]string) error {
+	if err := f.inj.k8sFault("annotate pod"); err != nil {
+		return err
+	}
+	a, ok := f.next.(interface {
+		AnnotateAgentPod(ctx context.Context, pod *corev1.Pod, annotations map[string]string) error
+	})
+	if !ok {
+		return errors.New("pod manager can't annotate pods")
+	}
+	return a.AnnotateAgentPod(ctx, pod, annotations)
+}
+
+// AdoptLegacyWorkspaces forwards workspace claim adoption
+// (podmanager.K8sManager, podmanager.MultiCluster). A manager keeping no
+// workspace claims has none to adopt.
+func (f *faultyManager) AdoptLegacyWorkspaces(ctx context.Context, namespace string, beads map[string]string) (int, error) {
+	if err := f.inj.k8sFault("adopt workspaces"); err != nil {
+		return 0, err
+	}
+	a, ok := f.next.(interface {
+		AdoptLegacyWorkspaces(ctx context.Context, namespace string, beads map[string]string) (int, error)
+	})
+	if !ok {
+		return 0, nil
+	}
+	return a.AdoptLegacyWorkspaces(ctx, namespace, beads)
+}
+
+func (f *faultyManager) ListAgentPods(ctx context.Context, namespace string, labelSelector map[string]string) ([]corev1.Pod, error) {
+	if err := f.inj.k8sFault("list pods"); err != nil {
+		return nil, err
+	}
+	return f.next.ListAgentPods(ctx, namespace, labelSelector)
+}
+
+func (f *faultyManager) GetAgentPod(ctx context.Context, name, namespace string) (*corev1.Pod, error) {
+	if err := f.inj.k8sFault("get pod"); err != nil {
+		return nil, err
+	}
+	return f.next.GetAgentPod(ctx, name, namespace)
+}
+
+// Transport wraps next (http.DefaultTransport when nil) so that requests are
+// delayed with probability DaemonDelayRate.
+func (i *Injector) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if hit, u := i.roll(i.cfg.DaemonDelayRate); hit && i.cfg.DaemonDelay > 0 {
+			delay := time.Duration((1 - u) * float64(i.cfg.DaemonDelay))
+			i.logger.Info("fault injection: delaying daemon request",
+				"method", req.Method, "path", req.URL.Path, "delay", delay)
+			select {
+			case <-time.After(delay):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
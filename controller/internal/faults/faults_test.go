@@ -0,0 +1,112 @@
+package faults
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+type stubManager struct{ calls int }
+
+func (s *stubManager) CreateAgentPod(context.Context, podmanager.AgentPodSpec) error {
+	s.calls++
+	return nil
+}
+
+func (s *stubManager) DeleteAgentPod(context.Context, string, string) error {
+	s.calls++
+	return nil
+}
+
+func (s *stubManager) ListAgentPods(context.Context, string, map[string]string) ([]corev1.Pod, error) {
+	s.calls++
+	return nil, nil
+}
+
+func (s *stubManager) GetAgentPod(context.Context, string, string) (*corev1.Pod, error) {
+	s.calls++
+	return &corev1.Pod{}, nil
+}
+
+func testInjector(cfg Config) *Injector {
+	cfg.Seed = 1
+	return New(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestManager_FailsAtRate(t *testing.T) {
+	ctx := context.Background()
+
+	next := &stubManager{}
+	m := testInjector(Config{K8sErrorRate: 1}).Manager(next)
+	if _, err := m.ListAgentPods(ctx, "ns", nil); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+	if err := m.DeleteAgentPod(ctx, "p", "ns"); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+	if next.calls != 0 {
+		t.Errorf("failed calls must not reach the real manager, got %d", next.calls)
+	}
+
+	next = &stubManager{}
+	m = testInjector(Config{}).Manager(next)
+	if _, err := m.GetAgentPod(ctx, "p", "ns"); err != nil || next.calls != 1 {
+		t.Errorf("rate 0 must pass through: err %v, calls %d", err, next.calls)
+	}
+
+	// A partial rate fails some calls and passes the rest.
+	next = &stubManager{}
+	m = testInjector(Config{K8sErrorRate: 0.5}).Manager(next)
+	failed := 0
+	for range 200 {
+		if m.CreateAgentPod(ctx, podmanager.AgentPodSpec{}) != nil {
+			failed++
+		}
+	}
+	if failed < 60 || failed > 140 || failed+next.calls != 200 {
+		t.Errorf("expected about half of 200 calls to fail, got %d (passed %d)", failed, next.calls)
+	}
+}
+
+func TestTransport_DelaysRequests(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: testInjector(Config{DaemonDelayRate: 1, DaemonDelay: 50 * time.Millisecond}).Transport(nil)}
+	start := time.Now()
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if time.Since(start) > time.Second {
+		t.Errorf("delay should be bounded by DaemonDelay, took %s", time.Since(start))
+	}
+
+	// A canceled request stops waiting.
+	client = &http.Client{Transport: testInjector(Config{DaemonDelayRate: 1, DaemonDelay: time.Hour}).Transport(nil)}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestDropEvent(t *testing.T) {
+	if !testInjector(Config{SSEDropRate: 1}).DropEvent("beads.bead.created") {
+		t.Error("rate 1 should drop")
+	}
+	if testInjector(Config{}).DropEvent("beads.bead.created") {
+		t.Error("rate 0 should never drop")
+	}
+}
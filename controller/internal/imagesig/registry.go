@@ -0,0 +1,203 @@
+package imagesig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxManifestBytes caps manifests and signature payloads read from a
+// registry.
+const maxManifestBytes = 4 << 20
+
+var manifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+}, ", ")
+
+// imageRef is a parsed image reference.
+type imageRef struct {
+	registry   string // host[:port], e.g. "ghcr.io"
+	repository string // e.g. "groblegark/gasboat/agent"
+	tag        string
+	digest     string
+}
+
+// parseRef parses "host/repo:tag" and "host/repo@sha256:..." references.
+// References without a registry host are Docker Hub images.
+func parseRef(image string) (imageRef, error) {
+	var ref imageRef
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.tag = name[:i], name[i+1:]
+	}
+	if ref.tag == "" && ref.digest == "" {
+		ref.tag = "latest"
+	}
+	first, rest, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.registry, ref.repository = first, rest
+	} else {
+		ref.registry, ref.repository = "docker.io", name
+		if !found {
+			ref.repository = "library/" + name
+		}
+	}
+	if ref.repository == "" {
+		return ref, fmt.Errorf("invalid image reference %q", image)
+	}
+	if ref.registry == "docker.io" {
+		ref.registry = "registry-1.docker.io"
+	}
+	return ref, nil
+}
+
+// registry is a minimal anonymous OCI distribution client.
+type registry struct {
+	client *http.Client
+	scheme string // "https"; tests use "http"
+}
+
+// manifest fetches a manifest by tag or digest and returns its body and
+// digest. A missing manifest is reported as errNotFound.
+func (r *registry) manifest(ctx context.Context, ref imageRef, reference string) ([]byte, string, error) {
+	u := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", r.scheme, ref.registry, ref.repository, reference)
+	resp, err := r.get(ctx, ref, u, manifestAccept)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestBytes))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading manifest: %w", err)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = sha256Digest(body)
+	}
+	return body, digest, nil
+}
+
+// blob fetches a blob and checks it against its digest.
+func (r *registry) blob(ctx context.Context, ref imageRef, digest string) ([]byte, error) {
+	u := fmt.Sprintf("%s://%s/v2/%s/blobs/%s", r.scheme, ref.registry, ref.repository, digest)
+	resp, err := r.get(ctx, ref, u, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestBytes))
+	if err != nil {
+		return nil, fmt.Errorf("reading blob: %w", err)
+	}
+	if got := sha256Digest(body); got != digest {
+		return nil, fmt.Errorf("blob %s has digest %s", digest, got)
+	}
+	return body, nil
+}
+
+// get issues a GET, answering a bearer token challenge with an anonymous
+// pull token.
+func (r *registry) get(ctx context.Context, ref imageRef, u, accept string) (*http.Response, error) {
+	do := func(token string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		return r.client.Do(req)
+	}
+	resp, err := do("")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		token, err := r.token(ctx, challenge, ref)
+		if err != nil {
+			return nil, fmt.Errorf("getting registry token: %w", err)
+		}
+		if resp, err = do(token); err != nil {
+			return nil, err
+		}
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errNotFound
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s returned %d", u, resp.StatusCode)
+	}
+}
+
+// token answers a `Bearer realm="...",service="..."` challenge.
+func (r *registry) token(ctx context.Context, challenge string, ref imageRef) (string, error) {
+	params := parseChallenge(challenge)
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+	q := url.Values{}
+	if s := params["service"]; s != "" {
+		q.Set("service", s)
+	}
+	q.Set("scope", "repository:"+ref.repository+":pull")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d", resp.StatusCode)
+	}
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestBytes)).Decode(&tok); err != nil {
+		return "", err
+	}
+	if tok.Token == "" {
+		return tok.AccessToken, nil
+	}
+	return tok.Token, nil
+}
+
+// parseChallenge parses the parameters of a WWW-Authenticate Bearer
+// challenge.
+func parseChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return params
+	}
+	for _, part := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			params[k] = strings.Trim(v, `"`)
+		}
+	}
+	return params
+}
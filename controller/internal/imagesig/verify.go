@@ -0,0 +1,481 @@
+// Package imagesig verifies cosign signatures of agent images before their
+// pods are scheduled.
+//
+// The verifier resolves an image to its manifest digest, fetches the cosign
+// signature manifest stored next to it ("sha256-<hex>.sig"), and accepts the
+// image when any signature over that digest verifies with:
+//
+//   - one of the configured public keys (cosign sign --key), or
+//   - a Fulcio certificate chaining to the configured roots, issued to one
+//     of the configured identities, whose signature is recorded in the Rekor
+//     transparency log (keyless signing). The Rekor bundle's signed entry
+//     timestamp is checked with the configured Rekor public key, and the
+//     certificate must have been valid at that time.
+//
+// Registries are accessed anonymously.
+package imagesig
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cosign signature layer annotations.
+const (
+	annotationSignature   = "dev.cosignproject.cosign/signature"
+	annotationCertificate = "dev.sigstore.cosign/certificate"
+	annotationChain       = "dev.sigstore.cosign/chain"
+	annotationBundle      = "dev.sigstore.cosign/bundle"
+)
+
+// CacheTTL is how long a verification result is reused for an image.
+const CacheTTL = 5 * time.Minute
+
+var errNotFound = errors.New("not found")
+
+// ErrUnsigned is returned for images without a valid signature.
+var ErrUnsigned = errors.New("no valid signature")
+
+// Fulcio certificate extensions naming the OIDC issuer.
+var (
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// Identity is a keyless signer: the OIDC issuer and a regular expression
+// the certificate subject (email or URI SAN) must fully match.
+type Identity struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+
+	subject *regexp.Regexp
+}
+
+// Config holds the trust material, PEM-encoded.
+type Config struct {
+	// Keys holds one or more PUBLIC KEY blocks.
+	Keys []byte
+	// Identities, Roots (Fulcio root and intermediate certificates), and
+	// RekorKey enable keyless verification; all three are required.
+	Identities []Identity
+	Roots      []byte
+	RekorKey   []byte
+}
+
+// Verifier checks image signatures, caching results for CacheTTL.
+type Verifier struct {
+	keys          []crypto.PublicKey
+	identities    []Identity
+	roots         *x509.CertPool
+	intermediates *x509.CertPool
+	rekorKey      crypto.PublicKey
+
+	registry registry
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]result
+}
+
+type result struct {
+	err error
+	at  time.Time
+}
+
+// New creates a verifier. At least one key or identity is required.
+func New(cfg Config, logger *slog.Logger) (*Verifier, error) {
+	v := &Verifier{
+		registry: registry{client: &http.Client{Timeout: 10 * time.Second}, scheme: "https"},
+		logger:   logger,
+		now:      time.Now,
+		cache:    make(map[string]result),
+	}
+	keys, err := parsePublicKeys(cfg.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("public keys: %w", err)
+	}
+	v.keys = keys
+
+	if len(cfg.Identities) > 0 {
+		for _, id := range cfg.Identities {
+			if id.Issuer == "" || id.Subject == "" {
+				return nil, fmt.Errorf("identity %+v: issuer and subject are required", id)
+			}
+			re, err := regexp.Compile("^(?:" + id.Subject + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("identity subject %q: %w", id.Subject, err)
+			}
+			id.subject = re
+			v.identities = append(v.identities, id)
+		}
+		v.roots, v.intermediates = x509.NewCertPool(), x509.NewCertPool()
+		certs, err := parseCertificates(cfg.Roots)
+		if err != nil || len(certs) == 0 {
+			return nil, fmt.Errorf("keyless verification needs Fulcio root certificates: %v", err)
+		}
+		for _, c := range certs {
+			if bytes.Equal(c.RawIssuer, c.RawSubject) {
+				v.roots.AddCert(c)
+			} else {
+				v.intermediates.AddCert(c)
+			}
+		}
+		rekor, err := parsePublicKeys(cfg.RekorKey)
+		if err != nil || len(rekor) != 1 {
+			return nil, fmt.Errorf("keyless verification needs one Rekor public key: %v", err)
+		}
+		v.rekorKey = rekor[0]
+	}
+	if len(v.keys) == 0 && len(v.identities) == 0 {
+		return nil, errors.New("no public keys or identities configured")
+	}
+	return v, nil
+}
+
+// Verify returns nil when image's current digest carries a valid
+// signature. Errors wrap ErrUnsigned when the image has no valid signature;
+// other errors mean it couldn't be checked.
+func (v *Verifier) Verify(ctx context.Context, image string) error {
+	v.mu.Lock()
+	if r, ok := v.cache[image]; ok && v.now().Sub(r.at) < CacheTTL {
+		v.mu.Unlock()
+		return r.err
+	}
+	v.mu.Unlock()
+
+	err := v.verify(ctx, image)
+	if err == nil || errors.Is(err, ErrUnsigned) {
+		v.mu.Lock()
+		v.cache[image] = result{err: err, at: v.now()}
+		v.mu.Unlock()
+	}
+	return err
+}
+
+func (v *Verifier) verify(ctx context.Context, image string) error {
+	ref, err := parseRef(image)
+	if err != nil {
+		return err
+	}
+	reference := ref.digest
+	if reference == "" {
+		reference = ref.tag
+	}
+	_, digest, err := v.registry.manifest(ctx, ref, reference)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", image, err)
+	}
+	algo, hexDigest, ok := strings.Cut(digest, ":")
+	if !ok || algo != "sha256" {
+		return fmt.Errorf("resolving %s: unexpected digest %q", image, digest)
+	}
+
+	body, _, err := v.registry.manifest(ctx, ref, "sha256-"+hexDigest+".sig")
+	if errors.Is(err, errNotFound) {
+		return fmt.Errorf("%s (%s): %w", image, truncDigest(digest), ErrUnsigned)
+	}
+	if err != nil {
+		return fmt.Errorf("fetching signatures of %s: %w", image, err)
+	}
+	var manifest struct {
+		Layers []struct {
+			Digest      string            `json:"digest"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return fmt.Errorf("parsing signature manifest of %s: %w", image, err)
+	}
+
+	var reasons []string
+	for _, layer := range manifest.Layers {
+		sig, err := base64.StdEncoding.DecodeString(layer.Annotations[annotationSignature])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+		payload, err := v.registry.blob(ctx, ref, layer.Digest)
+		if err != nil {
+			return fmt.Errorf("fetching signature payload of %s: %w", image, err)
+		}
+		if err := v.verifyLayer(payload, sig, layer.Annotations, digest); err != nil {
+			reasons = append(reasons, err.Error())
+			continue
+		}
+		v.logger.Debug("verified image signature", "image", image, "digest", truncDigest(digest))
+		return nil
+	}
+	if len(reasons) == 0 {
+		reasons = append(reasons, "no signatures")
+	}
+	return fmt.Errorf("%s (%s): %w: %s", image, truncDigest(digest), ErrUnsigned, strings.Join(reasons, "; "))
+}
+
+// verifyLayer checks one signature: its payload must name digest and the
+// signature must verify with a configured key or a trusted certificate.
+func (v *Verifier) verifyLayer(payload, sig []byte, annotations map[string]string, digest string) error {
+	var simple struct {
+		Critical struct {
+			Image struct {
+				Digest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+			Type string `json:"type"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(payload, &simple); err != nil {
+		return fmt.Errorf("invalid payload: %w", err)
+	}
+	if simple.Critical.Image.Digest != digest {
+		return fmt.Errorf("signature is for %s", truncDigest(simple.Critical.Image.Digest))
+	}
+
+	for _, key := range v.keys {
+		if verifySignature(key, payload, sig) == nil {
+			return nil
+		}
+	}
+	if certPEM := annotations[annotationCertificate]; certPEM != "" && len(v.identities) > 0 {
+		return v.verifyKeyless(payload, sig, certPEM, annotations)
+	}
+	return errors.New("signature doesn't match any trusted key")
+}
+
+// verifyKeyless checks a Fulcio-certified signature and its Rekor entry.
+func (v *Verifier) verifyKeyless(payload, sig []byte, certPEM string, annotations map[string]string) error {
+	certs, err := parseCertificates([]byte(certPEM))
+	if err != nil || len(certs) == 0 {
+		return fmt.Errorf("invalid certificate: %v", err)
+	}
+	cert := certs[0]
+	if err := verifySignature(cert.PublicKey, payload, sig); err != nil {
+		return fmt.Errorf("certificate signature: %w", err)
+	}
+
+	signedAt, err := v.verifyBundle(annotations[annotationBundle], payload, sig, cert)
+	if err != nil {
+		return fmt.Errorf("rekor bundle: %w", err)
+	}
+
+	intermediates := v.intermediates
+	if chain, err := parseCertificates([]byte(annotations[annotationChain])); err == nil && len(chain) > 0 {
+		intermediates = v.intermediates.Clone()
+		for _, c := range chain {
+			if !bytes.Equal(c.RawIssuer, c.RawSubject) {
+				intermediates.AddCert(c)
+			}
+		}
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:         v.roots,
+		Intermediates: intermediates,
+		CurrentTime:   signedAt,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}); err != nil {
+		return fmt.Errorf("certificate: %w", err)
+	}
+
+	issuer := certIssuer(cert)
+	for _, subject := range certSubjects(cert) {
+		for _, id := range v.identities {
+			if id.Issuer == issuer && id.subject.MatchString(subject) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("certificate identity %v (issuer %s) is not trusted", certSubjects(cert), issuer)
+}
+
+// verifyBundle checks the Rekor signed entry timestamp and that the entry
+// records this signature, returning when it was logged.
+func (v *Verifier) verifyBundle(raw string, payload, sig []byte, cert *x509.Certificate) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, errors.New("missing")
+	}
+	var bundle struct {
+		SignedEntryTimestamp []byte `json:"SignedEntryTimestamp"`
+		Payload              struct {
+			Body           string `json:"body"`
+			IntegratedTime int64  `json:"integratedTime"`
+			LogIndex       int64  `json:"logIndex"`
+			LogID          string `json:"logID"`
+		} `json:"Payload"`
+	}
+	if err := json.Unmarshal([]byte(raw), &bundle); err != nil {
+		return time.Time{}, err
+	}
+	p := bundle.Payload
+	// The SET signs the canonical (sorted-key) JSON of the payload.
+	canonical, err := json.Marshal(map[string]any{
+		"body":           p.Body,
+		"integratedTime": p.IntegratedTime,
+		"logIndex":       p.LogIndex,
+		"logID":          p.LogID,
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := verifySignature(v.rekorKey, canonical, bundle.SignedEntryTimestamp); err != nil {
+		return time.Time{}, fmt.Errorf("signed entry timestamp: %w", err)
+	}
+
+	body, err := base64.StdEncoding.DecodeString(p.Body)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("entry body: %w", err)
+	}
+	var entry struct {
+		Kind string `json:"kind"`
+		Spec struct {
+			Data struct {
+				Hash struct {
+					Algorithm string `json:"algorithm"`
+					Value     string `json:"value"`
+				} `json:"hash"`
+			} `json:"data"`
+			Signature struct {
+				Content   []byte `json:"content"`
+				PublicKey struct {
+					Content []byte `json:"content"`
+				} `json:"publicKey"`
+			} `json:"signature"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return time.Time{}, fmt.Errorf("entry body: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	if entry.Kind != "hashedrekord" || entry.Spec.Data.Hash.Algorithm != "sha256" ||
+		entry.Spec.Data.Hash.Value != hex.EncodeToString(sum[:]) ||
+		!bytes.Equal(entry.Spec.Signature.Content, sig) {
+		return time.Time{}, errors.New("entry doesn't record this signature")
+	}
+	if certs, err := parseCertificates(entry.Spec.Signature.PublicKey.Content); err != nil ||
+		len(certs) == 0 || !certs[0].Equal(cert) {
+		return time.Time{}, errors.New("entry doesn't record this certificate")
+	}
+	return time.Unix(p.IntegratedTime, 0), nil
+}
+
+// verifySignature checks sig over msg the way cosign signs: SHA-256
+// digests for ECDSA (ASN.1) and RSA (PKCS #1 v1.5), the message itself for
+// Ed25519.
+func verifySignature(key crypto.PublicKey, msg, sig []byte) error {
+	sum := sha256.Sum256(msg)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if ecdsa.VerifyASN1(k, sum[:], sig) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig) == nil {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(k, msg, sig) {
+			return nil
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T", key)
+	}
+	return errors.New("invalid signature")
+}
+
+func parsePublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		return nil, errors.New("trailing data after PEM blocks")
+	}
+	return keys, nil
+}
+
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// certIssuer returns the OIDC issuer recorded in a Fulcio certificate.
+func certIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(oidIssuerV2):
+			var s string
+			if _, err := asn1.Unmarshal(ext.Value, &s); err == nil {
+				return s
+			}
+		case ext.Id.Equal(oidIssuerV1):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+// certSubjects returns a Fulcio certificate's email and URI SANs.
+func certSubjects(cert *x509.Certificate) []string {
+	subjects := append([]string(nil), cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		subjects = append(subjects, u.String())
+	}
+	return subjects
+}
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// truncDigest returns the first 12 hex chars of a digest for messages.
+func truncDigest(digest string) string {
+	d := strings.TrimPrefix(digest, "sha256:")
+	if len(d) > 12 {
+		return d[:12]
+	}
+	return d
+}
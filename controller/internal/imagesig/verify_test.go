@@ -0,0 +1,332 @@
+package imagesig
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRegistry serves one image and, optionally, its signature manifest.
+type fakeRegistry struct {
+	repo      string
+	manifests map[string][]byte // tag or digest → manifest
+	blobs     map[string][]byte
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *httptest.Server) {
+	reg := &fakeRegistry{
+		repo:      "org/agent",
+		manifests: map[string][]byte{},
+		blobs:     map[string][]byte{},
+	}
+	image := []byte(`{"schemaVersion":2,"layers":[]}`)
+	reg.manifests["v1"] = image
+	reg.manifests[sha256Digest(image)] = image
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		prefix := "/v2/" + reg.repo + "/"
+		rest, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		kind, ref, _ := strings.Cut(rest, "/")
+		var body []byte
+		switch kind {
+		case "manifests":
+			body, ok = reg.manifests[ref]
+		case "blobs":
+			body, ok = reg.blobs[ref]
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if kind == "manifests" {
+			w.Header().Set("Docker-Content-Digest", sha256Digest(body))
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return reg, srv
+}
+
+func (reg *fakeRegistry) imageDigest() string {
+	return sha256Digest(reg.manifests["v1"])
+}
+
+// sign stores a signature layer over payload with the given annotations.
+func (reg *fakeRegistry) sign(payload []byte, annotations map[string]string) {
+	digest := sha256Digest(payload)
+	reg.blobs[digest] = payload
+	layer := map[string]any{"digest": digest, "annotations": annotations}
+	manifest, _ := json.Marshal(map[string]any{"schemaVersion": 2, "layers": []any{layer}})
+	reg.manifests["sha256-"+strings.TrimPrefix(reg.imageDigest(), "sha256:")+".sig"] = manifest
+}
+
+func simpleSigning(digest string) []byte {
+	return []byte(`{"critical":{"identity":{"docker-reference":"org/agent"},"image":{"docker-manifest-digest":"` +
+		digest + `"},"type":"cosign container image signature"},"optional":null}`)
+}
+
+func newKey(t *testing.T) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func signASN1(t *testing.T, key *ecdsa.PrivateKey, msg []byte) []byte {
+	t.Helper()
+	sum := sha256.Sum256(msg)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func newTestVerifier(t *testing.T, cfg Config, srv *httptest.Server) *Verifier {
+	t.Helper()
+	v, err := New(cfg, slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError})))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	v.registry = registry{client: srv.Client(), scheme: "http"}
+	return v
+}
+
+func imageName(srv *httptest.Server) string {
+	u, _ := url.Parse(srv.URL)
+	return u.Host + "/org/agent:v1"
+}
+
+func TestVerify_PublicKey(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	key, pub := newKey(t)
+	v := newTestVerifier(t, Config{Keys: pub}, srv)
+	ctx := context.Background()
+
+	if err := v.Verify(ctx, imageName(srv)); !errors.Is(err, ErrUnsigned) {
+		t.Fatalf("unsigned image: err = %v, want ErrUnsigned", err)
+	}
+
+	payload := simpleSigning(reg.imageDigest())
+	reg.sign(payload, map[string]string{
+		annotationSignature: base64.StdEncoding.EncodeToString(signASN1(t, key, payload)),
+	})
+	v.cache = map[string]result{}
+	if err := v.Verify(ctx, imageName(srv)); err != nil {
+		t.Fatalf("signed image: %v", err)
+	}
+
+	// A signature by another key, or over another digest, isn't trusted.
+	other, _ := newKey(t)
+	reg.sign(payload, map[string]string{
+		annotationSignature: base64.StdEncoding.EncodeToString(signASN1(t, other, payload)),
+	})
+	v.cache = map[string]result{}
+	if err := v.Verify(ctx, imageName(srv)); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("foreign key: err = %v, want ErrUnsigned", err)
+	}
+	payload = simpleSigning("sha256:" + strings.Repeat("0", 64))
+	reg.sign(payload, map[string]string{
+		annotationSignature: base64.StdEncoding.EncodeToString(signASN1(t, key, payload)),
+	})
+	v.cache = map[string]result{}
+	if err := v.Verify(ctx, imageName(srv)); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("other digest: err = %v, want ErrUnsigned", err)
+	}
+}
+
+func TestVerify_CachesResults(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	key, pub := newKey(t)
+	v := newTestVerifier(t, Config{Keys: pub}, srv)
+	payload := simpleSigning(reg.imageDigest())
+	reg.sign(payload, map[string]string{
+		annotationSignature: base64.StdEncoding.EncodeToString(signASN1(t, key, payload)),
+	})
+	now := time.Now()
+	v.now = func() time.Time { return now }
+	if err := v.Verify(context.Background(), imageName(srv)); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	reg.manifests = map[string][]byte{}
+	if err := v.Verify(context.Background(), imageName(srv)); err != nil {
+		t.Errorf("cached Verify: %v", err)
+	}
+	now = now.Add(CacheTTL)
+	if err := v.Verify(context.Background(), imageName(srv)); err == nil {
+		t.Error("expected the result to expire")
+	}
+}
+
+var testIssuer = "https://token.actions.githubusercontent.com"
+
+// keylessSigner is a test Fulcio CA and Rekor log.
+type keylessSigner struct {
+	rootPEM  []byte
+	rekorPEM []byte
+	root     *x509.Certificate
+	rootKey  *ecdsa.PrivateKey
+	rekorKey *ecdsa.PrivateKey
+}
+
+func newKeylessSigner(t *testing.T) *keylessSigner {
+	t.Helper()
+	s := &keylessSigner{}
+	s.rootKey, _ = newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-fulcio"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &s.rootKey.PublicKey, s.rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.root, _ = x509.ParseCertificate(der)
+	s.rootPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	s.rekorKey, s.rekorPEM = newKey(t)
+	return s
+}
+
+// sign returns signature annotations for payload from a short-lived
+// certificate issued to subject.
+func (s *keylessSigner) sign(t *testing.T, payload []byte, subject string) map[string]string {
+	t.Helper()
+	key, _ := newKey(t)
+	issuer, _ := asn1.Marshal(testIssuer)
+	u, _ := url.Parse(subject)
+	signedAt := time.Now().Add(-30 * time.Minute)
+	tmpl := &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		NotBefore:       signedAt.Add(-time.Minute),
+		NotAfter:        signedAt.Add(10 * time.Minute), // expired by now
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:            []*url.URL{u},
+		ExtraExtensions: []pkix.Extension{{Id: oidIssuerV2, Value: issuer}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, s.root, &key.PublicKey, s.rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	sig := signASN1(t, key, payload)
+
+	sum := sha256.Sum256(payload)
+	entry, _ := json.Marshal(map[string]any{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]any{
+			"data": map[string]any{"hash": map[string]any{"algorithm": "sha256", "value": hex.EncodeToString(sum[:])}},
+			"signature": map[string]any{
+				"content":   sig,
+				"publicKey": map[string]any{"content": certPEM},
+			},
+		},
+	})
+	set := map[string]any{
+		"body":           base64.StdEncoding.EncodeToString(entry),
+		"integratedTime": signedAt.Unix(),
+		"logIndex":       42,
+		"logID":          "c0ffee",
+	}
+	canonical, _ := json.Marshal(set)
+	bundle, _ := json.Marshal(map[string]any{
+		"SignedEntryTimestamp": signASN1(t, s.rekorKey, canonical),
+		"Payload":              set,
+	})
+	return map[string]string{
+		annotationSignature:   base64.StdEncoding.EncodeToString(sig),
+		annotationCertificate: string(certPEM),
+		annotationBundle:      string(bundle),
+	}
+}
+
+func TestVerify_Keyless(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	signer := newKeylessSigner(t)
+	workflow := "https://github.com/groblegark/gasboat/.github/workflows/release.yml@refs/heads/main"
+	v := newTestVerifier(t, Config{
+		Identities: []Identity{{Issuer: testIssuer, Subject: `https://github\.com/groblegark/gasboat/.*`}},
+		Roots:      signer.rootPEM,
+		RekorKey:   signer.rekorPEM,
+	}, srv)
+	ctx := context.Background()
+
+	payload := simpleSigning(reg.imageDigest())
+	reg.sign(payload, signer.sign(t, payload, workflow))
+	if err := v.Verify(ctx, imageName(srv)); err != nil {
+		t.Fatalf("keyless signature: %v", err)
+	}
+
+	// Another repository's workflow isn't a trusted identity.
+	reg.sign(payload, signer.sign(t, payload, "https://github.com/evil/fork/.github/workflows/release.yml@refs/heads/main"))
+	v.cache = map[string]result{}
+	if err := v.Verify(ctx, imageName(srv)); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("untrusted identity: err = %v, want ErrUnsigned", err)
+	}
+
+	// A bundle whose timestamp isn't signed by Rekor is rejected.
+	ann := signer.sign(t, payload, workflow)
+	ann[annotationBundle] = strings.Replace(ann[annotationBundle], `"logIndex":42`, `"logIndex":43`, 1)
+	reg.sign(payload, ann)
+	v.cache = map[string]result{}
+	if err := v.Verify(ctx, imageName(srv)); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("forged bundle: err = %v, want ErrUnsigned", err)
+	}
+}
+
+func TestNew_RequiresTrustMaterial(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if _, err := New(Config{}, logger); err == nil {
+		t.Error("expected an error without keys or identities")
+	}
+	if _, err := New(Config{Identities: []Identity{{Issuer: testIssuer, Subject: ".*"}}}, logger); err == nil {
+		t.Error("expected an error for identities without roots")
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	for image, want := range map[string]imageRef{
+		"ghcr.io/groblegark/gasboat/agent:v1": {registry: "ghcr.io", repository: "groblegark/gasboat/agent", tag: "v1"},
+		"localhost:5000/agent":                {registry: "localhost:5000", repository: "agent", tag: "latest"},
+		"ubuntu":                              {registry: "registry-1.docker.io", repository: "library/ubuntu", tag: "latest"},
+		"ghcr.io/org/agent@sha256:abc":        {registry: "ghcr.io", repository: "org/agent", digest: "sha256:abc"},
+	} {
+		got, err := parseRef(image)
+		if err != nil || got != want {
+			t.Errorf("parseRef(%q) = %+v, %v; want %+v", image, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package podmanager
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Capacity is a point-in-time view of the free CPU, memory, and pod slots on
+// schedulable nodes. It is a lightweight simulation of the scheduler used to
+// defer pods that would sit Pending as unschedulable: it honors node
+// selectors, required node affinity, and NoSchedule/NoExecute taints, and
+// compares resource requests against allocatable minus what running pods
+// already request.
+type Capacity struct {
+	nodes []*nodeCapacity
+	// eligible limits which clusters unpinned specs may land on; nil means any.
+	eligible map[string]bool
+}
+
+type nodeCapacity struct {
+	cluster  string
+	name     string
+	labels   map[string]string
+	taints   []corev1.Taint
+	cpuMilli int64 // free
+	memBytes int64 // free
+	pods     int64 // free pod slots
+}
+
+// Capacity snapshots the schedulable capacity of the manager's cluster. It
+// lists ready, schedulable nodes and the non-terminal pods bound to them in
+// all namespaces, and records each node's remaining capacity.
+func (m *K8sManager) Capacity(ctx context.Context) (*Capacity, error) {
+	nodeList, err := m.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+	podList, err := m.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing pods: %w", err)
+	}
+
+	byName := make(map[string]*nodeCapacity, len(nodeList.Items))
+	c := &Capacity{}
+	for i := range nodeList.Items {
+		n := &nodeList.Items[i]
+		if n.Spec.Unschedulable || !nodeReady(n) {
+			continue
+		}
+		alloc := n.Status.Allocatable
+		nc := &nodeCapacity{
+			name:     n.Name,
+			labels:   n.Labels,
+			taints:   n.Spec.Taints,
+			cpuMilli: alloc.Cpu().MilliValue(),
+			memBytes: alloc.Memory().Value(),
+			pods:     alloc.Pods().Value(),
+		}
+		byName[n.Name] = nc
+		c.nodes = append(c.nodes, nc)
+	}
+	for i := range podList.Items {
+		p := &podList.Items[i]
+		nc, ok := byName[p.Spec.NodeName]
+		if !ok || p.Status.Phase == corev1.PodSucceeded || p.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		cpu, mem := podRequests(&p.Spec)
+		nc.cpuMilli -= cpu
+		nc.memBytes -= mem
+		nc.pods--
+	}
+	return c, nil
+}
+
+// Reserve reports whether spec fits on some node and, if so, deducts its
+// requests from that node so later calls in the same pass see less room.
+// It returns "" when the pod fits, or why it doesn't.
+func (c *Capacity) Reserve(spec AgentPodSpec) string {
+	var cpu, mem int64
+	if spec.Resources != nil {
+		cpu = spec.Resources.Requests.Cpu().MilliValue()
+		mem = spec.Resources.Requests.Memory().Value()
+	}
+
+	misses := make(map[string]int)
+	candidates := 0
+	for _, n := range c.nodes {
+		if !c.clusterAllowed(spec, n.cluster) {
+			continue
+		}
+		candidates++
+		switch {
+		case !matchesNodeSelector(n.labels, spec.NodeSelector):
+			misses["node selector mismatch"]++
+		case !matchesRequiredAffinity(n.labels, spec.Affinity):
+			misses["node affinity mismatch"]++
+		case !toleratesTaints(n.taints, spec.Tolerations):
+			misses["untolerated taint"]++
+		case n.pods < 1:
+			misses["too many pods"]++
+		case n.cpuMilli < cpu:
+			misses["insufficient cpu"]++
+		case n.memBytes < mem:
+			misses["insufficient memory"]++
+		default:
+			n.cpuMilli -= cpu
+			n.memBytes -= mem
+			n.pods--
+			return ""
+		}
+	}
+	if candidates == 0 {
+		return "no schedulable nodes"
+	}
+	reasons := make([]string, 0, len(misses))
+	for r, count := range misses {
+		reasons = append(reasons, fmt.Sprintf("%d %s", count, r))
+	}
+	sort.Strings(reasons)
+	return fmt.Sprintf("0/%d nodes fit cpu=%s memory=%s: %s", candidates,
+		resource.NewMilliQuantity(cpu, resource.DecimalSI), resource.NewQuantity(mem, resource.BinarySI),
+		strings.Join(reasons, ", "))
+}
+
+func (c *Capacity) clusterAllowed(spec AgentPodSpec, cluster string) bool {
+	if spec.Cluster != "" {
+		return cluster == spec.Cluster
+	}
+	return c.eligible == nil || c.eligible[cluster]
+}
+
+// mergeCapacity combines per-cluster snapshots, tagging each node with its
+// cluster. Unpinned specs are limited to eligible clusters (nil = any).
+func mergeCapacity(snapshots map[string]*Capacity, eligible map[string]bool) *Capacity {
+	merged := &Capacity{eligible: eligible}
+	for cluster, s := range snapshots {
+		for _, n := range s.nodes {
+			n.cluster = cluster
+			merged.nodes = append(merged.nodes, n)
+		}
+	}
+	return merged
+}
+
+func nodeReady(n *corev1.Node) bool {
+	for _, c := range n.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podRequests returns the effective CPU (millicores) and memory (bytes) a pod
+// requests: the larger of its containers' sum and its largest init container.
+func podRequests(spec *corev1.PodSpec) (cpu, mem int64) {
+	for _, c := range spec.Containers {
+		cpu += c.Resources.Requests.Cpu().MilliValue()
+		mem += c.Resources.Requests.Memory().Value()
+	}
+	for _, c := range spec.InitContainers {
+		cpu = max(cpu, c.Resources.Requests.Cpu().MilliValue())
+		mem = max(mem, c.Resources.Requests.Memory().Value())
+	}
+	return cpu, mem
+}
+
+func matchesNodeSelector(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesRequiredAffinity evaluates required node affinity label
+// expressions. Terms are ORed; expressions within a term are ANDed.
+func matchesRequiredAffinity(labels map[string]string, affinity *corev1.Affinity) bool {
+	if affinity == nil || affinity.NodeAffinity == nil ||
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) == 0 {
+		return true
+	}
+	for _, term := range terms {
+		if matchesTerm(labels, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesTerm(labels map[string]string, term corev1.NodeSelectorTerm) bool {
+	for _, expr := range term.MatchExpressions {
+		v, has := labels[expr.Key]
+		switch expr.Operator {
+		case corev1.NodeSelectorOpIn:
+			if !has || !slices.Contains(expr.Values, v) {
+				return false
+			}
+		case corev1.NodeSelectorOpNotIn:
+			if has && slices.Contains(expr.Values, v) {
+				return false
+			}
+		case corev1.NodeSelectorOpExists:
+			if !has {
+				return false
+			}
+		case corev1.NodeSelectorOpDoesNotExist:
+			if has {
+				return false
+			}
+		}
+		// Gt/Lt are not used by gasboat pods; treat them as satisfied.
+	}
+	return true
+}
+
+func toleratesTaints(taints []corev1.Taint, tolerations []corev1.Toleration) bool {
+	for i := range taints {
+		t := &taints[i]
+		if t.Effect == corev1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(t) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
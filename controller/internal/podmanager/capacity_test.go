@@ -0,0 +1,131 @@
+package podmanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testNode(name, cpu, mem string, labels map[string]string, taints ...corev1.Taint) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(mem),
+				corev1.ResourcePods:   resource.MustParse("110"),
+			},
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func runningPod(name, node, cpu, mem string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "other"},
+		Spec: corev1.PodSpec{
+			NodeName: node,
+			Containers: []corev1.Container{{Name: "c", Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(mem),
+				},
+			}}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func requesting(cpu, mem string) AgentPodSpec {
+	spec := agentSpec("a")
+	spec.Resources = &corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(mem),
+	}}
+	return spec
+}
+
+func TestCapacity_ReservesUntilFull(t *testing.T) {
+	amd64 := map[string]string{"kubernetes.io/arch": "amd64"}
+	client := fake.NewSimpleClientset(
+		testNode("n1", "4", "8Gi", amd64),
+		runningPod("busy", "n1", "1", "2Gi"),
+	)
+	capacity, err := New(client, testLogger()).Capacity(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := requesting("2", "1Gi")
+	spec.NodeSelector = amd64
+	if reason := capacity.Reserve(spec); reason != "" {
+		t.Fatalf("first pod should fit: %s", reason)
+	}
+	// 1 CPU left after the running pod and the first reservation.
+	reason := capacity.Reserve(spec)
+	if !strings.Contains(reason, "1 insufficient cpu") {
+		t.Errorf("expected insufficient cpu, got %q", reason)
+	}
+}
+
+func TestCapacity_HonorsSelectorsTaintsAndReadiness(t *testing.T) {
+	spotTaint := corev1.Taint{Key: "spot", Value: "true", Effect: corev1.TaintEffectNoSchedule}
+	cordoned := testNode("cordoned", "8", "16Gi", nil)
+	cordoned.Spec.Unschedulable = true
+	client := fake.NewSimpleClientset(
+		testNode("spot", "8", "16Gi", map[string]string{"capacity": "spot"}, spotTaint),
+		testNode("arm", "8", "16Gi", map[string]string{"kubernetes.io/arch": "arm64"}),
+		cordoned,
+	)
+	capacity, err := New(client, testLogger()).Capacity(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec := requesting("1", "1Gi")
+	spec.NodeSelector = map[string]string{"kubernetes.io/arch": "amd64"}
+	if reason := capacity.Reserve(spec); !strings.HasPrefix(reason, "0/2 nodes fit") {
+		t.Errorf("amd64 pod should not fit (cordoned node excluded), got %q", reason)
+	}
+
+	spec.NodeSelector = map[string]string{"capacity": "spot"}
+	if reason := capacity.Reserve(spec); !strings.Contains(reason, "untolerated taint") {
+		t.Errorf("expected untolerated taint, got %q", reason)
+	}
+	spec.Tolerations = []corev1.Toleration{{Key: "spot", Operator: corev1.TolerationOpExists}}
+	if reason := capacity.Reserve(spec); reason != "" {
+		t.Errorf("tolerating pod should fit: %s", reason)
+	}
+}
+
+func TestMultiCluster_CapacityRespectsPlacement(t *testing.T) {
+	home := fake.NewSimpleClientset(testNode("h1", "1", "1Gi", nil))
+	burst := fake.NewSimpleClientset(testNode("b1", "16", "64Gi", nil))
+	m, err := NewMultiCluster([]Cluster{
+		{Name: "home", Manager: New(home, testLogger())},
+		{Name: "burst", Manager: New(burst, testLogger())},
+	}, PlaceByProject, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	capacity, err := m.Capacity(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unpinned pods only go home under project placement.
+	spec := requesting("4", "4Gi")
+	if reason := capacity.Reserve(spec); reason == "" {
+		t.Error("unpinned pod should not fit on the home cluster")
+	}
+	spec.Cluster = "burst"
+	if reason := capacity.Reserve(spec); reason != "" {
+		t.Errorf("pod pinned to burst should fit: %s", reason)
+	}
+}
@@ -19,6 +19,15 @@ type PodDefaults struct {
 	SecretEnv          []SecretEnvSource
 	ConfigMapName      string
 	WorkspaceStorage   *WorkspaceStorageSpec
+
+	// TerminationGracePeriodSeconds is how long agents get to shut down
+	// (AgentPodSpec.TerminationGracePeriodSeconds).
+	TerminationGracePeriodSeconds *int64
+
+	// Name resolution (AgentPodSpec.HostAliases, DNSPolicy, DNSConfig).
+	HostAliases []corev1.HostAlias
+	DNSPolicy   corev1.DNSPolicy
+	DNSConfig   *corev1.PodDNSConfig
 }
 
 // ApplyDefaults applies PodDefaults to an AgentPodSpec, filling in
@@ -52,6 +61,18 @@ func ApplyDefaults(spec *AgentPodSpec, defaults *PodDefaults) {
 	if spec.WorkspaceStorage == nil && defaults.WorkspaceStorage != nil {
 		spec.WorkspaceStorage = defaults.WorkspaceStorage
 	}
+	if spec.TerminationGracePeriodSeconds == nil && defaults.TerminationGracePeriodSeconds != nil {
+		spec.TerminationGracePeriodSeconds = defaults.TerminationGracePeriodSeconds
+	}
+	if len(spec.HostAliases) == 0 && len(defaults.HostAliases) > 0 {
+		spec.HostAliases = defaults.HostAliases
+	}
+	if spec.DNSPolicy == "" && defaults.DNSPolicy != "" {
+		spec.DNSPolicy = defaults.DNSPolicy
+	}
+	if spec.DNSConfig == nil && defaults.DNSConfig != nil {
+		spec.DNSConfig = defaults.DNSConfig
+	}
 	// Merge env maps (spec values take precedence over defaults).
 	if len(defaults.Env) > 0 {
 		if spec.Env == nil {
@@ -91,9 +112,10 @@ func DefaultPodDefaults(mode string) *PodDefaults {
 				corev1.ResourceMemory: resource.MustParse(DefaultMemoryLimit),
 			},
 		},
-		// Agent image is x86-only; pin to amd64 nodes.
+		// COOP_IMAGE is built for amd64; agents assigned another
+		// architecture get their own selector and image.
 		NodeSelector: map[string]string{
-			"kubernetes.io/arch": "amd64",
+			LabelArch: "amd64",
 		},
 		// Prefer on-demand, compute-optimized nodes; exclude per-MR nodes.
 		Affinity: &corev1.Affinity{
@@ -132,6 +154,9 @@ func DefaultPodDefaults(mode string) *PodDefaults {
 		},
 	}
 
+	gracePeriod := DefaultTerminationGracePeriod
+	defaults.TerminationGracePeriodSeconds = &gracePeriod
+
 	switch mode {
 	case "crew":
 		// Crew pods get persistent workspace storage.
@@ -0,0 +1,529 @@
+package podmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Labels the Docker backend keeps a pod's identity in, next to the pod's
+// own labels.
+const (
+	dockerLabelPod         = "gasboat.io/pod"
+	dockerLabelNamespace   = "gasboat.io/namespace"
+	dockerLabelAnnotations = "gasboat.io/annotations" // pod annotations as JSON
+)
+
+// dockerAPIVersion is the Docker Engine API version requested. Podman's
+// Docker-compatible API serves it too.
+const dockerAPIVersion = "v1.41"
+
+// DockerManager implements Manager with containers on a Docker (or Podman)
+// engine, for installations without a Kubernetes cluster. Each agent pod
+// is one container running the agent container of the pod K8sManager
+// would create, so agents see the same image, args and env.
+//
+// What has no Docker counterpart is dropped: init containers (repos are
+// not pre-cloned), ConfigMap mounts, subPath mounts, node placement and
+// ServiceAccounts. Workspace PVCs become named volumes, kept when the
+// container is removed. Secrets are read from files under the secrets
+// directory, one per key: {dir}/{secret}/{key}.
+type DockerManager struct {
+	client     *http.Client
+	baseURL    string
+	network    string
+	secretsDir string
+	pods       *K8sManager // builds the pod each container is made from
+	logger     *slog.Logger
+}
+
+// NewDocker creates a pod manager backed by the Docker engine at host
+// ("unix:///var/run/docker.sock" or "tcp://host:2375"). Containers join
+// network when it is set; secretsDir may be empty if agents use no secrets.
+func NewDocker(host, network, secretsDir string, logger *slog.Logger) (*DockerManager, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("parsing docker host %q: %w", host, err)
+	}
+	m := &DockerManager{
+		network:    network,
+		secretsDir: secretsDir,
+		pods:       &K8sManager{logger: logger},
+		logger:     logger,
+	}
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		m.baseURL = "http://docker"
+		m.client = &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}}
+	case "tcp", "http":
+		m.baseURL = "http://" + u.Host
+		m.client = &http.Client{}
+	default:
+		return nil, fmt.Errorf("docker host %q: unsupported scheme %q", host, u.Scheme)
+	}
+	return m, nil
+}
+
+// dockerContainer is the subset of a container inspect response used.
+type dockerContainer struct {
+	ID      string `json:"Id"`
+	Created string `json:"Created"`
+	Config  struct {
+		Image  string            `json:"Image"`
+		Labels map[string]string `json:"Labels"`
+	} `json:"Config"`
+	State struct {
+		Status     string `json:"Status"` // created, running, paused, restarting, removing, exited, dead
+		ExitCode   int    `json:"ExitCode"`
+		Error      string `json:"Error"`
+		StartedAt  string `json:"StartedAt"`
+		FinishedAt string `json:"FinishedAt"`
+		Health     *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+	RestartCount    int `json:"RestartCount"`
+	NetworkSettings struct {
+		IPAddress string `json:"IPAddress"`
+		Networks  map[string]struct {
+			IPAddress string `json:"IPAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+// dockerStatusError is a non-2xx response from the engine.
+type dockerStatusError struct {
+	code    int
+	message string
+}
+
+func (e *dockerStatusError) Error() string {
+	return fmt.Sprintf("docker: %d %s", e.code, e.message)
+}
+
+func dockerStatus(err error) int {
+	if se, ok := err.(*dockerStatusError); ok {
+		return se.code
+	}
+	return 0
+}
+
+// CreateAgentPod creates and starts the container for spec, pulling its
+// image if the engine doesn't have it.
+func (m *DockerManager) CreateAgentPod(ctx context.Context, spec AgentPodSpec) error {
+	pod := m.pods.buildPod(spec)
+	body, err := m.containerConfig(pod)
+	if err != nil {
+		return fmt.Errorf("building container for pod %s: %w", pod.Name, err)
+	}
+	m.logger.Info("creating agent container",
+		"pod", pod.Name, "project", spec.Project, "role", spec.Role, "agent", spec.AgentName)
+
+	name := dockerName(pod.Name, pod.Namespace)
+	query := url.Values{"name": {name}}
+	err = m.do(ctx, http.MethodPost, "/containers/create", query, body, nil)
+	if dockerStatus(err) == http.StatusNotFound {
+		if err := m.pull(ctx, spec.Image); err != nil {
+			return err
+		}
+		err = m.do(ctx, http.MethodPost, "/containers/create", query, body, nil)
+	}
+	if dockerStatus(err) == http.StatusConflict {
+		return apierrors.NewAlreadyExists(corev1.Resource("pods"), pod.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("creating container %s: %w", name, err)
+	}
+	if err := m.do(ctx, http.MethodPost, "/containers/"+name+"/start", nil, nil, nil); err != nil {
+		return fmt.Errorf("starting container %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteAgentPod stops and removes a pod's container. Its workspace volume
+// is kept.
+func (m *DockerManager) DeleteAgentPod(ctx context.Context, name, namespace string) error {
+	m.logger.Info("deleting agent container", "pod", name, "namespace", namespace)
+	container := dockerName(name, namespace)
+	stop := url.Values{"t": {"30"}}
+	if err := m.do(ctx, http.MethodPost, "/containers/"+container+"/stop", stop, nil, nil); err != nil &&
+		dockerStatus(err) != http.StatusNotModified && dockerStatus(err) != http.StatusNotFound {
+		m.logger.Warn("stopping agent container failed, removing it anyway", "pod", name, "error", err)
+	}
+	err := m.do(ctx, http.MethodDelete, "/containers/"+container, url.Values{"force": {"1"}}, nil, nil)
+	if dockerStatus(err) == http.StatusNotFound {
+		return apierrors.NewNotFound(corev1.Resource("pods"), name)
+	}
+	return err
+}
+
+// ListAgentPods lists the pods of containers matching the given labels.
+func (m *DockerManager) ListAgentPods(ctx context.Context, namespace string, labelSelector map[string]string) ([]corev1.Pod, error) {
+	filters := []string{dockerLabelNamespace + "=" + namespace}
+	for k, v := range labelSelector {
+		filters = append(filters, k+"="+v)
+	}
+	raw, _ := json.Marshal(map[string][]string{"label": filters})
+	var list []struct {
+		ID string `json:"Id"`
+	}
+	if err := m.do(ctx, http.MethodGet, "/containers/json", url.Values{"all": {"1"}, "filters": {string(raw)}}, nil, &list); err != nil {
+		return nil, fmt.Errorf("listing containers: %w", err)
+	}
+	pods := make([]corev1.Pod, 0, len(list))
+	for _, c := range list {
+		pod, err := m.inspect(ctx, c.ID)
+		if apierrors.IsNotFound(err) {
+			continue // removed since listed
+		}
+		if err != nil {
+			return nil, err
+		}
+		pods = append(pods, *pod)
+	}
+	return pods, nil
+}
+
+// GetAgentPod gets the pod of a single container by pod name.
+func (m *DockerManager) GetAgentPod(ctx context.Context, name, namespace string) (*corev1.Pod, error) {
+	return m.inspect(ctx, dockerName(name, namespace))
+}
+
+func (m *DockerManager) inspect(ctx context.Context, container string) (*corev1.Pod, error) {
+	var c dockerContainer
+	err := m.do(ctx, http.MethodGet, "/containers/"+container+"/json", nil, nil, &c)
+	if dockerStatus(err) == http.StatusNotFound {
+		return nil, apierrors.NewNotFound(corev1.Resource("pods"), container)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("inspecting container %s: %w", container, err)
+	}
+	return m.podFromContainer(&c), nil
+}
+
+// pull pulls image, waiting for the pull to finish.
+func (m *DockerManager) pull(ctx context.Context, image string) error {
+	m.logger.Info("pulling agent image", "image", image)
+	req, err := m.request(ctx, http.MethodPost, "/images/create", url.Values{"fromImage": {image}}, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pulling image %s: %w", image, err)
+	}
+	defer resp.Body.Close()
+	// Progress is streamed until the pull completes; errors are reported
+	// in the stream.
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("pulling image %s: %w", image, err)
+		}
+		if msg.Error != "" {
+			return fmt.Errorf("pulling image %s: %s", image, msg.Error)
+		}
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("pulling image %s: %w", image, &dockerStatusError{code: resp.StatusCode, message: resp.Status})
+	}
+	return nil
+}
+
+// containerConfig translates the agent container of pod into a container
+// create request.
+func (m *DockerManager) containerConfig(pod *corev1.Pod) (map[string]any, error) {
+	agent := pod.Spec.Containers[0]
+
+	var env []string
+	for _, e := range agent.Env {
+		switch {
+		case e.ValueFrom == nil:
+			env = append(env, e.Name+"="+e.Value)
+		case e.ValueFrom.SecretKeyRef != nil:
+			ref := e.ValueFrom.SecretKeyRef
+			value, err := m.secret(ref.Name, ref.Key)
+			if err != nil {
+				m.logger.Warn("agent secret env not set", "pod", pod.Name, "env", e.Name, "error", err)
+				continue
+			}
+			env = append(env, e.Name+"="+value)
+		}
+		// Downward API fields (POD_IP) have no Docker counterpart.
+	}
+
+	volumes := make(map[string]corev1.Volume, len(pod.Spec.Volumes))
+	for _, v := range pod.Spec.Volumes {
+		volumes[v.Name] = v
+	}
+	var binds []string
+	for _, vm := range agent.VolumeMounts {
+		v := volumes[vm.Name]
+		if vm.SubPath != "" {
+			continue
+		}
+		switch {
+		case v.PersistentVolumeClaim != nil:
+			binds = append(binds, v.PersistentVolumeClaim.ClaimName+":"+vm.MountPath)
+		case v.Secret != nil && m.secretsDir != "":
+			binds = append(binds, filepath.Join(m.secretsDir, v.Secret.SecretName)+":"+vm.MountPath+":ro")
+		}
+	}
+
+	labels := make(map[string]string, len(pod.Labels)+3)
+	for k, v := range pod.Labels {
+		labels[k] = v
+	}
+	labels[dockerLabelPod] = pod.Name
+	labels[dockerLabelNamespace] = pod.Namespace
+	annotations, err := json.Marshal(pod.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	labels[dockerLabelAnnotations] = string(annotations)
+
+	exposed := make(map[string]struct{}, len(agent.Ports))
+	for _, p := range agent.Ports {
+		exposed[fmt.Sprintf("%d/tcp", p.ContainerPort)] = struct{}{}
+	}
+
+	host := map[string]any{
+		"Binds":         binds,
+		"RestartPolicy": map[string]string{"Name": dockerRestartPolicy(pod.Spec.RestartPolicy)},
+	}
+	if cpu, ok := agent.Resources.Limits[corev1.ResourceCPU]; ok {
+		host["NanoCpus"] = cpu.MilliValue() * 1_000_000
+	}
+	if mem, ok := agent.Resources.Limits[corev1.ResourceMemory]; ok {
+		host["Memory"] = mem.Value()
+	}
+	if m.network != "" {
+		host["NetworkMode"] = m.network
+	}
+	var extraHosts []string
+	for _, a := range pod.Spec.HostAliases {
+		for _, h := range a.Hostnames {
+			extraHosts = append(extraHosts, h+":"+a.IP)
+		}
+	}
+	if len(extraHosts) > 0 {
+		host["ExtraHosts"] = extraHosts
+	}
+	if dns := pod.Spec.DNSConfig; dns != nil {
+		host["Dns"] = dns.Nameservers
+		host["DnsSearch"] = dns.Searches
+		var opts []string
+		for _, o := range dns.Options {
+			if o.Value != nil {
+				opts = append(opts, o.Name+":"+*o.Value)
+			} else {
+				opts = append(opts, o.Name)
+			}
+		}
+		host["DnsOptions"] = opts
+	}
+
+	cfg := map[string]any{
+		"Image":        agent.Image,
+		"Cmd":          agent.Args,
+		"Env":          env,
+		"Labels":       labels,
+		"ExposedPorts": exposed,
+		"HostConfig":   host,
+	}
+	if sc := pod.Spec.SecurityContext; sc != nil && sc.RunAsUser != nil && sc.RunAsGroup != nil {
+		cfg["User"] = fmt.Sprintf("%d:%d", *sc.RunAsUser, *sc.RunAsGroup)
+	}
+	return cfg, nil
+}
+
+// secret reads a secret key from the secrets directory.
+func (m *DockerManager) secret(name, key string) (string, error) {
+	if m.secretsDir == "" {
+		return "", fmt.Errorf("secret %s: no secrets directory configured", name)
+	}
+	data, err := os.ReadFile(filepath.Join(m.secretsDir, name, key))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\n"), nil
+}
+
+// podFromContainer describes a container as the pod it was created from,
+// so the reconciler and status reporter can treat it like any other pod.
+func (m *DockerManager) podFromContainer(c *dockerContainer) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      c.Config.Labels[dockerLabelPod],
+			Namespace: c.Config.Labels[dockerLabelNamespace],
+			Labels:    make(map[string]string, len(c.Config.Labels)),
+			UID:       types.UID(c.ID),
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  ContainerName,
+			Image: c.Config.Image,
+			Ports: []corev1.ContainerPort{
+				{Name: "api", ContainerPort: CoopDefaultPort},
+				{Name: "health", ContainerPort: CoopDefaultHealthPort},
+			},
+		}}},
+	}
+	for k, v := range c.Config.Labels {
+		switch k {
+		case dockerLabelPod, dockerLabelNamespace:
+		case dockerLabelAnnotations:
+			_ = json.Unmarshal([]byte(v), &pod.Annotations)
+		default:
+			pod.Labels[k] = v
+		}
+	}
+	if created, err := time.Parse(time.RFC3339Nano, c.Created); err == nil {
+		pod.CreationTimestamp = metav1.NewTime(created)
+	}
+
+	status := corev1.ContainerStatus{
+		Name:         ContainerName,
+		Image:        c.Config.Image,
+		RestartCount: int32(c.RestartCount),
+	}
+	started, _ := time.Parse(time.RFC3339Nano, c.State.StartedAt)
+	switch c.State.Status {
+	case "created":
+		pod.Status.Phase = corev1.PodPending
+		status.State.Waiting = &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}
+	case "exited", "dead":
+		pod.Status.Phase = corev1.PodSucceeded
+		reason := "Completed"
+		if c.State.ExitCode != 0 || c.State.Status == "dead" {
+			pod.Status.Phase = corev1.PodFailed
+			reason = "Error"
+		}
+		finished, _ := time.Parse(time.RFC3339Nano, c.State.FinishedAt)
+		status.State.Terminated = &corev1.ContainerStateTerminated{
+			ExitCode:   int32(c.State.ExitCode),
+			Reason:     reason,
+			Message:    c.State.Error,
+			StartedAt:  metav1.NewTime(started),
+			FinishedAt: metav1.NewTime(finished),
+		}
+	default: // running, paused, restarting, removing
+		pod.Status.Phase = corev1.PodRunning
+		status.State.Running = &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(started)}
+		status.Ready = c.State.Status == "running" && (c.State.Health == nil || c.State.Health.Status == "healthy")
+		if c.State.Status == "removing" {
+			now := metav1.Now()
+			pod.DeletionTimestamp = &now
+		}
+	}
+	pod.Status.Message = c.State.Error
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{status}
+	ready := corev1.ConditionFalse
+	if status.Ready {
+		ready = corev1.ConditionTrue
+	}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}
+
+	// The address on the configured network, else on the default bridge,
+	// else on any network the container joined.
+	pod.Status.PodIP = c.NetworkSettings.Networks[m.network].IPAddress
+	if pod.Status.PodIP == "" {
+		pod.Status.PodIP = c.NetworkSettings.IPAddress
+	}
+	for _, n := range c.NetworkSettings.Networks {
+		if pod.Status.PodIP == "" {
+			pod.Status.PodIP = n.IPAddress
+		}
+	}
+	return pod
+}
+
+func (m *DockerManager) request(ctx context.Context, method, path string, query url.Values, body any) (*http.Request, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	u := m.baseURL + "/" + dockerAPIVersion + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// do sends a request to the engine and decodes its JSON response into out.
+// Non-2xx responses are returned as a *dockerStatusError.
+func (m *DockerManager) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	req, err := m.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&msg)
+		return &dockerStatusError{code: resp.StatusCode, message: msg.Message}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// dockerName is the container name of a pod. Pods of different namespaces
+// may share an engine.
+func dockerName(pod, namespace string) string {
+	return namespace + "_" + pod
+}
+
+func dockerRestartPolicy(p corev1.RestartPolicy) string {
+	switch p {
+	case corev1.RestartPolicyAlways:
+		return "unless-stopped"
+	case corev1.RestartPolicyOnFailure:
+		return "on-failure"
+	default:
+		return "no"
+	}
+}
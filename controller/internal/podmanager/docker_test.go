@@ -0,0 +1,276 @@
+package podmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// fakeDocker is a minimal Docker Engine API keeping containers in memory.
+type fakeDocker struct {
+	mu         sync.Mutex
+	images     map[string]bool
+	containers map[string]*fakeContainer
+	pulls      []string
+}
+
+type fakeContainer struct {
+	create  map[string]any
+	state   string
+	exit    int
+	started bool
+}
+
+func newFakeDocker(t *testing.T, images ...string) (*fakeDocker, *DockerManager) {
+	t.Helper()
+	f := &fakeDocker{images: make(map[string]bool), containers: make(map[string]*fakeContainer)}
+	for _, img := range images {
+		f.images[img] = true
+	}
+	srv := httptest.NewServer(http.StripPrefix("/"+dockerAPIVersion, f))
+	t.Cleanup(srv.Close)
+	m, err := NewDocker("tcp://"+strings.TrimPrefix(srv.URL, "http://"), "gasboat", "", testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, m
+}
+
+func (f *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	notFound := func() {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "no such container"})
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/images/create":
+		image := r.URL.Query().Get("fromImage")
+		f.pulls = append(f.pulls, image)
+		f.images[image] = true
+		_, _ = w.Write([]byte(`{"status":"Pulling"}` + "\n" + `{"status":"Done"}` + "\n"))
+	case r.Method == http.MethodPost && r.URL.Path == "/containers/create":
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if !f.images[body["Image"].(string)] {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"message": "No such image"})
+			return
+		}
+		name := r.URL.Query().Get("name")
+		if _, ok := f.containers[name]; ok {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.containers[name] = &fakeContainer{create: body, state: "created"}
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"Id": name})
+	case r.Method == http.MethodGet && r.URL.Path == "/containers/json":
+		var filters map[string][]string
+		_ = json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+		var list []map[string]string
+		for name, c := range f.containers {
+			labels := c.create["Labels"].(map[string]any)
+			match := true
+			for _, kv := range filters["label"] {
+				k, v, _ := strings.Cut(kv, "=")
+				if labels[k] != v {
+					match = false
+				}
+			}
+			if match {
+				list = append(list, map[string]string{"Id": name})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case len(parts) >= 2 && parts[0] == "containers":
+		c, ok := f.containers[parts[1]]
+		if !ok {
+			notFound()
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "start":
+			c.state, c.started = "running", true
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "stop":
+			c.state = "exited"
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodDelete:
+			delete(f.containers, parts[1])
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "json":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"Id":      parts[1],
+				"Created": "2026-01-02T03:04:05.000000006Z",
+				"Config":  map[string]any{"Image": c.create["Image"], "Labels": c.create["Labels"]},
+				"State":   map[string]any{"Status": c.state, "ExitCode": c.exit, "StartedAt": "2026-01-02T03:04:06Z"},
+				"NetworkSettings": map[string]any{
+					"Networks": map[string]any{"gasboat": map[string]string{"IPAddress": "172.18.0.5"}},
+				},
+			})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func dockerSpec() AgentPodSpec {
+	return AgentPodSpec{
+		Project: "gasboat", Mode: "crew", Role: "crew", AgentName: "k8s",
+		BeadID: "bd-k8s", Image: "ghcr.io/org/agent:1", Namespace: "gasboat",
+		Env:              map[string]string{"BEADS_HTTP_ADDR": "daemon:8080"},
+		WorkspaceStorage: &WorkspaceStorageSpec{Size: "10Gi"},
+		ConfigHash:       "abc",
+	}
+}
+
+func TestDockerManager_CreatePullsAndStarts(t *testing.T) {
+	f, m := newFakeDocker(t)
+	ctx := context.Background()
+	if err := m.CreateAgentPod(ctx, dockerSpec()); err != nil {
+		t.Fatalf("CreateAgentPod: %v", err)
+	}
+	if !slices.Equal(f.pulls, []string{"ghcr.io/org/agent:1"}) {
+		t.Errorf("pulls = %v", f.pulls)
+	}
+	c := f.containers["gasboat_crew-gasboat-crew-k8s"]
+	if c == nil || !c.started {
+		t.Fatalf("container not created and started: %+v", f.containers)
+	}
+	env := c.create["Env"].([]any)
+	for _, want := range []string{"BOAT_AGENT=k8s", "BEADS_HTTP_ADDR=daemon:8080", "BOAT_SESSION_RESUME=1"} {
+		if !slices.Contains(env, any(want)) {
+			t.Errorf("env %v lacks %s", env, want)
+		}
+	}
+	host := c.create["HostConfig"].(map[string]any)
+	if binds := host["Binds"].([]any); !slices.Contains(binds, any("crew-gasboat-crew-k8s-workspace:"+MountWorkspace)) {
+		t.Errorf("binds = %v, want the workspace volume", binds)
+	}
+	if host["NetworkMode"] != "gasboat" || host["RestartPolicy"].(map[string]any)["Name"] != "unless-stopped" {
+		t.Errorf("host config = %v", host)
+	}
+
+	if err := m.CreateAgentPod(ctx, dockerSpec()); !apierrors.IsAlreadyExists(err) {
+		t.Errorf("second create: err = %v, want AlreadyExists", err)
+	}
+}
+
+func TestDockerManager_CreateWithDNS(t *testing.T) {
+	f, m := newFakeDocker(t)
+	spec := dockerSpec()
+	ndots := "2"
+	spec.HostAliases = []corev1.HostAlias{{IP: "10.0.0.5", Hostnames: []string{"git.corp", "api.corp"}}}
+	spec.DNSConfig = &corev1.PodDNSConfig{
+		Nameservers: []string{"10.0.0.2"},
+		Searches:    []string{"corp"},
+		Options:     []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}, {Name: "rotate"}},
+	}
+	if err := m.CreateAgentPod(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+	host := f.containers["gasboat_crew-gasboat-crew-k8s"].create["HostConfig"].(map[string]any)
+	if got := host["ExtraHosts"].([]any); !slices.Equal(got, []any{"git.corp:10.0.0.5", "api.corp:10.0.0.5"}) {
+		t.Errorf("extra hosts = %v", got)
+	}
+	if got := host["Dns"].([]any); !slices.Equal(got, []any{"10.0.0.2"}) {
+		t.Errorf("dns = %v", got)
+	}
+	if got := host["DnsOptions"].([]any); !slices.Equal(got, []any{"ndots:2", "rotate"}) {
+		t.Errorf("dns options = %v", got)
+	}
+}
+
+func TestDockerManager_ContainersAsPods(t *testing.T) {
+	f, m := newFakeDocker(t, "ghcr.io/org/agent:1")
+	ctx := context.Background()
+	if err := m.CreateAgentPod(ctx, dockerSpec()); err != nil {
+		t.Fatal(err)
+	}
+
+	pods, err := m.ListAgentPods(ctx, "gasboat", map[string]string{LabelApp: LabelAppValue})
+	if err != nil || len(pods) != 1 {
+		t.Fatalf("ListAgentPods = %v, %v", pods, err)
+	}
+	pod := pods[0]
+	if pod.Name != "crew-gasboat-crew-k8s" || pod.Namespace != "gasboat" || AgentPodName(&pod) != "crew-gasboat-crew-k8s" {
+		t.Errorf("pod identity = %s/%s", pod.Namespace, pod.Name)
+	}
+	if pod.Labels[LabelAgent] != "k8s" || pod.Labels[dockerLabelPod] != "" {
+		t.Errorf("labels = %v", pod.Labels)
+	}
+	if pod.Annotations[AnnotationBeadID] != "bd-k8s" || pod.Annotations[AnnotationConfigHash] != "abc" {
+		t.Errorf("annotations = %v", pod.Annotations)
+	}
+	if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP != "172.18.0.5" || !pod.Status.ContainerStatuses[0].Ready {
+		t.Errorf("status = %+v", pod.Status)
+	}
+	if pod.Spec.Containers[0].Name != ContainerName || pod.Spec.Containers[0].Image != "ghcr.io/org/agent:1" {
+		t.Errorf("containers = %+v", pod.Spec.Containers)
+	}
+
+	if pods, _ := m.ListAgentPods(ctx, "other", nil); len(pods) != 0 {
+		t.Errorf("other namespace lists %d pods", len(pods))
+	}
+
+	f.containers["gasboat_crew-gasboat-crew-k8s"].state = "exited"
+	f.containers["gasboat_crew-gasboat-crew-k8s"].exit = 1
+	got, err := m.GetAgentPod(ctx, "crew-gasboat-crew-k8s", "gasboat")
+	if err != nil || got.Status.Phase != corev1.PodFailed {
+		t.Fatalf("GetAgentPod = %+v, %v; want Failed", got, err)
+	}
+
+	if err := m.DeleteAgentPod(ctx, "crew-gasboat-crew-k8s", "gasboat"); err != nil {
+		t.Fatalf("DeleteAgentPod: %v", err)
+	}
+	if _, err := m.GetAgentPod(ctx, "crew-gasboat-crew-k8s", "gasboat"); !apierrors.IsNotFound(err) {
+		t.Errorf("get after delete: err = %v, want NotFound", err)
+	}
+	if err := m.DeleteAgentPod(ctx, "crew-gasboat-crew-k8s", "gasboat"); !apierrors.IsNotFound(err) {
+		t.Errorf("second delete: err = %v, want NotFound", err)
+	}
+}
+
+func TestDockerManager_SecretsFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "git-creds"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "git-creds", "token"), []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, m := newFakeDocker(t, "ghcr.io/org/agent:1")
+	m.secretsDir = dir
+
+	spec := dockerSpec()
+	spec.SecretEnv = []SecretEnvSource{
+		{EnvName: "GIT_TOKEN", SecretName: "git-creds", SecretKey: "token"},
+		{EnvName: "MISSING", SecretName: "nope", SecretKey: "key"},
+	}
+	if err := m.CreateAgentPod(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+	env := f.containers["gasboat_crew-gasboat-crew-k8s"].create["Env"].([]any)
+	if !slices.Contains(env, any("GIT_TOKEN=s3cret")) {
+		t.Errorf("env %v lacks GIT_TOKEN", env)
+	}
+	for _, e := range env {
+		if strings.HasPrefix(e.(string), "MISSING=") || strings.HasPrefix(e.(string), "POD_IP=") {
+			t.Errorf("unexpected env %s", e)
+		}
+	}
+}
@@ -127,6 +127,9 @@ fi
 		)
 	}
 
+	// apk and git go through the project's egress proxy, if any.
+	initEnv = append(initEnv, proxyEnv(spec)...)
+
 	runAsRoot := int64(0)
 	runAsNonRoot := false
 	return &corev1.Container{
@@ -1,18 +1,24 @@
 // Package podmanager handles K8s pod CRUD for Gasboat agents.
 // It translates beads lifecycle decisions into pod create/delete operations.
 // The pod manager never makes lifecycle decisions — it executes them.
+//
+// Agents run as K8s pods (K8sManager) or, without a cluster, as Docker
+// containers described as pods (DockerManager).
 package podmanager
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 )
@@ -24,6 +30,11 @@ const (
 	LabelRole    = "gasboat.io/role"
 	LabelAgent   = "gasboat.io/agent"
 	LabelMode    = "gasboat.io/mode"
+	LabelCluster = "gasboat.io/cluster"
+	// LabelCapacity marks pods scheduled onto spot capacity (value "spot").
+	LabelCapacity = "gasboat.io/capacity"
+	// LabelArch is the well-known node label for CPU architecture.
+	LabelArch = "kubernetes.io/arch"
 
 	// AnnotationBeadID is the canonical bead ID for this pod. When set,
 	// the status reporter uses it instead of constructing an ID from labels.
@@ -31,6 +42,10 @@ const (
 	// {mode}-{project}-{role}-{agent} pattern.
 	AnnotationBeadID = "gasboat.io/bead-id"
 
+	// AnnotationConfigHash records the hash of the agent ConfigMap a pod was
+	// created with, so the reconciler can restart pods whose config changed.
+	AnnotationConfigHash = "gasboat.io/config-hash"
+
 	// LabelAppValue is the app label value for all gasboat pods.
 	LabelAppValue = "gasboat"
 
@@ -40,6 +55,10 @@ const (
 	DefaultMemoryRequest = "1Gi"
 	DefaultMemoryLimit   = "8Gi"
 
+	// DefaultTerminationGracePeriod is the termination grace period, in
+	// seconds, of agent pods whose role or project doesn't set one.
+	DefaultTerminationGracePeriod = int64(30)
+
 	// Volume names.
 	VolumeWorkspace   = "workspace"
 	VolumeTmp         = "tmp"
@@ -98,6 +117,35 @@ type AgentPodSpec struct {
 	Namespace string
 	Env       map[string]string
 
+	// Metadata is the agent bead's metadata. It isn't rendered into the
+	// pod; pod policies (internal/policy) select on it.
+	Metadata map[string]string
+
+	// ExtraLabels and ExtraAnnotations are added to the pod (and its
+	// workspace PVC) from the agent bead. The controller's own labels and
+	// annotations take precedence.
+	ExtraLabels      map[string]string
+	ExtraAnnotations map[string]string
+
+	// Cluster names the cluster to run the pod on (see MultiCluster). Empty
+	// leaves the choice to the placement policy.
+	Cluster string
+
+	// Spot marks the pod as running on spot capacity: it is labeled so that
+	// preemptions can be told apart from failures, and gets a longer
+	// termination grace period to checkpoint in.
+	Spot bool
+
+	// TerminationGracePeriodSeconds is how long the agent gets to shut down
+	// once its pod is deleted. Nil uses DefaultTerminationGracePeriod; spot
+	// pods get at least SpotTerminationGracePeriod.
+	TerminationGracePeriodSeconds *int64
+
+	// PreStop is a shell script run in the agent container when its pod
+	// is deleted, before the agent is stopped (see package prestop). Empty
+	// runs none.
+	PreStop string
+
 	// Resources sets compute requests/limits. If nil, defaults are used.
 	Resources *corev1.ResourceRequirements
 
@@ -109,6 +157,11 @@ type AgentPodSpec struct {
 	// Contains agent configuration (role, project, daemon connection, etc.).
 	ConfigMapName string
 
+	// ConfigHash identifies the rendered contents of ConfigMapName
+	// (AnnotationConfigHash). Empty for ConfigMaps the controller doesn't
+	// render.
+	ConfigHash string
+
 	// ServiceAccountName for the pod. Empty uses the namespace default.
 	ServiceAccountName string
 
@@ -121,6 +174,18 @@ type AgentPodSpec struct {
 	// Affinity rules for pod scheduling (e.g. prefer compute-optimized nodes).
 	Affinity *corev1.Affinity
 
+	// HostAliases are added to the pod's /etc/hosts.
+	HostAliases []corev1.HostAlias
+
+	// DNSPolicy and DNSConfig set the pod's name resolution. An empty
+	// policy leaves the cluster default (ClusterFirst).
+	DNSPolicy corev1.DNSPolicy
+	DNSConfig *corev1.PodDNSConfig
+
+	// Proxy routes the agent's and the init-clone container's outbound
+	// traffic through an HTTP proxy. Nil connects directly.
+	Proxy *ProxySpec
+
 	// WorkspaceStorage configures a PVC for persistent workspace.
 	// If nil, an EmptyDir is used.
 	WorkspaceStorage *WorkspaceStorageSpec
@@ -157,7 +222,8 @@ type AgentPodSpec struct {
 
 // WorkspaceStorageSpec configures a PVC-backed workspace volume.
 type WorkspaceStorageSpec struct {
-	// ClaimName is the PVC name. If empty, derived from pod name.
+	// ClaimName is the PVC name. If empty, the agent's workspace claim is
+	// found by bead ID or created (see WorkspaceClaimName).
 	ClaimName string
 
 	// Size is the requested storage (e.g., "10Gi").
@@ -172,15 +238,23 @@ func (s *AgentPodSpec) PodName() string {
 	return fmt.Sprintf("%s-%s-%s-%s", s.Mode, s.Project, s.Role, s.AgentName)
 }
 
-// Labels returns the standard label set for this agent pod.
+// Labels returns the label set for this agent pod: the standard labels on
+// top of ExtraLabels.
 func (s *AgentPodSpec) Labels() map[string]string {
-	return map[string]string{
-		LabelApp:     LabelAppValue,
-		LabelProject: s.Project,
-		LabelMode:    s.Mode,
-		LabelRole:    s.Role,
-		LabelAgent:   s.AgentName,
-	}
+	labels := make(map[string]string, len(s.ExtraLabels)+5)
+	maps.Copy(labels, s.ExtraLabels)
+	labels[LabelApp] = LabelAppValue
+	labels[LabelProject] = s.Project
+	labels[LabelMode] = s.Mode
+	labels[LabelRole] = s.Role
+	labels[LabelAgent] = s.AgentName
+	if s.Cluster != "" {
+		labels[LabelCluster] = s.Cluster
+	}
+	if s.Spot {
+		labels[LabelCapacity] = CapacitySpot
+	}
+	return labels
 }
 
 // Manager creates, deletes, and lists agent pods in K8s.
@@ -205,11 +279,16 @@ func New(client kubernetes.Interface, logger *slog.Logger) *K8sManager {
 // CreateAgentPod creates a pod for the given agent spec.
 // If the spec includes WorkspaceStorage, a PVC is created first (idempotent).
 func (m *K8sManager) CreateAgentPod(ctx context.Context, spec AgentPodSpec) error {
-	// Ensure PVC exists before creating the pod.
+	// Ensure PVC exists before creating the pod, reattaching the agent's
+	// existing workspace if it has one.
 	if spec.WorkspaceStorage != nil {
-		if err := m.ensurePVC(ctx, spec); err != nil {
+		claim, err := m.workspaceClaim(ctx, spec)
+		if err != nil {
 			return fmt.Errorf("ensuring workspace PVC: %w", err)
 		}
+		ws := *spec.WorkspaceStorage
+		ws.ClaimName = claim
+		spec.WorkspaceStorage = &ws
 	}
 
 	pod := m.buildPod(spec)
@@ -223,14 +302,10 @@ func (m *K8sManager) CreateAgentPod(ctx context.Context, spec AgentPodSpec) erro
 	return nil
 }
 
-// ensurePVC creates the workspace PVC if it does not already exist.
-func (m *K8sManager) ensurePVC(ctx context.Context, spec AgentPodSpec) error {
+// createPVC creates the workspace PVC claimName if it does not already
+// exist.
+func (m *K8sManager) createPVC(ctx context.Context, spec AgentPodSpec, claimName string) error {
 	ws := spec.WorkspaceStorage
-	claimName := ws.ClaimName
-	if claimName == "" {
-		claimName = spec.PodName() + "-workspace"
-	}
-
 	size := ws.Size
 	if size == "" {
 		size = "10Gi"
@@ -255,6 +330,9 @@ func (m *K8sManager) ensurePVC(ctx context.Context, spec AgentPodSpec) error {
 	if storageClass != "" {
 		pvc.Spec.StorageClassName = &storageClass
 	}
+	if spec.BeadID != "" {
+		pvc.Annotations = map[string]string{AnnotationBeadID: spec.BeadID}
+	}
 
 	_, err := m.client.CoreV1().PersistentVolumeClaims(spec.Namespace).Create(ctx, pvc, metav1.CreateOptions{})
 	if apierrors.IsAlreadyExists(err) {
@@ -291,6 +369,23 @@ func (m *K8sManager) GetAgentPod(ctx context.Context, name, namespace string) (*
 	return m.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
+// AnnotateAgentPod merges annotations into pod's, e.g. the bead ID of a pod
+// adopted from a manual deployment. The patch carries the listed
+// resourceVersion, so a pod changed since it was listed is left alone.
+func (m *K8sManager) AnnotateAgentPod(ctx context.Context, pod *corev1.Pod, annotations map[string]string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"resourceVersion": pod.ResourceVersion,
+			"annotations":     annotations,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal annotation patch: %w", err)
+	}
+	_, err = m.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
 func (m *K8sManager) buildPod(spec AgentPodSpec) *corev1.Pod {
 	container := m.buildContainer(spec)
 	volumes := m.buildVolumes(spec)
@@ -327,19 +422,40 @@ func (m *K8sManager) buildPod(spec AgentPodSpec) *corev1.Pod {
 	if spec.Affinity != nil {
 		podSpec.Affinity = spec.Affinity
 	}
+	if len(spec.HostAliases) > 0 {
+		podSpec.HostAliases = spec.HostAliases
+	}
+	if spec.DNSPolicy != "" {
+		podSpec.DNSPolicy = spec.DNSPolicy
+	}
+	if spec.DNSConfig != nil {
+		podSpec.DNSConfig = spec.DNSConfig
+	}
 
-	// Use a 30s termination grace period for all modes.
-	gracePeriod := int64(30)
+	// Spot capacity always gets long enough for a preempted agent to
+	// checkpoint.
+	gracePeriod := DefaultTerminationGracePeriod
+	if spec.TerminationGracePeriodSeconds != nil {
+		gracePeriod = *spec.TerminationGracePeriodSeconds
+	}
+	if spec.Spot {
+		gracePeriod = max(gracePeriod, SpotTerminationGracePeriod)
+	}
 	podSpec.TerminationGracePeriodSeconds = &gracePeriod
 
+	annotations := make(map[string]string, len(spec.ExtraAnnotations)+2)
+	maps.Copy(annotations, spec.ExtraAnnotations)
+	annotations[AnnotationBeadID] = spec.BeadID
+	if spec.ConfigHash != "" {
+		annotations[AnnotationConfigHash] = spec.ConfigHash
+	}
+
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      spec.PodName(),
-			Namespace: spec.Namespace,
-			Labels:    spec.Labels(),
-			Annotations: map[string]string{
-				AnnotationBeadID: spec.BeadID,
-			},
+			Name:        spec.PodName(),
+			Namespace:   spec.Namespace,
+			Labels:      spec.Labels(),
+			Annotations: annotations,
 		},
 		Spec: podSpec,
 	}
@@ -417,6 +533,14 @@ func (m *K8sManager) buildContainer(spec AgentPodSpec) corev1.Container {
 		PeriodSeconds:    5,
 	}
 
+	if spec.PreStop != "" {
+		c.Lifecycle = &corev1.Lifecycle{
+			PreStop: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{Command: []string{"sh", "-c", spec.PreStop}},
+			},
+		}
+	}
+
 	return c
 }
 
@@ -443,18 +567,11 @@ func (m *K8sManager) buildEnvVars(spec AgentPodSpec) []corev1.EnvVar {
 		envVars = append(envVars, corev1.EnvVar{Name: "BOAT_SESSION_RESUME", Value: "1"})
 	}
 
-	// All agents get BEADS_ACTOR, GIT_AUTHOR_NAME, BEADS_AGENT_NAME, and
-	// BOAT_AGENT_BEAD_ID (the agent's own bead, used by prime.sh to look up
-	// hook_bead and instructions without a list+filter round-trip).
-	// KD_AGENT_ID and KD_ACTOR are used by gb (and kd) for gate identity.
-	envVars = append(envVars,
-		corev1.EnvVar{Name: "BEADS_ACTOR", Value: spec.AgentName},
-		corev1.EnvVar{Name: "KD_ACTOR", Value: spec.AgentName},
-		corev1.EnvVar{Name: "KD_AGENT_ID", Value: spec.BeadID},
-		corev1.EnvVar{Name: "GIT_AUTHOR_NAME", Value: spec.AgentName},
-		corev1.EnvVar{Name: "BEADS_AGENT_NAME", Value: fmt.Sprintf("%s/%s", spec.Project, spec.AgentName)},
-		corev1.EnvVar{Name: "BOAT_AGENT_BEAD_ID", Value: spec.BeadID},
-	)
+	// All agents get their identity (BEADS_ACTOR, KD_AGENT_ID, ...).
+	envVars = append(envVars, IdentityEnv(spec)...)
+
+	// Egress proxy; the agent bead's own env below can override it.
+	envVars = append(envVars, proxyEnv(spec)...)
 
 	// Add plain env vars from spec.
 	for k, v := range spec.Env {
@@ -543,13 +660,16 @@ func (m *K8sManager) buildVolumes(spec AgentPodSpec) []corev1.Volume {
 		},
 	})
 
-	// Beads config volume: ConfigMap mount if specified.
+	// Beads config volume: ConfigMap mount if specified. Optional so a pod
+	// whose ConfigMap hasn't reached its cluster yet still starts.
 	if spec.ConfigMapName != "" {
+		optional := true
 		volumes = append(volumes, corev1.Volume{
 			Name: VolumeBeadsConfig,
 			VolumeSource: corev1.VolumeSource{
 				ConfigMap: &corev1.ConfigMapVolumeSource{
 					LocalObjectReference: corev1.LocalObjectReference{Name: spec.ConfigMapName},
+					Optional:             &optional,
 				},
 			},
 		})
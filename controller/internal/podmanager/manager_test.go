@@ -154,6 +154,51 @@ func TestCreateAgentPod_WithPVC(t *testing.T) {
 	}
 }
 
+func TestCreateAgentPod_ExtraLabelsAndAnnotations(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	mgr := New(client, testLogger())
+
+	spec := AgentPodSpec{
+		Mode:      "crew",
+		Project:   "proj",
+		Role:      "dev",
+		AgentName: "alpha",
+		BeadID:    "bd-1",
+		Image:     "img:v1",
+		Namespace: "ns",
+		ExtraLabels: map[string]string{
+			"cost-center": "cc-42",
+			LabelAgent:    "spoofed",
+		},
+		ExtraAnnotations: map[string]string{
+			"example.com/owner": "alice",
+			AnnotationBeadID:    "forged",
+		},
+		WorkspaceStorage: &WorkspaceStorageSpec{Size: "1Gi"},
+	}
+	if err := mgr.CreateAgentPod(context.Background(), spec); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pod, err := client.CoreV1().Pods("ns").Get(context.Background(), "crew-proj-dev-alpha", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod.Labels["cost-center"] != "cc-42" || pod.Labels[LabelAgent] != "alpha" {
+		t.Errorf("pod labels = %v", pod.Labels)
+	}
+	if pod.Annotations["example.com/owner"] != "alice" || pod.Annotations[AnnotationBeadID] != "bd-1" {
+		t.Errorf("pod annotations = %v", pod.Annotations)
+	}
+	pvc, err := client.CoreV1().PersistentVolumeClaims("ns").Get(context.Background(), "bd-1-workspace", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pvc.Labels["cost-center"] != "cc-42" {
+		t.Errorf("PVC labels = %v", pvc.Labels)
+	}
+}
+
 func TestCreateAgentPod_PVCIdempotent(t *testing.T) {
 	client := fake.NewSimpleClientset()
 	mgr := New(client, testLogger())
@@ -368,6 +413,60 @@ func TestBuildPod_TerminationGracePeriod(t *testing.T) {
 	if pod.Spec.TerminationGracePeriodSeconds == nil || *pod.Spec.TerminationGracePeriodSeconds != 30 {
 		t.Error("expected 30s termination grace period")
 	}
+
+	// A role or project grace period replaces the default, and is kept on
+	// spot capacity when it is longer than the spot minimum.
+	grace := int64(600)
+	spec.TerminationGracePeriodSeconds = &grace
+	spec.Spot = true
+	if got := *mgr.buildPod(spec).Spec.TerminationGracePeriodSeconds; got != 600 {
+		t.Errorf("grace period = %d, want 600", got)
+	}
+}
+
+func TestBuildPod_PreStop(t *testing.T) {
+	mgr := New(fake.NewSimpleClientset(), testLogger())
+	spec := AgentPodSpec{
+		Mode: "crew", Project: "proj", Role: "dev", AgentName: "alpha",
+		Image: "img:v1", Namespace: "ns",
+	}
+
+	if pod := mgr.buildPod(spec); pod.Spec.Containers[0].Lifecycle != nil {
+		t.Error("expected no lifecycle hooks without a preStop script")
+	}
+
+	spec.PreStop = "gb yield --checkpoint"
+	lc := mgr.buildPod(spec).Spec.Containers[0].Lifecycle
+	if lc == nil || lc.PreStop == nil || lc.PreStop.Exec == nil {
+		t.Fatalf("lifecycle = %+v, want a preStop exec hook", lc)
+	}
+	if got := lc.PreStop.Exec.Command; len(got) != 3 || got[0] != "sh" || got[2] != "gb yield --checkpoint" {
+		t.Errorf("preStop command = %q", got)
+	}
+}
+
+func TestBuildPod_DNS(t *testing.T) {
+	mgr := New(fake.NewSimpleClientset(), testLogger())
+	spec := AgentPodSpec{
+		Mode: "crew", Project: "proj", Role: "dev", AgentName: "alpha",
+		Image: "img:v1", Namespace: "ns",
+	}
+
+	pod := mgr.buildPod(spec)
+	if pod.Spec.HostAliases != nil || pod.Spec.DNSPolicy != "" || pod.Spec.DNSConfig != nil {
+		t.Errorf("expected cluster DNS defaults, got %+v", pod.Spec)
+	}
+
+	spec.HostAliases = []corev1.HostAlias{{IP: "10.0.0.5", Hostnames: []string{"git.corp"}}}
+	spec.DNSPolicy = corev1.DNSNone
+	spec.DNSConfig = &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.2"}}
+	pod = mgr.buildPod(spec)
+	if len(pod.Spec.HostAliases) != 1 || pod.Spec.HostAliases[0].IP != "10.0.0.5" {
+		t.Errorf("host aliases = %+v", pod.Spec.HostAliases)
+	}
+	if pod.Spec.DNSPolicy != corev1.DNSNone || pod.Spec.DNSConfig == nil || pod.Spec.DNSConfig.Nameservers[0] != "10.0.0.2" {
+		t.Errorf("dns = %q %+v", pod.Spec.DNSPolicy, pod.Spec.DNSConfig)
+	}
 }
 
 func TestBuildPod_ServiceAccountName(t *testing.T) {
@@ -781,6 +880,9 @@ func TestBuildVolumes_ConfigMap(t *testing.T) {
 			if v.ConfigMap.Name != "agent-config" {
 				t.Errorf("ConfigMap name = %s, want agent-config", v.ConfigMap.Name)
 			}
+			if v.ConfigMap.Optional == nil || !*v.ConfigMap.Optional {
+				t.Error("expected ConfigMap volume to be optional")
+			}
 		}
 	}
 	if !found {
@@ -1085,3 +1187,16 @@ func TestBuildInitCloneContainer_ChownsWorkspace(t *testing.T) {
 		t.Errorf("script should chown workspace to %d:%d", AgentUID, AgentGID)
 	}
 }
+
+func TestBuildPod_ConfigHashAnnotation(t *testing.T) {
+	mgr := New(fake.NewSimpleClientset(), testLogger())
+	spec := AgentPodSpec{Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha", Image: "img:v1", Namespace: "ns"}
+
+	if pod := mgr.buildPod(spec); pod.Annotations[AnnotationConfigHash] != "" {
+		t.Errorf("unexpected config-hash annotation %q", pod.Annotations[AnnotationConfigHash])
+	}
+	spec.ConfigMapName, spec.ConfigHash = "proj-dev-alpha-config", "abc123"
+	if pod := mgr.buildPod(spec); pod.Annotations[AnnotationConfigHash] != "abc123" {
+		t.Errorf("config-hash annotation = %q, want abc123", pod.Annotations[AnnotationConfigHash])
+	}
+}
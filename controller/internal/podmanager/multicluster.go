@@ -0,0 +1,269 @@
+package podmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Placement policies for agents whose spec does not name a cluster.
+const (
+	// PlaceByProject runs unpinned agents on the home cluster.
+	PlaceByProject = "project"
+	// PlaceByCapacity fills clusters in order, moving to the next once a
+	// cluster reaches its MaxPods.
+	PlaceByCapacity = "capacity"
+)
+
+// Cluster is one Kubernetes cluster agent pods can run on.
+type Cluster struct {
+	Name    string
+	Manager Manager
+	// MaxPods caps active agent pods on the cluster under capacity
+	// placement. 0 means unlimited.
+	MaxPods int
+}
+
+// MultiCluster implements Manager across several clusters. The first cluster
+// is the home cluster, where the controller runs. Pods are placed on the
+// cluster their spec names, else by the placement policy; listed pods carry
+// the LabelCluster label of the cluster they were found on.
+type MultiCluster struct {
+	clusters []Cluster
+	policy   string
+	logger   *slog.Logger
+}
+
+// NewMultiCluster creates a manager over clusters, home cluster first.
+func NewMultiCluster(clusters []Cluster, policy string, logger *slog.Logger) (*MultiCluster, error) {
+	if len(clusters) == 0 {
+		return nil, errors.New("at least one cluster is required")
+	}
+	switch policy {
+	case "":
+		policy = PlaceByProject
+	case PlaceByProject, PlaceByCapacity:
+	default:
+		return nil, fmt.Errorf("unknown placement policy %q (want %s or %s)", policy, PlaceByProject, PlaceByCapacity)
+	}
+	return &MultiCluster{clusters: clusters, policy: policy, logger: logger}, nil
+}
+
+// Clusters returns the cluster names, home cluster first.
+func (m *MultiCluster) Clusters() []string {
+	names := make([]string, len(m.clusters))
+	for i, c := range m.clusters {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// CreateAgentPod creates the pod on the cluster chosen by place.
+func (m *MultiCluster) CreateAgentPod(ctx context.Context, spec AgentPodSpec) error {
+	c, err := m.place(ctx, spec)
+	if err != nil {
+		return err
+	}
+	spec.Cluster = c.Name
+	return c.Manager.CreateAgentPod(ctx, spec)
+}
+
+// place picks the cluster for spec: the one it names, else the home cluster,
+// or under capacity placement the first cluster below its MaxPods. When
+// every cluster is full the home cluster is used, leaving the global
+// CoopMaxPods cap to throttle creation.
+func (m *MultiCluster) place(ctx context.Context, spec AgentPodSpec) (Cluster, error) {
+	if spec.Cluster != "" {
+		for _, c := range m.clusters {
+			if c.Name == spec.Cluster {
+				return c, nil
+			}
+		}
+		return Cluster{}, fmt.Errorf("pod %s: unknown cluster %q", spec.PodName(), spec.Cluster)
+	}
+	home := m.clusters[0]
+	if m.policy != PlaceByCapacity {
+		return home, nil
+	}
+	for _, c := range m.clusters {
+		if c.MaxPods <= 0 {
+			return c, nil
+		}
+		n, err := m.activePods(ctx, c, spec.Namespace)
+		if err != nil {
+			m.logger.Warn("skipping cluster for placement: cannot count pods",
+				"cluster", c.Name, "error", err)
+			continue
+		}
+		if n < c.MaxPods {
+			return c, nil
+		}
+	}
+	m.logger.Warn("all clusters at capacity, placing pod on home cluster",
+		"pod", spec.PodName(), "cluster", home.Name)
+	return home, nil
+}
+
+// activePods counts the agent pods on c that are not terminal.
+func (m *MultiCluster) activePods(ctx context.Context, c Cluster, namespace string) (int, error) {
+	pods, err := c.Manager.ListAgentPods(ctx, namespace, map[string]string{LabelApp: LabelAppValue})
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, p := range pods {
+		if _, ok := p.Labels[LabelAgent]; !ok {
+			continue
+		}
+		if p.Status.Phase != corev1.PodFailed && p.Status.Phase != corev1.PodSucceeded {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// DeleteAgentPod deletes the named pod from every cluster it exists on.
+// It returns the home cluster's NotFound error if it exists on none.
+func (m *MultiCluster) DeleteAgentPod(ctx context.Context, name, namespace string) error {
+	var errs []error
+	deleted := false
+	for _, c := range m.clusters {
+		if _, err := c.Manager.GetAgentPod(ctx, name, namespace); err != nil {
+			if !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("cluster %s: %w", c.Name, err))
+			}
+			continue
+		}
+		if err := c.Manager.DeleteAgentPod(ctx, name, namespace); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", c.Name, err))
+			continue
+		}
+		deleted = true
+	}
+	if !deleted && len(errs) == 0 {
+		return m.clusters[0].Manager.DeleteAgentPod(ctx, name, namespace)
+	}
+	return errors.Join(errs...)
+}
+
+// DeleteClusterPod deletes the named pod from one cluster only, e.g. a
+// duplicate left on a cluster the agent has moved away from.
+func (m *MultiCluster) DeleteClusterPod(ctx context.Context, cluster, name, namespace string) error {
+	for _, c := range m.clusters {
+		if c.Name == cluster {
+			return c.Manager.DeleteAgentPod(ctx, name, namespace)
+		}
+	}
+	return fmt.Errorf("unknown cluster %q", cluster)
+}
+
+// AnnotateAgentPod merges annotations into a listed pod's on the cluster
+// its LabelCluster label names.
+func (m *MultiCluster) AnnotateAgentPod(ctx context.Context, pod *corev1.Pod, annotations map[string]string) error {
+	for _, c := range m.clusters {
+		if c.Name != pod.Labels[LabelCluster] {
+			continue
+		}
+		a, ok := c.Manager.(interface {
+			AnnotateAgentPod(context.Context, *corev1.Pod, map[string]string) error
+		})
+		if !ok {
+			return fmt.Errorf("cluster %s: pods can't be annotated", c.Name)
+		}
+		return a.AnnotateAgentPod(ctx, pod, annotations)
+	}
+	return fmt.Errorf("unknown cluster %q", pod.Labels[LabelCluster])
+}
+
+// AdoptLegacyWorkspaces adopts legacy workspace claims on every cluster
+// (see K8sManager.AdoptLegacyWorkspaces).
+func (m *MultiCluster) AdoptLegacyWorkspaces(ctx context.Context, namespace string, beads map[string]string) (int, error) {
+	total := 0
+	var errs []error
+	for _, c := range m.clusters {
+		a, ok := c.Manager.(interface {
+			AdoptLegacyWorkspaces(context.Context, string, map[string]string) (int, error)
+		})
+		if !ok {
+			continue
+		}
+		n, err := a.AdoptLegacyWorkspaces(ctx, namespace, beads)
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cluster %s: %w", c.Name, err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// ListAgentPods lists matching pods on every cluster. It fails if any
+// cluster can't be listed: a partial view would make the reconciler treat
+// that cluster's agents as missing and create duplicates elsewhere.
+func (m *MultiCluster) ListAgentPods(ctx context.Context, namespace string, labelSelector map[string]string) ([]corev1.Pod, error) {
+	var all []corev1.Pod
+	for _, c := range m.clusters {
+		pods, err := c.Manager.ListAgentPods(ctx, namespace, labelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+		for i := range pods {
+			stampCluster(&pods[i], c.Name)
+		}
+		all = append(all, pods...)
+	}
+	return all, nil
+}
+
+// GetAgentPod returns the named pod from the first cluster that has it.
+func (m *MultiCluster) GetAgentPod(ctx context.Context, name, namespace string) (*corev1.Pod, error) {
+	var firstErr error
+	for _, c := range m.clusters {
+		pod, err := c.Manager.GetAgentPod(ctx, name, namespace)
+		if err == nil {
+			stampCluster(pod, c.Name)
+			return pod, nil
+		}
+		if firstErr == nil || (apierrors.IsNotFound(firstErr) && !apierrors.IsNotFound(err)) {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// Capacity merges the schedulable capacity of every cluster. Unpinned pods
+// may use any cluster under capacity placement, else only the home cluster.
+// It fails if any cluster's capacity is unknown.
+func (m *MultiCluster) Capacity(ctx context.Context) (*Capacity, error) {
+	snapshots := make(map[string]*Capacity, len(m.clusters))
+	for _, c := range m.clusters {
+		cm, ok := c.Manager.(interface {
+			Capacity(ctx context.Context) (*Capacity, error)
+		})
+		if !ok {
+			return nil, fmt.Errorf("cluster %s: capacity not supported", c.Name)
+		}
+		s, err := cm.Capacity(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+		snapshots[c.Name] = s
+	}
+	var eligible map[string]bool
+	if m.policy != PlaceByCapacity {
+		eligible = map[string]bool{m.clusters[0].Name: true}
+	}
+	return mergeCapacity(snapshots, eligible), nil
+}
+
+// stampCluster labels a pod with the cluster it was found on, which also
+// covers pods created before multi-cluster support.
+func stampCluster(pod *corev1.Pod, cluster string) {
+	if pod.Labels == nil {
+		pod.Labels = make(map[string]string)
+	}
+	pod.Labels[LabelCluster] = cluster
+}
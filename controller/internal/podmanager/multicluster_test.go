@@ -0,0 +1,137 @@
+package podmanager
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestMultiCluster returns a MultiCluster over fake "home" and "burst"
+// clusters with the given MaxPods.
+func newTestMultiCluster(t *testing.T, policy string, homeMax, burstMax int) (*MultiCluster, *fake.Clientset, *fake.Clientset) {
+	t.Helper()
+	home, burst := fake.NewSimpleClientset(), fake.NewSimpleClientset()
+	m, err := NewMultiCluster([]Cluster{
+		{Name: "home", Manager: New(home, testLogger()), MaxPods: homeMax},
+		{Name: "burst", Manager: New(burst, testLogger()), MaxPods: burstMax},
+	}, policy, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, home, burst
+}
+
+func agentSpec(name string) AgentPodSpec {
+	return AgentPodSpec{Project: "gasboat", Mode: "crew", Role: "dev", AgentName: name, Image: "agent:latest", Namespace: "gasboat"}
+}
+
+func podCount(t *testing.T, c *fake.Clientset) int {
+	t.Helper()
+	list, err := c.CoreV1().Pods("gasboat").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(list.Items)
+}
+
+func TestMultiCluster_PinnedClusterWins(t *testing.T) {
+	m, home, burst := newTestMultiCluster(t, PlaceByProject, 0, 0)
+	ctx := context.Background()
+
+	spec := agentSpec("a")
+	spec.Cluster = "burst"
+	if err := m.CreateAgentPod(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateAgentPod(ctx, agentSpec("b")); err != nil {
+		t.Fatal(err)
+	}
+	if podCount(t, home) != 1 || podCount(t, burst) != 1 {
+		t.Fatalf("pods: home=%d burst=%d, want 1 each", podCount(t, home), podCount(t, burst))
+	}
+	pod, err := burst.CoreV1().Pods("gasboat").Get(ctx, "crew-gasboat-dev-a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pod.Labels[LabelCluster] != "burst" {
+		t.Errorf("cluster label = %q, want burst", pod.Labels[LabelCluster])
+	}
+
+	spec.Cluster = "mars"
+	if err := m.CreateAgentPod(ctx, spec); err == nil {
+		t.Error("expected error for unknown cluster")
+	}
+}
+
+func TestMultiCluster_CapacityPlacementBursts(t *testing.T) {
+	m, home, burst := newTestMultiCluster(t, PlaceByCapacity, 2, 0)
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "b", "c"} {
+		if err := m.CreateAgentPod(ctx, agentSpec(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if podCount(t, home) != 2 || podCount(t, burst) != 1 {
+		t.Fatalf("pods: home=%d burst=%d, want 2 and 1", podCount(t, home), podCount(t, burst))
+	}
+
+	// Terminal pods don't count against capacity.
+	pod, _ := home.CoreV1().Pods("gasboat").Get(ctx, "crew-gasboat-dev-a", metav1.GetOptions{})
+	pod.Status.Phase = corev1.PodFailed
+	if _, err := home.CoreV1().Pods("gasboat").UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateAgentPod(ctx, agentSpec("d")); err != nil {
+		t.Fatal(err)
+	}
+	if podCount(t, home) != 3 {
+		t.Errorf("expected pod d on home after a pod there failed, home has %d pods", podCount(t, home))
+	}
+}
+
+func TestMultiCluster_ListStampsClusterAndDeleteFindsPod(t *testing.T) {
+	m, _, burst := newTestMultiCluster(t, PlaceByProject, 0, 0)
+	ctx := context.Background()
+
+	spec := agentSpec("a")
+	spec.Cluster = "burst"
+	if err := m.CreateAgentPod(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.CreateAgentPod(ctx, agentSpec("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	pods, err := m.ListAgentPods(ctx, "gasboat", map[string]string{LabelApp: LabelAppValue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusters := map[string]string{}
+	for _, p := range pods {
+		clusters[p.Name] = p.Labels[LabelCluster]
+	}
+	if clusters["crew-gasboat-dev-a"] != "burst" || clusters["crew-gasboat-dev-b"] != "home" {
+		t.Errorf("listed clusters = %v", clusters)
+	}
+
+	if err := m.DeleteAgentPod(ctx, "crew-gasboat-dev-a", "gasboat"); err != nil {
+		t.Fatal(err)
+	}
+	if podCount(t, burst) != 0 {
+		t.Error("pod not deleted from burst cluster")
+	}
+	if err := m.DeleteAgentPod(ctx, "crew-gasboat-dev-a", "gasboat"); !apierrors.IsNotFound(err) {
+		t.Errorf("expected NotFound deleting a missing pod, got %v", err)
+	}
+}
+
+func TestNewMultiCluster_RejectsUnknownPolicy(t *testing.T) {
+	if _, err := NewMultiCluster([]Cluster{{Name: "home"}}, "random", testLogger()); err == nil {
+		t.Error("expected error for unknown placement policy")
+	}
+}
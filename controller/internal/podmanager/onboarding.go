@@ -0,0 +1,48 @@
+package podmanager
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// LabelOnboarding marks the disposable smoke-test pods that validate a new
+// project (value "true"). Like warm pods they carry no gasboat.io/agent
+// label, so the reconciler and status reporter ignore them.
+const LabelOnboarding = "gasboat.io/onboarding"
+
+// CreateOnboardingPod starts a disposable pod built like an agent pod of
+// spec's project: it pulls the agent image, resolves the project's secrets
+// and clones its repos with the project's credentials. The workspace is
+// never persisted and the pod is not restarted.
+func (m *K8sManager) CreateOnboardingPod(ctx context.Context, spec AgentPodSpec) (*corev1.Pod, error) {
+	spec.WorkspaceStorage = nil
+	spec.BeadID = ""
+	pod := m.buildPod(spec)
+	pod.Name = fmt.Sprintf("onboard-%s-%s", spec.Project, utilrand.String(5))
+	delete(pod.Labels, LabelAgent)
+	pod.Labels[LabelOnboarding] = "true"
+	pod.Annotations = nil
+	pod.Spec.RestartPolicy = corev1.RestartPolicyNever
+
+	m.logger.Info("creating onboarding pod", "pod", pod.Name, "project", spec.Project)
+	created, err := m.client.CoreV1().Pods(spec.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("creating onboarding pod %s: %w", pod.Name, err)
+	}
+	return created, nil
+}
+
+// ListOnboardingPods lists the onboarding pods of project in namespace.
+func (m *K8sManager) ListOnboardingPods(ctx context.Context, namespace, project string) ([]corev1.Pod, error) {
+	list, err := m.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true,%s=%s", LabelOnboarding, LabelProject, project),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing onboarding pods: %w", err)
+	}
+	return list.Items, nil
+}
@@ -0,0 +1,48 @@
+package podmanager
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// CapacitySpot is the LabelCapacity value for pods on spot nodes.
+	CapacitySpot = "spot"
+
+	// SpotTerminationGracePeriod matches the two-minute interruption notice
+	// cloud providers give before reclaiming a spot node.
+	SpotTerminationGracePeriod = int64(120)
+)
+
+// preemptionReasons are pod status reasons set by the kubelet when a pod is
+// terminated because its node is going away.
+var preemptionReasons = map[string]bool{
+	"Shutdown":     true, // graceful node shutdown
+	"NodeShutdown": true,
+	"Terminated":   true, // "Pod was terminated in response to imminent node shutdown."
+	"NodeLost":     true,
+}
+
+// PreemptionReason returns why a spot pod is being (or was) preempted off
+// its node, or "" if it wasn't. Pods not on spot capacity are never
+// considered preempted: their disruptions are failures like any other.
+//
+// A preempted pod carries a DisruptionTarget condition (set when the node is
+// drained, tainted, or shut down) or, once terminated by a node shutdown, a
+// kubelet status reason.
+func PreemptionReason(pod *corev1.Pod) string {
+	if pod.Labels[LabelCapacity] != CapacitySpot {
+		return ""
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.DisruptionTarget && c.Status == corev1.ConditionTrue {
+			if c.Reason != "" {
+				return c.Reason
+			}
+			return string(corev1.DisruptionTarget)
+		}
+	}
+	if preemptionReasons[pod.Status.Reason] {
+		return pod.Status.Reason
+	}
+	return ""
+}
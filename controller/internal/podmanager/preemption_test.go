@@ -0,0 +1,63 @@
+package podmanager
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPreemptionReason(t *testing.T) {
+	spot := map[string]string{LabelCapacity: CapacitySpot}
+	disrupted := []corev1.PodCondition{{
+		Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue, Reason: "DeletionByTaintManager",
+	}}
+	tests := []struct {
+		name   string
+		labels map[string]string
+		status corev1.PodStatus
+		want   string
+	}{
+		{"spot pod disrupted", spot, corev1.PodStatus{Phase: corev1.PodRunning, Conditions: disrupted}, "DeletionByTaintManager"},
+		{"spot pod node shutdown", spot, corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Terminated"}, "Terminated"},
+		{"spot pod crashed", spot, corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Error"}, ""},
+		{"on-demand pod disrupted", nil, corev1.PodStatus{Phase: corev1.PodFailed, Conditions: disrupted}, ""},
+		{"condition cleared", spot, corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type: corev1.DisruptionTarget, Status: corev1.ConditionFalse,
+		}}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: tt.labels}, Status: tt.status}
+			if got := PreemptionReason(pod); got != tt.want {
+				t.Errorf("PreemptionReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPod_Spot(t *testing.T) {
+	mgr := New(fake.NewSimpleClientset(), testLogger())
+	spec := AgentPodSpec{
+		Mode: "job", Project: "proj", Role: "job", AgentName: "j1",
+		Image: "img:v1", Namespace: "ns", Spot: true,
+	}
+
+	pod := mgr.buildPod(spec)
+	if pod.Labels[LabelCapacity] != CapacitySpot {
+		t.Errorf("capacity label = %q, want spot", pod.Labels[LabelCapacity])
+	}
+	if got := *pod.Spec.TerminationGracePeriodSeconds; got != SpotTerminationGracePeriod {
+		t.Errorf("grace period = %d, want %d", got, SpotTerminationGracePeriod)
+	}
+
+	spec.Spot = false
+	pod = mgr.buildPod(spec)
+	if _, ok := pod.Labels[LabelCapacity]; ok {
+		t.Error("on-demand pod should not carry the capacity label")
+	}
+	if got := *pod.Spec.TerminationGracePeriodSeconds; got != 30 {
+		t.Errorf("grace period = %d, want 30", got)
+	}
+}
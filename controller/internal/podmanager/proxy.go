@@ -0,0 +1,90 @@
+package podmanager
+
+import (
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ProxySpec routes the outbound traffic of an agent pod through an HTTP
+// proxy, for clusters that only allow egress through one.
+type ProxySpec struct {
+	HTTPProxy  string // e.g. http://proxy.corp.example:3128
+	HTTPSProxy string // Defaults to HTTPProxy
+	NoProxy    string // Comma-separated hosts, domains and CIDRs reached directly
+}
+
+// clusterNoProxy is always reached directly: the pod itself and cluster
+// services.
+var clusterNoProxy = []string{"localhost", "127.0.0.1", ".svc", ".cluster.local"}
+
+// internalAddrEnv are the env vars holding addresses of gasboat services in
+// the cluster, which agents must reach without the proxy whatever their
+// names.
+var internalAddrEnv = []string{
+	"BEADS_HTTP_ADDR", "BEADS_GRPC_ADDR", "BEADS_NATS_URL",
+	"COOP_NATS_URL", "COOP_BROKER_URL", "COOP_MUX_URL",
+}
+
+// proxyEnv returns the proxy env vars of spec, in both the upper and lower
+// case spellings since tools disagree on which they read (apk and curl
+// only honor http_proxy). It returns nil when spec has no proxy.
+func proxyEnv(spec AgentPodSpec) []corev1.EnvVar {
+	p := spec.Proxy
+	if p == nil || (p.HTTPProxy == "" && p.HTTPSProxy == "") {
+		return nil
+	}
+	httpsProxy := p.HTTPSProxy
+	if httpsProxy == "" {
+		httpsProxy = p.HTTPProxy
+	}
+
+	var noProxy []string
+	for _, h := range strings.Split(p.NoProxy, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			noProxy = append(noProxy, h)
+		}
+	}
+	noProxy = append(noProxy, clusterNoProxy...)
+	for _, key := range internalAddrEnv {
+		if host := addrHost(spec.Env[key]); host != "" {
+			noProxy = append(noProxy, host)
+		}
+	}
+	slices.Sort(noProxy)
+	noProxy = slices.Compact(noProxy)
+
+	var env []corev1.EnvVar
+	for _, kv := range [][2]string{
+		{"HTTP_PROXY", p.HTTPProxy},
+		{"HTTPS_PROXY", httpsProxy},
+		{"NO_PROXY", strings.Join(noProxy, ",")},
+	} {
+		if kv[1] == "" {
+			continue
+		}
+		env = append(env,
+			corev1.EnvVar{Name: kv[0], Value: kv[1]},
+			corev1.EnvVar{Name: strings.ToLower(kv[0]), Value: kv[1]},
+		)
+	}
+	return env
+}
+
+// addrHost returns the host of a service address, given as a URL or as
+// host:port.
+func addrHost(addr string) string {
+	if addr == "" {
+		return ""
+	}
+	if u, err := url.Parse(addr); err == nil && u.Host != "" {
+		return u.Hostname()
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
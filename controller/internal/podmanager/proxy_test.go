@@ -0,0 +1,51 @@
+package podmanager
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func envValue(env []corev1.EnvVar, name string) (string, bool) {
+	for _, e := range env {
+		if e.Name == name {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+func TestBuildPod_Proxy(t *testing.T) {
+	mgr := New(fake.NewSimpleClientset(), testLogger())
+	spec := AgentPodSpec{
+		Mode: "crew", Project: "proj", Role: "dev", AgentName: "alpha",
+		Image: "img:v1", Namespace: "ns", GitURL: "https://git.corp.example/org/repo.git",
+		Env: map[string]string{
+			"BEADS_HTTP_ADDR": "http://beads-daemon:8080",
+			"BEADS_GRPC_ADDR": "beads-daemon:9090",
+			"COOP_MUX_URL":    "http://coopmux.gasboat.svc:9800",
+		},
+	}
+
+	pod := mgr.buildPod(spec)
+	if _, ok := envValue(pod.Spec.Containers[0].Env, "HTTP_PROXY"); ok {
+		t.Error("expected no proxy env without a proxy")
+	}
+
+	spec.Proxy = &ProxySpec{HTTPProxy: "http://proxy.corp:3128", NoProxy: "git.corp.example, 10.0.0.0/8"}
+	pod = mgr.buildPod(spec)
+	wantNoProxy := ".cluster.local,.svc,10.0.0.0/8,127.0.0.1,beads-daemon,coopmux.gasboat.svc,git.corp.example,localhost"
+	for _, c := range []corev1.Container{pod.Spec.Containers[0], pod.Spec.InitContainers[0]} {
+		for name, want := range map[string]string{
+			"HTTP_PROXY":  "http://proxy.corp:3128",
+			"https_proxy": "http://proxy.corp:3128",
+			"NO_PROXY":    wantNoProxy,
+			"no_proxy":    wantNoProxy,
+		} {
+			if got, _ := envValue(c.Env, name); got != want {
+				t.Errorf("%s: %s = %q, want %q", c.Name, name, got, want)
+			}
+		}
+	}
+}
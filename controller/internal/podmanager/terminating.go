@@ -0,0 +1,28 @@
+package podmanager
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AnnotationTerminateAfter marks an agent pod in its termination grace
+// window: the agent was told to checkpoint, and the pod is deleted once the
+// RFC 3339 time it holds has passed. Until then the reconciler leaves the
+// pod alone even though its bead is closed.
+const AnnotationTerminateAfter = "gasboat.io/terminate-after"
+
+// TerminateAfter returns when a terminating pod may be deleted, and whether
+// the pod is terminating at all.
+func TerminateAfter(pod *corev1.Pod) (time.Time, bool) {
+	v := pod.Annotations[AnnotationTerminateAfter]
+	if v == "" {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		// An unreadable deadline protects nothing.
+		return time.Time{}, true
+	}
+	return at, true
+}
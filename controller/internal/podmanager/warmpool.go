@@ -0,0 +1,176 @@
+package podmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+)
+
+// LabelWarm marks idle warm-pool pods (value "true"). Warm pods carry no
+// gasboat.io/agent label, so the reconciler and status reporter ignore them
+// until they are adopted.
+const LabelWarm = "gasboat.io/warm"
+
+// AgentPodName returns the canonical {mode}-{project}-{role}-{agent} name of
+// the agent a pod runs, from its labels. It differs from pod.Name for pods
+// adopted from the warm pool, which keep their pool name. Pods missing any of
+// the identity labels fall back to pod.Name.
+func AgentPodName(pod *corev1.Pod) string {
+	mode, project := pod.Labels[LabelMode], pod.Labels[LabelProject]
+	role, agent := pod.Labels[LabelRole], pod.Labels[LabelAgent]
+	if mode == "" || project == "" || role == "" || agent == "" {
+		return pod.Name
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", mode, project, role, agent)
+}
+
+// IdentityEnv returns the environment variables that identify the agent a
+// pod runs. Warm pods start without them and receive them on adoption.
+func IdentityEnv(spec AgentPodSpec) []corev1.EnvVar {
+	// KD_AGENT_ID and KD_ACTOR are used by gb (and kd) for gate identity.
+	// BOAT_AGENT_BEAD_ID is the agent's own bead, used by prime.sh to look
+	// up hook_bead and instructions without a list+filter round-trip.
+	return []corev1.EnvVar{
+		{Name: "BEADS_ACTOR", Value: spec.AgentName},
+		{Name: "KD_ACTOR", Value: spec.AgentName},
+		{Name: "KD_AGENT_ID", Value: spec.BeadID},
+		{Name: "GIT_AUTHOR_NAME", Value: spec.AgentName},
+		{Name: "BEADS_AGENT_NAME", Value: fmt.Sprintf("%s/%s", spec.Project, spec.AgentName)},
+		{Name: "BOAT_AGENT_BEAD_ID", Value: spec.BeadID},
+	}
+}
+
+// Warmable reports whether an agent with this spec can run in a warm pod.
+// Pods with a workspace PVC are excluded: the claim belongs to the agent's
+// bead and can't be attached to an already-running pod.
+func Warmable(spec AgentPodSpec) bool {
+	return spec.WorkspaceStorage == nil
+}
+
+// CreateWarmPod starts an idle pod for spec's project, role, and mode. The
+// pod pulls the image and clones the workspace like an agent pod, but has no
+// agent identity until AdoptWarmPod assigns one.
+func (m *K8sManager) CreateWarmPod(ctx context.Context, spec AgentPodSpec) error {
+	if !Warmable(spec) {
+		return fmt.Errorf("%s/%s pods use a workspace PVC and cannot be pooled", spec.Project, spec.Role)
+	}
+	spec.AgentName = ""
+	spec.BeadID = ""
+	pod := m.buildPod(spec)
+	pod.Name = fmt.Sprintf("warm-%s-%s-%s", spec.Project, spec.Role, utilrand.String(5))
+	delete(pod.Labels, LabelAgent)
+	pod.Labels[LabelWarm] = "true"
+	pod.Annotations = nil
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{Name: "BOAT_WARM", Value: "1"})
+
+	m.logger.Info("creating warm pod", "pod", pod.Name, "project", spec.Project, "role", spec.Role)
+	if _, err := m.client.CoreV1().Pods(spec.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("creating warm pod %s: %w", pod.Name, err)
+	}
+	return nil
+}
+
+// ListWarmPods lists the idle warm pods in namespace.
+func (m *K8sManager) ListWarmPods(ctx context.Context, namespace string) ([]corev1.Pod, error) {
+	list, err := m.client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: LabelWarm + "=true",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing warm pods: %w", err)
+	}
+	return list.Items, nil
+}
+
+// AdoptWarmPod claims a ready warm pod matching spec's project, role, mode,
+// and image, relabeling it as spec's agent. It returns nil if no pod is
+// available. The caller must still hand the pod its identity (IdentityEnv).
+func (m *K8sManager) AdoptWarmPod(ctx context.Context, spec AgentPodSpec) (*corev1.Pod, error) {
+	if !Warmable(spec) {
+		return nil, nil
+	}
+	pods, err := m.ListWarmPods(ctx, spec.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if !warmPodMatches(pod, spec) {
+			continue
+		}
+		adopted, err := m.relabel(ctx, pod, spec)
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			continue // claimed or removed since listing
+		}
+		if err != nil {
+			return nil, err
+		}
+		m.logger.Info("adopted warm pod", "pod", pod.Name, "agent", spec.PodName())
+		return adopted, nil
+	}
+	return nil, nil
+}
+
+// relabel turns a warm pod into spec's agent pod. The patch carries the
+// listed resourceVersion so two adopters can't claim the same pod.
+func (m *K8sManager) relabel(ctx context.Context, pod *corev1.Pod, spec AgentPodSpec) (*corev1.Pod, error) {
+	labels := map[string]any{}
+	for k, v := range spec.ExtraLabels {
+		labels[k] = v
+	}
+	labels[LabelWarm] = nil
+	labels[LabelAgent] = spec.AgentName
+	annotations := maps.Clone(spec.ExtraAnnotations)
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationBeadID] = spec.BeadID
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"resourceVersion": pod.ResourceVersion,
+			"labels":          labels,
+			"annotations":     annotations,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal adoption patch: %w", err)
+	}
+	return m.client.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// warmPodMatches reports whether an idle warm pod can run spec's agent.
+func warmPodMatches(pod *corev1.Pod, spec AgentPodSpec) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning || !podReady(pod) {
+		return false
+	}
+	if pod.Labels[LabelProject] != spec.Project || pod.Labels[LabelRole] != spec.Role ||
+		pod.Labels[LabelMode] != spec.Mode {
+		return false
+	}
+	return AgentImage(pod) == spec.Image
+}
+
+// AgentImage returns the image of a pod's agent container.
+func AgentImage(pod *corev1.Pod) string {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == ContainerName {
+			return c.Image
+		}
+	}
+	return ""
+}
+
+func podReady(pod *corev1.Pod) bool {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
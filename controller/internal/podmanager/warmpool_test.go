@@ -0,0 +1,91 @@
+package podmanager
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func jobSpec(name string) AgentPodSpec {
+	spec := agentSpec(name)
+	spec.Mode, spec.Role = "job", "job"
+	spec.BeadID = "bd-" + name
+	return spec
+}
+
+// markReady makes every pod in the fake cluster running and ready.
+func markReady(t *testing.T, client *fake.Clientset) {
+	t.Helper()
+	ctx := context.Background()
+	list, err := client.CoreV1().Pods("gasboat").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range list.Items {
+		pod := &list.Items[i]
+		pod.Status.Phase = corev1.PodRunning
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		if _, err := client.CoreV1().Pods("gasboat").UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAdoptWarmPod(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	m := New(client, testLogger())
+	ctx := context.Background()
+
+	spec := jobSpec("")
+	if err := m.CreateWarmPod(ctx, spec); err != nil {
+		t.Fatal(err)
+	}
+	// Not ready yet: nothing to adopt.
+	if pod, err := m.AdoptWarmPod(ctx, jobSpec("j1")); err != nil || pod != nil {
+		t.Fatalf("AdoptWarmPod before ready = %v, %v; want nil, nil", pod, err)
+	}
+	markReady(t, client)
+
+	other := jobSpec("j1")
+	other.Image = "agent:next"
+	if pod, _ := m.AdoptWarmPod(ctx, other); pod != nil {
+		t.Error("adopted a warm pod running a different image")
+	}
+
+	adopter := jobSpec("j1")
+	adopter.ExtraLabels = map[string]string{"team": "payments"}
+	adopter.ExtraAnnotations = map[string]string{"example.com/owner": "alice"}
+	pod, err := m.AdoptWarmPod(ctx, adopter)
+	if err != nil || pod == nil {
+		t.Fatalf("AdoptWarmPod = %v, %v", pod, err)
+	}
+	if pod.Labels["team"] != "payments" || pod.Annotations["example.com/owner"] != "alice" {
+		t.Errorf("adopted pod lacks the bead's labels/annotations: %v %v", pod.Labels, pod.Annotations)
+	}
+	if _, warm := pod.Labels[LabelWarm]; warm {
+		t.Error("adopted pod still labeled warm")
+	}
+	if got := AgentPodName(pod); got != "job-gasboat-job-j1" {
+		t.Errorf("AgentPodName = %q, want job-gasboat-job-j1", got)
+	}
+	if pod.Annotations[AnnotationBeadID] != "bd-j1" {
+		t.Errorf("bead annotation = %q", pod.Annotations[AnnotationBeadID])
+	}
+
+	// The pool is now empty.
+	if pod, _ := m.AdoptWarmPod(ctx, jobSpec("j2")); pod != nil {
+		t.Errorf("adopted %s twice", pod.Name)
+	}
+}
+
+func TestCreateWarmPod_RejectsPersistentWorkspace(t *testing.T) {
+	m := New(fake.NewSimpleClientset(), testLogger())
+	spec := agentSpec("")
+	spec.WorkspaceStorage = &WorkspaceStorageSpec{Size: "10Gi"}
+	if err := m.CreateWarmPod(context.Background(), spec); err == nil {
+		t.Error("expected error pooling a pod with a workspace PVC")
+	}
+}
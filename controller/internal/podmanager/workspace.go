@@ -0,0 +1,158 @@
+package podmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Workspace claims belong to an agent bead, not a pod name: renaming an
+// agent or changing its role changes its pod name but keeps its bead. New
+// claims are named after the bead (WorkspaceClaimName) and every claim is
+// annotated with AnnotationBeadID, so a recreated pod reattaches its
+// workspace whatever the claim is called. Claims from before this scheme
+// are named {pod name}-workspace; they are adopted in place, since claims
+// can't be renamed.
+
+// WorkspaceClaimName returns the name of a new workspace claim for the
+// agent bead beadID.
+func WorkspaceClaimName(beadID string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, beadID)
+	return strings.Trim(name, "-.") + "-workspace"
+}
+
+// legacyClaimName is the claim name used before claims were named by bead.
+func legacyClaimName(podName string) string {
+	return podName + "-workspace"
+}
+
+// workspaceClaim returns the claim holding spec's workspace, creating one
+// if the agent has none. A claim annotated with the agent's bead ID is
+// reattached; an unclaimed legacy claim under the current pod name is
+// adopted.
+func (m *K8sManager) workspaceClaim(ctx context.Context, spec AgentPodSpec) (string, error) {
+	ws := spec.WorkspaceStorage
+	if ws.ClaimName != "" {
+		return ws.ClaimName, m.createPVC(ctx, spec, ws.ClaimName)
+	}
+	if spec.BeadID == "" {
+		name := legacyClaimName(spec.PodName())
+		return name, m.createPVC(ctx, spec, name)
+	}
+
+	claims, err := m.client.CoreV1().PersistentVolumeClaims(spec.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: LabelApp + "=" + LabelAppValue,
+	})
+	if err != nil {
+		return "", fmt.Errorf("listing workspace PVCs: %w", err)
+	}
+	var owned *corev1.PersistentVolumeClaim
+	var legacy *corev1.PersistentVolumeClaim
+	for i := range claims.Items {
+		pvc := &claims.Items[i]
+		if pvc.DeletionTimestamp != nil {
+			continue
+		}
+		switch {
+		case pvc.Annotations[AnnotationBeadID] == spec.BeadID:
+			// Prefer the bead-named claim should the agent own two.
+			if owned == nil || pvc.Name == WorkspaceClaimName(spec.BeadID) {
+				owned = pvc
+			}
+		case pvc.Name == legacyClaimName(spec.PodName()) && pvc.Annotations[AnnotationBeadID] == "":
+			legacy = pvc
+		}
+	}
+	if owned == nil && legacy != nil {
+		owned = legacy
+	}
+	if owned != nil {
+		if err := m.claimWorkspace(ctx, owned, spec); err != nil {
+			return "", err
+		}
+		return owned.Name, nil
+	}
+	name := WorkspaceClaimName(spec.BeadID)
+	return name, m.createPVC(ctx, spec, name)
+}
+
+// claimWorkspace marks pvc as the workspace of spec's agent: annotated with
+// its bead ID and labeled with its current name and role.
+func (m *K8sManager) claimWorkspace(ctx context.Context, pvc *corev1.PersistentVolumeClaim, spec AgentPodSpec) error {
+	labels := spec.Labels()
+	if pvc.Annotations[AnnotationBeadID] == spec.BeadID && pvc.Labels[LabelAgent] == labels[LabelAgent] &&
+		pvc.Labels[LabelRole] == labels[LabelRole] && pvc.Labels[LabelMode] == labels[LabelMode] {
+		return nil
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"labels":      labels,
+			"annotations": map[string]string{AnnotationBeadID: spec.BeadID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("marshal workspace patch: %w", err)
+	}
+	if _, err := m.client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("claiming workspace PVC %s: %w", pvc.Name, err)
+	}
+	m.logger.Info("reattached workspace PVC", "pvc", pvc.Name, "agent", spec.PodName(), "bead", spec.BeadID)
+	return nil
+}
+
+// AdoptLegacyWorkspaces annotates legacy workspace claims that carry no bead
+// ID with the bead of the agent named by their pod name. beads maps pod
+// names to bead IDs. Run while agents still have their old names, it lets
+// a later rename keep the workspace. It returns how many claims it adopted.
+func (m *K8sManager) AdoptLegacyWorkspaces(ctx context.Context, namespace string, beads map[string]string) (int, error) {
+	claims, err := m.client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: LabelApp + "=" + LabelAppValue,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("listing workspace PVCs: %w", err)
+	}
+	adopted := 0
+	for _, pvc := range claims.Items {
+		if pvc.DeletionTimestamp != nil || pvc.Annotations[AnnotationBeadID] != "" {
+			continue
+		}
+		podName, ok := strings.CutSuffix(pvc.Name, "-workspace")
+		beadID := beads[podName]
+		if !ok || beadID == "" {
+			continue
+		}
+		patch, err := json.Marshal(map[string]any{
+			"metadata": map[string]any{
+				"resourceVersion": pvc.ResourceVersion,
+				"annotations":     map[string]string{AnnotationBeadID: beadID},
+			},
+		})
+		if err != nil {
+			return adopted, fmt.Errorf("marshal workspace patch: %w", err)
+		}
+		_, err = m.client.CoreV1().PersistentVolumeClaims(namespace).Patch(ctx, pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			continue // changed or removed since listing; next pass
+		}
+		if err != nil {
+			return adopted, fmt.Errorf("adopting workspace PVC %s: %w", pvc.Name, err)
+		}
+		m.logger.Info("adopted legacy workspace PVC", "pvc", pvc.Name, "bead", beadID)
+		adopted++
+	}
+	return adopted, nil
+}
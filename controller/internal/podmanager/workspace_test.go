@@ -0,0 +1,124 @@
+package podmanager
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func workspaceSpec(agent, beadID string) AgentPodSpec {
+	return AgentPodSpec{
+		Mode: "crew", Project: "proj", Role: "dev", AgentName: agent, BeadID: beadID,
+		Image: "img:v1", Namespace: "ns",
+		WorkspaceStorage: &WorkspaceStorageSpec{Size: "1Gi"},
+	}
+}
+
+// podClaim returns the workspace claim the named pod mounts.
+func podClaim(t *testing.T, client *fake.Clientset, name string) string {
+	t.Helper()
+	pod, err := client.CoreV1().Pods("ns").Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range pod.Spec.Volumes {
+		if v.Name == VolumeWorkspace && v.PersistentVolumeClaim != nil {
+			return v.PersistentVolumeClaim.ClaimName
+		}
+	}
+	return ""
+}
+
+func legacyPVC(name string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name: name, Namespace: "ns",
+		Labels: map[string]string{LabelApp: LabelAppValue, LabelAgent: "alpha"},
+	}}
+}
+
+func TestWorkspaceClaimName(t *testing.T) {
+	for id, want := range map[string]string{
+		"kd-abc12":       "kd-abc12-workspace",
+		"Crew_Proj/Dev-": "crew-proj-dev-workspace",
+	} {
+		if got := WorkspaceClaimName(id); got != want {
+			t.Errorf("WorkspaceClaimName(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestCreateAgentPod_RenameReattachesWorkspace(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	mgr := New(client, testLogger())
+
+	if err := mgr.CreateAgentPod(ctx, workspaceSpec("alpha", "bd-1")); err != nil {
+		t.Fatal(err)
+	}
+	if got := podClaim(t, client, "crew-proj-dev-alpha"); got != "bd-1-workspace" {
+		t.Errorf("claim = %q, want bd-1-workspace", got)
+	}
+
+	// Renamed: a new pod name, the same bead and workspace.
+	renamed := workspaceSpec("beta", "bd-1")
+	if err := mgr.CreateAgentPod(ctx, renamed); err != nil {
+		t.Fatal(err)
+	}
+	if got := podClaim(t, client, "crew-proj-dev-beta"); got != "bd-1-workspace" {
+		t.Errorf("claim after rename = %q, want bd-1-workspace", got)
+	}
+	claims, _ := client.CoreV1().PersistentVolumeClaims("ns").List(ctx, metav1.ListOptions{})
+	if len(claims.Items) != 1 || claims.Items[0].Labels[LabelAgent] != "beta" {
+		t.Errorf("claims = %+v, want the one claim relabeled for beta", claims.Items)
+	}
+	if renamed.WorkspaceStorage.ClaimName != "" {
+		t.Error("CreateAgentPod modified the caller's workspace spec")
+	}
+}
+
+func TestCreateAgentPod_AdoptsLegacyWorkspace(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(legacyPVC("crew-proj-dev-alpha-workspace"))
+	mgr := New(client, testLogger())
+
+	if err := mgr.CreateAgentPod(ctx, workspaceSpec("alpha", "bd-1")); err != nil {
+		t.Fatal(err)
+	}
+	if got := podClaim(t, client, "crew-proj-dev-alpha"); got != "crew-proj-dev-alpha-workspace" {
+		t.Errorf("claim = %q, want the legacy claim", got)
+	}
+	pvc, err := client.CoreV1().PersistentVolumeClaims("ns").Get(ctx, "crew-proj-dev-alpha-workspace", metav1.GetOptions{})
+	if err != nil || pvc.Annotations[AnnotationBeadID] != "bd-1" {
+		t.Errorf("legacy claim not tied to its bead: %v, %v", pvc, err)
+	}
+}
+
+func TestAdoptLegacyWorkspaces_ThenRename(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		legacyPVC("crew-proj-dev-alpha-workspace"),
+		legacyPVC("crew-proj-dev-gone-workspace"),
+	)
+	mgr := New(client, testLogger())
+
+	n, err := mgr.AdoptLegacyWorkspaces(ctx, "ns", map[string]string{"crew-proj-dev-alpha": "bd-1"})
+	if err != nil || n != 1 {
+		t.Fatalf("adopted %d, err %v; want 1", n, err)
+	}
+	if err := mgr.CreateAgentPod(ctx, workspaceSpec("beta", "bd-1")); err != nil {
+		t.Fatal(err)
+	}
+	if got := podClaim(t, client, "crew-proj-dev-beta"); got != "crew-proj-dev-alpha-workspace" {
+		t.Errorf("claim after rename = %q, want the adopted legacy claim", got)
+	}
+	// An unadopted legacy claim of another name is not taken over.
+	if err := mgr.CreateAgentPod(ctx, workspaceSpec("gamma", "bd-2")); err != nil {
+		t.Fatal(err)
+	}
+	if got := podClaim(t, client, "crew-proj-dev-gamma"); got != "bd-2-workspace" {
+		t.Errorf("claim = %q, want a new bd-2-workspace", got)
+	}
+}
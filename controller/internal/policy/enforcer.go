@@ -0,0 +1,152 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+// ConfigGetter reads daemon config beads.
+type ConfigGetter interface {
+	GetConfig(ctx context.Context, key string) (*beadsapi.ConfigEntry, error)
+}
+
+// Enforcer evaluates the static policy and the latest runtime policy from
+// the ConfigKey config bead.
+type Enforcer struct {
+	static *Policy
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	runtime *Policy
+}
+
+// NewEnforcer creates an enforcer for the static policy (may be nil).
+func NewEnforcer(static *Policy, logger *slog.Logger) *Enforcer {
+	return &Enforcer{static: static, logger: logger}
+}
+
+// Refresh reloads the runtime policy from the ConfigKey config bead. A
+// missing bead clears it; an unreadable or invalid one keeps the last good
+// policy.
+func (e *Enforcer) Refresh(ctx context.Context, source ConfigGetter) error {
+	entry, err := source.GetConfig(ctx, ConfigKey)
+	var apiErr *beadsapi.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound:
+		entry = nil
+	case err != nil:
+		return fmt.Errorf("reading %s config: %w", ConfigKey, err)
+	}
+	var p *Policy
+	if entry != nil {
+		if p, err = Parse(entry.Value); err != nil {
+			return err
+		}
+	}
+	e.mu.Lock()
+	e.runtime = p
+	e.mu.Unlock()
+	return nil
+}
+
+// Admit evaluates spec, applying mutations to it. It returns an ErrDenied
+// error when a deny rule is violated.
+func (e *Enforcer) Admit(spec *podmanager.AgentPodSpec) error {
+	e.mu.RLock()
+	runtime := e.runtime
+	e.mu.RUnlock()
+
+	res := e.static.Evaluate(spec)
+	rt := runtime.Evaluate(spec)
+	res.Denials = append(res.Denials, rt.Denials...)
+	res.Warnings = append(res.Warnings, rt.Warnings...)
+	res.Mutations = append(res.Mutations, rt.Mutations...)
+
+	pod := spec.PodName()
+	for _, m := range res.Mutations {
+		e.logger.Info("pod policy mutated spec", "pod", pod, "mutation", m)
+	}
+	for _, w := range res.Warnings {
+		e.logger.Warn("pod policy warning", "pod", pod, "violation", w)
+	}
+	if err := res.Err(); err != nil {
+		e.logger.Warn("pod policy denied spec", "pod", pod, "violations", res.Denials)
+		return err
+	}
+	return nil
+}
+
+// Manager wraps m so that pods are only created for admitted specs.
+func (e *Enforcer) Manager(m podmanager.Manager) podmanager.Manager {
+	return &policyManager{next: m, enf: e}
+}
+
+type policyManager struct {
+	next podmanager.Manager
+	enf  *Enforcer
+}
+
+func (p *policyManager) CreateAgentPod(ctx context.Context, spec podmanager.AgentPodSpec) error {
+	if err := p.enf.Admit(&spec); err != nil {
+		return err
+	}
+	return p.next.CreateAgentPod(ctx, spec)
+}
+
+func (p *policyManager) DeleteAgentPod(ctx context.Context, name, namespace string) error {
+	return p.next.DeleteAgentPod(ctx, name, namespace)
+}
+
+// DeleteClusterPod forwards cluster-specific deletes (podmanager.MultiCluster)
+// so wrapping a multi-cluster manager doesn't hide them from the reconciler.
+func (p *policyManager) DeleteClusterPod(ctx context.Context, cluster, name, namespace string) error {
+	if d, ok := p.next.(interface {
+		DeleteClusterPod(ctx context.Context, cluster, name, namespace string) error
+	}); ok {
+		return d.DeleteClusterPod(ctx, cluster, name, namespace)
+	}
+	return p.next.DeleteAgentPod(ctx, name, namespace)
+}
+
+// AnnotateAgentPod forwards pod annotation (podmanager.K8sManager,
+// podmanager.MultiCluster), which pod adoption and the termination grace
+// window need.
+func (p *policyManager) AnnotateAgentPod(ctx context.Context, pod *corev1.Pod, annotations map[string]string) error {
+	a, ok := p.next.(interface {
+		AnnotateAgentPod(ctx context.Context, pod *corev1.Pod, annotations map[string]string) error
+	})
+	if !ok {
+		return errors.New("pod manager can't annotate pods")
+	}
+	return a.AnnotateAgentPod(ctx, pod, annotations)
+}
+
+// AdoptLegacyWorkspaces forwards workspace claim adoption
+// (podmanager.K8sManager, podmanager.MultiCluster). A manager keeping no
+// workspace claims has none to adopt.
+func (p *policyManager) AdoptLegacyWorkspaces(ctx context.Context, namespace string, beads map[string]string) (int, error) {
+	a, ok := p.next.(interface {
+		AdoptLegacyWorkspaces(ctx context.Context, namespace string, beads map[string]string) (int, error)
+	})
+	if !ok {
+		return 0, nil
+	}
+	return a.AdoptLegacyWorkspaces(ctx, namespace, beads)
+}
+
+func (p *policyManager) ListAgentPods(ctx context.Context, namespace string, labelSelector map[string]string) ([]corev1.Pod, error) {
+	return p.next.ListAgentPods(ctx, namespace, labelSelector)
+}
+
+func (p *policyManager) GetAgentPod(ctx context.Context, name, namespace string) (*corev1.Pod, error) {
+	return p.next.GetAgentPod(ctx, name, namespace)
+}
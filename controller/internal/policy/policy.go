@@ -0,0 +1,226 @@
+// Package policy admits or rejects agent pod specs before they are created.
+//
+// A Policy is a list of declarative rules evaluated against the rendered
+// AgentPodSpec and the agent bead's metadata: allowed and denied images,
+// allowed namespaces, and required resource limits. Rules may fill in
+// missing limits (mutation) before checking them. Violations of "deny"
+// rules reject the spec; "warn" rules are only logged.
+//
+// Rules come from the controller's configuration (POD_POLICY_RULES) and the
+// daemon config bead ConfigKey, which operators can change at runtime.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+// ConfigKey is the daemon config bead holding runtime policy rules.
+const ConfigKey = "pod-policy"
+
+// Rule actions.
+const (
+	ActionDeny = "deny"
+	ActionWarn = "warn"
+)
+
+// ErrDenied wraps the error returned for specs that violate a deny rule.
+var ErrDenied = errors.New("denied by pod policy")
+
+// Rule is one admission rule. Empty selectors match every agent; empty
+// checks are skipped.
+type Rule struct {
+	Name string `json:"name"`
+	// Action is ActionDeny (default) or ActionWarn.
+	Action string `json:"action,omitempty"`
+
+	// Selectors.
+	Projects []string          `json:"projects,omitempty"`
+	Roles    []string          `json:"roles,omitempty"`
+	Modes    []string          `json:"modes,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"` // agent bead metadata that must match exactly
+
+	// AllowedImages and DeniedImages are path.Match globs, e.g.
+	// "ghcr.io/groblegark/*" ("*" doesn't cross "/").
+	AllowedImages []string `json:"allowed_images,omitempty"`
+	DeniedImages  []string `json:"denied_images,omitempty"`
+
+	AllowedNamespaces []string `json:"allowed_namespaces,omitempty"`
+
+	// RequireLimits names resources ("cpu", "memory") that must have a
+	// limit. DefaultLimits is filled in for missing limits first.
+	RequireLimits []string          `json:"require_limits,omitempty"`
+	DefaultLimits map[string]string `json:"default_limits,omitempty"`
+}
+
+// Policy is an ordered set of rules.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Parse decodes and validates a policy. Empty input is an empty policy.
+func Parse(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return p, nil
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("parsing pod policy: %w", err)
+	}
+	for i, r := range p.Rules {
+		name := r.Name
+		if name == "" {
+			name = fmt.Sprintf("rules[%d]", i)
+		}
+		switch r.Action {
+		case "", ActionDeny, ActionWarn:
+		default:
+			return nil, fmt.Errorf("pod policy %s: unknown action %q", name, r.Action)
+		}
+		for _, pattern := range slices.Concat(r.AllowedImages, r.DeniedImages) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("pod policy %s: image pattern %q: %w", name, pattern, err)
+			}
+		}
+		for res, q := range r.DefaultLimits {
+			if _, err := resource.ParseQuantity(q); err != nil {
+				return nil, fmt.Errorf("pod policy %s: default limit %s: %w", name, res, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Result is the outcome of evaluating a spec.
+type Result struct {
+	Denials   []string // violations of deny rules
+	Warnings  []string // violations of warn rules
+	Mutations []string // default limits filled in
+}
+
+// Err returns an ErrDenied error listing the denials, or nil.
+func (r Result) Err() error {
+	if len(r.Denials) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDenied, strings.Join(r.Denials, "; "))
+}
+
+// Evaluate checks spec against every matching rule, filling in default
+// limits on the way. spec.Metadata is the agent bead metadata rules select
+// on.
+func (p *Policy) Evaluate(spec *podmanager.AgentPodSpec) Result {
+	var res Result
+	if p == nil {
+		return res
+	}
+	for i, rule := range p.Rules {
+		if !rule.matches(spec) {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("rules[%d]", i)
+		}
+		res.Mutations = append(res.Mutations, rule.mutate(spec, name)...)
+		for _, v := range rule.check(spec) {
+			msg := name + ": " + v
+			if rule.Action == ActionWarn {
+				res.Warnings = append(res.Warnings, msg)
+			} else {
+				res.Denials = append(res.Denials, msg)
+			}
+		}
+	}
+	return res
+}
+
+func (r *Rule) matches(spec *podmanager.AgentPodSpec) bool {
+	if len(r.Projects) > 0 && !slices.Contains(r.Projects, spec.Project) {
+		return false
+	}
+	if len(r.Roles) > 0 && !slices.Contains(r.Roles, spec.Role) {
+		return false
+	}
+	if len(r.Modes) > 0 && !slices.Contains(r.Modes, spec.Mode) {
+		return false
+	}
+	for k, v := range r.Metadata {
+		if spec.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// mutate fills in the rule's default limits where spec has none.
+func (r *Rule) mutate(spec *podmanager.AgentPodSpec, name string) []string {
+	var applied []string
+	for res, q := range r.DefaultLimits {
+		rn := corev1.ResourceName(res)
+		resources := effectiveResources(spec)
+		if _, ok := resources.Limits[rn]; ok {
+			continue
+		}
+		// Copy before writing: specs may share defaults with other specs.
+		resources = resources.DeepCopy()
+		if resources.Limits == nil {
+			resources.Limits = corev1.ResourceList{}
+		}
+		resources.Limits[rn] = resource.MustParse(q)
+		spec.Resources = resources
+		applied = append(applied, fmt.Sprintf("%s: set %s limit to %s", name, res, q))
+	}
+	slices.Sort(applied)
+	return applied
+}
+
+// check returns the rule's violations.
+func (r *Rule) check(spec *podmanager.AgentPodSpec) []string {
+	var violations []string
+	if spec.Image != "" {
+		if len(r.AllowedImages) > 0 && !matchAny(r.AllowedImages, spec.Image) {
+			violations = append(violations, fmt.Sprintf("image %s is not allowed", spec.Image))
+		}
+		if matchAny(r.DeniedImages, spec.Image) {
+			violations = append(violations, fmt.Sprintf("image %s is forbidden", spec.Image))
+		}
+	}
+	if len(r.AllowedNamespaces) > 0 && !slices.Contains(r.AllowedNamespaces, spec.Namespace) {
+		violations = append(violations, fmt.Sprintf("namespace %s is not allowed", spec.Namespace))
+	}
+	resources := effectiveResources(spec)
+	for _, res := range r.RequireLimits {
+		if q, ok := resources.Limits[corev1.ResourceName(res)]; !ok || q.IsZero() {
+			violations = append(violations, fmt.Sprintf("missing %s limit", res))
+		}
+	}
+	return violations
+}
+
+// effectiveResources returns the resources the pod will be created with:
+// spec.Resources, or the pod manager's defaults when unset.
+func effectiveResources(spec *podmanager.AgentPodSpec) *corev1.ResourceRequirements {
+	if spec.Resources != nil {
+		return spec.Resources
+	}
+	return podmanager.DefaultPodDefaults(spec.Mode).Resources
+}
+
+func matchAny(patterns []string, image string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, image); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+func mustParse(t *testing.T, s string) *Policy {
+	t.Helper()
+	p, err := Parse([]byte(s))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return p
+}
+
+func testSpec() podmanager.AgentPodSpec {
+	return podmanager.AgentPodSpec{
+		Project: "gasboat", Mode: "crew", Role: "crew", AgentName: "k8s",
+		Image: "ghcr.io/groblegark/gasboat/agent:latest", Namespace: "gasboat",
+		Metadata: map[string]string{"tier": "prod"},
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	for name, in := range map[string]string{
+		"bad json":     `{"rules":`,
+		"bad action":   `{"rules":[{"action":"block"}]}`,
+		"bad pattern":  `{"rules":[{"denied_images":["["]}]}`,
+		"bad quantity": `{"rules":[{"default_limits":{"cpu":"lots"}}]}`,
+	} {
+		if _, err := Parse([]byte(in)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if p, err := Parse(nil); err != nil || len(p.Rules) != 0 {
+		t.Errorf("Parse(nil) = %v, %v", p, err)
+	}
+}
+
+func TestEvaluate_Images(t *testing.T) {
+	p := mustParse(t, `{"rules":[
+		{"name":"registry","allowed_images":["ghcr.io/groblegark/*/*"]},
+		{"name":"no-latest","action":"warn","denied_images":["*:latest","*/*/*/*:latest"]}
+	]}`)
+	spec := testSpec()
+	res := p.Evaluate(&spec)
+	if len(res.Denials) != 0 || len(res.Warnings) != 1 {
+		t.Fatalf("Evaluate = %+v", res)
+	}
+
+	spec.Image = "docker.io/evil/miner:1"
+	res = p.Evaluate(&spec)
+	if err := res.Err(); !errors.Is(err, ErrDenied) {
+		t.Fatalf("Err = %v, want ErrDenied", err)
+	}
+}
+
+func TestEvaluate_Selectors(t *testing.T) {
+	p := mustParse(t, `{"rules":[
+		{"name":"prod-ns","projects":["gasboat"],"metadata":{"tier":"prod"},"allowed_namespaces":["gasboat-prod"]},
+		{"name":"other","roles":["qa"],"allowed_namespaces":["nowhere"]}
+	]}`)
+	spec := testSpec()
+	res := p.Evaluate(&spec)
+	if len(res.Denials) != 1 || res.Denials[0] != "prod-ns: namespace gasboat is not allowed" {
+		t.Fatalf("Denials = %v", res.Denials)
+	}
+
+	spec.Metadata = nil
+	if res := p.Evaluate(&spec); res.Err() != nil {
+		t.Errorf("rule applied without matching metadata: %v", res.Err())
+	}
+}
+
+func TestEvaluate_LimitsMutation(t *testing.T) {
+	p := mustParse(t, `{"rules":[{"name":"limits","require_limits":["cpu","nvidia.com/gpu"],"default_limits":{"cpu":"2"}}]}`)
+	shared := &corev1.ResourceRequirements{Requests: corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("500m"),
+	}}
+	spec := testSpec()
+	spec.Resources = shared
+
+	res := p.Evaluate(&spec)
+	if len(res.Mutations) != 1 {
+		t.Errorf("Mutations = %v", res.Mutations)
+	}
+	if got := spec.Resources.Limits[corev1.ResourceCPU]; got.String() != "2" {
+		t.Errorf("cpu limit = %s, want 2", got.String())
+	}
+	if shared.Limits != nil {
+		t.Error("mutation wrote through to shared resources")
+	}
+	if len(res.Denials) != 1 || res.Denials[0] != "limits: missing nvidia.com/gpu limit" {
+		t.Errorf("Denials = %v", res.Denials)
+	}
+
+	// Unset resources render the default limits.
+	spec = testSpec()
+	p = mustParse(t, `{"rules":[{"require_limits":["cpu","memory"]}]}`)
+	if res := p.Evaluate(&spec); res.Err() != nil {
+		t.Errorf("default resources: %v", res.Err())
+	}
+}
+
+type fakeConfigs struct {
+	value string
+	err   error
+}
+
+func (f *fakeConfigs) GetConfig(_ context.Context, key string) (*beadsapi.ConfigEntry, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.value == "" {
+		return nil, &beadsapi.APIError{StatusCode: http.StatusNotFound, Message: "not found"}
+	}
+	return &beadsapi.ConfigEntry{Key: key, Value: json.RawMessage(f.value)}, nil
+}
+
+type recordingManager struct {
+	podmanager.Manager
+	created []podmanager.AgentPodSpec
+}
+
+func (m *recordingManager) CreateAgentPod(_ context.Context, spec podmanager.AgentPodSpec) error {
+	m.created = append(m.created, spec)
+	return nil
+}
+
+func TestEnforcer_RuntimePolicy(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelError}))
+	enf := NewEnforcer(nil, logger)
+	next := &recordingManager{}
+	pods := enf.Manager(next)
+	ctx := context.Background()
+
+	src := &fakeConfigs{value: `{"rules":[{"denied_images":["ghcr.io/groblegark/gasboat/*"]}]}`}
+	if err := enf.Refresh(ctx, src); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if err := pods.CreateAgentPod(ctx, testSpec()); !errors.Is(err, ErrDenied) {
+		t.Fatalf("CreateAgentPod = %v, want ErrDenied", err)
+	}
+
+	// Daemon errors keep the last policy.
+	src.err = &beadsapi.APIError{StatusCode: http.StatusServiceUnavailable, Message: "unavailable"}
+	if err := enf.Refresh(ctx, src); err == nil {
+		t.Fatal("expected a refresh error")
+	}
+	if err := pods.CreateAgentPod(ctx, testSpec()); !errors.Is(err, ErrDenied) {
+		t.Fatalf("CreateAgentPod after failed refresh = %v, want ErrDenied", err)
+	}
+
+	// Deleting the config bead lifts the policy.
+	src.err, src.value = nil, ""
+	if err := enf.Refresh(ctx, src); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if err := pods.CreateAgentPod(ctx, testSpec()); err != nil {
+		t.Fatalf("CreateAgentPod: %v", err)
+	}
+	if len(next.created) != 1 {
+		t.Errorf("created %d pods, want 1", len(next.created))
+	}
+}
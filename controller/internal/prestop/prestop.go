@@ -0,0 +1,63 @@
+// Package prestop renders the preStop hook of agent pods: a shell script
+// run in the agent container when its pod is deleted, before the agent is
+// sent SIGTERM. Kubernetes counts the hook against the pod's termination
+// grace period.
+//
+// Role and project beads set the hook as a text/template (see
+// beadsapi.PreStopField), so shutdown behavior can change without a
+// controller release. Templates see the fields of Vars:
+//
+//	gb yield --checkpoint --agent-id {{.BeadID}} --note "pod stopping" || true
+//	BEADS_HTTP_ADDR={{.DaemonURL}} gb yield --checkpoint --note "{{.Role}} stopping"; git push || true
+//
+// Values are substituted as is; they are IDs, names and addresses, which
+// need no shell quoting. A template of None runs no hook.
+package prestop
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// Default is the hook of agents whose role and project set none: a last
+// checkpoint, in case the agent didn't record one while it was told to
+// wind down. Failure doesn't hold up the shutdown.
+const Default = `gb yield --checkpoint --agent-id {{.BeadID}} --note "pod stopping" || true`
+
+// None is the template that disables the hook.
+const None = "none"
+
+// Vars are the values a template can use.
+type Vars struct {
+	BeadID    string // Agent bead ID
+	DaemonURL string // Beads daemon HTTP address (BEADS_HTTP_ADDR)
+	Project   string
+	Mode      string
+	Role      string
+	AgentName string
+}
+
+// Render renders the hook template text with vars. It returns "" for None
+// or a template rendering only whitespace.
+func Render(text string, vars Vars) (string, error) {
+	if strings.TrimSpace(text) == None {
+		return "", nil
+	}
+	t, err := template.New("prestop").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("prestop: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, vars); err != nil {
+		return "", fmt.Errorf("prestop: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Validate reports whether text is a template Render accepts: it parses
+// and uses only the fields of Vars.
+func Validate(text string) error {
+	_, err := Render(text, Vars{})
+	return err
+}
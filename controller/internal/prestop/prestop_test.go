@@ -0,0 +1,35 @@
+package prestop
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	vars := Vars{BeadID: "kd-abc", DaemonURL: "http://beads:8080", Project: "gasboat", Mode: "crew", Role: "dev", AgentName: "max"}
+	tests := []struct {
+		text, want string
+	}{
+		{Default, `gb yield --checkpoint --agent-id kd-abc --note "pod stopping" || true`},
+		{"curl -fsS {{.DaemonURL}}/health # {{.Project}}/{{.Role}}/{{.AgentName}} ({{.Mode}})", "curl -fsS http://beads:8080/health # gasboat/dev/max (crew)"},
+		{None, ""},
+		{"  \n", ""},
+	}
+	for _, tt := range tests {
+		got, err := Render(tt.text, vars)
+		if err != nil || got != tt.want {
+			t.Errorf("Render(%q) = %q, %v; want %q", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(Default); err != nil {
+		t.Errorf("Default: %v", err)
+	}
+	for _, text := range []string{"echo {{.BeadID", "echo {{.Namespace}}"} {
+		if err := Validate(text); err == nil || !strings.HasPrefix(err.Error(), "prestop: ") {
+			t.Errorf("Validate(%q) = %v, want a prestop error", text, err)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// Package rbacreconciler gives each project's agents their own narrowly
+// scoped identity (least-privilege mode).
+//
+// For every project bead without a service_account override, the reconciler
+// keeps a ServiceAccount, Role, and RoleBinding named "{project}-agent" in
+// the agent namespace of each agent cluster. Agent pods of the project run
+// as that ServiceAccount instead of the shared agent ServiceAccount.
+//
+// Objects of projects that go away are left in place: running pods may
+// still use them.
+package rbacreconciler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+// DefaultRules lets agents observe, but not change, workloads in their
+// namespace. The controller must hold every permission it grants.
+var DefaultRules = []rbacv1.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "watch"}},
+	{APIGroups: []string{""}, Resources: []string{"pods/log"}, Verbs: []string{"get"}},
+	{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"get", "list"}},
+}
+
+// ServiceAccountName returns the ServiceAccount (and Role and RoleBinding)
+// name for a project's agents.
+func ServiceAccountName(project string) string {
+	return project + "-agent"
+}
+
+// Reconciler keeps per-project agent RBAC objects in place.
+type Reconciler struct {
+	clients   []kubernetes.Interface
+	namespace string
+	rules     []rbacv1.PolicyRule
+	logger    *slog.Logger
+}
+
+// New creates a reconciler granting rules (DefaultRules if empty) to each
+// project's agents in namespace on every one of clients.
+func New(clients []kubernetes.Interface, namespace string, rules []rbacv1.PolicyRule, logger *slog.Logger) *Reconciler {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	return &Reconciler{
+		clients:   clients,
+		namespace: namespace,
+		rules:     rules,
+		logger:    logger,
+	}
+}
+
+// Reconcile ensures the ServiceAccount, Role, and RoleBinding of every
+// project that doesn't name its own ServiceAccount.
+func (r *Reconciler) Reconcile(ctx context.Context, projects map[string]config.ProjectCacheEntry) error {
+	var errs []error
+	for project, entry := range projects {
+		if entry.ServiceAccount != "" {
+			continue
+		}
+		name := ServiceAccountName(project)
+		if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+			r.logger.Warn("skipping agent RBAC for project with invalid name",
+				"project", project, "reason", msgs[0])
+			continue
+		}
+		for _, client := range r.clients {
+			if err := r.ensure(ctx, client, project, name); err != nil {
+				errs = append(errs, fmt.Errorf("project %s: %w", project, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("agent RBAC reconciliation had %d errors: %w", len(errs), errs[0])
+	}
+	return nil
+}
+
+func (r *Reconciler) meta(project, name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: r.namespace,
+		Labels: map[string]string{
+			podmanager.LabelApp:     podmanager.LabelAppValue,
+			podmanager.LabelProject: project,
+			"gasboat.io/managed-by": "controller",
+		},
+	}
+}
+
+// ensure creates the project's ServiceAccount, Role, and RoleBinding, and
+// updates the Role when its rules changed.
+func (r *Reconciler) ensure(ctx context.Context, client kubernetes.Interface, project, name string) error {
+	sas := client.CoreV1().ServiceAccounts(r.namespace)
+	if _, err := sas.Get(ctx, name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		sa := &corev1.ServiceAccount{ObjectMeta: r.meta(project, name)}
+		if _, err := sas.Create(ctx, sa, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("creating ServiceAccount %s: %w", name, err)
+		}
+		r.logger.Info("created agent ServiceAccount", "name", name, "project", project)
+	} else if err != nil {
+		return fmt.Errorf("getting ServiceAccount %s: %w", name, err)
+	}
+
+	roles := client.RbacV1().Roles(r.namespace)
+	role, err := roles.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		role = &rbacv1.Role{ObjectMeta: r.meta(project, name), Rules: r.rules}
+		if _, err := roles.Create(ctx, role, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("creating Role %s: %w", name, err)
+		}
+		r.logger.Info("created agent Role", "name", name, "project", project)
+	case err != nil:
+		return fmt.Errorf("getting Role %s: %w", name, err)
+	case !reflect.DeepEqual(role.Rules, r.rules):
+		role.Rules = r.rules
+		if _, err := roles.Update(ctx, role, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("updating Role %s: %w", name, err)
+		}
+		r.logger.Info("updated agent Role rules", "name", name, "project", project)
+	}
+
+	bindings := client.RbacV1().RoleBindings(r.namespace)
+	if _, err := bindings.Get(ctx, name, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		binding := &rbacv1.RoleBinding{
+			ObjectMeta: r.meta(project, name),
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: name},
+			Subjects: []rbacv1.Subject{{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      name,
+				Namespace: r.namespace,
+			}},
+		}
+		if _, err := bindings.Create(ctx, binding, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("creating RoleBinding %s: %w", name, err)
+		}
+		r.logger.Info("created agent RoleBinding", "name", name, "project", project)
+	} else if err != nil {
+		return fmt.Errorf("getting RoleBinding %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package rbacreconciler
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/config"
+)
+
+func newTestReconciler(rules []rbacv1.PolicyRule) (*Reconciler, *fake.Clientset) {
+	client := fake.NewSimpleClientset()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	return New([]kubernetes.Interface{client}, "test-ns", rules, logger), client
+}
+
+func TestReconcile_CreatesProjectRBAC(t *testing.T) {
+	r, client := newTestReconciler(nil)
+	projects := map[string]config.ProjectCacheEntry{
+		"gasboat": {},
+		"custom":  {ServiceAccount: "custom-sa"},
+	}
+
+	if err := r.Reconcile(context.Background(), projects); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.CoreV1().ServiceAccounts("test-ns").Get(ctx, "gasboat-agent", metav1.GetOptions{}); err != nil {
+		t.Errorf("ServiceAccount: %v", err)
+	}
+	role, err := client.RbacV1().Roles("test-ns").Get(ctx, "gasboat-agent", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Role: %v", err)
+	}
+	if len(role.Rules) != len(DefaultRules) {
+		t.Errorf("Role rules = %v, want DefaultRules", role.Rules)
+	}
+	binding, err := client.RbacV1().RoleBindings("test-ns").Get(ctx, "gasboat-agent", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("RoleBinding: %v", err)
+	}
+	if binding.RoleRef.Name != "gasboat-agent" || len(binding.Subjects) != 1 ||
+		binding.Subjects[0].Kind != "ServiceAccount" || binding.Subjects[0].Name != "gasboat-agent" {
+		t.Errorf("RoleBinding = %+v", binding)
+	}
+	if binding.Labels["gasboat.io/managed-by"] != "controller" {
+		t.Errorf("labels = %v", binding.Labels)
+	}
+
+	// Projects with their own ServiceAccount get nothing.
+	if _, err := client.CoreV1().ServiceAccounts("test-ns").Get(ctx, "custom-agent", metav1.GetOptions{}); err == nil {
+		t.Error("created a ServiceAccount for a project with a service_account override")
+	}
+}
+
+func TestReconcile_UpdatesRoleRules(t *testing.T) {
+	r, client := newTestReconciler(nil)
+	projects := map[string]config.ProjectCacheEntry{"gasboat": {}}
+	if err := r.Reconcile(context.Background(), projects); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	r.rules = []rbacv1.PolicyRule{{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: []string{"get"}}}
+	if err := r.Reconcile(context.Background(), projects); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	role, _ := client.RbacV1().Roles("test-ns").Get(context.Background(), "gasboat-agent", metav1.GetOptions{})
+	if len(role.Rules) != 1 || role.Rules[0].Resources[0] != "configmaps" {
+		t.Errorf("Role rules = %v, want the new rules", role.Rules)
+	}
+}
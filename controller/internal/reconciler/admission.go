@@ -0,0 +1,70 @@
+package reconciler
+
+import (
+	"context"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+// WaitingCapacityState is the agent_state of beads whose pods are deferred
+// because no node has room for them.
+const WaitingCapacityState = "waiting_capacity"
+
+// CapacitySource snapshots schedulable cluster capacity
+// (podmanager.MultiCluster).
+type CapacitySource interface {
+	Capacity(ctx context.Context) (*podmanager.Capacity, error)
+}
+
+// SetCapacitySource enables capacity-aware admission: before creating a pod
+// the reconciler checks it fits on some node and otherwise defers it,
+// marking the bead waiting_capacity, instead of leaving a Pending pod.
+func (r *Reconciler) SetCapacitySource(c CapacitySource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.capacity = c
+}
+
+// admission admits pod creations against one capacity snapshot per pass.
+type admission struct {
+	r        *Reconciler
+	snapshot *podmanager.Capacity
+	taken    bool
+}
+
+// admit reserves room for spec's pod, returning why it does not fit, or
+// "" when it may be created now. Capacity is snapshotted on first use; if
+// that fails, pods are admitted so an API hiccup can't stall all creation.
+func (a *admission) admit(ctx context.Context, spec podmanager.AgentPodSpec) string {
+	r := a.r
+	if r.capacity == nil {
+		return ""
+	}
+	if !a.taken {
+		a.taken = true
+		snap, err := r.capacity.Capacity(ctx)
+		if err != nil {
+			r.logger.Warn("cluster capacity unavailable, admitting pods without a capacity check", "error", err)
+		}
+		a.snapshot = snap
+	}
+	if a.snapshot == nil {
+		return ""
+	}
+	return a.snapshot.Reserve(spec)
+}
+
+// markWaiting marks bead waiting_capacity, once while it stays deferred.
+func (r *Reconciler) markWaiting(ctx context.Context, name string, bead beadsapi.AgentBead) {
+	if r.waiting[name] {
+		return
+	}
+	if u, ok := r.lister.(beadFieldUpdater); ok {
+		if err := u.UpdateBeadFields(ctx, bead.ID, map[string]string{"agent_state": WaitingCapacityState}); err != nil {
+			r.logger.Warn("failed to mark bead waiting for capacity", "bead", bead.ID, "error", err)
+			return
+		}
+	}
+	r.waiting[name] = true
+}
@@ -0,0 +1,105 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+// nodeCapacity is a CapacitySource backed by a fake cluster with one ready
+// node that has room for the given number of pods.
+type nodeCapacity struct {
+	mgr   *podmanager.K8sManager
+	err   error
+	calls int
+}
+
+func newNodeCapacity(pods string) *nodeCapacity {
+	client := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status: corev1.NodeStatus{
+			Allocatable: corev1.ResourceList{corev1.ResourcePods: resource.MustParse(pods)},
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	})
+	return &nodeCapacity{mgr: podmanager.New(client, testLogger())}
+}
+
+func (n *nodeCapacity) Capacity(ctx context.Context) (*podmanager.Capacity, error) {
+	n.calls++
+	if n.err != nil {
+		return nil, n.err
+	}
+	return n.mgr.Capacity(ctx)
+}
+
+func twoBeads() []beadsapi.AgentBead {
+	return []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+		{ID: "bd-2", Project: "proj", Mode: "crew", Role: "dev", AgentName: "beta"},
+	}
+}
+
+func TestReconcile_DefersPodsWithoutCapacity(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: twoBeads()}}
+	mgr := &mockManager{}
+	capacity := newNodeCapacity("1")
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("img"))
+	r.SetCapacitySource(capacity)
+	ctx := context.Background()
+
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 1 {
+		t.Fatalf("created %d pods, want 1 (node has one slot)", len(mgr.created))
+	}
+	if capacity.calls != 1 {
+		t.Errorf("capacity snapshots = %d, want one per pass", capacity.calls)
+	}
+	deferred := "bd-2"
+	if mgr.created[0].AgentName == "beta" {
+		deferred = "bd-1"
+	}
+	if got := lister.updates[deferred]["agent_state"]; got != WaitingCapacityState {
+		t.Errorf("deferred bead agent_state = %q, want %q", got, WaitingCapacityState)
+	}
+
+	// Still no room: the bead is not re-marked.
+	lister.updates = nil
+	mgr.created = nil
+	mgr.pods = nil
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, marked := lister.updates[deferred]; marked {
+		t.Error("waiting bead marked again on a later pass")
+	}
+}
+
+func TestReconcile_AdmitsWhenCapacityUnavailable(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: twoBeads()}}
+	mgr := &mockManager{}
+	capacity := newNodeCapacity("0")
+	capacity.err = errors.New("forbidden")
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("img"))
+	r.SetCapacitySource(capacity)
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 2 {
+		t.Errorf("created %d pods, want 2 when capacity can't be read", len(mgr.created))
+	}
+	if lister.updates != nil {
+		t.Errorf("unexpected bead updates: %v", lister.updates)
+	}
+}
@@ -0,0 +1,100 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+// agentSpawner is implemented by bead listers that can also create agent
+// beads (beadsapi.Client).
+type agentSpawner interface {
+	SpawnAgentWith(ctx context.Context, req beadsapi.SpawnAgentRequest) (string, error)
+}
+
+// podAnnotator is implemented by pod managers that can annotate running
+// pods (podmanager.K8sManager, podmanager.MultiCluster).
+type podAnnotator interface {
+	AnnotateAgentPod(ctx context.Context, pod *corev1.Pod, annotations map[string]string) error
+}
+
+// AdoptPods takes over agent pods the controller did not create, such as
+// those of a manual deployment, so the next pass does not delete them as
+// orphans. A pod carrying all identity labels (project, role, agent, mode)
+// but no agent bead gets one created from its labels, pinned to the pod's
+// image so it isn't restarted as drifted. Pods of known agents whose
+// bead-id annotation is missing or stale have it back-filled. It returns
+// the number of pods adopted and stops at the first failure.
+func (r *Reconciler) AdoptPods(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spawner, ok := r.lister.(agentSpawner)
+	if !ok {
+		return 0, errors.New("bead lister can't create agent beads")
+	}
+	annotator, ok := r.pods.(podAnnotator)
+	if !ok {
+		return 0, errors.New("pod manager can't annotate pods")
+	}
+
+	desired, actual, _, err := r.observe(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	adopted := 0
+	for _, name := range sortedNames(actual) {
+		pod := actual[name]
+		if pod.DeletionTimestamp != nil || isTerminal(&pod) || !hasIdentityLabels(&pod) {
+			continue
+		}
+		project := pod.Labels[podmanager.LabelProject]
+		if r.projectPaused(project) {
+			continue
+		}
+		bead, ok := desired[name]
+		if !ok {
+			id, err := spawner.SpawnAgentWith(ctx, beadsapi.SpawnAgentRequest{
+				AgentName: pod.Labels[podmanager.LabelAgent],
+				Project:   project,
+				Role:      pod.Labels[podmanager.LabelRole],
+				Mode:      pod.Labels[podmanager.LabelMode],
+				Image:     podmanager.AgentImage(&pod),
+			})
+			if err != nil {
+				return adopted, fmt.Errorf("adopting pod %s: %w", pod.Name, err)
+			}
+			bead = beadsapi.AgentBead{ID: id}
+			r.logger.Info("created agent bead for adopted pod", "pod", pod.Name, "bead", id)
+		}
+		if pod.Annotations[podmanager.AnnotationBeadID] == bead.ID {
+			continue
+		}
+		if err := annotator.AnnotateAgentPod(ctx, &pod, map[string]string{
+			podmanager.AnnotationBeadID: bead.ID,
+		}); err != nil {
+			return adopted, fmt.Errorf("annotating adopted pod %s: %w", pod.Name, err)
+		}
+		r.logger.Info("adopted agent pod", "pod", pod.Name, "bead", bead.ID,
+			"previous_bead", pod.Annotations[podmanager.AnnotationBeadID])
+		adopted++
+	}
+	return adopted, nil
+}
+
+// hasIdentityLabels reports whether pod names its agent with all of the
+// labels its bead is matched on.
+func hasIdentityLabels(pod *corev1.Pod) bool {
+	for _, l := range []string{podmanager.LabelProject, podmanager.LabelRole, podmanager.LabelAgent, podmanager.LabelMode} {
+		if pod.Labels[l] == "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/faults"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/policy"
+)
+
+// spawningLister is a mockLister whose spawned agents join its beads.
+type spawningLister struct {
+	mockLister
+	spawned []beadsapi.SpawnAgentRequest
+}
+
+func (m *spawningLister) SpawnAgentWith(_ context.Context, req beadsapi.SpawnAgentRequest) (string, error) {
+	m.spawned = append(m.spawned, req)
+	id := "bd-" + req.AgentName
+	m.beads = append(m.beads, beadsapi.AgentBead{ID: id, Project: req.Project, Mode: req.Mode, Role: req.Role, AgentName: req.AgentName})
+	return id, nil
+}
+
+// annotatingManager is a mockManager that records pod annotations.
+type annotatingManager struct {
+	mockManager
+	annotated map[string]string // pod name → bead ID
+}
+
+func (m *annotatingManager) AnnotateAgentPod(_ context.Context, pod *corev1.Pod, annotations map[string]string) error {
+	m.annotated[pod.Name] = annotations[podmanager.AnnotationBeadID]
+	return nil
+}
+
+func TestAdoptPods(t *testing.T) {
+	lister := &spawningLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-known", Project: "proj", Mode: "crew", Role: "dev", AgentName: "known"},
+		{ID: "bd-tagged", Project: "proj", Mode: "crew", Role: "dev", AgentName: "tagged"},
+	}}}
+	known := makePod("crew-proj-dev-known", "ns", "crew", "proj", "dev", "known", corev1.PodRunning)
+	known.Annotations = map[string]string{podmanager.AnnotationBeadID: "bd-stale"}
+	tagged := makePod("crew-proj-dev-tagged", "ns", "crew", "proj", "dev", "tagged", corev1.PodRunning)
+	tagged.Annotations = map[string]string{podmanager.AnnotationBeadID: "bd-tagged"}
+	unlabeled := makePod("manual", "ns", "", "proj", "dev", "manual", corev1.PodRunning)
+	mgr := &annotatingManager{
+		mockManager: mockManager{pods: []corev1.Pod{
+			known, tagged, unlabeled,
+			makePod("crew-proj-dev-legacy", "ns", "crew", "proj", "dev", "legacy", corev1.PodRunning),
+			makePod("crew-proj-dev-done", "ns", "crew", "proj", "dev", "done", corev1.PodSucceeded),
+		}},
+		annotated: make(map[string]string),
+	}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder(""))
+
+	n, err := r.AdoptPods(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(mgr.annotated) != 2 || mgr.annotated["crew-proj-dev-known"] != "bd-known" ||
+		mgr.annotated["crew-proj-dev-legacy"] != "bd-legacy" {
+		t.Errorf("adopted %d, annotated %v", n, mgr.annotated)
+	}
+	if len(lister.spawned) != 1 || lister.spawned[0] != (beadsapi.SpawnAgentRequest{
+		AgentName: "legacy", Project: "proj", Role: "dev", Mode: "crew", Image: "ghcr.io/org/agent:v1",
+	}) {
+		t.Errorf("spawned %+v, want the legacy agent only", lister.spawned)
+	}
+
+	// The adopted pod now has a bead and survives the next pass; the pod
+	// without identity labels is still an orphan.
+	plan, err := r.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range plan.Actions {
+		if a.Pod == "crew-proj-dev-legacy" {
+			t.Errorf("adopted pod planned for %s: %s", a.Kind, a.Reason)
+		}
+	}
+}
+
+func TestAdoptPods_NeedsAnnotator(t *testing.T) {
+	r := New(&spawningLister{}, &mockManager{}, testConfig("ns"), testLogger(), simpleSpecBuilder(""))
+	if _, err := r.AdoptPods(context.Background()); err == nil {
+		t.Error("expected an error without a pod annotator")
+	}
+}
+
+func TestAdoptPods_ThroughWrappedManager(t *testing.T) {
+	lister := &spawningLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-known", Project: "proj", Mode: "crew", Role: "dev", AgentName: "known"},
+	}}}
+	mgr := &annotatingManager{
+		mockManager: mockManager{pods: []corev1.Pod{
+			makePod("crew-proj-dev-known", "ns", "crew", "proj", "dev", "known", corev1.PodRunning),
+		}},
+		annotated: make(map[string]string),
+	}
+	// FAULT_INJECTION and then POD_POLICY wrap the manager, as in main.
+	wrapped := policy.NewEnforcer(nil, testLogger()).Manager(faults.New(faults.Config{}, testLogger()).Manager(mgr))
+	r := New(lister, wrapped, testConfig("ns"), testLogger(), simpleSpecBuilder(""))
+
+	n, err := r.AdoptPods(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || mgr.annotated["crew-proj-dev-known"] != "bd-known" {
+		t.Errorf("adopted %d, annotated %v", n, mgr.annotated)
+	}
+}
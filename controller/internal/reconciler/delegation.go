@@ -0,0 +1,62 @@
+package reconciler
+
+import (
+	"context"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// CancelledReason is why the reconciler closes a delegated agent whose
+// parent agent is gone.
+const CancelledReason = "parent agent cancelled"
+
+// beadCloser is implemented by bead listers that can also close beads
+// (beadsapi.Client).
+type beadCloser interface {
+	CloseBead(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// orphanedDelegates returns the pod names of agents delegated work by an
+// agent (beadsapi.ParentAgentField) that is no longer active, directly or
+// through a cancelled ancestor.
+func orphanedDelegates(desired map[string]beadsapi.AgentBead) map[string]bool {
+	parents := make(map[string]string, len(desired)) // bead ID → parent bead ID
+	names := make(map[string]string, len(desired))   // bead ID → pod name
+	for name, bead := range desired {
+		parents[bead.ID] = bead.Metadata[beadsapi.ParentAgentField]
+		names[bead.ID] = name
+	}
+	orphaned := make(map[string]bool)
+	var cancelled func(id string, depth int) bool
+	cancelled = func(id string, depth int) bool {
+		parent := parents[id]
+		if parent == "" || depth > len(parents) {
+			return false // not delegated, or a parent cycle
+		}
+		if _, active := parents[parent]; !active {
+			return true
+		}
+		return cancelled(parent, depth+1)
+	}
+	for id, name := range names {
+		if cancelled(id, 0) {
+			orphaned[name] = true
+		}
+	}
+	return orphaned
+}
+
+// closeCancelled closes the bead of a delegated agent whose parent is
+// gone; the plan already deleted its pod.
+func (r *Reconciler) closeCancelled(ctx context.Context, name string, bead beadsapi.AgentBead) {
+	c, ok := r.lister.(beadCloser)
+	if !ok {
+		return
+	}
+	if err := c.CloseBead(ctx, bead.ID, map[string]string{"reason": CancelledReason}); err != nil {
+		r.logger.Warn("failed to close cancelled delegate", "pod", name, "bead", bead.ID, "error", err)
+		return
+	}
+	r.logger.Info("closed delegate of a cancelled parent", "pod", name, "bead", bead.ID,
+		"parent", bead.Metadata[beadsapi.ParentAgentField])
+}
@@ -0,0 +1,99 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+)
+
+// closingLister is a mockLister that records closed beads.
+type closingLister struct {
+	mockLister
+	closed []string
+}
+
+func (m *closingLister) CloseBead(_ context.Context, beadID string, _ map[string]string) error {
+	m.closed = append(m.closed, beadID)
+	return nil
+}
+
+func delegate(id, name, parent string) beadsapi.AgentBead {
+	return beadsapi.AgentBead{ID: id, Project: "proj", Mode: "job", Role: "job", AgentName: name,
+		Metadata: map[string]string{beadsapi.ParentAgentField: parent}}
+}
+
+func TestOrphanedDelegates_FollowsCancelledAncestors(t *testing.T) {
+	desired := map[string]beadsapi.AgentBead{
+		"lead":  {ID: "bd-lead"},
+		"child": delegate("bd-child", "child", "bd-lead"),
+		"lost":  delegate("bd-lost", "lost", "bd-gone"),
+		"grand": delegate("bd-grand", "grand", "bd-lost"),
+		"loopa": delegate("bd-loopa", "loopa", "bd-loopb"),
+		"loopb": delegate("bd-loopb", "loopb", "bd-loopa"),
+	}
+	got := orphanedDelegates(desired)
+	if len(got) != 2 || !got["lost"] || !got["grand"] {
+		t.Errorf("orphaned = %v, want lost and grand", got)
+	}
+}
+
+func TestReconcile_CancelsDelegatesOfClosedParent(t *testing.T) {
+	lister := &closingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-lead", Project: "proj", Mode: "crew", Role: "dev", AgentName: "lead"},
+		delegate("bd-j1", "j1", "bd-lead"),
+		delegate("bd-j2", "j2", "bd-gone"),
+	}}}
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-lead", "ns", "crew", "proj", "dev", "lead", corev1.PodRunning),
+		makePod("job-proj-job-j1", "ns", "job", "proj", "job", "j1", corev1.PodRunning),
+		makePod("job-proj-job-j2", "ns", "job", "proj", "job", "j2", corev1.PodRunning),
+	}}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder(""))
+
+	plan, err := r.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Cancelled) != 1 || plan.Cancelled[0] != "job-proj-job-j2" {
+		t.Fatalf("cancelled = %v, want [job-proj-job-j2]", plan.Cancelled)
+	}
+	if len(lister.closed) != 0 {
+		t.Fatalf("plan closed beads: %v", lister.closed)
+	}
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.deleted) != 1 || mgr.deleted[0] != "job-proj-job-j2" {
+		t.Errorf("deleted = %v, want [job-proj-job-j2]", mgr.deleted)
+	}
+	if len(mgr.created) != 0 {
+		t.Errorf("created %d pods, want none", len(mgr.created))
+	}
+	if len(lister.closed) != 1 || lister.closed[0] != "bd-j2" {
+		t.Errorf("closed = %v, want [bd-j2]", lister.closed)
+	}
+}
+
+func TestReconcile_CancelSkipsPausedProject(t *testing.T) {
+	lister := &closingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		delegate("bd-j1", "j1", "bd-gone"),
+	}}}
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("job-proj-job-j1", "ns", "job", "proj", "job", "j1", corev1.PodRunning),
+	}}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{"proj": {ReconcilePaused: true}})
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder(""))
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.deleted) != 0 || len(lister.closed) != 0 {
+		t.Errorf("deleted = %v, closed = %v, want none for a paused project", mgr.deleted, lister.closed)
+	}
+}
@@ -0,0 +1,147 @@
+package reconciler
+
+import (
+	"context"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+// DiffEntry describes one pod whose actual state differs from the desired state.
+type DiffEntry struct {
+	Pod     string `json:"pod"`
+	Cluster string `json:"cluster,omitempty"`
+	Project string `json:"project,omitempty"`
+	Agent   string `json:"agent,omitempty"`
+	Phase   string `json:"phase,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Paused  bool   `json:"paused,omitempty"` // project reconcile is paused, so no action will be taken
+}
+
+// StateDiff is the difference between desired agent beads and actual agent
+// pods, as the next reconcile pass would see it.
+type StateDiff struct {
+	Desired        int         `json:"desired"`
+	Actual         int         `json:"actual"`
+	Missing        []DiffEntry `json:"missing"`     // desired, no pod
+	Orphans        []DiffEntry `json:"orphans"`     // pod, no desired bead
+	Terminal       []DiffEntry `json:"terminal"`    // pod Failed or Succeeded, will be recreated
+	Drifted        []DiffEntry `json:"drifted"`     // pod spec differs from desired spec
+	Duplicates     []DiffEntry `json:"duplicates"`  // extra copy of a pod on another cluster
+	Hibernating    []DiffEntry `json:"hibernating"` // outside its schedule; a Phase means the pod is still up
+	PausedProjects []string    `json:"paused_projects"`
+}
+
+// InSync reports whether no pod needs to be created, deleted, or recreated.
+func (d *StateDiff) InSync() bool {
+	return len(d.Missing) == 0 && len(d.Orphans) == 0 && len(d.Terminal) == 0 && len(d.Drifted) == 0 &&
+		len(d.Duplicates) == 0 && !d.hibernationPending()
+}
+
+// hibernationPending reports whether a hibernating agent still has a pod.
+func (d *StateDiff) hibernationPending() bool {
+	for _, e := range d.Hibernating {
+		if e.Phase != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff compares desired and actual state without changing anything. It does
+// not consult burst or max-pod limits, so a pass may act on only part of it.
+func (r *Reconciler) Diff(ctx context.Context) (*StateDiff, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	desired, actualMap, strays, err := r.observe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &StateDiff{
+		Desired:        len(desired),
+		Actual:         len(actualMap),
+		Missing:        []DiffEntry{},
+		Orphans:        []DiffEntry{},
+		Terminal:       []DiffEntry{},
+		Drifted:        []DiffEntry{},
+		Duplicates:     []DiffEntry{},
+		Hibernating:    []DiffEntry{},
+		PausedProjects: []string{},
+	}
+	for name, entry := range r.cfg.ProjectCache.Load() {
+		if entry.ReconcilePaused {
+			diff.PausedProjects = append(diff.PausedProjects, name)
+		}
+	}
+
+	for name, pod := range actualMap {
+		if _, ok := desired[name]; !ok {
+			project := pod.Labels[podmanager.LabelProject]
+			diff.Orphans = append(diff.Orphans, DiffEntry{
+				Pod:     name,
+				Cluster: pod.Labels[podmanager.LabelCluster],
+				Project: project,
+				Agent:   pod.Labels[podmanager.LabelAgent],
+				Phase:   string(pod.Status.Phase),
+				Paused:  r.projectPaused(project),
+			})
+		}
+	}
+
+	now := r.now()
+	for name, bead := range desired {
+		entry := DiffEntry{
+			Pod:     name,
+			Project: bead.Project,
+			Agent:   bead.AgentName,
+			Paused:  r.projectPaused(bead.Project),
+		}
+		pod, exists := actualMap[name]
+		entry.Cluster = pod.Labels[podmanager.LabelCluster]
+		if !entry.Paused {
+			if sched := r.scheduleFor(name, bead); sched != nil && !sched.Active(now) {
+				if exists {
+					entry.Phase = string(pod.Status.Phase)
+				}
+				entry.Reason = "outside schedule"
+				diff.Hibernating = append(diff.Hibernating, entry)
+				continue
+			}
+		}
+		switch {
+		case !exists:
+			diff.Missing = append(diff.Missing, entry)
+		case pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded:
+			entry.Phase = string(pod.Status.Phase)
+			diff.Terminal = append(diff.Terminal, entry)
+		default:
+			spec := r.specBuilder(r.cfg, bead.Project, bead.Mode, bead.Role, bead.AgentName, bead.Metadata)
+			if reason := podDriftReason(spec, &pod, r.digestTracker); reason != "" {
+				entry.Phase = string(pod.Status.Phase)
+				entry.Reason = reason
+				diff.Drifted = append(diff.Drifted, entry)
+			}
+		}
+	}
+
+	for _, pod := range strays {
+		diff.Duplicates = append(diff.Duplicates, DiffEntry{
+			Pod:     pod.Name,
+			Cluster: pod.Labels[podmanager.LabelCluster],
+			Project: pod.Labels[podmanager.LabelProject],
+			Agent:   pod.Labels[podmanager.LabelAgent],
+			Phase:   string(pod.Status.Phase),
+			Reason:  "pod also exists on another cluster",
+		})
+	}
+
+	sort.Strings(diff.PausedProjects)
+	for _, entries := range [][]DiffEntry{diff.Missing, diff.Orphans, diff.Terminal, diff.Drifted, diff.Duplicates, diff.Hibernating} {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Pod < entries[j].Pod })
+	}
+	return diff, nil
+}
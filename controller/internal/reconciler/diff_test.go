@@ -0,0 +1,68 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+)
+
+func TestDiff_ReportsWithoutActing(t *testing.T) {
+	lister := &mockLister{
+		beads: []beadsapi.AgentBead{
+			{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+			{ID: "bd-2", Project: "proj", Mode: "crew", Role: "dev", AgentName: "beta"},
+			{ID: "bd-3", Project: "proj", Mode: "crew", Role: "dev", AgentName: "gamma"},
+			{ID: "bd-4", Project: "frozen", Mode: "crew", Role: "dev", AgentName: "delta"},
+		},
+	}
+	mgr := &mockManager{
+		pods: []corev1.Pod{
+			makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", corev1.PodRunning),
+			makePod("crew-proj-dev-beta", "ns", "crew", "proj", "dev", "beta", corev1.PodFailed),
+			makePod("crew-proj-dev-old", "ns", "crew", "proj", "dev", "old", corev1.PodRunning),
+		},
+	}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{"frozen": {ReconcilePaused: true}})
+
+	// Desired image differs from the pods' v1, so alpha has drifted.
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v2"))
+	diff, err := r.Diff(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mgr.created) != 0 || len(mgr.deleted) != 0 {
+		t.Fatalf("Diff must not act: created %d, deleted %v", len(mgr.created), mgr.deleted)
+	}
+	if diff.Desired != 4 || diff.Actual != 3 || diff.InSync() {
+		t.Errorf("unexpected totals: %+v", diff)
+	}
+	if len(diff.Missing) != 2 || diff.Missing[0].Pod != "crew-frozen-dev-delta" || !diff.Missing[0].Paused ||
+		diff.Missing[1].Pod != "crew-proj-dev-gamma" || diff.Missing[1].Paused {
+		t.Errorf("unexpected missing: %+v", diff.Missing)
+	}
+	if len(diff.Orphans) != 1 || diff.Orphans[0].Agent != "old" {
+		t.Errorf("unexpected orphans: %+v", diff.Orphans)
+	}
+	if len(diff.Terminal) != 1 || diff.Terminal[0].Phase != string(corev1.PodFailed) {
+		t.Errorf("unexpected terminal: %+v", diff.Terminal)
+	}
+	if len(diff.Drifted) != 1 || diff.Drifted[0].Agent != "alpha" || diff.Drifted[0].Reason == "" {
+		t.Errorf("unexpected drifted: %+v", diff.Drifted)
+	}
+	if len(diff.PausedProjects) != 1 || diff.PausedProjects[0] != "frozen" {
+		t.Errorf("unexpected paused projects: %v", diff.PausedProjects)
+	}
+}
+
+func TestDiff_ListerErrorFails(t *testing.T) {
+	r := New(&mockLister{err: context.DeadlineExceeded}, &mockManager{}, testConfig("ns"), testLogger(), simpleSpecBuilder("img"))
+	if _, err := r.Diff(context.Background()); err == nil {
+		t.Fatal("expected error when the daemon is unreachable")
+	}
+}
@@ -0,0 +1,59 @@
+package reconciler
+
+import (
+	"context"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/schedule"
+)
+
+// HibernatingState is the agent_state of beads whose pods are stopped
+// because the agent is outside its schedule.
+const HibernatingState = "hibernating"
+
+// markHibernating marks the bead of an agent outside its schedule (whose
+// pod the plan deletes) hibernating, once. An agent whose window opens
+// again is simply recreated like any missing pod.
+func (r *Reconciler) markHibernating(ctx context.Context, name string, bead beadsapi.AgentBead) {
+	delete(r.waiting, name)
+	if r.asleep[name] || bead.AgentState == HibernatingState {
+		r.asleep[name] = true
+		return
+	}
+	fields := map[string]string{"agent_state": HibernatingState}
+	if _, queued := r.queued[name]; queued {
+		fields[QueuePositionField] = ""
+	}
+	if u, ok := r.lister.(beadFieldUpdater); ok {
+		if err := u.UpdateBeadFields(ctx, bead.ID, fields); err != nil {
+			r.logger.Warn("failed to mark bead hibernating", "bead", bead.ID, "error", err)
+			return
+		}
+	}
+	delete(r.queued, name)
+	r.asleep[name] = true
+}
+
+// scheduleFor returns the schedule governing bead: its own schedule field,
+// else its project's. It returns nil when the agent is always active. An
+// unparseable schedule is logged once and ignored, so a typo can't stop
+// agents.
+func (r *Reconciler) scheduleFor(name string, bead beadsapi.AgentBead) *schedule.Schedule {
+	raw := bead.Metadata[beadsapi.ScheduleField]
+	if raw == "" {
+		raw = r.cfg.ProjectCache.Load()[bead.Project].Schedule
+	}
+	if raw == "" {
+		return nil
+	}
+	sched, err := schedule.Parse(raw)
+	if err != nil {
+		if r.badSchedules[name] != raw {
+			r.logger.Warn("ignoring invalid agent schedule", "pod", name, "schedule", raw, "error", err)
+			r.badSchedules[name] = raw
+		}
+		return nil
+	}
+	delete(r.badSchedules, name)
+	return sched
+}
@@ -0,0 +1,126 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+)
+
+func TestReconcile_HibernatesOutsideSchedule(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-a1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "a1"},
+		{ID: "bd-a2", Project: "proj", Mode: "crew", Role: "dev", AgentName: "a2",
+			Metadata: map[string]string{beadsapi.ScheduleField: "* 00:00-24:00"}},
+	}}}
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-a1", "ns", "crew", "proj", "dev", "a1", corev1.PodRunning),
+		makePod("crew-proj-dev-a2", "ns", "crew", "proj", "dev", "a2", corev1.PodRunning),
+	}}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{"proj": {Schedule: "Mon-Fri 09:00-17:00"}})
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder(""))
+	// 2026-03-07 is a Saturday.
+	r.now = func() time.Time { return time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// a1 follows the project schedule; a2's own schedule keeps it awake.
+	if len(mgr.deleted) != 1 || mgr.deleted[0] != "crew-proj-dev-a1" {
+		t.Fatalf("deleted = %v, want [crew-proj-dev-a1]", mgr.deleted)
+	}
+	if got := lister.updates["bd-a1"]["agent_state"]; got != HibernatingState {
+		t.Errorf("bd-a1 agent_state = %q, want %q", got, HibernatingState)
+	}
+	if _, ok := lister.updates["bd-a2"]; ok {
+		t.Errorf("bd-a2 updated: %v", lister.updates["bd-a2"])
+	}
+
+	// Asleep with the pod gone: nothing is recreated or rewritten.
+	mgr.pods = mgr.pods[1:]
+	lister.updates = nil
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 0 || len(lister.updates) != 0 {
+		t.Fatalf("second pass: created %d pods, updates %v", len(mgr.created), lister.updates)
+	}
+
+	// Monday morning: the pod comes back.
+	r.now = func() time.Time { return time.Date(2026, 3, 9, 9, 0, 0, 0, time.UTC) }
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 1 || mgr.created[0].AgentName != "a1" {
+		t.Fatalf("created = %v, want a1", mgr.created)
+	}
+}
+
+func TestReconcile_HibernateIgnoresInvalidSchedule(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-a1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "a1",
+			Metadata: map[string]string{beadsapi.ScheduleField: "weekdays 9-5"}},
+	}}
+	mgr := &mockManager{}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder(""))
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 1 {
+		t.Fatalf("created %d pods, want 1 (invalid schedule fails open)", len(mgr.created))
+	}
+}
+
+func TestReconcile_HibernateSkipsPausedProject(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-a1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "a1"},
+	}}
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-a1", "ns", "crew", "proj", "dev", "a1", corev1.PodRunning),
+	}}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{
+		"proj": {Schedule: "Mon 09:00-10:00", ReconcilePaused: true},
+	})
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder(""))
+	r.now = func() time.Time { return time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC) }
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.deleted) != 0 {
+		t.Fatalf("deleted = %v, want none for a paused project", mgr.deleted)
+	}
+}
+
+func TestDiff_ReportsHibernating(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-a1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "a1"},
+		{ID: "bd-a2", Project: "proj", Mode: "crew", Role: "dev", AgentName: "a2"},
+	}}
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-a1", "ns", "crew", "proj", "dev", "a1", corev1.PodRunning),
+	}}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{"proj": {Schedule: "Mon-Fri 09:00-17:00"}})
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder(""))
+	r.now = func() time.Time { return time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC) }
+
+	diff, err := r.Diff(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff.Missing) != 0 || len(diff.Hibernating) != 2 || diff.InSync() {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	if diff.Hibernating[0].Phase != string(corev1.PodRunning) || diff.Hibernating[1].Phase != "" {
+		t.Errorf("unexpected hibernating: %+v", diff.Hibernating)
+	}
+}
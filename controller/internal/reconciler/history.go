@@ -0,0 +1,247 @@
+package reconciler
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// historyMaxActions caps the actions kept per history entry so one mass
+// pass cannot crowd out the rest of the history in its store.
+const historyMaxActions = 25
+
+// HistoryEntry records one reconcile pass that changed something or failed.
+// Passes with nothing to do are only counted, in the next entry's
+// IdlePasses.
+type HistoryEntry struct {
+	At             time.Time    `json:"at"`
+	DurationMS     int64        `json:"duration_ms"`
+	Desired        int          `json:"desired"`
+	Actual         int          `json:"actual"`
+	Actions        []PlanAction `json:"actions"`
+	OmittedActions int          `json:"omitted_actions,omitempty"` // actions past historyMaxActions
+	Deferred       int          `json:"deferred,omitempty"`
+	Error          string       `json:"error,omitempty"`
+	IdlePasses     int          `json:"idle_passes,omitempty"` // passes without changes since the previous entry
+}
+
+// HistoryStore persists the reconcile history so it survives controller
+// restarts and leader changes.
+type HistoryStore interface {
+	// LoadHistory returns the stored entries, oldest first, or none if
+	// nothing has been stored yet.
+	LoadHistory(ctx context.Context) ([]HistoryEntry, error)
+	SaveHistory(ctx context.Context, entries []HistoryEntry) error
+}
+
+// HistoryFilter selects history entries. Zero fields match everything.
+type HistoryFilter struct {
+	Since time.Time
+	// Agent matches actions by agent name, pod name or bead ID; entries
+	// are trimmed to the matching actions and dropped if none match.
+	Agent string
+}
+
+// AgentDrift sums up the pod operations history recorded for one agent.
+type AgentDrift struct {
+	Pod        string     `json:"pod"`
+	Project    string     `json:"project,omitempty"`
+	Agent      string     `json:"agent,omitempty"`
+	BeadID     string     `json:"bead_id,omitempty"`
+	Creates    int        `json:"creates"`
+	Deletes    int        `json:"deletes"`
+	Restarts   int        `json:"restarts"`
+	LastAt     time.Time  `json:"last_at"`
+	LastKind   ActionKind `json:"last_kind"`
+	LastReason string     `json:"last_reason"`
+}
+
+// History keeps a bounded record of past reconcile passes, persisted to a
+// HistoryStore. Only the replica running reconcile passes writes it; other
+// replicas read the store on every query.
+type History struct {
+	mu      sync.Mutex
+	store   HistoryStore
+	size    int
+	logger  *slog.Logger
+	entries []HistoryEntry // oldest first
+	loaded  bool           // entries include the stored ones
+	writer  bool           // this replica records passes
+	idle    int            // passes without changes since the last entry
+}
+
+// NewHistory creates a history keeping the latest size entries. A nil
+// store keeps them in memory only.
+func NewHistory(store HistoryStore, size int, logger *slog.Logger) *History {
+	return &History{store: store, size: size, logger: logger, loaded: store == nil}
+}
+
+// SetHistory records every reconcile pass in h.
+func (r *Reconciler) SetHistory(h *History) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history = h
+}
+
+// History returns the reconcile history, or nil when it is not kept.
+func (r *Reconciler) History() *History {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.history
+}
+
+// record adds the pass that produced plan, taking took, and persists the
+// history. Store failures are logged; the pass is kept in memory and
+// written with the next one.
+func (h *History) record(ctx context.Context, plan *ReconcilePlan, took time.Duration) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.writer = true
+
+	if len(plan.Actions) == 0 && plan.Error == "" {
+		h.idle++
+		return
+	}
+	entry := HistoryEntry{
+		At:         plan.At,
+		DurationMS: took.Milliseconds(),
+		Desired:    plan.Desired,
+		Actual:     plan.Actual,
+		Actions:    plan.Actions,
+		Deferred:   len(plan.Deferred),
+		Error:      plan.Error,
+		IdlePasses: h.idle,
+	}
+	if entry.Actions == nil {
+		entry.Actions = []PlanAction{}
+	}
+	if n := len(entry.Actions); n > historyMaxActions {
+		entry.Actions = entry.Actions[:historyMaxActions]
+		entry.OmittedActions = n - historyMaxActions
+	}
+	h.idle = 0
+	h.entries = h.trim(append(h.entries, entry))
+
+	if !h.loaded {
+		stored, err := h.store.LoadHistory(ctx)
+		if err != nil {
+			h.logger.Warn("failed to load reconcile history, keeping passes in memory", "error", err)
+			return
+		}
+		h.entries = h.trim(append(stored, h.entries...))
+		h.loaded = true
+	}
+	if h.store == nil {
+		return
+	}
+	if err := h.store.SaveHistory(ctx, h.entries); err != nil {
+		h.logger.Warn("failed to save reconcile history", "error", err)
+	}
+}
+
+// trim drops the oldest entries past the history's size.
+func (h *History) trim(entries []HistoryEntry) []HistoryEntry {
+	if len(entries) > h.size {
+		entries = slices.Clone(entries[len(entries)-h.size:])
+	}
+	return entries
+}
+
+// snapshot returns all entries, oldest first.
+func (h *History) snapshot(ctx context.Context) ([]HistoryEntry, error) {
+	h.mu.Lock()
+	if h.writer || h.store == nil {
+		defer h.mu.Unlock()
+		return slices.Clone(h.entries), nil
+	}
+	h.mu.Unlock()
+	return h.store.LoadHistory(ctx)
+}
+
+// Entries returns the entries matching f, newest first.
+func (h *History) Entries(ctx context.Context, f HistoryFilter) ([]HistoryEntry, error) {
+	all, err := h.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := []HistoryEntry{}
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if e.At.Before(f.Since) {
+			break
+		}
+		if f.Agent != "" {
+			var actions []PlanAction
+			for _, a := range e.Actions {
+				if a.matches(f.Agent) {
+					actions = append(actions, a)
+				}
+			}
+			if len(actions) == 0 {
+				continue
+			}
+			e.Actions = actions
+			e.OmittedActions = 0
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// Drift sums up the pod operations recorded since since per agent, the
+// agents with the most operations first.
+func (h *History) Drift(ctx context.Context, since time.Time) ([]AgentDrift, error) {
+	all, err := h.snapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byPod := make(map[string]*AgentDrift)
+	for _, e := range all {
+		if e.At.Before(since) {
+			continue
+		}
+		for _, a := range e.Actions {
+			d, ok := byPod[a.Pod]
+			if !ok {
+				d = &AgentDrift{Pod: a.Pod}
+				byPod[a.Pod] = d
+			}
+			switch a.Kind {
+			case ActionCreate:
+				d.Creates++
+			case ActionDelete:
+				d.Deletes++
+			case ActionRestart:
+				d.Restarts++
+			}
+			if a.Project != "" {
+				d.Project, d.Agent, d.BeadID = a.Project, a.Agent, a.BeadID
+			}
+			d.LastAt, d.LastKind, d.LastReason = e.At, a.Kind, a.Reason
+		}
+	}
+	out := make([]AgentDrift, 0, len(byPod))
+	for _, d := range byPod {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if na, nb := a.Creates+a.Deletes+a.Restarts, b.Creates+b.Deletes+b.Restarts; na != nb {
+			return na > nb
+		}
+		return a.Pod < b.Pod
+	})
+	return out, nil
+}
+
+// matches reports whether the action concerns agent, given as an agent
+// name, pod name or bead ID.
+func (a PlanAction) matches(agent string) bool {
+	return a.Agent == agent || a.Pod == agent || a.BeadID == agent
+}
@@ -0,0 +1,121 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+type failingHistoryStore struct {
+	saved []HistoryEntry
+	err   error
+}
+
+func (s *failingHistoryStore) LoadHistory(context.Context) ([]HistoryEntry, error) {
+	return nil, s.err
+}
+
+func (s *failingHistoryStore) SaveHistory(_ context.Context, entries []HistoryEntry) error {
+	s.saved = entries
+	return nil
+}
+
+func TestReconcile_RecordsHistory(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+	}}
+	mgr := &mockManager{}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+	store := NewFileHistoryStore(filepath.Join(t.TempDir(), "history.json"))
+	r.SetHistory(NewHistory(store, 10, testLogger()))
+	ctx := context.Background()
+
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	mgr.pods = []corev1.Pod{makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", corev1.PodRunning)}
+	// Two passes with nothing to do, then one that cannot list beads.
+	for range 2 {
+		if err := r.Reconcile(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister.err = errors.New("daemon down")
+	if err := r.Reconcile(ctx); err == nil {
+		t.Fatal("expected the listing error")
+	}
+
+	stored, err := store.LoadHistory(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("stored %d entries, want the create and the failure: %+v", len(stored), stored)
+	}
+	if a := stored[0].Actions; len(a) != 1 || a[0].Kind != ActionCreate || a[0].Agent != "alpha" {
+		t.Errorf("first entry actions = %+v", a)
+	}
+	if stored[1].Error == "" || stored[1].IdlePasses != 2 {
+		t.Errorf("second entry = %+v, want the error after 2 idle passes", stored[1])
+	}
+}
+
+func TestHistory_BoundsEntriesAndActions(t *testing.T) {
+	h := NewHistory(nil, 3, testLogger())
+	ctx := context.Background()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		plan := &ReconcilePlan{At: start.Add(time.Duration(i) * time.Hour)}
+		for j := range historyMaxActions + i {
+			plan.Actions = append(plan.Actions, PlanAction{Kind: ActionCreate, Pod: fmt.Sprintf("pod-%d", j)})
+		}
+		h.record(ctx, plan, time.Second)
+	}
+
+	entries, err := h.Entries(ctx, HistoryFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || !entries[0].At.Equal(start.Add(4*time.Hour)) {
+		t.Fatalf("entries = %d, newest at %v; want the latest 3", len(entries), entries[0].At)
+	}
+	if len(entries[0].Actions) != historyMaxActions || entries[0].OmittedActions != 4 || entries[0].DurationMS != 1000 {
+		t.Errorf("newest entry keeps %d actions, omits %d, took %dms", len(entries[0].Actions), entries[0].OmittedActions, entries[0].DurationMS)
+	}
+
+	recent, _ := h.Entries(ctx, HistoryFilter{Since: start.Add(3 * time.Hour), Agent: "pod-0"})
+	if len(recent) != 2 || len(recent[0].Actions) != 1 {
+		t.Errorf("pod-0 since 03:00 = %+v", recent)
+	}
+}
+
+func TestHistory_KeepsPassesWhenStoreUnreadable(t *testing.T) {
+	store := &failingHistoryStore{err: errors.New("daemon down")}
+	h := NewHistory(store, 10, testLogger())
+	ctx := context.Background()
+	restart := &ReconcilePlan{Actions: []PlanAction{{Kind: ActionRestart, Pod: "crew-proj-dev-alpha", Reason: "pod Failed"}}}
+
+	h.record(ctx, restart, 0)
+	if store.saved != nil {
+		t.Fatal("history saved without loading the stored entries first")
+	}
+
+	// Once the store is readable again both passes are written together.
+	store.err = nil
+	h.record(ctx, restart, 0)
+	if len(store.saved) != 2 {
+		t.Errorf("saved %d entries, want 2", len(store.saved))
+	}
+
+	drift, _ := h.Drift(ctx, time.Time{})
+	if len(drift) != 1 || drift[0].Restarts != 2 || drift[0].LastReason != "pod Failed" {
+		t.Errorf("drift = %+v", drift)
+	}
+}
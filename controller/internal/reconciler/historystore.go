@@ -0,0 +1,108 @@
+package reconciler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// HistoryConfigKey is the daemon config key holding the reconcile history.
+const HistoryConfigKey = "controller:reconcile-history"
+
+// configStore reads and writes daemon config entries (beadsapi.Client).
+type configStore interface {
+	GetConfig(ctx context.Context, key string) (*beadsapi.ConfigEntry, error)
+	SetConfig(ctx context.Context, key string, value []byte) error
+}
+
+// DaemonHistoryStore keeps the reconcile history in a daemon config entry,
+// where every controller replica can read it.
+type DaemonHistoryStore struct {
+	configs configStore
+}
+
+// NewDaemonHistoryStore creates a history store on the daemon's configs.
+func NewDaemonHistoryStore(configs configStore) *DaemonHistoryStore {
+	return &DaemonHistoryStore{configs: configs}
+}
+
+// LoadHistory implements HistoryStore.
+func (s *DaemonHistoryStore) LoadHistory(ctx context.Context) ([]HistoryEntry, error) {
+	entry, err := s.configs.GetConfig(ctx, HistoryConfigKey)
+	if err != nil {
+		var apiErr *beadsapi.APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []HistoryEntry
+	if err := json.Unmarshal(entry.Value, &entries); err != nil {
+		return nil, fmt.Errorf("decoding reconcile history: %w", err)
+	}
+	return entries, nil
+}
+
+// SaveHistory implements HistoryStore.
+func (s *DaemonHistoryStore) SaveHistory(ctx context.Context, entries []HistoryEntry) error {
+	value, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("encoding reconcile history: %w", err)
+	}
+	return s.configs.SetConfig(ctx, HistoryConfigKey, value)
+}
+
+// FileHistoryStore keeps the reconcile history in a local JSON file, for
+// controllers with a persistent volume and no need to share it.
+type FileHistoryStore struct {
+	path string
+}
+
+// NewFileHistoryStore creates a history store writing path.
+func NewFileHistoryStore(path string) *FileHistoryStore {
+	return &FileHistoryStore{path: path}
+}
+
+// LoadHistory implements HistoryStore.
+func (s *FileHistoryStore) LoadHistory(context.Context) ([]HistoryEntry, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []HistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decoding reconcile history %s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+// SaveHistory implements HistoryStore. The file is replaced atomically so
+// a crash mid-write leaves the previous history.
+func (s *FileHistoryStore) SaveHistory(_ context.Context, entries []HistoryEntry) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("encoding reconcile history: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,49 @@
+package reconciler
+
+import (
+	"context"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// BlockedUnsignedImageState is the agent_state of beads whose pods are held
+// back because their image has no verifiable signature.
+const BlockedUnsignedImageState = "blocked_unsigned_image"
+
+// ImageVerifier checks an image's signature (imagesig.Verifier).
+type ImageVerifier interface {
+	Verify(ctx context.Context, image string) error
+}
+
+// SetImageVerifier enables signature verification: pods are only created,
+// and running pods only replaced, once their image verifies. Beads whose
+// image doesn't are marked blocked_unsigned_image and retried every pass.
+func (r *Reconciler) SetImageVerifier(v ImageVerifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verifier = v
+}
+
+// verifyImage checks image's signature; it passes when verification is off.
+func (r *Reconciler) verifyImage(ctx context.Context, image string) error {
+	if r.verifier == nil || image == "" {
+		return nil
+	}
+	return r.verifier.Verify(ctx, image)
+}
+
+// markBlocked marks bead blocked on image the first time image fails to
+// verify for it.
+func (r *Reconciler) markBlocked(ctx context.Context, name string, bead beadsapi.AgentBead, image string) {
+	if r.blocked[name] == image || bead.AgentState == BlockedUnsignedImageState {
+		r.blocked[name] = image
+		return
+	}
+	if u, ok := r.lister.(beadFieldUpdater); ok {
+		if err := u.UpdateBeadFields(ctx, bead.ID, map[string]string{"agent_state": BlockedUnsignedImageState}); err != nil {
+			r.logger.Warn("failed to mark bead blocked on unsigned image", "bead", bead.ID, "error", err)
+			return
+		}
+	}
+	r.blocked[name] = image
+}
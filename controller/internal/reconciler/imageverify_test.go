@@ -0,0 +1,82 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// allowList is an ImageVerifier trusting a fixed set of images.
+type allowList map[string]bool
+
+func (a allowList) Verify(_ context.Context, image string) error {
+	if a[image] {
+		return nil
+	}
+	return errors.New("no valid signature")
+}
+
+func TestReconcile_HoldsBeadsWithUnsignedImages(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+	}}}
+	mgr := &mockManager{}
+	trusted := allowList{}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("img:v1"))
+	r.SetImageVerifier(trusted)
+	ctx := context.Background()
+
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 0 {
+		t.Fatalf("created %d pods for an unsigned image", len(mgr.created))
+	}
+	if got := lister.updates["bd-1"]["agent_state"]; got != BlockedUnsignedImageState {
+		t.Errorf("agent_state = %q, want %q", got, BlockedUnsignedImageState)
+	}
+
+	// Still unsigned: the bead is not re-marked.
+	lister.updates = nil
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, marked := lister.updates["bd-1"]; marked {
+		t.Error("blocked bead marked again on a later pass")
+	}
+
+	// Once the image is signed the pod is created.
+	trusted["img:v1"] = true
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 1 {
+		t.Errorf("created %d pods after the image was signed, want 1", len(mgr.created))
+	}
+}
+
+func TestReconcile_KeepsPodWhenUpgradeImageUnsigned(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+	}}}
+	// The pod runs img:v1 (makePod); the desired spec moves to unsigned img:v2.
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", corev1.PodRunning),
+	}}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("img:v2"))
+	r.SetImageVerifier(allowList{"ghcr.io/org/agent:v1": true})
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.deleted) != 0 || len(mgr.created) != 0 {
+		t.Errorf("deleted %v, created %d; want the running pod kept", mgr.deleted, len(mgr.created))
+	}
+	if got := lister.updates["bd-1"]["agent_state"]; got != BlockedUnsignedImageState {
+		t.Errorf("agent_state = %q, want %q", got, BlockedUnsignedImageState)
+	}
+}
@@ -0,0 +1,578 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/policy"
+)
+
+// ActionKind is what a planned action does to a pod.
+type ActionKind string
+
+const (
+	// ActionCreate creates a missing agent pod.
+	ActionCreate ActionKind = "create"
+	// ActionDelete deletes a pod: an orphan, a duplicate on another
+	// cluster, a hibernating agent's pod, or a pod whose replacement is
+	// deferred.
+	ActionDelete ActionKind = "delete"
+	// ActionRestart deletes a terminal or drifted pod and creates its
+	// replacement from the current spec.
+	ActionRestart ActionKind = "restart"
+)
+
+// PlanAction is one pod operation of a reconcile plan.
+type PlanAction struct {
+	Kind      ActionKind `json:"kind"`
+	Pod       string     `json:"pod"`
+	Namespace string     `json:"namespace,omitempty"`
+	Cluster   string     `json:"cluster,omitempty"`
+	Project   string     `json:"project,omitempty"`
+	Agent     string     `json:"agent,omitempty"`
+	BeadID    string     `json:"bead_id,omitempty"`
+	Image     string     `json:"image,omitempty"` // image of the pod created
+	Reason    string     `json:"reason"`
+
+	name    string // canonical agent pod name (Pod may be a warm pool name)
+	spec    *podmanager.AgentPodSpec
+	stray   bool // delete only the copy on Cluster
+	upgrade bool // drift upgrade, tracked by the UpgradeTracker
+}
+
+// PlanDeferral is an agent whose pod the plan leaves alone for now.
+type PlanDeferral struct {
+	Pod           string `json:"pod"`
+	Project       string `json:"project,omitempty"`
+	Agent         string `json:"agent,omitempty"`
+	BeadID        string `json:"bead_id,omitempty"`
+	Reason        string `json:"reason"`
+	State         string `json:"state,omitempty"`          // agent_state the bead is marked with
+	QueuePosition int    `json:"queue_position,omitempty"` // 1-based place in the spawn queue
+
+	image string // unverified image, for BlockedUnsignedImageState
+}
+
+// ReconcilePlan is what a reconcile pass does: the pod operations, in the
+// order they are applied, and the agents deferred to a later pass.
+type ReconcilePlan struct {
+	At          time.Time      `json:"at"`
+	Desired     int            `json:"desired"`
+	Actual      int            `json:"actual"`
+	Actions     []PlanAction   `json:"actions"`
+	Deferred    []PlanDeferral `json:"deferred"`
+	Hibernating []string       `json:"hibernating,omitempty"`
+	// Cancelled lists delegated agents whose parent agent is gone; their
+	// pods are deleted and beads closed.
+	Cancelled []string `json:"cancelled,omitempty"`
+	// OrphanGuard is set when no agent beads were listed while pods
+	// exist; orphan deletion is skipped rather than killing every agent.
+	OrphanGuard bool   `json:"orphan_guard,omitempty"`
+	Error       string `json:"error,omitempty"` // why applying the plan stopped
+
+	desired     map[string]beadsapi.AgentBead // all agent beads
+	awake       map[string]beadsapi.AgentBead // desired minus hibernating agents
+	hibernating map[string]beadsapi.AgentBead
+	cancelled   map[string]beadsapi.AgentBead
+	verified    map[string]bool // pods whose image verified
+	active      int             // active pods once the plan is applied
+	awakeActual int             // pods of awake agents and orphans
+	queued      int             // deferrals in the spawn queue
+	burstLimit  int
+}
+
+// Creates returns the number of pods the plan creates, restarts included.
+func (p *ReconcilePlan) Creates() int {
+	n := 0
+	for _, a := range p.Actions {
+		if a.Kind != ActionDelete {
+			n++
+		}
+	}
+	return n
+}
+
+// Deletes returns the number of pods the plan deletes, restarts included.
+func (p *ReconcilePlan) Deletes() int {
+	n := 0
+	for _, a := range p.Actions {
+		if a.Kind != ActionCreate {
+			n++
+		}
+	}
+	return n
+}
+
+// Plan computes the next reconcile pass without changing any pod or bead.
+// Unlike Diff it applies the burst, max-pod, capacity, upgrade and image
+// signature limits, so its actions are exactly what Apply would do now.
+// Preemptions not yet counted on their beads are not reflected.
+func (r *Reconciler) Plan(ctx context.Context) (*ReconcilePlan, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	desired, actual, strays, err := r.observe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.plan(ctx, desired, actual, strays), nil
+}
+
+// Apply executes plan, stopping at the first failed pod operation. A plan
+// goes stale as beads and pods change; Reconcile plans and applies in one
+// step.
+func (r *Reconciler) Apply(ctx context.Context, plan *ReconcilePlan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.apply(ctx, plan)
+}
+
+// LastPlan returns the plan of the latest reconcile pass, or nil before
+// the first one.
+func (r *Reconciler) LastPlan() *ReconcilePlan {
+	return r.lastPlan.Load()
+}
+
+// plan decides one pass over the observed state. It may refresh in-memory
+// upgrade tracking and query capacity and image signatures, but writes
+// nothing.
+func (r *Reconciler) plan(ctx context.Context, desired map[string]beadsapi.AgentBead, actual map[string]corev1.Pod, strays []corev1.Pod) *ReconcilePlan {
+	p := &ReconcilePlan{
+		At:          r.now(),
+		Desired:     len(desired),
+		Actual:      len(actual),
+		Actions:     []PlanAction{},
+		Deferred:    []PlanDeferral{},
+		desired:     desired,
+		hibernating: make(map[string]beadsapi.AgentBead),
+		cancelled:   make(map[string]beadsapi.AgentBead),
+		verified:    make(map[string]bool),
+	}
+	awake := maps.Clone(desired)
+	actual = maps.Clone(actual)
+	p.awake = awake
+
+	// Delegated agents whose parent agent was closed are cancelled with it.
+	for _, name := range sortedNames(orphanedDelegates(desired)) {
+		bead := desired[name]
+		if r.projectPaused(bead.Project) {
+			continue
+		}
+		if pod, ok := actual[name]; ok && pod.DeletionTimestamp == nil {
+			p.Actions = append(p.Actions, podAction(ActionDelete, name, &pod, bead, CancelledReason))
+		}
+		delete(awake, name)
+		delete(actual, name)
+		p.cancelled[name] = bead
+		p.Cancelled = append(p.Cancelled, name)
+	}
+
+	// Agents outside their schedule lose their pods and drop out of the
+	// rest of the pass, which neither recreates nor counts them.
+	now := r.now()
+	for _, name := range sortedNames(desired) {
+		bead := desired[name]
+		if _, ok := p.cancelled[name]; ok || r.projectPaused(bead.Project) {
+			continue
+		}
+		if sched := r.scheduleFor(name, bead); sched == nil || sched.Active(now) {
+			continue
+		}
+		if pod, ok := actual[name]; ok && pod.DeletionTimestamp == nil {
+			p.Actions = append(p.Actions, podAction(ActionDelete, name, &pod, bead, "outside schedule"))
+		}
+		delete(awake, name)
+		delete(actual, name)
+		p.hibernating[name] = bead
+		p.Hibernating = append(p.Hibernating, name)
+	}
+
+	p.awakeActual = len(actual)
+
+	// Delete copies of an agent's pod left on a cluster it moved away from.
+	if _, ok := r.pods.(clusterPodDeleter); ok {
+		for _, pod := range strays {
+			if r.projectPaused(pod.Labels[podmanager.LabelProject]) {
+				continue
+			}
+			a := podAction(ActionDelete, podmanager.AgentPodName(&pod), &pod, awake[podmanager.AgentPodName(&pod)], "duplicate of a pod on another cluster")
+			a.stray = true
+			p.Actions = append(p.Actions, a)
+		}
+	}
+
+	// Delete orphan pods (exist in K8s but not in desired).
+	// Guard: if daemon returned zero beads but pods exist, this is likely a
+	// transient daemon issue (restart, query race, etc.). Refuse to mass-delete
+	// to prevent an "orphan storm" that kills all agent pods.
+	if len(awake) == 0 && len(actual) > 0 {
+		p.OrphanGuard = true
+	} else {
+		for _, name := range sortedNames(actual) {
+			if _, ok := awake[name]; ok {
+				continue
+			}
+			pod := actual[name]
+			project := pod.Labels[podmanager.LabelProject]
+			if r.projectPaused(project) {
+				p.Deferred = append(p.Deferred, PlanDeferral{
+					Pod: name, Project: project, Agent: pod.Labels[podmanager.LabelAgent],
+					Reason: "orphan kept: reconcile paused for project",
+				})
+				continue
+			}
+			// Agents told to checkpoint keep their pod until the grace
+			// window ends or they exit.
+			if until, ok := podmanager.TerminateAfter(&pod); ok && r.now().Before(until) && !isTerminal(&pod) {
+				p.Deferred = append(p.Deferred, PlanDeferral{
+					Pod: name, Project: project, Agent: pod.Labels[podmanager.LabelAgent],
+					BeadID: pod.Annotations[podmanager.AnnotationBeadID],
+					Reason: "orphan kept: terminating until " + until.UTC().Format(time.RFC3339),
+				})
+				continue
+			}
+			p.Actions = append(p.Actions, podAction(ActionDelete, name, &pod, beadsapi.AgentBead{}, "orphan: no agent bead"))
+		}
+	}
+
+	// Count active (non-terminal, non-orphan) pods for concurrency limiting.
+	// Exclude both Failed and Succeeded pods — they are terminal and will be
+	// deleted+recreated below.
+	for name, pod := range actual {
+		if _, inDesired := awake[name]; inDesired && !isTerminal(&pod) {
+			p.active++
+		}
+	}
+
+	// Clean stale upgrade entries (pods deleted but never recreated).
+	r.upgradeTracker.CleanStaleUpgrades(10 * time.Minute)
+	r.upgradeTracker.Reset()
+
+	// Scan all pods for drift and register with the upgrade tracker, so
+	// it has the full picture before any upgrade decision.
+	driftReasons := make(map[string]string)
+	driftImages := make(map[string]string)
+	for _, name := range sortedNames(awake) {
+		bead := awake[name]
+		pod, exists := actual[name]
+		if !exists || isTerminal(&pod) || r.projectPaused(bead.Project) || r.missingTemplate(bead) != "" {
+			continue
+		}
+		desiredSpec := r.specBuilder(r.cfg, bead.Project, bead.Mode, bead.Role, bead.AgentName, bead.Metadata)
+		desiredSpec.BeadID = bead.ID
+		if reason := podDriftReason(desiredSpec, &pod, r.digestTracker); reason != "" {
+			driftReasons[name] = reason
+			driftImages[name] = desiredSpec.Image
+			r.upgradeTracker.RegisterDrift(name, bead.Mode)
+		}
+		// Clear upgrade tracking for pods that have been successfully recreated.
+		if IsPodReady(&pod) {
+			r.upgradeTracker.ClearUpgrading(name)
+		}
+	}
+
+	// Create missing pods and recreate failed pods, most urgent first.
+	// Respect CoopBurstLimit (max pods created per pass) and
+	// CoopMaxPods (total active pod cap); beads left waiting are told
+	// their place in the queue.
+	p.burstLimit = r.cfg.CoopBurstLimit
+	if p.burstLimit <= 0 {
+		p.burstLimit = 3 // safety default
+	}
+	created := 0
+	upgrading := make(map[string]bool) // modes with an upgrade planned this pass
+	adm := &admission{r: r}
+	for _, name := range spawnOrder(awake) {
+		bead := awake[name]
+		if r.projectPaused(bead.Project) {
+			continue // Operator has paused reconciliation for this project.
+		}
+		deferral := PlanDeferral{Pod: name, Project: bead.Project, Agent: bead.AgentName, BeadID: bead.ID}
+
+		var replace *corev1.Pod
+		reason := "missing pod"
+		upgrade := false
+		if pod, exists := actual[name]; exists {
+			switch {
+			case isTerminal(&pod):
+				replace, reason = &pod, fmt.Sprintf("pod %s", pod.Status.Phase)
+			case driftReasons[name] != "":
+				reason = driftReasons[name]
+				// Role-aware upgrade strategy: one upgrade per mode at a time.
+				if !r.upgradeTracker.CanUpgrade(name, bead.Mode) || upgrading[bead.Mode] {
+					deferral.Reason = "upgrade deferred by strategy: " + reason
+					p.Deferred = append(p.Deferred, deferral)
+					continue
+				}
+				// Keep the running pod until its replacement's image verifies.
+				if err := r.verifyImage(ctx, driftImages[name]); err != nil {
+					p.deferUnsigned(deferral, driftImages[name], err)
+					continue
+				}
+				p.verified[name] = true
+				upgrading[bead.Mode] = true
+				replace, upgrade = &pod, true
+				p.active-- // no longer active after deletion
+			default:
+				continue
+			}
+		}
+
+		// deferCreate defers the new pod, still deleting the one it
+		// replaces as a pass always has.
+		deferCreate := func(d PlanDeferral, queued bool) {
+			if replace != nil {
+				a := podAction(ActionDelete, name, replace, bead, reason+"; replacement deferred")
+				a.upgrade = upgrade
+				p.Actions = append(p.Actions, a)
+			}
+			p.deferral(d, queued)
+		}
+
+		project := r.cfg.ProjectCache.Load()[bead.Project]
+		if state := project.Onboarding; !beadsapi.OnboardingAllowsAgents(state) {
+			deferral.Reason = "project onboarding " + state
+			deferCreate(deferral, false)
+			continue
+		}
+		// Upgrades replace a running agent; only new pods wait for budget.
+		if project.BudgetPaused && !upgrade {
+			deferral.Reason = "project daily budget exhausted"
+			deferCreate(deferral, false)
+			continue
+		}
+		if tmpl := r.missingTemplate(bead); tmpl != "" {
+			deferral.Reason = fmt.Sprintf("template %s not found", tmpl)
+			deferCreate(deferral, false)
+			continue
+		}
+		if created >= p.burstLimit {
+			deferral.Reason = fmt.Sprintf("spawn burst limit reached (%d)", p.burstLimit)
+			deferCreate(deferral, true)
+			continue
+		}
+		if r.cfg.CoopMaxPods > 0 && p.active >= r.cfg.CoopMaxPods {
+			deferral.Reason = fmt.Sprintf("max concurrent pods reached (%d)", r.cfg.CoopMaxPods)
+			deferCreate(deferral, true)
+			continue
+		}
+
+		spec := r.specBuilder(r.cfg, bead.Project, bead.Mode, bead.Role, bead.AgentName, bead.Metadata)
+		spec.BeadID = bead.ID
+		if err := r.verifyImage(ctx, spec.Image); err != nil {
+			if replace != nil {
+				a := podAction(ActionDelete, name, replace, bead, reason+"; replacement image unverified")
+				a.upgrade = upgrade
+				p.Actions = append(p.Actions, a)
+			}
+			p.deferUnsigned(deferral, spec.Image, err)
+			continue
+		}
+		p.verified[name] = true
+		if why := adm.admit(ctx, spec); why != "" {
+			deferral.Reason = "insufficient cluster capacity: " + why
+			deferral.State = WaitingCapacityState
+			deferCreate(deferral, true)
+			continue
+		}
+
+		kind := ActionCreate
+		if replace != nil {
+			kind = ActionRestart
+		}
+		a := podAction(kind, name, replace, bead, reason)
+		if replace == nil {
+			a.Pod, a.Namespace = name, spec.Namespace
+		}
+		a.spec, a.Image, a.upgrade = &spec, spec.Image, upgrade
+		p.Actions = append(p.Actions, a)
+		created++
+		p.active++
+	}
+	return p
+}
+
+// Upgrade reports whether a restart replaces a pod whose image drifted.
+func (a PlanAction) Upgrade() bool {
+	return a.upgrade
+}
+
+// ActionRecorder is told about every pod the reconciler creates, e.g. to
+// write a Kubernetes Event for the spawn, restart or upgrade.
+type ActionRecorder interface {
+	// RecordAction is called once a's pod was created; a.Pod and
+	// a.Namespace name the new pod.
+	RecordAction(ctx context.Context, a PlanAction)
+}
+
+// SetActionRecorder reports the pods created by reconcile passes to rec.
+func (r *Reconciler) SetActionRecorder(rec ActionRecorder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recorder = rec
+}
+
+// podAction builds an action on pod (nil for a create) for bead's agent.
+func podAction(kind ActionKind, name string, pod *corev1.Pod, bead beadsapi.AgentBead, reason string) PlanAction {
+	a := PlanAction{Kind: kind, Pod: name, Project: bead.Project, Agent: bead.AgentName, BeadID: bead.ID, Reason: reason, name: name}
+	if pod != nil {
+		a.Pod, a.Namespace = pod.Name, pod.Namespace
+		a.Cluster = pod.Labels[podmanager.LabelCluster]
+		if a.Project == "" {
+			a.Project, a.Agent = pod.Labels[podmanager.LabelProject], pod.Labels[podmanager.LabelAgent]
+		}
+	}
+	return a
+}
+
+// deferral records d, giving it the next spawn queue position if queued.
+func (p *ReconcilePlan) deferral(d PlanDeferral, queued bool) {
+	if queued {
+		p.queued++
+		d.QueuePosition = p.queued
+	}
+	p.Deferred = append(p.Deferred, d)
+}
+
+// deferUnsigned defers d until image has a verifiable signature.
+func (p *ReconcilePlan) deferUnsigned(d PlanDeferral, image string, err error) {
+	d.Reason = fmt.Sprintf("image signature not verified: %s: %v", image, err)
+	d.State = BlockedUnsignedImageState
+	d.image = image
+	p.Deferred = append(p.Deferred, d)
+}
+
+// apply executes p and records the bead state it implies: hibernating,
+// waiting_capacity and blocked_unsigned_image marks and spawn queue
+// positions.
+func (r *Reconciler) apply(ctx context.Context, p *ReconcilePlan) error {
+	for name := range r.asleep {
+		if _, ok := p.desired[name]; !ok {
+			delete(r.asleep, name)
+		}
+	}
+	for name := range r.badSchedules {
+		if _, ok := p.desired[name]; !ok {
+			delete(r.badSchedules, name)
+		}
+	}
+	for name, bead := range p.awake {
+		if !r.projectPaused(bead.Project) {
+			delete(r.asleep, name)
+		}
+	}
+	for name := range r.waiting {
+		if _, ok := p.awake[name]; !ok {
+			delete(r.waiting, name)
+		}
+	}
+	for name := range r.blocked {
+		if _, ok := p.awake[name]; !ok || p.verified[name] {
+			delete(r.blocked, name)
+		}
+	}
+
+	if len(p.Actions) > 0 {
+		r.logger.Info("applying reconcile plan",
+			"creates", p.Creates(), "deletes", p.Deletes(), "deferred", len(p.Deferred))
+	}
+	if p.OrphanGuard {
+		r.logger.Warn("desired state is empty but agent pods exist — skipping orphan deletion to prevent mass kill",
+			"actual_pods", p.awakeActual)
+	}
+
+	created := 0
+	for _, a := range p.Actions {
+		if a.Kind != ActionCreate {
+			if err := r.applyDelete(ctx, a); err != nil {
+				return err
+			}
+			if a.upgrade {
+				r.upgradeTracker.MarkUpgrading(a.name)
+			}
+		}
+		if a.Kind == ActionDelete {
+			continue
+		}
+		delete(r.waiting, a.name)
+		r.logger.Info("creating pod", "pod", a.name, "reason", a.Reason)
+		if err := r.pods.CreateAgentPod(ctx, *a.spec); errors.Is(err, policy.ErrDenied) {
+			// A rejected spec shouldn't hold up the other agents.
+			r.logger.Warn("pod creation denied by policy", "pod", a.name, "error", err)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("creating pod %s: %w", a.name, err)
+		}
+		// Mark the image as deployed so digest drift is cleared.
+		if r.digestTracker != nil && a.spec.Image != "" {
+			r.digestTracker.MarkDeployed(a.spec.Image)
+		}
+		if r.recorder != nil {
+			done := a
+			done.Pod, done.Namespace = a.spec.PodName(), a.spec.Namespace
+			r.recorder.RecordAction(ctx, done)
+		}
+		created++
+	}
+
+	for _, name := range p.Hibernating {
+		r.markHibernating(ctx, name, p.hibernating[name])
+	}
+	for _, name := range p.Cancelled {
+		r.closeCancelled(ctx, name, p.cancelled[name])
+	}
+	var queue []string
+	for _, d := range p.Deferred {
+		r.logger.Info("deferring pod", "pod", d.Pod, "reason", d.Reason)
+		switch d.State {
+		case WaitingCapacityState:
+			r.markWaiting(ctx, d.Pod, p.awake[d.Pod])
+		case BlockedUnsignedImageState:
+			r.markBlocked(ctx, d.Pod, p.awake[d.Pod], d.image)
+		}
+		if d.QueuePosition > 0 {
+			queue = append(queue, d.Pod)
+		}
+	}
+	r.publishQueue(ctx, p.awake, queue)
+
+	if created > 0 || len(p.awake) > p.awakeActual {
+		r.logger.Info("reconcile pass complete",
+			"created", created, "deleted", p.Deletes(), "deferred", len(p.Deferred),
+			"active", p.active, "desired", len(p.awake), "burst_limit", p.burstLimit)
+	}
+	return nil
+}
+
+// applyDelete deletes the pod of a delete or restart action.
+func (r *Reconciler) applyDelete(ctx context.Context, a PlanAction) error {
+	r.logger.Info("deleting pod", "pod", a.Pod, "cluster", a.Cluster, "reason", a.Reason)
+	if a.stray {
+		if err := r.pods.(clusterPodDeleter).DeleteClusterPod(ctx, a.Cluster, a.Pod, a.Namespace); err != nil {
+			return fmt.Errorf("deleting duplicate pod %s on cluster %s: %w", a.Pod, a.Cluster, err)
+		}
+		return nil
+	}
+	if err := r.pods.DeleteAgentPod(ctx, a.Pod, a.Namespace); err != nil {
+		return fmt.Errorf("deleting pod %s (%s): %w", a.name, a.Reason, err)
+	}
+	return nil
+}
+
+// sortedNames returns the keys of m in order, so plans are deterministic.
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
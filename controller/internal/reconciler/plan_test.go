@@ -0,0 +1,257 @@
+package reconciler
+
+import (
+	"context"
+	"maps"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+func TestPlan_ChangesNothing(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+		{ID: "bd-2", Project: "proj", Mode: "crew", Role: "dev", AgentName: "beta"},
+		{ID: "bd-3", Project: "proj", Mode: "crew", Role: "dev", AgentName: "gamma"},
+	}}}
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-beta", "ns", "crew", "proj", "dev", "beta", corev1.PodFailed),
+		makePod("crew-proj-dev-gone", "ns", "crew", "proj", "dev", "gone", corev1.PodRunning),
+	}}
+	cfg := testConfig("ns")
+	cfg.CoopBurstLimit = 1
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+
+	plan, err := r.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 0 || len(mgr.deleted) != 0 || len(lister.updates) != 0 {
+		t.Fatalf("Plan changed state: created %d, deleted %v, updated %v", len(mgr.created), mgr.deleted, lister.updates)
+	}
+	if r.LastPlan() != nil {
+		t.Error("Plan recorded as the latest pass")
+	}
+
+	// The orphan goes first, then one creation within the burst limit; the
+	// failed pod is deleted although its replacement is deferred.
+	want := []struct {
+		kind   ActionKind
+		pod    string
+		reason string
+	}{
+		{ActionDelete, "crew-proj-dev-gone", "orphan"},
+		{ActionCreate, "crew-proj-dev-alpha", "missing pod"},
+		{ActionDelete, "crew-proj-dev-beta", "pod Failed; replacement deferred"},
+	}
+	if len(plan.Actions) != len(want) {
+		t.Fatalf("actions = %+v, want %d", plan.Actions, len(want))
+	}
+	for i, w := range want {
+		a := plan.Actions[i]
+		if a.Kind != w.kind || a.Pod != w.pod || !strings.HasPrefix(a.Reason, w.reason) {
+			t.Errorf("action %d = %s %s (%s), want %s %s (%s...)", i, a.Kind, a.Pod, a.Reason, w.kind, w.pod, w.reason)
+		}
+	}
+	if len(plan.Deferred) != 2 || plan.Deferred[0].QueuePosition != 1 || plan.Deferred[1].QueuePosition != 2 {
+		t.Errorf("deferred = %+v, want beta and gamma queued", plan.Deferred)
+	}
+	if plan.Creates() != 1 || plan.Deletes() != 2 {
+		t.Errorf("creates = %d, deletes = %d", plan.Creates(), plan.Deletes())
+	}
+
+	// Applying it does exactly that.
+	if err := r.Apply(context.Background(), plan); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 1 || mgr.created[0].AgentName != "alpha" {
+		t.Errorf("created %+v, want alpha", mgr.created)
+	}
+	if len(mgr.deleted) != 2 {
+		t.Errorf("deleted %v, want the orphan and the failed pod", mgr.deleted)
+	}
+	if lister.updates["bd-3"][QueuePositionField] != "2" {
+		t.Errorf("queue positions = %v", lister.updates)
+	}
+}
+
+func TestPlan_RestartsDriftedPod(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+	}}
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", corev1.PodRunning),
+	}}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v2"))
+
+	plan, err := r.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Kind != ActionRestart || plan.Actions[0].Image != "ghcr.io/org/agent:v2" ||
+		!strings.Contains(plan.Actions[0].Reason, "agent image changed") {
+		t.Fatalf("actions = %+v, want one restart onto v2", plan.Actions)
+	}
+}
+
+func TestReconcile_RecordsLastPlan(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+	}}
+	mgr := &mockManager{}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("img:v1"))
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	plan := r.LastPlan()
+	if plan == nil || len(plan.Actions) != 1 || plan.Actions[0].Kind != ActionCreate || plan.Error != "" {
+		t.Fatalf("last plan = %+v", plan)
+	}
+
+	mgr.createErr = context.DeadlineExceeded
+	mgr.pods = nil
+	if err := r.Reconcile(context.Background()); err == nil {
+		t.Fatal("expected the failed create to fail the pass")
+	}
+	if plan := r.LastPlan(); plan == nil || plan.Error == "" {
+		t.Errorf("failed pass not recorded in the last plan: %+v", plan)
+	}
+}
+
+func TestPlan_HoldsBackAgentsOfProjectsOnboarding(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "new", Mode: "crew", Role: "dev", AgentName: "alpha"},
+		{ID: "bd-2", Project: "old", Mode: "crew", Role: "dev", AgentName: "beta"},
+	}}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{
+		"new": {Onboarding: beadsapi.OnboardingRunning},
+		"old": {},
+	})
+	r := New(lister, &mockManager{}, cfg, testLogger(), simpleSpecBuilder("img:v1"))
+
+	plan, err := r.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Agent != "beta" {
+		t.Errorf("actions = %+v, want only beta created", plan.Actions)
+	}
+	if len(plan.Deferred) != 1 || plan.Deferred[0].Reason != "project onboarding running" || plan.Deferred[0].QueuePosition != 0 {
+		t.Errorf("deferred = %+v, want alpha held back unqueued", plan.Deferred)
+	}
+
+	cache := maps.Clone(cfg.ProjectCache.Load())
+	cache["new"] = config.ProjectCacheEntry{Onboarding: beadsapi.OnboardingPassed}
+	cfg.ProjectCache.Store(cache)
+	if plan, _ := r.Plan(context.Background()); plan.Creates() != 2 {
+		t.Errorf("creates after onboarding passed = %d, want 2", plan.Creates())
+	}
+}
+
+func TestPlan_HoldsBackAgentsOverBudget(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "spendy", Mode: "crew", Role: "dev", AgentName: "alpha"},
+		{ID: "bd-2", Project: "frugal", Mode: "crew", Role: "dev", AgentName: "beta"},
+	}}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{
+		"spendy": {BudgetPaused: true},
+		"frugal": {},
+	})
+	r := New(lister, &mockManager{}, cfg, testLogger(), simpleSpecBuilder("img:v1"))
+
+	plan, err := r.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Actions) != 1 || plan.Actions[0].Agent != "beta" {
+		t.Errorf("actions = %+v, want only beta created", plan.Actions)
+	}
+	if len(plan.Deferred) != 1 || plan.Deferred[0].Reason != "project daily budget exhausted" {
+		t.Errorf("deferred = %+v, want alpha held back for budget", plan.Deferred)
+	}
+}
+
+func TestPlan_ResolvesAgentTemplates(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "p", Mode: "job", Role: "job", AgentName: "alpha",
+			Metadata: map[string]string{beadsapi.TemplateField: "reviewer"}},
+		{ID: "bd-2", Project: "p", Mode: "job", Role: "job", AgentName: "beta",
+			Metadata: map[string]string{beadsapi.TemplateField: "reviewer", "image": "own:v1"}},
+		{ID: "bd-3", Project: "p", Mode: "job", Role: "job", AgentName: "gamma",
+			Metadata: map[string]string{beadsapi.TemplateField: "gone"}},
+	}}
+	cfg := testConfig("ns")
+	cfg.TemplateCache = config.NewSnapshot(map[string]beadsapi.TemplateInfo{
+		"reviewer": {Name: "reviewer", Fields: map[string]string{"image": "reviewer:v2"}},
+	})
+	builder := func(cfg *config.Config, project, mode, role, agentName string, metadata map[string]string) podmanager.AgentPodSpec {
+		spec := simpleSpecBuilder("default:v1")(cfg, project, mode, role, agentName, metadata)
+		if img := metadata["image"]; img != "" {
+			spec.Image = img
+		}
+		return spec
+	}
+	r := New(lister, &mockManager{}, cfg, testLogger(), builder)
+
+	plan, err := r.Plan(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	images := make(map[string]string)
+	for _, a := range plan.Actions {
+		images[a.Agent] = a.Image
+	}
+	if images["alpha"] != "reviewer:v2" || images["beta"] != "own:v1" || len(images) != 2 {
+		t.Errorf("created images = %v, want alpha from the template and beta's own", images)
+	}
+	if len(plan.Deferred) != 1 || plan.Deferred[0].Agent != "gamma" || plan.Deferred[0].Reason != "template gone not found" {
+		t.Errorf("deferred = %+v, want gamma held back", plan.Deferred)
+	}
+}
+
+// actionLog records the actions reported to an ActionRecorder.
+type actionLog []PlanAction
+
+func (l *actionLog) RecordAction(_ context.Context, a PlanAction) { *l = append(*l, a) }
+
+func TestReconcile_RecordsCreatedPods(t *testing.T) {
+	lister := &mockLister{
+		beads: []beadsapi.AgentBead{
+			{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+			{ID: "bd-2", Project: "proj", Mode: "crew", Role: "dev", AgentName: "beta"},
+		},
+	}
+	mgr := &mockManager{
+		pods: []corev1.Pod{
+			makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", corev1.PodRunning),
+		},
+	}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("img:v2"))
+	var log actionLog
+	r.SetActionRecorder(&log)
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]PlanAction{}
+	for _, a := range log {
+		got[a.BeadID] = a
+	}
+	if len(log) != 2 {
+		t.Fatalf("recorded %d actions, want 2: %+v", len(log), log)
+	}
+	if a := got["bd-1"]; a.Kind != ActionRestart || !a.Upgrade() || a.Pod != "crew-proj-dev-alpha" || a.Namespace != "ns" {
+		t.Errorf("drifted pod recorded as %+v (upgrade %v), want an upgrade restart", a, a.Upgrade())
+	}
+	if a := got["bd-2"]; a.Kind != ActionCreate || a.Upgrade() || a.Pod != "crew-proj-dev-beta" {
+		t.Errorf("new pod recorded as %+v, want a create", a)
+	}
+}
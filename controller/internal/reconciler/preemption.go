@@ -0,0 +1,118 @@
+package reconciler
+
+import (
+	"context"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+// PreemptionsField is the agent bead field counting how many times the
+// agent's spot pod has been preempted.
+const PreemptionsField = "preemptions"
+
+// Preemptions returns the preemption count from agent bead metadata.
+func Preemptions(metadata map[string]string) int {
+	n, _ := strconv.Atoi(metadata[PreemptionsField])
+	return n
+}
+
+// Checkpointer asks an agent to save its session before its pod is lost.
+type Checkpointer interface {
+	Checkpoint(ctx context.Context, pod *corev1.Pod) error
+}
+
+// SetCheckpointer sets what is called when a running spot pod is about to
+// be preempted. Without one, preemptions are only counted.
+func (r *Reconciler) SetCheckpointer(c Checkpointer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkpointer = c
+}
+
+// beadFieldUpdater is implemented by bead listers that can also write bead
+// fields (beadsapi.Client).
+type beadFieldUpdater interface {
+	UpdateBeadFields(ctx context.Context, beadID string, fields map[string]string) error
+}
+
+// trackPreemptions checkpoints spot pods that are about to be preempted and
+// counts each preemption once, on the pod's terminal state or, if the pod
+// vanished with its node, on its absence. Counts are written to the agent
+// bead and into desired, so the specs built this pass already see them.
+func (r *Reconciler) trackPreemptions(ctx context.Context, desired map[string]beadsapi.AgentBead, actual map[string]corev1.Pod) {
+	for name := range r.preempting {
+		if _, ok := desired[name]; !ok {
+			delete(r.preempting, name)
+		}
+	}
+	for name := range r.counted {
+		if _, ok := desired[name]; !ok {
+			delete(r.counted, name)
+		}
+	}
+
+	for name, bead := range desired {
+		pod, exists := actual[name]
+		if !exists {
+			if uid, ok := r.preempting[name]; ok {
+				delete(r.preempting, name)
+				r.countPreemption(ctx, name, uid, "pod gone after preemption signal", desired)
+			}
+			continue
+		}
+		reason := podmanager.PreemptionReason(&pod)
+		if reason == "" {
+			continue
+		}
+		if isTerminal(&pod) {
+			delete(r.preempting, name)
+			r.countPreemption(ctx, name, pod.UID, reason, desired)
+			continue
+		}
+		if r.preempting[name] == pod.UID {
+			continue
+		}
+		r.preempting[name] = pod.UID
+		r.logger.Info("spot pod is being preempted", "pod", name, "bead", bead.ID, "reason", reason)
+		if r.checkpointer != nil {
+			if err := r.checkpointer.Checkpoint(ctx, &pod); err != nil {
+				r.logger.Warn("failed to checkpoint preempted agent", "pod", name, "error", err)
+			}
+		}
+	}
+}
+
+// countPreemption increments the agent's preemption count unless the pod
+// with uid was already counted.
+func (r *Reconciler) countPreemption(ctx context.Context, name string, uid types.UID, reason string, desired map[string]beadsapi.AgentBead) {
+	if r.counted[name] == uid {
+		return
+	}
+	r.counted[name] = uid
+
+	bead := desired[name]
+	n := Preemptions(bead.Metadata) + 1
+	meta := make(map[string]string, len(bead.Metadata)+1)
+	for k, v := range bead.Metadata {
+		meta[k] = v
+	}
+	meta[PreemptionsField] = strconv.Itoa(n)
+	bead.Metadata = meta
+	desired[name] = bead
+
+	r.logger.Info("agent pod preempted", "pod", name, "bead", bead.ID, "reason", reason, "preemptions", n)
+	if r.cfg.Spot != nil && n >= r.cfg.Spot.MaxPreemptions {
+		r.logger.Warn("agent preempted repeatedly, rescheduling onto on-demand capacity",
+			"pod", name, "preemptions", n, "max", r.cfg.Spot.MaxPreemptions)
+	}
+	if u, ok := r.lister.(beadFieldUpdater); ok {
+		if err := u.UpdateBeadFields(ctx, bead.ID, map[string]string{PreemptionsField: strconv.Itoa(n)}); err != nil {
+			r.logger.Warn("failed to record preemption on agent bead", "bead", bead.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+// updatingLister is a mockLister that records bead field updates, merged
+// per bead.
+type updatingLister struct {
+	mockLister
+	updates map[string]map[string]string
+}
+
+func (m *updatingLister) UpdateBeadFields(_ context.Context, beadID string, fields map[string]string) error {
+	if m.updates == nil {
+		m.updates = make(map[string]map[string]string)
+	}
+	if m.updates[beadID] == nil {
+		m.updates[beadID] = make(map[string]string)
+	}
+	for k, v := range fields {
+		m.updates[beadID][k] = v
+	}
+	return nil
+}
+
+type recordingCheckpointer struct{ pods []string }
+
+func (c *recordingCheckpointer) Checkpoint(_ context.Context, pod *corev1.Pod) error {
+	c.pods = append(c.pods, pod.Name)
+	return nil
+}
+
+func spotPod(uid string, phase corev1.PodPhase, preempted bool) corev1.Pod {
+	p := makePod("job-proj-job-j1", "ns", "job", "proj", "job", "j1", phase)
+	p.UID = types.UID(uid)
+	p.Labels[podmanager.LabelCapacity] = podmanager.CapacitySpot
+	if preempted {
+		p.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue, Reason: "DeletionByTaintManager",
+		}}
+	}
+	return p
+}
+
+// spotSpecBuilder records the preemption count each spec was built with.
+func spotSpecBuilder(seen *[]int) SpecBuilder {
+	return func(cfg *config.Config, project, mode, role, agentName string, metadata map[string]string) podmanager.AgentPodSpec {
+		*seen = append(*seen, Preemptions(metadata))
+		return simpleSpecBuilder("ghcr.io/org/agent:v1")(cfg, project, mode, role, agentName, metadata)
+	}
+}
+
+func TestReconcile_Preemption_CheckpointsThenCountsOnce(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-j1", Project: "proj", Mode: "job", Role: "job", AgentName: "j1",
+			Metadata: map[string]string{PreemptionsField: "1"}},
+	}}}
+	mgr := &mockManager{pods: []corev1.Pod{spotPod("uid-1", corev1.PodRunning, true)}}
+	cp := &recordingCheckpointer{}
+	var seen []int
+	r := New(lister, mgr, testConfig("ns"), testLogger(), spotSpecBuilder(&seen))
+	r.SetCheckpointer(cp)
+	ctx := context.Background()
+
+	// Signal on a running pod: checkpoint once, nothing counted yet.
+	for range 2 {
+		if err := r.Reconcile(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(cp.pods) != 1 {
+		t.Errorf("checkpoints = %v, want one", cp.pods)
+	}
+	if lister.updates != nil {
+		t.Errorf("counted a preemption before the pod stopped: %v", lister.updates)
+	}
+
+	// The pod terminates: counted and recreated with the new count.
+	mgr.pods = []corev1.Pod{spotPod("uid-1", corev1.PodFailed, true)}
+	seen = nil
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := lister.updates["bd-j1"][PreemptionsField]; got != "2" {
+		t.Errorf("preemptions written = %q, want 2", got)
+	}
+	if len(mgr.created) != 1 || len(seen) == 0 || seen[len(seen)-1] != 2 {
+		t.Errorf("expected recreation built with 2 preemptions, created=%d seen=%v", len(mgr.created), seen)
+	}
+
+	// The same terminal pod seen again isn't counted twice.
+	lister.updates = nil
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if lister.updates != nil {
+		t.Errorf("preemption counted twice: %v", lister.updates)
+	}
+}
+
+func TestReconcile_Preemption_CountsPodThatVanished(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-j1", Project: "proj", Mode: "job", Role: "job", AgentName: "j1"},
+	}}}
+	mgr := &mockManager{pods: []corev1.Pod{spotPod("uid-1", corev1.PodRunning, true)}}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+	ctx := context.Background()
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The node was reclaimed and the pod garbage-collected.
+	mgr.pods = nil
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := lister.updates["bd-j1"][PreemptionsField]; got != "1" {
+		t.Errorf("preemptions written = %q, want 1", got)
+	}
+}
+
+func TestReconcile_Preemption_IgnoresOrdinaryFailures(t *testing.T) {
+	lister := &updatingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-j1", Project: "proj", Mode: "job", Role: "job", AgentName: "j1"},
+	}}}
+	mgr := &mockManager{pods: []corev1.Pod{spotPod("uid-1", corev1.PodFailed, false)}}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if lister.updates != nil {
+		t.Errorf("crash counted as preemption: %v", lister.updates)
+	}
+}
@@ -0,0 +1,72 @@
+package reconciler
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// QueuePositionField is the agent bead field holding a deferred agent's
+// 1-based position in the spawn queue. It is cleared once the pod is created.
+const QueuePositionField = "queue_position"
+
+// spawnOrder returns the desired pod names in the order pods are created:
+// most urgent bead priority first (0 = critical), then oldest bead, then
+// name so the order is stable.
+func spawnOrder(desired map[string]beadsapi.AgentBead) []string {
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := desired[names[i]], desired[names[j]]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// publishQueue writes each deferred bead's position in the spawn queue and
+// clears it from beads that have left the queue. Only changed positions are
+// written.
+func (r *Reconciler) publishQueue(ctx context.Context, desired map[string]beadsapi.AgentBead, deferred []string) {
+	u, ok := r.lister.(beadFieldUpdater)
+	if !ok {
+		return
+	}
+	positions := make(map[string]int, len(deferred))
+	for i, name := range deferred {
+		positions[name] = i + 1
+	}
+
+	for name, pos := range positions {
+		if r.queued[name] == pos {
+			continue
+		}
+		bead := desired[name]
+		if err := u.UpdateBeadFields(ctx, bead.ID, map[string]string{QueuePositionField: strconv.Itoa(pos)}); err != nil {
+			r.logger.Warn("failed to write spawn queue position", "bead", bead.ID, "error", err)
+			continue
+		}
+		r.queued[name] = pos
+	}
+	for name := range r.queued {
+		if _, still := positions[name]; still {
+			continue
+		}
+		if bead, ok := desired[name]; ok {
+			if err := u.UpdateBeadFields(ctx, bead.ID, map[string]string{QueuePositionField: ""}); err != nil {
+				r.logger.Warn("failed to clear spawn queue position", "bead", bead.ID, "error", err)
+				continue
+			}
+		}
+		delete(r.queued, name)
+	}
+}
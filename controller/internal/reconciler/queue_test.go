@@ -0,0 +1,60 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestReconcile_SpawnsByPriorityThenAge(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	lister := &updatingLister{mockLister: mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-old", Project: "proj", Mode: "crew", Role: "dev", AgentName: "old", Priority: 2, CreatedAt: t0},
+		{ID: "bd-new", Project: "proj", Mode: "crew", Role: "dev", AgentName: "new", Priority: 2, CreatedAt: t0.Add(time.Hour)},
+		{ID: "bd-inc", Project: "proj", Mode: "job", Role: "job", AgentName: "inc", Priority: 0, CreatedAt: t0.Add(2 * time.Hour)},
+	}}}
+	mgr := &mockManager{}
+	cfg := testConfig("ns")
+	cfg.CoopBurstLimit = 1
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder("img"))
+	ctx := context.Background()
+
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 1 || mgr.created[0].AgentName != "inc" {
+		t.Fatalf("created = %v, want the P0 agent first", mgr.created)
+	}
+	if got := lister.updates["bd-old"][QueuePositionField]; got != "1" {
+		t.Errorf("bd-old queue position = %q, want 1", got)
+	}
+	if got := lister.updates["bd-new"][QueuePositionField]; got != "2" {
+		t.Errorf("bd-new queue position = %q, want 2", got)
+	}
+	if _, ok := lister.updates["bd-inc"]; ok {
+		t.Error("spawned bead was given a queue position")
+	}
+
+	// Next pass: the older agent spawns and leaves the queue; the newer
+	// one moves up.
+	for _, spec := range mgr.created {
+		mgr.pods = append(mgr.pods, makePod(spec.PodName(), "ns", spec.Mode, spec.Project, spec.Role, spec.AgentName, corev1.PodRunning))
+	}
+	mgr.created = nil
+	if err := r.Reconcile(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.created) != 1 || mgr.created[0].AgentName != "old" {
+		t.Fatalf("created = %v, want the oldest P2 agent", mgr.created)
+	}
+	if got := lister.updates["bd-old"][QueuePositionField]; got != "" {
+		t.Errorf("bd-old queue position = %q, want cleared", got)
+	}
+	if got := lister.updates["bd-new"][QueuePositionField]; got != "1" {
+		t.Errorf("bd-new queue position = %q, want 1", got)
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"gasboat/controller/internal/beadsapi"
 	"gasboat/controller/internal/config"
@@ -31,6 +33,28 @@ type Reconciler struct {
 	mu             sync.Mutex // prevent concurrent reconciles
 	digestTracker  *ImageDigestTracker
 	upgradeTracker *UpgradeTracker
+	lastSuccess    atomic.Int64 // unix nanos of the last pass that returned nil
+	lastPlan       atomic.Pointer[ReconcilePlan]
+	history        *History
+
+	checkpointer Checkpointer
+	preempting   map[string]types.UID // pod name → UID of a live pod being preempted
+	counted      map[string]types.UID // pod name → UID of the last pod counted as preempted
+
+	capacity CapacitySource
+	waiting  map[string]bool // pod names whose beads are marked waiting_capacity
+	queued   map[string]int  // pod name → spawn queue position last written
+
+	now          func() time.Time
+	asleep       map[string]bool   // pod names whose beads are marked hibernating
+	badSchedules map[string]string // pod name → invalid schedule already warned about
+
+	verifier ImageVerifier
+	blocked  map[string]string // pod name → unverified image its bead is blocked on
+
+	recorder ActionRecorder
+
+	workspacesAdopted bool // legacy workspace PVCs tied to their beads
 }
 
 // New creates a Reconciler.
@@ -49,6 +73,14 @@ func New(
 		specBuilder:    specBuilder,
 		digestTracker:  NewImageDigestTracker(logger),
 		upgradeTracker: NewUpgradeTracker(logger),
+		preempting:     make(map[string]types.UID),
+		counted:        make(map[string]types.UID),
+		waiting:        make(map[string]bool),
+		queued:         make(map[string]int),
+		now:            time.Now,
+		asleep:         make(map[string]bool),
+		badSchedules:   make(map[string]string),
+		blocked:        make(map[string]string),
 	}
 }
 
@@ -61,21 +93,62 @@ func (r *Reconciler) DigestTracker() *ImageDigestTracker {
 // Reconcile performs a single reconciliation pass:
 // 1. List desired beads from daemon
 // 2. List actual pods from K8s
-// 3. Create missing pods, delete orphan pods, recreate failed pods
+// 3. Plan creates, deletes and restarts (see Plan) and apply the plan
+// 4. Record the pass in the history, if one is kept
 func (r *Reconciler) Reconcile(ctx context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	start := time.Now()
+	desired, actualMap, strays, err := r.observe(ctx)
+	if err != nil {
+		r.history.record(ctx, &ReconcilePlan{At: r.now(), Error: err.Error()}, time.Since(start))
+		return err
+	}
+	r.trackPreemptions(ctx, desired, actualMap)
+	r.adoptWorkspaces(ctx, desired)
+	plan := r.plan(ctx, desired, actualMap, strays)
+	err = r.apply(ctx, plan)
+	if err != nil {
+		plan.Error = err.Error()
+	}
+	r.lastPlan.Store(plan)
+	r.history.record(ctx, plan, time.Since(start))
+	if err != nil {
+		return err
+	}
+
+	r.lastSuccess.Store(time.Now().UnixNano())
+	return nil
+}
+
+// LastSuccess returns when a reconcile pass last completed without error,
+// or the zero time if none has.
+func (r *Reconciler) LastSuccess() time.Time {
+	n := r.lastSuccess.Load()
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// observe lists desired agent beads and actual agent pods, keyed by pod name.
+// When a pod name exists on more than one cluster, one pod is kept (see
+// preferPod) and the others are returned as strays.
+func (r *Reconciler) observe(ctx context.Context) (map[string]beadsapi.AgentBead, map[string]corev1.Pod, []corev1.Pod, error) {
 	// Get desired state from daemon.
 	beads, err := r.lister.ListAgentBeads(ctx)
 	if err != nil {
 		// Fail-safe: if we can't reach the daemon, do NOT delete any pods.
-		return fmt.Errorf("listing agent beads: %w", err)
+		return nil, nil, nil, fmt.Errorf("listing agent beads: %w", err)
 	}
 
-	// Build desired pod name set.
+	// Build desired pod name set, filling in template fields. Beads whose
+	// template is missing keep their own fields; plan holds them back.
 	desired := make(map[string]beadsapi.AgentBead)
+	templates := r.cfg.TemplateCache.Load()
 	for _, b := range beads {
+		b.Metadata, _ = beadsapi.ResolveTemplate(b.Metadata, templates)
 		podName := fmt.Sprintf("%s-%s-%s-%s", b.Mode, b.Project, b.Role, b.AgentName)
 		desired[podName] = b
 	}
@@ -85,10 +158,11 @@ func (r *Reconciler) Reconcile(ctx context.Context) error {
 		podmanager.LabelApp: podmanager.LabelAppValue,
 	})
 	if err != nil {
-		return fmt.Errorf("listing agent pods: %w", err)
+		return nil, nil, nil, fmt.Errorf("listing agent pods: %w", err)
 	}
 
 	actualMap := make(map[string]corev1.Pod)
+	var strays []corev1.Pod
 	for _, p := range actual {
 		// Only consider pods with the gasboat.io/agent label — this
 		// excludes the controller itself and other infrastructure pods
@@ -96,146 +170,84 @@ func (r *Reconciler) Reconcile(ctx context.Context) error {
 		if _, ok := p.Labels[podmanager.LabelAgent]; !ok {
 			continue
 		}
-		actualMap[p.Name] = p
-	}
-
-	// Delete orphan pods (exist in K8s but not in desired).
-	// Guard: if daemon returned zero beads but pods exist, this is likely a
-	// transient daemon issue (restart, query race, etc.). Refuse to mass-delete
-	// to prevent an "orphan storm" that kills all agent pods.
-	if len(desired) == 0 && len(actualMap) > 0 {
-		r.logger.Warn("desired state is empty but agent pods exist — skipping orphan deletion to prevent mass kill",
-			"actual_pods", len(actualMap))
-	} else {
-		for name, pod := range actualMap {
-			if _, ok := desired[name]; !ok {
-				r.logger.Info("deleting orphan pod", "pod", name)
-				if err := r.pods.DeleteAgentPod(ctx, name, pod.Namespace); err != nil {
-					return fmt.Errorf("deleting orphan pod %s: %w", name, err)
-				}
-			}
-		}
-	}
-
-	// Count active (non-terminal, non-orphan) pods for concurrency limiting.
-	// Only count pods that are in the desired set — orphans were just deleted.
-	// Exclude both Failed and Succeeded pods — they are terminal and will be
-	// deleted+recreated below.
-	activePods := 0
-	for name, pod := range actualMap {
-		if _, inDesired := desired[name]; inDesired &&
-			pod.Status.Phase != corev1.PodFailed &&
-			pod.Status.Phase != corev1.PodSucceeded {
-			activePods++
+		// Key by the agent's canonical name: pods adopted from the warm
+		// pool keep their pool name.
+		name := podmanager.AgentPodName(&p)
+		if prev, dup := actualMap[name]; dup {
+			keep, stray := r.preferPod(desired[name], prev, p)
+			actualMap[name] = keep
+			strays = append(strays, stray)
+			continue
 		}
+		actualMap[name] = p
 	}
+	return desired, actualMap, strays, nil
+}
 
-	// Clean stale upgrade entries (pods deleted but never recreated).
-	r.upgradeTracker.CleanStaleUpgrades(10 * time.Minute)
-	r.upgradeTracker.Reset()
-
-	// Phase 1: Scan all pods for drift and register with upgrade tracker.
-	// This builds the full picture before making any upgrade decisions.
-	driftReasons := make(map[string]string)
-	for name, bead := range desired {
-		pod, exists := actualMap[name]
-		if !exists || pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
-			continue // Missing or terminal pods are handled in phase 2
+// preferPod chooses between two pods with the same name on different
+// clusters: the one on the bead's pinned cluster, else a non-terminal one,
+// else the first (pods are listed home cluster first).
+func (r *Reconciler) preferPod(bead beadsapi.AgentBead, first, second corev1.Pod) (keep, stray corev1.Pod) {
+	if pinned := r.cfg.ProjectCache.Load()[bead.Project].Cluster; pinned != "" {
+		if second.Labels[podmanager.LabelCluster] == pinned && first.Labels[podmanager.LabelCluster] != pinned {
+			return second, first
 		}
-		desiredSpec := r.specBuilder(r.cfg, bead.Project, bead.Mode, bead.Role, bead.AgentName, bead.Metadata)
-		desiredSpec.BeadID = bead.ID
-		reason := podDriftReason(desiredSpec, &pod, r.digestTracker)
-		if reason != "" {
-			driftReasons[name] = reason
-			r.upgradeTracker.RegisterDrift(name, bead.Mode)
-		}
-
-		// Clear upgrade tracking for pods that have been successfully recreated.
-		if IsPodReady(&pod) {
-			r.upgradeTracker.ClearUpgrading(name)
+		if first.Labels[podmanager.LabelCluster] == pinned {
+			return first, second
 		}
 	}
-
-	// Create missing pods and recreate failed pods.
-	// Respect CoopBurstLimit (max pods created per pass) and
-	// CoopMaxPods (total active pod cap).
-	burstLimit := r.cfg.CoopBurstLimit
-	if burstLimit <= 0 {
-		burstLimit = 3 // safety default
+	if isTerminal(&first) && !isTerminal(&second) {
+		return second, first
 	}
-	created := 0
-
-	for name, bead := range desired {
-		if pod, exists := actualMap[name]; exists {
-			// Pod exists. Check if it's in a terminal state (Failed or Succeeded).
-			if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
-				r.logger.Info("deleting terminal pod for recreation",
-					"pod", name, "phase", pod.Status.Phase)
-				if err := r.pods.DeleteAgentPod(ctx, name, pod.Namespace); err != nil {
-					return fmt.Errorf("deleting terminal pod %s: %w", name, err)
-				}
-				// Fall through to create.
-			} else if reason, hasDrift := driftReasons[name]; hasDrift {
-				// Pod has spec drift. Use role-aware upgrade strategy.
-				if !r.upgradeTracker.CanUpgrade(name, bead.Mode) {
-					r.logger.Info("spec drift detected but upgrade deferred by strategy",
-						"pod", name, "mode", bead.Mode, "reason", reason)
-					continue
-				}
-				r.logger.Info("spec drift detected, upgrading pod",
-					"pod", name, "mode", bead.Mode, "reason", reason)
-				if err := r.pods.DeleteAgentPod(ctx, name, pod.Namespace); err != nil {
-					return fmt.Errorf("deleting pod for update %s: %w", name, err)
-				}
-				r.upgradeTracker.MarkUpgrading(name)
-				activePods-- // no longer active after deletion
-				// Fall through to create with new spec.
-			} else {
-				continue
-			}
-		}
+	return first, second
+}
 
-		// Check burst limit.
-		if created >= burstLimit {
-			r.logger.Info("spawn burst limit reached, deferring remaining pods",
-				"limit", burstLimit, "deferred", name)
-			continue
-		}
+func isTerminal(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded
+}
 
-		// Check max concurrent pods.
-		if r.cfg.CoopMaxPods > 0 && activePods >= r.cfg.CoopMaxPods {
-			r.logger.Info("max concurrent pods reached, deferring pod",
-				"limit", r.cfg.CoopMaxPods, "active", activePods, "deferred", name)
-			continue
-		}
+// clusterPodDeleter is implemented by pod managers spanning several clusters
+// (podmanager.MultiCluster).
+type clusterPodDeleter interface {
+	DeleteClusterPod(ctx context.Context, cluster, name, namespace string) error
+}
 
-		// Create the pod.
-		spec := r.specBuilder(r.cfg, bead.Project, bead.Mode, bead.Role, bead.AgentName, bead.Metadata)
-		spec.BeadID = bead.ID
-		r.logger.Info("creating pod", "pod", name)
-		if err := r.pods.CreateAgentPod(ctx, spec); err != nil {
-			return fmt.Errorf("creating pod %s: %w", name, err)
+// podsByCluster counts pods per cluster label, for logging. Pods without the
+// label (single-cluster controllers) are not counted.
+func podsByCluster(pods map[string]corev1.Pod) map[string]int {
+	counts := make(map[string]int)
+	for _, p := range pods {
+		if c := p.Labels[podmanager.LabelCluster]; c != "" {
+			counts[c]++
 		}
-		// Mark the image as deployed so digest drift is cleared.
-		if r.digestTracker != nil && spec.Image != "" {
-			r.digestTracker.MarkDeployed(spec.Image)
-		}
-		created++
-		activePods++
 	}
+	return counts
+}
 
-	if created > 0 || len(desired) > len(actualMap) {
-		r.logger.Info("reconcile pass complete",
-			"created", created, "active", activePods,
-			"desired", len(desired), "burst_limit", burstLimit)
-	}
+// projectPaused reports whether an operator has paused reconciliation for
+// the project via its project bead.
+func (r *Reconciler) projectPaused(project string) bool {
+	return project != "" && r.cfg.ProjectCache.Load()[project].ReconcilePaused
+}
 
-	return nil
+// missingTemplate returns the template bead named by bead that doesn't
+// exist, or "". Such agents get no new pod and their running one is not
+// upgraded, since their spec lacks the template's fields.
+func (r *Reconciler) missingTemplate(bead beadsapi.AgentBead) string {
+	name := bead.Metadata[beadsapi.TemplateField]
+	if _, ok := r.cfg.TemplateCache.Load()[name]; name == "" || ok {
+		return ""
+	}
+	return name
 }
 
 // podDriftReason returns a non-empty string describing why the pod needs
 // recreation, or "" if the pod matches the desired spec.
 func podDriftReason(desired podmanager.AgentPodSpec, actual *corev1.Pod, tracker *ImageDigestTracker) string {
+	// Project pinned to another cluster. Unpinned pods stay where they are.
+	if current := actual.Labels[podmanager.LabelCluster]; desired.Cluster != "" && current != "" && current != desired.Cluster {
+		return fmt.Sprintf("cluster changed: %s -> %s", current, desired.Cluster)
+	}
 	// Tag changed (e.g., latest → 2026.58.3).
 	if agentChanged(desired.Image, actual) {
 		return fmt.Sprintf("agent image changed: %s", desired.Image)
@@ -246,6 +258,11 @@ func podDriftReason(desired podmanager.AgentPodSpec, actual *corev1.Pod, tracker
 	if tracker != nil && desired.Image != "" && tracker.HasDrift(desired.Image) {
 		return fmt.Sprintf("image digest updated in registry: %s", desired.Image)
 	}
+	// Agent ConfigMap re-rendered from changed beads. Pods created before
+	// the controller rendered configs carry no hash and are left alone.
+	if current := actual.Annotations[podmanager.AnnotationConfigHash]; desired.ConfigHash != "" && current != "" && current != desired.ConfigHash {
+		return "agent config changed"
+	}
 	return ""
 }
 
@@ -0,0 +1,94 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+// clusterManager is a mockManager that can also delete from one cluster.
+type clusterManager struct {
+	mockManager
+	clusterDeleted []string // "cluster/name"
+}
+
+func (m *clusterManager) DeleteClusterPod(_ context.Context, cluster, name, _ string) error {
+	m.clusterDeleted = append(m.clusterDeleted, cluster+"/"+name)
+	return nil
+}
+
+func clusterPod(cluster string, phase corev1.PodPhase) corev1.Pod {
+	p := makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", phase)
+	p.Labels[podmanager.LabelCluster] = cluster
+	return p
+}
+
+func TestReconcile_DuplicatePods_KeepsPinnedCluster(t *testing.T) {
+	lister := &mockLister{
+		beads: []beadsapi.AgentBead{
+			{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+		},
+	}
+	mgr := &clusterManager{mockManager: mockManager{
+		pods: []corev1.Pod{
+			clusterPod("home", corev1.PodRunning),
+			clusterPod("burst", corev1.PodRunning),
+		},
+	}}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{"proj": {Cluster: "burst"}})
+
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mgr.clusterDeleted) != 1 || mgr.clusterDeleted[0] != "home/crew-proj-dev-alpha" {
+		t.Errorf("cluster deletions = %v, want [home/crew-proj-dev-alpha]", mgr.clusterDeleted)
+	}
+	if len(mgr.deleted) != 0 || len(mgr.created) != 0 {
+		t.Errorf("expected no other changes, got deleted=%v created=%d", mgr.deleted, len(mgr.created))
+	}
+}
+
+func TestReconcile_DuplicatePods_PrefersLivePodWhenUnpinned(t *testing.T) {
+	lister := &mockLister{
+		beads: []beadsapi.AgentBead{
+			{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+		},
+	}
+	mgr := &clusterManager{mockManager: mockManager{
+		pods: []corev1.Pod{
+			clusterPod("home", corev1.PodFailed),
+			clusterPod("burst", corev1.PodRunning),
+		},
+	}}
+
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mgr.clusterDeleted) != 1 || mgr.clusterDeleted[0] != "home/crew-proj-dev-alpha" {
+		t.Errorf("cluster deletions = %v, want the failed home pod", mgr.clusterDeleted)
+	}
+	if len(mgr.created) != 0 {
+		t.Errorf("expected the running burst pod to be kept, got %d creations", len(mgr.created))
+	}
+}
+
+func TestPodDriftReason_ClusterChanged(t *testing.T) {
+	pod := clusterPod("home", corev1.PodRunning)
+	spec := podmanager.AgentPodSpec{Image: "ghcr.io/org/agent:v1", Cluster: "burst"}
+	if got := podDriftReason(spec, &pod, nil); got != "cluster changed: home -> burst" {
+		t.Errorf("drift reason = %q", got)
+	}
+	// Unpinned specs leave pods where they are.
+	spec.Cluster = ""
+	if got := podDriftReason(spec, &pod, nil); got != "" {
+		t.Errorf("unpinned drift reason = %q, want none", got)
+	}
+}
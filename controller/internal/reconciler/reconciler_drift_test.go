@@ -424,57 +424,3 @@ func TestReconcile_ConcurrencySafe(t *testing.T) {
 		}
 	}
 }
-
-// --- podDriftReason unit tests ---
-
-func TestPodDriftReason_NoChange(t *testing.T) {
-	spec := podmanager.AgentPodSpec{Image: "img:v1"}
-	pod := &corev1.Pod{
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{{Name: "agent", Image: "img:v1"}},
-		},
-	}
-	reason := podDriftReason(spec, pod, nil)
-	if reason != "" {
-		t.Errorf("expected no drift, got: %s", reason)
-	}
-}
-
-func TestPodDriftReason_ImageTagChanged(t *testing.T) {
-	spec := podmanager.AgentPodSpec{Image: "img:v2"}
-	pod := &corev1.Pod{
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{{Name: "agent", Image: "img:v1"}},
-		},
-	}
-	reason := podDriftReason(spec, pod, nil)
-	if reason == "" {
-		t.Error("expected drift reason for image tag change")
-	}
-}
-
-func TestPodDriftReason_EmptyDesiredImage(t *testing.T) {
-	spec := podmanager.AgentPodSpec{Image: ""}
-	pod := &corev1.Pod{
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{{Name: "agent", Image: "img:v1"}},
-		},
-	}
-	reason := podDriftReason(spec, pod, nil)
-	if reason != "" {
-		t.Errorf("expected no drift when desired image is empty, got: %s", reason)
-	}
-}
-
-func TestPodDriftReason_NoAgentContainer(t *testing.T) {
-	spec := podmanager.AgentPodSpec{Image: "img:v2"}
-	pod := &corev1.Pod{
-		Spec: corev1.PodSpec{
-			Containers: []corev1.Container{{Name: "sidecar", Image: "other:v1"}},
-		},
-	}
-	reason := podDriftReason(spec, pod, nil)
-	if reason != "" {
-		t.Errorf("expected no drift when no agent container, got: %s", reason)
-	}
-}
@@ -0,0 +1,83 @@
+package reconciler
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"gasboat/controller/internal/podmanager"
+)
+
+func TestPodDriftReason_NoChange(t *testing.T) {
+	spec := podmanager.AgentPodSpec{Image: "img:v1"}
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "agent", Image: "img:v1"}},
+		},
+	}
+	reason := podDriftReason(spec, pod, nil)
+	if reason != "" {
+		t.Errorf("expected no drift, got: %s", reason)
+	}
+}
+
+func TestPodDriftReason_ImageTagChanged(t *testing.T) {
+	spec := podmanager.AgentPodSpec{Image: "img:v2"}
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "agent", Image: "img:v1"}},
+		},
+	}
+	reason := podDriftReason(spec, pod, nil)
+	if reason == "" {
+		t.Error("expected drift reason for image tag change")
+	}
+}
+
+func TestPodDriftReason_EmptyDesiredImage(t *testing.T) {
+	spec := podmanager.AgentPodSpec{Image: ""}
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "agent", Image: "img:v1"}},
+		},
+	}
+	reason := podDriftReason(spec, pod, nil)
+	if reason != "" {
+		t.Errorf("expected no drift when desired image is empty, got: %s", reason)
+	}
+}
+
+func TestPodDriftReason_NoAgentContainer(t *testing.T) {
+	spec := podmanager.AgentPodSpec{Image: "img:v2"}
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "sidecar", Image: "other:v1"}},
+		},
+	}
+	reason := podDriftReason(spec, pod, nil)
+	if reason != "" {
+		t.Errorf("expected no drift when no agent container, got: %s", reason)
+	}
+}
+
+func TestPodDriftReason_ConfigHashChanged(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{podmanager.AnnotationConfigHash: "aaa"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "agent", Image: "img:v1"}},
+		},
+	}
+	spec := podmanager.AgentPodSpec{Image: "img:v1", ConfigHash: "bbb"}
+	if reason := podDriftReason(spec, pod, nil); reason != "agent config changed" {
+		t.Errorf("expected config drift, got: %q", reason)
+	}
+
+	// Pods created without a rendered config are not restarted for one.
+	pod.Annotations = nil
+	if reason := podDriftReason(spec, pod, nil); reason != "" {
+		t.Errorf("expected no drift for pod without config hash, got: %s", reason)
+	}
+}
@@ -0,0 +1,39 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/podmanager"
+)
+
+func TestReconcile_KeepsTerminatingPodsUntilGraceEnds(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	terminating := func(name, agent, until string, phase corev1.PodPhase) corev1.Pod {
+		pod := makePod(name, "ns", "crew", "proj", "dev", agent, phase)
+		pod.Annotations = map[string]string{podmanager.AnnotationTerminateAfter: until}
+		return pod
+	}
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+	}}
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", corev1.PodRunning),
+		terminating("crew-proj-dev-pushing", "pushing", "2026-03-01T12:01:00Z", corev1.PodRunning),
+		terminating("crew-proj-dev-exited", "exited", "2026-03-01T12:01:00Z", corev1.PodSucceeded),
+		terminating("crew-proj-dev-late", "late", "2026-03-01T11:59:00Z", corev1.PodRunning),
+	}}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+	r.now = func() time.Time { return now }
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.deleted) != 2 || mgr.deleted[0] != "crew-proj-dev-exited" || mgr.deleted[1] != "crew-proj-dev-late" {
+		t.Errorf("deleted %v, want the exited pod and the one past its grace window", mgr.deleted)
+	}
+}
@@ -0,0 +1,27 @@
+package reconciler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/policy"
+)
+
+func TestReconcile_PolicyDenialSkipsPod(t *testing.T) {
+	lister := &mockLister{
+		beads: []beadsapi.AgentBead{
+			{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+		},
+	}
+	mgr := &mockManager{createErr: fmt.Errorf("%w: no", policy.ErrDenied)}
+
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("img:v1"))
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("a denied spec failed the pass: %v", err)
+	}
+	if len(mgr.created) != 1 {
+		t.Errorf("expected 1 create attempt, got %d", len(mgr.created))
+	}
+}
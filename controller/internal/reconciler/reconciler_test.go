@@ -428,3 +428,59 @@ func TestReconcile_MaxPods_CapsActiveCount(t *testing.T) {
 		t.Errorf("expected 1 pod created (max pods cap), got %d", len(mgr.created))
 	}
 }
+
+// --- Paused project tests ---
+
+func TestReconcile_PausedProject_LeavesPodsAlone(t *testing.T) {
+	lister := &mockLister{
+		beads: []beadsapi.AgentBead{
+			{ID: "bd-1", Project: "frozen", Mode: "crew", Role: "dev", AgentName: "alpha"},
+			{ID: "bd-2", Project: "frozen", Mode: "crew", Role: "dev", AgentName: "beta"},
+			{ID: "bd-3", Project: "live", Mode: "crew", Role: "dev", AgentName: "gamma"},
+		},
+	}
+	mgr := &mockManager{
+		pods: []corev1.Pod{
+			// Failed pod and orphan in the paused project: both kept.
+			makePod("crew-frozen-dev-alpha", "ns", "crew", "frozen", "dev", "alpha", corev1.PodFailed),
+			makePod("crew-frozen-dev-old", "ns", "crew", "frozen", "dev", "old", corev1.PodRunning),
+			// Orphan in a live project: deleted.
+			makePod("crew-live-dev-old", "ns", "crew", "live", "dev", "old", corev1.PodRunning),
+		},
+	}
+	cfg := testConfig("ns")
+	cfg.ProjectCache = config.NewSnapshot(map[string]config.ProjectCacheEntry{
+		"frozen": {ReconcilePaused: true},
+		"live":   {},
+	})
+
+	r := New(lister, mgr, cfg, testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mgr.deleted) != 1 || mgr.deleted[0] != "crew-live-dev-old" {
+		t.Errorf("expected only the live orphan deleted, got %v", mgr.deleted)
+	}
+	if len(mgr.created) != 1 || mgr.created[0].AgentName != "gamma" {
+		t.Errorf("expected only gamma created, got %v", mgr.created)
+	}
+}
+
+func TestReconcile_LastSuccess(t *testing.T) {
+	lister := &mockLister{err: fmt.Errorf("daemon down")}
+	r := New(lister, &mockManager{}, testConfig("ns"), testLogger(), simpleSpecBuilder("img"))
+
+	_ = r.Reconcile(context.Background())
+	if !r.LastSuccess().IsZero() {
+		t.Fatal("failed pass must not record success")
+	}
+
+	lister.err = nil
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.LastSuccess().IsZero() {
+		t.Error("expected successful pass to be recorded")
+	}
+}
@@ -0,0 +1,40 @@
+package reconciler
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+func TestReconcile_AdoptedWarmPodMatchesByLabels(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-j1", Project: "proj", Mode: "job", Role: "job", AgentName: "j1"},
+	}}
+	// A warm pod adopted for j1 keeps its pool name.
+	mgr := &mockManager{pods: []corev1.Pod{
+		makePod("warm-proj-job-x7k2p", "ns", "job", "proj", "job", "j1", corev1.PodFailed),
+	}}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.deleted) != 1 || mgr.deleted[0] != "warm-proj-job-x7k2p" {
+		t.Errorf("deleted = %v, want the adopted pod by its own name", mgr.deleted)
+	}
+	if len(mgr.created) != 1 || mgr.created[0].PodName() != "job-proj-job-j1" {
+		t.Errorf("created = %v, want job-proj-job-j1 recreated", mgr.created)
+	}
+
+	// Running, it is left alone rather than deleted as an orphan.
+	mgr.pods[0].Status.Phase = corev1.PodRunning
+	mgr.deleted, mgr.created = nil, nil
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.deleted) != 0 || len(mgr.created) != 0 {
+		t.Errorf("deleted=%v created=%d, want no changes", mgr.deleted, len(mgr.created))
+	}
+}
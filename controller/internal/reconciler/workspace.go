@@ -0,0 +1,42 @@
+package reconciler
+
+import (
+	"context"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// workspaceAdopter is implemented by pod managers keeping workspace PVCs
+// (podmanager.K8sManager, podmanager.MultiCluster).
+type workspaceAdopter interface {
+	AdoptLegacyWorkspaces(ctx context.Context, namespace string, beads map[string]string) (int, error)
+}
+
+// adoptWorkspaces ties workspace claims from before claims were named by
+// bead to their agents' beads, so an agent renamed later keeps its
+// workspace (see podmanager.WorkspaceClaimName). Claims created since are
+// tied at creation, so this runs until it succeeds once.
+func (r *Reconciler) adoptWorkspaces(ctx context.Context, desired map[string]beadsapi.AgentBead) {
+	if r.workspacesAdopted {
+		return
+	}
+	a, ok := r.pods.(workspaceAdopter)
+	if !ok {
+		r.logger.Info("pod manager keeps no workspace PVCs; skipping legacy workspace adoption")
+		r.workspacesAdopted = true
+		return
+	}
+	beads := make(map[string]string, len(desired))
+	for name, bead := range desired {
+		beads[name] = bead.ID
+	}
+	n, err := a.AdoptLegacyWorkspaces(ctx, r.cfg.Namespace, beads)
+	if err != nil {
+		r.logger.Warn("failed to adopt legacy workspace PVCs", "adopted", n, "error", err)
+		return
+	}
+	if n > 0 {
+		r.logger.Info("adopted legacy workspace PVCs", "count", n)
+	}
+	r.workspacesAdopted = true
+}
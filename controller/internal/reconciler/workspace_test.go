@@ -0,0 +1,67 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/faults"
+	"gasboat/controller/internal/policy"
+)
+
+// workspaceManager is a mockManager recording legacy workspace adoptions.
+type workspaceManager struct {
+	mockManager
+	adoptErr error
+	calls    []map[string]string
+}
+
+func (m *workspaceManager) AdoptLegacyWorkspaces(_ context.Context, _ string, beads map[string]string) (int, error) {
+	m.calls = append(m.calls, maps.Clone(beads))
+	return len(beads), m.adoptErr
+}
+
+func TestReconcile_AdoptsLegacyWorkspacesUntilSuccess(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+	}}
+	mgr := &workspaceManager{
+		mockManager: mockManager{pods: []corev1.Pod{
+			makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", corev1.PodRunning),
+		}},
+		adoptErr: errors.New("forbidden"),
+	}
+	r := New(lister, mgr, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+
+	for range 3 {
+		if err := r.Reconcile(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		mgr.adoptErr = nil
+	}
+	if len(mgr.calls) != 2 || mgr.calls[1]["crew-proj-dev-alpha"] != "bd-1" {
+		t.Errorf("adoptions = %v, want a retry after the failure and none after success", mgr.calls)
+	}
+}
+
+func TestReconcile_AdoptsLegacyWorkspacesThroughWrappedManager(t *testing.T) {
+	lister := &mockLister{beads: []beadsapi.AgentBead{
+		{ID: "bd-1", Project: "proj", Mode: "crew", Role: "dev", AgentName: "alpha"},
+	}}
+	mgr := &workspaceManager{mockManager: mockManager{pods: []corev1.Pod{
+		makePod("crew-proj-dev-alpha", "ns", "crew", "proj", "dev", "alpha", corev1.PodRunning),
+	}}}
+	wrapped := policy.NewEnforcer(nil, testLogger()).Manager(faults.New(faults.Config{}, testLogger()).Manager(mgr))
+	r := New(lister, wrapped, testConfig("ns"), testLogger(), simpleSpecBuilder("ghcr.io/org/agent:v1"))
+
+	if err := r.Reconcile(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(mgr.calls) != 1 || mgr.calls[0]["crew-proj-dev-alpha"] != "bd-1" {
+		t.Errorf("adoptions = %v, want one through the policy and fault wrappers", mgr.calls)
+	}
+}
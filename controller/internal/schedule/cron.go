@@ -0,0 +1,172 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed standard five-field cron expression:
+//
+//	minute hour day-of-month month day-of-week
+//
+// Fields take "*", values, ranges ("1-5"), lists ("1,15") and steps
+// ("*/15", "0-30/10"); months and days also take names ("jan", "mon").
+// Day-of-week 0 and 7 are Sunday. As in cron(8), when both day fields are
+// restricted a day matching either one runs. The descriptors @yearly,
+// @monthly, @weekly, @daily (@midnight) and @hourly are accepted, and a
+// "CRON_TZ=<IANA zone>" prefix sets the time zone (default UTC):
+//
+//	CRON_TZ=Europe/Berlin 30 6 * * Mon-Fri
+type Cron struct {
+	minute, hour, dom, month, dow uint64 // bit n set = value n matches
+	domAny, dowAny                bool   // day field was "*"
+	loc                           *time.Location
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// ParseCron parses a cron expression.
+func ParseCron(s string) (*Cron, error) {
+	c := &Cron{loc: time.UTC}
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "CRON_TZ="); ok {
+		zone, expr, _ := strings.Cut(rest, " ")
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("time zone: %w", err)
+		}
+		c.loc, s = loc, strings.TrimSpace(expr)
+	}
+	if expr, ok := cronDescriptors[strings.ToLower(s)]; ok {
+		s = expr
+	}
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("want 5 fields \"<minute> <hour> <day-of-month> <month> <day-of-week>\", got %d", len(fields))
+	}
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	days := make(map[string]int, len(weekdays))
+	for name, d := range weekdays {
+		days[name] = int(d)
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7, days); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is Sunday too
+	}
+	c.domAny = strings.HasPrefix(fields[2], "*")
+	c.dowAny = strings.HasPrefix(fields[4], "*")
+	return c, nil
+}
+
+func parseCronField(s string, lo, hi int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+		}
+		first, last := lo, hi
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = cronValue(from, lo, hi, names); err != nil {
+				return 0, err
+			}
+			last = first
+			if isRange {
+				if last, err = cronValue(to, lo, hi, names); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				last = hi // "5/15" runs from 5 to the end
+			}
+			if last < first {
+				return 0, fmt.Errorf("range %q is backwards", rng)
+			}
+		}
+		for v := first; v <= last; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < lo || v > hi {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, lo, hi)
+	}
+	return v, nil
+}
+
+// Next returns the first time after t the expression fires, or the zero
+// time if it never does (e.g. "0 0 30 2 *").
+func (c *Cron) Next(t time.Time) time.Time {
+	t = t.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	// Skipping whole months, days and hours, five years covers every
+	// satisfiable expression (Feb 29 on a given weekday included).
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// 2026-03-02 is a Monday.
+	from := time.Date(2026, 3, 2, 10, 17, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * * *", "2026-03-02 10:18"},
+		{"*/15 * * * *", "2026-03-02 10:30"},
+		{"0 9 * * Mon-Fri", "2026-03-03 09:00"},
+		{"30 6 * * 0", "2026-03-08 06:30"},
+		{"30 6 * * 7", "2026-03-08 06:30"},
+		{"0 0 1 */3 *", "2026-04-01 00:00"},
+		{"0 12 13 * fri", "2026-03-06 12:00"}, // either day field matches
+		{"5/20 10 * * *", "2026-03-02 10:25"},
+		{"@daily", "2026-03-03 00:00"},
+		{"@hourly", "2026-03-02 11:00"},
+		{"0 0 29 feb *", "2028-02-29 00:00"},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := c.Next(from).Format("2006-01-02 15:04"); got != tt.want {
+			t.Errorf("%q: Next = %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestCronNext_TimeZone(t *testing.T) {
+	c, err := ParseCron("CRON_TZ=America/New_York 0 9 * * *")
+	if err != nil {
+		t.Skip("tzdata unavailable:", err)
+	}
+	got := c.Next(time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got.UTC(), want)
+	}
+}
+
+func TestCronNext_Never(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next = %s, want zero", got)
+	}
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	for _, bad := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"* * * * funday",
+		"CRON_TZ=Mars/Olympus * * * * *",
+	} {
+		if _, err := ParseCron(bad); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package schedule parses agent working-hours windows and reports whether a
+// point in time falls inside them.
+//
+// It also parses cron expressions for recurring jobs (see ParseCron).
+//
+// A schedule is one or more windows separated by ";". Each window is a day
+// spec, a time range, and an optional IANA time zone (default UTC):
+//
+//	Mon-Fri 08:00-19:00 America/New_York
+//	Mon-Fri 09:00-17:30; Sat 10:00-14:00
+//	* 22:00-06:00 Europe/London
+//
+// Days are Mon..Sun, as a range ("Mon-Fri"), a list ("Mon,Wed,Fri"), or "*"
+// for every day. A range whose end is before its start runs past midnight
+// and belongs to the day it starts on.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed set of active windows.
+type Schedule struct {
+	windows []window
+}
+
+type window struct {
+	days       [7]bool // indexed by time.Weekday
+	start, end int     // minutes since midnight; end may be <= start (overnight)
+	loc        *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Parse parses a schedule string. An empty string is an error; callers treat
+// a missing schedule as always active.
+func Parse(s string) (*Schedule, error) {
+	sched := &Schedule{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		w, err := parseWindow(part)
+		if err != nil {
+			return nil, fmt.Errorf("window %q: %w", part, err)
+		}
+		sched.windows = append(sched.windows, w)
+	}
+	if len(sched.windows) == 0 {
+		return nil, fmt.Errorf("schedule is empty")
+	}
+	return sched, nil
+}
+
+func parseWindow(s string) (window, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 || len(fields) > 3 {
+		return window{}, fmt.Errorf("want \"<days> <HH:MM-HH:MM> [time zone]\"")
+	}
+	w := window{loc: time.UTC}
+	if err := parseDays(fields[0], &w.days); err != nil {
+		return window{}, err
+	}
+	from, to, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return window{}, fmt.Errorf("time range %q must be HH:MM-HH:MM", fields[1])
+	}
+	var err error
+	if w.start, err = parseClock(from); err != nil {
+		return window{}, err
+	}
+	if w.end, err = parseClock(to); err != nil {
+		return window{}, err
+	}
+	if w.start == w.end {
+		return window{}, fmt.Errorf("time range %q is empty", fields[1])
+	}
+	if len(fields) == 3 {
+		if w.loc, err = time.LoadLocation(fields[2]); err != nil {
+			return window{}, fmt.Errorf("time zone: %w", err)
+		}
+	}
+	return w, nil
+}
+
+func parseDays(s string, days *[7]bool) error {
+	if s == "*" {
+		for i := range days {
+			days[i] = true
+		}
+		return nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(item, "-")
+		first, ok := weekdays[strings.ToLower(from)]
+		if !ok {
+			return fmt.Errorf("unknown day %q", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[strings.ToLower(to)]; !ok {
+				return fmt.Errorf("unknown day %q", to)
+			}
+		}
+		// Ranges may wrap the week, e.g. Fri-Mon.
+		for d := first; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		if s == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("time %q must be HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Active reports whether t falls inside any window.
+func (s *Schedule) Active(t time.Time) bool {
+	for _, w := range s.windows {
+		if w.active(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w window) active(t time.Time) bool {
+	local := t.In(w.loc)
+	minute := local.Hour()*60 + local.Minute()
+	if w.start < w.end {
+		return w.days[local.Weekday()] && minute >= w.start && minute < w.end
+	}
+	// Overnight: the evening part belongs to today, the morning part to the
+	// window that started yesterday.
+	if minute >= w.start {
+		return w.days[local.Weekday()]
+	}
+	if minute < w.end {
+		return w.days[(local.Weekday()+6)%7]
+	}
+	return false
+}
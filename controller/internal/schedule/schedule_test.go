@@ -0,0 +1,64 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActive(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("tzdata unavailable:", err)
+	}
+	// 2026-03-02 is a Monday.
+	at := func(day, hour, min int, loc *time.Location) time.Time {
+		return time.Date(2026, 3, 2+day, hour, min, 0, 0, loc)
+	}
+
+	tests := []struct {
+		sched string
+		t     time.Time
+		want  bool
+	}{
+		{"Mon-Fri 09:00-17:00", at(0, 9, 0, time.UTC), true},
+		{"Mon-Fri 09:00-17:00", at(0, 17, 0, time.UTC), false},
+		{"Mon-Fri 09:00-17:00", at(5, 12, 0, time.UTC), false}, // Saturday
+		{"Mon-Fri 09:00-17:00 America/New_York", at(0, 13, 59, time.UTC), false},
+		{"Mon-Fri 09:00-17:00 America/New_York", at(0, 9, 30, ny), true},
+		{"Mon,Wed 09:00-17:00; Sat 10:00-14:00", at(5, 11, 0, time.UTC), true},
+		{"Mon,Wed 09:00-17:00; Sat 10:00-14:00", at(1, 11, 0, time.UTC), false},
+		{"Fri-Mon 00:00-24:00", at(6, 23, 59, time.UTC), true}, // Sunday, wrapped range
+		{"Fri-Mon 00:00-24:00", at(2, 12, 0, time.UTC), false}, // Wednesday
+		{"Fri 22:00-06:00", at(4, 23, 0, time.UTC), true},      // Friday night
+		{"Fri 22:00-06:00", at(5, 5, 59, time.UTC), true},      // Saturday morning
+		{"Fri 22:00-06:00", at(4, 5, 0, time.UTC), false},      // Friday morning
+		{"* 08:00-19:00", at(6, 8, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.sched)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.sched, err)
+		}
+		if got := s.Active(tt.t); got != tt.want {
+			t.Errorf("%q at %s: Active = %v, want %v", tt.sched, tt.t.Format(time.RFC1123), got, tt.want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, bad := range []string{
+		"",
+		" ; ",
+		"Mon-Fri",
+		"Mon-Fri 9-17",
+		"Funday 09:00-17:00",
+		"Mon 09:00-09:00",
+		"Mon 09:00-25:00",
+		"Mon 09:00-17:00 Mars/Olympus",
+		"Mon 09:00-17:00 UTC extra",
+	} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q): expected error", bad)
+		}
+	}
+}
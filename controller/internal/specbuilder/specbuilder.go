@@ -0,0 +1,168 @@
+// Package specbuilder builds agent pod specs from agent beads and controller
+// config.
+//
+// A spec is built by running named stages in order, each refining the spec
+// left by the ones before it:
+//
+//	base         identity, image, namespace and daemon addresses
+//	role         mode defaults for the agent's role: resources, storage, nodes, grace period
+//	project      project bead overrides: image, storage class, ServiceAccount, RTK, cluster, grace period, DNS, proxy
+//	arch         CPU architecture node selector and per-arch image
+//	spot         spot placement of job agents
+//	prestop      preStop hook from the project or role bead, else the default
+//	agent        agent bead overrides: env, resources, ServiceAccount, ConfigMap, RTK
+//	credentials  controller-wide credentials, NATS, coopmux and artifact export
+//	repos        git repositories to clone and projects to register
+//	secrets      per-project secret env overrides
+//	mock         scripted claudeless sessions (mock_scenario)
+//
+// The reconciler, the event handler, warm pools, onboarding and spawn
+// previews all build specs through Default, so they agree on every pod.
+// A site that needs different behavior replaces one stage with Register
+// (for example from an init function in a fork of cmd/controller) or
+// reorders or drops stages with SetOrder (env: SPEC_STAGES).
+package specbuilder
+
+import (
+	"fmt"
+	"slices"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+// Stage names of the default registry.
+const (
+	StageBase        = "base"
+	StageRole        = "role"
+	StageProject     = "project"
+	StageArch        = "arch"
+	StageSpot        = "spot"
+	StagePreStop     = "prestop"
+	StageAgent       = "agent"
+	StageCredentials = "credentials"
+	StageRepos       = "repos"
+	StageSecrets     = "secrets"
+	StageMock        = "mock"
+)
+
+// DefaultOrder is the order stages of a new Registry run in.
+var DefaultOrder = []string{
+	StageBase, StageRole, StageProject, StageArch, StageSpot, StagePreStop,
+	StageAgent, StageCredentials, StageRepos, StageSecrets, StageMock,
+}
+
+// Input identifies the agent a spec is built for.
+type Input struct {
+	Project   string
+	Mode      string // derived from Role when empty (see ModeForRole)
+	Role      string
+	AgentName string
+	BeadID    string
+	// Namespace overrides the controller's namespace.
+	Namespace string
+	// Metadata is the agent bead's fields, with its template resolved.
+	Metadata map[string]string
+}
+
+// Stage is one step of building a spec. Stages run in order on the same
+// spec, which starts out empty but for an Env map.
+type Stage func(cfg *config.Config, in Input, spec *podmanager.AgentPodSpec)
+
+// Registry holds the stages specs are built from and their order. Set it
+// up before building specs; it is not safe to change while in use.
+type Registry struct {
+	stages map[string]Stage
+	order  []string
+}
+
+// Default is the registry the controller builds specs with.
+var Default = New()
+
+// New returns a registry with the default stages in DefaultOrder.
+func New() *Registry {
+	return &Registry{
+		stages: map[string]Stage{
+			StageBase:        applyBase,
+			StageRole:        applyRoleDefaults,
+			StageProject:     applyProjectDefaults,
+			StageArch:        applyArch,
+			StageSpot:        applySpotPolicy,
+			StagePreStop:     applyPreStop,
+			StageAgent:       applyAgentOverrides,
+			StageCredentials: applyCredentials,
+			StageRepos:       applyRepos,
+			StageSecrets:     applyProjectSecrets,
+			StageMock:        applyMockScenario,
+		},
+		order: slices.Clone(DefaultOrder),
+	}
+}
+
+// Register adds a stage, or replaces the stage of the same name in place.
+// A new stage runs last unless SetOrder places it.
+func (r *Registry) Register(name string, stage Stage) {
+	if _, ok := r.stages[name]; !ok {
+		r.order = append(r.order, name)
+	}
+	r.stages[name] = stage
+}
+
+// SetOrder sets which stages run and in what order. Every name must be a
+// registered stage and appear once; stages left out don't run.
+func (r *Registry) SetOrder(names []string) error {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := r.stages[name]; !ok {
+			return fmt.Errorf("unknown spec stage %q", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("spec stage %q listed more than once", name)
+		}
+		seen[name] = true
+	}
+	r.order = slices.Clone(names)
+	return nil
+}
+
+// Order returns the stages specs are built from, in order.
+func (r *Registry) Order() []string {
+	return slices.Clone(r.order)
+}
+
+// Build runs the stages for in.
+func (r *Registry) Build(cfg *config.Config, in Input) podmanager.AgentPodSpec {
+	in.Mode = ModeForRole(in.Mode, in.Role)
+	spec := podmanager.AgentPodSpec{Env: make(map[string]string)}
+	for _, name := range r.order {
+		r.stages[name](cfg, in, &spec)
+	}
+	return spec
+}
+
+// FromBead builds the spec of an agent bead. It has the signature of
+// reconciler.SpecBuilder.
+func (r *Registry) FromBead(cfg *config.Config, project, mode, role, agentName string, metadata map[string]string) podmanager.AgentPodSpec {
+	return r.Build(cfg, Input{Project: project, Mode: mode, Role: role, AgentName: agentName, Metadata: metadata})
+}
+
+// FromBead builds the spec of an agent bead with Default.
+func FromBead(cfg *config.Config, project, mode, role, agentName string, metadata map[string]string) podmanager.AgentPodSpec {
+	return Default.FromBead(cfg, project, mode, role, agentName, metadata)
+}
+
+// ModeForRole returns the canonical mode for a role.
+// If mode is already set, it is returned unchanged.
+func ModeForRole(mode, role string) string {
+	if mode != "" {
+		return mode
+	}
+	switch role {
+	case "captain", "crew":
+		return "crew"
+	case "job":
+		return "job"
+	default:
+		return "crew"
+	}
+}
@@ -0,0 +1,62 @@
+package specbuilder
+
+import (
+	"slices"
+	"testing"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+func TestRegistry_RegisterReplacesStage(t *testing.T) {
+	r := New()
+	r.Register(StageMock, func(_ *config.Config, in Input, spec *podmanager.AgentPodSpec) {
+		spec.Env["CUSTOM_MOCK"] = in.AgentName
+	})
+	if !slices.Equal(r.Order(), DefaultOrder) {
+		t.Errorf("order = %v, want %v", r.Order(), DefaultOrder)
+	}
+	spec := r.Build(&config.Config{Namespace: "test"}, Input{Project: "p", Role: "crew", AgentName: "a1"})
+	if spec.Env["CUSTOM_MOCK"] != "a1" {
+		t.Errorf("replaced stage did not run: env = %v", spec.Env)
+	}
+	if spec.Mode != "crew" || spec.Namespace != "test" {
+		t.Errorf("default stages did not run: mode=%q namespace=%q", spec.Mode, spec.Namespace)
+	}
+}
+
+func TestRegistry_NewStageRunsLast(t *testing.T) {
+	r := New()
+	r.Register("site", func(_ *config.Config, _ Input, spec *podmanager.AgentPodSpec) {
+		spec.Image = "site/" + spec.Image
+	})
+	if got := r.Order(); got[len(got)-1] != "site" {
+		t.Fatalf("order = %v", got)
+	}
+	spec := r.Build(&config.Config{CoopImage: "coop:1"}, Input{Role: "crew"})
+	if spec.Image != "site/coop:1" {
+		t.Errorf("image = %q, want site/coop:1", spec.Image)
+	}
+}
+
+func TestRegistry_SetOrder(t *testing.T) {
+	r := New()
+	if err := r.SetOrder([]string{StageBase, "nope"}); err == nil {
+		t.Error("unknown stage accepted")
+	}
+	if err := r.SetOrder([]string{StageBase, StageBase}); err == nil {
+		t.Error("duplicate stage accepted")
+	}
+	if !slices.Equal(r.Order(), DefaultOrder) {
+		t.Errorf("order changed by rejected SetOrder: %v", r.Order())
+	}
+
+	// Dropping the role stage leaves the spec without mode defaults.
+	if err := r.SetOrder([]string{StageBase}); err != nil {
+		t.Fatal(err)
+	}
+	spec := r.Build(&config.Config{}, Input{Role: "crew", AgentName: "a1"})
+	if spec.AgentName != "a1" || spec.Resources != nil {
+		t.Errorf("spec = %+v, want base stage only", spec)
+	}
+}
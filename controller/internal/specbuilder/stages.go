@@ -0,0 +1,553 @@
+package specbuilder
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/prestop"
+	"gasboat/controller/internal/rbacreconciler"
+	"gasboat/controller/internal/reconciler"
+)
+
+// applyBase sets the agent's identity, its image (the agent bead's image
+// override, else the controller default), namespace and daemon addresses.
+func applyBase(cfg *config.Config, in Input, spec *podmanager.AgentPodSpec) {
+	spec.Project = in.Project
+	spec.Mode = in.Mode
+	spec.Role = in.Role
+	spec.AgentName = in.AgentName
+	spec.BeadID = in.BeadID
+	spec.Metadata = in.Metadata
+	spec.Image = cfg.CoopImage
+	if img := in.Metadata["image"]; img != "" {
+		spec.Image = img
+	}
+	spec.Namespace = cfg.Namespace
+	if in.Namespace != "" {
+		spec.Namespace = in.Namespace
+	}
+	spec.Env["BEADS_GRPC_ADDR"] = cfg.BeadsGRPCAddr
+	if addr := in.Metadata["beads_grpc_addr"]; addr != "" {
+		spec.Env["BEADS_GRPC_ADDR"] = addr
+	}
+	spec.Env["BEADS_HTTP_ADDR"] = cfg.BeadsHTTPAddr
+}
+
+// applyRoleDefaults applies the defaults of the agent's mode: workspace
+// storage, resources, node placement and termination grace period, with
+// those of its role bead (cfg.RoleCache) on top. A role image replaces the
+// controller's default image, but not the agent bead's image override.
+func applyRoleDefaults(cfg *config.Config, in Input, spec *podmanager.AgentPodSpec) {
+	defaults := podmanager.DefaultPodDefaults(in.Mode)
+	if role, ok := cfg.RoleCache.Load()[in.Role]; ok {
+		if role.Image != "" && in.Metadata["image"] == "" {
+			spec.Image = role.Image
+		}
+		if role.Resources != nil {
+			defaults.Resources = role.Resources
+		}
+		defaults.Env = role.Env
+		if role.StorageSize != "" && defaults.WorkspaceStorage == nil {
+			defaults.WorkspaceStorage = &podmanager.WorkspaceStorageSpec{}
+		}
+		if ws := defaults.WorkspaceStorage; ws != nil {
+			if role.StorageSize != "" {
+				ws.Size = role.StorageSize
+			}
+			if role.StorageClass != "" {
+				ws.StorageClassName = role.StorageClass
+			}
+		}
+		if role.TerminationGrace > 0 {
+			defaults.TerminationGracePeriodSeconds = gracePeriodSeconds(role.TerminationGrace)
+		}
+	}
+	podmanager.ApplyDefaults(spec, defaults)
+}
+
+// applyProjectDefaults applies per-project overrides from project bead
+// metadata, including the termination grace period, DNS settings and
+// egress proxy.
+func applyProjectDefaults(cfg *config.Config, _ Input, spec *podmanager.AgentPodSpec) {
+	entry, ok := cfg.ProjectCache.Load()[spec.Project]
+	if !ok {
+		return
+	}
+	if entry.Image != "" {
+		spec.Image = entry.Image
+	}
+	if entry.StorageClass != "" && spec.WorkspaceStorage != nil {
+		spec.WorkspaceStorage.StorageClassName = entry.StorageClass
+	}
+	if entry.ServiceAccount != "" {
+		spec.ServiceAccountName = entry.ServiceAccount
+	}
+	if entry.RTKEnabled {
+		spec.Env["RTK_ENABLED"] = "true"
+	}
+	if entry.TerminationGrace > 0 {
+		spec.TerminationGracePeriodSeconds = gracePeriodSeconds(entry.TerminationGrace)
+	}
+	if len(entry.HostAliases) > 0 {
+		spec.HostAliases = entry.HostAliases
+	}
+	if entry.DNSPolicy != "" {
+		spec.DNSPolicy = entry.DNSPolicy
+	}
+	if entry.DNSConfig != nil {
+		spec.DNSConfig = entry.DNSConfig
+	}
+	if entry.HTTPProxy != "" || entry.HTTPSProxy != "" {
+		spec.Proxy = &podmanager.ProxySpec{
+			HTTPProxy:  entry.HTTPProxy,
+			HTTPSProxy: entry.HTTPSProxy,
+			NoProxy:    entry.NoProxy,
+		}
+	}
+	spec.Cluster = entry.Cluster
+}
+
+// gracePeriodSeconds converts a termination grace period to whole seconds.
+func gracePeriodSeconds(d time.Duration) *int64 {
+	seconds := int64(d / time.Second)
+	return &seconds
+}
+
+// applyArch pins the agent to the CPU architecture chosen by its agent bead,
+// its project bead, or the role default (AGENT_ROLE_ARCH), in that order,
+// replacing the default amd64 node selector. "any" drops the selector for
+// multi-arch images. An image for the architecture (AGENT_ARCH_IMAGES)
+// replaces the controller's default image, but not a project or agent
+// image override.
+func applyArch(cfg *config.Config, in Input, spec *podmanager.AgentPodSpec) {
+	arch := in.Metadata[beadsapi.ArchField]
+	if !beadsapi.ValidArch(arch) {
+		arch = cfg.ProjectCache.Load()[spec.Project].Arch
+	}
+	if !beadsapi.ValidArch(arch) && cfg.Arch != nil {
+		arch = cfg.Arch.RoleArch[spec.Role]
+	}
+	if !beadsapi.ValidArch(arch) {
+		return
+	}
+
+	selector := make(map[string]string, len(spec.NodeSelector)+1)
+	for k, v := range spec.NodeSelector {
+		selector[k] = v
+	}
+	if arch == beadsapi.ArchAny {
+		delete(selector, podmanager.LabelArch)
+	} else {
+		selector[podmanager.LabelArch] = arch
+	}
+	spec.NodeSelector = selector
+
+	if cfg.Arch != nil && spec.Image == cfg.CoopImage {
+		if image := cfg.Arch.Images[arch]; image != "" {
+			spec.Image = image
+		}
+	}
+}
+
+// applySpotPolicy places job-mode agents on spot nodes when the spot policy
+// is enabled. Once the agent bead's preemptions count (kept by the
+// reconciler) reaches the policy's limit, the agent is pinned to on-demand
+// nodes instead.
+func applySpotPolicy(cfg *config.Config, in Input, spec *podmanager.AgentPodSpec) {
+	if cfg.Spot == nil || spec.Mode != "job" {
+		return
+	}
+	selector := cfg.Spot.NodeSelector
+	spot := reconciler.Preemptions(in.Metadata) < cfg.Spot.MaxPreemptions
+	if !spot {
+		selector = cfg.Spot.OnDemandNodeSelector
+	}
+	merged := make(map[string]string, len(spec.NodeSelector)+len(selector))
+	for k, v := range spec.NodeSelector {
+		merged[k] = v
+	}
+	for k, v := range selector {
+		merged[k] = v
+	}
+	spec.NodeSelector = merged
+	if spot {
+		spec.Spot = true
+		spec.Tolerations = append(spec.Tolerations, cfg.Spot.Tolerations...)
+	}
+}
+
+// applyPreStop renders the agent's preStop hook: its project bead's
+// template, else its role bead's, else prestop.Default. A template that
+// fails to render is logged and replaced by the default.
+func applyPreStop(cfg *config.Config, in Input, spec *podmanager.AgentPodSpec) {
+	text := cfg.ProjectCache.Load()[spec.Project].PreStop
+	if text == "" {
+		text = cfg.RoleCache.Load()[in.Role].PreStop
+	}
+	if text == "" {
+		text = prestop.Default
+	}
+	vars := prestop.Vars{
+		BeadID:    spec.BeadID,
+		DaemonURL: spec.Env["BEADS_HTTP_ADDR"],
+		Project:   spec.Project,
+		Mode:      spec.Mode,
+		Role:      spec.Role,
+		AgentName: spec.AgentName,
+	}
+	hook, err := prestop.Render(text, vars)
+	if err != nil {
+		slog.Warn("invalid preStop hook, using the default", "agent", spec.PodName(), "error", err)
+		hook, _ = prestop.Render(prestop.Default, vars)
+	}
+	spec.PreStop = hook
+}
+
+// applyAgentOverrides applies the agent bead's own settings, usually
+// filled in from its template (beadsapi.ResolveTemplate): env, resources,
+// ServiceAccount, ConfigMap and RTK. Malformed env or resources are ignored.
+func applyAgentOverrides(_ *config.Config, in Input, spec *podmanager.AgentPodSpec) {
+	if env, err := beadsapi.TemplateEnv(in.Metadata); err == nil {
+		for k, v := range env {
+			spec.Env[k] = v
+		}
+	}
+	if res, err := beadsapi.TemplateResources(in.Metadata); err == nil && res != nil {
+		spec.Resources = res
+	}
+	if sa := in.Metadata["service_account"]; sa != "" {
+		spec.ServiceAccountName = sa
+	}
+	if cm := in.Metadata["configmap"]; cm != "" {
+		spec.ConfigMapName = cm
+	}
+	spec.ExtraLabels = userPodMetadata(beadsapi.PodLabels(in.Metadata))
+	spec.ExtraAnnotations = userPodMetadata(beadsapi.PodAnnotations(in.Metadata))
+	// Agent-level RTK override: force-disable RTK for this agent even if the
+	// project has it enabled. Set rtk_enabled=false on the agent bead to opt out.
+	if in.Metadata["rtk_enabled"] == "false" {
+		delete(spec.Env, "RTK_ENABLED")
+	} else if in.Metadata["rtk_enabled"] == "true" {
+		spec.Env["RTK_ENABLED"] = "true"
+	}
+}
+
+// userPodMetadata drops the labels or annotations a bead may not set: those
+// in the controller's gasboat.io/ namespace, which select and classify its
+// pods, and app.kubernetes.io/name. Invalid entries were already dropped by
+// the decoder.
+func userPodMetadata(set map[string]string, _ error) map[string]string {
+	for k := range set {
+		if strings.HasPrefix(k, "gasboat.io/") || k == podmanager.LabelApp {
+			delete(set, k)
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return set
+}
+
+// applyCredentials wires controller-level config into an AgentPodSpec:
+// the ServiceAccount, Claude and daemon credentials, git and forge tokens,
+// NATS, coopmux and artifact export.
+func applyCredentials(cfg *config.Config, _ Input, spec *podmanager.AgentPodSpec) {
+	// Least-privilege mode: known projects run as their own ServiceAccount,
+	// kept by the RBAC reconciler.
+	if _, known := cfg.ProjectCache.Load()[spec.Project]; spec.ServiceAccountName == "" && cfg.AgentRBAC && known {
+		spec.ServiceAccountName = rbacreconciler.ServiceAccountName(spec.Project)
+	}
+	if spec.ServiceAccountName == "" && cfg.CoopServiceAccount != "" {
+		spec.ServiceAccountName = cfg.CoopServiceAccount
+	}
+	if cfg.ClaudeOAuthSecret != "" {
+		spec.CredentialsSecret = cfg.ClaudeOAuthSecret
+	}
+	// CLAUDE_CODE_OAUTH_TOKEN: preferred auth method — coop auto-writes
+	// .credentials.json when this env var is set. Takes priority over the
+	// static credentials secret mount.
+	if cfg.ClaudeOAuthTokenSecret != "" {
+		spec.SecretEnv = append(spec.SecretEnv, podmanager.SecretEnvSource{
+			EnvName:    "CLAUDE_CODE_OAUTH_TOKEN",
+			SecretName: cfg.ClaudeOAuthTokenSecret,
+			SecretKey:  "token",
+		})
+	}
+	// ANTHROPIC_API_KEY: fallback when OAuth is unavailable.
+	if cfg.AnthropicApiKeySecret != "" {
+		spec.SecretEnv = append(spec.SecretEnv, podmanager.SecretEnvSource{
+			EnvName:    "ANTHROPIC_API_KEY",
+			SecretName: cfg.AnthropicApiKeySecret,
+			SecretKey:  "key",
+		})
+	}
+	if cfg.BeadsTokenSecret != "" {
+		spec.DaemonTokenSecret = cfg.BeadsTokenSecret
+	}
+
+	// Git credentials: inject GIT_USERNAME and GIT_TOKEN from secret for clone/push.
+	// Also pass the secret name to init-clone container for private repo clones.
+	if cfg.GitCredentialsSecret != "" {
+		spec.GitCredentialsSecret = cfg.GitCredentialsSecret
+		spec.SecretEnv = append(spec.SecretEnv,
+			podmanager.SecretEnvSource{
+				EnvName:    "GIT_USERNAME",
+				SecretName: cfg.GitCredentialsSecret,
+				SecretKey:  "username",
+			},
+			podmanager.SecretEnvSource{
+				EnvName:    "GIT_TOKEN",
+				SecretName: cfg.GitCredentialsSecret,
+				SecretKey:  "token",
+			},
+		)
+	}
+
+	// Wire NATS config to all agents for beads decisions, coop events, and bus emit.
+	if cfg.NatsURL != "" {
+		spec.Env["BEADS_NATS_URL"] = cfg.NatsURL
+		spec.Env["COOP_NATS_URL"] = cfg.NatsURL
+	}
+	if cfg.NatsTokenSecret != "" {
+		spec.SecretEnv = append(spec.SecretEnv, podmanager.SecretEnvSource{
+			EnvName:    "COOP_NATS_TOKEN",
+			SecretName: cfg.NatsTokenSecret,
+			SecretKey:  "token",
+		})
+	}
+
+	// Default storage class for agent workspace PVCs. Applied only if no project
+	// bead override already set it, so project-level config takes precedence.
+	if cfg.AgentStorageClass != "" && spec.WorkspaceStorage != nil && spec.WorkspaceStorage.StorageClassName == "" {
+		spec.WorkspaceStorage.StorageClassName = cfg.AgentStorageClass
+	}
+
+	// Default Claude model for agent pods (e.g., "claude-opus-4-6").
+	if cfg.ClaudeModel != "" {
+		spec.Env["CLAUDE_MODEL"] = cfg.ClaudeModel
+	}
+
+	// Termination grace: how long the agent has to checkpoint once it is
+	// told its pod is going (agent_state=terminating).
+	if cfg.AgentTerminationGrace > 0 {
+		spec.Env["BOAT_TERMINATION_GRACE"] = strconv.Itoa(int(cfg.AgentTerminationGrace.Seconds()))
+	}
+
+	// E2E beads address: isolated beads instance for e2e tests so spawn
+	// events don't hit the production agents controller.
+	if cfg.BeadsE2EHTTPAddr != "" {
+		spec.Env["BEADS_E2E_HTTP_ADDR"] = cfg.BeadsE2EHTTPAddr
+	}
+
+	// Wire coopmux registration config. The agent runs coop directly (builtin)
+	// so it gets COOP_BROKER_URL/TOKEN as env vars.
+	if cfg.CoopmuxURL != "" {
+		spec.Env["COOP_BROKER_URL"] = cfg.CoopmuxURL
+		spec.Env["COOP_MUX_URL"] = cfg.CoopmuxURL
+	}
+	if cfg.CoopmuxTokenSecret != "" {
+		spec.SecretEnv = append(spec.SecretEnv, podmanager.SecretEnvSource{
+			EnvName:    "COOP_BROKER_TOKEN",
+			SecretName: cfg.CoopmuxTokenSecret,
+			SecretKey:  "token",
+		})
+		if cfg.CoopmuxURL != "" {
+			spec.SecretEnv = append(spec.SecretEnv, podmanager.SecretEnvSource{
+				EnvName:    "COOP_MUX_TOKEN",
+				SecretName: cfg.CoopmuxTokenSecret,
+				SecretKey:  "token",
+			})
+		}
+	}
+
+	// GitHub token for gh CLI (releases, GHCR push) inside agent pods.
+	if cfg.GithubTokenSecret != "" {
+		spec.SecretEnv = append(spec.SecretEnv, podmanager.SecretEnvSource{
+			EnvName:    "GITHUB_TOKEN",
+			SecretName: cfg.GithubTokenSecret,
+			SecretKey:  "token",
+		})
+	}
+
+	// GitLab token for glab CLI (GLAB_TOKEN) and git clone/push (GITLAB_TOKEN).
+	// Also passed to init-clone for authenticated GitLab repo cloning.
+	if cfg.GitlabTokenSecret != "" {
+		spec.GitlabTokenSecret = cfg.GitlabTokenSecret
+		spec.SecretEnv = append(spec.SecretEnv,
+			podmanager.SecretEnvSource{
+				EnvName:    "GLAB_TOKEN",
+				SecretName: cfg.GitlabTokenSecret,
+				SecretKey:  "token",
+			},
+			podmanager.SecretEnvSource{
+				EnvName:    "GITLAB_TOKEN",
+				SecretName: cfg.GitlabTokenSecret,
+				SecretKey:  "token",
+			},
+		)
+	}
+
+	// RWX access token for RWX API calls (dispatches, triggers) inside agent pods.
+	if cfg.RwxAccessTokenSecret != "" {
+		spec.SecretEnv = append(spec.SecretEnv, podmanager.SecretEnvSource{
+			EnvName:    "RWX_ACCESS_TOKEN",
+			SecretName: cfg.RwxAccessTokenSecret,
+			SecretKey:  "token",
+		})
+	}
+
+	// Artifact export: agents upload session artifacts here when they
+	// finish or fail (see gb agent start).
+	if cfg.ArtifactBucket != "" {
+		spec.Env["BOAT_ARTIFACT_BUCKET"] = cfg.ArtifactBucket
+		spec.Env["BOAT_ARTIFACT_ENDPOINT"] = cfg.ArtifactEndpoint
+		spec.Env["BOAT_ARTIFACT_REGION"] = cfg.ArtifactRegion
+		if cfg.ArtifactPrefix != "" {
+			spec.Env["BOAT_ARTIFACT_PREFIX"] = cfg.ArtifactPrefix
+		}
+		if cfg.ArtifactPaths != "" {
+			spec.Env["BOAT_ARTIFACT_PATHS"] = cfg.ArtifactPaths
+		}
+		if cfg.ArtifactCredentialsSecret != "" {
+			spec.SecretEnv = append(spec.SecretEnv,
+				podmanager.SecretEnvSource{
+					EnvName:    "BOAT_ARTIFACT_ACCESS_KEY_ID",
+					SecretName: cfg.ArtifactCredentialsSecret,
+					SecretKey:  "access_key_id",
+				},
+				podmanager.SecretEnvSource{
+					EnvName:    "BOAT_ARTIFACT_SECRET_ACCESS_KEY",
+					SecretName: cfg.ArtifactCredentialsSecret,
+					SecretKey:  "secret_access_key",
+				},
+			)
+		}
+	}
+}
+
+// applyRepos wires the project's repositories from the project cache
+// (multi-repo aware) and the projects the entrypoint registers.
+func applyRepos(cfg *config.Config, _ Input, spec *podmanager.AgentPodSpec) {
+	// Wire git info from project cache (multi-repo aware).
+	if entry, ok := cfg.ProjectCache.Load()[spec.Project]; ok {
+		if len(entry.Repos) > 0 {
+			for _, r := range entry.Repos {
+				if r.Role == "primary" {
+					spec.GitURL = r.URL
+					if r.Branch != "" {
+						spec.GitDefaultBranch = r.Branch
+					}
+				} else {
+					name := r.Name
+					if name == "" {
+						name = repoNameFromURL(r.URL)
+					}
+					branch := r.Branch
+					spec.ReferenceRepos = append(spec.ReferenceRepos, podmanager.RepoRef{
+						URL: r.URL, Branch: branch, Name: name,
+					})
+				}
+			}
+		} else {
+			// Legacy single-repo fallback.
+			if entry.GitURL != "" {
+				spec.GitURL = entry.GitURL
+			}
+			if entry.DefaultBranch != "" {
+				spec.GitDefaultBranch = entry.DefaultBranch
+			}
+		}
+	}
+
+	// Build BOAT_REFERENCE_REPOS env var for the entrypoint (fallback cloning).
+	if len(spec.ReferenceRepos) > 0 {
+		var entries []string
+		for _, r := range spec.ReferenceRepos {
+			b := r.Branch
+			if b == "" {
+				b = "main"
+			}
+			entries = append(entries, fmt.Sprintf("%s=%s:%s", r.Name, r.URL, b))
+		}
+		spec.Env["BOAT_REFERENCE_REPOS"] = strings.Join(entries, ",")
+	}
+
+	// Build BOAT_PROJECTS env var from project cache for entrypoint project registration.
+	if projects := cfg.ProjectCache.Load(); len(projects) > 0 {
+		var projectEntries []string
+		for name, entry := range projects {
+			if entry.GitURL != "" && entry.Prefix != "" {
+				projectEntries = append(projectEntries, fmt.Sprintf("%s=%s:%s", name, entry.GitURL, entry.Prefix))
+			}
+		}
+		if len(projectEntries) > 0 {
+			spec.Env["BOAT_PROJECTS"] = strings.Join(projectEntries, ",")
+		}
+	}
+}
+
+// applyProjectSecrets merges the project's secret env overrides on top of
+// the controller-wide ones.
+func applyProjectSecrets(cfg *config.Config, _ Input, spec *podmanager.AgentPodSpec) {
+	// Per-project secret overrides: merge project secrets on top of globals.
+	// Matching env names replace the global entry; new env names are additive.
+	// Secrets must be named "{project}-*" to prevent cross-project access.
+	if entry, ok := cfg.ProjectCache.Load()[spec.Project]; ok {
+		for _, ps := range entry.Secrets {
+			if !strings.HasPrefix(ps.Secret, spec.Project+"-") {
+				slog.Warn("skipping secret with invalid prefix",
+					"secret", ps.Secret, "project", spec.Project)
+				continue
+			}
+			src := podmanager.SecretEnvSource{
+				EnvName: ps.Env, SecretName: ps.Secret, SecretKey: ps.Key,
+			}
+			overrideOrAppendSecretEnv(&spec.SecretEnv, src)
+
+			// Update init container credential refs for git-related overrides.
+			switch ps.Env {
+			case "GIT_TOKEN", "GIT_USERNAME":
+				spec.GitCredentialsSecret = ps.Secret
+			case "GITLAB_TOKEN":
+				spec.GitlabTokenSecret = ps.Secret
+			}
+		}
+	}
+}
+
+// applyMockScenario overrides BOAT_COMMAND to run claudeless with the
+// agent's mock_scenario file.
+func applyMockScenario(_ *config.Config, in Input, spec *podmanager.AgentPodSpec) {
+	if scenario := in.Metadata["mock_scenario"]; scenario != "" {
+		spec.Env["BOAT_COMMAND"] = fmt.Sprintf("claudeless --scenario /scenarios/%s.toml --dangerously-skip-permissions", scenario)
+	}
+}
+
+// overrideOrAppendSecretEnv replaces an existing SecretEnvSource with the
+// same EnvName, or appends if no match exists.
+func overrideOrAppendSecretEnv(envs *[]podmanager.SecretEnvSource, src podmanager.SecretEnvSource) {
+	for i, e := range *envs {
+		if e.EnvName == src.EnvName {
+			(*envs)[i] = src
+			return
+		}
+	}
+	*envs = append(*envs, src)
+}
+
+// repoNameFromURL extracts the repository name from a URL.
+// "https://github.com/org/my-repo.git" → "my-repo"
+func repoNameFromURL(rawURL string) string {
+	u := strings.TrimSuffix(rawURL, ".git")
+	parts := strings.Split(u, "/")
+	if len(parts) > 0 {
+		return parts[len(parts)-1]
+	}
+	return "repo"
+}
@@ -0,0 +1,257 @@
+package specbuilder
+
+import (
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+func TestOverrideOrAppendSecretEnv_OverridesExisting(t *testing.T) {
+	envs := []podmanager.SecretEnvSource{
+		{EnvName: "GITHUB_TOKEN", SecretName: "global-gh", SecretKey: "token"},
+		{EnvName: "OTHER_SECRET", SecretName: "other", SecretKey: "key"},
+	}
+	src := podmanager.SecretEnvSource{
+		EnvName: "GITHUB_TOKEN", SecretName: "project-gh", SecretKey: "my-token",
+	}
+	overrideOrAppendSecretEnv(&envs, src)
+
+	if len(envs) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(envs))
+	}
+	if envs[0].SecretName != "project-gh" {
+		t.Errorf("expected SecretName project-gh, got %s", envs[0].SecretName)
+	}
+	if envs[0].SecretKey != "my-token" {
+		t.Errorf("expected SecretKey my-token, got %s", envs[0].SecretKey)
+	}
+}
+
+func TestOverrideOrAppendSecretEnv_AppendsNew(t *testing.T) {
+	envs := []podmanager.SecretEnvSource{
+		{EnvName: "GITHUB_TOKEN", SecretName: "global-gh", SecretKey: "token"},
+	}
+	src := podmanager.SecretEnvSource{
+		EnvName: "JIRA_API_TOKEN", SecretName: "proj-jira", SecretKey: "api-token",
+	}
+	overrideOrAppendSecretEnv(&envs, src)
+
+	if len(envs) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(envs))
+	}
+	if envs[1].EnvName != "JIRA_API_TOKEN" {
+		t.Errorf("expected JIRA_API_TOKEN, got %s", envs[1].EnvName)
+	}
+}
+
+func TestControllerStages_PerProjectSecretOverride(t *testing.T) {
+	cfg := &config.Config{
+		GithubTokenSecret: "global-gh-token",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				Secrets: []beadsapi.SecretEntry{
+					{Env: "GITHUB_TOKEN", Secret: "myproject-gh-token", Key: "my-token"},
+				},
+			},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	// GITHUB_TOKEN should be overridden to project-specific secret.
+	found := false
+	for _, se := range spec.SecretEnv {
+		if se.EnvName == "GITHUB_TOKEN" {
+			found = true
+			if se.SecretName != "myproject-gh-token" {
+				t.Errorf("expected SecretName myproject-gh-token, got %s", se.SecretName)
+			}
+			if se.SecretKey != "my-token" {
+				t.Errorf("expected SecretKey my-token, got %s", se.SecretKey)
+			}
+		}
+	}
+	if !found {
+		t.Error("GITHUB_TOKEN not found in SecretEnv")
+	}
+}
+
+func TestControllerStages_PerProjectSecretAdditive(t *testing.T) {
+	cfg := &config.Config{
+		GithubTokenSecret: "global-gh-token",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				Secrets: []beadsapi.SecretEntry{
+					{Env: "JIRA_API_TOKEN", Secret: "myproject-jira", Key: "api-token"},
+				},
+			},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	// Both GITHUB_TOKEN (global) and JIRA_API_TOKEN (project) should be present.
+	envNames := map[string]bool{}
+	for _, se := range spec.SecretEnv {
+		envNames[se.EnvName] = true
+	}
+	if !envNames["GITHUB_TOKEN"] {
+		t.Error("expected GITHUB_TOKEN from global config")
+	}
+	if !envNames["JIRA_API_TOKEN"] {
+		t.Error("expected JIRA_API_TOKEN from project config")
+	}
+}
+
+func TestControllerStages_GitCredentialOverride(t *testing.T) {
+	cfg := &config.Config{
+		GitCredentialsSecret: "global-git-creds",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				Secrets: []beadsapi.SecretEntry{
+					{Env: "GIT_TOKEN", Secret: "myproject-git-creds", Key: "token"},
+				},
+			},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	if spec.GitCredentialsSecret != "myproject-git-creds" {
+		t.Errorf("expected GitCredentialsSecret myproject-git-creds, got %s", spec.GitCredentialsSecret)
+	}
+}
+
+func TestControllerStages_NoProjectOverrides(t *testing.T) {
+	cfg := &config.Config{
+		GithubTokenSecret: "global-gh-token",
+		ProjectCache:      config.NewSnapshot(map[string]config.ProjectCacheEntry{}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	// Should still have the global GITHUB_TOKEN.
+	found := false
+	for _, se := range spec.SecretEnv {
+		if se.EnvName == "GITHUB_TOKEN" {
+			found = true
+			if se.SecretName != "global-gh-token" {
+				t.Errorf("expected global-gh-token, got %s", se.SecretName)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected GITHUB_TOKEN from global config")
+	}
+}
+
+func TestControllerStages_ArtifactExport(t *testing.T) {
+	cfg := &config.Config{
+		ArtifactBucket:            "agent-artifacts",
+		ArtifactEndpoint:          "http://minio:9000",
+		ArtifactRegion:            "us-east-1",
+		ArtifactCredentialsSecret: "artifact-creds",
+	}
+	spec := &podmanager.AgentPodSpec{Project: "myproject", Env: map[string]string{}}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	if spec.Env["BOAT_ARTIFACT_BUCKET"] != "agent-artifacts" || spec.Env["BOAT_ARTIFACT_ENDPOINT"] != "http://minio:9000" {
+		t.Errorf("artifact env not set: %v", spec.Env)
+	}
+	if _, ok := spec.Env["BOAT_ARTIFACT_PATHS"]; ok {
+		t.Error("BOAT_ARTIFACT_PATHS set without ARTIFACT_PATHS")
+	}
+	keys := map[string]string{}
+	for _, se := range spec.SecretEnv {
+		if se.SecretName == "artifact-creds" {
+			keys[se.EnvName] = se.SecretKey
+		}
+	}
+	if keys["BOAT_ARTIFACT_ACCESS_KEY_ID"] != "access_key_id" || keys["BOAT_ARTIFACT_SECRET_ACCESS_KEY"] != "secret_access_key" {
+		t.Errorf("artifact credentials not wired: %v", keys)
+	}
+
+	spec = &podmanager.AgentPodSpec{Project: "myproject", Env: map[string]string{}}
+	runStages(&config.Config{}, spec, StageCredentials, StageRepos, StageSecrets)
+	if _, ok := spec.Env["BOAT_ARTIFACT_BUCKET"]; ok {
+		t.Error("artifact env set without a bucket")
+	}
+}
+
+func TestControllerStages_RejectsSecretWithWrongPrefix(t *testing.T) {
+	cfg := &config.Config{
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				Secrets: []beadsapi.SecretEntry{
+					// Valid: starts with "myproject-"
+					{Env: "VALID_TOKEN", Secret: "myproject-creds", Key: "token"},
+					// Invalid: starts with "pihealth-" instead of "myproject-"
+					{Env: "INVALID_TOKEN", Secret: "pihealth-jira", Key: "api-token"},
+					// Invalid: no prefix at all
+					{Env: "BAD_SECRET", Secret: "shared-secret", Key: "key"},
+				},
+			},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	// Only the valid secret should be present.
+	envNames := map[string]bool{}
+	for _, se := range spec.SecretEnv {
+		envNames[se.EnvName] = true
+	}
+	if !envNames["VALID_TOKEN"] {
+		t.Error("expected VALID_TOKEN to be present (valid prefix)")
+	}
+	if envNames["INVALID_TOKEN"] {
+		t.Error("expected INVALID_TOKEN to be skipped (wrong prefix)")
+	}
+	if envNames["BAD_SECRET"] {
+		t.Error("expected BAD_SECRET to be skipped (wrong prefix)")
+	}
+}
+
+func TestControllerStages_LeastPrivilegeServiceAccount(t *testing.T) {
+	cfg := &config.Config{
+		CoopServiceAccount: "shared-agent",
+		AgentRBAC:          true,
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"gasboat": {},
+			"custom":  {ServiceAccount: "custom-sa"},
+		}),
+	}
+
+	for project, want := range map[string]string{
+		"gasboat": "gasboat-agent",
+		"custom":  "custom-sa",
+		"unknown": "shared-agent",
+	} {
+		spec := FromBead(cfg, project, "crew", "crew", "a1", nil)
+		if spec.ServiceAccountName != want {
+			t.Errorf("%s: ServiceAccountName = %q, want %q", project, spec.ServiceAccountName, want)
+		}
+	}
+
+	cfg.AgentRBAC = false
+	if spec := FromBead(cfg, "gasboat", "crew", "crew", "a1", nil); spec.ServiceAccountName != "shared-agent" {
+		t.Errorf("AgentRBAC off: ServiceAccountName = %q, want shared-agent", spec.ServiceAccountName)
+	}
+}
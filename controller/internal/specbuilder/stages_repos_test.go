@@ -0,0 +1,120 @@
+package specbuilder
+
+import (
+	"testing"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+func TestRepoNameFromURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/org/my-repo.git", "my-repo"},
+		{"https://github.com/org/my-repo", "my-repo"},
+		{"https://gitlab.com/PiHealth/CoreFICS/monorepo", "monorepo"},
+		{"https://gitlab.com/PiHealth/CoreFICS/monorepo.git", "monorepo"},
+		{"repo", "repo"},
+	}
+	for _, tc := range tests {
+		got := repoNameFromURL(tc.url)
+		if got != tc.want {
+			t.Errorf("repoNameFromURL(%q) = %q, want %q", tc.url, got, tc.want)
+		}
+	}
+}
+
+func TestControllerStages_MultiRepo(t *testing.T) {
+	cfg := &config.Config{
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				Repos: []beadsapi.RepoEntry{
+					{URL: "https://github.com/org/main-repo.git", Branch: "develop", Role: "primary"},
+					{URL: "https://github.com/org/shared-lib.git", Role: "reference", Name: "shared-lib"},
+					{URL: "https://github.com/org/other.git", Role: "reference"},
+				},
+			},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	if spec.GitURL != "https://github.com/org/main-repo.git" {
+		t.Errorf("expected primary GitURL, got %s", spec.GitURL)
+	}
+	if spec.GitDefaultBranch != "develop" {
+		t.Errorf("expected develop branch, got %s", spec.GitDefaultBranch)
+	}
+	if len(spec.ReferenceRepos) != 2 {
+		t.Fatalf("expected 2 reference repos, got %d", len(spec.ReferenceRepos))
+	}
+	if spec.ReferenceRepos[0].Name != "shared-lib" {
+		t.Errorf("expected shared-lib, got %s", spec.ReferenceRepos[0].Name)
+	}
+	if spec.ReferenceRepos[1].Name != "other" {
+		t.Errorf("expected other (derived from URL), got %s", spec.ReferenceRepos[1].Name)
+	}
+
+	// BOAT_REFERENCE_REPOS should be set.
+	refRepos := spec.Env["BOAT_REFERENCE_REPOS"]
+	if refRepos == "" {
+		t.Fatal("expected BOAT_REFERENCE_REPOS to be set")
+	}
+}
+
+func TestControllerStages_LegacySingleRepo(t *testing.T) {
+	cfg := &config.Config{
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				GitURL:        "https://github.com/org/legacy.git",
+				DefaultBranch: "master",
+			},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	if spec.GitURL != "https://github.com/org/legacy.git" {
+		t.Errorf("expected legacy GitURL, got %s", spec.GitURL)
+	}
+	if spec.GitDefaultBranch != "master" {
+		t.Errorf("expected master branch, got %s", spec.GitDefaultBranch)
+	}
+	if len(spec.ReferenceRepos) != 0 {
+		t.Errorf("expected no reference repos, got %d", len(spec.ReferenceRepos))
+	}
+}
+
+func TestControllerStages_ReferenceOnlyRepos(t *testing.T) {
+	cfg := &config.Config{
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				Repos: []beadsapi.RepoEntry{
+					{URL: "https://github.com/org/ref1.git", Role: "reference", Name: "ref1"},
+					{URL: "https://github.com/org/ref2.git", Role: "reference", Name: "ref2"},
+				},
+			},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	runStages(cfg, spec, StageCredentials, StageRepos, StageSecrets)
+
+	if spec.GitURL != "" {
+		t.Errorf("expected empty GitURL, got %s", spec.GitURL)
+	}
+	if len(spec.ReferenceRepos) != 2 {
+		t.Fatalf("expected 2 reference repos, got %d", len(spec.ReferenceRepos))
+	}
+}
@@ -0,0 +1,147 @@
+package specbuilder
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/prestop"
+)
+
+func TestBuild_TerminationGrace(t *testing.T) {
+	cfg := &config.Config{
+		Namespace: "test",
+		RoleCache: config.NewSnapshot(map[string]config.RoleCacheEntry{
+			"crew": {TerminationGrace: 5 * time.Minute},
+		}),
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"slow": {TerminationGrace: 10 * time.Minute},
+		}),
+	}
+
+	for _, tt := range []struct {
+		project, role string
+		want          int64
+	}{
+		{"p", "captain", podmanager.DefaultTerminationGracePeriod},
+		{"p", "crew", 300},
+		{"slow", "crew", 600}, // the project's setting wins over the role's
+		{"slow", "captain", 600},
+	} {
+		spec := FromBead(cfg, tt.project, "crew", tt.role, "a1", nil)
+		if got := spec.TerminationGracePeriodSeconds; got == nil || *got != tt.want {
+			t.Errorf("%s/%s: grace period = %v, want %d", tt.project, tt.role, got, tt.want)
+		}
+	}
+}
+
+func TestApplySpotPolicy(t *testing.T) {
+	cfg := &config.Config{
+		Spot: &config.SpotPolicy{
+			NodeSelector:         map[string]string{"karpenter.sh/capacity-type": "spot"},
+			Tolerations:          []corev1.Toleration{{Key: "spot", Operator: corev1.TolerationOpExists}},
+			OnDemandNodeSelector: map[string]string{"karpenter.sh/capacity-type": "on-demand"},
+			MaxPreemptions:       2,
+		},
+	}
+
+	spec := FromBead(cfg, "proj", "job", "job", "j1", map[string]string{"preemptions": "1"})
+	if !spec.Spot || spec.NodeSelector["karpenter.sh/capacity-type"] != "spot" || len(spec.Tolerations) != 1 {
+		t.Errorf("expected spot placement, got spot=%v selector=%v tolerations=%v", spec.Spot, spec.NodeSelector, spec.Tolerations)
+	}
+	if spec.NodeSelector["kubernetes.io/arch"] != "amd64" {
+		t.Errorf("default node selector lost: %v", spec.NodeSelector)
+	}
+
+	spec = FromBead(cfg, "proj", "job", "job", "j1", map[string]string{"preemptions": "2"})
+	if spec.Spot || spec.NodeSelector["karpenter.sh/capacity-type"] != "on-demand" || len(spec.Tolerations) != 0 {
+		t.Errorf("expected on-demand after max preemptions, got spot=%v selector=%v", spec.Spot, spec.NodeSelector)
+	}
+
+	spec = FromBead(cfg, "proj", "crew", "crew", "c1", nil)
+	if spec.Spot || spec.NodeSelector["karpenter.sh/capacity-type"] != "" {
+		t.Errorf("crew agents must not be placed on spot, got %v", spec.NodeSelector)
+	}
+}
+
+func TestApplyArch(t *testing.T) {
+	cfg := &config.Config{
+		CoopImage: "agent:v1",
+		Arch: &config.ArchPolicy{
+			Images:   map[string]string{"arm64": "agent:v1-arm64"},
+			RoleArch: map[string]string{"job": "arm64"},
+		},
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"multi":  {Arch: "any"},
+			"pinned": {Arch: "arm64", Image: "custom:v2"},
+		}),
+	}
+
+	spec := FromBead(cfg, "proj", "crew", "crew", "c1", nil)
+	if spec.NodeSelector["kubernetes.io/arch"] != "amd64" || spec.Image != "agent:v1" {
+		t.Errorf("default: selector=%v image=%s", spec.NodeSelector, spec.Image)
+	}
+
+	// Role default.
+	spec = FromBead(cfg, "proj", "job", "job", "j1", nil)
+	if spec.NodeSelector["kubernetes.io/arch"] != "arm64" || spec.Image != "agent:v1-arm64" {
+		t.Errorf("role arch: selector=%v image=%s", spec.NodeSelector, spec.Image)
+	}
+
+	// Project "any" drops the selector and keeps the multi-arch image.
+	spec = FromBead(cfg, "multi", "job", "job", "j1", nil)
+	if _, ok := spec.NodeSelector["kubernetes.io/arch"]; ok || spec.Image != "agent:v1" {
+		t.Errorf("project any: selector=%v image=%s", spec.NodeSelector, spec.Image)
+	}
+
+	// A project image override is kept.
+	spec = FromBead(cfg, "pinned", "crew", "crew", "c1", nil)
+	if spec.NodeSelector["kubernetes.io/arch"] != "arm64" || spec.Image != "custom:v2" {
+		t.Errorf("project image: selector=%v image=%s", spec.NodeSelector, spec.Image)
+	}
+
+	// The agent bead wins over project and role.
+	spec = FromBead(cfg, "multi", "job", "job", "j1", map[string]string{"arch": "amd64"})
+	if spec.NodeSelector["kubernetes.io/arch"] != "amd64" || spec.Image != "agent:v1" {
+		t.Errorf("agent arch: selector=%v image=%s", spec.NodeSelector, spec.Image)
+	}
+
+	// An agent image naming the default still gets the arch image.
+	in := Input{Project: "proj", Mode: "job", Role: "job", AgentName: "j1",
+		Metadata: map[string]string{"image": "agent:v1"}}
+	spec = Default.Build(cfg, in)
+	if spec.NodeSelector["kubernetes.io/arch"] != "arm64" || spec.Image != "agent:v1-arm64" {
+		t.Errorf("agent default image: selector=%v image=%s", spec.NodeSelector, spec.Image)
+	}
+}
+
+func TestBuild_PreStop(t *testing.T) {
+	cfg := &config.Config{
+		Namespace:     "test",
+		BeadsHTTPAddr: "daemon:8080",
+		RoleCache: config.NewSnapshot(map[string]config.RoleCacheEntry{
+			"crew":   {PreStop: "flush --daemon {{.DaemonURL}} {{.BeadID}}"},
+			"broken": {PreStop: "{{.Nope}}"},
+		}),
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"quiet": {PreStop: prestop.None},
+		}),
+	}
+
+	for _, tt := range []struct {
+		project, role, want string
+	}{
+		{"p", "crew", "flush --daemon daemon:8080 bd-1"},
+		{"p", "captain", `gb yield --checkpoint --agent-id bd-1 --note "pod stopping" || true`},
+		{"p", "broken", `gb yield --checkpoint --agent-id bd-1 --note "pod stopping" || true`},
+		{"quiet", "crew", ""}, // the project's setting wins over the role's
+	} {
+		spec := Default.Build(cfg, Input{Project: tt.project, Mode: "crew", Role: tt.role, AgentName: "a1", BeadID: "bd-1"})
+		if spec.PreStop != tt.want {
+			t.Errorf("%s/%s: preStop = %q, want %q", tt.project, tt.role, spec.PreStop, tt.want)
+		}
+	}
+}
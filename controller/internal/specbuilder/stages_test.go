@@ -0,0 +1,220 @@
+package specbuilder
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/config"
+	"gasboat/controller/internal/podmanager"
+)
+
+func TestApplyProjectDefaults_RTKEnabled(t *testing.T) {
+	cfg := &config.Config{
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				RTKEnabled: true,
+			},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	applyProjectDefaults(cfg, Input{}, spec)
+
+	if spec.Env["RTK_ENABLED"] != "true" {
+		t.Errorf("expected RTK_ENABLED=true, got %q", spec.Env["RTK_ENABLED"])
+	}
+}
+
+func TestApplyProjectDefaults_DNS(t *testing.T) {
+	aliases := []corev1.HostAlias{{IP: "10.0.0.5", Hostnames: []string{"git.corp"}}}
+	dns := &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.2"}}
+	cfg := &config.Config{
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"corp": {HostAliases: aliases, DNSPolicy: corev1.DNSNone, DNSConfig: dns},
+		}),
+	}
+
+	spec := FromBead(cfg, "corp", "crew", "crew", "a1", nil)
+	if len(spec.HostAliases) != 1 || spec.DNSPolicy != corev1.DNSNone || spec.DNSConfig != dns {
+		t.Errorf("dns settings = %+v %q %+v", spec.HostAliases, spec.DNSPolicy, spec.DNSConfig)
+	}
+	if spec := FromBead(cfg, "other", "crew", "crew", "a1", nil); spec.HostAliases != nil || spec.DNSPolicy != "" || spec.DNSConfig != nil {
+		t.Errorf("other project got dns settings: %+v", spec)
+	}
+}
+
+func TestApplyProjectDefaults_Proxy(t *testing.T) {
+	cfg := &config.Config{
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"corp": {HTTPProxy: "http://proxy.corp:3128", NoProxy: "git.corp"},
+		}),
+	}
+
+	spec := FromBead(cfg, "corp", "crew", "crew", "a1", nil)
+	if p := spec.Proxy; p == nil || p.HTTPProxy != "http://proxy.corp:3128" || p.NoProxy != "git.corp" {
+		t.Errorf("proxy = %+v", p)
+	}
+	if spec := FromBead(cfg, "other", "crew", "crew", "a1", nil); spec.Proxy != nil {
+		t.Errorf("other project got proxy %+v", spec.Proxy)
+	}
+}
+
+func TestApplyProjectDefaults_RTKDisabledByDefault(t *testing.T) {
+	cfg := &config.Config{
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {},
+		}),
+	}
+	spec := &podmanager.AgentPodSpec{
+		Project: "myproject",
+		Env:     map[string]string{},
+	}
+	applyProjectDefaults(cfg, Input{}, spec)
+
+	if _, ok := spec.Env["RTK_ENABLED"]; ok {
+		t.Error("expected RTK_ENABLED to not be set when project has RTK disabled")
+	}
+}
+
+func TestBuild_RTKAgentOverrideDisable(t *testing.T) {
+	cfg := &config.Config{
+		Namespace: "test",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {
+				RTKEnabled: true,
+			},
+		}),
+	}
+	in := Input{
+		Project:   "myproject",
+		Role:      "crew",
+		AgentName: "agent1",
+		Metadata:  map[string]string{"rtk_enabled": "false"},
+	}
+	spec := Default.Build(cfg, in)
+
+	if _, ok := spec.Env["RTK_ENABLED"]; ok {
+		t.Error("expected RTK_ENABLED to be removed by agent-level override")
+	}
+}
+
+func TestBuild_RTKAgentOverrideEnable(t *testing.T) {
+	cfg := &config.Config{
+		Namespace: "test",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{
+			"myproject": {},
+		}),
+	}
+	in := Input{
+		Project:   "myproject",
+		Role:      "crew",
+		AgentName: "agent1",
+		Metadata:  map[string]string{"rtk_enabled": "true"},
+	}
+	spec := Default.Build(cfg, in)
+
+	if spec.Env["RTK_ENABLED"] != "true" {
+		t.Errorf("expected RTK_ENABLED=true from agent override, got %q", spec.Env["RTK_ENABLED"])
+	}
+}
+
+func TestBuild_PodLabelsFromBead(t *testing.T) {
+	cfg := &config.Config{Namespace: "test"}
+	in := Input{
+		Project:   "myproject",
+		Role:      "crew",
+		AgentName: "agent1",
+		Metadata: map[string]string{
+			beadsapi.PodLabelsField:      `{"team":"payments","gasboat.io/capacity":"spot","app.kubernetes.io/name":"x"}`,
+			beadsapi.PodAnnotationsField: `{"example.com/owner":"alice","gasboat.io/bead-id":"forged"}`,
+		},
+	}
+	spec := Default.Build(cfg, in)
+
+	if len(spec.ExtraLabels) != 1 || spec.ExtraLabels["team"] != "payments" {
+		t.Errorf("ExtraLabels = %v, want only team", spec.ExtraLabels)
+	}
+	if len(spec.ExtraAnnotations) != 1 || spec.ExtraAnnotations["example.com/owner"] != "alice" {
+		t.Errorf("ExtraAnnotations = %v, want only example.com/owner", spec.ExtraAnnotations)
+	}
+}
+
+func TestBuild_AgentEnvAndResources(t *testing.T) {
+	cfg := &config.Config{
+		Namespace:    "test",
+		ProjectCache: config.NewSnapshot(map[string]config.ProjectCacheEntry{"myproject": {}}),
+	}
+	spec := FromBead(cfg, "myproject", "crew", "crew", "a1", map[string]string{
+		"env":       `{"LINT":"strict"}`,
+		"resources": `{"requests":{"cpu":"2"},"limits":{"memory":"8Gi"}}`,
+	})
+	if spec.Env["LINT"] != "strict" {
+		t.Errorf("LINT = %q", spec.Env["LINT"])
+	}
+	if spec.Resources == nil || spec.Resources.Requests.Cpu().String() != "2" || spec.Resources.Limits.Memory().String() != "8Gi" {
+		t.Errorf("resources = %+v", spec.Resources)
+	}
+}
+
+func TestBuild_RoleBeadDefaults(t *testing.T) {
+	cpu := resource.MustParse("4")
+	cfg := &config.Config{
+		Namespace: "test",
+		CoopImage: "coop:default",
+		RoleCache: config.NewSnapshot(map[string]config.RoleCacheEntry{
+			"crew": {
+				Image:       "coop:crew",
+				StorageSize: "50Gi",
+				Env:         map[string]string{"ROLE_ENV": "crew", "BEADS_HTTP_ADDR": "ignored"},
+				Resources:   &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: cpu}},
+			},
+			"job": {StorageSize: "5Gi"},
+		}),
+		BeadsHTTPAddr: "daemon:8080",
+	}
+
+	spec := FromBead(cfg, "p", "crew", "crew", "a1", nil)
+	if spec.Image != "coop:crew" {
+		t.Errorf("image = %q, want the role image", spec.Image)
+	}
+	if spec.WorkspaceStorage == nil || spec.WorkspaceStorage.Size != "50Gi" {
+		t.Errorf("workspace storage = %+v, want 50Gi", spec.WorkspaceStorage)
+	}
+	if spec.Env["ROLE_ENV"] != "crew" || spec.Env["BEADS_HTTP_ADDR"] != "daemon:8080" {
+		t.Errorf("env = %v", spec.Env)
+	}
+	if spec.Resources == nil || !spec.Resources.Requests.Cpu().Equal(cpu) {
+		t.Errorf("resources = %+v, want the role's", spec.Resources)
+	}
+
+	// The agent bead's image and resources win over the role's.
+	spec = FromBead(cfg, "p", "crew", "crew", "a1", map[string]string{
+		"image":     "coop:mine",
+		"resources": `{"requests":{"cpu":"1"}}`,
+	})
+	if spec.Image != "coop:mine" || spec.Resources.Requests.Cpu().String() != "1" {
+		t.Errorf("agent overrides: image=%q resources=%+v", spec.Image, spec.Resources)
+	}
+
+	// A storage size gives job agents a workspace PVC; other roles keep
+	// the mode defaults.
+	if spec := FromBead(cfg, "p", "job", "job", "j1", nil); spec.WorkspaceStorage == nil || spec.WorkspaceStorage.Size != "5Gi" {
+		t.Errorf("job workspace storage = %+v, want 5Gi", spec.WorkspaceStorage)
+	}
+	if spec := FromBead(cfg, "p", "crew", "captain", "c1", nil); spec.Image != "coop:default" || spec.Env["ROLE_ENV"] != "" {
+		t.Errorf("captain: image=%q env=%v", spec.Image, spec.Env)
+	}
+}
+
+// runStages runs the named default stages on spec.
+func runStages(cfg *config.Config, spec *podmanager.AgentPodSpec, names ...string) {
+	stages := New().stages
+	for _, name := range names {
+		stages[name](cfg, Input{Project: spec.Project}, spec)
+	}
+}
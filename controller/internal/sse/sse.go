@@ -0,0 +1,322 @@
+// Package sse is the client for the beads daemon's Server-Sent Events
+// stream, shared by the controller's subscriber and the slack-bridge.
+//
+// A Client keeps one stream open: it resumes with Last-Event-ID after a
+// reconnect, treats a connection that has gone silent (no event and no
+// keepalive comment within the heartbeat timeout) as dead, reconnects with
+// jittered exponential backoff, and skips events the daemon replays across a
+// reconnect. Events are delivered synchronously, so a slow consumer slows
+// reading from the daemon (which buffers) instead of losing events.
+package sse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults for Config.
+const (
+	DefaultHeartbeatTimeout = 2 * time.Minute
+	DefaultMinBackoff       = time.Second
+	DefaultMaxBackoff       = 30 * time.Second
+)
+
+const (
+	// maxEventSize bounds a single SSE line.
+	maxEventSize = 1 << 20
+	// recentIDs is how many delivered event IDs are remembered to skip replays.
+	recentIDs = 256
+)
+
+// Event is one Server-Sent Event.
+type Event struct {
+	ID    string // "id:" field; empty if the server sent none
+	Topic string // "event:" field, e.g. "beads.bead.created"
+	Data  []byte // "data:" lines joined by newlines
+}
+
+// Handler processes one event. It is called from the reading goroutine; the
+// event ID becomes the resume point once it returns.
+type Handler func(ctx context.Context, ev Event)
+
+// Config configures a Client.
+type Config struct {
+	// BaseURL is the daemon HTTP base URL (e.g., "http://localhost:8080").
+	BaseURL string
+	// Topics is the optional comma-separated topic filter. Empty means all events.
+	Topics string
+	// LastEventID is the initial resume point, e.g. restored from state.
+	LastEventID string
+	// HeartbeatTimeout is how long a connection may go without any line
+	// before it is considered dead. Zero uses DefaultHeartbeatTimeout;
+	// negative disables detection.
+	HeartbeatTimeout time.Duration
+	// MinBackoff and MaxBackoff bound the reconnect delay (defaults 1s, 30s).
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	Logger     *slog.Logger
+}
+
+// Stats are cumulative stream counters.
+type Stats struct {
+	Connects          int64 // successful connections
+	Disconnects       int64 // connections or attempts that ended with an error
+	Events            int64 // events delivered to the handler
+	Duplicates        int64 // replayed events skipped
+	HeartbeatTimeouts int64 // connections dropped for silence
+}
+
+// Client is a reconnecting SSE stream reader.
+type Client struct {
+	cfg        Config
+	logger     *slog.Logger
+	httpClient *http.Client // reused across reconnections (long-lived, no timeout)
+
+	mu          sync.Mutex
+	lastID      string
+	connectedAt time.Time           // zero while disconnected
+	recent      []string            // ring of recently delivered IDs
+	recentSet   map[string]struct{} // contents of recent
+	recentNext  int
+
+	connects, disconnects, events, duplicates, heartbeatTimeouts atomic.Int64
+}
+
+// New creates a Client. Call Run to start streaming.
+func New(cfg Config) *Client {
+	if cfg.HeartbeatTimeout == 0 {
+		cfg.HeartbeatTimeout = DefaultHeartbeatTimeout
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(DefaultMaxBackoff, cfg.MinBackoff)
+	}
+	return &Client{
+		cfg:        cfg,
+		logger:     cfg.Logger,
+		httpClient: &http.Client{Timeout: 0},
+		lastID:     cfg.LastEventID,
+		recentSet:  make(map[string]struct{}, recentIDs),
+	}
+}
+
+// LastEventID returns the ID of the last event handled.
+func (c *Client) LastEventID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastID
+}
+
+// SetLastEventID sets the resume point for the next connection.
+func (c *Client) SetLastEventID(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastID = id
+}
+
+// Connected reports whether a stream is open and, if so, since when.
+func (c *Client) Connected() (bool, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.connectedAt.IsZero(), c.connectedAt
+}
+
+// Stats returns a snapshot of the stream counters.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Connects:          c.connects.Load(),
+		Disconnects:       c.disconnects.Load(),
+		Events:            c.events.Load(),
+		Duplicates:        c.duplicates.Load(),
+		HeartbeatTimeouts: c.heartbeatTimeouts.Load(),
+	}
+}
+
+// Run streams events to handle until ctx is canceled, reconnecting as
+// needed. It always returns ctx.Err().
+func (c *Client) Run(ctx context.Context, handle Handler) error {
+	backoff := c.cfg.MinBackoff
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		connected, err := c.stream(ctx, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c.disconnects.Add(1)
+		if connected {
+			backoff = c.cfg.MinBackoff
+		}
+		// Jitter spreads reconnects from many clients after a daemon restart.
+		delay := backoff + rand.N(backoff/2+1)
+		c.logger.Warn("SSE stream error, reconnecting",
+			"error", err, "backoff", delay, "last_event_id", c.LastEventID())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		if !connected {
+			backoff = min(backoff*2, c.cfg.MaxBackoff)
+		}
+	}
+}
+
+// errHeartbeat marks a connection dropped by heartbeat detection.
+var errHeartbeat = errors.New("no data or keepalive received")
+
+// stream runs one connection. connected reports whether it got as far as an
+// open stream.
+func (c *Client) stream(ctx context.Context, handle Handler) (connected bool, err error) {
+	url := strings.TrimRight(c.cfg.BaseURL, "/") + "/v1/events/stream"
+	if c.cfg.Topics != "" {
+		url += "?topics=" + c.cfg.Topics
+	}
+
+	connCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	req, err := http.NewRequestWithContext(connCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("create SSE request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	lastID := c.LastEventID()
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("SSE connect: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return false, fmt.Errorf("SSE endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	c.connects.Add(1)
+	c.mu.Lock()
+	c.connectedAt = time.Now()
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.connectedAt = time.Time{}
+		c.mu.Unlock()
+	}()
+	c.logger.Info("SSE stream connected", "url", url, "last_event_id", lastID)
+
+	// Any line, including a keepalive comment, proves the connection alive.
+	hb := c.cfg.HeartbeatTimeout
+	var timer *time.Timer
+	if hb > 0 {
+		timer = time.AfterFunc(hb, func() { cancel(errHeartbeat) })
+		defer timer.Stop()
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var ev Event
+	var data strings.Builder
+	for scanner.Scan() {
+		if timer != nil {
+			timer.Reset(hb)
+		}
+		line := scanner.Text()
+
+		// Empty line = end of event.
+		if line == "" {
+			if data.Len() > 0 && ev.Topic != "" {
+				ev.Data = []byte(data.String())
+				// The handler may block (backpressure); that isn't silence.
+				if timer != nil {
+					timer.Stop()
+				}
+				c.deliver(ctx, ev, handle)
+				if timer != nil {
+					timer.Reset(hb)
+				}
+			}
+			if ev.ID != "" {
+				c.SetLastEventID(ev.ID)
+			}
+			ev = Event{}
+			data.Reset()
+			continue
+		}
+
+		// Comment lines (keepalive).
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			ev.ID = value
+		case "event":
+			ev.Topic = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+
+	if errors.Is(context.Cause(connCtx), errHeartbeat) {
+		c.heartbeatTimeouts.Add(1)
+		return true, fmt.Errorf("%w for %s", errHeartbeat, hb)
+	}
+	if err := scanner.Err(); err != nil {
+		return true, fmt.Errorf("SSE read: %w", err)
+	}
+	return true, errors.New("SSE stream closed by server")
+}
+
+// deliver hands ev to handle unless it was already delivered.
+func (c *Client) deliver(ctx context.Context, ev Event, handle Handler) {
+	if ev.ID != "" && !c.remember(ev.ID) {
+		c.duplicates.Add(1)
+		c.logger.Debug("skipping replayed SSE event", "id", ev.ID, "topic", ev.Topic)
+		return
+	}
+	c.events.Add(1)
+	handle(ctx, ev)
+}
+
+// remember records id as delivered; it returns false if it already was.
+func (c *Client) remember(id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.recentSet[id]; ok {
+		return false
+	}
+	if len(c.recent) < recentIDs {
+		c.recent = append(c.recent, id)
+	} else {
+		delete(c.recentSet, c.recent[c.recentNext])
+		c.recent[c.recentNext] = id
+		c.recentNext = (c.recentNext + 1) % recentIDs
+	}
+	c.recentSet[id] = struct{}{}
+	return true
+}
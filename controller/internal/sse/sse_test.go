@@ -0,0 +1,231 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// collector records delivered events.
+type collector struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (c *collector) handle(_ context.Context, ev Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.events = append(c.events, ev)
+}
+
+func (c *collector) wait(t *testing.T, n int) []Event {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		c.mu.Lock()
+		got := append([]Event(nil), c.events...)
+		c.mu.Unlock()
+		if len(got) >= n {
+			return got
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d events, got %d", n, len(got))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// start runs a client against handler until the test ends.
+func start(t *testing.T, handler http.HandlerFunc, cfg Config) (*Client, *collector) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cfg.BaseURL = srv.URL
+	cfg.Logger = testLogger()
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = 10 * time.Millisecond
+	}
+	c := New(cfg)
+	col := &collector{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx, col.handle) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != context.Canceled {
+			t.Errorf("Run returned %v, want context.Canceled", err)
+		}
+	})
+	return c, col
+}
+
+func streamHeaders(w http.ResponseWriter) http.Flusher {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	f := w.(http.Flusher)
+	f.Flush()
+	return f
+}
+
+func TestClient_ParsesEvents(t *testing.T) {
+	var gotURL atomic.Value
+	c, col := start(t, func(w http.ResponseWriter, r *http.Request) {
+		gotURL.Store(r.URL.String())
+		f := streamHeaders(w)
+		fmt.Fprint(w, ": keepalive\n\n")
+		fmt.Fprint(w, "id: 1\nevent: beads.bead.created\ndata: {\"a\":\ndata: 1}\n\n")
+		fmt.Fprint(w, "id:2\ndata:{}\n\n") // no event: skipped, but resumes after it
+		fmt.Fprint(w, "id:3\nevent:beads.bead.closed\ndata:{}\n\n")
+		f.Flush()
+		<-r.Context().Done()
+	}, Config{Topics: "beads.bead.*"})
+
+	got := col.wait(t, 2)
+	if u := gotURL.Load(); u != "/v1/events/stream?topics=beads.bead.*" {
+		t.Errorf("request URL = %q", u)
+	}
+	if got[0].ID != "1" || got[0].Topic != "beads.bead.created" || string(got[0].Data) != "{\"a\":\n1}" {
+		t.Errorf("first event = %+v (data %q)", got[0], got[0].Data)
+	}
+	if got[1].ID != "3" || got[1].Topic != "beads.bead.closed" {
+		t.Errorf("second event = %+v", got[1])
+	}
+	if id := c.LastEventID(); id != "3" {
+		t.Errorf("LastEventID() = %q, want 3", id)
+	}
+	if ok, _ := c.Connected(); !ok {
+		t.Error("expected Connected() while the stream is open")
+	}
+}
+
+func TestClient_ResumesAndSkipsReplayedEvents(t *testing.T) {
+	var conns atomic.Int32
+	var mu sync.Mutex
+	var lastIDs []string
+	c, col := start(t, func(w http.ResponseWriter, r *http.Request) {
+		n := conns.Add(1)
+		mu.Lock()
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+		f := streamHeaders(w)
+		if n == 1 {
+			fmt.Fprint(w, "id:7\nevent:e\ndata:first\n\n")
+			f.Flush()
+			return
+		}
+		// A daemon that resumes inclusively replays the last event.
+		fmt.Fprint(w, "id:7\nevent:e\ndata:first\n\nid:8\nevent:e\ndata:second\n\n")
+		f.Flush()
+		<-r.Context().Done()
+	}, Config{LastEventID: "5"})
+
+	got := col.wait(t, 2)
+	if string(got[0].Data) != "first" || string(got[1].Data) != "second" {
+		t.Errorf("events = %q, %q", got[0].Data, got[1].Data)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lastIDs) < 2 || lastIDs[0] != "5" || lastIDs[1] != "7" {
+		t.Errorf("Last-Event-ID headers = %v, want [5 7]", lastIDs)
+	}
+	if s := c.Stats(); s.Duplicates != 1 || s.Events != 2 || s.Connects < 2 {
+		t.Errorf("Stats() = %+v", s)
+	}
+	if id := c.LastEventID(); id != "8" {
+		t.Errorf("LastEventID() = %q, want 8", id)
+	}
+}
+
+func TestClient_HeartbeatTimeoutReconnects(t *testing.T) {
+	var conns atomic.Int32
+	c, col := start(t, func(w http.ResponseWriter, r *http.Request) {
+		n := conns.Add(1)
+		f := streamHeaders(w)
+		fmt.Fprintf(w, "id:%d\nevent:e\ndata:x\n\n", n)
+		f.Flush()
+		<-r.Context().Done() // then silence
+	}, Config{HeartbeatTimeout: 100 * time.Millisecond})
+
+	col.wait(t, 2)
+	if s := c.Stats(); s.HeartbeatTimeouts < 1 {
+		t.Errorf("expected a heartbeat timeout, got %+v", s)
+	}
+}
+
+func TestClient_SlowHandlerIsNotAHeartbeatTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := streamHeaders(w)
+		fmt.Fprint(w, "id:1\nevent:e\ndata:x\n\nid:2\nevent:e\ndata:y\n\n")
+		f.Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	c := New(Config{BaseURL: srv.URL, HeartbeatTimeout: 50 * time.Millisecond, Logger: testLogger()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	timeouts := make(chan int64, 2)
+	go func() {
+		_ = c.Run(ctx, func(_ context.Context, ev Event) {
+			if ev.ID == "1" {
+				time.Sleep(200 * time.Millisecond) // backpressure, not silence
+			}
+			timeouts <- c.Stats().HeartbeatTimeouts
+		})
+	}()
+	for i := range 2 {
+		select {
+		case n := <-timeouts:
+			if n != 0 {
+				t.Fatalf("event %d: heartbeat timeouts = %d while handler was busy", i+1, n)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for events")
+		}
+	}
+}
+
+func TestClient_RetriesNon200WithBackoff(t *testing.T) {
+	var conns atomic.Int32
+	c, col := start(t, func(w http.ResponseWriter, r *http.Request) {
+		if conns.Add(1) < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		f := streamHeaders(w)
+		fmt.Fprint(w, "id:1\nevent:e\ndata:x\n\n")
+		f.Flush()
+		<-r.Context().Done()
+	}, Config{})
+
+	col.wait(t, 1)
+	if s := c.Stats(); s.Disconnects != 2 || s.Connects != 1 {
+		t.Errorf("Stats() = %+v, want 2 failed attempts then 1 connect", s)
+	}
+}
+
+func TestRemember_EvictsOldest(t *testing.T) {
+	c := New(Config{Logger: testLogger()})
+	for i := range recentIDs + 1 {
+		if !c.remember(fmt.Sprint(i)) {
+			t.Fatalf("id %d reported as seen", i)
+		}
+	}
+	if c.remember(fmt.Sprint(recentIDs)) {
+		t.Error("most recent id not remembered")
+	}
+	if !c.remember("0") {
+		t.Error("oldest id not evicted")
+	}
+}
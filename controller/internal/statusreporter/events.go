@@ -0,0 +1,188 @@
+package statusreporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/reconciler"
+)
+
+// Reasons of the Kubernetes Events written for agent lifecycle transitions.
+const (
+	ReasonAgentSpawned     = "AgentSpawned"
+	ReasonAgentRestarted   = "AgentRestarted"
+	ReasonAgentUpgraded    = "AgentUpgraded"
+	ReasonAgentFailed      = "AgentFailed"
+	ReasonAgentPreempted   = "AgentPreempted"
+	ReasonAgentTerminating = "AgentTerminating"
+)
+
+// eventReportingController identifies the controller in the Events it writes.
+const eventReportingController = "gasboat.io/controller"
+
+// AgentEvent is an agent lifecycle transition, written as a Kubernetes Event
+// regarding the agent's pod so kubectl describe and event exporters see it.
+type AgentEvent struct {
+	PodName   string
+	Namespace string
+	Cluster   string // cluster the pod runs on; empty for the home cluster
+	BeadID    string
+	Warning   bool   // Warning rather than Normal event
+	Reason    string // one of the Reason* constants
+	Note      string // human-readable description
+}
+
+// EventRecorder writes AgentEvents through the events.k8s.io/v1 API.
+type EventRecorder struct {
+	instance string
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewEventRecorder creates a recorder reporting as instance (the
+// controller's pod name).
+func NewEventRecorder(instance string, logger *slog.Logger) *EventRecorder {
+	return &EventRecorder{instance: instance, logger: logger, now: time.Now}
+}
+
+// record writes ev to client. Failures are logged, not returned: Events
+// are informational and must not hold up the transition they describe.
+func (e *EventRecorder) record(ctx context.Context, client kubernetes.Interface, ev AgentEvent) {
+	now := e.now()
+	eventType := corev1.EventTypeNormal
+	if ev.Warning {
+		eventType = corev1.EventTypeWarning
+	}
+	event := &eventsv1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			// Named like client-go's recorder: the object plus a unique suffix.
+			Name:      fmt.Sprintf("%s.%x", ev.PodName, now.UnixNano()),
+			Namespace: ev.Namespace,
+		},
+		EventTime:           metav1.NewMicroTime(now),
+		ReportingController: eventReportingController,
+		ReportingInstance:   e.instance,
+		Action:              ev.Reason,
+		Reason:              ev.Reason,
+		Regarding: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       ev.PodName,
+			Namespace:  ev.Namespace,
+		},
+		Note: ev.Note,
+		Type: eventType,
+	}
+	if ev.BeadID != "" {
+		event.Annotations = map[string]string{podmanager.AnnotationBeadID: ev.BeadID}
+	}
+	if _, err := client.EventsV1().Events(ev.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		e.logger.Warn("failed to write agent event",
+			"pod", ev.PodName, "reason", ev.Reason, "error", err)
+	}
+}
+
+// WithEvents makes the reporter write Kubernetes Events for agent lifecycle
+// transitions: those passed to RecordAgentEvent, the reconciler's pod
+// actions (see RecordAction) and pods SyncAll finds failed or preempted.
+func (r *HTTPReporter) WithEvents(rec *EventRecorder) *HTTPReporter {
+	r.events = rec
+	return r
+}
+
+// RecordAgentEvent writes ev as a Kubernetes Event on the cluster the pod
+// runs on. It does nothing unless events are enabled (WithEvents) and the
+// cluster is Kubernetes.
+func (r *HTTPReporter) RecordAgentEvent(ctx context.Context, ev AgentEvent) {
+	if r.events == nil {
+		return
+	}
+	if ev.Namespace == "" {
+		ev.Namespace = r.namespace
+	}
+	for _, c := range r.clusters {
+		if c.Name == ev.Cluster || ev.Cluster == "" {
+			if c.Client != nil {
+				r.events.record(ctx, c.Client, ev)
+			}
+			return
+		}
+	}
+	r.logger.Debug("no client for agent event cluster", "cluster", ev.Cluster, "pod", ev.PodName)
+}
+
+// RecordAction records a pod the reconciler created: a spawn, a restart,
+// or a drift upgrade. It implements reconciler.ActionRecorder.
+func (r *HTTPReporter) RecordAction(ctx context.Context, a reconciler.PlanAction) {
+	ev := AgentEvent{
+		PodName:   a.Pod,
+		Namespace: a.Namespace,
+		Cluster:   a.Cluster,
+		BeadID:    a.BeadID,
+		Note:      a.Reason,
+	}
+	switch {
+	case a.Kind == reconciler.ActionCreate:
+		ev.Reason = ReasonAgentSpawned
+	case a.Kind == reconciler.ActionRestart && a.Upgrade():
+		ev.Reason = ReasonAgentUpgraded
+	case a.Kind == reconciler.ActionRestart:
+		ev.Reason = ReasonAgentRestarted
+	default:
+		return
+	}
+	r.RecordAgentEvent(ctx, ev)
+}
+
+// recordTransition writes an Event the first time SyncAll sees the agent
+// of beadID in a failed or preempted state.
+func (r *HTTPReporter) recordTransition(ctx context.Context, cluster, beadID string, pod *corev1.Pod, status PodStatus) {
+	if r.events == nil {
+		return
+	}
+	state := PhaseToAgentState(status.Phase)
+	if status.Preempted {
+		state = "preempted"
+	}
+	r.mu.Lock()
+	if r.lastStates == nil {
+		r.lastStates = make(map[string]string)
+	}
+	prev := r.lastStates[beadID]
+	r.lastStates[beadID] = state
+	r.mu.Unlock()
+	if state == prev {
+		return
+	}
+	ev := AgentEvent{
+		PodName:   pod.Name,
+		Namespace: pod.Namespace,
+		Cluster:   cluster,
+		BeadID:    beadID,
+		Warning:   true,
+		Note:      status.Message,
+	}
+	switch state {
+	case "failed":
+		ev.Reason = ReasonAgentFailed
+		if ev.Note == "" {
+			ev.Note = "agent pod failed"
+		}
+	case "preempted":
+		ev.Reason = ReasonAgentPreempted
+		if ev.Note == "" {
+			ev.Note = "agent pod lost its spot node"
+		}
+	default:
+		return
+	}
+	r.RecordAgentEvent(ctx, ev)
+}
@@ -0,0 +1,120 @@
+package statusreporter
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	eventsv1 "k8s.io/api/events/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"gasboat/controller/internal/podmanager"
+	"gasboat/controller/internal/reconciler"
+)
+
+func listEvents(t *testing.T, client *fake.Clientset) []eventsv1.Event {
+	t.Helper()
+	list, err := client.EventsV1().Events("ns").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return list.Items
+}
+
+func TestRecordAgentEvent_WritesPodEvent(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	r := NewHTTPReporter(&mockBeadUpdater{}, client, "ns", testLogger()).
+		WithEvents(NewEventRecorder("controller-0", testLogger()))
+
+	r.RecordAgentEvent(context.Background(), AgentEvent{
+		PodName: "crew-proj-dev-alpha", BeadID: "bd-1",
+		Reason: ReasonAgentSpawned, Note: "agent spawned",
+	})
+
+	events := listEvents(t, client)
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1", len(events))
+	}
+	ev := events[0]
+	if ev.Regarding.Kind != "Pod" || ev.Regarding.Name != "crew-proj-dev-alpha" || ev.Regarding.Namespace != "ns" {
+		t.Errorf("regarding = %+v", ev.Regarding)
+	}
+	if ev.Reason != ReasonAgentSpawned || ev.Type != corev1.EventTypeNormal || ev.Note != "agent spawned" {
+		t.Errorf("event = reason %q type %q note %q", ev.Reason, ev.Type, ev.Note)
+	}
+	if ev.ReportingController != eventReportingController || ev.ReportingInstance != "controller-0" {
+		t.Errorf("reporting = %q/%q", ev.ReportingController, ev.ReportingInstance)
+	}
+	if ev.Annotations[podmanager.AnnotationBeadID] != "bd-1" {
+		t.Errorf("annotations = %v", ev.Annotations)
+	}
+}
+
+func TestRecordAgentEvent_DisabledWithoutRecorder(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	r := NewHTTPReporter(&mockBeadUpdater{}, client, "ns", testLogger())
+	r.RecordAgentEvent(context.Background(), AgentEvent{PodName: "p", Reason: ReasonAgentSpawned})
+	if n := len(listEvents(t, client)); n != 0 {
+		t.Errorf("events = %d, want none", n)
+	}
+}
+
+func TestRecordAction_Reasons(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	r := NewHTTPReporter(&mockBeadUpdater{}, client, "ns", testLogger()).
+		WithEvents(NewEventRecorder("controller-0", testLogger()))
+	ctx := context.Background()
+
+	r.RecordAction(ctx, reconciler.PlanAction{Kind: reconciler.ActionCreate, Pod: "a", Namespace: "ns", Reason: "missing pod"})
+	r.RecordAction(ctx, reconciler.PlanAction{Kind: reconciler.ActionRestart, Pod: "b", Namespace: "ns", Reason: "pod Failed"})
+	r.RecordAction(ctx, reconciler.PlanAction{Kind: reconciler.ActionDelete, Pod: "c", Namespace: "ns", Reason: "orphan"})
+
+	reasons := map[string]string{}
+	for _, ev := range listEvents(t, client) {
+		reasons[ev.Regarding.Name] = ev.Reason
+	}
+	want := map[string]string{"a": ReasonAgentSpawned, "b": ReasonAgentRestarted}
+	if len(reasons) != len(want) || reasons["a"] != want["a"] || reasons["b"] != want["b"] {
+		t.Errorf("event reasons = %v, want %v", reasons, want)
+	}
+}
+
+func TestSyncAll_FailedPodEventOnce(t *testing.T) {
+	pod := makePod("crew-proj-dev-alpha", "ns", corev1.PodFailed, agentLabels("proj", "dev", "alpha"), "")
+	pod.Status.Message = "OOMKilled"
+	client := fake.NewSimpleClientset(pod)
+	r := NewHTTPReporter(&mockBeadUpdater{}, client, "ns", testLogger()).
+		WithEvents(NewEventRecorder("controller-0", testLogger()))
+	ctx := context.Background()
+
+	for range 2 {
+		if err := r.SyncAll(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events := listEvents(t, client)
+	if len(events) != 1 {
+		t.Fatalf("events = %d, want 1 across both passes", len(events))
+	}
+	if ev := events[0]; ev.Reason != ReasonAgentFailed || ev.Type != corev1.EventTypeWarning || ev.Note != "OOMKilled" {
+		t.Errorf("event = reason %q type %q note %q", ev.Reason, ev.Type, ev.Note)
+	}
+
+	// Once the pod is gone, a replacement that fails again is reported again.
+	if err := client.CoreV1().Pods("ns").Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SyncAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Pods("ns").Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SyncAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(listEvents(t, client)); n != 2 {
+		t.Errorf("events = %d after the replacement failed, want 2", n)
+	}
+}
@@ -0,0 +1,93 @@
+package statusreporter
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// RegistryStore reads and replaces the coop service registry
+// (*beadsapi.Client).
+type RegistryStore interface {
+	GetCoopRegistry(ctx context.Context) (*beadsapi.CoopRegistry, error)
+	SetCoopRegistry(ctx context.Context, reg *beadsapi.CoopRegistry) error
+}
+
+// WithRegistry makes the reporter maintain the coop service registry
+// (beadsapi.CoopRegistryKey): SyncAll publishes the coop endpoint of every
+// running agent pod, and clearing an agent's backend metadata drops it.
+func (r *HTTPReporter) WithRegistry(store RegistryStore) *HTTPReporter {
+	r.registryStore = store
+	return r
+}
+
+// publishRegistry replaces the registry with agents. Entries on clusters
+// that could not be listed this pass are carried over from the previous
+// registry rather than dropped.
+func (r *HTTPReporter) publishRegistry(ctx context.Context, agents map[string]beadsapi.CoopEndpoint, unlisted map[string]bool) error {
+	r.registryMu.Lock()
+	defer r.registryMu.Unlock()
+	if err := r.loadRegistry(ctx); err != nil {
+		return err
+	}
+	for id, ep := range r.registry.Agents {
+		if _, ok := agents[id]; !ok && unlisted[ep.Cluster] {
+			agents[id] = ep
+		}
+	}
+	return r.writeRegistry(ctx, agents)
+}
+
+// forgetRegistry drops an agent from the registry.
+func (r *HTTPReporter) forgetRegistry(ctx context.Context, beadID string) error {
+	r.registryMu.Lock()
+	defer r.registryMu.Unlock()
+	if err := r.loadRegistry(ctx); err != nil {
+		return err
+	}
+	if _, ok := r.registry.Agents[beadID]; !ok {
+		return nil
+	}
+	agents := maps.Clone(r.registry.Agents)
+	delete(agents, beadID)
+	return r.writeRegistry(ctx, agents)
+}
+
+// loadRegistry reads the published registry once, so a restarted
+// controller continues its revision numbering. Callers hold registryMu.
+func (r *HTTPReporter) loadRegistry(ctx context.Context) error {
+	if r.registry != nil {
+		return nil
+	}
+	reg, err := r.registryStore.GetCoopRegistry(ctx)
+	if err != nil {
+		return fmt.Errorf("reading coop registry: %w", err)
+	}
+	if reg == nil {
+		reg = &beadsapi.CoopRegistry{Agents: make(map[string]beadsapi.CoopEndpoint)}
+	}
+	r.registry = reg
+	return nil
+}
+
+// writeRegistry publishes agents as the next revision if they differ from
+// the current registry. Callers hold registryMu.
+func (r *HTTPReporter) writeRegistry(ctx context.Context, agents map[string]beadsapi.CoopEndpoint) error {
+	if maps.Equal(agents, r.registry.Agents) {
+		return nil
+	}
+	next := &beadsapi.CoopRegistry{
+		Revision:  r.registry.Revision + 1,
+		UpdatedAt: time.Now().UTC(),
+		Agents:    agents,
+	}
+	if err := r.registryStore.SetCoopRegistry(ctx, next); err != nil {
+		return fmt.Errorf("writing coop registry: %w", err)
+	}
+	r.registry = next
+	r.logger.Info("published coop registry", "revision", next.Revision, "agents", len(agents))
+	return nil
+}
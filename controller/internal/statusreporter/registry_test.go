@@ -0,0 +1,108 @@
+package statusreporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+type memRegistry struct {
+	reg    *beadsapi.CoopRegistry
+	writes int
+}
+
+func (m *memRegistry) GetCoopRegistry(context.Context) (*beadsapi.CoopRegistry, error) {
+	return m.reg, nil
+}
+
+func (m *memRegistry) SetCoopRegistry(_ context.Context, reg *beadsapi.CoopRegistry) error {
+	m.reg = reg
+	m.writes++
+	return nil
+}
+
+func coopPod(name, agent, ip string) *corev1.Pod {
+	pod := makePod(name, "ns", corev1.PodRunning, agentLabels("proj", "dev", agent), ip)
+	pod.Spec.Containers = []corev1.Container{{Name: "coop", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}}}
+	return pod
+}
+
+func TestSyncAll_PublishesCoopRegistry(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(coopPod("crew-proj-dev-alpha", "alpha", "10.0.0.1"))
+	store := &memRegistry{reg: &beadsapi.CoopRegistry{Revision: 7, Agents: map[string]beadsapi.CoopEndpoint{
+		// Left behind by a pod that is gone since the last controller ran.
+		"crew-proj-dev-gone": {Agent: "gone", URL: "http://10.0.0.9:8080"},
+	}}}
+	r := NewHTTPReporter(&mockBeadUpdater{}, client, "ns", testLogger()).WithRegistry(store)
+
+	if err := r.SyncAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ep, ok := store.reg.Agents["crew-proj-dev-alpha"]
+	if store.reg.Revision != 8 || len(store.reg.Agents) != 1 || !ok {
+		t.Fatalf("registry = %+v, want revision 8 with only alpha", store.reg)
+	}
+	if ep.Agent != "alpha" || ep.URL != "http://10.0.0.1:8080" || ep.Pod != "crew-proj-dev-alpha" || ep.Namespace != "ns" {
+		t.Errorf("alpha endpoint = %+v", ep)
+	}
+
+	// Nothing changed: no write.
+	if err := r.SyncAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if store.writes != 1 {
+		t.Fatalf("writes = %d after unchanged sync, want 1", store.writes)
+	}
+
+	// The pod was recreated with a new IP.
+	pod := coopPod("crew-proj-dev-alpha", "alpha", "10.0.0.2")
+	if _, err := client.CoreV1().Pods("ns").Update(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SyncAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if store.reg.Revision != 9 || store.reg.Agents["crew-proj-dev-alpha"].URL != "http://10.0.0.2:8080" {
+		t.Fatalf("registry after IP change = %+v", store.reg)
+	}
+
+	// Clearing the agent's backend metadata drops it.
+	if err := r.ReportBackendMetadata(ctx, "crew-proj-dev-alpha", BackendMetadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if store.reg.Revision != 10 || len(store.reg.Agents) != 0 {
+		t.Fatalf("registry after clear = %+v", store.reg)
+	}
+}
+
+func TestSyncAll_CoopRegistryKeepsUnlistedCluster(t *testing.T) {
+	broken := fake.NewSimpleClientset()
+	broken.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("unreachable")
+	})
+	store := &memRegistry{reg: &beadsapi.CoopRegistry{Revision: 1, Agents: map[string]beadsapi.CoopEndpoint{
+		"crew-proj-dev-beta":  {Agent: "beta", URL: "http://10.1.0.1:8080", Cluster: "burst"},
+		"crew-proj-dev-gamma": {Agent: "gamma", URL: "http://10.0.0.3:8080", Cluster: "home"},
+	}}}
+	r := NewHTTPReporter(&mockBeadUpdater{}, nil, "ns", testLogger()).WithClusters(
+		Cluster{Name: "home", Client: fake.NewSimpleClientset(coopPod("crew-proj-dev-alpha", "alpha", "10.0.0.1"))},
+		Cluster{Name: "burst", Client: broken},
+	).WithRegistry(store)
+
+	if err := r.SyncAll(context.Background()); err == nil {
+		t.Fatal("expected an error for the unreachable cluster")
+	}
+	got := store.reg.Agents
+	if len(got) != 2 || got["crew-proj-dev-alpha"].Cluster != "home" || got["crew-proj-dev-beta"].Cluster != "burst" {
+		t.Errorf("registry = %+v, want alpha (home) and beta (carried over)", got)
+	}
+}
@@ -4,15 +4,18 @@ package statusreporter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
+	"gasboat/controller/internal/beadsapi"
 	"gasboat/controller/internal/podmanager"
 	"gasboat/controller/internal/reconciler"
 )
@@ -24,6 +27,9 @@ type PodStatus struct {
 	Phase     string // Pending, Running, Succeeded, Failed, Unknown
 	Ready     bool
 	Message   string
+	// Preempted is set when the pod lost (or is losing) its spot node; the
+	// agent is reported "preempted" rather than "failed".
+	Preempted bool
 }
 
 // BackendMetadata holds connection info written to agent bead notes
@@ -33,7 +39,8 @@ type BackendMetadata struct {
 	Namespace string // K8s namespace
 	Backend   string // "coop" or "k8s"
 	CoopURL   string // e.g., "http://crew-gasboat-crew-furiosa.gasboat.svc.cluster.local:8080"
-	CoopToken string // auth token (optional)
+	Cluster   string // cluster the pod runs on; empty for single-cluster controllers
+	Node      string // node the pod is scheduled on
 }
 
 // Reporter syncs pod status back to beads.
@@ -48,6 +55,10 @@ type Reporter interface {
 	// SyncAll reconciles all agent pod statuses with beads.
 	SyncAll(ctx context.Context) error
 
+	// RecordAgentEvent writes an agent lifecycle transition as a
+	// Kubernetes Event on the agent's pod.
+	RecordAgentEvent(ctx context.Context, ev AgentEvent)
+
 	// Metrics returns the current metrics snapshot.
 	Metrics() MetricsSnapshot
 }
@@ -59,11 +70,18 @@ type MetricsSnapshot struct {
 	SyncAllRuns        int64
 	SyncAllErrors      int64
 	AgentsByState      map[string]int64 // state -> count
+	PodsByCluster      map[string]int64 // cluster -> agent pods seen in the last SyncAll
 }
 
+// PhaseTerminating is the synthetic phase of a running pod whose agent was
+// told to checkpoint before the pod is deleted (see
+// podmanager.AnnotationTerminateAfter).
+const PhaseTerminating = "Terminating"
+
 // PhaseToAgentState maps a K8s pod phase to a beads agent_state.
 // In addition to standard K8s phases, it handles the synthetic "Stopped"
-// phase emitted by the controller for AgentStop events.
+// phase emitted by the controller for AgentStop events, and "Terminating"
+// for agents given a grace window to checkpoint before their pod goes.
 func PhaseToAgentState(phase string) string {
 	switch corev1.PodPhase(phase) {
 	case corev1.PodPending:
@@ -79,6 +97,9 @@ func PhaseToAgentState(phase string) string {
 		if phase == "Stopped" {
 			return "done"
 		}
+		if phase == PhaseTerminating {
+			return "terminating"
+		}
 		return ""
 	}
 }
@@ -129,13 +150,38 @@ type BeadUpdater interface {
 	UpdateAgentState(ctx context.Context, beadID, state string) error
 }
 
+// Cluster is a Kubernetes cluster whose agent pods are reported.
+type Cluster struct {
+	Name   string
+	Client kubernetes.Interface
+
+	// Pods, when set, lists the agent pods instead of Client; used for
+	// backends other than Kubernetes (podmanager.DockerManager).
+	Pods PodLister
+}
+
+// PodLister lists agent pods. podmanager.Manager implements it.
+type PodLister interface {
+	ListAgentPods(ctx context.Context, namespace string, labelSelector map[string]string) ([]corev1.Pod, error)
+}
+
 // HTTPReporter reports backend metadata to beads via the daemon HTTP API.
 type HTTPReporter struct {
 	daemon    BeadUpdater
-	client    kubernetes.Interface
+	clusters  []Cluster
 	namespace string
 	logger    *slog.Logger
 
+	mu            sync.Mutex
+	podsByCluster map[string]int64
+
+	events     *EventRecorder
+	lastStates map[string]string // bead ID → agent state SyncAll last saw
+
+	registryStore RegistryStore
+	registryMu    sync.Mutex
+	registry      *beadsapi.CoopRegistry // last read or published; nil until loaded
+
 	reportsTotal atomic.Int64
 	reportErrors atomic.Int64
 	syncRuns     atomic.Int64
@@ -146,18 +192,29 @@ type HTTPReporter struct {
 func NewHTTPReporter(daemon BeadUpdater, client kubernetes.Interface, namespace string, logger *slog.Logger) *HTTPReporter {
 	return &HTTPReporter{
 		daemon:    daemon,
-		client:    client,
+		clusters:  []Cluster{{Client: client}},
 		namespace: namespace,
 		logger:    logger,
 	}
 }
 
+// WithClusters makes SyncAll report pods from clusters instead of the single
+// client passed to NewHTTPReporter. Named clusters are recorded in the
+// agent's backend metadata.
+func (r *HTTPReporter) WithClusters(clusters ...Cluster) *HTTPReporter {
+	r.clusters = clusters
+	return r
+}
+
 // ReportPodStatus updates the agent's state in beads based on pod phase.
 // Maps K8s pod phases to beads agent states via the daemon HTTP API.
 func (r *HTTPReporter) ReportPodStatus(ctx context.Context, agentName string, status PodStatus) error {
 	r.reportsTotal.Add(1)
 
 	state := PhaseToAgentState(status.Phase)
+	if status.Preempted {
+		state = "preempted"
+	}
 	if state == "" {
 		r.logger.Debug("skipping status report for unknown phase",
 			"agent", agentName, "phase", status.Phase)
@@ -196,8 +253,17 @@ func (r *HTTPReporter) ReportBackendMetadata(ctx context.Context, agentName stri
 	if meta.CoopURL != "" {
 		lines = append(lines, fmt.Sprintf("coop_url: %s", meta.CoopURL))
 	}
-	if meta.CoopToken != "" {
-		lines = append(lines, fmt.Sprintf("coop_token: %s", meta.CoopToken))
+	if meta.Cluster != "" {
+		lines = append(lines, fmt.Sprintf("pod_cluster: %s", meta.Cluster))
+	}
+	if meta.Node != "" {
+		lines = append(lines, fmt.Sprintf("pod_node: %s", meta.Node))
+	}
+
+	if meta.CoopURL == "" && r.registryStore != nil {
+		if err := r.forgetRegistry(ctx, agentName); err != nil {
+			r.logger.Warn("failed to remove agent from coop registry", "agent", agentName, "error", err)
+		}
 	}
 
 	if len(lines) == 0 {
@@ -217,58 +283,144 @@ func (r *HTTPReporter) ReportBackendMetadata(ctx context.Context, agentName stri
 	return nil
 }
 
-// SyncAll reconciles all agent pod statuses with beads.
+// SyncAll reconciles all agent pod statuses with beads. A cluster that
+// can't be listed is skipped so the others are still reported; its error is
+// returned once every cluster has been visited.
 func (r *HTTPReporter) SyncAll(ctx context.Context) error {
 	r.syncRuns.Add(1)
 
-	pods, err := r.client.CoreV1().Pods(r.namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: "app.kubernetes.io/name=gasboat",
-	})
+	var errs []error
+	seen := make(map[string]bool)
+	counts := make(map[string]int64)
+	endpoints := make(map[string]beadsapi.CoopEndpoint)
+	unlisted := make(map[string]bool)
+	total := 0
+	for _, c := range r.clusters {
+		n, err := r.syncCluster(ctx, c, seen, endpoints)
+		if err != nil {
+			r.syncErrors.Add(1)
+			unlisted[c.Name] = true
+			if c.Name != "" {
+				err = fmt.Errorf("cluster %s: %w", c.Name, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		if c.Name != "" {
+			counts[c.Name] = int64(n)
+		}
+		total += n
+	}
+
+	r.mu.Lock()
+	r.podsByCluster = counts
+	if len(unlisted) == 0 {
+		// Forget agents whose pods are gone, so a respawn that fails again
+		// is reported again.
+		for beadID := range r.lastStates {
+			if !seen[beadID] {
+				delete(r.lastStates, beadID)
+			}
+		}
+	}
+	r.mu.Unlock()
+
+	if r.registryStore != nil {
+		if err := r.publishRegistry(ctx, endpoints, unlisted); err != nil {
+			r.logger.Warn("failed to publish coop registry", "error", err)
+		}
+	}
+
+	r.logger.Info("sync completed", "pods", total)
+	return errors.Join(errs...)
+}
+
+// syncCluster reports the agent pods on one cluster and returns how many
+// there are, adding running coop sessions to endpoints. Beads already
+// reported from an earlier cluster (a duplicate the reconciler has yet to
+// remove) are skipped.
+func (r *HTTPReporter) syncCluster(ctx context.Context, c Cluster, seen map[string]bool, endpoints map[string]beadsapi.CoopEndpoint) (int, error) {
+	pods, err := r.listPods(ctx, c)
 	if err != nil {
-		r.syncErrors.Add(1)
-		return fmt.Errorf("listing agent pods: %w", err)
+		return 0, fmt.Errorf("listing agent pods: %w", err)
 	}
 
-	for _, pod := range pods.Items {
+	agents := 0
+	for _, pod := range pods {
 		agentLabel := pod.Labels[podmanager.LabelAgent]
 		projectLabel := pod.Labels[podmanager.LabelProject]
 		roleLabel := pod.Labels[podmanager.LabelRole]
 		if agentLabel == "" || projectLabel == "" || roleLabel == "" {
 			continue
 		}
+		agents++
 
 		beadID := agentBeadID(&pod)
+		if seen[beadID] {
+			r.logger.Debug("SyncAll: skipping duplicate pod", "bead", beadID, "cluster", c.Name)
+			continue
+		}
+		seen[beadID] = true
 		status := PodStatus{
 			PodName:   pod.Name,
 			Namespace: pod.Namespace,
 			Phase:     string(pod.Status.Phase),
 			Ready:     reconciler.IsPodReady(&pod),
 			Message:   pod.Status.Message,
+			Preempted: podmanager.PreemptionReason(&pod) != "",
+		}
+		if _, ok := podmanager.TerminateAfter(&pod); ok && pod.Status.Phase == corev1.PodRunning {
+			status.Phase = PhaseTerminating
 		}
 
 		if err := r.ReportPodStatus(ctx, beadID, status); err != nil {
 			r.logger.Warn("SyncAll: failed to report pod status",
 				"bead", beadID, "pod", pod.Name, "error", err)
 		}
+		r.recordTransition(ctx, c.Name, beadID, &pod, status)
 
 		// Write backend metadata for coop-enabled pods so ResolveBackend() works
 		// after controller restarts. Detect coop by checking for port 8080 on any container.
 		// Uses pod IP because individual pods don't have DNS entries (no headless Service).
 		if coopPort := detectCoopPort(&pod); coopPort > 0 && pod.Status.PodIP != "" {
+			coopURL := fmt.Sprintf("http://%s:%d", pod.Status.PodIP, coopPort)
 			if err := r.ReportBackendMetadata(ctx, beadID, BackendMetadata{
 				PodName:   pod.Name,
 				Namespace: pod.Namespace,
 				Backend:   "coop",
-				CoopURL:   fmt.Sprintf("http://%s:%d", pod.Status.PodIP, coopPort),
+				CoopURL:   coopURL,
+				Cluster:   c.Name,
+				Node:      pod.Spec.NodeName,
 			}); err != nil {
 				r.logger.Warn("SyncAll: failed to report backend metadata",
 					"bead", beadID, "pod", pod.Name, "error", err)
 			}
+			if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+				endpoints[beadID] = beadsapi.CoopEndpoint{
+					Agent:     agentLabel,
+					URL:       coopURL,
+					Pod:       pod.Name,
+					Namespace: pod.Namespace,
+					Cluster:   c.Name,
+				}
+			}
 		}
 	}
+	return agents, nil
+}
 
-	r.logger.Info("sync completed", "pods", len(pods.Items))
-	return nil
+// listPods lists the gasboat pods of one cluster.
+func (r *HTTPReporter) listPods(ctx context.Context, c Cluster) ([]corev1.Pod, error) {
+	if c.Pods != nil {
+		return c.Pods.ListAgentPods(ctx, r.namespace, map[string]string{podmanager.LabelApp: podmanager.LabelAppValue})
+	}
+	list, err := c.Client.CoreV1().Pods(r.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "app.kubernetes.io/name=gasboat",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
 }
 
 // Metrics returns a snapshot of current metric values.
@@ -278,5 +430,19 @@ func (r *HTTPReporter) Metrics() MetricsSnapshot {
 		StatusReportErrors: r.reportErrors.Load(),
 		SyncAllRuns:        r.syncRuns.Load(),
 		SyncAllErrors:      r.syncErrors.Load(),
+		PodsByCluster:      r.podsByClusterSnapshot(),
+	}
+}
+
+func (r *HTTPReporter) podsByClusterSnapshot() map[string]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.podsByCluster) == 0 {
+		return nil
+	}
+	out := make(map[string]int64, len(r.podsByCluster))
+	for k, v := range r.podsByCluster {
+		out[k] = v
 	}
+	return out
 }
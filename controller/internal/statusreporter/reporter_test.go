@@ -10,7 +10,9 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	"gasboat/controller/internal/podmanager"
 )
@@ -60,6 +62,7 @@ func TestPhaseToAgentState(t *testing.T) {
 		{"Succeeded", "done"},
 		{"Failed", "failed"},
 		{"Stopped", "done"},
+		{"Terminating", "terminating"},
 		{"Unknown", ""},
 		{"", ""},
 		{"SomethingElse", ""},
@@ -319,6 +322,7 @@ func TestReportPodStatus_AllPhases(t *testing.T) {
 		{"Succeeded", "done"},
 		{"Failed", "failed"},
 		{"Stopped", "done"},
+		{"Terminating", "terminating"},
 	}
 	for _, tt := range phases {
 		t.Run(tt.phase, func(t *testing.T) {
@@ -354,7 +358,7 @@ func TestReportBackendMetadata_FullMetadata(t *testing.T) {
 		Namespace: "ns",
 		Backend:   "coop",
 		CoopURL:   "http://pod-1.ns.svc:8080",
-		CoopToken: "tok123",
+		Node:      "node-a",
 	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -368,7 +372,7 @@ func TestReportBackendMetadata_FullMetadata(t *testing.T) {
 		"pod_name: pod-1",
 		"pod_namespace: ns",
 		"coop_url: http://pod-1.ns.svc:8080",
-		"coop_token: tok123",
+		"pod_node: node-a",
 	} {
 		if !strings.Contains(notes, expected) {
 			t.Errorf("notes missing %q, got: %s", expected, notes)
@@ -867,3 +871,96 @@ func TestSyncAll_CoopMetadataUsesCorrectURL(t *testing.T) {
 		t.Errorf("expected beadID crew-gasboat-crew-furiosa, got %s", daemon.notesCalls[0].beadID)
 	}
 }
+
+func TestSyncAll_MultipleClusters(t *testing.T) {
+	coop := []corev1.Container{{Name: "coop", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}}}
+	alpha := makePod("crew-proj-dev-alpha", "ns", corev1.PodRunning, agentLabels("proj", "dev", "alpha"), "10.0.0.1")
+	beta := makePod("crew-proj-dev-beta", "ns", corev1.PodRunning, agentLabels("proj", "dev", "beta"), "10.1.0.1")
+	beta.Spec.Containers = coop
+	// A duplicate of alpha left on the burst cluster is not reported.
+	alphaDup := makePod("crew-proj-dev-alpha", "ns", corev1.PodFailed, agentLabels("proj", "dev", "alpha"), "")
+
+	broken := fake.NewSimpleClientset()
+	broken.PrependReactor("list", "pods", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("unreachable")
+	})
+
+	daemon := &mockBeadUpdater{}
+	r := NewHTTPReporter(daemon, nil, "ns", testLogger()).WithClusters(
+		Cluster{Name: "home", Client: fake.NewSimpleClientset(alpha)},
+		Cluster{Name: "burst", Client: fake.NewSimpleClientset(beta, alphaDup)},
+		Cluster{Name: "down", Client: broken},
+	)
+
+	err := r.SyncAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "cluster down") {
+		t.Fatalf("expected error naming cluster down, got %v", err)
+	}
+	if len(daemon.stateCalls) != 2 {
+		t.Fatalf("expected 2 state updates, got %+v", daemon.stateCalls)
+	}
+	for _, c := range daemon.stateCalls {
+		if c.state != "working" {
+			t.Errorf("bead %s reported %s, want working", c.beadID, c.state)
+		}
+	}
+	if len(daemon.notesCalls) != 1 || !strings.Contains(daemon.notesCalls[0].notes, "pod_cluster: burst") {
+		t.Errorf("expected backend metadata with pod_cluster: burst, got %+v", daemon.notesCalls)
+	}
+
+	m := r.Metrics()
+	if m.SyncAllErrors != 1 {
+		t.Errorf("SyncAllErrors = %d, want 1", m.SyncAllErrors)
+	}
+	if m.PodsByCluster["home"] != 1 || m.PodsByCluster["burst"] != 2 {
+		t.Errorf("PodsByCluster = %v", m.PodsByCluster)
+	}
+}
+
+// podList is a PodLister for backends other than Kubernetes.
+type podList []corev1.Pod
+
+func (l podList) ListAgentPods(_ context.Context, namespace string, _ map[string]string) ([]corev1.Pod, error) {
+	var pods []corev1.Pod
+	for _, p := range l {
+		if p.Namespace == namespace {
+			pods = append(pods, p)
+		}
+	}
+	return pods, nil
+}
+
+func TestSyncAll_PodListerCluster(t *testing.T) {
+	pod := makePod("crew-proj-dev-alpha", "ns", corev1.PodRunning, agentLabels("proj", "dev", "alpha"), "172.18.0.5")
+	pod.Spec.Containers = []corev1.Container{{Name: "agent", Ports: []corev1.ContainerPort{{ContainerPort: 8080}}}}
+	daemon := &mockBeadUpdater{}
+	r := NewHTTPReporter(daemon, nil, "ns", testLogger()).WithClusters(Cluster{Pods: podList{*pod}})
+
+	if err := r.SyncAll(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(daemon.stateCalls) != 1 || daemon.stateCalls[0].state != "working" {
+		t.Errorf("state updates = %+v", daemon.stateCalls)
+	}
+	if len(daemon.notesCalls) != 1 || !strings.Contains(daemon.notesCalls[0].notes, "coop_url: http://172.18.0.5:8080") {
+		t.Errorf("backend metadata = %+v", daemon.notesCalls)
+	}
+}
+
+func TestSyncAll_ReportsPreemptedSpotPods(t *testing.T) {
+	labels := agentLabels("proj", "job", "j1")
+	labels[podmanager.LabelCapacity] = podmanager.CapacitySpot
+	pod := makePod("crew-proj-job-j1", "ns", corev1.PodFailed, labels, "")
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue, Reason: "TerminationByKubelet",
+	}}
+	daemon := &mockBeadUpdater{}
+	r := NewHTTPReporter(daemon, fake.NewSimpleClientset(pod), "ns", testLogger())
+
+	if err := r.SyncAll(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(daemon.stateCalls) != 1 || daemon.stateCalls[0].state != "preempted" {
+		t.Errorf("state calls = %+v, want one preempted", daemon.stateCalls)
+	}
+}
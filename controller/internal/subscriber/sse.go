@@ -4,17 +4,15 @@
 package subscriber
 
 import (
-	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"strings"
 	"sync"
 	"time"
 
 	"gasboat/controller/internal/beadsapi"
+	"gasboat/controller/internal/sse"
 )
 
 // SSEConfig holds configuration for the SSE watcher.
@@ -34,67 +32,63 @@ type SSEConfig struct {
 
 	// BeadsGRPCAddr is the beads daemon gRPC address (host:port) for agent pod env vars.
 	BeadsGRPCAddr string
+
+	// DropEvent, when set, is asked about each received event; events it
+	// returns true for are discarded as if lost (fault injection).
+	DropEvent func(topic string) bool
+
+	// StrictEvents drops bead events carrying fields the event schema does
+	// not know, instead of only counting them.
+	StrictEvents bool
 }
 
 // SSEWatcher subscribes to the kbeads SSE event stream and translates bead
-// events into lifecycle Events. It tracks Last-Event-ID for reconnection and
-// auto-reconnects with exponential backoff.
+// events into lifecycle Events. Reconnection, Last-Event-ID resume and
+// heartbeat detection are handled by the shared sse.Client.
 type SSEWatcher struct {
-	cfg        SSEConfig
-	events     chan Event
-	logger     *slog.Logger
-	httpClient *http.Client // reused across reconnections (long-lived, no timeout)
-
-	mu          sync.Mutex
-	lastEventID string // tracks the most recent SSE event ID for reconnection
+	cfg     SSEConfig
+	events  chan Event
+	logger  *slog.Logger
+	client  *sse.Client
+	decoder *beadsapi.EventDecoder
+
+	mu       sync.Mutex
+	restarts map[string]string // bead ID → restart_requested value already acted on
 }
 
+// restartRequestTTL bounds how old a restart_requested timestamp may be and
+// still trigger a restart, so requests aren't replayed after a controller
+// restart forgets which ones it handled.
+const restartRequestTTL = 5 * time.Minute
+
 // NewSSEWatcher creates a watcher backed by the kbeads SSE event stream.
 func NewSSEWatcher(cfg SSEConfig, logger *slog.Logger) *SSEWatcher {
-	return &SSEWatcher{
-		cfg:        cfg,
-		events:     make(chan Event, 64),
-		logger:     logger,
-		httpClient: &http.Client{Timeout: 0}, // no timeout for long-lived SSE
+	w := &SSEWatcher{
+		cfg:    cfg,
+		events: make(chan Event, 64),
+		logger: logger,
+		client: sse.New(sse.Config{
+			BaseURL: cfg.BeadsHTTPAddr,
+			Topics:  cfg.Topics,
+			Logger:  logger,
+		}),
+		restarts: make(map[string]string),
+	}
+	w.decoder = &beadsapi.EventDecoder{
+		Strict: cfg.StrictEvents,
+		OnUnknown: func(topic, field string) {
+			w.logger.Debug("unknown field in SSE event", "topic", topic, "field", field)
+		},
 	}
+	return w
 }
 
 // Start begins watching the SSE stream. Blocks until ctx is canceled.
-// Reconnects with exponential backoff on errors.
+// Reconnects with jittered exponential backoff on errors.
 func (w *SSEWatcher) Start(ctx context.Context) error {
-	backoff := time.Second
-	maxBackoff := 30 * time.Second
-
-	for {
-		select {
-		case <-ctx.Done():
-			close(w.events)
-			return fmt.Errorf("watcher stopped: %w", ctx.Err())
-		default:
-		}
-
-		err := w.stream(ctx)
-		if err != nil {
-			if ctx.Err() != nil {
-				close(w.events)
-				return fmt.Errorf("watcher stopped: %w", ctx.Err())
-			}
-			w.logger.Warn("SSE stream error, reconnecting",
-				"error", err, "backoff", backoff)
-			select {
-			case <-ctx.Done():
-				close(w.events)
-				return fmt.Errorf("watcher stopped: %w", ctx.Err())
-			case <-time.After(backoff):
-			}
-			backoff *= 2
-			if backoff > maxBackoff {
-				backoff = maxBackoff
-			}
-		} else {
-			backoff = time.Second
-		}
-	}
+	err := w.client.Run(ctx, w.handle)
+	close(w.events)
+	return fmt.Errorf("watcher stopped: %w", err)
 }
 
 // Events returns a read-only channel of lifecycle events.
@@ -102,135 +96,25 @@ func (w *SSEWatcher) Events() <-chan Event {
 	return w.events
 }
 
-// stream connects to the SSE endpoint and reads events until disconnection.
-func (w *SSEWatcher) stream(ctx context.Context) error {
-	url := strings.TrimRight(w.cfg.BeadsHTTPAddr, "/") + "/v1/events/stream"
-	if w.cfg.Topics != "" {
-		url += "?topics=" + w.cfg.Topics
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return fmt.Errorf("create SSE request: %w", err)
-	}
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-
-	// Send Last-Event-ID for reconnection replay.
-	w.mu.Lock()
-	lastID := w.lastEventID
-	w.mu.Unlock()
-	if lastID != "" {
-		req.Header.Set("Last-Event-ID", lastID)
-	}
-
-	w.logger.Info("connecting to SSE event stream",
-		"url", url, "last_event_id", lastID)
-
-	resp, err := w.httpClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("SSE connect: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("SSE endpoint returned status %d", resp.StatusCode)
-	}
-
-	w.logger.Info("SSE stream connected")
-
-	scanner := bufio.NewScanner(resp.Body)
-	// Increase scanner buffer for large events.
-	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024)
-
-	var (
-		eventID   string
-		eventType string
-		eventData string
-	)
-
-	for scanner.Scan() {
-		// Check for context cancellation.
-		if ctx.Err() != nil {
-			return nil
-		}
-
-		line := scanner.Text()
-
-		// Empty line = end of event.
-		if line == "" {
-			if eventData != "" && eventType != "" {
-				w.processSSEEvent(eventID, eventType, eventData)
-			}
-			// Update last event ID for reconnection.
-			if eventID != "" {
-				w.mu.Lock()
-				w.lastEventID = eventID
-				w.mu.Unlock()
-			}
-			eventID = ""
-			eventType = ""
-			eventData = ""
-			continue
-		}
-
-		// Comment lines (keepalive).
-		if strings.HasPrefix(line, ":") {
-			continue
-		}
-
-		// Parse SSE fields.
-		if strings.HasPrefix(line, "id:") {
-			eventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
-		} else if strings.HasPrefix(line, "event:") {
-			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
-		} else if strings.HasPrefix(line, "data:") {
-			eventData = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("SSE stream read: %w", err)
+// handle receives one event from the stream.
+func (w *SSEWatcher) handle(ctx context.Context, ev sse.Event) {
+	if w.cfg.DropEvent != nil && w.cfg.DropEvent(ev.Topic) {
+		w.logger.Debug("dropping SSE event", "id", ev.ID, "topic", ev.Topic)
+		return
 	}
-
-	return fmt.Errorf("SSE stream closed by server")
-}
-
-// sseBeadPayload matches the kbeads event JSON for bead lifecycle events.
-// BeadCreated: {"bead": {...}}
-// BeadUpdated: {"bead": {...}, "changes": {...}}
-// BeadClosed:  {"bead": {...}, "closed_by": "..."}
-// BeadDeleted: {"bead_id": "..."}
-type sseBeadPayload struct {
-	Bead     *sseBead       `json:"bead,omitempty"`
-	Changes  map[string]any `json:"changes,omitempty"`
-	ClosedBy string         `json:"closed_by,omitempty"`
-	BeadID   string         `json:"bead_id,omitempty"` // for delete events
-}
-
-// sseBead mirrors the kbeads model.Bead fields we need for event mapping.
-type sseBead struct {
-	ID         string          `json:"id"`
-	Title      string          `json:"title"`
-	Type       string          `json:"type"`
-	Status     string          `json:"status"`
-	Labels     []string        `json:"labels"`
-	Fields     json.RawMessage `json:"fields"` // raw JSON object
-	AgentState string          `json:"agent_state"`
-	Assignee   string          `json:"assignee"`
-	CreatedBy  string          `json:"created_by"`
+	w.processSSEEvent(ctx, ev.ID, ev.Topic, string(ev.Data))
 }
 
 // processSSEEvent parses an SSE event and emits a lifecycle Event if relevant.
-func (w *SSEWatcher) processSSEEvent(id, topic, data string) {
+func (w *SSEWatcher) processSSEEvent(ctx context.Context, id, topic, data string) {
 	// Only care about bead lifecycle events.
 	if !strings.HasPrefix(topic, "beads.bead.") {
 		return
 	}
 
-	var payload sseBeadPayload
-	if err := json.Unmarshal([]byte(data), &payload); err != nil {
-		w.logger.Debug("skipping malformed SSE event",
+	payload, err := w.decoder.Decode(topic, []byte(data))
+	if err != nil {
+		w.logger.Warn("skipping undecodable SSE event",
 			"id", id, "topic", topic, "error", err)
 		return
 	}
@@ -258,7 +142,7 @@ func (w *SSEWatcher) processSSEEvent(id, topic, data string) {
 	}
 
 	// Convert to the internal beadData format the mapBeadEvent expects.
-	fields := beadsapi.ParseFieldsJSON(payload.Bead.Fields)
+	fields := payload.Bead.FieldsMap()
 	bd := beadData{
 		ID:         payload.Bead.ID,
 		Title:      payload.Bead.Title,
@@ -285,17 +169,25 @@ func (w *SSEWatcher) processSSEEvent(id, topic, data string) {
 	if !ok {
 		return
 	}
+	event.ID = id
 
 	w.logger.Info("emitting lifecycle event from SSE",
 		"type", event.Type, "project", event.Project,
 		"role", event.Role, "agent", event.AgentName,
 		"bead", event.BeadID, "sse_id", id)
 
+	// A full channel stalls the stream rather than losing the event; the
+	// daemon buffers until the handler catches up.
 	select {
 	case w.events <- event:
+		return
 	default:
-		w.logger.Warn("event channel full, dropping event",
-			"type", event.Type, "bead", event.BeadID)
+	}
+	w.logger.Warn("event channel full, waiting for consumer",
+		"type", event.Type, "bead", event.BeadID)
+	select {
+	case w.events <- event:
+	case <-ctx.Done():
 	}
 }
 
@@ -310,6 +202,10 @@ func (w *SSEWatcher) mapBeadEvent(action string, payload beadEventPayload) (Even
 		if payload.Bead.AgentState == "stopping" {
 			return w.buildEvent(AgentStop, payload.Bead)
 		}
+		// Check for a new restart request (gb agent restart).
+		if w.newRestartRequest(payload.Bead) {
+			return w.buildEvent(AgentStuck, payload.Bead)
+		}
 		// Check if status changed to in_progress (re-spawn).
 		if payload.Bead.Status == "in_progress" {
 			if _, ok := payload.Changes["status"]; ok {
@@ -326,6 +222,27 @@ func (w *SSEWatcher) mapBeadEvent(action string, payload beadEventPayload) (Even
 	}
 }
 
+// newRestartRequest reports whether the bead carries a recent
+// restart_requested timestamp (RFC 3339) that hasn't been acted on yet, and
+// records it as handled.
+func (w *SSEWatcher) newRestartRequest(bead beadData) bool {
+	value := bead.Fields["restart_requested"]
+	if value == "" {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil || time.Since(at) > restartRequestTTL {
+		return false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.restarts[bead.ID] == value {
+		return false
+	}
+	w.restarts[bead.ID] = value
+	return true
+}
+
 // buildEvent constructs a lifecycle Event from a bead payload.
 // Same logic as NATSWatcher.buildEvent.
 func (w *SSEWatcher) buildEvent(eventType EventType, bead beadData) (Event, bool) {
@@ -349,6 +266,7 @@ func (w *SSEWatcher) buildEvent(eventType EventType, bead beadData) (Event, bool
 	for k, v := range bead.Fields {
 		meta[k] = v
 	}
+	beadsapi.MergePodLabels(meta, bead.Labels)
 	meta["namespace"] = w.cfg.Namespace
 	if w.cfg.CoopImage != "" && meta["image"] == "" {
 		meta["image"] = w.cfg.CoopImage
@@ -368,18 +286,30 @@ func (w *SSEWatcher) buildEvent(eventType EventType, bead beadData) (Event, bool
 	}, true
 }
 
+// UnknownEventFields returns how often each field unknown to the event
+// schema has been received (e.g. "bead.owner_team" → 12).
+func (w *SSEWatcher) UnknownEventFields() map[string]int64 {
+	return w.decoder.UnknownFields()
+}
+
+// Connected reports whether the SSE stream is currently connected and, if so,
+// since when. Thread-safe.
+func (w *SSEWatcher) Connected() (bool, time.Time) {
+	return w.client.Connected()
+}
+
 // LastEventID returns the most recently seen SSE event ID. Thread-safe.
 func (w *SSEWatcher) LastEventID() string {
-	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.lastEventID
+	return w.client.LastEventID()
 }
 
 // SetLastEventID sets the last event ID for reconnection. Thread-safe.
 // Useful for restoring state after a process restart.
 func (w *SSEWatcher) SetLastEventID(id string) {
-	w.mu.Lock()
-	w.lastEventID = id
-	w.mu.Unlock()
+	w.client.SetLastEventID(id)
 }
 
+// StreamStats returns the SSE stream's connection and delivery counters.
+func (w *SSEWatcher) StreamStats() sse.Stats {
+	return w.client.Stats()
+}
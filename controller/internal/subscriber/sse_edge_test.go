@@ -353,3 +353,92 @@ func TestSSEWatcher_MissingEventField(t *testing.T) {
 
 	cancel()
 }
+
+// TestSSEWatcher_ConnectedTracksStream verifies that Connected reflects
+// whether the stream is currently open.
+func TestSSEWatcher_ConnectedTracksStream(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	w := NewSSEWatcher(SSEConfig{BeadsHTTPAddr: srv.URL}, testLogger())
+	if ok, _ := w.Connected(); ok {
+		t.Fatal("expected disconnected before Start")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = w.Start(ctx) }()
+
+	waitFor := func(want bool) {
+		t.Helper()
+		for ctx.Err() == nil {
+			if ok, _ := w.Connected(); ok == want {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for Connected() == %v", want)
+	}
+	waitFor(true)
+	if _, since := w.Connected(); since.IsZero() {
+		t.Error("expected a connection time")
+	}
+	close(release) // server ends the stream; the watcher backs off before reconnecting
+	waitFor(false)
+}
+
+// TestSSEWatcher_DropEvent verifies that events rejected by the DropEvent
+// hook are discarded but still advance Last-Event-ID.
+func TestSSEWatcher_DropEvent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for i, agent := range []string{"dropped", "kept"} {
+			data, _ := json.Marshal(map[string]any{
+				"bead": map[string]any{
+					"id":     "kd-" + agent,
+					"type":   "agent",
+					"status": "in_progress",
+					"fields": map[string]string{"project": "p", "role": "devops", "agent": agent},
+				},
+			})
+			fmt.Fprintf(w, "id:%d\nevent:beads.bead.created\ndata:%s\n\n", i+1, data)
+		}
+		flusher.Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	var calls atomic.Int32
+	w := NewSSEWatcher(SSEConfig{
+		BeadsHTTPAddr: srv.URL,
+		DropEvent:     func(string) bool { return calls.Add(1) == 1 },
+	}, testLogger())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = w.Start(ctx) }()
+
+	select {
+	case event := <-w.Events():
+		if event.BeadID != "kd-kept" {
+			t.Fatalf("expected the dropped event to be skipped, got %s", event.BeadID)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for event")
+	}
+	if id := w.LastEventID(); id != "2" {
+		t.Errorf("expected Last-Event-ID 2, got %q", id)
+	}
+}
@@ -169,6 +169,9 @@ func TestSSEWatcher_ParsesAgentClosedEvent(t *testing.T) {
 		if event.BeadID != "kd-close1" {
 			t.Fatalf("expected bead ID kd-close1, got %s", event.BeadID)
 		}
+		if event.ID != "42" {
+			t.Errorf("expected event ID 42, got %q", event.ID)
+		}
 	case <-ctx.Done():
 		t.Fatal("timed out waiting for event")
 	}
@@ -278,6 +281,44 @@ func TestSSEWatcher_AgentStopOnUpdated(t *testing.T) {
 	cancel()
 }
 
+// TestSSEWatcher_RestartRequestedOnUpdated verifies that a fresh
+// restart_requested value maps to AgentStuck exactly once, and that stale
+// timestamps are ignored.
+func TestSSEWatcher_RestartRequestedOnUpdated(t *testing.T) {
+	w := NewSSEWatcher(SSEConfig{Namespace: "ns"}, testLogger())
+
+	bead := beadData{
+		ID:     "kd-restart1",
+		Type:   "agent",
+		Status: "in_progress",
+		Fields: map[string]string{
+			"project":           "p",
+			"role":              "crew",
+			"agent":             "a1",
+			"restart_requested": time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	event, ok := w.mapBeadEvent("updated", beadEventPayload{Bead: bead})
+	if !ok || event.Type != AgentStuck {
+		t.Fatalf("expected AgentStuck, got %s (ok=%v)", event.Type, ok)
+	}
+
+	// The same request replayed (e.g. an unrelated field update) must not
+	// restart the pod again.
+	event, ok = w.mapBeadEvent("updated", beadEventPayload{Bead: bead})
+	if !ok || event.Type != AgentUpdate {
+		t.Fatalf("expected AgentUpdate on replay, got %s (ok=%v)", event.Type, ok)
+	}
+
+	bead.ID = "kd-restart2"
+	bead.Fields["restart_requested"] = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	event, ok = w.mapBeadEvent("updated", beadEventPayload{Bead: bead})
+	if !ok || event.Type != AgentUpdate {
+		t.Fatalf("expected AgentUpdate for stale request, got %s (ok=%v)", event.Type, ok)
+	}
+}
+
 // TestSSEWatcher_KeepaliveIgnored verifies that keepalive comments don't break parsing.
 func TestSSEWatcher_KeepaliveIgnored(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -357,3 +398,42 @@ func TestSSEWatcher_TopicFilterInURL(t *testing.T) {
 		t.Fatalf("expected URL %q, got %q", expected, receivedPath)
 	}
 }
+
+// TestSSEWatcher_VersionedPayloadsAndStrictMode verifies that v2 payloads map
+// like v1 and that strict mode drops events with unknown fields.
+func TestSSEWatcher_VersionedPayloadsAndStrictMode(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, _ := w.(http.Flusher)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		fields := `"fields": {"project": "p", "role": "devops", "agent": "a1"}`
+		fmt.Fprintf(w, "id:1\nevent:beads.bead.created\ndata:{\"bead\": {\"id\": \"kd-new1\", \"type\": \"agent\", \"owner_team\": \"infra\", %s}}\n\n", fields)
+		fmt.Fprintf(w, "id:2\nevent:beads.bead.updated\ndata:{\"version\": 2, \"bead\": {\"id\": \"kd-v2\", \"type\": \"agent\", \"status\": \"in_progress\", %s}, \"changes\": [{\"field\": \"status\", \"old\": \"open\", \"new\": \"in_progress\"}]}\n\n", fields)
+		flusher.Flush()
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	w := NewSSEWatcher(SSEConfig{BeadsHTTPAddr: srv.URL, Namespace: "ns", StrictEvents: true}, testLogger())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = w.Start(ctx) }()
+
+	select {
+	case event := <-w.Events():
+		// The strict-mode rejection of kd-new1 means kd-v2 comes first; its
+		// status change maps to a re-spawn.
+		if event.BeadID != "kd-v2" || event.Type != AgentSpawn {
+			t.Fatalf("expected AgentSpawn for kd-v2, got %s for %s", event.Type, event.BeadID)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for event")
+	}
+	cancel()
+
+	if got := w.UnknownEventFields(); got["bead.owner_team"] != 1 {
+		t.Errorf("UnknownEventFields() = %v", got)
+	}
+}
@@ -33,6 +33,7 @@ const (
 
 // Event represents a beads lifecycle event that requires a pod operation.
 type Event struct {
+	ID        string // SSE event ID; replays of one event share it
 	Type      EventType
 	Project   string
 	Mode      string
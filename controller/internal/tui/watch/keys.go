@@ -0,0 +1,52 @@
+package watch
+
+import "github.com/charmbracelet/bubbles/key"
+
+// KeyMap defines the key bindings for the watch TUI.
+type KeyMap struct {
+	Refresh key.Binding
+	Pause   key.Binding // Freeze the event feed
+	Clear   key.Binding // Clear the event feed
+
+	Help key.Binding
+	Quit key.Binding
+}
+
+// DefaultKeyMap returns the default key bindings.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Refresh: key.NewBinding(
+			key.WithKeys("R", "r"),
+			key.WithHelp("r", "refresh"),
+		),
+		Pause: key.NewBinding(
+			key.WithKeys("p", " "),
+			key.WithHelp("p", "pause events"),
+		),
+		Clear: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "clear events"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "help"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "esc", "ctrl+c"),
+			key.WithHelp("q", "quit"),
+		),
+	}
+}
+
+// ShortHelp returns key bindings for the short help view.
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Refresh, k.Pause, k.Clear, k.Help, k.Quit}
+}
+
+// FullHelp returns key bindings for the full help view.
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Refresh, k.Pause, k.Clear},
+		{k.Help, k.Quit},
+	}
+}
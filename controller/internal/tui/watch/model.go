@@ -0,0 +1,346 @@
+// Package watch provides a Bubbletea TUI dashboard of agents, pending
+// decisions, and recent bead events. It is the terminal counterpart of the
+// Slack agent dashboard: the roster is fetched via the beadsapi HTTP client
+// and refreshed whenever the daemon's SSE stream reports an agent or decision
+// change, with periodic polling as a fallback.
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	pollInterval      = 15 * time.Second
+	reconnectInterval = 2 * time.Second
+	maxEvents         = 200
+)
+
+// EventItem is a display-friendly summary of one SSE bead event.
+type EventItem struct {
+	At       time.Time
+	Topic    string
+	BeadID   string
+	BeadType string
+	Title    string
+	Status   string
+	Actor    string
+}
+
+// Model is the Bubbletea model for the watch dashboard.
+type Model struct {
+	// Dimensions
+	width, height int
+
+	// Data
+	agents     []beadsapi.AgentBead
+	decisions  []*beadsapi.BeadDetail
+	events     []EventItem // newest first
+	lastUpdate time.Time
+
+	// UI state
+	keys     KeyMap
+	help     help.Model
+	showHelp bool
+	project  string // empty = all projects
+	paused   bool   // stop appending events
+	fetching bool
+	stale    bool // a change arrived while fetching
+	live     bool // SSE stream connected
+	err      error
+
+	// API client
+	client *beadsapi.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New creates a new watch TUI model.
+func New(client *beadsapi.Client) *Model {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Model{
+		keys:   DefaultKeyMap(),
+		help:   help.New(),
+		client: client,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// SetProject limits the agent roster to one project.
+func (m *Model) SetProject(project string) {
+	m.project = project
+}
+
+// Init initializes the model.
+func (m *Model) Init() tea.Cmd {
+	m.fetching = true
+	return tea.Batch(
+		m.fetchSnapshot(),
+		m.connect(),
+		m.startPolling(),
+		tea.SetWindowTitle("GB Watch"),
+	)
+}
+
+// --- Messages ---
+
+type snapshotMsg struct {
+	agents    []beadsapi.AgentBead
+	decisions []*beadsapi.BeadDetail
+	err       error
+}
+
+type tickMsg time.Time
+
+type connectedMsg struct {
+	events <-chan beadsapi.SSEEvent
+}
+
+type streamErrMsg struct {
+	err error
+}
+
+type streamEventMsg struct {
+	evt    beadsapi.SSEEvent
+	events <-chan beadsapi.SSEEvent
+}
+
+type reconnectMsg struct{}
+
+// --- Commands ---
+
+// fetchSnapshot fetches the agent roster and pending decisions.
+func (m *Model) fetchSnapshot() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		agents, err := client.ListAgentBeads(ctx)
+		if err != nil {
+			return snapshotMsg{err: fmt.Errorf("list agents: %w", err)}
+		}
+		decisions, err := client.ListDecisionBeads(ctx)
+		if err != nil {
+			return snapshotMsg{err: fmt.Errorf("list decisions: %w", err)}
+		}
+		return snapshotMsg{agents: agents, decisions: decisions}
+	}
+}
+
+// startPolling starts the fallback poll ticker.
+func (m *Model) startPolling() tea.Cmd {
+	return tea.Tick(pollInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// connect opens the daemon SSE stream for bead lifecycle events.
+func (m *Model) connect() tea.Cmd {
+	client, ctx := m.client, m.ctx
+	return func() tea.Msg {
+		events, err := client.EventStream(ctx, "beads.bead.>")
+		if err != nil {
+			return streamErrMsg{err: err}
+		}
+		return connectedMsg{events: events}
+	}
+}
+
+// waitForEvent delivers the next SSE event, or streamErrMsg when the stream
+// ends.
+func waitForEvent(events <-chan beadsapi.SSEEvent) tea.Cmd {
+	return func() tea.Msg {
+		evt, ok := <-events
+		if !ok {
+			return streamErrMsg{err: fmt.Errorf("event stream closed")}
+		}
+		return streamEventMsg{evt: evt, events: events}
+	}
+}
+
+// refresh fetches a snapshot unless one is in flight, in which case another
+// fetch follows it.
+func (m *Model) refresh() tea.Cmd {
+	if m.fetching {
+		m.stale = true
+		return nil
+	}
+	m.fetching = true
+	return m.fetchSnapshot()
+}
+
+// Update handles messages.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			m.cancel()
+			return m, tea.Quit
+
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = !m.showHelp
+
+		case key.Matches(msg, m.keys.Refresh):
+			cmds = append(cmds, m.refresh())
+
+		case key.Matches(msg, m.keys.Pause):
+			m.paused = !m.paused
+
+		case key.Matches(msg, m.keys.Clear):
+			m.events = nil
+		}
+
+	case snapshotMsg:
+		m.fetching = false
+		if msg.err != nil {
+			m.err = msg.err
+		} else {
+			m.err = nil
+			m.agents = m.filterAgents(msg.agents)
+			m.decisions = msg.decisions
+			m.lastUpdate = time.Now()
+		}
+		if m.stale {
+			m.stale = false
+			cmds = append(cmds, m.refresh())
+		}
+
+	case tickMsg:
+		cmds = append(cmds, m.refresh(), m.startPolling())
+
+	case connectedMsg:
+		m.live = true
+		cmds = append(cmds, waitForEvent(msg.events))
+
+	case streamErrMsg:
+		m.live = false
+		cmds = append(cmds, tea.Tick(reconnectInterval, func(time.Time) tea.Msg {
+			return reconnectMsg{}
+		}))
+
+	case reconnectMsg:
+		if m.ctx.Err() == nil {
+			cmds = append(cmds, m.connect())
+		}
+
+	case streamEventMsg:
+		item, ok := parseEvent(msg.evt, time.Now())
+		if ok {
+			if !m.paused {
+				m.addEvent(item)
+			}
+			if item.BeadType == "agent" || item.BeadType == "decision" {
+				cmds = append(cmds, m.refresh())
+			}
+		}
+		cmds = append(cmds, waitForEvent(msg.events))
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// addEvent prepends an event, keeping at most maxEvents.
+func (m *Model) addEvent(item EventItem) {
+	m.events = append([]EventItem{item}, m.events...)
+	if len(m.events) > maxEvents {
+		m.events = m.events[:maxEvents]
+	}
+}
+
+// filterAgents applies the project filter and sorts by project, then name.
+func (m *Model) filterAgents(agents []beadsapi.AgentBead) []beadsapi.AgentBead {
+	var result []beadsapi.AgentBead
+	for _, a := range agents {
+		if m.project == "" || a.Project == m.project {
+			result = append(result, a)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		ri, rj := stateRank(result[i]), stateRank(result[j])
+		if ri != rj {
+			return ri < rj
+		}
+		if result[i].Project != result[j].Project {
+			return result[i].Project < result[j].Project
+		}
+		return result[i].AgentName < result[j].AgentName
+	})
+	return result
+}
+
+// agentStatus classifies an agent the same way the Slack dashboard does.
+func agentStatus(a beadsapi.AgentBead) string {
+	switch {
+	case a.AgentState == "failed" || a.PodPhase == "failed":
+		return "dead"
+	case a.AgentState == "working":
+		return "working"
+	default:
+		return "idle"
+	}
+}
+
+func stateRank(a beadsapi.AgentBead) int {
+	switch agentStatus(a) {
+	case "working":
+		return 0
+	case "idle":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// parseEvent summarizes a beads.bead.* SSE event.
+func parseEvent(evt beadsapi.SSEEvent, at time.Time) (EventItem, bool) {
+	var payload struct {
+		Bead struct {
+			ID        string `json:"id"`
+			Type      string `json:"type"`
+			Title     string `json:"title"`
+			Status    string `json:"status"`
+			Assignee  string `json:"assignee"`
+			CreatedBy string `json:"created_by"`
+		} `json:"bead"`
+	}
+	if err := json.Unmarshal(evt.Data, &payload); err != nil || payload.Bead.ID == "" {
+		return EventItem{}, false
+	}
+	b := payload.Bead
+	actor := b.Assignee
+	if actor == "" {
+		actor = b.CreatedBy
+	}
+	return EventItem{
+		At:       at,
+		Topic:    evt.Event,
+		BeadID:   b.ID,
+		BeadType: b.Type,
+		Title:    b.Title,
+		Status:   b.Status,
+		Actor:    actor,
+	}, true
+}
+
+// View renders the TUI.
+func (m *Model) View() string {
+	return m.renderView()
+}
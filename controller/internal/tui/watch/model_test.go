@@ -0,0 +1,160 @@
+package watch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gasboat/controller/internal/beadsapi"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestFilterAgents tests project filtering and working/idle/dead ordering.
+func TestFilterAgents(t *testing.T) {
+	m := New(nil)
+	m.SetProject("gasboat")
+
+	agents := []beadsapi.AgentBead{
+		{AgentName: "zed", Project: "gasboat", AgentState: "working"},
+		{AgentName: "dead", Project: "gasboat", AgentState: "failed"},
+		{AgentName: "idle", Project: "gasboat", AgentState: "done"},
+		{AgentName: "alpha", Project: "gasboat", AgentState: "working"},
+		{AgentName: "other", Project: "town", AgentState: "working"},
+	}
+
+	got := m.filterAgents(agents)
+
+	want := []string{"alpha", "zed", "idle", "dead"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d agents, want %d", len(got), len(want))
+	}
+	for i, name := range want {
+		if got[i].AgentName != name {
+			t.Errorf("position %d: got %q, want %q", i, got[i].AgentName, name)
+		}
+	}
+}
+
+// TestAgentStatus tests that pod failure marks an agent dead.
+func TestAgentStatus(t *testing.T) {
+	tests := []struct {
+		agent beadsapi.AgentBead
+		want  string
+	}{
+		{beadsapi.AgentBead{AgentState: "working"}, "working"},
+		{beadsapi.AgentBead{AgentState: "working", PodPhase: "failed"}, "dead"},
+		{beadsapi.AgentBead{AgentState: "failed"}, "dead"},
+		{beadsapi.AgentBead{AgentState: "spawning"}, "idle"},
+		{beadsapi.AgentBead{}, "idle"},
+	}
+	for _, tt := range tests {
+		if got := agentStatus(tt.agent); got != tt.want {
+			t.Errorf("agentStatus(%+v) = %q, want %q", tt.agent, got, tt.want)
+		}
+	}
+}
+
+// TestParseEvent tests SSE bead event parsing.
+func TestParseEvent(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	evt := beadsapi.SSEEvent{
+		Event: "beads.bead.closed",
+		Data:  json.RawMessage(`{"bead":{"id":"kd-1","type":"task","title":"Fix it","status":"closed","assignee":"k8s"}}`),
+	}
+
+	item, ok := parseEvent(evt, at)
+	if !ok {
+		t.Fatal("parseEvent returned !ok")
+	}
+	if item.BeadID != "kd-1" || item.BeadType != "task" || item.Actor != "k8s" || !item.At.Equal(at) {
+		t.Errorf("unexpected item: %+v", item)
+	}
+
+	if _, ok := parseEvent(beadsapi.SSEEvent{Event: "beads.bead.updated", Data: json.RawMessage(`{}`)}, at); ok {
+		t.Error("expected !ok for event without a bead")
+	}
+}
+
+// TestStreamEventRefreshesOnAgentChange tests that agent events trigger a
+// snapshot fetch while other bead types only feed the event list.
+func TestStreamEventRefreshesOnAgentChange(t *testing.T) {
+	m := New(nil)
+	ch := make(chan beadsapi.SSEEvent)
+
+	m.Update(streamEventMsg{
+		evt:    beadsapi.SSEEvent{Event: "beads.bead.created", Data: json.RawMessage(`{"bead":{"id":"kd-2","type":"task"}}`)},
+		events: ch,
+	})
+	if m.fetching {
+		t.Error("task event should not trigger a fetch")
+	}
+	if len(m.events) != 1 {
+		t.Fatalf("events = %d, want 1", len(m.events))
+	}
+
+	m.Update(streamEventMsg{
+		evt:    beadsapi.SSEEvent{Event: "beads.bead.updated", Data: json.RawMessage(`{"bead":{"id":"kd-3","type":"agent"}}`)},
+		events: ch,
+	})
+	if !m.fetching {
+		t.Error("agent event should trigger a fetch")
+	}
+
+	// A second change while fetching is coalesced into one follow-up fetch.
+	m.Update(streamEventMsg{
+		evt:    beadsapi.SSEEvent{Event: "beads.bead.updated", Data: json.RawMessage(`{"bead":{"id":"kd-3","type":"agent"}}`)},
+		events: ch,
+	})
+	if !m.stale {
+		t.Error("change during fetch should mark the snapshot stale")
+	}
+	if m.events[0].BeadID != "kd-3" || len(m.events) != 3 {
+		t.Errorf("events not prepended: %+v", m.events)
+	}
+}
+
+// TestPauseKeepsEventsFrozen tests that paused feeds drop new events.
+func TestPauseKeepsEventsFrozen(t *testing.T) {
+	m := New(nil)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if !m.paused {
+		t.Fatal("p should pause the event feed")
+	}
+	m.Update(streamEventMsg{
+		evt:    beadsapi.SSEEvent{Event: "beads.bead.created", Data: json.RawMessage(`{"bead":{"id":"kd-4","type":"task"}}`)},
+		events: make(chan beadsapi.SSEEvent),
+	})
+	if len(m.events) != 0 {
+		t.Errorf("paused feed recorded %d events", len(m.events))
+	}
+}
+
+// TestRenderView tests that the dashboard renders all three sections.
+func TestRenderView(t *testing.T) {
+	m := New(nil)
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m.Update(snapshotMsg{
+		agents: []beadsapi.AgentBead{{AgentName: "k8s", Project: "gasboat", Role: "crew", AgentState: "working"}},
+		decisions: []*beadsapi.BeadDetail{
+			{ID: "kd-9", Title: "Merge?", Fields: map[string]string{"question": "Merge the PR?"}},
+		},
+	})
+
+	view := m.View()
+	for _, want := range []string{"Agent Watch", "1 working", "k8s", "Merge the PR?", "Recent Events"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("hello", 10); got != "hello" {
+		t.Errorf("truncate short = %q", got)
+	}
+	if got := truncate("hello world", 6); got != "hello…" {
+		t.Errorf("truncate long = %q", got)
+	}
+}
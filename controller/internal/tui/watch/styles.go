@@ -0,0 +1,72 @@
+package watch
+
+import "github.com/charmbracelet/lipgloss"
+
+// Color palette (shared with the decision TUI).
+var (
+	colorDead    = lipgloss.Color("196") // bright red
+	colorPending = lipgloss.Color("214") // orange
+	colorWorking = lipgloss.Color("76")  // green
+	colorAccent  = lipgloss.Color("39")  // blue
+	colorMuted   = lipgloss.Color("242") // gray
+	colorWhite   = lipgloss.Color("15")
+)
+
+// Styles for the watch TUI
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("12"))
+
+	sectionStyle = lipgloss.NewStyle().
+			Foreground(colorMuted)
+
+	workingStyle = lipgloss.NewStyle().
+			Foreground(colorWorking)
+
+	idleStyle = lipgloss.NewStyle().
+			Foreground(colorWhite)
+
+	deadStyle = lipgloss.NewStyle().
+			Foreground(colorDead).
+			Bold(true)
+
+	pendingStyle = lipgloss.NewStyle().
+			Foreground(colorPending)
+
+	escalatedStyle = lipgloss.NewStyle().
+			Foreground(colorDead).
+			Bold(true)
+
+	idStyle = lipgloss.NewStyle().
+		Foreground(colorAccent)
+
+	mutedStyle = lipgloss.NewStyle().
+			Foreground(colorMuted)
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(colorMuted)
+
+	liveStyle = lipgloss.NewStyle().
+			Foreground(colorWorking).
+			Bold(true)
+
+	offlineStyle = lipgloss.NewStyle().
+			Foreground(colorPending).
+			Bold(true)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(colorDead)
+)
+
+// statusBadge returns a styled status marker for an agent.
+func statusBadge(status string) string {
+	switch status {
+	case "working":
+		return workingStyle.Render("●")
+	case "dead":
+		return deadStyle.Render("✗")
+	default:
+		return idleStyle.Render("○")
+	}
+}
@@ -0,0 +1,224 @@
+package watch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gasboat/controller/internal/beadsapi"
+)
+
+// renderView renders the entire view.
+func (m *Model) renderView() string {
+	var b strings.Builder
+
+	if m.width < 40 || m.height < 10 {
+		return "Terminal too small. Please resize."
+	}
+
+	b.WriteString(m.renderHeader())
+	b.WriteString("\n")
+
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(truncate(fmt.Sprintf("Error: %v", m.err), m.width)))
+		b.WriteString("\n")
+	}
+
+	// Layout: agents get up to half the screen, decisions up to a quarter,
+	// events fill what is left. Each section spends one line on its header.
+	footer := 2
+	if m.showHelp {
+		footer = 4
+	}
+	avail := m.height - 2 - footer
+	agentRows := min(len(m.agents), max(3, avail/2-1))
+	decisionRows := min(len(m.decisions), max(2, avail/4-1))
+	eventRows := max(1, avail-agentRows-decisionRows-3)
+
+	b.WriteString(m.renderAgents(agentRows))
+	b.WriteString(m.renderDecisions(decisionRows))
+	b.WriteString(m.renderEvents(eventRows))
+
+	if m.showHelp {
+		b.WriteString("\n")
+		b.WriteString(m.help.View(m.keys))
+	} else {
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("r: refresh  p: pause events  c: clear events  ?: help  q: quit"))
+	}
+
+	return b.String()
+}
+
+// renderHeader renders the title line with roster counters.
+func (m *Model) renderHeader() string {
+	var working, idle, dead int
+	for _, a := range m.agents {
+		switch agentStatus(a) {
+		case "working":
+			working++
+		case "dead":
+			dead++
+		default:
+			idle++
+		}
+	}
+
+	conn := liveStyle.Render("● live")
+	if !m.live {
+		conn = offlineStyle.Render("○ reconnecting")
+	}
+	title := "Agent Watch"
+	if m.project != "" {
+		title += " · " + m.project
+	}
+	summary := fmt.Sprintf("%d working · %d idle · %d dead · %d decisions",
+		working, idle, dead, len(m.decisions))
+	if !m.lastUpdate.IsZero() {
+		summary += " · updated " + m.lastUpdate.Format("15:04:05")
+	}
+	if m.paused {
+		summary += " · events paused"
+	}
+	return titleStyle.Render(title) + "  " + conn + "  " + mutedStyle.Render(summary)
+}
+
+// renderAgents renders the agent roster.
+func (m *Model) renderAgents(rows int) string {
+	var b strings.Builder
+	b.WriteString(m.sectionHeader(fmt.Sprintf("Agents (%d)", len(m.agents))))
+
+	if len(m.agents) == 0 {
+		b.WriteString(mutedStyle.Render("  No agents."))
+		b.WriteString("\n")
+		return b.String()
+	}
+	for i, a := range m.agents {
+		if i >= rows {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("  ... and %d more", len(m.agents)-i)))
+			b.WriteString("\n")
+			break
+		}
+		b.WriteString(m.renderAgentLine(a))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderAgentLine renders one agent: status, name, project/role, state.
+func (m *Model) renderAgentLine(a beadsapi.AgentBead) string {
+	status := agentStatus(a)
+	state := a.AgentState
+	if state == "" {
+		state = a.PodPhase
+	}
+	role := a.Role
+	if a.Mode != "" && a.Mode != a.Role {
+		role = a.Mode + "/" + a.Role
+	}
+	text := fmt.Sprintf("%-20s %-14s %-12s %s", a.AgentName, a.Project, role, state)
+	text = truncate(text, m.width-4)
+
+	style := idleStyle
+	switch status {
+	case "working":
+		style = workingStyle
+	case "dead":
+		style = deadStyle
+	}
+	return " " + statusBadge(status) + " " + style.Render(text)
+}
+
+// renderDecisions renders pending decisions.
+func (m *Model) renderDecisions(rows int) string {
+	var b strings.Builder
+	b.WriteString(m.sectionHeader(fmt.Sprintf("Pending Decisions (%d)", len(m.decisions))))
+
+	if len(m.decisions) == 0 {
+		b.WriteString(mutedStyle.Render("  None."))
+		b.WriteString("\n")
+		return b.String()
+	}
+	for i, d := range m.decisions {
+		if i >= rows {
+			b.WriteString(mutedStyle.Render(fmt.Sprintf("  ... and %d more", len(m.decisions)-i)))
+			b.WriteString("\n")
+			break
+		}
+		question := d.Fields["question"]
+		if question == "" {
+			question = d.Title
+		}
+		marker := pendingStyle.Render("?")
+		for _, label := range d.Labels {
+			if label == "escalated" {
+				marker = escalatedStyle.Render("!")
+				break
+			}
+		}
+		who := ""
+		if d.Assignee != "" {
+			who = d.Assignee + " · "
+		}
+		rest := truncate(who+question, m.width-len(d.ID)-6)
+		b.WriteString(" " + marker + " " + idStyle.Render(d.ID) + " " + rest)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderEvents renders the recent event feed, newest first.
+func (m *Model) renderEvents(rows int) string {
+	var b strings.Builder
+	b.WriteString(m.sectionHeader("Recent Events"))
+
+	if len(m.events) == 0 {
+		b.WriteString(mutedStyle.Render("  Waiting for events..."))
+		b.WriteString("\n")
+		return b.String()
+	}
+	for i, e := range m.events {
+		if i >= rows {
+			break
+		}
+		b.WriteString(m.renderEventLine(e))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderEventLine renders one event: time, action, bead, and title.
+func (m *Model) renderEventLine(e EventItem) string {
+	action := e.Topic[strings.LastIndexByte(e.Topic, '.')+1:]
+	kind := e.BeadType
+	if kind == "" {
+		kind = "bead"
+	}
+	detail := e.Title
+	if e.Actor != "" {
+		detail += " (" + e.Actor + ")"
+	}
+	prefix := fmt.Sprintf(" %s %-8s %-9s ", e.At.Format(time.TimeOnly), action, kind)
+	rest := truncate(detail, m.width-len(prefix)-len(e.BeadID)-2)
+	return mutedStyle.Render(prefix) + idStyle.Render(e.BeadID) + " " + rest
+}
+
+// sectionHeader renders a full-width section rule.
+func (m *Model) sectionHeader(title string) string {
+	header := "─── " + title + " "
+	header += strings.Repeat("─", max(0, m.width-utf8.RuneCountInString(header)-2))
+	return sectionStyle.Render(header) + "\n"
+}
+
+// truncate shortens s to at most n runes, marking the cut with "…".
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
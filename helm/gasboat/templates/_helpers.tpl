@@ -174,6 +174,15 @@ Coopmux service URL
 {{- printf "http://%s:%d" (include "gasboat.coopmux.fullname" .) (int .Values.coopmux.service.port) }}
 {{- end }}
 
+{{/*
+Slack bridge service URL
+*/}}
+{{- define "gasboat.slackBridge.serviceURL" -}}
+{{- $port := 8090 -}}
+{{- with .Values.slackBridge.service }}{{ $port = .port | default 8090 }}{{ end -}}
+{{- printf "http://%s-slack-bridge:%d" (include "gasboat.fullname" .) (int $port) }}
+{{- end }}
+
 {{/*
 Coopmux ingress middlewares — shared middleware list for all ingress routes.
 Accepts a dict with "fullname" and "Values" keys plus an "includeBasicAuth" boolean.
@@ -298,25 +298,16 @@ fi
 
 echo "${SETTINGS_JSON}" | jq . > "${CLAUDE_DIR}/settings.json"
 
-# Materialize hooks from config beads (writes workspace .claude/settings.json).
-# Falls back to hardcoded hooks if daemon is unreachable or no config beads exist.
+# Prime the workspace and register the session: gb setup session materializes
+# hooks from config beads (falling back to the hardcoded defaults when the
+# daemon is unreachable or no config beads exist) and marks the agent bead
+# working for this session.
 mkdir -p "${WORKSPACE}/.claude"
-MATERIALIZED=0
 
 if command -v gb &>/dev/null; then
-    echo "[entrypoint] Materializing hooks from config beads (role: ${ROLE})"
-    if gb setup claude --workspace="${WORKSPACE}" --role="${ROLE}" 2>&1; then
-        if grep -q '"hooks"' "${WORKSPACE}/.claude/settings.json" 2>/dev/null; then
-            MATERIALIZED=1
-            echo "[entrypoint] Hooks materialized from config beads"
-        fi
-    fi
-fi
-
-if [ "${MATERIALIZED}" = "0" ]; then
-    echo "[entrypoint] No config beads found, installing default gb hooks"
-    gb setup claude --defaults --workspace="${WORKSPACE}" 2>&1 || \
-        echo "[entrypoint] WARNING: gb setup --defaults failed, no hooks installed"
+    echo "[entrypoint] Setting up session (role: ${ROLE})"
+    gb setup session --workspace="${WORKSPACE}" --role="${ROLE}" 2>&1 || \
+        echo "[entrypoint] WARNING: gb setup session failed, hooks may be missing"
 fi
 
 # ── RTK context file ─────────────────────────────────────────────────────